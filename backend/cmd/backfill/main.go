@@ -0,0 +1,79 @@
+// Command backfill runs a one-off data migration listed in database.Backfills.
+//
+//	go run ./cmd/backfill -list
+//	go run ./cmd/backfill -name <name> -batch 200 -rate 1000
+//
+// Progress is recorded after every batch and can be followed with GET /api/admin/jobs/:name.
+// An interrupted backfill resumes after the last processed row when run again, a completed one only runs again with -restart.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/database"
+)
+
+func main() {
+	envFile := flag.String("env", ".env", "Path to .env file")
+	name := flag.String("name", "", "Backfill to run")
+	list := flag.Bool("list", false, "List the backfills")
+	batch := flag.Int("batch", 0, "Rows updated per transaction, overrides the backfill's batch size")
+	rate := flag.Float64("rate", -1, "Rows updated per second at most, 0 for unlimited, overrides the backfill's rate")
+	restart := flag.Bool("restart", false, "Run a completed backfill again from the first row")
+	flag.Parse()
+
+	if *list {
+		names := make([]string, 0, len(database.Backfills))
+		for n := range database.Backfills {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			fmt.Printf("%s\t%s\n", n, database.Backfills[n].Description)
+		}
+		return
+	}
+
+	backfill, ok := database.Backfills[*name]
+	if !ok {
+		log.Fatalf("Unknown backfill %q, see -list", *name)
+	}
+	if *batch > 0 {
+		backfill.BatchSize = *batch
+	}
+	if *rate >= 0 {
+		backfill.Rate = *rate
+	}
+
+	cfg, err := config.Load(*envFile)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	db, err := database.Connect(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	// an interrupted backfill is recorded as failed and resumes where it stopped
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := database.RunBackfill(ctx, db, database.BackfillRows(*name, backfill), *restart); err != nil {
+		if errors.Is(err, database.ErrBackfillRunning) {
+			log.Fatalf("Backfill %s is already running", *name)
+		}
+		log.Fatalf("Backfill failed: %v", err)
+	}
+	log.Printf("Backfill %s completed", *name)
+}
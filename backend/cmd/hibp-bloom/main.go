@@ -0,0 +1,89 @@
+// Command hibp-bloom builds the breached password filter used by the password policy
+// from the SHA-1 Pwned Passwords list of Have I Been Pwned, one "HASH:COUNT" per line.
+//
+//	go run ./cmd/hibp-bloom -in pwned-passwords-sha1.txt -out breached.bloom -min-count 10
+//
+// The filter size follows from the number of hashes and the false positive rate, about 1.8 MB per million
+// hashes at 0.1%. Keeping only hashes seen at least -min-count times keeps it small enough to load in memory.
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"flag"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"NodeTurtleAPI/internal/services/passwords"
+)
+
+func main() {
+	in := flag.String("in", "", "Path to the Pwned Passwords SHA-1 list")
+	out := flag.String("out", "breached.bloom", "Path the filter is written to")
+	minCount := flag.Int("min-count", 1, "Only include passwords seen in at least this many breaches")
+	rate := flag.Float64("fp", 0.001, "False positive rate")
+	flag.Parse()
+
+	if *in == "" {
+		log.Fatal("Missing -in")
+	}
+
+	// first pass counts the hashes, so the filter can be sized for them
+	var n uint64
+	if err := eachHash(*in, *minCount, func([sha1.Size]byte) { n++ }); err != nil {
+		log.Fatalf("Failed to read hashes: %v", err)
+	}
+
+	filter := passwords.NewBloomFilter(n, *rate)
+	if err := eachHash(*in, *minCount, filter.AddHash); err != nil {
+		log.Fatalf("Failed to read hashes: %v", err)
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatalf("Failed to create filter file: %v", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if _, err := filter.WriteTo(w); err != nil {
+		log.Fatalf("Failed to write filter: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		log.Fatalf("Failed to write filter: %v", err)
+	}
+
+	log.Printf("Wrote %d hashes to %s", n, *out)
+}
+
+func eachHash(path string, minCount int, fn func([sha1.Size]byte)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		hashHex, countStr, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if countStr != "" {
+			if count, err := strconv.Atoi(countStr); err == nil && count < minCount {
+				continue
+			}
+		}
+
+		var hash [sha1.Size]byte
+		if len(hashHex) != 2*sha1.Size {
+			continue
+		}
+		if _, err := hex.Decode(hash[:], []byte(hashHex)); err != nil {
+			continue
+		}
+		fn(hash)
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,57 @@
+// Command replay-events rebuilds a table derived from the domain event log, see internal/services/events.
+//
+//	go run ./cmd/replay-events -projection project_counters -dry-run
+//
+// A dry run reports how many rows drifted from the log without changing them.
+// Events are appended by the API in the meantime, writers wait for the rebuild to finish.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/database"
+	"NodeTurtleAPI/internal/services/events"
+)
+
+func main() {
+	envFile := flag.String("env", ".env", "Path to .env file")
+	projection := flag.String("projection", "", "Projection to rebuild: "+strings.Join(events.Projections(), ", "))
+	dryRun := flag.Bool("dry-run", false, "Report the drifted rows without fixing them")
+	flag.Parse()
+
+	if *projection == "" {
+		log.Fatal("Missing -projection")
+	}
+
+	cfg, err := config.Load(*envFile)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	db, err := database.Connect(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	eventService := events.NewEventService(db)
+	rebuild, err := eventService.Rebuild(ctx, *projection, *dryRun)
+	if err != nil {
+		log.Fatalf("Failed to rebuild %s: %v", *projection, err)
+	}
+
+	if rebuild.DryRun {
+		log.Printf("Replayed %d events, %d rows of %s drifted", rebuild.Events, rebuild.RowsFixed, rebuild.Projection)
+		return
+	}
+	log.Printf("Replayed %d events, fixed %d rows of %s", rebuild.Events, rebuild.RowsFixed, rebuild.Projection)
+}
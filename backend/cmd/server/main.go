@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
@@ -10,6 +12,7 @@ import (
 	"NodeTurtleAPI/internal/api"
 	"NodeTurtleAPI/internal/config"
 	"NodeTurtleAPI/internal/database"
+	"NodeTurtleAPI/internal/logging"
 )
 
 func main() {
@@ -24,15 +27,32 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Connect to database
-	db, err := database.Connect(cfg.Database)
+	// log JSON lines for log aggregation, including what is written through the log package
+	logger := logging.New(os.Stdout, cfg.Env != "PROD")
+	slog.SetDefault(logger)
+
+	// Connect to database, or to the replica when starting in read-only mode
+	db, mirror, err := database.ConnectMirror(cfg.Database)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 	defer db.Close()
 
+	// every region users were placed in needs its database
+	shards, err := database.OpenShards(cfg.Shards, db, cfg.Database.MaxOpenConns)
+	if err != nil {
+		log.Fatalf("Failed to connect to regional databases: %v", err)
+	}
+	defer shards.Close()
+	if err := shards.CheckRegions(context.Background()); err != nil {
+		log.Fatalf("Failed to check regional databases: %v", err)
+	}
+
 	// Start the API server
-	server := api.NewServer(cfg, db)
+	server, err := api.NewServer(cfg, db, mirror, logger)
+	if err != nil {
+		log.Fatalf("Failed to create server: %v", err)
+	}
 	go func() {
 		if err := server.Start(); err != nil {
 			log.Printf("Server shutdown: %v", err)
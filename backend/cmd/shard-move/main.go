@@ -0,0 +1,76 @@
+// Command shard-move moves a user's data to the database of another region, see internal/database/shards.go.
+//
+//	go run ./cmd/shard-move -staging -user 6f1c...-... -region eu
+//
+// An empty -region moves the user back to the primary database. The move is recorded in shard_moves.
+// Services don't read regional databases yet, so the command refuses to run without -staging or with ENV=PROD.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func main() {
+	envFile := flag.String("env", ".env", "Path to .env file")
+	user := flag.String("user", "", "ID of the user to move")
+	region := flag.String("region", "", "Region to move the user to, empty for the primary database")
+	staging := flag.Bool("staging", false, "Confirm this is not a production environment")
+	flag.Parse()
+
+	if !*staging {
+		log.Fatal("Moved users lose access to their data until services read regional databases, pass -staging outside production")
+	}
+
+	userID, err := uuid.Parse(*user)
+	if err != nil {
+		log.Fatal("Missing or invalid -user")
+	}
+
+	cfg, err := config.Load(*envFile)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if cfg.Env == "PROD" {
+		log.Fatal("Refusing to move users in production")
+	}
+
+	db, err := database.Connect(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	shards, err := database.OpenShards(cfg.Shards, db, cfg.Database.MaxOpenConns)
+	if err != nil {
+		log.Fatalf("Failed to connect to regional databases: %v", err)
+	}
+	defer shards.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	move, err := database.MoveUser(ctx, shards, userID, *region)
+	if err != nil {
+		log.Fatalf("Failed to move user: %v", err)
+	}
+
+	tables := make([]string, 0, len(move.RowsCopied))
+	for table := range move.RowsCopied {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		log.Printf("%s: %d rows", table, move.RowsCopied[table])
+	}
+	log.Printf("Moved user %s from %q to %q (move %d)", userID, move.FromRegion, move.ToRegion, move.ID)
+}
@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/ids"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/annotations"
+	"NodeTurtleAPI/internal/services/projects"
+	"NodeTurtleAPI/internal/services/users"
+	"NodeTurtleAPI/internal/utils"
+	"context"
+	"log"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnotations(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	s := annotations.NewAnnotationService(db)
+	moderator := testData.Users[UserAlice].ID
+	bob := testData.Users[UserBob].ID
+
+	// not annotated yet
+	a, err := s.GetUserAnnotation(bob)
+	assert.NoError(t, err)
+	assert.Empty(t, a.Labels)
+	assert.Nil(t, a.UpdatedBy)
+
+	a, err = s.SetUserAnnotation(bob, data.AnnotationUpdate{Labels: []string{"Repeat Offender", "repeat offender"}, Notes: " Ticket 12 "}, moderator)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"repeat offender"}, a.Labels)
+	assert.Equal(t, "Ticket 12", a.Notes)
+	assert.Equal(t, moderator, *a.UpdatedBy)
+
+	// replaced, not merged
+	_, err = s.SetUserAnnotation(bob, data.AnnotationUpdate{Labels: []string{"verified educator"}}, moderator)
+	assert.NoError(t, err)
+	a, err = s.GetUserAnnotation(bob)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"verified educator"}, a.Labels)
+	assert.Empty(t, a.Notes)
+
+	_, err = s.SetUserAnnotation(uuid.New(), data.AnnotationUpdate{Labels: []string{"spam"}}, moderator)
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+
+	projectID := testData.Projects[ProjectAlicePublic].ID
+	_, err = s.SetProjectAnnotation(projectID, data.AnnotationUpdate{Labels: []string{"curriculum"}}, moderator)
+	assert.NoError(t, err)
+
+	// admin listings filter by label
+	us := users.NewUserService(db)
+	filters := data.DefaultUserFilter()
+	filters.Label = utils.Ptr("Verified Educator")
+	found, total, err := us.ListUsers(context.Background(), filters)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, bob, found[0].ID)
+
+	ps := projects.NewProjectService(db, ids.V7, config.QuotasConfig{})
+	projectsFound, total, err := ps.ListProjects(context.Background(), data.ProjectFilter{Page: 1, Limit: 10, Label: utils.Ptr("curriculum")})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, projectID, projectsFound[0].ID)
+}
+
+func TestNormalizeLabels(t *testing.T) {
+	assert.Equal(t, []string{"repeat offender", "verified educator"},
+		annotations.NormalizeLabels([]string{" Verified   Educator", "", "repeat offender", "REPEAT OFFENDER"}))
+	assert.Equal(t, []string{}, annotations.NormalizeLabels(nil))
+}
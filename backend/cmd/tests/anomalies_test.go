@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/clock"
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/anomalies"
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAnalyzeAnomalies(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec("TRUNCATE admin_alerts")
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	cfg := config.AnomaliesConfig{
+		Interval: 5,
+		// the registrations of the test data don't make a spike
+		RegistrationFactor: 5,
+		MinRegistrations:   1000,
+		LikesPerASN:        3,
+		RouteErrors:        2,
+		Emails:             []string{"ops@example.com"},
+	}
+	clk := clock.NewFake(time.Date(2026, 3, 1, 12, 1, 0, 0, time.UTC))
+	counters := anomalies.NewCounters(5*time.Minute, clk)
+	mockMailService := mocks.MockMailService{}
+	mockMailService.On("SendEmail", "ops@example.com", mock.Anything, "anomaly_alert", mock.Anything).Return(nil)
+	s := anomalies.NewAnomalyService(db, cfg, counters, &mockMailService, clk)
+
+	for i := 0; i < 3; i++ {
+		counters.RecordLike("AS64500")
+		counters.RecordServerError("/api/projects/:id")
+	}
+	counters.RecordLike("AS64501")
+	counters.RecordServerError("/api/users/:id")
+
+	// the window is analyzed once it ended
+	raised, err := s.Analyze(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, raised)
+
+	clk.Advance(5 * time.Minute)
+	raised, err = s.Analyze(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, raised, 2) {
+		subjects := map[data.AlertKind]string{}
+		for _, alert := range raised {
+			subjects[alert.Kind] = alert.Subject
+			assert.Equal(t, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), alert.WindowStart.UTC())
+			assert.Equal(t, 3, alert.Observed)
+		}
+		assert.Equal(t, "AS64500", subjects[data.AlertLikesSurge])
+		assert.Equal(t, "/api/projects/:id", subjects[data.AlertServerErrors])
+	}
+	mockMailService.AssertNumberOfCalls(t, "SendEmail", 2)
+
+	// another instance noticing the same anomaly doesn't raise it again
+	other := anomalies.NewCounters(5*time.Minute, clock.NewFake(time.Date(2026, 3, 1, 12, 2, 0, 0, time.UTC)))
+	for i := 0; i < 3; i++ {
+		other.RecordServerError("/api/projects/:id")
+	}
+	raised, err = anomalies.NewAnomalyService(db, cfg, other, &mockMailService, clk).Analyze(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, raised)
+	mockMailService.AssertNumberOfCalls(t, "SendEmail", 2)
+
+	alerts, err := s.ListAlerts(ctx, data.AlertFilter{Kind: data.AlertServerErrors, Limit: 10})
+	assert.NoError(t, err)
+	if !assert.Len(t, alerts, 1) {
+		return
+	}
+
+	admin := testData.Users[UserAlice].ID
+	acknowledged, err := s.AcknowledgeAlert(ctx, alerts[0].ID, admin)
+	assert.NoError(t, err)
+	if assert.NotNil(t, acknowledged) && assert.NotNil(t, acknowledged.AcknowledgedBy) {
+		assert.Equal(t, admin, *acknowledged.AcknowledgedBy)
+		assert.NotNil(t, acknowledged.AcknowledgedAt)
+	}
+
+	// acknowledging again keeps the first acknowledgement
+	again, err := s.AcknowledgeAlert(ctx, alerts[0].ID, testData.Users[UserBob].ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, again) && assert.NotNil(t, again.AcknowledgedBy) {
+		assert.Equal(t, admin, *again.AcknowledgedBy)
+	}
+
+	open, err := s.ListAlerts(ctx, data.AlertFilter{Open: true, Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, open, 1) {
+		assert.Equal(t, data.AlertLikesSurge, open[0].Kind)
+	}
+
+	_, err = s.AcknowledgeAlert(ctx, 999999, admin)
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+}
@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/audit"
+	"context"
+	"encoding/json"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordAudit(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec("TRUNCATE audit_logs")
+	assert.NoError(t, err)
+
+	s := audit.NewAuditService(db)
+	admin := testData.Users[UserAlice].ID
+	target := testData.Users[UserBob].ID
+
+	err = s.Record(data.AuditEntry{
+		ActorID:    &admin,
+		Action:     data.AuditImpersonationStart,
+		TargetType: "user",
+		TargetID:   target.String(),
+		Details:    map[string]interface{}{"reason": "Ticket 42"},
+		IP:         "203.0.113.7",
+	})
+	assert.NoError(t, err)
+
+	// optional fields are stored as NULL
+	assert.NoError(t, s.Record(data.AuditEntry{Action: data.AuditImpersonationStop}))
+
+	var action, targetID, ip string
+	var details []byte
+	err = db.QueryRow("SELECT action, target_id, ip, details FROM audit_logs WHERE actor_id = $1", admin).Scan(&action, &targetID, &ip, &details)
+	assert.NoError(t, err)
+	assert.Equal(t, data.AuditImpersonationStart, action)
+	assert.Equal(t, target.String(), targetID)
+	assert.Equal(t, "203.0.113.7", ip)
+
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal(details, &decoded))
+	assert.Equal(t, "Ticket 42", decoded["reason"])
+
+	var nulls int
+	err = db.QueryRow("SELECT COUNT(*) FROM audit_logs WHERE actor_id IS NULL AND target_id IS NULL AND ip IS NULL").Scan(&nulls)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, nulls)
+}
+
+func TestListAudit(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec("TRUNCATE audit_logs")
+	assert.NoError(t, err)
+
+	s := audit.NewAuditService(db)
+	admin := testData.Users[UserAlice].ID
+	project := testData.Projects[ProjectAlicePublic].ID.String()
+
+	assert.NoError(t, s.Record(data.AuditEntry{ActorID: &admin, Action: data.AuditProjectFeature, TargetType: "project", TargetID: project}))
+	assert.NoError(t, s.Record(data.AuditEntry{ActorID: &admin, Action: data.AuditProjectUnfeature, TargetType: "project", TargetID: project}))
+	assert.NoError(t, s.Record(data.AuditEntry{ActorID: &admin, Action: data.AuditImpersonationStart, TargetType: "user"}))
+
+	entries, err := s.List(data.AuditFilter{Actions: data.FeaturingAuditActions, TargetID: project, Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, data.AuditProjectUnfeature, entries[0].Action)
+		assert.Equal(t, admin, *entries[0].ActorID)
+	}
+
+	// the next page continues after the cursor
+	entries, err = s.List(data.AuditFilter{Limit: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		next, err := s.List(data.AuditFilter{Cursor: &data.AuditCursor{Time: entries[0].CreatedAt, ID: entries[0].ID}, Limit: 10})
+		assert.NoError(t, err)
+		assert.Len(t, next, 2)
+	}
+
+	bob := testData.Users[UserBob].ID
+	entries, err = s.List(data.AuditFilter{ActorID: &bob, Limit: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	future := time.Now().Add(time.Hour)
+	entries, err = s.List(data.AuditFilter{After: &future, Limit: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestAuditLogAppendOnly(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	s := audit.NewAuditService(db)
+	admin := testData.Users[UserAlice].ID
+	assert.NoError(t, s.Record(data.AuditEntry{ActorID: &admin, Action: data.AuditUserDelete, TargetType: "user", TargetID: uuid.New().String()}))
+
+	_, err = db.Exec("UPDATE audit_logs SET action = 'tampered' WHERE actor_id = $1", admin)
+	assert.Error(t, err)
+	_, err = db.Exec("DELETE FROM audit_logs WHERE actor_id = $1", admin)
+	assert.Error(t, err)
+}
+
+func TestTargetHistory(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec("TRUNCATE audit_logs")
+	assert.NoError(t, err)
+
+	s := audit.NewAuditService(db)
+	bans := services.NewBanService(db)
+	ctx := context.Background()
+	chris := testData.Users[UserChris].ID
+	bob := testData.Users[UserBob].ID
+	project := testData.Projects[ProjectAlicePublic].ID
+
+	assert.NoError(t, s.Record(data.AuditEntry{ActorID: &chris, Action: data.AuditVerificationReview, TargetType: "user", TargetID: bob.String()}))
+	_, err = bans.BanUser(context.Background(), bob, chris, nil, "spam links")
+	assert.NoError(t, err)
+	assert.NoError(t, s.Record(data.AuditEntry{ActorID: &chris, Action: data.AuditProjectFeature, TargetType: "project", TargetID: project.String()}))
+
+	history, err := s.UserHistory(ctx, bob, data.HistoryFilter{Limit: 50})
+	assert.NoError(t, err)
+	if assert.Len(t, history, 3) {
+		assert.Equal(t, "user.created", history[0].Action)
+		assert.Equal(t, data.AuditVerificationReview, history[1].Action)
+		assert.Equal(t, "user.ban", history[2].Action)
+		assert.Equal(t, data.HistorySanction, history[2].Kind)
+		assert.Equal(t, "chris", history[2].ActorUsername)
+	}
+
+	history, err = s.UserHistory(ctx, bob, data.HistoryFilter{Search: "SPAM", Limit: 50})
+	assert.NoError(t, err)
+	if assert.Len(t, history, 1) {
+		assert.Equal(t, "spam links", history[0].Summary)
+	}
+
+	history, err = s.UserHistory(ctx, bob, data.HistoryFilter{Kind: data.HistoryAudit, Limit: 50})
+	assert.NoError(t, err)
+	assert.Len(t, history, 1)
+
+	history, err = s.ProjectHistory(ctx, project, data.HistoryFilter{Limit: 50})
+	assert.NoError(t, err)
+	if assert.Len(t, history, 2) {
+		assert.Equal(t, "project.created", history[0].Action)
+		assert.Equal(t, data.AuditProjectFeature, history[1].Action)
+	}
+
+	_, err = s.UserHistory(ctx, uuid.New(), data.HistoryFilter{Limit: 50})
+	assert.ErrorIs(t, err, services.ErrUserNotFound)
+	_, err = s.ProjectHistory(ctx, uuid.New(), data.HistoryFilter{Limit: 50})
+	assert.ErrorIs(t, err, services.ErrProjectNotFound)
+}
@@ -1,9 +1,11 @@
 package tests
 
 import (
+	"context"
 	"errors"
 	"log"
 	"testing"
+	"time"
 
 	"NodeTurtleAPI/internal/config"
 	"NodeTurtleAPI/internal/data"
@@ -27,7 +29,12 @@ func setupAuthService() (auth.IAuthService, TestData, func()) {
 		ExpireTime: 24,
 	}
 
-	return auth.NewService(db, jwtConfig), *testData, func() { db.Close() }
+	s, err := auth.NewService(db, jwtConfig)
+	if err != nil {
+		log.Fatalf("Failed to create auth service: %v", err)
+	}
+
+	return s, *testData, func() { db.Close() }
 }
 
 func TestLogin(t *testing.T) {
@@ -68,7 +75,7 @@ func TestLogin(t *testing.T) {
 
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			token, user, err := s.Login(tt.email, tt.password)
+			token, user, err := s.Login(context.Background(), tt.email, tt.password)
 
 			if tt.err != nil {
 				assert.Error(t, err)
@@ -179,6 +186,138 @@ func TestVerifyToken(t *testing.T) {
 	}
 }
 
+func TestSigningKeyRotation(t *testing.T) {
+	jwtConfig := config.JWTConfig{
+		Secret:     "test-secret",
+		ExpireTime: 24,
+		KeysDir:    t.TempDir(),
+	}
+
+	user := data.User{ID: uuid.New(), Role: data.Role{ID: data.RoleUser.ToID(), Name: "user"}}
+
+	s, err := auth.NewService(nil, jwtConfig)
+	assert.NoError(t, err)
+
+	// a key is generated when the directory is empty
+	jwks := s.JWKS()
+	assert.Len(t, jwks.Keys, 1)
+	firstKid := jwks.Keys[0].Kid
+
+	oldToken, err := s.CreateAccessToken(user)
+	assert.NoError(t, err)
+
+	newKid, err := s.RotateSigningKey()
+	assert.NoError(t, err)
+	assert.NotEqual(t, firstKid, newKid)
+
+	jwks = s.JWKS()
+	assert.Len(t, jwks.Keys, 2)
+	assert.Equal(t, newKid, jwks.Keys[0].Kid, "primary key should be listed first")
+
+	newToken, err := s.CreateAccessToken(user)
+	assert.NoError(t, err)
+
+	for _, token := range []string{oldToken, newToken} {
+		claims, err := s.VerifyToken(token)
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID.String(), claims.Subject)
+	}
+
+	// the rotated key is still accepted after a restart
+	reloaded, err := auth.NewService(nil, jwtConfig)
+	assert.NoError(t, err)
+	assert.Len(t, reloaded.JWKS().Keys, 2)
+	_, err = reloaded.VerifyToken(oldToken)
+	assert.NoError(t, err)
+
+	// tokens signed with the secret before keys were configured are rejected, unless within the grace period
+	legacy := auth.AuthService{JwtKey: []byte("test-secret"), JwtExp: 24}
+	legacyToken, err := legacy.CreateAccessToken(user)
+	assert.NoError(t, err)
+	_, err = s.VerifyToken(legacyToken)
+	assert.Error(t, err)
+
+	graceConfig := jwtConfig
+	graceConfig.SecretGraceUntil = time.Now().Add(time.Hour)
+	migrating, err := auth.NewService(nil, graceConfig)
+	assert.NoError(t, err)
+	_, err = migrating.VerifyToken(legacyToken)
+	assert.NoError(t, err)
+
+	graceConfig.SecretGraceUntil = time.Now().Add(-time.Minute)
+	migrated, err := auth.NewService(nil, graceConfig)
+	assert.NoError(t, err)
+	_, err = migrated.VerifyToken(legacyToken)
+	assert.Error(t, err)
+
+	// keys that are not part of the ring are rejected
+	other, err := auth.NewService(nil, config.JWTConfig{ExpireTime: 24, KeysDir: t.TempDir()})
+	assert.NoError(t, err)
+	foreignToken, err := other.CreateAccessToken(user)
+	assert.NoError(t, err)
+	_, err = s.VerifyToken(foreignToken)
+	assert.Error(t, err)
+
+	// a pinned primary key has to exist
+	_, err = auth.NewService(nil, config.JWTConfig{ExpireTime: 24, KeysDir: jwtConfig.KeysDir, PrimaryKeyID: "missing"})
+	assert.Error(t, err)
+
+	_, err = legacy.RotateSigningKey()
+	assert.ErrorIs(t, err, services.ErrKeyRotationUnavailable)
+	assert.Empty(t, legacy.JWKS().Keys)
+}
+
+func TestCreateImpersonationToken(t *testing.T) {
+	s, err := auth.NewService(nil, config.JWTConfig{Secret: "test-secret", ExpireTime: 24, ImpersonationTTL: 15})
+	assert.NoError(t, err)
+
+	user := data.User{ID: uuid.New(), Role: data.Role{ID: data.RoleUser.ToID(), Name: "user"}}
+	impersonatorID := uuid.New()
+
+	token, expiresAt, err := s.CreateImpersonationToken(user, impersonatorID)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), expiresAt, time.Minute)
+
+	claims, err := s.VerifyToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID.String(), claims.Subject)
+	assert.Equal(t, impersonatorID.String(), claims.Impersonator)
+	assert.Equal(t, expiresAt.Unix(), claims.ExpiresAt)
+
+	// regular tokens carry no impersonator
+	token, err = s.CreateAccessToken(user)
+	assert.NoError(t, err)
+	claims, err = s.VerifyToken(token)
+	assert.NoError(t, err)
+	assert.Empty(t, claims.Impersonator)
+}
+
+func TestCreateEmbedToken(t *testing.T) {
+	s, err := auth.NewService(nil, config.JWTConfig{Secret: "test-secret", ExpireTime: 24, EmbedTTL: 60})
+	assert.NoError(t, err)
+
+	projectID := uuid.New()
+
+	token, expiresAt, err := s.CreateEmbedToken(projectID)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Minute)
+
+	assert.NoError(t, s.VerifyEmbedToken(token, projectID))
+	assert.ErrorIs(t, s.VerifyEmbedToken(token, uuid.New()), services.ErrInvalidToken)
+	assert.ErrorIs(t, s.VerifyEmbedToken("garbage", projectID), services.ErrInvalidToken)
+
+	claims, err := s.VerifyToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, auth.ScopeProjectEmbed, claims.Scope)
+	assert.Equal(t, projectID.String(), claims.Subject)
+
+	// access tokens can't be used as embed tokens
+	user := data.User{ID: projectID, Role: data.Role{ID: data.RoleUser.ToID(), Name: "user"}}
+	token, err = s.CreateAccessToken(user)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, s.VerifyEmbedToken(token, projectID), services.ErrInvalidToken)
+}
+
 func TestHashPassword(t *testing.T) {
 	tests := map[string]struct {
 		password string
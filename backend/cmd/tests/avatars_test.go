@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/avatars"
+	"NodeTurtleAPI/internal/services/profiles"
+	"NodeTurtleAPI/internal/storage"
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestAvatars(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	store := storage.NewLocal(t.TempDir())
+	s := avatars.NewAvatarService(db, store, config.AvatarsConfig{MaxBytes: 1 << 20, MaxPixels: 1000 * 1000})
+	bob := testData.Users[UserBob]
+
+	_, err = s.SetAvatar(ctx, bob.ID, []byte("GIF89a but not really"))
+	assert.ErrorIs(t, err, services.ErrInvalidImage)
+	_, err = s.SetAvatar(ctx, bob.ID, []byte("<svg xmlns='http://www.w3.org/2000/svg'></svg>"))
+	assert.ErrorIs(t, err, services.ErrInvalidImage)
+	_, err = s.SetAvatar(ctx, bob.ID, encodePNG(t, 2000, 600))
+	assert.ErrorIs(t, err, services.ErrImageTooLarge, "the pixel limit is checked before decoding")
+
+	avatar, err := s.SetAvatar(ctx, bob.ID, encodePNG(t, 300, 100))
+	assert.NoError(t, err)
+	if !assert.NotNil(t, avatar) || !assert.Len(t, avatar.URLs, len(data.AvatarSizes)) {
+		return
+	}
+
+	// every size is cropped square and scaled
+	firstURL := avatar.URLs[strconv.Itoa(data.AvatarSizes[0])]
+	for _, size := range data.AvatarSizes {
+		parts := strings.Split(avatar.URLs[strconv.Itoa(size)], "/")
+		userID, name := parts[len(parts)-2], parts[len(parts)-1]
+		assert.Equal(t, bob.ID.String(), userID)
+
+		content, contentType, err := s.GetImage(ctx, uuid.MustParse(userID), name)
+		assert.NoError(t, err)
+		assert.Equal(t, "image/png", contentType)
+		img, err := png.Decode(bytes.NewReader(content))
+		if assert.NoError(t, err) {
+			assert.Equal(t, image.Rect(0, 0, size, size), img.Bounds())
+		}
+	}
+
+	profile, err := profiles.NewProfileService(db).GetProfile(ctx, bob.Username)
+	assert.NoError(t, err)
+	if assert.NotNil(t, profile) && assert.NotNil(t, profile.AvatarURL) {
+		assert.Equal(t, firstURL, *profile.AvatarURL)
+	}
+
+	// a new upload replaces the images of the previous one
+	replaced, err := s.SetAvatar(ctx, bob.ID, encodePNG(t, 50, 50))
+	assert.NoError(t, err)
+	assert.NotEqual(t, avatar.URLs, replaced.URLs)
+	oldName := firstURL[strings.LastIndex(firstURL, "/")+1:]
+	_, _, err = s.GetImage(ctx, bob.ID, oldName)
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+
+	assert.NoError(t, s.DeleteAvatar(ctx, bob.ID))
+	assert.NoError(t, s.DeleteAvatar(ctx, bob.ID), "deleting a missing avatar isn't an error")
+	profile, err = profiles.NewProfileService(db).GetProfile(ctx, bob.Username)
+	assert.NoError(t, err)
+	if assert.NotNil(t, profile) {
+		assert.Nil(t, profile.AvatarURL)
+	}
+
+	_, _, err = s.GetImage(ctx, bob.ID, "../../etc/passwd")
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+
+	_, err = s.SetAvatar(ctx, uuid.New(), encodePNG(t, 50, 50))
+	assert.ErrorIs(t, err, services.ErrUserNotFound)
+}
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/database"
+	"NodeTurtleAPI/internal/services/jobs"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunBackfill(t *testing.T) {
+	_, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`
+		DELETE FROM background_jobs WHERE name = 'projects_title_slug';
+		ALTER TABLE projects ADD COLUMN IF NOT EXISTS title_slug TEXT;`)
+	assert.NoError(t, err)
+	defer db.Exec(`
+		DELETE FROM background_jobs WHERE name = 'projects_title_slug';
+		ALTER TABLE projects DROP COLUMN IF EXISTS title_slug;`)
+
+	var projects int64
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM projects").Scan(&projects))
+
+	calls := 0
+	backfill := database.RowBackfill{
+		Table:   "projects",
+		Columns: []string{"title"},
+		Set:     []string{"title_slug"},
+		Where:   "title_slug IS NULL",
+		Compute: func(values []interface{}) ([]interface{}, error) {
+			calls++
+			// fail halfway through, the next run resumes after the last batch
+			if calls == 3 {
+				return nil, errors.New("interrupted")
+			}
+			return []interface{}{strings.ToLower(strings.ReplaceAll(values[0].(string), " ", "-"))}, nil
+		},
+		BatchSize: 2,
+	}
+
+	err = database.RunBackfill(context.Background(), db, database.BackfillRows("projects_title_slug", backfill), false)
+	assert.ErrorContains(t, err, "interrupted")
+
+	s := jobs.NewJobService(db)
+	job, err := s.GetJob("projects_title_slug")
+	assert.NoError(t, err)
+	assert.Equal(t, data.JobFailed, job.Status)
+	assert.Equal(t, int64(2), job.Processed, "the failed batch is rolled back")
+	assert.Equal(t, projects, *job.Total)
+
+	assert.NoError(t, database.RunBackfill(context.Background(), db, database.BackfillRows("projects_title_slug", backfill), false))
+	job, err = s.GetJob("projects_title_slug")
+	assert.NoError(t, err)
+	assert.Equal(t, data.JobCompleted, job.Status)
+	assert.Equal(t, projects, job.Processed)
+	assert.Equal(t, int(projects)+1, calls, "rows of completed batches are not computed again")
+
+	var title, slug string
+	assert.NoError(t, db.QueryRow("SELECT title, title_slug FROM projects ORDER BY id LIMIT 1").Scan(&title, &slug))
+	assert.Equal(t, strings.ToLower(strings.ReplaceAll(title, " ", "-")), slug)
+
+	// completed backfills only run again when restarted
+	_, err = db.Exec("UPDATE projects SET title_slug = NULL")
+	assert.NoError(t, err)
+	assert.NoError(t, database.RunBackfill(context.Background(), db, database.BackfillRows("projects_title_slug", backfill), false))
+	var missing int64
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM projects WHERE title_slug IS NULL").Scan(&missing))
+	assert.Equal(t, projects, missing)
+
+	assert.NoError(t, database.RunBackfill(context.Background(), db, database.BackfillRows("projects_title_slug", backfill), true))
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM projects WHERE title_slug IS NULL").Scan(&missing))
+	assert.Equal(t, int64(0), missing)
+	job, err = s.GetJob("projects_title_slug")
+	assert.NoError(t, err)
+	assert.Equal(t, projects, job.Processed, "a restart counts from the first row")
+}
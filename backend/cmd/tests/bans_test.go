@@ -1,7 +1,10 @@
 package tests
 
 import (
+	"NodeTurtleAPI/internal/data"
 	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/utils"
+	"context"
 	"log"
 	"testing"
 	"time"
@@ -27,28 +30,35 @@ func TestBanUser(t *testing.T) {
 	tests := map[string]struct {
 		userId     uuid.UUID
 		bannedBy   uuid.UUID
-		expires_at time.Time
+		expires_at *time.Time
 		reason     string
 		err        error
 	}{
 		"Successful ban": {
 			userId:     td.Users[UserAlice].ID,
 			bannedBy:   td.Users[UserChris].ID,
-			expires_at: time.Now().Add(time.Hour),
+			expires_at: utils.Ptr(time.Now().Add(time.Hour)),
 			reason:     "test ban",
 			err:        nil,
 		},
+		"Permanent ban": {
+			userId:     td.Users[UserBob].ID,
+			bannedBy:   td.Users[UserChris].ID,
+			expires_at: nil,
+			reason:     "test permanent ban",
+			err:        nil,
+		},
 		"Self ban (self account deactivation)": {
 			userId:     td.Users[UserAlice].ID,
 			bannedBy:   td.Users[UserAlice].ID,
-			expires_at: time.Now().Add(time.Hour),
+			expires_at: utils.Ptr(time.Now().Add(time.Hour)),
 			reason:     "test self ban",
 			err:        nil,
 		},
 		"Ban receiver ID not found": {
 			userId:     uuid.New(),
 			bannedBy:   td.Users[UserChris].ID,
-			expires_at: time.Now().Add(time.Hour),
+			expires_at: utils.Ptr(time.Now().Add(time.Hour)),
 			reason:     "test ban",
 			err:        services.ErrUserNotFound,
 		},
@@ -57,7 +67,7 @@ func TestBanUser(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 
-			ban, err := s.BanUser(tt.userId, tt.bannedBy, tt.expires_at, tt.reason)
+			ban, err := s.BanUser(context.Background(), tt.userId, tt.bannedBy, tt.expires_at, tt.reason)
 
 			if tt.err != nil {
 				assert.Error(t, err)
@@ -69,6 +79,8 @@ func TestBanUser(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, nil, err)
+				assert.Equal(t, tt.expires_at == nil, ban.Permanent())
+				assert.True(t, ban.IsValid())
 			}
 		})
 	}
@@ -99,7 +111,7 @@ func TestUnbanUser(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 
-			err := s.UnbanUser(tt.userId)
+			err := s.UnbanUser(context.Background(), tt.userId, td.Users[UserChris].ID)
 
 			if tt.err != nil {
 				assert.Error(t, err)
@@ -111,3 +123,129 @@ func TestUnbanUser(t *testing.T) {
 		})
 	}
 }
+
+func TestBanHistoryAndAppeals(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	s := services.NewBanService(db)
+	alice := testData.Users[UserAlice]
+	chris := testData.Users[UserChris]
+
+	_, err = s.ListBanHistory(ctx, uuid.New())
+	assert.ErrorIs(t, err, services.ErrUserNotFound)
+
+	_, err = s.BanUser(ctx, alice.ID, chris.ID, utils.Ptr(time.Now().Add(time.Hour)), "first")
+	assert.NoError(t, err)
+	assert.NoError(t, s.UnbanUser(ctx, alice.ID, chris.ID))
+	_, err = s.BanUser(ctx, alice.ID, chris.ID, utils.Ptr(time.Now().Add(time.Hour)), "second")
+	assert.NoError(t, err)
+
+	history, err := s.ListBanHistory(ctx, alice.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, history, 3) {
+		assert.Equal(t, data.BanActionBan, history[0].Action)
+		assert.Equal(t, "first", history[0].Reason)
+		assert.Equal(t, data.BanActionUnban, history[1].Action)
+		assert.Equal(t, "second", history[2].Reason)
+		assert.Equal(t, chris.Username, history[2].ActorUsername)
+	}
+
+	_, err = db.Exec("UPDATE ban_history SET reason = 'changed' WHERE user_id = $1", alice.ID)
+	assert.Error(t, err, "the history can't be changed")
+
+	_, err = s.AppealBan(ctx, testData.Users[UserBob].ID, "Please")
+	assert.ErrorIs(t, err, services.ErrNotBanned)
+	_, err = s.AppealBan(ctx, testData.Users[UserFrank].ID, "Please")
+	assert.ErrorIs(t, err, services.ErrNotBanned, "expired bans can't be appealed")
+
+	appeal, err := s.AppealBan(ctx, alice.ID, "I was hacked")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, history[2].ID, appeal.BanID, "the current ban is appealed")
+	assert.Equal(t, "second", appeal.BanReason)
+	assert.Equal(t, data.AppealOpen, appeal.Status)
+
+	_, err = s.AppealBan(ctx, alice.ID, "Again")
+	assert.ErrorIs(t, err, services.ErrAlreadyAppealed)
+
+	open, err := s.ListOpenAppeals(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, open, 1)
+
+	resolved, err := s.ResolveAppeal(ctx, appeal.ID, chris.ID, data.BanAppealDecision{Status: data.AppealAccepted, Resolution: "Welcome back"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, data.AppealAccepted, resolved.Status)
+	assert.Equal(t, "Welcome back", resolved.Resolution)
+
+	var banned bool
+	assert.NoError(t, db.QueryRow("SELECT EXISTS(SELECT 1 FROM banned_users WHERE user_id = $1)", alice.ID).Scan(&banned))
+	assert.False(t, banned, "accepting the appeal lifts the ban")
+
+	history, err = s.ListBanHistory(ctx, alice.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, history, 4) {
+		assert.Equal(t, data.BanActionUnban, history[3].Action)
+	}
+
+	_, err = s.ResolveAppeal(ctx, appeal.ID, chris.ID, data.BanAppealDecision{Status: data.AppealRejected})
+	assert.ErrorIs(t, err, services.ErrAppealResolved)
+	_, err = s.ResolveAppeal(ctx, uuid.New(), chris.ID, data.BanAppealDecision{Status: data.AppealRejected})
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+}
+
+func TestIPBans(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	s := services.NewBanService(db)
+	ctx := context.Background()
+	chris := testData.Users[UserChris]
+
+	ban, err := s.AddIPBan(ctx, "203.0.113.7/24", "spam", nil, chris.ID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "203.0.113.0/24", ban.Subnet, "host bits are cleared")
+	assert.Nil(t, ban.ExpiresAt)
+
+	_, err = s.AddIPBan(ctx, "203.0.113.0/24", "again", nil, chris.ID)
+	assert.ErrorIs(t, err, services.ErrIPAlreadyBanned)
+
+	single, err := s.AddIPBan(ctx, "198.51.100.7", "abuse", utils.Ptr(time.Now().Add(-time.Hour)), chris.ID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "198.51.100.7/32", single.Subnet)
+
+	active, err := s.ActiveIPBans(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, active, 1, "expired bans aren't in force") {
+		assert.Equal(t, ban.ID, active[0].ID)
+	}
+
+	all, err := s.ListIPBans(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	updated, err := s.UpdateIPBan(ctx, single.ID, "abuse, extended", nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "abuse, extended", updated.Reason)
+		assert.Nil(t, updated.ExpiresAt)
+	}
+
+	assert.NoError(t, s.RemoveIPBan(ctx, ban.ID))
+	assert.ErrorIs(t, s.RemoveIPBan(ctx, ban.ID), services.ErrRecordNotFound)
+	_, err = s.GetIPBan(ctx, ban.ID)
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+}
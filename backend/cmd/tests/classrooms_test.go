@@ -0,0 +1,165 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/ids"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/classrooms"
+	"NodeTurtleAPI/internal/services/projects"
+	"NodeTurtleAPI/internal/utils"
+	"context"
+	"log"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupClassroomService() (classrooms.IClassroomService, projects.IProjectService, TestData, func()) {
+	testData, db, err := createTestData()
+
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+
+	return classrooms.NewClassroomService(db), projects.NewProjectService(db, ids.V7, config.QuotasConfig{}), *testData, func() { db.Close() }
+}
+
+func TestClassroomRoster(t *testing.T) {
+	s, _, td, close := setupClassroomService()
+	defer close()
+	ctx := context.Background()
+
+	classroom, err := s.CreateClassroom(ctx, data.ClassroomCreate{Name: "Class 5B", OwnerID: td.Users[UserAlice].ID})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, classroom.MembersCount)
+
+	err = s.InviteMembers(ctx, classroom.ID, []string{td.Users[UserBob].Email, "missing@example.com"})
+	assert.NoError(t, err)
+
+	// invited users aren't on the roster before they accept
+	isMember, err := s.IsMember(ctx, classroom.ID, td.Users[UserBob].ID)
+	assert.NoError(t, err)
+	assert.False(t, isMember)
+
+	invites, err := s.GetInvites(ctx, td.Users[UserBob].ID)
+	assert.NoError(t, err)
+	assert.Len(t, invites, 1)
+	assert.Equal(t, classroom.ID, invites[0].ClassroomID)
+
+	err = s.AcceptInvite(ctx, classroom.ID, td.Users[UserBob].ID)
+	assert.NoError(t, err)
+
+	err = s.AcceptInvite(ctx, classroom.ID, td.Users[UserBob].ID)
+	assert.Equal(t, services.ErrRecordNotFound, err)
+
+	// inviting an existing member is a no-op
+	err = s.InviteMembers(ctx, classroom.ID, []string{td.Users[UserBob].Email})
+	assert.NoError(t, err)
+
+	invites, err = s.GetInvites(ctx, td.Users[UserBob].ID)
+	assert.NoError(t, err)
+	assert.Len(t, invites, 0)
+
+	members, err := s.GetMembers(ctx, classroom.ID)
+	assert.NoError(t, err)
+	assert.Len(t, members, 2)
+	assert.Equal(t, data.ClassroomTeacher, members[0].Role)
+
+	userClassrooms, err := s.GetUserClassrooms(ctx, td.Users[UserBob].ID)
+	assert.NoError(t, err)
+	assert.Len(t, userClassrooms, 1)
+
+	err = s.RemoveMember(ctx, classroom.ID, td.Users[UserAlice].ID)
+	assert.Equal(t, services.ErrRecordNotFound, err)
+
+	err = s.RemoveMember(ctx, classroom.ID, td.Users[UserBob].ID)
+	assert.NoError(t, err)
+
+	isMember, err = s.IsMember(ctx, classroom.ID, td.Users[UserBob].ID)
+	assert.NoError(t, err)
+	assert.False(t, isMember)
+
+	err = s.DeleteClassroom(ctx, classroom.ID)
+	assert.NoError(t, err)
+
+	_, err = s.GetClassroom(ctx, classroom.ID)
+	assert.Equal(t, services.ErrRecordNotFound, err)
+}
+
+func TestClassroomProjectVisibility(t *testing.T) {
+	s, ps, td, close := setupClassroomService()
+	defer close()
+	ctx := context.Background()
+
+	classroom, err := s.CreateClassroom(ctx, data.ClassroomCreate{Name: "Class 5B", OwnerID: td.Users[UserAlice].ID})
+	assert.NoError(t, err)
+
+	err = s.InviteMembers(ctx, classroom.ID, []string{td.Users[UserBob].Email})
+	assert.NoError(t, err)
+	err = s.AcceptInvite(ctx, classroom.ID, td.Users[UserBob].ID)
+	assert.NoError(t, err)
+
+	project, err := ps.UpdateProject(ctx, data.ProjectUpdate{
+		ID:          td.Projects[ProjectAlicePrivate].ID,
+		ClassroomID: &classroom.ID,
+	})
+	assert.NoError(t, err)
+	assert.False(t, project.IsPublic)
+	assert.Equal(t, classroom.ID, *project.ClassroomID)
+
+	tests := map[string]struct {
+		userID *uuid.UUID
+		err    error
+	}{
+		"Visible to the owner": {
+			userID: utils.Ptr(td.Users[UserAlice].ID),
+			err:    nil,
+		},
+		"Visible to a roster member": {
+			userID: utils.Ptr(td.Users[UserBob].ID),
+			err:    nil,
+		},
+		"Hidden from other users": {
+			userID: utils.Ptr(td.Users[UserChris].ID),
+			err:    services.ErrRecordNotFound,
+		},
+		"Hidden from guests": {
+			userID: nil,
+			err:    services.ErrRecordNotFound,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ps.GetProject(ctx, td.Projects[ProjectAlicePrivate].ID, tt.userID)
+
+			if tt.err != nil {
+				assert.Equal(t, tt.err, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	bobView, err := ps.GetUserProjects(ctx, td.Users[UserAlice].ID, utils.Ptr(td.Users[UserBob].ID), nil)
+	assert.NoError(t, err)
+	assert.Len(t, bobView, 2) // public project and the classroom project
+
+	chrisView, err := ps.GetUserProjects(ctx, td.Users[UserAlice].ID, utils.Ptr(td.Users[UserChris].ID), nil)
+	assert.NoError(t, err)
+	assert.Len(t, chrisView, 1)
+
+	classProjects, err := s.GetClassroomProjects(ctx, classroom.ID)
+	assert.NoError(t, err)
+	assert.Len(t, classProjects, 1)
+
+	// making the project public stops sharing it with the classroom
+	project, err = ps.UpdateProject(ctx, data.ProjectUpdate{
+		ID:       td.Projects[ProjectAlicePrivate].ID,
+		IsPublic: utils.Ptr(true),
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, project.ClassroomID)
+}
@@ -0,0 +1,69 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services/consents"
+	"NodeTurtleAPI/internal/services/dumps"
+	"context"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsents(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	s := consents.NewConsentService(db)
+	bob := testData.Users[UserBob].ID
+
+	// defaults apply until a choice is made
+	current, err := s.GetConsents(ctx, bob)
+	assert.NoError(t, err)
+	assert.Len(t, current, len(data.ConsentPurposes))
+	for _, c := range current {
+		assert.Equal(t, data.DefaultConsent(c.Purpose), c.Granted)
+		assert.Nil(t, c.UpdatedAt)
+	}
+
+	granted, err := s.HasConsent(ctx, bob, data.ConsentMarketingEmails)
+	assert.NoError(t, err)
+	assert.False(t, granted)
+
+	_, err = s.SetConsents(ctx, bob, map[data.ConsentPurpose]bool{
+		data.ConsentMarketingEmails: true,
+		data.ConsentResearchData:    false,
+	})
+	assert.NoError(t, err)
+
+	granted, err = s.HasConsent(ctx, bob, data.ConsentMarketingEmails)
+	assert.NoError(t, err)
+	assert.True(t, granted)
+
+	// withdrawing research consent opts out of the data dumps
+	d := dumps.NewDumpService(db, config.DumpsConfig{})
+	optOut, err := d.IsOptedOut(bob)
+	assert.NoError(t, err)
+	assert.True(t, optOut)
+
+	// and opting back in through the dumps records the consent
+	assert.NoError(t, d.SetOptOut(bob, false))
+	granted, err = s.HasConsent(ctx, bob, data.ConsentResearchData)
+	assert.NoError(t, err)
+	assert.True(t, granted)
+
+	current, err = s.GetConsents(ctx, bob)
+	assert.NoError(t, err)
+	for _, c := range current {
+		if c.Purpose == data.ConsentResearchData {
+			assert.NotNil(t, c.GrantedAt)
+			assert.NotNil(t, c.WithdrawnAt)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/ids"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/credits"
+	"NodeTurtleAPI/internal/services/projects"
+	"NodeTurtleAPI/internal/utils"
+	"context"
+	"log"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectCredits(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	s := credits.NewCreditService(db)
+	ps := projects.NewProjectService(db, ids.V7, config.QuotasConfig{})
+	bob := testData.Users[UserBob].ID
+	chris := testData.Users[UserChris].ID
+	publicProject := testData.Projects[ProjectAlicePublic].ID
+	privateProject := testData.Projects[ProjectAlicePrivate].ID
+
+	credit, err := s.AddCredit(ctx, publicProject, bob)
+	assert.NoError(t, err)
+	assert.Equal(t, data.CreditPending, credit.Status)
+	assert.Equal(t, testData.Users[UserBob].Username, credit.Username)
+
+	_, err = s.AddCredit(ctx, publicProject, bob)
+	assert.ErrorIs(t, err, services.ErrAlreadyCredited)
+	_, err = s.AddCredit(ctx, uuid.New(), bob)
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+
+	_, err = s.AddCredit(ctx, privateProject, bob)
+	assert.NoError(t, err)
+	_, err = s.AddCredit(ctx, publicProject, chris)
+	assert.NoError(t, err)
+
+	pending, err := s.ListPendingCredits(ctx, bob)
+	assert.NoError(t, err)
+	assert.Len(t, pending, 2)
+
+	// pending credits aren't listed publicly
+	accepted, err := s.ListCredits(ctx, publicProject, false)
+	assert.NoError(t, err)
+	assert.Empty(t, accepted)
+
+	_, err = s.RespondToCredit(ctx, publicProject, bob, true)
+	assert.NoError(t, err)
+	_, err = s.RespondToCredit(ctx, privateProject, bob, true)
+	assert.NoError(t, err)
+	credit, err = s.RespondToCredit(ctx, publicProject, chris, false)
+	assert.NoError(t, err)
+	assert.Equal(t, data.CreditDeclined, credit.Status)
+	assert.NotNil(t, credit.RespondedAt)
+
+	// answered once
+	_, err = s.RespondToCredit(ctx, publicProject, chris, true)
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+
+	accepted, err = s.ListCredits(ctx, publicProject, false)
+	assert.NoError(t, err)
+	assert.Len(t, accepted, 1)
+	all, err := s.ListCredits(ctx, publicProject, true)
+	assert.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	// the credit doesn't make the private project visible to guests
+	contributed, err := ps.GetContributedProjects(ctx, bob, nil)
+	assert.NoError(t, err)
+	assert.Len(t, contributed, 1)
+	assert.Equal(t, publicProject, contributed[0].ID)
+
+	contributed, err = ps.GetContributedProjects(ctx, bob, utils.Ptr(testData.Users[UserAlice].ID))
+	assert.NoError(t, err)
+	assert.Len(t, contributed, 2)
+
+	assert.NoError(t, s.RemoveCredit(ctx, publicProject, bob))
+	assert.ErrorIs(t, s.RemoveCredit(ctx, publicProject, bob), services.ErrRecordNotFound)
+}
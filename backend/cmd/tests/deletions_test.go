@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/clock"
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/auth"
+	"NodeTurtleAPI/internal/services/deletions"
+	"NodeTurtleAPI/internal/services/tokens"
+	"NodeTurtleAPI/internal/storage"
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountDeletions(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	bob := testData.Users[UserBob]
+	project := testData.Projects[ProjectAlicePublic]
+
+	s := deletions.NewDeletionService(db, storage.NewLocal(t.TempDir()), config.DeletionsConfig{GracePeriod: 720 * time.Hour, Interval: 60})
+	tokenService := tokens.NewTokenService(db, config.TokensConfig{}, clock.System)
+	authService, err := auth.NewService(db, config.JWTConfig{Secret: "test-secret", ExpireTime: 24})
+	if err != nil {
+		log.Fatalf("Failed to create auth service: %v", err)
+	}
+
+	_, err = s.Schedule(ctx, uuid.New())
+	assert.ErrorIs(t, err, services.ErrUserNotFound)
+
+	_, err = tokenService.New(ctx, bob.ID, data.ScopeRefresh)
+	assert.NoError(t, err)
+
+	deletion, err := s.Schedule(ctx, bob.ID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, bob.Username, deletion.Username)
+	assert.WithinDuration(t, time.Now().Add(720*time.Hour), deletion.ScheduledFor, time.Minute)
+
+	var tokenCount int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM tokens WHERE user_id = $1", bob.ID).Scan(&tokenCount))
+	assert.Zero(t, tokenCount, "scheduling a deletion signs the user out everywhere")
+
+	again, err := s.Schedule(ctx, bob.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, deletion.ScheduledFor, again.ScheduledFor, "scheduling again keeps the date")
+
+	pending, err := s.ListPending(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, pending, 1)
+
+	_, _, err = authService.Login(context.Background(), bob.Email, bob.Password)
+	assert.NoError(t, err)
+	pending, err = s.ListPending(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, pending, "logging in cancels the deletion")
+
+	_, err = s.Schedule(ctx, bob.ID)
+	assert.NoError(t, err)
+
+	deleted, err := s.DeleteDue(ctx)
+	assert.NoError(t, err)
+	assert.Zero(t, deleted, "nothing is deleted during the grace period")
+
+	_, err = db.Exec("UPDATE account_deletions SET scheduled_for = NOW() - INTERVAL '1 minute' WHERE user_id = $1", bob.ID)
+	assert.NoError(t, err)
+
+	deleted, err = s.DeleteDue(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	var exists bool
+	assert.NoError(t, db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)", bob.ID).Scan(&exists))
+	assert.False(t, exists)
+
+	var likes int
+	assert.NoError(t, db.QueryRow("SELECT likes_count FROM projects WHERE id = $1", project.ID).Scan(&likes))
+	assert.Equal(t, project.LikesCount, likes, "like counts of the projects the user liked are kept")
+
+	var anonymous int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM project_likes WHERE project_id = $1 AND user_id IS NULL", project.ID).Scan(&anonymous))
+	assert.Equal(t, 1, anonymous, "likes of the user are kept without naming them")
+
+	pending, err = s.ListPending(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+}
@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/dumps"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setupDumpService(cfg config.DumpsConfig) (dumps.IDumpService, TestData, func()) {
+	testData, db, err := createTestData()
+
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+
+	return dumps.NewDumpService(db, cfg), *testData, func() { db.Close() }
+}
+
+func TestGenerateDump(t *testing.T) {
+	s, td, close := setupDumpService(config.DumpsConfig{Dir: t.TempDir(), Keep: 1})
+	defer close()
+
+	err := s.SetOptOut(td.Users[UserBob].ID, true)
+	assert.NoError(t, err)
+
+	optOut, err := s.IsOptedOut(td.Users[UserBob].ID)
+	assert.NoError(t, err)
+	assert.True(t, optOut)
+
+	dump, err := s.Generate()
+	assert.NoError(t, err)
+
+	path, err := s.Path(dump.Filename)
+	assert.NoError(t, err)
+
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	assert.NoError(t, err)
+
+	// public projects of users who didn't opt out, under pseudonyms and without their titles
+	wantRecords := 0
+	for _, p := range td.Projects {
+		if p.IsPublic && p.CreatorID != td.Users[UserBob].ID {
+			wantRecords++
+		}
+	}
+
+	ids := map[string]bool{}
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		var project data.DumpProject
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &project))
+		assert.NotContains(t, scanner.Text(), td.Users[UserAlice].ID.String())
+		for _, p := range td.Projects {
+			assert.NotContains(t, scanner.Text(), p.ID.String())
+			assert.NotContains(t, scanner.Text(), `"`+p.Title+`"`)
+		}
+		ids[project.ID] = true
+	}
+	assert.Equal(t, dump.Records, len(ids))
+	assert.Equal(t, wantRecords, dump.Records)
+
+	_, err = s.Path("../" + dump.Filename)
+	assert.Equal(t, services.ErrRecordNotFound, err)
+}
@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"context"
+	"log"
+	"strings"
+	"testing"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/requestid"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/consents"
+	"NodeTurtleAPI/internal/services/mail"
+	"NodeTurtleAPI/internal/utils"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendEmailIsRecorded(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	alice := testData.Users[UserAlice]
+
+	// templates aren't found from the tests directory, so sending fails before reaching the SMTP server
+	s := mail.NewMailService(db, config.MailConfig{Host: "localhost", Port: 1, From: "NodeTurtle <noreply@nodeturtle.test>"})
+
+	ctx := requestid.NewContext(context.Background(), "req-1")
+	assert.Error(t, s.SendEmail(ctx, alice.Email, "Activate Your Account", "activation", map[string]string{}))
+	assert.Error(t, s.SendEmail(context.Background(), "nobody@example.com", "Activate Your Account", "activation", map[string]string{}))
+
+	emails, err := s.ListEmails(ctx, data.EmailFilter{UserID: &alice.ID, Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, emails, 1) {
+		assert.Equal(t, alice.Email, emails[0].Recipient)
+		assert.Equal(t, "req-1", emails[0].RequestID)
+		assert.Equal(t, data.EmailFailed, emails[0].Status)
+		assert.Contains(t, emails[0].Error, "template activation not found")
+		assert.Regexp(t, `^<[0-9a-f-]+@nodeturtle\.test>$`, emails[0].MessageID)
+		assert.Nil(t, emails[0].SentAt)
+	}
+
+	emails, err = s.ListEmails(ctx, data.EmailFilter{RequestID: "req-1", Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, emails, 1)
+
+	// emails to addresses without an account are recorded too
+	emails, err = s.ListEmails(ctx, data.EmailFilter{Recipient: "NOBODY@example.com", Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, emails, 1) {
+		assert.Nil(t, emails[0].UserID)
+		assert.Empty(t, emails[0].RequestID)
+	}
+}
+
+func TestEmailPreferences(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	alice := testData.Users[UserAlice]
+	s := mail.NewMailService(db, config.MailConfig{Host: "localhost", Port: 1, From: "noreply@nodeturtle.test", UnsubscribeSecret: "secret"})
+
+	prefs, err := s.GetPreferences(ctx, alice.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, data.EmailPreferences{Marketing: false, Digests: true, Security: true}, *prefs)
+
+	// optional emails carry a link to unsubscribe
+	fields := map[string]string{}
+	assert.Error(t, s.SendEmail(ctx, alice.Email, "Your Weekly Digest", "digest", fields))
+	token, found := strings.CutPrefix(fields["unsubscribe_url"], "/api/email/unsubscribe/")
+	assert.True(t, found)
+
+	_, err = s.Unsubscribe(ctx, token+"x")
+	assert.ErrorIs(t, err, services.ErrInvalidToken)
+
+	category, err := s.Unsubscribe(ctx, token)
+	assert.NoError(t, err)
+	assert.Equal(t, data.EmailDigests, category)
+
+	prefs, err = s.GetPreferences(ctx, alice.ID)
+	assert.NoError(t, err)
+	assert.False(t, prefs.Digests)
+	assert.NotNil(t, prefs.UpdatedAt)
+
+	// digests are no longer sent, but recorded
+	assert.NoError(t, s.SendEmail(ctx, alice.Email, "Your Weekly Digest", "digest", map[string]string{}))
+	emails, err := s.ListEmails(ctx, data.EmailFilter{UserID: &alice.ID, Limit: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, emails, 1) {
+		assert.Equal(t, data.EmailSuppressed, emails[0].Status)
+	}
+
+	// marketing is the consent to marketing emails
+	prefs, err = s.UpdatePreferences(ctx, alice.ID, data.EmailPreferencesUpdate{Marketing: utils.Ptr(true), Digests: utils.Ptr(true)})
+	assert.NoError(t, err)
+	assert.True(t, prefs.Marketing)
+	assert.True(t, prefs.Digests)
+	assert.True(t, prefs.Security)
+
+	granted, err := consents.NewConsentService(db).HasConsent(ctx, alice.ID, data.ConsentMarketingEmails)
+	assert.NoError(t, err)
+	assert.True(t, granted)
+
+	_, err = s.UpdatePreferences(ctx, uuid.New(), data.EmailPreferencesUpdate{Security: utils.Ptr(false)})
+	assert.ErrorIs(t, err, services.ErrUserNotFound)
+}
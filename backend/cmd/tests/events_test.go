@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/ids"
+	"NodeTurtleAPI/internal/services/events"
+	"NodeTurtleAPI/internal/services/projects"
+	"context"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebuildProjectCounters(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	ps := projects.NewProjectService(db, ids.V7, config.QuotasConfig{})
+	es := events.NewEventService(db)
+
+	// the test data is inserted without events, seeded the way the migration creating the log does
+	_, err = db.Exec(`
+		INSERT INTO domain_events (type, project_id, payload)
+		SELECT 'project.counters_seeded', p.id, jsonb_build_object(
+			'likes', (SELECT COUNT(*) FROM project_likes l WHERE l.project_id = p.id),
+			'views', p.views_count,
+			'forks', (SELECT COUNT(*) FROM projects f WHERE f.forked_from = p.id)
+		)
+		FROM projects p`)
+	assert.NoError(t, err)
+
+	project := testData.Projects[ProjectAlicePublic]
+	frank := testData.Users[UserFrank].ID
+	bob := testData.Users[UserBob].ID
+
+	assert.NoError(t, ps.LikeProject(ctx, project.ID, frank))
+	assert.NoError(t, ps.RecordView(ctx, project.ID, &frank, "10.0.0.1"))
+	assert.NoError(t, ps.RecordView(ctx, project.ID, nil, "10.0.0.2"))
+	// counted once per viewer and day, so is the event
+	assert.NoError(t, ps.RecordView(ctx, project.ID, nil, "10.0.0.2"))
+
+	fork, err := ps.ForkProject(ctx, project.ID, bob)
+	assert.NoError(t, err)
+	_, err = ps.ForkProject(ctx, project.ID, frank)
+	assert.NoError(t, err)
+	assert.NoError(t, ps.DeleteProject(ctx, fork.ID))
+
+	var likes, views, forks int
+	counters := func() {
+		err := db.QueryRow("SELECT likes_count, views_count, fork_count FROM projects WHERE id = $1", project.ID).Scan(&likes, &views, &forks)
+		assert.NoError(t, err)
+	}
+	counters()
+	wantLikes, wantViews, wantForks := likes, views, forks
+
+	// nothing drifted yet
+	rebuild, err := es.Rebuild(ctx, "project_counters", true)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), rebuild.RowsFixed)
+
+	_, err = db.Exec("UPDATE projects SET likes_count = 100, views_count = 0, fork_count = 7 WHERE id = $1", project.ID)
+	assert.NoError(t, err)
+
+	rebuild, err = es.Rebuild(ctx, "project_counters", true)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), rebuild.RowsFixed)
+	counters()
+	assert.Equal(t, 100, likes, "a dry run changes nothing")
+
+	rebuild, err = es.Rebuild(ctx, "project_counters", false)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), rebuild.RowsFixed)
+	counters()
+	assert.Equal(t, wantLikes, likes)
+	assert.Equal(t, wantViews, views)
+	assert.Equal(t, wantForks, forks)
+	assert.Equal(t, 1, forks)
+
+	_, err = es.Rebuild(ctx, "trending", false)
+	assert.ErrorIs(t, err, events.ErrUnknownProjection)
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/exports"
+	"NodeTurtleAPI/internal/storage"
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDataExports(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	alice := testData.Users[UserAlice]
+	mockMailService := mocks.MockMailService{}
+	s := exports.NewExportService(db, storage.NewLocal(t.TempDir()), &mockMailService, config.ExportsConfig{Interval: 5, TTL: time.Hour})
+
+	export, err := s.RequestExport(ctx, alice.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, export) {
+		assert.Equal(t, data.ExportPending, export.Status)
+	}
+	_, err = s.RequestExport(ctx, alice.ID)
+	assert.ErrorIs(t, err, services.ErrExportPending, "one export is prepared at a time")
+
+	var link string
+	mockMailService.On("SendEmail", alice.Email, "Your Data Export Is Ready - Turtle Graphics", "export", mock.MatchedBy(func(values map[string]string) bool {
+		link = values["url"]
+		return strings.HasPrefix(link, "/api/exports/")
+	})).Return(nil).Once()
+
+	prepared, err := s.PreparePending(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, prepared)
+	mockMailService.AssertExpectations(t)
+
+	list, err := s.ListExports(ctx, alice.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, list, 1) {
+		assert.Equal(t, data.ExportReady, list[0].Status)
+		assert.NotNil(t, list[0].Size)
+		assert.NotNil(t, list[0].ExpiresAt)
+	}
+
+	archive, err := s.Download(ctx, strings.TrimPrefix(link, "/api/exports/"))
+	assert.NoError(t, err)
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	files := map[string][]byte{}
+	for _, f := range reader.File {
+		rc, err := f.Open()
+		assert.NoError(t, err)
+		var buf bytes.Buffer
+		buf.ReadFrom(rc)
+		rc.Close()
+		files[f.Name] = buf.Bytes()
+	}
+
+	var account map[string]interface{}
+	assert.NoError(t, json.Unmarshal(files["account.json"], &account))
+	assert.Equal(t, alice.Email, account["email"])
+	assert.NotContains(t, string(files["account.json"]), "password")
+	for _, name := range []string{"settings.json", "likes.json", "shared_with_me.json", "tokens.json"} {
+		assert.Contains(t, files, name)
+	}
+	for _, p := range testData.Projects {
+		if p.CreatorID == alice.ID {
+			assert.Contains(t, files, "projects/"+p.ID.String()+".json")
+		}
+	}
+
+	_, err = s.Download(ctx, "not-a-token")
+	assert.ErrorIs(t, err, services.ErrInvalidToken)
+
+	// expired exports can't be downloaded and are deleted with their archive
+	_, err = db.Exec("UPDATE data_exports SET expires_at = NOW() - INTERVAL '1 minute'")
+	assert.NoError(t, err)
+	_, err = s.Download(ctx, strings.TrimPrefix(link, "/api/exports/"))
+	assert.ErrorIs(t, err, services.ErrInvalidToken)
+
+	deleted, err := s.DeleteExpired(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	list, err = s.ListExports(ctx, alice.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+
+	// a new export can be asked for once the previous one is ready
+	_, err = s.RequestExport(ctx, alice.ID)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/clock"
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/featured"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setupFeaturedService(cfg config.FeaturedConfig) (featured.IFeaturedService, TestData, func()) {
+	testData, db, err := createTestData()
+
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+
+	return featured.NewFeaturedService(db, cfg, clock.System), *testData, func() { db.Close() }
+}
+
+func TestFeaturedQueue(t *testing.T) {
+	s, td, close := setupFeaturedService(config.FeaturedConfig{Slots: 2, GracePeriod: 48, InactivityWindow: 72, MinLikes: 1, Duration: 168})
+	defer close()
+
+	admin := td.Users[UserChris].ID
+
+	_, err := s.Enqueue(td.Projects[ProjectAlicePrivate].ID, admin)
+	assert.Equal(t, services.ErrProjectNotFound, err)
+
+	entry, err := s.Enqueue(td.Projects[ProjectAlicePublic].ID, admin)
+	assert.NoError(t, err)
+	assert.Equal(t, td.Projects[ProjectAlicePublic].Title, entry.Title)
+
+	_, err = s.Enqueue(td.Projects[ProjectAlicePublic].ID, admin)
+	assert.Equal(t, services.ErrAlreadyQueued, err)
+
+	queue, err := s.GetQueue()
+	assert.NoError(t, err)
+	assert.Len(t, queue, 1)
+
+	err = s.Dequeue(td.Projects[ProjectAlicePublic].ID)
+	assert.NoError(t, err)
+
+	err = s.Dequeue(td.Projects[ProjectAlicePublic].ID)
+	assert.Equal(t, services.ErrRecordNotFound, err)
+}
+
+func TestFeaturedRotate(t *testing.T) {
+	s, td, close := setupFeaturedService(config.FeaturedConfig{Slots: 2, GracePeriod: 48, InactivityWindow: 72, MinLikes: 1, Duration: 168})
+	defer close()
+
+	_, err := s.Enqueue(td.Projects[ProjectAlicePublic].ID, td.Users[UserChris].ID)
+	assert.NoError(t, err)
+
+	// bob's featured project is still within its grace period, the free slot is backfilled from the queue
+	rotation, err := s.Rotate()
+	assert.NoError(t, err)
+	assert.Empty(t, rotation.Expired)
+	assert.Equal(t, td.Projects[ProjectAlicePublic].ID, rotation.Featured[0])
+
+	queue, err := s.GetQueue()
+	assert.NoError(t, err)
+	assert.Empty(t, queue)
+
+	// all slots are taken
+	rotation, err = s.Rotate()
+	assert.NoError(t, err)
+	assert.Empty(t, rotation.Featured)
+}
+
+func TestFeatureProject(t *testing.T) {
+	s, td, close := setupFeaturedService(config.FeaturedConfig{Slots: 2, GracePeriod: 48, InactivityWindow: 72, MinLikes: 1, Duration: 168})
+	defer close()
+
+	admin := td.Users[UserChris].ID
+	projectID := td.Projects[ProjectAlicePublic].ID
+
+	_, err := s.Feature(td.Projects[ProjectAlicePrivate].ID, admin, time.Now().Add(time.Hour))
+	assert.Equal(t, services.ErrProjectNotFound, err)
+
+	// featuring takes the project off the queue
+	_, err = s.Enqueue(projectID, admin)
+	assert.NoError(t, err)
+
+	project, err := s.Feature(projectID, admin, time.Now().Add(2*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, admin, *project.FeaturedBy)
+	assert.Equal(t, td.Users[UserChris].Username, *project.FeaturedByUsername)
+
+	queue, err := s.GetQueue()
+	assert.NoError(t, err)
+	assert.Empty(t, queue)
+
+	expiring, err := s.ListFeatured(3 * time.Hour)
+	assert.NoError(t, err)
+	if assert.Len(t, expiring, 1) {
+		assert.Equal(t, projectID, expiring[0].ProjectID)
+	}
+
+	all, err := s.ListFeatured(0)
+	assert.NoError(t, err)
+	assert.Greater(t, len(all), len(expiring))
+
+	assert.NoError(t, s.Unfeature(projectID))
+	assert.Equal(t, services.ErrRecordNotFound, s.Unfeature(projectID))
+}
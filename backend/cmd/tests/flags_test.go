@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/cache"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/database"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/announcements"
+	"NodeTurtleAPI/internal/services/flags"
+	"NodeTurtleAPI/internal/utils"
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatureFlags(t *testing.T) {
+	_, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec("TRUNCATE feature_flags")
+	assert.NoError(t, err)
+
+	// two API instances sharing the database
+	first := cache.NewRegistry(db, time.Hour)
+	second := cache.NewRegistry(db, time.Hour)
+	firstFlags := flags.NewFlagService(db, first)
+	secondFlags := flags.NewFlagService(db, second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go second.Listen(ctx, database.DSN(testDatabaseConfig()))
+
+	assert.False(t, secondFlags.IsEnabled("new-editor"))
+
+	flag, err := firstFlags.SetFlag("new-editor", true, utils.Ptr("Node editor rewrite"))
+	assert.NoError(t, err)
+	assert.True(t, flag.Enabled)
+	assert.Equal(t, "Node editor rewrite", flag.Description)
+	assert.True(t, firstFlags.IsEnabled("new-editor"))
+
+	// the second instance drops its cached flags once it receives the notification
+	assert.Eventually(t, func() bool { return secondFlags.IsEnabled("new-editor") }, 5*time.Second, 10*time.Millisecond)
+
+	// the description is kept when not provided
+	flag, err = firstFlags.SetFlag("new-editor", false, nil)
+	assert.NoError(t, err)
+	assert.False(t, flag.Enabled)
+	assert.Equal(t, "Node editor rewrite", flag.Description)
+
+	all, err := firstFlags.GetFlags()
+	assert.NoError(t, err)
+	assert.Len(t, all, 1)
+
+	metrics := second.Metrics()
+	assert.Len(t, metrics, 1)
+	assert.Equal(t, "feature_flags", metrics[0].Name)
+	assert.GreaterOrEqual(t, metrics[0].Invalidations, int64(1))
+}
+
+func TestAnnouncements(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec("TRUNCATE announcements")
+	assert.NoError(t, err)
+
+	s := announcements.NewAnnouncementService(db, cache.NewRegistry(db, time.Hour))
+	admin := testData.Users[UserAlice].ID
+
+	info, err := s.Create(data.AnnouncementCreate{Message: "New nodes available"}, admin)
+	assert.NoError(t, err)
+	assert.Equal(t, data.AnnouncementInfo, info.Level)
+
+	_, err = s.Create(data.AnnouncementCreate{Message: "Maintenance tonight", Level: data.AnnouncementCritical}, admin)
+	assert.NoError(t, err)
+
+	_, err = s.Create(data.AnnouncementCreate{Message: "Scheduled", StartsAt: utils.Ptr(time.Now().Add(time.Hour))}, admin)
+	assert.NoError(t, err)
+
+	_, err = s.Create(data.AnnouncementCreate{
+		Message:  "Over",
+		StartsAt: utils.Ptr(time.Now().Add(-2 * time.Hour)),
+		EndsAt:   utils.Ptr(time.Now().Add(-time.Hour)),
+	}, admin)
+	assert.NoError(t, err)
+
+	active, err := s.GetActive()
+	assert.NoError(t, err)
+	assert.Len(t, active, 2)
+	assert.Equal(t, "Maintenance tonight", active[0].Message)
+	assert.Equal(t, "New nodes available", active[1].Message)
+
+	assert.NoError(t, s.Delete(info.ID))
+	assert.ErrorIs(t, s.Delete(info.ID), services.ErrRecordNotFound)
+
+	active, err = s.GetActive()
+	assert.NoError(t, err)
+	assert.Len(t, active, 1)
+}
@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/ids"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/folders"
+	"NodeTurtleAPI/internal/services/projects"
+	"NodeTurtleAPI/internal/utils"
+	"context"
+	"log"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectFolders(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	s := folders.NewFolderService(db)
+	ps := projects.NewProjectService(db, ids.V7, config.QuotasConfig{})
+	alice := testData.Users[UserAlice].ID
+	bob := testData.Users[UserBob].ID
+	public := testData.Projects[ProjectAlicePublic].ID
+	private := testData.Projects[ProjectAlicePrivate].ID
+
+	loops, err := s.CreateFolder(ctx, alice, data.FolderCreate{Name: "Loops"})
+	assert.NoError(t, err)
+	shapes, err := s.CreateFolder(ctx, alice, data.FolderCreate{Name: "Shapes"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, shapes.Position)
+
+	spirals, err := s.CreateFolder(ctx, alice, data.FolderCreate{Name: "Spirals", ParentID: &loops.ID})
+	assert.NoError(t, err)
+	_, err = s.CreateFolder(ctx, alice, data.FolderCreate{Name: "Deeper", ParentID: &spirals.ID})
+	assert.ErrorIs(t, err, services.ErrFolderTooDeep)
+	_, err = s.CreateFolder(ctx, bob, data.FolderCreate{Name: "Mine", ParentID: &loops.ID})
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+
+	// projects of other users can't be filed, nor into folders of other users
+	assert.ErrorIs(t, s.MoveProject(ctx, bob, public, nil), services.ErrProjectNotFound)
+	assert.ErrorIs(t, s.MoveProject(ctx, alice, public, utils.Ptr(uuid.New())), services.ErrRecordNotFound)
+
+	assert.NoError(t, s.MoveProject(ctx, alice, public, &spirals.ID))
+	assert.NoError(t, s.MoveProject(ctx, alice, private, &spirals.ID))
+	assert.NoError(t, s.ReorderProjects(ctx, alice, spirals.ID, []uuid.UUID{private, public}))
+	assert.ErrorIs(t, s.ReorderProjects(ctx, alice, spirals.ID, []uuid.UUID{private}), services.ErrFolderOrder)
+	assert.ErrorIs(t, s.ReorderProjects(ctx, alice, spirals.ID, []uuid.UUID{private, private}), services.ErrFolderOrder)
+
+	assert.NoError(t, s.ReorderFolders(ctx, alice, nil, []uuid.UUID{shapes.ID, loops.ID}))
+	assert.ErrorIs(t, s.ReorderFolders(ctx, alice, nil, []uuid.UUID{shapes.ID}), services.ErrFolderOrder)
+
+	tree, err := s.ListFolders(ctx, alice)
+	assert.NoError(t, err)
+	if assert.Len(t, tree, 2) {
+		assert.Equal(t, "Shapes", tree[0].Name)
+		assert.Equal(t, "Loops", tree[1].Name)
+		if assert.Len(t, tree[1].Folders, 1) {
+			assert.Equal(t, []uuid.UUID{private, public}, tree[1].Folders[0].ProjectIDs)
+		}
+	}
+
+	// the listing follows the order of the folder, visibility still applies
+	filed, err := ps.GetUserProjectSummaries(ctx, alice, &alice, &data.FolderFilter{FolderID: &spirals.ID})
+	assert.NoError(t, err)
+	if assert.Len(t, filed, 2) {
+		assert.Equal(t, private, filed[0].ID)
+		assert.Equal(t, public, filed[1].ID)
+	}
+	filed, err = ps.GetUserProjectSummaries(ctx, alice, &bob, &data.FolderFilter{FolderID: &spirals.ID})
+	assert.NoError(t, err)
+	assert.Len(t, filed, 1)
+
+	unfiled, err := ps.GetUserProjectSummaries(ctx, alice, &alice, &data.FolderFilter{})
+	assert.NoError(t, err)
+	for _, p := range unfiled {
+		assert.NotContains(t, []uuid.UUID{public, private}, p.ID)
+	}
+
+	// deleting a folder keeps its projects, in no folder
+	renamed, err := s.RenameFolder(ctx, alice, loops.ID, "Repeats")
+	assert.NoError(t, err)
+	assert.Equal(t, "Repeats", renamed.Name)
+	assert.ErrorIs(t, s.DeleteFolder(ctx, bob, loops.ID), services.ErrRecordNotFound)
+	assert.NoError(t, s.DeleteFolder(ctx, alice, loops.ID))
+
+	tree, err = s.ListFolders(ctx, alice)
+	assert.NoError(t, err)
+	assert.Len(t, tree, 1)
+
+	unfiledAfter, err := ps.GetUserProjectSummaries(ctx, alice, &alice, &data.FolderFilter{})
+	assert.NoError(t, err)
+	assert.Len(t, unfiledAfter, len(unfiled)+2)
+}
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/ids"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/guests"
+	"NodeTurtleAPI/internal/services/projects"
+	"NodeTurtleAPI/internal/services/users"
+	"context"
+	"encoding/json"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuestAccounts(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	s := guests.NewGuestService(db, time.Hour)
+	ps := projects.NewProjectService(db, ids.V7, config.QuotasConfig{})
+	us := users.NewUserService(db)
+
+	guest, err := s.CreateGuest(ctx)
+	assert.NoError(t, err)
+	assert.True(t, guest.IsGuest())
+	assert.True(t, guest.IsActivated)
+
+	loaded, err := us.GetUserByID(ctx, guest.ID)
+	assert.NoError(t, err)
+	assert.True(t, loaded.IsGuest())
+
+	project, err := ps.CreateProject(ctx, data.ProjectCreate{Title: "Guest Spiral", CreatorID: guest.ID, Data: json.RawMessage(`{}`)})
+	assert.NoError(t, err)
+	count, err := ps.CountUserProjects(ctx, guest.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	_, err = s.ClaimGuest(ctx, guest.ID, data.UserRegistration{Email: testData.Users[UserAlice].Email, Username: "claimed", Password: "Str0ngPassw0rd"})
+	assert.ErrorIs(t, err, services.ErrDuplicateEmail)
+	_, err = s.ClaimGuest(ctx, guest.ID, data.UserRegistration{Email: "claimed@test.test", Username: testData.Users[UserAlice].Username, Password: "Str0ngPassw0rd"})
+	assert.ErrorIs(t, err, services.ErrDuplicateUsername)
+
+	user, err := s.ClaimGuest(ctx, guest.ID, data.UserRegistration{Email: "claimed@test.test", Username: "claimed", Password: "Str0ngPassw0rd"})
+	assert.NoError(t, err)
+	assert.False(t, user.IsActivated)
+
+	// the project moved to the new account and the guest is gone
+	isOwner, err := ps.IsOwner(ctx, project.ID, user.ID)
+	assert.NoError(t, err)
+	assert.True(t, isOwner)
+	_, err = us.GetUserByID(ctx, guest.ID)
+	assert.ErrorIs(t, err, services.ErrUserNotFound)
+
+	// claimed once
+	_, err = s.ClaimGuest(ctx, guest.ID, data.UserRegistration{Email: "again@test.test", Username: "again", Password: "Str0ngPassw0rd"})
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+
+	// expired guests are deleted with their projects
+	expiring := guests.NewGuestService(db, -time.Minute)
+	expired, err := expiring.CreateGuest(ctx)
+	assert.NoError(t, err)
+	_, err = ps.CreateProject(ctx, data.ProjectCreate{Title: "Forgotten Spiral", CreatorID: expired.ID, Data: json.RawMessage(`{}`)})
+	assert.NoError(t, err)
+	_, err = s.ClaimGuest(ctx, expired.ID, data.UserRegistration{Email: "late@test.test", Username: "late", Password: "Str0ngPassw0rd"})
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+
+	_, err = s.CreateGuest(ctx)
+	assert.NoError(t, err)
+
+	deleted, err := s.DeleteExpiredGuests(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	count, err = ps.CountUserProjects(ctx, expired.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
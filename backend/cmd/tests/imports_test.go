@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/imports"
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectUploads(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	s := imports.NewImportService(db, time.Hour)
+	alice := testData.Users[UserAlice].ID
+	bob := testData.Users[UserBob].ID
+
+	file := []byte(`{"title":"Imported","data":{"nodes":[],"edges":[]}}`)
+	upload, err := s.CreateUpload(ctx, alice, int64(len(file)))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), upload.Offset)
+
+	// uploads of other users can't be seen or written to
+	_, err = s.GetUpload(ctx, upload.ID, bob)
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+	_, err = s.AppendChunk(ctx, upload.ID, bob, 0, file[:20])
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+
+	upload, err = s.AppendChunk(ctx, upload.ID, alice, 0, file[:20])
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20), upload.Offset)
+
+	// a chunk retried after its response got lost is rejected with the offset to resume from
+	upload, err = s.AppendChunk(ctx, upload.ID, alice, 0, file[:20])
+	assert.ErrorIs(t, err, services.ErrUploadOffset)
+	assert.Equal(t, int64(20), upload.Offset)
+
+	_, err = s.ReadUpload(ctx, upload.ID, alice)
+	assert.ErrorIs(t, err, services.ErrUploadIncomplete)
+
+	_, err = s.AppendChunk(ctx, upload.ID, alice, 20, append(file[20:], '\n'))
+	assert.ErrorIs(t, err, services.ErrUploadSize)
+
+	upload, err = s.AppendChunk(ctx, upload.ID, alice, 20, file[20:])
+	assert.NoError(t, err)
+	assert.True(t, upload.Complete())
+
+	assembled, err := s.ReadUpload(ctx, upload.ID, alice)
+	assert.NoError(t, err)
+	assert.Equal(t, file, assembled)
+
+	assert.NoError(t, s.DeleteUpload(ctx, upload.ID, alice))
+	assert.ErrorIs(t, s.DeleteUpload(ctx, upload.ID, alice), services.ErrRecordNotFound)
+
+	// unfinished uploads expire
+	expired, err := imports.NewImportService(db, -time.Minute).CreateUpload(ctx, alice, 10)
+	assert.NoError(t, err)
+	_, err = s.GetUpload(ctx, expired.ID, alice)
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+
+	deleted, err := s.DeleteExpiredUploads(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
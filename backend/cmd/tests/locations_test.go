@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/locations"
+	"log"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupLocationService() (locations.ILocationService, TestData, func()) {
+	testData, db, err := createTestData()
+
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+
+	return locations.NewLocationService(db, config.LoginVerificationConfig{CodeTTL: 15}), *testData, func() { db.Close() }
+}
+
+func TestTrustedLocations(t *testing.T) {
+	s, td, close := setupLocationService()
+	defer close()
+
+	userID := td.Users[UserAlice].ID
+
+	// the first login establishes the first trusted location
+	trusted, err := s.IsTrusted(userID, "SI")
+	assert.NoError(t, err)
+	assert.True(t, trusted)
+
+	assert.NoError(t, s.Trust(userID, "SI"))
+	assert.NoError(t, s.Trust(userID, "SI"))
+
+	trusted, err = s.IsTrusted(userID, "FR")
+	assert.NoError(t, err)
+	assert.False(t, trusted)
+
+	list, err := s.ListTrusted(userID)
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, "SI", list[0].Country)
+
+	assert.NoError(t, s.RemoveTrusted(userID, "SI"))
+	assert.Equal(t, services.ErrRecordNotFound, s.RemoveTrusted(userID, "SI"))
+}
+
+func TestLoginChallenge(t *testing.T) {
+	s, td, close := setupLocationService()
+	defer close()
+
+	userID := td.Users[UserAlice].ID
+
+	challenge, err := s.CreateChallenge(userID, "FR")
+	assert.NoError(t, err)
+	assert.Len(t, challenge.Code, 6)
+
+	wrongCode := wrongLoginCode(challenge.Code)
+
+	_, err = s.VerifyChallenge(challenge.ID, wrongCode)
+	assert.Equal(t, services.ErrInvalidToken, err)
+
+	_, err = s.VerifyChallenge(uuid.New(), challenge.Code)
+	assert.Equal(t, services.ErrInvalidToken, err)
+
+	verified, err := s.VerifyChallenge(challenge.ID, challenge.Code)
+	assert.NoError(t, err)
+	assert.Equal(t, userID, verified.UserID)
+	assert.Equal(t, "FR", verified.Country)
+
+	// codes are single use
+	_, err = s.VerifyChallenge(challenge.ID, challenge.Code)
+	assert.Equal(t, services.ErrInvalidToken, err)
+
+	// too many wrong codes discard the challenge
+	challenge, err = s.CreateChallenge(userID, "FR")
+	assert.NoError(t, err)
+	wrongCode = wrongLoginCode(challenge.Code)
+	for i := 0; i < 4; i++ {
+		_, err = s.VerifyChallenge(challenge.ID, wrongCode)
+		assert.Equal(t, services.ErrInvalidToken, err)
+	}
+	_, err = s.VerifyChallenge(challenge.ID, wrongCode)
+	assert.Equal(t, services.ErrTooManyAttempts, err)
+
+	_, err = s.VerifyChallenge(challenge.ID, challenge.Code)
+	assert.Equal(t, services.ErrInvalidToken, err)
+}
+
+func wrongLoginCode(code string) string {
+	if code == "000000" {
+		return "111111"
+	}
+	return "000000"
+}
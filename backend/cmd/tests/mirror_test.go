@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/database"
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMirror(t *testing.T) {
+	testData, testDB, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer testDB.Close()
+
+	ctx := context.Background()
+	alice := testData.Users[UserAlice].ID
+
+	// the test database stands in for the replica, connections to it can't write like on a hot standby
+	cfg := testDatabaseConfig()
+	cfg.ReplicaURL = database.DSN(cfg) + " options='-c default_transaction_read_only=on'"
+	db, mirror, err := database.ConnectMirror(cfg)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer db.Close()
+
+	rename := func(username string) error {
+		_, err := db.ExecContext(ctx, "UPDATE users SET username = $2 WHERE id = $1", alice, username)
+		return err
+	}
+
+	assert.False(t, mirror.ReadOnly())
+	assert.NoError(t, rename("alice_primary"))
+
+	status, err := mirror.SetReadOnly(ctx, true, "failover")
+	assert.NoError(t, err)
+	assert.True(t, status.Enabled)
+	assert.True(t, status.Replica)
+	assert.Equal(t, "failover", status.Reason)
+
+	// reads are served from the replica, the idle connection to the primary was dropped
+	var username string
+	assert.NoError(t, db.QueryRowContext(ctx, "SELECT username FROM users WHERE id = $1", alice).Scan(&username))
+	assert.Equal(t, "alice_primary", username)
+	assert.ErrorContains(t, rename("alice_replica"), "read-only transaction")
+
+	// switching on again only updates the reason
+	again, err := mirror.SetReadOnly(ctx, true, "maintenance")
+	assert.NoError(t, err)
+	assert.Equal(t, status.Since, again.Since)
+	assert.Equal(t, "maintenance", mirror.Status().Reason)
+
+	// other instances follow the mode shared through the primary database
+	otherDB, other, err := database.ConnectMirror(cfg)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer otherDB.Close()
+	assert.False(t, other.ReadOnly())
+
+	syncCtx, cancel := context.WithTimeout(ctx, time.Second)
+	other.Sync(syncCtx)
+	cancel()
+	assert.True(t, other.ReadOnly())
+	assert.Equal(t, "maintenance", other.Status().Reason)
+
+	_, err = mirror.SetReadOnly(ctx, false, "")
+	assert.NoError(t, err)
+	assert.False(t, mirror.ReadOnly())
+	assert.NoError(t, rename("alice_restored"))
+}
@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services/consents"
+	"NodeTurtleAPI/internal/services/notifications"
+	"context"
+	"encoding/json"
+	"log"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLikeNotifications(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	s := notifications.NewNotificationService(db, &mocks.MockMailService{})
+	alice := testData.Users[UserAlice].ID
+	frank := testData.Users[UserFrank].ID
+	project := testData.Projects[ProjectAlicePublic]
+
+	n, err := s.NotifyLike(ctx, project.ID, frank)
+	assert.NoError(t, err)
+	if assert.NotNil(t, n) {
+		assert.Equal(t, alice, n.UserID)
+		assert.Equal(t, data.NotificationProjectLiked, n.Type)
+
+		var liked data.ProjectLiked
+		assert.NoError(t, json.Unmarshal(n.Payload, &liked))
+		assert.Equal(t, project.ID, liked.ProjectID)
+		assert.Equal(t, frank, liked.UserID)
+		assert.Equal(t, testData.Users[UserFrank].Username, liked.Username)
+	}
+
+	// liking again while the notification is unread doesn't notify twice
+	n, err = s.NotifyLike(ctx, project.ID, frank)
+	assert.NoError(t, err)
+	assert.Nil(t, n)
+
+	// nor do creators liking their own project
+	n, err = s.NotifyLike(ctx, project.ID, alice)
+	assert.NoError(t, err)
+	assert.Nil(t, n)
+
+	list, unread, err := s.GetNotifications(ctx, alice, 10)
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, 1, unread)
+
+	marked, err := s.MarkRead(ctx, alice, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), marked)
+
+	_, unread, err = s.GetNotifications(ctx, alice, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, unread)
+
+	// once read, a new like notifies again unless the creator turned it off
+	off := false
+	prefs, err := s.UpdatePreferences(ctx, alice, data.NotificationPreferencesUpdate{Likes: &off})
+	assert.NoError(t, err)
+	assert.False(t, prefs.Likes)
+	assert.Equal(t, data.DigestOff, prefs.Digest)
+
+	n, err = s.NotifyLike(ctx, project.ID, frank)
+	assert.NoError(t, err)
+	assert.Nil(t, n)
+
+	on := true
+	_, err = s.UpdatePreferences(ctx, alice, data.NotificationPreferencesUpdate{Likes: &on})
+	assert.NoError(t, err)
+
+	n, err = s.NotifyLike(ctx, project.ID, frank)
+	assert.NoError(t, err)
+	assert.NotNil(t, n)
+}
+
+func TestNotificationDigests(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	alice := testData.Users[UserAlice]
+	bob := testData.Users[UserBob]
+
+	mockMailService := mocks.MockMailService{}
+	s := notifications.NewNotificationService(db, &mockMailService)
+	c := consents.NewConsentService(db)
+
+	// users who never changed their preferences get the defaults
+	prefs, err := s.GetPreferences(ctx, alice.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, data.DefaultNotificationPreferences.Likes, prefs.Likes)
+	assert.Equal(t, data.DigestOff, prefs.Digest)
+	assert.Nil(t, prefs.UpdatedAt)
+
+	daily := data.DigestDaily
+	for _, id := range []uuid.UUID{alice.ID, bob.ID} {
+		_, err = s.UpdatePreferences(ctx, id, data.NotificationPreferencesUpdate{Digest: &daily})
+		assert.NoError(t, err)
+	}
+	// only Alice consents to marketing emails
+	_, err = c.SetConsents(ctx, alice.ID, map[data.ConsentPurpose]bool{data.ConsentMarketingEmails: true})
+	assert.NoError(t, err)
+
+	// nothing is due within a day of switching the digest on
+	sent, err := s.SendDigests(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	// a day later, with the likes of the test data given in between
+	_, err = db.Exec("UPDATE notification_preferences SET digest_sent_at = NOW() - INTERVAL '2 days'")
+	assert.NoError(t, err)
+	_, err = db.Exec("UPDATE project_likes SET created_at = NOW() - INTERVAL '1 day'")
+	assert.NoError(t, err)
+
+	mockMailService.On("SendEmail", alice.Email, "Your Daily Digest - Turtle Graphics", "digest", mock.MatchedBy(func(values map[string]string) bool {
+		return values["Likes"] == "2" && values["Forks"] == "0" && values["TopProject"] == testData.Projects[ProjectAlicePublic].Title
+	})).Return(nil).Once()
+
+	sent, err = s.SendDigests(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	mockMailService.AssertExpectations(t)
+
+	// the next digest starts where the last one ended
+	sent, err = s.SendDigests(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+}
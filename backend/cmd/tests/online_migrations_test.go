@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/database"
+	"NodeTurtleAPI/internal/services/jobs"
+	"context"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunOnlineMigrations(t *testing.T) {
+	_, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`
+		DELETE FROM background_jobs WHERE name IN ('idx_projects_online_test', 'projects_title_length');
+		DROP INDEX IF EXISTS idx_projects_online_test;
+		ALTER TABLE projects ADD COLUMN IF NOT EXISTS title_length INTEGER;`)
+	assert.NoError(t, err)
+	defer db.Exec(`
+		DELETE FROM background_jobs WHERE name IN ('idx_projects_online_test', 'projects_title_length');
+		DROP INDEX IF EXISTS idx_projects_online_test;
+		ALTER TABLE projects DROP COLUMN IF EXISTS title_length;`)
+
+	migrations := []database.OnlineMigration{
+		database.CreateIndexConcurrently("idx_projects_online_test", "projects (last_edited_at)"),
+		database.BackfillBatches("projects_title_length", database.Backfill{
+			Table:     "projects",
+			Set:       "title_length = length(title)",
+			Where:     "title_length IS NULL",
+			BatchSize: 2,
+		}),
+	}
+
+	assert.NoError(t, database.RunOnlineMigrations(context.Background(), db, migrations))
+
+	var valid bool
+	err = db.QueryRow(`
+		SELECT i.indisvalid FROM pg_class c JOIN pg_index i ON i.indexrelid = c.oid
+		WHERE c.relname = 'idx_projects_online_test'`).Scan(&valid)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	var missing int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM projects WHERE title_length IS NULL").Scan(&missing))
+	assert.Equal(t, 0, missing)
+
+	var projects int64
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM projects").Scan(&projects))
+
+	s := jobs.NewJobService(db)
+	job, err := s.GetJob("projects_title_length")
+	assert.NoError(t, err)
+	assert.Equal(t, data.JobCompleted, job.Status)
+	assert.Equal(t, projects, job.Processed)
+	assert.Equal(t, projects, *job.Total)
+
+	// completed migrations are skipped
+	_, err = db.Exec("UPDATE projects SET title_length = NULL")
+	assert.NoError(t, err)
+	assert.NoError(t, database.RunOnlineMigrations(context.Background(), db, migrations))
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM projects WHERE title_length IS NULL").Scan(&missing))
+	assert.Equal(t, int(projects), missing)
+}
@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/database"
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaintainPartitions(t *testing.T) {
+	_, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`
+		DROP TABLE IF EXISTS partition_test;
+		CREATE TABLE partition_test (
+			id BIGSERIAL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (id, created_at)
+		) PARTITION BY RANGE (created_at);`)
+	assert.NoError(t, err)
+	defer db.Exec("DROP TABLE IF EXISTS partition_test")
+
+	partitions := func() []string {
+		rows, err := db.Query(`
+			SELECT c.relname FROM pg_inherits i
+			JOIN pg_class c ON c.oid = i.inhrelid
+			JOIN pg_class p ON p.oid = i.inhparent
+			WHERE p.relname = 'partition_test'
+			ORDER BY c.relname`)
+		assert.NoError(t, err)
+		defer rows.Close()
+
+		names := []string{}
+		for rows.Next() {
+			var name string
+			assert.NoError(t, rows.Scan(&name))
+			names = append(names, name)
+		}
+		return names
+	}
+
+	tables := []database.PartitionedTable{{Name: "partition_test", Retention: 1}}
+	january := time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, database.MaintainPartitions(context.Background(), db, tables, 2, january))
+	assert.Equal(t, []string{"partition_test_p2025_01", "partition_test_p2025_02", "partition_test_p2025_03"}, partitions())
+
+	// running again is a no-op
+	assert.NoError(t, database.MaintainPartitions(context.Background(), db, tables, 2, january))
+	assert.Len(t, partitions(), 3)
+
+	_, err = db.Exec("INSERT INTO partition_test (created_at) VALUES ($1), ($2)", january, january.AddDate(0, 1, 0))
+	assert.NoError(t, err)
+
+	// in March the January partition falls out of the one month retention
+	march := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, database.MaintainPartitions(context.Background(), db, tables, 2, march))
+	assert.Equal(t, []string{"partition_test_p2025_02", "partition_test_p2025_03", "partition_test_p2025_04", "partition_test_p2025_05"}, partitions())
+
+	var count int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM partition_test").Scan(&count))
+	assert.Equal(t, 1, count)
+}
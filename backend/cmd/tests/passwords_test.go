@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services/passwords"
+	"crypto/sha1"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluatePassword(t *testing.T) {
+	dir := t.TempDir()
+	filterPath := filepath.Join(dir, "breached.bloom")
+
+	filter := passwords.NewBloomFilter(10, 0.001)
+	filter.AddHash(sha1.Sum([]byte("Tr0ub4dour&3xyz")))
+
+	f, err := os.Create(filterPath)
+	assert.NoError(t, err)
+	_, err = filter.WriteTo(f)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	s, err := passwords.NewPasswordService(config.PasswordsConfig{MinLength: 8, MinScore: 2, BreachedFilter: filterPath})
+	assert.NoError(t, err)
+
+	codes := func(strength data.PasswordStrength) []string {
+		c := []string{}
+		for _, issue := range strength.Issues {
+			c = append(c, issue.Code)
+		}
+		return c
+	}
+
+	tests := map[string]struct {
+		password   string
+		userInputs []string
+		wantOK     bool
+		wantCodes  []string
+	}{
+		"Strong passphrase": {
+			password: "correct horse battery staple",
+			wantOK:   true,
+		},
+		"Random characters": {
+			password: "xK9#mQ2$vL7p",
+			wantOK:   true,
+		},
+		"Too short": {
+			password:  "aB3$",
+			wantCodes: []string{data.PasswordTooShort},
+		},
+		"Common password": {
+			password:  "password123",
+			wantCodes: []string{data.PasswordTooWeak, data.PasswordCommonPattern},
+		},
+		"L33t common password": {
+			password:  "P@ssw0rd",
+			wantCodes: []string{data.PasswordTooWeak},
+		},
+		"Keyboard walk": {
+			password:  "qwertyuiop",
+			wantCodes: []string{data.PasswordTooWeak, data.PasswordCommonPattern},
+		},
+		"Contains username": {
+			password:   "turtlemaster99!",
+			userInputs: []string{"turtlemaster", "someone@test.test"},
+			wantCodes:  []string{data.PasswordContainsUser},
+		},
+		"Breached password": {
+			password:  "Tr0ub4dour&3xyz",
+			wantCodes: []string{data.PasswordBreached},
+		},
+		"Too long": {
+			password:  string(make([]byte, 73)),
+			wantCodes: []string{data.PasswordTooLong},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			strength := s.Evaluate(tt.password, tt.userInputs)
+
+			assert.Equal(t, tt.wantOK, strength.Acceptable(), "issues: %v", strength.Issues)
+			for _, code := range tt.wantCodes {
+				assert.Contains(t, codes(strength), code)
+			}
+		})
+	}
+}
+
+func TestBloomFilter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "filter.bloom")
+
+	filter := passwords.NewBloomFilter(1000, 0.01)
+	for _, p := range []string{"123456", "password", "qwerty"} {
+		filter.AddHash(sha1.Sum([]byte(p)))
+	}
+
+	f, err := os.Create(path)
+	assert.NoError(t, err)
+	_, err = filter.WriteTo(f)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	loaded, err := passwords.LoadBloomFilter(path)
+	assert.NoError(t, err)
+
+	assert.True(t, loaded.Contains("123456"))
+	assert.True(t, loaded.Contains("password"))
+	assert.True(t, loaded.Contains("qwerty"))
+	assert.False(t, loaded.Contains("a much longer passphrase nobody uses"))
+
+	assert.NoError(t, os.WriteFile(path, []byte("not a filter file"), 0o600))
+	_, err = passwords.LoadBloomFilter(path)
+	assert.Error(t, err)
+
+	_, err = passwords.NewPasswordService(config.PasswordsConfig{BreachedFilter: filepath.Join(t.TempDir(), "missing.bloom")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/profiles"
+	"context"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfiles(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	s := profiles.NewProfileService(db)
+	bob := testData.Users[UserBob]
+
+	wantProjects, wantLikes := 0, 0
+	for _, p := range testData.Projects {
+		if p.CreatorID == bob.ID && p.IsPublic {
+			wantProjects++
+			wantLikes += p.LikesCount
+		}
+	}
+
+	// users who never edited their profile have an empty one
+	profile, err := s.GetProfile(ctx, bob.Username)
+	assert.NoError(t, err)
+	if assert.NotNil(t, profile) {
+		assert.Equal(t, bob.Username, profile.Username)
+		assert.Empty(t, profile.Bio)
+		assert.Nil(t, profile.Website)
+		assert.Empty(t, profile.SocialLinks)
+		assert.Equal(t, wantProjects, profile.PublicProjects)
+		assert.Equal(t, wantLikes, profile.LikesReceived, "likes of private projects aren't told")
+	}
+
+	profile, err = s.UpdateProfile(ctx, bob.ID, data.UserProfileUpdate{
+		Bio:         "Draws spirals",
+		Website:     "https://bob.example.com",
+		SocialLinks: map[string]string{"github": "https://github.com/bob"},
+	})
+	assert.NoError(t, err)
+	if assert.NotNil(t, profile) {
+		assert.Equal(t, "Draws spirals", profile.Bio)
+		if assert.NotNil(t, profile.Website) {
+			assert.Equal(t, "https://bob.example.com", *profile.Website)
+		}
+		assert.Equal(t, map[string]string{"github": "https://github.com/bob"}, profile.SocialLinks)
+		assert.Equal(t, wantProjects, profile.PublicProjects)
+	}
+
+	// updates replace the whole profile
+	profile, err = s.UpdateProfile(ctx, bob.ID, data.UserProfileUpdate{Bio: "Draws squares"})
+	assert.NoError(t, err)
+	if assert.NotNil(t, profile) {
+		assert.Nil(t, profile.Website)
+		assert.Empty(t, profile.SocialLinks)
+	}
+
+	// users who never activated their account have no profile
+	_, err = s.GetProfile(ctx, testData.Users[UserJohn].Username)
+	assert.ErrorIs(t, err, services.ErrUserNotFound)
+
+	_, err = s.GetProfile(ctx, "nobody")
+	assert.ErrorIs(t, err, services.ErrUserNotFound)
+}
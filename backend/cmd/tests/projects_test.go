@@ -1,11 +1,15 @@
 package tests
 
 import (
+	"NodeTurtleAPI/internal/config"
 	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/ids"
 	"NodeTurtleAPI/internal/services"
 	"NodeTurtleAPI/internal/services/projects"
 	"NodeTurtleAPI/internal/utils"
+	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"testing"
 	"time"
@@ -21,7 +25,7 @@ func setupProjectService() (projects.IProjectService, TestData, func()) {
 		log.Fatalf("Failed setup test data: %v", err)
 	}
 
-	return projects.NewProjectService(db), *testData, func() { db.Close() }
+	return projects.NewProjectService(db, ids.V7, config.QuotasConfig{}), *testData, func() { db.Close() }
 }
 
 func TestCreateProject(t *testing.T) {
@@ -35,11 +39,12 @@ func TestCreateProject(t *testing.T) {
 		IsPublic:    false,
 	}
 
-	project, err := s.CreateProject(p)
+	project, err := s.CreateProject(context.Background(), p)
 
 	assert.NoError(t, err)
-	assert.NotNil(t, project)
-
+	if assert.NotNil(t, project) {
+		assert.Equal(t, uuid.Version(7), project.ID.Version())
+	}
 }
 
 func TestDeleteProject(t *testing.T) {
@@ -62,7 +67,7 @@ func TestDeleteProject(t *testing.T) {
 
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			err := s.DeleteProject(tt.projectID)
+			err := s.DeleteProject(context.Background(), tt.projectID)
 
 			if tt.err != nil {
 				assert.Error(t, err)
@@ -98,7 +103,7 @@ func TestGetProject(t *testing.T) {
 
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			p, err := s.GetProject(tt.projectID, &tt.requestingUserID)
+			p, err := s.GetProject(context.Background(), tt.projectID, &tt.requestingUserID)
 
 			if tt.err != nil {
 				assert.Error(t, err)
@@ -113,31 +118,67 @@ func TestGetProject(t *testing.T) {
 	}
 }
 
+func TestGetProjectsByIDs(t *testing.T) {
+	s, td, close := setupProjectService()
+	defer close()
+
+	alice := td.Users[UserAlice].ID
+	ids := []uuid.UUID{
+		td.Projects[ProjectBobFeatured].ID,
+		td.Projects[ProjectAlicePrivate].ID,
+		uuid.New(),
+		td.Projects[ProjectAlicePublic].ID,
+		td.Projects[ProjectBobPrivate].ID,
+	}
+
+	t.Run("Owner sees own private projects in requested order", func(t *testing.T) {
+		projects, err := s.GetProjectsByIDs(context.Background(), ids, &alice)
+		assert.NoError(t, err)
+		if assert.Len(t, projects, 3) {
+			assert.Equal(t, td.Projects[ProjectBobFeatured].ID, projects[0].ID)
+			assert.Equal(t, td.Projects[ProjectAlicePrivate].ID, projects[1].ID)
+			assert.Equal(t, td.Projects[ProjectAlicePublic].ID, projects[2].ID)
+		}
+	})
+
+	t.Run("Guest only sees public projects", func(t *testing.T) {
+		projects, err := s.GetProjectsByIDs(context.Background(), ids, nil)
+		assert.NoError(t, err)
+		assert.Len(t, projects, 2)
+	})
+}
+
 func TestGetUserProjects(t *testing.T) {
 	s, td, close := setupProjectService()
 	defer close()
 
 	tests := map[string]struct {
 		profileUserID         uuid.UUID
-		requestingUserID      uuid.UUID
+		requestingUserID      *uuid.UUID
 		expectPrivateProjects bool
 		expectedArrayLength   int
 	}{
 		"Requester is the owner of the projects": {
 			profileUserID:         td.Users[UserAlice].ID,
-			requestingUserID:      td.Users[UserAlice].ID,
+			requestingUserID:      utils.Ptr(td.Users[UserAlice].ID),
 			expectPrivateProjects: true,
 			expectedArrayLength:   3,
 		},
 		"Requester is not the owner of the projects": {
 			profileUserID:         td.Users[UserAlice].ID,
-			requestingUserID:      uuid.New(),
+			requestingUserID:      utils.Ptr(uuid.New()),
+			expectPrivateProjects: false,
+			expectedArrayLength:   2,
+		},
+		"Guest": {
+			profileUserID:         td.Users[UserAlice].ID,
+			requestingUserID:      nil,
 			expectPrivateProjects: false,
 			expectedArrayLength:   2,
 		},
 		"No projects found because user does not exist": {
 			profileUserID:         uuid.New(),
-			requestingUserID:      td.Users[UserAlice].ID,
+			requestingUserID:      utils.Ptr(td.Users[UserAlice].ID),
 			expectPrivateProjects: false,
 			expectedArrayLength:   0,
 		},
@@ -145,7 +186,7 @@ func TestGetUserProjects(t *testing.T) {
 
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			p, err := s.GetUserProjects(tt.profileUserID, tt.requestingUserID)
+			p, err := s.GetUserProjects(context.Background(), tt.profileUserID, tt.requestingUserID, nil)
 
 			assert.NoError(t, err)
 			assert.Equal(t, nil, err)
@@ -176,7 +217,7 @@ func TestGetFeaturedProjects(t *testing.T) {
 	page := 1
 	expectedLength := 1
 
-	p, err := s.GetFeaturedProjects(limit, page)
+	p, err := s.GetFeaturedProjects(context.Background(), limit, page)
 
 	if err != nil {
 		assert.Error(t, err)
@@ -196,7 +237,7 @@ func TestGetLikedProjects(t *testing.T) {
 	userID := td.Users[UserBob].ID
 	expectedLength := 4
 
-	p, err := s.GetLikedProjects(userID)
+	p, err := s.GetLikedProjects(context.Background(), userID)
 
 	if err != nil {
 		assert.Error(t, err)
@@ -217,11 +258,11 @@ func TestLikeProject(t *testing.T) {
 	initialLikes := project.LikesCount
 	user := td.Users[UserJohn]
 
-	err := s.LikeProject(project.ID, user.ID)
+	err := s.LikeProject(context.Background(), project.ID, user.ID)
 	assert.NoError(t, err)
 
 	// try liking the project twice
-	err = s.LikeProject(project.ID, user.ID)
+	err = s.LikeProject(context.Background(), project.ID, user.ID)
 	assert.NotNil(t, err)
 
 	if err != nil {
@@ -229,7 +270,7 @@ func TestLikeProject(t *testing.T) {
 	} else {
 		assert.Equal(t, nil, err)
 
-		p, err := s.GetProject(project.ID, &user.ID)
+		p, err := s.GetProject(context.Background(), project.ID, &user.ID)
 
 		assert.NoError(t, err)
 
@@ -246,11 +287,11 @@ func TestUnlikeProject(t *testing.T) {
 	initialLikes := project.LikesCount
 	userID := project.LikedByUsers[0]
 
-	err := s.UnlikeProject(project.ID, userID)
+	err := s.UnlikeProject(context.Background(), project.ID, userID)
 	assert.NoError(t, err)
 
 	// try unliking the project twice
-	err = s.UnlikeProject(project.ID, userID)
+	err = s.UnlikeProject(context.Background(), project.ID, userID)
 	assert.NotNil(t, err)
 
 	if err != nil {
@@ -258,7 +299,7 @@ func TestUnlikeProject(t *testing.T) {
 	} else {
 		assert.Equal(t, nil, err)
 
-		p, err := s.GetProject(project.ID, &userID)
+		p, err := s.GetProject(context.Background(), project.ID, &userID)
 
 		assert.NoError(t, err)
 
@@ -267,6 +308,50 @@ func TestUnlikeProject(t *testing.T) {
 	}
 }
 
+func TestLikedByMeAndLikers(t *testing.T) {
+	s, td, close := setupProjectService()
+	defer close()
+
+	ctx := context.Background()
+	project := td.Projects[ProjectBobFeatured]
+	liker := project.LikedByUsers[0]
+	other := td.Users[UserJohn].ID
+
+	p, err := s.GetProject(ctx, project.ID, &liker)
+	assert.NoError(t, err)
+	if assert.NotNil(t, p.IsLikedByMe) {
+		assert.True(t, *p.IsLikedByMe)
+	}
+
+	p, err = s.GetProject(ctx, project.ID, &other)
+	assert.NoError(t, err)
+	if assert.NotNil(t, p.IsLikedByMe) {
+		assert.False(t, *p.IsLikedByMe)
+	}
+
+	// guests can't like projects, the field is left out for them
+	p, err = s.GetProject(ctx, project.ID, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, p.IsLikedByMe)
+
+	projects, err := s.GetUserProjectSummaries(ctx, project.CreatorID, &liker, nil)
+	assert.NoError(t, err)
+	for _, p := range projects {
+		if p.ID == project.ID && assert.NotNil(t, p.IsLikedByMe) {
+			assert.True(t, *p.IsLikedByMe)
+		}
+	}
+
+	likers, total, err := s.GetLikers(ctx, project.ID, 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, len(project.LikedByUsers), total)
+	assert.Len(t, likers, 1)
+
+	likers, _, err = s.GetLikers(ctx, project.ID, 1, 100)
+	assert.NoError(t, err)
+	assert.Len(t, likers, len(project.LikedByUsers))
+}
+
 func TestUnlikeProject_NotLikedInitially(t *testing.T) {
 	s, td, close := setupProjectService()
 	defer close()
@@ -275,11 +360,11 @@ func TestUnlikeProject_NotLikedInitially(t *testing.T) {
 	initialLikes := project.LikesCount
 	userID := td.Users[UserJohn].ID
 
-	err := s.UnlikeProject(project.ID, userID)
+	err := s.UnlikeProject(context.Background(), project.ID, userID)
 	assert.NotNil(t, err)
 
 	// try unliking the project twice
-	err = s.UnlikeProject(project.ID, userID)
+	err = s.UnlikeProject(context.Background(), project.ID, userID)
 	assert.NotNil(t, err)
 
 	if err != nil {
@@ -287,7 +372,7 @@ func TestUnlikeProject_NotLikedInitially(t *testing.T) {
 	} else {
 		assert.Equal(t, nil, err)
 
-		p, err := s.GetProject(project.ID, &userID)
+		p, err := s.GetProject(context.Background(), project.ID, &userID)
 
 		assert.NoError(t, err)
 
@@ -312,7 +397,7 @@ func TestUpdateProject(t *testing.T) {
 		IsPublic:    &newIsPublic,
 	}
 
-	updatedProject, err := s.UpdateProject(update)
+	updatedProject, err := s.UpdateProject(context.Background(), update)
 	assert.NoError(t, err)
 	assert.NotNil(t, updatedProject)
 	assert.Equal(t, newTitle, updatedProject.Title)
@@ -326,7 +411,7 @@ func TestUpdateProject(t *testing.T) {
 		Description: &newDescription2,
 	}
 
-	updatedProject, err = s.UpdateProject(update)
+	updatedProject, err = s.UpdateProject(context.Background(), update)
 	assert.NoError(t, err)
 	assert.NotNil(t, updatedProject)
 	assert.Equal(t, newTitle, updatedProject.Title) // Title should remain unchanged
@@ -335,7 +420,7 @@ func TestUpdateProject(t *testing.T) {
 
 	// Try updating with no fields (should error)
 	emptyUpdate := data.ProjectUpdate{ID: project.ID}
-	updatedProject, err = s.UpdateProject(emptyUpdate)
+	updatedProject, err = s.UpdateProject(context.Background(), emptyUpdate)
 	assert.Error(t, err)
 	assert.Nil(t, updatedProject)
 	assert.Equal(t, services.ErrNoFields, err)
@@ -343,12 +428,211 @@ func TestUpdateProject(t *testing.T) {
 	// Try updating a non-existent project
 	badID := uuid.New()
 	update.ID = badID
-	updatedProject, err = s.UpdateProject(update)
+	updatedProject, err = s.UpdateProject(context.Background(), update)
 	assert.Error(t, err)
 	assert.Nil(t, updatedProject)
 	assert.Equal(t, services.ErrRecordNotFound, err)
 }
 
+func TestUpdateProjectIfMatch(t *testing.T) {
+	s, td, close := setupProjectService()
+	defer close()
+
+	ctx := context.Background()
+	bob := td.Projects[ProjectBobFeatured].CreatorID
+	project, err := s.GetProject(ctx, td.Projects[ProjectBobFeatured].ID, &bob)
+	assert.NoError(t, err)
+	loaded := project.ETag()
+
+	updated, err := s.UpdateProject(ctx, data.ProjectUpdate{ID: project.ID, Title: utils.Ptr("first writer"), IfMatch: &loaded})
+	assert.NoError(t, err)
+	assert.NotEqual(t, loaded, updated.ETag())
+
+	// a second writer that loaded the same state loses
+	_, err = s.UpdateProject(ctx, data.ProjectUpdate{ID: project.ID, Title: utils.Ptr("second writer"), IfMatch: &loaded})
+	assert.ErrorIs(t, err, services.ErrEditConflict)
+
+	stored, err := s.GetProject(ctx, project.ID, &project.CreatorID)
+	assert.NoError(t, err)
+	assert.Equal(t, "first writer", stored.Title)
+	assert.Equal(t, updated.ETag(), stored.ETag())
+
+	_, err = s.UpdateProject(ctx, data.ProjectUpdate{ID: uuid.New(), Title: utils.Ptr("missing"), IfMatch: &loaded})
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+}
+
+func TestUpdateProjectDryRun(t *testing.T) {
+	s, td, close := setupProjectService()
+	defer close()
+
+	project := td.Projects[ProjectAlicePrivate]
+	newTitle := "Dry Run Title"
+
+	preview, err := s.UpdateProject(context.Background(), data.ProjectUpdate{ID: project.ID, Title: &newTitle, DryRun: true})
+	assert.NoError(t, err)
+	assert.Equal(t, newTitle, preview.Title)
+
+	// nothing is persisted
+	stored, err := s.GetProject(context.Background(), project.ID, &project.CreatorID)
+	assert.NoError(t, err)
+	assert.Equal(t, project.Title, stored.Title)
+
+	created, err := s.CreateProject(context.Background(), data.ProjectCreate{Title: "Dry Run Project", CreatorID: project.CreatorID, Data: json.RawMessage(`{}`), DryRun: true})
+	assert.NoError(t, err)
+
+	_, err = s.GetProject(context.Background(), created.ID, &project.CreatorID)
+	assert.Equal(t, services.ErrRecordNotFound, err)
+}
+
+func TestSaveProjectData(t *testing.T) {
+	s, td, close := setupProjectService()
+	defer close()
+
+	project := td.Projects[ProjectAlicePrivate]
+	flowData := json.RawMessage(`{"nodes":[],"edges":[]}`)
+
+	version, err := s.SaveProjectData(context.Background(), project.ID, flowData, 1, project.CreatorID, "tab-1")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, version)
+
+	save, err := s.GetLastSave(context.Background(), project.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, project.CreatorID, save.UserID)
+	assert.Equal(t, project.CreatorUsername, save.Username)
+	assert.Equal(t, "tab-1", save.Session)
+
+	// a second tab still at version 1
+	version, err = s.SaveProjectData(context.Background(), project.ID, flowData, 1, project.CreatorID, "tab-2")
+	assert.ErrorIs(t, err, services.ErrEditConflict)
+	assert.Equal(t, 2, version)
+
+	// the rejected save doesn't count as the last one
+	save, err = s.GetLastSave(context.Background(), project.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "tab-1", save.Session)
+
+	// full updates of the flow move the version too
+	updated, err := s.UpdateProject(context.Background(), data.ProjectUpdate{ID: project.ID, Data: json.RawMessage(`{"nodes":[],"edges":[],"viewport":{}}`)})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, updated.Version)
+
+	// and aren't autosaves
+	_, err = s.GetLastSave(context.Background(), project.ID)
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+
+	_, err = s.SaveProjectData(context.Background(), uuid.New(), flowData, 1, project.CreatorID, "")
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+}
+
+func TestProjectRevisions(t *testing.T) {
+	s, td, close := setupProjectService()
+	defer close()
+
+	project := td.Projects[ProjectAlicePrivate]
+	flow := func(label string) json.RawMessage {
+		return json.RawMessage(`{"nodes":[{"id":"1","type":"start","data":{"label":"` + label + `"}}],"edges":[]}`)
+	}
+
+	// only flow changes are kept
+	newTitle := "Renamed"
+	_, err := s.UpdateProject(context.Background(), data.ProjectUpdate{ID: project.ID, Title: &newTitle})
+	assert.NoError(t, err)
+	revisions, err := s.ListRevisions(context.Background(), project.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, revisions)
+
+	_, err = s.UpdateProject(context.Background(), data.ProjectUpdate{ID: project.ID, Data: flow("v2")})
+	assert.NoError(t, err)
+	_, err = s.UpdateProject(context.Background(), data.ProjectUpdate{ID: project.ID, Data: flow("v2")})
+	assert.NoError(t, err)
+
+	revisions, err = s.ListRevisions(context.Background(), project.ID)
+	assert.NoError(t, err)
+	assert.Len(t, revisions, 1)
+	assert.Equal(t, "Renamed", revisions[0].Title)
+	assert.Nil(t, revisions[0].Data)
+
+	revision, err := s.GetRevision(context.Background(), project.ID, revisions[0].ID)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(project.Data), string(revision.Data))
+
+	_, err = s.GetRevision(context.Background(), td.Projects[ProjectAlicePublic].ID, revisions[0].ID)
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+
+	// a dry run stores nothing
+	_, err = s.UpdateProject(context.Background(), data.ProjectUpdate{ID: project.ID, Data: flow("dry"), DryRun: true})
+	assert.NoError(t, err)
+
+	// the oldest revisions are dropped beyond the limit of the owner's role
+	for i := 0; i < data.RevisionLimit(data.RoleUser)+2; i++ {
+		_, err = s.UpdateProject(context.Background(), data.ProjectUpdate{ID: project.ID, Data: flow(fmt.Sprint(i))})
+		assert.NoError(t, err)
+	}
+	revisions, err = s.ListRevisions(context.Background(), project.ID)
+	assert.NoError(t, err)
+	assert.Len(t, revisions, data.RevisionLimit(data.RoleUser))
+}
+
+func TestExportProject(t *testing.T) {
+	s, td, close := setupProjectService()
+	defer close()
+
+	project := td.Projects[ProjectAlicePrivate]
+	flow := func(label string) json.RawMessage {
+		return json.RawMessage(`{"nodes":[{"id":"1","type":"start","data":{"label":"` + label + `"}}],"edges":[]}`)
+	}
+	for i := 0; i < 2; i++ {
+		_, err := s.UpdateProject(context.Background(), data.ProjectUpdate{ID: project.ID, Data: flow(fmt.Sprint(i))})
+		assert.NoError(t, err)
+	}
+
+	export, err := s.ExportProject(context.Background(), project.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, data.ProjectArchiveFormat, export.Format)
+	assert.Equal(t, project.Title, export.Title)
+	assert.JSONEq(t, string(flow("1")), string(export.Data))
+	if assert.Len(t, export.Revisions, 2) {
+		assert.JSONEq(t, string(flow("0")), string(export.Revisions[1].Data))
+	}
+
+	// importing recreates the project with its revisions in the same order
+	imported, err := s.CreateProject(context.Background(), data.ProjectCreate{
+		Title:     export.Title,
+		CreatorID: td.Users[UserBob].ID,
+		Data:      export.Data,
+		Revisions: export.Revisions,
+	})
+	assert.NoError(t, err)
+	reexport, err := s.ExportProject(context.Background(), imported.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, reexport.Revisions, 2) {
+		assert.JSONEq(t, string(export.Revisions[1].Data), string(reexport.Revisions[1].Data))
+		assert.WithinDuration(t, export.Revisions[0].CreatedAt, reexport.Revisions[0].CreatedAt, time.Millisecond)
+	}
+
+	// revisions beyond the limit of the importing user's role are dropped, oldest first
+	many := make([]data.ArchivedRevision, data.RevisionLimit(data.RoleUser)+2)
+	for i := range many {
+		many[i] = data.ArchivedRevision{Title: "Old", Data: flow(fmt.Sprint(i)), CreatedAt: time.Now()}
+	}
+	imported, err = s.CreateProject(context.Background(), data.ProjectCreate{Title: "Many Revisions", CreatorID: td.Users[UserBob].ID, Data: flow("now"), Revisions: many})
+	assert.NoError(t, err)
+	reexport, err = s.ExportProject(context.Background(), imported.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, reexport.Revisions, data.RevisionLimit(data.RoleUser)) {
+		assert.JSONEq(t, string(flow("2")), string(reexport.Revisions[0].Data))
+	}
+
+	_, err = s.ExportProject(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+}
+
+func TestRevisionLimit(t *testing.T) {
+	assert.Equal(t, 10, data.RevisionLimit(data.RoleUser))
+	assert.Equal(t, 100, data.RevisionLimit(data.RolePremium))
+	assert.Equal(t, 10, data.RevisionLimit("unknown"))
+}
+
 func TestIsOwner(t *testing.T) {
 	s, td, close := setupProjectService()
 
@@ -371,7 +655,7 @@ func TestIsOwner(t *testing.T) {
 
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			isOwner, err := s.IsOwner(tt.projectID, tt.userID)
+			isOwner, err := s.IsOwner(context.Background(), tt.projectID, tt.userID)
 
 			assert.NoError(t, err)
 			assert.Equal(t, tt.isOwner, isOwner)
@@ -381,6 +665,86 @@ func TestIsOwner(t *testing.T) {
 	defer close()
 }
 
+func TestGetPublicProjectsAfter(t *testing.T) {
+	s, _, close := setupProjectService()
+	defer close()
+
+	ctx := context.Background()
+	filters := data.PublicProjectFilter{SortField: "created_at", SortOrder: "desc", Limit: 10}
+	all, _, err := s.GetPublicProjects(ctx, data.PublicProjectFilter{SortField: "created_at", SortOrder: "desc", Page: 1, Limit: 100})
+	assert.NoError(t, err)
+
+	// walking the pages visits every public project once, newest first
+	filters.Limit = 2
+	var walked []data.Project
+	var cursor *data.ProjectCursor
+	for pages := 0; pages <= len(all); pages++ {
+		var page []data.Project
+		page, cursor, err = s.GetPublicProjectsAfter(ctx, filters, cursor)
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, len(page), 2)
+		walked = append(walked, page...)
+		if cursor == nil {
+			break
+		}
+	}
+	assert.Nil(t, cursor)
+	if assert.Len(t, walked, len(all)) {
+		for i := 1; i < len(walked); i++ {
+			assert.False(t, walked[i].CreatedAt.After(walked[i-1].CreatedAt))
+		}
+	}
+
+	// oldest first
+	filters.SortOrder = "asc"
+	filters.Limit = 1
+	page, next, err := s.GetPublicProjectsAfter(ctx, filters, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, page, 1) && assert.NotNil(t, next) {
+		assert.Equal(t, walked[len(walked)-1].ID, page[0].ID)
+	}
+
+	featured, next, err := s.GetFeaturedProjectsAfter(ctx, 5, nil)
+	assert.NoError(t, err)
+	assert.Len(t, featured, 1)
+	assert.Nil(t, next)
+}
+
+func TestProjectSummaries(t *testing.T) {
+	s, td, close := setupProjectService()
+	defer close()
+
+	ctx := context.Background()
+	filters := data.PublicProjectFilter{SortField: "created_at", SortOrder: "desc", Page: 1, Limit: 100}
+
+	full, total, err := s.GetPublicProjects(ctx, filters)
+	assert.NoError(t, err)
+	summaries, summaryTotal, err := s.GetPublicProjectSummaries(ctx, filters)
+	assert.NoError(t, err)
+	assert.Equal(t, total, summaryTotal)
+	if assert.Len(t, summaries, len(full)) {
+		for i := range summaries {
+			assert.Equal(t, full[i].ID, summaries[i].ID)
+			assert.NotEmpty(t, full[i].Data)
+			assert.Nil(t, summaries[i].Data)
+		}
+	}
+
+	alice := td.Users[UserAlice].ID
+	owned, err := s.GetUserProjectSummaries(ctx, alice, &alice, nil)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, owned)
+	for _, p := range owned {
+		assert.Nil(t, p.Data)
+	}
+
+	featured, err := s.GetFeaturedProjectSummaries(ctx, 10, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, featured, 1) {
+		assert.Nil(t, featured[0].Data)
+	}
+}
+
 func TestGetPublicProjects(t *testing.T) {
 	s, td, close := setupProjectService()
 	defer close()
@@ -471,7 +835,7 @@ func TestGetPublicProjects(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			projects, total, err := s.GetPublicProjects(tt.filters)
+			projects, total, err := s.GetPublicProjects(context.Background(), tt.filters)
 			assert.NoError(t, err)
 			assert.Equal(t, tt.expectedTotal, total)
 			assert.Equal(t, len(tt.expectedTitles), len(projects))
@@ -483,6 +847,448 @@ func TestGetPublicProjects(t *testing.T) {
 	}
 }
 
+func TestRecordViewAndTrending(t *testing.T) {
+	s, td, close := setupProjectService()
+	defer close()
+
+	project := td.Projects[ProjectJohnUnactivated]
+	alice := td.Users[UserAlice].ID
+
+	// the same viewer is only counted once per day
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, s.RecordView(context.Background(), project.ID, &alice, "10.0.0.1"))
+		assert.NoError(t, s.RecordView(context.Background(), project.ID, nil, "10.0.0.1"))
+	}
+
+	p, err := s.GetProject(context.Background(), project.ID, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, p.ViewsCount)
+
+	// enough fresh views outweigh the likes of the most liked project
+	for i := 0; i < 30; i++ {
+		assert.NoError(t, s.RecordView(context.Background(), project.ID, nil, fmt.Sprintf("10.0.1.%d", i)))
+	}
+
+	projects, total, err := s.GetPublicProjects(context.Background(), data.PublicProjectFilter{
+		SortField: "trending",
+		SortOrder: "desc",
+		Page:      1,
+		Limit:     2,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 7, total)
+	if assert.Len(t, projects, 2) {
+		assert.Equal(t, project.ID, projects[0].ID)
+		assert.Equal(t, 32, projects[0].ViewsCount)
+		assert.Equal(t, td.Projects[ProjectMultiLiked].ID, projects[1].ID)
+	}
+}
+
+func TestDiscoverProjects(t *testing.T) {
+	s, td, close := setupProjectService()
+	defer close()
+
+	ctx := context.Background()
+	alice := td.Users[UserAlice].ID
+	opts := data.DiscoverOptions{UserID: &alice, IP: "10.0.0.1", Limit: 100, MinLikes: 1, SeenDays: 30}
+
+	projects, err := s.DiscoverProjects(ctx, opts)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, projects)
+	for _, p := range projects {
+		assert.True(t, p.IsPublic)
+		assert.GreaterOrEqual(t, p.LikesCount, 1)
+		assert.NotEqual(t, alice, p.CreatorID)
+		assert.Nil(t, p.Data)
+	}
+
+	// nothing turns up again once seen
+	for _, p := range projects {
+		assert.NoError(t, s.RecordView(ctx, p.ID, &alice, "10.0.0.1"))
+	}
+	seen, err := s.DiscoverProjects(ctx, opts)
+	assert.NoError(t, err)
+	assert.Empty(t, seen)
+
+	// other viewers still get them
+	guest, err := s.DiscoverProjects(ctx, data.DiscoverOptions{IP: "10.0.0.2", Limit: 100, MinLikes: 1, SeenDays: 30})
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, len(guest), len(projects))
+}
+
+func TestPublishedSnapshots(t *testing.T) {
+	s, td, close := setupProjectService()
+	defer close()
+
+	ctx := context.Background()
+	alice := td.Users[UserAlice].ID
+	bob := td.Projects[ProjectBobFeatured].CreatorID
+	project, err := s.CreateProject(ctx, data.ProjectCreate{Title: "draft", Data: json.RawMessage(`{"nodes":[1]}`), CreatorID: alice})
+	assert.NoError(t, err)
+
+	// never published, embeds follow the project
+	embedded, err := s.GetEmbeddedProject(ctx, project.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, embedded.PublishedAt)
+
+	_, err = s.UpdateProject(ctx, data.ProjectUpdate{ID: project.ID, IsPublic: utils.Ptr(true)})
+	assert.NoError(t, err)
+
+	// editing after making it public changes neither embeds nor forks
+	_, err = s.UpdateProject(ctx, data.ProjectUpdate{ID: project.ID, Title: utils.Ptr("edited"), Data: json.RawMessage(`{"nodes":[1,2]}`)})
+	assert.NoError(t, err)
+
+	embedded, err = s.GetEmbeddedProject(ctx, project.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, embedded.PublishedAt)
+	assert.Equal(t, "draft", embedded.Title)
+	assert.JSONEq(t, `{"nodes":[1]}`, string(embedded.Data))
+
+	fork, err := s.ForkProject(ctx, project.ID, bob)
+	assert.NoError(t, err)
+	assert.Equal(t, "draft", fork.Title)
+	assert.JSONEq(t, `{"nodes":[1]}`, string(fork.Data))
+
+	// the owner forks their current version
+	own, err := s.ForkProject(ctx, project.ID, alice)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"nodes":[1,2]}`, string(own.Data))
+
+	// making it public again keeps the snapshot, publishing replaces it
+	_, err = s.UpdateProject(ctx, data.ProjectUpdate{ID: project.ID, IsPublic: utils.Ptr(true)})
+	assert.NoError(t, err)
+	embedded, err = s.GetEmbeddedProject(ctx, project.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "draft", embedded.Title)
+
+	snapshot, err := s.PublishProject(ctx, project.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "edited", snapshot.Title)
+
+	embedded, err = s.GetEmbeddedProject(ctx, project.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "edited", embedded.Title)
+	assert.Equal(t, snapshot.Version, embedded.Version)
+	assert.JSONEq(t, `{"nodes":[1,2]}`, string(embedded.Data))
+
+	_, err = s.PublishProject(ctx, uuid.New())
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+}
+
+func TestMetadataDraft(t *testing.T) {
+	s, td, close := setupProjectService()
+	defer close()
+
+	ctx := context.Background()
+	projectID := td.Projects[ProjectAlicePublic].ID
+	project, err := s.GetProject(ctx, projectID, nil)
+	assert.NoError(t, err)
+
+	_, err = s.GetMetadataDraft(ctx, projectID)
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+
+	// fields left out come from the project
+	draft, err := s.SaveMetadataDraft(ctx, projectID, data.ProjectMetadataDraftUpdate{Title: utils.Ptr("staged")})
+	assert.NoError(t, err)
+	assert.Equal(t, "staged", draft.Title)
+	assert.Equal(t, project.Description, draft.Description)
+
+	// and later from the draft
+	draft, err = s.SaveMetadataDraft(ctx, projectID, data.ProjectMetadataDraftUpdate{Tags: []string{" Loops", "loops", "Turtle"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "staged", draft.Title)
+	assert.Equal(t, []string{"loops", "turtle"}, draft.Tags)
+
+	unchanged, err := s.GetProject(ctx, projectID, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, project.Title, unchanged.Title)
+	assert.Empty(t, unchanged.Tags)
+
+	snapshot, err := s.PublishProject(ctx, projectID)
+	assert.NoError(t, err)
+	assert.Equal(t, "staged", snapshot.Title)
+	assert.Equal(t, []string{"loops", "turtle"}, snapshot.Tags)
+
+	published, err := s.GetProject(ctx, projectID, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "staged", published.Title)
+	assert.Equal(t, []string{"loops", "turtle"}, published.Tags)
+
+	_, err = s.GetMetadataDraft(ctx, projectID)
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+
+	_, err = s.SaveMetadataDraft(ctx, projectID, data.ProjectMetadataDraftUpdate{Description: utils.Ptr("later")})
+	assert.NoError(t, err)
+	assert.NoError(t, s.DiscardMetadataDraft(ctx, projectID))
+	assert.ErrorIs(t, s.DiscardMetadataDraft(ctx, projectID), services.ErrRecordNotFound)
+
+	_, err = s.SaveMetadataDraft(ctx, uuid.New(), data.ProjectMetadataDraftUpdate{Title: utils.Ptr("staged")})
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+}
+
+func TestProjectAccessibility(t *testing.T) {
+	s, td, close := setupProjectService()
+	defer close()
+
+	ctx := context.Background()
+	alice := td.Users[UserAlice].ID
+	projectID := td.Projects[ProjectAlicePublic].ID
+
+	project, err := s.GetProject(ctx, projectID, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, project.Accessibility)
+
+	described, err := s.SetAccessibility(ctx, projectID, data.ProjectAccessibilityUpdate{ThumbnailAlt: utils.Ptr("A green spiral")})
+	assert.NoError(t, err)
+	assert.Equal(t, "A green spiral", described.ThumbnailAlt)
+
+	// fields left out keep their value
+	described, err = s.SetAccessibility(ctx, projectID, data.ProjectAccessibilityUpdate{OutputDescription: utils.Ptr("A spiral of 36 lines")})
+	assert.NoError(t, err)
+	assert.Equal(t, "A green spiral", described.ThumbnailAlt)
+	assert.Equal(t, "A spiral of 36 lines", described.OutputDescription)
+
+	project, err = s.GetProject(ctx, projectID, nil)
+	assert.NoError(t, err)
+	if assert.NotNil(t, project.Accessibility) {
+		assert.Equal(t, "A spiral of 36 lines", project.Accessibility.OutputDescription)
+	}
+
+	embedded, err := s.GetEmbeddedProject(ctx, projectID)
+	assert.NoError(t, err)
+	assert.NotNil(t, embedded.Accessibility)
+
+	summaries, err := s.GetUserProjectSummaries(ctx, alice, nil, nil)
+	assert.NoError(t, err)
+	for _, p := range summaries {
+		assert.Equal(t, p.ID == projectID, p.Accessibility != nil)
+	}
+
+	_, err = s.SetAccessibility(ctx, uuid.New(), data.ProjectAccessibilityUpdate{ThumbnailAlt: utils.Ptr("A green spiral")})
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+}
+
+func TestCheckTitle(t *testing.T) {
+	s, td, close := setupProjectService()
+	defer close()
+
+	ctx := context.Background()
+	alice := td.Users[UserAlice].ID
+	project := td.Projects[ProjectAlicePublic]
+
+	conflict, err := s.CheckTitle(ctx, alice, "alice's PUBLIC project", nil)
+	assert.NoError(t, err)
+	if assert.NotNil(t, conflict) {
+		assert.Equal(t, []string{"alice's PUBLIC project (2)", "alice's PUBLIC project (3)", "alice's PUBLIC project (4)"}, conflict.Suggestions)
+	}
+
+	// a project keeping its own title
+	conflict, err = s.CheckTitle(ctx, alice, project.Title, &project.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, conflict)
+
+	// titles of other creators don't count
+	conflict, err = s.CheckTitle(ctx, td.Users[UserBob].ID, project.Title, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, conflict)
+
+	// suggestions skip numbered titles already taken
+	_, err = s.CreateProject(ctx, data.ProjectCreate{Title: project.Title + " (2)", CreatorID: alice, Data: json.RawMessage(`{}`)})
+	assert.NoError(t, err)
+	conflict, err = s.CheckTitle(ctx, alice, project.Title+" (2)", nil)
+	assert.NoError(t, err)
+	if assert.NotNil(t, conflict) {
+		assert.Equal(t, []string{project.Title + " (3)", project.Title + " (4)", project.Title + " (5)"}, conflict.Suggestions)
+	}
+}
+
+func TestRecentProjects(t *testing.T) {
+	s, td, close := setupProjectService()
+	defer close()
+
+	alice := td.Users[UserAlice].ID
+	opened := []uuid.UUID{
+		td.Projects[ProjectAlicePublic].ID,
+		td.Projects[ProjectBobPrivate].ID,
+		td.Projects[ProjectAlicePrivate].ID,
+		td.Projects[ProjectMultiLiked].ID,
+		td.Projects[ProjectAlicePublic].ID,
+	}
+	for _, id := range opened {
+		assert.NoError(t, s.RecordOpen(context.Background(), id, alice))
+	}
+
+	recent, err := s.GetRecentProjects(context.Background(), alice, 10)
+	assert.NoError(t, err)
+	// reopening moves a project to the front, projects alice can't see are left out
+	if assert.Len(t, recent, 3) {
+		assert.Equal(t, td.Projects[ProjectAlicePublic].ID, recent[0].ID)
+		assert.Equal(t, td.Projects[ProjectMultiLiked].ID, recent[1].ID)
+		assert.Equal(t, td.Projects[ProjectAlicePrivate].ID, recent[2].ID)
+	}
+
+	recent, err = s.GetRecentProjects(context.Background(), alice, 1)
+	assert.NoError(t, err)
+	assert.Len(t, recent, 1)
+}
+
+func TestProjectMembers(t *testing.T) {
+	s, td, close := setupProjectService()
+	defer close()
+
+	ctx := context.Background()
+	projectID := td.Projects[ProjectAlicePrivate].ID
+	alice := td.Users[UserAlice].ID
+	bob := td.Users[UserBob].ID
+
+	_, err := s.GetProject(ctx, projectID, &bob)
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+
+	member, err := s.AddMember(ctx, projectID, data.ProjectMemberInvite{Email: "bob@example.com", Role: data.ProjectViewer})
+	assert.NoError(t, err)
+	assert.Equal(t, bob, member.UserID)
+	assert.Equal(t, data.ProjectViewer, member.Role)
+
+	// private projects become visible to members
+	_, err = s.GetProject(ctx, projectID, &bob)
+	assert.NoError(t, err)
+
+	role, err := s.GetProjectRole(ctx, projectID, bob)
+	assert.NoError(t, err)
+	assert.Equal(t, data.ProjectViewer, role)
+
+	// inviting again changes the role
+	_, err = s.AddMember(ctx, projectID, data.ProjectMemberInvite{Email: "bob@example.com", Role: data.ProjectEditor})
+	assert.NoError(t, err)
+	role, err = s.GetProjectRole(ctx, projectID, bob)
+	assert.NoError(t, err)
+	assert.Equal(t, data.ProjectEditor, role)
+
+	role, err = s.GetProjectRole(ctx, projectID, alice)
+	assert.NoError(t, err)
+	assert.Equal(t, data.ProjectOwner, role)
+
+	role, err = s.GetProjectRole(ctx, projectID, td.Users[UserTom].ID)
+	assert.NoError(t, err)
+	assert.Empty(t, role)
+
+	// the owner and unknown users can't be invited
+	_, err = s.AddMember(ctx, projectID, data.ProjectMemberInvite{Email: "alice@example.com", Role: data.ProjectEditor})
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+	_, err = s.AddMember(ctx, projectID, data.ProjectMemberInvite{Email: "nobody@example.com", Role: data.ProjectEditor})
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+
+	members, err := s.GetMembers(ctx, projectID)
+	assert.NoError(t, err)
+	if assert.Len(t, members, 1) {
+		assert.Equal(t, td.Users[UserBob].Username, members[0].Username)
+	}
+
+	assert.NoError(t, s.RemoveMember(ctx, projectID, bob))
+	assert.ErrorIs(t, s.RemoveMember(ctx, projectID, bob), services.ErrRecordNotFound)
+
+	_, err = s.GetProject(ctx, projectID, &bob)
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+}
+
+func TestCompactProjectData(t *testing.T) {
+	s, td, close := setupProjectService()
+	defer close()
+
+	ctx := context.Background()
+	crufty := `"{\"nodes\":[{\"id\":\"a\",\"type\":\"startNode\",\"position\":{\"x\":0,\"y\":0},\"selected\":true,\"measured\":{\"width\":150,\"height\":40}}],\"edges\":[{\"id\":\"e1\",\"source\":\"a\",\"target\":\"deleted\"}],\"nodeCount\":2}"`
+	canonical := `"{\"edges\":[],\"nodeCount\":1,\"nodes\":[{\"id\":\"a\",\"position\":{\"x\":0,\"y\":0},\"type\":\"startNode\"}]}"`
+	project, err := s.CreateProject(ctx, data.ProjectCreate{
+		Title:     "crufty",
+		Data:      json.RawMessage(crufty),
+		CreatorID: td.Users[UserAlice].ID,
+	})
+	assert.NoError(t, err)
+
+	compacted := func(report *data.CompactionReport) *data.CompactedProject {
+		for _, p := range report.Projects {
+			if p.ID == project.ID {
+				return &p
+			}
+		}
+		return nil
+	}
+
+	// a dry run reports the change without writing it
+	report, err := s.CompactProjectData(ctx, data.CompactionOptions{DryRun: true, BatchSize: 2})
+	assert.NoError(t, err)
+	assert.True(t, report.DryRun)
+	if p := compacted(report); assert.NotNil(t, p) {
+		assert.Equal(t, len(crufty), p.BytesBefore)
+		assert.Equal(t, len(canonical), p.BytesAfter)
+	}
+	stored, err := s.GetProject(ctx, project.ID, &project.CreatorID)
+	assert.NoError(t, err)
+	assert.JSONEq(t, crufty, string(stored.Data))
+
+	report, err = s.CompactProjectData(ctx, data.CompactionOptions{BatchSize: 2})
+	assert.NoError(t, err)
+	assert.NotNil(t, compacted(report))
+	assert.Greater(t, report.BytesBefore, report.BytesAfter)
+
+	// the version is kept, so open editors don't run into edit conflicts
+	stored, err = s.GetProject(ctx, project.ID, &project.CreatorID)
+	assert.NoError(t, err)
+	assert.JSONEq(t, canonical, string(stored.Data))
+	assert.Equal(t, project.Version, stored.Version)
+
+	// compacted data stays as it is
+	report, err = s.CompactProjectData(ctx, data.CompactionOptions{BatchSize: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, report.Changed)
+}
+
+func TestDataIntegrity(t *testing.T) {
+	td, db, err := createTestData()
+	if err != nil {
+		t.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+	s := projects.NewProjectService(db, ids.V7, config.QuotasConfig{})
+
+	ctx := context.Background()
+	project, err := s.CreateProject(ctx, data.ProjectCreate{
+		Title:     "checked",
+		Data:      json.RawMessage(`"{\"nodes\":[],\"edges\":[]}"`),
+		CreatorID: td.Users[UserAlice].ID,
+	})
+	assert.NoError(t, err)
+
+	report, err := s.ScanDataIntegrity(ctx, data.IntegrityScanOptions{BatchSize: 2})
+	assert.NoError(t, err)
+	assert.Empty(t, report.Corrupted)
+	assert.Greater(t, report.Scanned, 0)
+
+	// data changed behind the API's back no longer matches its checksum
+	_, err = db.Exec("UPDATE projects SET data = '{\"nodes\":[1]}' WHERE id = $1", project.ID)
+	assert.NoError(t, err)
+
+	// it's still served, the mismatch is only counted
+	stored, err := s.GetProject(ctx, project.ID, &project.CreatorID)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"nodes":[1]}`, string(stored.Data))
+	assert.Equal(t, int64(1), s.IntegrityMetrics().ChecksumMismatches)
+
+	report, err = s.ScanDataIntegrity(ctx, data.IntegrityScanOptions{BatchSize: 2})
+	assert.NoError(t, err)
+	if assert.Len(t, report.Corrupted, 1) {
+		assert.Equal(t, project.ID, report.Corrupted[0].ID)
+		assert.Equal(t, "checked", report.Corrupted[0].Title)
+	}
+
+	// saving through the API writes a new checksum
+	_, err = s.SaveProjectData(ctx, project.ID, json.RawMessage(`"{\"nodes\":[]}"`), project.Version, project.CreatorID, "")
+	assert.NoError(t, err)
+	report, err = s.ScanDataIntegrity(ctx, data.IntegrityScanOptions{BatchSize: 2})
+	assert.NoError(t, err)
+	assert.Empty(t, report.Corrupted)
+}
+
 func TestListProjects(t *testing.T) {
 	s, td, close := setupProjectService()
 	defer close()
@@ -584,7 +1390,7 @@ func TestListProjects(t *testing.T) {
 
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			_, _, err := s.ListProjects(tt.filters)
+			_, _, err := s.ListProjects(context.Background(), tt.filters)
 			if tt.expectErr != nil {
 				assert.Error(t, err)
 				assert.Equal(t, tt.expectErr, err)
@@ -594,3 +1400,143 @@ func TestListProjects(t *testing.T) {
 		})
 	}
 }
+
+func TestForkProject(t *testing.T) {
+	s, td, close := setupProjectService()
+	defer close()
+
+	source := td.Projects[ProjectAlicePublic]
+	forker := td.Users[UserBob].ID
+	since := time.Now().Add(-time.Hour)
+
+	fork, err := s.ForkProject(context.Background(), source.ID, forker)
+	assert.NoError(t, err)
+	assert.Equal(t, forker, fork.CreatorID)
+	assert.Equal(t, source.Title, fork.Title)
+	assert.False(t, fork.IsPublic)
+	assert.Equal(t, source.ID, *fork.ForkedFrom)
+
+	parent, err := s.GetProject(context.Background(), source.ID, &forker)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, parent.ForkCount)
+
+	// private forks are only listed for their owner
+	forks, total, err := s.GetForks(context.Background(), source.ID, &forker, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, fork.ID, forks[0].ID)
+
+	forks, total, err = s.GetForks(context.Background(), source.ID, nil, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, total)
+	assert.Len(t, forks, 0)
+
+	_, err = s.ForkProject(context.Background(), uuid.New(), forker)
+	assert.Equal(t, services.ErrRecordNotFound, err)
+
+	// deleting a fork decrements the counter but still counts towards the limit
+	err = s.DeleteProject(context.Background(), fork.ID)
+	assert.NoError(t, err)
+
+	parent, err = s.GetProject(context.Background(), source.ID, &forker)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, parent.ForkCount)
+
+	count, err := s.CountUserForks(context.Background(), forker, since)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestProjectQuotas(t *testing.T) {
+	td, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	alice := td.Users[UserAlice].ID
+	other := td.Projects[ProjectBobFeatured].ID
+
+	var owned int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM projects WHERE creator_id = $1", alice).Scan(&owned))
+
+	// room for a single project more, with small flows
+	quota := config.QuotaConfig{Projects: owned + 1, Bytes: 64}
+	s := projects.NewProjectService(db, ids.V7, config.QuotasConfig{User: quota})
+
+	usage, err := s.GetQuota(ctx, alice)
+	assert.NoError(t, err)
+	assert.Equal(t, data.RoleUser, usage.Role)
+	assert.Equal(t, owned, usage.Projects)
+	assert.Equal(t, owned+1, usage.MaxProjects)
+
+	large := json.RawMessage(`{"nodes":[],"edges":[],"viewport":{"x":0,"y":0,"zoom":1},"padding":"................"}`)
+	var quotaErr *services.QuotaError
+
+	_, err = s.CreateProject(ctx, data.ProjectCreate{Title: "tooLarge", CreatorID: alice, Data: large})
+	if assert.ErrorAs(t, err, &quotaErr) {
+		assert.Equal(t, services.QuotaBytes, quotaErr.Quota)
+	}
+
+	project, err := s.CreateProject(ctx, data.ProjectCreate{Title: "lastOne", CreatorID: alice, Data: json.RawMessage(`{}`)})
+	assert.NoError(t, err)
+
+	_, err = s.CreateProject(ctx, data.ProjectCreate{Title: "oneTooMany", CreatorID: alice, Data: json.RawMessage(`{}`)})
+	if assert.ErrorAs(t, err, &quotaErr) {
+		assert.Equal(t, services.QuotaProjects, quotaErr.Quota)
+		assert.Equal(t, owned+1, quotaErr.Used)
+	}
+
+	_, err = s.ForkProject(ctx, other, alice)
+	assert.ErrorAs(t, err, &quotaErr)
+
+	_, err = s.UpdateProject(ctx, data.ProjectUpdate{ID: project.ID, Data: large})
+	assert.ErrorAs(t, err, &quotaErr)
+
+	_, err = s.SaveProjectData(ctx, project.ID, large, project.Version, alice, "")
+	assert.ErrorAs(t, err, &quotaErr)
+}
+
+func TestStorageQuota(t *testing.T) {
+	td, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	alice := td.Users[UserAlice].ID
+	flow := json.RawMessage(`{"nodes":[],"edges":[],"padding":"................................"}`)
+
+	usage, err := projects.NewProjectService(db, ids.V7, config.QuotasConfig{}).GetQuota(ctx, alice)
+	assert.NoError(t, err)
+	assert.Greater(t, usage.StorageBytes, 0)
+
+	// room for a single flow more
+	s := projects.NewProjectService(db, ids.V7, config.QuotasConfig{User: config.QuotaConfig{Storage: usage.StorageBytes + len(flow) + 10}})
+
+	project, err := s.CreateProject(ctx, data.ProjectCreate{Title: "fits", CreatorID: alice, Data: flow})
+	assert.NoError(t, err)
+
+	after, err := s.GetQuota(ctx, alice)
+	assert.NoError(t, err)
+	assert.Greater(t, after.StorageBytes, usage.StorageBytes)
+	assert.Equal(t, usage.StorageBytes+len(flow)+10, after.MaxStorage)
+
+	var quotaErr *services.QuotaError
+	_, err = s.CreateProject(ctx, data.ProjectCreate{Title: "doesNotFit", CreatorID: alice, Data: flow})
+	if assert.ErrorAs(t, err, &quotaErr) {
+		assert.Equal(t, services.QuotaStorage, quotaErr.Quota)
+	}
+
+	_, err = s.ForkProject(ctx, project.ID, alice)
+	assert.ErrorAs(t, err, &quotaErr)
+
+	// growing a project is limited, shrinking it isn't
+	_, err = s.SaveProjectData(ctx, project.ID, append(flow[:len(flow)-2:len(flow)-2], `................"}`...), project.Version, alice, "")
+	assert.ErrorAs(t, err, &quotaErr)
+
+	_, err = s.SaveProjectData(ctx, project.ID, json.RawMessage(`{}`), project.Version, alice, "")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/database"
+	"NodeTurtleAPI/internal/services/realtime"
+	"context"
+	"encoding/json"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRealtimeEvents(t *testing.T) {
+	td, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	// the stream of alice is connected to the second of two API instances
+	first := realtime.NewRealtimeService(db)
+	second := realtime.NewRealtimeService(db)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go second.Listen(ctx, database.DSN(testDatabaseConfig()))
+
+	alice := td.Users[UserAlice].ID
+	events, unsubscribe := second.Subscribe(alice)
+	bobEvents, unsubscribeBob := second.Subscribe(td.Users[UserBob].ID)
+	defer unsubscribeBob()
+
+	conflict := data.EditConflict{ProjectID: td.Projects[ProjectAlicePrivate].ID, Version: 3, RejectedVersion: 2, Session: "tab-2"}
+
+	// the listener may still be connecting, publish until the event comes through
+	var received data.RealtimeEvent
+	assert.Eventually(t, func() bool {
+		assert.NoError(t, first.Publish(context.Background(), alice, data.EventEditConflict, conflict))
+		select {
+		case received = <-events:
+			return true
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, data.EventEditConflict, received.Type)
+	var got data.EditConflict
+	assert.NoError(t, json.Unmarshal(received.Data, &got))
+	assert.Equal(t, conflict, got)
+
+	// events not matching the schema of their type are rejected before reaching anyone
+	err = first.Publish(context.Background(), alice, data.EventEditConflict, map[string]interface{}{"project_id": conflict.ProjectID, "version": 3, "extra": true})
+	assert.ErrorIs(t, err, realtime.ErrInvalidEvent)
+	err = first.Publish(context.Background(), alice, data.EventEditConflict, data.EditConflict{Version: 3})
+	assert.ErrorIs(t, err, realtime.ErrInvalidEvent)
+	err = first.Publish(context.Background(), alice, "project.unknown", conflict)
+	assert.ErrorIs(t, err, realtime.ErrInvalidEvent)
+
+	// events only reach their user
+	select {
+	case <-bobEvents:
+		t.Error("Expected no event for bob")
+	default:
+	}
+
+	unsubscribe()
+	_, open := <-events
+	assert.False(t, open)
+
+	// closing ends the remaining streams
+	second.Close()
+	_, open = <-bobEvents
+	assert.False(t, open)
+}
@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/renders"
+	"NodeTurtleAPI/internal/services/thumbnails"
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderQueue(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	renderCfg := config.RenderConfig{MaxInstructions: 1000, Timeout: time.Second, Width: 64, Height: 48}
+	workerRenderer := thumbnails.NewThumbnailService(db, renderCfg)
+	renderCfg.Workers = true
+	renderCfg.WorkerWait = 300 * time.Millisecond
+	api := thumbnails.NewThumbnailService(db, renderCfg)
+	s := renders.NewRenderService(db)
+
+	project := &data.Project{ID: testData.Projects[ProjectAlicePublic].ID}
+	assert.NoError(t, db.QueryRow("SELECT version FROM projects WHERE id = $1", project.ID).Scan(&project.Version))
+
+	// without a worker, the API queues the thumbnail and gives up waiting
+	_, err = api.GetThumbnail(ctx, project, data.ThumbnailPNG)
+	assert.ErrorIs(t, err, services.ErrRenderPending)
+	_, err = api.GetThumbnail(ctx, project, data.ThumbnailPNG)
+	assert.ErrorIs(t, err, services.ErrRenderPending)
+
+	status, err := s.GetStatus(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, status.Queue.Pending, "a thumbnail is queued once")
+	assert.Empty(t, status.Workers)
+
+	worker := data.RenderWorker{ID: "render-1-a1b2c3d4", Hostname: "render-1", Concurrency: 2}
+	assert.NoError(t, s.Register(ctx, worker))
+
+	job, err := s.Claim(ctx, worker.ID)
+	assert.NoError(t, err)
+	if !assert.NotNil(t, job) {
+		return
+	}
+	assert.Equal(t, project.ID, job.ProjectID)
+	assert.Equal(t, 1, job.Attempts)
+
+	next, err := s.Claim(ctx, worker.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, next, "a job is claimed by one worker")
+
+	version, err := workerRenderer.RenderProject(ctx, job.ProjectID, job.Format)
+	assert.NoError(t, err)
+	assert.Equal(t, project.Version, version)
+	assert.NoError(t, s.Complete(ctx, job, version))
+
+	thumbnail, err := api.GetThumbnail(ctx, project, data.ThumbnailPNG)
+	assert.NoError(t, err)
+	if assert.NotNil(t, thumbnail) {
+		assert.Equal(t, []byte("\x89PNG"), thumbnail.Content[:4])
+	}
+
+	worker.JobsDone = 1
+	assert.NoError(t, s.Heartbeat(ctx, worker))
+	status, err = s.GetStatus(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, data.RenderQueueStats{}, status.Queue)
+	if assert.Len(t, status.Workers, 1) {
+		assert.Equal(t, int64(1), status.Workers[0].JobsDone)
+	}
+
+	// jobs of workers that stopped reporting are put back in the queue
+	_, err = api.GetThumbnail(ctx, project, data.ThumbnailSVG)
+	assert.ErrorIs(t, err, services.ErrRenderPending)
+	job, err = s.Claim(ctx, worker.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, job)
+	_, err = db.Exec("UPDATE render_workers SET heartbeat_at = NOW() - INTERVAL '1 minute'")
+	assert.NoError(t, err)
+
+	requeued, err := s.RequeueStale(ctx, 30*time.Second, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, requeued)
+	status, err = s.GetStatus(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, status.Queue.Pending)
+	assert.Empty(t, status.Workers)
+
+	// a program that took down every worker it was handed to is given up on
+	_, err = db.Exec("UPDATE render_jobs SET attempts = 3")
+	assert.NoError(t, err)
+	_, err = s.RequeueStale(ctx, 30*time.Second, 3)
+	assert.NoError(t, err)
+	_, err = api.GetThumbnail(ctx, project, data.ThumbnailSVG)
+	assert.ErrorIs(t, err, services.ErrRenderLimit)
+
+	// stopping workers hand their jobs back
+	_, err = db.Exec("UPDATE projects SET version = version + 1 WHERE id = $1", project.ID)
+	assert.NoError(t, err)
+	project.Version++
+	_, err = api.GetThumbnail(ctx, project, data.ThumbnailSVG)
+	assert.ErrorIs(t, err, services.ErrRenderPending)
+	assert.NoError(t, s.Register(ctx, worker))
+	job, err = s.Claim(ctx, worker.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, job)
+	assert.NoError(t, s.Deregister(ctx, worker.ID))
+	status, err = s.GetStatus(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, status.Queue.Pending)
+	assert.Empty(t, status.Workers)
+}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/ids"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/projects"
+	"NodeTurtleAPI/internal/services/reports"
+	"context"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectReports(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	s := reports.NewReportService(db)
+	ps := projects.NewProjectService(db, ids.V7, config.QuotasConfig{})
+	projectID := testData.Projects[ProjectAlicePublic].ID
+	alice := testData.Users[UserAlice].ID
+	bob := testData.Users[UserBob].ID
+	chris := testData.Users[UserChris].ID
+	moderator := testData.Users[UserTom].ID
+
+	report, err := s.ReportProject(ctx, projectID, bob, data.ProjectReportSubmission{Reason: data.ReportSpam, Details: " link farm "})
+	assert.NoError(t, err)
+	assert.Equal(t, data.ReportOpen, report.Status)
+	assert.Equal(t, "link farm", report.Details)
+	assert.Equal(t, testData.Users[UserBob].Username, report.ReporterUsername)
+
+	// one open report per user and project
+	_, err = s.ReportProject(ctx, projectID, bob, data.ProjectReportSubmission{Reason: data.ReportOther})
+	assert.ErrorIs(t, err, services.ErrAlreadyReported)
+
+	dismissed, err := s.ReportProject(ctx, projectID, chris, data.ProjectReportSubmission{Reason: data.ReportHarassment})
+	assert.NoError(t, err)
+
+	open, err := s.ListOpenReports(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, open, 2) {
+		assert.Equal(t, report.ID, open[0].ID)
+	}
+
+	dismissed, err = s.DismissReport(ctx, dismissed.ID, moderator)
+	assert.NoError(t, err)
+	assert.Equal(t, data.ReportDismissed, dismissed.Status)
+	assert.Equal(t, moderator, *dismissed.ResolvedBy)
+	_, err = s.DismissReport(ctx, dismissed.ID, moderator)
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+
+	takedown, err := s.TakeDownProject(ctx, projectID, moderator, "Spam")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, takedown.ReportsResolved)
+	assert.Equal(t, testData.Users[UserAlice].Username, takedown.CreatorUsername)
+
+	_, err = s.TakeDownProject(ctx, projectID, moderator, "Spam")
+	assert.ErrorIs(t, err, services.ErrAlreadyTakenDown)
+
+	report, err = s.GetReport(ctx, report.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, data.ReportActioned, report.Status)
+
+	open, err = s.ListOpenReports(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, open)
+
+	// the project stays public but only its creator can see it
+	_, err = ps.GetProject(ctx, projectID, &bob)
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+	_, err = ps.GetProject(ctx, projectID, nil)
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+	project, err := ps.GetProject(ctx, projectID, &alice)
+	assert.NoError(t, err)
+	assert.True(t, project.IsPublic)
+	assert.NotNil(t, project.HiddenAt)
+
+	public, _, err := ps.GetPublicProjects(ctx, data.PublicProjectFilter{Page: 1, Limit: 100})
+	assert.NoError(t, err)
+	for _, p := range public {
+		assert.NotEqual(t, projectID, p.ID)
+	}
+}
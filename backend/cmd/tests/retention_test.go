@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services/retention"
+	"context"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetentionPurge(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec("DELETE FROM retention_runs")
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	alice := testData.Users[UserAlice].ID
+	project := testData.Projects[ProjectAlicePublic].ID
+
+	_, err = db.Exec(`INSERT INTO trusted_locations (user_id, country, last_seen_at) VALUES
+		($1, 'DE', NOW() - INTERVAL '400 days'), ($1, 'PL', NOW())`, alice)
+	assert.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO project_views (project_id, viewer, viewed_on) VALUES
+		($1, 'a', CURRENT_DATE - 40), ($1, 'b', CURRENT_DATE - 35), ($1, 'c', CURRENT_DATE)`, project)
+	assert.NoError(t, err)
+
+	// a batch size of 1 makes every class take several statements
+	s := retention.NewRetentionService(db, config.RetentionConfig{BatchSize: 1, LoginHistory: 365, ProjectViews: 30}, config.PartitionsConfig{AuditRetention: 24})
+
+	runs, err := s.Purge(ctx, data.RetentionManual, &alice)
+	assert.NoError(t, err)
+	// taken down projects are kept forever
+	if assert.Len(t, runs, 2) {
+		assert.Equal(t, data.RetentionLoginHistory, runs[0].Class)
+		assert.Equal(t, int64(1), runs[0].Deleted)
+		assert.Equal(t, data.RetentionProjectViews, runs[1].Class)
+		assert.Equal(t, int64(2), runs[1].Deleted)
+	}
+
+	var views int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM project_views WHERE project_id = $1", project).Scan(&views))
+	assert.Equal(t, 1, views)
+
+	classes, err := s.Classes(ctx)
+	assert.NoError(t, err)
+	for _, c := range classes {
+		switch c.Name {
+		case data.RetentionProjectViews:
+			if assert.NotNil(t, c.LastRun) {
+				assert.Equal(t, alice, *c.LastRun.ActorID)
+			}
+		case data.RetentionTakenDownProjects:
+			assert.Nil(t, c.LastRun)
+		case data.RetentionAuditLogs:
+			assert.Equal(t, 24, c.Months)
+		}
+	}
+
+	history, err := s.ListRuns(ctx, data.RetentionLoginHistory, 10)
+	assert.NoError(t, err)
+	assert.Len(t, history, 1)
+}
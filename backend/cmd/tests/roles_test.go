@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services/roles"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRoleService() (roles.IRoleService, func()) {
+	_, db, err := createTestData()
+
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+
+	return roles.NewRoleService(db), func() { db.Close() }
+}
+
+func TestHasPermission(t *testing.T) {
+	s, close := setupRoleService()
+	defer close()
+
+	tests := map[string]struct {
+		role       data.RoleType
+		permission data.Permission
+		want       bool
+	}{
+		"Admin can delete users": {
+			role:       data.RoleAdmin,
+			permission: data.PermUsersDelete,
+			want:       true,
+		},
+		"Moderator can ban users": {
+			role:       data.RoleModerator,
+			permission: data.PermUsersBan,
+			want:       true,
+		},
+		"Moderator can't delete users": {
+			role:       data.RoleModerator,
+			permission: data.PermUsersDelete,
+			want:       false,
+		},
+		"User can't feature projects": {
+			role:       data.RoleUser,
+			permission: data.PermProjectsFeature,
+			want:       false,
+		},
+		"Unknown permission": {
+			role:       data.RoleAdmin,
+			permission: data.Permission("projects.unknown"),
+			want:       false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			granted, err := s.HasPermission(tt.role.ToID(), tt.permission)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, granted)
+		})
+	}
+}
+
+func TestGetPermissions(t *testing.T) {
+	s, close := setupRoleService()
+	defer close()
+
+	permissions, err := s.GetPermissions(data.RoleModerator.ToID())
+	assert.NoError(t, err)
+	assert.Equal(t, []data.Permission{data.PermProjectsFeature, data.PermProjectsRead, data.PermUsersBan, data.PermUsersRead}, permissions)
+
+	permissions, err = s.GetPermissions(data.RoleUser.ToID())
+	assert.NoError(t, err)
+	assert.Empty(t, permissions)
+}
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/ids"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/projects"
+	"NodeTurtleAPI/internal/services/runs"
+	"context"
+	"encoding/json"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// square draws a square with sides of 50, in 32 steps.
+const square = `{"nodes":[
+	{"id":"start","type":"startNode","data":{}},
+	{"id":"loop","type":"loopNode","data":{"loopCount":4}},
+	{"id":"move","type":"moveNode","data":{"distance":50}},
+	{"id":"turn","type":"rotateNode","data":{"angle":90}}
+],"edges":[
+	{"source":"start","target":"loop"},
+	{"source":"loop","sourceHandle":"loop","target":"move"},
+	{"source":"move","target":"turn"}
+]}`
+
+func TestRunMetering(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	alice := testData.Users[UserAlice]
+	quotas := config.QuotasConfig{User: config.QuotaConfig{RunSteps: 1000, RunSegments: 100, ComputeDaily: 80}}
+	s := runs.NewRunService(db, quotas, config.RenderConfig{MaxInstructions: 200000, Timeout: time.Second})
+	ps := projects.NewProjectService(db, ids.V7, quotas)
+
+	_, err = s.Run(ctx, uuid.New(), json.RawMessage(square))
+	assert.ErrorIs(t, err, services.ErrUserNotFound)
+
+	run, err := s.Run(ctx, alice.ID, json.RawMessage(square))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, run.Segments, 4)
+	assert.Equal(t, run.Steps, run.ComputeToday)
+	assert.Equal(t, 80, run.MaxComputeDaily)
+
+	_, err = s.Run(ctx, alice.ID, json.RawMessage(square))
+	assert.NoError(t, err)
+
+	// the third square needs more than is left for the day, the steps it took until then are metered
+	_, err = s.Run(ctx, alice.ID, json.RawMessage(square))
+	var quotaErr *services.QuotaError
+	if assert.ErrorAs(t, err, &quotaErr) {
+		assert.Equal(t, services.QuotaCompute, quotaErr.Quota)
+		assert.Equal(t, 80, quotaErr.Used)
+	}
+
+	_, err = s.Run(ctx, alice.ID, json.RawMessage(square))
+	assert.ErrorAs(t, err, &quotaErr, "runs are rejected once the day's compute is used up")
+
+	usage, err := ps.GetQuota(ctx, alice.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, usage.RunsToday, "rejected runs aren't metered")
+	assert.Equal(t, 80, usage.ComputeToday)
+	assert.Equal(t, 80, usage.MaxComputeDaily)
+
+	// a run over the memory budget of the role
+	_, err = db.Exec("DELETE FROM compute_usage WHERE user_id = $1", alice.ID)
+	assert.NoError(t, err)
+	s = runs.NewRunService(db, config.QuotasConfig{User: config.QuotaConfig{RunSegments: 3}}, config.RenderConfig{MaxInstructions: 200000})
+	_, err = s.Run(ctx, alice.ID, json.RawMessage(square))
+	var limitErr *services.RunLimitError
+	if assert.ErrorAs(t, err, &limitErr) {
+		assert.Equal(t, services.RunSegments, limitErr.Budget)
+		assert.Equal(t, 3, limitErr.Limit)
+	}
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/database"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/deletions"
+	"NodeTurtleAPI/internal/services/exports"
+	"NodeTurtleAPI/internal/storage"
+	"context"
+	"database/sql"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShards(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	bob := testData.Users[UserBob].ID
+
+	// the test database stands in for the regional one
+	shards := database.NewShards(db, "us", map[string]*sql.DB{"eu": db})
+
+	_, err = db.Exec("UPDATE users SET region = 'eu' WHERE id = $1", bob)
+	assert.NoError(t, err)
+
+	region, err := shards.UserRegion(ctx, bob)
+	assert.NoError(t, err)
+	assert.Equal(t, "eu", region)
+
+	_, err = shards.ForUser(ctx, bob)
+	assert.NoError(t, err)
+
+	home, err := shards.DB("us")
+	assert.NoError(t, err)
+	assert.Equal(t, shards.Primary(), home)
+
+	_, err = shards.DB("ap")
+	assert.ErrorIs(t, err, database.ErrUnknownRegion)
+
+	assert.NoError(t, shards.CheckRegions(ctx))
+
+	_, err = database.MoveUser(ctx, shards, bob, "eu")
+	assert.ErrorIs(t, err, database.ErrSameRegion)
+
+	// the primary database would only delete or export the directory entry of a user living elsewhere
+	_, err = deletions.NewDeletionService(db, storage.NewLocal(t.TempDir()), config.DeletionsConfig{GracePeriod: time.Hour}).Schedule(ctx, bob)
+	assert.ErrorIs(t, err, services.ErrUserInRegion)
+	_, err = exports.NewExportService(db, storage.NewLocal(t.TempDir()), &mocks.MockMailService{}, config.ExportsConfig{TTL: time.Hour}).RequestExport(ctx, bob)
+	assert.ErrorIs(t, err, services.ErrUserInRegion)
+
+	// users with a scheduled deletion stay where the deletion runs
+	alice := testData.Users[UserAlice].ID
+	_, err = db.Exec("INSERT INTO account_deletions (user_id, scheduled_for) VALUES ($1, NOW() + INTERVAL '1 day')", alice)
+	assert.NoError(t, err)
+	_, err = database.MoveUser(ctx, shards, alice, "eu")
+	assert.ErrorIs(t, err, database.ErrPendingRequest)
+
+	// bob's region is no longer configured
+	assert.ErrorIs(t, database.NewShards(db, "us", nil).CheckRegions(ctx), database.ErrUnknownRegion)
+}
+
+// TestShardedTables checks every table holding rows that go away or block along with a user's is moved with them.
+func TestShardedTables(t *testing.T) {
+	_, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	// tables referencing the users table, or a table doing so, through foreign keys deleting along or blocking deletes.
+	// Constraints of partitions are left out for the one of their parent.
+	rows, err := db.Query(`
+		WITH RECURSIVE owned(relid) AS (
+			SELECT 'users'::regclass::oid
+			UNION
+			SELECT c.conrelid FROM pg_constraint c JOIN owned o ON c.confrelid = o.relid
+			WHERE c.contype = 'f' AND c.confdeltype IN ('a', 'r', 'c') AND c.conparentid = 0
+		)
+		SELECT relid::regclass::text FROM owned`)
+	assert.NoError(t, err)
+	defer rows.Close()
+
+	sharded := map[string]bool{}
+	for _, table := range database.ShardedTables {
+		sharded[table.Name] = true
+	}
+
+	// moves are recorded in the primary database whatever the region of the user
+	unsharded := map[string]bool{"shard_moves": true}
+
+	for rows.Next() {
+		var table string
+		assert.NoError(t, rows.Scan(&table))
+		if !unsharded[table] {
+			assert.True(t, sharded[table], "%s references users but isn't moved with them", table)
+		}
+	}
+	assert.NoError(t, rows.Err())
+}
@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/signups"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignupAllowlist(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	s := signups.NewSignupService(db)
+	admin := testData.Users[UserAlice].ID
+
+	subnet, err := s.AddToAllowlist(data.SignupAllowlistCreate{Subnet: "192.0.2.77/24", Note: "Springfield High"}, admin)
+	assert.NoError(t, err)
+	assert.Equal(t, "192.0.2.0/24", *subnet.Subnet)
+
+	_, err = s.AddToAllowlist(data.SignupAllowlistCreate{Subnet: "192.0.2.0/24"}, admin)
+	assert.ErrorIs(t, err, services.ErrAlreadyAllowlisted)
+
+	domain, err := s.AddToAllowlist(data.SignupAllowlistCreate{Domain: "School.edu"}, admin)
+	assert.NoError(t, err)
+	assert.Equal(t, "school.edu", *domain.Domain)
+
+	single, err := s.AddToAllowlist(data.SignupAllowlistCreate{Subnet: "198.51.100.7"}, admin)
+	assert.NoError(t, err)
+	assert.Equal(t, "198.51.100.7/32", *single.Subnet)
+
+	tests := map[string]struct {
+		ip     string
+		domain string
+		want   bool
+	}{
+		"IP in subnet":      {ip: "192.0.2.200", want: true},
+		"Single IP":         {ip: "198.51.100.7", want: true},
+		"Domain":            {ip: "203.0.113.1", domain: "school.edu", want: true},
+		"Neither":           {ip: "203.0.113.1", domain: "spam.test", want: false},
+		"Neighbouring host": {ip: "198.51.100.8", want: false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			allowlisted, err := s.IsAllowlisted(tt.ip, tt.domain)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, allowlisted)
+		})
+	}
+
+	entries, err := s.ListAllowlist()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+
+	assert.NoError(t, s.RemoveFromAllowlist(subnet.ID))
+	assert.ErrorIs(t, s.RemoveFromAllowlist(subnet.ID), services.ErrRecordNotFound)
+	_, err = s.GetAllowlistEntry(subnet.ID)
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+}
@@ -0,0 +1,45 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/services/system"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBHealth(t *testing.T) {
+	_, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	s := system.NewSystemService(db)
+
+	health, err := s.DBHealth()
+	assert.NoError(t, err)
+	assert.Positive(t, health.DatabaseBytes)
+	assert.Positive(t, health.Pool.MaxConnections)
+	assert.Positive(t, health.Pool.ServerConnections)
+	assert.False(t, health.InRecovery)
+	assert.Nil(t, health.ReplayLag)
+	assert.NotNil(t, health.Replicas)
+
+	var users bool
+	for _, table := range health.Tables {
+		if table.Name == "users" {
+			users = true
+			assert.Positive(t, table.TotalBytes)
+			assert.GreaterOrEqual(t, table.BloatRatio, 0.0)
+			assert.LessOrEqual(t, table.BloatRatio, 1.0)
+		}
+	}
+	assert.True(t, users)
+
+	for _, index := range health.Indexes {
+		if index.Name == "users_pkey" {
+			assert.False(t, index.Unused)
+		}
+	}
+}
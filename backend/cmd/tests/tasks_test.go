@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/requestid"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/mail"
+	"NodeTurtleAPI/internal/services/tasks"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskQueue(t *testing.T) {
+	_, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec("TRUNCATE tasks")
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	s := tasks.NewTaskService(db)
+	mailService := mail.NewMailService(db, config.MailConfig{Host: "localhost", Port: 1})
+
+	// queued emails keep the request that queued them
+	queued := requestid.NewContext(ctx, "req-1")
+	err = mailService.QueueEmail(queued, "alice@example.com", "Reset Your Password", "reset", map[string]string{"url": "/reset/token"})
+	assert.NoError(t, err)
+
+	task, err := s.Claim(ctx)
+	if !assert.NoError(t, err) || !assert.NotNil(t, task) {
+		return
+	}
+	assert.Equal(t, data.TaskEmail, task.Kind)
+	assert.Equal(t, data.TaskRunning, task.Status)
+	assert.Equal(t, 1, task.Attempts)
+	assert.Equal(t, "req-1", task.RequestID)
+	var email data.EmailTask
+	assert.NoError(t, json.Unmarshal(task.Payload, &email))
+	assert.Equal(t, data.EmailTask{To: "alice@example.com", Subject: "Reset Your Password", Template: "reset", Data: map[string]string{"url": "/reset/token"}}, email)
+
+	// a running task isn't claimed twice
+	other, err := s.Claim(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, other)
+
+	// a failed task waits for its backoff
+	dead, err := s.Fail(ctx, task, errors.New("smtp: connection reset"), time.Hour, 2)
+	assert.NoError(t, err)
+	assert.False(t, dead)
+	other, err = s.Claim(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, other)
+
+	_, err = db.Exec("UPDATE tasks SET run_at = NOW() WHERE id = $1", task.ID)
+	assert.NoError(t, err)
+	task, err = s.Claim(ctx)
+	if !assert.NoError(t, err) || !assert.NotNil(t, task) {
+		return
+	}
+	assert.Equal(t, 2, task.Attempts)
+	assert.Equal(t, "smtp: connection reset", task.LastError)
+
+	// the last attempt kills the task
+	dead, err = s.Fail(ctx, task, errors.New("smtp: mailbox unavailable"), 0, 2)
+	assert.NoError(t, err)
+	assert.True(t, dead)
+	other, err = s.Claim(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, other)
+
+	deadTasks, err := s.ListDead(ctx, data.TaskFilter{Kind: data.TaskEmail, Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, deadTasks, 1) {
+		assert.Equal(t, task.ID, deadTasks[0].ID)
+		assert.Equal(t, "smtp: mailbox unavailable", deadTasks[0].LastError)
+		assert.NotNil(t, deadTasks[0].FailedAt)
+	}
+
+	// retried tasks get all their attempts back
+	retried, err := s.Retry(ctx, task.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, retried) {
+		assert.Equal(t, data.TaskPending, retried.Status)
+		assert.Equal(t, 0, retried.Attempts)
+	}
+	_, err = s.Retry(ctx, task.ID)
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+
+	// tasks of a runner that was stopped don't count the attempt
+	task, err = s.Claim(ctx)
+	if !assert.NoError(t, err) || !assert.NotNil(t, task) {
+		return
+	}
+	assert.NoError(t, s.Release(ctx, task))
+	task, err = s.Claim(ctx)
+	if !assert.NoError(t, err) || !assert.NotNil(t, task) {
+		return
+	}
+	assert.Equal(t, 1, task.Attempts)
+
+	// tasks of a runner that stopped responding are requeued
+	time.Sleep(10 * time.Millisecond)
+	requeued, err := s.RequeueStale(ctx, time.Millisecond, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, requeued)
+
+	task, err = s.Claim(ctx)
+	if !assert.NoError(t, err) || !assert.NotNil(t, task) {
+		return
+	}
+	assert.NoError(t, s.Complete(ctx, task))
+
+	other, err = s.Claim(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, other)
+	deadTasks, err = s.ListDead(ctx, data.TaskFilter{Limit: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, deadTasks)
+}
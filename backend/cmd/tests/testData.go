@@ -73,6 +73,17 @@ const (
 	TokenTomAccountSuspended  = "tom_account_suspended"
 )
 
+func testDatabaseConfig() config.DatabaseConfig {
+	return config.DatabaseConfig{
+		Host:     config.GetEnv("TEST_DB_HOST", "localhost"),
+		Port:     config.GetEnvAsInt("TEST_DB_PORT", 5432),
+		User:     config.GetEnv("TEST_DB_USER", "postgres"),
+		Password: config.GetEnv("TEST_DB_PASSWORD", "admin"),
+		Name:     config.GetEnv("TEST_DB_NAME", "NodeTurtle_Test"),
+		SSLMode:  config.GetEnv("TEST_DB_SSLMODE", "disable"),
+	}
+}
+
 func createTestData() (*TestData, *sql.DB, error) {
 	adminID := uuid.New()
 
@@ -122,7 +133,7 @@ func createTestData() (*TestData, *sql.DB, error) {
 				BannedBy:  adminID,
 				Reason:    "test ban",
 				BannedAt:  time.Now().UTC(),
-				ExpiresAt: time.Now().UTC().Add(24 * time.Hour),
+				ExpiresAt: utils.Ptr(time.Now().UTC().Add(24 * time.Hour)),
 			}),
 		},
 		UserFrank: {
@@ -136,7 +147,7 @@ func createTestData() (*TestData, *sql.DB, error) {
 				BannedBy:  adminID,
 				Reason:    "test expired ban",
 				BannedAt:  time.Now().UTC(),
-				ExpiresAt: time.Now().UTC().Add(-24 * time.Hour), // expired ban
+				ExpiresAt: utils.Ptr(time.Now().UTC().Add(-24 * time.Hour)), // expired ban
 			}),
 		},
 	}
@@ -300,21 +311,12 @@ func createTestData() (*TestData, *sql.DB, error) {
 		TokenTomAccountSuspended:  t4,
 	}
 
-	config := config.DatabaseConfig{
-		Host:     config.GetEnv("TEST_DB_HOST", "localhost"),
-		Port:     config.GetEnvAsInt("TEST_DB_PORT", 5432),
-		User:     config.GetEnv("TEST_DB_USER", "postgres"),
-		Password: config.GetEnv("TEST_DB_PASSWORD", "admin"),
-		Name:     config.GetEnv("TEST_DB_NAME", "NodeTurtle_Test"),
-		SSLMode:  config.GetEnv("TEST_DB_SSLMODE", "disable"),
-	}
-
-	db, err := database.Connect(config)
+	db, err := database.Connect(testDatabaseConfig())
 	if err != nil {
 		log.Fatalf("Failed to connect to test database: %v", err)
 	}
 
-	_, err = db.Exec(`TRUNCATE tokens, users RESTART IDENTITY CASCADE;`)
+	_, err = db.Exec(`TRUNCATE tokens, users, data_dumps, domain_events RESTART IDENTITY CASCADE;`)
 	if err != nil {
 		log.Fatalf("Failed to erase test database: %v", err)
 	}
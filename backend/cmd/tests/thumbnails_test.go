@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/thumbnails"
+	"context"
+	"encoding/json"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetThumbnail(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	s := thumbnails.NewThumbnailService(db, config.RenderConfig{MaxInstructions: 1000, Timeout: time.Second, Width: 64, Height: 48})
+
+	project := &data.Project{
+		ID:      testData.Projects[ProjectAlicePublic].ID,
+		Version: 1,
+		Data: json.RawMessage(`{"nodes":[
+			{"id":"s","type":"startNode"},
+			{"id":"m","type":"moveNode","data":{"distance":50}}
+		],"edges":[{"source":"s","target":"m"}]}`),
+	}
+
+	svg, err := s.GetThumbnail(ctx, project, data.ThumbnailSVG)
+	assert.NoError(t, err)
+	assert.Contains(t, string(svg.Content), "<path")
+
+	png, err := s.GetThumbnail(ctx, project, data.ThumbnailPNG)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("\x89PNG"), png.Content[:4])
+
+	// the stored thumbnail is served until the version changes
+	project.Data = json.RawMessage(`{"nodes":[],"edges":[]}`)
+	stored, err := s.GetThumbnail(ctx, project, data.ThumbnailSVG)
+	assert.NoError(t, err)
+	assert.Equal(t, svg.Content, stored.Content)
+
+	project.Version = 2
+	project.Data = json.RawMessage(`{"nodes":[
+		{"id":"s","type":"startNode"},
+		{"id":"loop","type":"loopNode","data":{"loopCount":30}},
+		{"id":"a","type":"rotateNode","data":{"angle":10}},
+		{"id":"b","type":"rotateNode","data":{"angle":-10}}
+	],"edges":[
+		{"source":"s","target":"loop"},
+		{"source":"loop","sourceHandle":"loop","target":"a"},
+		{"source":"loop","sourceHandle":"loop","target":"b"}
+	]}`)
+	_, err = s.GetThumbnail(ctx, project, data.ThumbnailSVG)
+	assert.ErrorIs(t, err, services.ErrRenderLimit)
+
+	var renderErr string
+	err = db.QueryRow("SELECT error FROM project_thumbnails WHERE project_id = $1 AND format = 'svg'", project.ID).Scan(&renderErr)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, renderErr)
+
+	// an older version doesn't replace a newer one
+	project.Version = 1
+	_, err = s.GetThumbnail(ctx, project, data.ThumbnailSVG)
+	assert.NoError(t, err)
+	var version int
+	assert.NoError(t, db.QueryRow("SELECT version FROM project_thumbnails WHERE project_id = $1 AND format = 'svg'", project.ID).Scan(&version))
+	assert.Equal(t, 2, version)
+}
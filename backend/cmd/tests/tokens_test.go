@@ -1,8 +1,13 @@
 package tests
 
 import (
+	"NodeTurtleAPI/internal/clock"
+	"NodeTurtleAPI/internal/config"
 	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
 	"NodeTurtleAPI/internal/services/tokens"
+	"NodeTurtleAPI/internal/utils"
+	"context"
 	"database/sql"
 	"log"
 	"testing"
@@ -12,6 +17,14 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+var tokensConfig = config.TokensConfig{
+	ActivationTTL:   72 * time.Hour,
+	ResetTTL:        30 * time.Minute,
+	DeactivationTTL: 15 * time.Minute,
+	RefreshTTL:      168 * time.Hour,
+	MagicLinkTTL:    15 * time.Minute,
+}
+
 func setupTokenService() (tokens.ITokenService, TestData, *sql.DB, func()) {
 	testData, db, err := createTestData()
 
@@ -19,7 +32,7 @@ func setupTokenService() (tokens.ITokenService, TestData, *sql.DB, func()) {
 		log.Fatalf("Failed setup test data: %v", err)
 	}
 
-	return tokens.NewTokenService(db), *testData, db, func() { db.Close() }
+	return tokens.NewTokenService(db, tokensConfig, clock.System), *testData, db, func() { db.Close() }
 }
 
 func TestGenerateToken(t *testing.T) {
@@ -43,10 +56,10 @@ func TestTokenService_New(t *testing.T) {
 	defer close()
 
 	userID := td.Users[UserAlice].ID
-	ttl := 1 * time.Hour
+	ttl := tokensConfig.ResetTTL
 	scope := data.ScopePasswordReset
 
-	token, err := s.New(userID, ttl, scope)
+	token, err := s.New(context.Background(), userID, scope)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, token)
@@ -77,7 +90,7 @@ func TestTokenService_DeleteAllForUser(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, countBefore > 0, "Test token should exist before deletion")
 
-	err = s.DeleteAllForUser(scopeToDelete, userIDToDelete)
+	err = s.DeleteAllForUser(context.Background(), scopeToDelete, userIDToDelete)
 	assert.NoError(t, err)
 
 	// Verify token is deleted
@@ -87,11 +100,121 @@ func TestTokenService_DeleteAllForUser(t *testing.T) {
 	assert.Equal(t, 0, countAfter, "Token should be deleted")
 
 	// Test Case 2: Delete non-existent tokens (different scope for the same user)
-	err = s.DeleteAllForUser(data.ScopeRefresh, userIDToDelete)
+	err = s.DeleteAllForUser(context.Background(), data.ScopeRefresh, userIDToDelete)
 	assert.NoError(t, err, "Deleting non-existent tokens should not return an error")
 
 	// Test Case 3: Delete for a user with no tokens of that scope
 	otherUserID := td.Users[UserAlice].ID
-	err = s.DeleteAllForUser(data.ScopeUserActivation, otherUserID)
+	err = s.DeleteAllForUser(context.Background(), data.ScopeUserActivation, otherUserID)
 	assert.NoError(t, err, "Deleting non-existent tokens for a user should not return an error")
 }
+
+func TestTokenService_NewWithoutLifetime(t *testing.T) {
+	_, td, db, close := setupTokenService()
+	defer close()
+
+	s := tokens.NewTokenService(db, config.TokensConfig{}, clock.System)
+
+	token, err := s.New(context.Background(), td.Users[UserAlice].ID, data.ScopePasswordReset)
+	assert.Error(t, err)
+	assert.Nil(t, token)
+}
+
+func TestTokenService_Consume(t *testing.T) {
+	s, td, _, close := setupTokenService()
+	defer close()
+
+	userID := td.Users[UserAlice].ID
+
+	// single use tokens can only be consumed once
+	reset, err := s.New(context.Background(), userID, data.ScopePasswordReset)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Consume(context.Background(), data.ScopePasswordReset, reset.Plaintext))
+	assert.ErrorIs(t, s.Consume(context.Background(), data.ScopePasswordReset, reset.Plaintext), services.ErrInvalidToken)
+
+	magic, err := s.New(context.Background(), userID, data.ScopeMagicLink)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(tokensConfig.MagicLinkTTL), magic.ExpiresAt, time.Minute)
+	assert.NoError(t, s.Consume(context.Background(), data.ScopeMagicLink, magic.Plaintext))
+	assert.ErrorIs(t, s.Consume(context.Background(), data.ScopeMagicLink, magic.Plaintext), services.ErrInvalidToken)
+
+	// activation tokens stay valid until they expire
+	activation, err := s.New(context.Background(), userID, data.ScopeUserActivation)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Consume(context.Background(), data.ScopeUserActivation, activation.Plaintext))
+	assert.NoError(t, s.Consume(context.Background(), data.ScopeUserActivation, activation.Plaintext))
+
+	// tokens are bound to their scope
+	deactivation, err := s.New(context.Background(), userID, data.ScopeDeactivate)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, s.Consume(context.Background(), data.ScopePasswordReset, deactivation.Plaintext), services.ErrInvalidToken)
+
+	assert.ErrorIs(t, s.Consume(context.Background(), data.ScopeDeactivate, "invalid"), services.ErrInvalidToken)
+}
+
+func TestTokenService_ConsumeExpired(t *testing.T) {
+	_, td, db, close := setupTokenService()
+	defer close()
+
+	clk := clock.NewFake(time.Now().UTC())
+	s := tokens.NewTokenService(db, tokensConfig, clk)
+
+	userID := td.Users[UserAlice].ID
+
+	activation, err := s.New(context.Background(), userID, data.ScopeUserActivation)
+	assert.NoError(t, err)
+
+	clk.Advance(tokensConfig.ActivationTTL - time.Minute)
+	assert.NoError(t, s.Consume(context.Background(), data.ScopeUserActivation, activation.Plaintext))
+
+	clk.Advance(2 * time.Minute)
+	assert.ErrorIs(t, s.Consume(context.Background(), data.ScopeUserActivation, activation.Plaintext), services.ErrInvalidToken)
+}
+
+func TestTokenService_StatsAndRevoke(t *testing.T) {
+	s, td, db, close := setupTokenService()
+	defer close()
+
+	ctx := context.Background()
+	alice := td.Users[UserAlice].ID
+	bob := td.Users[UserBob].ID
+
+	_, err := db.Exec("DELETE FROM tokens")
+	assert.NoError(t, err)
+
+	for _, userID := range []uuid.UUID{alice, bob} {
+		_, err := s.New(ctx, userID, data.ScopeRefresh)
+		assert.NoError(t, err)
+	}
+	_, err = s.New(ctx, alice, data.ScopeUserActivation)
+	assert.NoError(t, err)
+
+	expired, err := tokens.GenerateToken(bob, -time.Hour, data.ScopePasswordReset)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Insert(ctx, expired))
+
+	stats, err := s.Stats(ctx)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []data.TokenStats{
+		{Scope: data.ScopePasswordReset, Active: 0, Expired: 1},
+		{Scope: data.ScopeRefresh, Active: 2, Expired: 0},
+		{Scope: data.ScopeUserActivation, Active: 1, Expired: 0},
+	}, stats)
+
+	// nothing was issued before an hour ago
+	revoked, err := s.Revoke(ctx, data.TokenRevocation{IssuedBefore: utils.Ptr(time.Now().Add(-time.Hour))})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), revoked)
+
+	revoked, err = s.Revoke(ctx, data.TokenRevocation{UserID: &alice, Scope: data.ScopeRefresh})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), revoked)
+
+	revoked, err = s.Revoke(ctx, data.TokenRevocation{Scope: data.ScopeRefresh, IssuedBefore: utils.Ptr(time.Now().Add(time.Minute))})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), revoked)
+
+	var remaining int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM tokens").Scan(&remaining))
+	assert.Equal(t, 2, remaining)
+}
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"context"
 	"errors"
 	"log"
 	"testing"
@@ -62,7 +63,7 @@ func TestCreateUser(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 
-			_, err := s.CreateUser(tt.reg)
+			_, err := s.CreateUser(context.Background(), tt.reg)
 
 			if tt.err != nil {
 				assert.Error(t, err)
@@ -105,7 +106,7 @@ func TestResetPassword(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 
-			err := s.ResetPassword(tt.token, tt.newPassword)
+			err := s.ResetPassword(context.Background(), tt.token, tt.newPassword)
 
 			if tt.err != nil {
 				assert.Error(t, err)
@@ -116,6 +117,10 @@ func TestResetPassword(t *testing.T) {
 			}
 		})
 	}
+
+	// reset tokens are single use
+	err := s.ResetPassword(context.Background(), td.Tokens["bob_valid_password_reset"].Plaintext, "anotherPassword1234")
+	assert.Equal(t, services.ErrInvalidToken, err)
 }
 
 func TestChangePassword(t *testing.T) {
@@ -151,7 +156,7 @@ func TestChangePassword(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 
-			err := s.ChangePassword(tt.userId, tt.oldPassword, tt.newPassword)
+			err := s.ChangePassword(context.Background(), tt.userId, tt.oldPassword, tt.newPassword)
 
 			if tt.err != nil {
 				assert.Error(t, err)
@@ -185,7 +190,7 @@ func TestGetUserById(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 
-			_, err := s.GetUserByID(tt.userId)
+			_, err := s.GetUserByID(context.Background(), tt.userId)
 
 			if tt.err != nil {
 				assert.Error(t, err)
@@ -219,7 +224,7 @@ func TestGetUserByEmail(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 
-			_, err := s.GetUserByEmail(tt.email)
+			_, err := s.GetUserByEmail(context.Background(), tt.email)
 
 			if tt.err != nil {
 				assert.Error(t, err)
@@ -253,7 +258,7 @@ func TestGetUserByUsername(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 
-			_, err := s.GetUserByUsername(tt.username)
+			_, err := s.GetUserByUsername(context.Background(), tt.username)
 
 			if tt.err != nil {
 				assert.Error(t, err)
@@ -388,7 +393,7 @@ func TestListUsers(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 
-			_, _, err := s.ListUsers(tt.filters)
+			_, _, err := s.ListUsers(context.Background(), tt.filters)
 
 			if tt.err != nil {
 				assert.Error(t, err)
@@ -440,7 +445,7 @@ func TestUpdateUser(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 
-			_, err := s.UpdateUser(tt.userID, *tt.updates)
+			_, err := s.UpdateUser(context.Background(), tt.userID, *tt.updates)
 
 			if tt.err != nil {
 				assert.Error(t, err)
@@ -453,6 +458,50 @@ func TestUpdateUser(t *testing.T) {
 	}
 }
 
+func TestChangeUsername(t *testing.T) {
+	s, td, close := setupUserService()
+	defer close()
+
+	ctx := context.Background()
+	alice := td.Users[UserAlice]
+	bob := td.Users[UserBob]
+
+	user, err := s.ChangeUsername(ctx, alice.ID, "alicenew")
+	assert.NoError(t, err)
+	assert.Equal(t, "alicenew", user.Username)
+
+	var cooldown *services.UsernameCooldownError
+	_, err = s.ChangeUsername(ctx, alice.ID, "aliceagain")
+	if assert.ErrorAs(t, err, &cooldown) {
+		assert.WithinDuration(t, time.Now().Add(data.UsernameChangeInterval), cooldown.NextChange, time.Minute)
+	}
+
+	// the old username stays reserved and leads to alice
+	exists, err := s.UsernameExists(ctx, alice.Username)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	_, err = s.ChangeUsername(ctx, bob.ID, alice.Username)
+	assert.ErrorIs(t, err, services.ErrUsernameReserved)
+
+	_, err = s.ChangeUsername(ctx, bob.ID, td.Users[UserChris].Username)
+	assert.ErrorIs(t, err, services.ErrDuplicateUsername)
+
+	resolved, err := s.ResolveUsername(ctx, alice.Username)
+	assert.NoError(t, err)
+	assert.Equal(t, data.ResolvedUsername{UserID: alice.ID, Username: "alicenew", Moved: true}, *resolved)
+
+	resolved, err = s.ResolveUsername(ctx, "alicenew")
+	assert.NoError(t, err)
+	assert.False(t, resolved.Moved)
+
+	_, err = s.ResolveUsername(ctx, "nobodyever")
+	assert.ErrorIs(t, err, services.ErrUserNotFound)
+
+	_, err = s.ChangeUsername(ctx, uuid.New(), "ghost")
+	assert.ErrorIs(t, err, services.ErrUserNotFound)
+}
+
 func TestDeleteUser(t *testing.T) {
 	s, td, close := setupUserService()
 	defer close()
@@ -474,7 +523,7 @@ func TestDeleteUser(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 
-			err := s.DeleteUser(tt.userId)
+			err := s.DeleteUser(context.Background(), tt.userId)
 
 			if tt.err != nil {
 				assert.Error(t, err)
@@ -516,7 +565,7 @@ func TestGetForToken(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 
-			_, err := s.GetForToken(tt.tokenScope, tt.tokenPlaintext)
+			_, err := s.GetForToken(context.Background(), tt.tokenScope, tt.tokenPlaintext)
 
 			if tt.err != nil {
 				assert.Error(t, err)
@@ -550,7 +599,7 @@ func TestEmailExists(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 
-			exists, err := s.EmailExists(tt.email)
+			exists, err := s.EmailExists(context.Background(), tt.email)
 
 			assert.Equal(t, tt.exists, exists)
 			assert.NoError(t, err)
@@ -578,10 +627,144 @@ func TestUsernameExists(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 
-			exists, err := s.UsernameExists(tt.username)
+			exists, err := s.UsernameExists(context.Background(), tt.username)
 
 			assert.Equal(t, tt.exists, exists)
 			assert.NoError(t, err)
 		})
 	}
 }
+
+func TestProvisionUser(t *testing.T) {
+	s, td, close := setupUserService()
+	defer close()
+
+	tests := map[string]struct {
+		entry data.UserProvision
+		err   error
+	}{
+		"Successful provisioning with username": {
+			entry: data.UserProvision{Email: "student1@example.com", Username: "student1"},
+			err:   nil,
+		},
+		"Successful provisioning with generated username": {
+			entry: data.UserProvision{Email: "jane.doe@example.com"},
+			err:   nil,
+		},
+		"Duplicate email": {
+			entry: data.UserProvision{Email: td.Users[UserBob].Email},
+			err:   services.ErrDuplicateEmail,
+		},
+		"Duplicate username": {
+			entry: data.UserProvision{Email: "student2@example.com", Username: td.Users[UserBob].Username},
+			err:   services.ErrDuplicateUsername,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+
+			user, password, err := s.ProvisionUser(context.Background(), tt.entry)
+
+			if tt.err != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.err, err)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, password)
+				assert.True(t, user.IsActivated)
+				assert.True(t, user.PasswordResetRequired)
+
+				err = s.ChangePassword(context.Background(), user.ID, password, "newpassword123")
+				assert.NoError(t, err)
+
+				updated, err := s.GetUserByID(context.Background(), user.ID)
+				assert.NoError(t, err)
+				assert.False(t, updated.PasswordResetRequired)
+			}
+		})
+	}
+}
+
+func TestDeprovisionUser(t *testing.T) {
+	s, td, close := setupUserService()
+	defer close()
+
+	tests := map[string]struct {
+		email string
+		err   error
+	}{
+		"Successful deprovisioning": {
+			email: td.Users[UserAlice].Email,
+			err:   nil,
+		},
+		"User not found": {
+			email: "missing@example.com",
+			err:   services.ErrUserNotFound,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+
+			user, err := s.DeprovisionUser(context.Background(), tt.email)
+
+			if tt.err != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.err, err)
+			} else {
+				assert.NoError(t, err)
+				assert.False(t, user.IsActivated)
+			}
+		})
+	}
+}
+
+func TestBulkUpdateUsers(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	s := users.NewUserService(db)
+	alice, john, chris := testData.Users[UserAlice], testData.Users[UserJohn], testData.Users[UserChris]
+	admin := data.User{ID: chris.ID, Role: data.Role{ID: data.RoleAdmin.ToID(), Name: data.RoleAdmin.String()}}
+	moderator := data.User{ID: testData.Users[UserBob].ID, Role: data.Role{ID: data.RoleModerator.ToID(), Name: data.RoleModerator.String()}}
+
+	missing := uuid.New()
+	results, err := s.BulkUpdateUsers(ctx, admin, data.BulkUserUpdate{Action: data.BulkActivate, UserIDs: []uuid.UUID{john.ID, missing, chris.ID}})
+	assert.NoError(t, err)
+	assert.Equal(t, []data.BulkUserResult{
+		{UserID: john.ID, Username: john.Username},
+		{UserID: missing, Error: "User not found"},
+		{UserID: chris.ID, Username: chris.Username, Error: "Cannot change your own account"},
+	}, results)
+	user, err := s.GetUserByID(ctx, john.ID)
+	assert.NoError(t, err)
+	assert.True(t, user.IsActivated)
+
+	role := data.RolePremium
+	_, err = s.BulkUpdateUsers(ctx, admin, data.BulkUserUpdate{Action: data.BulkSetRole, UserIDs: []uuid.UUID{alice.ID}, Role: &role})
+	assert.NoError(t, err)
+	user, err = s.GetUserByID(ctx, alice.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, data.RolePremium.ToID(), user.Role.ID)
+
+	results, err = s.BulkUpdateUsers(ctx, moderator, data.BulkUserUpdate{Action: data.BulkBan, UserIDs: []uuid.UUID{alice.ID, chris.ID}, Reason: "spam", Duration: 24})
+	assert.NoError(t, err)
+	if assert.Len(t, results, 2) {
+		assert.Empty(t, results[0].Error)
+		assert.Equal(t, "Cannot change a user with an equal or higher role", results[1].Error)
+	}
+	var bans int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM banned_users WHERE user_id IN ($1, $2) AND expires_at > NOW()", alice.ID, chris.ID).Scan(&bans))
+	assert.Equal(t, 1, bans)
+
+	_, err = s.BulkUpdateUsers(ctx, admin, data.BulkUserUpdate{Action: data.BulkDeactivate, UserIDs: []uuid.UUID{john.ID}})
+	assert.NoError(t, err)
+	user, err = s.GetUserByID(ctx, john.ID)
+	assert.NoError(t, err)
+	assert.False(t, user.IsActivated)
+}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/ids"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/projects"
+	"NodeTurtleAPI/internal/services/users"
+	"NodeTurtleAPI/internal/services/verification"
+	"context"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerificationRequests(t *testing.T) {
+	testData, db, err := createTestData()
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	s := verification.NewVerificationService(db)
+	admin := testData.Users[UserAlice].ID
+	bob := testData.Users[UserBob].ID
+	chris := testData.Users[UserChris].ID
+	evidence := "https://youtube.com/@bob links back to this account"
+
+	_, err = s.GetLatestRequest(ctx, bob)
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+
+	request, err := s.SubmitRequest(ctx, bob, evidence)
+	assert.NoError(t, err)
+	assert.Equal(t, data.VerificationPending, request.Status)
+	assert.Equal(t, testData.Users[UserBob].Username, request.Username)
+
+	// one pending request at a time
+	_, err = s.SubmitRequest(ctx, bob, evidence)
+	assert.ErrorIs(t, err, services.ErrVerificationPending)
+
+	rejected, err := s.SubmitRequest(ctx, chris, evidence)
+	assert.NoError(t, err)
+
+	pending, err := s.ListPendingRequests(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, pending, 2)
+	assert.Equal(t, request.ID, pending[0].ID)
+
+	_, err = s.ReviewRequest(ctx, rejected.ID, data.VerificationReview{Status: data.VerificationRejected, Note: "No link back"}, admin)
+	assert.NoError(t, err)
+
+	reviewed, err := s.ReviewRequest(ctx, request.ID, data.VerificationReview{Status: data.VerificationApproved}, admin)
+	assert.NoError(t, err)
+	assert.Equal(t, data.VerificationApproved, reviewed.Status)
+	assert.Equal(t, admin, *reviewed.ReviewedBy)
+
+	// a request is reviewed once
+	_, err = s.ReviewRequest(ctx, request.ID, data.VerificationReview{Status: data.VerificationRejected}, admin)
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+
+	_, err = s.SubmitRequest(ctx, bob, evidence)
+	assert.ErrorIs(t, err, services.ErrAlreadyVerified)
+
+	us := users.NewUserService(db)
+	user, err := us.GetUserByID(context.Background(), bob)
+	assert.NoError(t, err)
+	assert.True(t, user.Verified)
+	user, err = us.GetUserByID(context.Background(), chris)
+	assert.NoError(t, err)
+	assert.False(t, user.Verified)
+
+	// the badge shows on the creator's projects
+	ps := projects.NewProjectService(db, ids.V7, config.QuotasConfig{})
+	project, err := ps.GetProject(context.Background(), testData.Projects[ProjectBobFeatured].ID, &bob)
+	assert.NoError(t, err)
+	assert.True(t, project.CreatorVerified)
+
+	// a rejected user may try again
+	_, err = s.SubmitRequest(ctx, chris, evidence)
+	assert.NoError(t, err)
+}
+
+func TestLooksVerified(t *testing.T) {
+	assert.True(t, data.LooksVerified("VerifiedTeacher"))
+	assert.True(t, data.LooksVerified("aliceOFFICIAL"))
+	assert.False(t, data.LooksVerified("alice"))
+}
@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/webhooks"
+	"context"
+	"log"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupWebhookService() (webhooks.IWebhookService, TestData, func()) {
+	testData, db, err := createTestData()
+
+	if err != nil {
+		log.Fatalf("Failed setup test data: %v", err)
+	}
+
+	return webhooks.NewWebhookService(db, config.WebhooksConfig{CheckInterval: 1, Timeout: 1}), *testData, func() { db.Close() }
+}
+
+func TestSetProjectWebhook(t *testing.T) {
+	s, td, close := setupWebhookService()
+	defer close()
+
+	projectID := td.Projects[ProjectAlicePublic].ID
+
+	created, err := s.SetProjectWebhook(data.ProjectWebhook{
+		ProjectID:      projectID,
+		URL:            "https://hooks.example.com/a",
+		LikeThresholds: []int{1, 10},
+		NotifyFeatured: true,
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, created.Secret)
+	assert.Equal(t, []int{1, 10}, created.LikeThresholds)
+
+	updated, err := s.SetProjectWebhook(data.ProjectWebhook{
+		ProjectID:      projectID,
+		URL:            "https://hooks.example.com/b",
+		LikeThresholds: []int{5},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, created.Secret, updated.Secret, "secret is kept on update")
+	assert.Equal(t, "https://hooks.example.com/b", updated.URL)
+	assert.False(t, updated.NotifyFeatured)
+
+	_, err = s.SetProjectWebhook(data.ProjectWebhook{ProjectID: uuid.New(), URL: "https://hooks.example.com/c"})
+	assert.ErrorIs(t, err, services.ErrProjectNotFound)
+
+	assert.NoError(t, s.DeleteProjectWebhook(projectID))
+	_, err = s.GetProjectWebhook(projectID)
+	assert.ErrorIs(t, err, services.ErrRecordNotFound)
+	assert.ErrorIs(t, s.DeleteProjectWebhook(projectID), services.ErrRecordNotFound)
+}
+
+func TestDeliverMilestones(t *testing.T) {
+	s, td, close := setupWebhookService()
+	defer close()
+
+	projectID := td.Projects[ProjectBobFeatured].ID
+
+	// loopback addresses are refused, so every delivery fails without leaving the machine
+	_, err := s.SetProjectWebhook(data.ProjectWebhook{
+		ProjectID:      projectID,
+		URL:            "https://127.0.0.1:1/hook",
+		LikeThresholds: []int{1, 3, 100},
+		NotifyFeatured: true,
+	})
+	assert.NoError(t, err)
+
+	delivered, err := s.DeliverMilestones(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+
+	deliveries, err := s.GetDeliveries(projectID, 10)
+	assert.NoError(t, err)
+	// thresholds 1 and 3 are reached and the project is featured
+	assert.Len(t, deliveries, 3)
+	for _, d := range deliveries {
+		assert.NotNil(t, d.Error)
+		assert.Nil(t, d.StatusCode)
+	}
+
+	_, err = s.DeliverMilestones(context.Background())
+	assert.NoError(t, err)
+
+	deliveries, err = s.GetDeliveries(projectID, 10)
+	assert.NoError(t, err)
+	assert.Len(t, deliveries, 3, "milestones are delivered only once")
+}
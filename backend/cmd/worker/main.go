@@ -0,0 +1,75 @@
+// Command worker renders project thumbnails queued by the API when RENDER_WORKERS is set, see internal/worker.
+//
+//	go run ./cmd/worker -env .env
+//
+// Workers share the database and the rendering limits of the API, any number of them can run side by side.
+// They serve /healthz and Prometheus /metrics on WORKER_ADDR.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/database"
+	"NodeTurtleAPI/internal/services/renders"
+	"NodeTurtleAPI/internal/services/thumbnails"
+	"NodeTurtleAPI/internal/worker"
+)
+
+func main() {
+	envFile := flag.String("env", ".env", "Path to .env file")
+	flag.Parse()
+
+	cfg, err := config.Load(*envFile)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if cfg.Worker.Concurrency <= 0 {
+		cfg.Worker.Concurrency = runtime.NumCPU()
+	}
+
+	db, err := database.Connect(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "worker"
+	}
+
+	// the worker renders itself, whatever the API is configured to do
+	renderCfg := cfg.Render
+	renderCfg.Workers = false
+	renderService := renders.NewRenderService(db)
+	thumbnailService := thumbnails.NewThumbnailService(db, renderCfg)
+	w := worker.New(hostname, cfg.Worker, &renderService, &thumbnailService)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if cfg.Worker.Addr != "" {
+		srv := &http.Server{Addr: cfg.Worker.Addr, Handler: w.Handler(), ReadHeaderTimeout: 5 * time.Second}
+		go func() {
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("Failed to serve worker metrics: %v", err)
+			}
+		}()
+		defer srv.Close()
+	}
+
+	if err := w.Run(ctx); err != nil {
+		log.Fatalf("Render worker %s failed: %v", w.ID(), err)
+	}
+	log.Printf("Render worker %s stopped", w.ID())
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/anomalies"
+
+	"github.com/labstack/echo/v4"
+)
+
+// alertsLimit is the number of alerts returned when the request doesn't ask for a number.
+const alertsLimit = 50
+
+// AlertHandler handles HTTP requests for the anomaly alerts of admins.
+type AlertHandler struct {
+	anomalyService anomalies.IAnomalyService
+}
+
+// NewAlertHandler creates a new AlertHandler with the provided services.
+func NewAlertHandler(anomalyService anomalies.IAnomalyService) AlertHandler {
+	return AlertHandler{
+		anomalyService: anomalyService,
+	}
+}
+
+// List handles the request to list the anomaly alerts, newest first.
+// They can be narrowed down to a kind and to the alerts nobody acknowledged yet.
+func (h *AlertHandler) List(c echo.Context) error {
+	var filter data.AlertFilter
+	if err := c.Bind(&filter); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid query parameters")
+	}
+
+	if err := c.Validate(&filter); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = alertsLimit
+	}
+
+	alerts, err := h.anomalyService.ListAlerts(c.Request().Context(), filter)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal alert retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve alerts")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"alerts": alerts,
+		"meta": map[string]interface{}{
+			"limit": filter.Limit,
+		},
+	})
+}
+
+// Acknowledge handles the request to mark an alert as looked into, which takes it off the open alerts.
+func (h *AlertHandler) Acknowledge(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	alertID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid alert ID")
+	}
+
+	alert, err := h.anomalyService.AcknowledgeAlert(c.Request().Context(), alertID, contextUser.ID)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Alert not found")
+		}
+		logging.Error(c.Request().Context(), "Internal alert acknowledgement error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to acknowledge alert")
+	}
+
+	return c.JSON(http.StatusOK, alert)
+}
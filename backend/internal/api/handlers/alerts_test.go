@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListAlerts(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	windowStart := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	alerts := []data.Alert{
+		{ID: 7, Kind: data.AlertServerErrors, Subject: "/api/projects/:id", Observed: 73, Threshold: 50, WindowStart: windowStart, WindowEnd: windowStart.Add(5 * time.Minute)},
+	}
+
+	mockAnomalyService := mocks.MockAnomalyService{}
+	mockAnomalyService.On("ListAlerts", data.AlertFilter{Limit: alertsLimit}).Return(alerts, nil)
+	mockAnomalyService.On("ListAlerts", data.AlertFilter{Kind: data.AlertServerErrors, Open: true, Limit: 10}).Return(alerts, nil)
+	mockAnomalyService.On("ListAlerts", data.AlertFilter{Kind: data.AlertLikesSurge, Limit: alertsLimit}).Return(nil, errors.New("database error"))
+
+	handler := NewAlertHandler(&mockAnomalyService)
+
+	tests := map[string]struct {
+		query     string
+		wantCode  int
+		wantError bool
+	}{
+		"Latest alerts": {
+			query:    "",
+			wantCode: http.StatusOK,
+		},
+		"Open alerts of a kind": {
+			query:    "?kind=server_errors&open=true&limit=10",
+			wantCode: http.StatusOK,
+		},
+		"Unknown kind": {
+			query:     "?kind=meteor_strike",
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Invalid open flag": {
+			query:     "?open=maybe",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Service error": {
+			query:     "?kind=likes_surge",
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/alerts"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.List(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"subject":"/api/projects/:id"`)
+			}
+		})
+	}
+}
+
+func TestAcknowledgeAlert(t *testing.T) {
+	e := echo.New()
+
+	admin := &data.User{ID: uuid.New(), Username: "admin"}
+	acknowledgedAt := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	mockAnomalyService := mocks.MockAnomalyService{}
+	mockAnomalyService.On("AcknowledgeAlert", int64(7), admin.ID).Return(&data.Alert{ID: 7, Kind: data.AlertLikesSurge, AcknowledgedAt: &acknowledgedAt, AcknowledgedBy: &admin.ID}, nil)
+	mockAnomalyService.On("AcknowledgeAlert", int64(8), admin.ID).Return(nil, services.ErrRecordNotFound)
+	mockAnomalyService.On("AcknowledgeAlert", int64(9), admin.ID).Return(nil, errors.New("database error"))
+
+	handler := NewAlertHandler(&mockAnomalyService)
+
+	tests := map[string]struct {
+		alertID     string
+		contextUser *data.User
+		wantCode    int
+		wantError   bool
+	}{
+		"Acknowledged": {
+			alertID:     "7",
+			contextUser: admin,
+			wantCode:    http.StatusOK,
+		},
+		"Not found": {
+			alertID:     "8",
+			contextUser: admin,
+			wantCode:    http.StatusNotFound,
+			wantError:   true,
+		},
+		"Service error": {
+			alertID:     "9",
+			contextUser: admin,
+			wantCode:    http.StatusInternalServerError,
+			wantError:   true,
+		},
+		"Invalid ID": {
+			alertID:     "seven",
+			contextUser: admin,
+			wantCode:    http.StatusBadRequest,
+			wantError:   true,
+		},
+		"Not authenticated": {
+			alertID:   "7",
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/alerts/"+tt.alertID+"/acknowledge", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.alertID)
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.Acknowledge(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"acknowledged_by":"`+admin.ID.String()+`"`)
+			}
+		})
+	}
+}
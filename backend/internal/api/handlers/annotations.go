@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/annotations"
+	"NodeTurtleAPI/internal/services/audit"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// AnnotationHandler handles HTTP requests for the internal notes and labels staff keep on users and projects.
+type AnnotationHandler struct {
+	annotationService annotations.IAnnotationService
+	auditService      audit.IAuditService
+}
+
+// NewAnnotationHandler creates a new AnnotationHandler with the provided services.
+func NewAnnotationHandler(annotationService annotations.IAnnotationService, auditService audit.IAuditService) AnnotationHandler {
+	return AnnotationHandler{
+		annotationService: annotationService,
+		auditService:      auditService,
+	}
+}
+
+// GetUser handles the request to retrieve the annotation of a user.
+func (h *AnnotationHandler) GetUser(c echo.Context) error {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID")
+	}
+
+	annotation, err := h.annotationService.GetUserAnnotation(userID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal annotation retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve annotation")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"annotation": annotation,
+	})
+}
+
+// SetUser handles the request to replace the notes and labels of a user. Every change is audited.
+func (h *AnnotationHandler) SetUser(c echo.Context) error {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID")
+	}
+
+	return h.set(c, "user", userID, h.annotationService.GetUserAnnotation, h.annotationService.SetUserAnnotation)
+}
+
+// GetProject handles the request to retrieve the annotation of a project.
+func (h *AnnotationHandler) GetProject(c echo.Context) error {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	annotation, err := h.annotationService.GetProjectAnnotation(projectID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal annotation retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve annotation")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"annotation": annotation,
+	})
+}
+
+// SetProject handles the request to replace the notes and labels of a project. Every change is audited.
+func (h *AnnotationHandler) SetProject(c echo.Context) error {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	return h.set(c, "project", projectID, h.annotationService.GetProjectAnnotation, h.annotationService.SetProjectAnnotation)
+}
+
+// set validates the update, records it in the audit log and saves it.
+// The change is recorded before it is saved, so no annotation changes without a trace.
+func (h *AnnotationHandler) set(
+	c echo.Context,
+	targetType string,
+	targetID uuid.UUID,
+	get func(uuid.UUID) (*data.Annotation, error),
+	save func(uuid.UUID, data.AnnotationUpdate, uuid.UUID) (*data.Annotation, error),
+) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var payload data.AnnotationUpdate
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+	payload.Labels = annotations.NormalizeLabels(payload.Labels)
+
+	previous, err := get(targetID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal annotation retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update annotation")
+	}
+
+	err = h.auditService.Record(data.AuditEntry{
+		ActorID:    &contextUser.ID,
+		Action:     data.AuditAnnotationUpdate,
+		TargetType: targetType,
+		TargetID:   targetID.String(),
+		Details: map[string]interface{}{
+			"previous_labels": previous.Labels,
+			"labels":          payload.Labels,
+			"previous_notes":  previous.Notes,
+			"notes":           payload.Notes,
+		},
+		IP: c.RealIP(),
+	})
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal audit log error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to record annotation change")
+	}
+
+	annotation, err := save(targetID, payload, contextUser.ID)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			if targetType == "user" {
+				return echo.NewHTTPError(http.StatusNotFound, "User not found")
+			}
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		logging.Error(c.Request().Context(), "Internal annotation update error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update annotation")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"annotation": annotation,
+	})
+}
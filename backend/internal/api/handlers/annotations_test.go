@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSetUserAnnotation(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	moderator := &data.User{ID: uuid.New(), Username: "moderator"}
+	userID := uuid.New()
+	unknownID := uuid.New()
+	auditFailsID := uuid.New()
+
+	previous := &data.Annotation{Labels: []string{"repeat offender"}, Notes: "Ticket 12"}
+	update := data.AnnotationUpdate{Labels: []string{"repeat offender", "verified educator"}, Notes: "Ticket 12, ticket 40"}
+
+	mockAnnotationService := mocks.MockAnnotationService{}
+	mockAuditService := mocks.MockAuditService{}
+
+	mockAnnotationService.On("GetUserAnnotation", mock.Anything).Return(previous, nil)
+	mockAnnotationService.On("SetUserAnnotation", userID, update, moderator.ID).
+		Return(&data.Annotation{Labels: update.Labels, Notes: update.Notes, UpdatedBy: &moderator.ID}, nil)
+	mockAnnotationService.On("SetUserAnnotation", unknownID, update, moderator.ID).Return(nil, services.ErrRecordNotFound)
+
+	mockAuditService.On("Record", mock.MatchedBy(func(entry data.AuditEntry) bool {
+		return entry.TargetID == auditFailsID.String()
+	})).Return(errors.New("db down"))
+	mockAuditService.On("Record", mock.MatchedBy(func(entry data.AuditEntry) bool {
+		return *entry.ActorID == moderator.ID && entry.Action == data.AuditAnnotationUpdate && entry.TargetType == "user" &&
+			assert.ObjectsAreEqual(previous.Labels, entry.Details["previous_labels"])
+	})).Return(nil)
+
+	handler := NewAnnotationHandler(&mockAnnotationService, &mockAuditService)
+
+	tests := map[string]struct {
+		userID    string
+		reqBody   string
+		wantCode  int
+		wantError bool
+	}{
+		"Labels are normalized": {
+			userID:   userID.String(),
+			reqBody:  `{"labels":["Verified  Educator","repeat offender","repeat offender"],"notes":"Ticket 12, ticket 40"}`,
+			wantCode: http.StatusOK,
+		},
+		"Invalid user ID": {
+			userID:    "abc",
+			reqBody:   `{"labels":[]}`,
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Label too long": {
+			userID:    userID.String(),
+			reqBody:   `{"labels":["` + strings.Repeat("a", 51) + `"]}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Unknown user": {
+			userID:    unknownID.String(),
+			reqBody:   `{"labels":["repeat offender","verified educator"],"notes":"Ticket 12, ticket 40"}`,
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Audit log unavailable": {
+			userID:    auditFailsID.String(),
+			reqBody:   `{"labels":["repeat offender"]}`,
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.userID)
+			c.Set("user", moderator)
+
+			err := handler.SetUser(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), "verified educator")
+			}
+		})
+	}
+
+	// nothing is saved when the change can't be audited
+	mockAnnotationService.AssertNotCalled(t, "SetUserAnnotation", auditFailsID, mock.Anything, mock.Anything)
+}
+
+func TestGetProjectAnnotation(t *testing.T) {
+	e := echo.New()
+
+	projectID := uuid.New()
+	brokenID := uuid.New()
+
+	mockAnnotationService := mocks.MockAnnotationService{}
+	mockAnnotationService.On("GetProjectAnnotation", projectID).Return(&data.Annotation{Labels: []string{"curriculum"}}, nil)
+	mockAnnotationService.On("GetProjectAnnotation", brokenID).Return(nil, errors.New("db down"))
+
+	handler := NewAnnotationHandler(&mockAnnotationService, &mocks.MockAuditService{})
+
+	tests := map[string]struct {
+		projectID string
+		wantCode  int
+		wantError bool
+	}{
+		"Annotated project": {
+			projectID: projectID.String(),
+			wantCode:  http.StatusOK,
+		},
+		"Invalid project ID": {
+			projectID: "abc",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Service error": {
+			projectID: brokenID.String(),
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.projectID)
+
+			err := handler.GetProject(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), "curriculum")
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/announcements"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AnnouncementHandler handles HTTP requests related to announcements.
+type AnnouncementHandler struct {
+	announcementService announcements.IAnnouncementService
+}
+
+// NewAnnouncementHandler creates a new AnnouncementHandler with the provided announcement service.
+func NewAnnouncementHandler(announcementService announcements.IAnnouncementService) AnnouncementHandler {
+	return AnnouncementHandler{
+		announcementService: announcementService,
+	}
+}
+
+// List handles the request to retrieve the announcements shown right now.
+func (h *AnnouncementHandler) List(c echo.Context) error {
+	active, err := h.announcementService.GetActive()
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal announcement retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve announcements")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"announcements": active,
+	})
+}
+
+// Create handles the request to publish an announcement.
+func (h *AnnouncementHandler) Create(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var payload data.AnnouncementCreate
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if payload.StartsAt != nil && payload.EndsAt != nil && !payload.EndsAt.After(*payload.StartsAt) {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, "Announcement must end after it starts")
+	}
+
+	announcement, err := h.announcementService.Create(payload, contextUser.ID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal announcement creation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create announcement")
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"announcement": announcement,
+	})
+}
+
+// Delete handles the request to remove an announcement.
+func (h *AnnouncementHandler) Delete(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid announcement ID")
+	}
+
+	if err := h.announcementService.Delete(id); err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Announcement not found")
+		}
+		logging.Error(c.Request().Context(), "Internal announcement deletion error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete announcement")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateAnnouncement(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	admin := &data.User{ID: uuid.New(), Username: "admin"}
+
+	mockAnnouncementService := mocks.MockAnnouncementService{}
+	mockAnnouncementService.On("Create", mock.MatchedBy(func(a data.AnnouncementCreate) bool {
+		return a.Message == "Maintenance tonight"
+	}), admin.ID).Return(&data.Announcement{ID: 1, Message: "Maintenance tonight", Level: data.AnnouncementWarning, StartsAt: time.Now()}, nil)
+	mockAnnouncementService.On("Create", mock.Anything, mock.Anything).Return(nil, services.ErrInternal)
+
+	handler := NewAnnouncementHandler(&mockAnnouncementService)
+
+	tests := map[string]struct {
+		user      *data.User
+		reqBody   string
+		wantCode  int
+		wantError bool
+	}{
+		"Valid announcement": {
+			user:     admin,
+			reqBody:  `{"message":"Maintenance tonight","level":"warning"}`,
+			wantCode: http.StatusCreated,
+		},
+		"No user in context": {
+			reqBody:   `{"message":"Maintenance tonight"}`,
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Missing message": {
+			user:      admin,
+			reqBody:   `{"level":"info"}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Unknown level": {
+			user:      admin,
+			reqBody:   `{"message":"Maintenance tonight","level":"urgent"}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Ends before it starts": {
+			user:      admin,
+			reqBody:   `{"message":"Maintenance tonight","starts_at":"2030-01-02T00:00:00Z","ends_at":"2030-01-01T00:00:00Z"}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Service error": {
+			user:      admin,
+			reqBody:   `{"message":"Something else"}`,
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			if tt.user != nil {
+				c.Set("user", tt.user)
+			}
+
+			err := handler.Create(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
+
+func TestDeleteAnnouncement(t *testing.T) {
+	e := echo.New()
+
+	mockAnnouncementService := mocks.MockAnnouncementService{}
+	mockAnnouncementService.On("Delete", int64(1)).Return(nil)
+	mockAnnouncementService.On("Delete", int64(2)).Return(services.ErrRecordNotFound)
+
+	handler := NewAnnouncementHandler(&mockAnnouncementService)
+
+	tests := map[string]struct {
+		id        string
+		wantCode  int
+		wantError bool
+	}{
+		"Existing announcement": {
+			id:       "1",
+			wantCode: http.StatusNoContent,
+		},
+		"Unknown announcement": {
+			id:        "2",
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Invalid ID": {
+			id:        "abc",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+
+			err := handler.Delete(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+		})
+	}
+
+	mockAnnouncementService.AssertExpectations(t)
+}
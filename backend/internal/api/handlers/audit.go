@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services/audit"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// auditLogLimit is the number of audit log entries returned when the request doesn't ask for a number.
+const auditLogLimit = 50
+
+// AuditHandler handles HTTP requests for browsing the audit log.
+type AuditHandler struct {
+	auditService audit.IAuditService
+}
+
+// NewAuditHandler creates a new AuditHandler with the provided services.
+func NewAuditHandler(auditService audit.IAuditService) AuditHandler {
+	return AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// List handles the request to list the audit log entries, newest first, a page at a time.
+// The entries can be filtered by a comma separated list of actions, the actor, the target and a time range.
+func (h *AuditHandler) List(c echo.Context) error {
+	var query struct {
+		Action     string     `query:"action"`
+		ActorID    *uuid.UUID `query:"actor_id"`
+		TargetType string     `query:"target_type" validate:"max=50"`
+		TargetID   string     `query:"target_id" validate:"max=200"`
+		After      *time.Time `query:"after"`
+		Before     *time.Time `query:"before"`
+		Cursor     string     `query:"cursor"`
+		Limit      int        `query:"limit" validate:"omitempty,min=1,max=500"`
+	}
+
+	if err := c.Bind(&query); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid query parameters")
+	}
+
+	if err := c.Validate(&query); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	filter := data.AuditFilter{
+		Actions:    []string{},
+		ActorID:    query.ActorID,
+		TargetType: query.TargetType,
+		TargetID:   query.TargetID,
+		After:      query.After,
+		Before:     query.Before,
+		Limit:      query.Limit,
+	}
+
+	for _, action := range strings.Split(query.Action, ",") {
+		if action = strings.TrimSpace(action); action != "" {
+			filter.Actions = append(filter.Actions, action)
+		}
+	}
+
+	if query.Cursor != "" {
+		cursor, err := data.DecodeAuditCursor(query.Cursor)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid cursor")
+		}
+		filter.Cursor = cursor
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = auditLogLimit
+	}
+
+	entries, err := h.auditService.List(filter)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal audit log retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve audit log")
+	}
+
+	// a full page may be followed by more entries
+	var nextCursor *string
+	if len(entries) == filter.Limit {
+		last := entries[len(entries)-1]
+		encoded := data.AuditCursor{Time: last.CreatedAt, ID: last.ID}.Encode()
+		nextCursor = &encoded
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"audit_logs": entries,
+		"meta": map[string]interface{}{
+			"limit":       filter.Limit,
+			"next_cursor": nextCursor,
+		},
+	})
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// auditRecorder returns an audit service accepting every entry, for handlers recording actions
+// that aren't the subject of the test.
+func auditRecorder() *mocks.MockAuditService {
+	mockAuditService := &mocks.MockAuditService{}
+	mockAuditService.On("Record", mock.Anything).Return(nil)
+	return mockAuditService
+}
+
+func TestListAuditLogs(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	actorID := uuid.New()
+	recordedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	page := []data.AuditEntry{
+		{ID: 42, ActorID: &actorID, Action: data.AuditUserBan, CreatedAt: recordedAt},
+		{ID: 41, ActorID: &actorID, Action: data.AuditUserUnban, CreatedAt: recordedAt},
+	}
+	cursor := data.AuditCursor{Time: recordedAt, ID: 41}
+
+	mockAuditService := mocks.MockAuditService{}
+	mockAuditService.On("List", data.AuditFilter{Actions: []string{}, Limit: auditLogLimit}).Return(page, nil)
+	mockAuditService.On("List", data.AuditFilter{Actions: []string{data.AuditUserBan, data.AuditUserUnban}, ActorID: &actorID, Limit: 2}).Return(page, nil)
+	mockAuditService.On("List", data.AuditFilter{Actions: []string{}, Cursor: &cursor, Limit: 2}).Return([]data.AuditEntry{}, nil)
+
+	handler := NewAuditHandler(&mockAuditService)
+
+	tests := map[string]struct {
+		query          string
+		wantCode       int
+		wantError      bool
+		wantNextCursor bool
+	}{
+		"Latest entries": {
+			query:    "",
+			wantCode: http.StatusOK,
+		},
+		"Filtered full page": {
+			query:          "?action=user.ban,user.unban&actor_id=" + actorID.String() + "&limit=2",
+			wantCode:       http.StatusOK,
+			wantNextCursor: true,
+		},
+		"Last page": {
+			query:    "?cursor=" + cursor.Encode() + "&limit=2",
+			wantCode: http.StatusOK,
+		},
+		"Invalid cursor": {
+			query:     "?cursor=not-a-cursor",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Invalid actor ID": {
+			query:     "?actor_id=invalid-uuid",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Limit too large": {
+			query:     "?limit=1000",
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/audit-logs"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.List(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				if tt.wantNextCursor {
+					assert.Contains(t, rec.Body.String(), `"next_cursor":"`+cursor.Encode()+`"`)
+				} else {
+					assert.Contains(t, rec.Body.String(), `"next_cursor":null`)
+				}
+			}
+		})
+	}
+}
@@ -4,12 +4,19 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
+	"NodeTurtleAPI/internal/api/middleware"
+	"NodeTurtleAPI/internal/config"
 	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
 	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/audit"
 	"NodeTurtleAPI/internal/services/auth"
+	"NodeTurtleAPI/internal/services/locations"
 	"NodeTurtleAPI/internal/services/mail"
+	"NodeTurtleAPI/internal/services/passwords"
 	"NodeTurtleAPI/internal/services/tokens"
 	"NodeTurtleAPI/internal/services/users"
 
@@ -18,19 +25,35 @@ import (
 
 // AuthHandler handles HTTP requests related to authentication operations.
 type AuthHandler struct {
-	authService  auth.IAuthService
-	userService  users.IUserService
-	tokenService tokens.ITokenService
-	mailService  mail.IMailService
+	authService     auth.IAuthService
+	oauthService    auth.IOAuthService
+	userService     users.IUserService
+	tokenService    tokens.ITokenService
+	mailService     mail.IMailService
+	locationService locations.ILocationService
+	clientURL       string
+	loginConfig     config.LoginVerificationConfig
+	passwordService passwords.IPasswordService
+	auditService    audit.IAuditService
+	// magicLinks limits the login links requested per email address
+	magicLinks *middleware.Limiter
 }
 
 // NewAuthHandler creates a new AuthHandler with the provided services.
-func NewAuthHandler(authService auth.IAuthService, userService users.IUserService, tokenService tokens.ITokenService, mailService mail.IMailService) AuthHandler {
+// clientURL is the frontend URL users are redirected to after a social login.
+func NewAuthHandler(authService auth.IAuthService, oauthService auth.IOAuthService, userService users.IUserService, tokenService tokens.ITokenService, mailService mail.IMailService, locationService locations.ILocationService, clientURL string, loginConfig config.LoginVerificationConfig, passwordService passwords.IPasswordService, auditService audit.IAuditService) AuthHandler {
 	return AuthHandler{
-		authService:  authService,
-		userService:  userService,
-		tokenService: tokenService,
-		mailService:  mailService,
+		authService:     authService,
+		oauthService:    oauthService,
+		userService:     userService,
+		tokenService:    tokenService,
+		mailService:     mailService,
+		locationService: locationService,
+		clientURL:       strings.TrimRight(clientURL, "/"),
+		loginConfig:     loginConfig,
+		passwordService: passwordService,
+		auditService:    auditService,
+		magicLinks:      newMagicLinkLimiter(loginConfig.MagicLinksPerHour),
 	}
 }
 
@@ -86,6 +109,7 @@ func clearTokenCookies(c echo.Context) {
 
 // Register handles the request to create a new user account.
 // It validates registration data, creates the user, and sends an activation email.
+// The response holds a status token the client can poll the activation with, see TokenHandler.ActivationStatus.
 // Returns an error if the registration data is invalid, if a user with the same
 // email already exists, or if account creation fails.
 func (h *AuthHandler) Register(c echo.Context) error {
@@ -98,7 +122,15 @@ func (h *AuthHandler) Register(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
 	}
 
-	user, err := h.userService.CreateUser(registration)
+	if data.LooksVerified(registration.Username) {
+		return reservedUsername()
+	}
+
+	if strength := h.passwordService.Evaluate(registration.Password, []string{registration.Username, registration.Email}); !strength.Acceptable() {
+		return weakPassword(strength)
+	}
+
+	user, err := h.userService.CreateUser(c.Request().Context(), registration)
 	if err != nil {
 		if errors.Is(err, services.ErrDuplicateEmail) {
 			return echo.NewHTTPError(http.StatusConflict, "Email is already taken")
@@ -106,28 +138,41 @@ func (h *AuthHandler) Register(c echo.Context) error {
 		if errors.Is(err, services.ErrDuplicateUsername) {
 			return echo.NewHTTPError(http.StatusConflict, "Username is already taken")
 		}
-		c.Logger().Errorf("Internal user creation error %v", err)
+		logging.Error(c.Request().Context(), "Internal user creation error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create user")
 	}
 
-	activationToken, err := h.tokenService.New(user.ID, 24*time.Hour, data.ScopeUserActivation)
+	activationToken, err := h.tokenService.New(c.Request().Context(), user.ID, data.ScopeUserActivation)
 	if err != nil {
-		c.Logger().Errorf("Internal activation token creation error %v", err)
+		logging.Error(c.Request().Context(), "Internal activation token creation error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create Activation token")
 	}
 
 	activationLink := fmt.Sprintf("/activate/%s", activationToken.Plaintext)
 	emailData := map[string]string{
-		"Username": user.Username,
-		"url":      activationLink,
+		"Username":  user.Username,
+		"url":       activationLink,
+		"ExpiresAt": formatExpiry(activationToken.ExpiresAt),
+	}
+	// the account exists either way, an email that can't be queued can be asked for again
+	if err := h.mailService.QueueEmail(c.Request().Context(), user.Email, "Activate Your Account", "activation", emailData); err != nil {
+		logging.Error(c.Request().Context(), "Failed to queue activation email", err)
+	}
+
+	statusToken, err := h.tokenService.New(c.Request().Context(), user.ID, data.ScopeActivationStatus)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal activation status token creation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create activation status token")
 	}
-	go h.mailService.SendEmail(user.Email, "Activate Your Account", "activation", emailData)
 
-	return c.NoContent(http.StatusCreated)
+	return c.JSON(http.StatusCreated, map[string]string{
+		"status_token": statusToken.Plaintext,
+	})
 }
 
 // Login handles user authentication requests.
 // It validates login credentials, creates JWT and refresh tokens for successful logins.
+// Logins from a country the user hasn't logged in from before have to be confirmed with an emailed code first, see VerifyLogin.
 // Returns an error if credentials are invalid, if the account is not activated,
 // or if authentication fails.
 func (h *AuthHandler) Login(c echo.Context) error {
@@ -140,31 +185,71 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
 	}
 
-	token, user, err := h.authService.Login(login.Email, login.Password)
+	token, user, err := h.authService.Login(c.Request().Context(), login.Email, login.Password)
 	if err != nil {
 		if errors.Is(err, services.ErrInvalidCredentials) {
+			h.recordLoginFailure(c, login.Email, "invalid_credentials")
 			return echo.NewHTTPError(http.StatusUnauthorized, err)
 		}
 		if errors.Is(err, services.ErrInactiveAccount) {
 			return echo.NewHTTPError(http.StatusForbidden, "INACTIVE_ACCOUNT")
 		}
 		if errors.Is(err, services.ErrAccountSuspended) {
+			h.recordLoginFailure(c, login.Email, "suspended")
 			return echo.NewHTTPError(http.StatusForbidden, err)
 		}
-		c.Logger().Errorf("Internal login error %v", err)
+		logging.Error(c.Request().Context(), "Internal login error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to login")
 	}
 
+	if country := h.loginCountry(c); country != "" {
+		trusted, err := h.locationService.IsTrusted(user.ID, country)
+		if err != nil {
+			logging.Error(c.Request().Context(), "Internal trusted location check error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to login")
+		}
+		if !trusted {
+			return h.requireLoginVerification(c, user, country)
+		}
+		if err := h.locationService.Trust(user.ID, country); err != nil {
+			logging.Error(c.Request().Context(), "Internal trusted location update error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to login")
+		}
+	}
+
+	return startSession(c, h.tokenService, token, user)
+}
+
+// recordLoginFailure adds a failed login to the audit log. Unlike staff actions, the login isn't held up
+// when the failure can't be recorded.
+func (h *AuthHandler) recordLoginFailure(c echo.Context, email, reason string) {
+	err := h.auditService.Record(data.AuditEntry{
+		Action:     data.AuditLoginFailure,
+		TargetType: "email",
+		TargetID:   strings.ToLower(email),
+		Details: map[string]interface{}{
+			"reason":     reason,
+			"user_agent": c.Request().UserAgent(),
+		},
+		IP: c.RealIP(),
+	})
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal audit log error", err)
+	}
+}
+
+// startSession replaces the refresh tokens of the user, sets the token cookies and responds with the session.
+func startSession(c echo.Context, tokenService tokens.ITokenService, token string, user *data.User) error {
 	// delete all refresh tokens
-	if err := h.tokenService.DeleteAllForUser(data.ScopeRefresh, user.ID); err != nil {
-		c.Logger().Errorf("Internal refresh token deletion error %v", err)
+	if err := tokenService.DeleteAllForUser(c.Request().Context(), data.ScopeRefresh, user.ID); err != nil {
+		logging.Error(c.Request().Context(), "Internal refresh token deletion error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete old refresh tokens")
 	}
 
 	// generate a new refresh token
-	refreshToken, err := h.tokenService.New(user.ID, (time.Hour * 168), data.ScopeRefresh)
+	refreshToken, err := tokenService.New(c.Request().Context(), user.ID, data.ScopeRefresh)
 	if err != nil {
-		c.Logger().Errorf("Internal refresh token creation error %v", err)
+		logging.Error(c.Request().Context(), "Internal refresh token creation error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create new refresh token")
 	}
 
@@ -174,10 +259,12 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		"token":        token,
 		"refreshToken": refreshToken.Plaintext,
 		"user": map[string]interface{}{
-			"id":       user.ID,
-			"username": user.Username,
-			"email":    user.Email,
-			"role":     user.Role.Name,
+			"id":                      user.ID,
+			"username":                user.Username,
+			"email":                   user.Email,
+			"role":                    user.Role.Name,
+			"password_reset_required": user.PasswordResetRequired,
+			"guest_expires_at":        user.GuestExpiresAt,
 		},
 	})
 }
@@ -200,12 +287,12 @@ func (h *AuthHandler) RefreshToken(c echo.Context) error {
 		}
 	}
 
-	user, err := h.userService.GetForToken(data.ScopeRefresh, payload.RefreshToken)
+	user, err := h.userService.GetForToken(c.Request().Context(), data.ScopeRefresh, payload.RefreshToken)
 	if err != nil {
 		if errors.Is(err, services.ErrRecordNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, err)
 		}
-		c.Logger().Errorf("Internal user retrieval error %v", err)
+		logging.Error(c.Request().Context(), "Internal user retrieval error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to refresh session")
 	}
 
@@ -213,17 +300,17 @@ func (h *AuthHandler) RefreshToken(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusForbidden, services.BanMessage(user.Ban.Reason, user.Ban.ExpiresAt))
 	}
 
-	h.tokenService.DeleteAllForUser(data.ScopeRefresh, user.ID)
+	h.tokenService.DeleteAllForUser(c.Request().Context(), data.ScopeRefresh, user.ID)
 
 	token, err := h.authService.CreateAccessToken(*user)
 	if err != nil {
-		c.Logger().Errorf("Internal access token creation error %v", err)
+		logging.Error(c.Request().Context(), "Internal access token creation error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create new access token")
 	}
 
-	refreshToken, err := h.tokenService.New(user.ID, (time.Hour * 168), data.ScopeRefresh)
+	refreshToken, err := h.tokenService.New(c.Request().Context(), user.ID, data.ScopeRefresh)
 	if err != nil {
-		c.Logger().Errorf("Internal refresh token creation error %v", err)
+		logging.Error(c.Request().Context(), "Internal refresh token creation error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create new refresh token")
 	}
 
@@ -245,12 +332,37 @@ func (h *AuthHandler) Logout(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
 	}
 
-	if err := h.tokenService.DeleteAllForUser(data.ScopeRefresh, contextUser.ID); err != nil {
+	if err := h.tokenService.DeleteAllForUser(c.Request().Context(), data.ScopeRefresh, contextUser.ID); err != nil {
 		// logging instead of returning to allow user to logout without encountering some erorr
-		c.Logger().Error("Failed to delete refresh tokens on user logout")
+		logging.Error(c.Request().Context(), "Failed to delete refresh tokens on user logout", err)
 	}
 
 	clearTokenCookies(c)
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+// JWKS handles the request for the public keys access tokens are signed with,
+// so other services can verify tokens issued by the API.
+func (h *AuthHandler) JWKS(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, h.authService.JWKS())
+}
+
+// RotateSigningKey handles the request to sign new access tokens with a newly generated key.
+// Tokens signed with the previous key stay valid until they expire.
+func (h *AuthHandler) RotateSigningKey(c echo.Context) error {
+	kid, err := h.authService.RotateSigningKey()
+	if err != nil {
+		if errors.Is(err, services.ErrKeyRotationUnavailable) {
+			return echo.NewHTTPError(http.StatusConflict, "Key rotation requires JWT_KEYS_DIR to be configured")
+		}
+		logging.Error(c.Request().Context(), "Internal signing key rotation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to rotate signing key")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"kid":     kid,
+		"message": "Signing key rotated. Set JWT_PRIMARY_KEY_ID to the new key if the primary key is pinned in the configuration.",
+	})
+}
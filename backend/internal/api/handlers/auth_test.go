@@ -7,9 +7,11 @@ import (
 	"testing"
 	"time"
 
+	"NodeTurtleAPI/internal/config"
 	"NodeTurtleAPI/internal/data"
 	"NodeTurtleAPI/internal/mocks"
 	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/utils"
 
 	"github.com/go-playground/validator"
 	"github.com/google/uuid"
@@ -55,15 +57,23 @@ func TestRegister(t *testing.T) {
 	})).Return(nil, services.ErrDuplicateUsername)
 	mockUserService.On("CreateUser", mock.Anything).Return(nil, services.ErrInternal)
 
-	mockTokenService.On("New", tokenUserId, mock.Anything, data.ScopeUserActivation).Return(nil, services.ErrInternal)
-	mockTokenService.On("New", mock.Anything, mock.Anything, data.ScopeUserActivation).Return(&data.Token{
+	mockTokenService.On("New", tokenUserId, data.ScopeUserActivation).Return(nil, services.ErrInternal)
+	mockTokenService.On("New", mock.Anything, data.ScopeUserActivation).Return(&data.Token{
 		Plaintext: "mocktoken",
 		Scope:     data.ScopeUserActivation,
 	}, nil)
+	mockTokenService.On("New", mock.Anything, data.ScopeActivationStatus).Return(&data.Token{
+		Plaintext: "statustoken",
+		Scope:     data.ScopeActivationStatus,
+	}, nil)
+
+	mockMailerService.On("QueueEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-	mockMailerService.On("SendEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockPasswordService := mocks.MockPasswordService{}
+	mockPasswordService.On("Evaluate", "weak", mock.Anything).Return(data.PasswordStrength{Score: 0, Issues: []data.PasswordIssue{{Code: data.PasswordTooShort, Message: "Password must be at least 8 characters long"}}})
+	mockPasswordService.On("Evaluate", mock.Anything, mock.Anything).Return(data.PasswordStrength{Score: 4, Issues: []data.PasswordIssue{}})
 
-	handler := NewAuthHandler(&mockAuthService, &mockUserService, &mockTokenService, &mockMailerService)
+	handler := NewAuthHandler(&mockAuthService, &mocks.MockOAuthService{}, &mockUserService, &mockTokenService, &mockMailerService, &mocks.MockLocationService{}, "", config.LoginVerificationConfig{}, &mockPasswordService, auditRecorder())
 
 	tests := map[string]struct {
 		reqBody   string
@@ -85,6 +95,11 @@ func TestRegister(t *testing.T) {
 			wantCode:  http.StatusUnprocessableEntity,
 			wantError: true,
 		},
+		"Verified-looking username": {
+			reqBody:   `{"email":"test@test.test","username":"VerifiedTeacher","password":"TestPassword123"}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
 		"Email taken": {
 			reqBody:   `{"email":"exists@test.test","username":"testuser","password":"TestPassword123"}`,
 			wantCode:  http.StatusConflict,
@@ -112,6 +127,11 @@ func TestRegister(t *testing.T) {
 			wantCode:  http.StatusUnprocessableEntity,
 			wantError: true,
 		},
+		"Missing password": {
+			reqBody:   `{"email":"test@test.test","username":"testuser"}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
 		"Malformed JSON triggers bind error": {
 			reqBody:   `{"email":"test@test.test`,
 			wantCode:  http.StatusBadRequest,
@@ -146,6 +166,7 @@ func TestRegister(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.JSONEq(t, `{"status_token":"statustoken"}`, rec.Body.String())
 			}
 		})
 	}
@@ -153,7 +174,7 @@ func TestRegister(t *testing.T) {
 	mockUserService.AssertExpectations(t)
 	mockTokenService.AssertExpectations(t)
 	mockMailerService.AssertExpectations(t)
-
+	mockPasswordService.AssertExpectations(t)
 }
 
 func TestLogin(t *testing.T) {
@@ -178,10 +199,12 @@ func TestLogin(t *testing.T) {
 	mockAuthService.On("Login", "banned@test.test", "TestPassword123").Return("", nil, services.ErrAccountSuspended)
 	mockAuthService.On("Login", mock.Anything, mock.Anything).Return("", nil, services.ErrInternal)
 
-	mockTokenService.On("New", mock.Anything, mock.Anything, mock.Anything).Return(&data.Token{UserID: uuid.New(), ExpiresAt: time.Now().UTC().Add(time.Hour), Scope: data.ScopeRefresh}, nil)
+	mockTokenService.On("New", mock.Anything, mock.Anything).Return(&data.Token{UserID: uuid.New(), ExpiresAt: time.Now().UTC().Add(time.Hour), Scope: data.ScopeRefresh}, nil)
 	mockTokenService.On("DeleteAllForUser", mock.Anything, mock.Anything).Return(nil)
 
-	handler := NewAuthHandler(&mockAuthService, &mockUserService, &mockTokenService, &mockMailerService)
+	mockAuditService := auditRecorder()
+
+	handler := NewAuthHandler(&mockAuthService, &mocks.MockOAuthService{}, &mockUserService, &mockTokenService, &mockMailerService, &mocks.MockLocationService{}, "", config.LoginVerificationConfig{}, &mocks.MockPasswordService{}, mockAuditService)
 
 	tests := map[string]struct {
 		reqBody   string
@@ -257,6 +280,12 @@ func TestLogin(t *testing.T) {
 	}
 
 	mockAuthService.AssertExpectations(t)
+
+	// failed logins are recorded, whether the password was wrong or the account is suspended
+	mockAuditService.AssertNumberOfCalls(t, "Record", 2)
+	mockAuditService.AssertCalled(t, "Record", mock.MatchedBy(func(entry data.AuditEntry) bool {
+		return entry.Action == data.AuditLoginFailure && entry.TargetID == "wrong@test.test" && entry.Details["reason"] == "invalid_credentials"
+	}))
 }
 
 func TestRefreshToken(t *testing.T) {
@@ -270,7 +299,7 @@ func TestRefreshToken(t *testing.T) {
 
 	validUser := &data.User{ID: uuid.New(), Email: "test@test.test", Username: "testuser", IsActivated: true}
 	bannedUser := &data.User{ID: uuid.New(), Email: "test2@test.test", Username: "testuser2", IsActivated: true, Ban: &data.Ban{
-		ExpiresAt: time.Now().Add(time.Hour),
+		ExpiresAt: utils.Ptr(time.Now().Add(time.Hour)),
 	}}
 	refreshToken := "valid-refresh-token"
 	newAccessToken := "new-access-token"
@@ -281,10 +310,10 @@ func TestRefreshToken(t *testing.T) {
 	mockUserService.On("GetForToken", data.ScopeRefresh, "internalerror").Return(nil, services.ErrInternal)
 	mockUserService.On("GetForToken", data.ScopeRefresh, "banned").Return(bannedUser, nil)
 	mockAuthService.On("CreateAccessToken", *validUser).Return(newAccessToken, nil)
-	mockTokenService.On("New", validUser.ID, mock.Anything, data.ScopeRefresh).Return(newRefreshToken, nil)
+	mockTokenService.On("New", validUser.ID, data.ScopeRefresh).Return(newRefreshToken, nil)
 	mockTokenService.On("DeleteAllForUser", data.ScopeRefresh, validUser.ID).Return(nil)
 
-	handler := NewAuthHandler(&mockAuthService, &mockUserService, &mockTokenService, &mockMailerService)
+	handler := NewAuthHandler(&mockAuthService, &mocks.MockOAuthService{}, &mockUserService, &mockTokenService, &mockMailerService, &mocks.MockLocationService{}, "", config.LoginVerificationConfig{}, &mocks.MockPasswordService{}, auditRecorder())
 
 	tests := map[string]struct {
 		body      string
@@ -363,7 +392,7 @@ func TestLogout(t *testing.T) {
 
 	mockTokenService.On("DeleteAllForUser", data.ScopeRefresh, userID).Return(nil)
 
-	handler := NewAuthHandler(&mockAuthService, &mockUserService, &mockTokenService, &mockMailerService)
+	handler := NewAuthHandler(&mockAuthService, &mocks.MockOAuthService{}, &mockUserService, &mockTokenService, &mockMailerService, &mocks.MockLocationService{}, "", config.LoginVerificationConfig{}, &mocks.MockPasswordService{}, auditRecorder())
 
 	tests := map[string]struct {
 		contextUser interface{}
@@ -408,3 +437,75 @@ func TestLogout(t *testing.T) {
 
 	mockTokenService.AssertExpectations(t)
 }
+
+func TestRotateSigningKey(t *testing.T) {
+	e := echo.New()
+
+	tests := map[string]struct {
+		rotateErr error
+		wantCode  int
+		wantError bool
+	}{
+		"Rotated": {
+			wantCode: http.StatusOK,
+		},
+		"Rotation unavailable": {
+			rotateErr: services.ErrKeyRotationUnavailable,
+			wantCode:  http.StatusConflict,
+			wantError: true,
+		},
+		"Internal error": {
+			rotateErr: services.ErrInternal,
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockAuthService := mocks.MockAuthService{}
+			if tt.rotateErr != nil {
+				mockAuthService.On("RotateSigningKey").Return("", tt.rotateErr)
+			} else {
+				mockAuthService.On("RotateSigningKey").Return("new-kid", nil)
+			}
+			handler := NewAuthHandler(&mockAuthService, &mocks.MockOAuthService{}, &mocks.MockUserService{}, &mocks.MockTokenService{}, &mocks.MockMailService{}, &mocks.MockLocationService{}, "", config.LoginVerificationConfig{}, &mocks.MockPasswordService{}, auditRecorder())
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.RotateSigningKey(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), "new-kid")
+			}
+		})
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	e := echo.New()
+
+	mockAuthService := mocks.MockAuthService{}
+	mockAuthService.On("JWKS").Return(data.JWKS{Keys: []data.JWK{{Kty: "RSA", Alg: "RS256", Kid: "current", N: "n", E: "AQAB"}}})
+	handler := NewAuthHandler(&mockAuthService, &mocks.MockOAuthService{}, &mocks.MockUserService{}, &mocks.MockTokenService{}, &mocks.MockMailService{}, &mocks.MockLocationService{}, "", config.LoginVerificationConfig{}, &mocks.MockPasswordService{}, auditRecorder())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/jwks", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := handler.JWKS(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"kid":"current"`)
+	assert.NotEmpty(t, rec.Header().Get("Cache-Control"))
+}
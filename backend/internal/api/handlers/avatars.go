@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/avatars"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// AvatarHandler handles HTTP requests to upload, remove and serve user avatars.
+type AvatarHandler struct {
+	avatarService avatars.IAvatarService
+	cfg           config.AvatarsConfig
+}
+
+// NewAvatarHandler creates a new AvatarHandler with the provided avatar service and upload limits.
+func NewAvatarHandler(avatarService avatars.IAvatarService, cfg config.AvatarsConfig) AvatarHandler {
+	return AvatarHandler{
+		avatarService: avatarService,
+		cfg:           cfg,
+	}
+}
+
+// Upload handles the request of a user to replace their avatar with an image uploaded as the "avatar" field of a multipart form.
+func (h *AvatarHandler) Upload(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	fileHeader, err := c.FormFile("avatar")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Missing avatar file")
+	}
+	tooLarge := echo.NewHTTPError(http.StatusRequestEntityTooLarge, fmt.Sprintf("Avatar exceeds the limit of %d bytes", h.cfg.MaxBytes))
+	if h.cfg.MaxBytes > 0 && fileHeader.Size > int64(h.cfg.MaxBytes) {
+		return tooLarge
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid avatar file")
+	}
+	defer file.Close()
+
+	upload, err := io.ReadAll(file)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid avatar file")
+	}
+
+	avatar, err := h.avatarService.SetAvatar(c.Request().Context(), contextUser.ID, upload)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidImage):
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, "Avatar must be a PNG, JPEG or GIF image")
+		case errors.Is(err, services.ErrImageTooLarge):
+			return tooLarge
+		case errors.Is(err, services.ErrUserNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, "User not found")
+		}
+		logging.Error(c.Request().Context(), "Internal avatar upload error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to upload avatar")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"avatar": avatar,
+	})
+}
+
+// Delete handles the request of a user to remove their avatar.
+func (h *AvatarHandler) Delete(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	if err := h.avatarService.DeleteAvatar(c.Request().Context(), contextUser.ID); err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "User not found")
+		}
+		logging.Error(c.Request().Context(), "Internal avatar deletion error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete avatar")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Avatar deleted successfully",
+	})
+}
+
+// Get handles the request to retrieve an image of an avatar, no session needed.
+// Every upload is served under new names, so the images can be cached for good.
+func (h *AvatarHandler) Get(c echo.Context) error {
+	userID, err := uuid.Parse(c.Param("userID"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Avatar not found")
+	}
+
+	content, contentType, err := h.avatarService.GetImage(c.Request().Context(), userID, c.Param("name"))
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Avatar not found")
+		}
+		logging.Error(c.Request().Context(), "Internal avatar retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve avatar")
+	}
+
+	c.Response().Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	c.Response().Header().Set("X-Content-Type-Options", "nosniff")
+	return c.Blob(http.StatusOK, contentType, content)
+}
@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func multipartAvatar(t *testing.T, field string, content []byte) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile(field, "avatar.png")
+	assert.NoError(t, err)
+	_, err = part.Write(content)
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+	return body, writer.FormDataContentType()
+}
+
+func TestUploadAvatar(t *testing.T) {
+	e := echo.New()
+	user := &data.User{ID: uuid.New(), Username: "alice", IsActivated: true}
+	upload := []byte("image")
+
+	tests := map[string]struct {
+		contextUser *data.User
+		field       string
+		content     []byte
+		setupMocks  func(m *mocks.MockAvatarService)
+		wantCode    int
+		wantError   bool
+	}{
+		"User not authenticated": {
+			field:     "avatar",
+			content:   upload,
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Missing file": {
+			contextUser: user,
+			field:       "file",
+			content:     upload,
+			wantCode:    http.StatusBadRequest,
+			wantError:   true,
+		},
+		"File too large": {
+			contextUser: user,
+			field:       "avatar",
+			content:     bytes.Repeat([]byte("a"), 101),
+			wantCode:    http.StatusRequestEntityTooLarge,
+			wantError:   true,
+		},
+		"Not an image": {
+			contextUser: user,
+			field:       "avatar",
+			content:     upload,
+			setupMocks: func(m *mocks.MockAvatarService) {
+				m.On("SetAvatar", user.ID, upload).Return(nil, services.ErrInvalidImage)
+			},
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Too many pixels": {
+			contextUser: user,
+			field:       "avatar",
+			content:     upload,
+			setupMocks: func(m *mocks.MockAvatarService) {
+				m.On("SetAvatar", user.ID, upload).Return(nil, services.ErrImageTooLarge)
+			},
+			wantCode:  http.StatusRequestEntityTooLarge,
+			wantError: true,
+		},
+		"Avatar uploaded": {
+			contextUser: user,
+			field:       "avatar",
+			content:     upload,
+			setupMocks: func(m *mocks.MockAvatarService) {
+				m.On("SetAvatar", user.ID, upload).Return(data.NewAvatar("avatars/"+user.ID.String()+"/0123456789abcdef"), nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Storage error": {
+			contextUser: user,
+			field:       "avatar",
+			content:     upload,
+			setupMocks: func(m *mocks.MockAvatarService) {
+				m.On("SetAvatar", user.ID, upload).Return(nil, errors.New("storage error"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockAvatarService := mocks.MockAvatarService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockAvatarService)
+			}
+			handler := NewAvatarHandler(&mockAvatarService, config.AvatarsConfig{MaxBytes: 100})
+
+			body, contentType := multipartAvatar(t, tt.field, tt.content)
+			req := httptest.NewRequest(http.MethodPost, "/", body)
+			req.Header.Set(echo.HeaderContentType, contentType)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.Upload(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), "/api/avatars/"+user.ID.String()+"/0123456789abcdef-64.png")
+			}
+			mockAvatarService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteAvatar(t *testing.T) {
+	e := echo.New()
+	user := &data.User{ID: uuid.New(), Username: "alice", IsActivated: true}
+
+	tests := map[string]struct {
+		contextUser *data.User
+		setupMocks  func(m *mocks.MockAvatarService)
+		wantCode    int
+		wantError   bool
+	}{
+		"User not authenticated": {
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Avatar deleted": {
+			contextUser: user,
+			setupMocks: func(m *mocks.MockAvatarService) {
+				m.On("DeleteAvatar", user.ID).Return(nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Database error": {
+			contextUser: user,
+			setupMocks: func(m *mocks.MockAvatarService) {
+				m.On("DeleteAvatar", user.ID).Return(errors.New("database error"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockAvatarService := mocks.MockAvatarService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockAvatarService)
+			}
+			handler := NewAvatarHandler(&mockAvatarService, config.AvatarsConfig{})
+
+			req := httptest.NewRequest(http.MethodDelete, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.Delete(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+			mockAvatarService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetAvatar(t *testing.T) {
+	e := echo.New()
+	userID := uuid.New()
+
+	tests := map[string]struct {
+		userID     string
+		name       string
+		setupMocks func(m *mocks.MockAvatarService)
+		wantCode   int
+		wantError  bool
+	}{
+		"Invalid user ID": {
+			userID:    "abc",
+			name:      "0123456789abcdef-64.png",
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Avatar not found": {
+			userID: userID.String(),
+			name:   "0123456789abcdef-64.png",
+			setupMocks: func(m *mocks.MockAvatarService) {
+				m.On("GetImage", userID, "0123456789abcdef-64.png").Return(nil, "", services.ErrRecordNotFound)
+			},
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Avatar found": {
+			userID: userID.String(),
+			name:   "0123456789abcdef-64.png",
+			setupMocks: func(m *mocks.MockAvatarService) {
+				m.On("GetImage", userID, "0123456789abcdef-64.png").Return([]byte("png"), "image/png", nil)
+			},
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockAvatarService := mocks.MockAvatarService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockAvatarService)
+			}
+			handler := NewAvatarHandler(&mockAvatarService, config.AvatarsConfig{})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("userID", "name")
+			c.SetParamValues(tt.userID, tt.name)
+
+			err := handler.Get(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Equal(t, "image/png", rec.Header().Get(echo.HeaderContentType))
+				assert.Contains(t, rec.Header().Get("Cache-Control"), "immutable")
+			}
+			mockAvatarService.AssertExpectations(t)
+		})
+	}
+}
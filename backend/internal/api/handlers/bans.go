@@ -0,0 +1,442 @@
+package handlers
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/audit"
+	"NodeTurtleAPI/internal/services/mail"
+	"NodeTurtleAPI/internal/services/users"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// BanHandler handles HTTP requests for the ban history of users, the appeals of banned users and the IP ban list.
+type BanHandler struct {
+	userService   users.IUserService
+	banService    services.IBanService
+	mailService   mail.IMailService
+	auditService  audit.IAuditService
+	ipBansChanged func()
+}
+
+// NewBanHandler creates a new BanHandler with the provided services.
+// ipBansChanged is called after the IP ban list changes, for the change to be enforced right away.
+func NewBanHandler(userService users.IUserService, banService services.IBanService, mailService mail.IMailService, auditService audit.IAuditService, ipBansChanged func()) BanHandler {
+	return BanHandler{
+		userService:   userService,
+		banService:    banService,
+		mailService:   mailService,
+		auditService:  auditService,
+		ipBansChanged: ipBansChanged,
+	}
+}
+
+// banExpiry returns when a ban of duration hours made now expires, nil for a permanent ban.
+// It reports false when neither a duration nor a permanent ban was asked for.
+func banExpiry(duration int, permanent bool) (*time.Time, bool) {
+	if permanent {
+		return nil, true
+	}
+	if duration <= 0 {
+		return nil, false
+	}
+	expiresAt := time.Now().UTC().Add(time.Duration(duration) * time.Hour)
+	return &expiresAt, true
+}
+
+// History handles the request to retrieve every ban and unban of a user.
+func (h *BanHandler) History(c echo.Context) error {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID")
+	}
+
+	history, err := h.banService.ListBanHistory(c.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "User not found")
+		}
+		logging.Error(c.Request().Context(), "Internal ban history error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve ban history")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"bans": history,
+	})
+}
+
+// Appeal handles a banned user's appeal of their ban. Banned users can't sign in, so the appeal is authenticated
+// with the email and password of the account instead of a session.
+func (h *BanHandler) Appeal(c echo.Context) error {
+	var payload data.BanAppealSubmission
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	user, err := h.userService.GetUserByEmail(c.Request().Context(), payload.Email)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			return echo.NewHTTPError(http.StatusUnauthorized, services.ErrInvalidCredentials)
+		}
+		logging.Error(c.Request().Context(), "Internal user retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to appeal ban")
+	}
+
+	matches, err := user.Password.Matches(payload.Password)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal password matching error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to appeal ban")
+	}
+	if !matches {
+		return echo.NewHTTPError(http.StatusUnauthorized, services.ErrInvalidCredentials)
+	}
+
+	appeal, err := h.banService.AppealBan(c.Request().Context(), user.ID, payload.Message)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotBanned):
+			return echo.NewHTTPError(http.StatusConflict, "Account is not banned")
+		case errors.Is(err, services.ErrAlreadyAppealed):
+			return echo.NewHTTPError(http.StatusConflict, "The ban has been appealed already")
+		}
+		logging.Error(c.Request().Context(), "Internal ban appeal error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to appeal ban")
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": "Appeal submitted, you will be notified of the decision by email",
+		"appeal":  appeal,
+	})
+}
+
+// ListAppeals handles the request to retrieve the appeals waiting for review.
+func (h *BanHandler) ListAppeals(c echo.Context) error {
+	appeals, err := h.banService.ListOpenAppeals(c.Request().Context())
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal ban appeal listing error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve appeals")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"appeals": appeals,
+	})
+}
+
+// ResolveAppeal handles the request to accept or reject an appeal, accepting it lifts the ban.
+// Only admins may accept appeals of users ranked the same or higher than themselves, as with bans.
+// The decision is recorded in the audit log before it's saved, and the user is notified by email.
+func (h *BanHandler) ResolveAppeal(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	appealID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid appeal ID")
+	}
+
+	var payload data.BanAppealDecision
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	appeal, err := h.banService.GetAppeal(c.Request().Context(), appealID)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Appeal not found")
+		}
+		logging.Error(c.Request().Context(), "Internal ban appeal retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to resolve appeal")
+	}
+
+	if appeal.Status != data.AppealOpen {
+		return echo.NewHTTPError(http.StatusConflict, "Appeal was already resolved")
+	}
+
+	if contextUser.Role.Name != data.RoleAdmin.String() {
+		appellant, err := h.userService.GetUserByID(c.Request().Context(), appeal.UserID)
+		if err != nil {
+			logging.Error(c.Request().Context(), "Internal user retrieval error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to resolve appeal")
+		}
+		if appellant.Role.ID >= contextUser.Role.ID {
+			return echo.NewHTTPError(http.StatusForbidden, "Cannot resolve the appeal of a user with an equal or higher role")
+		}
+	}
+
+	err = h.auditService.Record(data.AuditEntry{
+		ActorID:    &contextUser.ID,
+		Action:     data.AuditBanAppealReview,
+		TargetType: "user",
+		TargetID:   appeal.UserID.String(),
+		Details: map[string]interface{}{
+			"appeal_id": appeal.ID,
+			"status":    payload.Status,
+		},
+		IP: c.RealIP(),
+	})
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal audit log error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to record appeal decision")
+	}
+
+	appeal, err = h.banService.ResolveAppeal(c.Request().Context(), appealID, contextUser.ID, payload)
+	if err != nil {
+		// resolved by another moderator in the meantime
+		if errors.Is(err, services.ErrAppealResolved) {
+			return echo.NewHTTPError(http.StatusConflict, "Appeal was already resolved")
+		}
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Appeal not found")
+		}
+		logging.Error(c.Request().Context(), "Internal ban appeal resolution error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to resolve appeal")
+	}
+
+	emailData := map[string]string{
+		"Username":   appeal.Username,
+		"Decision":   "Rejected",
+		"Resolution": appeal.Resolution,
+		"ExpiresAt":  "Never",
+		"Outcome":    "Your account remains permanently suspended.",
+	}
+	if appeal.BanExpiresAt != nil {
+		emailData["ExpiresAt"] = formatExpiry(*appeal.BanExpiresAt)
+		emailData["Outcome"] = "Your account remains suspended until the date shown above."
+	}
+	if appeal.Status == data.AppealAccepted {
+		emailData["Decision"] = "Accepted"
+		emailData["ExpiresAt"] = "Lifted"
+		emailData["Outcome"] = "Your suspension has been lifted and you can log in again."
+	}
+	if err := h.mailService.QueueEmail(c.Request().Context(), appeal.Email, "Ban appeal reviewed - Turtle Graphics", "ban_appeal", emailData); err != nil {
+		logging.Error(c.Request().Context(), "Failed to queue ban appeal email", err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"appeal": appeal,
+	})
+}
+
+// ListIPBans handles the request to list the IP bans, including expired ones.
+func (h *BanHandler) ListIPBans(c echo.Context) error {
+	bans, err := h.banService.ListIPBans(c.Request().Context())
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal IP ban retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve IP bans")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"bans": bans,
+	})
+}
+
+// GetIPBan handles the request to retrieve a single IP ban.
+func (h *BanHandler) GetIPBan(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid IP ban ID")
+	}
+
+	ban, err := h.banService.GetIPBan(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "IP ban not found")
+		}
+		logging.Error(c.Request().Context(), "Internal IP ban retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve IP ban")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"ban": ban,
+	})
+}
+
+// AddIPBan handles the request to ban a range of IP addresses. A range holding the IP of the moderator
+// is refused, so they can't lock themselves out. The ban is recorded in the audit log before it is saved.
+func (h *BanHandler) AddIPBan(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var payload data.IPBanCreate
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	expiresAt, ok := banExpiry(payload.Duration, payload.Permanent)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, "A duration is required unless the ban is permanent")
+	}
+
+	if subnetContains(payload.Subnet, c.RealIP()) {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, "Cannot ban a range holding your own IP address")
+	}
+
+	if err := h.recordIPBan(c, contextUser, data.AuditIPBanAdd, payload.Subnet, payload.Reason, expiresAt); err != nil {
+		return err
+	}
+
+	ban, err := h.banService.AddIPBan(c.Request().Context(), payload.Subnet, payload.Reason, expiresAt, contextUser.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrIPAlreadyBanned):
+			return echo.NewHTTPError(http.StatusConflict, "IP range is already banned")
+		case errors.Is(err, services.ErrInvalidData):
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, "Invalid subnet")
+		}
+		logging.Error(c.Request().Context(), "Internal IP ban creation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to ban IP range")
+	}
+	h.ipBansChanged()
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"ban": ban,
+	})
+}
+
+// UpdateIPBan handles the request to change the reason and the expiry of an IP ban, the new duration counting from now.
+// The change is recorded in the audit log before it is saved.
+func (h *BanHandler) UpdateIPBan(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid IP ban ID")
+	}
+
+	var payload data.IPBanUpdate
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	expiresAt, ok := banExpiry(payload.Duration, payload.Permanent)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, "A duration is required unless the ban is permanent")
+	}
+
+	ban, err := h.banService.GetIPBan(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "IP ban not found")
+		}
+		logging.Error(c.Request().Context(), "Internal IP ban retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update IP ban")
+	}
+
+	if err := h.recordIPBan(c, contextUser, data.AuditIPBanUpdate, ban.Subnet, payload.Reason, expiresAt); err != nil {
+		return err
+	}
+
+	ban, err = h.banService.UpdateIPBan(c.Request().Context(), id, payload.Reason, expiresAt)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "IP ban not found")
+		}
+		logging.Error(c.Request().Context(), "Internal IP ban update error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update IP ban")
+	}
+	h.ipBansChanged()
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"ban": ban,
+	})
+}
+
+// RemoveIPBan handles the request to lift an IP ban.
+// The change is recorded in the audit log before it is saved.
+func (h *BanHandler) RemoveIPBan(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid IP ban ID")
+	}
+
+	ban, err := h.banService.GetIPBan(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "IP ban not found")
+		}
+		logging.Error(c.Request().Context(), "Internal IP ban retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to lift IP ban")
+	}
+
+	if err := h.recordIPBan(c, contextUser, data.AuditIPBanRemove, ban.Subnet, ban.Reason, ban.ExpiresAt); err != nil {
+		return err
+	}
+
+	if err := h.banService.RemoveIPBan(c.Request().Context(), id); err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "IP ban not found")
+		}
+		logging.Error(c.Request().Context(), "Internal IP ban removal error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to lift IP ban")
+	}
+	h.ipBansChanged()
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// recordIPBan adds a change of the IP ban of a subnet to the audit log.
+func (h *BanHandler) recordIPBan(c echo.Context, actor *data.User, action, subnet, reason string, expiresAt *time.Time) error {
+	err := h.auditService.Record(data.AuditEntry{
+		ActorID:    &actor.ID,
+		Action:     action,
+		TargetType: "ip_ban",
+		TargetID:   subnet,
+		Details: map[string]interface{}{
+			"reason":     reason,
+			"expires_at": expiresAt,
+		},
+		IP: c.RealIP(),
+	})
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal audit log error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to record IP ban change")
+	}
+	return nil
+}
+
+// subnetContains reports whether the subnet in CIDR notation, or the single IP, holds ip.
+func subnetContains(subnet, ip string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	if single := net.ParseIP(subnet); single != nil {
+		return single.Equal(addr)
+	}
+	_, network, err := net.ParseCIDR(subnet)
+	return err == nil && network.Contains(addr)
+}
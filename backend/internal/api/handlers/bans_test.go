@@ -0,0 +1,441 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBanHistory(t *testing.T) {
+	e := echo.New()
+
+	userID := uuid.New()
+	unknownID := uuid.New()
+	failingID := uuid.New()
+	expiresAt := time.Now().Add(24 * time.Hour)
+
+	mockBanService := new(mocks.MockBanService)
+	mockBanService.On("ListBanHistory", userID).Return([]data.BanEvent{
+		{ID: 1, UserID: userID, Action: data.BanActionBan, Reason: "spam", ExpiresAt: &expiresAt},
+		{ID: 2, UserID: userID, Action: data.BanActionUnban},
+	}, nil)
+	mockBanService.On("ListBanHistory", unknownID).Return(nil, services.ErrUserNotFound)
+	mockBanService.On("ListBanHistory", failingID).Return(nil, services.ErrInternal)
+
+	handler := NewBanHandler(&mocks.MockUserService{}, mockBanService, &mocks.MockMailService{}, &mocks.MockAuditService{}, func() {})
+
+	tests := map[string]struct {
+		userID   string
+		wantCode int
+	}{
+		"lists the bans and unbans": {
+			userID:   userID.String(),
+			wantCode: http.StatusOK,
+		},
+		"invalid user id": {
+			userID:   "1234",
+			wantCode: http.StatusBadRequest,
+		},
+		"user not found": {
+			userID:   unknownID.String(),
+			wantCode: http.StatusNotFound,
+		},
+		"internal error": {
+			userID:   failingID.String(),
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+			c.SetPath("/api/admin/users/:id/bans")
+			c.SetParamNames("id")
+			c.SetParamValues(tt.userID)
+
+			err := handler.History(c)
+
+			if tt.wantCode != http.StatusOK {
+				he, ok := err.(*echo.HTTPError)
+				assert.True(t, ok)
+				assert.Equal(t, tt.wantCode, he.Code)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"action":"unban"`)
+			}
+		})
+	}
+}
+
+func TestAppealBan(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	banned := &data.User{ID: uuid.New(), Email: "banned@test.test", Username: "banned"}
+	assert.NoError(t, banned.Password.Set("password123"))
+	appealed := &data.User{ID: uuid.New(), Email: "appealed@test.test", Username: "appealed"}
+	assert.NoError(t, appealed.Password.Set("password123"))
+	notBanned := &data.User{ID: uuid.New(), Email: "free@test.test", Username: "free"}
+	assert.NoError(t, notBanned.Password.Set("password123"))
+
+	mockUserService := new(mocks.MockUserService)
+	mockUserService.On("GetUserByEmail", banned.Email).Return(banned, nil)
+	mockUserService.On("GetUserByEmail", appealed.Email).Return(appealed, nil)
+	mockUserService.On("GetUserByEmail", notBanned.Email).Return(notBanned, nil)
+	mockUserService.On("GetUserByEmail", mock.Anything).Return(nil, services.ErrUserNotFound)
+
+	mockBanService := new(mocks.MockBanService)
+	mockBanService.On("AppealBan", banned.ID, "I was hacked").Return(&data.BanAppeal{ID: uuid.New(), UserID: banned.ID, Status: data.AppealOpen}, nil)
+	mockBanService.On("AppealBan", appealed.ID, mock.Anything).Return(nil, services.ErrAlreadyAppealed)
+	mockBanService.On("AppealBan", notBanned.ID, mock.Anything).Return(nil, services.ErrNotBanned)
+
+	handler := NewBanHandler(mockUserService, mockBanService, &mocks.MockMailService{}, &mocks.MockAuditService{}, func() {})
+
+	tests := map[string]struct {
+		body     string
+		wantCode int
+	}{
+		"appeal submitted": {
+			body:     `{"email":"banned@test.test","password":"password123","message":"I was hacked"}`,
+			wantCode: http.StatusCreated,
+		},
+		"wrong password": {
+			body:     `{"email":"banned@test.test","password":"wrong","message":"I was hacked"}`,
+			wantCode: http.StatusUnauthorized,
+		},
+		"unknown email": {
+			body:     `{"email":"nobody@test.test","password":"password123","message":"I was hacked"}`,
+			wantCode: http.StatusUnauthorized,
+		},
+		"missing message": {
+			body:     `{"email":"banned@test.test","password":"password123"}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		"invalid body": {
+			body:     `{`,
+			wantCode: http.StatusBadRequest,
+		},
+		"ban appealed already": {
+			body:     `{"email":"appealed@test.test","password":"password123","message":"Please"}`,
+			wantCode: http.StatusConflict,
+		},
+		"account not banned": {
+			body:     `{"email":"free@test.test","password":"password123","message":"Please"}`,
+			wantCode: http.StatusConflict,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.Appeal(c)
+
+			if tt.wantCode != http.StatusCreated {
+				he, ok := err.(*echo.HTTPError)
+				assert.True(t, ok)
+				assert.Equal(t, tt.wantCode, he.Code)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+		})
+	}
+
+	mockBanService.AssertExpectations(t)
+}
+
+func TestResolveAppeal(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	adminUser := &data.User{ID: uuid.New(), Role: data.Role{ID: data.RoleAdmin.ToID(), Name: data.RoleAdmin.String()}}
+	moderatorUser := &data.User{ID: uuid.New(), Role: data.Role{ID: data.RoleModerator.ToID(), Name: data.RoleModerator.String()}}
+	bannedUser := &data.User{ID: uuid.New(), Role: data.Role{ID: data.RoleUser.ToID(), Name: data.RoleUser.String()}}
+	bannedModerator := &data.User{ID: uuid.New(), Role: data.Role{ID: data.RoleModerator.ToID(), Name: data.RoleModerator.String()}}
+
+	expiresAt := time.Now().Add(24 * time.Hour)
+	openAppeal := &data.BanAppeal{ID: uuid.New(), UserID: bannedUser.ID, Email: "banned@test.test", BanExpiresAt: &expiresAt, Status: data.AppealOpen}
+	moderatorAppeal := &data.BanAppeal{ID: uuid.New(), UserID: bannedModerator.ID, Status: data.AppealOpen}
+	resolvedAppeal := &data.BanAppeal{ID: uuid.New(), UserID: bannedUser.ID, Status: data.AppealRejected}
+	racedAppeal := &data.BanAppeal{ID: uuid.New(), UserID: bannedUser.ID, Status: data.AppealOpen}
+	unknownID := uuid.New()
+
+	accept := data.BanAppealDecision{Status: data.AppealAccepted, Resolution: "Welcome back"}
+
+	mockUserService := new(mocks.MockUserService)
+	mockUserService.On("GetUserByID", bannedUser.ID).Return(bannedUser, nil)
+	mockUserService.On("GetUserByID", bannedModerator.ID).Return(bannedModerator, nil)
+
+	mockBanService := new(mocks.MockBanService)
+	for _, a := range []*data.BanAppeal{openAppeal, moderatorAppeal, resolvedAppeal, racedAppeal} {
+		mockBanService.On("GetAppeal", a.ID).Return(a, nil)
+	}
+	mockBanService.On("GetAppeal", unknownID).Return(nil, services.ErrRecordNotFound)
+	mockBanService.On("ResolveAppeal", openAppeal.ID, mock.Anything, accept).Return(&data.BanAppeal{ID: openAppeal.ID, Email: openAppeal.Email, Status: data.AppealAccepted}, nil)
+	mockBanService.On("ResolveAppeal", racedAppeal.ID, mock.Anything, mock.Anything).Return(nil, services.ErrAppealResolved)
+
+	mockAuditService := new(mocks.MockAuditService)
+	mockAuditService.On("Record", mock.Anything).Return(nil)
+
+	mockMailService := new(mocks.MockMailService)
+	mockMailService.On("QueueEmail", openAppeal.Email, mock.Anything, "ban_appeal", mock.Anything).Return(nil).Maybe()
+
+	handler := NewBanHandler(mockUserService, mockBanService, mockMailService, mockAuditService, func() {})
+
+	tests := map[string]struct {
+		contextUser *data.User
+		appealID    string
+		body        string
+		wantCode    int
+	}{
+		"admin accepts an appeal": {
+			contextUser: adminUser,
+			appealID:    openAppeal.ID.String(),
+			body:        `{"status":"accepted","resolution":"Welcome back"}`,
+			wantCode:    http.StatusOK,
+		},
+		"moderator accepts the appeal of a user": {
+			contextUser: moderatorUser,
+			appealID:    openAppeal.ID.String(),
+			body:        `{"status":"accepted","resolution":"Welcome back"}`,
+			wantCode:    http.StatusOK,
+		},
+		"moderator can't resolve the appeal of another moderator": {
+			contextUser: moderatorUser,
+			appealID:    moderatorAppeal.ID.String(),
+			body:        `{"status":"accepted"}`,
+			wantCode:    http.StatusForbidden,
+		},
+		"invalid status": {
+			contextUser: adminUser,
+			appealID:    openAppeal.ID.String(),
+			body:        `{"status":"open"}`,
+			wantCode:    http.StatusUnprocessableEntity,
+		},
+		"invalid appeal id": {
+			contextUser: adminUser,
+			appealID:    "1234",
+			body:        `{"status":"rejected"}`,
+			wantCode:    http.StatusBadRequest,
+		},
+		"appeal not found": {
+			contextUser: adminUser,
+			appealID:    unknownID.String(),
+			body:        `{"status":"rejected"}`,
+			wantCode:    http.StatusNotFound,
+		},
+		"appeal resolved already": {
+			contextUser: adminUser,
+			appealID:    resolvedAppeal.ID.String(),
+			body:        `{"status":"rejected"}`,
+			wantCode:    http.StatusConflict,
+		},
+		"appeal resolved by another moderator meanwhile": {
+			contextUser: adminUser,
+			appealID:    racedAppeal.ID.String(),
+			body:        `{"status":"rejected"}`,
+			wantCode:    http.StatusConflict,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", tt.contextUser)
+			c.SetPath("/api/admin/ban-appeals/:id/resolve")
+			c.SetParamNames("id")
+			c.SetParamValues(tt.appealID)
+
+			err := handler.ResolveAppeal(c)
+
+			if tt.wantCode != http.StatusOK {
+				he, ok := err.(*echo.HTTPError)
+				assert.True(t, ok)
+				assert.Equal(t, tt.wantCode, he.Code)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"status":"accepted"`)
+			}
+		})
+	}
+
+	mockBanService.AssertExpectations(t)
+}
+
+func TestAddIPBan(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	admin := &data.User{ID: uuid.New(), Role: data.Role{ID: data.RoleAdmin.ToID(), Name: data.RoleAdmin.String()}}
+
+	tests := map[string]struct {
+		body        string
+		setupMocks  func(*mocks.MockBanService)
+		wantCode    int
+		wantChanged bool
+	}{
+		"temporary ban": {
+			body: `{"subnet":"203.0.113.0/24","reason":"spam","duration":24}`,
+			setupMocks: func(m *mocks.MockBanService) {
+				m.On("AddIPBan", "203.0.113.0/24", "spam", mock.MatchedBy(func(expiresAt *time.Time) bool {
+					return expiresAt != nil && expiresAt.After(time.Now().Add(23*time.Hour))
+				}), admin.ID).Return(&data.IPBan{ID: 1, Subnet: "203.0.113.0/24"}, nil)
+			},
+			wantCode:    http.StatusCreated,
+			wantChanged: true,
+		},
+		"permanent ban of a single IP": {
+			body: `{"subnet":"198.51.100.7","reason":"abuse","permanent":true}`,
+			setupMocks: func(m *mocks.MockBanService) {
+				m.On("AddIPBan", "198.51.100.7", "abuse", (*time.Time)(nil), admin.ID).Return(&data.IPBan{ID: 2, Subnet: "198.51.100.7/32"}, nil)
+			},
+			wantCode:    http.StatusCreated,
+			wantChanged: true,
+		},
+		"neither duration nor permanent": {
+			body:     `{"subnet":"203.0.113.0/24","reason":"spam"}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		"invalid subnet": {
+			body:     `{"subnet":"not-an-ip","reason":"spam","permanent":true}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		"range holding the own IP": {
+			body:     `{"subnet":"192.0.2.0/24","reason":"spam","permanent":true}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		"range banned already": {
+			body: `{"subnet":"203.0.113.0/24","reason":"spam","permanent":true}`,
+			setupMocks: func(m *mocks.MockBanService) {
+				m.On("AddIPBan", "203.0.113.0/24", "spam", (*time.Time)(nil), admin.ID).Return(nil, services.ErrIPAlreadyBanned)
+			},
+			wantCode: http.StatusConflict,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockBanService := new(mocks.MockBanService)
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockBanService)
+			}
+			mockAuditService := new(mocks.MockAuditService)
+			mockAuditService.On("Record", mock.Anything).Return(nil).Maybe()
+
+			changed := false
+			handler := NewBanHandler(&mocks.MockUserService{}, mockBanService, &mocks.MockMailService{}, mockAuditService, func() { changed = true })
+
+			// httptest requests come from 192.0.2.1
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", admin)
+
+			err := handler.AddIPBan(c)
+
+			if tt.wantCode != http.StatusCreated {
+				he, ok := err.(*echo.HTTPError)
+				if assert.True(t, ok) {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+			assert.Equal(t, tt.wantChanged, changed)
+			mockBanService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRemoveIPBan(t *testing.T) {
+	e := echo.New()
+
+	admin := &data.User{ID: uuid.New(), Role: data.Role{ID: data.RoleAdmin.ToID(), Name: data.RoleAdmin.String()}}
+
+	tests := map[string]struct {
+		id          string
+		setupMocks  func(*mocks.MockBanService)
+		wantCode    int
+		wantChanged bool
+	}{
+		"ban lifted": {
+			id: "1",
+			setupMocks: func(m *mocks.MockBanService) {
+				m.On("GetIPBan", int64(1)).Return(&data.IPBan{ID: 1, Subnet: "203.0.113.0/24", Reason: "spam"}, nil)
+				m.On("RemoveIPBan", int64(1)).Return(nil)
+			},
+			wantCode:    http.StatusNoContent,
+			wantChanged: true,
+		},
+		"invalid id": {
+			id:       "abc",
+			wantCode: http.StatusBadRequest,
+		},
+		"ban not found": {
+			id: "2",
+			setupMocks: func(m *mocks.MockBanService) {
+				m.On("GetIPBan", int64(2)).Return(nil, services.ErrRecordNotFound)
+			},
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockBanService := new(mocks.MockBanService)
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockBanService)
+			}
+			mockAuditService := new(mocks.MockAuditService)
+			mockAuditService.On("Record", mock.Anything).Return(nil).Maybe()
+
+			changed := false
+			handler := NewBanHandler(&mocks.MockUserService{}, mockBanService, &mocks.MockMailService{}, mockAuditService, func() { changed = true })
+
+			req := httptest.NewRequest(http.MethodDelete, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", admin)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+
+			err := handler.RemoveIPBan(c)
+
+			if tt.wantCode != http.StatusNoContent {
+				he, ok := err.(*echo.HTTPError)
+				if assert.True(t, ok) {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+			assert.Equal(t, tt.wantChanged, changed)
+			mockBanService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/flow"
+	"NodeTurtleAPI/internal/services/projects"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CapabilitiesHandler handles HTTP requests to find out what the server supports.
+type CapabilitiesHandler struct {
+	limits   config.LimitsConfig
+	quotas   config.QuotasConfig
+	imports  config.ImportsConfig
+	readOnly func() bool
+}
+
+// NewCapabilitiesHandler creates a new CapabilitiesHandler describing the server by its config,
+// and reading whether the API is in read-only mode from readOnly.
+func NewCapabilitiesHandler(limits config.LimitsConfig, quotas config.QuotasConfig, imports config.ImportsConfig, readOnly func() bool) CapabilitiesHandler {
+	return CapabilitiesHandler{
+		limits:   limits,
+		quotas:   quotas,
+		imports:  imports,
+		readOnly: readOnly,
+	}
+}
+
+// Get handles the request to retrieve what the server supports for the current user, if any:
+// the size limits of their role, the node types of flows, and whether realtime events and exports are available.
+// Guests get the editor only, so exports are unavailable to them as they are to anonymous users,
+// and they can hold as many projects as guests can before registering.
+func (h *CapabilitiesHandler) Get(c echo.Context) error {
+	role := data.RoleUser
+	var user *data.User
+	if contextUser, ok := c.Get("user").(*data.User); ok {
+		user = contextUser
+		role = data.RoleType(user.Role.Name)
+	}
+
+	quota := projects.QuotaFor(h.quotas, role)
+	capabilities := data.Capabilities{
+		MaxDataBytes:   smallestLimit(h.limits.FlowBytes, quota.MaxBytes),
+		MaxNodes:       h.limits.FlowNodes,
+		MaxProjects:    quota.MaxProjects,
+		MaxStorage:     quota.MaxStorage,
+		MaxImportBytes: h.imports.MaxBytes,
+		NodeTypes:      flow.NodeTypes(),
+		Realtime:       user != nil,
+		ExportFormats:  map[string][]string{},
+		ReadOnly:       h.readOnly(),
+	}
+
+	if user != nil {
+		capabilities.Role = &role
+		if user.IsGuest() {
+			capabilities.MaxProjects = smallestLimit(h.limits.GuestProjects, quota.MaxProjects)
+		} else {
+			capabilities.ExportFormats["project"] = []string{"json"}
+			capabilities.ExportFormats["liked_projects"] = likedExportFormats
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"capabilities": capabilities,
+	})
+}
+
+// smallestLimit returns the tighter of two limits, where 0 means there is none.
+func smallestLimit(a, b int) int {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
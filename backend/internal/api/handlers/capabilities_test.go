@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/flow"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCapabilities(t *testing.T) {
+	e := echo.New()
+
+	guestExpiresAt := time.Now().Add(time.Hour)
+	user := &data.User{ID: uuid.New(), Role: data.Role{Name: data.RoleUser.String()}, IsActivated: true}
+	premium := &data.User{ID: uuid.New(), Role: data.Role{Name: data.RolePremium.String()}, IsActivated: true}
+	guest := &data.User{ID: uuid.New(), Role: data.Role{Name: data.RoleUser.String()}, GuestExpiresAt: &guestExpiresAt}
+
+	limits := config.LimitsConfig{FlowNodes: 500, FlowBytes: 1 << 20, GuestProjects: 3}
+	quotas := config.QuotasConfig{
+		User:    config.QuotaConfig{Projects: 10, Bytes: 1 << 19, Storage: 10 << 20},
+		Premium: config.QuotaConfig{Projects: 100, Bytes: 4 << 20, Storage: 200 << 20},
+	}
+
+	tests := map[string]struct {
+		contextUser *data.User
+		readOnly    bool
+		want        data.Capabilities
+	}{
+		"Anonymous users get the limits of new accounts": {
+			want: data.Capabilities{
+				MaxDataBytes:  1 << 19,
+				MaxNodes:      500,
+				MaxProjects:   10,
+				MaxStorage:    10 << 20,
+				ExportFormats: map[string][]string{},
+			},
+		},
+		"Premium users get the limit of the flow size": {
+			contextUser: premium,
+			want: data.Capabilities{
+				MaxDataBytes:  1 << 20,
+				MaxNodes:      500,
+				MaxProjects:   100,
+				MaxStorage:    200 << 20,
+				Realtime:      true,
+				ExportFormats: map[string][]string{"project": {"json"}, "liked_projects": {"json", "opml"}},
+			},
+		},
+		"Users in read-only mode": {
+			contextUser: user,
+			readOnly:    true,
+			want: data.Capabilities{
+				MaxDataBytes:  1 << 19,
+				MaxNodes:      500,
+				MaxProjects:   10,
+				MaxStorage:    10 << 20,
+				Realtime:      true,
+				ExportFormats: map[string][]string{"project": {"json"}, "liked_projects": {"json", "opml"}},
+				ReadOnly:      true,
+			},
+		},
+		"Guests can't export": {
+			contextUser: guest,
+			want: data.Capabilities{
+				MaxDataBytes:  1 << 19,
+				MaxNodes:      500,
+				MaxProjects:   3,
+				MaxStorage:    10 << 20,
+				Realtime:      true,
+				ExportFormats: map[string][]string{},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler := NewCapabilitiesHandler(limits, quotas, config.ImportsConfig{MaxBytes: 5 << 20}, func() bool { return tt.readOnly })
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+				role := data.RoleType(tt.contextUser.Role.Name)
+				tt.want.Role = &role
+			}
+			tt.want.MaxImportBytes = 5 << 20
+			tt.want.NodeTypes = flow.NodeTypes()
+
+			err := handler.Get(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var res struct {
+				Capabilities data.Capabilities `json:"capabilities"`
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			assert.Equal(t, tt.want, res.Capabilities)
+		})
+	}
+}
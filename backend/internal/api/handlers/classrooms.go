@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"net/http"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/classrooms"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// ClassroomHandler handles HTTP requests related to classrooms and their rosters.
+type ClassroomHandler struct {
+	classroomService classrooms.IClassroomService
+}
+
+// NewClassroomHandler creates a new ClassroomHandler with the provided services.
+func NewClassroomHandler(classroomService classrooms.IClassroomService) ClassroomHandler {
+	return ClassroomHandler{
+		classroomService: classroomService,
+	}
+}
+
+// Create handles the request to create a new classroom.
+// The creator becomes the classroom teacher.
+func (h *ClassroomHandler) Create(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	if !contextUser.IsActivated {
+		return echo.NewHTTPError(http.StatusForbidden, "Account is not activated")
+	}
+
+	var payload data.ClassroomCreate
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+	payload.OwnerID = contextUser.ID
+
+	classroom, err := h.classroomService.CreateClassroom(c.Request().Context(), payload)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal classroom creation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create classroom")
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"classroom": classroom,
+	})
+}
+
+// List handles the request to retrieve the classrooms the current user is a member of.
+func (h *ClassroomHandler) List(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	classrooms, err := h.classroomService.GetUserClassrooms(c.Request().Context(), contextUser.ID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal classroom retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve classrooms")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"classrooms": classrooms,
+	})
+}
+
+// Get handles the request to retrieve a classroom with its roster.
+// Only roster members can view a classroom.
+func (h *ClassroomHandler) Get(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	classroom, err := h.memberClassroom(c, contextUser)
+	if err != nil {
+		return err
+	}
+
+	members, err := h.classroomService.GetMembers(c.Request().Context(), classroom.ID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal classroom members retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve classroom")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"classroom": classroom,
+		"members":   members,
+	})
+}
+
+// Delete handles the request to delete a classroom.
+// Only the classroom owner can delete it, projects shared with it become private.
+func (h *ClassroomHandler) Delete(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	classroom, err := h.ownedClassroom(c, contextUser)
+	if err != nil {
+		return err
+	}
+
+	if err := h.classroomService.DeleteClassroom(c.Request().Context(), classroom.ID); err != nil {
+		if err == services.ErrRecordNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "Classroom not found")
+		}
+		logging.Error(c.Request().Context(), "Internal classroom deletion error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete classroom")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// InviteMembers handles the request to invite users to the classroom roster by email.
+// Only the classroom owner can manage the roster. Invited users join once they accept,
+// the response is the same whether or not the emails are registered.
+func (h *ClassroomHandler) InviteMembers(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	classroom, err := h.ownedClassroom(c, contextUser)
+	if err != nil {
+		return err
+	}
+
+	var payload struct {
+		Emails []string `json:"emails" validate:"required,min=1,max=500,dive,email"`
+	}
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := h.classroomService.InviteMembers(c.Request().Context(), classroom.ID, payload.Emails); err != nil {
+		logging.Error(c.Request().Context(), "Internal classroom invitation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to invite classroom members")
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"message": "Registered users will see the invitation and join the classroom once they accept it.",
+	})
+}
+
+// ListInvites handles the request to retrieve the pending classroom invitations of the current user.
+func (h *ClassroomHandler) ListInvites(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	invites, err := h.classroomService.GetInvites(c.Request().Context(), contextUser.ID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal classroom invitations retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve classroom invitations")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"invites": invites,
+	})
+}
+
+// AcceptInvite handles the request to join a classroom the current user was invited to.
+func (h *ClassroomHandler) AcceptInvite(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	classroomID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid classroom ID")
+	}
+
+	if err := h.classroomService.AcceptInvite(c.Request().Context(), classroomID, contextUser.ID); err != nil {
+		if err == services.ErrRecordNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "Invitation not found")
+		}
+		logging.Error(c.Request().Context(), "Internal classroom invitation acceptance error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to join classroom")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DeclineInvite handles the request to decline an invitation to a classroom.
+func (h *ClassroomHandler) DeclineInvite(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	classroomID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid classroom ID")
+	}
+
+	if err := h.classroomService.DeclineInvite(c.Request().Context(), classroomID, contextUser.ID); err != nil {
+		if err == services.ErrRecordNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "Invitation not found")
+		}
+		logging.Error(c.Request().Context(), "Internal classroom invitation deletion error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to decline invitation")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RemoveMember handles the request to remove a user from the classroom roster.
+// The classroom owner can remove anyone but themselves, other members can only leave.
+func (h *ClassroomHandler) RemoveMember(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	userID, err := uuid.Parse(c.Param("userID"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID")
+	}
+
+	classroom, err := h.memberClassroom(c, contextUser)
+	if err != nil {
+		return err
+	}
+
+	if classroom.OwnerID != contextUser.ID && userID != contextUser.ID {
+		return echo.NewHTTPError(http.StatusForbidden, "You do not have permission to manage this classroom")
+	}
+
+	if err := h.classroomService.RemoveMember(c.Request().Context(), classroom.ID, userID); err != nil {
+		if err == services.ErrRecordNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "Member not found")
+		}
+		logging.Error(c.Request().Context(), "Internal classroom member deletion error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to remove classroom member")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetProjects handles the request to retrieve the projects shared with a classroom.
+// Only roster members can view them.
+func (h *ClassroomHandler) GetProjects(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	classroom, err := h.memberClassroom(c, contextUser)
+	if err != nil {
+		return err
+	}
+
+	projects, err := h.classroomService.GetClassroomProjects(c.Request().Context(), classroom.ID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal classroom projects retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve classroom projects")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"projects": projects,
+	})
+}
+
+// memberClassroom loads the classroom from the id path param if the user is on its roster.
+// Classrooms are reported as not found to non-members so their existence is not disclosed.
+func (h *ClassroomHandler) memberClassroom(c echo.Context, user *data.User) (*data.Classroom, error) {
+	classroomID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid classroom ID")
+	}
+
+	isMember, err := h.classroomService.IsMember(c.Request().Context(), classroomID, user.ID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal classroom membership error", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve classroom")
+	}
+	if !isMember {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "Classroom not found")
+	}
+
+	classroom, err := h.classroomService.GetClassroom(c.Request().Context(), classroomID)
+	if err != nil {
+		if err == services.ErrRecordNotFound {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Classroom not found")
+		}
+		logging.Error(c.Request().Context(), "Internal classroom retrieval error", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve classroom")
+	}
+
+	return classroom, nil
+}
+
+// ownedClassroom loads the classroom from the id path param if the user owns it.
+func (h *ClassroomHandler) ownedClassroom(c echo.Context, user *data.User) (*data.Classroom, error) {
+	classroom, err := h.memberClassroom(c, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if classroom.OwnerID != user.ID {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "You do not have permission to manage this classroom")
+	}
+
+	return classroom, nil
+}
@@ -0,0 +1,365 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateClassroom(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	mockClassroomService := mocks.MockClassroomService{}
+	handler := NewClassroomHandler(&mockClassroomService)
+
+	teacher := &data.User{ID: uuid.New(), Username: "teacher", IsActivated: true}
+	inactiveUser := &data.User{ID: uuid.New(), Username: "inactive", IsActivated: false}
+
+	mockClassroomService.On("CreateClassroom", data.ClassroomCreate{Name: "Class 5B", OwnerID: teacher.ID}).
+		Return(&data.Classroom{ID: uuid.New(), Name: "Class 5B", OwnerID: teacher.ID, MembersCount: 1}, nil)
+
+	tests := map[string]struct {
+		contextUser *data.User
+		reqBody     string
+		wantCode    int
+		wantError   bool
+	}{
+		"User not authenticated": {
+			reqBody:   `{"name":"Class 5B"}`,
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"User not activated": {
+			contextUser: inactiveUser,
+			reqBody:     `{"name":"Class 5B"}`,
+			wantCode:    http.StatusForbidden,
+			wantError:   true,
+		},
+		"Name too short": {
+			contextUser: teacher,
+			reqBody:     `{"name":"5B"}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Successful creation": {
+			contextUser: teacher,
+			reqBody:     `{"name":"Class 5B"}`,
+			wantCode:    http.StatusCreated,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.Create(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
+
+func TestGetClassroomProjects(t *testing.T) {
+	e := echo.New()
+
+	mockClassroomService := mocks.MockClassroomService{}
+	handler := NewClassroomHandler(&mockClassroomService)
+
+	student := &data.User{ID: uuid.New(), Username: "student", IsActivated: true}
+	outsider := &data.User{ID: uuid.New(), Username: "outsider", IsActivated: true}
+	classroom := &data.Classroom{ID: uuid.New(), Name: "Class 5B", OwnerID: uuid.New()}
+
+	mockClassroomService.On("IsMember", classroom.ID, student.ID).Return(true, nil)
+	mockClassroomService.On("IsMember", classroom.ID, outsider.ID).Return(false, nil)
+	mockClassroomService.On("GetClassroom", classroom.ID).Return(classroom, nil)
+	mockClassroomService.On("GetClassroomProjects", classroom.ID).Return([]data.Project{{ID: uuid.New(), ClassroomID: &classroom.ID}}, nil)
+
+	tests := map[string]struct {
+		contextUser *data.User
+		classroomID string
+		wantCode    int
+		wantError   bool
+	}{
+		"User not authenticated": {
+			classroomID: classroom.ID.String(),
+			wantCode:    http.StatusUnauthorized,
+			wantError:   true,
+		},
+		"Invalid classroom ID": {
+			contextUser: student,
+			classroomID: "invalid",
+			wantCode:    http.StatusBadRequest,
+			wantError:   true,
+		},
+		"Not a roster member": {
+			contextUser: outsider,
+			classroomID: classroom.ID.String(),
+			wantCode:    http.StatusNotFound,
+			wantError:   true,
+		},
+		"Roster member": {
+			contextUser: student,
+			classroomID: classroom.ID.String(),
+			wantCode:    http.StatusOK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.classroomID)
+
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.GetProjects(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), classroom.ID.String())
+			}
+		})
+	}
+}
+
+func TestInviteClassroomMembers(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	mockClassroomService := mocks.MockClassroomService{}
+	handler := NewClassroomHandler(&mockClassroomService)
+
+	teacher := &data.User{ID: uuid.New(), Username: "teacher", IsActivated: true}
+	student := &data.User{ID: uuid.New(), Username: "student", IsActivated: true}
+	classroom := &data.Classroom{ID: uuid.New(), Name: "Class 5B", OwnerID: teacher.ID}
+
+	mockClassroomService.On("IsMember", classroom.ID, mock.Anything).Return(true, nil)
+	mockClassroomService.On("GetClassroom", classroom.ID).Return(classroom, nil)
+	mockClassroomService.On("InviteMembers", classroom.ID, mock.Anything).Return(nil)
+
+	tests := map[string]struct {
+		contextUser *data.User
+		reqBody     string
+		wantCode    int
+		wantError   bool
+	}{
+		"Not the classroom owner": {
+			contextUser: student,
+			reqBody:     `{"emails":["ann@example.com"]}`,
+			wantCode:    http.StatusForbidden,
+			wantError:   true,
+		},
+		"Invalid email": {
+			contextUser: teacher,
+			reqBody:     `{"emails":["ann"]}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Registered email": {
+			contextUser: teacher,
+			reqBody:     `{"emails":["ann@example.com"]}`,
+			wantCode:    http.StatusAccepted,
+		},
+		"Unknown email": {
+			contextUser: teacher,
+			reqBody:     `{"emails":["missing@example.com"]}`,
+			wantCode:    http.StatusAccepted,
+		},
+	}
+
+	var bodies []string
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(classroom.ID.String())
+			c.Set("user", tt.contextUser)
+
+			err := handler.InviteMembers(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.NotContains(t, rec.Body.String(), "ann")
+				bodies = append(bodies, rec.Body.String())
+			}
+		})
+	}
+
+	// registered and unknown emails get the same answer
+	assert.Len(t, bodies, 2)
+	assert.Equal(t, bodies[0], bodies[1])
+}
+
+func TestAcceptClassroomInvite(t *testing.T) {
+	e := echo.New()
+
+	mockClassroomService := mocks.MockClassroomService{}
+	handler := NewClassroomHandler(&mockClassroomService)
+
+	student := &data.User{ID: uuid.New(), Username: "student", IsActivated: true}
+	invitedID := uuid.New()
+	otherID := uuid.New()
+
+	mockClassroomService.On("AcceptInvite", invitedID, student.ID).Return(nil)
+	mockClassroomService.On("AcceptInvite", otherID, student.ID).Return(services.ErrRecordNotFound)
+
+	tests := map[string]struct {
+		classroomID string
+		wantCode    int
+		wantError   bool
+	}{
+		"Invalid classroom ID": {
+			classroomID: "invalid",
+			wantCode:    http.StatusBadRequest,
+			wantError:   true,
+		},
+		"Not invited": {
+			classroomID: otherID.String(),
+			wantCode:    http.StatusNotFound,
+			wantError:   true,
+		},
+		"Successful join": {
+			classroomID: invitedID.String(),
+			wantCode:    http.StatusNoContent,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.classroomID)
+			c.Set("user", student)
+
+			err := handler.AcceptInvite(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
+
+func TestRemoveClassroomMember(t *testing.T) {
+	e := echo.New()
+
+	mockClassroomService := mocks.MockClassroomService{}
+	handler := NewClassroomHandler(&mockClassroomService)
+
+	teacher := &data.User{ID: uuid.New(), Username: "teacher", IsActivated: true}
+	student := &data.User{ID: uuid.New(), Username: "student", IsActivated: true}
+	classmate := &data.User{ID: uuid.New(), Username: "classmate", IsActivated: true}
+	classroom := &data.Classroom{ID: uuid.New(), Name: "Class 5B", OwnerID: teacher.ID}
+
+	mockClassroomService.On("IsMember", classroom.ID, mock.Anything).Return(true, nil)
+	mockClassroomService.On("GetClassroom", classroom.ID).Return(classroom, nil)
+	mockClassroomService.On("RemoveMember", classroom.ID, teacher.ID).Return(services.ErrRecordNotFound)
+	mockClassroomService.On("RemoveMember", classroom.ID, mock.Anything).Return(nil)
+
+	tests := map[string]struct {
+		contextUser *data.User
+		userID      string
+		wantCode    int
+		wantError   bool
+	}{
+		"Student removing classmate": {
+			contextUser: student,
+			userID:      classmate.ID.String(),
+			wantCode:    http.StatusForbidden,
+			wantError:   true,
+		},
+		"Owner cannot be removed": {
+			contextUser: teacher,
+			userID:      teacher.ID.String(),
+			wantCode:    http.StatusNotFound,
+			wantError:   true,
+		},
+		"Student leaving": {
+			contextUser: student,
+			userID:      student.ID.String(),
+			wantCode:    http.StatusNoContent,
+		},
+		"Owner removing student": {
+			contextUser: teacher,
+			userID:      student.ID.String(),
+			wantCode:    http.StatusNoContent,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id", "userID")
+			c.SetParamValues(classroom.ID.String(), tt.userID)
+			c.Set("user", tt.contextUser)
+
+			err := handler.RemoveMember(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
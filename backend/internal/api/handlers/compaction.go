@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services/audit"
+	"NodeTurtleAPI/internal/services/projects"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CompactionHandler handles HTTP requests to compact stored project data.
+type CompactionHandler struct {
+	projectService projects.IProjectService
+	auditService   audit.IAuditService
+	cfg            config.CompactionConfig
+}
+
+// NewCompactionHandler creates a new CompactionHandler with the provided services and pacing.
+func NewCompactionHandler(projectService projects.IProjectService, auditService audit.IAuditService, cfg config.CompactionConfig) CompactionHandler {
+	return CompactionHandler{
+		projectService: projectService,
+		auditService:   auditService,
+		cfg:            cfg,
+	}
+}
+
+// Compact handles the request to re-serialize the data of every project canonically instead of waiting for the scheduled job.
+// With dry_run the report shows what would change without writing anything, which is worth checking before the first real run.
+func (h *CompactionHandler) Compact(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	dryRun, err := parseDryRun(c)
+	if err != nil {
+		return err
+	}
+
+	if !dryRun {
+		err := h.auditService.Record(data.AuditEntry{
+			ActorID:    &contextUser.ID,
+			Action:     data.AuditProjectsCompact,
+			TargetType: "projects",
+			IP:         c.RealIP(),
+		})
+		if err != nil {
+			logging.Error(c.Request().Context(), "Internal audit log error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to record project compaction")
+		}
+	}
+
+	report, err := h.projectService.CompactProjectData(c.Request().Context(), data.CompactionOptions{
+		DryRun:    dryRun,
+		BatchSize: h.cfg.BatchSize,
+		Pause:     h.cfg.Pause,
+	})
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal project compaction error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to compact project data")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"report": report,
+	})
+}
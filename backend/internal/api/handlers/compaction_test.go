@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCompactProjects(t *testing.T) {
+	e := echo.New()
+
+	admin := &data.User{ID: uuid.New(), Username: "admin", IsActivated: true}
+	cfg := config.CompactionConfig{BatchSize: 100, Pause: time.Millisecond}
+	report := func(dryRun bool) *data.CompactionReport {
+		return &data.CompactionReport{DryRun: dryRun, Scanned: 10, Changed: 2, BytesBefore: 5000, BytesAfter: 3000, Projects: []data.CompactedProject{}}
+	}
+
+	tests := map[string]struct {
+		contextUser *data.User
+		query       string
+		setupMocks  func(p *mocks.MockProjectService, a *mocks.MockAuditService)
+		wantCode    int
+		wantError   bool
+	}{
+		"Not authenticated": {
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Invalid dry run": {
+			contextUser: admin,
+			query:       "?dry_run=maybe",
+			wantCode:    http.StatusBadRequest,
+			wantError:   true,
+		},
+		"Dry run is not audited": {
+			contextUser: admin,
+			query:       "?dry_run=true",
+			setupMocks: func(p *mocks.MockProjectService, a *mocks.MockAuditService) {
+				p.On("CompactProjectData", data.CompactionOptions{DryRun: true, BatchSize: 100, Pause: time.Millisecond}).Return(report(true), nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Compaction is audited": {
+			contextUser: admin,
+			setupMocks: func(p *mocks.MockProjectService, a *mocks.MockAuditService) {
+				a.On("Record", mock.MatchedBy(func(entry data.AuditEntry) bool {
+					return entry.Action == data.AuditProjectsCompact && *entry.ActorID == admin.ID
+				})).Return(nil)
+				p.On("CompactProjectData", data.CompactionOptions{BatchSize: 100, Pause: time.Millisecond}).Return(report(false), nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Compaction error": {
+			contextUser: admin,
+			query:       "?dry_run=true",
+			setupMocks: func(p *mocks.MockProjectService, a *mocks.MockAuditService) {
+				p.On("CompactProjectData", mock.Anything).Return(nil, errors.New("database error"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockProjectService := mocks.MockProjectService{}
+			mockAuditService := mocks.MockAuditService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockProjectService, &mockAuditService)
+			}
+			handler := NewCompactionHandler(&mockProjectService, &mockAuditService, cfg)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/projects/compact"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.Compact(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+			mockProjectService.AssertExpectations(t)
+			mockAuditService.AssertExpectations(t)
+		})
+	}
+}
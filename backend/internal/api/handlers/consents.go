@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services/consents"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ConsentHandler handles HTTP requests to manage what users agree to their data being used for.
+type ConsentHandler struct {
+	consentService consents.IConsentService
+}
+
+// NewConsentHandler creates a new ConsentHandler with the provided services.
+func NewConsentHandler(consentService consents.IConsentService) ConsentHandler {
+	return ConsentHandler{
+		consentService: consentService,
+	}
+}
+
+// GetCurrent handles the request to retrieve the current user's consent for every purpose.
+func (h *ConsentHandler) GetCurrent(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	userConsents, err := h.consentService.GetConsents(c.Request().Context(), contextUser.ID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal consent retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve consents")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"consents": userConsents,
+	})
+}
+
+// UpdateCurrent handles the request to give or withdraw the current user's consent for one or more purposes.
+// Purposes left out of the request keep their current choice.
+func (h *ConsentHandler) UpdateCurrent(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var payload data.ConsentUpdate
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	changes := payload.Changes()
+	if len(changes) == 0 {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, "No consents to update")
+	}
+
+	userConsents, err := h.consentService.SetConsents(c.Request().Context(), contextUser.ID, changes)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal consent update error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update consents")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"consents": userConsents,
+	})
+}
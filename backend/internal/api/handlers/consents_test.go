@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateConsents(t *testing.T) {
+	e := echo.New()
+
+	user := &data.User{ID: uuid.New(), Username: "consenting", IsActivated: true}
+	failingUser := &data.User{ID: uuid.New(), Username: "failing", IsActivated: true}
+
+	mockConsentService := mocks.MockConsentService{}
+	handler := NewConsentHandler(&mockConsentService)
+
+	mockConsentService.On("SetConsents", user.ID, map[data.ConsentPurpose]bool{
+		data.ConsentAnalytics:    true,
+		data.ConsentResearchData: false,
+	}).Return([]data.Consent{}, nil)
+	mockConsentService.On("SetConsents", failingUser.ID, map[data.ConsentPurpose]bool{
+		data.ConsentMarketingEmails: true,
+	}).Return(nil, errors.New("database error"))
+
+	tests := map[string]struct {
+		contextUser *data.User
+		reqBody     string
+		wantCode    int
+		wantError   bool
+	}{
+		"User not authenticated": {
+			reqBody:   `{"analytics":true}`,
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Invalid body": {
+			contextUser: user,
+			reqBody:     `{"analytics":"yes"}`,
+			wantCode:    http.StatusBadRequest,
+			wantError:   true,
+		},
+		"Nothing to update": {
+			contextUser: user,
+			reqBody:     `{}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Service error": {
+			contextUser: failingUser,
+			reqBody:     `{"marketing_emails":true}`,
+			wantCode:    http.StatusInternalServerError,
+			wantError:   true,
+		},
+		"Only the given purposes change": {
+			contextUser: user,
+			reqBody:     `{"analytics":true,"research_data":false}`,
+			wantCode:    http.StatusOK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.UpdateCurrent(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+		})
+	}
+	mockConsentService.AssertExpectations(t)
+}
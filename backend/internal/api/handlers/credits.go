@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/credits"
+	"NodeTurtleAPI/internal/services/projects"
+	"NodeTurtleAPI/internal/services/users"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// CreditHandler handles HTTP requests related to co-author credits on projects.
+type CreditHandler struct {
+	creditService  credits.ICreditService
+	projectService projects.IProjectService
+	userService    users.IUserService
+}
+
+// NewCreditHandler creates a new CreditHandler with the provided services.
+func NewCreditHandler(creditService credits.ICreditService, projectService projects.IProjectService, userService users.IUserService) CreditHandler {
+	return CreditHandler{
+		creditService:  creditService,
+		projectService: projectService,
+		userService:    userService,
+	}
+}
+
+// List handles the request to list the co-authors of a project.
+// Everyone who can see the project sees the accepted credits, the owner also sees pending and declined ones.
+func (h *CreditHandler) List(c echo.Context) error {
+	var requestingUserID *uuid.UUID
+	if contextUser, ok := c.Get("user").(*data.User); ok {
+		requestingUserID = &contextUser.ID
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	project, err := h.projectService.GetProject(c.Request().Context(), projectID, requestingUserID)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		logging.Error(c.Request().Context(), "Internal project retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve credits")
+	}
+
+	isOwner := requestingUserID != nil && *requestingUserID == project.CreatorID
+	credits, err := h.creditService.ListCredits(c.Request().Context(), projectID, isOwner)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal credit retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve credits")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"credits": credits,
+	})
+}
+
+// Add handles the request of a project owner to credit a user as co-author.
+// The project is listed on the user's profile once they accept the credit.
+func (h *CreditHandler) Add(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	var payload struct {
+		Username string `json:"username" validate:"required"`
+	}
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	isOwner, err := h.projectService.IsOwner(c.Request().Context(), projectID, contextUser.ID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal project ownership check error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to credit user")
+	}
+	if !isOwner {
+		return echo.NewHTTPError(http.StatusForbidden, "You do not have permission to credit users on this project")
+	}
+
+	user, err := h.userService.GetUserByUsername(c.Request().Context(), payload.Username)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "User not found")
+		}
+		logging.Error(c.Request().Context(), "Internal user retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to credit user")
+	}
+
+	if user.ID == contextUser.ID {
+		return echo.NewHTTPError(http.StatusBadRequest, "You can't credit yourself")
+	}
+
+	credit, err := h.creditService.AddCredit(c.Request().Context(), projectID, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAlreadyCredited):
+			return echo.NewHTTPError(http.StatusConflict, "User is already credited")
+		case errors.Is(err, services.ErrRecordNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		logging.Error(c.Request().Context(), "Internal credit creation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to credit user")
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"credit": credit,
+	})
+}
+
+// Remove handles the request to remove a credit. The project owner can remove any credit,
+// credited users can remove their own.
+func (h *CreditHandler) Remove(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	userID, err := uuid.Parse(c.Param("userID"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID")
+	}
+
+	if userID != contextUser.ID {
+		isOwner, err := h.projectService.IsOwner(c.Request().Context(), projectID, contextUser.ID)
+		if err != nil {
+			logging.Error(c.Request().Context(), "Internal project ownership check error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to remove credit")
+		}
+		if !isOwner {
+			return echo.NewHTTPError(http.StatusForbidden, "You do not have permission to remove this credit")
+		}
+	}
+
+	if err := h.creditService.RemoveCredit(c.Request().Context(), projectID, userID); err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Credit not found")
+		}
+		logging.Error(c.Request().Context(), "Internal credit removal error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to remove credit")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListPending handles the request to list the credits waiting for the current user's answer.
+func (h *CreditHandler) ListPending(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	credits, err := h.creditService.ListPendingCredits(c.Request().Context(), contextUser.ID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal credit retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve credits")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"credits": credits,
+	})
+}
+
+// Respond handles the request of the current user to accept or decline a credit on a project.
+func (h *CreditHandler) Respond(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	if !contextUser.IsActivated {
+		return echo.NewHTTPError(http.StatusForbidden, "Account is not activated")
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	var payload struct {
+		Accept *bool `json:"accept" validate:"required"`
+	}
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	credit, err := h.creditService.RespondToCredit(c.Request().Context(), projectID, contextUser.ID, *payload.Accept)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "No pending credit on this project")
+		}
+		logging.Error(c.Request().Context(), "Internal credit update error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to respond to credit")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"credit": credit,
+	})
+}
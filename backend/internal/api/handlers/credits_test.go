@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddCredit(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	owner := &data.User{ID: uuid.New(), Username: "owner", IsActivated: true}
+	partner := &data.User{ID: uuid.New(), Username: "partner", IsActivated: true}
+	classmate := &data.User{ID: uuid.New(), Username: "classmate", IsActivated: true}
+	projectID := uuid.New()
+	otherProjectID := uuid.New()
+
+	mockCreditService := mocks.MockCreditService{}
+	mockProjectService := mocks.MockProjectService{}
+	mockUserService := mocks.MockUserService{}
+
+	mockProjectService.On("IsOwner", projectID, owner.ID).Return(true, nil)
+	mockProjectService.On("IsOwner", otherProjectID, owner.ID).Return(false, nil)
+	mockUserService.On("GetUserByUsername", "partner").Return(partner, nil)
+	mockUserService.On("GetUserByUsername", "classmate").Return(classmate, nil)
+	mockUserService.On("GetUserByUsername", "owner").Return(owner, nil)
+	mockUserService.On("GetUserByUsername", mock.Anything).Return(nil, services.ErrUserNotFound)
+	mockCreditService.On("AddCredit", projectID, partner.ID).Return(&data.Credit{ProjectID: projectID, UserID: partner.ID, Username: "partner", Status: data.CreditPending}, nil)
+	mockCreditService.On("AddCredit", projectID, classmate.ID).Return(nil, services.ErrAlreadyCredited)
+
+	handler := NewCreditHandler(&mockCreditService, &mockProjectService, &mockUserService)
+
+	tests := map[string]struct {
+		projectID string
+		reqBody   string
+		wantCode  int
+		wantError bool
+	}{
+		"Co-author credited": {
+			projectID: projectID.String(),
+			reqBody:   `{"username":"partner"}`,
+			wantCode:  http.StatusCreated,
+		},
+		"Already credited": {
+			projectID: projectID.String(),
+			reqBody:   `{"username":"classmate"}`,
+			wantCode:  http.StatusConflict,
+			wantError: true,
+		},
+		"Crediting yourself": {
+			projectID: projectID.String(),
+			reqBody:   `{"username":"owner"}`,
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Unknown user": {
+			projectID: projectID.String(),
+			reqBody:   `{"username":"nobody"}`,
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Not the owner": {
+			projectID: otherProjectID.String(),
+			reqBody:   `{"username":"partner"}`,
+			wantCode:  http.StatusForbidden,
+			wantError: true,
+		},
+		"Missing username": {
+			projectID: projectID.String(),
+			reqBody:   `{}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.projectID)
+			c.Set("user", owner)
+
+			err := handler.Add(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"status":"pending"`)
+			}
+		})
+	}
+}
+
+func TestListCredits(t *testing.T) {
+	e := echo.New()
+
+	owner := &data.User{ID: uuid.New(), Username: "owner", IsActivated: true}
+	projectID := uuid.New()
+	privateProjectID := uuid.New()
+	project := &data.Project{ID: projectID, CreatorID: owner.ID, IsPublic: true}
+
+	mockCreditService := mocks.MockCreditService{}
+	mockProjectService := mocks.MockProjectService{}
+
+	mockProjectService.On("GetProject", projectID, mock.Anything).Return(project, nil)
+	mockProjectService.On("GetProject", privateProjectID, mock.Anything).Return(nil, services.ErrRecordNotFound)
+	mockCreditService.On("ListCredits", projectID, true).Return([]data.Credit{{Username: "accepted"}, {Username: "pending"}}, nil)
+	mockCreditService.On("ListCredits", projectID, false).Return([]data.Credit{{Username: "accepted"}}, nil)
+
+	handler := NewCreditHandler(&mockCreditService, &mockProjectService, &mocks.MockUserService{})
+
+	tests := map[string]struct {
+		contextUser *data.User
+		projectID   string
+		wantCode    int
+		wantPending bool
+		wantError   bool
+	}{
+		"Owner sees pending credits": {
+			contextUser: owner,
+			projectID:   projectID.String(),
+			wantCode:    http.StatusOK,
+			wantPending: true,
+		},
+		"Guest sees accepted credits": {
+			projectID: projectID.String(),
+			wantCode:  http.StatusOK,
+		},
+		"Project not visible": {
+			projectID: privateProjectID.String(),
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.projectID)
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.List(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), "accepted")
+				assert.Equal(t, tt.wantPending, strings.Contains(rec.Body.String(), `"username":"pending"`))
+			}
+		})
+	}
+}
+
+func TestRespondToCredit(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	user := &data.User{ID: uuid.New(), Username: "partner", IsActivated: true}
+	projectID := uuid.New()
+	unknownProjectID := uuid.New()
+
+	mockCreditService := mocks.MockCreditService{}
+	mockCreditService.On("RespondToCredit", projectID, user.ID, false).Return(&data.Credit{ProjectID: projectID, UserID: user.ID, Status: data.CreditDeclined}, nil)
+	mockCreditService.On("RespondToCredit", unknownProjectID, user.ID, true).Return(nil, services.ErrRecordNotFound)
+
+	handler := NewCreditHandler(&mockCreditService, &mocks.MockProjectService{}, &mocks.MockUserService{})
+
+	tests := map[string]struct {
+		projectID string
+		reqBody   string
+		wantCode  int
+		wantError bool
+	}{
+		"Credit declined": {
+			projectID: projectID.String(),
+			reqBody:   `{"accept":false}`,
+			wantCode:  http.StatusOK,
+		},
+		"Missing answer": {
+			projectID: projectID.String(),
+			reqBody:   `{}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"No pending credit": {
+			projectID: unknownProjectID.String(),
+			reqBody:   `{"accept":true}`,
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.projectID)
+			c.Set("user", user)
+
+			err := handler.Respond(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"status":"declined"`)
+			}
+		})
+	}
+}
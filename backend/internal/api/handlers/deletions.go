@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/deletions"
+	"NodeTurtleAPI/internal/services/mail"
+	"NodeTurtleAPI/internal/services/tokens"
+	"NodeTurtleAPI/internal/services/users"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DeletionHandler handles HTTP requests for the deletion of accounts.
+type DeletionHandler struct {
+	userService     users.IUserService
+	tokenService    tokens.ITokenService
+	deletionService deletions.IDeletionService
+	mailService     mail.IMailService
+}
+
+// NewDeletionHandler creates a new DeletionHandler with the provided services.
+func NewDeletionHandler(userService users.IUserService, tokenService tokens.ITokenService, deletionService deletions.IDeletionService, mailService mail.IMailService) DeletionHandler {
+	return DeletionHandler{
+		userService:     userService,
+		tokenService:    tokenService,
+		deletionService: deletionService,
+		mailService:     mailService,
+	}
+}
+
+// Confirm handles the confirmation of an account deletion with the emailed token. The account is signed out
+// everywhere and deleted for good once the grace period ends, unless its owner logs in before then.
+func (h *DeletionHandler) Confirm(c echo.Context) error {
+	token := c.Param("token")
+	if token == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid deletion token")
+	}
+
+	user, err := h.userService.GetForToken(c.Request().Context(), data.ScopeDeactivate, token)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Token or user not found")
+	}
+
+	if err := h.tokenService.Consume(c.Request().Context(), data.ScopeDeactivate, token); err != nil {
+		if errors.Is(err, services.ErrInvalidToken) {
+			return echo.NewHTTPError(http.StatusNotFound, "Token or user not found")
+		}
+		logging.Error(c.Request().Context(), "Internal token consumption error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete account")
+	}
+
+	deletion, err := h.deletionService.Schedule(c.Request().Context(), user.ID)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Token or user not found")
+		}
+		if errors.Is(err, services.ErrUserInRegion) {
+			return echo.NewHTTPError(http.StatusConflict, "Accounts stored in another region can't be deleted yet, please contact support")
+		}
+		logging.Error(c.Request().Context(), "Internal account deletion error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete account")
+	}
+
+	emailData := map[string]string{
+		"Username":  user.Username,
+		"url":       "/login",
+		"DeletesAt": formatExpiry(deletion.ScheduledFor),
+	}
+	if err := h.mailService.QueueEmail(c.Request().Context(), user.Email, "Account deletion scheduled", "deletion", emailData); err != nil {
+		logging.Error(c.Request().Context(), "Failed to queue deletion email", err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":       "Account will be deleted, log in before then to cancel",
+		"scheduled_for": deletion.ScheduledFor,
+	})
+}
+
+// ListPending handles the request to retrieve the accounts scheduled for deletion.
+func (h *DeletionHandler) ListPending(c echo.Context) error {
+	pending, err := h.deletionService.ListPending(c.Request().Context())
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal account deletion listing error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve account deletions")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"deletions": pending,
+	})
+}
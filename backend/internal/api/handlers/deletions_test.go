@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestConfirmDeletion(t *testing.T) {
+	e := echo.New()
+
+	mockUserService := new(mocks.MockUserService)
+	mockTokenService := new(mocks.MockTokenService)
+	mockDeletionService := new(mocks.MockDeletionService)
+	mockMailService := new(mocks.MockMailService)
+
+	userID := uuid.New()
+	userIDErr := uuid.New()
+	scheduledFor := time.Now().Add(720 * time.Hour)
+
+	mockUserService.On("GetForToken", mock.Anything, "token").Return(&data.User{ID: userID, Email: "test@test.test", Username: "testuser"}, nil)
+	mockUserService.On("GetForToken", mock.Anything, "scheduleFail").Return(&data.User{ID: userIDErr, Email: "fail@test.test", Username: "failUser"}, nil)
+	mockUserService.On("GetForToken", mock.Anything, "-").Return(nil, services.ErrRecordNotFound)
+	mockUserService.On("GetForToken", mock.Anything, "usedToken").Return(&data.User{ID: uuid.New(), Email: "used@test.test", Username: "usedTokenUser"}, nil)
+
+	mockTokenService.On("Consume", data.ScopeDeactivate, "usedToken").Return(services.ErrInvalidToken)
+	mockTokenService.On("Consume", data.ScopeDeactivate, mock.Anything).Return(nil)
+
+	mockDeletionService.On("Schedule", userID).Return(&data.AccountDeletion{UserID: userID, ScheduledFor: scheduledFor}, nil)
+	mockDeletionService.On("Schedule", userIDErr).Return(nil, services.ErrInternal)
+
+	mockMailService.On("QueueEmail", "test@test.test", mock.Anything, "deletion", mock.Anything).Return(nil).Maybe()
+
+	handler := NewDeletionHandler(mockUserService, mockTokenService, mockDeletionService, mockMailService)
+
+	tests := map[string]struct {
+		token     string
+		wantCode  int
+		wantError bool
+	}{
+		"Valid token": {
+			token:    "token",
+			wantCode: http.StatusOK,
+		},
+		"Invalid token": {
+			token:     "",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"user with token not found": {
+			token:     "-",
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Failed to schedule deletion": {
+			token:     "scheduleFail",
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+		"Token already used": {
+			token:     "usedToken",
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/api/:token")
+			c.SetParamNames("token")
+			c.SetParamValues(tt.token)
+
+			err := handler.Confirm(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), "scheduled_for")
+			}
+		})
+	}
+
+	mockUserService.AssertExpectations(t)
+	mockTokenService.AssertExpectations(t)
+	mockDeletionService.AssertExpectations(t)
+}
+
+func TestListPendingDeletions(t *testing.T) {
+	e := echo.New()
+
+	tests := map[string]struct {
+		deletions []data.AccountDeletion
+		err       error
+		wantCode  int
+	}{
+		"lists the pending deletions": {
+			deletions: []data.AccountDeletion{{UserID: uuid.New(), Username: "alice", ScheduledFor: time.Now()}},
+			wantCode:  http.StatusOK,
+		},
+		"internal error": {
+			err:      services.ErrInternal,
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockDeletionService := new(mocks.MockDeletionService)
+			mockDeletionService.On("ListPending").Return(tt.deletions, tt.err)
+			handler := NewDeletionHandler(&mocks.MockUserService{}, &mocks.MockTokenService{}, mockDeletionService, &mocks.MockMailService{})
+
+			rec := httptest.NewRecorder()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+			err := handler.ListPending(c)
+
+			if tt.err != nil {
+				he, ok := err.(*echo.HTTPError)
+				assert.True(t, ok)
+				assert.Equal(t, tt.wantCode, he.Code)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), "alice")
+			}
+		})
+	}
+}
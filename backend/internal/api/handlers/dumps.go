@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/dumps"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DumpHandler handles HTTP requests related to public data dumps.
+type DumpHandler struct {
+	dumpService dumps.IDumpService
+}
+
+// NewDumpHandler creates a new DumpHandler with the provided services.
+func NewDumpHandler(dumpService dumps.IDumpService) DumpHandler {
+	return DumpHandler{
+		dumpService: dumpService,
+	}
+}
+
+// List handles the request to retrieve the index of available data dumps.
+func (h *DumpHandler) List(c echo.Context) error {
+	dumps, err := h.dumpService.List()
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal data dump retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve data dumps")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"dumps": dumps,
+	})
+}
+
+// Download handles the request to download a data dump listed in the index.
+func (h *DumpHandler) Download(c echo.Context) error {
+	filename := c.Param("filename")
+
+	path, err := h.dumpService.Path(filename)
+	if err != nil {
+		if err == services.ErrRecordNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "Data dump not found")
+		}
+		logging.Error(c.Request().Context(), "Internal data dump retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve data dump")
+	}
+
+	return c.Attachment(path, filename)
+}
+
+// Generate handles the request to produce a data dump right away instead of waiting for the scheduled job.
+func (h *DumpHandler) Generate(c echo.Context) error {
+	dump, err := h.dumpService.Generate()
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal data dump generation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to generate data dump")
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"dump": dump,
+	})
+}
+
+// GetOptOut handles the request to check whether the current user opted out of public data dumps.
+func (h *DumpHandler) GetOptOut(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	optOut, err := h.dumpService.IsOptedOut(contextUser.ID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal data dump opt-out retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve opt-out status")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"opt_out": optOut,
+	})
+}
+
+// SetOptOut handles the request to opt the current user out of, or back into, public data dumps.
+func (h *DumpHandler) SetOptOut(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var payload struct {
+		OptOut *bool `json:"opt_out" validate:"required"`
+	}
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := h.dumpService.SetOptOut(contextUser.ID, *payload.OptOut); err != nil {
+		logging.Error(c.Request().Context(), "Internal data dump opt-out update error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update opt-out status")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"opt_out": *payload.OptOut,
+	})
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadDump(t *testing.T) {
+	e := echo.New()
+
+	mockDumpService := mocks.MockDumpService{}
+	handler := NewDumpHandler(&mockDumpService)
+
+	path := filepath.Join(t.TempDir(), "projects-20250101-000000.jsonl.gz")
+	assert.NoError(t, os.WriteFile(path, []byte("dump"), 0o644))
+
+	mockDumpService.On("Path", "projects-20250101-000000.jsonl.gz").Return(path, nil)
+	mockDumpService.On("Path", "../../etc/passwd").Return("", services.ErrRecordNotFound)
+
+	tests := map[string]struct {
+		filename  string
+		wantCode  int
+		wantError bool
+	}{
+		"Unknown dump": {
+			filename:  "../../etc/passwd",
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Successful download": {
+			filename: "projects-20250101-000000.jsonl.gz",
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("filename")
+			c.SetParamValues(tt.filename)
+
+			err := handler.Download(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), tt.filename)
+				assert.Equal(t, "dump", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestSetResearchOptOut(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	mockDumpService := mocks.MockDumpService{}
+	handler := NewDumpHandler(&mockDumpService)
+
+	user := &data.User{ID: uuid.New(), Username: "researched", IsActivated: true}
+
+	mockDumpService.On("SetOptOut", user.ID, true).Return(nil)
+	mockDumpService.On("SetOptOut", user.ID, false).Return(nil)
+
+	tests := map[string]struct {
+		contextUser *data.User
+		reqBody     string
+		wantCode    int
+		wantError   bool
+	}{
+		"User not authenticated": {
+			reqBody:   `{"opt_out":true}`,
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Missing opt_out": {
+			contextUser: user,
+			reqBody:     `{}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Opt out": {
+			contextUser: user,
+			reqBody:     `{"opt_out":true}`,
+			wantCode:    http.StatusOK,
+		},
+		"Opt back in": {
+			contextUser: user,
+			reqBody:     `{"opt_out":false}`,
+			wantCode:    http.StatusOK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.SetOptOut(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+		})
+	}
+
+	mockDumpService.AssertExpectations(t)
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/mail"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// emailsLimit is the maximum number of emails returned at once.
+const emailsLimit = 200
+
+// EmailHandler handles HTTP requests to look into the emails sent by the API, and the requests of users
+// to choose which optional emails they get.
+type EmailHandler struct {
+	mailService mail.IMailService
+}
+
+// NewEmailHandler creates a new EmailHandler with the provided services.
+func NewEmailHandler(mailService mail.IMailService) EmailHandler {
+	return EmailHandler{
+		mailService: mailService,
+	}
+}
+
+// List handles the request to retrieve the latest emails, optionally of a single user, recipient or request,
+// for support to check whether an email a user says they never got was sent.
+func (h *EmailHandler) List(c echo.Context) error {
+	filter := data.EmailFilter{
+		Recipient: c.QueryParam("recipient"),
+		RequestID: c.QueryParam("request_id"),
+	}
+
+	if raw := c.QueryParam("user_id"); raw != "" {
+		userID, err := uuid.Parse(raw)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID")
+		}
+		filter.UserID = &userID
+	}
+
+	filter.Limit, _ = strconv.Atoi(c.QueryParam("limit"))
+	if filter.Limit <= 0 || filter.Limit > emailsLimit {
+		filter.Limit = 50
+	}
+
+	emails, err := h.mailService.ListEmails(c.Request().Context(), filter)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal email listing error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve emails")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"emails": emails,
+	})
+}
+
+// Templates handles the request to list the email templates along with their sample data.
+func (h *EmailHandler) Templates(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"templates": h.mailService.ListTemplates(),
+	})
+}
+
+// Preview handles the request to render an email template with sample data, so template edits can be checked
+// without going through the flows sending it. When asked to, the preview is also emailed to the admin.
+func (h *EmailHandler) Preview(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var payload data.EmailPreviewRequest
+	if c.Request().ContentLength != 0 {
+		if err := c.Bind(&payload); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+		}
+	}
+
+	preview, err := h.mailService.PreviewTemplate(c.Param("name"), payload.Data)
+	if err != nil {
+		if errors.Is(err, mail.ErrTemplateNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Email template not found")
+		}
+		logging.Error(c.Request().Context(), "Internal email preview error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to render email template")
+	}
+
+	if payload.Send {
+		err := h.mailService.SendEmail(c.Request().Context(), contextUser.Email, "[Preview] "+preview.Subject, preview.Template, preview.Data)
+		if err != nil {
+			logging.Error(c.Request().Context(), "Internal email preview sending error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to send preview email")
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"preview": preview,
+		"sent":    payload.Send,
+	})
+}
+
+// GetPreferences handles the request to retrieve which optional emails the authenticated user gets.
+func (h *EmailHandler) GetPreferences(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	prefs, err := h.mailService.GetPreferences(c.Request().Context(), contextUser.ID)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "User not found")
+		}
+		logging.Error(c.Request().Context(), "Internal email preferences retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve email preferences")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"preferences": prefs,
+	})
+}
+
+// UpdatePreferences handles the request to change which optional emails the authenticated user gets.
+// Preferences left out of the request are unchanged. Marketing is the consent to marketing emails.
+func (h *EmailHandler) UpdatePreferences(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var payload data.EmailPreferencesUpdate
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if payload.Marketing == nil && payload.Digests == nil && payload.Security == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "No updates provided")
+	}
+
+	prefs, err := h.mailService.UpdatePreferences(c.Request().Context(), contextUser.ID, payload)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "User not found")
+		}
+		logging.Error(c.Request().Context(), "Internal email preferences update error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update email preferences")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"preferences": prefs,
+	})
+}
+
+// Unsubscribe handles the request of an unsubscribe link of an optional email, no session needed:
+// the signed token is proof enough that the link came from an email to the user.
+// Mail clients post to the same link for their own unsubscribe button.
+func (h *EmailHandler) Unsubscribe(c echo.Context) error {
+	category, err := h.mailService.Unsubscribe(c.Request().Context(), c.Param("token"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidToken):
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid unsubscribe link")
+		case errors.Is(err, services.ErrUserNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, "User not found")
+		}
+		logging.Error(c.Request().Context(), "Internal unsubscribe error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to unsubscribe")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"unsubscribed": category,
+	})
+}
@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/mail"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestListEmails(t *testing.T) {
+	e := echo.New()
+
+	userID := uuid.New()
+
+	tests := map[string]struct {
+		query      string
+		setupMocks func(m *mocks.MockMailService)
+		wantCode   int
+		wantError  bool
+	}{
+		"Invalid user ID": {
+			query:     "?user_id=abc",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Emails of a user": {
+			query: "?user_id=" + userID.String(),
+			setupMocks: func(m *mocks.MockMailService) {
+				m.On("ListEmails", data.EmailFilter{UserID: &userID, Limit: 50}).Return([]data.Email{{ID: 1, UserID: &userID, Status: data.EmailSent}}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Limit is capped": {
+			query: "?request_id=abc123&limit=1000",
+			setupMocks: func(m *mocks.MockMailService) {
+				m.On("ListEmails", data.EmailFilter{RequestID: "abc123", Limit: 50}).Return([]data.Email{}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Database error": {
+			query: "?recipient=alice@example.com&limit=10",
+			setupMocks: func(m *mocks.MockMailService) {
+				m.On("ListEmails", data.EmailFilter{Recipient: "alice@example.com", Limit: 10}).Return(nil, errors.New("database error"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockMailService := mocks.MockMailService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockMailService)
+			}
+			handler := NewEmailHandler(&mockMailService)
+
+			req := httptest.NewRequest(http.MethodGet, "/"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.List(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+			mockMailService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPreviewEmailTemplate(t *testing.T) {
+	e := echo.New()
+
+	admin := &data.User{ID: uuid.New(), Email: "admin@example.com"}
+	preview := &data.EmailPreview{
+		Template: "ban",
+		Subject:  "Account Suspended",
+		Data:     map[string]string{"Username": "bob", "Reason": "Spam"},
+		HTML:     "<p>bob</p>",
+	}
+
+	tests := map[string]struct {
+		name       string
+		body       string
+		setupMocks func(m *mocks.MockMailService)
+		wantCode   int
+		wantError  bool
+	}{
+		"Preview with overrides": {
+			name: "ban",
+			body: `{"data":{"Username":"bob"}}`,
+			setupMocks: func(m *mocks.MockMailService) {
+				m.On("PreviewTemplate", "ban", map[string]string{"Username": "bob"}).Return(preview, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Preview without body": {
+			name: "ban",
+			setupMocks: func(m *mocks.MockMailService) {
+				m.On("PreviewTemplate", "ban", map[string]string(nil)).Return(preview, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Preview sent to the admin": {
+			name: "ban",
+			body: `{"data":{"Username":"bob"},"send":true}`,
+			setupMocks: func(m *mocks.MockMailService) {
+				m.On("PreviewTemplate", "ban", map[string]string{"Username": "bob"}).Return(preview, nil)
+				m.On("SendEmail", "admin@example.com", "[Preview] Account Suspended", "ban", preview.Data).Return(nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Sending fails": {
+			name: "ban",
+			body: `{"send":true}`,
+			setupMocks: func(m *mocks.MockMailService) {
+				m.On("PreviewTemplate", "ban", map[string]string(nil)).Return(preview, nil)
+				m.On("SendEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("smtp error"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+		"Invalid body": {
+			name:      "ban",
+			body:      `{"data":`,
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Unknown template": {
+			name: "missing",
+			setupMocks: func(m *mocks.MockMailService) {
+				m.On("PreviewTemplate", "missing", map[string]string(nil)).Return(nil, mail.ErrTemplateNotFound)
+			},
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Rendering fails": {
+			name: "ban",
+			setupMocks: func(m *mocks.MockMailService) {
+				m.On("PreviewTemplate", "ban", map[string]string(nil)).Return(nil, errors.New("template error"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockMailService := mocks.MockMailService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockMailService)
+			}
+			handler := NewEmailHandler(&mockMailService)
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("name")
+			c.SetParamValues(tt.name)
+			c.Set("user", admin)
+
+			err := handler.Preview(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+			mockMailService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateEmailPreferences(t *testing.T) {
+	e := echo.New()
+
+	user := &data.User{ID: uuid.New(), Email: "alice@example.com"}
+	off := false
+
+	tests := map[string]struct {
+		body       string
+		setupMocks func(m *mocks.MockMailService)
+		wantCode   int
+		wantError  bool
+	}{
+		"Digests turned off": {
+			body: `{"digests":false}`,
+			setupMocks: func(m *mocks.MockMailService) {
+				m.On("UpdatePreferences", user.ID, data.EmailPreferencesUpdate{Digests: &off}).Return(&data.EmailPreferences{Security: true}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"No updates provided": {
+			body:      `{}`,
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Invalid body": {
+			body:      `{"digests":`,
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Database error": {
+			body: `{"digests":false}`,
+			setupMocks: func(m *mocks.MockMailService) {
+				m.On("UpdatePreferences", user.ID, mock.Anything).Return(nil, errors.New("database error"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockMailService := mocks.MockMailService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockMailService)
+			}
+			handler := NewEmailHandler(&mockMailService)
+
+			req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", user)
+
+			err := handler.UpdatePreferences(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+			mockMailService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	e := echo.New()
+
+	tests := map[string]struct {
+		setupMocks func(m *mocks.MockMailService)
+		wantCode   int
+		wantError  bool
+	}{
+		"Unsubscribed": {
+			setupMocks: func(m *mocks.MockMailService) {
+				m.On("Unsubscribe", "token").Return(data.EmailDigests, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Invalid token": {
+			setupMocks: func(m *mocks.MockMailService) {
+				m.On("Unsubscribe", "token").Return(data.EmailCategory(""), services.ErrInvalidToken)
+			},
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"User deleted": {
+			setupMocks: func(m *mocks.MockMailService) {
+				m.On("Unsubscribe", "token").Return(data.EmailCategory(""), services.ErrUserNotFound)
+			},
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Database error": {
+			setupMocks: func(m *mocks.MockMailService) {
+				m.On("Unsubscribe", "token").Return(data.EmailCategory(""), errors.New("database error"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockMailService := mocks.MockMailService{}
+			tt.setupMocks(&mockMailService)
+			handler := NewEmailHandler(&mockMailService)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("token")
+			c.SetParamValues("token")
+
+			err := handler.Unsubscribe(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.JSONEq(t, `{"unsubscribed":"digests"}`, rec.Body.String())
+			}
+			mockMailService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/auth"
+	"NodeTurtleAPI/internal/services/projects"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// EmbedHandler handles HTTP requests letting other sites embed projects, including private ones.
+type EmbedHandler struct {
+	projectService projects.IProjectService
+	authService    auth.IAuthService
+}
+
+// NewEmbedHandler creates a new EmbedHandler with the provided services.
+func NewEmbedHandler(projectService projects.IProjectService, authService auth.IAuthService) EmbedHandler {
+	return EmbedHandler{
+		projectService: projectService,
+		authService:    authService,
+	}
+}
+
+// CreateToken handles the request of a project owner to issue an embed token for the project.
+// The token grants read-only access to this project only and expires after a short time.
+func (h *EmbedHandler) CreateToken(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	isOwner, err := h.projectService.IsOwner(c.Request().Context(), projectID, contextUser.ID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal project ownership check error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create embed token")
+	}
+
+	if !isOwner {
+		return echo.NewHTTPError(http.StatusForbidden, "You do not have permission to embed this project")
+	}
+
+	token, expiresAt, err := h.authService.CreateEmbedToken(projectID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal embed token creation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create embed token")
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"token":      token,
+		"expires_at": expiresAt,
+	})
+}
+
+// Get handles the request of an embedding site to retrieve a project, authorized by the embed token in the token query parameter.
+func (h *EmbedHandler) Get(c echo.Context) error {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	token := c.QueryParam("token")
+	if token == "" {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Missing embed token")
+	}
+
+	if err := h.authService.VerifyEmbedToken(token, projectID); err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Invalid or expired embed token")
+	}
+
+	project, err := h.projectService.GetEmbeddedProject(c.Request().Context(), projectID)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		logging.Error(c.Request().Context(), "Internal project retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve project")
+	}
+
+	// the response may hold a private project, it must not end up in shared caches
+	c.Response().Header().Set("Cache-Control", "private, no-store")
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"project": project,
+	})
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateEmbedToken(t *testing.T) {
+	e := echo.New()
+
+	owner := &data.User{ID: uuid.New(), Username: "owner"}
+	projectID := uuid.New()
+	otherProjectID := uuid.New()
+	brokenProjectID := uuid.New()
+
+	mockProjectService := mocks.MockProjectService{}
+	mockAuthService := mocks.MockAuthService{}
+
+	mockProjectService.On("IsOwner", projectID, owner.ID).Return(true, nil)
+	mockProjectService.On("IsOwner", otherProjectID, owner.ID).Return(false, nil)
+	mockProjectService.On("IsOwner", brokenProjectID, owner.ID).Return(false, errors.New("db down"))
+	mockAuthService.On("CreateEmbedToken", projectID).Return("embed-token", time.Now().Add(time.Hour), nil)
+
+	handler := NewEmbedHandler(&mockProjectService, &mockAuthService)
+
+	tests := map[string]struct {
+		projectID string
+		wantCode  int
+		wantError bool
+	}{
+		"Owner creates token": {
+			projectID: projectID.String(),
+			wantCode:  http.StatusCreated,
+		},
+		"Invalid project ID": {
+			projectID: "abc",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Not the owner": {
+			projectID: otherProjectID.String(),
+			wantCode:  http.StatusForbidden,
+			wantError: true,
+		},
+		"Ownership check fails": {
+			projectID: brokenProjectID.String(),
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.projectID)
+			c.Set("user", owner)
+
+			err := handler.CreateToken(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), "embed-token")
+			}
+		})
+	}
+
+	mockAuthService.AssertExpectations(t)
+}
+
+func TestGetEmbeddedProject(t *testing.T) {
+	e := echo.New()
+
+	projectID := uuid.New()
+	deletedProjectID := uuid.New()
+	project := &data.Project{ID: projectID, Title: "Private spiral", IsPublic: false}
+
+	mockProjectService := mocks.MockProjectService{}
+	mockAuthService := mocks.MockAuthService{}
+
+	mockAuthService.On("VerifyEmbedToken", "embed-token", projectID).Return(nil)
+	mockAuthService.On("VerifyEmbedToken", "embed-token", deletedProjectID).Return(nil)
+	mockAuthService.On("VerifyEmbedToken", "other-token", projectID).Return(services.ErrInvalidToken)
+	mockProjectService.On("GetEmbeddedProject", projectID).Return(project, nil)
+	mockProjectService.On("GetEmbeddedProject", deletedProjectID).Return(nil, services.ErrRecordNotFound)
+
+	handler := NewEmbedHandler(&mockProjectService, &mockAuthService)
+
+	tests := map[string]struct {
+		projectID string
+		token     string
+		wantCode  int
+		wantError bool
+	}{
+		"Valid token": {
+			projectID: projectID.String(),
+			token:     "embed-token",
+			wantCode:  http.StatusOK,
+		},
+		"Missing token": {
+			projectID: projectID.String(),
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Token of another project": {
+			projectID: projectID.String(),
+			token:     "other-token",
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Invalid project ID": {
+			projectID: "abc",
+			token:     "embed-token",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Deleted project": {
+			projectID: deletedProjectID.String(),
+			token:     "embed-token",
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/?token="+tt.token, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.projectID)
+
+			err := handler.Get(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), "Private spiral")
+				assert.Equal(t, "private, no-store", rec.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/exports"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ExportHandler handles HTTP requests for the copies of their data users can ask for.
+type ExportHandler struct {
+	exportService exports.IExportService
+	cfg           config.ExportsConfig
+}
+
+// NewExportHandler creates a new ExportHandler with the provided export service and settings.
+func NewExportHandler(exportService exports.IExportService, cfg config.ExportsConfig) ExportHandler {
+	return ExportHandler{
+		exportService: exportService,
+		cfg:           cfg,
+	}
+}
+
+// Request handles the request of a user for a copy of their data. The archive is prepared in the background
+// and a download link is emailed once it's ready.
+func (h *ExportHandler) Request(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	// nothing would prepare the export
+	if h.cfg.Interval <= 0 {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "Data exports are disabled")
+	}
+
+	export, err := h.exportService.RequestExport(c.Request().Context(), contextUser.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrExportPending):
+			return echo.NewHTTPError(http.StatusConflict, "A data export is already being prepared")
+		case errors.Is(err, services.ErrUserNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, "User not found")
+		case errors.Is(err, services.ErrUserInRegion):
+			return echo.NewHTTPError(http.StatusConflict, "Accounts stored in another region can't be exported yet, please contact support")
+		}
+		logging.Error(c.Request().Context(), "Internal export request error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to request data export")
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"export": export,
+	})
+}
+
+// List handles the request of a user to retrieve their data exports that haven't expired.
+func (h *ExportHandler) List(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	list, err := h.exportService.ListExports(c.Request().Context(), contextUser.ID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal export retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve data exports")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"exports": list,
+	})
+}
+
+// Download handles the request to download the archive of an export through the emailed link, no session needed.
+func (h *ExportHandler) Download(c echo.Context) error {
+	archive, err := h.exportService.Download(c.Request().Context(), c.Param("token"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidToken) {
+			return echo.NewHTTPError(http.StatusNotFound, "Data export not found or expired")
+		}
+		logging.Error(c.Request().Context(), "Internal export download error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to download data export")
+	}
+
+	c.Response().Header().Set("Cache-Control", "private, no-store")
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="nodeturtle-data-`+time.Now().UTC().Format("2006-01-02")+`.zip"`)
+	return c.Blob(http.StatusOK, "application/zip", archive)
+}
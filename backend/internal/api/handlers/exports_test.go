@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestExport(t *testing.T) {
+	e := echo.New()
+	user := &data.User{ID: uuid.New(), Username: "alice", IsActivated: true}
+	enabled := config.ExportsConfig{Interval: 5, TTL: 24 * time.Hour}
+
+	tests := map[string]struct {
+		contextUser *data.User
+		cfg         config.ExportsConfig
+		setupMocks  func(m *mocks.MockExportService)
+		wantCode    int
+		wantError   bool
+	}{
+		"User not authenticated": {
+			cfg:       enabled,
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Exports disabled": {
+			contextUser: user,
+			wantCode:    http.StatusServiceUnavailable,
+			wantError:   true,
+		},
+		"Export already pending": {
+			contextUser: user,
+			cfg:         enabled,
+			setupMocks: func(m *mocks.MockExportService) {
+				m.On("RequestExport", user.ID).Return(nil, services.ErrExportPending)
+			},
+			wantCode:  http.StatusConflict,
+			wantError: true,
+		},
+		"User living in another region": {
+			contextUser: user,
+			cfg:         enabled,
+			setupMocks: func(m *mocks.MockExportService) {
+				m.On("RequestExport", user.ID).Return(nil, services.ErrUserInRegion)
+			},
+			wantCode:  http.StatusConflict,
+			wantError: true,
+		},
+		"Export requested": {
+			contextUser: user,
+			cfg:         enabled,
+			setupMocks: func(m *mocks.MockExportService) {
+				m.On("RequestExport", user.ID).Return(&data.DataExport{ID: uuid.New(), Status: data.ExportPending, RequestedAt: time.Now()}, nil)
+			},
+			wantCode: http.StatusAccepted,
+		},
+		"Database error": {
+			contextUser: user,
+			cfg:         enabled,
+			setupMocks: func(m *mocks.MockExportService) {
+				m.On("RequestExport", user.ID).Return(nil, errors.New("database error"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockExportService := mocks.MockExportService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockExportService)
+			}
+			handler := NewExportHandler(&mockExportService, tt.cfg)
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.Request(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+			mockExportService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListExports(t *testing.T) {
+	e := echo.New()
+	user := &data.User{ID: uuid.New(), Username: "alice", IsActivated: true}
+
+	mockExportService := mocks.MockExportService{}
+	mockExportService.On("ListExports", user.ID).Return([]data.DataExport{{ID: uuid.New(), Status: data.ExportReady}}, nil)
+	handler := NewExportHandler(&mockExportService, config.ExportsConfig{})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user", user)
+
+	assert.NoError(t, handler.List(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"ready"`)
+	mockExportService.AssertExpectations(t)
+}
+
+func TestDownloadExport(t *testing.T) {
+	e := echo.New()
+
+	tests := map[string]struct {
+		token      string
+		setupMocks func(m *mocks.MockExportService)
+		wantCode   int
+		wantError  bool
+	}{
+		"Invalid or expired token": {
+			token: "expired",
+			setupMocks: func(m *mocks.MockExportService) {
+				m.On("Download", "expired").Return(nil, services.ErrInvalidToken)
+			},
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Archive downloaded": {
+			token: "valid",
+			setupMocks: func(m *mocks.MockExportService) {
+				m.On("Download", "valid").Return([]byte("PK"), nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Storage error": {
+			token: "valid",
+			setupMocks: func(m *mocks.MockExportService) {
+				m.On("Download", "valid").Return(nil, errors.New("storage error"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockExportService := mocks.MockExportService{}
+			tt.setupMocks(&mockExportService)
+			handler := NewExportHandler(&mockExportService, config.ExportsConfig{})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("token")
+			c.SetParamValues(tt.token)
+
+			err := handler.Download(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Equal(t, "application/zip", rec.Header().Get(echo.HeaderContentType))
+				assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), "attachment")
+			}
+			mockExportService.AssertExpectations(t)
+		})
+	}
+}
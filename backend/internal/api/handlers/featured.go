@@ -0,0 +1,329 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/audit"
+	"NodeTurtleAPI/internal/services/featured"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// FeaturedHandler handles HTTP requests related to curating the featured projects rotation.
+// Every change to the featured projects made through it is recorded in the audit log.
+type FeaturedHandler struct {
+	featuredService featured.IFeaturedService
+	auditService    audit.IAuditService
+}
+
+// featuredHistoryLimit is the maximum number of entries returned by the featuring history.
+const featuredHistoryLimit = 200
+
+// NewFeaturedHandler creates a new FeaturedHandler with the provided services.
+func NewFeaturedHandler(featuredService featured.IFeaturedService, auditService audit.IAuditService) FeaturedHandler {
+	return FeaturedHandler{
+		featuredService: featuredService,
+		auditService:    auditService,
+	}
+}
+
+// List handles the request to retrieve the currently featured projects, the ones expiring soonest first.
+// With expiring_within (in hours) only the projects whose slot ends within that time are returned.
+func (h *FeaturedHandler) List(c echo.Context) error {
+	var expiringWithin time.Duration
+	if v := c.QueryParam("expiring_within"); v != "" {
+		hours, err := strconv.Atoi(v)
+		if err != nil || hours <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "expiring_within must be a positive number of hours")
+		}
+		expiringWithin = time.Duration(hours) * time.Hour
+	}
+
+	projects, err := h.featuredService.ListFeatured(expiringWithin)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal featured projects retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve featured projects")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"projects": projects,
+	})
+}
+
+// Feature handles the request to feature a public project for the given number of hours, bypassing the queue.
+// Featuring an already featured project replaces the end of its slot.
+func (h *FeaturedHandler) Feature(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	var payload struct {
+		Duration int `json:"duration" validate:"required,min=1"`
+	}
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	until := time.Now().UTC().Add(time.Duration(payload.Duration) * time.Hour)
+
+	err = h.record(c, contextUser, data.AuditProjectFeature, projectID, map[string]interface{}{
+		"duration":       payload.Duration,
+		"featured_until": until,
+	})
+	if err != nil {
+		return err
+	}
+
+	project, err := h.featuredService.Feature(projectID, contextUser.ID, until)
+	if err != nil {
+		if err == services.ErrProjectNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "Public project not found")
+		}
+		logging.Error(c.Request().Context(), "Internal featuring error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to feature project")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"project": project,
+	})
+}
+
+// Unfeature handles the request to end the featured slot of a project right away.
+func (h *FeaturedHandler) Unfeature(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	if err := h.record(c, contextUser, data.AuditProjectUnfeature, projectID, nil); err != nil {
+		return err
+	}
+
+	if err := h.featuredService.Unfeature(projectID); err != nil {
+		if err == services.ErrRecordNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "Project is not featured")
+		}
+		logging.Error(c.Request().Context(), "Internal unfeaturing error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to unfeature project")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// History handles the request to retrieve who featured, unfeatured, queued or pinned what, newest first.
+// It can be narrowed down to a single project with project_id.
+func (h *FeaturedHandler) History(c echo.Context) error {
+	filter := data.AuditFilter{
+		Actions:    data.FeaturingAuditActions,
+		TargetType: "project",
+	}
+
+	if v := c.QueryParam("project_id"); v != "" {
+		projectID, err := uuid.Parse(v)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+		}
+		filter.TargetID = projectID.String()
+	}
+
+	filter.Limit, _ = strconv.Atoi(c.QueryParam("limit"))
+	if filter.Limit <= 0 || filter.Limit > featuredHistoryLimit {
+		filter.Limit = 50
+	}
+
+	entries, err := h.auditService.List(filter)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal featuring history retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve featuring history")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"history": entries,
+	})
+}
+
+// GetQueue handles the request to retrieve the curated featured queue.
+func (h *FeaturedHandler) GetQueue(c echo.Context) error {
+	queue, err := h.featuredService.GetQueue()
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal featured queue retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve featured queue")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"queue": queue,
+	})
+}
+
+// Enqueue handles the request to add a public project to the curated featured queue.
+func (h *FeaturedHandler) Enqueue(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var payload struct {
+		ProjectID uuid.UUID `json:"project_id" validate:"required"`
+	}
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := h.record(c, contextUser, data.AuditFeaturedEnqueue, payload.ProjectID, nil); err != nil {
+		return err
+	}
+
+	entry, err := h.featuredService.Enqueue(payload.ProjectID, contextUser.ID)
+	if err != nil {
+		switch err {
+		case services.ErrProjectNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, "Public project not found")
+		case services.ErrAlreadyQueued:
+			return echo.NewHTTPError(http.StatusConflict, "Project is already queued")
+		}
+		logging.Error(c.Request().Context(), "Internal featured queue error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to queue project")
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"entry": entry,
+	})
+}
+
+// Dequeue handles the request to remove a project from the curated featured queue.
+func (h *FeaturedHandler) Dequeue(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	if err := h.record(c, contextUser, data.AuditFeaturedDequeue, projectID, nil); err != nil {
+		return err
+	}
+
+	if err := h.featuredService.Dequeue(projectID); err != nil {
+		if err == services.ErrRecordNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "Project is not queued")
+		}
+		logging.Error(c.Request().Context(), "Internal featured queue error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to remove project from queue")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Pin handles the request to pin or unpin a featured project.
+// Pinned projects are exempt from the inactivity-based rotation.
+func (h *FeaturedHandler) Pin(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	var payload struct {
+		Pinned *bool `json:"pinned" validate:"required"`
+	}
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	err = h.record(c, contextUser, data.AuditFeaturedPin, projectID, map[string]interface{}{
+		"pinned": *payload.Pinned,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := h.featuredService.SetPinned(projectID, *payload.Pinned); err != nil {
+		if err == services.ErrProjectNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		logging.Error(c.Request().Context(), "Internal featured pin error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to pin project")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Rotate handles the request to run the featured rotation immediately instead of waiting for the scheduler.
+// The projects it expired or featured are recorded in the audit log afterwards.
+func (h *FeaturedHandler) Rotate(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	rotation, err := h.featuredService.Rotate()
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal featured rotation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to rotate featured projects")
+	}
+
+	// the rotation already happened, so a failure to record it is only logged
+	if err := featured.RecordRotation(h.auditService, &contextUser.ID, c.RealIP(), rotation); err != nil {
+		logging.Error(c.Request().Context(), "Internal audit log error", err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"rotation": rotation,
+	})
+}
+
+// record adds a featuring action on a project to the audit log before it's carried out.
+func (h *FeaturedHandler) record(c echo.Context, actor *data.User, action string, projectID uuid.UUID, details map[string]interface{}) error {
+	err := h.auditService.Record(data.AuditEntry{
+		ActorID:    &actor.ID,
+		Action:     action,
+		TargetType: "project",
+		TargetID:   projectID.String(),
+		Details:    details,
+		IP:         c.RealIP(),
+	})
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal audit log error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to record featuring change")
+	}
+	return nil
+}
@@ -0,0 +1,428 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestEnqueueFeatured(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	mockFeaturedService := mocks.MockFeaturedService{}
+	mockAuditService := mocks.MockAuditService{}
+	handler := NewFeaturedHandler(&mockFeaturedService, &mockAuditService)
+
+	admin := &data.User{ID: uuid.New(), Username: "admin", IsActivated: true}
+	mockAuditService.On("Record", mock.MatchedBy(func(entry data.AuditEntry) bool {
+		return entry.Action == data.AuditFeaturedEnqueue && *entry.ActorID == admin.ID
+	})).Return(nil)
+	projectID := uuid.New()
+	privateID := uuid.New()
+	queuedID := uuid.New()
+
+	mockFeaturedService.On("Enqueue", privateID, admin.ID).Return(nil, services.ErrProjectNotFound)
+	mockFeaturedService.On("Enqueue", queuedID, admin.ID).Return(nil, services.ErrAlreadyQueued)
+	mockFeaturedService.On("Enqueue", projectID, admin.ID).Return(&data.FeaturedQueueEntry{ProjectID: projectID, QueuedBy: &admin.ID}, nil)
+
+	tests := map[string]struct {
+		reqBody   string
+		wantCode  int
+		wantError bool
+	}{
+		"Missing project ID": {
+			reqBody:   `{}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Project not public": {
+			reqBody:   `{"project_id":"` + privateID.String() + `"}`,
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Project already queued": {
+			reqBody:   `{"project_id":"` + queuedID.String() + `"}`,
+			wantCode:  http.StatusConflict,
+			wantError: true,
+		},
+		"Successful enqueue": {
+			reqBody:  `{"project_id":"` + projectID.String() + `"}`,
+			wantCode: http.StatusCreated,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", admin)
+
+			err := handler.Enqueue(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
+
+func TestPinFeatured(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	mockFeaturedService := mocks.MockFeaturedService{}
+	mockAuditService := mocks.MockAuditService{}
+	handler := NewFeaturedHandler(&mockFeaturedService, &mockAuditService)
+
+	admin := &data.User{ID: uuid.New(), Username: "admin", IsActivated: true}
+	projectID := uuid.New()
+	missingID := uuid.New()
+
+	mockAuditService.On("Record", mock.MatchedBy(func(entry data.AuditEntry) bool {
+		return entry.Action == data.AuditFeaturedPin && *entry.ActorID == admin.ID
+	})).Return(nil)
+
+	mockFeaturedService.On("SetPinned", missingID, true).Return(services.ErrProjectNotFound)
+	mockFeaturedService.On("SetPinned", projectID, false).Return(nil)
+
+	tests := map[string]struct {
+		projectID string
+		reqBody   string
+		wantCode  int
+		wantError bool
+	}{
+		"Invalid project ID": {
+			projectID: "invalid",
+			reqBody:   `{"pinned":true}`,
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Missing pinned flag": {
+			projectID: projectID.String(),
+			reqBody:   `{}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Project not found": {
+			projectID: missingID.String(),
+			reqBody:   `{"pinned":true}`,
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Successful unpin": {
+			projectID: projectID.String(),
+			reqBody:   `{"pinned":false}`,
+			wantCode:  http.StatusNoContent,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.projectID)
+			c.Set("user", admin)
+
+			err := handler.Pin(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
+
+func TestFeatureFeaturedProject(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	admin := &data.User{ID: uuid.New(), Username: "admin", IsActivated: true}
+	projectID := uuid.New()
+	// featured_until is computed from the duration when the request is handled
+	inAWeek := mock.MatchedBy(func(until time.Time) bool {
+		return time.Until(until) > 167*time.Hour && time.Until(until) <= 168*time.Hour
+	})
+	audited := func(a *mocks.MockAuditService) {
+		a.On("Record", mock.MatchedBy(func(entry data.AuditEntry) bool {
+			return entry.Action == data.AuditProjectFeature && *entry.ActorID == admin.ID && entry.TargetID == projectID.String()
+		})).Return(nil)
+	}
+
+	tests := map[string]struct {
+		projectID  string
+		reqBody    string
+		setupMocks func(f *mocks.MockFeaturedService, a *mocks.MockAuditService)
+		wantCode   int
+		wantError  bool
+	}{
+		"Invalid project ID": {
+			projectID: "invalid",
+			reqBody:   `{"duration":168}`,
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Missing duration": {
+			projectID: projectID.String(),
+			reqBody:   `{}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Negative duration": {
+			projectID: projectID.String(),
+			reqBody:   `{"duration":-1}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Project not public": {
+			projectID: projectID.String(),
+			reqBody:   `{"duration":168}`,
+			setupMocks: func(f *mocks.MockFeaturedService, a *mocks.MockAuditService) {
+				audited(a)
+				f.On("Feature", projectID, admin.ID, inAWeek).Return(nil, services.ErrProjectNotFound)
+			},
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Audit log failure": {
+			projectID: projectID.String(),
+			reqBody:   `{"duration":168}`,
+			setupMocks: func(f *mocks.MockFeaturedService, a *mocks.MockAuditService) {
+				a.On("Record", mock.Anything).Return(errors.New("database error"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+		"Successful feature": {
+			projectID: projectID.String(),
+			reqBody:   `{"duration":168}`,
+			setupMocks: func(f *mocks.MockFeaturedService, a *mocks.MockAuditService) {
+				audited(a)
+				f.On("Feature", projectID, admin.ID, inAWeek).Return(&data.FeaturedProject{ProjectID: projectID, FeaturedBy: &admin.ID}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockFeaturedService := mocks.MockFeaturedService{}
+			mockAuditService := mocks.MockAuditService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockFeaturedService, &mockAuditService)
+			}
+			handler := NewFeaturedHandler(&mockFeaturedService, &mockAuditService)
+
+			req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.projectID)
+			c.Set("user", admin)
+
+			err := handler.Feature(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+			mockFeaturedService.AssertExpectations(t)
+			mockAuditService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUnfeatureProject(t *testing.T) {
+	e := echo.New()
+
+	admin := &data.User{ID: uuid.New(), Username: "admin", IsActivated: true}
+	projectID := uuid.New()
+
+	mockFeaturedService := mocks.MockFeaturedService{}
+	mockAuditService := mocks.MockAuditService{}
+	handler := NewFeaturedHandler(&mockFeaturedService, &mockAuditService)
+
+	notFeaturedID := uuid.New()
+	mockAuditService.On("Record", mock.MatchedBy(func(entry data.AuditEntry) bool {
+		return entry.Action == data.AuditProjectUnfeature && *entry.ActorID == admin.ID
+	})).Return(nil)
+	mockFeaturedService.On("Unfeature", notFeaturedID).Return(services.ErrRecordNotFound)
+	mockFeaturedService.On("Unfeature", projectID).Return(nil)
+
+	tests := map[string]struct {
+		projectID string
+		wantCode  int
+		wantError bool
+	}{
+		"Invalid project ID": {
+			projectID: "invalid",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Project not featured": {
+			projectID: notFeaturedID.String(),
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Successful unfeature": {
+			projectID: projectID.String(),
+			wantCode:  http.StatusNoContent,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.projectID)
+			c.Set("user", admin)
+
+			err := handler.Unfeature(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
+
+func TestListFeatured(t *testing.T) {
+	e := echo.New()
+
+	mockFeaturedService := mocks.MockFeaturedService{}
+	handler := NewFeaturedHandler(&mockFeaturedService, &mocks.MockAuditService{})
+
+	mockFeaturedService.On("ListFeatured", time.Duration(0)).Return([]data.FeaturedProject{}, nil)
+	mockFeaturedService.On("ListFeatured", 24*time.Hour).Return([]data.FeaturedProject{}, nil)
+
+	tests := map[string]struct {
+		query     string
+		wantCode  int
+		wantError bool
+	}{
+		"All featured projects": {
+			wantCode: http.StatusOK,
+		},
+		"Expiring within a day": {
+			query:    "?expiring_within=24",
+			wantCode: http.StatusOK,
+		},
+		"Invalid expiring_within": {
+			query:     "?expiring_within=soon",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/featured"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.List(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+		})
+	}
+	mockFeaturedService.AssertExpectations(t)
+}
+
+func TestFeaturedHistory(t *testing.T) {
+	e := echo.New()
+
+	mockAuditService := mocks.MockAuditService{}
+	handler := NewFeaturedHandler(&mocks.MockFeaturedService{}, &mockAuditService)
+
+	projectID := uuid.New()
+	mockAuditService.On("List", data.AuditFilter{Actions: data.FeaturingAuditActions, TargetType: "project", Limit: 50}).Return([]data.AuditEntry{}, nil)
+	mockAuditService.On("List", data.AuditFilter{Actions: data.FeaturingAuditActions, TargetType: "project", TargetID: projectID.String(), Limit: 10}).Return([]data.AuditEntry{}, nil)
+
+	tests := map[string]struct {
+		query     string
+		wantCode  int
+		wantError bool
+	}{
+		"Latest changes": {
+			wantCode: http.StatusOK,
+		},
+		"Changes to one project": {
+			query:    "?project_id=" + projectID.String() + "&limit=10",
+			wantCode: http.StatusOK,
+		},
+		"Invalid project ID": {
+			query:     "?project_id=invalid",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/featured/history"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.History(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+		})
+	}
+	mockAuditService.AssertExpectations(t)
+}
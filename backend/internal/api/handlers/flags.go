@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services/flags"
+
+	"github.com/labstack/echo/v4"
+)
+
+// flagNamePattern restricts flag names to what the client can reference as a plain key.
+var flagNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,63}$`)
+
+// FlagHandler handles HTTP requests related to feature flags.
+type FlagHandler struct {
+	flagService flags.IFlagService
+}
+
+// NewFlagHandler creates a new FlagHandler with the provided flag service.
+func NewFlagHandler(flagService flags.IFlagService) FlagHandler {
+	return FlagHandler{
+		flagService: flagService,
+	}
+}
+
+// GetEnabled handles the request to list the names of the enabled feature flags, used by the client to toggle features.
+func (h *FlagHandler) GetEnabled(c echo.Context) error {
+	all, err := h.flagService.GetFlags()
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal feature flag retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve feature flags")
+	}
+
+	enabled := make([]string, 0)
+	for _, f := range all {
+		if f.Enabled {
+			enabled = append(enabled, f.Name)
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"flags": enabled,
+	})
+}
+
+// List handles the request to list every feature flag with its state.
+func (h *FlagHandler) List(c echo.Context) error {
+	all, err := h.flagService.GetFlags()
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal feature flag retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve feature flags")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"flags": all,
+	})
+}
+
+// Set handles the request to turn a feature flag on or off, creating it if needed.
+func (h *FlagHandler) Set(c echo.Context) error {
+	name := c.Param("name")
+	if !flagNamePattern.MatchString(name) {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid flag name")
+	}
+
+	var payload struct {
+		Enabled     *bool   `json:"enabled" validate:"required"`
+		Description *string `json:"description" validate:"omitempty,max=500"`
+	}
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	flag, err := h.flagService.SetFlag(name, *payload.Enabled, payload.Description)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal feature flag update error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update feature flag")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"flag": flag,
+	})
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+
+	"github.com/go-playground/validator"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetEnabledFlags(t *testing.T) {
+	e := echo.New()
+
+	mockFlagService := mocks.MockFlagService{}
+	mockFlagService.On("GetFlags").Return([]data.FeatureFlag{
+		{Name: "comments", Enabled: true},
+		{Name: "new-editor", Enabled: false},
+	}, nil)
+
+	handler := NewFlagHandler(&mockFlagService)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	assert.NoError(t, handler.GetEnabled(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"flags":["comments"]}`, rec.Body.String())
+}
+
+func TestSetFlag(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	mockFlagService := mocks.MockFlagService{}
+	mockFlagService.On("SetFlag", "new-editor", true, mock.Anything).Return(&data.FeatureFlag{Name: "new-editor", Enabled: true, UpdatedAt: time.Now()}, nil)
+	mockFlagService.On("SetFlag", "broken", false, mock.Anything).Return(nil, errors.New("database error"))
+
+	handler := NewFlagHandler(&mockFlagService)
+
+	tests := map[string]struct {
+		name      string
+		reqBody   string
+		wantCode  int
+		wantError bool
+	}{
+		"Enable flag": {
+			name:     "new-editor",
+			reqBody:  `{"enabled":true,"description":"Node editor rewrite"}`,
+			wantCode: http.StatusOK,
+		},
+		"Invalid flag name": {
+			name:      "New Editor",
+			reqBody:   `{"enabled":true}`,
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Missing enabled": {
+			name:      "new-editor",
+			reqBody:   `{"description":"Node editor rewrite"}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Malformed JSON": {
+			name:      "new-editor",
+			reqBody:   `{"enabled":`,
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Database error": {
+			name:      "broken",
+			reqBody:   `{"enabled":false}`,
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("name")
+			c.SetParamValues(tt.name)
+
+			err := handler.Set(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+		})
+	}
+
+	mockFlagService.AssertExpectations(t)
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/folders"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// FolderHandler handles HTTP requests for the folders users sort their own projects into.
+type FolderHandler struct {
+	folderService folders.IFolderService
+}
+
+// NewFolderHandler creates a new FolderHandler with the provided services.
+func NewFolderHandler(folderService folders.IFolderService) FolderHandler {
+	return FolderHandler{
+		folderService: folderService,
+	}
+}
+
+// List handles the request to list the folders of the current user, with the projects in each.
+func (h *FolderHandler) List(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	folders, err := h.folderService.ListFolders(c.Request().Context(), contextUser.ID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal folder retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve folders")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"folders": folders,
+	})
+}
+
+// Create handles the request to create a folder, or a subfolder of a top-level folder.
+func (h *FolderHandler) Create(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var payload data.FolderCreate
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	folder, err := h.folderService.CreateFolder(c.Request().Context(), contextUser.ID, payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRecordNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, "Parent folder not found")
+		case errors.Is(err, services.ErrFolderTooDeep):
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, "Folders can only be nested one level deep")
+		}
+		logging.Error(c.Request().Context(), "Internal folder creation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create folder")
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"folder": folder,
+	})
+}
+
+// Rename handles the request to rename a folder of the current user.
+func (h *FolderHandler) Rename(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	folderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid folder ID")
+	}
+
+	var payload struct {
+		Name string `json:"name" validate:"required,min=1,max=100"`
+	}
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	folder, err := h.folderService.RenameFolder(c.Request().Context(), contextUser.ID, folderID, payload.Name)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Folder not found")
+		}
+		logging.Error(c.Request().Context(), "Internal folder update error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to rename folder")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"folder": folder,
+	})
+}
+
+// Delete handles the request to delete a folder of the current user and its subfolders.
+// The projects in them are kept, in no folder.
+func (h *FolderHandler) Delete(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	folderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid folder ID")
+	}
+
+	if err := h.folderService.DeleteFolder(c.Request().Context(), contextUser.ID, folderID); err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Folder not found")
+		}
+		logging.Error(c.Request().Context(), "Internal folder deletion error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete folder")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ReorderFolders handles the request to put the top-level folders, or the subfolders of the parent folder, in a new order.
+// The order has to list every one of them.
+func (h *FolderHandler) ReorderFolders(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var payload struct {
+		ParentID  *uuid.UUID  `json:"parent_id"`
+		FolderIDs []uuid.UUID `json:"folder_ids" validate:"required"`
+	}
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	err := h.folderService.ReorderFolders(c.Request().Context(), contextUser.ID, payload.ParentID, payload.FolderIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRecordNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, "Parent folder not found")
+		case errors.Is(err, services.ErrFolderOrder):
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, "The order must list every folder exactly once")
+		}
+		logging.Error(c.Request().Context(), "Internal folder reorder error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to reorder folders")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ReorderProjects handles the request to put the projects in a folder in a new order.
+// The order has to list every project in the folder.
+func (h *FolderHandler) ReorderProjects(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	folderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid folder ID")
+	}
+
+	var payload struct {
+		ProjectIDs []uuid.UUID `json:"project_ids" validate:"required"`
+	}
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	err = h.folderService.ReorderProjects(c.Request().Context(), contextUser.ID, folderID, payload.ProjectIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRecordNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, "Folder not found")
+		case errors.Is(err, services.ErrFolderOrder):
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, "The order must list every project in the folder exactly once")
+		}
+		logging.Error(c.Request().Context(), "Internal folder reorder error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to reorder projects")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// MoveProject handles the request to put a project of the current user last in one of their folders,
+// or to take it out of its folder with a null folder_id.
+func (h *FolderHandler) MoveProject(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	var payload struct {
+		FolderID *uuid.UUID `json:"folder_id"`
+	}
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	err = h.folderService.MoveProject(c.Request().Context(), contextUser.ID, projectID, payload.FolderID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrProjectNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		case errors.Is(err, services.ErrRecordNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, "Folder not found")
+		}
+		logging.Error(c.Request().Context(), "Internal project move error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to move project")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateFolder(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	user := &data.User{ID: uuid.New(), Username: "owner", IsActivated: true}
+	parentID := uuid.New()
+	subfolderID := uuid.New()
+
+	mockFolderService := mocks.MockFolderService{}
+	mockFolderService.On("CreateFolder", user.ID, data.FolderCreate{Name: "Loops"}).Return(&data.Folder{ID: uuid.New(), Name: "Loops"}, nil)
+	mockFolderService.On("CreateFolder", user.ID, data.FolderCreate{Name: "Spirals", ParentID: &parentID}).Return(&data.Folder{ID: uuid.New(), ParentID: &parentID, Name: "Spirals"}, nil)
+	mockFolderService.On("CreateFolder", user.ID, data.FolderCreate{Name: "Deeper", ParentID: &subfolderID}).Return(nil, services.ErrFolderTooDeep)
+
+	handler := NewFolderHandler(&mockFolderService)
+
+	tests := map[string]struct {
+		reqBody   string
+		wantCode  int
+		wantError bool
+	}{
+		"Top-level folder": {
+			reqBody:  `{"name":"Loops"}`,
+			wantCode: http.StatusCreated,
+		},
+		"Subfolder": {
+			reqBody:  `{"name":"Spirals","parent_id":"` + parentID.String() + `"}`,
+			wantCode: http.StatusCreated,
+		},
+		"Subfolder of a subfolder": {
+			reqBody:   `{"name":"Deeper","parent_id":"` + subfolderID.String() + `"}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Missing name": {
+			reqBody:   `{}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Invalid request body": {
+			reqBody:   `invalid json`,
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", user)
+
+			err := handler.Create(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
+
+func TestReorderFolderProjects(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	user := &data.User{ID: uuid.New(), Username: "owner", IsActivated: true}
+	folderID := uuid.New()
+	otherFolderID := uuid.New()
+	first, second := uuid.New(), uuid.New()
+
+	mockFolderService := mocks.MockFolderService{}
+	mockFolderService.On("ReorderProjects", user.ID, folderID, []uuid.UUID{second, first}).Return(nil)
+	mockFolderService.On("ReorderProjects", user.ID, folderID, []uuid.UUID{second}).Return(services.ErrFolderOrder)
+	mockFolderService.On("ReorderProjects", user.ID, otherFolderID, []uuid.UUID{second, first}).Return(services.ErrRecordNotFound)
+
+	handler := NewFolderHandler(&mockFolderService)
+
+	tests := map[string]struct {
+		folderID  string
+		reqBody   string
+		wantCode  int
+		wantError bool
+	}{
+		"Reordered": {
+			folderID: folderID.String(),
+			reqBody:  `{"project_ids":["` + second.String() + `","` + first.String() + `"]}`,
+			wantCode: http.StatusNoContent,
+		},
+		"Project left out": {
+			folderID:  folderID.String(),
+			reqBody:   `{"project_ids":["` + second.String() + `"]}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Folder of another user": {
+			folderID:  otherFolderID.String(),
+			reqBody:   `{"project_ids":["` + second.String() + `","` + first.String() + `"]}`,
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Invalid folder ID": {
+			folderID:  "invalid-uuid",
+			reqBody:   `{"project_ids":[]}`,
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.folderID)
+			c.Set("user", user)
+
+			err := handler.ReorderProjects(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
+
+func TestMoveProjectToFolder(t *testing.T) {
+	e := echo.New()
+
+	user := &data.User{ID: uuid.New(), Username: "owner", IsActivated: true}
+	projectID := uuid.New()
+	otherProjectID := uuid.New()
+	folderID := uuid.New()
+
+	mockFolderService := mocks.MockFolderService{}
+	mockFolderService.On("MoveProject", user.ID, projectID, &folderID).Return(nil)
+	mockFolderService.On("MoveProject", user.ID, projectID, (*uuid.UUID)(nil)).Return(nil)
+	mockFolderService.On("MoveProject", user.ID, otherProjectID, &folderID).Return(services.ErrProjectNotFound)
+
+	handler := NewFolderHandler(&mockFolderService)
+
+	tests := map[string]struct {
+		projectID string
+		reqBody   string
+		wantCode  int
+		wantError bool
+	}{
+		"Moved into a folder": {
+			projectID: projectID.String(),
+			reqBody:   `{"folder_id":"` + folderID.String() + `"}`,
+			wantCode:  http.StatusNoContent,
+		},
+		"Taken out of its folder": {
+			projectID: projectID.String(),
+			reqBody:   `{"folder_id":null}`,
+			wantCode:  http.StatusNoContent,
+		},
+		"Project of another user": {
+			projectID: otherProjectID.String(),
+			reqBody:   `{"folder_id":"` + folderID.String() + `"}`,
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.projectID)
+			c.Set("user", user)
+
+			err := handler.MoveProject(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/auth"
+	"NodeTurtleAPI/internal/services/guests"
+	"NodeTurtleAPI/internal/services/mail"
+	"NodeTurtleAPI/internal/services/passwords"
+	"NodeTurtleAPI/internal/services/tokens"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GuestHandler handles HTTP requests related to anonymous guest accounts.
+type GuestHandler struct {
+	guestService    guests.IGuestService
+	authService     auth.IAuthService
+	tokenService    tokens.ITokenService
+	mailService     mail.IMailService
+	passwordService passwords.IPasswordService
+}
+
+// NewGuestHandler creates a new GuestHandler with the provided services.
+func NewGuestHandler(guestService guests.IGuestService, authService auth.IAuthService, tokenService tokens.ITokenService, mailService mail.IMailService, passwordService passwords.IPasswordService) GuestHandler {
+	return GuestHandler{
+		guestService:    guestService,
+		authService:     authService,
+		tokenService:    tokenService,
+		mailService:     mailService,
+		passwordService: passwordService,
+	}
+}
+
+// Create handles the request to start a session as a new guest.
+// Guests can try the editor with a few private projects, which are deleted when the guest expires unless claimed.
+func (h *GuestHandler) Create(c echo.Context) error {
+	guest, err := h.guestService.CreateGuest(c.Request().Context())
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal guest creation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create guest account")
+	}
+
+	token, err := h.authService.CreateAccessToken(*guest)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal access token creation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create access token")
+	}
+
+	return startSession(c, h.tokenService, token, guest)
+}
+
+// Claim handles the request of a guest to register an account, the projects of the guest are moved to it.
+// The guest session ends, the new account has to be activated like any other registration.
+func (h *GuestHandler) Claim(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	if !contextUser.IsGuest() {
+		return echo.NewHTTPError(http.StatusForbidden, "Only guest accounts can be claimed")
+	}
+
+	var registration data.UserRegistration
+	if err := c.Bind(&registration); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&registration); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if data.LooksVerified(registration.Username) {
+		return reservedUsername()
+	}
+
+	if strength := h.passwordService.Evaluate(registration.Password, []string{registration.Username, registration.Email}); !strength.Acceptable() {
+		return weakPassword(strength)
+	}
+
+	user, err := h.guestService.ClaimGuest(c.Request().Context(), contextUser.ID, registration)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrDuplicateEmail):
+			return echo.NewHTTPError(http.StatusConflict, "Email is already taken")
+		case errors.Is(err, services.ErrDuplicateUsername):
+			return echo.NewHTTPError(http.StatusConflict, "Username is already taken")
+		case errors.Is(err, services.ErrRecordNotFound):
+			return echo.NewHTTPError(http.StatusUnauthorized, "GUEST_EXPIRED")
+		}
+		logging.Error(c.Request().Context(), "Internal guest claim error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create user")
+	}
+
+	clearTokenCookies(c)
+
+	activationToken, err := h.tokenService.New(c.Request().Context(), user.ID, data.ScopeUserActivation)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal activation token creation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create Activation token")
+	}
+
+	activationLink := fmt.Sprintf("/activate/%s", activationToken.Plaintext)
+	emailData := map[string]string{
+		"Username":  user.Username,
+		"url":       activationLink,
+		"ExpiresAt": formatExpiry(activationToken.ExpiresAt),
+	}
+	// the guest is registered already, the activation email can be resent
+	if err := h.mailService.QueueEmail(c.Request().Context(), user.Email, "Activate Your Account", "activation", emailData); err != nil {
+		logging.Error(c.Request().Context(), "Failed to queue activation email", err)
+	}
+
+	return c.NoContent(http.StatusCreated)
+}
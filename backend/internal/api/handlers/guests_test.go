@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/utils"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestClaimGuest(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	guest := &data.User{ID: uuid.New(), Username: "guest1a2b3c4d5e6f", IsActivated: true, GuestExpiresAt: utils.Ptr(time.Now().Add(time.Hour))}
+	expiredGuest := &data.User{ID: uuid.New(), Username: "guest6f5e4d3c2b1a", IsActivated: true, GuestExpiresAt: utils.Ptr(time.Now().Add(time.Hour))}
+	registered := &data.User{ID: uuid.New(), Username: "registered", IsActivated: true}
+	claimed := &data.User{ID: uuid.New(), Email: "new@test.test", Username: "newuser"}
+
+	mockGuestService := mocks.MockGuestService{}
+	mockTokenService := mocks.MockTokenService{}
+	mockMailService := mocks.MockMailService{}
+	mockPasswordService := mocks.MockPasswordService{}
+
+	mockGuestService.On("ClaimGuest", guest.ID, mock.MatchedBy(func(reg data.UserRegistration) bool {
+		return reg.Email == "new@test.test"
+	})).Return(claimed, nil)
+	mockGuestService.On("ClaimGuest", guest.ID, mock.MatchedBy(func(reg data.UserRegistration) bool {
+		return reg.Email == "exists@test.test"
+	})).Return(nil, services.ErrDuplicateEmail)
+	mockGuestService.On("ClaimGuest", expiredGuest.ID, mock.Anything).Return(nil, services.ErrRecordNotFound)
+	mockTokenService.On("New", claimed.ID, data.ScopeUserActivation).Return(&data.Token{Plaintext: "mocktoken", Scope: data.ScopeUserActivation}, nil)
+	mockMailService.On("QueueEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockPasswordService.On("Evaluate", mock.Anything, mock.Anything).Return(data.PasswordStrength{Score: 4, Issues: []data.PasswordIssue{}})
+
+	handler := NewGuestHandler(&mockGuestService, &mocks.MockAuthService{}, &mockTokenService, &mockMailService, &mockPasswordService)
+
+	tests := map[string]struct {
+		contextUser *data.User
+		reqBody     string
+		wantCode    int
+		wantError   bool
+	}{
+		"Guest claimed": {
+			contextUser: guest,
+			reqBody:     `{"email":"new@test.test","username":"newuser","password":"Str0ngPassw0rd"}`,
+			wantCode:    http.StatusCreated,
+		},
+		"Email taken": {
+			contextUser: guest,
+			reqBody:     `{"email":"exists@test.test","username":"newuser","password":"Str0ngPassw0rd"}`,
+			wantCode:    http.StatusConflict,
+			wantError:   true,
+		},
+		"Guest expired": {
+			contextUser: expiredGuest,
+			reqBody:     `{"email":"new@test.test","username":"newuser","password":"Str0ngPassw0rd"}`,
+			wantCode:    http.StatusUnauthorized,
+			wantError:   true,
+		},
+		"Registered user": {
+			contextUser: registered,
+			reqBody:     `{"email":"new@test.test","username":"newuser","password":"Str0ngPassw0rd"}`,
+			wantCode:    http.StatusForbidden,
+			wantError:   true,
+		},
+		"Reserved username": {
+			contextUser: guest,
+			reqBody:     `{"email":"new@test.test","username":"OfficialTurtle","password":"Str0ngPassw0rd"}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Invalid registration": {
+			contextUser: guest,
+			reqBody:     `{"email":"not-an-email","username":"newuser","password":"Str0ngPassw0rd"}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", tt.contextUser)
+
+			err := handler.Claim(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				mockTokenService.AssertCalled(t, "New", claimed.ID, data.ScopeUserActivation)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/audit"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// historyLimit is the number of history entries returned when the request doesn't ask for a number.
+const historyLimit = 200
+
+// HistoryHandler handles HTTP requests for the history of users and projects, which staff look into to resolve disputes.
+type HistoryHandler struct {
+	auditService audit.IAuditService
+}
+
+// NewHistoryHandler creates a new HistoryHandler with the provided services.
+func NewHistoryHandler(auditService audit.IAuditService) HistoryHandler {
+	return HistoryHandler{
+		auditService: auditService,
+	}
+}
+
+// User handles the request to retrieve the audit log entries, sanctions and state changes of a user in chronological order.
+func (h *HistoryHandler) User(c echo.Context) error {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID")
+	}
+
+	filter, err := bindHistoryFilter(c)
+	if err != nil {
+		return err
+	}
+
+	entries, err := h.auditService.UserHistory(c.Request().Context(), userID, filter)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "User not found")
+		}
+		logging.Error(c.Request().Context(), "Internal user history error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve user history")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"history": entries,
+	})
+}
+
+// Project handles the request to retrieve the audit log entries, sanctions and state changes of a project in chronological order.
+func (h *HistoryHandler) Project(c echo.Context) error {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	filter, err := bindHistoryFilter(c)
+	if err != nil {
+		return err
+	}
+
+	entries, err := h.auditService.ProjectHistory(c.Request().Context(), projectID, filter)
+	if err != nil {
+		if errors.Is(err, services.ErrProjectNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		logging.Error(c.Request().Context(), "Internal project history error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve project history")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"history": entries,
+	})
+}
+
+// bindHistoryFilter reads the history filter from the query string.
+func bindHistoryFilter(c echo.Context) (data.HistoryFilter, error) {
+	var filter data.HistoryFilter
+	if err := c.Bind(&filter); err != nil {
+		return filter, echo.NewHTTPError(http.StatusBadRequest, "Invalid query parameters")
+	}
+
+	if err := c.Validate(&filter); err != nil {
+		return filter, echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if filter.After != nil && filter.Before != nil && filter.Before.Before(*filter.After) {
+		return filter, echo.NewHTTPError(http.StatusUnprocessableEntity, "before must not be earlier than after")
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = historyLimit
+	}
+	return filter, nil
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserHistory(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	userID := uuid.New()
+	after := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		id         string
+		query      string
+		setupMocks func(m *mocks.MockAuditService)
+		wantCode   int
+	}{
+		"Whole history": {
+			id: userID.String(),
+			setupMocks: func(m *mocks.MockAuditService) {
+				m.On("UserHistory", userID, data.HistoryFilter{Limit: historyLimit}).Return([]data.HistoryEntry{
+					{Kind: data.HistoryState, Action: "user.created"},
+					{Kind: data.HistorySanction, Action: "user.ban", Summary: "spam"},
+				}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Filtered history": {
+			id:    userID.String(),
+			query: "?kind=sanction&search=spam&after=2025-01-01T00:00:00Z&limit=10",
+			setupMocks: func(m *mocks.MockAuditService) {
+				m.On("UserHistory", userID, data.HistoryFilter{Kind: data.HistorySanction, Search: "spam", After: &after, Limit: 10}).Return([]data.HistoryEntry{}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Invalid user ID": {
+			id:       "abc",
+			wantCode: http.StatusBadRequest,
+		},
+		"Invalid kind": {
+			id:       userID.String(),
+			query:    "?kind=everything",
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		"Invalid date": {
+			id:       userID.String(),
+			query:    "?after=yesterday",
+			wantCode: http.StatusBadRequest,
+		},
+		"Range ends before it starts": {
+			id:       userID.String(),
+			query:    "?after=2025-02-01T00:00:00Z&before=2025-01-01T00:00:00Z",
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		"User not found": {
+			id: userID.String(),
+			setupMocks: func(m *mocks.MockAuditService) {
+				m.On("UserHistory", userID, data.HistoryFilter{Limit: historyLimit}).Return(nil, services.ErrUserNotFound)
+			},
+			wantCode: http.StatusNotFound,
+		},
+		"Database error": {
+			id: userID.String(),
+			setupMocks: func(m *mocks.MockAuditService) {
+				m.On("UserHistory", userID, data.HistoryFilter{Limit: historyLimit}).Return(nil, errors.New("database error"))
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockAuditService := mocks.MockAuditService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockAuditService)
+			}
+			handler := NewHistoryHandler(&mockAuditService)
+
+			req := httptest.NewRequest(http.MethodGet, "/"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+
+			err := handler.User(c)
+
+			if tt.wantCode != http.StatusOK {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+			mockAuditService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProjectHistory(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	projectID := uuid.New()
+
+	tests := map[string]struct {
+		id         string
+		setupMocks func(m *mocks.MockAuditService)
+		wantCode   int
+	}{
+		"Whole history": {
+			id: projectID.String(),
+			setupMocks: func(m *mocks.MockAuditService) {
+				m.On("ProjectHistory", projectID, data.HistoryFilter{Limit: historyLimit}).Return([]data.HistoryEntry{
+					{Kind: data.HistoryState, Action: "report.filed"},
+					{Kind: data.HistoryAudit, Action: data.AuditProjectTakedown},
+				}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Invalid project ID": {
+			id:       "abc",
+			wantCode: http.StatusBadRequest,
+		},
+		"Project not found": {
+			id: projectID.String(),
+			setupMocks: func(m *mocks.MockAuditService) {
+				m.On("ProjectHistory", projectID, data.HistoryFilter{Limit: historyLimit}).Return(nil, services.ErrProjectNotFound)
+			},
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockAuditService := mocks.MockAuditService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockAuditService)
+			}
+			handler := NewHistoryHandler(&mockAuditService)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+
+			err := handler.Project(c)
+
+			if tt.wantCode != http.StatusOK {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+			mockAuditService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/audit"
+	"NodeTurtleAPI/internal/services/auth"
+	"NodeTurtleAPI/internal/services/users"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// ImpersonationHandler handles HTTP requests letting support staff act as a user to debug what they see.
+type ImpersonationHandler struct {
+	userService  users.IUserService
+	authService  auth.IAuthService
+	auditService audit.IAuditService
+}
+
+// NewImpersonationHandler creates a new ImpersonationHandler with the provided services.
+func NewImpersonationHandler(userService users.IUserService, authService auth.IAuthService, auditService audit.IAuditService) ImpersonationHandler {
+	return ImpersonationHandler{
+		userService:  userService,
+		authService:  authService,
+		auditService: auditService,
+	}
+}
+
+// Start handles the request to impersonate a user.
+// The short-lived impersonation token replaces the access token cookie, the refresh token cookie is left alone,
+// so refreshing the session afterwards returns to the staff member's own account.
+func (h *ImpersonationHandler) Start(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	if _, impersonating := c.Get("impersonator").(uuid.UUID); impersonating {
+		return echo.NewHTTPError(http.StatusForbidden, "IMPERSONATION_FORBIDDEN")
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID")
+	}
+
+	if userID == contextUser.ID {
+		return echo.NewHTTPError(http.StatusBadRequest, "Cannot impersonate yourself")
+	}
+
+	var payload struct {
+		Reason string `json:"reason" validate:"required,max=500"`
+	}
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	user, err := h.userService.GetUserByID(c.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "User not found")
+		}
+		logging.Error(c.Request().Context(), "Internal user retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve user")
+	}
+
+	// acting as a staff member would hand out their permissions
+	if user.Role.Name != data.RoleUser.String() {
+		return echo.NewHTTPError(http.StatusForbidden, "Only regular users can be impersonated")
+	}
+
+	token, expiresAt, err := h.authService.CreateImpersonationToken(*user, contextUser.ID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal impersonation token creation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create impersonation token")
+	}
+
+	err = h.auditService.Record(data.AuditEntry{
+		ActorID:    &contextUser.ID,
+		Action:     data.AuditImpersonationStart,
+		TargetType: "user",
+		TargetID:   user.ID.String(),
+		Details: map[string]interface{}{
+			"reason":     payload.Reason,
+			"expires_at": expiresAt,
+		},
+		IP: c.RealIP(),
+	})
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal audit log error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to record impersonation")
+	}
+
+	c.SetCookie(&http.Cookie{
+		Name:     "access_token",
+		Value:    token,
+		Expires:  expiresAt,
+		HttpOnly: true,
+		Secure:   false,
+		SameSite: http.SameSiteLaxMode,
+		Path:     "/",
+	})
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"token":      token,
+		"expires_at": expiresAt,
+		"user": map[string]interface{}{
+			"id":       user.ID,
+			"username": user.Username,
+			"email":    user.Email,
+			"role":     user.Role.Name,
+		},
+	})
+}
+
+// Stop handles the request to end an impersonation by dropping the impersonation token cookie.
+// The client refreshes the session afterwards to get an access token for the staff member's own account.
+func (h *ImpersonationHandler) Stop(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	impersonatorID, ok := c.Get("impersonator").(uuid.UUID)
+	if !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "Not impersonating a user")
+	}
+
+	err := h.auditService.Record(data.AuditEntry{
+		ActorID:    &impersonatorID,
+		Action:     data.AuditImpersonationStop,
+		TargetType: "user",
+		TargetID:   contextUser.ID.String(),
+		IP:         c.RealIP(),
+	})
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal audit log error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to record impersonation")
+	}
+
+	c.SetCookie(&http.Cookie{
+		Name:     "access_token",
+		Value:    "",
+		Expires:  time.Unix(0, 0),
+		HttpOnly: true,
+		Secure:   false,
+		SameSite: http.SameSiteLaxMode,
+		Path:     "/",
+	})
+
+	return c.NoContent(http.StatusNoContent)
+}
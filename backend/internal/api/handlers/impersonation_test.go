@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStartImpersonation(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	admin := &data.User{ID: uuid.New(), Username: "admin", Role: data.Role{Name: data.RoleAdmin.String()}}
+	student := &data.User{ID: uuid.New(), Username: "student", Role: data.Role{Name: data.RoleUser.String()}}
+	moderator := &data.User{ID: uuid.New(), Username: "moderator", Role: data.Role{Name: data.RoleModerator.String()}}
+	unknownID := uuid.New()
+
+	mockUserService := mocks.MockUserService{}
+	mockAuthService := mocks.MockAuthService{}
+	mockAuditService := mocks.MockAuditService{}
+
+	mockUserService.On("GetUserByID", student.ID).Return(student, nil)
+	mockUserService.On("GetUserByID", moderator.ID).Return(moderator, nil)
+	mockUserService.On("GetUserByID", unknownID).Return(nil, services.ErrUserNotFound)
+
+	expiresAt := time.Now().Add(15 * time.Minute)
+	mockAuthService.On("CreateImpersonationToken", *student, admin.ID).Return("impersonation-token", expiresAt, nil)
+
+	mockAuditService.On("Record", mock.MatchedBy(func(entry data.AuditEntry) bool {
+		return *entry.ActorID == admin.ID && entry.Action == data.AuditImpersonationStart &&
+			entry.TargetID == student.ID.String() && entry.Details["reason"] == "Ticket 42"
+	})).Return(nil)
+
+	handler := NewImpersonationHandler(&mockUserService, &mockAuthService, &mockAuditService)
+
+	tests := map[string]struct {
+		userID        string
+		reqBody       string
+		impersonating bool
+		wantCode      int
+		wantError     bool
+	}{
+		"Impersonate regular user": {
+			userID:   student.ID.String(),
+			reqBody:  `{"reason":"Ticket 42"}`,
+			wantCode: http.StatusOK,
+		},
+		"Missing reason": {
+			userID:    student.ID.String(),
+			reqBody:   `{}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Invalid user ID": {
+			userID:    "abc",
+			reqBody:   `{"reason":"Ticket 42"}`,
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Impersonate yourself": {
+			userID:    admin.ID.String(),
+			reqBody:   `{"reason":"Ticket 42"}`,
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Unknown user": {
+			userID:    unknownID.String(),
+			reqBody:   `{"reason":"Ticket 42"}`,
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Staff member": {
+			userID:    moderator.ID.String(),
+			reqBody:   `{"reason":"Ticket 42"}`,
+			wantCode:  http.StatusForbidden,
+			wantError: true,
+		},
+		"Already impersonating": {
+			userID:        student.ID.String(),
+			reqBody:       `{"reason":"Ticket 42"}`,
+			impersonating: true,
+			wantCode:      http.StatusForbidden,
+			wantError:     true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.userID)
+			c.Set("user", admin)
+			if tt.impersonating {
+				c.Set("impersonator", uuid.New())
+			}
+
+			err := handler.Start(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Header().Get("Set-Cookie"), "access_token=impersonation-token")
+			}
+		})
+	}
+
+	mockAuthService.AssertExpectations(t)
+	mockAuditService.AssertExpectations(t)
+}
+
+func TestStopImpersonation(t *testing.T) {
+	e := echo.New()
+
+	student := &data.User{ID: uuid.New(), Username: "student"}
+	impersonatorID := uuid.New()
+
+	mockAuditService := mocks.MockAuditService{}
+	mockAuditService.On("Record", mock.MatchedBy(func(entry data.AuditEntry) bool {
+		return *entry.ActorID == impersonatorID && entry.Action == data.AuditImpersonationStop
+	})).Return(nil)
+
+	handler := NewImpersonationHandler(&mocks.MockUserService{}, &mocks.MockAuthService{}, &mockAuditService)
+
+	tests := map[string]struct {
+		impersonating bool
+		wantCode      int
+		wantError     bool
+	}{
+		"Impersonating": {
+			impersonating: true,
+			wantCode:      http.StatusNoContent,
+		},
+		"Own session": {
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", student)
+			if tt.impersonating {
+				c.Set("impersonator", impersonatorID)
+			}
+
+			err := handler.Stop(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Header().Get("Set-Cookie"), "access_token=;")
+			}
+		})
+	}
+
+	mockAuditService.AssertExpectations(t)
+}
@@ -0,0 +1,371 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"NodeTurtleAPI/internal/codegen"
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/flow"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/imports"
+	"NodeTurtleAPI/internal/services/projects"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// uploadOffsetHeader carries the position of a chunk within the file, as in the tus protocol.
+	uploadOffsetHeader = "Upload-Offset"
+	// chunkContentType is the content type of chunks, as in the tus protocol.
+	chunkContentType = "application/offset+octet-stream"
+)
+
+// ImportHandler handles HTTP requests to import projects from files.
+// Files up to the body limit can be sent in one request, larger ones are uploaded in chunks.
+// An interrupted upload resumes from the last chunk that was received, which flaky school networks depend on.
+type ImportHandler struct {
+	projectService projects.IProjectService
+	importService  imports.IImportService
+	limits         config.LimitsConfig
+	cfg            config.ImportsConfig
+}
+
+// NewImportHandler creates a new ImportHandler with the provided services and limits.
+func NewImportHandler(projectService projects.IProjectService, importService imports.IImportService, limits config.LimitsConfig, cfg config.ImportsConfig) ImportHandler {
+	return ImportHandler{
+		projectService: projectService,
+		importService:  importService,
+		limits:         limits,
+		cfg:            cfg,
+	}
+}
+
+// Import handles the request to create a project from an import file sent as the request body.
+func (h *ImportHandler) Import(c echo.Context) error {
+	contextUser, err := activatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	file, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	return h.importProject(c, contextUser, file)
+}
+
+// CreateUpload handles the request to start a chunked upload of an import file of the announced size.
+func (h *ImportHandler) CreateUpload(c echo.Context) error {
+	contextUser, err := activatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	var payload struct {
+		Size int64 `json:"size" validate:"required,min=1"`
+	}
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if payload.Size > int64(h.cfg.MaxBytes) {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, map[string]interface{}{
+			"message":  "Import file is too large",
+			"max_size": h.cfg.MaxBytes,
+		})
+	}
+
+	upload, err := h.importService.CreateUpload(c.Request().Context(), contextUser.ID, payload.Size)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal upload creation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to start upload")
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"upload":     upload,
+		"chunk_size": h.cfg.ChunkBytes,
+	})
+}
+
+// GetUpload handles the request to retrieve an upload, clients resume an interrupted upload from its offset.
+func (h *ImportHandler) GetUpload(c echo.Context) error {
+	contextUser, uploadID, err := h.uploadParams(c)
+	if err != nil {
+		return err
+	}
+
+	upload, err := h.importService.GetUpload(c.Request().Context(), uploadID, contextUser.ID)
+	if err != nil {
+		return uploadError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"upload": upload,
+	})
+}
+
+// AppendChunk handles the request to upload the next chunk of a file. The chunk is the raw request body,
+// and the Upload-Offset header has to match the bytes received so far, so a chunk is never stored twice.
+func (h *ImportHandler) AppendChunk(c echo.Context) error {
+	contextUser, uploadID, err := h.uploadParams(c)
+	if err != nil {
+		return err
+	}
+
+	if c.Request().Header.Get(echo.HeaderContentType) != chunkContentType {
+		return echo.NewHTTPError(http.StatusUnsupportedMediaType, "Chunks must be sent as "+chunkContentType)
+	}
+
+	offset, err := strconv.ParseInt(c.Request().Header.Get(uploadOffsetHeader), 10, 64)
+	if err != nil || offset < 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid upload offset")
+	}
+
+	chunk, err := io.ReadAll(io.LimitReader(c.Request().Body, int64(h.cfg.ChunkBytes)+1))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+	if len(chunk) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "Chunk is empty")
+	}
+	if len(chunk) > h.cfg.ChunkBytes {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, map[string]interface{}{
+			"message":    "Chunk is too large",
+			"chunk_size": h.cfg.ChunkBytes,
+		})
+	}
+
+	upload, err := h.importService.AppendChunk(c.Request().Context(), uploadID, contextUser.ID, offset, chunk)
+	if err != nil {
+		switch err {
+		case services.ErrUploadOffset:
+			return echo.NewHTTPError(http.StatusConflict, map[string]interface{}{
+				"message": "Chunk does not start at the upload offset",
+				"offset":  upload.Offset,
+			})
+		case services.ErrUploadSize:
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, "Chunk exceeds the size of the upload")
+		}
+		return uploadError(c, err)
+	}
+
+	c.Response().Header().Set(uploadOffsetHeader, strconv.FormatInt(upload.Offset, 10))
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"upload": upload,
+	})
+}
+
+// CompleteUpload handles the request to create a project from a fully uploaded file.
+// The upload is deleted once the project is created. A file that fails validation is kept until it expires,
+// so the response can be inspected without uploading again.
+func (h *ImportHandler) CompleteUpload(c echo.Context) error {
+	contextUser, uploadID, err := h.uploadParams(c)
+	if err != nil {
+		return err
+	}
+
+	file, err := h.importService.ReadUpload(c.Request().Context(), uploadID, contextUser.ID)
+	if err != nil {
+		if err == services.ErrUploadIncomplete {
+			return echo.NewHTTPError(http.StatusConflict, "Upload is incomplete")
+		}
+		return uploadError(c, err)
+	}
+
+	if err := h.importProject(c, contextUser, file); err != nil {
+		return err
+	}
+
+	if err := h.importService.DeleteUpload(c.Request().Context(), uploadID, contextUser.ID); err != nil {
+		logging.Error(c.Request().Context(), "Internal upload deletion error", err)
+	}
+
+	return nil
+}
+
+// CancelUpload handles the request to abandon an upload.
+func (h *ImportHandler) CancelUpload(c echo.Context) error {
+	contextUser, uploadID, err := h.uploadParams(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.importService.DeleteUpload(c.Request().Context(), uploadID, contextUser.ID); err != nil {
+		return uploadError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// importProject validates an import file and creates a private project of the user from it.
+func (h *ImportHandler) importProject(c echo.Context, user *data.User, file []byte) error {
+	var payload data.ProjectImport
+	if err := json.Unmarshal(file, &payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, "Invalid import file")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	flowData, err := h.importedFlow("data", payload.Data)
+	if err != nil {
+		return err
+	}
+
+	// revisions are checked like the current flow, an instance with lower limits refuses them the same way
+	for i := range payload.Revisions {
+		revisionData, err := h.importedFlow(fmt.Sprintf("revisions[%d].data", i), payload.Revisions[i].Data)
+		if err != nil {
+			return err
+		}
+		payload.Revisions[i].Data = revisionData
+	}
+
+	project, err := h.projectService.CreateProject(c.Request().Context(), data.ProjectCreate{
+		Title:       payload.Title,
+		CreatorID:   user.ID,
+		Description: payload.Description,
+		Data:        flowData,
+		Revisions:   payload.Revisions,
+	})
+	if err != nil {
+		if he := quotaExceeded(err); he != nil {
+			return he
+		}
+		logging.Error(c.Request().Context(), "Internal project import error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to import project")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"project": project,
+	})
+}
+
+// importedFlow compacts and validates a flow of an import file.
+// Exports are indented for people to read, the flow limits apply to the flow itself.
+func (h *ImportHandler) importedFlow(field string, raw json.RawMessage) (json.RawMessage, error) {
+	var flowData bytes.Buffer
+	if err := json.Compact(&flowData, raw); err != nil {
+		return nil, echo.NewHTTPError(http.StatusUnprocessableEntity, "Invalid import file")
+	}
+
+	limits := flow.Limits{MaxNodes: h.limits.FlowNodes, MaxBytes: h.limits.FlowBytes}
+	if err := flow.Validate(field, flowData.Bytes(), limits); err != nil {
+		return nil, invalidFlow(err)
+	}
+
+	return flowData.Bytes(), nil
+}
+
+// Export handles the request to download a project of the current user with its revisions as an import file,
+// to back it up or move it to another instance. With a format such as javascript or logo, the program is
+// downloaded as source code in that language instead.
+func (h *ImportHandler) Export(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	// the import file by default, or the program translated into the language of the format
+	var target codegen.Target
+	if format := c.QueryParam("format"); format != "" && format != "json" {
+		var ok bool
+		if target, ok = codegen.Lookup(format); !ok {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format, expected json or one of %s", strings.Join(codegen.Formats(), ", ")))
+		}
+	}
+
+	// revisions are only shown to the owner, so only the owner exports them
+	isOwner, err := h.projectService.IsOwner(c.Request().Context(), projectID, contextUser.ID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal project ownership check error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to check project ownership")
+	}
+	if !isOwner {
+		return echo.NewHTTPError(http.StatusForbidden, "You do not have permission to export this project")
+	}
+
+	export, err := h.projectService.ExportProject(c.Request().Context(), projectID)
+	if err != nil {
+		if err == services.ErrRecordNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		logging.Error(c.Request().Context(), "Internal project export error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to export project")
+	}
+
+	if target != nil {
+		program, err := codegen.Parse(export.Data)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, "The project can't be translated, it has a cycle or is too large")
+		}
+		program.Title = export.Title
+
+		filename := fmt.Sprintf("project-%s.%s", projectID, target.Extension())
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+		return c.Blob(http.StatusOK, target.ContentType(), target.Generate(program))
+	}
+
+	filename := fmt.Sprintf("project-%s.json", projectID)
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+
+	return c.JSONPretty(http.StatusOK, export, "  ")
+}
+
+// uploadParams returns the current user and the upload ID from the path.
+func (h *ImportHandler) uploadParams(c echo.Context) (*data.User, uuid.UUID, error) {
+	contextUser, err := activatedUser(c)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+
+	uploadID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid upload ID")
+	}
+
+	return contextUser, uploadID, nil
+}
+
+// activatedUser returns the current user, who must be activated.
+func activatedUser(c echo.Context) (*data.User, error) {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	if !contextUser.IsActivated {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "Account is not activated")
+	}
+
+	return contextUser, nil
+}
+
+func uploadError(c echo.Context, err error) error {
+	if err == services.ErrRecordNotFound {
+		return echo.NewHTTPError(http.StatusNotFound, "Upload not found")
+	}
+	logging.Error(c.Request().Context(), "Internal upload error", err)
+	return echo.NewHTTPError(http.StatusInternalServerError, "Failed to process upload")
+}
@@ -0,0 +1,414 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestImportProject(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	user := &data.User{ID: uuid.New(), Username: "user", IsActivated: true}
+	project := &data.Project{ID: uuid.New(), Title: "Imported", CreatorID: user.ID}
+
+	tests := map[string]struct {
+		contextUser *data.User
+		body        string
+		setupMocks  func(m *mocks.MockProjectService)
+		wantCode    int
+		wantError   bool
+	}{
+		"Not authenticated": {
+			body:      `{}`,
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Invalid JSON": {
+			contextUser: user,
+			body:        `{"title":`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Missing title": {
+			contextUser: user,
+			body:        `{"data":{"nodes":[],"edges":[]}}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Invalid flow": {
+			contextUser: user,
+			body:        `{"title":"Imported","data":{"nodes":[{"id":"1","type":"spinNode","position":{"x":0,"y":0}}]}}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Indented export is imported compacted and private": {
+			contextUser: user,
+			body:        "{\n  \"title\": \"Imported\",\n  \"data\": {\n    \"nodes\": [],\n    \"edges\": []\n  }\n}",
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("CreateProject", mock.MatchedBy(func(p data.ProjectCreate) bool {
+					return p.Title == "Imported" && p.CreatorID == user.ID && !p.IsPublic && string(p.Data) == `{"nodes":[],"edges":[]}`
+				})).Return(project, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Unknown export format": {
+			contextUser: user,
+			body:        `{"format":"nodeturtle-project/9","title":"Imported","data":{"nodes":[],"edges":[]}}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Invalid revision flow": {
+			contextUser: user,
+			body:        `{"title":"Imported","data":{"nodes":[],"edges":[]},"revisions":[{"title":"Old","data":{"nodes":[{"id":"1","type":"spinNode","position":{"x":0,"y":0}}]},"created_at":"2025-01-01T00:00:00Z"}]}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Export is imported with its revisions": {
+			contextUser: user,
+			body:        `{"format":"nodeturtle-project/1","title":"Imported","data":{"nodes":[],"edges":[]},"revisions":[{"title":"Old","data":{ "nodes": [], "edges": [] },"created_at":"2025-01-01T00:00:00Z"}]}`,
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("CreateProject", mock.MatchedBy(func(p data.ProjectCreate) bool {
+					return len(p.Revisions) == 1 && p.Revisions[0].Title == "Old" && string(p.Revisions[0].Data) == `{"nodes":[],"edges":[]}`
+				})).Return(project, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockProjectService := mocks.MockProjectService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockProjectService)
+			}
+			handler := NewImportHandler(&mockProjectService, &mocks.MockImportService{}, config.LimitsConfig{}, config.ImportsConfig{})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/projects/import", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.Import(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+			mockProjectService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestExportProject(t *testing.T) {
+	e := echo.New()
+
+	user := &data.User{ID: uuid.New(), Username: "user", IsActivated: true}
+	projectID := uuid.New()
+	export := &data.ProjectImport{
+		Format:    data.ProjectArchiveFormat,
+		Title:     "Exported",
+		Data:      json.RawMessage(`{"nodes":[],"edges":[]}`),
+		Revisions: []data.ArchivedRevision{{Title: "Old", Data: json.RawMessage(`{"nodes":[],"edges":[]}`)}},
+	}
+	program := &data.ProjectImport{
+		Title: "Line",
+		Data:  json.RawMessage(`{"nodes":[{"id":"s","type":"startNode"},{"id":"m","type":"moveNode","data":{"distance":50}}],"edges":[{"source":"s","target":"m"}]}`),
+	}
+	cycle := &data.ProjectImport{
+		Title: "Cycle",
+		Data:  json.RawMessage(`{"nodes":[{"id":"s","type":"startNode"},{"id":"m","type":"moveNode"}],"edges":[{"source":"s","target":"m"},{"source":"m","target":"m"}]}`),
+	}
+
+	tests := map[string]struct {
+		projectID  string
+		format     string
+		setupMocks func(m *mocks.MockProjectService)
+		wantCode   int
+		wantError  bool
+		wantBody   string
+	}{
+		"Invalid project ID": {
+			projectID: "invalid",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Not the owner": {
+			projectID: projectID.String(),
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("IsOwner", projectID, user.ID).Return(false, nil)
+			},
+			wantCode:  http.StatusForbidden,
+			wantError: true,
+		},
+		"Successful export": {
+			projectID: projectID.String(),
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("IsOwner", projectID, user.ID).Return(true, nil)
+				m.On("ExportProject", projectID).Return(export, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"JavaScript export": {
+			projectID: projectID.String(),
+			format:    "javascript",
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("IsOwner", projectID, user.ID).Return(true, nil)
+				m.On("ExportProject", projectID).Return(program, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: "t.forward(50);",
+		},
+		"Logo export": {
+			projectID: projectID.String(),
+			format:    "logo",
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("IsOwner", projectID, user.ID).Return(true, nil)
+				m.On("ExportProject", projectID).Return(program, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: "forward 50",
+		},
+		"Unknown format": {
+			projectID: projectID.String(),
+			format:    "pascal",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Program with a cycle": {
+			projectID: projectID.String(),
+			format:    "logo",
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("IsOwner", projectID, user.ID).Return(true, nil)
+				m.On("ExportProject", projectID).Return(cycle, nil)
+			},
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockProjectService := mocks.MockProjectService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockProjectService)
+			}
+			handler := NewImportHandler(&mockProjectService, &mocks.MockImportService{}, config.LimitsConfig{}, config.ImportsConfig{})
+
+			req := httptest.NewRequest(http.MethodGet, "/?format="+tt.format, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.projectID)
+			c.Set("user", user)
+
+			err := handler.Export(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), "attachment")
+				if tt.wantBody != "" {
+					assert.Contains(t, rec.Body.String(), tt.wantBody)
+				} else {
+					var file data.ProjectImport
+					assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &file))
+					assert.Equal(t, data.ProjectArchiveFormat, file.Format)
+					assert.Len(t, file.Revisions, 1)
+				}
+			}
+			mockProjectService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProjectUploads(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	user := &data.User{ID: uuid.New(), Username: "user", IsActivated: true}
+	uploadID := uuid.New()
+	file := `{"title":"Imported","data":{"nodes":[],"edges":[]}}`
+	size := int64(len(file))
+	upload := func(offset int64) *data.ProjectUpload {
+		return &data.ProjectUpload{ID: uploadID, UserID: user.ID, Size: size, Offset: offset, ExpiresAt: time.Now().Add(time.Hour)}
+	}
+	cfg := config.ImportsConfig{MaxBytes: 1000, ChunkBytes: 20}
+
+	tests := map[string]struct {
+		method      string
+		path        string
+		contentType string
+		offset      string
+		body        string
+		setupMocks  func(m *mocks.MockImportService, p *mocks.MockProjectService)
+		handler     func(h *ImportHandler) echo.HandlerFunc
+		wantCode    int
+		wantOffset  string
+		wantError   bool
+	}{
+		"Start upload": {
+			body: `{"size":52}`,
+			setupMocks: func(m *mocks.MockImportService, p *mocks.MockProjectService) {
+				m.On("CreateUpload", user.ID, int64(52)).Return(upload(0), nil)
+			},
+			handler:  func(h *ImportHandler) echo.HandlerFunc { return h.CreateUpload },
+			wantCode: http.StatusCreated,
+		},
+		"Start upload too large": {
+			body:      `{"size":1001}`,
+			handler:   func(h *ImportHandler) echo.HandlerFunc { return h.CreateUpload },
+			wantCode:  http.StatusRequestEntityTooLarge,
+			wantError: true,
+		},
+		"Resume upload": {
+			setupMocks: func(m *mocks.MockImportService, p *mocks.MockProjectService) {
+				m.On("GetUpload", uploadID, user.ID).Return(upload(20), nil)
+			},
+			handler:  func(h *ImportHandler) echo.HandlerFunc { return h.GetUpload },
+			wantCode: http.StatusOK,
+		},
+		"Unknown upload": {
+			setupMocks: func(m *mocks.MockImportService, p *mocks.MockProjectService) {
+				m.On("GetUpload", uploadID, user.ID).Return(nil, services.ErrRecordNotFound)
+			},
+			handler:   func(h *ImportHandler) echo.HandlerFunc { return h.GetUpload },
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Append chunk": {
+			contentType: chunkContentType,
+			offset:      "20",
+			body:        file[20:40],
+			setupMocks: func(m *mocks.MockImportService, p *mocks.MockProjectService) {
+				m.On("AppendChunk", uploadID, user.ID, int64(20), []byte(file[20:40])).Return(upload(40), nil)
+			},
+			handler:    func(h *ImportHandler) echo.HandlerFunc { return h.AppendChunk },
+			wantCode:   http.StatusOK,
+			wantOffset: "40",
+		},
+		"Append chunk at wrong offset": {
+			contentType: chunkContentType,
+			offset:      "0",
+			body:        file[:20],
+			setupMocks: func(m *mocks.MockImportService, p *mocks.MockProjectService) {
+				m.On("AppendChunk", uploadID, user.ID, int64(0), []byte(file[:20])).Return(upload(20), services.ErrUploadOffset)
+			},
+			handler:   func(h *ImportHandler) echo.HandlerFunc { return h.AppendChunk },
+			wantCode:  http.StatusConflict,
+			wantError: true,
+		},
+		"Append chunk too large": {
+			contentType: chunkContentType,
+			offset:      "0",
+			body:        file[:21],
+			handler:     func(h *ImportHandler) echo.HandlerFunc { return h.AppendChunk },
+			wantCode:    http.StatusRequestEntityTooLarge,
+			wantError:   true,
+		},
+		"Append chunk without offset": {
+			contentType: chunkContentType,
+			body:        file[:20],
+			handler:     func(h *ImportHandler) echo.HandlerFunc { return h.AppendChunk },
+			wantCode:    http.StatusBadRequest,
+			wantError:   true,
+		},
+		"Append chunk as JSON": {
+			offset:    "0",
+			body:      file[:20],
+			handler:   func(h *ImportHandler) echo.HandlerFunc { return h.AppendChunk },
+			wantCode:  http.StatusUnsupportedMediaType,
+			wantError: true,
+		},
+		"Complete incomplete upload": {
+			setupMocks: func(m *mocks.MockImportService, p *mocks.MockProjectService) {
+				m.On("ReadUpload", uploadID, user.ID).Return(nil, services.ErrUploadIncomplete)
+			},
+			handler:   func(h *ImportHandler) echo.HandlerFunc { return h.CompleteUpload },
+			wantCode:  http.StatusConflict,
+			wantError: true,
+		},
+		"Complete upload": {
+			setupMocks: func(m *mocks.MockImportService, p *mocks.MockProjectService) {
+				m.On("ReadUpload", uploadID, user.ID).Return([]byte(file), nil)
+				m.On("DeleteUpload", uploadID, user.ID).Return(nil)
+				p.On("CreateProject", mock.AnythingOfType("data.ProjectCreate")).Return(&data.Project{ID: uuid.New()}, nil)
+			},
+			handler:  func(h *ImportHandler) echo.HandlerFunc { return h.CompleteUpload },
+			wantCode: http.StatusOK,
+		},
+		"Cancel upload": {
+			setupMocks: func(m *mocks.MockImportService, p *mocks.MockProjectService) {
+				m.On("DeleteUpload", uploadID, user.ID).Return(nil)
+			},
+			handler:  func(h *ImportHandler) echo.HandlerFunc { return h.CancelUpload },
+			wantCode: http.StatusNoContent,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockImportService := mocks.MockImportService{}
+			mockProjectService := mocks.MockProjectService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockImportService, &mockProjectService)
+			}
+			handler := NewImportHandler(&mockProjectService, &mockImportService, config.LimitsConfig{}, cfg)
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			contentType := tt.contentType
+			if contentType == "" {
+				contentType = echo.MIMEApplicationJSON
+			}
+			req.Header.Set(echo.HeaderContentType, contentType)
+			if tt.offset != "" {
+				req.Header.Set(uploadOffsetHeader, tt.offset)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(uploadID.String())
+			c.Set("user", user)
+
+			err := tt.handler(&handler)(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Equal(t, tt.wantOffset, rec.Header().Get(uploadOffsetHeader))
+			}
+			mockImportService.AssertExpectations(t)
+			mockProjectService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services/projects"
+
+	"github.com/labstack/echo/v4"
+)
+
+// IntegrityHandler handles HTTP requests to check stored project data for corruption.
+type IntegrityHandler struct {
+	projectService projects.IProjectService
+	cfg            config.IntegrityConfig
+}
+
+// NewIntegrityHandler creates a new IntegrityHandler with the provided service and pacing.
+func NewIntegrityHandler(projectService projects.IProjectService, cfg config.IntegrityConfig) IntegrityHandler {
+	return IntegrityHandler{
+		projectService: projectService,
+		cfg:            cfg,
+	}
+}
+
+// Scan handles the request to compare the data of every project with its checksum instead of waiting for the scheduled scan,
+// and lists the projects whose data doesn't match.
+func (h *IntegrityHandler) Scan(c echo.Context) error {
+	report, err := h.projectService.ScanDataIntegrity(c.Request().Context(), data.IntegrityScanOptions{
+		BatchSize: h.cfg.BatchSize,
+		Pause:     h.cfg.Pause,
+	})
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal project integrity scan error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to scan project data")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"report": report,
+	})
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanDataIntegrity(t *testing.T) {
+	e := echo.New()
+
+	cfg := config.IntegrityConfig{BatchSize: 100, Pause: time.Millisecond}
+	opts := data.IntegrityScanOptions{BatchSize: 100, Pause: time.Millisecond}
+
+	tests := map[string]struct {
+		setupMocks func(m *mocks.MockProjectService)
+		wantCode   int
+		wantError  bool
+	}{
+		"Corrupted projects": {
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("ScanDataIntegrity", opts).Return(&data.IntegrityReport{
+					Scanned:   10,
+					Corrupted: []data.CorruptedProject{{ID: uuid.New(), Title: "broken", StoredChecksum: "abc", DataChecksum: "def"}},
+				}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Database error": {
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("ScanDataIntegrity", opts).Return(nil, errors.New("database error"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockProjectService := mocks.MockProjectService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockProjectService)
+			}
+			handler := NewIntegrityHandler(&mockProjectService, cfg)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.Scan(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), "broken")
+			}
+			mockProjectService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/jobs"
+
+	"github.com/labstack/echo/v4"
+)
+
+// JobHandler handles HTTP requests related to background jobs.
+type JobHandler struct {
+	jobService jobs.IJobService
+}
+
+// NewJobHandler creates a new JobHandler with the provided job service.
+func NewJobHandler(jobService jobs.IJobService) JobHandler {
+	return JobHandler{
+		jobService: jobService,
+	}
+}
+
+// List handles the request to list background jobs and their progress.
+func (h *JobHandler) List(c echo.Context) error {
+	jobs, err := h.jobService.GetJobs()
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal job retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve jobs")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"jobs": jobs,
+	})
+}
+
+// Get handles the request to retrieve the progress of a single background job.
+func (h *JobHandler) Get(c echo.Context) error {
+	job, err := h.jobService.GetJob(c.Param("name"))
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Job not found")
+		}
+		logging.Error(c.Request().Context(), "Internal job retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve job")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"job": job,
+	})
+}
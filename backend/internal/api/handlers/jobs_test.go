@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetJob(t *testing.T) {
+	e := echo.New()
+
+	mockJobService := mocks.MockJobService{}
+
+	total := int64(5000)
+	mockJobService.On("GetJob", "projects_backfill").Return(&data.Job{
+		Name:      "projects_backfill",
+		Kind:      data.JobKindBackfill,
+		Status:    data.JobRunning,
+		Total:     &total,
+		Processed: 1000,
+		StartedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}, nil)
+	mockJobService.On("GetJob", "unknown").Return(nil, services.ErrRecordNotFound)
+	mockJobService.On("GetJob", "broken").Return(nil, errors.New("database error"))
+
+	handler := NewJobHandler(&mockJobService)
+
+	tests := map[string]struct {
+		name      string
+		wantCode  int
+		wantError bool
+	}{
+		"Running job": {
+			name:     "projects_backfill",
+			wantCode: http.StatusOK,
+		},
+		"Unknown job": {
+			name:      "unknown",
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Database error": {
+			name:      "broken",
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("name")
+			c.SetParamValues(tt.name)
+
+			err := handler.Get(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"processed":1000`)
+				assert.Contains(t, rec.Body.String(), `"total":5000`)
+			}
+		})
+	}
+}
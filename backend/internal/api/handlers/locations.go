@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"NodeTurtleAPI/internal/api/middleware"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// loginCountry returns the ISO country code of the client as reported by the proxy,
+// or an empty string when login verification is disabled or the country is unknown.
+func (h *AuthHandler) loginCountry(c echo.Context) string {
+	if h.loginConfig.CountryHeader == "" {
+		return ""
+	}
+
+	country := strings.ToUpper(strings.TrimSpace(c.Request().Header.Get(h.loginConfig.CountryHeader)))
+	// Cloudflare reports XX for unknown and T1 for Tor exit nodes
+	if len(country) != 2 || country == "XX" || country == "T1" {
+		return ""
+	}
+	return country
+}
+
+// requireLoginVerification emails a one-time code confirming a login from a new country
+// and responds with the challenge the code has to be submitted for.
+func (h *AuthHandler) requireLoginVerification(c echo.Context, user *data.User, country string) error {
+	challenge, err := h.locationService.CreateChallenge(user.ID, country)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal login challenge creation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to login")
+	}
+
+	emailData := map[string]string{
+		"Username":  user.Username,
+		"Code":      challenge.Code,
+		"Country":   country,
+		"ExpiresAt": formatExpiry(challenge.ExpiresAt),
+	}
+	if err := h.mailService.QueueEmail(c.Request().Context(), user.Email, "Confirm your login", "login_code", emailData); err != nil {
+		logging.Error(c.Request().Context(), "Failed to queue login code email", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to login")
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"verification_required": true,
+		"challenge":             challenge,
+		"message":               "This login is from a new location. Enter the code we sent to your email to continue.",
+	})
+}
+
+// VerifyLogin handles the confirmation of a login from a new country with the emailed code.
+// On success the country becomes a trusted location and the user is signed in like with a regular login.
+func (h *AuthHandler) VerifyLogin(c echo.Context) error {
+	var payload struct {
+		ChallengeID uuid.UUID `json:"challenge_id" validate:"required"`
+		Code        string    `json:"code" validate:"required,len=6,numeric"`
+	}
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	challenge, err := h.locationService.VerifyChallenge(payload.ChallengeID, payload.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidToken):
+			return echo.NewHTTPError(http.StatusUnauthorized, "Invalid or expired verification code")
+		case errors.Is(err, services.ErrTooManyAttempts):
+			// the challenge is discarded, so there is nothing to wait for
+			return middleware.TooManyRequests(c, middleware.CodeTooManyAttempts, "Too many attempts, please login again", 0)
+		}
+		logging.Error(c.Request().Context(), "Internal login challenge verification error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to login")
+	}
+
+	user, err := h.userService.GetUserByID(c.Request().Context(), challenge.UserID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal user retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to login")
+	}
+
+	if user.Ban.IsValid() {
+		return echo.NewHTTPError(http.StatusForbidden, services.BanMessage(user.Ban.Reason, user.Ban.ExpiresAt))
+	}
+
+	if err := h.locationService.Trust(user.ID, challenge.Country); err != nil {
+		logging.Error(c.Request().Context(), "Internal trusted location update error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to login")
+	}
+
+	token, err := h.authService.CreateAccessToken(*user)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal access token creation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create access token")
+	}
+
+	return startSession(c, h.tokenService, token, user)
+}
+
+// GetTrustedLocations handles the request to list the countries the user confirmed logging in from.
+func (h *AuthHandler) GetTrustedLocations(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	locations, err := h.locationService.ListTrusted(contextUser.ID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal trusted location retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve trusted locations")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"locations": locations,
+	})
+}
+
+// RemoveTrustedLocation handles the request to stop trusting a country, the next login from it has to be confirmed again.
+func (h *AuthHandler) RemoveTrustedLocation(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	country := strings.ToUpper(c.Param("country"))
+	if len(country) != 2 {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid country code")
+	}
+
+	if err := h.locationService.RemoveTrusted(contextUser.ID, country); err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Location not found")
+		}
+		logging.Error(c.Request().Context(), "Internal trusted location removal error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to remove trusted location")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
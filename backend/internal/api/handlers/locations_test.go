@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLoginFromNewCountry(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	mockAuthService := mocks.MockAuthService{}
+	mockTokenService := mocks.MockTokenService{}
+	mockMailService := mocks.MockMailService{}
+	mockLocationService := mocks.MockLocationService{}
+
+	user := &data.User{ID: uuid.New(), Email: "test@test.test", Username: "testuser", IsActivated: true}
+	challenge := &data.LoginChallenge{ID: uuid.New(), UserID: user.ID, Country: "FR", Code: "123456", ExpiresAt: time.Now().Add(15 * time.Minute)}
+
+	mockAuthService.On("Login", "test@test.test", "TestPassword123").Return("mocktoken", user, nil)
+	mockLocationService.On("IsTrusted", user.ID, "SI").Return(true, nil)
+	mockLocationService.On("IsTrusted", user.ID, "FR").Return(false, nil)
+	mockLocationService.On("Trust", user.ID, "SI").Return(nil)
+	mockLocationService.On("CreateChallenge", user.ID, "FR").Return(challenge, nil)
+	mockMailService.On("QueueEmail", user.Email, mock.Anything, "login_code", mock.Anything).Return(nil)
+	mockTokenService.On("DeleteAllForUser", data.ScopeRefresh, user.ID).Return(nil)
+	mockTokenService.On("New", user.ID, data.ScopeRefresh).Return(&data.Token{Plaintext: "refresh", Scope: data.ScopeRefresh}, nil)
+
+	handler := NewAuthHandler(&mockAuthService, &mocks.MockOAuthService{}, &mocks.MockUserService{}, &mockTokenService, &mockMailService, &mockLocationService, "", config.LoginVerificationConfig{CountryHeader: "CF-IPCountry"}, &mocks.MockPasswordService{}, auditRecorder())
+
+	tests := map[string]struct {
+		country  string
+		wantCode int
+		wantBody string
+	}{
+		"Unknown country": {
+			country:  "XX",
+			wantCode: http.StatusOK,
+			wantBody: "mocktoken",
+		},
+		"Trusted country": {
+			country:  "si",
+			wantCode: http.StatusOK,
+			wantBody: "mocktoken",
+		},
+		"New country": {
+			country:  "FR",
+			wantCode: http.StatusAccepted,
+			wantBody: challenge.ID.String(),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/auth/session", strings.NewReader(`{"email":"test@test.test","password":"TestPassword123"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set("CF-IPCountry", tt.country)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.Login(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.wantBody)
+			if tt.wantCode == http.StatusAccepted {
+				assert.NotContains(t, rec.Body.String(), "mocktoken")
+				assert.NotContains(t, rec.Body.String(), challenge.Code)
+			}
+		})
+	}
+
+	mockLocationService.AssertExpectations(t)
+}
+
+func TestVerifyLogin(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	mockAuthService := mocks.MockAuthService{}
+	mockUserService := mocks.MockUserService{}
+	mockTokenService := mocks.MockTokenService{}
+	mockLocationService := mocks.MockLocationService{}
+
+	user := &data.User{ID: uuid.New(), Email: "test@test.test", Username: "testuser", IsActivated: true}
+	challengeID := uuid.New()
+	expiredID := uuid.New()
+	exhaustedID := uuid.New()
+
+	mockLocationService.On("VerifyChallenge", challengeID, "123456").Return(&data.LoginChallenge{ID: challengeID, UserID: user.ID, Country: "FR"}, nil)
+	mockLocationService.On("VerifyChallenge", expiredID, "123456").Return(nil, services.ErrInvalidToken)
+	mockLocationService.On("VerifyChallenge", exhaustedID, "123456").Return(nil, services.ErrTooManyAttempts)
+	mockLocationService.On("Trust", user.ID, "FR").Return(nil)
+	mockUserService.On("GetUserByID", user.ID).Return(user, nil)
+	mockAuthService.On("CreateAccessToken", *user).Return("access-token", nil)
+	mockTokenService.On("DeleteAllForUser", data.ScopeRefresh, user.ID).Return(nil)
+	mockTokenService.On("New", user.ID, data.ScopeRefresh).Return(&data.Token{Plaintext: "refresh", Scope: data.ScopeRefresh}, nil)
+
+	handler := NewAuthHandler(&mockAuthService, &mocks.MockOAuthService{}, &mockUserService, &mockTokenService, &mocks.MockMailService{}, &mockLocationService, "", config.LoginVerificationConfig{CountryHeader: "CF-IPCountry"}, &mocks.MockPasswordService{}, auditRecorder())
+
+	tests := map[string]struct {
+		reqBody   string
+		wantCode  int
+		wantError bool
+	}{
+		"Invalid code format": {
+			reqBody:   `{"challenge_id":"` + challengeID.String() + `","code":"12ab"}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Invalid or expired code": {
+			reqBody:   `{"challenge_id":"` + expiredID.String() + `","code":"123456"}`,
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Too many attempts": {
+			reqBody:   `{"challenge_id":"` + exhaustedID.String() + `","code":"123456"}`,
+			wantCode:  http.StatusTooManyRequests,
+			wantError: true,
+		},
+		"Successful verification": {
+			reqBody:  `{"challenge_id":"` + challengeID.String() + `","code":"123456"}`,
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/auth/session/verify", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.VerifyLogin(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), "access-token")
+			}
+		})
+	}
+
+	mockLocationService.AssertExpectations(t)
+}
+
+func TestRemoveTrustedLocation(t *testing.T) {
+	e := echo.New()
+
+	mockLocationService := mocks.MockLocationService{}
+	handler := NewAuthHandler(&mocks.MockAuthService{}, &mocks.MockOAuthService{}, &mocks.MockUserService{}, &mocks.MockTokenService{}, &mocks.MockMailService{}, &mockLocationService, "", config.LoginVerificationConfig{}, &mocks.MockPasswordService{}, auditRecorder())
+
+	user := &data.User{ID: uuid.New(), Username: "testuser", IsActivated: true}
+
+	mockLocationService.On("RemoveTrusted", user.ID, "FR").Return(nil)
+	mockLocationService.On("RemoveTrusted", user.ID, "DE").Return(services.ErrRecordNotFound)
+
+	tests := map[string]struct {
+		user      *data.User
+		country   string
+		wantCode  int
+		wantError bool
+	}{
+		"Unauthenticated": {
+			country:   "FR",
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Invalid country": {
+			user:      user,
+			country:   "France",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Not trusted": {
+			user:      user,
+			country:   "de",
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Removed": {
+			user:     user,
+			country:  "fr",
+			wantCode: http.StatusNoContent,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("country")
+			c.SetParamValues(tt.country)
+			if tt.user != nil {
+				c.Set("user", tt.user)
+			}
+
+			err := handler.RemoveTrustedLocation(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
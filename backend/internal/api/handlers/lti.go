@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/auth"
+	"NodeTurtleAPI/internal/services/lti"
+	"NodeTurtleAPI/internal/services/tokens"
+	"NodeTurtleAPI/internal/services/users"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// LTIHandler handles HTTP requests of the LTI 1.3 tool provider.
+type LTIHandler struct {
+	ltiService   lti.ILTIService
+	authService  auth.IAuthService
+	userService  users.IUserService
+	tokenService tokens.ITokenService
+	clientURL    string
+}
+
+// NewLTIHandler creates a new LTIHandler with the provided services.
+// clientURL is the frontend URL users are redirected to after a launch.
+func NewLTIHandler(ltiService lti.ILTIService, authService auth.IAuthService, userService users.IUserService, tokenService tokens.ITokenService, clientURL string) LTIHandler {
+	return LTIHandler{
+		ltiService:   ltiService,
+		authService:  authService,
+		userService:  userService,
+		tokenService: tokenService,
+		clientURL:    strings.TrimRight(clientURL, "/"),
+	}
+}
+
+// Login handles the OIDC third-party initiated login sent by the platform
+// and redirects the user agent to the platform authorization endpoint.
+func (h *LTIHandler) Login(c echo.Context) error {
+	var payload data.LTILoginRequest
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	redirectURL, err := h.ltiService.InitiateLogin(payload)
+	if err != nil {
+		if errors.Is(err, services.ErrUnknownPlatform) {
+			return echo.NewHTTPError(http.StatusBadRequest, "Unknown LTI platform")
+		}
+		logging.Error(c.Request().Context(), "Internal LTI login error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to initiate LTI login")
+	}
+
+	return c.Redirect(http.StatusFound, redirectURL)
+}
+
+// Launch handles the id_token posted by the platform after authorization.
+// It signs the user in with the same JWT + refresh token pair as the password login
+// and redirects to the linked project or to the deep linking project picker.
+// An LMS user with the email of an unlinked account is not signed in but sent to confirm the link with that account.
+func (h *LTIHandler) Launch(c echo.Context) error {
+	idToken := c.FormValue("id_token")
+	state := c.FormValue("state")
+	if idToken == "" || state == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Missing id_token or state")
+	}
+
+	result, err := h.ltiService.HandleLaunch(idToken, state)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidToken), errors.Is(err, services.ErrUnknownPlatform):
+			return echo.NewHTTPError(http.StatusUnauthorized, "Invalid LTI launch")
+		case errors.Is(err, services.ErrUnsupportedMessage):
+			return echo.NewHTTPError(http.StatusBadRequest, "Unsupported LTI message type")
+		case errors.Is(err, services.ErrInactiveAccount):
+			return echo.NewHTTPError(http.StatusForbidden, "INACTIVE_ACCOUNT")
+		case errors.Is(err, services.ErrPrivilegedAccount):
+			return echo.NewHTTPError(http.StatusForbidden, "Staff accounts can't sign in through an LMS")
+		}
+		logging.Error(c.Request().Context(), "Internal LTI launch error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to process LTI launch")
+	}
+
+	if result.LinkRequestID != nil {
+		return c.Redirect(http.StatusFound, fmt.Sprintf("%s/lti/link/%s", h.clientURL, result.LinkRequestID))
+	}
+
+	user, err := h.userService.GetUserByID(c.Request().Context(), result.UserID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal user retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to process LTI launch")
+	}
+
+	if user.Ban.IsValid() {
+		return echo.NewHTTPError(http.StatusForbidden, services.BanMessage(user.Ban.Reason, user.Ban.ExpiresAt))
+	}
+
+	if err := h.authService.UpdateLastLogin(c.Request().Context(), user.ID); err != nil {
+		logging.Error(c.Request().Context(), "Internal last login update error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to process LTI launch")
+	}
+
+	token, err := h.authService.CreateAccessToken(*user)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal access token creation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create access token")
+	}
+
+	if err := h.tokenService.DeleteAllForUser(c.Request().Context(), data.ScopeRefresh, user.ID); err != nil {
+		logging.Error(c.Request().Context(), "Internal refresh token deletion error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete old refresh tokens")
+	}
+
+	refreshToken, err := h.tokenService.New(c.Request().Context(), user.ID, data.ScopeRefresh)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal refresh token creation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create new refresh token")
+	}
+
+	setTokenCookies(c, token, refreshToken.Plaintext)
+
+	var route string
+	switch {
+	case result.DeepLinkID != nil:
+		route = fmt.Sprintf("/lti/deep-link/%s", result.DeepLinkID)
+	case result.ProjectID != nil:
+		route = fmt.Sprintf("/projects/%s?lti_launch=%s", result.ProjectID, result.LaunchID)
+	default:
+		route = fmt.Sprintf("/projects?lti_launch=%s", result.LaunchID)
+	}
+
+	return c.Redirect(http.StatusFound, h.clientURL+route)
+}
+
+// JWKS handles the request for the public key set used to verify messages signed by the tool.
+func (h *LTIHandler) JWKS(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.ltiService.JWKS())
+}
+
+// DeepLink handles the instructor's project selection for a pending deep linking request.
+// It returns the signed response which the client has to form-post to the platform return URL.
+func (h *LTIHandler) DeepLink(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	deepLinkID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid deep link ID")
+	}
+
+	var payload struct {
+		ProjectIDs []uuid.UUID `json:"project_ids" validate:"required,min=1,max=20"`
+	}
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	response, err := h.ltiService.CreateDeepLinkResponse(deepLinkID, contextUser.ID, payload.ProjectIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRecordNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, "Deep link request not found or expired")
+		case errors.Is(err, services.ErrProjectNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, "No linkable projects found")
+		}
+		logging.Error(c.Request().Context(), "Internal LTI deep link error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create deep link response")
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// Submit handles the request to hand in the assignment behind an LTI launch,
+// passing the submission back to the platform gradebook.
+func (h *LTIHandler) Submit(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	launchID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid launch ID")
+	}
+
+	launch, err := h.ltiService.SubmitAssignment(launchID, contextUser.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRecordNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, "Launch not found")
+		case errors.Is(err, services.ErrGradingUnavailable):
+			return echo.NewHTTPError(http.StatusConflict, "Grade passback is not available for this assignment")
+		}
+		logging.Error(c.Request().Context(), "Internal LTI submission error", err)
+		return echo.NewHTTPError(http.StatusBadGateway, "Failed to submit assignment to the LMS")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"launch": launch,
+	})
+}
+
+// ConfirmLink handles the signed-in user's confirmation of a link request made by a launch with their email,
+// linking the LMS user to their account.
+func (h *LTIHandler) ConfirmLink(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	requestID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid link request ID")
+	}
+
+	if err := h.ltiService.ConfirmLink(requestID, contextUser.ID); err != nil {
+		switch {
+		case errors.Is(err, services.ErrRecordNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, "Link request not found or expired")
+		case errors.Is(err, services.ErrPrivilegedAccount):
+			return echo.NewHTTPError(http.StatusForbidden, "Staff accounts can't be linked to an LMS")
+		}
+		logging.Error(c.Request().Context(), "Internal LTI link confirmation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to link LMS account")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "LMS account linked. Launch the assignment again to continue.",
+	})
+}
+
+// RegisterPlatform handles the request to register a new LTI platform.
+func (h *LTIHandler) RegisterPlatform(c echo.Context) error {
+	var payload data.LTIPlatformCreate
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	platform, err := h.ltiService.RegisterPlatform(payload)
+	if err != nil {
+		if errors.Is(err, services.ErrPlatformExists) {
+			return echo.NewHTTPError(http.StatusConflict, "Platform is already registered")
+		}
+		logging.Error(c.Request().Context(), "Internal LTI platform registration error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to register platform")
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"platform": platform,
+	})
+}
+
+// ListPlatforms handles the request to retrieve all registered LTI platforms.
+func (h *LTIHandler) ListPlatforms(c echo.Context) error {
+	platforms, err := h.ltiService.ListPlatforms()
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal LTI platform retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve platforms")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"platforms": platforms,
+	})
+}
@@ -0,0 +1,537 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/utils"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLTILogin(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	mockLTIService := mocks.MockLTIService{}
+	handler := NewLTIHandler(&mockLTIService, &mocks.MockAuthService{}, &mocks.MockUserService{}, &mocks.MockTokenService{}, "http://client.test")
+
+	mockLTIService.On("InitiateLogin", mock.MatchedBy(func(req data.LTILoginRequest) bool {
+		return req.Issuer == "https://unknown.test"
+	})).Return("", services.ErrUnknownPlatform)
+	mockLTIService.On("InitiateLogin", mock.MatchedBy(func(req data.LTILoginRequest) bool {
+		return req.Issuer == "https://canvas.test"
+	})).Return("https://canvas.test/auth?state=abc", nil)
+
+	tests := map[string]struct {
+		query        string
+		wantCode     int
+		wantError    bool
+		wantLocation string
+	}{
+		"Missing parameters": {
+			query:     "iss=https://canvas.test",
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Unknown platform": {
+			query:     "iss=https://unknown.test&login_hint=1&target_link_uri=https://tool.test",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Successful login initiation": {
+			query:        "iss=https://canvas.test&login_hint=1&target_link_uri=https://tool.test",
+			wantCode:     http.StatusFound,
+			wantError:    false,
+			wantLocation: "https://canvas.test/auth?state=abc",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.Login(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Equal(t, tt.wantLocation, rec.Header().Get(echo.HeaderLocation))
+			}
+		})
+	}
+}
+
+func TestLTILaunch(t *testing.T) {
+	e := echo.New()
+
+	mockLTIService := mocks.MockLTIService{}
+	mockAuthService := mocks.MockAuthService{}
+	mockUserService := mocks.MockUserService{}
+	mockTokenService := mocks.MockTokenService{}
+
+	handler := NewLTIHandler(&mockLTIService, &mockAuthService, &mockUserService, &mockTokenService, "http://client.test/")
+
+	student := &data.User{ID: uuid.New(), Username: "student1234", IsActivated: true}
+	banned := &data.User{ID: uuid.New(), Username: "banned1234", IsActivated: true, Ban: &data.Ban{ExpiresAt: utils.Ptr(time.Now().Add(time.Hour))}}
+
+	projectID := uuid.New()
+	launchID := uuid.New()
+	deepLinkID := uuid.New()
+	linkRequestID := uuid.New()
+
+	mockLTIService.On("HandleLaunch", "invalid", "state").Return(nil, services.ErrInvalidToken)
+	mockLTIService.On("HandleLaunch", "unsupported", "state").Return(nil, services.ErrUnsupportedMessage)
+	mockLTIService.On("HandleLaunch", "deactivated", "state").Return(nil, services.ErrInactiveAccount)
+	mockLTIService.On("HandleLaunch", "staff", "state").Return(nil, services.ErrPrivilegedAccount)
+	mockLTIService.On("HandleLaunch", "existing", "state").Return(&data.LTILaunchResult{
+		MessageType:   data.LTIMessageResourceLink,
+		LinkRequestID: &linkRequestID,
+	}, nil)
+	mockLTIService.On("HandleLaunch", "banned", "state").Return(&data.LTILaunchResult{
+		MessageType: data.LTIMessageResourceLink,
+		UserID:      banned.ID,
+		LaunchID:    &launchID,
+	}, nil)
+	mockLTIService.On("HandleLaunch", "resource", "state").Return(&data.LTILaunchResult{
+		MessageType: data.LTIMessageResourceLink,
+		UserID:      student.ID,
+		LaunchID:    &launchID,
+		ProjectID:   &projectID,
+	}, nil)
+	mockLTIService.On("HandleLaunch", "deeplink", "state").Return(&data.LTILaunchResult{
+		MessageType: data.LTIMessageDeepLinking,
+		UserID:      student.ID,
+		DeepLinkID:  &deepLinkID,
+	}, nil)
+
+	mockUserService.On("GetUserByID", student.ID).Return(student, nil)
+	mockUserService.On("GetUserByID", banned.ID).Return(banned, nil)
+	mockAuthService.On("UpdateLastLogin", student.ID).Return(nil)
+	mockAuthService.On("CreateAccessToken", *student).Return("access-token", nil)
+	mockTokenService.On("DeleteAllForUser", data.ScopeRefresh, student.ID).Return(nil)
+	mockTokenService.On("New", student.ID, data.ScopeRefresh).Return(&data.Token{Plaintext: "refresh-token", Scope: data.ScopeRefresh}, nil)
+
+	tests := map[string]struct {
+		idToken      string
+		state        string
+		wantCode     int
+		wantError    bool
+		wantLocation string
+		wantCookies  int
+	}{
+		"Missing state": {
+			idToken:   "resource",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Invalid token": {
+			idToken:   "invalid",
+			state:     "state",
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Unsupported message type": {
+			idToken:   "unsupported",
+			state:     "state",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Banned user": {
+			idToken:   "banned",
+			state:     "state",
+			wantCode:  http.StatusForbidden,
+			wantError: true,
+		},
+		"Deactivated account": {
+			idToken:   "deactivated",
+			state:     "state",
+			wantCode:  http.StatusForbidden,
+			wantError: true,
+		},
+		"Staff account": {
+			idToken:   "staff",
+			state:     "state",
+			wantCode:  http.StatusForbidden,
+			wantError: true,
+		},
+		"Existing account has to confirm the link": {
+			idToken:      "existing",
+			state:        "state",
+			wantCode:     http.StatusFound,
+			wantLocation: "http://client.test/lti/link/" + linkRequestID.String(),
+		},
+		"Resource link launch": {
+			idToken:      "resource",
+			state:        "state",
+			wantCode:     http.StatusFound,
+			wantLocation: "http://client.test/projects/" + projectID.String() + "?lti_launch=" + launchID.String(),
+			wantCookies:  2,
+		},
+		"Deep linking launch": {
+			idToken:      "deeplink",
+			state:        "state",
+			wantCode:     http.StatusFound,
+			wantLocation: "http://client.test/lti/deep-link/" + deepLinkID.String(),
+			wantCookies:  2,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			form := url.Values{}
+			form.Set("id_token", tt.idToken)
+			form.Set("state", tt.state)
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.Launch(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Equal(t, tt.wantLocation, rec.Header().Get(echo.HeaderLocation))
+
+				cookies := rec.Result().Cookies()
+				assert.Len(t, cookies, tt.wantCookies)
+			}
+		})
+	}
+}
+
+func TestLTIDeepLink(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	mockLTIService := mocks.MockLTIService{}
+	handler := NewLTIHandler(&mockLTIService, &mocks.MockAuthService{}, &mocks.MockUserService{}, &mocks.MockTokenService{}, "http://client.test")
+
+	instructor := &data.User{ID: uuid.New(), Username: "teacher", IsActivated: true}
+	projectID := uuid.New()
+	deepLinkID := uuid.New()
+	expiredID := uuid.New()
+
+	mockLTIService.On("CreateDeepLinkResponse", expiredID, instructor.ID, mock.Anything).Return(nil, services.ErrRecordNotFound)
+	mockLTIService.On("CreateDeepLinkResponse", deepLinkID, instructor.ID, []uuid.UUID{projectID}).Return(&data.LTIDeepLinkResponse{
+		ReturnURL: "https://canvas.test/deep_link_return",
+		JWT:       "signed",
+	}, nil)
+
+	tests := map[string]struct {
+		contextUser *data.User
+		deepLinkID  string
+		reqBody     string
+		wantCode    int
+		wantError   bool
+	}{
+		"User not authenticated": {
+			deepLinkID: deepLinkID.String(),
+			reqBody:    `{"project_ids":["` + projectID.String() + `"]}`,
+			wantCode:   http.StatusUnauthorized,
+			wantError:  true,
+		},
+		"Invalid deep link ID": {
+			contextUser: instructor,
+			deepLinkID:  "invalid",
+			reqBody:     `{"project_ids":["` + projectID.String() + `"]}`,
+			wantCode:    http.StatusBadRequest,
+			wantError:   true,
+		},
+		"No projects selected": {
+			contextUser: instructor,
+			deepLinkID:  deepLinkID.String(),
+			reqBody:     `{"project_ids":[]}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Expired deep link": {
+			contextUser: instructor,
+			deepLinkID:  expiredID.String(),
+			reqBody:     `{"project_ids":["` + projectID.String() + `"]}`,
+			wantCode:    http.StatusNotFound,
+			wantError:   true,
+		},
+		"Successful deep link": {
+			contextUser: instructor,
+			deepLinkID:  deepLinkID.String(),
+			reqBody:     `{"project_ids":["` + projectID.String() + `"]}`,
+			wantCode:    http.StatusOK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.deepLinkID)
+
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.DeepLink(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"jwt":"signed"`)
+			}
+		})
+	}
+}
+
+func TestLTIConfirmLink(t *testing.T) {
+	e := echo.New()
+
+	mockLTIService := mocks.MockLTIService{}
+	handler := NewLTIHandler(&mockLTIService, &mocks.MockAuthService{}, &mocks.MockUserService{}, &mocks.MockTokenService{}, "http://client.test")
+
+	student := &data.User{ID: uuid.New(), Username: "student", IsActivated: true}
+	moderator := &data.User{ID: uuid.New(), Username: "moderator", IsActivated: true}
+	requestID := uuid.New()
+	expiredID := uuid.New()
+
+	mockLTIService.On("ConfirmLink", requestID, student.ID).Return(nil)
+	mockLTIService.On("ConfirmLink", expiredID, student.ID).Return(services.ErrRecordNotFound)
+	mockLTIService.On("ConfirmLink", requestID, moderator.ID).Return(services.ErrPrivilegedAccount)
+
+	tests := map[string]struct {
+		contextUser *data.User
+		requestID   string
+		wantCode    int
+		wantError   bool
+	}{
+		"User not authenticated": {
+			requestID: requestID.String(),
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Invalid link request ID": {
+			contextUser: student,
+			requestID:   "invalid",
+			wantCode:    http.StatusBadRequest,
+			wantError:   true,
+		},
+		"Expired or foreign link request": {
+			contextUser: student,
+			requestID:   expiredID.String(),
+			wantCode:    http.StatusNotFound,
+			wantError:   true,
+		},
+		"Staff account": {
+			contextUser: moderator,
+			requestID:   requestID.String(),
+			wantCode:    http.StatusForbidden,
+			wantError:   true,
+		},
+		"Successful link": {
+			contextUser: student,
+			requestID:   requestID.String(),
+			wantCode:    http.StatusOK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.requestID)
+
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.ConfirmLink(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
+
+func TestLTISubmit(t *testing.T) {
+	e := echo.New()
+
+	mockLTIService := mocks.MockLTIService{}
+	handler := NewLTIHandler(&mockLTIService, &mocks.MockAuthService{}, &mocks.MockUserService{}, &mocks.MockTokenService{}, "http://client.test")
+
+	student := &data.User{ID: uuid.New(), Username: "student", IsActivated: true}
+	launchID := uuid.New()
+	ungradedID := uuid.New()
+	unknownID := uuid.New()
+	submittedAt := time.Now()
+
+	mockLTIService.On("SubmitAssignment", unknownID, student.ID).Return(nil, services.ErrRecordNotFound)
+	mockLTIService.On("SubmitAssignment", ungradedID, student.ID).Return(nil, services.ErrGradingUnavailable)
+	mockLTIService.On("SubmitAssignment", launchID, student.ID).Return(&data.LTILaunch{ID: launchID, UserID: student.ID, SubmittedAt: &submittedAt}, nil)
+
+	tests := map[string]struct {
+		contextUser *data.User
+		launchID    string
+		wantCode    int
+		wantError   bool
+	}{
+		"User not authenticated": {
+			launchID:  launchID.String(),
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Invalid launch ID": {
+			contextUser: student,
+			launchID:    "invalid",
+			wantCode:    http.StatusBadRequest,
+			wantError:   true,
+		},
+		"Launch not found": {
+			contextUser: student,
+			launchID:    unknownID.String(),
+			wantCode:    http.StatusNotFound,
+			wantError:   true,
+		},
+		"Grading unavailable": {
+			contextUser: student,
+			launchID:    ungradedID.String(),
+			wantCode:    http.StatusConflict,
+			wantError:   true,
+		},
+		"Successful submission": {
+			contextUser: student,
+			launchID:    launchID.String(),
+			wantCode:    http.StatusOK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.launchID)
+
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.Submit(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
+
+func TestLTIRegisterPlatform(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	mockLTIService := mocks.MockLTIService{}
+	handler := NewLTIHandler(&mockLTIService, &mocks.MockAuthService{}, &mocks.MockUserService{}, &mocks.MockTokenService{}, "http://client.test")
+
+	mockLTIService.On("RegisterPlatform", mock.MatchedBy(func(p data.LTIPlatformCreate) bool {
+		return p.ClientID == "existing"
+	})).Return(nil, services.ErrPlatformExists)
+	mockLTIService.On("RegisterPlatform", mock.Anything).Return(&data.LTIPlatform{ID: 1, Issuer: "https://canvas.test", ClientID: "client"}, nil)
+
+	validBody := func(clientID string) string {
+		return `{"issuer":"https://canvas.test","client_id":"` + clientID + `","deployment_ids":["1"],` +
+			`"auth_login_url":"https://canvas.test/auth","auth_token_url":"https://canvas.test/token","keyset_url":"https://canvas.test/jwks"}`
+	}
+
+	tests := map[string]struct {
+		reqBody   string
+		wantCode  int
+		wantError bool
+	}{
+		"Invalid request body": {
+			reqBody:   `{"issuer":`,
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Missing deployments": {
+			reqBody:   `{"issuer":"https://canvas.test","client_id":"client","auth_login_url":"https://canvas.test/auth","auth_token_url":"https://canvas.test/token","keyset_url":"https://canvas.test/jwks"}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Platform already registered": {
+			reqBody:   validBody("existing"),
+			wantCode:  http.StatusConflict,
+			wantError: true,
+		},
+		"Successful registration": {
+			reqBody:  validBody("client"),
+			wantCode: http.StatusCreated,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.RegisterPlatform(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"NodeTurtleAPI/internal/api/middleware"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/time/rate"
+)
+
+// newMagicLinkLimiter creates the store limiting login links to perHour requests per email address.
+func newMagicLinkLimiter(perHour int) *middleware.Limiter {
+	if perHour <= 0 {
+		return nil
+	}
+
+	return middleware.NewLimiter(rate.Limit(float64(perHour)/3600), perHour, time.Hour)
+}
+
+// RequestMagicLink handles the request to log in without a password.
+// It emails a single use login link to the address. The response is the same whether or not
+// an account can log in with the address, so it can't be used to find out which emails are registered.
+func (h *AuthHandler) RequestMagicLink(c echo.Context) error {
+	var payload struct {
+		Email string `json:"email" validate:"required,email"`
+	}
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if h.magicLinks != nil {
+		if allowed, retryAfter := h.magicLinks.Allow(strings.ToLower(payload.Email)); !allowed {
+			return middleware.TooManyRequests(c, middleware.CodeMagicLinkLimit, "Too many login links requested, please try again later", retryAfter)
+		}
+	}
+
+	accepted := map[string]interface{}{
+		"message": "If an account with that email exists, a login link has been sent.",
+	}
+
+	user, err := h.userService.GetUserByEmail(c.Request().Context(), payload.Email)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			return c.JSON(http.StatusAccepted, accepted)
+		}
+		logging.Error(c.Request().Context(), "Internal user retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve user")
+	}
+
+	if !user.IsActivated || user.Ban.IsValid() {
+		return c.JSON(http.StatusAccepted, accepted)
+	}
+
+	loginToken, err := h.tokenService.New(c.Request().Context(), user.ID, data.ScopeMagicLink)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal login token creation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create login token")
+	}
+
+	loginLink := fmt.Sprintf("/magic/%s", loginToken.Plaintext)
+	emailData := map[string]string{
+		"Username":  user.Username,
+		"url":       loginLink,
+		"ExpiresAt": formatExpiry(loginToken.ExpiresAt),
+	}
+
+	// the response stays the same, it doesn't tell whether the email has an account
+	if err := h.mailService.QueueEmail(c.Request().Context(), user.Email, "Your Login Link", "magic_link", emailData); err != nil {
+		logging.Error(c.Request().Context(), "Failed to queue login link email", err)
+	}
+
+	return c.JSON(http.StatusAccepted, accepted)
+}
+
+// MagicLogin handles the exchange of an emailed login link for a session.
+// The link proves the user owns the email, so the country the user logs in from is trusted afterwards.
+// Returns an error if the token is invalid, expired or was already used, or if the account can't log in.
+func (h *AuthHandler) MagicLogin(c echo.Context) error {
+	token := c.Param("token")
+	if token == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid login token")
+	}
+
+	tokenUser, err := h.userService.GetForToken(c.Request().Context(), data.ScopeMagicLink, token)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Invalid or expired login link")
+		}
+		logging.Error(c.Request().Context(), "Internal user retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to login")
+	}
+
+	// consuming the token makes sure a link opened twice at the same time logs in once
+	if err := h.tokenService.Consume(c.Request().Context(), data.ScopeMagicLink, token); err != nil {
+		if errors.Is(err, services.ErrInvalidToken) {
+			return echo.NewHTTPError(http.StatusNotFound, "Invalid or expired login link")
+		}
+		logging.Error(c.Request().Context(), "Internal login token consumption error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to login")
+	}
+
+	// the token lookup doesn't load the role the access token is issued for
+	user, err := h.userService.GetUserByID(c.Request().Context(), tokenUser.ID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal user retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to login")
+	}
+
+	if user.Ban.IsValid() {
+		return echo.NewHTTPError(http.StatusForbidden, services.BanMessage(user.Ban.Reason, user.Ban.ExpiresAt))
+	}
+
+	if !user.IsActivated {
+		return echo.NewHTTPError(http.StatusForbidden, "INACTIVE_ACCOUNT")
+	}
+
+	if country := h.loginCountry(c); country != "" {
+		if err := h.locationService.Trust(user.ID, country); err != nil {
+			logging.Error(c.Request().Context(), "Internal trusted location update error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to login")
+		}
+	}
+
+	if err := h.authService.UpdateLastLogin(c.Request().Context(), user.ID); err != nil {
+		logging.Error(c.Request().Context(), "Internal last login update error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to login")
+	}
+
+	accessToken, err := h.authService.CreateAccessToken(*user)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal access token creation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create access token")
+	}
+
+	return startSession(c, h.tokenService, accessToken, user)
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/utils"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRequestMagicLink(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	mockUserService := mocks.MockUserService{}
+	mockTokenService := mocks.MockTokenService{}
+	mockMailService := mocks.MockMailService{}
+
+	user := &data.User{ID: uuid.New(), Email: "test@test.test", Username: "testuser", IsActivated: true}
+	inactiveUser := &data.User{ID: uuid.New(), Email: "inactive@test.test", Username: "inactive"}
+
+	mockUserService.On("GetUserByEmail", user.Email).Return(user, nil)
+	mockUserService.On("GetUserByEmail", "TEST@test.test").Return(user, nil)
+	mockUserService.On("GetUserByEmail", inactiveUser.Email).Return(inactiveUser, nil)
+	mockUserService.On("GetUserByEmail", "unknown@test.test").Return(nil, services.ErrUserNotFound)
+	mockTokenService.On("New", user.ID, data.ScopeMagicLink).Return(&data.Token{Plaintext: "magic", Scope: data.ScopeMagicLink, ExpiresAt: time.Now().Add(15 * time.Minute)}, nil)
+	mockMailService.On("QueueEmail", user.Email, mock.Anything, "magic_link", mock.Anything).Return(nil)
+
+	handler := NewAuthHandler(&mocks.MockAuthService{}, &mocks.MockOAuthService{}, &mockUserService, &mockTokenService, &mockMailService, &mocks.MockLocationService{}, "", config.LoginVerificationConfig{MagicLinksPerHour: 2}, &mocks.MockPasswordService{}, auditRecorder())
+
+	tests := []struct {
+		name      string
+		reqBody   string
+		wantCode  int
+		wantError bool
+	}{
+		{
+			name:      "Invalid email",
+			reqBody:   `{"email":"not-an-email"}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		{
+			name:     "Unknown email",
+			reqBody:  `{"email":"unknown@test.test"}`,
+			wantCode: http.StatusAccepted,
+		},
+		{
+			name:     "Inactive account",
+			reqBody:  `{"email":"inactive@test.test"}`,
+			wantCode: http.StatusAccepted,
+		},
+		{
+			name:     "Link sent",
+			reqBody:  `{"email":"test@test.test"}`,
+			wantCode: http.StatusAccepted,
+		},
+		{
+			name:     "Second link within the limit",
+			reqBody:  `{"email":"TEST@test.test"}`,
+			wantCode: http.StatusAccepted,
+		},
+		{
+			name:      "Too many links for the email",
+			reqBody:   `{"email":"test@test.test"}`,
+			wantCode:  http.StatusTooManyRequests,
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/auth/magic-link", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.RequestMagicLink(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+				if tt.wantCode == http.StatusTooManyRequests {
+					assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), "login link has been sent")
+			}
+		})
+	}
+
+	mockTokenService.AssertNumberOfCalls(t, "New", 2)
+}
+
+func TestMagicLogin(t *testing.T) {
+	e := echo.New()
+
+	mockAuthService := mocks.MockAuthService{}
+	mockUserService := mocks.MockUserService{}
+	mockTokenService := mocks.MockTokenService{}
+	mockLocationService := mocks.MockLocationService{}
+
+	user := &data.User{ID: uuid.New(), Email: "test@test.test", Username: "testuser", IsActivated: true, Role: data.Role{ID: 1, Name: "user"}}
+	bannedUser := &data.User{ID: uuid.New(), Email: "banned@test.test", Username: "banned", IsActivated: true,
+		Ban: &data.Ban{ExpiresAt: utils.Ptr(time.Now().Add(24 * time.Hour)), Reason: "spam"}}
+
+	mockUserService.On("GetForToken", data.ScopeMagicLink, "valid").Return(&data.User{ID: user.ID}, nil)
+	mockUserService.On("GetForToken", data.ScopeMagicLink, "used").Return(&data.User{ID: user.ID}, nil)
+	mockUserService.On("GetForToken", data.ScopeMagicLink, "banned").Return(&data.User{ID: bannedUser.ID}, nil)
+	mockUserService.On("GetForToken", data.ScopeMagicLink, "expired").Return(nil, services.ErrRecordNotFound)
+	mockUserService.On("GetUserByID", user.ID).Return(user, nil)
+	mockUserService.On("GetUserByID", bannedUser.ID).Return(bannedUser, nil)
+	mockTokenService.On("Consume", data.ScopeMagicLink, "valid").Return(nil)
+	mockTokenService.On("Consume", data.ScopeMagicLink, "banned").Return(nil)
+	mockTokenService.On("Consume", data.ScopeMagicLink, "used").Return(services.ErrInvalidToken)
+	mockLocationService.On("Trust", user.ID, "FR").Return(nil)
+	mockAuthService.On("UpdateLastLogin", user.ID).Return(nil)
+	mockAuthService.On("CreateAccessToken", *user).Return("access-token", nil)
+	mockTokenService.On("DeleteAllForUser", data.ScopeRefresh, user.ID).Return(nil)
+	mockTokenService.On("New", user.ID, data.ScopeRefresh).Return(&data.Token{Plaintext: "refresh", Scope: data.ScopeRefresh}, nil)
+
+	handler := NewAuthHandler(&mockAuthService, &mocks.MockOAuthService{}, &mockUserService, &mockTokenService, &mocks.MockMailService{}, &mockLocationService, "", config.LoginVerificationConfig{CountryHeader: "CF-IPCountry"}, &mocks.MockPasswordService{}, auditRecorder())
+
+	tests := map[string]struct {
+		token     string
+		wantCode  int
+		wantError bool
+	}{
+		"Expired link": {
+			token:     "expired",
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Link used concurrently": {
+			token:     "used",
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Banned user": {
+			token:     "banned",
+			wantCode:  http.StatusForbidden,
+			wantError: true,
+		},
+		"Successful login": {
+			token:    "valid",
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("CF-IPCountry", "FR")
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("token")
+			c.SetParamValues(tt.token)
+
+			err := handler.MagicLogin(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), "access-token")
+			}
+		})
+	}
+
+	mockAuthService.AssertExpectations(t)
+	mockLocationService.AssertExpectations(t)
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+
+	"NodeTurtleAPI/internal/services/mail"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MailboxHandler handles HTTP requests to read the emails captured instead of sent in dev and test setups.
+// Its routes are only registered with MAIL_CAPTURE, which can't be enabled in production.
+type MailboxHandler struct {
+	mailbox *mail.Mailbox
+}
+
+// NewMailboxHandler creates a new MailboxHandler over the mailbox of the mail service.
+func NewMailboxHandler(mailbox *mail.Mailbox) MailboxHandler {
+	return MailboxHandler{
+		mailbox: mailbox,
+	}
+}
+
+// List handles the request to retrieve the captured emails, newest first, optionally only those sent to the address in ?to=.
+func (h *MailboxHandler) List(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"emails": h.mailbox.List(c.QueryParam("to")),
+	})
+}
+
+// Clear handles the request to empty the mailbox between tests.
+func (h *MailboxHandler) Clear(c echo.Context) error {
+	h.mailbox.Clear()
+	return c.NoContent(http.StatusNoContent)
+}
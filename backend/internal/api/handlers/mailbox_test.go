@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services/mail"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMailbox(t *testing.T) {
+	e := echo.New()
+
+	mailbox := mail.NewMailbox()
+	mailbox.Add(data.CapturedEmail{To: "alice@example.com", Template: "activation", Data: map[string]string{"url": "http://localhost/activate/abc"}})
+	mailbox.Add(data.CapturedEmail{To: "bob@example.com", Template: "activation"})
+	mailbox.Add(data.CapturedEmail{To: "alice@example.com", Template: "reset"})
+	handler := NewMailboxHandler(mailbox)
+
+	list := func(query string) []data.CapturedEmail {
+		req := httptest.NewRequest(http.MethodGet, "/"+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		assert.NoError(t, handler.List(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var body struct {
+			Emails []data.CapturedEmail `json:"emails"`
+		}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return body.Emails
+	}
+
+	assert.Len(t, list(""), 3)
+
+	// newest first, recipients match regardless of case
+	emails := list("?to=Alice@example.com")
+	if assert.Len(t, emails, 2) {
+		assert.Equal(t, "reset", emails[0].Template)
+		assert.Equal(t, "http://localhost/activate/abc", emails[1].Data["url"])
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/", nil)
+	rec := httptest.NewRecorder()
+	assert.NoError(t, handler.Clear(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	assert.Empty(t, list(""))
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MetricsHandler handles HTTP requests for operational metrics.
+type MetricsHandler struct {
+	botMetrics    func() data.BotMetrics
+	cacheMetrics  func() []data.CacheMetrics
+	signupMetrics func() data.SignupMetrics
+	loadMetrics   func() data.LoadMetrics
+	dataMetrics   func() data.IntegrityMetrics
+	probeMetrics  func() data.ProbeMetrics
+}
+
+// NewMetricsHandler creates a new MetricsHandler reading crawler traffic counters from botMetrics
+// the counters of the in-memory caches from cacheMetrics, the signup limit counters from signupMetrics,
+// the load of the instance from loadMetrics, the checksum mismatches of project data from dataMetrics
+// and the requests of probes from probeMetrics.
+func NewMetricsHandler(botMetrics func() data.BotMetrics, cacheMetrics func() []data.CacheMetrics, signupMetrics func() data.SignupMetrics, loadMetrics func() data.LoadMetrics, dataMetrics func() data.IntegrityMetrics, probeMetrics func() data.ProbeMetrics) MetricsHandler {
+	return MetricsHandler{
+		botMetrics:    botMetrics,
+		cacheMetrics:  cacheMetrics,
+		signupMetrics: signupMetrics,
+		loadMetrics:   loadMetrics,
+		dataMetrics:   dataMetrics,
+		probeMetrics:  probeMetrics,
+	}
+}
+
+// Bots handles the request to retrieve the crawler traffic counters.
+func (h *MetricsHandler) Bots(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"metrics": h.botMetrics(),
+	})
+}
+
+// Caches handles the request to retrieve the hit and miss counters of the in-memory caches.
+func (h *MetricsHandler) Caches(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"caches": h.cacheMetrics(),
+	})
+}
+
+// Signups handles the request to retrieve the counters of signups allowed and blocked by the signup limits.
+func (h *MetricsHandler) Signups(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"metrics": h.signupMetrics(),
+	})
+}
+
+// Load handles the request to retrieve the current load of the instance and whether low-priority requests are shed.
+func (h *MetricsHandler) Load(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"metrics": h.loadMetrics(),
+	})
+}
+
+// Integrity handles the request to retrieve how often project data read didn't match its checksum.
+func (h *MetricsHandler) Integrity(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"metrics": h.dataMetrics(),
+	})
+}
+
+// Probes handles the request to retrieve the counters of the requests of load balancer probes and uptime checkers,
+// which the other traffic counters leave out.
+func (h *MetricsHandler) Probes(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"metrics": h.probeMetrics(),
+	})
+}
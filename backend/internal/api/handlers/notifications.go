@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services/consents"
+	"NodeTurtleAPI/internal/services/notifications"
+
+	"github.com/labstack/echo/v4"
+)
+
+// notificationsLimit is the maximum number of notifications returned at once.
+const notificationsLimit = 100
+
+// NotificationHandler handles HTTP requests to read in-app notifications and manage notification preferences.
+type NotificationHandler struct {
+	notificationService notifications.INotificationService
+	consentService      consents.IConsentService
+}
+
+// NewNotificationHandler creates a new NotificationHandler with the provided services.
+func NewNotificationHandler(notificationService notifications.INotificationService, consentService consents.IConsentService) NotificationHandler {
+	return NotificationHandler{
+		notificationService: notificationService,
+		consentService:      consentService,
+	}
+}
+
+// List handles the request to retrieve the current user's latest notifications and how many are unread.
+func (h *NotificationHandler) List(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 || limit > notificationsLimit {
+		limit = 20
+	}
+
+	userNotifications, unread, err := h.notificationService.GetNotifications(c.Request().Context(), contextUser.ID, limit)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal notification retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve notifications")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"notifications": userNotifications,
+		"unread":        unread,
+	})
+}
+
+// MarkRead handles the request to mark notifications of the current user as read, all of them when no IDs are given.
+func (h *NotificationHandler) MarkRead(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var payload struct {
+		IDs []int64 `json:"ids" validate:"max=100"`
+	}
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	marked, err := h.notificationService.MarkRead(c.Request().Context(), contextUser.ID, payload.IDs)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal notification update error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to mark notifications as read")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"marked": marked,
+	})
+}
+
+// GetPreferences handles the request to retrieve the current user's notification preferences.
+func (h *NotificationHandler) GetPreferences(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	prefs, err := h.notificationService.GetPreferences(c.Request().Context(), contextUser.ID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal notification preferences retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve notification preferences")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"preferences": prefs,
+	})
+}
+
+// UpdatePreferences handles the request to change the current user's notification preferences.
+// Preferences left out of the request are unchanged. Digests are marketing emails,
+// so switching them on needs the user's consent to those first.
+func (h *NotificationHandler) UpdatePreferences(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var payload data.NotificationPreferencesUpdate
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if payload.Likes == nil && payload.Digest == nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, "No preferences to update")
+	}
+
+	if payload.Digest != nil && *payload.Digest != data.DigestOff {
+		granted, err := h.consentService.HasConsent(c.Request().Context(), contextUser.ID, data.ConsentMarketingEmails)
+		if err != nil {
+			logging.Error(c.Request().Context(), "Internal consent retrieval error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update notification preferences")
+		}
+		if !granted {
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, "Email digests need consent to marketing emails")
+		}
+	}
+
+	prefs, err := h.notificationService.UpdatePreferences(c.Request().Context(), contextUser.ID, payload)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal notification preferences update error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update notification preferences")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"preferences": prefs,
+	})
+}
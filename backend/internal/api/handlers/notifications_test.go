@@ -0,0 +1,294 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListNotifications(t *testing.T) {
+	e := echo.New()
+
+	user := &data.User{ID: uuid.New(), Username: "creator", IsActivated: true}
+
+	tests := map[string]struct {
+		contextUser *data.User
+		query       string
+		setupMocks  func(m *mocks.MockNotificationService)
+		wantCode    int
+		wantError   bool
+	}{
+		"User not authenticated": {
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Latest notifications": {
+			contextUser: user,
+			setupMocks: func(m *mocks.MockNotificationService) {
+				m.On("GetNotifications", user.ID, 20).Return([]data.Notification{{ID: 1, Type: data.NotificationProjectLiked}}, 1, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Limit is capped": {
+			contextUser: user,
+			query:       "?limit=1000",
+			setupMocks: func(m *mocks.MockNotificationService) {
+				m.On("GetNotifications", user.ID, 20).Return([]data.Notification{}, 0, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Database error": {
+			contextUser: user,
+			query:       "?limit=5",
+			setupMocks: func(m *mocks.MockNotificationService) {
+				m.On("GetNotifications", user.ID, 5).Return(nil, 0, errors.New("database error"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockNotificationService := mocks.MockNotificationService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockNotificationService)
+			}
+			handler := NewNotificationHandler(&mockNotificationService, &mocks.MockConsentService{})
+
+			req := httptest.NewRequest(http.MethodGet, "/"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.List(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+			mockNotificationService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMarkNotificationsRead(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	user := &data.User{ID: uuid.New(), Username: "creator", IsActivated: true}
+
+	tests := map[string]struct {
+		reqBody    string
+		setupMocks func(m *mocks.MockNotificationService)
+		wantCode   int
+		wantError  bool
+	}{
+		"Invalid body": {
+			reqBody:   `{"ids":"all"}`,
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Some notifications": {
+			reqBody: `{"ids":[1,2]}`,
+			setupMocks: func(m *mocks.MockNotificationService) {
+				m.On("MarkRead", user.ID, []int64{1, 2}).Return(int64(2), nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"All notifications": {
+			reqBody: `{}`,
+			setupMocks: func(m *mocks.MockNotificationService) {
+				m.On("MarkRead", user.ID, []int64(nil)).Return(int64(7), nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Database error": {
+			reqBody: `{"ids":[1]}`,
+			setupMocks: func(m *mocks.MockNotificationService) {
+				m.On("MarkRead", user.ID, []int64{1}).Return(int64(0), errors.New("database error"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockNotificationService := mocks.MockNotificationService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockNotificationService)
+			}
+			handler := NewNotificationHandler(&mockNotificationService, &mocks.MockConsentService{})
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", user)
+
+			err := handler.MarkRead(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+			mockNotificationService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateNotificationPreferences(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	user := &data.User{ID: uuid.New(), Username: "creator", IsActivated: true}
+
+	off := false
+	weekly := data.DigestWeekly
+	daily := data.DigestDaily
+	digestOff := data.DigestOff
+
+	tests := map[string]struct {
+		contextUser *data.User
+		reqBody     string
+		setupMocks  func(n *mocks.MockNotificationService, c *mocks.MockConsentService)
+		wantCode    int
+		wantError   bool
+	}{
+		"User not authenticated": {
+			reqBody:   `{"likes":false}`,
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Invalid body": {
+			contextUser: user,
+			reqBody:     `{"likes":"no"}`,
+			wantCode:    http.StatusBadRequest,
+			wantError:   true,
+		},
+		"Unknown digest frequency": {
+			contextUser: user,
+			reqBody:     `{"digest":"hourly"}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Nothing to update": {
+			contextUser: user,
+			reqBody:     `{}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Like notifications off": {
+			contextUser: user,
+			reqBody:     `{"likes":false}`,
+			setupMocks: func(n *mocks.MockNotificationService, c *mocks.MockConsentService) {
+				n.On("UpdatePreferences", user.ID, data.NotificationPreferencesUpdate{Likes: &off}).
+					Return(&data.NotificationPreferences{Likes: false, Digest: data.DigestOff}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Digest without consent to marketing emails": {
+			contextUser: user,
+			reqBody:     `{"digest":"weekly"}`,
+			setupMocks: func(n *mocks.MockNotificationService, c *mocks.MockConsentService) {
+				c.On("HasConsent", user.ID, data.ConsentMarketingEmails).Return(false, nil)
+			},
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Consent check error": {
+			contextUser: user,
+			reqBody:     `{"digest":"daily"}`,
+			setupMocks: func(n *mocks.MockNotificationService, c *mocks.MockConsentService) {
+				c.On("HasConsent", user.ID, data.ConsentMarketingEmails).Return(false, errors.New("database error"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+		"Weekly digest": {
+			contextUser: user,
+			reqBody:     `{"digest":"weekly"}`,
+			setupMocks: func(n *mocks.MockNotificationService, c *mocks.MockConsentService) {
+				c.On("HasConsent", user.ID, data.ConsentMarketingEmails).Return(true, nil)
+				n.On("UpdatePreferences", user.ID, data.NotificationPreferencesUpdate{Digest: &weekly}).
+					Return(&data.NotificationPreferences{Likes: true, Digest: data.DigestWeekly}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Switching the digest off needs no consent": {
+			contextUser: user,
+			reqBody:     `{"digest":"off"}`,
+			setupMocks: func(n *mocks.MockNotificationService, c *mocks.MockConsentService) {
+				n.On("UpdatePreferences", user.ID, data.NotificationPreferencesUpdate{Digest: &digestOff}).
+					Return(&data.NotificationPreferences{Likes: true, Digest: data.DigestOff}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Database error": {
+			contextUser: user,
+			reqBody:     `{"digest":"daily"}`,
+			setupMocks: func(n *mocks.MockNotificationService, c *mocks.MockConsentService) {
+				c.On("HasConsent", user.ID, data.ConsentMarketingEmails).Return(true, nil)
+				n.On("UpdatePreferences", user.ID, data.NotificationPreferencesUpdate{Digest: &daily}).
+					Return(nil, errors.New("database error"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockNotificationService := mocks.MockNotificationService{}
+			mockConsentService := mocks.MockConsentService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockNotificationService, &mockConsentService)
+			}
+			handler := NewNotificationHandler(&mockNotificationService, &mockConsentService)
+
+			req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.UpdatePreferences(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+			mockNotificationService.AssertExpectations(t)
+			mockConsentService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// OAuthProviders handles the request to list the enabled social login providers.
+func (h *AuthHandler) OAuthProviders(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"providers": h.oauthService.Providers(),
+	})
+}
+
+// OAuthLogin handles the request to sign in with a social login provider
+// and redirects the user agent to the provider authorization page.
+func (h *AuthHandler) OAuthLogin(c echo.Context) error {
+	redirectURL, err := h.oauthService.AuthCodeURL(c.Request().Context(), c.Param("provider"))
+	if err != nil {
+		if errors.Is(err, services.ErrUnknownProvider) {
+			return echo.NewHTTPError(http.StatusNotFound, "Unknown login provider")
+		}
+		logging.Error(c.Request().Context(), "Internal OAuth login error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to initiate login")
+	}
+
+	return c.Redirect(http.StatusFound, redirectURL)
+}
+
+// OAuthCallback handles the redirect back from the provider after authorization.
+// It signs the user in with the same JWT + refresh token pair as the password login and redirects to the frontend.
+// Denied authorizations, provider accounts without a verified email and deactivated accounts are sent back
+// to the frontend login page.
+func (h *AuthHandler) OAuthCallback(c echo.Context) error {
+	if providerErr := c.QueryParam("error"); providerErr != "" {
+		return h.oauthFailure(c, providerErr)
+	}
+
+	code := c.QueryParam("code")
+	state := c.QueryParam("state")
+	if code == "" || state == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Missing code or state")
+	}
+
+	userID, err := h.oauthService.Authenticate(c.Request().Context(), c.Param("provider"), code, state)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnknownProvider):
+			return echo.NewHTTPError(http.StatusNotFound, "Unknown login provider")
+		case errors.Is(err, services.ErrInvalidToken):
+			return echo.NewHTTPError(http.StatusUnauthorized, "Invalid or expired login attempt")
+		case errors.Is(err, services.ErrUnverifiedEmail):
+			return h.oauthFailure(c, "unverified_email")
+		case errors.Is(err, services.ErrInactiveAccount):
+			return h.oauthFailure(c, "inactive_account")
+		}
+		logging.Error(c.Request().Context(), "Internal OAuth callback error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to login")
+	}
+
+	user, err := h.userService.GetUserByID(c.Request().Context(), userID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal user retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to login")
+	}
+
+	if user.Ban.IsValid() {
+		return echo.NewHTTPError(http.StatusForbidden, services.BanMessage(user.Ban.Reason, user.Ban.ExpiresAt))
+	}
+
+	if err := h.authService.UpdateLastLogin(c.Request().Context(), user.ID); err != nil {
+		logging.Error(c.Request().Context(), "Internal last login update error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to login")
+	}
+
+	token, err := h.authService.CreateAccessToken(*user)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal access token creation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create access token")
+	}
+
+	if err := h.tokenService.DeleteAllForUser(c.Request().Context(), data.ScopeRefresh, user.ID); err != nil {
+		logging.Error(c.Request().Context(), "Internal refresh token deletion error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete old refresh tokens")
+	}
+
+	refreshToken, err := h.tokenService.New(c.Request().Context(), user.ID, data.ScopeRefresh)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal refresh token creation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create new refresh token")
+	}
+
+	setTokenCookies(c, token, refreshToken.Plaintext)
+
+	return c.Redirect(http.StatusFound, h.clientURL+"/")
+}
+
+// oauthFailure redirects the user agent back to the frontend login page with the reason the social login failed.
+func (h *AuthHandler) oauthFailure(c echo.Context, reason string) error {
+	return c.Redirect(http.StatusFound, h.clientURL+"/login?oauth_error="+url.QueryEscape(reason))
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOAuthLogin(t *testing.T) {
+	e := echo.New()
+
+	mockOAuthService := mocks.MockOAuthService{}
+	handler := NewAuthHandler(&mocks.MockAuthService{}, &mockOAuthService, &mocks.MockUserService{}, &mocks.MockTokenService{}, &mocks.MockMailService{}, &mocks.MockLocationService{}, "http://client.test", config.LoginVerificationConfig{}, &mocks.MockPasswordService{}, auditRecorder())
+
+	mockOAuthService.On("AuthCodeURL", "github").Return("https://github.com/login/oauth/authorize?state=abc", nil)
+	mockOAuthService.On("AuthCodeURL", "myspace").Return("", services.ErrUnknownProvider)
+
+	tests := map[string]struct {
+		provider     string
+		wantCode     int
+		wantLocation string
+		wantError    bool
+	}{
+		"Unknown provider": {
+			provider:  "myspace",
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Redirect to provider": {
+			provider:     "github",
+			wantCode:     http.StatusFound,
+			wantLocation: "https://github.com/login/oauth/authorize?state=abc",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("provider")
+			c.SetParamValues(tt.provider)
+
+			err := handler.OAuthLogin(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Equal(t, tt.wantLocation, rec.Header().Get(echo.HeaderLocation))
+			}
+		})
+	}
+}
+
+func TestOAuthCallback(t *testing.T) {
+	e := echo.New()
+
+	mockAuthService := mocks.MockAuthService{}
+	mockOAuthService := mocks.MockOAuthService{}
+	mockUserService := mocks.MockUserService{}
+	mockTokenService := mocks.MockTokenService{}
+	handler := NewAuthHandler(&mockAuthService, &mockOAuthService, &mockUserService, &mockTokenService, &mocks.MockMailService{}, &mocks.MockLocationService{}, "http://client.test", config.LoginVerificationConfig{}, &mocks.MockPasswordService{}, auditRecorder())
+
+	user := &data.User{ID: uuid.New(), Email: "ann@example.com", Username: "ann1234", IsActivated: true}
+
+	mockOAuthService.On("Authenticate", "google", "good-code", "state").Return(user.ID, nil)
+	mockOAuthService.On("Authenticate", "google", "bad-code", "state").Return(uuid.Nil, services.ErrInvalidToken)
+	mockOAuthService.On("Authenticate", "google", "unverified-code", "state").Return(uuid.Nil, services.ErrUnverifiedEmail)
+	mockOAuthService.On("Authenticate", "google", "deactivated-code", "state").Return(uuid.Nil, services.ErrInactiveAccount)
+	mockUserService.On("GetUserByID", user.ID).Return(user, nil)
+	mockAuthService.On("UpdateLastLogin", user.ID).Return(nil)
+	mockAuthService.On("CreateAccessToken", *user).Return("access-token", nil)
+	mockTokenService.On("DeleteAllForUser", data.ScopeRefresh, user.ID).Return(nil)
+	mockTokenService.On("New", user.ID, data.ScopeRefresh).Return(&data.Token{Plaintext: "refresh-token", Scope: data.ScopeRefresh}, nil)
+
+	tests := map[string]struct {
+		query        string
+		wantCode     int
+		wantLocation string
+		wantError    bool
+	}{
+		"Authorization denied": {
+			query:        "?error=access_denied",
+			wantCode:     http.StatusFound,
+			wantLocation: "http://client.test/login?oauth_error=access_denied",
+		},
+		"Missing code": {
+			query:     "?state=state",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Invalid code": {
+			query:     "?code=bad-code&state=state",
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Unverified email": {
+			query:        "?code=unverified-code&state=state",
+			wantCode:     http.StatusFound,
+			wantLocation: "http://client.test/login?oauth_error=unverified_email",
+		},
+		"Deactivated account": {
+			query:        "?code=deactivated-code&state=state",
+			wantCode:     http.StatusFound,
+			wantLocation: "http://client.test/login?oauth_error=inactive_account",
+		},
+		"Successful login": {
+			query:        "?code=good-code&state=state",
+			wantCode:     http.StatusFound,
+			wantLocation: "http://client.test/",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("provider")
+			c.SetParamValues("google")
+
+			err := handler.OAuthCallback(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Equal(t, tt.wantLocation, rec.Header().Get(echo.HeaderLocation))
+			}
+		})
+	}
+
+	mockTokenService.AssertExpectations(t)
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"net/http"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/labstack/echo/v4"
+)
+
+// mimeOPML is the content type of OPML documents.
+const mimeOPML = "text/x-opml+xml; charset=UTF-8"
+
+type opmlDocument struct {
+	XMLName xml.Name      `xml:"opml"`
+	Version string        `xml:"version,attr"`
+	Title   string        `xml:"head>title"`
+	Created string        `xml:"head>dateCreated"`
+	Outline []opmlOutline `xml:"body>outline"`
+}
+
+type opmlOutline struct {
+	Text    string `xml:"text,attr"`
+	Type    string `xml:"type,attr"`
+	URL     string `xml:"url,attr"`
+	Creator string `xml:"creator,attr,omitempty"`
+}
+
+// writeOPML responds with the links as an OPML 2.0 outline.
+func writeOPML(c echo.Context, title string, created time.Time, links []data.ProjectLink) error {
+	doc := opmlDocument{
+		Version: "2.0",
+		Title:   title,
+		Created: created.Format(time.RFC1123Z),
+		Outline: make([]opmlOutline, 0, len(links)),
+	}
+	for _, link := range links {
+		doc.Outline = append(doc.Outline, opmlOutline{
+			Text:    link.Title,
+			Type:    "link",
+			URL:     link.URL,
+			Creator: link.Creator,
+		})
+	}
+
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return c.Blob(http.StatusOK, mimeOPML, append([]byte(xml.Header), body...))
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/profiles"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ProfileHandler handles HTTP requests to read and edit public user profiles.
+type ProfileHandler struct {
+	profileService profiles.IProfileService
+}
+
+// NewProfileHandler creates a new ProfileHandler with the provided profile service.
+func NewProfileHandler(profileService profiles.IProfileService) ProfileHandler {
+	return ProfileHandler{
+		profileService: profileService,
+	}
+}
+
+// Get handles the request to retrieve the public profile of a user by their username, no session needed.
+// Previous usernames lead to the profile too, with moved set in the response.
+func (h *ProfileHandler) Get(c echo.Context) error {
+	profile, err := h.profileService.GetProfile(c.Request().Context(), c.Param("username"))
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "User not found")
+		}
+		logging.Error(c.Request().Context(), "Internal profile retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve profile")
+	}
+
+	response := map[string]interface{}{
+		"profile": profile,
+	}
+	// the username was given up, clients link to the current one from now on
+	if profile.Username != c.Param("username") {
+		response["moved"] = true
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// Update handles the request of a user to replace the bio, website and social links of their own profile.
+func (h *ProfileHandler) Update(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	if c.Param("username") != contextUser.Username {
+		return echo.NewHTTPError(http.StatusForbidden, "You can only edit your own profile")
+	}
+
+	var payload data.UserProfileUpdate
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	// links are rendered on the profile page, anything but web pages could run script there
+	if payload.Website != "" && !isWebURL(payload.Website) {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, "Website must be an http or https URL")
+	}
+	for network, link := range payload.SocialLinks {
+		if !isWebURL(link) {
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, "The "+network+" link must be an http or https URL")
+		}
+	}
+
+	profile, err := h.profileService.UpdateProfile(c.Request().Context(), contextUser.ID, payload)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "User not found")
+		}
+		logging.Error(c.Request().Context(), "Internal profile update error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update profile")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"profile": profile,
+	})
+}
+
+// isWebURL reports whether link is an absolute http or https URL.
+func isWebURL(link string) bool {
+	u, err := url.Parse(link)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
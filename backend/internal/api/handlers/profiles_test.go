@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetProfile(t *testing.T) {
+	e := echo.New()
+
+	tests := map[string]struct {
+		username   string
+		setupMocks func(m *mocks.MockProfileService)
+		wantCode   int
+		wantError  bool
+		wantMoved  bool
+	}{
+		"Profile found": {
+			username: "alice",
+			setupMocks: func(m *mocks.MockProfileService) {
+				m.On("GetProfile", "alice").Return(&data.UserProfile{Username: "alice", PublicProjects: 2, LikesReceived: 5}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Previous username": {
+			username: "alicia",
+			setupMocks: func(m *mocks.MockProfileService) {
+				m.On("GetProfile", "alicia").Return(&data.UserProfile{Username: "alice"}, nil)
+			},
+			wantCode:  http.StatusOK,
+			wantMoved: true,
+		},
+		"User not found": {
+			username: "nobody",
+			setupMocks: func(m *mocks.MockProfileService) {
+				m.On("GetProfile", "nobody").Return(nil, services.ErrUserNotFound)
+			},
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Database error": {
+			username: "alice",
+			setupMocks: func(m *mocks.MockProfileService) {
+				m.On("GetProfile", "alice").Return(nil, errors.New("database error"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockProfileService := mocks.MockProfileService{}
+			tt.setupMocks(&mockProfileService)
+			handler := NewProfileHandler(&mockProfileService)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("username")
+			c.SetParamValues(tt.username)
+
+			err := handler.Get(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Equal(t, tt.wantMoved, strings.Contains(rec.Body.String(), `"moved":true`))
+			}
+			mockProfileService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateProfile(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	user := &data.User{ID: uuid.New(), Username: "alice", IsActivated: true}
+
+	tests := map[string]struct {
+		contextUser *data.User
+		username    string
+		reqBody     string
+		setupMocks  func(m *mocks.MockProfileService)
+		wantCode    int
+		wantError   bool
+	}{
+		"User not authenticated": {
+			username:  "alice",
+			reqBody:   `{"bio":"hi"}`,
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Profile of someone else": {
+			contextUser: user,
+			username:    "bob",
+			reqBody:     `{"bio":"hi"}`,
+			wantCode:    http.StatusForbidden,
+			wantError:   true,
+		},
+		"Invalid body": {
+			contextUser: user,
+			username:    "alice",
+			reqBody:     `{"bio":1}`,
+			wantCode:    http.StatusBadRequest,
+			wantError:   true,
+		},
+		"Bio too long": {
+			contextUser: user,
+			username:    "alice",
+			reqBody:     `{"bio":"` + strings.Repeat("a", 501) + `"}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Unknown social network": {
+			contextUser: user,
+			username:    "alice",
+			reqBody:     `{"social_links":{"myspace":"https://myspace.com/alice"}}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Script link": {
+			contextUser: user,
+			username:    "alice",
+			reqBody:     `{"social_links":{"github":"javascript:alert(1)"}}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Website not on the web": {
+			contextUser: user,
+			username:    "alice",
+			reqBody:     `{"website":"ftp://alice.example.com"}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Profile updated": {
+			contextUser: user,
+			username:    "alice",
+			reqBody:     `{"bio":"Turtle artist","website":"https://alice.example.com","social_links":{"github":"https://github.com/alice"}}`,
+			setupMocks: func(m *mocks.MockProfileService) {
+				m.On("UpdateProfile", user.ID, data.UserProfileUpdate{
+					Bio:         "Turtle artist",
+					Website:     "https://alice.example.com",
+					SocialLinks: map[string]string{"github": "https://github.com/alice"},
+				}).Return(&data.UserProfile{Username: "alice", Bio: "Turtle artist"}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Database error": {
+			contextUser: user,
+			username:    "alice",
+			reqBody:     `{}`,
+			setupMocks: func(m *mocks.MockProfileService) {
+				m.On("UpdateProfile", user.ID, data.UserProfileUpdate{}).Return(nil, errors.New("database error"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockProfileService := mocks.MockProfileService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockProfileService)
+			}
+			handler := NewProfileHandler(&mockProfileService)
+
+			req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("username")
+			c.SetParamValues(tt.username)
+
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.Update(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+			mockProfileService.AssertExpectations(t)
+		})
+	}
+}
@@ -1,12 +1,24 @@
 package handlers
 
 import (
+	"NodeTurtleAPI/internal/api/middleware"
+	"NodeTurtleAPI/internal/config"
 	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/flow"
+	"NodeTurtleAPI/internal/logging"
 	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/classrooms"
+	"NodeTurtleAPI/internal/services/notifications"
 	"NodeTurtleAPI/internal/services/projects"
+	"NodeTurtleAPI/internal/services/realtime"
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,17 +27,32 @@ import (
 
 // ProjectHandler handles HTTP requests related to project operations.
 type ProjectHandler struct {
-	projectService projects.IProjectService
+	projectService      projects.IProjectService
+	classroomService    classrooms.IClassroomService
+	realtimeService     realtime.IRealtimeService
+	notificationService notifications.INotificationService
+	limits              config.LimitsConfig
+	discover            config.DiscoverConfig
+	clientURL           string
 }
 
 // NewProjectHandler creates a new UserHandler with the provided services.
-func NewProjectHandler(projectService projects.IProjectService) ProjectHandler {
+// clientURL is the frontend origin used to build links to projects.
+func NewProjectHandler(projectService projects.IProjectService, classroomService classrooms.IClassroomService, realtimeService realtime.IRealtimeService, notificationService notifications.INotificationService, limits config.LimitsConfig, discover config.DiscoverConfig, clientURL string) ProjectHandler {
 	return ProjectHandler{
-		projectService: projectService,
+		projectService:      projectService,
+		classroomService:    classroomService,
+		realtimeService:     realtimeService,
+		notificationService: notificationService,
+		limits:              limits,
+		discover:            discover,
+		clientURL:           clientURL,
 	}
 }
 
 // Get handles the request to retrieve a single project.
+// The response carries the project's ETag, when If-None-Match has it the project is unchanged and 304 is returned without a body,
+// so editors polling the project don't download its flow again.
 func (h *ProjectHandler) Get(c echo.Context) error {
 	var userID *uuid.UUID
 
@@ -41,18 +68,124 @@ func (h *ProjectHandler) Get(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
 	}
 
-	project, err := h.projectService.GetProject(projectID, userID)
+	project, err := h.projectService.GetProject(c.Request().Context(), projectID, userID)
 	if err != nil {
+		if err == services.ErrRecordNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve project")
 	}
 
+	// creators opening their own project and probes don't count as views, a failed count doesn't fail the request
+	if (userID == nil || *userID != project.CreatorID) && !middleware.IsProbe(c) {
+		if err := h.projectService.RecordView(c.Request().Context(), projectID, userID, c.RealIP()); err != nil {
+			logging.Error(c.Request().Context(), "Internal project view error", err)
+		}
+	}
+	if userID != nil {
+		if err := h.projectService.RecordOpen(c.Request().Context(), projectID, *userID); err != nil {
+			logging.Error(c.Request().Context(), "Internal recent project error", err)
+		}
+	}
+
+	etag := project.ETag().String()
+	c.Response().Header().Set("ETag", etag)
+	if etagMatches(c.Request().Header.Get("If-None-Match"), etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"project": project,
 	})
 }
 
+// etagMatches reports whether an If-None-Match header lists the tag, or is "*".
+// Weak tags match too, as If-None-Match compares weakly.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatch parses the If-Match header of a write into the state the project has to be in.
+// It returns nil without the header or with "*", which any existing project matches.
+// Only a single strong tag can be checked along with the write, anything else fails the precondition.
+func ifMatch(c echo.Context) (*data.ProjectETag, error) {
+	header := strings.TrimSpace(c.Request().Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	etag, err := data.ParseProjectETag(header)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusPreconditionFailed, "Invalid If-Match header")
+	}
+	return etag, nil
+}
+
+// BatchGet handles the request to retrieve several projects in one go.
+// Projects the caller can't see are left out, so the response may hold fewer projects than requested.
+func (h *ProjectHandler) BatchGet(c echo.Context) error {
+	var userID *uuid.UUID
+	if user, ok := c.Get("user").(*data.User); ok {
+		userID = &user.ID
+	}
+
+	var payload data.ProjectBatchGet
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+	if err := c.Validate(payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	projects, err := h.projectService.GetProjectsByIDs(c.Request().Context(), payload.IDs, userID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal batch get projects error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve projects")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"projects": projects,
+	})
+}
+
+// Discover handles the request to retrieve a few random public projects the user hasn't seen lately,
+// for a "surprise me" button. Each request draws a new sample, the client keeps no state between them.
+func (h *ProjectHandler) Discover(c echo.Context) error {
+	opts := data.DiscoverOptions{
+		IP:       c.RealIP(),
+		MinLikes: h.discover.MinLikes,
+		SeenDays: h.discover.SeenDays,
+	}
+	if user, ok := c.Get("user").(*data.User); ok {
+		opts.UserID = &user.ID
+	}
+
+	opts.Limit, _ = strconv.Atoi(c.QueryParam("limit"))
+	if opts.Limit <= 0 {
+		opts.Limit = 1
+	}
+	opts.Limit = min(opts.Limit, 20)
+
+	projects, err := h.projectService.DiscoverProjects(c.Request().Context(), opts)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal project discovery error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve projects")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"projects": projects,
+	})
+}
+
 // GetFeatured handles the request to retrieve a list of featured projects.
-// It supports pagination through query parameters.
+// It supports pagination through query parameters, by page or with a cursor like GetPublic.
+// Like the other lists, projects come without their flow data unless requested with ?include=data.
 func (h *ProjectHandler) GetFeatured(c echo.Context) error {
 	limit, _ := strconv.Atoi(c.QueryParam("limit"))
 	page, _ := strconv.Atoi(c.QueryParam("page"))
@@ -64,7 +197,37 @@ func (h *ProjectHandler) GetFeatured(c echo.Context) error {
 		page = 1
 	}
 
-	projects, err := h.projectService.GetFeaturedProjects(limit, page)
+	if c.QueryParams().Has("cursor") {
+		cursor, err := projectCursor(c)
+		if err != nil {
+			return err
+		}
+
+		// as with public projects, at most 100 per page
+		limit = min(limit, 100)
+		getFeatured := h.projectService.GetFeaturedProjectSummariesAfter
+		if includesData(c) {
+			getFeatured = h.projectService.GetFeaturedProjectsAfter
+		}
+
+		projects, next, err := getFeatured(c.Request().Context(), limit, cursor)
+		if err != nil {
+			logging.Error(c.Request().Context(), "Internal project retrieval error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve featured projects")
+		}
+
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"projects": projects,
+			"meta":     cursorMeta(limit, next),
+		})
+	}
+
+	getFeatured := h.projectService.GetFeaturedProjectSummaries
+	if includesData(c) {
+		getFeatured = h.projectService.GetFeaturedProjects
+	}
+
+	projects, err := getFeatured(c.Request().Context(), limit, page)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve featured projects")
 	}
@@ -76,6 +239,7 @@ func (h *ProjectHandler) GetFeatured(c echo.Context) error {
 
 // Create handles the request to create a new project.
 // If no project data is provided, the handler creates it
+// With ?dry_run=true the request is fully validated and the project that would be created is returned without saving it.
 func (h *ProjectHandler) Create(c echo.Context) error {
 	contextUser, ok := c.Get("user").(*data.User)
 	if !ok {
@@ -86,11 +250,17 @@ func (h *ProjectHandler) Create(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusForbidden, "Account is not activated")
 	}
 
+	dryRun, err := parseDryRun(c)
+	if err != nil {
+		return err
+	}
+
 	var payload struct {
 		Title       string          `json:"title" validate:"required,min=3,max=100"`
 		Description string          `json:"description" validate:"max=5000"`
 		Data        json.RawMessage `json:"data,omitempty"`
 		IsPublic    bool            `json:"is_public"`
+		ClassroomID *uuid.UUID      `json:"classroom_id,omitempty"`
 	}
 
 	if err := c.Bind(&payload); err != nil {
@@ -101,6 +271,32 @@ func (h *ProjectHandler) Create(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
 	}
 
+	if err := flow.Validate("data", payload.Data, h.flowLimits()); err != nil {
+		return invalidFlow(err)
+	}
+
+	if payload.ClassroomID != nil {
+		if payload.IsPublic {
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, "Projects shared with a classroom cannot be public")
+		}
+		if err := h.checkClassroomMember(c, *payload.ClassroomID, contextUser.ID); err != nil {
+			return err
+		}
+	}
+
+	if contextUser.IsGuest() {
+		if payload.IsPublic || payload.ClassroomID != nil {
+			return guestProjectsPrivate()
+		}
+		if err := h.checkGuestProjectLimit(c, contextUser.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := h.checkTitle(c, contextUser.ID, payload.Title, nil); err != nil {
+		return err
+	}
+
 	var flowData json.RawMessage
 	if payload.Data != nil {
 		flowData = payload.Data
@@ -114,14 +310,26 @@ func (h *ProjectHandler) Create(c echo.Context) error {
 		Description: payload.Description,
 		Data:        flowData,
 		IsPublic:    payload.IsPublic,
+		ClassroomID: payload.ClassroomID,
+		DryRun:      dryRun,
 	}
 
-	project, err := h.projectService.CreateProject(p)
+	project, err := h.projectService.CreateProject(c.Request().Context(), p)
 	if err != nil {
-		c.Logger().Errorf("Internal project creation error %v", err)
+		if he := quotaExceeded(err); he != nil {
+			return he
+		}
+		logging.Error(c.Request().Context(), "Internal project creation error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create project")
 	}
 
+	if dryRun {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"dry_run": true,
+			"project": project,
+		})
+	}
+
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"project": project,
 	})
@@ -145,7 +353,7 @@ func (h *ProjectHandler) Delete(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
 	}
 
-	isOwner, err := h.projectService.IsOwner(projectID, contextUser.ID)
+	isOwner, err := h.projectService.IsOwner(c.Request().Context(), projectID, contextUser.ID)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete project")
 	}
@@ -154,7 +362,7 @@ func (h *ProjectHandler) Delete(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusForbidden, "You do not have permission to delete this project")
 	}
 
-	err = h.projectService.DeleteProject(projectID)
+	err = h.projectService.DeleteProject(c.Request().Context(), projectID)
 
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete project")
@@ -165,7 +373,11 @@ func (h *ProjectHandler) Delete(c echo.Context) error {
 
 // Update handles the request to update a project.
 // Update payload includes title, description, public status and data.
+// Editors can update the project as well, but only the owner can change its visibility.
 // If data is not provided, empty json object {} is created.
+// With ?dry_run=true the request is fully validated and the result is returned along with the changed fields, without saving it.
+// With an If-Match header holding the ETag the project was loaded with, the update is only saved if nobody edited the project since,
+// and rejected with 412 otherwise.
 func (h *ProjectHandler) Update(c echo.Context) error {
 	// user validation
 	contextUser, ok := c.Get("user").(*data.User)
@@ -184,19 +396,30 @@ func (h *ProjectHandler) Update(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
 	}
 
-	// project ownership check
-	isOwner, err := h.projectService.IsOwner(projectID, contextUser.ID)
+	// project ownership check, editors can update the project too
+	role, err := h.projectService.GetProjectRole(c.Request().Context(), projectID, contextUser.ID)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update project")
 	}
-	if !isOwner {
+	if !canEdit(role) {
 		return echo.NewHTTPError(http.StatusForbidden, "You do not have permission to update this project")
 	}
 
+	dryRun, err := parseDryRun(c)
+	if err != nil {
+		return err
+	}
+
+	etag, err := ifMatch(c)
+	if err != nil {
+		return err
+	}
+
 	var payload struct {
 		Title       *string         `json:"title,omitempty" validate:"omitempty,min=3,max=100"`
 		Description *string         `json:"description,omitempty" validate:"omitempty,max=5000"`
 		IsPublic    *bool           `json:"is_public,omitempty"`
+		ClassroomID *uuid.UUID      `json:"classroom_id,omitempty"`
 		Data        json.RawMessage `json:"data,omitempty"`
 	}
 
@@ -208,27 +431,78 @@ func (h *ProjectHandler) Update(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
 	}
 
+	if err := flow.Validate("data", payload.Data, h.flowLimits()); err != nil {
+		return invalidFlow(err)
+	}
+
+	if role != data.ProjectOwner && (payload.IsPublic != nil || payload.ClassroomID != nil) {
+		return echo.NewHTTPError(http.StatusForbidden, "Only the project owner can change who can see this project")
+	}
+
+	if contextUser.IsGuest() && ((payload.IsPublic != nil && *payload.IsPublic) || (payload.ClassroomID != nil && *payload.ClassroomID != uuid.Nil)) {
+		return guestProjectsPrivate()
+	}
+
+	// uuid.Nil stops sharing with the classroom
+	if payload.ClassroomID != nil && *payload.ClassroomID != uuid.Nil {
+		if payload.IsPublic != nil && *payload.IsPublic {
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, "Projects shared with a classroom cannot be public")
+		}
+		if err := h.checkClassroomMember(c, *payload.ClassroomID, contextUser.ID); err != nil {
+			return err
+		}
+	}
+
+	if payload.Title != nil {
+		if err := h.checkTitle(c, contextUser.ID, *payload.Title, &projectID); err != nil {
+			return err
+		}
+	}
+
 	updates := data.ProjectUpdate{
 		ID:          projectID,
 		Title:       payload.Title,
 		Description: payload.Description,
 		IsPublic:    payload.IsPublic,
+		ClassroomID: payload.ClassroomID,
 		Data:        payload.Data,
+		DryRun:      dryRun,
+		IfMatch:     etag,
+	}
+
+	if dryRun {
+		return h.previewUpdate(c, updates, contextUser.ID)
 	}
 
-	updatedProject, err := h.projectService.UpdateProject(updates)
+	updatedProject, err := h.projectService.UpdateProject(c.Request().Context(), updates)
 	if err != nil {
+		if err == services.ErrEditConflict {
+			return editedMeanwhile()
+		}
+		if he := quotaExceeded(err); he != nil {
+			return he
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update project")
 	}
 
+	c.Response().Header().Set("ETag", updatedProject.ETag().String())
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"project": updatedProject,
 	})
 }
 
-// Like handles the request to like a project.
-func (h *ProjectHandler) Like(c echo.Context) error {
-	// user validation
+// editedMeanwhile is the response to an update whose If-Match no longer matches the project.
+func editedMeanwhile() *echo.HTTPError {
+	return echo.NewHTTPError(http.StatusPreconditionFailed, "Project was changed in the meantime")
+}
+
+// editorSessionHeader identifies the editor tab an autosave comes from, so conflicting saves can be told apart.
+const editorSessionHeader = "X-Editor-Session"
+
+// SaveData handles the editor's autosave of a project's flow, by the owner or an editor.
+// The write only succeeds if the project is still at the version the editor loaded, so concurrent tabs don't overwrite each other.
+// A stale write is rejected with 409 and the current version, and the open sessions of the user are told who saved last.
+func (h *ProjectHandler) SaveData(c echo.Context) error {
 	contextUser, ok := c.Get("user").(*data.User)
 	if !ok {
 		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
@@ -238,105 +512,214 @@ func (h *ProjectHandler) Like(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusForbidden, "Account is not activated")
 	}
 
-	// param validation
-	idStr := c.Param("id")
-	projectID, err := uuid.Parse(idStr)
+	projectID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
 	}
 
-	// project ownership check, owners cannot like their own projects
-	isOwner, err := h.projectService.IsOwner(projectID, contextUser.ID)
+	role, err := h.projectService.GetProjectRole(c.Request().Context(), projectID, contextUser.ID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to like a project")
+		logging.Error(c.Request().Context(), "Internal project ownership check error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to save project")
 	}
-	if isOwner {
-		return echo.NewHTTPError(http.StatusForbidden, "Project owners cannot like their own projects")
+	if !canEdit(role) {
+		return echo.NewHTTPError(http.StatusForbidden, "You do not have permission to update this project")
+	}
+
+	var payload struct {
+		Data    json.RawMessage `json:"data" validate:"required"`
+		Version int             `json:"version" validate:"required,min=1"`
+	}
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
 	}
 
-	err = h.projectService.LikeProject(projectID, contextUser.ID)
+	if err := flow.Validate("data", payload.Data, h.flowLimits()); err != nil {
+		return invalidFlow(err)
+	}
+
+	session := c.Request().Header.Get(editorSessionHeader)
+	if len(session) > 64 {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid editor session")
+	}
 
+	version, err := h.projectService.SaveProjectData(c.Request().Context(), projectID, payload.Data, payload.Version, contextUser.ID, session)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to like a project")
+		switch {
+		case err == services.ErrEditConflict:
+			h.notifyEditConflict(c, contextUser.ID, data.EditConflict{
+				ProjectID:       projectID,
+				Version:         version,
+				RejectedVersion: payload.Version,
+				Session:         session,
+			})
+			return echo.NewHTTPError(http.StatusConflict, map[string]interface{}{
+				"message": "Project was changed in the meantime",
+				"version": version,
+			})
+		case err == services.ErrRecordNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		if he := quotaExceeded(err); he != nil {
+			return he
+		}
+		logging.Error(c.Request().Context(), "Internal project autosave error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to save project")
 	}
 
-	return c.NoContent(http.StatusCreated)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"version": version,
+	})
 }
 
-func (h *ProjectHandler) Unlike(c echo.Context) error {
-	// user validation
-	contextUser, ok := c.Get("user").(*data.User)
-	if !ok {
-		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+// notifyEditConflict tells every open session of the user that an autosave was rejected and who saved last,
+// so the editor can prompt to merge. A failed notification doesn't change the response.
+func (h *ProjectHandler) notifyEditConflict(c echo.Context, userID uuid.UUID, conflict data.EditConflict) {
+	ctx := c.Request().Context()
+
+	save, err := h.projectService.GetLastSave(ctx, conflict.ProjectID)
+	switch {
+	case err == nil:
+		conflict.SavedBy = save
+	case err != services.ErrRecordNotFound:
+		logging.Error(c.Request().Context(), "Internal project last save error", err)
 	}
 
-	if !contextUser.IsActivated {
-		return echo.NewHTTPError(http.StatusForbidden, "Account is not activated")
+	if err := h.realtimeService.Publish(ctx, userID, data.EventEditConflict, conflict); err != nil {
+		logging.Error(c.Request().Context(), "Internal realtime publish error", err)
 	}
+}
 
-	// param validation
-	idStr := c.Param("id")
-	projectID, err := uuid.Parse(idStr)
+// previewUpdate responds with the project as it would be after the update and the fields that would change,
+// without persisting anything.
+func (h *ProjectHandler) previewUpdate(c echo.Context, updates data.ProjectUpdate, userID uuid.UUID) error {
+	current, err := h.projectService.GetProject(c.Request().Context(), updates.ID, &userID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+		if err == services.ErrRecordNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		logging.Error(c.Request().Context(), "Internal project retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update project")
 	}
 
-	// project ownership check, owners cannot like and unlike their own projects
-	isOwner, err := h.projectService.IsOwner(projectID, contextUser.ID)
+	preview, err := h.projectService.UpdateProject(c.Request().Context(), updates)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to unlike a project")
+		if err == services.ErrNoFields {
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, "No fields to update")
+		}
+		if err == services.ErrEditConflict {
+			return editedMeanwhile()
+		}
+		if he := quotaExceeded(err); he != nil {
+			return he
+		}
+		logging.Error(c.Request().Context(), "Internal project dry run error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update project")
 	}
-	if isOwner {
-		return echo.NewHTTPError(http.StatusForbidden, "Project owners cannot unlike their own projects")
+
+	changes := make([]string, 0)
+	if preview.Title != current.Title {
+		changes = append(changes, "title")
+	}
+	if preview.Description != current.Description {
+		changes = append(changes, "description")
 	}
+	if preview.IsPublic != current.IsPublic {
+		changes = append(changes, "is_public")
+	}
+	if !equalIDs(preview.ClassroomID, current.ClassroomID) {
+		changes = append(changes, "classroom_id")
+	}
+	if !bytes.Equal(preview.Data, current.Data) {
+		changes = append(changes, "data")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"dry_run": true,
+		"project": preview,
+		"changes": changes,
+	})
+}
 
-	err = h.projectService.UnlikeProject(projectID, contextUser.ID)
+// parseDryRun reads the dry_run query parameter. Dry runs validate the request and report the outcome without persisting it.
+func parseDryRun(c echo.Context) (bool, error) {
+	value := c.QueryParam("dry_run")
+	if value == "" {
+		return false, nil
+	}
 
+	dryRun, err := strconv.ParseBool(value)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to unlike a project")
+		return false, echo.NewHTTPError(http.StatusBadRequest, "Invalid dry_run value")
 	}
+	return dryRun, nil
+}
 
-	return c.NoContent(http.StatusNoContent)
+func equalIDs(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
 }
 
-func (h *ProjectHandler) GetUserProjects(c echo.Context) error {
-	// user validation
+// checkClassroomMember ensures projects are only shared with classrooms the user is on the roster of.
+func (h *ProjectHandler) checkClassroomMember(c echo.Context, classroomID, userID uuid.UUID) error {
+	isMember, err := h.classroomService.IsMember(c.Request().Context(), classroomID, userID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to verify classroom membership")
+	}
+	if !isMember {
+		return echo.NewHTTPError(http.StatusForbidden, "You are not a member of this classroom")
+	}
+	return nil
+}
+
+// canEdit reports whether the role allows changing a project.
+func canEdit(role data.ProjectRole) bool {
+	return role == data.ProjectOwner || role == data.ProjectEditor
+}
+
+// GetMembers handles the request to list the users a project is shared with.
+// Only the owner and the members themselves can see them.
+func (h *ProjectHandler) GetMembers(c echo.Context) error {
 	contextUser, ok := c.Get("user").(*data.User)
 	if !ok {
 		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
 	}
 
-	if !contextUser.IsActivated {
-		return echo.NewHTTPError(http.StatusForbidden, "Account is not activated")
-	}
-
-	// param validation
-	idStr := c.Param("id")
-	userID, err := uuid.Parse(idStr)
+	projectID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID")
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
 	}
 
-	projects, err := h.projectService.GetUserProjects(userID, contextUser.ID)
+	role, err := h.projectService.GetProjectRole(c.Request().Context(), projectID, contextUser.ID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to get user projects")
+		logging.Error(c.Request().Context(), "Internal project ownership check error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve project members")
 	}
-
-	response := map[string]interface{}{
-		"projects": projects,
+	if role == "" {
+		return echo.NewHTTPError(http.StatusForbidden, "You do not have permission to view the members of this project")
 	}
 
-	if len(projects) > 0 {
-		response["meta"] = map[string]interface{}{
-			"username": projects[0].CreatorUsername,
-		}
+	members, err := h.projectService.GetMembers(c.Request().Context(), projectID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal project member retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve project members")
 	}
 
-	return c.JSON(http.StatusOK, response)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"members": members,
+	})
 }
 
-func (h *ProjectHandler) GetLikedProjects(c echo.Context) error {
-	// user validation
+// AddMember handles the request to share a project with a user as a viewer or editor.
+// Inviting a user who already is a member changes their role. Only the owner can share the project.
+func (h *ProjectHandler) AddMember(c echo.Context) error {
 	contextUser, ok := c.Get("user").(*data.User)
 	if !ok {
 		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
@@ -346,80 +729,932 @@ func (h *ProjectHandler) GetLikedProjects(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusForbidden, "Account is not activated")
 	}
 
-	// param validation
-	idStr := c.Param("id")
-	userID, err := uuid.Parse(idStr)
+	projectID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID")
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
 	}
 
-	projects, err := h.projectService.GetLikedProjects(userID)
+	isOwner, err := h.projectService.IsOwner(c.Request().Context(), projectID, contextUser.ID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to get liked projects")
+		logging.Error(c.Request().Context(), "Internal project ownership check error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to add project member")
+	}
+	if !isOwner {
+		return echo.NewHTTPError(http.StatusForbidden, "You do not have permission to share this project")
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"projects": projects,
-	})
-}
-
-// GetPublic handles the request to retrieve a paginated and filtered list of public projects.
-func (h *ProjectHandler) GetPublic(c echo.Context) error {
-	filters := data.DefaultPublicProjectFilter()
-
-	if err := c.Bind(&filters); err != nil {
+	var payload data.ProjectMemberInvite
+	if err := c.Bind(&payload); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
 	}
 
-	if err := c.Validate(&filters); err != nil {
+	if err := c.Validate(&payload); err != nil {
 		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
 	}
 
-	projects, total, err := h.projectService.GetPublicProjects(filters)
+	member, err := h.projectService.AddMember(c.Request().Context(), projectID, payload)
 	if err != nil {
-		c.Logger().Errorf("Internal project retrieval error %v", err)
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve public projects")
+		if err == services.ErrRecordNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "User not found")
+		}
+		logging.Error(c.Request().Context(), "Internal project member creation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to add project member")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"projects": projects,
-		"meta": map[string]interface{}{
-			"total": total,
-			"page":  filters.Page,
-			"limit": filters.Limit,
-		},
+		"member": member,
 	})
 }
 
-// List handles the request to retrieve a paginated list of all projects.
-// binds payload to data.PublicProjectFilter for filtering options
-func (h *ProjectHandler) List(c echo.Context) error {
-	filters := data.DefaultProjectFilter()
-
-	if err := c.Bind(&filters); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+// RemoveMember handles the request to stop sharing a project with a user.
+// The owner can remove anyone, members can only leave.
+func (h *ProjectHandler) RemoveMember(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
 	}
 
-	if err := c.Validate(&filters); err != nil {
-		c.Logger().Errorf("Filter validation error: %v", err)
-		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
 	}
 
-	projects, total, err := h.projectService.ListProjects(filters)
+	userID, err := uuid.Parse(c.Param("userID"))
 	if err != nil {
-		c.Logger().Errorf("Internal project retrieval error %v", err)
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve projects")
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID")
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"projects": projects,
-		"meta": map[string]interface{}{
+	if userID != contextUser.ID {
+		isOwner, err := h.projectService.IsOwner(c.Request().Context(), projectID, contextUser.ID)
+		if err != nil {
+			logging.Error(c.Request().Context(), "Internal project ownership check error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to remove project member")
+		}
+		if !isOwner {
+			return echo.NewHTTPError(http.StatusForbidden, "You do not have permission to manage the members of this project")
+		}
+	}
+
+	if err := h.projectService.RemoveMember(c.Request().Context(), projectID, userID); err != nil {
+		if err == services.ErrRecordNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "Member not found")
+		}
+		logging.Error(c.Request().Context(), "Internal project member deletion error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to remove project member")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetLikers handles the request to retrieve a paginated list of the users who liked a project the requester can view.
+func (h *ProjectHandler) GetLikers(c echo.Context) error {
+	var userID *uuid.UUID
+
+	if contextUser := c.Get("user"); contextUser != nil {
+		if user, ok := contextUser.(*data.User); ok {
+			userID = &user.ID
+		}
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if page <= 0 {
+		page = 1
+	}
+
+	if _, err := h.projectService.GetProject(c.Request().Context(), projectID, userID); err != nil {
+		if err == services.ErrRecordNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		logging.Error(c.Request().Context(), "Internal project retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve likers")
+	}
+
+	likers, total, err := h.projectService.GetLikers(c.Request().Context(), projectID, page, limit)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal liker retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve likers")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"likers": likers,
+		"meta": map[string]interface{}{
+			"total": total,
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
+
+// Like handles the request to like a project.
+func (h *ProjectHandler) Like(c echo.Context) error {
+	// user validation
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	if !contextUser.IsActivated {
+		return echo.NewHTTPError(http.StatusForbidden, "Account is not activated")
+	}
+
+	// param validation
+	idStr := c.Param("id")
+	projectID, err := uuid.Parse(idStr)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	// project ownership check, owners cannot like their own projects
+	isOwner, err := h.projectService.IsOwner(c.Request().Context(), projectID, contextUser.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to like a project")
+	}
+	if isOwner {
+		return echo.NewHTTPError(http.StatusForbidden, "Project owners cannot like their own projects")
+	}
+
+	err = h.projectService.LikeProject(c.Request().Context(), projectID, contextUser.ID)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to like a project")
+	}
+
+	h.notifyLike(c, projectID, contextUser.ID)
+
+	return c.NoContent(http.StatusCreated)
+}
+
+// notifyLike enqueues a notification to the creator of a liked project and pushes it to their open sessions.
+// A failed notification doesn't change the response, the like is in already.
+func (h *ProjectHandler) notifyLike(c echo.Context, projectID, userID uuid.UUID) {
+	ctx := c.Request().Context()
+
+	notification, err := h.notificationService.NotifyLike(ctx, projectID, userID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal like notification error", err)
+		return
+	}
+	if notification == nil {
+		return
+	}
+
+	if err := h.realtimeService.Publish(ctx, notification.UserID, data.EventNotification, notification); err != nil {
+		logging.Error(c.Request().Context(), "Internal realtime publish error", err)
+	}
+}
+
+func (h *ProjectHandler) Unlike(c echo.Context) error {
+	// user validation
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	if !contextUser.IsActivated {
+		return echo.NewHTTPError(http.StatusForbidden, "Account is not activated")
+	}
+
+	// param validation
+	idStr := c.Param("id")
+	projectID, err := uuid.Parse(idStr)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	// project ownership check, owners cannot like and unlike their own projects
+	isOwner, err := h.projectService.IsOwner(c.Request().Context(), projectID, contextUser.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to unlike a project")
+	}
+	if isOwner {
+		return echo.NewHTTPError(http.StatusForbidden, "Project owners cannot unlike their own projects")
+	}
+
+	err = h.projectService.UnlikeProject(c.Request().Context(), projectID, contextUser.ID)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to unlike a project")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetUserProjects handles the request to list the projects of a user, without their flow data unless requested with ?include=data.
+// The list can be narrowed down to one of the user's folders with ?folder.
+func (h *ProjectHandler) GetUserProjects(c echo.Context) error {
+	// guests are allowed, they only see public projects
+	var requestingUserID *uuid.UUID
+	if contextUser, ok := c.Get("user").(*data.User); ok {
+		if !contextUser.IsActivated {
+			return echo.NewHTTPError(http.StatusForbidden, "Account is not activated")
+		}
+		requestingUserID = &contextUser.ID
+	}
+
+	// param validation
+	idStr := c.Param("id")
+	userID, err := uuid.Parse(idStr)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID")
+	}
+
+	// ?folder=<id> lists the projects in a folder, ?folder=none those in no folder
+	var folder *data.FolderFilter
+	switch param := c.QueryParam("folder"); param {
+	case "":
+	case "none":
+		folder = &data.FolderFilter{}
+	default:
+		folderID, err := uuid.Parse(param)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid folder ID")
+		}
+		folder = &data.FolderFilter{FolderID: &folderID}
+	}
+
+	getUserProjects := h.projectService.GetUserProjectSummaries
+	if includesData(c) {
+		getUserProjects = h.projectService.GetUserProjects
+	}
+
+	projects, err := getUserProjects(c.Request().Context(), userID, requestingUserID, folder)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to get user projects")
+	}
+
+	response := map[string]interface{}{
+		"projects": projects,
+	}
+
+	if len(projects) > 0 {
+		response["meta"] = map[string]interface{}{
+			"username": projects[0].CreatorUsername,
+		}
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetContributedProjects handles the request to list the projects a user accepted a co-author credit on.
+// Guests are allowed, they only see public projects.
+func (h *ProjectHandler) GetContributedProjects(c echo.Context) error {
+	var requestingUserID *uuid.UUID
+	if contextUser, ok := c.Get("user").(*data.User); ok {
+		if !contextUser.IsActivated {
+			return echo.NewHTTPError(http.StatusForbidden, "Account is not activated")
+		}
+		requestingUserID = &contextUser.ID
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID")
+	}
+
+	projects, err := h.projectService.GetContributedProjects(c.Request().Context(), userID, requestingUserID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal contributed projects retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to get contributed projects")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"projects": projects,
+	})
+}
+
+func (h *ProjectHandler) GetLikedProjects(c echo.Context) error {
+	// guests are allowed, only public projects are listed
+	if contextUser, ok := c.Get("user").(*data.User); ok && !contextUser.IsActivated {
+		return echo.NewHTTPError(http.StatusForbidden, "Account is not activated")
+	}
+
+	// param validation
+	idStr := c.Param("id")
+	userID, err := uuid.Parse(idStr)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID")
+	}
+
+	projects, err := h.projectService.GetLikedProjects(c.Request().Context(), userID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to get liked projects")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"projects": projects,
+	})
+}
+
+// GetRecentProjects handles the request to list the projects the user opened last, so the editor can offer
+// to continue where they left off on any device.
+func (h *ProjectHandler) GetRecentProjects(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 || limit > projects.RecentProjectsKept {
+		limit = 10
+	}
+
+	recent, err := h.projectService.GetRecentProjects(c.Request().Context(), contextUser.ID, limit)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal recent projects retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve recent projects")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"projects": recent,
+	})
+}
+
+// likedExportFormats are the formats liked projects are exported in, the default first.
+var likedExportFormats = []string{"json", "opml"}
+
+// ExportLikedProjects handles the request to download the projects the current user liked as a portable list of links,
+// either as JSON (default) or as an OPML outline (?format=opml) that bookmark and feed tools can import.
+func (h *ProjectHandler) ExportLikedProjects(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = likedExportFormats[0]
+	}
+	if !slices.Contains(likedExportFormats, format) {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid export format, use json or opml")
+	}
+
+	projects, err := h.projectService.GetLikedProjects(c.Request().Context(), contextUser.ID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal liked projects retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to export liked projects")
+	}
+
+	links := make([]data.ProjectLink, 0, len(projects))
+	for _, p := range projects {
+		links = append(links, data.ProjectLink{
+			Title:   p.Title,
+			Creator: p.CreatorUsername,
+			URL:     fmt.Sprintf("%s/projects/%s", h.clientURL, p.ID),
+		})
+	}
+
+	exportedAt := time.Now().UTC()
+	filename := "liked-projects." + format
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+
+	if format == "opml" {
+		return writeOPML(c, fmt.Sprintf("Projects liked by %s", contextUser.Username), exportedAt, links)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"exported_at": exportedAt,
+		"projects":    links,
+	})
+}
+
+// Fork handles the request to copy a visible project into a new private project owned by the current user.
+// Users are limited in how many forks they can create per hour and per day to prevent mass-forking.
+func (h *ProjectHandler) Fork(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	if !contextUser.IsActivated {
+		return echo.NewHTTPError(http.StatusForbidden, "Account is not activated")
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	if err := h.checkForkLimits(c, contextUser.ID); err != nil {
+		return err
+	}
+
+	if _, err := h.projectService.GetProject(c.Request().Context(), projectID, &contextUser.ID); err != nil {
+		if err == services.ErrRecordNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		logging.Error(c.Request().Context(), "Internal project retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fork project")
+	}
+
+	project, err := h.projectService.ForkProject(c.Request().Context(), projectID, contextUser.ID)
+	if err != nil {
+		if err == services.ErrRecordNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		if he := quotaExceeded(err); he != nil {
+			return he
+		}
+		logging.Error(c.Request().Context(), "Internal project fork error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fork project")
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"project": project,
+	})
+}
+
+// checkForkLimits returns a 429 error if the user has reached the hourly or daily fork limit.
+func (h *ProjectHandler) checkForkLimits(c echo.Context, userID uuid.UUID) error {
+	windows := []struct {
+		limit  int
+		period time.Duration
+	}{
+		{h.limits.ForksPerHour, time.Hour},
+		{h.limits.ForksPerDay, 24 * time.Hour},
+	}
+
+	for _, w := range windows {
+		if w.limit <= 0 {
+			continue
+		}
+
+		count, err := h.projectService.CountUserForks(c.Request().Context(), userID, time.Now().Add(-w.period))
+		if err != nil {
+			logging.Error(c.Request().Context(), "Internal fork count error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fork project")
+		}
+		if count >= w.limit {
+			return echo.NewHTTPError(http.StatusTooManyRequests, "Fork limit reached, try again later")
+		}
+	}
+
+	return nil
+}
+
+// checkGuestProjectLimit returns a 403 error if the guest holds as many projects as guests are allowed to.
+func (h *ProjectHandler) checkGuestProjectLimit(c echo.Context, userID uuid.UUID) error {
+	if h.limits.GuestProjects <= 0 {
+		return nil
+	}
+
+	count, err := h.projectService.CountUserProjects(c.Request().Context(), userID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal project count error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create project")
+	}
+	if count >= h.limits.GuestProjects {
+		return echo.NewHTTPError(http.StatusForbidden, "GUEST_PROJECT_LIMIT")
+	}
+
+	return nil
+}
+
+// checkTitle returns a 409 error with free titles to pick instead if unique titles are enforced and the creator
+// already gave another of their projects the title. projectID is set for an existing project.
+func (h *ProjectHandler) checkTitle(c echo.Context, creatorID uuid.UUID, title string, projectID *uuid.UUID) error {
+	if !h.limits.UniqueTitles {
+		return nil
+	}
+
+	conflict, err := h.projectService.CheckTitle(c.Request().Context(), creatorID, title, projectID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal project title check error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to check project title")
+	}
+	if conflict != nil {
+		return echo.NewHTTPError(http.StatusConflict, map[string]interface{}{
+			"code":     "TITLE_TAKEN",
+			"message":  "You already have a project with this title",
+			"conflict": conflict,
+		})
+	}
+
+	return nil
+}
+
+// quotaExceeded reports which quota of the user's role a change would exceed, or returns nil for other errors.
+func quotaExceeded(err error) *echo.HTTPError {
+	var quotaErr *services.QuotaError
+	if !errors.As(err, &quotaErr) {
+		return nil
+	}
+	return echo.NewHTTPError(http.StatusForbidden, map[string]interface{}{
+		"message": "Quota exceeded",
+		"quota":   quotaErr,
+	})
+}
+
+// Publish handles the request of a project owner to publish the current version of the project.
+// Embeds show and forks copy the published version, so the owner can keep editing without changing them until publishing again.
+// Metadata the owner staged in a draft goes live together with the published version.
+// Projects are published on their own when they're made public or featured for the first time.
+func (h *ProjectHandler) Publish(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	isOwner, err := h.projectService.IsOwner(c.Request().Context(), projectID, contextUser.ID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal project ownership check error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to publish project")
+	}
+	if !isOwner {
+		return echo.NewHTTPError(http.StatusForbidden, "You do not have permission to publish this project")
+	}
+
+	snapshot, err := h.projectService.PublishProject(c.Request().Context(), projectID)
+	if err != nil {
+		if err == services.ErrRecordNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		logging.Error(c.Request().Context(), "Internal project publish error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to publish project")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"snapshot": snapshot,
+	})
+}
+
+// GetMetadataDraft handles the request of a project owner to retrieve the title, description and tags staged for the next publish.
+func (h *ProjectHandler) GetMetadataDraft(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	if err := h.checkOwner(c, projectID, contextUser.ID, "retrieve the metadata draft of"); err != nil {
+		return err
+	}
+
+	draft, err := h.projectService.GetMetadataDraft(c.Request().Context(), projectID)
+	if err != nil {
+		if err == services.ErrRecordNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "No metadata draft")
+		}
+		logging.Error(c.Request().Context(), "Internal metadata draft retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve metadata draft")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"draft": draft,
+	})
+}
+
+// SaveMetadataDraft handles the request of a project owner to stage changes of the title, description or tags.
+// The project keeps its metadata until the owner publishes it.
+func (h *ProjectHandler) SaveMetadataDraft(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	var payload data.ProjectMetadataDraftUpdate
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := h.checkOwner(c, projectID, contextUser.ID, "edit the metadata draft of"); err != nil {
+		return err
+	}
+
+	if payload.Title != nil {
+		if err := h.checkTitle(c, contextUser.ID, *payload.Title, &projectID); err != nil {
+			return err
+		}
+	}
+
+	draft, err := h.projectService.SaveMetadataDraft(c.Request().Context(), projectID, payload)
+	if err != nil {
+		if err == services.ErrRecordNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		logging.Error(c.Request().Context(), "Internal metadata draft save error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to save metadata draft")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"draft": draft,
+	})
+}
+
+// DiscardMetadataDraft handles the request of a project owner to drop the metadata staged for the next publish.
+func (h *ProjectHandler) DiscardMetadataDraft(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	if err := h.checkOwner(c, projectID, contextUser.ID, "discard the metadata draft of"); err != nil {
+		return err
+	}
+
+	if err := h.projectService.DiscardMetadataDraft(c.Request().Context(), projectID); err != nil {
+		if err == services.ErrRecordNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "No metadata draft")
+		}
+		logging.Error(c.Request().Context(), "Internal metadata draft discard error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to discard metadata draft")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SetAccessibility handles the request of a project owner to describe the thumbnail and the output of the project for screen readers.
+// The descriptions are returned with the project, in lists and embeds alike.
+func (h *ProjectHandler) SetAccessibility(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	var payload data.ProjectAccessibilityUpdate
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := h.checkOwner(c, projectID, contextUser.ID, "describe"); err != nil {
+		return err
+	}
+
+	accessibility, err := h.projectService.SetAccessibility(c.Request().Context(), projectID, payload)
+	if err != nil {
+		if err == services.ErrRecordNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		logging.Error(c.Request().Context(), "Internal project accessibility update error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update accessibility descriptions")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"accessibility": accessibility,
+	})
+}
+
+// checkOwner returns a 403 error if the user doesn't own the project. The action completes the message of the error.
+func (h *ProjectHandler) checkOwner(c echo.Context, projectID, userID uuid.UUID, action string) error {
+	isOwner, err := h.projectService.IsOwner(c.Request().Context(), projectID, userID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal project ownership check error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to check project ownership")
+	}
+	if !isOwner {
+		return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("You do not have permission to %s this project", action))
+	}
+	return nil
+}
+
+// GetQuota handles the request to retrieve the quota of the authenticated user's role and how much of it they use.
+func (h *ProjectHandler) GetQuota(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	quota, err := h.projectService.GetQuota(c.Request().Context(), contextUser.ID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal quota retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve quota")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"quota": quota,
+	})
+}
+
+// guestProjectsPrivate is the error returned when a guest tries to share a project, guest projects stay private until claimed.
+func guestProjectsPrivate() error {
+	return echo.NewHTTPError(http.StatusForbidden, "Register to share projects")
+}
+
+// GetForks handles the request to retrieve the direct forks of a project.
+// Only forks visible to the requester are listed. It supports pagination through query parameters.
+func (h *ProjectHandler) GetForks(c echo.Context) error {
+	var userID *uuid.UUID
+
+	if contextUser := c.Get("user"); contextUser != nil {
+		if user, ok := contextUser.(*data.User); ok {
+			userID = &user.ID
+		}
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+	if page <= 0 {
+		page = 1
+	}
+
+	if _, err := h.projectService.GetProject(c.Request().Context(), projectID, userID); err != nil {
+		if err == services.ErrRecordNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		logging.Error(c.Request().Context(), "Internal project retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve forks")
+	}
+
+	forks, total, err := h.projectService.GetForks(c.Request().Context(), projectID, userID, page, limit)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal fork retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve forks")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"projects": forks,
+		"meta": map[string]interface{}{
+			"total": total,
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
+
+// GetPublic handles the request to retrieve a paginated and filtered list of public projects.
+//
+// Pages are selected by page and limit, or with a cursor when the cursor parameter is present: an empty cursor
+// starts at the first page, and each page returns the cursor of the next one in its meta, null after the last page.
+// Cursor pages don't shift while projects are published and don't slow down further in, but are only sorted by creation time.
+// Projects come without their flow data unless requested with ?include=data.
+func (h *ProjectHandler) GetPublic(c echo.Context) error {
+	filters := data.DefaultPublicProjectFilter()
+
+	if err := c.Bind(&filters); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&filters); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if c.QueryParams().Has("cursor") {
+		if filters.SortField != "created_at" {
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, "Cursor pagination is only available when sorting by created_at")
+		}
+
+		cursor, err := projectCursor(c)
+		if err != nil {
+			return err
+		}
+
+		getPublic := h.projectService.GetPublicProjectSummariesAfter
+		if includesData(c) {
+			getPublic = h.projectService.GetPublicProjectsAfter
+		}
+
+		projects, next, err := getPublic(c.Request().Context(), filters, cursor)
+		if err != nil {
+			logging.Error(c.Request().Context(), "Internal project retrieval error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve public projects")
+		}
+
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"projects": projects,
+			"meta":     cursorMeta(filters.Limit, next),
+		})
+	}
+
+	getPublic := h.projectService.GetPublicProjectSummaries
+	if includesData(c) {
+		getPublic = h.projectService.GetPublicProjects
+	}
+
+	projects, total, err := getPublic(c.Request().Context(), filters)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal project retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve public projects")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"projects": projects,
+		"meta": map[string]interface{}{
+			"total": total,
+			"page":  filters.Page,
+			"limit": filters.Limit,
+		},
+	})
+}
+
+// includesData reports whether the include query parameter, a comma separated list, asks for the flow data of listed projects.
+func includesData(c echo.Context) bool {
+	for _, field := range strings.Split(c.QueryParam("include"), ",") {
+		if strings.TrimSpace(field) == "data" {
+			return true
+		}
+	}
+	return false
+}
+
+// projectCursor decodes the cursor query parameter, an empty cursor starts at the first page.
+func projectCursor(c echo.Context) (*data.ProjectCursor, error) {
+	encoded := c.QueryParam("cursor")
+	if encoded == "" {
+		return nil, nil
+	}
+
+	cursor, err := data.DecodeProjectCursor(encoded)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid cursor")
+	}
+	return cursor, nil
+}
+
+// cursorMeta describes a cursor page, next_cursor is null on the last page.
+func cursorMeta(limit int, next *data.ProjectCursor) map[string]interface{} {
+	var nextCursor *string
+	if next != nil {
+		encoded := next.Encode()
+		nextCursor = &encoded
+	}
+
+	return map[string]interface{}{
+		"limit":       limit,
+		"next_cursor": nextCursor,
+	}
+}
+
+// List handles the request to retrieve a paginated list of all projects.
+// binds payload to data.PublicProjectFilter for filtering options
+func (h *ProjectHandler) List(c echo.Context) error {
+	filters := data.DefaultProjectFilter()
+
+	if err := c.Bind(&filters); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&filters); err != nil {
+		logging.Error(c.Request().Context(), "Filter validation error", err)
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	projects, total, err := h.projectService.ListProjects(c.Request().Context(), filters)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal project retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve projects")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"projects": projects,
+		"meta": map[string]interface{}{
 			"total": total,
 			"page":  filters.Page,
 		},
 	})
 }
 
+// Feature handles the legacy request to feature a project for a number of hours, or unfeature it without a duration.
+// It isn't recorded in the audit log; the featured endpoints of the FeaturedHandler are.
 func (h *ProjectHandler) Feature(c echo.Context) error {
 	idStr := c.Param("id")
 	projectID, err := uuid.Parse(idStr)
@@ -450,7 +1685,7 @@ func (h *ProjectHandler) Feature(c echo.Context) error {
 		featuredUntil = &t
 	}
 
-	project, err := h.projectService.FeatureProject(projectID, featuredUntil)
+	project, err := h.projectService.FeatureProject(c.Request().Context(), projectID, featuredUntil)
 	if err != nil {
 		if err == services.ErrProjectNotFound {
 			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
@@ -462,3 +1697,20 @@ func (h *ProjectHandler) Feature(c echo.Context) error {
 		"project": project,
 	})
 }
+
+// flowLimits returns the configured bounds on the size of project flows.
+func (h *ProjectHandler) flowLimits() flow.Limits {
+	return flow.Limits{
+		MaxNodes: h.limits.FlowNodes,
+		MaxBytes: h.limits.FlowBytes,
+	}
+}
+
+// invalidFlow reports flow validation errors along with the location of each problem in the payload,
+// so the editor can highlight the offending nodes.
+func invalidFlow(err error) *echo.HTTPError {
+	return echo.NewHTTPError(http.StatusUnprocessableEntity, map[string]interface{}{
+		"message": "Invalid flow data",
+		"errors":  err,
+	})
+}
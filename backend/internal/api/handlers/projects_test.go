@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"NodeTurtleAPI/internal/config"
 	"NodeTurtleAPI/internal/data"
 	"NodeTurtleAPI/internal/mocks"
 	"NodeTurtleAPI/internal/services"
 	"NodeTurtleAPI/internal/utils"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -53,7 +55,13 @@ func TestCreateProject(t *testing.T) {
 		LastEditedAt:    time.Now(),
 	}
 
-	handler := NewProjectHandler(&mockProjectService)
+	mockClassroomService := mocks.MockClassroomService{}
+	handler := NewProjectHandler(&mockProjectService, &mockClassroomService, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, config.DiscoverConfig{}, "")
+
+	classroomID := uuid.New()
+	otherClassroomID := uuid.New()
+	mockClassroomService.On("IsMember", classroomID, validUser.ID).Return(true, nil)
+	mockClassroomService.On("IsMember", otherClassroomID, validUser.ID).Return(false, nil)
 
 	tests := map[string]struct {
 		contextUser *data.User
@@ -97,6 +105,48 @@ func TestCreateProject(t *testing.T) {
 			wantCode:    http.StatusUnprocessableEntity,
 			wantError:   true,
 		},
+		"Invalid flow data": {
+			contextUser: validUser,
+			requestBody: `{"title":"Test Project","is_public":true,"data":{"nodes":[{"id":"1","type":"rotateNode","position":{"x":0,"y":0},"data":{"angle":"left"}}],"edges":[]}}`,
+			setupMocks:  func() {},
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Classroom project cannot be public": {
+			contextUser: validUser,
+			requestBody: `{"title":"Test Project","is_public":true,"classroom_id":"` + classroomID.String() + `"}`,
+			setupMocks:  func() {},
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Not a classroom member": {
+			contextUser: validUser,
+			requestBody: `{"title":"Test Project","is_public":false,"classroom_id":"` + otherClassroomID.String() + `"}`,
+			setupMocks:  func() {},
+			wantCode:    http.StatusForbidden,
+			wantError:   true,
+		},
+		"Successful classroom creation": {
+			contextUser: validUser,
+			requestBody: `{"title":"Test Project","is_public":false,"classroom_id":"` + classroomID.String() + `"}`,
+			setupMocks: func() {
+				mockProjectService.On("CreateProject", mock.MatchedBy(func(p data.ProjectCreate) bool {
+					return p.ClassroomID != nil && *p.ClassroomID == classroomID && !p.IsPublic
+				})).Return(expectedProject, nil)
+			},
+			wantCode:  http.StatusOK,
+			wantError: false,
+		},
+		"Project quota exceeded": {
+			contextUser: validUser,
+			requestBody: `{"title":"Test Project","description":"Test Description","is_public":true}`,
+			setupMocks: func() {
+				mockProjectService.On("CreateProject", mock.AnythingOfType("data.ProjectCreate")).
+					Return(nil, &services.QuotaError{Quota: services.QuotaProjects, Limit: 50, Used: 50})
+			},
+			wantCode:  http.StatusForbidden,
+			wantError: true,
+		},
 		"Successful creation": {
 			contextUser: validUser,
 			requestBody: `{"title":"Test Project","description":"Test Description","is_public":true}`,
@@ -138,6 +188,78 @@ func TestCreateProject(t *testing.T) {
 	}
 }
 
+func TestUniqueProjectTitles(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	mockProjectService := mocks.MockProjectService{}
+	handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{UniqueTitles: true}, config.DiscoverConfig{}, "")
+
+	user := &data.User{
+		ID:          uuid.New(),
+		Username:    "validuser",
+		IsActivated: true,
+	}
+	project := &data.Project{ID: uuid.New(), Title: "Turtle", CreatorID: user.ID}
+
+	tests := map[string]struct {
+		setupMocks func()
+		wantCode   int
+		wantError  bool
+	}{
+		"Title taken": {
+			setupMocks: func() {
+				mockProjectService.On("CheckTitle", user.ID, "Turtle", (*uuid.UUID)(nil)).
+					Return(&data.TitleConflict{Title: "Turtle", Suggestions: []string{"Turtle (2)", "Turtle (3)", "Turtle (4)"}}, nil)
+			},
+			wantCode:  http.StatusConflict,
+			wantError: true,
+		},
+		"Title check fails": {
+			setupMocks: func() {
+				mockProjectService.On("CheckTitle", user.ID, "Turtle", (*uuid.UUID)(nil)).Return(nil, services.ErrInternal)
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+		"Title free": {
+			setupMocks: func() {
+				mockProjectService.On("CheckTitle", user.ID, "Turtle", (*uuid.UUID)(nil)).Return(nil, nil)
+				mockProjectService.On("CreateProject", mock.AnythingOfType("data.ProjectCreate")).Return(project, nil)
+			},
+			wantCode:  http.StatusOK,
+			wantError: false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockProjectService.ExpectedCalls = nil
+			mockProjectService.Calls = nil
+			tt.setupMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/projects", strings.NewReader(`{"title":"Turtle","is_public":false}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", user)
+
+			err := handler.Create(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+				mockProjectService.AssertNotCalled(t, "CreateProject", mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
+
 func TestDeleteProject(t *testing.T) {
 	e := echo.New()
 	e.Validator = &CustomValidator{validator: validator.New()}
@@ -160,7 +282,7 @@ func TestDeleteProject(t *testing.T) {
 
 	projectID := uuid.New()
 
-	handler := NewProjectHandler(&mockProjectService)
+	handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, config.DiscoverConfig{}, "")
 
 	tests := map[string]struct {
 		contextUser *data.User
@@ -300,7 +422,7 @@ func TestUpdateProject(t *testing.T) {
 		LastEditedAt:    time.Now(),
 	}
 
-	handler := NewProjectHandler(&mockProjectService)
+	handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, config.DiscoverConfig{}, "")
 
 	tests := map[string]struct {
 		contextUser *data.User
@@ -334,13 +456,13 @@ func TestUpdateProject(t *testing.T) {
 			wantCode:    http.StatusBadRequest,
 			wantError:   true,
 		},
-		"IsOwner service error": {
+		"GetProjectRole service error": {
 			contextUser: validUser,
 			projectID:   projectID.String(),
 			requestBody: `{"title":"Updated"}`,
 			setupMocks: func() {
-				mockProjectService.On("IsOwner", projectID, validUser.ID).
-					Return(false, fmt.Errorf("database error"))
+				mockProjectService.On("GetProjectRole", projectID, validUser.ID).
+					Return(data.ProjectRole(""), fmt.Errorf("database error"))
 			},
 			wantCode:  http.StatusInternalServerError,
 			wantError: true,
@@ -350,8 +472,8 @@ func TestUpdateProject(t *testing.T) {
 			projectID:   projectID.String(),
 			requestBody: `{"title":"Updated"}`,
 			setupMocks: func() {
-				mockProjectService.On("IsOwner", projectID, validUser.ID).
-					Return(false, nil)
+				mockProjectService.On("GetProjectRole", projectID, validUser.ID).
+					Return(data.ProjectRole(""), nil)
 			},
 			wantCode:  http.StatusForbidden,
 			wantError: true,
@@ -361,19 +483,30 @@ func TestUpdateProject(t *testing.T) {
 			projectID:   projectID.String(),
 			requestBody: `invalid json`,
 			setupMocks: func() {
-				mockProjectService.On("IsOwner", projectID, validUser.ID).
-					Return(true, nil)
+				mockProjectService.On("GetProjectRole", projectID, validUser.ID).
+					Return(data.ProjectOwner, nil)
 			},
 			wantCode:  http.StatusBadRequest,
 			wantError: true,
 		},
+		"Invalid flow data": {
+			contextUser: validUser,
+			projectID:   projectID.String(),
+			requestBody: `{"data":"{\"nodes\":[{\"id\":\"1\",\"type\":\"spinNode\",\"position\":{\"x\":0,\"y\":0}}],\"edges\":[]}"}`,
+			setupMocks: func() {
+				mockProjectService.On("GetProjectRole", projectID, validUser.ID).
+					Return(data.ProjectOwner, nil)
+			},
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
 		"Validation error - title too short": {
 			contextUser: validUser,
 			projectID:   projectID.String(),
 			requestBody: `{"title":"ab"}`,
 			setupMocks: func() {
-				mockProjectService.On("IsOwner", projectID, validUser.ID).
-					Return(true, nil)
+				mockProjectService.On("GetProjectRole", projectID, validUser.ID).
+					Return(data.ProjectOwner, nil)
 			},
 			wantCode:  http.StatusUnprocessableEntity,
 			wantError: true,
@@ -383,8 +516,8 @@ func TestUpdateProject(t *testing.T) {
 			projectID:   projectID.String(),
 			requestBody: `{"title":"Updated Project"}`,
 			setupMocks: func() {
-				mockProjectService.On("IsOwner", projectID, validUser.ID).
-					Return(true, nil)
+				mockProjectService.On("GetProjectRole", projectID, validUser.ID).
+					Return(data.ProjectOwner, nil)
 				mockProjectService.On("UpdateProject", mock.AnythingOfType("data.ProjectUpdate")).
 					Return(nil, fmt.Errorf("database error"))
 			},
@@ -396,8 +529,43 @@ func TestUpdateProject(t *testing.T) {
 			projectID:   projectID.String(),
 			requestBody: `{"title":"Updated Project","description":"Updated Description"}`,
 			setupMocks: func() {
-				mockProjectService.On("IsOwner", projectID, validUser.ID).
-					Return(true, nil)
+				mockProjectService.On("GetProjectRole", projectID, validUser.ID).
+					Return(data.ProjectOwner, nil)
+				mockProjectService.On("UpdateProject", mock.AnythingOfType("data.ProjectUpdate")).
+					Return(expectedProject, nil)
+			},
+			wantCode:  http.StatusOK,
+			wantError: false,
+		},
+		"Viewer cannot update": {
+			contextUser: validUser,
+			projectID:   projectID.String(),
+			requestBody: `{"title":"Updated Project"}`,
+			setupMocks: func() {
+				mockProjectService.On("GetProjectRole", projectID, validUser.ID).
+					Return(data.ProjectViewer, nil)
+			},
+			wantCode:  http.StatusForbidden,
+			wantError: true,
+		},
+		"Editor cannot change visibility": {
+			contextUser: validUser,
+			projectID:   projectID.String(),
+			requestBody: `{"title":"Updated Project","is_public":false}`,
+			setupMocks: func() {
+				mockProjectService.On("GetProjectRole", projectID, validUser.ID).
+					Return(data.ProjectEditor, nil)
+			},
+			wantCode:  http.StatusForbidden,
+			wantError: true,
+		},
+		"Successful update by editor": {
+			contextUser: validUser,
+			projectID:   projectID.String(),
+			requestBody: `{"title":"Updated Project"}`,
+			setupMocks: func() {
+				mockProjectService.On("GetProjectRole", projectID, validUser.ID).
+					Return(data.ProjectEditor, nil)
 				mockProjectService.On("UpdateProject", mock.AnythingOfType("data.ProjectUpdate")).
 					Return(expectedProject, nil)
 			},
@@ -437,139 +605,379 @@ func TestUpdateProject(t *testing.T) {
 	}
 }
 
-func TestLikeProject(t *testing.T) {
+func TestProjectETags(t *testing.T) {
 	e := echo.New()
 	e.Validator = &CustomValidator{validator: validator.New()}
 
-	mockProjectService := mocks.MockProjectService{}
+	owner := &data.User{ID: uuid.New(), Username: "owner", IsActivated: true}
+	project := &data.Project{ID: uuid.New(), Title: "Project", CreatorID: owner.ID, Version: 3, LastEditedAt: time.Now().UTC().Truncate(time.Microsecond)}
+	etag := project.ETag()
+	updated := &data.Project{ID: project.ID, Title: "Updated", CreatorID: owner.ID, Version: 3, LastEditedAt: project.LastEditedAt.Add(time.Second)}
 
-	validUser := &data.User{
-		ID:          uuid.New(),
-		Email:       "validuser@test.com",
-		Username:    "validuser",
-		IsActivated: true,
+	tests := map[string]struct {
+		method     string
+		header     string
+		value      string
+		setupMocks func(m *mocks.MockProjectService)
+		wantCode   int
+		wantETag   string
+		wantError  bool
+	}{
+		"Get without If-None-Match": {
+			method: http.MethodGet,
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("GetProject", project.ID, &owner.ID).Return(project, nil)
+				m.On("RecordOpen", project.ID, owner.ID).Return(nil)
+			},
+			wantCode: http.StatusOK,
+			wantETag: etag.String(),
+		},
+		"Get unchanged project": {
+			method: http.MethodGet,
+			header: "If-None-Match",
+			value:  `"1-abc", W/` + etag.String(),
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("GetProject", project.ID, &owner.ID).Return(project, nil)
+				m.On("RecordOpen", project.ID, owner.ID).Return(nil)
+			},
+			wantCode: http.StatusNotModified,
+			wantETag: etag.String(),
+		},
+		"Get changed project": {
+			method: http.MethodGet,
+			header: "If-None-Match",
+			value:  `"2-abc"`,
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("GetProject", project.ID, &owner.ID).Return(project, nil)
+				m.On("RecordOpen", project.ID, owner.ID).Return(nil)
+			},
+			wantCode: http.StatusOK,
+			wantETag: etag.String(),
+		},
+		"Update with matching If-Match": {
+			method: http.MethodPatch,
+			header: "If-Match",
+			value:  etag.String(),
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("GetProjectRole", project.ID, owner.ID).Return(data.ProjectOwner, nil)
+				m.On("UpdateProject", data.ProjectUpdate{ID: project.ID, Title: utils.Ptr("Updated"), IfMatch: &etag}).Return(updated, nil)
+			},
+			wantCode: http.StatusOK,
+			wantETag: updated.ETag().String(),
+		},
+		"Update of a project edited meanwhile": {
+			method: http.MethodPatch,
+			header: "If-Match",
+			value:  etag.String(),
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("GetProjectRole", project.ID, owner.ID).Return(data.ProjectOwner, nil)
+				m.On("UpdateProject", data.ProjectUpdate{ID: project.ID, Title: utils.Ptr("Updated"), IfMatch: &etag}).Return(nil, services.ErrEditConflict)
+			},
+			wantCode:  http.StatusPreconditionFailed,
+			wantError: true,
+		},
+		"Update with a weak If-Match": {
+			method: http.MethodPatch,
+			header: "If-Match",
+			value:  "W/" + etag.String(),
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("GetProjectRole", project.ID, owner.ID).Return(data.ProjectOwner, nil)
+			},
+			wantCode:  http.StatusPreconditionFailed,
+			wantError: true,
+		},
 	}
 
-	inactiveUser := &data.User{
-		ID:          uuid.New(),
-		Email:       "inactive@test.com",
-		Username:    "inactive",
-		IsActivated: false,
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockProjectService := mocks.MockProjectService{}
+			tt.setupMocks(&mockProjectService)
+			handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, config.DiscoverConfig{}, "")
+
+			req := httptest.NewRequest(tt.method, "/", nil)
+			if tt.method == http.MethodPatch {
+				req = httptest.NewRequest(tt.method, "/", strings.NewReader(`{"title":"Updated"}`))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			}
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(project.ID.String())
+			c.Set("user", owner)
+
+			var err error
+			if tt.method == http.MethodGet {
+				err = handler.Get(c)
+			} else {
+				err = handler.Update(c)
+			}
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Equal(t, tt.wantETag, rec.Header().Get("ETag"))
+			}
+			mockProjectService.AssertExpectations(t)
+		})
 	}
+}
+
+func TestSaveProjectData(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
 
+	owner := &data.User{ID: uuid.New(), Username: "owner", IsActivated: true}
 	projectID := uuid.New()
+	otherProjectID := uuid.New()
+	flowData := json.RawMessage(`{"nodes":[],"edges":[]}`)
 
-	handler := NewProjectHandler(&mockProjectService)
+	lastSave := &data.ProjectSave{UserID: owner.ID, Username: owner.Username, Session: "tab-1", SavedAt: time.Now()}
+
+	mockProjectService := mocks.MockProjectService{}
+	mockProjectService.On("GetProjectRole", projectID, owner.ID).Return(data.ProjectOwner, nil)
+	mockProjectService.On("GetProjectRole", otherProjectID, owner.ID).Return(data.ProjectRole(""), nil)
+	mockProjectService.On("SaveProjectData", projectID, flowData, 3, owner.ID, "tab-2").Return(4, nil)
+	mockProjectService.On("SaveProjectData", projectID, flowData, 2, owner.ID, "tab-2").Return(4, services.ErrEditConflict)
+	mockProjectService.On("GetLastSave", projectID).Return(lastSave, nil)
+
+	mockRealtimeService := mocks.MockRealtimeService{}
+	mockRealtimeService.On("Publish", owner.ID, data.EventEditConflict, data.EditConflict{
+		ProjectID:       projectID,
+		Version:         4,
+		RejectedVersion: 2,
+		Session:         "tab-2",
+		SavedBy:         lastSave,
+	}).Return(nil)
+
+	handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mockRealtimeService, &mocks.MockNotificationService{}, config.LimitsConfig{FlowNodes: 1}, config.DiscoverConfig{}, "")
 
 	tests := map[string]struct {
-		contextUser *data.User
 		projectID   string
-		setupMocks  func()
+		session     string
+		requestBody string
 		wantCode    int
 		wantError   bool
 	}{
-		"User not authenticated": {
-			contextUser: nil,
+		"Saved": {
 			projectID:   projectID.String(),
-			setupMocks:  func() {},
-			wantCode:    http.StatusUnauthorized,
-			wantError:   true,
+			requestBody: `{"data":{"nodes":[],"edges":[]},"version":3}`,
+			wantCode:    http.StatusOK,
 		},
-		"User not activated": {
-			contextUser: inactiveUser,
+		"Stale version": {
 			projectID:   projectID.String(),
-			setupMocks:  func() {},
-			wantCode:    http.StatusForbidden,
+			requestBody: `{"data":{"nodes":[],"edges":[]},"version":2}`,
+			wantCode:    http.StatusConflict,
 			wantError:   true,
 		},
-		"Invalid project ID": {
-			contextUser: validUser,
-			projectID:   "invalid-uuid",
-			setupMocks:  func() {},
-			wantCode:    http.StatusBadRequest,
-			wantError:   true,
-		},
-		"IsOwner service error": {
-			contextUser: validUser,
+		"Missing version": {
 			projectID:   projectID.String(),
-			setupMocks: func() {
-				mockProjectService.On("IsOwner", projectID, validUser.ID).
-					Return(false, fmt.Errorf("database error"))
-			},
-			wantCode:  http.StatusInternalServerError,
-			wantError: true,
+			requestBody: `{"data":{"nodes":[],"edges":[]}}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
 		},
-		"User is owner": {
-			contextUser: validUser,
+		"Too many nodes": {
 			projectID:   projectID.String(),
-			setupMocks: func() {
-				mockProjectService.On("IsOwner", projectID, validUser.ID).
-					Return(true, nil)
-			},
-			wantCode:  http.StatusForbidden,
-			wantError: true,
+			requestBody: `{"data":{"nodes":[{"id":"a","type":"startNode","position":{"x":0,"y":0}},{"id":"b","type":"moveNode","position":{"x":0,"y":0}}],"edges":[]},"version":3}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
 		},
-		"Like service error": {
-			contextUser: validUser,
+		"Invalid editor session": {
 			projectID:   projectID.String(),
-			setupMocks: func() {
-				mockProjectService.On("IsOwner", projectID, validUser.ID).
-					Return(false, nil)
-				mockProjectService.On("LikeProject", projectID, validUser.ID).
-					Return(fmt.Errorf("database error"))
-			},
-			wantCode:  http.StatusInternalServerError,
-			wantError: true,
+			session:     strings.Repeat("x", 65),
+			requestBody: `{"data":{"nodes":[],"edges":[]},"version":3}`,
+			wantCode:    http.StatusBadRequest,
+			wantError:   true,
 		},
-		"Successful like": {
-			contextUser: validUser,
-			projectID:   projectID.String(),
-			setupMocks: func() {
-				mockProjectService.On("IsOwner", projectID, validUser.ID).
-					Return(false, nil)
-				mockProjectService.On("LikeProject", projectID, validUser.ID).
-					Return(nil)
-			},
-			wantCode:  http.StatusCreated,
-			wantError: false,
+		"Not the owner": {
+			projectID:   otherProjectID.String(),
+			requestBody: `{"data":{"nodes":[],"edges":[]},"version":3}`,
+			wantCode:    http.StatusForbidden,
+			wantError:   true,
 		},
 	}
 
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			mockProjectService.ExpectedCalls = nil
-			tt.setupMocks()
+			session := "tab-2"
+			if tt.session != "" {
+				session = tt.session
+			}
 
-			req := httptest.NewRequest(http.MethodPost, "/projects/"+tt.projectID+"/like", nil)
+			req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(tt.requestBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(editorSessionHeader, session)
 			rec := httptest.NewRecorder()
 			c := e.NewContext(req, rec)
 			c.SetParamNames("id")
 			c.SetParamValues(tt.projectID)
+			c.Set("user", owner)
 
-			if tt.contextUser != nil {
-				c.Set("user", tt.contextUser)
-			}
-
-			err := handler.Like(c)
+			err := handler.SaveData(c)
 
 			if tt.wantError {
 				assert.Error(t, err)
 				if he, ok := err.(*echo.HTTPError); ok {
 					assert.Equal(t, tt.wantCode, he.Code)
+					if he.Code == http.StatusConflict {
+						// the editor needs the current version to reload
+						assert.Equal(t, 4, he.Message.(map[string]interface{})["version"])
+					}
 				}
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.JSONEq(t, `{"version":4}`, rec.Body.String())
 			}
 		})
 	}
+
+	// the stale save told the other sessions who saved last
+	mockRealtimeService.AssertExpectations(t)
 }
 
-func TestUnlikeProject(t *testing.T) {
+func TestProjectDryRun(t *testing.T) {
 	e := echo.New()
 	e.Validator = &CustomValidator{validator: validator.New()}
 
 	mockProjectService := mocks.MockProjectService{}
+	handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, config.DiscoverConfig{}, "")
+
+	user := &data.User{ID: uuid.New(), Username: "validuser", IsActivated: true}
+	projectID := uuid.New()
+
+	current := &data.Project{
+		ID:          projectID,
+		Title:       "Old Title",
+		Description: "Description",
+		Data:        json.RawMessage(`{}`),
+		CreatorID:   user.ID,
+	}
+	preview := *current
+	preview.Title = "New Title"
+
+	tests := map[string]struct {
+		method      string
+		query       string
+		requestBody string
+		setupMocks  func()
+		wantCode    int
+		wantBody    map[string]interface{}
+		wantError   bool
+	}{
+		"Invalid dry_run value": {
+			method:      http.MethodPatch,
+			query:       "?dry_run=maybe",
+			requestBody: `{"title":"New Title"}`,
+			setupMocks: func() {
+				mockProjectService.On("GetProjectRole", projectID, user.ID).Return(data.ProjectOwner, nil)
+			},
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Dry run update with invalid flow data": {
+			method:      http.MethodPatch,
+			query:       "?dry_run=true",
+			requestBody: `{"data":{"nodes":[{"id":"1","type":"spinNode","position":{"x":0,"y":0}}]}}`,
+			setupMocks: func() {
+				mockProjectService.On("GetProjectRole", projectID, user.ID).Return(data.ProjectOwner, nil)
+			},
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Dry run update without fields": {
+			method:      http.MethodPatch,
+			query:       "?dry_run=true",
+			requestBody: `{}`,
+			setupMocks: func() {
+				mockProjectService.On("GetProjectRole", projectID, user.ID).Return(data.ProjectOwner, nil)
+				mockProjectService.On("GetProject", projectID, &user.ID).Return(current, nil)
+				mockProjectService.On("UpdateProject", mock.Anything).Return(nil, services.ErrNoFields)
+			},
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Dry run update": {
+			method:      http.MethodPatch,
+			query:       "?dry_run=true",
+			requestBody: `{"title":"New Title","description":"Description"}`,
+			setupMocks: func() {
+				mockProjectService.On("GetProjectRole", projectID, user.ID).Return(data.ProjectOwner, nil)
+				mockProjectService.On("GetProject", projectID, &user.ID).Return(current, nil)
+				mockProjectService.On("UpdateProject", mock.MatchedBy(func(p data.ProjectUpdate) bool {
+					return p.DryRun && *p.Title == "New Title"
+				})).Return(&preview, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: map[string]interface{}{"dry_run": true, "changes": []interface{}{"title"}},
+		},
+		"Dry run create": {
+			method:      http.MethodPost,
+			query:       "?dry_run=1",
+			requestBody: `{"title":"New Title","is_public":false}`,
+			setupMocks: func() {
+				mockProjectService.On("CreateProject", mock.MatchedBy(func(p data.ProjectCreate) bool {
+					return p.DryRun
+				})).Return(&preview, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: map[string]interface{}{"dry_run": true},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockProjectService.ExpectedCalls = nil
+			tt.setupMocks()
+
+			req := httptest.NewRequest(tt.method, "/projects/"+projectID.String()+tt.query, strings.NewReader(tt.requestBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(projectID.String())
+			c.Set("user", user)
+
+			var err error
+			if tt.method == http.MethodPost {
+				err = handler.Create(c)
+			} else {
+				err = handler.Update(c)
+			}
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, rec.Code)
+
+			var body map[string]interface{}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			for key, want := range tt.wantBody {
+				assert.Equal(t, want, body[key])
+			}
+		})
+	}
+}
+
+func TestLikeProject(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	mockProjectService := mocks.MockProjectService{}
+	mockNotificationService := mocks.MockNotificationService{}
+	mockRealtimeService := mocks.MockRealtimeService{}
 
 	validUser := &data.User{
 		ID:          uuid.New(),
@@ -586,8 +994,10 @@ func TestUnlikeProject(t *testing.T) {
 	}
 
 	projectID := uuid.New()
+	creatorID := uuid.New()
+	notification := &data.Notification{ID: 1, UserID: creatorID, Type: data.NotificationProjectLiked}
 
-	handler := NewProjectHandler(&mockProjectService)
+	handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mockRealtimeService, &mockNotificationService, config.LimitsConfig{}, config.DiscoverConfig{}, "")
 
 	tests := map[string]struct {
 		contextUser *data.User
@@ -637,28 +1047,60 @@ func TestUnlikeProject(t *testing.T) {
 			wantCode:  http.StatusForbidden,
 			wantError: true,
 		},
-		"Unlike service error": {
+		"Like service error": {
 			contextUser: validUser,
 			projectID:   projectID.String(),
 			setupMocks: func() {
 				mockProjectService.On("IsOwner", projectID, validUser.ID).
 					Return(false, nil)
-				mockProjectService.On("UnlikeProject", projectID, validUser.ID).
+				mockProjectService.On("LikeProject", projectID, validUser.ID).
 					Return(fmt.Errorf("database error"))
 			},
 			wantCode:  http.StatusInternalServerError,
 			wantError: true,
 		},
-		"Successful unlike": {
+		"Successful like": {
 			contextUser: validUser,
 			projectID:   projectID.String(),
 			setupMocks: func() {
 				mockProjectService.On("IsOwner", projectID, validUser.ID).
 					Return(false, nil)
-				mockProjectService.On("UnlikeProject", projectID, validUser.ID).
+				mockProjectService.On("LikeProject", projectID, validUser.ID).
+					Return(nil)
+				mockNotificationService.On("NotifyLike", projectID, validUser.ID).
+					Return(notification, nil)
+				mockRealtimeService.On("Publish", creatorID, data.EventNotification, notification).
 					Return(nil)
 			},
-			wantCode:  http.StatusNoContent,
+			wantCode:  http.StatusCreated,
+			wantError: false,
+		},
+		"Creator turned like notifications off": {
+			contextUser: validUser,
+			projectID:   projectID.String(),
+			setupMocks: func() {
+				mockProjectService.On("IsOwner", projectID, validUser.ID).
+					Return(false, nil)
+				mockProjectService.On("LikeProject", projectID, validUser.ID).
+					Return(nil)
+				mockNotificationService.On("NotifyLike", projectID, validUser.ID).
+					Return(nil, nil)
+			},
+			wantCode:  http.StatusCreated,
+			wantError: false,
+		},
+		"Failed notification still likes": {
+			contextUser: validUser,
+			projectID:   projectID.String(),
+			setupMocks: func() {
+				mockProjectService.On("IsOwner", projectID, validUser.ID).
+					Return(false, nil)
+				mockProjectService.On("LikeProject", projectID, validUser.ID).
+					Return(nil)
+				mockNotificationService.On("NotifyLike", projectID, validUser.ID).
+					Return(nil, fmt.Errorf("database error"))
+			},
+			wantCode:  http.StatusCreated,
 			wantError: false,
 		},
 	}
@@ -666,9 +1108,13 @@ func TestUnlikeProject(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 			mockProjectService.ExpectedCalls = nil
+			mockNotificationService.ExpectedCalls = nil
+			mockNotificationService.Calls = nil
+			mockRealtimeService.ExpectedCalls = nil
+			mockRealtimeService.Calls = nil
 			tt.setupMocks()
 
-			req := httptest.NewRequest(http.MethodDelete, "/projects/"+tt.projectID+"/like", nil)
+			req := httptest.NewRequest(http.MethodPost, "/projects/"+tt.projectID+"/like", nil)
 			rec := httptest.NewRecorder()
 			c := e.NewContext(req, rec)
 			c.SetParamNames("id")
@@ -678,7 +1124,7 @@ func TestUnlikeProject(t *testing.T) {
 				c.Set("user", tt.contextUser)
 			}
 
-			err := handler.Unlike(c)
+			err := handler.Like(c)
 
 			if tt.wantError {
 				assert.Error(t, err)
@@ -689,11 +1135,13 @@ func TestUnlikeProject(t *testing.T) {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.wantCode, rec.Code)
 			}
+			mockNotificationService.AssertExpectations(t)
+			mockRealtimeService.AssertExpectations(t)
 		})
 	}
 }
 
-func TestGetUserProjects(t *testing.T) {
+func TestUnlikeProject(t *testing.T) {
 	e := echo.New()
 	e.Validator = &CustomValidator{validator: validator.New()}
 
@@ -713,66 +1161,80 @@ func TestGetUserProjects(t *testing.T) {
 		IsActivated: false,
 	}
 
-	targetUserID := uuid.New()
-	expectedProjects := []data.Project{
-		{
-			ID:              uuid.New(),
-			Title:           "Project 1",
-			Description:     "Description 1",
-			CreatorID:       targetUserID,
-			CreatorUsername: "targetuser",
-			IsPublic:        true,
-		},
-	}
+	projectID := uuid.New()
 
-	handler := NewProjectHandler(&mockProjectService)
+	handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, config.DiscoverConfig{}, "")
 
 	tests := map[string]struct {
 		contextUser *data.User
-		userID      string
+		projectID   string
 		setupMocks  func()
 		wantCode    int
 		wantError   bool
 	}{
 		"User not authenticated": {
 			contextUser: nil,
-			userID:      targetUserID.String(),
+			projectID:   projectID.String(),
 			setupMocks:  func() {},
 			wantCode:    http.StatusUnauthorized,
 			wantError:   true,
 		},
 		"User not activated": {
 			contextUser: inactiveUser,
-			userID:      targetUserID.String(),
+			projectID:   projectID.String(),
 			setupMocks:  func() {},
 			wantCode:    http.StatusForbidden,
 			wantError:   true,
 		},
-		"Invalid user ID": {
+		"Invalid project ID": {
 			contextUser: validUser,
-			userID:      "invalid-uuid",
+			projectID:   "invalid-uuid",
 			setupMocks:  func() {},
 			wantCode:    http.StatusBadRequest,
 			wantError:   true,
 		},
-		"Service error": {
+		"IsOwner service error": {
 			contextUser: validUser,
-			userID:      targetUserID.String(),
+			projectID:   projectID.String(),
 			setupMocks: func() {
-				mockProjectService.On("GetUserProjects", targetUserID, validUser.ID).
-					Return(nil, fmt.Errorf("database error"))
+				mockProjectService.On("IsOwner", projectID, validUser.ID).
+					Return(false, fmt.Errorf("database error"))
 			},
 			wantCode:  http.StatusInternalServerError,
 			wantError: true,
 		},
-		"Successful get": {
+		"User is owner": {
 			contextUser: validUser,
-			userID:      targetUserID.String(),
+			projectID:   projectID.String(),
 			setupMocks: func() {
-				mockProjectService.On("GetUserProjects", targetUserID, validUser.ID).
-					Return(expectedProjects, nil)
+				mockProjectService.On("IsOwner", projectID, validUser.ID).
+					Return(true, nil)
 			},
-			wantCode:  http.StatusOK,
+			wantCode:  http.StatusForbidden,
+			wantError: true,
+		},
+		"Unlike service error": {
+			contextUser: validUser,
+			projectID:   projectID.String(),
+			setupMocks: func() {
+				mockProjectService.On("IsOwner", projectID, validUser.ID).
+					Return(false, nil)
+				mockProjectService.On("UnlikeProject", projectID, validUser.ID).
+					Return(fmt.Errorf("database error"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+		"Successful unlike": {
+			contextUser: validUser,
+			projectID:   projectID.String(),
+			setupMocks: func() {
+				mockProjectService.On("IsOwner", projectID, validUser.ID).
+					Return(false, nil)
+				mockProjectService.On("UnlikeProject", projectID, validUser.ID).
+					Return(nil)
+			},
+			wantCode:  http.StatusNoContent,
 			wantError: false,
 		},
 	}
@@ -782,17 +1244,17 @@ func TestGetUserProjects(t *testing.T) {
 			mockProjectService.ExpectedCalls = nil
 			tt.setupMocks()
 
-			req := httptest.NewRequest(http.MethodGet, "/users/"+tt.userID+"/projects", nil)
+			req := httptest.NewRequest(http.MethodDelete, "/projects/"+tt.projectID+"/like", nil)
 			rec := httptest.NewRecorder()
 			c := e.NewContext(req, rec)
 			c.SetParamNames("id")
-			c.SetParamValues(tt.userID)
+			c.SetParamValues(tt.projectID)
 
 			if tt.contextUser != nil {
 				c.Set("user", tt.contextUser)
 			}
 
-			err := handler.GetUserProjects(c)
+			err := handler.Unlike(c)
 
 			if tt.wantError {
 				assert.Error(t, err)
@@ -807,7 +1269,166 @@ func TestGetUserProjects(t *testing.T) {
 	}
 }
 
-func TestGetLikedProjects(t *testing.T) {
+func TestProjectMembers(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	owner := &data.User{ID: uuid.New(), Username: "owner", IsActivated: true}
+	editor := &data.User{ID: uuid.New(), Username: "editor", IsActivated: true}
+	stranger := &data.User{ID: uuid.New(), Username: "stranger", IsActivated: true}
+	projectID := uuid.New()
+
+	member := data.ProjectMember{UserID: editor.ID, Username: editor.Username, Role: data.ProjectEditor, AddedAt: time.Now()}
+	invite := data.ProjectMemberInvite{Email: "editor@test.com", Role: data.ProjectEditor}
+
+	tests := map[string]struct {
+		method      string
+		contextUser *data.User
+		userID      string
+		requestBody string
+		setupMocks  func(m *mocks.MockProjectService)
+		wantCode    int
+		wantError   bool
+	}{
+		"List members": {
+			method:      http.MethodGet,
+			contextUser: editor,
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("GetProjectRole", projectID, editor.ID).Return(data.ProjectEditor, nil)
+				m.On("GetMembers", projectID).Return([]data.ProjectMember{member}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"List members as non-member": {
+			method:      http.MethodGet,
+			contextUser: stranger,
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("GetProjectRole", projectID, stranger.ID).Return(data.ProjectRole(""), nil)
+			},
+			wantCode:  http.StatusForbidden,
+			wantError: true,
+		},
+		"Add member": {
+			method:      http.MethodPost,
+			contextUser: owner,
+			requestBody: `{"email":"editor@test.com","role":"editor"}`,
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("IsOwner", projectID, owner.ID).Return(true, nil)
+				m.On("AddMember", projectID, invite).Return(&member, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Add member as editor": {
+			method:      http.MethodPost,
+			contextUser: editor,
+			requestBody: `{"email":"stranger@test.com","role":"editor"}`,
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("IsOwner", projectID, editor.ID).Return(false, nil)
+			},
+			wantCode:  http.StatusForbidden,
+			wantError: true,
+		},
+		"Add member with invalid role": {
+			method:      http.MethodPost,
+			contextUser: owner,
+			requestBody: `{"email":"editor@test.com","role":"owner"}`,
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("IsOwner", projectID, owner.ID).Return(true, nil)
+			},
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Add unknown user": {
+			method:      http.MethodPost,
+			contextUser: owner,
+			requestBody: `{"email":"editor@test.com","role":"editor"}`,
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("IsOwner", projectID, owner.ID).Return(true, nil)
+				m.On("AddMember", projectID, invite).Return(nil, services.ErrRecordNotFound)
+			},
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Remove member as owner": {
+			method:      http.MethodDelete,
+			contextUser: owner,
+			userID:      editor.ID.String(),
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("IsOwner", projectID, owner.ID).Return(true, nil)
+				m.On("RemoveMember", projectID, editor.ID).Return(nil)
+			},
+			wantCode: http.StatusNoContent,
+		},
+		"Leave project": {
+			method:      http.MethodDelete,
+			contextUser: editor,
+			userID:      editor.ID.String(),
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("RemoveMember", projectID, editor.ID).Return(nil)
+			},
+			wantCode: http.StatusNoContent,
+		},
+		"Remove other member as editor": {
+			method:      http.MethodDelete,
+			contextUser: editor,
+			userID:      stranger.ID.String(),
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("IsOwner", projectID, editor.ID).Return(false, nil)
+			},
+			wantCode:  http.StatusForbidden,
+			wantError: true,
+		},
+		"Remove non-member": {
+			method:      http.MethodDelete,
+			contextUser: owner,
+			userID:      stranger.ID.String(),
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("IsOwner", projectID, owner.ID).Return(true, nil)
+				m.On("RemoveMember", projectID, stranger.ID).Return(services.ErrRecordNotFound)
+			},
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockProjectService := mocks.MockProjectService{}
+			tt.setupMocks(&mockProjectService)
+			handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, config.DiscoverConfig{}, "")
+
+			req := httptest.NewRequest(tt.method, "/api/projects/"+projectID.String()+"/members", strings.NewReader(tt.requestBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id", "userID")
+			c.SetParamValues(projectID.String(), tt.userID)
+			c.Set("user", tt.contextUser)
+
+			var err error
+			switch tt.method {
+			case http.MethodGet:
+				err = handler.GetMembers(c)
+			case http.MethodPost:
+				err = handler.AddMember(c)
+			case http.MethodDelete:
+				err = handler.RemoveMember(c)
+			}
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+			mockProjectService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetUserProjects(t *testing.T) {
 	e := echo.New()
 	e.Validator = &CustomValidator{validator: validator.New()}
 
@@ -828,32 +1449,37 @@ func TestGetLikedProjects(t *testing.T) {
 	}
 
 	targetUserID := uuid.New()
+	folderID := uuid.New()
 	expectedProjects := []data.Project{
 		{
 			ID:              uuid.New(),
-			Title:           "Liked Project 1",
+			Title:           "Project 1",
 			Description:     "Description 1",
-			CreatorID:       uuid.New(),
-			CreatorUsername: "someuser",
+			CreatorID:       targetUserID,
+			CreatorUsername: "targetuser",
 			IsPublic:        true,
 		},
 	}
 
-	handler := NewProjectHandler(&mockProjectService)
+	handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, config.DiscoverConfig{}, "")
 
 	tests := map[string]struct {
 		contextUser *data.User
 		userID      string
+		query       string
 		setupMocks  func()
 		wantCode    int
 		wantError   bool
 	}{
-		"User not authenticated": {
+		"Guest": {
 			contextUser: nil,
 			userID:      targetUserID.String(),
-			setupMocks:  func() {},
-			wantCode:    http.StatusUnauthorized,
-			wantError:   true,
+			setupMocks: func() {
+				mockProjectService.On("GetUserProjectSummaries", targetUserID, (*uuid.UUID)(nil), (*data.FolderFilter)(nil)).
+					Return(expectedProjects, nil)
+			},
+			wantCode:  http.StatusOK,
+			wantError: false,
 		},
 		"User not activated": {
 			contextUser: inactiveUser,
@@ -873,18 +1499,165 @@ func TestGetLikedProjects(t *testing.T) {
 			contextUser: validUser,
 			userID:      targetUserID.String(),
 			setupMocks: func() {
-				mockProjectService.On("GetLikedProjects", targetUserID).
+				mockProjectService.On("GetUserProjectSummaries", targetUserID, &validUser.ID, (*data.FolderFilter)(nil)).
 					Return(nil, fmt.Errorf("database error"))
 			},
 			wantCode:  http.StatusInternalServerError,
 			wantError: true,
 		},
-		"Successful get": {
+		"Invalid folder ID": {
 			contextUser: validUser,
 			userID:      targetUserID.String(),
-			setupMocks: func() {
-				mockProjectService.On("GetLikedProjects", targetUserID).
-					Return(expectedProjects, nil)
+			query:       "?folder=invalid-uuid",
+			setupMocks:  func() {},
+			wantCode:    http.StatusBadRequest,
+			wantError:   true,
+		},
+		"Projects in a folder": {
+			contextUser: validUser,
+			userID:      targetUserID.String(),
+			query:       "?folder=" + folderID.String(),
+			setupMocks: func() {
+				mockProjectService.On("GetUserProjectSummaries", targetUserID, &validUser.ID, &data.FolderFilter{FolderID: &folderID}).
+					Return(expectedProjects, nil)
+			},
+			wantCode:  http.StatusOK,
+			wantError: false,
+		},
+		"Projects in no folder": {
+			contextUser: validUser,
+			userID:      targetUserID.String(),
+			query:       "?folder=none",
+			setupMocks: func() {
+				mockProjectService.On("GetUserProjectSummaries", targetUserID, &validUser.ID, &data.FolderFilter{}).
+					Return(expectedProjects, nil)
+			},
+			wantCode:  http.StatusOK,
+			wantError: false,
+		},
+		"Successful get": {
+			contextUser: validUser,
+			userID:      targetUserID.String(),
+			setupMocks: func() {
+				mockProjectService.On("GetUserProjectSummaries", targetUserID, &validUser.ID, (*data.FolderFilter)(nil)).
+					Return(expectedProjects, nil)
+			},
+			wantCode:  http.StatusOK,
+			wantError: false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockProjectService.ExpectedCalls = nil
+			tt.setupMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/users/"+tt.userID+"/projects"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.userID)
+
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.GetUserProjects(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
+
+func TestGetLikedProjects(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	mockProjectService := mocks.MockProjectService{}
+
+	validUser := &data.User{
+		ID:          uuid.New(),
+		Email:       "validuser@test.com",
+		Username:    "validuser",
+		IsActivated: true,
+	}
+
+	inactiveUser := &data.User{
+		ID:          uuid.New(),
+		Email:       "inactive@test.com",
+		Username:    "inactive",
+		IsActivated: false,
+	}
+
+	targetUserID := uuid.New()
+	expectedProjects := []data.Project{
+		{
+			ID:              uuid.New(),
+			Title:           "Liked Project 1",
+			Description:     "Description 1",
+			CreatorID:       uuid.New(),
+			CreatorUsername: "someuser",
+			IsPublic:        true,
+		},
+	}
+
+	handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, config.DiscoverConfig{}, "")
+
+	tests := map[string]struct {
+		contextUser *data.User
+		userID      string
+		setupMocks  func()
+		wantCode    int
+		wantError   bool
+	}{
+		"Guest": {
+			contextUser: nil,
+			userID:      targetUserID.String(),
+			setupMocks: func() {
+				mockProjectService.On("GetLikedProjects", targetUserID).
+					Return(expectedProjects, nil)
+			},
+			wantCode:  http.StatusOK,
+			wantError: false,
+		},
+		"User not activated": {
+			contextUser: inactiveUser,
+			userID:      targetUserID.String(),
+			setupMocks:  func() {},
+			wantCode:    http.StatusForbidden,
+			wantError:   true,
+		},
+		"Invalid user ID": {
+			contextUser: validUser,
+			userID:      "invalid-uuid",
+			setupMocks:  func() {},
+			wantCode:    http.StatusBadRequest,
+			wantError:   true,
+		},
+		"Service error": {
+			contextUser: validUser,
+			userID:      targetUserID.String(),
+			setupMocks: func() {
+				mockProjectService.On("GetLikedProjects", targetUserID).
+					Return(nil, fmt.Errorf("database error"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+		"Successful get": {
+			contextUser: validUser,
+			userID:      targetUserID.String(),
+			setupMocks: func() {
+				mockProjectService.On("GetLikedProjects", targetUserID).
+					Return(expectedProjects, nil)
 			},
 			wantCode:  http.StatusOK,
 			wantError: false,
@@ -921,6 +1694,183 @@ func TestGetLikedProjects(t *testing.T) {
 	}
 }
 
+func TestGetRecentProjects(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	mockProjectService := mocks.MockProjectService{}
+	handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, config.DiscoverConfig{}, "")
+
+	user := &data.User{ID: uuid.New(), Username: "user", IsActivated: true}
+	recent := []data.RecentProject{
+		{Project: data.Project{ID: uuid.New(), Title: "Last opened"}, OpenedAt: time.Now()},
+	}
+
+	tests := map[string]struct {
+		contextUser *data.User
+		query       string
+		setupMocks  func()
+		wantCode    int
+	}{
+		"Not authenticated": {
+			setupMocks: func() {},
+			wantCode:   http.StatusUnauthorized,
+		},
+		"Default limit": {
+			contextUser: user,
+			setupMocks: func() {
+				mockProjectService.On("GetRecentProjects", user.ID, 10).Return(recent, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Custom limit": {
+			contextUser: user,
+			query:       "?limit=25",
+			setupMocks: func() {
+				mockProjectService.On("GetRecentProjects", user.ID, 25).Return(recent, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Limit beyond what is kept": {
+			contextUser: user,
+			query:       "?limit=500",
+			setupMocks: func() {
+				mockProjectService.On("GetRecentProjects", user.ID, 10).Return(recent, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Service error": {
+			contextUser: user,
+			setupMocks: func() {
+				mockProjectService.On("GetRecentProjects", user.ID, 10).Return(nil, fmt.Errorf("database error"))
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockProjectService.ExpectedCalls = nil
+			tt.setupMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/users/me/recent-projects"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.GetRecentProjects(c)
+
+			if tt.wantCode != http.StatusOK {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var response struct {
+				Projects []map[string]interface{} `json:"projects"`
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			if assert.Len(t, response.Projects, 1) {
+				// the project fields sit next to when it was opened
+				assert.Equal(t, "Last opened", response.Projects[0]["title"])
+				assert.Contains(t, response.Projects[0], "opened_at")
+			}
+			mockProjectService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestExportLikedProjects(t *testing.T) {
+	e := echo.New()
+
+	mockProjectService := mocks.MockProjectService{}
+
+	user := &data.User{ID: uuid.New(), Username: "validuser", IsActivated: true}
+	failingUser := &data.User{ID: uuid.New(), Username: "failing", IsActivated: true}
+	projectID := uuid.New()
+
+	mockProjectService.On("GetLikedProjects", user.ID).Return([]data.Project{
+		{ID: projectID, Title: "Spirals & Stars", CreatorUsername: "someuser", IsPublic: true},
+	}, nil)
+	mockProjectService.On("GetLikedProjects", failingUser.ID).Return(nil, fmt.Errorf("database error"))
+
+	handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, config.DiscoverConfig{}, "https://turtle.test")
+
+	tests := map[string]struct {
+		contextUser     *data.User
+		format          string
+		wantCode        int
+		wantError       bool
+		wantContentType string
+		wantBody        []string
+	}{
+		"User not authenticated": {
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Invalid format": {
+			contextUser: user,
+			format:      "csv",
+			wantCode:    http.StatusBadRequest,
+			wantError:   true,
+		},
+		"Service error": {
+			contextUser: failingUser,
+			wantCode:    http.StatusInternalServerError,
+			wantError:   true,
+		},
+		"JSON export": {
+			contextUser:     user,
+			wantCode:        http.StatusOK,
+			wantContentType: echo.MIMEApplicationJSON,
+			wantBody:        []string{`"title":"Spirals \u0026 Stars"`, `"creator":"someuser"`, `"url":"https://turtle.test/projects/` + projectID.String() + `"`},
+		},
+		"OPML export": {
+			contextUser:     user,
+			format:          "opml",
+			wantCode:        http.StatusOK,
+			wantContentType: "text/x-opml+xml",
+			wantBody:        []string{`<opml version="2.0">`, `text="Spirals &amp; Stars"`, `url="https://turtle.test/projects/` + projectID.String() + `"`},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/users/me/liked-projects/export?format="+tt.format, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.ExportLikedProjects(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Header().Get(echo.HeaderContentType), tt.wantContentType)
+				assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), "attachment")
+				for _, want := range tt.wantBody {
+					assert.Contains(t, rec.Body.String(), want)
+				}
+			}
+		})
+	}
+}
+
 func TestGetProject(t *testing.T) {
 	e := echo.New()
 	e.Validator = &CustomValidator{validator: validator.New()}
@@ -948,7 +1898,9 @@ func TestGetProject(t *testing.T) {
 		LastEditedAt:    time.Now(),
 	}
 
-	handler := NewProjectHandler(&mockProjectService)
+	otherUser := &data.User{ID: uuid.New(), Username: "otheruser", IsActivated: true}
+
+	handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, config.DiscoverConfig{}, "")
 
 	tests := map[string]struct {
 		contextUser *data.User
@@ -963,6 +1915,7 @@ func TestGetProject(t *testing.T) {
 			setupMocks: func() {
 				mockProjectService.On("GetProject", projectID, (*uuid.UUID)(nil)).
 					Return(expectedProject, nil)
+				mockProjectService.On("RecordView", projectID, (*uuid.UUID)(nil), "192.0.2.1").Return(nil)
 			},
 			wantCode:  http.StatusOK,
 			wantError: false,
@@ -981,7 +1934,7 @@ func TestGetProject(t *testing.T) {
 				mockProjectService.On("GetProject", projectID, &validUser.ID).
 					Return(nil, services.ErrRecordNotFound)
 			},
-			wantCode:  http.StatusInternalServerError,
+			wantCode:  http.StatusNotFound,
 			wantError: true,
 		},
 		"Service error": {
@@ -1000,6 +1953,21 @@ func TestGetProject(t *testing.T) {
 			setupMocks: func() {
 				mockProjectService.On("GetProject", projectID, &validUser.ID).
 					Return(expectedProject, nil)
+				mockProjectService.On("RecordOpen", projectID, validUser.ID).Return(nil)
+			},
+			wantCode:  http.StatusOK,
+			wantError: false,
+		},
+		"View and open by another user are recorded": {
+			contextUser: otherUser,
+			projectID:   projectID.String(),
+			setupMocks: func() {
+				mockProjectService.On("GetProject", projectID, &otherUser.ID).
+					Return(expectedProject, nil)
+				mockProjectService.On("RecordView", projectID, &otherUser.ID, mock.Anything).
+					Return(fmt.Errorf("database error"))
+				mockProjectService.On("RecordOpen", projectID, otherUser.ID).
+					Return(fmt.Errorf("database error"))
 			},
 			wantCode:  http.StatusOK,
 			wantError: false,
@@ -1033,20 +2001,126 @@ func TestGetProject(t *testing.T) {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.wantCode, rec.Code)
 			}
+			mockProjectService.AssertExpectations(t)
 		})
 	}
 }
 
-func TestGetFeaturedProjects(t *testing.T) {
+func TestBatchGetProjects(t *testing.T) {
 	e := echo.New()
 	e.Validator = &CustomValidator{validator: validator.New()}
 
 	mockProjectService := mocks.MockProjectService{}
+	handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, config.DiscoverConfig{}, "")
 
-	expectedProjects := []data.Project{
-		{
-			ID:              uuid.New(),
-			Title:           "Featured Project 1",
+	validUser := &data.User{ID: uuid.New(), Username: "validuser", IsActivated: true}
+	visibleID, hiddenID := uuid.New(), uuid.New()
+	visible := []data.Project{{ID: visibleID, Title: "Visible", IsPublic: true}}
+
+	tooMany := make([]string, 101)
+	for i := range tooMany {
+		tooMany[i] = `"` + uuid.NewString() + `"`
+	}
+
+	tests := map[string]struct {
+		contextUser *data.User
+		body        string
+		setupMocks  func()
+		wantCode    int
+		wantCount   int
+	}{
+		"Invalid body": {
+			body:       `{"ids":"nope"}`,
+			setupMocks: func() {},
+			wantCode:   http.StatusBadRequest,
+		},
+		"No IDs": {
+			body:       `{"ids":[]}`,
+			setupMocks: func() {},
+			wantCode:   http.StatusUnprocessableEntity,
+		},
+		"Too many IDs": {
+			body:       `{"ids":[` + strings.Join(tooMany, ",") + `]}`,
+			setupMocks: func() {},
+			wantCode:   http.StatusUnprocessableEntity,
+		},
+		"Service error": {
+			contextUser: validUser,
+			body:        fmt.Sprintf(`{"ids":["%s"]}`, visibleID),
+			setupMocks: func() {
+				mockProjectService.On("GetProjectsByIDs", []uuid.UUID{visibleID}, &validUser.ID).
+					Return(nil, fmt.Errorf("database error"))
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+		"Guest gets the visible subset": {
+			body: fmt.Sprintf(`{"ids":["%s","%s"]}`, visibleID, hiddenID),
+			setupMocks: func() {
+				mockProjectService.On("GetProjectsByIDs", []uuid.UUID{visibleID, hiddenID}, (*uuid.UUID)(nil)).
+					Return(visible, nil)
+			},
+			wantCode:  http.StatusOK,
+			wantCount: 1,
+		},
+		"User gets the visible subset": {
+			contextUser: validUser,
+			body:        fmt.Sprintf(`{"ids":["%s","%s"]}`, visibleID, hiddenID),
+			setupMocks: func() {
+				mockProjectService.On("GetProjectsByIDs", []uuid.UUID{visibleID, hiddenID}, &validUser.ID).
+					Return(visible, nil)
+			},
+			wantCode:  http.StatusOK,
+			wantCount: 1,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockProjectService.ExpectedCalls = nil
+			tt.setupMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/projects/batch-get", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.BatchGet(c)
+
+			if tt.wantCode != http.StatusOK {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var response struct {
+				Projects []data.Project `json:"projects"`
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.Len(t, response.Projects, tt.wantCount)
+			mockProjectService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetFeaturedProjects(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	mockProjectService := mocks.MockProjectService{}
+
+	expectedProjects := []data.Project{
+		{
+			ID:              uuid.New(),
+			Title:           "Featured Project 1",
 			Description:     "Featured Description 1",
 			Data:            json.RawMessage(`{}`),
 			CreatorID:       uuid.New(),
@@ -1072,7 +2146,7 @@ func TestGetFeaturedProjects(t *testing.T) {
 		},
 	}
 
-	handler := NewProjectHandler(&mockProjectService)
+	handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, config.DiscoverConfig{}, "")
 
 	tests := map[string]struct {
 		queryParams   map[string]string
@@ -1086,7 +2160,7 @@ func TestGetFeaturedProjects(t *testing.T) {
 		"Default pagination (no params)": {
 			queryParams: map[string]string{},
 			setupMocks: func() {
-				mockProjectService.On("GetFeaturedProjects", 10, 1).
+				mockProjectService.On("GetFeaturedProjectSummaries", 10, 1).
 					Return(expectedProjects, nil)
 			},
 			expectedLimit: 10,
@@ -1101,7 +2175,7 @@ func TestGetFeaturedProjects(t *testing.T) {
 				"page":  "2",
 			},
 			setupMocks: func() {
-				mockProjectService.On("GetFeaturedProjects", 5, 2).
+				mockProjectService.On("GetFeaturedProjectSummaries", 5, 2).
 					Return(expectedProjects, nil)
 			},
 			expectedLimit: 5,
@@ -1116,7 +2190,7 @@ func TestGetFeaturedProjects(t *testing.T) {
 				"page":  "1",
 			},
 			setupMocks: func() {
-				mockProjectService.On("GetFeaturedProjects", 10, 1).
+				mockProjectService.On("GetFeaturedProjectSummaries", 10, 1).
 					Return(expectedProjects, nil)
 			},
 			expectedLimit: 10,
@@ -1131,7 +2205,7 @@ func TestGetFeaturedProjects(t *testing.T) {
 				"page":  "1",
 			},
 			setupMocks: func() {
-				mockProjectService.On("GetFeaturedProjects", 10, 1).
+				mockProjectService.On("GetFeaturedProjectSummaries", 10, 1).
 					Return(expectedProjects, nil)
 			},
 			expectedLimit: 10,
@@ -1146,7 +2220,7 @@ func TestGetFeaturedProjects(t *testing.T) {
 				"page":  "0",
 			},
 			setupMocks: func() {
-				mockProjectService.On("GetFeaturedProjects", 15, 1).
+				mockProjectService.On("GetFeaturedProjectSummaries", 15, 1).
 					Return(expectedProjects, nil)
 			},
 			expectedLimit: 15,
@@ -1161,7 +2235,7 @@ func TestGetFeaturedProjects(t *testing.T) {
 				"page":  "-2",
 			},
 			setupMocks: func() {
-				mockProjectService.On("GetFeaturedProjects", 20, 1).
+				mockProjectService.On("GetFeaturedProjectSummaries", 20, 1).
 					Return(expectedProjects, nil)
 			},
 			expectedLimit: 20,
@@ -1176,7 +2250,7 @@ func TestGetFeaturedProjects(t *testing.T) {
 				"page":  "1",
 			},
 			setupMocks: func() {
-				mockProjectService.On("GetFeaturedProjects", 10, 1).
+				mockProjectService.On("GetFeaturedProjectSummaries", 10, 1).
 					Return(expectedProjects, nil)
 			},
 			expectedLimit: 10,
@@ -1191,7 +2265,7 @@ func TestGetFeaturedProjects(t *testing.T) {
 				"page":  "xyz",
 			},
 			setupMocks: func() {
-				mockProjectService.On("GetFeaturedProjects", 8, 1).
+				mockProjectService.On("GetFeaturedProjectSummaries", 8, 1).
 					Return(expectedProjects, nil)
 			},
 			expectedLimit: 8,
@@ -1206,7 +2280,7 @@ func TestGetFeaturedProjects(t *testing.T) {
 				"page":  "1",
 			},
 			setupMocks: func() {
-				mockProjectService.On("GetFeaturedProjects", 10, 1).
+				mockProjectService.On("GetFeaturedProjectSummaries", 10, 1).
 					Return(nil, fmt.Errorf("database error"))
 			},
 			expectedLimit: 10,
@@ -1221,7 +2295,7 @@ func TestGetFeaturedProjects(t *testing.T) {
 				"page":  "999",
 			},
 			setupMocks: func() {
-				mockProjectService.On("GetFeaturedProjects", 10, 999).
+				mockProjectService.On("GetFeaturedProjectSummaries", 10, 999).
 					Return([]data.Project{}, nil)
 			},
 			expectedLimit: 10,
@@ -1236,7 +2310,7 @@ func TestGetFeaturedProjects(t *testing.T) {
 				"page":  "1",
 			},
 			setupMocks: func() {
-				mockProjectService.On("GetFeaturedProjects", 1000, 1).
+				mockProjectService.On("GetFeaturedProjectSummaries", 1000, 1).
 					Return(expectedProjects, nil)
 			},
 			expectedLimit: 1000,
@@ -1283,13 +2357,113 @@ func TestGetFeaturedProjects(t *testing.T) {
 	}
 }
 
+func TestGetProjectsWithCursor(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	project := data.Project{ID: uuid.New(), Title: "Public Project", IsPublic: true, CreatedAt: time.Now()}
+	next := &data.ProjectCursor{Time: project.CreatedAt, ID: project.ID}
+	after := data.ProjectCursor{Time: time.UnixMicro(1700000000000000).UTC(), ID: uuid.New()}
+
+	tests := map[string]struct {
+		featured       bool
+		query          string
+		setupMocks     func(m *mocks.MockProjectService)
+		wantCode       int
+		wantError      bool
+		wantNextCursor bool
+	}{
+		"First page": {
+			query: "?cursor=&limit=1",
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("GetPublicProjectSummariesAfter", mock.MatchedBy(func(filters data.PublicProjectFilter) bool {
+					return filters.Limit == 1
+				}), (*data.ProjectCursor)(nil)).Return([]data.Project{project}, next, nil)
+			},
+			wantCode:       http.StatusOK,
+			wantNextCursor: true,
+		},
+		"Last page": {
+			query: "?cursor=" + after.Encode(),
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("GetPublicProjectSummariesAfter", mock.Anything, &after).Return([]data.Project{project}, nil, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Invalid cursor": {
+			query:     "?cursor=not-a-cursor",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Cursor with another sort field": {
+			query:     "?cursor=&sort_field=likes_count",
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Featured first page": {
+			featured: true,
+			query:    "?cursor=&limit=500",
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("GetFeaturedProjectSummariesAfter", 100, (*data.ProjectCursor)(nil)).Return([]data.Project{project}, next, nil)
+			},
+			wantCode:       http.StatusOK,
+			wantNextCursor: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockProjectService := mocks.MockProjectService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockProjectService)
+			}
+			handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, config.DiscoverConfig{}, "")
+
+			req := httptest.NewRequest(http.MethodGet, "/projects/public"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			var err error
+			if tt.featured {
+				err = handler.GetFeatured(c)
+			} else {
+				err = handler.GetPublic(c)
+			}
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+
+				var response struct {
+					Meta struct {
+						NextCursor *string `json:"next_cursor"`
+					} `json:"meta"`
+				}
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				if tt.wantNextCursor && assert.NotNil(t, response.Meta.NextCursor) {
+					decoded, err := data.DecodeProjectCursor(*response.Meta.NextCursor)
+					assert.NoError(t, err)
+					assert.Equal(t, project.ID, decoded.ID)
+				} else {
+					assert.Nil(t, response.Meta.NextCursor)
+				}
+			}
+			mockProjectService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestGetPublicProjects(t *testing.T) {
 	e := echo.New()
 	e.Validator = &CustomValidator{validator: validator.New()}
 
 	mockProjectService := mocks.MockProjectService{}
 
-	handler := NewProjectHandler(&mockProjectService)
+	handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, config.DiscoverConfig{}, "")
 
 	// Sample test data
 	project1 := data.Project{
@@ -1326,7 +2500,7 @@ func TestGetPublicProjects(t *testing.T) {
 		"Successful request with default params": {
 			query: "",
 			setupMocks: func() {
-				mockProjectService.On("GetPublicProjects", mock.MatchedBy(func(filters data.PublicProjectFilter) bool {
+				mockProjectService.On("GetPublicProjectSummaries", mock.MatchedBy(func(filters data.PublicProjectFilter) bool {
 					return filters.Page == 1 && filters.Limit == 10 &&
 						filters.SortField == "created_at" && filters.SortOrder == "desc"
 				})).Return([]data.Project{project1, project2}, 2, nil)
@@ -1337,7 +2511,7 @@ func TestGetPublicProjects(t *testing.T) {
 		"Successful request with custom params": {
 			query: "?page=2&limit=5&sort_field=likes_count&sort_order=asc&search_term=test",
 			setupMocks: func() {
-				mockProjectService.On("GetPublicProjects", mock.MatchedBy(func(filters data.PublicProjectFilter) bool {
+				mockProjectService.On("GetPublicProjectSummaries", mock.MatchedBy(func(filters data.PublicProjectFilter) bool {
 					return filters.Page == 2 && filters.Limit == 5 &&
 						filters.SortField == "likes_count" && filters.SortOrder == "asc" &&
 						filters.SearchTerm == "test"
@@ -1384,7 +2558,7 @@ func TestGetPublicProjects(t *testing.T) {
 		"Service error": {
 			query: "?page=1&limit=10",
 			setupMocks: func() {
-				mockProjectService.On("GetPublicProjects", mock.AnythingOfType("data.PublicProjectFilter")).
+				mockProjectService.On("GetPublicProjectSummaries", mock.AnythingOfType("data.PublicProjectFilter")).
 					Return(nil, 0, fmt.Errorf("database error"))
 			},
 			wantCode:  http.StatusInternalServerError,
@@ -1393,7 +2567,7 @@ func TestGetPublicProjects(t *testing.T) {
 		"Empty results": {
 			query: "?search_term=nonexistent",
 			setupMocks: func() {
-				mockProjectService.On("GetPublicProjects", mock.MatchedBy(func(filters data.PublicProjectFilter) bool {
+				mockProjectService.On("GetPublicProjectSummaries", mock.MatchedBy(func(filters data.PublicProjectFilter) bool {
 					return filters.SearchTerm == "nonexistent"
 				})).Return([]data.Project{}, 0, nil)
 			},
@@ -1403,17 +2577,26 @@ func TestGetPublicProjects(t *testing.T) {
 		"Valid sort by likes_count desc": {
 			query: "?sort_field=likes_count&sort_order=desc",
 			setupMocks: func() {
-				mockProjectService.On("GetPublicProjects", mock.MatchedBy(func(filters data.PublicProjectFilter) bool {
+				mockProjectService.On("GetPublicProjectSummaries", mock.MatchedBy(func(filters data.PublicProjectFilter) bool {
 					return filters.SortField == "likes_count" && filters.SortOrder == "desc"
 				})).Return([]data.Project{project1, project2}, 2, nil)
 			},
 			wantCode:  http.StatusOK,
 			wantError: false,
 		},
+		"Flow data included on request": {
+			query: "?include=data",
+			setupMocks: func() {
+				mockProjectService.On("GetPublicProjects", mock.AnythingOfType("data.PublicProjectFilter")).
+					Return([]data.Project{project1, project2}, 2, nil)
+			},
+			wantCode:  http.StatusOK,
+			wantError: false,
+		},
 		"Invalid query params ignored (defaults used)": {
 			query: "?invalid_param=value&another_invalid=123",
 			setupMocks: func() {
-				mockProjectService.On("GetPublicProjects", mock.MatchedBy(func(filters data.PublicProjectFilter) bool {
+				mockProjectService.On("GetPublicProjectSummaries", mock.MatchedBy(func(filters data.PublicProjectFilter) bool {
 					// Should use defaults when invalid params are provided
 					return filters.Page == 1 && filters.Limit == 10 &&
 						filters.SortField == "created_at" && filters.SortOrder == "desc"
@@ -1475,7 +2658,7 @@ func TestListProjects(t *testing.T) {
 
 	mockProjectService := mocks.MockProjectService{}
 
-	handler := NewProjectHandler(&mockProjectService)
+	handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, config.DiscoverConfig{}, "")
 
 	project1 := data.Project{
 		ID: uuid.New(),
@@ -1549,7 +2732,7 @@ func TestFeatureProject(t *testing.T) {
 
 	mockProjectService := mocks.MockProjectService{}
 
-	handler := NewProjectHandler(&mockProjectService)
+	handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, config.DiscoverConfig{}, "")
 
 	project := data.Project{
 		ID: uuid.New(),
@@ -1635,3 +2818,774 @@ func TestFeatureProject(t *testing.T) {
 
 	mockProjectService.AssertExpectations(t)
 }
+
+func TestForkProject(t *testing.T) {
+	e := echo.New()
+
+	mockProjectService := mocks.MockProjectService{}
+	handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{ForksPerHour: 5, ForksPerDay: 20}, config.DiscoverConfig{}, "")
+
+	user := &data.User{ID: uuid.New(), Username: "forker", IsActivated: true}
+	spammer := &data.User{ID: uuid.New(), Username: "spammer", IsActivated: true}
+	inactiveUser := &data.User{ID: uuid.New(), Username: "inactive", IsActivated: false}
+
+	project := data.Project{ID: uuid.New(), IsPublic: true}
+	hiddenProjectID := uuid.New()
+	fork := data.Project{ID: uuid.New(), CreatorID: user.ID, ForkedFrom: &project.ID}
+
+	mockProjectService.On("CountUserForks", user.ID, mock.Anything).Return(1, nil)
+	mockProjectService.On("CountUserForks", spammer.ID, mock.Anything).Return(5, nil)
+	mockProjectService.On("GetProject", project.ID, &user.ID).Return(utils.Ptr(project), nil)
+	mockProjectService.On("GetProject", hiddenProjectID, &user.ID).Return(nil, services.ErrRecordNotFound)
+	mockProjectService.On("ForkProject", project.ID, user.ID).Return(utils.Ptr(fork), nil)
+
+	tests := map[string]struct {
+		contextUser *data.User
+		projectID   string
+		wantCode    int
+		wantError   bool
+	}{
+		"User not authenticated": {
+			projectID: project.ID.String(),
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"User not activated": {
+			contextUser: inactiveUser,
+			projectID:   project.ID.String(),
+			wantCode:    http.StatusForbidden,
+			wantError:   true,
+		},
+		"Invalid project ID": {
+			contextUser: user,
+			projectID:   "invalid-uuid",
+			wantCode:    http.StatusBadRequest,
+			wantError:   true,
+		},
+		"Fork limit reached": {
+			contextUser: spammer,
+			projectID:   project.ID.String(),
+			wantCode:    http.StatusTooManyRequests,
+			wantError:   true,
+		},
+		"Project not visible": {
+			contextUser: user,
+			projectID:   hiddenProjectID.String(),
+			wantCode:    http.StatusNotFound,
+			wantError:   true,
+		},
+		"Successful fork": {
+			contextUser: user,
+			projectID:   project.ID.String(),
+			wantCode:    http.StatusCreated,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.projectID)
+
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.Fork(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), fork.ID.String())
+			}
+		})
+	}
+}
+
+func TestGetForks(t *testing.T) {
+	e := echo.New()
+
+	mockProjectService := mocks.MockProjectService{}
+	handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, config.DiscoverConfig{}, "")
+
+	project := data.Project{ID: uuid.New(), IsPublic: true, ForkCount: 1}
+	fork := data.Project{ID: uuid.New(), IsPublic: true, ForkedFrom: &project.ID}
+
+	mockProjectService.On("GetProject", project.ID, (*uuid.UUID)(nil)).Return(utils.Ptr(project), nil)
+	mockProjectService.On("GetProject", mock.Anything, (*uuid.UUID)(nil)).Return(nil, services.ErrRecordNotFound)
+	mockProjectService.On("GetForks", project.ID, (*uuid.UUID)(nil), 1, 10).Return([]data.Project{fork}, 1, nil)
+	mockProjectService.On("GetForks", project.ID, (*uuid.UUID)(nil), 2, 5).Return([]data.Project{}, 1, nil)
+
+	tests := map[string]struct {
+		projectID string
+		query     string
+		wantCode  int
+		wantError bool
+	}{
+		"Invalid project ID": {
+			projectID: "invalid-uuid",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Project not found": {
+			projectID: uuid.New().String(),
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Default pagination": {
+			projectID: project.ID.String(),
+			wantCode:  http.StatusOK,
+		},
+		"Custom pagination": {
+			projectID: project.ID.String(),
+			query:     "?page=2&limit=5",
+			wantCode:  http.StatusOK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.projectID)
+
+			err := handler.GetForks(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"total":1`)
+			}
+		})
+	}
+}
+
+func TestGetLikers(t *testing.T) {
+	e := echo.New()
+
+	mockProjectService := mocks.MockProjectService{}
+	handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, config.DiscoverConfig{}, "")
+
+	user := &data.User{ID: uuid.New(), Username: "user"}
+	project := data.Project{ID: uuid.New(), IsPublic: true, LikesCount: 1}
+	private := data.Project{ID: uuid.New(), CreatorID: user.ID}
+	liker := data.ProjectLiker{UserID: uuid.New(), Username: "liker", LikedAt: time.Now()}
+
+	mockProjectService.On("GetProject", project.ID, (*uuid.UUID)(nil)).Return(utils.Ptr(project), nil)
+	mockProjectService.On("GetProject", private.ID, &user.ID).Return(utils.Ptr(private), nil)
+	mockProjectService.On("GetProject", mock.Anything, mock.Anything).Return(nil, services.ErrRecordNotFound)
+	mockProjectService.On("GetLikers", project.ID, 1, 20).Return([]data.ProjectLiker{liker}, 1, nil)
+	mockProjectService.On("GetLikers", project.ID, 2, 5).Return([]data.ProjectLiker{}, 1, nil)
+	mockProjectService.On("GetLikers", private.ID, 1, 20).Return(nil, 0, errors.New("database error"))
+
+	tests := map[string]struct {
+		projectID string
+		query     string
+		user      *data.User
+		wantCode  int
+		wantError bool
+	}{
+		"Invalid project ID": {
+			projectID: "invalid-uuid",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Project not visible": {
+			projectID: private.ID.String(),
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Default pagination": {
+			projectID: project.ID.String(),
+			wantCode:  http.StatusOK,
+		},
+		"Custom pagination": {
+			projectID: project.ID.String(),
+			query:     "?page=2&limit=5",
+			wantCode:  http.StatusOK,
+		},
+		"Database error": {
+			projectID: private.ID.String(),
+			user:      user,
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.projectID)
+			if tt.user != nil {
+				c.Set("user", tt.user)
+			}
+
+			err := handler.GetLikers(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"total":1`)
+			}
+		})
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	e := echo.New()
+
+	cfg := config.DiscoverConfig{MinLikes: 5, SeenDays: 30}
+	user := &data.User{ID: uuid.New(), Username: "user"}
+	guest := data.DiscoverOptions{IP: "192.0.2.1", Limit: 1, MinLikes: 5, SeenDays: 30}
+	signedIn := data.DiscoverOptions{UserID: &user.ID, IP: "192.0.2.1", Limit: 20, MinLikes: 5, SeenDays: 30}
+	project := data.Project{ID: uuid.New(), Title: "surprise", IsPublic: true, LikesCount: 12}
+
+	tests := map[string]struct {
+		query      string
+		user       *data.User
+		setupMocks func(m *mocks.MockProjectService)
+		wantCode   int
+		wantError  bool
+	}{
+		"Single project by default": {
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("DiscoverProjects", guest).Return([]data.Project{project}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Signed in user with capped limit": {
+			query: "?limit=500",
+			user:  user,
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("DiscoverProjects", signedIn).Return([]data.Project{project}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Database error": {
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("DiscoverProjects", guest).Return(nil, errors.New("database error"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockProjectService := mocks.MockProjectService{}
+			tt.setupMocks(&mockProjectService)
+			handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, cfg, "")
+
+			req := httptest.NewRequest(http.MethodGet, "/"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			if tt.user != nil {
+				c.Set("user", tt.user)
+			}
+
+			err := handler.Discover(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), "surprise")
+			}
+			mockProjectService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPublishProject(t *testing.T) {
+	e := echo.New()
+
+	owner := &data.User{ID: uuid.New(), Username: "owner", IsActivated: true}
+	projectID := uuid.New()
+	snapshot := &data.ProjectSnapshot{ProjectID: projectID, Title: "Published", Data: json.RawMessage(`{}`), Version: 4, PublishedAt: time.Now()}
+
+	tests := map[string]struct {
+		contextUser *data.User
+		projectID   string
+		setupMocks  func(m *mocks.MockProjectService)
+		wantCode    int
+		wantError   bool
+	}{
+		"User not authenticated": {
+			projectID: projectID.String(),
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Invalid project ID": {
+			contextUser: owner,
+			projectID:   "invalid-uuid",
+			wantCode:    http.StatusBadRequest,
+			wantError:   true,
+		},
+		"Not the owner": {
+			contextUser: owner,
+			projectID:   projectID.String(),
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("IsOwner", projectID, owner.ID).Return(false, nil)
+			},
+			wantCode:  http.StatusForbidden,
+			wantError: true,
+		},
+		"Published": {
+			contextUser: owner,
+			projectID:   projectID.String(),
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("IsOwner", projectID, owner.ID).Return(true, nil)
+				m.On("PublishProject", projectID).Return(snapshot, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Database error": {
+			contextUser: owner,
+			projectID:   projectID.String(),
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("IsOwner", projectID, owner.ID).Return(true, nil)
+				m.On("PublishProject", projectID).Return(nil, errors.New("database error"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockProjectService := mocks.MockProjectService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockProjectService)
+			}
+			handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, config.DiscoverConfig{}, "")
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.projectID)
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.Publish(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"version":4`)
+			}
+			mockProjectService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSaveMetadataDraft(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	owner := &data.User{ID: uuid.New(), Username: "owner", IsActivated: true}
+	projectID := uuid.New()
+	title := "Staged title"
+	draft := &data.ProjectMetadataDraft{ProjectID: projectID, Title: title, Tags: []string{"loops"}, UpdatedAt: time.Now()}
+
+	tests := map[string]struct {
+		contextUser *data.User
+		projectID   string
+		body        string
+		setupMocks  func(m *mocks.MockProjectService)
+		wantCode    int
+		wantError   bool
+	}{
+		"User not authenticated": {
+			projectID: projectID.String(),
+			body:      `{"title":"Staged title"}`,
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Invalid project ID": {
+			contextUser: owner,
+			projectID:   "invalid-uuid",
+			body:        `{"title":"Staged title"}`,
+			wantCode:    http.StatusBadRequest,
+			wantError:   true,
+		},
+		"Title too short": {
+			contextUser: owner,
+			projectID:   projectID.String(),
+			body:        `{"title":"ab"}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Too many tags": {
+			contextUser: owner,
+			projectID:   projectID.String(),
+			body:        `{"tags":["a","b","c","d","e","f","g","h","i","j","k"]}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Not the owner": {
+			contextUser: owner,
+			projectID:   projectID.String(),
+			body:        `{"title":"Staged title"}`,
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("IsOwner", projectID, owner.ID).Return(false, nil)
+			},
+			wantCode:  http.StatusForbidden,
+			wantError: true,
+		},
+		"Project not found": {
+			contextUser: owner,
+			projectID:   projectID.String(),
+			body:        `{"title":"Staged title"}`,
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("IsOwner", projectID, owner.ID).Return(true, nil)
+				m.On("SaveMetadataDraft", projectID, data.ProjectMetadataDraftUpdate{Title: &title}).Return(nil, services.ErrRecordNotFound)
+			},
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Staged": {
+			contextUser: owner,
+			projectID:   projectID.String(),
+			body:        `{"title":"Staged title","tags":["loops"]}`,
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("IsOwner", projectID, owner.ID).Return(true, nil)
+				m.On("SaveMetadataDraft", projectID, data.ProjectMetadataDraftUpdate{Title: &title, Tags: []string{"loops"}}).Return(draft, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockProjectService := mocks.MockProjectService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockProjectService)
+			}
+			handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, config.DiscoverConfig{}, "")
+
+			req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.projectID)
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.SaveMetadataDraft(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"title":"Staged title"`)
+			}
+			mockProjectService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDiscardMetadataDraft(t *testing.T) {
+	e := echo.New()
+
+	owner := &data.User{ID: uuid.New(), Username: "owner", IsActivated: true}
+	projectID := uuid.New()
+
+	tests := map[string]struct {
+		setupMocks func(m *mocks.MockProjectService)
+		wantCode   int
+		wantError  bool
+	}{
+		"Not the owner": {
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("IsOwner", projectID, owner.ID).Return(false, nil)
+			},
+			wantCode:  http.StatusForbidden,
+			wantError: true,
+		},
+		"No draft": {
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("IsOwner", projectID, owner.ID).Return(true, nil)
+				m.On("DiscardMetadataDraft", projectID).Return(services.ErrRecordNotFound)
+			},
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Discarded": {
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("IsOwner", projectID, owner.ID).Return(true, nil)
+				m.On("DiscardMetadataDraft", projectID).Return(nil)
+			},
+			wantCode: http.StatusNoContent,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockProjectService := mocks.MockProjectService{}
+			tt.setupMocks(&mockProjectService)
+			handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, config.DiscoverConfig{}, "")
+
+			req := httptest.NewRequest(http.MethodDelete, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(projectID.String())
+			c.Set("user", owner)
+
+			err := handler.DiscardMetadataDraft(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+			mockProjectService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSetProjectAccessibility(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	owner := &data.User{ID: uuid.New(), Username: "owner", IsActivated: true}
+	projectID := uuid.New()
+	alt := "A green spiral on a white background"
+	described := &data.ProjectAccessibility{ThumbnailAlt: alt, UpdatedAt: time.Now()}
+
+	tests := map[string]struct {
+		body       string
+		setupMocks func(m *mocks.MockProjectService)
+		wantCode   int
+		wantError  bool
+	}{
+		"Alt text too long": {
+			body:      `{"thumbnail_alt":"` + strings.Repeat("a", 501) + `"}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Not the owner": {
+			body: `{"thumbnail_alt":"` + alt + `"}`,
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("IsOwner", projectID, owner.ID).Return(false, nil)
+			},
+			wantCode:  http.StatusForbidden,
+			wantError: true,
+		},
+		"Project not found": {
+			body: `{"thumbnail_alt":"` + alt + `"}`,
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("IsOwner", projectID, owner.ID).Return(true, nil)
+				m.On("SetAccessibility", projectID, data.ProjectAccessibilityUpdate{ThumbnailAlt: &alt}).Return(nil, services.ErrRecordNotFound)
+			},
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Described": {
+			body: `{"thumbnail_alt":"` + alt + `"}`,
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("IsOwner", projectID, owner.ID).Return(true, nil)
+				m.On("SetAccessibility", projectID, data.ProjectAccessibilityUpdate{ThumbnailAlt: &alt}).Return(described, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockProjectService := mocks.MockProjectService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockProjectService)
+			}
+			handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, config.DiscoverConfig{}, "")
+
+			req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(projectID.String())
+			c.Set("user", owner)
+
+			err := handler.SetAccessibility(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"thumbnail_alt":"`+alt+`"`)
+			}
+			mockProjectService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetQuota(t *testing.T) {
+	e := echo.New()
+
+	user := &data.User{ID: uuid.New(), Username: "user", IsActivated: true}
+	usage := &data.QuotaUsage{Role: data.RoleUser, Projects: 3, StorageBytes: 4096, Quota: data.Quota{MaxProjects: 50, MaxBytes: 256 << 10, MaxStorage: 10 << 20}}
+
+	tests := map[string]struct {
+		contextUser *data.User
+		setupMocks  func(m *mocks.MockProjectService)
+		wantCode    int
+		wantError   bool
+	}{
+		"User not authenticated": {
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Quota of the user": {
+			contextUser: user,
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("GetQuota", user.ID).Return(usage, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Database error": {
+			contextUser: user,
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("GetQuota", user.ID).Return(nil, errors.New("database error"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockProjectService := mocks.MockProjectService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockProjectService)
+			}
+			handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{}, config.DiscoverConfig{}, "")
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.GetQuota(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"max_projects":50`)
+				assert.Contains(t, rec.Body.String(), `"projects":3`)
+			}
+			mockProjectService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateProjectAsGuest(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	guest := &data.User{ID: uuid.New(), Username: "guest1a2b3c4d5e6f", IsActivated: true, GuestExpiresAt: utils.Ptr(time.Now().Add(time.Hour))}
+	fullGuest := &data.User{ID: uuid.New(), Username: "guest6f5e4d3c2b1a", IsActivated: true, GuestExpiresAt: utils.Ptr(time.Now().Add(time.Hour))}
+
+	mockProjectService := mocks.MockProjectService{}
+	mockProjectService.On("CountUserProjects", guest.ID).Return(2, nil)
+	mockProjectService.On("CountUserProjects", fullGuest.ID).Return(3, nil)
+	mockProjectService.On("CreateProject", mock.MatchedBy(func(p data.ProjectCreate) bool {
+		return p.CreatorID == guest.ID && !p.IsPublic
+	})).Return(&data.Project{ID: uuid.New(), Title: "Test Project", CreatorID: guest.ID}, nil)
+
+	handler := NewProjectHandler(&mockProjectService, &mocks.MockClassroomService{}, &mocks.MockRealtimeService{}, &mocks.MockNotificationService{}, config.LimitsConfig{GuestProjects: 3}, config.DiscoverConfig{}, "")
+
+	tests := map[string]struct {
+		contextUser *data.User
+		requestBody string
+		wantCode    int
+		wantError   bool
+	}{
+		"Private project created": {
+			contextUser: guest,
+			requestBody: `{"title":"Test Project","is_public":false}`,
+			wantCode:    http.StatusOK,
+		},
+		"Public project": {
+			contextUser: guest,
+			requestBody: `{"title":"Test Project","is_public":true}`,
+			wantCode:    http.StatusForbidden,
+			wantError:   true,
+		},
+		"Project limit reached": {
+			contextUser: fullGuest,
+			requestBody: `{"title":"Test Project","is_public":false}`,
+			wantCode:    http.StatusForbidden,
+			wantError:   true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.requestBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", tt.contextUser)
+
+			err := handler.Create(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
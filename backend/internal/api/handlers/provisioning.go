@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxRosterSize limits how many accounts can be provisioned in a single request.
+const maxRosterSize = 500
+
+// Provision handles the request to bulk-create accounts from a class roster.
+// The roster is accepted as JSON ({"users": [...]}), as a text/csv body or as a
+// multipart "file" upload with an "email" and optional "username" column.
+// Accounts are activated, get a generated password and must change it on first login.
+// Rows that fail (e.g. the email is already registered) are reported per entry.
+func (h *UserHandler) Provision(c echo.Context) error {
+	roster, err := readRoster(c)
+	if err != nil {
+		return err
+	}
+
+	results := make([]data.ProvisionResult, 0, len(roster))
+	created := 0
+	for _, entry := range roster {
+		result := data.ProvisionResult{Email: entry.Email}
+
+		user, password, err := h.userService.ProvisionUser(c.Request().Context(), entry)
+		if err != nil {
+			switch {
+			case errors.Is(err, services.ErrDuplicateEmail):
+				result.Error = "Email is already taken"
+			case errors.Is(err, services.ErrDuplicateUsername):
+				result.Error = "Username is already taken"
+			default:
+				logging.Error(c.Request().Context(), "Internal user provisioning error", err)
+				result.Error = "Failed to create user"
+			}
+			results = append(results, result)
+			continue
+		}
+
+		result.UserID = &user.ID
+		result.Username = user.Username
+		result.Password = password
+		results = append(results, result)
+		created++
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"created": created,
+		"failed":  len(results) - created,
+		"results": results,
+	})
+}
+
+// Deprovision handles the request to bulk-deactivate accounts from a class roster.
+// It accepts the same roster formats as Provision; only the email column is used.
+// Deactivated users are signed out and can no longer log in, their projects are kept.
+func (h *UserHandler) Deprovision(c echo.Context) error {
+	roster, err := readRoster(c)
+	if err != nil {
+		return err
+	}
+
+	results := make([]data.ProvisionResult, 0, len(roster))
+	deactivated := 0
+	for _, entry := range roster {
+		result := data.ProvisionResult{Email: entry.Email}
+
+		user, err := h.userService.DeprovisionUser(c.Request().Context(), entry.Email)
+		if err != nil {
+			if errors.Is(err, services.ErrUserNotFound) {
+				result.Error = "User not found"
+			} else {
+				logging.Error(c.Request().Context(), "Internal user deprovisioning error", err)
+				result.Error = "Failed to deactivate user"
+			}
+			results = append(results, result)
+			continue
+		}
+
+		if err := h.tokenService.DeleteAllForUser(c.Request().Context(), data.ScopeRefresh, user.ID); err != nil {
+			logging.Error(c.Request().Context(), "Internal token deletion error", err)
+		}
+
+		result.UserID = &user.ID
+		result.Username = user.Username
+		results = append(results, result)
+		deactivated++
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"deactivated": deactivated,
+		"failed":      len(results) - deactivated,
+		"results":     results,
+	})
+}
+
+// readRoster reads and validates roster entries from a JSON body, a CSV body or a multipart CSV upload.
+func readRoster(c echo.Context) ([]data.UserProvision, error) {
+	var roster []data.UserProvision
+
+	contentType := c.Request().Header.Get(echo.HeaderContentType)
+	switch {
+	case strings.HasPrefix(contentType, echo.MIMEMultipartForm):
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Missing roster file")
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid roster file")
+		}
+		defer file.Close()
+
+		roster, err = parseRosterCSV(file)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	case strings.HasPrefix(contentType, "text/csv"):
+		var err error
+		roster, err = parseRosterCSV(c.Request().Body)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	default:
+		var payload struct {
+			Users []data.UserProvision `json:"users"`
+		}
+		if err := c.Bind(&payload); err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+		}
+		roster = payload.Users
+	}
+
+	if len(roster) == 0 {
+		return nil, echo.NewHTTPError(http.StatusUnprocessableEntity, "Roster is empty")
+	}
+	if len(roster) > maxRosterSize {
+		return nil, echo.NewHTTPError(http.StatusUnprocessableEntity, fmt.Sprintf("Roster exceeds the limit of %d users", maxRosterSize))
+	}
+
+	for i := range roster {
+		if err := c.Validate(&roster[i]); err != nil {
+			return nil, echo.NewHTTPError(http.StatusUnprocessableEntity, fmt.Sprintf("Entry %d: %v", i+1, err))
+		}
+	}
+
+	return roster, nil
+}
+
+// parseRosterCSV parses a CSV roster. The first row is a header naming the
+// "email" and optional "username" columns, other columns are ignored.
+func parseRosterCSV(r io.Reader) ([]data.UserProvision, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("invalid roster file")
+	}
+
+	emailCol, usernameCol := -1, -1
+	for i, column := range header {
+		switch strings.ToLower(strings.TrimSpace(column)) {
+		case "email":
+			emailCol = i
+		case "username":
+			usernameCol = i
+		}
+	}
+	if emailCol == -1 {
+		return nil, errors.New("roster is missing the email column")
+	}
+
+	var roster []data.UserProvision
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.New("invalid roster file")
+		}
+
+		var entry data.UserProvision
+		if emailCol < len(record) {
+			entry.Email = strings.TrimSpace(record[emailCol])
+		}
+		if usernameCol != -1 && usernameCol < len(record) {
+			entry.Username = strings.TrimSpace(record[usernameCol])
+		}
+
+		// skip blank lines exported by spreadsheets
+		if entry.Email == "" && entry.Username == "" {
+			continue
+		}
+
+		roster = append(roster, entry)
+		if len(roster) > maxRosterSize {
+			break
+		}
+	}
+
+	return roster, nil
+}
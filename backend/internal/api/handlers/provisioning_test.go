@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func multipartRoster(t *testing.T, content string) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "roster.csv")
+	assert.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+	return body, writer.FormDataContentType()
+}
+
+func TestProvision(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	mockUserService := mocks.MockUserService{}
+	handler := NewUserHandler(&mockUserService, &mocks.MockAuthService{}, &mocks.MockTokenService{}, &mocks.MockBanService{}, &mocks.MockMailService{}, &mocks.MockPasswordService{}, auditRecorder())
+
+	mockUserService.On("ProvisionUser", data.UserProvision{Email: "taken@example.com"}).Return(nil, "", services.ErrDuplicateEmail)
+	mockUserService.On("ProvisionUser", mock.Anything).Return(&data.User{ID: uuid.New(), Username: "student1234", IsActivated: true, PasswordResetRequired: true}, "generated", nil)
+
+	multipartBody, multipartType := multipartRoster(t, "name,email,username\nAnn,ann@example.com,ann\n")
+
+	tests := map[string]struct {
+		contentType string
+		reqBody     string
+		body        *bytes.Buffer
+		wantCode    int
+		wantError   bool
+		wantCreated int
+		wantFailed  int
+	}{
+		"Invalid request body": {
+			contentType: echo.MIMEApplicationJSON,
+			reqBody:     `{"users":`,
+			wantCode:    http.StatusBadRequest,
+			wantError:   true,
+		},
+		"Empty roster": {
+			contentType: echo.MIMEApplicationJSON,
+			reqBody:     `{"users":[]}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Invalid email": {
+			contentType: echo.MIMEApplicationJSON,
+			reqBody:     `{"users":[{"email":"not-an-email"}]}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"CSV without email column": {
+			contentType: "text/csv",
+			reqBody:     "name,username\nAnn,ann\n",
+			wantCode:    http.StatusBadRequest,
+			wantError:   true,
+		},
+		"JSON roster with duplicate": {
+			contentType: echo.MIMEApplicationJSON,
+			reqBody:     `{"users":[{"email":"new@example.com"},{"email":"taken@example.com"}]}`,
+			wantCode:    http.StatusOK,
+			wantCreated: 1,
+			wantFailed:  1,
+		},
+		"CSV roster": {
+			contentType: "text/csv",
+			reqBody:     "email,username\nann@example.com,ann\n\nbob@example.com,\n",
+			wantCode:    http.StatusOK,
+			wantCreated: 2,
+		},
+		"Multipart CSV upload": {
+			contentType: multipartType,
+			body:        multipartBody,
+			wantCode:    http.StatusOK,
+			wantCreated: 1,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			body := tt.body
+			if body == nil {
+				body = bytes.NewBufferString(tt.reqBody)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/", body)
+			req.Header.Set(echo.HeaderContentType, tt.contentType)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.Provision(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+
+				var response struct {
+					Created int                    `json:"created"`
+					Failed  int                    `json:"failed"`
+					Results []data.ProvisionResult `json:"results"`
+				}
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, tt.wantCreated, response.Created)
+				assert.Equal(t, tt.wantFailed, response.Failed)
+
+				for _, result := range response.Results {
+					if result.Error == "" {
+						assert.Equal(t, "generated", result.Password)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDeprovision(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	mockUserService := mocks.MockUserService{}
+	mockTokenService := mocks.MockTokenService{}
+	handler := NewUserHandler(&mockUserService, &mocks.MockAuthService{}, &mockTokenService, &mocks.MockBanService{}, &mocks.MockMailService{}, &mocks.MockPasswordService{}, auditRecorder())
+
+	student := &data.User{ID: uuid.New(), Email: "ann@example.com", Username: "ann"}
+
+	mockUserService.On("DeprovisionUser", "ann@example.com").Return(student, nil)
+	mockUserService.On("DeprovisionUser", "missing@example.com").Return(nil, services.ErrUserNotFound)
+	mockTokenService.On("DeleteAllForUser", data.ScopeRefresh, student.ID).Return(nil)
+
+	tests := map[string]struct {
+		contentType     string
+		reqBody         string
+		wantCode        int
+		wantError       bool
+		wantDeactivated int
+		wantFailed      int
+	}{
+		"Empty roster": {
+			contentType: "text/csv",
+			reqBody:     "email\n",
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Roster with unknown user": {
+			contentType:     echo.MIMEApplicationJSON,
+			reqBody:         `{"users":[{"email":"ann@example.com"},{"email":"missing@example.com"}]}`,
+			wantCode:        http.StatusOK,
+			wantDeactivated: 1,
+			wantFailed:      1,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, tt.contentType)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.Deprovision(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+
+				var response struct {
+					Deactivated int `json:"deactivated"`
+					Failed      int `json:"failed"`
+				}
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, tt.wantDeactivated, response.Deactivated)
+				assert.Equal(t, tt.wantFailed, response.Failed)
+				mockTokenService.AssertExpectations(t)
+			}
+		})
+	}
+}
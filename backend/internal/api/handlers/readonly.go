@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services/audit"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ReadOnlyHandler handles HTTP requests to look up and switch the read-only mode the API falls back to
+// while the primary database fails over or is under maintenance.
+type ReadOnlyHandler struct {
+	status       func() data.ReadOnlyStatus
+	setReadOnly  func(ctx context.Context, enabled bool, reason string) (data.ReadOnlyStatus, error)
+	auditService audit.IAuditService
+}
+
+// NewReadOnlyHandler creates a new ReadOnlyHandler reading the mode from status and switching it with setReadOnly.
+func NewReadOnlyHandler(status func() data.ReadOnlyStatus, setReadOnly func(ctx context.Context, enabled bool, reason string) (data.ReadOnlyStatus, error), auditService audit.IAuditService) ReadOnlyHandler {
+	return ReadOnlyHandler{
+		status:       status,
+		setReadOnly:  setReadOnly,
+		auditService: auditService,
+	}
+}
+
+// Get handles the request to retrieve whether the API is in read-only mode, so clients can tell users up front.
+func (h *ReadOnlyHandler) Get(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"read_only": h.status(),
+	})
+}
+
+// Set handles the request to switch read-only mode of every instance on or off.
+//
+// Unlike other privileged actions, switching on still happens when it can't be recorded in the audit log or shared
+// with the other instances: read-only mode is for when the primary database is unavailable, and both live in it.
+// Switching off needs the primary database and is recorded afterwards, once writes reach it again.
+func (h *ReadOnlyHandler) Set(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var payload data.ReadOnlyUpdate
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	entry := data.AuditEntry{
+		ActorID:    &contextUser.ID,
+		Action:     data.AuditReadOnlyDisable,
+		TargetType: "system",
+		TargetID:   "read_only",
+		Details:    map[string]interface{}{},
+		IP:         c.RealIP(),
+	}
+
+	if *payload.Enabled {
+		entry.Action = data.AuditReadOnlyEnable
+		entry.Details["reason"] = payload.Reason
+		if err := h.auditService.Record(entry); err != nil {
+			logging.Error(c.Request().Context(), "Internal audit log error, switching to read-only mode anyway", err)
+		}
+	}
+
+	status, err := h.setReadOnly(c.Request().Context(), *payload.Enabled, payload.Reason)
+	if err != nil {
+		if !*payload.Enabled {
+			logging.Error(c.Request().Context(), "Internal read-only mode switch error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to switch read-only mode off")
+		}
+		logging.Error(c.Request().Context(), "Internal read-only mode sharing error, switched on this instance until the primary database is back", err)
+	}
+
+	if !*payload.Enabled {
+		if err := h.auditService.Record(entry); err != nil {
+			logging.Error(c.Request().Context(), "Internal audit log error", err)
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"read_only": status,
+	})
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSetReadOnly(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	admin := &data.User{ID: uuid.New(), Username: "admin", IsActivated: true}
+	audited := func(action string, err error) func(a *mocks.MockAuditService) {
+		return func(a *mocks.MockAuditService) {
+			a.On("Record", mock.MatchedBy(func(entry data.AuditEntry) bool {
+				return entry.Action == action && *entry.ActorID == admin.ID
+			})).Return(err)
+		}
+	}
+
+	tests := map[string]struct {
+		body        string
+		setupMocks  func(a *mocks.MockAuditService)
+		shareErr    error
+		wantCode    int
+		wantError   bool
+		wantEnabled bool
+	}{
+		"Missing enabled": {
+			body:      `{"reason":"failover"}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Switched on": {
+			body:        `{"enabled":true,"reason":"failover"}`,
+			setupMocks:  audited(data.AuditReadOnlyEnable, nil),
+			wantCode:    http.StatusOK,
+			wantEnabled: true,
+		},
+		"Switched on while the audit log is unavailable": {
+			body:        `{"enabled":true,"reason":"failover"}`,
+			setupMocks:  audited(data.AuditReadOnlyEnable, errors.New("connection refused")),
+			wantCode:    http.StatusOK,
+			wantEnabled: true,
+		},
+		"Switched on while the primary database is unavailable": {
+			body:        `{"enabled":true,"reason":"failover"}`,
+			setupMocks:  audited(data.AuditReadOnlyEnable, errors.New("connection refused")),
+			shareErr:    errors.New("connection refused"),
+			wantCode:    http.StatusOK,
+			wantEnabled: true,
+		},
+		"Switched off": {
+			body:       `{"enabled":false}`,
+			setupMocks: audited(data.AuditReadOnlyDisable, nil),
+			wantCode:   http.StatusOK,
+		},
+		"Switched off while the primary database is unavailable": {
+			body:      `{"enabled":false}`,
+			shareErr:  errors.New("connection refused"),
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockAuditService := mocks.MockAuditService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockAuditService)
+			}
+			var current data.ReadOnlyStatus
+			setReadOnly := func(ctx context.Context, enabled bool, reason string) (data.ReadOnlyStatus, error) {
+				// like the mirror, switching off needs the primary database
+				if tt.shareErr == nil || enabled {
+					current = data.ReadOnlyStatus{Enabled: enabled, Reason: reason}
+				}
+				return current, tt.shareErr
+			}
+			handler := NewReadOnlyHandler(func() data.ReadOnlyStatus { return current }, setReadOnly, &mockAuditService)
+
+			req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", admin)
+
+			err := handler.Set(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Equal(t, tt.wantEnabled, current.Enabled)
+			}
+			mockAuditService.AssertExpectations(t)
+		})
+	}
+}
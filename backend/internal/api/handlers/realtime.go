@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services/realtime"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RealtimeHandler handles the stream of events pushed to the open sessions of a user.
+type RealtimeHandler struct {
+	realtimeService realtime.IRealtimeService
+	keepAlive       time.Duration
+}
+
+// NewRealtimeHandler creates a new RealtimeHandler with the provided service.
+func NewRealtimeHandler(realtimeService realtime.IRealtimeService) RealtimeHandler {
+	return RealtimeHandler{
+		realtimeService: realtimeService,
+		// proxies close connections idle for a minute
+		keepAlive: 30 * time.Second,
+	}
+}
+
+// Stream handles the request to receive the events of the current user as server-sent events,
+// until the client disconnects or the server shuts down. The client names the newest protocol version it speaks
+// in the protocol query parameter, the stream starts with a hello event telling the version it speaks.
+func (h *RealtimeHandler) Stream(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	protocol, err := realtime.Negotiate(c.QueryParam("protocol"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unsupported realtime protocol, versions %d to %d are supported", realtime.MinProtocol, realtime.MaxProtocol))
+	}
+
+	hello, err := json.Marshal(data.RealtimeHello{
+		Protocol:    protocol,
+		MinProtocol: realtime.MinProtocol,
+		MaxProtocol: realtime.MaxProtocol,
+		Events:      realtime.EventTypes(protocol),
+	})
+	if err != nil {
+		return err
+	}
+
+	events, unsubscribe := h.realtimeService.Subscribe(contextUser.ID)
+	defer unsubscribe()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	// keeps nginx from buffering the stream
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	fmt.Fprintf(res, "event: %s\ndata: %s\n\n", data.EventHello, hello)
+	res.Flush()
+
+	ticker := time.NewTicker(h.keepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			// newer events would only confuse clients speaking an older version
+			if !realtime.Supports(protocol, event.Type) {
+				continue
+			}
+			fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event.Type, event.Data)
+			res.Flush()
+		case <-ticker.C:
+			fmt.Fprint(res, ": keep-alive\n\n")
+			res.Flush()
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRealtimeStream(t *testing.T) {
+	e := echo.New()
+	user := &data.User{ID: uuid.New(), Username: "user", IsActivated: true}
+
+	t.Run("Not authenticated", func(t *testing.T) {
+		handler := NewRealtimeHandler(&mocks.MockRealtimeService{})
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/api/users/me/events", nil), httptest.NewRecorder())
+
+		err := handler.Stream(c)
+
+		if assert.Error(t, err) {
+			assert.Equal(t, http.StatusUnauthorized, err.(*echo.HTTPError).Code)
+		}
+	})
+
+	t.Run("Streams events until the subscription ends", func(t *testing.T) {
+		events := make(chan data.RealtimeEvent, 1)
+		events <- data.RealtimeEvent{Type: data.EventEditConflict, Data: json.RawMessage(`{"version":4}`)}
+		close(events)
+
+		unsubscribed := false
+		mockRealtimeService := mocks.MockRealtimeService{}
+		mockRealtimeService.On("Subscribe", user.ID).Return((<-chan data.RealtimeEvent)(events), func() { unsubscribed = true })
+
+		handler := NewRealtimeHandler(&mockRealtimeService)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/api/users/me/events", nil), rec)
+		c.Set("user", user)
+
+		err := handler.Stream(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "text/event-stream", rec.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, "event: hello\ndata: {\"protocol\":1,\"min_protocol\":1,\"max_protocol\":1,\"events\":[\"notification\",\"project.edit_conflict\"]}\n\n"+
+			"event: project.edit_conflict\ndata: {\"version\":4}\n\n", rec.Body.String())
+		assert.True(t, unsubscribed)
+	})
+
+	t.Run("Newer clients are spoken to in the newest version of the server", func(t *testing.T) {
+		events := make(chan data.RealtimeEvent)
+		close(events)
+
+		mockRealtimeService := mocks.MockRealtimeService{}
+		mockRealtimeService.On("Subscribe", user.ID).Return((<-chan data.RealtimeEvent)(events), func() {})
+
+		handler := NewRealtimeHandler(&mockRealtimeService)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/api/users/me/events?protocol=99", nil), rec)
+		c.Set("user", user)
+
+		err := handler.Stream(c)
+
+		assert.NoError(t, err)
+		assert.Contains(t, rec.Body.String(), `"protocol":1,`)
+	})
+
+	t.Run("Unsupported protocol", func(t *testing.T) {
+		for _, protocol := range []string{"0", "v1"} {
+			handler := NewRealtimeHandler(&mocks.MockRealtimeService{})
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/api/users/me/events?protocol="+protocol, nil), httptest.NewRecorder())
+			c.Set("user", user)
+
+			err := handler.Stream(c)
+
+			if assert.Error(t, err) {
+				assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+			}
+		}
+	})
+
+	t.Run("Events unknown to the protocol are left out", func(t *testing.T) {
+		events := make(chan data.RealtimeEvent, 1)
+		events <- data.RealtimeEvent{Type: "project.future", Data: json.RawMessage(`{}`)}
+		close(events)
+
+		mockRealtimeService := mocks.MockRealtimeService{}
+		mockRealtimeService.On("Subscribe", user.ID).Return((<-chan data.RealtimeEvent)(events), func() {})
+
+		handler := NewRealtimeHandler(&mockRealtimeService)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/api/users/me/events", nil), rec)
+		c.Set("user", user)
+
+		err := handler.Stream(c)
+
+		assert.NoError(t, err)
+		assert.NotContains(t, rec.Body.String(), "project.future")
+	})
+}
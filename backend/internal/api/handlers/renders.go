@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services/renders"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RenderHandler handles HTTP requests for the render queue and the render workers processing it.
+type RenderHandler struct {
+	renderService renders.IRenderService
+}
+
+// NewRenderHandler creates a new RenderHandler with the provided service.
+func NewRenderHandler(renderService renders.IRenderService) RenderHandler {
+	return RenderHandler{
+		renderService: renderService,
+	}
+}
+
+// Status handles the request to retrieve the depth of the render queue and the registered render workers with their load.
+func (h *RenderHandler) Status(c echo.Context) error {
+	status, err := h.renderService.GetStatus(c.Request().Context())
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal render status retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve render status")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status": status,
+	})
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderStatus(t *testing.T) {
+	e := echo.New()
+
+	tests := map[string]struct {
+		status    *data.RenderStatus
+		err       error
+		wantCode  int
+		wantError bool
+	}{
+		"Status collected": {
+			status: &data.RenderStatus{
+				Queue:   data.RenderQueueStats{Pending: 4, Running: 2, OldestPending: 1.5},
+				Workers: []data.RenderWorker{{ID: "render-1-a1b2c3d4", Hostname: "render-1", Concurrency: 2, Busy: 2, HeartbeatAt: time.Now()}},
+			},
+			wantCode: http.StatusOK,
+		},
+		"Database error": {
+			err:       errors.New("database error"),
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockRenderService := mocks.MockRenderService{}
+			mockRenderService.On("GetStatus").Return(tt.status, tt.err)
+			handler := NewRenderHandler(&mockRenderService)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.Status(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"oldest_pending_seconds":1.5`)
+				assert.Contains(t, rec.Body.String(), `"busy":2`)
+			}
+			mockRenderService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/audit"
+	"NodeTurtleAPI/internal/services/mail"
+	"NodeTurtleAPI/internal/services/projects"
+	"NodeTurtleAPI/internal/services/reports"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// ReportHandler handles HTTP requests to report projects and the moderation of reported projects.
+type ReportHandler struct {
+	reportService  reports.IReportService
+	projectService projects.IProjectService
+	mailService    mail.IMailService
+	auditService   audit.IAuditService
+}
+
+// NewReportHandler creates a new ReportHandler with the provided services.
+func NewReportHandler(reportService reports.IReportService, projectService projects.IProjectService, mailService mail.IMailService, auditService audit.IAuditService) ReportHandler {
+	return ReportHandler{
+		reportService:  reportService,
+		projectService: projectService,
+		mailService:    mailService,
+		auditService:   auditService,
+	}
+}
+
+// Report handles the request to report a project the current user can see to the moderators.
+func (h *ReportHandler) Report(c echo.Context) error {
+	contextUser, err := activatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	var payload data.ProjectReportSubmission
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	project, err := h.projectService.GetProject(c.Request().Context(), projectID, &contextUser.ID)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		logging.Error(c.Request().Context(), "Internal project retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to report project")
+	}
+
+	if project.CreatorID == contextUser.ID {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, "You cannot report your own project")
+	}
+
+	report, err := h.reportService.ReportProject(c.Request().Context(), projectID, contextUser.ID, payload)
+	if err != nil {
+		if errors.Is(err, services.ErrAlreadyReported) {
+			return echo.NewHTTPError(http.StatusConflict, "You already reported this project")
+		}
+		logging.Error(c.Request().Context(), "Internal project report error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to report project")
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"report": report,
+	})
+}
+
+// ListOpen handles the request to retrieve the reports waiting for review.
+func (h *ReportHandler) ListOpen(c echo.Context) error {
+	reports, err := h.reportService.ListOpenReports(c.Request().Context())
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal report listing error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve reports")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"reports": reports,
+	})
+}
+
+// Dismiss handles the request to close an open report without acting on the project.
+// The decision is recorded in the audit log before it's saved.
+func (h *ReportHandler) Dismiss(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	reportID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid report ID")
+	}
+
+	report, err := h.reportService.GetReport(c.Request().Context(), reportID)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Report not found")
+		}
+		logging.Error(c.Request().Context(), "Internal report retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to dismiss report")
+	}
+
+	if report.Status != data.ReportOpen {
+		return echo.NewHTTPError(http.StatusConflict, "Report was already resolved")
+	}
+
+	err = h.auditService.Record(data.AuditEntry{
+		ActorID:    &contextUser.ID,
+		Action:     data.AuditReportDismiss,
+		TargetType: "project",
+		TargetID:   report.ProjectID.String(),
+		Details: map[string]interface{}{
+			"report_id": report.ID,
+			"reason":    report.Reason,
+		},
+		IP: c.RealIP(),
+	})
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal audit log error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to record report dismissal")
+	}
+
+	report, err = h.reportService.DismissReport(c.Request().Context(), reportID, contextUser.ID)
+	if err != nil {
+		// resolved by another moderator in the meantime
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusConflict, "Report was already resolved")
+		}
+		logging.Error(c.Request().Context(), "Internal report dismissal error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to dismiss report")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"report": report,
+	})
+}
+
+// TakeDown handles the request to hide a project from everyone but its creator and resolve its open reports.
+// The decision is recorded in the audit log before it's saved, and the creator is notified by email with the reason.
+func (h *ReportHandler) TakeDown(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	var payload data.ProjectTakedownRequest
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	err = h.auditService.Record(data.AuditEntry{
+		ActorID:    &contextUser.ID,
+		Action:     data.AuditProjectTakedown,
+		TargetType: "project",
+		TargetID:   projectID.String(),
+		Details: map[string]interface{}{
+			"reason": payload.Reason,
+		},
+		IP: c.RealIP(),
+	})
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal audit log error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to record project takedown")
+	}
+
+	takedown, err := h.reportService.TakeDownProject(c.Request().Context(), projectID, contextUser.ID, payload.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRecordNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		case errors.Is(err, services.ErrAlreadyTakenDown):
+			return echo.NewHTTPError(http.StatusConflict, "Project was already taken down")
+		}
+		logging.Error(c.Request().Context(), "Internal project takedown error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to take down project")
+	}
+
+	emailData := map[string]string{
+		"Username": takedown.CreatorUsername,
+		"Title":    takedown.Title,
+		"Reason":   takedown.Reason,
+	}
+	if err := h.mailService.QueueEmail(c.Request().Context(), takedown.CreatorEmail, "Project Taken Down - Turtle Graphics", "takedown", emailData); err != nil {
+		logging.Error(c.Request().Context(), "Failed to queue takedown email", err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"takedown": takedown,
+	})
+}
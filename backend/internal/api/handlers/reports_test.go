@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestReportProject(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	user := &data.User{ID: uuid.New(), Username: "user", IsActivated: true}
+	projectID := uuid.New()
+	project := &data.Project{ID: projectID, CreatorID: uuid.New(), IsPublic: true}
+	submission := data.ProjectReportSubmission{Reason: data.ReportSpam, Details: "Link farm"}
+
+	tests := map[string]struct {
+		contextUser *data.User
+		projectID   string
+		body        string
+		setupMocks  func(r *mocks.MockReportService, p *mocks.MockProjectService)
+		wantCode    int
+		wantError   bool
+	}{
+		"Not authenticated": {
+			projectID: projectID.String(),
+			body:      `{"reason":"spam"}`,
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Invalid project ID": {
+			contextUser: user,
+			projectID:   "invalid",
+			body:        `{"reason":"spam"}`,
+			wantCode:    http.StatusBadRequest,
+			wantError:   true,
+		},
+		"Unknown reason": {
+			contextUser: user,
+			projectID:   projectID.String(),
+			body:        `{"reason":"boring"}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Project not visible": {
+			contextUser: user,
+			projectID:   projectID.String(),
+			body:        `{"reason":"spam"}`,
+			setupMocks: func(r *mocks.MockReportService, p *mocks.MockProjectService) {
+				p.On("GetProject", projectID, &user.ID).Return(nil, services.ErrRecordNotFound)
+			},
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Own project": {
+			contextUser: user,
+			projectID:   projectID.String(),
+			body:        `{"reason":"spam"}`,
+			setupMocks: func(r *mocks.MockReportService, p *mocks.MockProjectService) {
+				p.On("GetProject", projectID, &user.ID).Return(&data.Project{ID: projectID, CreatorID: user.ID}, nil)
+			},
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Already reported": {
+			contextUser: user,
+			projectID:   projectID.String(),
+			body:        `{"reason":"spam","details":"Link farm"}`,
+			setupMocks: func(r *mocks.MockReportService, p *mocks.MockProjectService) {
+				p.On("GetProject", projectID, &user.ID).Return(project, nil)
+				r.On("ReportProject", projectID, user.ID, submission).Return(nil, services.ErrAlreadyReported)
+			},
+			wantCode:  http.StatusConflict,
+			wantError: true,
+		},
+		"Successful report": {
+			contextUser: user,
+			projectID:   projectID.String(),
+			body:        `{"reason":"spam","details":"Link farm"}`,
+			setupMocks: func(r *mocks.MockReportService, p *mocks.MockProjectService) {
+				p.On("GetProject", projectID, &user.ID).Return(project, nil)
+				r.On("ReportProject", projectID, user.ID, submission).Return(&data.ProjectReport{ID: 1, ProjectID: projectID, Status: data.ReportOpen}, nil)
+			},
+			wantCode: http.StatusCreated,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockReportService := mocks.MockReportService{}
+			mockProjectService := mocks.MockProjectService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockReportService, &mockProjectService)
+			}
+			handler := NewReportHandler(&mockReportService, &mockProjectService, &mocks.MockMailService{}, &mocks.MockAuditService{})
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.projectID)
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.Report(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+			mockReportService.AssertExpectations(t)
+			mockProjectService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDismissReport(t *testing.T) {
+	e := echo.New()
+
+	moderator := &data.User{ID: uuid.New(), Username: "moderator", IsActivated: true}
+	projectID := uuid.New()
+	openReport := &data.ProjectReport{ID: 1, ProjectID: projectID, Reason: data.ReportSpam, Status: data.ReportOpen}
+
+	tests := map[string]struct {
+		reportID   string
+		setupMocks func(r *mocks.MockReportService, a *mocks.MockAuditService)
+		wantCode   int
+		wantError  bool
+	}{
+		"Invalid report ID": {
+			reportID:  "abc",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Report not found": {
+			reportID: "1",
+			setupMocks: func(r *mocks.MockReportService, a *mocks.MockAuditService) {
+				r.On("GetReport", int64(1)).Return(nil, services.ErrRecordNotFound)
+			},
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Report already resolved": {
+			reportID: "1",
+			setupMocks: func(r *mocks.MockReportService, a *mocks.MockAuditService) {
+				r.On("GetReport", int64(1)).Return(&data.ProjectReport{ID: 1, Status: data.ReportActioned}, nil)
+			},
+			wantCode:  http.StatusConflict,
+			wantError: true,
+		},
+		"Report dismissed": {
+			reportID: "1",
+			setupMocks: func(r *mocks.MockReportService, a *mocks.MockAuditService) {
+				r.On("GetReport", int64(1)).Return(openReport, nil)
+				a.On("Record", mock.MatchedBy(func(entry data.AuditEntry) bool {
+					return entry.Action == data.AuditReportDismiss && entry.TargetID == projectID.String()
+				})).Return(nil)
+				r.On("DismissReport", int64(1), moderator.ID).Return(&data.ProjectReport{ID: 1, ProjectID: projectID, Status: data.ReportDismissed}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockReportService := mocks.MockReportService{}
+			mockAuditService := mocks.MockAuditService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockReportService, &mockAuditService)
+			}
+			handler := NewReportHandler(&mockReportService, &mocks.MockProjectService{}, &mocks.MockMailService{}, &mockAuditService)
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.reportID)
+			c.Set("user", moderator)
+
+			err := handler.Dismiss(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+			mockReportService.AssertExpectations(t)
+			mockAuditService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTakeDownProject(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	moderator := &data.User{ID: uuid.New(), Username: "moderator", IsActivated: true}
+	projectID := uuid.New()
+	takedown := &data.ProjectTakedown{
+		ProjectID:       projectID,
+		Title:           "Spam",
+		CreatorUsername: "creator",
+		CreatorEmail:    "creator@example.com",
+		Reason:          "Link farm",
+		ReportsResolved: 2,
+		HiddenAt:        time.Now(),
+	}
+	audited := func(a *mocks.MockAuditService) {
+		a.On("Record", mock.MatchedBy(func(entry data.AuditEntry) bool {
+			return entry.Action == data.AuditProjectTakedown && entry.TargetID == projectID.String()
+		})).Return(nil)
+	}
+
+	tests := map[string]struct {
+		body       string
+		setupMocks func(r *mocks.MockReportService, a *mocks.MockAuditService, m *mocks.MockMailService, sent chan struct{})
+		wantCode   int
+		wantError  bool
+	}{
+		"Missing reason": {
+			body:      `{}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Project not found": {
+			body: `{"reason":"Link farm"}`,
+			setupMocks: func(r *mocks.MockReportService, a *mocks.MockAuditService, m *mocks.MockMailService, sent chan struct{}) {
+				audited(a)
+				r.On("TakeDownProject", projectID, moderator.ID, "Link farm").Return(nil, services.ErrRecordNotFound)
+			},
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Already taken down": {
+			body: `{"reason":"Link farm"}`,
+			setupMocks: func(r *mocks.MockReportService, a *mocks.MockAuditService, m *mocks.MockMailService, sent chan struct{}) {
+				audited(a)
+				r.On("TakeDownProject", projectID, moderator.ID, "Link farm").Return(nil, services.ErrAlreadyTakenDown)
+			},
+			wantCode:  http.StatusConflict,
+			wantError: true,
+		},
+		"Creator is notified": {
+			body: `{"reason":"Link farm"}`,
+			setupMocks: func(r *mocks.MockReportService, a *mocks.MockAuditService, m *mocks.MockMailService, sent chan struct{}) {
+				audited(a)
+				r.On("TakeDownProject", projectID, moderator.ID, "Link farm").Return(takedown, nil)
+				m.On("QueueEmail", "creator@example.com", mock.Anything, "takedown", mock.MatchedBy(func(data map[string]string) bool {
+					return data["Title"] == "Spam" && data["Reason"] == "Link farm"
+				})).Run(func(mock.Arguments) { close(sent) }).Return(nil)
+			},
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockReportService := mocks.MockReportService{}
+			mockAuditService := mocks.MockAuditService{}
+			mockMailService := mocks.MockMailService{}
+			sent := make(chan struct{})
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockReportService, &mockAuditService, &mockMailService, sent)
+			}
+			handler := NewReportHandler(&mockReportService, &mocks.MockProjectService{}, &mockMailService, &mockAuditService)
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(projectID.String())
+			c.Set("user", moderator)
+
+			err := handler.TakeDown(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				// the email is sent in the background
+				select {
+				case <-sent:
+				case <-time.After(time.Second):
+					t.Error("takedown email was not sent")
+				}
+			}
+			mockReportService.AssertExpectations(t)
+			mockAuditService.AssertExpectations(t)
+			mockMailService.AssertExpectations(t)
+		})
+	}
+}
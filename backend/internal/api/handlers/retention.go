@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services/audit"
+	"NodeTurtleAPI/internal/services/retention"
+
+	"github.com/labstack/echo/v4"
+)
+
+// retentionRunsLimit is the maximum number of purges returned at once.
+const retentionRunsLimit = 500
+
+// RetentionHandler handles HTTP requests to review the data retention policies and their enforcement.
+type RetentionHandler struct {
+	retentionService retention.IRetentionService
+	auditService     audit.IAuditService
+}
+
+// NewRetentionHandler creates a new RetentionHandler with the provided services.
+func NewRetentionHandler(retentionService retention.IRetentionService, auditService audit.IAuditService) RetentionHandler {
+	return RetentionHandler{
+		retentionService: retentionService,
+		auditService:     auditService,
+	}
+}
+
+// ListClasses handles the request to retrieve every data class with its retention period and latest purge.
+func (h *RetentionHandler) ListClasses(c echo.Context) error {
+	classes, err := h.retentionService.Classes(c.Request().Context())
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal retention policy retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve retention policies")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"classes": classes,
+	})
+}
+
+// ListRuns handles the request to retrieve the latest purges, optionally of a single class.
+func (h *RetentionHandler) ListRuns(c echo.Context) error {
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 || limit > retentionRunsLimit {
+		limit = 50
+	}
+
+	runs, err := h.retentionService.ListRuns(c.Request().Context(), c.QueryParam("class"), limit)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal retention run retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve retention runs")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"runs": runs,
+	})
+}
+
+// Purge handles the request to delete expired data right away instead of waiting for the scheduled purge.
+// The purge is recorded in the audit log before it starts.
+func (h *RetentionHandler) Purge(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	err := h.auditService.Record(data.AuditEntry{
+		ActorID:    &contextUser.ID,
+		Action:     data.AuditRetentionPurge,
+		TargetType: "retention",
+		IP:         c.RealIP(),
+	})
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal audit log error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to record retention purge")
+	}
+
+	runs, err := h.retentionService.Purge(c.Request().Context(), data.RetentionManual, &contextUser.ID)
+	if err != nil {
+		// the runs that failed record their error, the others still happened
+		logging.Error(c.Request().Context(), "Internal retention purge error", err)
+		if len(runs) == 0 {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to purge expired data")
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"runs": runs,
+	})
+}
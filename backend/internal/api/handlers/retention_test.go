@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPurgeRetention(t *testing.T) {
+	e := echo.New()
+
+	admin := &data.User{ID: uuid.New(), Username: "admin", IsActivated: true}
+	audited := func(a *mocks.MockAuditService) {
+		a.On("Record", mock.MatchedBy(func(entry data.AuditEntry) bool {
+			return entry.Action == data.AuditRetentionPurge && *entry.ActorID == admin.ID
+		})).Return(nil)
+	}
+
+	tests := map[string]struct {
+		contextUser *data.User
+		setupMocks  func(r *mocks.MockRetentionService, a *mocks.MockAuditService)
+		wantCode    int
+		wantError   bool
+	}{
+		"Not authenticated": {
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Audit log failure": {
+			contextUser: admin,
+			setupMocks: func(r *mocks.MockRetentionService, a *mocks.MockAuditService) {
+				a.On("Record", mock.Anything).Return(errors.New("database error"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+		"Purge is audited": {
+			contextUser: admin,
+			setupMocks: func(r *mocks.MockRetentionService, a *mocks.MockAuditService) {
+				audited(a)
+				r.On("Purge", data.RetentionManual, &admin.ID).Return([]data.RetentionRun{{Class: data.RetentionProjectViews, Deleted: 12}}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		"Failed classes are reported in their runs": {
+			contextUser: admin,
+			setupMocks: func(r *mocks.MockRetentionService, a *mocks.MockAuditService) {
+				audited(a)
+				r.On("Purge", data.RetentionManual, &admin.ID).Return([]data.RetentionRun{{Class: data.RetentionProjectViews, Error: "timeout"}}, errors.New("timeout"))
+			},
+			wantCode: http.StatusOK,
+		},
+		"Nothing could be purged": {
+			contextUser: admin,
+			setupMocks: func(r *mocks.MockRetentionService, a *mocks.MockAuditService) {
+				audited(a)
+				r.On("Purge", data.RetentionManual, &admin.ID).Return([]data.RetentionRun{}, errors.New("database error"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockRetentionService := mocks.MockRetentionService{}
+			mockAuditService := mocks.MockAuditService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockRetentionService, &mockAuditService)
+			}
+			handler := NewRetentionHandler(&mockRetentionService, &mockAuditService)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/retention/purge", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			if tt.contextUser != nil {
+				c.Set("user", tt.contextUser)
+			}
+
+			err := handler.Purge(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+			mockRetentionService.AssertExpectations(t)
+			mockAuditService.AssertExpectations(t)
+		})
+	}
+}
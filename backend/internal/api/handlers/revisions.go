@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/projects"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// RevisionHandler handles HTTP requests related to the revision history of projects.
+type RevisionHandler struct {
+	projectService projects.IProjectService
+}
+
+// NewRevisionHandler creates a new RevisionHandler with the provided services.
+func NewRevisionHandler(projectService projects.IProjectService) RevisionHandler {
+	return RevisionHandler{
+		projectService: projectService,
+	}
+}
+
+// ownedProject parses the project ID from the path and checks the current user owns the project.
+func (h *RevisionHandler) ownedProject(c echo.Context) (uuid.UUID, error) {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	isOwner, err := h.projectService.IsOwner(c.Request().Context(), projectID, contextUser.ID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal project ownership check error", err)
+		return uuid.Nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to check project ownership")
+	}
+	if !isOwner {
+		return uuid.Nil, echo.NewHTTPError(http.StatusForbidden, "You do not have permission to view the history of this project")
+	}
+
+	return projectID, nil
+}
+
+// List handles the request to list the revisions of a project, newest first.
+func (h *RevisionHandler) List(c echo.Context) error {
+	projectID, err := h.ownedProject(c)
+	if err != nil {
+		return err
+	}
+
+	revisions, err := h.projectService.ListRevisions(c.Request().Context(), projectID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal revision retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve revisions")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"revisions": revisions,
+	})
+}
+
+// Get handles the request to retrieve a single revision of a project, including its flow.
+func (h *RevisionHandler) Get(c echo.Context) error {
+	projectID, err := h.ownedProject(c)
+	if err != nil {
+		return err
+	}
+
+	revision, err := h.revision(c, projectID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"revision": revision,
+	})
+}
+
+// Restore handles the request to bring a project back to one of its revisions.
+// The version being replaced is kept as a new revision, so a restore can be undone.
+func (h *RevisionHandler) Restore(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	if !contextUser.IsActivated {
+		return echo.NewHTTPError(http.StatusForbidden, "Account is not activated")
+	}
+
+	projectID, err := h.ownedProject(c)
+	if err != nil {
+		return err
+	}
+
+	revision, err := h.revision(c, projectID)
+	if err != nil {
+		return err
+	}
+
+	project, err := h.projectService.UpdateProject(c.Request().Context(), data.ProjectUpdate{
+		ID:          projectID,
+		Title:       &revision.Title,
+		Description: &revision.Description,
+		Data:        revision.Data,
+	})
+	if err != nil {
+		if he := quotaExceeded(err); he != nil {
+			return he
+		}
+		logging.Error(c.Request().Context(), "Internal revision restore error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to restore revision")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"project": project,
+	})
+}
+
+// revision loads the revision of the project named by the revisionID path parameter.
+func (h *RevisionHandler) revision(c echo.Context, projectID uuid.UUID) (*data.ProjectRevision, error) {
+	revisionID, err := strconv.ParseInt(c.Param("revisionID"), 10, 64)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid revision ID")
+	}
+
+	revision, err := h.projectService.GetRevision(c.Request().Context(), projectID, revisionID)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Revision not found")
+		}
+		logging.Error(c.Request().Context(), "Internal revision retrieval error", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve revision")
+	}
+
+	return revision, nil
+}
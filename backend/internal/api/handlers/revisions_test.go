@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRestoreRevision(t *testing.T) {
+	e := echo.New()
+
+	owner := &data.User{ID: uuid.New(), Username: "owner", IsActivated: true}
+	projectID := uuid.New()
+	otherProjectID := uuid.New()
+	revision := &data.ProjectRevision{ID: 7, ProjectID: projectID, Title: "Spiral v1", Description: "", Data: json.RawMessage(`{"nodes":[],"edges":[]}`)}
+
+	mockProjectService := mocks.MockProjectService{}
+	mockProjectService.On("IsOwner", projectID, owner.ID).Return(true, nil)
+	mockProjectService.On("IsOwner", otherProjectID, owner.ID).Return(false, nil)
+	mockProjectService.On("GetRevision", projectID, int64(7)).Return(revision, nil)
+	mockProjectService.On("GetRevision", projectID, int64(8)).Return(nil, services.ErrRecordNotFound)
+	mockProjectService.On("UpdateProject", mock.MatchedBy(func(u data.ProjectUpdate) bool {
+		return u.ID == projectID && *u.Title == revision.Title && string(u.Data) == string(revision.Data) && !u.DryRun
+	})).Return(&data.Project{ID: projectID, Title: revision.Title, Data: revision.Data}, nil)
+
+	handler := NewRevisionHandler(&mockProjectService)
+
+	tests := map[string]struct {
+		projectID  string
+		revisionID string
+		wantCode   int
+		wantError  bool
+	}{
+		"Revision restored": {
+			projectID:  projectID.String(),
+			revisionID: "7",
+			wantCode:   http.StatusOK,
+		},
+		"Unknown revision": {
+			projectID:  projectID.String(),
+			revisionID: "8",
+			wantCode:   http.StatusNotFound,
+			wantError:  true,
+		},
+		"Invalid revision ID": {
+			projectID:  projectID.String(),
+			revisionID: "abc",
+			wantCode:   http.StatusBadRequest,
+			wantError:  true,
+		},
+		"Not the owner": {
+			projectID:  otherProjectID.String(),
+			revisionID: "7",
+			wantCode:   http.StatusForbidden,
+			wantError:  true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id", "revisionID")
+			c.SetParamValues(tt.projectID, tt.revisionID)
+			c.Set("user", owner)
+
+			err := handler.Restore(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), "Spiral v1")
+			}
+		})
+	}
+}
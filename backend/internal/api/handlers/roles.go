@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services/roles"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RoleHandler handles HTTP requests related to role permissions.
+type RoleHandler struct {
+	roleService roles.IRoleService
+}
+
+// NewRoleHandler creates a new RoleHandler with the provided role service.
+func NewRoleHandler(roleService roles.IRoleService) RoleHandler {
+	return RoleHandler{
+		roleService: roleService,
+	}
+}
+
+// GetCurrentPermissions handles the request to list the permissions of the authenticated user,
+// so the client knows which administrative pages to offer.
+func (h *RoleHandler) GetCurrentPermissions(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	permissions, err := h.roleService.GetPermissions(contextUser.Role.ID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal permission retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve permissions")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"role":        contextUser.Role.Name,
+		"permissions": permissions,
+	})
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/projects"
+	"NodeTurtleAPI/internal/services/runs"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// RunHandler handles HTTP requests to run programs on the server.
+type RunHandler struct {
+	projectService projects.IProjectService
+	runService     runs.IRunService
+}
+
+// NewRunHandler creates a new RunHandler with the provided services.
+func NewRunHandler(projectService projects.IProjectService, runService runs.IRunService) RunHandler {
+	return RunHandler{
+		projectService: projectService,
+		runService:     runService,
+	}
+}
+
+// runHints tell users how to get a program within a budget of a single run.
+var runHints = map[string]string{
+	services.RunSteps:    "Lower loop counts, or avoid loops that spawn turtles and branches inside loops.",
+	services.RunTime:     "Lower loop counts, or avoid loops that spawn turtles and branches inside loops.",
+	services.RunSegments: "Draw fewer lines, or lift the pen where nothing needs to be drawn.",
+}
+
+// Run handles the request to run a project the user can see and return what it draws.
+// The run is metered against the user's daily compute, and rejected with the budget it exceeds.
+func (h *RunHandler) Run(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	project, err := h.projectService.GetProject(c.Request().Context(), projectID, &contextUser.ID)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		logging.Error(c.Request().Context(), "Internal project retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve project")
+	}
+
+	run, err := h.runService.Run(c.Request().Context(), contextUser.ID, project.Data)
+	if err != nil {
+		var quotaErr *services.QuotaError
+		if errors.As(err, &quotaErr) {
+			return echo.NewHTTPError(http.StatusTooManyRequests, map[string]interface{}{
+				"message": fmt.Sprintf("Daily compute quota of %d steps used up, it resets at midnight UTC", quotaErr.Limit),
+				"quota":   quotaErr,
+			})
+		}
+		var limitErr *services.RunLimitError
+		if errors.As(err, &limitErr) {
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, map[string]interface{}{
+				"message": fmt.Sprintf("The program exceeds the %s budget of a run: %s", limitErr.Budget, runHints[limitErr.Budget]),
+				"limit":   limitErr,
+			})
+		}
+		if errors.Is(err, services.ErrInvalidData) {
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, "The project data can't be run")
+		}
+		logging.Error(c.Request().Context(), "Internal project run error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to run project")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"run": run,
+	})
+}
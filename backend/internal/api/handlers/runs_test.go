@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunProject(t *testing.T) {
+	e := echo.New()
+
+	user := &data.User{ID: uuid.New(), Role: data.Role{ID: data.RoleUser.ToID(), Name: data.RoleUser.String()}}
+
+	projects := map[string]*data.Project{}
+	for _, name := range []string{"square", "endless", "sprawling", "quota", "broken", "failing"} {
+		projects[name] = &data.Project{ID: uuid.New(), Data: json.RawMessage(`"` + name + `"`)}
+	}
+	missingID := uuid.New()
+
+	mockProjectService := new(mocks.MockProjectService)
+	for _, p := range projects {
+		mockProjectService.On("GetProject", p.ID, &user.ID).Return(p, nil)
+	}
+	mockProjectService.On("GetProject", missingID, &user.ID).Return(nil, services.ErrRecordNotFound)
+
+	mockRunService := new(mocks.MockRunService)
+	mockRunService.On("Run", user.ID, projects["square"].Data).Return(&data.ProjectRun{
+		Segments: []data.RunSegment{{X2: 50, Color: "#000000"}},
+		Steps:    14,
+	}, nil)
+	mockRunService.On("Run", user.ID, projects["endless"].Data).Return(nil, &services.RunLimitError{Budget: services.RunSteps, Limit: 50000})
+	mockRunService.On("Run", user.ID, projects["sprawling"].Data).Return(nil, &services.RunLimitError{Budget: services.RunSegments, Limit: 20000})
+	mockRunService.On("Run", user.ID, projects["quota"].Data).Return(nil, &services.QuotaError{Quota: services.QuotaCompute, Limit: 2000000, Used: 2000000})
+	mockRunService.On("Run", user.ID, projects["broken"].Data).Return(nil, services.ErrInvalidData)
+	mockRunService.On("Run", user.ID, projects["failing"].Data).Return(nil, services.ErrInternal)
+
+	handler := NewRunHandler(mockProjectService, mockRunService)
+
+	tests := map[string]struct {
+		projectID   string
+		wantCode    int
+		wantMessage string
+	}{
+		"runs the project": {
+			projectID: projects["square"].ID.String(),
+			wantCode:  http.StatusOK,
+		},
+		"invalid project id": {
+			projectID: "1234",
+			wantCode:  http.StatusBadRequest,
+		},
+		"project not found": {
+			projectID: missingID.String(),
+			wantCode:  http.StatusNotFound,
+		},
+		"over the steps budget": {
+			projectID:   projects["endless"].ID.String(),
+			wantCode:    http.StatusUnprocessableEntity,
+			wantMessage: "Lower loop counts",
+		},
+		"over the memory budget": {
+			projectID:   projects["sprawling"].ID.String(),
+			wantCode:    http.StatusUnprocessableEntity,
+			wantMessage: "Draw fewer lines",
+		},
+		"daily compute used up": {
+			projectID:   projects["quota"].ID.String(),
+			wantCode:    http.StatusTooManyRequests,
+			wantMessage: "resets at midnight UTC",
+		},
+		"unreadable project data": {
+			projectID: projects["broken"].ID.String(),
+			wantCode:  http.StatusUnprocessableEntity,
+		},
+		"internal error": {
+			projectID: projects["failing"].ID.String(),
+			wantCode:  http.StatusInternalServerError,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c := e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), rec)
+			c.Set("user", user)
+			c.SetPath("/api/projects/:id/run")
+			c.SetParamNames("id")
+			c.SetParamValues(tt.projectID)
+
+			err := handler.Run(c)
+
+			if tt.wantCode != http.StatusOK {
+				he, ok := err.(*echo.HTTPError)
+				if assert.True(t, ok) {
+					assert.Equal(t, tt.wantCode, he.Code)
+					if tt.wantMessage != "" {
+						assert.Contains(t, he.Message.(map[string]interface{})["message"], tt.wantMessage)
+					}
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"steps":14`)
+			}
+		})
+	}
+
+	mockRunService.AssertExpectations(t)
+}
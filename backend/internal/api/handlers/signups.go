@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/audit"
+	"NodeTurtleAPI/internal/services/signups"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SignupHandler handles HTTP requests for the allowlist of subnets and email domains exempt from the signup limits.
+type SignupHandler struct {
+	signupService signups.ISignupService
+	auditService  audit.IAuditService
+}
+
+// NewSignupHandler creates a new SignupHandler with the provided services.
+func NewSignupHandler(signupService signups.ISignupService, auditService audit.IAuditService) SignupHandler {
+	return SignupHandler{
+		signupService: signupService,
+		auditService:  auditService,
+	}
+}
+
+// ListAllowlist handles the request to list the allowlist entries.
+func (h *SignupHandler) ListAllowlist(c echo.Context) error {
+	entries, err := h.signupService.ListAllowlist()
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal signup allowlist retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve allowlist")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"entries": entries,
+	})
+}
+
+// AddToAllowlist handles the request to exempt a subnet or an email domain from the signup limits.
+// The change is recorded in the audit log before it is saved.
+func (h *SignupHandler) AddToAllowlist(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var payload data.SignupAllowlistCreate
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if (payload.Subnet == "") == (payload.Domain == "") {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, "Either a subnet or a domain is required")
+	}
+
+	if err := h.record(c, contextUser, data.AuditSignupAllowlistAdd, payload.Subnet+payload.Domain, payload.Note); err != nil {
+		return err
+	}
+
+	entry, err := h.signupService.AddToAllowlist(payload, contextUser.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAlreadyAllowlisted):
+			return echo.NewHTTPError(http.StatusConflict, "Subnet or domain is already allowlisted")
+		case errors.Is(err, services.ErrInvalidData):
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, "Invalid subnet")
+		}
+		logging.Error(c.Request().Context(), "Internal signup allowlist update error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update allowlist")
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"entry": entry,
+	})
+}
+
+// RemoveFromAllowlist handles the request to remove an entry from the allowlist.
+// The change is recorded in the audit log before it is saved.
+func (h *SignupHandler) RemoveFromAllowlist(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid allowlist entry ID")
+	}
+
+	entry, err := h.signupService.GetAllowlistEntry(id)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Allowlist entry not found")
+		}
+		logging.Error(c.Request().Context(), "Internal signup allowlist retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update allowlist")
+	}
+
+	target := entry.Domain
+	if entry.Subnet != nil {
+		target = entry.Subnet
+	}
+	if err := h.record(c, contextUser, data.AuditSignupAllowlistRemove, *target, entry.Note); err != nil {
+		return err
+	}
+
+	if err := h.signupService.RemoveFromAllowlist(id); err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Allowlist entry not found")
+		}
+		logging.Error(c.Request().Context(), "Internal signup allowlist update error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update allowlist")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// record adds a change of the allowlist entry for the subnet or domain to the audit log.
+func (h *SignupHandler) record(c echo.Context, actor *data.User, action, target, note string) error {
+	err := h.auditService.Record(data.AuditEntry{
+		ActorID:    &actor.ID,
+		Action:     action,
+		TargetType: "signup_allowlist",
+		TargetID:   target,
+		Details: map[string]interface{}{
+			"note": note,
+		},
+		IP: c.RealIP(),
+	})
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal audit log error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to record allowlist change")
+	}
+	return nil
+}
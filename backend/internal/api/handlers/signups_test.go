@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/utils"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddToSignupAllowlist(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	admin := &data.User{ID: uuid.New(), Username: "admin", IsActivated: true}
+
+	mockSignupService := mocks.MockSignupService{}
+	mockAuditService := mocks.MockAuditService{}
+
+	mockSignupService.On("AddToAllowlist", data.SignupAllowlistCreate{Subnet: "192.0.2.0/24", Note: "Springfield High"}, admin.ID).
+		Return(&data.SignupAllowlistEntry{ID: 1, Subnet: utils.Ptr("192.0.2.0/24"), Note: "Springfield High"}, nil)
+	mockSignupService.On("AddToAllowlist", data.SignupAllowlistCreate{Domain: "school.edu"}, admin.ID).
+		Return(nil, services.ErrAlreadyAllowlisted)
+	mockAuditService.On("Record", mock.MatchedBy(func(entry data.AuditEntry) bool {
+		return entry.Action == data.AuditSignupAllowlistAdd && *entry.ActorID == admin.ID
+	})).Return(nil)
+
+	handler := NewSignupHandler(&mockSignupService, &mockAuditService)
+
+	tests := map[string]struct {
+		reqBody   string
+		wantCode  int
+		wantError bool
+	}{
+		"Subnet allowlisted": {
+			reqBody:  `{"subnet":"192.0.2.0/24","note":"Springfield High"}`,
+			wantCode: http.StatusCreated,
+		},
+		"Domain already allowlisted": {
+			reqBody:   `{"domain":"school.edu"}`,
+			wantCode:  http.StatusConflict,
+			wantError: true,
+		},
+		"Subnet and domain": {
+			reqBody:   `{"subnet":"192.0.2.0/24","domain":"school.edu"}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Neither subnet nor domain": {
+			reqBody:   `{"note":"nothing"}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Invalid subnet": {
+			reqBody:   `{"subnet":"not-a-subnet"}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", admin)
+
+			err := handler.AddToAllowlist(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), "Springfield High")
+			}
+		})
+	}
+}
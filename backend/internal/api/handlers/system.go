@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services/system"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SystemHandler handles HTTP requests for the health of the API and its database.
+type SystemHandler struct {
+	systemService system.ISystemService
+}
+
+// NewSystemHandler creates a new SystemHandler with the provided service.
+func NewSystemHandler(systemService system.ISystemService) SystemHandler {
+	return SystemHandler{
+		systemService: systemService,
+	}
+}
+
+// DBHealth handles the request to retrieve table sizes, bloat estimates, index usage,
+// connection pool utilization and replication lag.
+func (h *SystemHandler) DBHealth(c echo.Context) error {
+	health, err := h.systemService.DBHealth()
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal database health retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve database health")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"health": health,
+	})
+}
+
+// Health handles the request of load balancer probes and uptime checkers to check that the API can serve requests.
+// It answers 503 while the database is unreachable.
+func (h *SystemHandler) Health(c echo.Context) error {
+	if err := h.systemService.Ping(c.Request().Context()); err != nil {
+		logging.Error(c.Request().Context(), "Internal database ping error", err)
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "Database unreachable")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status": "ok",
+	})
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBHealth(t *testing.T) {
+	e := echo.New()
+
+	tests := map[string]struct {
+		health    *data.DBHealth
+		err       error
+		wantCode  int
+		wantError bool
+	}{
+		"Health collected": {
+			health: &data.DBHealth{
+				DatabaseBytes: 8 << 20,
+				Tables:        []data.TableHealth{{Name: "projects", Rows: 900, DeadRows: 100, BloatRatio: 0.1}},
+				Indexes:       []data.IndexUsage{},
+				Pool:          data.PoolStats{ServerConnections: 10, MaxConnections: 100, Utilization: 0.1},
+				Replicas:      []data.ReplicaStatus{},
+				CollectedAt:   time.Now(),
+			},
+			wantCode: http.StatusOK,
+		},
+		"Database error": {
+			err:       errors.New("database error"),
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockSystemService := mocks.MockSystemService{}
+			mockSystemService.On("DBHealth").Return(tt.health, tt.err)
+			handler := NewSystemHandler(&mockSystemService)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.DBHealth(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"bloat_ratio":0.1`)
+				assert.Contains(t, rec.Body.String(), `"replicas":[]`)
+			}
+		})
+	}
+}
+
+func TestHealth(t *testing.T) {
+	e := echo.New()
+
+	tests := map[string]struct {
+		err       error
+		wantCode  int
+		wantError bool
+	}{
+		"Database answers": {
+			wantCode: http.StatusOK,
+		},
+		"Database unreachable": {
+			err:       errors.New("connection refused"),
+			wantCode:  http.StatusServiceUnavailable,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockSystemService := mocks.MockSystemService{}
+			mockSystemService.On("Ping").Return(tt.err)
+			handler := NewSystemHandler(&mockSystemService)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/health", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.Health(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
+			}
+		})
+	}
+}
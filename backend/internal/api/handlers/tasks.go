@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/tasks"
+
+	"github.com/labstack/echo/v4"
+)
+
+// tasksLimit is the number of dead tasks returned when the request doesn't ask for a number.
+const tasksLimit = 50
+
+// TaskHandler handles HTTP requests for the background tasks that ran out of attempts.
+type TaskHandler struct {
+	taskService tasks.ITaskService
+}
+
+// NewTaskHandler creates a new TaskHandler with the provided services.
+func NewTaskHandler(taskService tasks.ITaskService) TaskHandler {
+	return TaskHandler{
+		taskService: taskService,
+	}
+}
+
+// ListDead handles the request to list the dead tasks, the latest to fail first, optionally of a kind.
+func (h *TaskHandler) ListDead(c echo.Context) error {
+	var filter data.TaskFilter
+	if err := c.Bind(&filter); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid query parameters")
+	}
+
+	if err := c.Validate(&filter); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = tasksLimit
+	}
+
+	tasks, err := h.taskService.ListDead(c.Request().Context(), filter)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal task retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve tasks")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"tasks": tasks,
+		"meta": map[string]interface{}{
+			"limit": filter.Limit,
+		},
+	})
+}
+
+// Retry handles the request to put a dead task back in the queue, e.g. once the SMTP server that failed it is back.
+func (h *TaskHandler) Retry(c echo.Context) error {
+	taskID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID")
+	}
+
+	task, err := h.taskService.Retry(c.Request().Context(), taskID)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Dead task not found")
+		}
+		logging.Error(c.Request().Context(), "Internal task retry error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retry task")
+	}
+
+	return c.JSON(http.StatusOK, task)
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/go-playground/validator"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListDeadTasks(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	failedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	dead := []data.Task{
+		{ID: 7, Kind: data.TaskEmail, Payload: []byte(`{"to":"alice@example.com","data":{"url":"/reset/secret"}}`), Status: data.TaskDead, Attempts: 8, LastError: "smtp: mailbox unavailable", FailedAt: &failedAt},
+	}
+
+	mockTaskService := mocks.MockTaskService{}
+	mockTaskService.On("ListDead", data.TaskFilter{Limit: tasksLimit}).Return(dead, nil)
+	mockTaskService.On("ListDead", data.TaskFilter{Kind: data.TaskEmail, Limit: 10}).Return(nil, errors.New("database error"))
+
+	handler := NewTaskHandler(&mockTaskService)
+
+	tests := map[string]struct {
+		query     string
+		wantCode  int
+		wantError bool
+	}{
+		"Dead tasks": {
+			query:    "",
+			wantCode: http.StatusOK,
+		},
+		"Unknown kind": {
+			query:     "?kind=meteor_strike",
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Invalid limit": {
+			query:     "?limit=many",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Service error": {
+			query:     "?kind=email&limit=10",
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/tasks/dead"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.ListDead(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"last_error":"smtp: mailbox unavailable"`)
+				// payloads carry the tokens of the emails
+				assert.NotContains(t, rec.Body.String(), "secret")
+			}
+		})
+	}
+}
+
+func TestRetryTask(t *testing.T) {
+	e := echo.New()
+
+	mockTaskService := mocks.MockTaskService{}
+	mockTaskService.On("Retry", int64(7)).Return(&data.Task{ID: 7, Kind: data.TaskEmail, Status: data.TaskPending}, nil)
+	mockTaskService.On("Retry", int64(8)).Return(nil, services.ErrRecordNotFound)
+	mockTaskService.On("Retry", int64(9)).Return(nil, errors.New("database error"))
+
+	handler := NewTaskHandler(&mockTaskService)
+
+	tests := map[string]struct {
+		taskID    string
+		wantCode  int
+		wantError bool
+	}{
+		"Retried": {
+			taskID:   "7",
+			wantCode: http.StatusOK,
+		},
+		"Not dead or not found": {
+			taskID:    "8",
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Service error": {
+			taskID:    "9",
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+		"Invalid ID": {
+			taskID:    "seven",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/tasks/"+tt.taskID+"/retry", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.taskID)
+
+			err := handler.Retry(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"status":"pending"`)
+			}
+		})
+	}
+}
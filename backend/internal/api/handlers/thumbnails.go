@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/projects"
+	"NodeTurtleAPI/internal/services/thumbnails"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// ThumbnailHandler handles HTTP requests for the rendered thumbnails of projects.
+type ThumbnailHandler struct {
+	projectService   projects.IProjectService
+	thumbnailService thumbnails.IThumbnailService
+}
+
+// NewThumbnailHandler creates a new ThumbnailHandler with the provided services.
+func NewThumbnailHandler(projectService projects.IProjectService, thumbnailService thumbnails.IThumbnailService) ThumbnailHandler {
+	return ThumbnailHandler{
+		projectService:   projectService,
+		thumbnailService: thumbnailService,
+	}
+}
+
+// Get handles the request to retrieve an image of what the program of a project draws, used for gallery previews.
+// The image is a PNG unless ?format=svg is requested. Anyone who can see the project can see its thumbnail.
+func (h *ThumbnailHandler) Get(c echo.Context) error {
+	var userID *uuid.UUID
+	if user, ok := c.Get("user").(*data.User); ok {
+		userID = &user.ID
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	format := data.ThumbnailFormat(c.QueryParam("format"))
+	switch format {
+	case "":
+		format = data.ThumbnailPNG
+	case data.ThumbnailPNG, data.ThumbnailSVG:
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid thumbnail format")
+	}
+
+	project, err := h.projectService.GetProject(c.Request().Context(), projectID, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		logging.Error(c.Request().Context(), "Internal project retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve project")
+	}
+
+	thumbnail, err := h.thumbnailService.GetThumbnail(c.Request().Context(), project, format)
+	if err != nil {
+		if errors.Is(err, services.ErrRenderLimit) {
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, "RENDER_LIMIT")
+		}
+		if errors.Is(err, services.ErrRenderPending) {
+			// the thumbnail stays queued, a render worker is likely done by the time the client asks again
+			c.Response().Header().Set("Retry-After", "2")
+			return echo.NewHTTPError(http.StatusServiceUnavailable, "RENDER_PENDING")
+		}
+		logging.Error(c.Request().Context(), "Internal thumbnail rendering error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to render thumbnail")
+	}
+
+	return c.Blob(http.StatusOK, format.ContentType(), thumbnail.Content)
+}
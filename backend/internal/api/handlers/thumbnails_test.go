@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetThumbnail(t *testing.T) {
+	e := echo.New()
+
+	public := &data.Project{ID: uuid.New(), IsPublic: true, Version: 3}
+	runaway := &data.Project{ID: uuid.New(), IsPublic: true, Version: 1}
+	queued := &data.Project{ID: uuid.New(), IsPublic: true, Version: 2}
+	hidden := uuid.New()
+
+	mockProjectService := mocks.MockProjectService{}
+	mockThumbnailService := mocks.MockThumbnailService{}
+
+	mockProjectService.On("GetProject", public.ID, mock.Anything).Return(public, nil)
+	mockProjectService.On("GetProject", runaway.ID, mock.Anything).Return(runaway, nil)
+	mockProjectService.On("GetProject", queued.ID, mock.Anything).Return(queued, nil)
+	mockProjectService.On("GetProject", hidden, mock.Anything).Return(nil, services.ErrRecordNotFound)
+	mockThumbnailService.On("GetThumbnail", public, data.ThumbnailPNG).Return(&data.Thumbnail{Content: []byte("\x89PNG")}, nil)
+	mockThumbnailService.On("GetThumbnail", public, data.ThumbnailSVG).Return(&data.Thumbnail{Content: []byte("<svg/>")}, nil)
+	mockThumbnailService.On("GetThumbnail", runaway, mock.Anything).Return(nil, services.ErrRenderLimit)
+	mockThumbnailService.On("GetThumbnail", queued, mock.Anything).Return(nil, services.ErrRenderPending)
+
+	handler := NewThumbnailHandler(&mockProjectService, &mockThumbnailService)
+
+	tests := map[string]struct {
+		projectID       string
+		format          string
+		wantCode        int
+		wantContentType string
+		wantError       bool
+	}{
+		"PNG by default": {
+			projectID:       public.ID.String(),
+			wantCode:        http.StatusOK,
+			wantContentType: "image/png",
+		},
+		"SVG": {
+			projectID:       public.ID.String(),
+			format:          "svg",
+			wantCode:        http.StatusOK,
+			wantContentType: "image/svg+xml",
+		},
+		"Unknown format": {
+			projectID: public.ID.String(),
+			format:    "gif",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Project not visible": {
+			projectID: hidden.String(),
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Program exceeds limits": {
+			projectID: runaway.ID.String(),
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Waiting for a render worker": {
+			projectID: queued.ID.String(),
+			wantCode:  http.StatusServiceUnavailable,
+			wantError: true,
+		},
+		"Invalid project ID": {
+			projectID: "invalid",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/?format="+tt.format, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.projectID)
+
+			err := handler.Get(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Equal(t, tt.wantContentType, rec.Header().Get(echo.HeaderContentType))
+			}
+		})
+	}
+}
@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
 	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/audit"
 	"NodeTurtleAPI/internal/services/mail"
+	"NodeTurtleAPI/internal/services/passwords"
 	"NodeTurtleAPI/internal/services/tokens"
 	"NodeTurtleAPI/internal/services/users"
 	"NodeTurtleAPI/internal/utils"
@@ -12,22 +15,27 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
 // TokenHandler handles HTTP requests related to user tokens.
 type TokenHandler struct {
-	userService  users.IUserService
-	tokenService tokens.ITokenService
-	mailService  mail.IMailService
+	userService     users.IUserService
+	tokenService    tokens.ITokenService
+	mailService     mail.IMailService
+	passwordService passwords.IPasswordService
+	auditService    audit.IAuditService
 }
 
-// NewTokenHandler creates a new TokenHandler with the provided user, token, and mail services.
-func NewTokenHandler(userService users.IUserService, tokenService tokens.ITokenService, mailService mail.IMailService) TokenHandler {
+// NewTokenHandler creates a new TokenHandler with the provided user, token, mail, password and audit services.
+func NewTokenHandler(userService users.IUserService, tokenService tokens.ITokenService, mailService mail.IMailService, passwordService passwords.IPasswordService, auditService audit.IAuditService) TokenHandler {
 	return TokenHandler{
-		userService:  userService,
-		tokenService: tokenService,
-		mailService:  mailService,
+		userService:     userService,
+		tokenService:    tokenService,
+		mailService:     mailService,
+		passwordService: passwordService,
+		auditService:    auditService,
 	}
 }
 
@@ -47,12 +55,12 @@ func (h *TokenHandler) RequestActivationToken(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
 	}
 
-	user, err := h.userService.GetUserByEmail(payload.Email)
+	user, err := h.userService.GetUserByEmail(c.Request().Context(), payload.Email)
 	if err != nil {
 		if errors.Is(err, services.ErrUserNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "No matching email address found")
 		}
-		c.Logger().Errorf("Internal user retrieval error %v", err)
+		logging.Error(c.Request().Context(), "Internal user retrieval error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve user")
 	}
 
@@ -60,30 +68,29 @@ func (h *TokenHandler) RequestActivationToken(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusForbidden, services.BanMessage(user.Ban.Reason, user.Ban.ExpiresAt))
 	}
 
+	if user.IsDeactivated() {
+		return echo.NewHTTPError(http.StatusForbidden, "Account is deactivated")
+	}
+
 	if user.IsActivated {
 		return echo.NewHTTPError(http.StatusConflict, "Account is already activated")
 	}
 
-	activationToken, err := h.tokenService.New(user.ID, 24*time.Hour, data.ScopeUserActivation)
+	activationToken, err := h.sendActivation(c, user)
 	if err != nil {
-		c.Logger().Errorf("Internal activation token creation error %v", err)
+		logging.Error(c.Request().Context(), "Internal activation token creation error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create Activation token")
 	}
 
-	activationLink := fmt.Sprintf("/activate/%s", activationToken.Plaintext)
-	emailData := map[string]string{
-		"Username": user.Username,
-		"url":      activationLink,
-	}
-	go h.mailService.SendEmail(user.Email, "Activate Your Account", "activation", emailData)
-
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"message": "Account activation request successful. Please check your email to activate your account.",
+		"message":    "Account activation request successful. Please check your email to activate your account.",
+		"expires_at": activationToken.ExpiresAt,
 	})
 }
 
 // ActivateAccount handles account activation via email token.
-// It validates the activation token, marks the user as activated, and removes the token.
+// It validates the activation token and marks the user as activated.
+// Activation is idempotent, the token is kept until it expires so a second click of the same link succeeds as well.
 // Returns an error if the token is invalid or expired, or if activation fails.
 func (h *TokenHandler) ActivateAccount(c echo.Context) error {
 	token := c.Param("token")
@@ -91,13 +98,13 @@ func (h *TokenHandler) ActivateAccount(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid or expired reset token")
 	}
 
-	user, err := h.userService.GetForToken(data.ScopeUserActivation, token)
+	user, err := h.userService.GetForToken(c.Request().Context(), data.ScopeUserActivation, token)
 	if err != nil {
 		if errors.Is(err, services.ErrRecordNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, err)
 		}
 
-		c.Logger().Errorf("Internal user retrieval error %v", err)
+		logging.Error(c.Request().Context(), "Internal user retrieval error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve user")
 	}
 
@@ -105,26 +112,72 @@ func (h *TokenHandler) ActivateAccount(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusForbidden, services.BanMessage(user.Ban.Reason, user.Ban.ExpiresAt))
 	}
 
-	_, err = h.userService.UpdateUser(user.ID, data.UserUpdate{Activated: utils.Ptr(true)})
+	// activating would undo the deactivation by an admin
+	if user.IsDeactivated() {
+		return echo.NewHTTPError(http.StatusForbidden, "Account is deactivated")
+	}
+
+	if user.IsActivated {
+		return accountActivated(c)
+	}
+
+	_, err = h.userService.UpdateUser(c.Request().Context(), user.ID, data.UserUpdate{Activated: utils.Ptr(true)})
 
 	if err != nil {
 		if errors.Is(err, services.ErrEditConflict) {
+			// a concurrent click of the same link is the usual cause, which is not an error for the user
+			current, err := h.userService.GetUserByID(c.Request().Context(), user.ID)
+			if err == nil && current.IsActivated {
+				return accountActivated(c)
+			}
 			return echo.NewHTTPError(http.StatusConflict, "Edit conflict")
 		}
-		c.Logger().Errorf("Internal user update error %v", err)
+		logging.Error(c.Request().Context(), "Internal user update error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update user")
 	}
 
-	if err := h.tokenService.DeleteAllForUser(data.ScopeUserActivation, user.ID); err != nil {
-		c.Logger().Errorf("Internal activation token deletion error %v", err)
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete activation token")
-	}
+	return accountActivated(c)
+}
 
+func accountActivated(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]string{
 		"message": "Account activated successfully. You can now login.",
 	})
 }
 
+// ActivationStatus handles the request to check whether a registered account is activated,
+// so the frontend can follow an activation completed in another tab or device.
+// The account is identified by the status token of the registration response. Unknown and expired tokens
+// are reported as pending, so the answer tells nothing to anyone but the client that registered the account.
+func (h *TokenHandler) ActivationStatus(c echo.Context) error {
+	var query struct {
+		Token string `query:"token" validate:"required"`
+	}
+
+	if err := c.Bind(&query); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid query parameters")
+	}
+
+	if err := c.Validate(&query); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	status := "pending"
+
+	user, err := h.userService.GetForToken(c.Request().Context(), data.ScopeActivationStatus, query.Token)
+	if err != nil && !errors.Is(err, services.ErrRecordNotFound) {
+		logging.Error(c.Request().Context(), "Internal user retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve activation status")
+	}
+	if err == nil && user.IsActivated {
+		status = "activated"
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"status": status,
+	})
+}
+
 // RequestPasswordReset handles requests to reset a forgotten password.
 // It validates the email, creates a reset token, and sends a reset link via email.
 // Returns an error if the email is invalid, if the account is not activated,
@@ -142,12 +195,12 @@ func (h *TokenHandler) RequestPasswordReset(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
 	}
 
-	user, err := h.userService.GetUserByEmail(payload.Email)
+	user, err := h.userService.GetUserByEmail(c.Request().Context(), payload.Email)
 	if err != nil {
 		if errors.Is(err, services.ErrUserNotFound) {
 			return echo.NewHTTPError(http.StatusBadRequest, "Invalid email address")
 		}
-		c.Logger().Errorf("Internal user retrieval error %v", err)
+		logging.Error(c.Request().Context(), "Internal user retrieval error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve user")
 	}
 
@@ -159,22 +212,15 @@ func (h *TokenHandler) RequestPasswordReset(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusForbidden, "Account is not activated")
 	}
 
-	resetToken, err := h.tokenService.New(user.ID, 24*time.Hour, data.ScopePasswordReset)
+	resetToken, err := h.sendPasswordReset(c, user)
 	if err != nil {
-		c.Logger().Errorf("Internal reset token creation error %v", err)
+		logging.Error(c.Request().Context(), "Internal reset token creation error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create reset token")
 	}
 
-	resetLink := fmt.Sprintf("/reset/%s", resetToken.Plaintext)
-	emailData := map[string]string{
-		"Username": user.Username,
-		"url":      resetLink,
-	}
-
-	go h.mailService.SendEmail(user.Email, "Reset Your Password", "reset", emailData)
-
-	return c.JSON(http.StatusAccepted, map[string]string{
-		"message": "If an account with that email exists, a password reset link has been sent.",
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"expires_at": resetToken.ExpiresAt,
+		"message":    "If an account with that email exists, a password reset link has been sent.",
 	})
 }
 
@@ -190,7 +236,7 @@ func (h *TokenHandler) ResetPassword(c echo.Context) error {
 	}
 
 	var payload struct {
-		Password string `json:"password" validate:"required,min=8"`
+		Password string `json:"password" validate:"required"`
 	}
 
 	if err := c.Bind(&payload); err != nil {
@@ -201,14 +247,14 @@ func (h *TokenHandler) ResetPassword(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
 	}
 
-	user, err := h.userService.GetForToken(data.ScopePasswordReset, token)
+	user, err := h.userService.GetForToken(c.Request().Context(), data.ScopePasswordReset, token)
 	if err != nil {
 		switch {
 		case errors.Is(err, services.ErrRecordNotFound):
 			return echo.NewHTTPError(http.StatusNotFound, err)
 
 		default:
-			c.Logger().Errorf("Internal user retrieval error %v", err)
+			logging.Error(c.Request().Context(), "Internal user retrieval error", err)
 			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve data")
 		}
 	}
@@ -221,17 +267,21 @@ func (h *TokenHandler) ResetPassword(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusForbidden, "Account is not activated")
 	}
 
-	if err := h.userService.ResetPassword(token, payload.Password); err != nil {
+	if strength := h.passwordService.Evaluate(payload.Password, []string{user.Username, user.Email}); !strength.Acceptable() {
+		return weakPassword(strength)
+	}
+
+	if err := h.userService.ResetPassword(c.Request().Context(), token, payload.Password); err != nil {
 		if errors.Is(err, services.ErrEditConflict) {
 			return echo.NewHTTPError(http.StatusConflict, "Edit conflict")
 		}
-		c.Logger().Errorf("Internal user update error %v", err)
+		logging.Error(c.Request().Context(), "Internal user update error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update user")
 	}
 
 	// delete all password reset tokens for the user
-	if err := h.tokenService.DeleteAllForUser(data.ScopePasswordReset, user.ID); err != nil {
-		c.Logger().Errorf("Internal password reset deletion error %v", err)
+	if err := h.tokenService.DeleteAllForUser(c.Request().Context(), data.ScopePasswordReset, user.ID); err != nil {
+		logging.Error(c.Request().Context(), "Internal password reset deletion error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update user")
 	}
 
@@ -259,7 +309,7 @@ func (h *TokenHandler) RequestDeactivationToken(c echo.Context) error {
 
 	matches, err := contextUser.Password.Matches(payload.Password)
 	if err != nil {
-		c.Logger().Errorf("Internal password matching error %v", err)
+		logging.Error(c.Request().Context(), "Internal password matching error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
@@ -271,20 +321,246 @@ func (h *TokenHandler) RequestDeactivationToken(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusForbidden, "Account is not activated")
 	}
 
-	dt, err := h.tokenService.New(contextUser.ID, 24*time.Hour, data.ScopeDeactivate)
+	dt, err := h.tokenService.New(c.Request().Context(), contextUser.ID, data.ScopeDeactivate)
 	if err != nil {
-		c.Logger().Errorf("Internal deactivation token creation error %v", err)
+		logging.Error(c.Request().Context(), "Internal deactivation token creation error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create Deactivation token")
 	}
 
 	link := fmt.Sprintf("/deactivate/%s", dt.Plaintext)
 	emailData := map[string]string{
-		"Username": contextUser.Username,
-		"url":      link,
+		"Username":  contextUser.Username,
+		"url":       link,
+		"ExpiresAt": formatExpiry(dt.ExpiresAt),
+	}
+	if err := h.mailService.QueueEmail(c.Request().Context(), contextUser.Email, "Account deactivation", "deactivation", emailData); err != nil {
+		logging.Error(c.Request().Context(), "Failed to queue deactivation email", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to send deactivation email")
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"expires_at": dt.ExpiresAt,
+		"message":    "Deactivation email has been sent. Please follow the instructions to deactivate your account.",
+	})
+}
+
+// sendActivation creates an activation token for the user and queues the email of the activation link.
+func (h *TokenHandler) sendActivation(c echo.Context, user *data.User) (*data.Token, error) {
+	activationToken, err := h.tokenService.New(c.Request().Context(), user.ID, data.ScopeUserActivation)
+	if err != nil {
+		return nil, err
+	}
+
+	activationLink := fmt.Sprintf("/activate/%s", activationToken.Plaintext)
+	emailData := map[string]string{
+		"Username":  user.Username,
+		"url":       activationLink,
+		"ExpiresAt": formatExpiry(activationToken.ExpiresAt),
+	}
+	if err := h.mailService.QueueEmail(c.Request().Context(), user.Email, "Activate Your Account", "activation", emailData); err != nil {
+		return nil, err
+	}
+
+	return activationToken, nil
+}
+
+// sendPasswordReset creates a password reset token for the user and queues the email of the reset link.
+func (h *TokenHandler) sendPasswordReset(c echo.Context, user *data.User) (*data.Token, error) {
+	resetToken, err := h.tokenService.New(c.Request().Context(), user.ID, data.ScopePasswordReset)
+	if err != nil {
+		return nil, err
+	}
+
+	resetLink := fmt.Sprintf("/reset/%s", resetToken.Plaintext)
+	emailData := map[string]string{
+		"Username":  user.Username,
+		"url":       resetLink,
+		"ExpiresAt": formatExpiry(resetToken.ExpiresAt),
 	}
-	go h.mailService.SendEmail(contextUser.Email, "Account deactivation", "deactivation", emailData)
+	if err := h.mailService.QueueEmail(c.Request().Context(), user.Email, "Reset Your Password", "reset", emailData); err != nil {
+		return nil, err
+	}
+
+	return resetToken, nil
+}
+
+// formatExpiry formats a token expiry for the emails stating the deadline.
+func formatExpiry(t time.Time) string {
+	return t.UTC().Format("January 2, 2006 at 15:04 UTC")
+}
 
-	return c.JSON(http.StatusAccepted, map[string]string{
-		"message": "Deactivation email has been sent. Please follow the instructions to deactivate your account.",
+// Stats handles the request to retrieve the number of active and expired tokens per scope.
+func (h *TokenHandler) Stats(c echo.Context) error {
+	stats, err := h.tokenService.Stats(c.Request().Context())
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal token stats retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve token stats")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"scopes": stats,
 	})
 }
+
+// Revoke handles the request to revoke tokens in bulk by user, scope or issue time, e.g. when the tokens of a scope leaked.
+// At least one filter is required. The revocation is recorded in the audit log before the tokens are deleted.
+func (h *TokenHandler) Revoke(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var payload data.TokenRevocation
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if payload.Empty() {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, "At least one of user_id, scope or issued_before is required")
+	}
+
+	target := string(payload.Scope)
+	if payload.UserID != nil {
+		target = payload.UserID.String()
+	}
+
+	err := h.auditService.Record(data.AuditEntry{
+		ActorID:    &contextUser.ID,
+		Action:     data.AuditTokensRevoke,
+		TargetType: "tokens",
+		TargetID:   target,
+		Details: map[string]interface{}{
+			"user_id":       payload.UserID,
+			"scope":         payload.Scope,
+			"issued_before": payload.IssuedBefore,
+		},
+		IP: c.RealIP(),
+	})
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal audit log error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to record token revocation")
+	}
+
+	revoked, err := h.tokenService.Revoke(c.Request().Context(), payload)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal token revocation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to revoke tokens")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"revoked": revoked,
+	})
+}
+
+// ResendActivation handles the request of support to send a new activation email to a user on their behalf,
+// without the rate limits of the public route. It's recorded in the audit log before the email is sent.
+func (h *TokenHandler) ResendActivation(c echo.Context) error {
+	user, err := h.supportedUser(c)
+	if err != nil {
+		return err
+	}
+
+	if user.IsDeactivated() {
+		return echo.NewHTTPError(http.StatusForbidden, "Account is deactivated")
+	}
+
+	if user.IsActivated {
+		return echo.NewHTTPError(http.StatusConflict, "Account is already activated")
+	}
+
+	if err := h.recordSupportEmail(c, user, data.AuditActivationResend); err != nil {
+		return err
+	}
+
+	activationToken, err := h.sendActivation(c, user)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal activation token creation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create Activation token")
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"expires_at": activationToken.ExpiresAt,
+		"message":    "Activation email has been sent.",
+	})
+}
+
+// SendPasswordReset handles the request of support to send a password reset email to a user on their behalf,
+// without the rate limits of the public route. It's recorded in the audit log before the email is sent.
+func (h *TokenHandler) SendPasswordReset(c echo.Context) error {
+	user, err := h.supportedUser(c)
+	if err != nil {
+		return err
+	}
+
+	if !user.IsActivated {
+		return echo.NewHTTPError(http.StatusConflict, "Account is not activated")
+	}
+
+	if err := h.recordSupportEmail(c, user, data.AuditPasswordResetSend); err != nil {
+		return err
+	}
+
+	resetToken, err := h.sendPasswordReset(c, user)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal reset token creation error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create reset token")
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"expires_at": resetToken.ExpiresAt,
+		"message":    "Password reset email has been sent.",
+	})
+}
+
+// supportedUser retrieves the user of the id path parameter that support sends an email to.
+// Banned users get no emails, the public routes turn them away as well.
+func (h *TokenHandler) supportedUser(c echo.Context) (*data.User, error) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID")
+	}
+
+	user, err := h.userService.GetUserByID(c.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "User not found")
+		}
+		logging.Error(c.Request().Context(), "Internal user retrieval error", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve user")
+	}
+
+	if user.Ban.IsValid() {
+		return nil, echo.NewHTTPError(http.StatusConflict, "User is banned")
+	}
+
+	return user, nil
+}
+
+// recordSupportEmail records in the audit log that the current user sends an email to a user on their behalf.
+func (h *TokenHandler) recordSupportEmail(c echo.Context, user *data.User, action string) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	err := h.auditService.Record(data.AuditEntry{
+		ActorID:    &contextUser.ID,
+		Action:     action,
+		TargetType: "user",
+		TargetID:   user.ID.String(),
+		Details: map[string]interface{}{
+			"email": user.Email,
+		},
+		IP: c.RealIP(),
+	})
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal audit log error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to record email")
+	}
+
+	return nil
+}
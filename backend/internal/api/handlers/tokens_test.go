@@ -4,7 +4,10 @@ import (
 	"NodeTurtleAPI/internal/data"
 	"NodeTurtleAPI/internal/mocks"
 	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/utils"
 	"bytes"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -44,19 +47,27 @@ func TestRequestActivationToken(t *testing.T) {
 		Username:    "banned",
 		IsActivated: true,
 		Ban: &data.Ban{
-			ExpiresAt: time.Now().Add(time.Hour),
+			ExpiresAt: utils.Ptr(time.Now().Add(time.Hour)),
 		},
 	}
+	deprovisionedUser := data.User{
+		ID:            uuid.New(),
+		Email:         "deprovisioned@test.com",
+		Username:      "deprovisioned",
+		IsActivated:   false,
+		DeactivatedAt: utils.Ptr(time.Now().Add(-time.Hour)),
+	}
 	newRefreshToken := data.Token{Plaintext: "new-refresh-token", Scope: data.ScopeRefresh}
 
-	handler := NewTokenHandler(&mockUserService, &mockTokenService, &mockMailerService)
+	handler := NewTokenHandler(&mockUserService, &mockTokenService, &mockMailerService, &mocks.MockPasswordService{}, &mocks.MockAuditService{})
 
 	mockUserService.On("GetUserByEmail", inactiveUser.Email).Return(&inactiveUser, nil)
 	mockUserService.On("GetUserByEmail", bannedUser.Email).Return(&bannedUser, nil)
 	mockUserService.On("GetUserByEmail", activatedUser.Email).Return(&activatedUser, nil)
+	mockUserService.On("GetUserByEmail", deprovisionedUser.Email).Return(&deprovisionedUser, nil)
 	mockUserService.On("GetUserByEmail", mock.Anything).Return(nil, services.ErrUserNotFound)
-	mockTokenService.On("New", mock.Anything, mock.Anything, mock.Anything).Return(&newRefreshToken, nil)
-	mockMailerService.On("SendEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockTokenService.On("New", mock.Anything, mock.Anything).Return(&newRefreshToken, nil)
+	mockMailerService.On("QueueEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
 	tests := map[string]struct {
 		reqBody   string
@@ -83,6 +94,11 @@ func TestRequestActivationToken(t *testing.T) {
 			wantCode:  http.StatusForbidden,
 			wantError: true,
 		},
+		"User deactivated by an admin": {
+			reqBody:   `{"email":"deprovisioned@test.com"}`,
+			wantCode:  http.StatusForbidden,
+			wantError: true,
+		},
 		"User already activated": {
 			reqBody:   `{"email":"activated@test.com"}`,
 			wantCode:  http.StatusConflict,
@@ -130,24 +146,30 @@ func TestActivateAccount(t *testing.T) {
 
 	userIDValid := uuid.New()
 	userIDConflict := uuid.New()
+	userIDRaced := uuid.New()
 	userIDErr := uuid.New()
 
 	mockUserService.On("GetForToken", mock.Anything, "token").Return(&data.User{ID: userIDValid, Email: "test@test.test", Username: "testuser"}, nil)
 	mockUserService.On("GetForToken", mock.Anything, "editConflict").Return(&data.User{ID: userIDConflict, Email: "editConflict@test.test", Username: "testuser2"}, nil)
+	mockUserService.On("GetForToken", mock.Anything, "activated").Return(&data.User{ID: uuid.New(), Email: "activated@test.test", Username: "activatedUser", IsActivated: true}, nil)
+	mockUserService.On("GetForToken", mock.Anything, "raced").Return(&data.User{ID: userIDRaced, Email: "raced@test.test", Username: "racedUser"}, nil)
 	mockUserService.On("GetForToken", mock.Anything, "updateUserFail").Return(&data.User{ID: userIDErr, Email: "update@test.test", Username: "updateErrorUser"}, nil)
 	mockUserService.On("GetForToken", mock.Anything, "banned").Return(&data.User{ID: uuid.New(), Email: "banned@test.test", Username: "bannedUser", Ban: &data.Ban{
-		ExpiresAt: time.Now().Add(time.Hour),
+		ExpiresAt: utils.Ptr(time.Now().Add(time.Hour)),
 	}}, nil)
+	mockUserService.On("GetForToken", mock.Anything, "deprovisioned").Return(&data.User{ID: uuid.New(), Email: "deprovisioned@test.test", Username: "deprovisionedUser", DeactivatedAt: utils.Ptr(time.Now().Add(-time.Hour))}, nil)
 	mockUserService.On("GetForToken", mock.Anything, "-").Return(nil, services.ErrRecordNotFound)
 	mockUserService.On("GetForToken", mock.Anything, "internal error").Return(nil, services.ErrInternal)
 
 	mockUserService.On("UpdateUser", userIDConflict, mock.Anything).Return(nil, services.ErrEditConflict)
+	mockUserService.On("UpdateUser", userIDRaced, mock.Anything).Return(nil, services.ErrEditConflict)
+	mockUserService.On("UpdateUser", userIDErr, mock.Anything).Return(nil, services.ErrInternal)
 	mockUserService.On("UpdateUser", mock.Anything, mock.Anything).Return(&data.User{ID: userIDValid, Email: "test@test.test", Username: "testuser"}, nil)
 
-	mockTokenService.On("DeleteAllForUser", mock.Anything, userIDErr).Return(services.ErrInternal)
-	mockTokenService.On("DeleteAllForUser", mock.Anything, mock.Anything).Return(nil)
+	mockUserService.On("GetUserByID", userIDConflict).Return(&data.User{ID: userIDConflict, IsActivated: false}, nil)
+	mockUserService.On("GetUserByID", userIDRaced).Return(&data.User{ID: userIDRaced, IsActivated: true}, nil)
 
-	handler := NewTokenHandler(&mockUserService, &mockTokenService, &mockMailerService)
+	handler := NewTokenHandler(&mockUserService, &mockTokenService, &mockMailerService, &mocks.MockPasswordService{}, &mocks.MockAuditService{})
 
 	tests := map[string]struct {
 		token     string
@@ -164,11 +186,26 @@ func TestActivateAccount(t *testing.T) {
 			wantCode:  http.StatusConflict,
 			wantError: true,
 		},
+		"Already activated": {
+			token:     "activated",
+			wantCode:  http.StatusOK,
+			wantError: false,
+		},
+		"Activated by a concurrent request": {
+			token:     "raced",
+			wantCode:  http.StatusOK,
+			wantError: false,
+		},
 		"Banned user": {
 			token:     "banned",
 			wantCode:  http.StatusForbidden,
 			wantError: true,
 		},
+		"User deactivated by an admin": {
+			token:     "deprovisioned",
+			wantCode:  http.StatusForbidden,
+			wantError: true,
+		},
 		"Invalid token": {
 			token:     "",
 			wantCode:  http.StatusBadRequest,
@@ -214,8 +251,74 @@ func TestActivateAccount(t *testing.T) {
 	}
 
 	mockUserService.AssertExpectations(t)
-	mockTokenService.AssertExpectations(t)
+	mockTokenService.AssertNotCalled(t, "DeleteAllForUser", data.ScopeUserActivation, mock.Anything)
+}
+
+func TestActivationStatus(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	mockUserService := mocks.MockUserService{}
+	handler := NewTokenHandler(&mockUserService, &mocks.MockTokenService{}, &mocks.MockMailService{}, &mocks.MockPasswordService{}, &mocks.MockAuditService{})
+
+	mockUserService.On("GetForToken", data.ScopeActivationStatus, "active").Return(&data.User{ID: uuid.New(), IsActivated: true}, nil)
+	mockUserService.On("GetForToken", data.ScopeActivationStatus, "pending").Return(&data.User{ID: uuid.New(), IsActivated: false}, nil)
+	mockUserService.On("GetForToken", data.ScopeActivationStatus, "unknown").Return(nil, services.ErrRecordNotFound)
+	mockUserService.On("GetForToken", data.ScopeActivationStatus, "error").Return(nil, services.ErrInternal)
+
+	tests := map[string]struct {
+		token      string
+		wantCode   int
+		wantStatus string
+		wantError  bool
+	}{
+		"Missing token": {
+			token:     "",
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Activated account": {
+			token:      "active",
+			wantCode:   http.StatusOK,
+			wantStatus: "activated",
+		},
+		"Pending account": {
+			token:      "pending",
+			wantCode:   http.StatusOK,
+			wantStatus: "pending",
+		},
+		"Unknown token looks pending": {
+			token:      "unknown",
+			wantCode:   http.StatusOK,
+			wantStatus: "pending",
+		},
+		"Internal error": {
+			token:     "error",
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/auth/activation-status?token="+tt.token, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.ActivationStatus(c)
 
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.JSONEq(t, `{"status":"`+tt.wantStatus+`"}`, rec.Body.String())
+			}
+		})
+	}
 }
 
 func TestRequestPasswordReset(t *testing.T) {
@@ -235,17 +338,18 @@ func TestRequestPasswordReset(t *testing.T) {
 	mockUserService.On("GetUserByEmail", "resetTokenFail@test.test").Return(&data.User{ID: userIDFail, Email: "resetTokenFail@test.test", Username: "resetTokenFail", IsActivated: true}, nil)
 	mockUserService.On("GetUserByEmail", "notactivated@test.test").Return(&data.User{ID: userID, Email: "test@test.test", Username: "testuser", IsActivated: false}, nil)
 	mockUserService.On("GetUserByEmail", "banned@test.test").Return(&data.User{ID: userID, Email: "banned@test.test", Username: "testuser", IsActivated: false, Ban: &data.Ban{
-		ExpiresAt: time.Now().Add(time.Hour),
+		ExpiresAt: utils.Ptr(time.Now().Add(time.Hour)),
 	}}, nil)
 
-	mockTokenService.On("New", userID, mock.Anything, data.ScopePasswordReset).Return(&data.Token{
+	mockTokenService.On("New", userID, data.ScopePasswordReset).Return(&data.Token{
 		Plaintext: "mocktoken",
 		Scope:     data.ScopePasswordReset,
+		ExpiresAt: time.Date(2030, 1, 2, 15, 4, 0, 0, time.UTC),
 	}, nil)
-	mockTokenService.On("New", userIDFail, mock.Anything, data.ScopePasswordReset).Return(nil, services.ErrInternal)
-	mockMailerService.On("SendEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockTokenService.On("New", userIDFail, data.ScopePasswordReset).Return(nil, services.ErrInternal)
+	mockMailerService.On("QueueEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-	handler := NewTokenHandler(&mockUserService, &mockTokenService, &mockMailerService)
+	handler := NewTokenHandler(&mockUserService, &mockTokenService, &mockMailerService, &mocks.MockPasswordService{}, &mocks.MockAuditService{})
 
 	tests := map[string]struct {
 		body      string
@@ -311,6 +415,7 @@ func TestRequestPasswordReset(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"expires_at":"2030-01-02T15:04:00Z"`)
 			}
 		})
 	}
@@ -336,7 +441,7 @@ func TestResetPassword(t *testing.T) {
 	mockUserService.On("GetForToken", data.ScopePasswordReset, "validtoken").Return(validUser, nil)
 	mockUserService.On("GetForToken", data.ScopePasswordReset, "validtoken2").Return(&data.User{ID: userIDInternalFail, Email: "fail@test.test", Username: "failuser", IsActivated: true}, nil)
 	mockUserService.On("GetForToken", data.ScopePasswordReset, "bannedtoken").Return(&data.User{ID: uuid.New(), Email: "fail@test.test", Username: "failuser", IsActivated: true, Ban: &data.Ban{
-		ExpiresAt: time.Now().Add(time.Hour),
+		ExpiresAt: utils.Ptr(time.Now().Add(time.Hour)),
 	}}, nil)
 	mockUserService.On("GetForToken", data.ScopePasswordReset, "badtoken").Return(nil, services.ErrRecordNotFound)
 	mockUserService.On("GetForToken", data.ScopePasswordReset, "internalerror").Return(nil, services.ErrInternal)
@@ -348,7 +453,11 @@ func TestResetPassword(t *testing.T) {
 	mockTokenService.On("DeleteAllForUser", data.ScopePasswordReset, userIDValid).Return(nil)
 	mockTokenService.On("DeleteAllForUser", data.ScopePasswordReset, userIDInternalFail).Return(services.ErrInternal)
 
-	handler := NewTokenHandler(&mockUserService, &mockTokenService, &mockMailerService)
+	mockPasswordService := mocks.MockPasswordService{}
+	mockPasswordService.On("Evaluate", "short", mock.Anything).Return(data.PasswordStrength{Score: 0, Issues: []data.PasswordIssue{{Code: data.PasswordTooShort, Message: "Password must be at least 8 characters long"}}})
+	mockPasswordService.On("Evaluate", mock.Anything, mock.Anything).Return(data.PasswordStrength{Score: 4, Issues: []data.PasswordIssue{}})
+
+	handler := NewTokenHandler(&mockUserService, &mockTokenService, &mockMailerService, &mockPasswordService, &mocks.MockAuditService{})
 
 	tests := map[string]struct {
 		token     string
@@ -447,6 +556,7 @@ func TestResetPassword(t *testing.T) {
 	mockUserService.AssertExpectations(t)
 	mockTokenService.AssertExpectations(t)
 	mockMailerService.AssertExpectations(t)
+	mockPasswordService.AssertExpectations(t)
 }
 
 func TestRequestDeactivationToken(t *testing.T) {
@@ -476,10 +586,10 @@ func TestRequestDeactivationToken(t *testing.T) {
 	}
 	newDeactivationToken := data.Token{Plaintext: "new-token", Scope: data.ScopeDeactivate}
 
-	handler := NewTokenHandler(&mockUserService, &mockTokenService, &mockMailerService)
+	handler := NewTokenHandler(&mockUserService, &mockTokenService, &mockMailerService, &mocks.MockPasswordService{}, &mocks.MockAuditService{})
 
-	mockTokenService.On("New", mock.Anything, mock.Anything, mock.Anything).Return(&newDeactivationToken, nil)
-	mockMailerService.On("SendEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockTokenService.On("New", mock.Anything, mock.Anything).Return(&newDeactivationToken, nil)
+	mockMailerService.On("QueueEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
 	tests := map[string]struct {
 		contextUser *data.User
@@ -551,3 +661,216 @@ func TestRequestDeactivationToken(t *testing.T) {
 
 	mockTokenService.AssertExpectations(t)
 }
+
+func TestRevokeTokens(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	admin := &data.User{ID: uuid.New(), Username: "admin", IsActivated: true}
+	userID := uuid.New()
+
+	tests := map[string]struct {
+		reqBody   string
+		revoked   int64
+		auditErr  error
+		wantCode  int
+		wantError bool
+	}{
+		"Revoke scope": {
+			reqBody:  `{"scope":"refresh"}`,
+			revoked:  42,
+			wantCode: http.StatusOK,
+		},
+		"Revoke user tokens issued before": {
+			reqBody:  `{"user_id":"` + userID.String() + `","issued_before":"2025-01-01T00:00:00Z"}`,
+			revoked:  3,
+			wantCode: http.StatusOK,
+		},
+		"No filter": {
+			reqBody:   `{}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Unknown scope": {
+			reqBody:   `{"scope":"everything"}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Audit log unavailable": {
+			reqBody:   `{"scope":"refresh"}`,
+			auditErr:  errors.New("db down"),
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockTokenService := mocks.MockTokenService{}
+			mockAuditService := mocks.MockAuditService{}
+			mockTokenService.On("Revoke", mock.Anything).Return(tt.revoked, nil)
+			mockAuditService.On("Record", mock.MatchedBy(func(entry data.AuditEntry) bool {
+				return entry.Action == data.AuditTokensRevoke && *entry.ActorID == admin.ID
+			})).Return(tt.auditErr)
+
+			handler := NewTokenHandler(&mocks.MockUserService{}, &mockTokenService, &mocks.MockMailService{}, &mocks.MockPasswordService{}, &mockAuditService)
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", admin)
+
+			err := handler.Revoke(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+				mockTokenService.AssertNotCalled(t, "Revoke", mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"revoked":%d`, tt.revoked))
+				mockAuditService.AssertExpectations(t)
+			}
+		})
+	}
+}
+
+func TestSupportEmails(t *testing.T) {
+	e := echo.New()
+
+	admin := &data.User{ID: uuid.New(), Username: "admin", IsActivated: true}
+	inactive := &data.User{ID: uuid.New(), Username: "inactive", Email: "inactive@example.com"}
+	active := &data.User{ID: uuid.New(), Username: "active", Email: "active@example.com", IsActivated: true}
+	banned := &data.User{ID: uuid.New(), Username: "banned", Email: "banned@example.com", Ban: &data.Ban{ExpiresAt: utils.Ptr(time.Now().Add(time.Hour))}}
+	missingID := uuid.New()
+	expiresAt := time.Now().Add(time.Hour)
+
+	audited := func(a *mocks.MockAuditService, action string, user *data.User) {
+		a.On("Record", mock.MatchedBy(func(entry data.AuditEntry) bool {
+			return entry.Action == action && *entry.ActorID == admin.ID && entry.TargetID == user.ID.String()
+		})).Return(nil)
+	}
+
+	tests := map[string]struct {
+		userID     string
+		reset      bool
+		setupMocks func(u *mocks.MockUserService, tk *mocks.MockTokenService, m *mocks.MockMailService, a *mocks.MockAuditService)
+		wantCode   int
+		wantError  bool
+	}{
+		"Invalid user ID": {
+			userID:    "abc",
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"User not found": {
+			userID: missingID.String(),
+			setupMocks: func(u *mocks.MockUserService, tk *mocks.MockTokenService, m *mocks.MockMailService, a *mocks.MockAuditService) {
+				u.On("GetUserByID", missingID).Return(nil, services.ErrUserNotFound)
+			},
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Banned user": {
+			userID: banned.ID.String(),
+			setupMocks: func(u *mocks.MockUserService, tk *mocks.MockTokenService, m *mocks.MockMailService, a *mocks.MockAuditService) {
+				u.On("GetUserByID", banned.ID).Return(banned, nil)
+			},
+			wantCode:  http.StatusConflict,
+			wantError: true,
+		},
+		"Activation of an activated user": {
+			userID: active.ID.String(),
+			setupMocks: func(u *mocks.MockUserService, tk *mocks.MockTokenService, m *mocks.MockMailService, a *mocks.MockAuditService) {
+				u.On("GetUserByID", active.ID).Return(active, nil)
+			},
+			wantCode:  http.StatusConflict,
+			wantError: true,
+		},
+		"Activation resent": {
+			userID: inactive.ID.String(),
+			setupMocks: func(u *mocks.MockUserService, tk *mocks.MockTokenService, m *mocks.MockMailService, a *mocks.MockAuditService) {
+				u.On("GetUserByID", inactive.ID).Return(inactive, nil)
+				audited(a, data.AuditActivationResend, inactive)
+				tk.On("New", inactive.ID, data.ScopeUserActivation).Return(&data.Token{Plaintext: "token", ExpiresAt: expiresAt}, nil)
+				m.On("QueueEmail", inactive.Email, mock.Anything, "activation", mock.Anything).Return(nil)
+			},
+			wantCode: http.StatusAccepted,
+		},
+		"Reset of an inactive user": {
+			userID: inactive.ID.String(),
+			reset:  true,
+			setupMocks: func(u *mocks.MockUserService, tk *mocks.MockTokenService, m *mocks.MockMailService, a *mocks.MockAuditService) {
+				u.On("GetUserByID", inactive.ID).Return(inactive, nil)
+			},
+			wantCode:  http.StatusConflict,
+			wantError: true,
+		},
+		"Audit log unavailable": {
+			userID: active.ID.String(),
+			reset:  true,
+			setupMocks: func(u *mocks.MockUserService, tk *mocks.MockTokenService, m *mocks.MockMailService, a *mocks.MockAuditService) {
+				u.On("GetUserByID", active.ID).Return(active, nil)
+				a.On("Record", mock.Anything).Return(errors.New("db down"))
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+		"Reset sent": {
+			userID: active.ID.String(),
+			reset:  true,
+			setupMocks: func(u *mocks.MockUserService, tk *mocks.MockTokenService, m *mocks.MockMailService, a *mocks.MockAuditService) {
+				u.On("GetUserByID", active.ID).Return(active, nil)
+				audited(a, data.AuditPasswordResetSend, active)
+				tk.On("New", active.ID, data.ScopePasswordReset).Return(&data.Token{Plaintext: "token", ExpiresAt: expiresAt}, nil)
+				m.On("QueueEmail", active.Email, mock.Anything, "reset", mock.Anything).Return(nil)
+			},
+			wantCode: http.StatusAccepted,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockUserService := mocks.MockUserService{}
+			mockTokenService := mocks.MockTokenService{}
+			mockMailService := mocks.MockMailService{}
+			mockAuditService := mocks.MockAuditService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockUserService, &mockTokenService, &mockMailService, &mockAuditService)
+			}
+			handler := NewTokenHandler(&mockUserService, &mockTokenService, &mockMailService, &mocks.MockPasswordService{}, &mockAuditService)
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.userID)
+			c.Set("user", admin)
+
+			var err error
+			if tt.reset {
+				err = handler.SendPasswordReset(c)
+			} else {
+				err = handler.ResendActivation(c)
+			}
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+				mockTokenService.AssertNotCalled(t, "New", mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+			mockUserService.AssertExpectations(t)
+			mockTokenService.AssertExpectations(t)
+			mockMailService.AssertExpectations(t)
+			mockAuditService.AssertExpectations(t)
+		})
+	}
+}
@@ -4,12 +4,14 @@ import (
 	"errors"
 	"net/http"
 	"net/url"
-	"time"
 
 	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
 	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/audit"
 	"NodeTurtleAPI/internal/services/auth"
 	"NodeTurtleAPI/internal/services/mail"
+	"NodeTurtleAPI/internal/services/passwords"
 	"NodeTurtleAPI/internal/services/tokens"
 	"NodeTurtleAPI/internal/services/users"
 
@@ -19,21 +21,25 @@ import (
 
 // UserHandler handles HTTP requests related to user operations.
 type UserHandler struct {
-	userService  users.IUserService
-	authService  auth.IAuthService
-	tokenService tokens.ITokenService
-	banService   services.IBanService
-	mailService  mail.IMailService
+	userService     users.IUserService
+	authService     auth.IAuthService
+	tokenService    tokens.ITokenService
+	banService      services.IBanService
+	mailService     mail.IMailService
+	passwordService passwords.IPasswordService
+	auditService    audit.IAuditService
 }
 
 // NewUserHandler creates a new UserHandler with the provided services.
-func NewUserHandler(userService users.IUserService, authService auth.IAuthService, tokenService tokens.ITokenService, banService services.IBanService, mailService mail.IMailService) UserHandler {
+func NewUserHandler(userService users.IUserService, authService auth.IAuthService, tokenService tokens.ITokenService, banService services.IBanService, mailService mail.IMailService, passwordService passwords.IPasswordService, auditService audit.IAuditService) UserHandler {
 	return UserHandler{
-		userService:  userService,
-		authService:  authService,
-		tokenService: tokenService,
-		banService:   banService,
-		mailService:  mailService,
+		userService:     userService,
+		authService:     authService,
+		tokenService:    tokenService,
+		banService:      banService,
+		mailService:     mailService,
+		passwordService: passwordService,
+		auditService:    auditService,
 	}
 }
 
@@ -65,9 +71,9 @@ func (h *UserHandler) CheckEmail(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
 	}
 
-	exists, err := h.userService.EmailExists(param.Email)
+	exists, err := h.userService.EmailExists(c.Request().Context(), param.Email)
 	if err != nil && err != services.ErrUserNotFound {
-		c.Logger().Errorf("Internal email validation error %v", err)
+		logging.Error(c.Request().Context(), "Internal email validation error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to validate email")
 	}
 
@@ -91,17 +97,40 @@ func (h *UserHandler) CheckUsername(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
 	}
 
-	exists, err := h.userService.UsernameExists(param.Username)
+	exists, err := h.userService.UsernameExists(c.Request().Context(), param.Username)
 	if err != nil && err != services.ErrUserNotFound {
-		c.Logger().Errorf("Internal username validation error %v", err)
+		logging.Error(c.Request().Context(), "Internal username validation error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to validate username")
 	}
 
 	return c.JSON(http.StatusOK, map[string]bool{"exists": exists})
 }
 
+// Resolve handles the request to find the user a username leads to, for profile and project URLs holding a username.
+// Moved is set when the username was given up, clients replace it with the current one.
+func (h *UserHandler) Resolve(c echo.Context) error {
+	username, err := url.PathUnescape(c.Param("username"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid username encoding")
+	}
+
+	resolved, err := h.userService.ResolveUsername(c.Request().Context(), username)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "User not found")
+		}
+		logging.Error(c.Request().Context(), "Internal username resolution error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to resolve username")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"user": resolved,
+	})
+}
+
 // UpdateCurrent handles the request to update the currently authenticated user's information.
 // It validates the updates, ensures the user is activated, and applies the changes.
+// Usernames can be changed once every data.UsernameChangeInterval, the old one keeps leading to the user.
 // Returns an error if the user is not authenticated, not found, not activated, or if the update fails.
 func (h *UserHandler) UpdateCurrent(c echo.Context) error {
 	contextUser, ok := c.Get("user").(*data.User)
@@ -130,7 +159,7 @@ func (h *UserHandler) UpdateCurrent(c echo.Context) error {
 	// Password revalidation
 	ok, err := contextUser.Password.Matches(payload.Password)
 	if err != nil {
-		c.Logger().Errorf("Internal password matching error %v", err)
+		logging.Error(c.Request().Context(), "Internal password matching error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to verify password")
 	}
 	if !ok {
@@ -145,9 +174,9 @@ func (h *UserHandler) UpdateCurrent(c echo.Context) error {
 
 	// Check if email is taken
 	if payload.Email != nil {
-		existingUser, err := h.userService.GetUserByEmail(*payload.Email)
+		existingUser, err := h.userService.GetUserByEmail(c.Request().Context(), *payload.Email)
 		if err != nil && err != services.ErrUserNotFound {
-			c.Logger().Errorf("Internal user update error %v", err)
+			logging.Error(c.Request().Context(), "Internal user update error", err)
 			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update user")
 		}
 		if existingUser != nil && existingUser.ID != contextUser.ID {
@@ -157,23 +186,49 @@ func (h *UserHandler) UpdateCurrent(c echo.Context) error {
 	}
 
 	// Check if username is taken
-	if payload.Username != nil {
-		existingUser, err := h.userService.GetUserByUsername(*payload.Username)
+	rename := payload.Username != nil && *payload.Username != contextUser.Username
+	if rename {
+		if !contextUser.Verified && data.LooksVerified(*payload.Username) {
+			return reservedUsername()
+		}
+
+		existingUser, err := h.userService.GetUserByUsername(c.Request().Context(), *payload.Username)
 		if err != nil && err != services.ErrUserNotFound {
-			c.Logger().Errorf("Internal user retrieval error %v", err)
+			logging.Error(c.Request().Context(), "Internal user retrieval error", err)
 			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update user")
 		}
 		if existingUser != nil && existingUser.ID != contextUser.ID {
 			return echo.NewHTTPError(http.StatusConflict, "Username already in use")
 		}
-		updates.Username = payload.Username
 	}
 
-	user, err := h.userService.UpdateUser(contextUser.ID, updates)
+	user := contextUser
+	if rename {
+		user, err = h.userService.ChangeUsername(c.Request().Context(), contextUser.ID, *payload.Username)
+		if err != nil {
+			var cooldown *services.UsernameCooldownError
+			switch {
+			case errors.As(err, &cooldown):
+				return echo.NewHTTPError(http.StatusTooManyRequests, map[string]interface{}{
+					"message":     "Username can only be changed once every 30 days",
+					"next_change": cooldown.NextChange,
+				})
+			case errors.Is(err, services.ErrDuplicateUsername):
+				return echo.NewHTTPError(http.StatusConflict, "Username already in use")
+			case errors.Is(err, services.ErrUsernameReserved):
+				return echo.NewHTTPError(http.StatusConflict, "Username is reserved for its previous owner")
+			}
+			logging.Error(c.Request().Context(), "Internal username change error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update user")
+		}
+	}
 
-	if err != nil {
-		c.Logger().Errorf("Internal user update error %v", err)
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update user")
+	if updates.Email != nil {
+		user, err = h.userService.UpdateUser(c.Request().Context(), contextUser.ID, updates)
+		if err != nil {
+			logging.Error(c.Request().Context(), "Internal user update error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update user")
+		}
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
@@ -198,7 +253,7 @@ func (h *UserHandler) ChangePassword(c echo.Context) error {
 
 	var payload struct {
 		OldPassword string `json:"old_password" validate:"required"`
-		NewPassword string `json:"new_password" validate:"required,min=8"`
+		NewPassword string `json:"new_password" validate:"required"`
 	}
 
 	if err := c.Bind(&payload); err != nil {
@@ -209,22 +264,41 @@ func (h *UserHandler) ChangePassword(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
 	}
 
-	if err := h.userService.ChangePassword(contextUser.ID, payload.OldPassword, payload.NewPassword); err != nil {
+	if strength := h.passwordService.Evaluate(payload.NewPassword, []string{contextUser.Username, contextUser.Email}); !strength.Acceptable() {
+		return weakPassword(strength)
+	}
+
+	if err := h.userService.ChangePassword(c.Request().Context(), contextUser.ID, payload.OldPassword, payload.NewPassword); err != nil {
 		if errors.Is(err, services.ErrInvalidCredentials) {
 			return echo.NewHTTPError(http.StatusBadRequest, "Current password is incorrect")
 		}
-		c.Logger().Errorf("Internal password change error %v", err)
+		logging.Error(c.Request().Context(), "Internal password change error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to change password")
 	}
 
-	if err := h.tokenService.DeleteAllForUser(data.ScopeRefresh, contextUser.ID); err != nil {
-		c.Logger().Errorf("Internal token deletion error %v", err)
+	if err := h.tokenService.DeleteAllForUser(c.Request().Context(), data.ScopeRefresh, contextUser.ID); err != nil {
+		logging.Error(c.Request().Context(), "Internal token deletion error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to change password")
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
 
+// weakPassword reports every reason a password was rejected by the password policy,
+// so the client can show them next to the password field.
+func weakPassword(strength data.PasswordStrength) *echo.HTTPError {
+	return echo.NewHTTPError(http.StatusUnprocessableEntity, map[string]interface{}{
+		"message": "Password does not meet the requirements",
+		"score":   strength.Score,
+		"errors":  strength.Issues,
+	})
+}
+
+// reservedUsername rejects usernames that could pass for the verified badge on an unverified account.
+func reservedUsername() *echo.HTTPError {
+	return echo.NewHTTPError(http.StatusUnprocessableEntity, `Usernames containing "verified" or "official" are reserved for verified accounts`)
+}
+
 // List handles the request to retrieve a paginated list of all users.
 // binds payload to data.UserFilter for filtering options
 func (h *UserHandler) List(c echo.Context) error {
@@ -235,13 +309,13 @@ func (h *UserHandler) List(c echo.Context) error {
 	}
 
 	if err := c.Validate(&filters); err != nil {
-		c.Logger().Errorf("Filter validation error: %v", err)
+		logging.Error(c.Request().Context(), "Filter validation error", err)
 		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
 	}
 
-	users, total, err := h.userService.ListUsers(filters)
+	users, total, err := h.userService.ListUsers(c.Request().Context(), filters)
 	if err != nil {
-		c.Logger().Errorf("Internal user retrieval error %v", err)
+		logging.Error(c.Request().Context(), "Internal user retrieval error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve users")
 	}
 
@@ -264,12 +338,12 @@ func (h *UserHandler) Get(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID")
 	}
 
-	user, err := h.userService.GetUserByID(id)
+	user, err := h.userService.GetUserByID(c.Request().Context(), id)
 	if err != nil {
 		if errors.Is(err, services.ErrUserNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "User not found")
 		}
-		c.Logger().Errorf("Internal user retrieval error %v", err)
+		logging.Error(c.Request().Context(), "Internal user retrieval error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to get user")
 	}
 
@@ -281,18 +355,23 @@ func (h *UserHandler) Get(c echo.Context) error {
 // Returns an error if the user ID is invalid, if the user is not found,
 // if no valid updates are provided, or if the update fails.
 func (h *UserHandler) Update(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID")
 	}
 
-	user, err := h.userService.GetUserByID(id)
+	user, err := h.userService.GetUserByID(c.Request().Context(), id)
 	if err != nil {
 		if errors.Is(err, services.ErrUserNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "User not found")
 		}
-		c.Logger().Errorf("Internal user retrieval error %v", err)
+		logging.Error(c.Request().Context(), "Internal user retrieval error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to get user")
 	}
 
@@ -310,9 +389,9 @@ func (h *UserHandler) Update(c echo.Context) error {
 
 	// Check if email is taken
 	if updates.Email != nil {
-		existingUser, err := h.userService.GetUserByEmail(*updates.Email)
+		existingUser, err := h.userService.GetUserByEmail(c.Request().Context(), *updates.Email)
 		if err != nil && err != services.ErrUserNotFound {
-			c.Logger().Errorf("Internal user retrieval error %v", err)
+			logging.Error(c.Request().Context(), "Internal user retrieval error", err)
 			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update user")
 		}
 		if existingUser != nil && existingUser.ID != user.ID {
@@ -322,9 +401,9 @@ func (h *UserHandler) Update(c echo.Context) error {
 
 	// Check if username is taken
 	if updates.Username != nil {
-		existingUser, err := h.userService.GetUserByUsername(*updates.Username)
+		existingUser, err := h.userService.GetUserByUsername(c.Request().Context(), *updates.Username)
 		if err != nil && err != services.ErrUserNotFound {
-			c.Logger().Errorf("Internal user retrieval error %v", err)
+			logging.Error(c.Request().Context(), "Internal user retrieval error", err)
 			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update user")
 		}
 		if existingUser != nil && existingUser.ID != user.ID {
@@ -332,10 +411,25 @@ func (h *UserHandler) Update(c echo.Context) error {
 		}
 	}
 
-	user, err = h.userService.UpdateUser(user.ID, updates)
+	if updates.Role != nil && updates.Role.ToID() != user.Role.ID {
+		err = h.record(c, contextUser, data.AuditUserRoleChange, user.ID, map[string]interface{}{
+			"from": user.Role.Name,
+			"to":   *updates.Role,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if changes := userChanges(updates); len(changes) > 0 {
+		if err := h.record(c, contextUser, data.AuditUserUpdate, user.ID, changes); err != nil {
+			return err
+		}
+	}
+
+	user, err = h.userService.UpdateUser(c.Request().Context(), user.ID, updates)
 
 	if err != nil {
-		c.Logger().Errorf("Internal user update error %v", err)
+		logging.Error(c.Request().Context(), "Internal user update error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update user")
 	}
 
@@ -352,17 +446,26 @@ func (h *UserHandler) Update(c echo.Context) error {
 // Returns an error if the user ID is invalid, if the user is not found,
 // or if the deletion fails.
 func (h *UserHandler) Delete(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID")
 	}
 
-	if err := h.userService.DeleteUser(id); err != nil {
+	if err := h.record(c, contextUser, data.AuditUserDelete, id, nil); err != nil {
+		return err
+	}
+
+	if err := h.userService.DeleteUser(c.Request().Context(), id); err != nil {
 		if errors.Is(err, services.ErrUserNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "User not found")
 		}
-		c.Logger().Errorf("Internal user update error %v", err)
+		logging.Error(c.Request().Context(), "Internal user update error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete user")
 	}
 
@@ -380,9 +483,10 @@ func (h *UserHandler) Ban(c echo.Context) error {
 	}
 
 	var payload struct {
-		Reason   string    `json:"reason" validate:"required,min=1"`
-		Duration int       `json:"duration" validate:"required,min=1"`
-		UserID   uuid.UUID `json:"user_id" validate:"required"`
+		Reason    string    `json:"reason" validate:"required,min=1"`
+		Duration  int       `json:"duration" validate:"omitempty,min=1"`
+		Permanent bool      `json:"permanent"`
+		UserID    uuid.UUID `json:"user_id" validate:"required"`
 	}
 
 	if err := c.Bind(&payload); err != nil {
@@ -392,27 +496,45 @@ func (h *UserHandler) Ban(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
 	}
 
-	userToBan, err := h.userService.GetUserByID(payload.UserID)
+	expiresAt, ok := banExpiry(payload.Duration, payload.Permanent)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, "A duration is required unless the ban is permanent")
+	}
+
+	userToBan, err := h.userService.GetUserByID(c.Request().Context(), payload.UserID)
 	if err != nil {
 		if err == services.ErrUserNotFound {
 			return echo.NewHTTPError(http.StatusNotFound, "User not found")
 		}
-		c.Logger().Errorf("Internal user retrieval error %v", err)
+		logging.Error(c.Request().Context(), "Internal user retrieval error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve user")
 	}
 
-	ban, err := h.banService.BanUser(payload.UserID, contextUser.ID, time.Now().UTC().Add(time.Duration(payload.Duration)*time.Hour), payload.Reason)
+	// roles are ordered by privilege, only admins may ban users ranked the same or higher than themselves
+	if contextUser.Role.Name != data.RoleAdmin.String() && userToBan.Role.ID >= contextUser.Role.ID {
+		return echo.NewHTTPError(http.StatusForbidden, "Cannot ban a user with an equal or higher role")
+	}
+
+	err = h.record(c, contextUser, data.AuditUserBan, payload.UserID, map[string]interface{}{
+		"reason":     payload.Reason,
+		"expires_at": expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	ban, err := h.banService.BanUser(c.Request().Context(), payload.UserID, contextUser.ID, expiresAt, payload.Reason)
 	if err != nil {
 		if err == services.ErrUserNotFound {
 			return echo.NewHTTPError(http.StatusNotFound, "User not found")
 		}
-		c.Logger().Errorf("Internal user ban error %v", err)
+		logging.Error(c.Request().Context(), "Internal user ban error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to ban a user")
 	}
 
 	// invalidate all refresh tokens for banned user
-	if err := h.tokenService.DeleteAllForUser(data.ScopeRefresh, payload.UserID); err != nil {
-		c.Logger().Errorf("Internal token deletion error %v", err)
+	if err := h.tokenService.DeleteAllForUser(c.Request().Context(), data.ScopeRefresh, payload.UserID); err != nil {
+		logging.Error(c.Request().Context(), "Internal token deletion error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to ban a user")
 	}
 
@@ -421,9 +543,14 @@ func (h *UserHandler) Ban(c echo.Context) error {
 		"Username":  userToBan.Username,
 		"Reason":    ban.Reason,
 		"BannedAt":  ban.BannedAt.Format("January 2, 2006 at 3:04 PM MST"),
-		"ExpiresAt": ban.ExpiresAt.Format("January 2, 2006 at 3:04 PM MST"),
+		"ExpiresAt": "Never, the suspension is permanent",
+	}
+	if !ban.Permanent() {
+		emailData["ExpiresAt"] = ban.ExpiresAt.Format("January 2, 2006 at 3:04 PM MST")
+	}
+	if err := h.mailService.QueueEmail(c.Request().Context(), userToBan.Email, "Account Suspended - Turtle Graphics", "ban", emailData); err != nil {
+		logging.Error(c.Request().Context(), "Failed to queue ban email", err)
 	}
-	go h.mailService.SendEmail(userToBan.Email, "Account Suspended - Turtle Graphics", "ban", emailData)
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"message": "User banned successfully",
@@ -436,47 +563,130 @@ func (h *UserHandler) Ban(c echo.Context) error {
 }
 
 func (h *UserHandler) Unban(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
 	idStr := c.Param("userID")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID")
 	}
 
-	if err := h.banService.UnbanUser(id); err != nil {
+	if err := h.record(c, contextUser, data.AuditUserUnban, id, nil); err != nil {
+		return err
+	}
+
+	if err := h.banService.UnbanUser(c.Request().Context(), id, contextUser.ID); err != nil {
 		if err == services.ErrUserNotFound {
 			return echo.NewHTTPError(http.StatusNotFound, "User not found")
 		}
-		c.Logger().Errorf("Internal user unban error %v", err)
+		logging.Error(c.Request().Context(), "Internal user unban error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to unban a user")
 	}
 
 	return c.NoContent(http.StatusOK)
 }
 
-func (h *UserHandler) Deactivate(c echo.Context) error {
+// Bulk handles the request to activate, deactivate, change the role of or ban a list of users at once.
+// Users that can't be changed, such as unknown ones, are reported per entry while the others are changed.
+// Bulk bans don't email the banned users, cleanups are mostly of spam accounts.
+func (h *UserHandler) Bulk(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var payload data.BulkUserUpdate
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
 
-	token := c.Param("token")
-	if token == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "Invalid reset token")
+	switch payload.Action {
+	case data.BulkSetRole:
+		if payload.Role == nil || !payload.Role.IsValid() {
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, "Invalid role")
+		}
+		if contextUser.Role.Name != data.RoleAdmin.String() && payload.Role.ToID() >= contextUser.Role.ID {
+			return echo.NewHTTPError(http.StatusForbidden, "Cannot grant a role equal to or higher than your own")
+		}
+	case data.BulkBan:
+		if payload.Reason == "" || (payload.Duration == 0 && !payload.Permanent) {
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, "A reason and, unless the ban is permanent, a duration are required to ban users")
+		}
 	}
 
-	user, err := h.userService.GetForToken(data.ScopeDeactivate, token)
+	err := h.auditService.Record(data.AuditEntry{
+		ActorID: &contextUser.ID,
+		Action:  data.AuditUsersBulk,
+		Details: map[string]interface{}{
+			"action":    payload.Action,
+			"user_ids":  payload.UserIDs,
+			"role":      payload.Role,
+			"reason":    payload.Reason,
+			"duration":  payload.Duration,
+			"permanent": payload.Permanent,
+		},
+		IP: c.RealIP(),
+	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusNotFound, "Token or user not found")
+		logging.Error(c.Request().Context(), "Internal audit log error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to record bulk update")
 	}
 
-	_, err = h.banService.BanUser(user.ID, user.ID, time.Now().Add(87600*time.Hour), "Self-deactivation")
+	results, err := h.userService.BulkUpdateUsers(c.Request().Context(), *contextUser, payload)
 	if err != nil {
-		c.Logger().Errorf("Internal self-deactivation error %v", err)
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to deactivate account")
+		logging.Error(c.Request().Context(), "Internal bulk user update error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update users")
 	}
 
-	if err := h.tokenService.DeleteAllForUser(data.ScopeRefresh, user.ID); err != nil {
-		c.Logger().Errorf("Internal token deletion error %v", err)
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to deactivate account")
+	updated := 0
+	for _, result := range results {
+		if result.Error == "" {
+			updated++
+		}
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{
-		"message": "Account has been deactivated",
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"updated": updated,
+		"failed":  len(results) - updated,
+		"results": results,
+	})
+}
+
+// record adds an action the current user takes on a user to the audit log. Actions are recorded before they are
+// taken, an action that can't be recorded is refused.
+func (h *UserHandler) record(c echo.Context, actor *data.User, action string, userID uuid.UUID, details map[string]interface{}) error {
+	err := h.auditService.Record(data.AuditEntry{
+		ActorID:    &actor.ID,
+		Action:     action,
+		TargetType: "user",
+		TargetID:   userID.String(),
+		Details:    details,
+		IP:         c.RealIP(),
 	})
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal audit log error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to record the action")
+	}
+	return nil
+}
+
+// userChanges lists the fields an admin update changes other than the role, which is recorded on its own.
+func userChanges(updates data.UserUpdate) map[string]interface{} {
+	changes := map[string]interface{}{}
+	if updates.Username != nil {
+		changes["username"] = *updates.Username
+	}
+	if updates.Email != nil {
+		changes["email"] = *updates.Email
+	}
+	if updates.Activated != nil {
+		changes["activated"] = *updates.Activated
+	}
+	return changes
 }
@@ -4,6 +4,7 @@ import (
 	"NodeTurtleAPI/internal/data"
 	"NodeTurtleAPI/internal/mocks"
 	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/utils"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -35,7 +36,7 @@ func TestGetCurrentUser(t *testing.T) {
 		IsActivated: true,
 	}
 
-	handler := NewUserHandler(&mockUserService, &mockAuthService, &mockTokenService, &mockBanService, &mockMailService)
+	handler := NewUserHandler(&mockUserService, &mockAuthService, &mockTokenService, &mockBanService, &mockMailService, &mocks.MockPasswordService{}, auditRecorder())
 
 	tests := map[string]struct {
 		contextUser *data.User
@@ -116,13 +117,26 @@ func TestUpdateCurrentUser(t *testing.T) {
 	}
 	_ = inactiveUser.Password.Set("testpass")
 
+	verifiedUser := &data.User{
+		ID:          uuid.New(),
+		Email:       "verified@test.com",
+		Username:    "verified",
+		IsActivated: true,
+		Verified:    true,
+	}
+	_ = verifiedUser.Password.Set("testpass")
+
 	mockUserService.On("GetUserByEmail", validUser2.Email).Return(validUser2, nil)
 	mockUserService.On("GetUserByEmail", mock.Anything).Return(nil, services.ErrUserNotFound)
 	mockUserService.On("GetUserByUsername", validUser2.Username).Return(validUser2, nil)
 	mockUserService.On("GetUserByUsername", mock.Anything).Return(nil, services.ErrUserNotFound)
 	mockUserService.On("UpdateUser", validUser.ID, mock.Anything).Return(validUser, nil)
+	mockUserService.On("ChangeUsername", validUser.ID, "changedrecently").Return(nil, &services.UsernameCooldownError{NextChange: time.Now().Add(24 * time.Hour)})
+	mockUserService.On("ChangeUsername", validUser.ID, "reservedname").Return(nil, services.ErrUsernameReserved)
+	mockUserService.On("ChangeUsername", validUser.ID, mock.Anything).Return(validUser, nil)
+	mockUserService.On("ChangeUsername", verifiedUser.ID, mock.Anything).Return(verifiedUser, nil)
 
-	handler := NewUserHandler(&mockUserService, &mockAuthService, &mockTokenService, &mockBanService, &mockMailService)
+	handler := NewUserHandler(&mockUserService, &mockAuthService, &mockTokenService, &mockBanService, &mockMailService, &mocks.MockPasswordService{}, auditRecorder())
 
 	tests := map[string]struct {
 		contextUser *data.User
@@ -154,6 +168,30 @@ func TestUpdateCurrentUser(t *testing.T) {
 			wantCode:    http.StatusConflict,
 			wantError:   true,
 		},
+		"Username changed recently": {
+			contextUser: validUser,
+			reqBody:     `{"username":"changedrecently","password":"testpass"}`,
+			wantCode:    http.StatusTooManyRequests,
+			wantError:   true,
+		},
+		"Username reserved for its previous owner": {
+			contextUser: validUser,
+			reqBody:     `{"username":"reservedname","password":"testpass"}`,
+			wantCode:    http.StatusConflict,
+			wantError:   true,
+		},
+		"Verified-looking username": {
+			contextUser: validUser,
+			reqBody:     `{"username":"AliceOfficial","password":"testpass"}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Verified account may use verified-looking username": {
+			contextUser: verifiedUser,
+			reqBody:     `{"username":"AliceOfficial","password":"testpass"}`,
+			wantCode:    http.StatusOK,
+			wantError:   false,
+		},
 		"Incorrect password": {
 			contextUser: validUser,
 			reqBody:     `{"username":"newusername","email":"new@test.test","password":"incorrect"}`,
@@ -260,7 +298,11 @@ func TestChangePassword(t *testing.T) {
 	mockUserService.On("ChangePassword", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	mockTokenService.On("DeleteAllForUser", mock.Anything, mock.Anything).Return(nil)
 
-	handler := NewUserHandler(&mockUserService, &mockAuthService, &mockTokenService, &mockBanService, &mockMailService)
+	mockPasswordService := mocks.MockPasswordService{}
+	mockPasswordService.On("Evaluate", "123", mock.Anything).Return(data.PasswordStrength{Score: 0, Issues: []data.PasswordIssue{{Code: data.PasswordTooShort, Message: "Password must be at least 8 characters long"}}})
+	mockPasswordService.On("Evaluate", mock.Anything, mock.Anything).Return(data.PasswordStrength{Score: 4, Issues: []data.PasswordIssue{}})
+
+	handler := NewUserHandler(&mockUserService, &mockAuthService, &mockTokenService, &mockBanService, &mockMailService, &mockPasswordService, auditRecorder())
 
 	tests := map[string]struct {
 		contextUser *data.User
@@ -292,6 +334,12 @@ func TestChangePassword(t *testing.T) {
 			wantCode:    http.StatusUnprocessableEntity,
 			wantError:   true,
 		},
+		"Missing new password": {
+			contextUser: &validUser,
+			reqBody:     `{"old_password":"OldPassword123"}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
 		"Invalid JSON": {
 			contextUser: &validUser,
 			reqBody:     `{"old_password":`,
@@ -332,6 +380,7 @@ func TestChangePassword(t *testing.T) {
 	}
 
 	mockUserService.AssertExpectations(t)
+	mockPasswordService.AssertExpectations(t)
 }
 
 func TestListUsers(t *testing.T) {
@@ -344,7 +393,7 @@ func TestListUsers(t *testing.T) {
 	mockBanService := mocks.MockBanService{}
 	mockMailService := mocks.MockMailService{}
 
-	handler := NewUserHandler(&mockUserService, &mockAuthService, &mockTokenService, &mockBanService, &mockMailService)
+	handler := NewUserHandler(&mockUserService, &mockAuthService, &mockTokenService, &mockBanService, &mockMailService, &mocks.MockPasswordService{}, auditRecorder())
 
 	user1 := data.User{
 		ID:          uuid.New(),
@@ -429,7 +478,7 @@ func TestGetUserByID(t *testing.T) {
 	mockBanService := mocks.MockBanService{}
 	mockMailService := mocks.MockMailService{}
 
-	handler := NewUserHandler(&mockUserService, &mockAuthService, &mockTokenService, &mockBanService, &mockMailService)
+	handler := NewUserHandler(&mockUserService, &mockAuthService, &mockTokenService, &mockBanService, &mockMailService, &mocks.MockPasswordService{}, auditRecorder())
 
 	user := &data.User{
 		ID:          uuid.New(),
@@ -505,7 +554,8 @@ func TestUpdateUser(t *testing.T) {
 	mockBanService := mocks.MockBanService{}
 	mockMailService := mocks.MockMailService{}
 
-	handler := NewUserHandler(&mockUserService, &mockAuthService, &mockTokenService, &mockBanService, &mockMailService)
+	handler := NewUserHandler(&mockUserService, &mockAuthService, &mockTokenService, &mockBanService, &mockMailService, &mocks.MockPasswordService{}, auditRecorder())
+	adminUser := &data.User{ID: uuid.New(), Username: "adminuser", IsActivated: true, Role: data.Role{ID: data.RoleAdmin.ToID(), Name: data.RoleAdmin.String()}}
 
 	validUser := &data.User{
 		ID:          uuid.New(),
@@ -616,6 +666,7 @@ func TestUpdateUser(t *testing.T) {
 			c.SetPath("/api/:id")
 			c.SetParamNames("id")
 			c.SetParamValues(tt.userID)
+			c.Set("user", adminUser)
 
 			err := handler.Update(c)
 
@@ -646,9 +697,13 @@ func TestDeleteUser(t *testing.T) {
 	mockBanService := mocks.MockBanService{}
 	mockMailService := mocks.MockMailService{}
 
-	handler := NewUserHandler(&mockUserService, &mockAuthService, &mockTokenService, &mockBanService, &mockMailService)
+	mockAuditService := mocks.MockAuditService{}
+
+	handler := NewUserHandler(&mockUserService, &mockAuthService, &mockTokenService, &mockBanService, &mockMailService, &mocks.MockPasswordService{}, &mockAuditService)
+	adminUser := &data.User{ID: uuid.New(), Username: "adminuser", IsActivated: true, Role: data.Role{ID: data.RoleAdmin.ToID(), Name: data.RoleAdmin.String()}}
 
 	validUserID := uuid.New()
+	unrecordedUserID := uuid.New()
 
 	tests := map[string]struct {
 		userID    string
@@ -666,6 +721,11 @@ func TestDeleteUser(t *testing.T) {
 			wantCode:  http.StatusBadRequest,
 			wantError: true,
 		},
+		"Deletion can't be recorded": {
+			userID:    unrecordedUserID.String(),
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
 		"User not found": {
 			userID:    uuid.New().String(),
 			wantCode:  http.StatusNotFound,
@@ -674,6 +734,12 @@ func TestDeleteUser(t *testing.T) {
 	}
 
 	mockUserService.On("DeleteUser", validUserID).Return(nil)
+	mockAuditService.On("Record", mock.MatchedBy(func(entry data.AuditEntry) bool {
+		return entry.TargetID == unrecordedUserID.String()
+	})).Return(services.ErrInternal)
+	mockAuditService.On("Record", mock.MatchedBy(func(entry data.AuditEntry) bool {
+		return entry.Action == data.AuditUserDelete && *entry.ActorID == adminUser.ID
+	})).Return(nil)
 	mockUserService.On("DeleteUser", mock.Anything).Return(services.ErrUserNotFound)
 
 	for name, tt := range tests {
@@ -686,6 +752,7 @@ func TestDeleteUser(t *testing.T) {
 			c.SetPath("/api/:id")
 			c.SetParamNames("id")
 			c.SetParamValues(tt.userID)
+			c.Set("user", adminUser)
 
 			err := handler.Delete(c)
 
@@ -701,6 +768,7 @@ func TestDeleteUser(t *testing.T) {
 		})
 	}
 	mockUserService.AssertExpectations(t)
+	mockUserService.AssertNotCalled(t, "DeleteUser", unrecordedUserID)
 
 }
 
@@ -718,7 +786,7 @@ func TestCheckEmail(t *testing.T) {
 	mockUserService.On("EmailExists", "new@test.com").Return(false, services.ErrUserNotFound)
 	mockUserService.On("EmailExists", "error@test.com").Return(false, services.ErrInternal)
 
-	handler := NewUserHandler(&mockUserService, &mockAuthService, &mockTokenService, &mockBanService, &mockMailService)
+	handler := NewUserHandler(&mockUserService, &mockAuthService, &mockTokenService, &mockBanService, &mockMailService, &mocks.MockPasswordService{}, auditRecorder())
 
 	tests := map[string]struct {
 		email     string
@@ -799,7 +867,7 @@ func TestCheckUsername(t *testing.T) {
 	mockUserService.On("UsernameExists", "newusername").Return(false, services.ErrUserNotFound)
 	mockUserService.On("UsernameExists", "erroruser").Return(false, services.ErrInternal)
 
-	handler := NewUserHandler(&mockUserService, &mockAuthService, &mockTokenService, &mockBanService, &mockMailService)
+	handler := NewUserHandler(&mockUserService, &mockAuthService, &mockTokenService, &mockBanService, &mockMailService, &mocks.MockPasswordService{}, auditRecorder())
 
 	tests := map[string]struct {
 		username  string
@@ -871,6 +939,80 @@ func TestCheckUsername(t *testing.T) {
 	mockUserService.AssertExpectations(t)
 }
 
+func TestResolveUsername(t *testing.T) {
+	e := echo.New()
+
+	userID := uuid.New()
+
+	tests := map[string]struct {
+		username   string
+		setupMocks func(m *mocks.MockUserService)
+		wantCode   int
+		wantBody   string
+		wantError  bool
+	}{
+		"Current username": {
+			username: "alice",
+			setupMocks: func(m *mocks.MockUserService) {
+				m.On("ResolveUsername", "alice").Return(&data.ResolvedUsername{UserID: userID, Username: "alice"}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"user":{"user_id":"` + userID.String() + `","username":"alice","moved":false}}`,
+		},
+		"Previous username": {
+			username: "alicia",
+			setupMocks: func(m *mocks.MockUserService) {
+				m.On("ResolveUsername", "alicia").Return(&data.ResolvedUsername{UserID: userID, Username: "alice", Moved: true}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"user":{"user_id":"` + userID.String() + `","username":"alice","moved":true}}`,
+		},
+		"Unknown username": {
+			username: "nobody",
+			setupMocks: func(m *mocks.MockUserService) {
+				m.On("ResolveUsername", "nobody").Return(nil, services.ErrUserNotFound)
+			},
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Database error": {
+			username: "alice",
+			setupMocks: func(m *mocks.MockUserService) {
+				m.On("ResolveUsername", "alice").Return(nil, services.ErrInternal)
+			},
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockUserService := mocks.MockUserService{}
+			tt.setupMocks(&mockUserService)
+			handler := NewUserHandler(&mockUserService, &mocks.MockAuthService{}, &mocks.MockTokenService{}, &mocks.MockBanService{}, &mocks.MockMailService{}, &mocks.MockPasswordService{}, auditRecorder())
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("username")
+			c.SetParamValues(tt.username)
+
+			err := handler.Resolve(c)
+
+			if tt.wantError {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.JSONEq(t, tt.wantBody, rec.Body.String())
+			}
+			mockUserService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestBanUser(t *testing.T) {
 	e := echo.New()
 	e.Validator = &CustomValidator{validator: validator.New()}
@@ -881,18 +1023,21 @@ func TestBanUser(t *testing.T) {
 	mockBanService := mocks.MockBanService{}
 	mockMailService := mocks.MockMailService{}
 
-	adminUser := &data.User{ID: uuid.New(), Email: "admin@test.test", Username: "adminuser", IsActivated: true}
-	user := &data.User{ID: uuid.New()}
+	adminUser := &data.User{ID: uuid.New(), Email: "admin@test.test", Username: "adminuser", IsActivated: true, Role: data.Role{ID: data.RoleAdmin.ToID(), Name: data.RoleAdmin.String()}}
+	moderatorUser := &data.User{ID: uuid.New(), Username: "moderatoruser", IsActivated: true, Role: data.Role{ID: data.RoleModerator.ToID(), Name: data.RoleModerator.String()}}
+	user := &data.User{ID: uuid.New(), Role: data.Role{ID: data.RoleUser.ToID(), Name: data.RoleUser.String()}}
+	otherModerator := &data.User{ID: uuid.New(), Role: data.Role{ID: data.RoleModerator.ToID(), Name: data.RoleModerator.String()}}
 
-	mockBanService.On("BanUser", user.ID, adminUser.ID, mock.Anything, mock.Anything).Return(&data.Ban{ExpiresAt: time.Now().UTC(), Reason: "test", BannedAt: time.Now().UTC()}, nil)
+	mockUserService.On("GetUserByID", otherModerator.ID).Return(otherModerator, nil)
+	mockBanService.On("BanUser", user.ID, adminUser.ID, mock.Anything, mock.Anything).Return(&data.Ban{ExpiresAt: utils.Ptr(time.Now().UTC()), Reason: "test", BannedAt: time.Now().UTC()}, nil)
 	mockBanService.On("BanUser", mock.Anything, adminUser.ID, mock.Anything, mock.Anything).Return(nil, services.ErrUserNotFound)
 	mockUserService.On("GetUserByID", user.ID).Return(user, nil)
 	mockUserService.On("GetUserByID", mock.Anything).Return(nil, services.ErrUserNotFound)
-	mockMailService.On("SendEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockMailService.On("QueueEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	mockTokenService.On("DeleteAllForUser", data.ScopeRefresh, user.ID).Return(nil)
 	mockTokenService.On("DeleteAllForUser", data.ScopeRefresh, mock.Anything).Return(services.ErrInternal)
 
-	handler := NewUserHandler(&mockUserService, &mockAuthService, &mockTokenService, &mockBanService, &mockMailService)
+	handler := NewUserHandler(&mockUserService, &mockAuthService, &mockTokenService, &mockBanService, &mockMailService, &mocks.MockPasswordService{}, auditRecorder())
 
 	tests := map[string]struct {
 		contextUser *data.User
@@ -906,6 +1051,18 @@ func TestBanUser(t *testing.T) {
 			wantCode:    http.StatusOK,
 			wantError:   false,
 		},
+		"Successful permanent ban": {
+			contextUser: adminUser,
+			body:        fmt.Sprintf(`{"reason":"test","permanent":true,"user_id":"%s"}`, user.ID),
+			wantCode:    http.StatusOK,
+			wantError:   false,
+		},
+		"Moderator bans another moderator": {
+			contextUser: moderatorUser,
+			body:        fmt.Sprintf(`{"reason":"test","duration":24,"user_id":"%s"}`, otherModerator.ID),
+			wantCode:    http.StatusForbidden,
+			wantError:   true,
+		},
 		"Missing user in context": {
 			contextUser: nil,
 			body:        fmt.Sprintf(`{"reason":"test","duration":24,"user_id":"%s"}`, user.ID),
@@ -985,67 +1142,61 @@ func TestBanUser(t *testing.T) {
 	mockMailService.AssertExpectations(t)
 }
 
-func TestDeactivate(t *testing.T) {
+func TestUnbanUser(t *testing.T) {
+
 	e := echo.New()
 	e.Validator = &CustomValidator{validator: validator.New()}
 
-	mockUserService := new(mocks.MockUserService)
-	mockAuthService := new(mocks.MockAuthService)
-	mockTokenService := new(mocks.MockTokenService)
-	mockBanService := new(mocks.MockBanService)
-	mockMailService := new(mocks.MockMailService)
-
-	userID1 := uuid.New()
-	userIDErr := uuid.New()
-
-	mockUserService.On("GetForToken", mock.Anything, "token").Return(&data.User{ID: userID1, Email: "test@test.test", Username: "testuser"}, nil)
-	mockUserService.On("GetForToken", mock.Anything, "updateUserFail").Return(&data.User{ID: userIDErr, Email: "update@test.test", Username: "updateErrorUser"}, nil)
-	mockUserService.On("GetForToken", mock.Anything, "-").Return(nil, services.ErrRecordNotFound)
+	mockUserService := mocks.MockUserService{}
+	mockAuthService := mocks.MockAuthService{}
+	mockTokenService := mocks.MockTokenService{}
+	mockBanService := mocks.MockBanService{}
+	mockMailService := mocks.MockMailService{}
 
-	mockBanService.On("BanUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&data.Ban{}, nil)
+	handler := NewUserHandler(&mockUserService, &mockAuthService, &mockTokenService, &mockBanService, &mockMailService, &mocks.MockPasswordService{}, auditRecorder())
 
-	mockTokenService.On("DeleteAllForUser", mock.Anything, userIDErr).Return(services.ErrInternal)
-	mockTokenService.On("DeleteAllForUser", mock.Anything, mock.Anything).Return(nil)
+	adminUser := &data.User{ID: uuid.New(), Role: data.Role{ID: data.RoleAdmin.ToID(), Name: data.RoleAdmin.String()}}
+	validUserID := uuid.New()
 
-	handler := NewUserHandler(mockUserService, mockAuthService, mockTokenService, mockBanService, mockMailService)
+	mockBanService.On("UnbanUser", validUserID, adminUser.ID).Return(nil)
+	mockBanService.On("UnbanUser", mock.Anything, adminUser.ID).Return(services.ErrUserNotFound)
 
 	tests := map[string]struct {
-		token     string
+		userID    string
 		wantCode  int
+		wantBody  string
 		wantError bool
 	}{
-		"Valid token": {
-			token:     "token",
+		"Successful request": {
+			userID:    validUserID.String(),
 			wantCode:  http.StatusOK,
 			wantError: false,
 		},
-		"Invalid token": {
-			token:     "",
+		"Invalid user id": {
+			userID:    "1234",
 			wantCode:  http.StatusBadRequest,
 			wantError: true,
 		},
-		"user with token not found": {
-			token:     "-",
+		"User not found": {
+			userID:    uuid.New().String(),
 			wantCode:  http.StatusNotFound,
 			wantError: true,
 		},
-		"Failed to activate user": {
-			token:     "updateUserFail",
-			wantCode:  http.StatusInternalServerError,
-			wantError: true,
-		},
 	}
 
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 			rec := httptest.NewRecorder()
 			c := e.NewContext(req, rec)
-			c.SetPath("/api/:token")
-			c.SetParamNames("token")
-			c.SetParamValues(tt.token)
+			c.Set("user", adminUser)
 
-			err := handler.Deactivate(c)
+			c.SetPath("/api/:userID")
+			c.SetParamNames("userID")
+			c.SetParamValues(tt.userID)
+
+			err := handler.Unban(c)
 
 			if tt.wantError {
 				assert.Error(t, err)
@@ -1059,78 +1210,107 @@ func TestDeactivate(t *testing.T) {
 		})
 	}
 
-	mockUserService.AssertExpectations(t)
-	mockTokenService.AssertExpectations(t)
 	mockBanService.AssertExpectations(t)
 
 }
 
-func TestUnbanUser(t *testing.T) {
-
+func TestBulkUsers(t *testing.T) {
 	e := echo.New()
 	e.Validator = &CustomValidator{validator: validator.New()}
 
-	mockUserService := mocks.MockUserService{}
-	mockAuthService := mocks.MockAuthService{}
-	mockTokenService := mocks.MockTokenService{}
-	mockBanService := mocks.MockBanService{}
-	mockMailService := mocks.MockMailService{}
-
-	handler := NewUserHandler(&mockUserService, &mockAuthService, &mockTokenService, &mockBanService, &mockMailService)
-
-	validUserID := uuid.New()
-
-	mockBanService.On("UnbanUser", validUserID).Return(nil)
-	mockBanService.On("UnbanUser", mock.Anything).Return(services.ErrUserNotFound)
+	admin := &data.User{ID: uuid.New(), Role: data.Role{ID: data.RoleAdmin.ToID(), Name: data.RoleAdmin.String()}}
+	moderator := &data.User{ID: uuid.New(), Role: data.Role{ID: data.RoleModerator.ToID(), Name: data.RoleModerator.String()}}
+	userID, missingID := uuid.New(), uuid.New()
 
 	tests := map[string]struct {
-		userID    string
-		wantCode  int
-		wantBody  string
-		wantError bool
+		user        *data.User
+		body        string
+		mockResults []data.BulkUserResult
+		mockErr     error
+		wantCode    int
+		wantBody    string
 	}{
-		"Successful request": {
-			userID:    validUserID.String(),
-			wantCode:  http.StatusOK,
-			wantError: false,
-		},
-		"Invalid user id": {
-			userID:    "1234",
-			wantCode:  http.StatusBadRequest,
-			wantError: true,
+		"activates users, reporting the missing ones": {
+			user:        admin,
+			body:        fmt.Sprintf(`{"action":"activate","user_ids":["%s","%s"]}`, userID, missingID),
+			mockResults: []data.BulkUserResult{{UserID: userID, Username: "alice"}, {UserID: missingID, Error: "User not found"}},
+			wantCode:    http.StatusOK,
+			wantBody:    `"updated":1`,
 		},
-		"User not found": {
-			userID:    uuid.New().String(),
-			wantCode:  http.StatusNotFound,
-			wantError: true,
+		"bans users": {
+			user:        moderator,
+			body:        fmt.Sprintf(`{"action":"ban","user_ids":["%s"],"reason":"spam","duration":24}`, userID),
+			mockResults: []data.BulkUserResult{{UserID: userID, Username: "alice"}},
+			wantCode:    http.StatusOK,
+			wantBody:    `"failed":0`,
+		},
+		"unknown action": {
+			user:     admin,
+			body:     fmt.Sprintf(`{"action":"delete","user_ids":["%s"]}`, userID),
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		"no users": {
+			user:     admin,
+			body:     `{"action":"activate","user_ids":[]}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		"ban without a reason": {
+			user:     admin,
+			body:     fmt.Sprintf(`{"action":"ban","user_ids":["%s"],"duration":24}`, userID),
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		"unknown role": {
+			user:     admin,
+			body:     fmt.Sprintf(`{"action":"role","user_ids":["%s"],"role":"owner"}`, userID),
+			wantCode: http.StatusBadRequest,
+		},
+		"role without a role": {
+			user:     admin,
+			body:     fmt.Sprintf(`{"action":"role","user_ids":["%s"]}`, userID),
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		"granting a role as high as your own": {
+			user:     moderator,
+			body:     fmt.Sprintf(`{"action":"role","user_ids":["%s"],"role":"moderator"}`, userID),
+			wantCode: http.StatusForbidden,
+		},
+		"invalid body": {
+			user:     admin,
+			body:     `{"action":`,
+			wantCode: http.StatusBadRequest,
+		},
+		"internal error": {
+			user:     admin,
+			body:     fmt.Sprintf(`{"action":"deactivate","user_ids":["%s"]}`, userID),
+			mockErr:  services.ErrInternal,
+			wantCode: http.StatusInternalServerError,
 		},
 	}
 
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			mockUserService := mocks.MockUserService{}
+			mockUserService.On("BulkUpdateUsers", *tt.user, mock.Anything).Return(tt.mockResults, tt.mockErr)
+			handler := NewUserHandler(&mockUserService, &mocks.MockAuthService{}, &mocks.MockTokenService{}, &mocks.MockBanService{}, &mocks.MockMailService{}, &mocks.MockPasswordService{}, auditRecorder())
+
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/users/bulk", strings.NewReader(tt.body))
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 			rec := httptest.NewRecorder()
 			c := e.NewContext(req, rec)
+			c.Set("user", tt.user)
 
-			c.SetPath("/api/:userID")
-			c.SetParamNames("userID")
-			c.SetParamValues(tt.userID)
-
-			err := handler.Unban(c)
+			err := handler.Bulk(c)
 
-			if tt.wantError {
-				assert.Error(t, err)
-				if he, ok := err.(*echo.HTTPError); ok {
+			if tt.wantCode != http.StatusOK {
+				he, ok := err.(*echo.HTTPError)
+				if assert.True(t, ok) {
 					assert.Equal(t, tt.wantCode, he.Code)
 				}
-			} else {
-				assert.NoError(t, err)
-				assert.Equal(t, tt.wantCode, rec.Code)
+				return
 			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.wantBody)
 		})
 	}
-
-	mockBanService.AssertExpectations(t)
-
 }
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/audit"
+	"NodeTurtleAPI/internal/services/verification"
+
+	"github.com/labstack/echo/v4"
+)
+
+// VerificationHandler handles HTTP requests for the verified creator badge: users requesting it and admins reviewing the requests.
+type VerificationHandler struct {
+	verificationService verification.IVerificationService
+	auditService        audit.IAuditService
+}
+
+// NewVerificationHandler creates a new VerificationHandler with the provided services.
+func NewVerificationHandler(verificationService verification.IVerificationService, auditService audit.IAuditService) VerificationHandler {
+	return VerificationHandler{
+		verificationService: verificationService,
+		auditService:        auditService,
+	}
+}
+
+// Submit handles the request of the current user to get verified.
+func (h *VerificationHandler) Submit(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	if !contextUser.IsActivated {
+		return echo.NewHTTPError(http.StatusForbidden, "Account is not activated")
+	}
+
+	var payload data.VerificationSubmission
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	request, err := h.verificationService.SubmitRequest(c.Request().Context(), contextUser.ID, payload.Evidence)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAlreadyVerified):
+			return echo.NewHTTPError(http.StatusConflict, "Account is already verified")
+		case errors.Is(err, services.ErrVerificationPending):
+			return echo.NewHTTPError(http.StatusConflict, "A verification request is already pending")
+		}
+		logging.Error(c.Request().Context(), "Internal verification request error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to submit verification request")
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"request": request,
+	})
+}
+
+// GetCurrent handles the request to retrieve the status of the current user's latest verification request.
+func (h *VerificationHandler) GetCurrent(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	request, err := h.verificationService.GetLatestRequest(c.Request().Context(), contextUser.ID)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "No verification request found")
+		}
+		logging.Error(c.Request().Context(), "Internal verification request retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve verification request")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"request": request,
+	})
+}
+
+// ListPending handles the request to list the verification requests waiting for review.
+func (h *VerificationHandler) ListPending(c echo.Context) error {
+	requests, err := h.verificationService.ListPendingRequests(c.Request().Context())
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal verification request listing error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve verification requests")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"requests": requests,
+	})
+}
+
+// Review handles the request to approve or reject a pending verification request.
+// The decision is recorded in the audit log before it's saved.
+func (h *VerificationHandler) Review(c echo.Context) error {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	requestID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid verification request ID")
+	}
+
+	var payload data.VerificationReview
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	request, err := h.verificationService.GetRequest(c.Request().Context(), requestID)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Verification request not found")
+		}
+		logging.Error(c.Request().Context(), "Internal verification request retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to review verification request")
+	}
+
+	if request.Status != data.VerificationPending {
+		return echo.NewHTTPError(http.StatusConflict, "Verification request was already reviewed")
+	}
+
+	err = h.auditService.Record(data.AuditEntry{
+		ActorID:    &contextUser.ID,
+		Action:     data.AuditVerificationReview,
+		TargetType: "user",
+		TargetID:   request.UserID.String(),
+		Details: map[string]interface{}{
+			"request_id": request.ID,
+			"status":     payload.Status,
+			"note":       payload.Note,
+		},
+		IP: c.RealIP(),
+	})
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal audit log error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to record verification review")
+	}
+
+	request, err = h.verificationService.ReviewRequest(c.Request().Context(), requestID, payload, contextUser.ID)
+	if err != nil {
+		// reviewed by another admin in the meantime
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusConflict, "Verification request was already reviewed")
+		}
+		logging.Error(c.Request().Context(), "Internal verification review error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to review verification request")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"request": request,
+	})
+}
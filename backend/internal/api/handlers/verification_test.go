@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSubmitVerification(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	user := &data.User{ID: uuid.New(), Username: "creator", IsActivated: true}
+	pendingUser := &data.User{ID: uuid.New(), Username: "pending", IsActivated: true}
+	inactiveUser := &data.User{ID: uuid.New(), Username: "inactive"}
+	evidence := "https://youtube.com/@creator, the channel links back to this account"
+
+	mockVerificationService := mocks.MockVerificationService{}
+	mockVerificationService.On("SubmitRequest", user.ID, evidence).
+		Return(&data.VerificationRequest{ID: 1, UserID: user.ID, Evidence: evidence, Status: data.VerificationPending}, nil)
+	mockVerificationService.On("SubmitRequest", pendingUser.ID, evidence).Return(nil, services.ErrVerificationPending)
+
+	handler := NewVerificationHandler(&mockVerificationService, &mocks.MockAuditService{})
+
+	tests := map[string]struct {
+		contextUser *data.User
+		reqBody     string
+		wantCode    int
+		wantError   bool
+	}{
+		"Request submitted": {
+			contextUser: user,
+			reqBody:     `{"evidence":"` + evidence + `"}`,
+			wantCode:    http.StatusCreated,
+		},
+		"Evidence too short": {
+			contextUser: user,
+			reqBody:     `{"evidence":"trust me"}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantError:   true,
+		},
+		"Request already pending": {
+			contextUser: pendingUser,
+			reqBody:     `{"evidence":"` + evidence + `"}`,
+			wantCode:    http.StatusConflict,
+			wantError:   true,
+		},
+		"User not activated": {
+			contextUser: inactiveUser,
+			reqBody:     `{"evidence":"` + evidence + `"}`,
+			wantCode:    http.StatusForbidden,
+			wantError:   true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", tt.contextUser)
+
+			err := handler.Submit(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"status":"pending"`)
+			}
+		})
+	}
+}
+
+func TestReviewVerification(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	admin := &data.User{ID: uuid.New(), Username: "admin"}
+	userID := uuid.New()
+	approve := data.VerificationReview{Status: data.VerificationApproved, Note: "Channel confirmed"}
+
+	mockVerificationService := mocks.MockVerificationService{}
+	mockAuditService := mocks.MockAuditService{}
+
+	mockVerificationService.On("GetRequest", int64(1)).Return(&data.VerificationRequest{ID: 1, UserID: userID, Status: data.VerificationPending}, nil)
+	mockVerificationService.On("GetRequest", int64(2)).Return(&data.VerificationRequest{ID: 2, UserID: userID, Status: data.VerificationRejected}, nil)
+	mockVerificationService.On("GetRequest", int64(3)).Return(nil, services.ErrRecordNotFound)
+	mockVerificationService.On("GetRequest", int64(4)).Return(&data.VerificationRequest{ID: 4, UserID: userID, Status: data.VerificationPending}, nil)
+	mockVerificationService.On("ReviewRequest", int64(1), approve, admin.ID).
+		Return(&data.VerificationRequest{ID: 1, UserID: userID, Status: data.VerificationApproved, ReviewedBy: &admin.ID}, nil)
+
+	mockAuditService.On("Record", mock.MatchedBy(func(entry data.AuditEntry) bool {
+		return entry.Details["request_id"] == int64(4)
+	})).Return(errors.New("db down"))
+	mockAuditService.On("Record", mock.MatchedBy(func(entry data.AuditEntry) bool {
+		return *entry.ActorID == admin.ID && entry.Action == data.AuditVerificationReview && entry.TargetID == userID.String()
+	})).Return(nil)
+
+	handler := NewVerificationHandler(&mockVerificationService, &mockAuditService)
+
+	tests := map[string]struct {
+		requestID string
+		reqBody   string
+		wantCode  int
+		wantError bool
+	}{
+		"Request approved": {
+			requestID: "1",
+			reqBody:   `{"status":"approved","note":"Channel confirmed"}`,
+			wantCode:  http.StatusOK,
+		},
+		"Invalid status": {
+			requestID: "1",
+			reqBody:   `{"status":"pending"}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Invalid request ID": {
+			requestID: "abc",
+			reqBody:   `{"status":"approved"}`,
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Already reviewed": {
+			requestID: "2",
+			reqBody:   `{"status":"approved"}`,
+			wantCode:  http.StatusConflict,
+			wantError: true,
+		},
+		"Unknown request": {
+			requestID: "3",
+			reqBody:   `{"status":"approved"}`,
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+		"Audit log unavailable": {
+			requestID: "4",
+			reqBody:   `{"status":"approved"}`,
+			wantCode:  http.StatusInternalServerError,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(tt.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.requestID)
+			c.Set("user", admin)
+
+			err := handler.Review(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"status":"approved"`)
+			}
+		})
+	}
+
+	// nothing is saved when the review can't be audited
+	mockVerificationService.AssertNotCalled(t, "ReviewRequest", int64(4), mock.Anything, mock.Anything)
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/projects"
+	"NodeTurtleAPI/internal/services/webhooks"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// deliveriesLimit is the number of recent deliveries shown to the project owner.
+const deliveriesLimit = 50
+
+// WebhookHandler handles HTTP requests related to project milestone webhooks.
+type WebhookHandler struct {
+	webhookService webhooks.IWebhookService
+	projectService projects.IProjectService
+}
+
+// NewWebhookHandler creates a new WebhookHandler with the provided services.
+func NewWebhookHandler(webhookService webhooks.IWebhookService, projectService projects.IProjectService) WebhookHandler {
+	return WebhookHandler{
+		webhookService: webhookService,
+		projectService: projectService,
+	}
+}
+
+// ownedProject parses the project ID from the path and checks the current user owns the project.
+func (h *WebhookHandler) ownedProject(c echo.Context) (uuid.UUID, error) {
+	contextUser, ok := c.Get("user").(*data.User)
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	isOwner, err := h.projectService.IsOwner(c.Request().Context(), projectID, contextUser.ID)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal project ownership check error", err)
+		return uuid.Nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to check project ownership")
+	}
+	if !isOwner {
+		return uuid.Nil, echo.NewHTTPError(http.StatusForbidden, "You do not have permission to manage webhooks of this project")
+	}
+
+	return projectID, nil
+}
+
+// Get handles the request to retrieve the webhook of a project.
+func (h *WebhookHandler) Get(c echo.Context) error {
+	projectID, err := h.ownedProject(c)
+	if err != nil {
+		return err
+	}
+
+	webhook, err := h.webhookService.GetProjectWebhook(projectID)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Webhook not found")
+		}
+		logging.Error(c.Request().Context(), "Internal webhook retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve webhook")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"webhook": webhook,
+	})
+}
+
+// Set handles the request to configure the webhook notified when the project reaches like thresholds or gets featured.
+// Requests are signed with the returned secret in the X-NodeTurtle-Signature header.
+func (h *WebhookHandler) Set(c echo.Context) error {
+	projectID, err := h.ownedProject(c)
+	if err != nil {
+		return err
+	}
+
+	var payload struct {
+		URL            string `json:"url" validate:"required,url,startswith=https://,max=2000"`
+		LikeThresholds []int  `json:"like_thresholds" validate:"max=10,dive,min=1"`
+		NotifyFeatured *bool  `json:"notify_featured"`
+	}
+
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	webhook := data.ProjectWebhook{
+		ProjectID:      projectID,
+		URL:            payload.URL,
+		LikeThresholds: uniqueSorted(payload.LikeThresholds),
+		NotifyFeatured: true,
+	}
+	if payload.LikeThresholds == nil {
+		webhook.LikeThresholds = []int{100}
+	}
+	if payload.NotifyFeatured != nil {
+		webhook.NotifyFeatured = *payload.NotifyFeatured
+	}
+
+	saved, err := h.webhookService.SetProjectWebhook(webhook)
+	if err != nil {
+		if errors.Is(err, services.ErrProjectNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		logging.Error(c.Request().Context(), "Internal webhook update error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to save webhook")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"webhook": saved,
+	})
+}
+
+// Delete handles the request to remove the webhook of a project.
+func (h *WebhookHandler) Delete(c echo.Context) error {
+	projectID, err := h.ownedProject(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.webhookService.DeleteProjectWebhook(projectID); err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Webhook not found")
+		}
+		logging.Error(c.Request().Context(), "Internal webhook deletion error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete webhook")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetDeliveries handles the request to list the recent milestone notifications of a project and their outcome.
+func (h *WebhookHandler) GetDeliveries(c echo.Context) error {
+	projectID, err := h.ownedProject(c)
+	if err != nil {
+		return err
+	}
+
+	deliveries, err := h.webhookService.GetDeliveries(projectID, deliveriesLimit)
+	if err != nil {
+		logging.Error(c.Request().Context(), "Internal webhook deliveries retrieval error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve webhook deliveries")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"deliveries": deliveries,
+	})
+}
+
+func uniqueSorted(values []int) []int {
+	seen := make(map[int]bool, len(values))
+	unique := make([]int, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	sort.Ints(unique)
+	return unique
+}
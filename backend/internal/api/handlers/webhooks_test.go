@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetProjectWebhook(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+
+	mockWebhookService := mocks.MockWebhookService{}
+	mockProjectService := mocks.MockProjectService{}
+
+	owner := &data.User{ID: uuid.New(), Username: "owner", IsActivated: true}
+	other := &data.User{ID: uuid.New(), Username: "other", IsActivated: true}
+	projectID := uuid.New()
+
+	mockProjectService.On("IsOwner", projectID, owner.ID).Return(true, nil)
+	mockProjectService.On("IsOwner", projectID, other.ID).Return(false, nil)
+
+	mockWebhookService.On("SetProjectWebhook", data.ProjectWebhook{
+		ProjectID:      projectID,
+		URL:            "https://hooks.example.com/turtle",
+		LikeThresholds: []int{10, 100},
+		NotifyFeatured: false,
+	}).Return(&data.ProjectWebhook{ProjectID: projectID, URL: "https://hooks.example.com/turtle", Secret: "secret", LikeThresholds: []int{10, 100}}, nil)
+	mockWebhookService.On("SetProjectWebhook", data.ProjectWebhook{
+		ProjectID:      projectID,
+		URL:            "https://hooks.example.com/defaults",
+		LikeThresholds: []int{100},
+		NotifyFeatured: true,
+	}).Return(&data.ProjectWebhook{ProjectID: projectID, URL: "https://hooks.example.com/defaults", Secret: "secret", LikeThresholds: []int{100}, NotifyFeatured: true}, nil)
+
+	handler := NewWebhookHandler(&mockWebhookService, &mockProjectService)
+
+	tests := map[string]struct {
+		user      *data.User
+		projectID string
+		body      string
+		wantCode  int
+		wantError bool
+	}{
+		"Unauthenticated": {
+			projectID: projectID.String(),
+			body:      `{"url":"https://hooks.example.com/turtle"}`,
+			wantCode:  http.StatusUnauthorized,
+			wantError: true,
+		},
+		"Invalid project ID": {
+			user:      owner,
+			projectID: "invalid",
+			body:      `{"url":"https://hooks.example.com/turtle"}`,
+			wantCode:  http.StatusBadRequest,
+			wantError: true,
+		},
+		"Not the owner": {
+			user:      other,
+			projectID: projectID.String(),
+			body:      `{"url":"https://hooks.example.com/turtle"}`,
+			wantCode:  http.StatusForbidden,
+			wantError: true,
+		},
+		"Plain HTTP URL": {
+			user:      owner,
+			projectID: projectID.String(),
+			body:      `{"url":"http://hooks.example.com/turtle"}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Invalid threshold": {
+			user:      owner,
+			projectID: projectID.String(),
+			body:      `{"url":"https://hooks.example.com/turtle","like_thresholds":[0]}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: true,
+		},
+		"Custom thresholds": {
+			user:      owner,
+			projectID: projectID.String(),
+			body:      `{"url":"https://hooks.example.com/turtle","like_thresholds":[100,10,100],"notify_featured":false}`,
+			wantCode:  http.StatusOK,
+		},
+		"Defaults": {
+			user:      owner,
+			projectID: projectID.String(),
+			body:      `{"url":"https://hooks.example.com/defaults"}`,
+			wantCode:  http.StatusOK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.projectID)
+			if tt.user != nil {
+				c.Set("user", tt.user)
+			}
+
+			err := handler.Set(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"secret":"secret"`)
+			}
+		})
+	}
+
+	mockWebhookService.AssertExpectations(t)
+}
+
+func TestDeleteProjectWebhook(t *testing.T) {
+	e := echo.New()
+
+	mockWebhookService := mocks.MockWebhookService{}
+	mockProjectService := mocks.MockProjectService{}
+
+	owner := &data.User{ID: uuid.New(), Username: "owner", IsActivated: true}
+	projectID := uuid.New()
+	withoutWebhook := uuid.New()
+
+	mockProjectService.On("IsOwner", projectID, owner.ID).Return(true, nil)
+	mockProjectService.On("IsOwner", withoutWebhook, owner.ID).Return(true, nil)
+	mockWebhookService.On("DeleteProjectWebhook", projectID).Return(nil)
+	mockWebhookService.On("DeleteProjectWebhook", withoutWebhook).Return(services.ErrRecordNotFound)
+
+	handler := NewWebhookHandler(&mockWebhookService, &mockProjectService)
+
+	tests := map[string]struct {
+		projectID uuid.UUID
+		wantCode  int
+		wantError bool
+	}{
+		"Deleted": {
+			projectID: projectID,
+			wantCode:  http.StatusNoContent,
+		},
+		"No webhook": {
+			projectID: withoutWebhook,
+			wantCode:  http.StatusNotFound,
+			wantError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.projectID.String())
+			c.Set("user", owner)
+
+			err := handler.Delete(c)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				if he, ok := err.(*echo.HTTPError); ok {
+					assert.Equal(t, tt.wantCode, he.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
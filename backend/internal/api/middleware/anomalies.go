@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+
+	"NodeTurtleAPI/internal/services/anomalies"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TrackAnomalies counts the traffic the anomaly analyzer looks at: the server errors of every route, and the likes
+// made through likePath by the network (ASN) the proxy or CDN puts in asnHeader. Likes aren't counted by network
+// without asnHeader.
+func TrackAnomalies(counters *anomalies.Counters, asnHeader, likePath string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			err := next(c)
+
+			if serverError(c, err) {
+				counters.RecordServerError(c.Path())
+				return err
+			}
+
+			if err == nil && asnHeader != "" && c.Request().Method == http.MethodPost && c.Path() == likePath {
+				if asn := c.Request().Header.Get(asnHeader); asn != "" {
+					counters.RecordLike(asn)
+				}
+			}
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/clock"
+	"NodeTurtleAPI/internal/services/anomalies"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackAnomalies(t *testing.T) {
+	e := echo.New()
+	counters := anomalies.NewCounters(5*time.Minute, clock.NewFake(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)))
+	track := TrackAnomalies(counters, "CF-ASN", "/api/projects/:id/likes")
+
+	serve := func(method, path, asn string, handler echo.HandlerFunc) {
+		req := httptest.NewRequest(method, "/", nil)
+		if asn != "" {
+			req.Header.Set("CF-ASN", asn)
+		}
+		c := e.NewContext(req, httptest.NewRecorder())
+		c.SetPath(path)
+		track(handler)(c)
+	}
+	failing := func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "Database unreachable")
+	}
+	conflict := func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusConflict, "Project already liked")
+	}
+
+	serve(http.MethodPost, "/api/projects/:id/likes", "AS64500", okHandler)
+	serve(http.MethodPost, "/api/projects/:id/likes", "AS64500", okHandler)
+	// rejected likes, unlikes and likes of clients without a network aren't counted
+	serve(http.MethodPost, "/api/projects/:id/likes", "AS64500", conflict)
+	serve(http.MethodDelete, "/api/projects/:id/likes", "AS64500", okHandler)
+	serve(http.MethodPost, "/api/projects/:id/likes", "", okHandler)
+
+	serve(http.MethodGet, "/api/projects/:id", "", failing)
+	serve(http.MethodGet, "/api/projects/:id", "", conflict)
+
+	likes, failures := counters.Counts(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	assert.Equal(t, map[string]int{"AS64500": 2}, likes)
+	assert.Equal(t, map[string]int{"/api/projects/:id": 1}, failures)
+}
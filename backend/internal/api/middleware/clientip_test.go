@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPExtractor(t *testing.T) {
+	tests := map[string]struct {
+		trustedProxies []string
+		remoteAddr     string
+		forwardedFor   string
+		wantIP         string
+	}{
+		"Forwarded header ignored without proxies": {
+			remoteAddr:   "203.0.113.7:4242",
+			forwardedFor: "198.51.100.1",
+			wantIP:       "203.0.113.7",
+		},
+		"Forwarded header ignored from an untrusted address": {
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "203.0.113.7:4242",
+			forwardedFor:   "198.51.100.1",
+			wantIP:         "203.0.113.7",
+		},
+		"Private address not trusted by default": {
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "192.168.1.20:4242",
+			forwardedFor:   "198.51.100.1",
+			wantIP:         "192.168.1.20",
+		},
+		"Client behind a trusted proxy": {
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.1.2.3:4242",
+			forwardedFor:   "198.51.100.1",
+			wantIP:         "198.51.100.1",
+		},
+		"Address prepended by the client is skipped": {
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.1.2.3:4242",
+			forwardedFor:   "192.0.2.99, 198.51.100.1",
+			wantIP:         "198.51.100.1",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			extractor, err := IPExtractor(tt.trustedProxies)
+			assert.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set(echo.HeaderXForwardedFor, tt.forwardedFor)
+
+			assert.Equal(t, tt.wantIP, extractor(req))
+		})
+	}
+
+	_, err := IPExtractor([]string{"10.0.0.1"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,286 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/time/rate"
+)
+
+const (
+	behaviorWindow   = time.Minute
+	behaviorFlagTime = 15 * time.Minute
+	maxCacheEntries  = 1000
+)
+
+// crawlerPattern matches the user agents of search engines, scrapers and HTTP libraries.
+var crawlerPattern = regexp.MustCompile(`(?i)(googlebot|bingbot|yandex|baiduspider|duckduckbot|applebot|ahrefs|semrush|mj12bot|petalbot|ccbot|bytespider|facebookexternalhit|bot\b|crawler|spider|scrapy|python-requests|python-urllib|aiohttp|go-http-client|curl|wget|httpclient|okhttp|headless)`)
+
+// CrawlerGuard identifies crawler traffic and keeps it away from the expensive queries meant for users.
+// Crawlers are detected by user agent or by behavior, get a smaller request budget
+// and are served cached responses on public endpoints.
+type CrawlerGuard struct {
+	cfg     config.CrawlerConfig
+	bots    *Limiter
+	users   *Limiter
+	mu      sync.Mutex
+	clients map[string]*clientActivity
+	cache   map[string]cachedResponse
+	metrics data.BotMetrics
+	swept   time.Time
+}
+
+// clientActivity tracks the unauthenticated requests of a client within the current window.
+type clientActivity struct {
+	windowStart  time.Time
+	requests     int
+	flaggedUntil time.Time
+}
+
+type cachedResponse struct {
+	body        []byte
+	contentType string
+	expiresAt   time.Time
+}
+
+// NewCrawlerGuard creates a new CrawlerGuard with the provided budgets.
+func NewCrawlerGuard(cfg config.CrawlerConfig) *CrawlerGuard {
+	return &CrawlerGuard{
+		cfg:     cfg,
+		bots:    newLimiterStore(cfg.BotRequestsPerMinute),
+		users:   newLimiterStore(cfg.UserRequestsPerMinute),
+		clients: map[string]*clientActivity{},
+		cache:   map[string]cachedResponse{},
+		metrics: data.BotMetrics{Crawlers: map[string]int64{}},
+		swept:   time.Now(),
+	}
+}
+
+// newLimiterStore creates a per-client limiter allowing bursts of up to 10 seconds worth of the budget.
+func newLimiterStore(perMinute int) *Limiter {
+	if perMinute <= 0 {
+		return nil
+	}
+
+	return NewLimiter(rate.Limit(float64(perMinute)/60), max(1, perMinute/6), 5*time.Minute)
+}
+
+// Middleware classifies every API request and applies the request budget of its class, except those of probes.
+// The detected crawler name is stored in the context under "crawler". Clients are told apart by c.RealIP,
+// which only reads forwarding headers set by the trusted proxies of the server's IPExtractor.
+func (g *CrawlerGuard) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !strings.HasPrefix(c.Request().URL.Path, "/api") || IsProbe(c) {
+			return next(c)
+		}
+
+		ip := c.RealIP()
+		crawler := g.classify(c.Request(), ip)
+
+		store := g.users
+		if crawler != "" {
+			c.Set("crawler", crawler)
+			store = g.bots
+		}
+
+		if store != nil {
+			if allowed, retryAfter := store.Allow(ip); !allowed {
+				g.mu.Lock()
+				if crawler != "" {
+					g.metrics.BotThrottled++
+				} else {
+					g.metrics.UserThrottled++
+				}
+				g.mu.Unlock()
+				return TooManyRequests(c, CodeRateLimited, "Too many requests", retryAfter)
+			}
+		}
+
+		return next(c)
+	}
+}
+
+// Cache serves responses of public GET endpoints to crawlers from an in-memory cache.
+// Requests carrying credentials are never served from or stored in the cache.
+func (g *CrawlerGuard) Cache(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		if g.cfg.CacheTTL <= 0 || req.Method != http.MethodGet || c.Get("crawler") == nil || hasCredentials(req) {
+			return next(c)
+		}
+
+		key := req.URL.RequestURI()
+		now := time.Now()
+
+		g.mu.Lock()
+		cached, ok := g.cache[key]
+		if ok && now.Before(cached.expiresAt) {
+			g.metrics.CacheHits++
+			g.mu.Unlock()
+
+			c.Response().Header().Set("X-Cache", "HIT")
+			return c.Blob(http.StatusOK, cached.contentType, cached.body)
+		}
+		g.metrics.CacheMisses++
+		g.mu.Unlock()
+
+		recorder := &bodyRecorder{ResponseWriter: c.Response().Writer}
+		c.Response().Writer = recorder
+		c.Response().Header().Set("X-Cache", "MISS")
+
+		if err := next(c); err != nil {
+			return err
+		}
+
+		if c.Response().Status == http.StatusOK {
+			g.mu.Lock()
+			if len(g.cache) >= maxCacheEntries {
+				g.evictExpired(now)
+			}
+			if len(g.cache) < maxCacheEntries {
+				g.cache[key] = cachedResponse{
+					body:        recorder.body.Bytes(),
+					contentType: c.Response().Header().Get(echo.HeaderContentType),
+					expiresAt:   now.Add(time.Duration(g.cfg.CacheTTL) * time.Second),
+				}
+			}
+			g.mu.Unlock()
+		}
+
+		return nil
+	}
+}
+
+// Metrics returns a snapshot of the traffic counters.
+func (g *CrawlerGuard) Metrics() data.BotMetrics {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	snapshot := g.metrics
+	snapshot.Crawlers = make(map[string]int64, len(g.metrics.Crawlers))
+	for name, count := range g.metrics.Crawlers {
+		snapshot.Crawlers[name] = count
+	}
+	for _, activity := range g.clients {
+		if now.Before(activity.flaggedUntil) {
+			snapshot.FlaggedClients++
+		}
+	}
+
+	return snapshot
+}
+
+// RobotsTxt serves crawl directives matching the crawler request budget and points bulk consumers to the data dumps.
+func (g *CrawlerGuard) RobotsTxt(c echo.Context) error {
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+	if g.cfg.BotRequestsPerMinute > 0 {
+		fmt.Fprintf(&b, "Crawl-delay: %d\n", max(1, 60/g.cfg.BotRequestsPerMinute))
+	}
+	b.WriteString("Allow: /api/projects/\n")
+	b.WriteString("Allow: /api/dumps\n")
+	b.WriteString("Disallow: /api/\n")
+	b.WriteString("# Bulk data is available as dumps at /api/dumps, please use them instead of crawling.\n")
+
+	return c.String(http.StatusOK, b.String())
+}
+
+// classify returns the name of the crawler making the request, or an empty string for regular clients.
+// Clients sending more unauthenticated requests than the behavior threshold within a minute
+// are treated as crawlers for a while, regardless of their user agent.
+func (g *CrawlerGuard) classify(req *http.Request, ip string) string {
+	now := time.Now()
+	ua := req.UserAgent()
+
+	crawler := ""
+	switch {
+	case ua == "":
+		crawler = "unknown"
+	case crawlerPattern.MatchString(ua):
+		crawler = strings.ToLower(crawlerPattern.FindString(ua))
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if now.Sub(g.swept) > behaviorWindow {
+		g.sweep(now)
+	}
+
+	if crawler == "" && !hasCredentials(req) && g.cfg.BehaviorThreshold > 0 {
+		activity, ok := g.clients[ip]
+		if !ok {
+			activity = &clientActivity{windowStart: now}
+			g.clients[ip] = activity
+		}
+		if now.Sub(activity.windowStart) > behaviorWindow {
+			activity.windowStart = now
+			activity.requests = 0
+		}
+
+		activity.requests++
+		if activity.requests > g.cfg.BehaviorThreshold {
+			activity.flaggedUntil = now.Add(behaviorFlagTime)
+		}
+		if now.Before(activity.flaggedUntil) {
+			crawler = "behavior"
+		}
+	}
+
+	if crawler != "" {
+		g.metrics.BotRequests++
+		g.metrics.Crawlers[crawler]++
+	} else {
+		g.metrics.UserRequests++
+	}
+
+	return crawler
+}
+
+// sweep drops idle client activity and expired cache entries. Callers must hold the lock.
+func (g *CrawlerGuard) sweep(now time.Time) {
+	for ip, activity := range g.clients {
+		if now.Sub(activity.windowStart) > behaviorWindow && now.After(activity.flaggedUntil) {
+			delete(g.clients, ip)
+		}
+	}
+	g.evictExpired(now)
+	g.swept = now
+}
+
+func (g *CrawlerGuard) evictExpired(now time.Time) {
+	for key, cached := range g.cache {
+		if now.After(cached.expiresAt) {
+			delete(g.cache, key)
+		}
+	}
+}
+
+func hasCredentials(req *http.Request) bool {
+	if req.Header.Get("Authorization") != "" {
+		return true
+	}
+	cookie, err := req.Cookie("access_token")
+	return err == nil && cookie.Value != ""
+}
+
+// bodyRecorder copies the response body while it is written to the client.
+type bodyRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *bodyRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,214 @@
+package middleware
+
+import (
+	"NodeTurtleAPI/internal/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveCrawlerRequest runs a request through the crawler middleware and returns the crawler name set in the context
+func serveCrawlerRequest(e *echo.Echo, guard *CrawlerGuard, path, userAgent string) (string, error) {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("User-Agent", userAgent)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	var crawler string
+	err := guard.Middleware(func(c echo.Context) error {
+		crawler, _ = c.Get("crawler").(string)
+		return c.NoContent(http.StatusOK)
+	})(c)
+
+	return crawler, err
+}
+
+func TestCrawlerGuard_Classify(t *testing.T) {
+	e := echo.New()
+	guard := NewCrawlerGuard(config.CrawlerConfig{BehaviorThreshold: 100})
+
+	tests := map[string]struct {
+		userAgent   string
+		wantCrawler string
+	}{
+		"Browser": {
+			userAgent:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36",
+			wantCrawler: "",
+		},
+		"Search engine": {
+			userAgent:   "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			wantCrawler: "googlebot",
+		},
+		"HTTP library": {
+			userAgent:   "python-requests/2.31.0",
+			wantCrawler: "python-requests",
+		},
+		"Missing user agent": {
+			userAgent:   "",
+			wantCrawler: "unknown",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			crawler, err := serveCrawlerRequest(e, guard, "/api/projects/public", tt.userAgent)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCrawler, crawler)
+		})
+	}
+
+	metrics := guard.Metrics()
+	assert.Equal(t, int64(3), metrics.BotRequests)
+	assert.Equal(t, int64(1), metrics.UserRequests)
+	assert.Equal(t, int64(1), metrics.Crawlers["googlebot"])
+}
+
+func TestCrawlerGuard_IgnoresNonAPIPaths(t *testing.T) {
+	e := echo.New()
+	guard := NewCrawlerGuard(config.CrawlerConfig{})
+
+	crawler, err := serveCrawlerRequest(e, guard, "/robots.txt", "curl/8.0")
+	assert.NoError(t, err)
+	assert.Empty(t, crawler)
+	assert.Equal(t, int64(0), guard.Metrics().BotRequests)
+}
+
+func TestCrawlerGuard_BotBudget(t *testing.T) {
+	e := echo.New()
+	// a budget of 6 per minute allows a burst of a single request
+	guard := NewCrawlerGuard(config.CrawlerConfig{BotRequestsPerMinute: 6, UserRequestsPerMinute: 600})
+
+	_, err := serveCrawlerRequest(e, guard, "/api/projects/public", "curl/8.0")
+	assert.NoError(t, err)
+
+	_, err = serveCrawlerRequest(e, guard, "/api/projects/public", "curl/8.0")
+	if assert.Error(t, err) {
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusTooManyRequests, he.Code)
+	}
+
+	// users have their own budget
+	_, err = serveCrawlerRequest(e, guard, "/api/projects/public", "Mozilla/5.0")
+	assert.NoError(t, err)
+
+	metrics := guard.Metrics()
+	assert.Equal(t, int64(1), metrics.BotThrottled)
+	assert.Equal(t, int64(0), metrics.UserThrottled)
+}
+
+func TestCrawlerGuard_IgnoresForwardedFor(t *testing.T) {
+	e := echo.New()
+	extractor, err := IPExtractor(nil)
+	assert.NoError(t, err)
+	e.IPExtractor = extractor
+	guard := NewCrawlerGuard(config.CrawlerConfig{BotRequestsPerMinute: 6})
+
+	serve := func(forwardedFor string) error {
+		req := httptest.NewRequest(http.MethodGet, "/api/projects/public", nil)
+		req.Header.Set("User-Agent", "curl/8.0")
+		req.Header.Set(echo.HeaderXForwardedFor, forwardedFor)
+		req.Header.Set(echo.HeaderXRealIP, forwardedFor)
+		c := e.NewContext(req, httptest.NewRecorder())
+
+		return guard.Middleware(func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		})(c)
+	}
+
+	assert.NoError(t, serve("198.51.100.1"))
+
+	// a bot can't get a fresh budget by making up the address it forwards for
+	err = serve("198.51.100.2")
+	if assert.Error(t, err) {
+		assert.Equal(t, http.StatusTooManyRequests, err.(*echo.HTTPError).Code)
+	}
+}
+
+func TestCrawlerGuard_BehaviorDetection(t *testing.T) {
+	e := echo.New()
+	guard := NewCrawlerGuard(config.CrawlerConfig{BehaviorThreshold: 3})
+
+	for i := 0; i < 3; i++ {
+		crawler, err := serveCrawlerRequest(e, guard, "/api/projects/public", "Mozilla/5.0")
+		assert.NoError(t, err)
+		assert.Empty(t, crawler)
+	}
+
+	crawler, err := serveCrawlerRequest(e, guard, "/api/projects/public", "Mozilla/5.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "behavior", crawler)
+	assert.Equal(t, 1, guard.Metrics().FlaggedClients)
+
+	// authenticated requests don't count towards the threshold
+	req := httptest.NewRequest(http.MethodGet, "/api/projects/public", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	req.Header.Set("Authorization", "Bearer token")
+	c := e.NewContext(req, httptest.NewRecorder())
+	err = guard.Middleware(func(c echo.Context) error {
+		assert.Nil(t, c.Get("crawler"))
+		return nil
+	})(c)
+	assert.NoError(t, err)
+}
+
+func TestCrawlerGuard_Cache(t *testing.T) {
+	e := echo.New()
+	guard := NewCrawlerGuard(config.CrawlerConfig{CacheTTL: 60})
+
+	calls := 0
+	handler := guard.Cache(func(c echo.Context) error {
+		calls++
+		return c.JSON(http.StatusOK, map[string]interface{}{"projects": []string{}})
+	})
+
+	serve := func(crawler string, authHeader string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/projects/public?page=1", nil)
+		if authHeader != "" {
+			req.Header.Set("Authorization", authHeader)
+		}
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		if crawler != "" {
+			c.Set("crawler", crawler)
+		}
+		assert.NoError(t, handler(c))
+		return rec
+	}
+
+	first := serve("googlebot", "")
+	assert.Equal(t, "MISS", first.Header().Get("X-Cache"))
+
+	second := serve("bingbot", "")
+	assert.Equal(t, "HIT", second.Header().Get("X-Cache"))
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, 1, calls)
+
+	// users and authenticated crawlers always reach the handler
+	user := serve("", "")
+	assert.Empty(t, user.Header().Get("X-Cache"))
+	authenticated := serve("googlebot", "Bearer token")
+	assert.Empty(t, authenticated.Header().Get("X-Cache"))
+	assert.Equal(t, 3, calls)
+
+	metrics := guard.Metrics()
+	assert.Equal(t, int64(1), metrics.CacheHits)
+	assert.Equal(t, int64(1), metrics.CacheMisses)
+}
+
+func TestCrawlerGuard_RobotsTxt(t *testing.T) {
+	e := echo.New()
+	guard := NewCrawlerGuard(config.CrawlerConfig{BotRequestsPerMinute: 30})
+
+	req := httptest.NewRequest(http.MethodGet, "/robots.txt", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	assert.NoError(t, guard.RobotsTxt(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Crawl-delay: 2")
+	assert.Contains(t, rec.Body.String(), "/api/dumps")
+}
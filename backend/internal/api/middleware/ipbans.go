@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CodeIPBanned is the code of the 403 responses to requests from a banned IP range.
+const CodeIPBanned = "IP_BANNED"
+
+// ipBanRefresh is how long the banned IP ranges are kept in memory before they are read again,
+// so bans expiring or changed on another instance take effect within it.
+const ipBanRefresh = time.Minute
+
+// IPBanGuard turns away every request from a banned IP range with 403. The ranges in force are kept in memory,
+// read again every ipBanRefresh or as soon as they are changed on this instance.
+type IPBanGuard struct {
+	bans     services.IBanService
+	mu       sync.Mutex
+	networks []*net.IPNet
+	loadedAt time.Time
+}
+
+// NewIPBanGuard creates a new IPBanGuard enforcing the IP bans of the ban service.
+func NewIPBanGuard(bans services.IBanService) *IPBanGuard {
+	return &IPBanGuard{
+		bans: bans,
+	}
+}
+
+// Middleware rejects the request if the client IP lies in a banned range. When the ranges can't be read,
+// the ones read last stay in force. The client IP is c.RealIP, so forwarding headers only count when they come
+// from the trusted proxies of the server's IPExtractor and a banned client can't claim another address.
+func (g *IPBanGuard) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ip := net.ParseIP(c.RealIP())
+		if ip == nil {
+			return next(c)
+		}
+
+		networks, err := g.current(c.Request().Context())
+		if err != nil {
+			logging.Error(c.Request().Context(), "Internal IP ban retrieval error", err)
+		}
+
+		for _, network := range networks {
+			if network.Contains(ip) {
+				return echo.NewHTTPError(http.StatusForbidden, map[string]interface{}{
+					"code":    CodeIPBanned,
+					"message": "Access from your network has been blocked",
+				})
+			}
+		}
+
+		return next(c)
+	}
+}
+
+// Invalidate makes the next request read the banned ranges again, for changes to take effect right away.
+func (g *IPBanGuard) Invalidate() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.loadedAt = time.Time{}
+}
+
+// current returns the banned ranges, reading them again once they are older than ipBanRefresh.
+func (g *IPBanGuard) current(ctx context.Context) ([]*net.IPNet, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if time.Since(g.loadedAt) < ipBanRefresh {
+		return g.networks, nil
+	}
+
+	bans, err := g.bans.ActiveIPBans(ctx)
+	if err != nil {
+		// retry on the next refresh instead of on every request while the database is down
+		g.loadedAt = time.Now()
+		return g.networks, err
+	}
+
+	networks := make([]*net.IPNet, 0, len(bans))
+	for _, ban := range bans {
+		if _, network, err := net.ParseCIDR(ban.Subnet); err == nil {
+			networks = append(networks, network)
+		}
+	}
+
+	g.networks = networks
+	g.loadedAt = time.Now()
+	return g.networks, nil
+}
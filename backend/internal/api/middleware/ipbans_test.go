@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveIPBanRequest(e *echo.Echo, guard *IPBanGuard, ip string) error {
+	req := httptest.NewRequest(http.MethodGet, "/api/projects", nil)
+	req.RemoteAddr = net.JoinHostPort(ip, "1234")
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	return guard.Middleware(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})(c)
+}
+
+func TestIPBanGuard(t *testing.T) {
+	e := echo.New()
+
+	tests := map[string]struct {
+		ip       string
+		wantCode int
+	}{
+		"IP in a banned range":     {ip: "203.0.113.42", wantCode: http.StatusForbidden},
+		"Banned single IP":         {ip: "2001:db8::1", wantCode: http.StatusForbidden},
+		"IP outside banned ranges": {ip: "198.51.100.1", wantCode: http.StatusOK},
+	}
+
+	mockBanService := new(mocks.MockBanService)
+	mockBanService.On("ActiveIPBans").Return([]data.IPBan{{Subnet: "203.0.113.0/24"}, {Subnet: "2001:db8::1/128"}}, nil).Once()
+	guard := NewIPBanGuard(mockBanService)
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := serveIPBanRequest(e, guard, tt.ip)
+
+			if tt.wantCode == http.StatusOK {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+			}
+		})
+	}
+
+	// the ranges are read once and kept until invalidated
+	mockBanService.AssertNumberOfCalls(t, "ActiveIPBans", 1)
+}
+
+func TestIPBanGuard_IgnoresForwardedFor(t *testing.T) {
+	e := echo.New()
+	extractor, err := IPExtractor(nil)
+	assert.NoError(t, err)
+	e.IPExtractor = extractor
+
+	mockBanService := new(mocks.MockBanService)
+	mockBanService.On("ActiveIPBans").Return([]data.IPBan{{Subnet: "203.0.113.0/24"}}, nil)
+	guard := NewIPBanGuard(mockBanService)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/projects", nil)
+	req.RemoteAddr = "203.0.113.42:1234"
+	req.Header.Set(echo.HeaderXForwardedFor, "198.51.100.1")
+	req.Header.Set(echo.HeaderXRealIP, "198.51.100.1")
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	err = guard.Middleware(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})(c)
+	if assert.Error(t, err) {
+		assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+	}
+}
+
+func TestIPBanGuard_Invalidate(t *testing.T) {
+	e := echo.New()
+
+	mockBanService := new(mocks.MockBanService)
+	mockBanService.On("ActiveIPBans").Return([]data.IPBan{{Subnet: "203.0.113.0/24"}}, nil).Once()
+	mockBanService.On("ActiveIPBans").Return([]data.IPBan{}, nil).Once()
+	guard := NewIPBanGuard(mockBanService)
+
+	assert.Error(t, serveIPBanRequest(e, guard, "203.0.113.42"))
+
+	guard.Invalidate()
+	assert.NoError(t, serveIPBanRequest(e, guard, "203.0.113.42"))
+
+	mockBanService.AssertExpectations(t)
+}
+
+func TestIPBanGuard_KeepsRangesOnError(t *testing.T) {
+	e := echo.New()
+
+	mockBanService := new(mocks.MockBanService)
+	mockBanService.On("ActiveIPBans").Return([]data.IPBan{{Subnet: "203.0.113.0/24"}}, nil).Once()
+	mockBanService.On("ActiveIPBans").Return(nil, errors.New("database error")).Once()
+	guard := NewIPBanGuard(mockBanService)
+
+	assert.Error(t, serveIPBanRequest(e, guard, "203.0.113.42"))
+
+	guard.Invalidate()
+	assert.Error(t, serveIPBanRequest(e, guard, "203.0.113.42"))
+
+	mockBanService.AssertExpectations(t)
+}
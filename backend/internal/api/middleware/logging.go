@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"log/slog"
+	"regexp"
+	"time"
+
+	"NodeTurtleAPI/internal/logging"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequestLogger gives every request a logger carrying its method and route, which handlers and the services they call
+// reach through the request context, and logs one line per request once it has been answered.
+// It has to run after the request ID middleware, so the lines of a request carry its ID.
+func RequestLogger(logger *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx := logging.NewContext(req.Context(), logger.With("method", req.Method, "route", c.Path()), time.Now())
+			c.SetRequest(req.WithContext(ctx))
+
+			err := next(c)
+			if err != nil {
+				// answers the request now, so the logged status is the one the client gets
+				c.Error(err)
+			}
+
+			ctx = c.Request().Context()
+			level := slog.LevelInfo
+			if c.Response().Status >= 500 {
+				level = slog.LevelError
+			}
+			attrs := []any{
+				"uri", req.RequestURI,
+				"status", c.Response().Status,
+				"ip", c.RealIP(),
+			}
+			if IsProbe(c) {
+				attrs = append(attrs, "probe", true)
+			}
+			if err != nil {
+				attrs = append(attrs, "error", err.Error())
+			}
+			logging.FromContext(ctx).Log(ctx, level, "request", attrs...)
+
+			return err
+		}
+	}
+}
+
+// logAttrs adds the attributes to the lines logged for the request from now on.
+func logAttrs(c echo.Context, args ...any) {
+	c.SetRequest(c.Request().WithContext(logging.With(c.Request().Context(), args...)))
+}
+
+// requestIDPattern matches the request IDs accepted from clients and proxies in X-Request-ID.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// SanitizeRequestID drops request IDs sent by clients or proxies that could bloat or forge log lines,
+// so a new one is generated for the request instead.
+func SanitizeRequestID(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if id := c.Request().Header.Get(echo.HeaderXRequestID); id != "" && !requestIDPattern.MatchString(id) {
+			c.Request().Header.Del(echo.HeaderXRequestID)
+		}
+		return next(c)
+	}
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/requestid"
+
+	"github.com/labstack/echo/v4"
+	echomw "github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestLogger(t *testing.T) {
+	tests := map[string]struct {
+		requestID string
+		keepsID   bool
+		handler   echo.HandlerFunc
+		status    int
+		level     string
+	}{
+		"Success": {
+			requestID: "lb-1234",
+			keepsID:   true,
+			handler:   okHandler,
+			status:    http.StatusOK,
+			level:     "INFO",
+		},
+		"Internal error": {
+			requestID: "lb-1234",
+			keepsID:   true,
+			handler: func(c echo.Context) error {
+				return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve project")
+			},
+			status: http.StatusInternalServerError,
+			level:  "ERROR",
+		},
+		"Forged request ID": {
+			requestID: "x\n{\"level\":\"ERROR\"}",
+			handler:   okHandler,
+			status:    http.StatusOK,
+			level:     "INFO",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			e := echo.New()
+			e.Use(SanitizeRequestID)
+			e.Use(echomw.RequestIDWithConfig(echomw.RequestIDConfig{
+				RequestIDHandler: func(c echo.Context, id string) {
+					c.SetRequest(c.Request().WithContext(requestid.NewContext(c.Request().Context(), id)))
+				},
+			}))
+			e.Use(RequestLogger(logging.New(&buf, false)))
+			e.GET("/api/projects/:id", func(c echo.Context) error {
+				logAttrs(c, "user_id", "user-1")
+				return tt.handler(c)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/projects/42", nil)
+			req.Header.Set(echo.HeaderXRequestID, tt.requestID)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			require.Len(t, lines, 1)
+
+			var line map[string]any
+			require.NoError(t, json.Unmarshal([]byte(lines[0]), &line))
+			assert.Equal(t, tt.level, line["level"])
+			assert.Equal(t, "request", line["msg"])
+			assert.Equal(t, "/api/projects/:id", line["route"])
+			assert.Equal(t, "/api/projects/42", line["uri"])
+			assert.Equal(t, float64(tt.status), line["status"])
+			assert.Equal(t, "user-1", line["user_id"])
+			assert.Contains(t, line, "latency_ms")
+
+			// the ID of the request is logged and returned to the client
+			assert.Equal(t, rec.Header().Get(echo.HeaderXRequestID), line["request_id"])
+			if tt.keepsID {
+				assert.Equal(t, tt.requestID, line["request_id"])
+			} else {
+				assert.NotEqual(t, tt.requestID, line["request_id"])
+			}
+		})
+	}
+}
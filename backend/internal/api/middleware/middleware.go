@@ -2,11 +2,16 @@ package middleware
 
 import (
 	"net/http"
+	"slices"
 	"strings"
 
+	"NodeTurtleAPI/internal/clock"
 	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
 	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/audit"
 	"NodeTurtleAPI/internal/services/auth"
+	"NodeTurtleAPI/internal/services/roles"
 	"NodeTurtleAPI/internal/services/users"
 
 	"github.com/google/uuid"
@@ -40,16 +45,27 @@ func JWT(authService auth.IAuthService, userService users.IUserService) echo.Mid
 			}
 
 			claims, err := authService.VerifyToken(tokenString)
-			if err != nil {
+			// scoped tokens only grant access to a single resource, they are not sessions
+			if err != nil || claims.Scope != "" {
 				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid or expired token")
 			}
 
-			user, err := userService.GetUserByID(uuid.MustParse(claims.Subject))
+			user, err := userService.GetUserByID(c.Request().Context(), uuid.MustParse(claims.Subject))
 			if err != nil {
 				return echo.NewHTTPError(http.StatusUnauthorized, "User not found")
 			}
 
+			if claims.Impersonator != "" {
+				impersonatorID, err := uuid.Parse(claims.Impersonator)
+				if err != nil {
+					return echo.NewHTTPError(http.StatusUnauthorized, "Invalid or expired token")
+				}
+				c.Set("impersonator", impersonatorID)
+				logAttrs(c, "impersonator_id", impersonatorID)
+			}
+
 			c.Set("user", user)
+			logAttrs(c, "user_id", user.ID)
 			return next(c)
 		}
 	}
@@ -71,19 +87,133 @@ func RequireRole(role string) echo.MiddlewareFunc {
 	}
 }
 
-func CheckBan(next echo.HandlerFunc) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		user, ok := c.Get("user").(*data.User)
-		if !ok || user == nil {
-			return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+// RequirePermission middleware allows only users whose role has been granted the permission.
+func RequirePermission(roleService roles.IRoleService, permission data.Permission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user, ok := c.Get("user").(*data.User)
+			if !ok || user == nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+			}
+
+			granted, err := roleService.HasPermission(user.Role.ID, permission)
+			if err != nil {
+				logging.Error(c.Request().Context(), "Internal permission check error", err)
+				return echo.NewHTTPError(http.StatusInternalServerError, "Failed to check permissions")
+			}
+			if !granted {
+				return echo.NewHTTPError(http.StatusForbidden, "Insufficient permissions")
+			}
+			return next(c)
+		}
+	}
+}
+
+// CheckBan turns away banned users whose ban hasn't expired yet.
+var CheckBan = CheckBanAt(clock.System)
+
+// CheckBanAt turns away banned users whose ban hasn't expired by the time of the clock.
+func CheckBanAt(clk clock.Clock) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user, ok := c.Get("user").(*data.User)
+			if !ok || user == nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+			}
+			if user.Ban.ActiveAt(clk.Now()) {
+				return echo.NewHTTPError(http.StatusForbidden, services.BanMessage(user.Ban.Reason, user.Ban.ExpiresAt))
+			}
+			return next(c)
+		}
+	}
+}
+
+// CheckPasswordReset blocks users that still have to replace a generated password
+// from every route except the given allowed paths.
+func CheckPasswordReset(allowedPaths ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user, ok := c.Get("user").(*data.User)
+			if !ok || user == nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+			}
+			if user.PasswordResetRequired && !slices.Contains(allowedPaths, c.Path()) {
+				return echo.NewHTTPError(http.StatusForbidden, "PASSWORD_RESET_REQUIRED")
+			}
+			return next(c)
+		}
+	}
+}
+
+// RestrictGuests keeps guest accounts to the given routes, written as "METHOD /path",
+// and refuses guests whose account has expired by the time of the clock.
+func RestrictGuests(clk clock.Clock, allowedRoutes ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user, ok := c.Get("user").(*data.User)
+			if !ok || user == nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+			}
+			if !user.IsGuest() {
+				return next(c)
+			}
+			if !user.GuestExpiresAt.After(clk.Now()) {
+				return echo.NewHTTPError(http.StatusUnauthorized, "GUEST_EXPIRED")
+			}
+			if !slices.Contains(allowedRoutes, c.Request().Method+" "+c.Path()) {
+				return echo.NewHTTPError(http.StatusForbidden, "GUEST_FORBIDDEN")
+			}
+			return next(c)
 		}
-		if user.Ban.IsValid() {
-			return echo.NewHTTPError(http.StatusForbidden, services.BanMessage(user.Ban.Reason, user.Ban.ExpiresAt))
+	}
+}
+
+// Impersonation records every request made with an impersonation token in the audit log,
+// and blocks the given routes, written as "METHOD /path", for impersonators.
+// Requests are recorded before they are handled, a request that can't be recorded is refused.
+func Impersonation(auditService audit.IAuditService, blockedRoutes ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			impersonatorID, ok := c.Get("impersonator").(uuid.UUID)
+			if !ok {
+				return next(c)
+			}
+
+			user, ok := c.Get("user").(*data.User)
+			if !ok || user == nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+			}
+
+			route := c.Request().Method + " " + c.Path()
+			blocked := slices.Contains(blockedRoutes, route)
+
+			err := auditService.Record(data.AuditEntry{
+				ActorID:    &impersonatorID,
+				Action:     data.AuditImpersonationRequest,
+				TargetType: "user",
+				TargetID:   user.ID.String(),
+				Details: map[string]interface{}{
+					"route":   route,
+					"uri":     c.Request().RequestURI,
+					"blocked": blocked,
+				},
+				IP: c.RealIP(),
+			})
+			if err != nil {
+				logging.Error(c.Request().Context(), "Internal audit log error", err)
+				return echo.NewHTTPError(http.StatusInternalServerError, "Failed to record impersonated request")
+			}
+
+			if blocked {
+				return echo.NewHTTPError(http.StatusForbidden, "IMPERSONATION_FORBIDDEN")
+			}
+			return next(c)
 		}
-		return next(c)
 	}
 }
 
+// OptionalJWT reads the session when the request has one, the same way JWT does, and lets the request through
+// without a user otherwise. Impersonation tokens set the impersonator, so the Impersonation middleware records them.
 func OptionalJWT(authService auth.IAuthService, userService users.IUserService) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -105,10 +235,21 @@ func OptionalJWT(authService auth.IAuthService, userService users.IUserService)
 
 			if tokenString != "" {
 				claims, err := authService.VerifyToken(tokenString)
-				if err == nil {
-					user, err := userService.GetUserByID(uuid.MustParse(claims.Subject))
+				if err == nil && claims.Scope == "" {
+					user, err := userService.GetUserByID(c.Request().Context(), uuid.MustParse(claims.Subject))
 					if err == nil {
+						if claims.Impersonator != "" {
+							impersonatorID, err := uuid.Parse(claims.Impersonator)
+							if err != nil {
+								// a token JWT refuses isn't a session here either
+								return next(c)
+							}
+							c.Set("impersonator", impersonatorID)
+							logAttrs(c, "impersonator_id", impersonatorID)
+						}
+
 						c.Set("user", user)
+						logAttrs(c, "user_id", user.ID)
 					}
 				}
 			}
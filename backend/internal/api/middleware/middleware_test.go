@@ -1,10 +1,12 @@
 package middleware
 
 import (
+	"NodeTurtleAPI/internal/clock"
 	"NodeTurtleAPI/internal/data"
 	"NodeTurtleAPI/internal/mocks"
 	"NodeTurtleAPI/internal/services"
 	"NodeTurtleAPI/internal/services/auth"
+	"NodeTurtleAPI/internal/utils"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -14,6 +16,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 // createMockServices is a helper function to create new mock service instances
@@ -95,6 +98,91 @@ func TestJWT_ValidToken(t *testing.T) {
 	testJWTSuccess(t, e, mockAuth, mockUser, "valid-token", "testuser")
 }
 
+func TestJWT_ImpersonationToken(t *testing.T) {
+	e := echo.New()
+	mockAuth, mockUser := createMockServices()
+
+	userID := uuid.New()
+	impersonatorID := uuid.New()
+	mockAuth.On("VerifyToken", "impersonation-token").Return(&auth.Claims{
+		Role:           "user",
+		Impersonator:   impersonatorID.String(),
+		StandardClaims: jwt.StandardClaims{Subject: userID.String()},
+	}, nil)
+	mockAuth.On("VerifyToken", "bad-impersonator").Return(&auth.Claims{
+		Role:           "user",
+		Impersonator:   "not-a-uuid",
+		StandardClaims: jwt.StandardClaims{Subject: userID.String()},
+	}, nil)
+	mockUser.On("GetUserByID", userID).Return(&data.User{ID: userID, Username: "student"}, nil)
+
+	c, _ := createTestContext(e, "Bearer impersonation-token")
+	h := JWT(mockAuth, mockUser)(func(c echo.Context) error {
+		assert.Equal(t, impersonatorID, c.Get("impersonator"))
+		return c.NoContent(http.StatusOK)
+	})
+	assert.Nil(t, h(c))
+
+	testJWTFailure(t, e, mockAuth, mockUser, "Bearer bad-impersonator", http.StatusUnauthorized, "Invalid or expired token")
+}
+
+func TestOptionalJWT_ImpersonationToken(t *testing.T) {
+	e := echo.New()
+	mockAuth, mockUser := createMockServices()
+
+	userID := uuid.New()
+	impersonatorID := uuid.New()
+	mockAuth.On("VerifyToken", "impersonation-token").Return(&auth.Claims{
+		Role:           "user",
+		Impersonator:   impersonatorID.String(),
+		StandardClaims: jwt.StandardClaims{Subject: userID.String()},
+	}, nil)
+	mockAuth.On("VerifyToken", "bad-impersonator").Return(&auth.Claims{
+		Role:           "user",
+		Impersonator:   "not-a-uuid",
+		StandardClaims: jwt.StandardClaims{Subject: userID.String()},
+	}, nil)
+	mockUser.On("GetUserByID", userID).Return(&data.User{ID: userID, Username: "student"}, nil)
+
+	c, _ := createTestContext(e, "Bearer impersonation-token")
+	h := OptionalJWT(mockAuth, mockUser)(func(c echo.Context) error {
+		assert.Equal(t, impersonatorID, c.Get("impersonator"))
+		assert.NotNil(t, c.Get("user"))
+		return c.NoContent(http.StatusOK)
+	})
+	assert.Nil(t, h(c))
+
+	// a token with an invalid impersonator is not a session
+	c, _ = createTestContext(e, "Bearer bad-impersonator")
+	h = OptionalJWT(mockAuth, mockUser)(func(c echo.Context) error {
+		assert.Nil(t, c.Get("impersonator"))
+		assert.Nil(t, c.Get("user"))
+		return c.NoContent(http.StatusOK)
+	})
+	assert.Nil(t, h(c))
+}
+
+func TestJWT_ScopedTokenIsNotASession(t *testing.T) {
+	e := echo.New()
+	mockAuth, mockUser := createMockServices()
+
+	projectID := uuid.New()
+	mockAuth.On("VerifyToken", "embed-token").Return(&auth.Claims{
+		Scope:          auth.ScopeProjectEmbed,
+		StandardClaims: jwt.StandardClaims{Subject: projectID.String()},
+	}, nil)
+
+	testJWTFailure(t, e, mockAuth, mockUser, "Bearer embed-token", http.StatusUnauthorized, "Invalid or expired token")
+
+	c, _ := createTestContext(e, "Bearer embed-token")
+	h := OptionalJWT(mockAuth, mockUser)(func(c echo.Context) error {
+		assert.Nil(t, c.Get("user"))
+		return c.NoContent(http.StatusOK)
+	})
+	assert.Nil(t, h(c))
+	mockUser.AssertNotCalled(t, "GetUserByID", projectID)
+}
+
 func TestJWT_MissingAuthHeader(t *testing.T) {
 	e := echo.New()
 	mockAuth, mockUser := createMockServices()
@@ -234,7 +322,7 @@ func TestCheckBan_UserNotBanned(t *testing.T) {
 		Username: "user",
 		Role:     data.Role{Name: data.RoleUser.String()},
 		Ban: &data.Ban{
-			ExpiresAt: time.Now().Add(-time.Hour), // expired ban
+			ExpiresAt: utils.Ptr(time.Now().Add(-time.Hour)), // expired ban
 		},
 	}
 	c.Set("user", user)
@@ -283,7 +371,7 @@ func TestCheckBan_UserIsBanned(t *testing.T) {
 		Username: "user",
 		Role:     data.Role{Name: data.RoleUser.String()},
 		Ban: &data.Ban{
-			ExpiresAt: time.Now().Add(time.Hour),
+			ExpiresAt: utils.Ptr(time.Now().Add(time.Hour)),
 		},
 	}
 	c.Set("user", user)
@@ -301,3 +389,366 @@ func TestCheckBan_UserIsBanned(t *testing.T) {
 	assert.NotNil(t, httpErr)
 	assert.Equal(t, http.StatusForbidden, httpErr.Code)
 }
+
+func TestCheckBanAt_BanExpires(t *testing.T) {
+	e := echo.New()
+
+	clk := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	user := &data.User{
+		ID:       uuid.New(),
+		Username: "user",
+		Ban:      &data.Ban{ExpiresAt: utils.Ptr(clk.Now().Add(time.Hour))},
+	}
+
+	h := CheckBanAt(clk)(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	check := func() error {
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+		c.Set("user", user)
+		return h(c)
+	}
+
+	err := check()
+	if assert.Error(t, err) {
+		assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+	}
+
+	clk.Advance(59 * time.Minute)
+	assert.Error(t, check())
+
+	clk.Advance(time.Minute)
+	assert.NoError(t, check())
+}
+
+func TestCheckPasswordReset(t *testing.T) {
+	tests := map[string]struct {
+		resetRequired bool
+		path          string
+		wantCode      int
+	}{
+		"Reset not required": {
+			resetRequired: false,
+			path:          "/api/projects",
+			wantCode:      http.StatusOK,
+		},
+		"Reset required on blocked route": {
+			resetRequired: true,
+			path:          "/api/projects",
+			wantCode:      http.StatusForbidden,
+		},
+		"Reset required on allowed route": {
+			resetRequired: true,
+			path:          "/api/users/me/password",
+			wantCode:      http.StatusOK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath(tt.path)
+			c.Set("user", &data.User{
+				ID:                    uuid.New(),
+				Username:              "student",
+				PasswordResetRequired: tt.resetRequired,
+			})
+
+			h := CheckPasswordReset("/api/users/me/password")(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+
+			err := h(c)
+			if tt.wantCode == http.StatusOK {
+				assert.Nil(t, err)
+				assert.Equal(t, http.StatusOK, rec.Code)
+			} else {
+				httpErr, ok := err.(*echo.HTTPError)
+				assert.True(t, ok)
+				assert.Equal(t, tt.wantCode, httpErr.Code)
+				assert.Equal(t, "PASSWORD_RESET_REQUIRED", httpErr.Message)
+			}
+		})
+	}
+}
+
+func TestRestrictGuests(t *testing.T) {
+	tests := map[string]struct {
+		guestExpiresAt *time.Time
+		method         string
+		path           string
+		wantCode       int
+		wantMessage    string
+	}{
+		"Registered user": {
+			method:   http.MethodPost,
+			path:     "/api/classrooms",
+			wantCode: http.StatusOK,
+		},
+		"Guest on allowed route": {
+			guestExpiresAt: utils.Ptr(time.Now().Add(time.Hour)),
+			method:         http.MethodPost,
+			path:           "/api/projects",
+			wantCode:       http.StatusOK,
+		},
+		"Guest on blocked route": {
+			guestExpiresAt: utils.Ptr(time.Now().Add(time.Hour)),
+			method:         http.MethodPost,
+			path:           "/api/classrooms",
+			wantCode:       http.StatusForbidden,
+			wantMessage:    "GUEST_FORBIDDEN",
+		},
+		"Expired guest": {
+			guestExpiresAt: utils.Ptr(time.Now().Add(-time.Minute)),
+			method:         http.MethodPost,
+			path:           "/api/projects",
+			wantCode:       http.StatusUnauthorized,
+			wantMessage:    "GUEST_EXPIRED",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+
+			req := httptest.NewRequest(tt.method, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath(tt.path)
+			c.Set("user", &data.User{
+				ID:             uuid.New(),
+				Username:       "guest",
+				GuestExpiresAt: tt.guestExpiresAt,
+			})
+
+			h := RestrictGuests(clock.System, "POST /api/projects")(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+
+			err := h(c)
+			if tt.wantCode == http.StatusOK {
+				assert.Nil(t, err)
+				assert.Equal(t, http.StatusOK, rec.Code)
+			} else {
+				httpErr, ok := err.(*echo.HTTPError)
+				assert.True(t, ok)
+				assert.Equal(t, tt.wantCode, httpErr.Code)
+				assert.Equal(t, tt.wantMessage, httpErr.Message)
+			}
+		})
+	}
+}
+
+func TestRestrictGuests_GuestExpires(t *testing.T) {
+	e := echo.New()
+
+	clk := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	user := &data.User{ID: uuid.New(), Username: "guest", GuestExpiresAt: utils.Ptr(clk.Now().Add(time.Hour))}
+
+	h := RestrictGuests(clk, "POST /api/projects")(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	check := func() error {
+		c := e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder())
+		c.SetPath("/api/projects")
+		c.Set("user", user)
+		return h(c)
+	}
+
+	assert.NoError(t, check())
+
+	clk.Advance(59 * time.Minute)
+	assert.NoError(t, check())
+
+	clk.Advance(time.Minute)
+	err := check()
+	if assert.Error(t, err) {
+		assert.Equal(t, http.StatusUnauthorized, err.(*echo.HTTPError).Code)
+		assert.Equal(t, "GUEST_EXPIRED", err.(*echo.HTTPError).Message)
+	}
+}
+
+func TestImpersonation(t *testing.T) {
+	impersonatorID := uuid.New()
+	user := &data.User{ID: uuid.New(), Username: "student"}
+
+	tests := map[string]struct {
+		impersonating bool
+		method        string
+		path          string
+		auditErr      error
+		wantCode      int
+		wantBlocked   bool
+	}{
+		"Regular session": {
+			method:   http.MethodDelete,
+			path:     "/api/projects/:id",
+			wantCode: http.StatusOK,
+		},
+		"Impersonated request": {
+			impersonating: true,
+			method:        http.MethodGet,
+			path:          "/api/users/me",
+			wantCode:      http.StatusOK,
+		},
+		"Impersonated request on blocked route": {
+			impersonating: true,
+			method:        http.MethodPut,
+			path:          "/api/users/me/password",
+			wantCode:      http.StatusForbidden,
+			wantBlocked:   true,
+		},
+		"Same path with another method": {
+			impersonating: true,
+			method:        http.MethodGet,
+			path:          "/api/users/me/password",
+			wantCode:      http.StatusOK,
+		},
+		"Audit log unavailable": {
+			impersonating: true,
+			method:        http.MethodGet,
+			path:          "/api/users/me",
+			auditErr:      services.ErrInternal,
+			wantCode:      http.StatusInternalServerError,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+
+			mockAudit := new(mocks.MockAuditService)
+			if tt.impersonating {
+				mockAudit.On("Record", mock.MatchedBy(func(entry data.AuditEntry) bool {
+					return *entry.ActorID == impersonatorID &&
+						entry.Action == data.AuditImpersonationRequest &&
+						entry.TargetID == user.ID.String() &&
+						entry.Details["route"] == tt.method+" "+tt.path &&
+						entry.Details["blocked"] == tt.wantBlocked
+				})).Return(tt.auditErr)
+			}
+
+			req := httptest.NewRequest(tt.method, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath(tt.path)
+			c.Set("user", user)
+			if tt.impersonating {
+				c.Set("impersonator", impersonatorID)
+			}
+
+			h := Impersonation(mockAudit, "PUT /api/users/me/password", "DELETE /api/projects/:id")(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+
+			err := h(c)
+			if tt.wantCode == http.StatusOK {
+				assert.Nil(t, err)
+				assert.Equal(t, http.StatusOK, rec.Code)
+			} else {
+				httpErr, ok := err.(*echo.HTTPError)
+				assert.True(t, ok)
+				assert.Equal(t, tt.wantCode, httpErr.Code)
+			}
+
+			mockAudit.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	e := echo.New()
+	// a limit of 6 per minute allows a burst of a single request
+	h := NewRateLimiter(NewMemoryRateStore()).Limit("magic-link", 6)(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+	assert.NoError(t, h(c))
+	assert.Equal(t, "6", rec.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "10", rec.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "6;w=60;burst=1", rec.Header().Get("RateLimit-Policy"))
+
+	rec = httptest.NewRecorder()
+	c = e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+	err := h(c)
+	if assert.Error(t, err) {
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusTooManyRequests, he.Code)
+		// the next request is allowed once the bucket refilled, 10 seconds at 6 per minute
+		assert.Equal(t, "10", rec.Header().Get("Retry-After"))
+		assert.Equal(t, map[string]interface{}{
+			"code":        CodeRateLimited,
+			"message":     "Too many requests",
+			"retry_after": 10,
+		}, he.Message)
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	e := echo.New()
+
+	roleService := new(mocks.MockRoleService)
+	roleService.On("HasPermission", data.RoleModerator.ToID(), data.PermUsersBan).Return(true, nil)
+	roleService.On("HasPermission", data.RoleModerator.ToID(), data.PermUsersDelete).Return(false, nil)
+	roleService.On("HasPermission", data.RoleUser.ToID(), data.PermUsersBan).Return(false, services.ErrInternal)
+
+	moderator := &data.User{ID: uuid.New(), Role: data.Role{ID: data.RoleModerator.ToID(), Name: data.RoleModerator.String()}}
+	user := &data.User{ID: uuid.New(), Role: data.Role{ID: data.RoleUser.ToID(), Name: data.RoleUser.String()}}
+
+	tests := map[string]struct {
+		user       *data.User
+		permission data.Permission
+		wantCode   int
+	}{
+		"Granted": {
+			user:       moderator,
+			permission: data.PermUsersBan,
+			wantCode:   http.StatusOK,
+		},
+		"Not granted": {
+			user:       moderator,
+			permission: data.PermUsersDelete,
+			wantCode:   http.StatusForbidden,
+		},
+		"No user in context": {
+			permission: data.PermUsersBan,
+			wantCode:   http.StatusUnauthorized,
+		},
+		"Lookup failure": {
+			user:       user,
+			permission: data.PermUsersBan,
+			wantCode:   http.StatusInternalServerError,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, rec := createTestContext(e, "")
+			if tt.user != nil {
+				c.Set("user", tt.user)
+			}
+
+			err := RequirePermission(roleService, tt.permission)(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})(c)
+
+			if tt.wantCode == http.StatusOK {
+				assert.NoError(t, err)
+				assert.Equal(t, http.StatusOK, rec.Code)
+				return
+			}
+			httpErr, ok := err.(*echo.HTTPError)
+			if assert.True(t, ok) {
+				assert.Equal(t, tt.wantCode, httpErr.Code)
+			}
+		})
+	}
+}
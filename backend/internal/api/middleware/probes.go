@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"sync"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ProbeTokenHeader is the request header load balancer probes and uptime checkers send the probe token in.
+const ProbeTokenHeader = "X-Probe-Token"
+
+// probeKey marks requests of probes in the context.
+const probeKey = "probe"
+
+// ProbeGuard identifies the synthetic traffic of load balancer probes and uptime checkers by a static token.
+// Their requests skip rate limiting and load shedding, aren't counted as views or in the traffic metrics,
+// and are counted apart instead, so probing doesn't trip limits or show up on dashboards as visitors.
+type ProbeGuard struct {
+	token   []byte
+	mu      sync.Mutex
+	metrics data.ProbeMetrics
+}
+
+// NewProbeGuard creates a new ProbeGuard accepting the token of the configuration.
+func NewProbeGuard(cfg config.ProbesConfig) *ProbeGuard {
+	return &ProbeGuard{
+		token:   []byte(cfg.Token),
+		metrics: data.ProbeMetrics{Routes: map[string]int64{}},
+	}
+}
+
+// Middleware marks requests carrying the probe token as probes. Requests with a wrong token are rejected
+// rather than treated as regular traffic, so a misconfigured probe is noticed instead of being throttled later.
+func (g *ProbeGuard) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token := c.Request().Header.Get(ProbeTokenHeader)
+		if token == "" {
+			return next(c)
+		}
+
+		if len(g.token) == 0 || subtle.ConstantTimeCompare([]byte(token), g.token) != 1 {
+			g.mu.Lock()
+			g.metrics.Rejected++
+			g.mu.Unlock()
+			return echo.NewHTTPError(http.StatusUnauthorized, "Invalid probe token")
+		}
+
+		c.Set(probeKey, true)
+		err := next(c)
+
+		g.mu.Lock()
+		g.metrics.Requests++
+		g.metrics.Routes[c.Path()]++
+		if serverError(c, err) {
+			g.metrics.Failed++
+		}
+		g.mu.Unlock()
+
+		return err
+	}
+}
+
+// IsProbe reports whether the request was made by a probe.
+func IsProbe(c echo.Context) bool {
+	return c.Get(probeKey) != nil
+}
+
+// Metrics returns a snapshot of the probe counters.
+func (g *ProbeGuard) Metrics() data.ProbeMetrics {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	snapshot := g.metrics
+	snapshot.Routes = make(map[string]int64, len(g.metrics.Routes))
+	for route, count := range g.metrics.Routes {
+		snapshot.Routes[route] = count
+	}
+
+	return snapshot
+}
+
+// serverError reports whether the request was answered, or is about to be answered, with a server error.
+func serverError(c echo.Context, err error) bool {
+	if err == nil {
+		return c.Response().Status >= http.StatusInternalServerError
+	}
+
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		return he.Code >= http.StatusInternalServerError
+	}
+	return true
+}
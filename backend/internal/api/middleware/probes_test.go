@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+const probeToken = "0123456789abcdef0123456789abcdef"
+
+// serveProbe runs a request with the probe token through the probe guard and then the given middleware.
+func serveProbe(e *echo.Echo, guard *ProbeGuard, token string, inner echo.MiddlewareFunc, handler echo.HandlerFunc) error {
+	req := httptest.NewRequest(http.MethodGet, "/api/health", nil)
+	req.Header.Set("User-Agent", "curl/8.0")
+	if token != "" {
+		req.Header.Set(ProbeTokenHeader, token)
+	}
+	c := e.NewContext(req, httptest.NewRecorder())
+	c.SetPath("/api/health")
+
+	return guard.Middleware(inner(handler))(c)
+}
+
+func TestProbeGuard_SkipsLimits(t *testing.T) {
+	e := echo.New()
+	guard := NewProbeGuard(config.ProbesConfig{Token: probeToken})
+	// a budget of 6 per minute allows a burst of a single request
+	crawlers := NewCrawlerGuard(config.CrawlerConfig{BotRequestsPerMinute: 6, UserRequestsPerMinute: 6})
+	limit := NewRateLimiter(NewMemoryRateStore()).Limit("health", 6)
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, serveProbe(e, guard, probeToken, crawlers.Middleware, okHandler))
+		assert.NoError(t, serveProbe(e, guard, probeToken, limit, okHandler))
+	}
+
+	// probes are left out of the crawler traffic
+	assert.Equal(t, int64(0), crawlers.Metrics().BotRequests)
+
+	// the same client without the token is limited
+	assert.NoError(t, serveProbe(e, guard, "", crawlers.Middleware, okHandler))
+	assert.Error(t, serveProbe(e, guard, "", crawlers.Middleware, okHandler))
+
+	metrics := guard.Metrics()
+	assert.Equal(t, int64(6), metrics.Requests)
+	assert.Equal(t, int64(6), metrics.Routes["/api/health"])
+}
+
+func TestProbeGuard_SkipsShedding(t *testing.T) {
+	e := echo.New()
+	guard := NewProbeGuard(config.ProbesConfig{Token: probeToken})
+	flags := &mocks.MockFlagService{}
+	flags.On("IsEnabled", data.FlagShedLoad).Return(true)
+	l := NewLoadShedder(config.LoadSheddingConfig{}, flags, nil)
+
+	shed := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return l.Track()(l.Shed(next))
+	}
+
+	assert.NoError(t, serveProbe(e, guard, probeToken, shed, okHandler))
+	assert.Error(t, serveProbe(e, guard, "", shed, okHandler))
+
+	// only the request without the token counts as anonymous traffic
+	assert.Equal(t, int64(1), l.Metrics().Shed)
+	assert.Equal(t, int64(1), l.Metrics().Classes[ClassAnonymous].Requests)
+}
+
+func TestProbeGuard_InvalidToken(t *testing.T) {
+	e := echo.New()
+	passThrough := func(next echo.HandlerFunc) echo.HandlerFunc { return next }
+
+	tests := map[string]struct {
+		configured string
+		sent       string
+	}{
+		"Wrong token": {
+			configured: probeToken,
+			sent:       "fedcba9876543210fedcba9876543210",
+		},
+		"Probes disabled": {
+			sent: probeToken,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			guard := NewProbeGuard(config.ProbesConfig{Token: tt.configured})
+
+			err := serveProbe(e, guard, tt.sent, passThrough, okHandler)
+			if assert.Error(t, err) {
+				assert.Equal(t, http.StatusUnauthorized, err.(*echo.HTTPError).Code)
+			}
+			assert.Equal(t, int64(1), guard.Metrics().Rejected)
+			assert.Equal(t, int64(0), guard.Metrics().Requests)
+		})
+	}
+}
+
+func TestProbeGuard_CountsFailures(t *testing.T) {
+	e := echo.New()
+	guard := NewProbeGuard(config.ProbesConfig{Token: probeToken})
+	passThrough := func(next echo.HandlerFunc) echo.HandlerFunc { return next }
+
+	err := serveProbe(e, guard, probeToken, passThrough, func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "Database unreachable")
+	})
+	assert.Error(t, err)
+	assert.NoError(t, serveProbe(e, guard, probeToken, passThrough, okHandler))
+
+	metrics := guard.Metrics()
+	assert.Equal(t, int64(2), metrics.Requests)
+	assert.Equal(t, int64(1), metrics.Failed)
+}
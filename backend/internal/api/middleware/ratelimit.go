@@ -0,0 +1,229 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+)
+
+// Quota is the request budget of a bucket: PerMinute requests a minute on average, with room for Burst at once.
+type Quota struct {
+	PerMinute int
+	Burst     int
+}
+
+// NewQuota returns the quota of perMinute requests a minute allowing bursts of up to 10 seconds worth of it.
+func NewQuota(perMinute int) Quota {
+	return Quota{PerMinute: perMinute, Burst: max(1, perMinute/6)}
+}
+
+// period is the time it takes the bucket to regain a request.
+func (q Quota) period() time.Duration {
+	return time.Minute / time.Duration(q.PerMinute)
+}
+
+// RateDecision is the outcome of taking a request from a bucket.
+type RateDecision struct {
+	Allowed    bool
+	Remaining  int           // requests that can be made right away
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next request is allowed, if this one wasn't
+}
+
+// RateStore keeps the buckets of the rate limits. Buckets are kept by the generic cell rate algorithm,
+// a token bucket stored as the time it's full again, so a bucket is a single value wherever it's stored.
+type RateStore interface {
+	Take(ctx context.Context, key string, quota Quota) (RateDecision, error)
+	Close() error
+}
+
+// NewRateStore returns the store of the config: Redis when a URL is set, so instances share their buckets,
+// the memory of the instance otherwise.
+func NewRateStore(cfg config.RateLimitsConfig) (RateStore, error) {
+	if cfg.RedisURL == "" {
+		return NewMemoryRateStore(), nil
+	}
+	return NewRedisRateStore(cfg.RedisURL)
+}
+
+// MemoryRateStore keeps the buckets in memory, for a single instance.
+type MemoryRateStore struct {
+	mu    sync.Mutex
+	full  map[string]time.Time // when each bucket is full again
+	swept time.Time
+}
+
+// NewMemoryRateStore creates an empty MemoryRateStore.
+func NewMemoryRateStore() *MemoryRateStore {
+	return &MemoryRateStore{
+		full:  map[string]time.Time{},
+		swept: time.Now(),
+	}
+}
+
+// Take takes a request from the bucket of the key.
+func (s *MemoryRateStore) Take(ctx context.Context, key string, quota Quota) (RateDecision, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// full buckets are forgotten, a client seen again starts with a full bucket anyway
+	if now.Sub(s.swept) > time.Minute {
+		for k, full := range s.full {
+			if !full.After(now) {
+				delete(s.full, k)
+			}
+		}
+		s.swept = now
+	}
+
+	period := quota.period()
+	full := s.full[key]
+	if full.Before(now) {
+		full = now
+	}
+	next := full.Add(period)
+	allowAt := next.Add(-time.Duration(quota.Burst) * period)
+
+	if now.Before(allowAt) {
+		return RateDecision{Reset: full.Sub(now), RetryAfter: allowAt.Sub(now)}, nil
+	}
+
+	s.full[key] = next
+	return RateDecision{Allowed: true, Remaining: int(now.Sub(allowAt) / period), Reset: next.Sub(now)}, nil
+}
+
+// Close releases nothing, the buckets are gone with the instance.
+func (s *MemoryRateStore) Close() error {
+	return nil
+}
+
+// takeScript takes a request from a bucket by the clock of Redis, so instances with drifting clocks agree.
+// Times are in microseconds. It returns whether the request is allowed, the requests remaining,
+// the time until the bucket is full and the time until the next request is allowed.
+var takeScript = redis.NewScript(`
+local period = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+local full = tonumber(redis.call('GET', KEYS[1]) or now)
+if full < now then
+	full = now
+end
+local new_full = full + period
+local allow_at = new_full - burst * period
+if now < allow_at then
+	return {0, 0, full - now, allow_at - now}
+end
+redis.call('SET', KEYS[1], string.format('%.0f', new_full), 'PX', math.ceil((new_full - now) / 1000))
+return {1, math.floor((now - allow_at) / period), new_full - now, 0}
+`)
+
+// RedisRateStore keeps the buckets in Redis, shared by every instance of the API. While Redis can't be reached,
+// each instance limits requests by buckets of its own rather than letting them all through or turning them all away.
+type RedisRateStore struct {
+	client   *redis.Client
+	fallback *MemoryRateStore
+	logged   atomic.Int64 // when the last failure was logged, in Unix seconds
+}
+
+// NewRedisRateStore creates a RedisRateStore connecting to the Redis server at url, e.g. redis://localhost:6379/0.
+func NewRedisRateStore(url string) (*RedisRateStore, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RedisRateStore{
+		client:   redis.NewClient(opts),
+		fallback: NewMemoryRateStore(),
+	}, nil
+}
+
+// Take takes a request from the bucket of the key.
+func (s *RedisRateStore) Take(ctx context.Context, key string, quota Quota) (RateDecision, error) {
+	res, err := takeScript.Run(ctx, s.client, []string{"ratelimit:" + key}, quota.period().Microseconds(), quota.Burst).Int64Slice()
+	if err != nil || len(res) != 4 {
+		// failures are logged once a minute, not on every request while Redis is down
+		if now := time.Now().Unix(); now-s.logged.Load() >= 60 {
+			s.logged.Store(now)
+			logging.Error(ctx, "Rate limit store unreachable, limiting in memory", err)
+		}
+		return s.fallback.Take(ctx, key, quota)
+	}
+
+	return RateDecision{
+		Allowed:    res[0] == 1,
+		Remaining:  int(res[1]),
+		Reset:      time.Duration(res[2]) * time.Microsecond,
+		RetryAfter: time.Duration(res[3]) * time.Microsecond,
+	}, nil
+}
+
+// Close closes the connections to Redis.
+func (s *RedisRateStore) Close() error {
+	return s.client.Close()
+}
+
+// RateLimiter limits requests by buckets kept in a RateStore: signed-in users by their own bucket,
+// wherever they connect from, anonymous clients by the bucket of their IP.
+type RateLimiter struct {
+	store RateStore
+}
+
+// NewRateLimiter creates a RateLimiter keeping its buckets in the store.
+func NewRateLimiter(store RateStore) *RateLimiter {
+	return &RateLimiter{store: store}
+}
+
+// Limit limits every client to perMinute requests a minute in the named bucket, 0 disables the limit.
+// Routes limited under the same name share the budget of a client. Probes aren't limited.
+//
+// Responses carry the RateLimit-Limit (the budget of the minute), RateLimit-Remaining and RateLimit-Reset headers
+// of the bucket, throttled ones a 429 with Retry-After as well.
+func (l *RateLimiter) Limit(name string, perMinute int) echo.MiddlewareFunc {
+	quota := NewQuota(perMinute)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if perMinute <= 0 || IsProbe(c) {
+				return next(c)
+			}
+
+			client := "ip:" + c.RealIP()
+			if user, ok := c.Get("user").(*data.User); ok {
+				client = "user:" + user.ID.String()
+			}
+
+			decision, err := l.store.Take(c.Request().Context(), name+":"+client, quota)
+			if err != nil {
+				logging.Error(c.Request().Context(), "Internal rate limit error", err)
+				return echo.NewHTTPError(http.StatusInternalServerError, "Failed to check rate limit")
+			}
+
+			header := c.Response().Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(perMinute))
+			header.Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+			header.Set("RateLimit-Reset", strconv.Itoa(int(math.Ceil(decision.Reset.Seconds()))))
+			header.Set("RateLimit-Policy", strconv.Itoa(perMinute)+";w=60;burst="+strconv.Itoa(quota.Burst))
+
+			if !decision.Allowed {
+				return TooManyRequests(c, CodeRateLimited, "Too many requests", decision.RetryAfter)
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter_Buckets(t *testing.T) {
+	e := echo.New()
+	limiter := NewRateLimiter(NewMemoryRateStore())
+	// a limit of 12 per minute allows a burst of two requests
+	auth := limiter.Limit("auth", 12)
+	reads := limiter.Limit("reads", 12)
+
+	alice := &data.User{ID: uuid.New()}
+	bob := &data.User{ID: uuid.New()}
+
+	serve := func(limit echo.MiddlewareFunc, ip string, user *data.User) error {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = ip + ":1234"
+		c := e.NewContext(req, httptest.NewRecorder())
+		if user != nil {
+			c.Set("user", user)
+		}
+		return limit(okHandler)(c)
+	}
+
+	// anonymous clients are limited by IP
+	assert.NoError(t, serve(auth, "203.0.113.1", nil))
+	assert.NoError(t, serve(auth, "203.0.113.1", nil))
+	assert.Error(t, serve(auth, "203.0.113.1", nil))
+	assert.NoError(t, serve(auth, "203.0.113.2", nil))
+
+	// the reads bucket is apart from the auth one
+	assert.NoError(t, serve(reads, "203.0.113.1", nil))
+
+	// signed-in users are limited by user, wherever they connect from
+	assert.NoError(t, serve(reads, "203.0.113.3", alice))
+	assert.NoError(t, serve(reads, "203.0.113.4", alice))
+	assert.Error(t, serve(reads, "203.0.113.5", alice))
+	assert.NoError(t, serve(reads, "203.0.113.3", bob))
+}
+
+func TestRedisRateStore_Fallback(t *testing.T) {
+	// nothing listens on the port, every request is limited in memory instead
+	store, err := NewRedisRateStore("redis://127.0.0.1:1/0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer store.Close()
+
+	quota := NewQuota(6)
+	decision, err := store.Take(context.Background(), "auth:ip:203.0.113.1", quota)
+	assert.NoError(t, err)
+	assert.True(t, decision.Allowed)
+
+	decision, err = store.Take(context.Background(), "auth:ip:203.0.113.1", quota)
+	assert.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.InDelta(t, 10, decision.RetryAfter.Seconds(), 0.1)
+
+	_, err = NewRedisRateStore("not a url")
+	assert.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CodeReadOnly is the code of the 503 responses to writes in read-only mode.
+const CodeReadOnly = "READ_ONLY"
+
+// readOnlyRetryAfter is how long clients are asked to wait before retrying a write turned away in read-only mode
+const readOnlyRetryAfter = time.Minute
+
+// ReadOnly turns away writes with 503 while the API is in read-only mode, before they reach a database that may be
+// a replica. Requests with a safe method pass, except to the write routes that write anyway, such as signing in
+// through a link. So do the allowed routes, such as the switch that leaves read-only mode. Routes are written
+// as "METHOD /path". The status is read on every request, so the mode can be switched at runtime.
+func ReadOnly(status func() data.ReadOnlyStatus, allowedRoutes, writeRoutes []string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			method := c.Request().Method
+			route := method + " " + c.Path()
+			safe := method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+			if safe && !slices.Contains(writeRoutes, route) {
+				return next(c)
+			}
+
+			readOnly := status()
+			if !readOnly.Enabled || slices.Contains(allowedRoutes, route) {
+				return next(c)
+			}
+
+			message := "The site is read-only for maintenance, changes can't be saved right now"
+			if readOnly.Reason != "" {
+				message += ": " + readOnly.Reason
+			}
+
+			seconds := int(math.Ceil(readOnlyRetryAfter.Seconds()))
+			c.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+			return echo.NewHTTPError(http.StatusServiceUnavailable, map[string]interface{}{
+				"code":        CodeReadOnly,
+				"message":     message,
+				"retry_after": seconds,
+			})
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadOnly(t *testing.T) {
+	e := echo.New()
+	enabled := data.ReadOnlyStatus{Enabled: true, Reason: "database failover"}
+
+	tests := map[string]struct {
+		status   data.ReadOnlyStatus
+		method   string
+		path     string
+		wantCode int
+	}{
+		"Writes pass outside read-only mode": {
+			method:   http.MethodPost,
+			path:     "/api/projects",
+			wantCode: http.StatusOK,
+		},
+		"Reads pass in read-only mode": {
+			status:   enabled,
+			method:   http.MethodGet,
+			path:     "/api/projects/:id",
+			wantCode: http.StatusOK,
+		},
+		"Writes are turned away in read-only mode": {
+			status:   enabled,
+			method:   http.MethodPut,
+			path:     "/api/projects/:id",
+			wantCode: http.StatusServiceUnavailable,
+		},
+		"Reads that write are turned away in read-only mode": {
+			status:   enabled,
+			method:   http.MethodGet,
+			path:     "/api/auth/magic/:token",
+			wantCode: http.StatusServiceUnavailable,
+		},
+		"Reads that write pass outside read-only mode": {
+			method:   http.MethodGet,
+			path:     "/api/auth/magic/:token",
+			wantCode: http.StatusOK,
+		},
+		"Allowed routes pass in read-only mode": {
+			status:   enabled,
+			method:   http.MethodPut,
+			path:     "/api/admin/system/read-only",
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath(tt.path)
+
+			allowed := []string{"PUT /api/admin/system/read-only"}
+			writes := []string{"GET /api/auth/magic/:token"}
+			h := ReadOnly(func() data.ReadOnlyStatus { return tt.status }, allowed, writes)(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+
+			err := h(c)
+
+			if tt.wantCode == http.StatusOK {
+				assert.NoError(t, err)
+				assert.Equal(t, http.StatusOK, rec.Code)
+				return
+			}
+			if assert.Error(t, err) {
+				httpErr := err.(*echo.HTTPError)
+				assert.Equal(t, tt.wantCode, httpErr.Code)
+				body := httpErr.Message.(map[string]interface{})
+				assert.Equal(t, CodeReadOnly, body["code"])
+				assert.Contains(t, body["message"], "database failover")
+				assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
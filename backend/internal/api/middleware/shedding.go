@@ -0,0 +1,302 @@
+package middleware
+
+import (
+	"container/heap"
+	"database/sql"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services/flags"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CodeOverloaded is the code of the 503 responses of shed requests.
+const CodeOverloaded = "OVERLOADED"
+
+const (
+	// latencyWeight is how much a single request moves the average latency, so a few slow requests don't trip shedding
+	latencyWeight = 0.05
+	// shedRetryAfter is how long clients are asked to wait before retrying a shed request
+	shedRetryAfter = 30 * time.Second
+)
+
+// Traffic classes, in order of priority.
+const (
+	ClassEditor    = "editor"    // requests with credentials, mostly signed-in users working on their projects
+	ClassAnonymous = "anonymous" // browsing without signing in
+	ClassCrawler   = "crawler"   // requests the crawler guard identified as crawlers
+)
+
+// classWeights is the share of admissions each class gets while requests are queued.
+// Editors are admitted four times as often as anonymous visitors, who are admitted twice as often as crawlers.
+var classWeights = map[string]float64{
+	ClassEditor:    8,
+	ClassAnonymous: 2,
+	ClassCrawler:   1,
+}
+
+// LoadShedder turns away low-priority requests such as exports and search while the API is overloaded,
+// so editing and signing in stay responsive during traffic spikes instead of the whole site browning out.
+// The API counts as overloaded while too many requests are in flight or requests became slow on average,
+// and whenever the shed-load flag is on, which lets operators shed load at runtime.
+//
+// Once every connection of the database pool is in use, requests no longer start right away but queue until one finishes,
+// and the freed slots are given out by weighted fair queuing over the traffic classes. Requests waiting longer than the queue timeout are shed.
+type LoadShedder struct {
+	cfg      config.LoadSheddingConfig
+	flags    flags.IFlagService
+	pool     func() sql.DBStats
+	mu       sync.Mutex
+	inFlight int
+	latency  float64 // moving average in seconds
+	shed     int64
+	queue    waitQueue
+	virtual  float64            // finish tag of the last admitted request
+	finish   map[string]float64 // finish tag of the last queued request per class
+	classes  map[string]*data.TrafficClassMetrics
+}
+
+// NewLoadShedder creates a new LoadShedder with the provided thresholds, reading the shed-load flag from flags
+// and the usage of the database connection pool from pool.
+func NewLoadShedder(cfg config.LoadSheddingConfig, flags flags.IFlagService, pool func() sql.DBStats) *LoadShedder {
+	classes := map[string]*data.TrafficClassMetrics{}
+	for class := range classWeights {
+		classes[class] = &data.TrafficClassMetrics{}
+	}
+
+	return &LoadShedder{
+		cfg:     cfg,
+		flags:   flags,
+		pool:    pool,
+		finish:  map[string]float64{},
+		classes: classes,
+	}
+}
+
+// Track admits requests and measures the requests in flight and their latency. It is applied to every route, except the given route paths
+// of long-lived requests such as event streams, which would otherwise count as slow and in flight forever.
+// Probes are neither queued nor measured, a health check has to answer while the API is busy.
+func (l *LoadShedder) Track(skip ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if IsProbe(c) {
+				return next(c)
+			}
+			for _, path := range skip {
+				if c.Path() == path {
+					return next(c)
+				}
+			}
+
+			class := trafficClass(c)
+			if !l.admit(c, class) {
+				return l.reject(c, class)
+			}
+
+			start := time.Now()
+			err := next(c)
+			elapsed := time.Since(start).Seconds()
+
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.inFlight--
+			if c.Get(shedKey) == nil {
+				// shed requests answer right away and would hide the overload they are shed for
+				l.latency += (elapsed - l.latency) * latencyWeight
+			}
+			l.dispatch()
+
+			return err
+		}
+	}
+}
+
+// trafficClass returns the class a request is scheduled in. Credentials aren't verified here,
+// requests with invalid ones are rejected right after admission anyway.
+func trafficClass(c echo.Context) string {
+	switch {
+	case c.Get("crawler") != nil:
+		return ClassCrawler
+	case hasCredentials(c.Request()):
+		return ClassEditor
+	default:
+		return ClassAnonymous
+	}
+}
+
+// waiter is a request queued for admission.
+type waiter struct {
+	class    string
+	tag      float64 // virtual finish time, requests with lower tags are admitted first
+	index    int     // in the queue, -1 once admitted or given up
+	admitted chan struct{}
+}
+
+// admit counts the request as in flight, queuing it first while the database pool is saturated.
+// It reports false if the request wasn't admitted within the queue timeout.
+func (l *LoadShedder) admit(c echo.Context, class string) bool {
+	l.mu.Lock()
+	l.classes[class].Requests++
+	if len(l.queue) == 0 && !l.saturated() {
+		l.inFlight++
+		l.mu.Unlock()
+		return true
+	}
+
+	// the tag grows by the inverse of the weight, so heavier classes get through the queue faster
+	w := &waiter{class: class, admitted: make(chan struct{})}
+	w.tag = math.Max(l.virtual, l.finish[class]) + 1/classWeights[class]
+	l.finish[class] = w.tag
+	heap.Push(&l.queue, w)
+	l.classes[class].Queued++
+	l.mu.Unlock()
+
+	timer := time.NewTimer(l.cfg.QueueTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-w.admitted:
+		return true
+	case <-timer.C:
+	case <-c.Request().Context().Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if w.index < 0 {
+		// admitted while giving up
+		return true
+	}
+	heap.Remove(&l.queue, w.index)
+	l.classes[class].Queued--
+	return false
+}
+
+// dispatch admits queued requests for as long as the database pool isn't saturated. Callers must hold the lock.
+func (l *LoadShedder) dispatch() {
+	for len(l.queue) > 0 && !l.saturated() {
+		w := heap.Pop(&l.queue).(*waiter)
+		l.virtual = w.tag
+		l.classes[w.class].Queued--
+		l.inFlight++
+		close(w.admitted)
+	}
+}
+
+// saturated reports whether every connection of the database pool is in use and at least as many requests are in flight,
+// so connections held by background work alone don't hold up requests. Callers must hold the lock.
+func (l *LoadShedder) saturated() bool {
+	if l.pool == nil {
+		return false
+	}
+	stats := l.pool()
+	return stats.MaxOpenConnections > 0 && stats.InUse >= stats.MaxOpenConnections && l.inFlight >= stats.MaxOpenConnections
+}
+
+// shedKey marks requests answered by Shed.
+const shedKey = "load_shed"
+
+// Shed answers low-priority requests with 503 while the API is overloaded, other than those of probes.
+func (l *LoadShedder) Shed(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if IsProbe(c) {
+			return next(c)
+		}
+		if overloaded, _ := l.overloaded(); !overloaded {
+			return next(c)
+		}
+
+		c.Set(shedKey, true)
+		return l.reject(c, trafficClass(c))
+	}
+}
+
+// reject counts the shed request and answers it with 503.
+func (l *LoadShedder) reject(c echo.Context, class string) error {
+	l.mu.Lock()
+	l.shed++
+	l.classes[class].Shed++
+	l.mu.Unlock()
+
+	seconds := int(math.Ceil(shedRetryAfter.Seconds()))
+	c.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+	return echo.NewHTTPError(http.StatusServiceUnavailable, map[string]interface{}{
+		"code":        CodeOverloaded,
+		"message":     "This feature is temporarily unavailable due to high load, please try again later",
+		"retry_after": seconds,
+	})
+}
+
+// overloaded reports whether low-priority requests are shed, and why.
+func (l *LoadShedder) overloaded() (bool, string) {
+	if l.flags.IsEnabled(data.FlagShedLoad) {
+		return true, "flag"
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.cfg.MaxInFlight > 0 && l.inFlight > l.cfg.MaxInFlight {
+		return true, "in_flight"
+	}
+	if l.cfg.MaxLatency > 0 && l.latency > l.cfg.MaxLatency.Seconds() {
+		return true, "latency"
+	}
+	return false, ""
+}
+
+// Metrics returns a snapshot of the load and the requests shed since startup.
+func (l *LoadShedder) Metrics() data.LoadMetrics {
+	overloaded, reason := l.overloaded()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	classes := make(map[string]data.TrafficClassMetrics, len(l.classes))
+	for class, metrics := range l.classes {
+		classes[class] = *metrics
+	}
+
+	return data.LoadMetrics{
+		InFlight:   l.inFlight,
+		Queued:     len(l.queue),
+		LatencyMs:  l.latency * 1000,
+		Overloaded: overloaded,
+		Reason:     reason,
+		Shed:       l.shed,
+		Classes:    classes,
+	}
+}
+
+// waitQueue orders queued requests by their finish tag, it implements heap.Interface.
+type waitQueue []*waiter
+
+func (q waitQueue) Len() int           { return len(q) }
+func (q waitQueue) Less(i, j int) bool { return q[i].tag < q[j].tag }
+
+func (q waitQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waitQueue) Push(x any) {
+	w := x.(*waiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *waitQueue) Pop() any {
+	old := *q
+	w := old[len(old)-1]
+	old[len(old)-1] = nil
+	w.index = -1
+	*q = old[:len(old)-1]
+	return w
+}
@@ -0,0 +1,221 @@
+package middleware
+
+import (
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveShed runs a request through the tracking and shedding middleware of a low-priority route
+func serveShed(e *echo.Echo, l *LoadShedder, path string, handler echo.HandlerFunc) (*httptest.ResponseRecorder, error) {
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, path, nil), rec)
+	c.SetPath(path)
+
+	return rec, l.Track("/api/users/me/events")(l.Shed(handler))(c)
+}
+
+func assertShed(t *testing.T, rec *httptest.ResponseRecorder, err error) {
+	if assert.Error(t, err) {
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusServiceUnavailable, he.Code)
+		assert.Equal(t, CodeOverloaded, he.Message.(map[string]interface{})["code"])
+		assert.Equal(t, "30", rec.Header().Get("Retry-After"))
+	}
+}
+
+func okHandler(c echo.Context) error {
+	return c.NoContent(http.StatusOK)
+}
+
+func TestLoadShedder_Flag(t *testing.T) {
+	e := echo.New()
+	flags := &mocks.MockFlagService{}
+	flags.On("IsEnabled", data.FlagShedLoad).Return(false).Once()
+	flags.On("IsEnabled", data.FlagShedLoad).Return(true)
+	l := NewLoadShedder(config.LoadSheddingConfig{}, flags, nil)
+
+	_, err := serveShed(e, l, "/api/projects/public", okHandler)
+	assert.NoError(t, err)
+
+	// operators turned shedding on
+	rec, err := serveShed(e, l, "/api/projects/public", okHandler)
+	assertShed(t, rec, err)
+
+	m := l.Metrics()
+	assert.True(t, m.Overloaded)
+	assert.Equal(t, "flag", m.Reason)
+	assert.Equal(t, int64(1), m.Shed)
+	assert.Equal(t, int64(1), m.Classes[ClassAnonymous].Shed)
+}
+
+func TestLoadShedder_InFlight(t *testing.T) {
+	e := echo.New()
+	flags := &mocks.MockFlagService{}
+	flags.On("IsEnabled", data.FlagShedLoad).Return(false)
+	l := NewLoadShedder(config.LoadSheddingConfig{MaxInFlight: 1}, flags, nil)
+
+	// two requests still being handled, e.g. editor saves, push the instance over the limit
+	var rec *httptest.ResponseRecorder
+	var err error
+	serveShed(e, l, "/api/projects/:id/data", func(c echo.Context) error {
+		return l.Track()(func(c echo.Context) error {
+			rec, err = serveShed(e, l, "/api/projects/public", okHandler)
+			return nil
+		})(c)
+	})
+	assertShed(t, rec, err)
+
+	// the load is gone once they finished
+	_, err = serveShed(e, l, "/api/projects/public", okHandler)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, l.Metrics().InFlight)
+}
+
+func TestLoadShedder_Latency(t *testing.T) {
+	e := echo.New()
+	flags := &mocks.MockFlagService{}
+	flags.On("IsEnabled", data.FlagShedLoad).Return(false)
+	l := NewLoadShedder(config.LoadSheddingConfig{MaxLatency: time.Millisecond}, flags, nil)
+
+	slow := func(c echo.Context) error {
+		time.Sleep(5 * time.Millisecond)
+		return c.NoContent(http.StatusOK)
+	}
+
+	// the average follows slow requests gradually
+	for i := 0; i < 100; i++ {
+		if overloaded, _ := l.overloaded(); overloaded {
+			break
+		}
+		_, err := serveShed(e, l, "/api/projects/:id/data", slow)
+		assert.NoError(t, err)
+	}
+
+	rec, err := serveShed(e, l, "/api/projects/public", okHandler)
+	assertShed(t, rec, err)
+	assert.Equal(t, "latency", l.Metrics().Reason)
+}
+
+func TestLoadShedder_SkipsEventStreams(t *testing.T) {
+	e := echo.New()
+	flags := &mocks.MockFlagService{}
+	flags.On("IsEnabled", data.FlagShedLoad).Return(false)
+	l := NewLoadShedder(config.LoadSheddingConfig{MaxInFlight: 1}, flags, nil)
+
+	var inFlight int
+	_, err := serveShed(e, l, "/api/users/me/events", func(c echo.Context) error {
+		inFlight = l.Metrics().InFlight
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, inFlight)
+}
+
+// serveClass runs a request of the given traffic class through the tracking middleware.
+func serveClass(e *echo.Echo, l *LoadShedder, class string, handler echo.HandlerFunc) (*httptest.ResponseRecorder, error) {
+	req := httptest.NewRequest(http.MethodGet, "/api/projects/public", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	switch class {
+	case ClassEditor:
+		req.Header.Set("Authorization", "Bearer token")
+	case ClassCrawler:
+		c.Set("crawler", "googlebot")
+	}
+
+	return rec, l.Track()(handler)(c)
+}
+
+// saturatedPool reports a pool whose only connection is in use.
+func saturatedPool() sql.DBStats {
+	return sql.DBStats{MaxOpenConnections: 1, InUse: 1}
+}
+
+// holdConnection keeps a request in flight until the returned function is called.
+func holdConnection(t *testing.T, e *echo.Echo, l *LoadShedder) func() {
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		serveClass(e, l, ClassEditor, func(c echo.Context) error {
+			<-release
+			return nil
+		})
+	}()
+	assert.Eventually(t, func() bool { return l.Metrics().InFlight == 1 }, time.Second, time.Millisecond)
+
+	return func() {
+		close(release)
+		<-done
+	}
+}
+
+func TestLoadShedder_FairQueuing(t *testing.T) {
+	e := echo.New()
+	flags := &mocks.MockFlagService{}
+	flags.On("IsEnabled", data.FlagShedLoad).Return(false)
+	l := NewLoadShedder(config.LoadSheddingConfig{QueueTimeout: time.Second}, flags, saturatedPool)
+
+	release := holdConnection(t, e, l)
+
+	// requests queue while the pool is saturated, whatever order they arrive in
+	order := make(chan string, 3)
+	var wg sync.WaitGroup
+	for i, class := range []string{ClassCrawler, ClassAnonymous, ClassEditor} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := serveClass(e, l, class, func(c echo.Context) error {
+				order <- class
+				return nil
+			})
+			assert.NoError(t, err)
+		}()
+		assert.Eventually(t, func() bool { return l.Metrics().Queued == i+1 }, time.Second, time.Millisecond)
+	}
+
+	release()
+	wg.Wait()
+	close(order)
+
+	var admitted []string
+	for class := range order {
+		admitted = append(admitted, class)
+	}
+	assert.Equal(t, []string{ClassEditor, ClassAnonymous, ClassCrawler}, admitted)
+
+	m := l.Metrics()
+	assert.Equal(t, 0, m.Queued)
+	assert.Equal(t, 0, m.InFlight)
+	assert.Equal(t, int64(2), m.Classes[ClassEditor].Requests)
+	assert.Equal(t, int64(1), m.Classes[ClassCrawler].Requests)
+}
+
+func TestLoadShedder_QueueTimeout(t *testing.T) {
+	e := echo.New()
+	flags := &mocks.MockFlagService{}
+	flags.On("IsEnabled", data.FlagShedLoad).Return(false)
+	l := NewLoadShedder(config.LoadSheddingConfig{QueueTimeout: 10 * time.Millisecond}, flags, saturatedPool)
+
+	release := holdConnection(t, e, l)
+	defer release()
+
+	rec, err := serveClass(e, l, ClassCrawler, okHandler)
+	assertShed(t, rec, err)
+
+	m := l.Metrics()
+	assert.Equal(t, 0, m.Queued)
+	assert.Equal(t, int64(1), m.Classes[ClassCrawler].Shed)
+	assert.Equal(t, int64(0), m.Classes[ClassEditor].Shed)
+}
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services/signups"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/time/rate"
+)
+
+// SignupGuard throttles registrations by the subnet of the client and the domain of the email address,
+// so a single host can't create accounts in bulk. Subnets and domains on the allowlist are exempt.
+type SignupGuard struct {
+	allowlist signups.ISignupService
+	subnets   *Limiter
+	domains   *Limiter
+	mu        sync.Mutex
+	metrics   data.SignupMetrics
+}
+
+// NewSignupGuard creates a new SignupGuard with the provided budgets.
+func NewSignupGuard(cfg config.SignupsConfig, allowlist signups.ISignupService) *SignupGuard {
+	return &SignupGuard{
+		allowlist: allowlist,
+		subnets:   newHourlyLimiterStore(cfg.PerSubnetPerHour),
+		domains:   newHourlyLimiterStore(cfg.PerDomainPerHour),
+	}
+}
+
+// newHourlyLimiterStore creates a limiter allowing the whole hourly budget at once.
+func newHourlyLimiterStore(perHour int) *Limiter {
+	if perHour <= 0 {
+		return nil
+	}
+
+	return NewLimiter(rate.Limit(float64(perHour)/3600), perHour, time.Hour)
+}
+
+// Middleware applies the signup budgets to a registration route. The email is read from the JSON body,
+// which is left in place for the handler. The subnet is that of c.RealIP, so a client can only claim another
+// address through the trusted proxies of the server's IPExtractor.
+func (g *SignupGuard) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+		}
+		c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+		var payload struct {
+			Email string `json:"email"`
+		}
+		_ = json.Unmarshal(body, &payload)
+
+		ip := c.RealIP()
+		domain := emailDomain(payload.Email)
+
+		var retryAfter time.Duration
+		blockedBySubnet := g.subnets != nil && !allow(g.subnets, signupSubnet(ip), &retryAfter)
+		blockedByDomain := !blockedBySubnet && domain != "" && g.domains != nil && !allow(g.domains, domain, &retryAfter)
+
+		if blockedBySubnet || blockedByDomain {
+			allowlisted, err := g.allowlist.IsAllowlisted(ip, domain)
+			if err != nil {
+				logging.Error(c.Request().Context(), "Internal signup allowlist check error", err)
+			}
+			if !allowlisted {
+				g.count(func(m *data.SignupMetrics) {
+					if blockedBySubnet {
+						m.BlockedBySubnet++
+					} else {
+						m.BlockedByDomain++
+					}
+				})
+				return TooManyRequests(c, CodeSignupLimit, "Too many signups from your network, please try again later", retryAfter)
+			}
+			g.count(func(m *data.SignupMetrics) { m.Allowlisted++ })
+			return next(c)
+		}
+
+		g.count(func(m *data.SignupMetrics) { m.Allowed++ })
+		return next(c)
+	}
+}
+
+// Metrics returns a snapshot of the signup counters.
+func (g *SignupGuard) Metrics() data.SignupMetrics {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.metrics
+}
+
+func (g *SignupGuard) count(update func(*data.SignupMetrics)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	update(&g.metrics)
+}
+
+// allow takes a request from the budget of identifier, recording the wait in retryAfter when it is spent.
+func allow(store *Limiter, identifier string, retryAfter *time.Duration) bool {
+	allowed, wait := store.Allow(identifier)
+	*retryAfter = wait
+	return allowed
+}
+
+// signupSubnet returns the subnet signups of the IP are counted in, its /24 for IPv4 and its /48 for IPv6,
+// which is typically what a single host or customer gets.
+func signupSubnet(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ip
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return (&net.IPNet{IP: v4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+	return (&net.IPNet{IP: parsed.Mask(net.CIDRMask(48, 128)), Mask: net.CIDRMask(48, 128)}).String()
+}
+
+// emailDomain returns the lowercased domain of an email address, empty if it has none.
+func emailDomain(email string) string {
+	_, domain, found := strings.Cut(email, "@")
+	if !found {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(domain))
+}
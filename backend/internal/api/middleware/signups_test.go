@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/mocks"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newSignupServer creates a server reading the client IP the way the API does without trusted proxies
+func newSignupServer(t *testing.T) *echo.Echo {
+	e := echo.New()
+	extractor, err := IPExtractor(nil)
+	assert.NoError(t, err)
+	e.IPExtractor = extractor
+	return e
+}
+
+// serveSignup runs a registration through the signup middleware and returns the body the handler received
+func serveSignup(e *echo.Echo, guard *SignupGuard, ip, email string) (string, error) {
+	req := httptest.NewRequest(http.MethodPost, "/api/users", strings.NewReader(`{"email":"`+email+`"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.RemoteAddr = net.JoinHostPort(ip, "1234")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	var body string
+	err := guard.Middleware(func(c echo.Context) error {
+		b, _ := io.ReadAll(c.Request().Body)
+		body = string(b)
+		return c.NoContent(http.StatusCreated)
+	})(c)
+
+	return body, err
+}
+
+func assertSignupLimited(t *testing.T, err error) {
+	if assert.Error(t, err) {
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusTooManyRequests, he.Code)
+		assert.Equal(t, CodeSignupLimit, he.Message.(map[string]interface{})["code"])
+	}
+}
+
+func TestSignupGuard_SubnetBudget(t *testing.T) {
+	e := newSignupServer(t)
+	allowlist := &mocks.MockSignupService{}
+	allowlist.On("IsAllowlisted", mock.Anything, mock.Anything).Return(false, nil)
+	guard := NewSignupGuard(config.SignupsConfig{PerSubnetPerHour: 2}, allowlist)
+
+	body, err := serveSignup(e, guard, "203.0.113.10", "one@example.com")
+	assert.NoError(t, err)
+	assert.Contains(t, body, "one@example.com")
+	_, err = serveSignup(e, guard, "203.0.113.11", "two@example.com")
+	assert.NoError(t, err)
+
+	// same /24
+	_, err = serveSignup(e, guard, "203.0.113.200", "three@example.com")
+	assertSignupLimited(t, err)
+
+	// other subnets have their own budget
+	_, err = serveSignup(e, guard, "198.51.100.1", "four@example.com")
+	assert.NoError(t, err)
+
+	metrics := guard.Metrics()
+	assert.Equal(t, int64(3), metrics.Allowed)
+	assert.Equal(t, int64(1), metrics.BlockedBySubnet)
+}
+
+func TestSignupGuard_DomainBudget(t *testing.T) {
+	e := newSignupServer(t)
+	allowlist := &mocks.MockSignupService{}
+	allowlist.On("IsAllowlisted", mock.Anything, "school.edu").Return(true, nil)
+	allowlist.On("IsAllowlisted", mock.Anything, mock.Anything).Return(false, nil)
+	guard := NewSignupGuard(config.SignupsConfig{PerDomainPerHour: 1}, allowlist)
+
+	_, err := serveSignup(e, guard, "203.0.113.10", "one@spam.test")
+	assert.NoError(t, err)
+	_, err = serveSignup(e, guard, "198.51.100.1", "two@SPAM.test")
+	assertSignupLimited(t, err)
+
+	// allowlisted domains aren't limited
+	_, err = serveSignup(e, guard, "203.0.113.10", "one@school.edu")
+	assert.NoError(t, err)
+	_, err = serveSignup(e, guard, "203.0.113.11", "two@school.edu")
+	assert.NoError(t, err)
+
+	metrics := guard.Metrics()
+	assert.Equal(t, int64(2), metrics.Allowed)
+	assert.Equal(t, int64(1), metrics.BlockedByDomain)
+	assert.Equal(t, int64(1), metrics.Allowlisted)
+}
+
+func TestSignupGuard_IgnoresForwardedFor(t *testing.T) {
+	e := newSignupServer(t)
+	allowlist := &mocks.MockSignupService{}
+	allowlist.On("IsAllowlisted", mock.Anything, mock.Anything).Return(false, nil)
+	guard := NewSignupGuard(config.SignupsConfig{PerSubnetPerHour: 1}, allowlist)
+
+	serve := func(forwardedFor string) error {
+		req := httptest.NewRequest(http.MethodPost, "/api/users", strings.NewReader(`{"email":"someone@example.com"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderXForwardedFor, forwardedFor)
+		req.Header.Set(echo.HeaderXRealIP, forwardedFor)
+		req.RemoteAddr = "203.0.113.10:1234"
+		c := e.NewContext(req, httptest.NewRecorder())
+
+		return guard.Middleware(func(c echo.Context) error {
+			return c.NoContent(http.StatusCreated)
+		})(c)
+	}
+
+	assert.NoError(t, serve("198.51.100.1"))
+
+	// the subnet is that of the connection, whatever address the client claims to forward for
+	assertSignupLimited(t, serve("192.0.2.1"))
+	allowlist.AssertCalled(t, "IsAllowlisted", "203.0.113.10", "example.com")
+}
+
+func TestSignupSubnet(t *testing.T) {
+	assert.Equal(t, "203.0.113.0/24", signupSubnet("203.0.113.77"))
+	assert.Equal(t, "2001:db8:1::/48", signupSubnet("2001:db8:1:2::5"))
+}
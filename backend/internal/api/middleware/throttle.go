@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/time/rate"
+)
+
+// Codes of the 429 responses, so clients can tell the limits apart without parsing messages.
+const (
+	CodeRateLimited     = "RATE_LIMITED"
+	CodeSignupLimit     = "SIGNUP_LIMIT"
+	CodeMagicLinkLimit  = "MAGIC_LINK_LIMIT"
+	CodeTooManyAttempts = "TOO_MANY_ATTEMPTS"
+)
+
+// Limiter keeps a token bucket per client like echo's memory store, and also tells a throttled client how long to wait.
+type Limiter struct {
+	mu        sync.Mutex
+	limit     rate.Limit
+	burst     int
+	expiresIn time.Duration
+	visitors  map[string]*visitor
+	swept     time.Time
+}
+
+type visitor struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewLimiter creates a Limiter refilling at limit with room for burst requests.
+// Clients not seen for expiresIn are forgotten, starting with a full bucket again.
+func NewLimiter(limit rate.Limit, burst int, expiresIn time.Duration) *Limiter {
+	return &Limiter{
+		limit:     limit,
+		burst:     burst,
+		expiresIn: expiresIn,
+		visitors:  map[string]*visitor{},
+		swept:     time.Now(),
+	}
+}
+
+// Allow reports whether the client may proceed and, if it may not, how long until its next request is allowed.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.swept) > l.expiresIn {
+		for k, v := range l.visitors {
+			if now.Sub(v.lastSeen) > l.expiresIn {
+				delete(l.visitors, k)
+			}
+		}
+		l.swept = now
+	}
+
+	v, ok := l.visitors[key]
+	if !ok {
+		v = &visitor{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.visitors[key] = v
+	}
+	v.lastSeen = now
+
+	if v.limiter.AllowN(now, 1) {
+		return true, 0
+	}
+
+	missing := 1 - v.limiter.TokensAt(now)
+	return false, time.Duration(missing / float64(l.limit) * float64(time.Second))
+}
+
+// TooManyRequests is the response of every throttled request: a 429 with a machine-readable code and,
+// when the wait is known, a Retry-After header and retry_after field in whole seconds so clients can show a countdown.
+func TooManyRequests(c echo.Context, code, message string, retryAfter time.Duration) *echo.HTTPError {
+	body := map[string]interface{}{
+		"code":    code,
+		"message": message,
+	}
+
+	if retryAfter > 0 {
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		c.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+		body["retry_after"] = seconds
+	}
+
+	return echo.NewHTTPError(http.StatusTooManyRequests, body)
+}
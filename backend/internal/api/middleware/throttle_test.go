@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+)
+
+func TestLimiter(t *testing.T) {
+	// 2 per hour with a burst of 2, a spent budget refills a request every 30 minutes
+	l := NewLimiter(rate.Limit(2.0/3600), 2, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		allowed, wait := l.Allow("client")
+		assert.True(t, allowed)
+		assert.Zero(t, wait)
+	}
+
+	allowed, wait := l.Allow("client")
+	assert.False(t, allowed)
+	assert.InDelta(t, (30 * time.Minute).Seconds(), wait.Seconds(), 1)
+
+	// throttled requests don't push the wait further out
+	_, again := l.Allow("client")
+	assert.InDelta(t, wait.Seconds(), again.Seconds(), 1)
+
+	allowed, _ = l.Allow("other")
+	assert.True(t, allowed)
+}
@@ -0,0 +1,225 @@
+package api
+
+import (
+	"net/http"
+
+	m "NodeTurtleAPI/internal/api/middleware"
+	"NodeTurtleAPI/internal/clock"
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services/audit"
+	"NodeTurtleAPI/internal/services/auth"
+	"NodeTurtleAPI/internal/services/roles"
+	"NodeTurtleAPI/internal/services/users"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Route policies
+//
+// Every endpoint is declared once in a route table, along with who may call it, the permission it needs and how
+// it's limited. The middleware chain of each route is built from its entry by the same factories, in the same order
+// for every route, so a new endpoint gets all the guards of its policy by being added to the table, and lists of
+// routes kept by middleware (guest access, impersonation, read-only mode) are derived from it.
+//
+// Signed-in routes are chained as: session, ban, pending password reset, impersonation, guest access, permission,
+// rate limit. Public routes are limited first, so overload is turned away before any session is looked up,
+// then served from the crawler cache, then the session is read if there is one and impersonated requests are recorded. Rate limits therefore count
+// the requests of signed-in routes per user and those of public routes per IP.
+
+// AuthPolicy is who may call a route.
+type AuthPolicy int
+
+const (
+	// Public routes need no session.
+	Public AuthPolicy = iota
+	// OptionalAuth routes are public, a session is read when there is one so the user sees more.
+	OptionalAuth
+	// Registered routes need the session of a registered user who isn't banned.
+	Registered
+	// GuestAllowed routes let anonymous guest accounts in as well: the editor and the account itself.
+	GuestAllowed
+)
+
+// RateClass is how a route is limited.
+type RateClass int
+
+const (
+	Unlimited RateClass = iota
+	// Shed routes are turned away first when the API is overloaded: search, listings, thumbnails and exports.
+	Shed
+	// Signup routes create accounts and go through the signup guard.
+	Signup
+	// Polled routes are polled by the client while the user does something else, each with a budget of its own.
+	Polled
+	// Sensitive routes send emails or check codes, each with a small budget of its own so they can't be used in bulk.
+	Sensitive
+	// Costly routes create resources for anonymous clients or process uploads, each with the smallest budget.
+	Costly
+	// Auth routes sign in or refresh sessions and share a budget, so passwords can't be guessed across them.
+	Auth
+)
+
+// Route declares an endpoint and what guards it.
+type Route struct {
+	Method     string
+	Path       string
+	Handler    echo.HandlerFunc
+	Auth       AuthPolicy
+	Permission data.Permission // needed on top of the auth policy, empty if none
+	Rate       RateClass
+	Cached     bool // crawlers are served cached responses
+
+	// PasswordReset routes stay reachable while the user still has to replace a generated password.
+	PasswordReset bool
+	// NoImpersonation routes change the account itself and are off limits while impersonating.
+	NoImpersonation bool
+	// ReadOnlyAllowed routes are served in read-only mode despite their method,
+	// because they only read or switch read-only mode itself.
+	ReadOnlyAllowed bool
+	// Writes routes are turned away in read-only mode despite their safe method, because they write anyway,
+	// e.g. to sign in through a link.
+	Writes bool
+}
+
+// key returns the route as "METHOD /path", the way middleware lists routes.
+func (r Route) key() string {
+	return r.Method + " " + r.Path
+}
+
+// signedIn reports whether the route needs a session.
+func (r Route) signedIn() bool {
+	return r.Auth == Registered || r.Auth == GuestAllowed
+}
+
+// Policies builds the middleware chains of routes from their declarations.
+type Policies struct {
+	authService  auth.IAuthService
+	userService  users.IUserService
+	roleService  roles.IRoleService
+	auditService audit.IAuditService
+	crawlerGuard *m.CrawlerGuard
+	signupGuard  *m.SignupGuard
+	loadShedder  *m.LoadShedder
+	rateLimiter  *m.RateLimiter
+	rates        config.RateLimitsConfig
+	clock        clock.Clock // bans and guest accounts expire by its time
+}
+
+// NewPolicies creates the middleware factories of the route tables.
+func NewPolicies(authService auth.IAuthService, userService users.IUserService, roleService roles.IRoleService, auditService audit.IAuditService, crawlerGuard *m.CrawlerGuard, signupGuard *m.SignupGuard, loadShedder *m.LoadShedder, rateLimiter *m.RateLimiter, rates config.RateLimitsConfig, clk clock.Clock) Policies {
+	return Policies{
+		authService:  authService,
+		userService:  userService,
+		roleService:  roleService,
+		auditService: auditService,
+		crawlerGuard: crawlerGuard,
+		signupGuard:  signupGuard,
+		loadShedder:  loadShedder,
+		rateLimiter:  rateLimiter,
+		rates:        rates,
+		clock:        clk,
+	}
+}
+
+// Chain returns the middleware guarding a route, outermost first.
+// Every request of a signed-in user takes from the users bucket as well as from the bucket of the route's rate class.
+func (p Policies) Chain(r Route) []echo.MiddlewareFunc {
+	if !r.signedIn() {
+		chain := p.limit(r, nil)
+		if r.Cached {
+			chain = append(chain, p.crawlerGuard.Cache)
+		}
+		if r.Auth == OptionalAuth {
+			chain = append(chain, m.OptionalJWT(p.authService, p.userService), p.impersonation(r))
+		}
+		return chain
+	}
+
+	chain := []echo.MiddlewareFunc{m.JWT(p.authService, p.userService), m.CheckBanAt(p.clock)}
+
+	if r.PasswordReset {
+		chain = append(chain, m.CheckPasswordReset(r.Path))
+	} else {
+		chain = append(chain, m.CheckPasswordReset())
+	}
+
+	chain = append(chain, p.impersonation(r))
+
+	if r.Auth == GuestAllowed {
+		chain = append(chain, m.RestrictGuests(p.clock, r.key()))
+	} else {
+		chain = append(chain, m.RestrictGuests(p.clock))
+	}
+
+	if r.Permission != "" {
+		chain = append(chain, m.RequirePermission(p.roleService, r.Permission))
+	}
+
+	if p.rates.Users > 0 {
+		chain = append(chain, p.rateLimiter.Limit("users", p.rates.Users))
+	}
+
+	return p.limit(r, chain)
+}
+
+// impersonation returns the middleware recording the requests of impersonators to the route.
+func (p Policies) impersonation(r Route) echo.MiddlewareFunc {
+	if r.NoImpersonation {
+		return m.Impersonation(p.auditService, r.key())
+	}
+	return m.Impersonation(p.auditService)
+}
+
+// limit appends the limiter of the route's rate class to the chain. Polled, sensitive and costly routes get buckets
+// of their own, auth routes share theirs. Reads of routes not limited otherwise share the reads bucket when it's enabled.
+func (p Policies) limit(r Route, chain []echo.MiddlewareFunc) []echo.MiddlewareFunc {
+	switch r.Rate {
+	case Shed:
+		chain = append(chain, p.loadShedder.Shed)
+	case Signup:
+		return append(chain, p.signupGuard.Middleware)
+	case Auth:
+		return append(chain, p.rateLimiter.Limit("auth", p.rates.Auth))
+	case Polled:
+		return append(chain, p.rateLimiter.Limit(r.key(), p.rates.Polled))
+	case Sensitive:
+		return append(chain, p.rateLimiter.Limit(r.key(), p.rates.Sensitive))
+	case Costly:
+		return append(chain, p.rateLimiter.Limit(r.key(), p.rates.Costly))
+	}
+
+	if r.Method == http.MethodGet && p.rates.Reads > 0 {
+		chain = append(chain, p.rateLimiter.Limit("reads", p.rates.Reads))
+	}
+	return chain
+}
+
+// Register adds the routes to the server, each guarded by the chain of its policy.
+func (p Policies) Register(e *echo.Echo, routes []Route) {
+	for _, r := range routes {
+		e.Add(r.Method, r.Path, r.Handler, p.Chain(r)...)
+	}
+}
+
+// readOnlyAllowed returns the routes served in read-only mode despite their method.
+func readOnlyAllowed(routes []Route) []string {
+	allowed := make([]string, 0)
+	for _, r := range routes {
+		if r.ReadOnlyAllowed {
+			allowed = append(allowed, r.key())
+		}
+	}
+	return allowed
+}
+
+// writeRoutes returns the routes turned away in read-only mode despite their safe method.
+func writeRoutes(routes []Route) []string {
+	writes := make([]string, 0)
+	for _, r := range routes {
+		if r.Writes {
+			writes = append(writes, r.key())
+		}
+	}
+	return writes
+}
@@ -0,0 +1,267 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	m "NodeTurtleAPI/internal/api/middleware"
+	"NodeTurtleAPI/internal/clock"
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/services/auth"
+	"NodeTurtleAPI/internal/utils"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRouteTable(t *testing.T) {
+	routes := routeTable(routeHandlers{})
+
+	seen := map[string]bool{}
+	for _, r := range routes {
+		assert.False(t, seen[r.key()], "%s is declared twice", r.key())
+		seen[r.key()] = true
+
+		assert.NotNil(t, r.Handler, r.key())
+		if r.Permission != "" || r.PasswordReset || r.NoImpersonation {
+			assert.True(t, r.signedIn(), "%s guards a session it doesn't require", r.key())
+		}
+	}
+
+	assert.ElementsMatch(t, []string{"PUT /api/admin/system/read-only", "POST /api/projects/batch-get"}, readOnlyAllowed(routes))
+	assert.ElementsMatch(t, []string{
+		"GET /api/email/unsubscribe/:token",
+		"GET /api/auth/magic/:token",
+		"GET /api/auth/oauth/:provider",
+		"GET /api/auth/oauth/:provider/callback",
+	}, writeRoutes(routes))
+}
+
+func TestPoliciesChain(t *testing.T) {
+	p := Policies{clock: clock.System}
+
+	tests := map[string]struct {
+		route    Route
+		user     *data.User
+		wantLen  int
+		wantCode int
+	}{
+		"Public route": {
+			route:   Route{Method: http.MethodGet, Path: "/api/flags"},
+			wantLen: 0,
+		},
+		"Cached optional session, shed under load": {
+			route:   Route{Method: http.MethodGet, Path: "/api/projects/:id/forks", Auth: OptionalAuth, Rate: Shed, Cached: true},
+			wantLen: 4,
+		},
+		"Rate limited public route": {
+			route:   Route{Method: http.MethodPost, Path: "/api/auth/magic-link", Rate: Sensitive},
+			wantLen: 1,
+		},
+		"Auth route sharing the auth bucket": {
+			route:   Route{Method: http.MethodPost, Path: "/api/auth/session", Rate: Auth},
+			wantLen: 1,
+		},
+		"Guest on a guest route": {
+			route:    Route{Method: http.MethodPost, Path: "/api/projects", Auth: GuestAllowed},
+			user:     &data.User{ID: uuid.New(), GuestExpiresAt: utils.Ptr(time.Now().Add(time.Hour))},
+			wantLen:  5,
+			wantCode: http.StatusOK,
+		},
+		"Guest on a registered route": {
+			route:    Route{Method: http.MethodPost, Path: "/api/classrooms", Auth: Registered},
+			user:     &data.User{ID: uuid.New(), GuestExpiresAt: utils.Ptr(time.Now().Add(time.Hour))},
+			wantLen:  5,
+			wantCode: http.StatusForbidden,
+		},
+		"Pending password reset on an allowed route": {
+			route:    Route{Method: http.MethodPut, Path: "/api/users/me/password", Auth: Registered, PasswordReset: true},
+			user:     &data.User{ID: uuid.New(), PasswordResetRequired: true},
+			wantLen:  5,
+			wantCode: http.StatusOK,
+		},
+		"Pending password reset elsewhere": {
+			route:    Route{Method: http.MethodGet, Path: "/api/users/me/quota", Auth: Registered},
+			user:     &data.User{ID: uuid.New(), PasswordResetRequired: true},
+			wantLen:  5,
+			wantCode: http.StatusForbidden,
+		},
+		"Permission and rate limit": {
+			route:   Route{Method: http.MethodPost, Path: "/api/admin/dumps", Auth: Registered, Permission: data.PermDumpsGenerate, Rate: Costly},
+			wantLen: 7,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			chain := p.Chain(tt.route)
+			assert.Len(t, chain, tt.wantLen)
+
+			if tt.user == nil {
+				return
+			}
+
+			e := echo.New()
+			req := httptest.NewRequest(tt.route.Method, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath(tt.route.Path)
+			c.Set("user", tt.user)
+
+			// the session is read by the first middleware, the user is already set
+			h := func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			}
+			for i := len(chain) - 1; i > 0; i-- {
+				h = chain[i](h)
+			}
+
+			err := h(c)
+			if tt.wantCode == http.StatusOK {
+				assert.NoError(t, err)
+				assert.Equal(t, http.StatusOK, rec.Code)
+			} else if assert.Error(t, err) {
+				assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+			}
+		})
+	}
+}
+
+func TestPasswordResetOnFirstLogin(t *testing.T) {
+	p := Policies{clock: clock.System}
+
+	// provisioned accounts sign in with a generated password they have to replace first
+	user := &data.User{ID: uuid.New(), IsActivated: true, PasswordResetRequired: true}
+
+	serve := func(r Route) error {
+		e := echo.New()
+		c := e.NewContext(httptest.NewRequest(r.Method, "/", nil), httptest.NewRecorder())
+		c.SetPath(r.Path)
+		c.Set("user", user)
+
+		// the session is read by the first middleware, CheckBan and CheckPasswordReset follow
+		chain := p.Chain(r)
+		h := func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		}
+		for i := 2; i > 0; i-- {
+			h = chain[i](h)
+		}
+		return h(c)
+	}
+
+	for _, r := range routeTable(routeHandlers{}) {
+		if !r.signedIn() {
+			continue
+		}
+
+		err := serve(r)
+		if r.PasswordReset {
+			assert.NoError(t, err, r.key())
+		} else if assert.Error(t, err, r.key()) {
+			assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code, r.key())
+			assert.Equal(t, "PASSWORD_RESET_REQUIRED", err.(*echo.HTTPError).Message, r.key())
+		}
+	}
+
+	user.PasswordResetRequired = false
+	for _, r := range routeTable(routeHandlers{}) {
+		if r.signedIn() {
+			assert.NoError(t, serve(r), r.key())
+		}
+	}
+}
+
+func TestPoliciesReadsLimit(t *testing.T) {
+	p := Policies{
+		rateLimiter: m.NewRateLimiter(m.NewMemoryRateStore()),
+		rates:       config.RateLimitsConfig{Reads: 60},
+	}
+
+	// reads not limited otherwise share the reads bucket, writes and limited reads don't
+	assert.Len(t, p.Chain(Route{Method: http.MethodGet, Path: "/api/flags"}), 1)
+	assert.Len(t, p.Chain(Route{Method: http.MethodPost, Path: "/api/projects/batch-get"}), 0)
+	assert.Len(t, p.Chain(Route{Method: http.MethodGet, Path: "/api/auth/magic/:token", Rate: Auth}), 1)
+}
+
+func TestPoliciesUsersLimit(t *testing.T) {
+	p := Policies{
+		rateLimiter: m.NewRateLimiter(m.NewMemoryRateStore()),
+		rates:       config.RateLimitsConfig{Users: 6},
+	}
+
+	// signed-in routes of every rate class share the users bucket, public ones don't
+	assert.Len(t, p.Chain(Route{Method: http.MethodPost, Path: "/api/projects", Auth: GuestAllowed}), 6)
+	assert.Len(t, p.Chain(Route{Method: http.MethodPost, Path: "/api/admin/dumps", Auth: Registered, Rate: Costly}), 7)
+	assert.Len(t, p.Chain(Route{Method: http.MethodPost, Path: "/api/auth/magic-link", Rate: Sensitive}), 1)
+
+	user := &data.User{ID: uuid.New()}
+	serve := func(r Route) *httptest.ResponseRecorder {
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(r.Method, "/", nil), rec)
+		c.Set("user", user)
+
+		// the users bucket is the last middleware of routes without a rate class
+		chain := p.Chain(r)
+		err := chain[len(chain)-1](func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		})(c)
+		if err != nil {
+			c.Error(err)
+		}
+		return rec
+	}
+
+	// a limit of 6 per minute allows a burst of a single request, whichever route it's made to
+	rec := serve(Route{Method: http.MethodPost, Path: "/api/projects", Auth: GuestAllowed})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "6", rec.Header().Get("RateLimit-Limit"))
+
+	rec = serve(Route{Method: http.MethodPatch, Path: "/api/users/me", Auth: Registered})
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+}
+
+func TestPoliciesOptionalAuthImpersonation(t *testing.T) {
+	mockAuth := new(mocks.MockAuthService)
+	mockUser := new(mocks.MockUserService)
+	mockAudit := new(mocks.MockAuditService)
+	p := Policies{authService: mockAuth, userService: mockUser, auditService: mockAudit, clock: clock.System}
+
+	userID := uuid.New()
+	impersonatorID := uuid.New()
+	mockAuth.On("VerifyToken", "impersonation-token").Return(&auth.Claims{
+		Impersonator:   impersonatorID.String(),
+		StandardClaims: jwt.StandardClaims{Subject: userID.String()},
+	}, nil)
+	mockUser.On("GetUserByID", userID).Return(&data.User{ID: userID}, nil)
+	mockAudit.On("Record", mock.MatchedBy(func(entry data.AuditEntry) bool {
+		return *entry.ActorID == impersonatorID && entry.Details["route"] == "GET /api/projects/:id"
+	})).Return(nil).Once()
+
+	// public routes reading the session record impersonated requests like signed-in ones
+	r := Route{Method: http.MethodGet, Path: "/api/projects/:id", Auth: OptionalAuth}
+	h := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+	chain := p.Chain(r)
+	for i := len(chain) - 1; i >= 0; i-- {
+		h = chain[i](h)
+	}
+
+	e := echo.New()
+	req := httptest.NewRequest(r.Method, "/", nil)
+	req.Header.Set("Authorization", "Bearer impersonation-token")
+	c := e.NewContext(req, httptest.NewRecorder())
+	c.SetPath(r.Path)
+
+	assert.NoError(t, h(c))
+	mockAudit.AssertExpectations(t)
+}
@@ -4,32 +4,90 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"time"
 
 	"NodeTurtleAPI/internal/api/handlers"
 	m "NodeTurtleAPI/internal/api/middleware"
+	"NodeTurtleAPI/internal/cache"
+	"NodeTurtleAPI/internal/clock"
 	"NodeTurtleAPI/internal/config"
 	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/database"
+	"NodeTurtleAPI/internal/ids"
+	"NodeTurtleAPI/internal/requestid"
+	"NodeTurtleAPI/internal/rpc"
+	"NodeTurtleAPI/internal/runner"
+	"NodeTurtleAPI/internal/scheduler"
 	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/annotations"
+	"NodeTurtleAPI/internal/services/announcements"
+	"NodeTurtleAPI/internal/services/anomalies"
+	"NodeTurtleAPI/internal/services/audit"
 	"NodeTurtleAPI/internal/services/auth"
+	"NodeTurtleAPI/internal/services/avatars"
+	"NodeTurtleAPI/internal/services/classrooms"
+	"NodeTurtleAPI/internal/services/consents"
+	"NodeTurtleAPI/internal/services/credits"
+	"NodeTurtleAPI/internal/services/deletions"
+	"NodeTurtleAPI/internal/services/dumps"
+	"NodeTurtleAPI/internal/services/exports"
+	"NodeTurtleAPI/internal/services/featured"
+	"NodeTurtleAPI/internal/services/flags"
+	"NodeTurtleAPI/internal/services/folders"
+	"NodeTurtleAPI/internal/services/guests"
+	"NodeTurtleAPI/internal/services/imports"
+	"NodeTurtleAPI/internal/services/jobs"
+	"NodeTurtleAPI/internal/services/locations"
+	"NodeTurtleAPI/internal/services/lti"
 	"NodeTurtleAPI/internal/services/mail"
+	"NodeTurtleAPI/internal/services/notifications"
+	"NodeTurtleAPI/internal/services/passwords"
+	"NodeTurtleAPI/internal/services/profiles"
 	"NodeTurtleAPI/internal/services/projects"
+	"NodeTurtleAPI/internal/services/realtime"
+	"NodeTurtleAPI/internal/services/renders"
+	"NodeTurtleAPI/internal/services/reports"
+	"NodeTurtleAPI/internal/services/retention"
+	"NodeTurtleAPI/internal/services/roles"
+	"NodeTurtleAPI/internal/services/runs"
+	"NodeTurtleAPI/internal/services/signups"
+	"NodeTurtleAPI/internal/services/system"
+	"NodeTurtleAPI/internal/services/tasks"
+	"NodeTurtleAPI/internal/services/thumbnails"
 	"NodeTurtleAPI/internal/services/tokens"
 	"NodeTurtleAPI/internal/services/users"
+	"NodeTurtleAPI/internal/services/verification"
+	"NodeTurtleAPI/internal/services/webhooks"
+	"NodeTurtleAPI/internal/storage"
 
 	gomail "net/mail"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"google.golang.org/grpc"
 )
 
 type Server struct {
-	echo   *echo.Echo
-	config *config.Config
-	db     *sql.DB
+	echo         *echo.Echo
+	config       *config.Config
+	db           *sql.DB
+	mirror       *database.Mirror
+	stopMirror   context.CancelFunc
+	scheduler    *scheduler.Scheduler
+	tasks        *runner.Runner
+	caches       *cache.Registry
+	stopCaches   context.CancelFunc
+	realtime     *realtime.RealtimeService
+	stopRealtime context.CancelFunc
+	grpc         *grpc.Server // nil unless GRPC_ADDR is set
+	rateStore    m.RateStore
+	logger       *slog.Logger
 }
 
 type CustomValidator struct {
@@ -46,55 +104,387 @@ func emailValidation(fl validator.FieldLevel) bool {
 	return err == nil
 }
 
-func NewServer(cfg *config.Config, db *sql.DB) *Server {
+// NewServer creates the API server on the connection pool db, which mirror switches to a replica in read-only mode.
+// Requests are logged to logger.
+func NewServer(cfg *config.Config, db *sql.DB, mirror *database.Mirror, logger *slog.Logger) (*Server, error) {
 	e := echo.New()
 
 	e.Debug = cfg.Env == "DEV"
 
+	// rate limits, bans and throttles are keyed by the client IP, which only trusted proxies may forward
+	ipExtractor, err := m.IPExtractor(cfg.Server.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	e.IPExtractor = ipExtractor
+
 	// validator setup
 	v := validator.New()
 	v.RegisterValidation("email", emailValidation)
 	e.Validator = &CustomValidator{validator: v}
 
+	// cached data is shared by the services reading it on hot paths
+	caches := cache.NewRegistry(db, time.Duration(cfg.Cache.TTL)*time.Second)
+
 	// setup services
-	mailService := mail.NewMailService(cfg.Mail)
-	authService := auth.NewService(db, cfg.JWT)
+	mailService := mail.NewMailService(db, cfg.Mail)
+	authService, err := auth.NewService(db, cfg.JWT)
+	if err != nil {
+		return nil, fmt.Errorf("could not load JWT signing keys: %w", err)
+	}
+	oauthService := auth.NewOAuthService(db, cfg.OAuth)
 	userService := users.NewUserService(db)
-	tokenService := tokens.NewTokenService(db)
+	tokenService := tokens.NewTokenService(db, cfg.Tokens, clock.System)
 	banService := services.NewBanService(db)
-	projectService := projects.NewProjectService(db)
+	projectService := projects.NewProjectService(db, ids.ForVersion(cfg.IDs.Version), cfg.Quotas)
+	classroomService := classrooms.NewClassroomService(db)
+	featuredService := featured.NewFeaturedService(db, cfg.Featured, clock.System)
+	dumpService := dumps.NewDumpService(db, cfg.Dumps)
+	locationService := locations.NewLocationService(db, cfg.Login)
+	roleService := roles.NewRoleService(db)
+	webhookService := webhooks.NewWebhookService(db, cfg.Webhooks)
+	jobService := jobs.NewJobService(db)
+	taskService := tasks.NewTaskService(db)
+	flagService := flags.NewFlagService(db, caches)
+	announcementService := announcements.NewAnnouncementService(db, caches)
+	auditService := audit.NewAuditService(db)
+	annotationService := annotations.NewAnnotationService(db)
+	verificationService := verification.NewVerificationService(db)
+	reportService := reports.NewReportService(db)
+	consentService := consents.NewConsentService(db)
+	notificationService := notifications.NewNotificationService(db, &mailService)
+	profileService := profiles.NewProfileService(db)
+	objectStorage, err := storage.New(cfg.Storage)
+	if err != nil {
+		return nil, err
+	}
+	avatarService := avatars.NewAvatarService(db, objectStorage, cfg.Avatars)
+	exportService := exports.NewExportService(db, objectStorage, &mailService, cfg.Exports)
+	deletionService := deletions.NewDeletionService(db, objectStorage, cfg.Deletions)
+	retentionService := retention.NewRetentionService(db, cfg.Retention, cfg.Partitions)
+	creditService := credits.NewCreditService(db)
+	folderService := folders.NewFolderService(db)
+	guestService := guests.NewGuestService(db, cfg.Guests.TTL)
+	signupService := signups.NewSignupService(db)
+	systemService := system.NewSystemService(db)
+	thumbnailService := thumbnails.NewThumbnailService(db, cfg.Render)
+	runService := runs.NewRunService(db, cfg.Quotas, cfg.Render)
+	renderService := renders.NewRenderService(db)
+	realtimeService := realtime.NewRealtimeService(db)
+	importService := imports.NewImportService(db, cfg.Imports.UploadTTL)
+	anomalyCounters := anomalies.NewCounters(time.Duration(cfg.Anomalies.Interval)*time.Minute, clock.System)
+	anomalyService := anomalies.NewAnomalyService(db, cfg.Anomalies, anomalyCounters, &mailService, clock.System)
+	passwordService, err := passwords.NewPasswordService(cfg.Passwords)
+	if err != nil {
+		return nil, err
+	}
 
 	// setup handlers
-	authHandler := handlers.NewAuthHandler(&authService, &userService, &tokenService, &mailService)
-	userHandler := handlers.NewUserHandler(&userService, &authService, &tokenService, &banService, &mailService)
-	tokenHandler := handlers.NewTokenHandler(&userService, &tokenService, &mailService)
-	projectHandler := handlers.NewProjectHandler(&projectService)
+	authHandler := handlers.NewAuthHandler(&authService, &oauthService, &userService, &tokenService, &mailService, &locationService, cfg.Mail.ClientURL, cfg.Login, &passwordService, &auditService)
+	userHandler := handlers.NewUserHandler(&userService, &authService, &tokenService, &banService, &mailService, &passwordService, &auditService)
+	tokenHandler := handlers.NewTokenHandler(&userService, &tokenService, &mailService, &passwordService, &auditService)
+	projectHandler := handlers.NewProjectHandler(&projectService, &classroomService, realtimeService, &notificationService, cfg.Limits, cfg.Discover, cfg.Mail.ClientURL)
+	classroomHandler := handlers.NewClassroomHandler(&classroomService)
+	featuredHandler := handlers.NewFeaturedHandler(&featuredService, &auditService)
+	dumpHandler := handlers.NewDumpHandler(&dumpService)
+	roleHandler := handlers.NewRoleHandler(&roleService)
+	webhookHandler := handlers.NewWebhookHandler(&webhookService, &projectService)
+	jobHandler := handlers.NewJobHandler(&jobService)
+	flagHandler := handlers.NewFlagHandler(&flagService)
+	announcementHandler := handlers.NewAnnouncementHandler(&announcementService)
+	impersonationHandler := handlers.NewImpersonationHandler(&userService, &authService, &auditService)
+	embedHandler := handlers.NewEmbedHandler(&projectService, &authService)
+	annotationHandler := handlers.NewAnnotationHandler(&annotationService, &auditService)
+	verificationHandler := handlers.NewVerificationHandler(&verificationService, &auditService)
+	creditHandler := handlers.NewCreditHandler(&creditService, &projectService, &userService)
+	folderHandler := handlers.NewFolderHandler(&folderService)
+	revisionHandler := handlers.NewRevisionHandler(&projectService)
+	guestHandler := handlers.NewGuestHandler(&guestService, &authService, &tokenService, &mailService, &passwordService)
+	signupHandler := handlers.NewSignupHandler(&signupService, &auditService)
+	systemHandler := handlers.NewSystemHandler(&systemService)
+	thumbnailHandler := handlers.NewThumbnailHandler(&projectService, &thumbnailService)
+	realtimeHandler := handlers.NewRealtimeHandler(realtimeService)
+	importHandler := handlers.NewImportHandler(&projectService, &importService, cfg.Limits, cfg.Imports)
+	compactionHandler := handlers.NewCompactionHandler(&projectService, &auditService, cfg.Compaction)
+	integrityHandler := handlers.NewIntegrityHandler(&projectService, cfg.Integrity)
+	reportHandler := handlers.NewReportHandler(&reportService, &projectService, &mailService, &auditService)
+	consentHandler := handlers.NewConsentHandler(&consentService)
+	notificationHandler := handlers.NewNotificationHandler(&notificationService, &consentService)
+	retentionHandler := handlers.NewRetentionHandler(&retentionService, &auditService)
+	readOnlyHandler := handlers.NewReadOnlyHandler(mirror.Status, mirror.SetReadOnly, &auditService)
+	capabilitiesHandler := handlers.NewCapabilitiesHandler(cfg.Limits, cfg.Quotas, cfg.Imports, mirror.ReadOnly)
+	emailHandler := handlers.NewEmailHandler(&mailService)
+	profileHandler := handlers.NewProfileHandler(&profileService)
+	avatarHandler := handlers.NewAvatarHandler(&avatarService, cfg.Avatars)
+	exportHandler := handlers.NewExportHandler(&exportService, cfg.Exports)
+	renderHandler := handlers.NewRenderHandler(&renderService)
+	deletionHandler := handlers.NewDeletionHandler(&userService, &tokenService, &deletionService, &mailService)
+	historyHandler := handlers.NewHistoryHandler(&auditService)
+	auditHandler := handlers.NewAuditHandler(&auditService)
+	alertHandler := handlers.NewAlertHandler(&anomalyService)
+	taskHandler := handlers.NewTaskHandler(&taskService)
+	ipBanGuard := m.NewIPBanGuard(&banService)
+	banHandler := handlers.NewBanHandler(&userService, &banService, &mailService, &auditService, ipBanGuard.Invalidate)
+	runHandler := handlers.NewRunHandler(&projectService, &runService)
+
+	probeGuard := m.NewProbeGuard(cfg.Probes)
+	crawlerGuard := m.NewCrawlerGuard(cfg.Crawler)
+	signupGuard := m.NewSignupGuard(cfg.Signups, &signupService)
+	loadShedder := m.NewLoadShedder(cfg.Shedding, &flagService, db.Stats)
+	metricsHandler := handlers.NewMetricsHandler(crawlerGuard.Metrics, caches.Metrics, signupGuard.Metrics, loadShedder.Metrics, projectService.IntegrityMetrics, probeGuard.Metrics)
+
+	rateStore, err := m.NewRateStore(cfg.RateLimits)
+	if err != nil {
+		return nil, err
+	}
+	rateLimiter := m.NewRateLimiter(rateStore)
+
+	policies := NewPolicies(&authService, &userService, &roleService, &auditService, crawlerGuard, signupGuard, loadShedder, rateLimiter, cfg.RateLimits, clock.System)
+	routes := routeTable(routeHandlers{
+		auth:          &authHandler,
+		user:          &userHandler,
+		token:         &tokenHandler,
+		project:       &projectHandler,
+		classroom:     &classroomHandler,
+		featured:      &featuredHandler,
+		dump:          &dumpHandler,
+		metrics:       &metricsHandler,
+		role:          &roleHandler,
+		webhook:       &webhookHandler,
+		job:           &jobHandler,
+		flag:          &flagHandler,
+		announcement:  &announcementHandler,
+		impersonation: &impersonationHandler,
+		embed:         &embedHandler,
+		annotation:    &annotationHandler,
+		verification:  &verificationHandler,
+		credit:        &creditHandler,
+		folder:        &folderHandler,
+		revision:      &revisionHandler,
+		guest:         &guestHandler,
+		signup:        &signupHandler,
+		system:        &systemHandler,
+		thumbnail:     &thumbnailHandler,
+		realtime:      &realtimeHandler,
+		imports:       &importHandler,
+		compaction:    &compactionHandler,
+		integrity:     &integrityHandler,
+		report:        &reportHandler,
+		consent:       &consentHandler,
+		notification:  &notificationHandler,
+		retention:     &retentionHandler,
+		readOnly:      &readOnlyHandler,
+		capabilities:  &capabilitiesHandler,
+		email:         &emailHandler,
+		profile:       &profileHandler,
+		avatar:        &avatarHandler,
+		export:        &exportHandler,
+		render:        &renderHandler,
+		deletion:      &deletionHandler,
+		ban:           &banHandler,
+		history:       &historyHandler,
+		audit:         &auditHandler,
+		alert:         &alertHandler,
+		task:          &taskHandler,
+		run:           &runHandler,
+		crawlerGuard:  crawlerGuard,
+	})
+
+	// Setup LMS integration if a tool key is provided
+	if cfg.LTI.PrivateKeyPath != "" {
+		routes = append(routes, ltiRoutes(db, cfg, &authService, &userService, &tokenService)...)
+	}
+
+	// queued emails are sent by the task runner, which claims tasks from the database and waits while it's read-only
+	taskRunner := runner.New(cfg.Tasks, &taskService)
+	taskRunner.SkipWhile(mirror.ReadOnly)
+	taskRunner.Handle(data.TaskEmail, mailService.SendEmailTask)
+
+	// setup background jobs, they all write and wait while the API is read-only
+	sched := scheduler.New(clock.System)
+	sched.SkipWhile(mirror.ReadOnly)
+	if cfg.Featured.RotationInterval > 0 {
+		sched.Every("featured-rotation", time.Duration(cfg.Featured.RotationInterval)*time.Minute, func(ctx context.Context) error {
+			rotation, err := featuredService.Rotate()
+			if err != nil {
+				return err
+			}
+			return featured.RecordRotation(&auditService, nil, "", rotation)
+		})
+	}
+	if cfg.Webhooks.CheckInterval > 0 {
+		sched.Every("project-webhooks", time.Duration(cfg.Webhooks.CheckInterval)*time.Minute, func(ctx context.Context) error {
+			_, err := webhookService.DeliverMilestones(ctx)
+			return err
+		})
+	}
+	if cfg.Anomalies.Interval > 0 {
+		sched.Every("anomaly-analysis", time.Duration(cfg.Anomalies.Interval)*time.Minute, func(ctx context.Context) error {
+			_, err := anomalyService.Analyze(ctx)
+			return err
+		})
+	}
+	if cfg.Notifications.DigestInterval > 0 {
+		sched.Every("notification-digests", time.Duration(cfg.Notifications.DigestInterval)*time.Minute, func(ctx context.Context) error {
+			_, err := notificationService.SendDigests(ctx)
+			return err
+		})
+	}
+	if cfg.Database.OnlineMigrationsInterval > 0 {
+		sched.Every("online-migrations", time.Duration(cfg.Database.OnlineMigrationsInterval)*time.Minute, func(ctx context.Context) error {
+			return database.RunOnlineMigrations(ctx, db, database.OnlineMigrations)
+		})
+	}
+	if cfg.Partitions.Interval > 0 {
+		partitioned := []database.PartitionedTable{
+			{Name: "events", Retention: cfg.Partitions.EventsRetention},
+			{Name: "audit_logs", Retention: cfg.Partitions.AuditRetention},
+			{Name: "notifications", Retention: cfg.Partitions.NotificationsRetention},
+		}
+		sched.Every("partition-maintenance", time.Duration(cfg.Partitions.Interval)*time.Hour, func(ctx context.Context) error {
+			return database.MaintainPartitions(ctx, db, partitioned, cfg.Partitions.Ahead, time.Now())
+		})
+	}
+	if cfg.Guests.CleanupInterval > 0 {
+		sched.Every("guest-cleanup", time.Duration(cfg.Guests.CleanupInterval)*time.Minute, func(ctx context.Context) error {
+			_, err := guestService.DeleteExpiredGuests(ctx)
+			return err
+		})
+	}
+	if cfg.Imports.CleanupInterval > 0 {
+		sched.Every("upload-cleanup", time.Duration(cfg.Imports.CleanupInterval)*time.Minute, func(ctx context.Context) error {
+			_, err := importService.DeleteExpiredUploads(ctx)
+			return err
+		})
+	}
+	if cfg.Exports.Interval > 0 {
+		sched.Every("data-exports", time.Duration(cfg.Exports.Interval)*time.Minute, func(ctx context.Context) error {
+			if _, err := exportService.PreparePending(ctx); err != nil {
+				return err
+			}
+			_, err := exportService.DeleteExpired(ctx)
+			return err
+		})
+	}
+	if cfg.Deletions.Interval > 0 {
+		sched.Every("account-deletions", time.Duration(cfg.Deletions.Interval)*time.Minute, func(ctx context.Context) error {
+			_, err := deletionService.DeleteDue(ctx)
+			return err
+		})
+	}
+	if cfg.Compaction.Interval > 0 {
+		sched.Every("project-compaction", time.Duration(cfg.Compaction.Interval)*time.Hour, func(ctx context.Context) error {
+			_, err := projectService.CompactProjectData(ctx, data.CompactionOptions{
+				BatchSize: cfg.Compaction.BatchSize,
+				Pause:     cfg.Compaction.Pause,
+			})
+			return err
+		})
+	}
+	if cfg.Integrity.Interval > 0 {
+		sched.Every("project-integrity-scan", time.Duration(cfg.Integrity.Interval)*time.Hour, func(ctx context.Context) error {
+			_, err := projectService.ScanDataIntegrity(ctx, data.IntegrityScanOptions{
+				BatchSize: cfg.Integrity.BatchSize,
+				Pause:     cfg.Integrity.Pause,
+			})
+			return err
+		})
+	}
+	if cfg.Retention.Interval > 0 {
+		sched.Every("retention-purge", time.Duration(cfg.Retention.Interval)*time.Hour, func(ctx context.Context) error {
+			_, err := retentionService.Purge(ctx, data.RetentionScheduled, nil)
+			return err
+		})
+	}
+	if cfg.Dumps.Interval > 0 {
+		sched.Every("public-data-dump", time.Duration(cfg.Dumps.Interval)*time.Hour, func(ctx context.Context) error {
+			_, err := dumpService.Generate()
+			return err
+		})
+	}
 
 	// setup middleware
-	e.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
-		Format: "ip:${remote_ip} method:${method}, uri:${uri}, status:${status}, error:${error}\n",
+	// the request ID is returned in X-Request-ID and carried by the request context, so emails sent for a request and
+	// its log lines can be traced back to it; an ID set by a proxy in front of the API is kept
+	e.Use(m.SanitizeRequestID)
+	e.Use(middleware.RequestIDWithConfig(middleware.RequestIDConfig{
+		RequestIDHandler: func(c echo.Context, id string) {
+			c.SetRequest(c.Request().WithContext(requestid.NewContext(c.Request().Context(), id)))
+		},
 	}))
+	e.Use(m.RequestLogger(logger))
 	e.Use(middleware.Recover())
+	if cfg.Server.BodyLimit != "" {
+		e.Use(middleware.BodyLimit(cfg.Server.BodyLimit))
+	}
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins:     cfg.Server.AllowOrigins,
 		AllowCredentials: true,
+		// throttled clients read the wait from Retry-After to show a countdown and their budget from RateLimit-*,
+		// uploads resume from Upload-Offset, the editor sends the ETag of a project back on updates,
+		// and X-Request-ID is quoted to support
+		ExposeHeaders: []string{"Retry-After", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Upload-Offset", "ETag", echo.HeaderXRequestID},
 	}))
+	e.Use(ipBanGuard.Middleware)
+	// probes are identified first, the guards after it let them through
+	e.Use(probeGuard.Middleware)
+	e.Use(crawlerGuard.Middleware)
+	if cfg.Anomalies.Interval > 0 {
+		e.Use(m.TrackAnomalies(anomalyCounters, cfg.Anomalies.ASNHeader, likePath))
+	}
+	e.Use(loadShedder.Track(eventStreamPath))
+	e.Use(m.ReadOnly(mirror.Status, readOnlyAllowed(routes), writeRoutes(routes)))
+	// cancels the request context after the write timeout, aborting database work nobody waits for anymore
+	if cfg.Server.WriteTimeout > 0 {
+		e.Use(middleware.ContextTimeoutWithConfig(middleware.ContextTimeoutConfig{
+			Timeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
+			// event streams stay open for as long as the client listens
+			Skipper: func(c echo.Context) bool {
+				return c.Path() == eventStreamPath
+			},
+		}))
+	}
 
 	// Setup API routes
-	setupRoutes(e, &authHandler, &userHandler, &tokenHandler, &projectHandler, &authService, &userService)
+	policies.Register(e, routes)
+
+	// Setup the test mailbox if emails are captured instead of sent
+	if mailbox := mailService.Mailbox(); mailbox != nil {
+		setupMailbox(e, policies, mailbox)
+	}
 
 	// Setup frontend serving if path is provided
 	if cfg.Server.FrontendPath != "" {
 		setupClient(e, cfg.Server.FrontendPath)
 	}
 
-	return &Server{
-		echo:   e,
-		config: cfg,
-		db:     db,
+	// internal consumers share the services with the REST API
+	var grpcServer *grpc.Server
+	if cfg.GRPC.Addr != "" {
+		grpcServer = rpc.NewServer(cfg.GRPC.Token, &projectService, &userService, &thumbnailService)
 	}
+
+	return &Server{
+		echo:      e,
+		config:    cfg,
+		db:        db,
+		mirror:    mirror,
+		scheduler: sched,
+		tasks:     taskRunner,
+		caches:    caches,
+		realtime:  realtimeService,
+		grpc:      grpcServer,
+		rateStore: rateStore,
+		logger:    logger,
+	}, nil
 }
 
+// eventStreamPath is the route of the realtime event stream.
+const eventStreamPath = "/api/users/me/events"
+
+// likePath is the route liking projects, whose traffic is analyzed for anomalies.
+const likePath = "/api/projects/:id/likes"
+
 func setupClient(e *echo.Echo, frontendPath string) {
 	// Resolve to absolute path
 	absPath, err := filepath.Abs(frontendPath)
@@ -124,64 +514,107 @@ func setupClient(e *echo.Echo, frontendPath string) {
 	})
 }
 
-func setupRoutes(e *echo.Echo, authHandler *handlers.AuthHandler, userHandler *handlers.UserHandler, tokenHandler *handlers.TokenHandler, projectHandler *handlers.ProjectHandler, authService *auth.AuthService, userService *users.UserService) {
-
-	// Public routes
-	e.GET("/api/projects/public", projectHandler.GetPublic)
-	e.GET("/api/projects/featured", projectHandler.GetFeatured)
-	e.GET("/api/projects/:id", projectHandler.Get, m.OptionalJWT(authService, userService))
-
-	e.POST("/api/users", authHandler.Register)
-	e.GET("/api/users/username/:username", userHandler.CheckUsername)
-	e.GET("/api/users/email/:email", userHandler.CheckEmail)
-
-	e.POST("/api/auth/activate", tokenHandler.RequestActivationToken)
-	e.POST("/api/users/activate/:token", tokenHandler.ActivateAccount)
-	e.POST("/api/auth/session", authHandler.Login)
-	e.POST("/api/auth/refresh", authHandler.RefreshToken)
-	e.POST("/api/auth/deactivate/:token", userHandler.Deactivate)
-
-	e.POST("/api/password/request-reset", tokenHandler.RequestPasswordReset)
-	e.PUT("/api/password/reset/:token", tokenHandler.ResetPassword)
-
-	// Protected routes - requires authentication
-	api := e.Group("/api")
-	api.Use(m.JWT(authService, userService))
-	api.Use(m.CheckBan)
-
-	api.DELETE("/auth/session", authHandler.Logout)
-	api.GET("/users/me", userHandler.GetCurrent)
-	api.PATCH("/users/me", userHandler.UpdateCurrent)
-	api.PUT("/users/me/password", userHandler.ChangePassword)
-	api.POST("/users/me/deactivate", tokenHandler.RequestDeactivationToken)
-
-	api.POST("/projects", projectHandler.Create)
-	api.POST("/projects/:id/likes", projectHandler.Like)
-	api.DELETE("/projects/:id/likes", projectHandler.Unlike)
-	api.GET("/users/:id/projects", projectHandler.GetUserProjects)
-	api.GET("/users/:id/liked-projects", projectHandler.GetLikedProjects)
-	api.DELETE("/projects/:id", projectHandler.Delete)
-	api.PATCH("/projects/:id", projectHandler.Update)
-
-	// Role-specific routes
-	admin := api.Group("/admin")
-	admin.Use(m.RequireRole(data.RoleAdmin.String()))
-	admin.GET("/users/all", userHandler.List)
-	admin.GET("/projects/all", projectHandler.List)
-	admin.GET("/users/:id", userHandler.Get)
-	admin.PUT("/users/:id", userHandler.Update)
-	admin.PATCH("/projects/:id", projectHandler.Feature)
-	admin.DELETE("/users/:id", userHandler.Delete)
-	admin.POST("/users/ban", userHandler.Ban)
-	admin.DELETE("/users/ban/:userID", userHandler.Unban)
+// setupMailbox registers the routes of the test mailbox. They're unauthenticated, end-to-end tests
+// read the emails of the accounts they create.
+func setupMailbox(e *echo.Echo, policies Policies, mailbox *mail.Mailbox) {
+	fmt.Println("Warning: emails are captured instead of sent, readable at /api/testing/mailbox")
+
+	mailboxHandler := handlers.NewMailboxHandler(mailbox)
+
+	policies.Register(e, []Route{
+		{Method: http.MethodGet, Path: "/api/testing/mailbox", Handler: mailboxHandler.List},
+		{Method: http.MethodDelete, Path: "/api/testing/mailbox", Handler: mailboxHandler.Clear},
+	})
+}
+
+// ltiRoutes returns the routes of the LMS integration, none if the tool key can't be loaded.
+func ltiRoutes(db *sql.DB, cfg *config.Config, authService *auth.AuthService, userService *users.UserService, tokenService *tokens.TokenService) []Route {
+	ltiService, err := lti.NewLTIService(db, cfg.LTI)
+	if err != nil {
+		fmt.Printf("Warning: LTI integration disabled: %v\n", err)
+		return nil
+	}
+
+	ltiHandler := handlers.NewLTIHandler(&ltiService, authService, userService, tokenService, cfg.Mail.ClientURL)
+
+	return []Route{
+		{Method: http.MethodGet, Path: "/api/lti/login", Handler: ltiHandler.Login, Writes: true},
+		{Method: http.MethodPost, Path: "/api/lti/login", Handler: ltiHandler.Login},
+		{Method: http.MethodPost, Path: "/api/lti/launch", Handler: ltiHandler.Launch},
+		{Method: http.MethodGet, Path: "/api/lti/jwks", Handler: ltiHandler.JWKS},
+
+		{Method: http.MethodPost, Path: "/api/lti/deep-links/:id", Handler: ltiHandler.DeepLink, Auth: Registered},
+		{Method: http.MethodPost, Path: "/api/lti/launches/:id/submission", Handler: ltiHandler.Submit, Auth: Registered},
+		{Method: http.MethodPost, Path: "/api/lti/links/:id", Handler: ltiHandler.ConfirmLink, Auth: Registered},
+
+		{Method: http.MethodGet, Path: "/api/admin/lti/platforms", Handler: ltiHandler.ListPlatforms, Auth: Registered, Permission: data.PermLTIManage},
+		{Method: http.MethodPost, Path: "/api/admin/lti/platforms", Handler: ltiHandler.RegisterPlatform, Auth: Registered, Permission: data.PermLTIManage},
+	}
 }
 
 func (s *Server) Start() error {
+	// read-only mode is switched for every instance through the primary database
+	mirrorCtx, stopMirror := context.WithCancel(context.Background())
+	s.stopMirror = stopMirror
+	go s.mirror.Sync(mirrorCtx)
+
+	s.scheduler.Start()
+	s.tasks.Start()
+
+	if s.config.Cache.TTL > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.stopCaches = cancel
+		go func() {
+			if err := s.caches.Listen(ctx, database.DSN(s.config.Database)); err != nil {
+				s.logger.Error("Cache invalidation listener stopped", "error", err)
+			}
+		}()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopRealtime = cancel
+	go func() {
+		if err := s.realtime.Listen(ctx, database.DSN(s.config.Database)); err != nil {
+			s.logger.Error("Realtime listener stopped", "error", err)
+		}
+	}()
+
+	if s.grpc != nil {
+		listener, err := net.Listen("tcp", s.config.GRPC.Addr)
+		if err != nil {
+			return fmt.Errorf("could not listen for gRPC on %s: %w", s.config.GRPC.Addr, err)
+		}
+		go func() {
+			if err := s.grpc.Serve(listener); err != nil {
+				s.logger.Error("gRPC server stopped", "error", err)
+			}
+		}()
+	}
+
 	return s.echo.Start(fmt.Sprintf("%s:%d", s.config.Server.Host, s.config.Server.Port))
 }
 
 func (s *Server) Shutdown() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	return s.echo.Shutdown(ctx)
+	s.scheduler.Stop()
+	s.tasks.Stop()
+	if s.stopMirror != nil {
+		s.stopMirror()
+	}
+	if s.stopCaches != nil {
+		s.stopCaches()
+	}
+	if s.stopRealtime != nil {
+		s.stopRealtime()
+	}
+	// open event streams would otherwise hold up the shutdown until the timeout
+	s.realtime.Close()
+	if s.grpc != nil {
+		s.grpc.GracefulStop()
+	}
+	err := s.echo.Shutdown(ctx)
+	// requests still being served are limited until the server is down
+	s.rateStore.Close()
+	return err
 }
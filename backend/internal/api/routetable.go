@@ -0,0 +1,296 @@
+package api
+
+import (
+	"net/http"
+
+	"NodeTurtleAPI/internal/api/handlers"
+	m "NodeTurtleAPI/internal/api/middleware"
+	"NodeTurtleAPI/internal/data"
+)
+
+// routeHandlers are the handlers serving the routes of the API.
+type routeHandlers struct {
+	auth          *handlers.AuthHandler
+	user          *handlers.UserHandler
+	token         *handlers.TokenHandler
+	project       *handlers.ProjectHandler
+	classroom     *handlers.ClassroomHandler
+	featured      *handlers.FeaturedHandler
+	dump          *handlers.DumpHandler
+	metrics       *handlers.MetricsHandler
+	role          *handlers.RoleHandler
+	webhook       *handlers.WebhookHandler
+	job           *handlers.JobHandler
+	flag          *handlers.FlagHandler
+	announcement  *handlers.AnnouncementHandler
+	impersonation *handlers.ImpersonationHandler
+	embed         *handlers.EmbedHandler
+	annotation    *handlers.AnnotationHandler
+	verification  *handlers.VerificationHandler
+	credit        *handlers.CreditHandler
+	folder        *handlers.FolderHandler
+	revision      *handlers.RevisionHandler
+	guest         *handlers.GuestHandler
+	signup        *handlers.SignupHandler
+	system        *handlers.SystemHandler
+	thumbnail     *handlers.ThumbnailHandler
+	realtime      *handlers.RealtimeHandler
+	imports       *handlers.ImportHandler
+	compaction    *handlers.CompactionHandler
+	integrity     *handlers.IntegrityHandler
+	report        *handlers.ReportHandler
+	consent       *handlers.ConsentHandler
+	notification  *handlers.NotificationHandler
+	retention     *handlers.RetentionHandler
+	readOnly      *handlers.ReadOnlyHandler
+	capabilities  *handlers.CapabilitiesHandler
+	email         *handlers.EmailHandler
+	profile       *handlers.ProfileHandler
+	avatar        *handlers.AvatarHandler
+	export        *handlers.ExportHandler
+	render        *handlers.RenderHandler
+	deletion      *handlers.DeletionHandler
+	ban           *handlers.BanHandler
+	history       *handlers.HistoryHandler
+	audit         *handlers.AuditHandler
+	alert         *handlers.AlertHandler
+	task          *handlers.TaskHandler
+	run           *handlers.RunHandler
+	crawlerGuard  *m.CrawlerGuard
+}
+
+// routeTable declares every route of the API and its policy.
+func routeTable(h routeHandlers) []Route {
+	return []Route{
+		{Method: http.MethodGet, Path: "/robots.txt", Handler: h.crawlerGuard.RobotsTxt},
+		// polled by load balancers and uptime checkers, which send the probe token to skip the limits
+		{Method: http.MethodGet, Path: "/api/health", Handler: h.system.Health},
+
+		// public listings are served cached to crawlers, the ones beyond the project itself are shed under load
+		{Method: http.MethodGet, Path: "/api/projects/public", Handler: h.project.GetPublic, Rate: Shed, Cached: true},
+		{Method: http.MethodGet, Path: "/api/projects/featured", Handler: h.project.GetFeatured, Cached: true},
+		{Method: http.MethodGet, Path: "/api/projects/discover", Handler: h.project.Discover, Auth: OptionalAuth, Rate: Shed},
+		{Method: http.MethodGet, Path: "/api/projects/:id", Handler: h.project.Get, Auth: OptionalAuth, Cached: true},
+		{Method: http.MethodPost, Path: "/api/projects/batch-get", Handler: h.project.BatchGet, Auth: OptionalAuth, ReadOnlyAllowed: true},
+		{Method: http.MethodGet, Path: "/api/projects/:id/forks", Handler: h.project.GetForks, Auth: OptionalAuth, Rate: Shed, Cached: true},
+		{Method: http.MethodGet, Path: "/api/projects/:id/likers", Handler: h.project.GetLikers, Auth: OptionalAuth, Rate: Shed, Cached: true},
+		{Method: http.MethodGet, Path: "/api/projects/:id/thumbnail", Handler: h.thumbnail.Get, Auth: OptionalAuth, Rate: Shed, Cached: true},
+		{Method: http.MethodGet, Path: "/api/projects/:id/credits", Handler: h.credit.List, Auth: OptionalAuth, Cached: true},
+		// public profiles, guests only see public projects
+		{Method: http.MethodGet, Path: "/api/users/:id/projects", Handler: h.project.GetUserProjects, Auth: OptionalAuth, Cached: true},
+		{Method: http.MethodGet, Path: "/api/users/:id/liked-projects", Handler: h.project.GetLikedProjects, Auth: OptionalAuth, Rate: Shed, Cached: true},
+		{Method: http.MethodGet, Path: "/api/users/:id/contributed-projects", Handler: h.project.GetContributedProjects, Auth: OptionalAuth, Rate: Shed, Cached: true},
+		{Method: http.MethodGet, Path: "/api/users/:username/profile", Handler: h.profile.Get, Cached: true},
+		{Method: http.MethodGet, Path: "/api/users/resolve/:username", Handler: h.user.Resolve, Cached: true},
+		{Method: http.MethodGet, Path: "/api/avatars/:userID/:name", Handler: h.avatar.Get, Cached: true},
+		// authorized by the embed token of the project instead of a session
+		{Method: http.MethodGet, Path: "/api/embed/projects/:id", Handler: h.embed.Get},
+		// authorized by the token of the emailed download link
+		{Method: http.MethodGet, Path: "/api/exports/:token", Handler: h.export.Download, Rate: Shed},
+
+		{Method: http.MethodGet, Path: "/api/flags", Handler: h.flag.GetEnabled},
+		{Method: http.MethodGet, Path: "/api/read-only", Handler: h.readOnly.Get},
+		{Method: http.MethodGet, Path: "/api/capabilities", Handler: h.capabilities.Get, Auth: OptionalAuth},
+		{Method: http.MethodGet, Path: "/api/announcements", Handler: h.announcement.List},
+		{Method: http.MethodGet, Path: "/api/dumps", Handler: h.dump.List},
+		{Method: http.MethodGet, Path: "/api/dumps/:filename", Handler: h.dump.Download, Rate: Shed},
+
+		{Method: http.MethodPost, Path: "/api/users", Handler: h.auth.Register, Rate: Signup},
+		{Method: http.MethodGet, Path: "/api/users/username/:username", Handler: h.user.CheckUsername},
+		{Method: http.MethodGet, Path: "/api/users/email/:email", Handler: h.user.CheckEmail},
+		// unsubscribe links of optional emails carry a signed token instead of a session
+		{Method: http.MethodGet, Path: "/api/email/unsubscribe/:token", Handler: h.email.Unsubscribe, Rate: Sensitive, Writes: true},
+		{Method: http.MethodPost, Path: "/api/email/unsubscribe/:token", Handler: h.email.Unsubscribe, Rate: Sensitive},
+
+		{Method: http.MethodPost, Path: "/api/auth/activate", Handler: h.token.RequestActivationToken},
+		{Method: http.MethodPost, Path: "/api/users/activate/:token", Handler: h.token.ActivateAccount},
+		// polled while the user confirms the email, limited so it can't be used to probe emails in bulk
+		{Method: http.MethodGet, Path: "/api/auth/activation-status", Handler: h.token.ActivationStatus, Rate: Polled},
+		{Method: http.MethodPost, Path: "/api/auth/session", Handler: h.auth.Login, Rate: Auth},
+		{Method: http.MethodPost, Path: "/api/auth/session/verify", Handler: h.auth.VerifyLogin, Rate: Sensitive},
+		{Method: http.MethodPost, Path: "/api/auth/magic-link", Handler: h.auth.RequestMagicLink, Rate: Sensitive},
+		{Method: http.MethodGet, Path: "/api/auth/magic/:token", Handler: h.auth.MagicLogin, Rate: Auth, Writes: true},
+		{Method: http.MethodPost, Path: "/api/auth/refresh", Handler: h.auth.RefreshToken, Rate: Auth},
+		{Method: http.MethodPost, Path: "/api/auth/guest", Handler: h.guest.Create, Rate: Costly},
+		{Method: http.MethodGet, Path: "/api/auth/jwks", Handler: h.auth.JWKS},
+		{Method: http.MethodGet, Path: "/api/auth/oauth", Handler: h.auth.OAuthProviders},
+		{Method: http.MethodGet, Path: "/api/auth/oauth/:provider", Handler: h.auth.OAuthLogin, Writes: true},
+		{Method: http.MethodGet, Path: "/api/auth/oauth/:provider/callback", Handler: h.auth.OAuthCallback, Rate: Auth, Writes: true},
+		{Method: http.MethodPost, Path: "/api/auth/deactivate/:token", Handler: h.deletion.Confirm},
+		// banned users can't sign in, the appeal checks the credentials of the account itself
+		{Method: http.MethodPost, Path: "/api/users/me/ban-appeal", Handler: h.ban.Appeal, Rate: Sensitive},
+
+		{Method: http.MethodPost, Path: "/api/password/request-reset", Handler: h.token.RequestPasswordReset},
+		{Method: http.MethodPut, Path: "/api/password/reset/:token", Handler: h.token.ResetPassword},
+
+		// the account itself, guests get the editor and only what it needs of their account
+		{Method: http.MethodDelete, Path: "/api/auth/session", Handler: h.auth.Logout, Auth: GuestAllowed, PasswordReset: true, NoImpersonation: true},
+		{Method: http.MethodDelete, Path: "/api/auth/impersonation", Handler: h.impersonation.Stop, Auth: Registered},
+		{Method: http.MethodPost, Path: "/api/auth/guest/claim", Handler: h.guest.Claim, Auth: GuestAllowed, Rate: Signup},
+		{Method: http.MethodGet, Path: "/api/users/me", Handler: h.user.GetCurrent, Auth: GuestAllowed, PasswordReset: true},
+		{Method: http.MethodPatch, Path: "/api/users/me", Handler: h.user.UpdateCurrent, Auth: Registered, PasswordReset: true, NoImpersonation: true},
+		{Method: http.MethodPut, Path: "/api/users/:username/profile", Handler: h.profile.Update, Auth: Registered, NoImpersonation: true},
+		{Method: http.MethodPost, Path: "/api/users/me/avatar", Handler: h.avatar.Upload, Auth: Registered, Rate: Costly, NoImpersonation: true},
+		{Method: http.MethodDelete, Path: "/api/users/me/avatar", Handler: h.avatar.Delete, Auth: Registered, NoImpersonation: true},
+		{Method: http.MethodPost, Path: "/api/users/me/export", Handler: h.export.Request, Auth: Registered, Rate: Sensitive, NoImpersonation: true},
+		{Method: http.MethodGet, Path: "/api/users/me/exports", Handler: h.export.List, Auth: Registered},
+		{Method: http.MethodPut, Path: "/api/users/me/password", Handler: h.user.ChangePassword, Auth: Registered, PasswordReset: true, NoImpersonation: true},
+		{Method: http.MethodPost, Path: "/api/users/me/deactivate", Handler: h.token.RequestDeactivationToken, Auth: Registered, NoImpersonation: true},
+		{Method: http.MethodGet, Path: "/api/users/me/research-opt-out", Handler: h.dump.GetOptOut, Auth: Registered},
+		{Method: http.MethodPut, Path: "/api/users/me/research-opt-out", Handler: h.dump.SetOptOut, Auth: Registered},
+		{Method: http.MethodGet, Path: "/api/users/me/consents", Handler: h.consent.GetCurrent, Auth: Registered},
+		{Method: http.MethodPut, Path: "/api/users/me/consents", Handler: h.consent.UpdateCurrent, Auth: Registered, NoImpersonation: true},
+		{Method: http.MethodGet, Path: "/api/users/me/notifications", Handler: h.notification.List, Auth: Registered},
+		{Method: http.MethodPost, Path: "/api/users/me/notifications/read", Handler: h.notification.MarkRead, Auth: Registered},
+		{Method: http.MethodGet, Path: "/api/users/me/notification-preferences", Handler: h.notification.GetPreferences, Auth: Registered},
+		{Method: http.MethodPatch, Path: "/api/users/me/notification-preferences", Handler: h.notification.UpdatePreferences, Auth: Registered, NoImpersonation: true},
+		{Method: http.MethodGet, Path: "/api/users/me/email-preferences", Handler: h.email.GetPreferences, Auth: Registered},
+		{Method: http.MethodPatch, Path: "/api/users/me/email-preferences", Handler: h.email.UpdatePreferences, Auth: Registered, NoImpersonation: true},
+		{Method: http.MethodGet, Path: "/api/users/me/locations", Handler: h.auth.GetTrustedLocations, Auth: Registered},
+		{Method: http.MethodDelete, Path: "/api/users/me/locations/:country", Handler: h.auth.RemoveTrustedLocation, Auth: Registered, NoImpersonation: true},
+		{Method: http.MethodGet, Path: "/api/users/me/permissions", Handler: h.role.GetCurrentPermissions, Auth: Registered},
+		{Method: http.MethodGet, Path: "/api/users/me/verification", Handler: h.verification.GetCurrent, Auth: Registered},
+		{Method: http.MethodPost, Path: "/api/users/me/verification", Handler: h.verification.Submit, Auth: Registered, NoImpersonation: true},
+		{Method: http.MethodGet, Path: "/api/users/me/credits", Handler: h.credit.ListPending, Auth: Registered},
+		{Method: http.MethodPut, Path: "/api/users/me/credits/:id", Handler: h.credit.Respond, Auth: Registered},
+		{Method: http.MethodGet, Path: "/api/users/me/liked-projects/export", Handler: h.project.ExportLikedProjects, Auth: Registered, Rate: Shed},
+		{Method: http.MethodGet, Path: "/api/users/me/recent-projects", Handler: h.project.GetRecentProjects, Auth: GuestAllowed},
+		{Method: http.MethodGet, Path: "/api/users/me/quota", Handler: h.project.GetQuota, Auth: Registered},
+		{Method: http.MethodGet, Path: "/api/users/me/folders", Handler: h.folder.List, Auth: Registered},
+		{Method: http.MethodPost, Path: "/api/users/me/folders", Handler: h.folder.Create, Auth: Registered},
+		{Method: http.MethodPut, Path: "/api/users/me/folders/order", Handler: h.folder.ReorderFolders, Auth: Registered},
+		{Method: http.MethodPatch, Path: "/api/users/me/folders/:id", Handler: h.folder.Rename, Auth: Registered},
+		{Method: http.MethodDelete, Path: "/api/users/me/folders/:id", Handler: h.folder.Delete, Auth: Registered},
+		{Method: http.MethodPut, Path: "/api/users/me/folders/:id/order", Handler: h.folder.ReorderProjects, Auth: Registered},
+		{Method: http.MethodGet, Path: eventStreamPath, Handler: h.realtime.Stream, Auth: GuestAllowed},
+
+		{Method: http.MethodPost, Path: "/api/projects", Handler: h.project.Create, Auth: GuestAllowed},
+		{Method: http.MethodPost, Path: likePath, Handler: h.project.Like, Auth: Registered},
+		{Method: http.MethodDelete, Path: likePath, Handler: h.project.Unlike, Auth: Registered},
+		{Method: http.MethodPost, Path: "/api/projects/:id/forks", Handler: h.project.Fork, Auth: Registered},
+		{Method: http.MethodPost, Path: "/api/projects/:id/report", Handler: h.report.Report, Auth: Registered},
+		{Method: http.MethodDelete, Path: "/api/projects/:id", Handler: h.project.Delete, Auth: GuestAllowed, NoImpersonation: true},
+		{Method: http.MethodPatch, Path: "/api/projects/:id", Handler: h.project.Update, Auth: GuestAllowed},
+		{Method: http.MethodPatch, Path: "/api/projects/:id/data", Handler: h.project.SaveData, Auth: GuestAllowed},
+		// metered against the daily compute of the user's role
+		{Method: http.MethodPost, Path: "/api/projects/:id/run", Handler: h.run.Run, Auth: GuestAllowed, Rate: Shed},
+		{Method: http.MethodPost, Path: "/api/projects/:id/publish", Handler: h.project.Publish, Auth: Registered},
+		{Method: http.MethodGet, Path: "/api/projects/:id/metadata-draft", Handler: h.project.GetMetadataDraft, Auth: Registered},
+		{Method: http.MethodPut, Path: "/api/projects/:id/metadata-draft", Handler: h.project.SaveMetadataDraft, Auth: Registered},
+		{Method: http.MethodDelete, Path: "/api/projects/:id/metadata-draft", Handler: h.project.DiscardMetadataDraft, Auth: Registered},
+		{Method: http.MethodPut, Path: "/api/projects/:id/accessibility", Handler: h.project.SetAccessibility, Auth: Registered},
+		{Method: http.MethodPost, Path: "/api/projects/:id/embed-token", Handler: h.embed.CreateToken, Auth: Registered, NoImpersonation: true},
+		{Method: http.MethodPut, Path: "/api/projects/:id/folder", Handler: h.folder.MoveProject, Auth: Registered},
+		{Method: http.MethodPost, Path: "/api/projects/:id/credits", Handler: h.credit.Add, Auth: Registered},
+		{Method: http.MethodDelete, Path: "/api/projects/:id/credits/:userID", Handler: h.credit.Remove, Auth: Registered},
+		{Method: http.MethodPost, Path: "/api/projects/import", Handler: h.imports.Import, Auth: Registered},
+		{Method: http.MethodGet, Path: "/api/projects/:id/export", Handler: h.imports.Export, Auth: Registered, Rate: Shed},
+		{Method: http.MethodPost, Path: "/api/projects/uploads", Handler: h.imports.CreateUpload, Auth: Registered},
+		{Method: http.MethodGet, Path: "/api/projects/uploads/:id", Handler: h.imports.GetUpload, Auth: Registered},
+		{Method: http.MethodPatch, Path: "/api/projects/uploads/:id", Handler: h.imports.AppendChunk, Auth: Registered},
+		{Method: http.MethodDelete, Path: "/api/projects/uploads/:id", Handler: h.imports.CancelUpload, Auth: Registered},
+		{Method: http.MethodPost, Path: "/api/projects/uploads/:id/complete", Handler: h.imports.CompleteUpload, Auth: Registered},
+		{Method: http.MethodGet, Path: "/api/projects/:id/members", Handler: h.project.GetMembers, Auth: Registered},
+		{Method: http.MethodPost, Path: "/api/projects/:id/members", Handler: h.project.AddMember, Auth: Registered},
+		{Method: http.MethodDelete, Path: "/api/projects/:id/members/:userID", Handler: h.project.RemoveMember, Auth: Registered},
+		{Method: http.MethodGet, Path: "/api/projects/:id/revisions", Handler: h.revision.List, Auth: GuestAllowed},
+		{Method: http.MethodGet, Path: "/api/projects/:id/revisions/:revisionID", Handler: h.revision.Get, Auth: GuestAllowed},
+		{Method: http.MethodPost, Path: "/api/projects/:id/revisions/:revisionID/restore", Handler: h.revision.Restore, Auth: GuestAllowed},
+		{Method: http.MethodGet, Path: "/api/projects/:id/webhook", Handler: h.webhook.Get, Auth: Registered},
+		{Method: http.MethodPut, Path: "/api/projects/:id/webhook", Handler: h.webhook.Set, Auth: Registered},
+		{Method: http.MethodDelete, Path: "/api/projects/:id/webhook", Handler: h.webhook.Delete, Auth: Registered},
+		{Method: http.MethodGet, Path: "/api/projects/:id/webhook/deliveries", Handler: h.webhook.GetDeliveries, Auth: Registered},
+
+		{Method: http.MethodPost, Path: "/api/classrooms", Handler: h.classroom.Create, Auth: Registered},
+		{Method: http.MethodGet, Path: "/api/classrooms", Handler: h.classroom.List, Auth: Registered},
+		{Method: http.MethodGet, Path: "/api/classrooms/:id", Handler: h.classroom.Get, Auth: Registered},
+		{Method: http.MethodDelete, Path: "/api/classrooms/:id", Handler: h.classroom.Delete, Auth: Registered, NoImpersonation: true},
+		{Method: http.MethodGet, Path: "/api/classrooms/:id/projects", Handler: h.classroom.GetProjects, Auth: Registered},
+		{Method: http.MethodGet, Path: "/api/classrooms/invites", Handler: h.classroom.ListInvites, Auth: Registered},
+		{Method: http.MethodPost, Path: "/api/classrooms/:id/invite", Handler: h.classroom.AcceptInvite, Auth: Registered},
+		{Method: http.MethodDelete, Path: "/api/classrooms/:id/invite", Handler: h.classroom.DeclineInvite, Auth: Registered},
+		{Method: http.MethodPost, Path: "/api/classrooms/:id/members", Handler: h.classroom.InviteMembers, Auth: Registered, Rate: Sensitive},
+		{Method: http.MethodDelete, Path: "/api/classrooms/:id/members/:userID", Handler: h.classroom.RemoveMember, Auth: Registered},
+
+		// administrative routes, each guarded by the permission it needs so roles can be granted parts of them
+		{Method: http.MethodGet, Path: "/api/admin/users/all", Handler: h.user.List, Auth: Registered, Permission: data.PermUsersRead},
+		{Method: http.MethodGet, Path: "/api/admin/users/deletions", Handler: h.deletion.ListPending, Auth: Registered, Permission: data.PermUsersDelete},
+		{Method: http.MethodGet, Path: "/api/admin/projects/all", Handler: h.project.List, Auth: Registered, Permission: data.PermProjectsRead},
+		{Method: http.MethodGet, Path: "/api/admin/users/:id", Handler: h.user.Get, Auth: Registered, Permission: data.PermUsersRead},
+		{Method: http.MethodPut, Path: "/api/admin/users/:id", Handler: h.user.Update, Auth: Registered, Permission: data.PermUsersUpdate},
+		{Method: http.MethodPatch, Path: "/api/admin/projects/:id", Handler: h.project.Feature, Auth: Registered, Permission: data.PermProjectsFeature},
+		{Method: http.MethodDelete, Path: "/api/admin/users/:id", Handler: h.user.Delete, Auth: Registered, Permission: data.PermUsersDelete},
+		{Method: http.MethodPost, Path: "/api/admin/users/:id/resend-activation", Handler: h.token.ResendActivation, Auth: Registered, Permission: data.PermUsersUpdate},
+		{Method: http.MethodPost, Path: "/api/admin/users/:id/send-password-reset", Handler: h.token.SendPasswordReset, Auth: Registered, Permission: data.PermUsersUpdate},
+		{Method: http.MethodGet, Path: "/api/admin/emails", Handler: h.email.List, Auth: Registered, Permission: data.PermUsersRead},
+		{Method: http.MethodGet, Path: "/api/admin/email-templates", Handler: h.email.Templates, Auth: Registered, Permission: data.PermEmailsPreview},
+		{Method: http.MethodPost, Path: "/api/admin/email-templates/:name/preview", Handler: h.email.Preview, Auth: Registered, Permission: data.PermEmailsPreview, Rate: Sensitive},
+		{Method: http.MethodPost, Path: "/api/admin/users/:id/impersonate", Handler: h.impersonation.Start, Auth: Registered, Permission: data.PermUsersImpersonate},
+		{Method: http.MethodGet, Path: "/api/admin/users/:id/annotation", Handler: h.annotation.GetUser, Auth: Registered, Permission: data.PermAnnotationsManage},
+		{Method: http.MethodPut, Path: "/api/admin/users/:id/annotation", Handler: h.annotation.SetUser, Auth: Registered, Permission: data.PermAnnotationsManage},
+		{Method: http.MethodGet, Path: "/api/admin/projects/:id/annotation", Handler: h.annotation.GetProject, Auth: Registered, Permission: data.PermAnnotationsManage},
+		{Method: http.MethodPut, Path: "/api/admin/projects/:id/annotation", Handler: h.annotation.SetProject, Auth: Registered, Permission: data.PermAnnotationsManage},
+		{Method: http.MethodGet, Path: "/api/admin/verification-requests", Handler: h.verification.ListPending, Auth: Registered, Permission: data.PermUsersVerify},
+		{Method: http.MethodPut, Path: "/api/admin/verification-requests/:id", Handler: h.verification.Review, Auth: Registered, Permission: data.PermUsersVerify},
+		{Method: http.MethodGet, Path: "/api/admin/reports", Handler: h.report.ListOpen, Auth: Registered, Permission: data.PermReportsManage},
+		{Method: http.MethodPost, Path: "/api/admin/reports/:id/dismiss", Handler: h.report.Dismiss, Auth: Registered, Permission: data.PermReportsManage},
+		{Method: http.MethodPost, Path: "/api/admin/projects/:id/takedown", Handler: h.report.TakeDown, Auth: Registered, Permission: data.PermReportsManage},
+		{Method: http.MethodPost, Path: "/api/admin/users/ban", Handler: h.user.Ban, Auth: Registered, Permission: data.PermUsersBan},
+		{Method: http.MethodPost, Path: "/api/admin/users/bulk", Handler: h.user.Bulk, Auth: Registered, Permission: data.PermUsersBulk},
+		{Method: http.MethodDelete, Path: "/api/admin/users/ban/:userID", Handler: h.user.Unban, Auth: Registered, Permission: data.PermUsersBan},
+		{Method: http.MethodGet, Path: "/api/admin/users/:id/bans", Handler: h.ban.History, Auth: Registered, Permission: data.PermUsersBan},
+		{Method: http.MethodGet, Path: "/api/admin/users/:id/history", Handler: h.history.User, Auth: Registered, Permission: data.PermUsersRead},
+		{Method: http.MethodGet, Path: "/api/admin/projects/:id/history", Handler: h.history.Project, Auth: Registered, Permission: data.PermProjectsRead},
+		{Method: http.MethodGet, Path: "/api/admin/audit-logs", Handler: h.audit.List, Auth: Registered, Permission: data.PermAuditRead},
+		{Method: http.MethodGet, Path: "/api/admin/alerts", Handler: h.alert.List, Auth: Registered, Permission: data.PermAlertsManage},
+		{Method: http.MethodPost, Path: "/api/admin/alerts/:id/acknowledge", Handler: h.alert.Acknowledge, Auth: Registered, Permission: data.PermAlertsManage},
+		{Method: http.MethodGet, Path: "/api/admin/tasks/dead", Handler: h.task.ListDead, Auth: Registered, Permission: data.PermTasksManage},
+		{Method: http.MethodPost, Path: "/api/admin/tasks/:id/retry", Handler: h.task.Retry, Auth: Registered, Permission: data.PermTasksManage},
+		{Method: http.MethodGet, Path: "/api/admin/ban-appeals", Handler: h.ban.ListAppeals, Auth: Registered, Permission: data.PermUsersBan},
+		{Method: http.MethodPost, Path: "/api/admin/ban-appeals/:id/resolve", Handler: h.ban.ResolveAppeal, Auth: Registered, Permission: data.PermUsersBan},
+		{Method: http.MethodGet, Path: "/api/admin/ip-bans", Handler: h.ban.ListIPBans, Auth: Registered, Permission: data.PermUsersBan},
+		{Method: http.MethodPost, Path: "/api/admin/ip-bans", Handler: h.ban.AddIPBan, Auth: Registered, Permission: data.PermUsersBan},
+		{Method: http.MethodGet, Path: "/api/admin/ip-bans/:id", Handler: h.ban.GetIPBan, Auth: Registered, Permission: data.PermUsersBan},
+		{Method: http.MethodPut, Path: "/api/admin/ip-bans/:id", Handler: h.ban.UpdateIPBan, Auth: Registered, Permission: data.PermUsersBan},
+		{Method: http.MethodDelete, Path: "/api/admin/ip-bans/:id", Handler: h.ban.RemoveIPBan, Auth: Registered, Permission: data.PermUsersBan},
+		{Method: http.MethodPost, Path: "/api/admin/users/provision", Handler: h.user.Provision, Auth: Registered, Permission: data.PermUsersProvision},
+		{Method: http.MethodPost, Path: "/api/admin/users/deprovision", Handler: h.user.Deprovision, Auth: Registered, Permission: data.PermUsersProvision},
+		{Method: http.MethodGet, Path: "/api/admin/featured/queue", Handler: h.featured.GetQueue, Auth: Registered, Permission: data.PermProjectsFeature},
+		{Method: http.MethodPost, Path: "/api/admin/featured/queue", Handler: h.featured.Enqueue, Auth: Registered, Permission: data.PermProjectsFeature},
+		{Method: http.MethodDelete, Path: "/api/admin/featured/queue/:id", Handler: h.featured.Dequeue, Auth: Registered, Permission: data.PermProjectsFeature},
+		{Method: http.MethodPut, Path: "/api/admin/featured/:id/pin", Handler: h.featured.Pin, Auth: Registered, Permission: data.PermProjectsFeature},
+		{Method: http.MethodPost, Path: "/api/admin/featured/rotate", Handler: h.featured.Rotate, Auth: Registered, Permission: data.PermProjectsFeature},
+		{Method: http.MethodGet, Path: "/api/admin/featured", Handler: h.featured.List, Auth: Registered, Permission: data.PermProjectsFeature},
+		{Method: http.MethodGet, Path: "/api/admin/featured/history", Handler: h.featured.History, Auth: Registered, Permission: data.PermProjectsFeature},
+		{Method: http.MethodPut, Path: "/api/admin/featured/:id", Handler: h.featured.Feature, Auth: Registered, Permission: data.PermProjectsFeature},
+		{Method: http.MethodDelete, Path: "/api/admin/featured/:id", Handler: h.featured.Unfeature, Auth: Registered, Permission: data.PermProjectsFeature},
+		{Method: http.MethodPost, Path: "/api/admin/dumps", Handler: h.dump.Generate, Auth: Registered, Permission: data.PermDumpsGenerate},
+		{Method: http.MethodPost, Path: "/api/admin/projects/compact", Handler: h.compaction.Compact, Auth: Registered, Permission: data.PermProjectsCompact},
+		{Method: http.MethodGet, Path: "/api/admin/projects/integrity", Handler: h.integrity.Scan, Auth: Registered, Permission: data.PermProjectsRead},
+		{Method: http.MethodGet, Path: "/api/admin/retention", Handler: h.retention.ListClasses, Auth: Registered, Permission: data.PermRetentionManage},
+		{Method: http.MethodGet, Path: "/api/admin/retention/runs", Handler: h.retention.ListRuns, Auth: Registered, Permission: data.PermRetentionManage},
+		{Method: http.MethodPost, Path: "/api/admin/retention/purge", Handler: h.retention.Purge, Auth: Registered, Permission: data.PermRetentionManage},
+		{Method: http.MethodGet, Path: "/api/admin/metrics/bots", Handler: h.metrics.Bots, Auth: Registered, Permission: data.PermMetricsRead},
+		{Method: http.MethodGet, Path: "/api/admin/metrics/caches", Handler: h.metrics.Caches, Auth: Registered, Permission: data.PermMetricsRead},
+		{Method: http.MethodGet, Path: "/api/admin/metrics/signups", Handler: h.metrics.Signups, Auth: Registered, Permission: data.PermMetricsRead},
+		{Method: http.MethodGet, Path: "/api/admin/metrics/load", Handler: h.metrics.Load, Auth: Registered, Permission: data.PermMetricsRead},
+		{Method: http.MethodGet, Path: "/api/admin/metrics/integrity", Handler: h.metrics.Integrity, Auth: Registered, Permission: data.PermMetricsRead},
+		{Method: http.MethodGet, Path: "/api/admin/metrics/probes", Handler: h.metrics.Probes, Auth: Registered, Permission: data.PermMetricsRead},
+		{Method: http.MethodGet, Path: "/api/admin/system/db-health", Handler: h.system.DBHealth, Auth: Registered, Permission: data.PermMetricsRead},
+		{Method: http.MethodGet, Path: "/api/admin/system/render-workers", Handler: h.render.Status, Auth: Registered, Permission: data.PermMetricsRead},
+		// reachable in read-only mode to leave it
+		{Method: http.MethodPut, Path: "/api/admin/system/read-only", Handler: h.readOnly.Set, Auth: Registered, Permission: data.PermReadOnlyManage, ReadOnlyAllowed: true},
+		{Method: http.MethodPost, Path: "/api/admin/auth/keys/rotate", Handler: h.auth.RotateSigningKey, Auth: Registered, Permission: data.PermKeysRotate},
+		{Method: http.MethodGet, Path: "/api/admin/tokens/stats", Handler: h.token.Stats, Auth: Registered, Permission: data.PermMetricsRead},
+		{Method: http.MethodPost, Path: "/api/admin/tokens/revoke", Handler: h.token.Revoke, Auth: Registered, Permission: data.PermTokensRevoke},
+		{Method: http.MethodGet, Path: "/api/admin/jobs", Handler: h.job.List, Auth: Registered, Permission: data.PermJobsRead},
+		{Method: http.MethodGet, Path: "/api/admin/jobs/:name", Handler: h.job.Get, Auth: Registered, Permission: data.PermJobsRead},
+		{Method: http.MethodGet, Path: "/api/admin/flags", Handler: h.flag.List, Auth: Registered, Permission: data.PermFlagsManage},
+		{Method: http.MethodPut, Path: "/api/admin/flags/:name", Handler: h.flag.Set, Auth: Registered, Permission: data.PermFlagsManage},
+		{Method: http.MethodPost, Path: "/api/admin/announcements", Handler: h.announcement.Create, Auth: Registered, Permission: data.PermAnnouncementsManage},
+		{Method: http.MethodDelete, Path: "/api/admin/announcements/:id", Handler: h.announcement.Delete, Auth: Registered, Permission: data.PermAnnouncementsManage},
+		{Method: http.MethodGet, Path: "/api/admin/signup-allowlist", Handler: h.signup.ListAllowlist, Auth: Registered, Permission: data.PermSignupsManage},
+		{Method: http.MethodPost, Path: "/api/admin/signup-allowlist", Handler: h.signup.AddToAllowlist, Auth: Registered, Permission: data.PermSignupsManage},
+		{Method: http.MethodDelete, Path: "/api/admin/signup-allowlist/:id", Handler: h.signup.RemoveFromAllowlist, Auth: Registered, Permission: data.PermSignupsManage},
+	}
+}
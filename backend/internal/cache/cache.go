@@ -0,0 +1,184 @@
+// Package cache keeps small, rarely changing data such as feature flags in memory.
+// Values are loaded from the database on first use and reloaded after they are invalidated.
+// Invalidations are broadcast to every API instance with Postgres NOTIFY, the TTL only bounds
+// how stale a value gets when a notification is missed.
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+
+	"github.com/lib/pq"
+)
+
+// channel is the Postgres notification channel invalidations are published on, the payload is the cache name.
+const channel = "cache_invalidation"
+
+// entry is the part of a Value the Registry needs, independent of the cached type.
+type entry interface {
+	invalidate()
+	metrics() data.CacheMetrics
+}
+
+// Registry tracks the caches of an API instance and keeps them in sync with the other instances.
+type Registry struct {
+	db     *sql.DB
+	ttl    time.Duration
+	mu     sync.Mutex
+	caches map[string]entry
+}
+
+// NewRegistry creates an empty Registry. A ttl of 0 disables caching, every read loads the value.
+func NewRegistry(db *sql.DB, ttl time.Duration) *Registry {
+	return &Registry{
+		db:     db,
+		ttl:    ttl,
+		caches: map[string]entry{},
+	}
+}
+
+// Value is a single cached value, loaded with load when missing or expired.
+type Value[T any] struct {
+	ttl      time.Duration
+	load     func() (T, error)
+	mu       sync.Mutex
+	value    T
+	loadedAt time.Time
+	valid    bool
+	stats    data.CacheMetrics
+}
+
+// New registers a cache called name, the name other instances are told to invalidate.
+func New[T any](r *Registry, name string, load func() (T, error)) *Value[T] {
+	v := &Value[T]{ttl: r.ttl, load: load, stats: data.CacheMetrics{Name: name}}
+
+	r.mu.Lock()
+	r.caches[name] = v
+	r.mu.Unlock()
+
+	return v
+}
+
+// Get returns the cached value, loading it first if needed. Errors are not cached.
+func (v *Value[T]) Get() (T, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.valid && time.Since(v.loadedAt) < v.ttl {
+		v.stats.Hits++
+		return v.value, nil
+	}
+
+	v.stats.Misses++
+	value, err := v.load()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	v.value = value
+	v.loadedAt = time.Now()
+	v.valid = true
+	return value, nil
+}
+
+func (v *Value[T]) invalidate() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	// the instance publishing an invalidation also receives it, only count values actually dropped
+	if v.valid {
+		v.stats.Invalidations++
+	}
+	v.valid = false
+}
+
+func (v *Value[T]) metrics() data.CacheMetrics {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return v.stats
+}
+
+// Invalidate drops the cache called name on this instance and notifies the other instances.
+// It is called after the underlying data was changed.
+func (r *Registry) Invalidate(name string) error {
+	r.invalidateLocal(name)
+
+	_, err := r.db.Exec("SELECT pg_notify($1, $2)", channel, name)
+	return err
+}
+
+func (r *Registry) invalidateLocal(name string) {
+	r.mu.Lock()
+	c, ok := r.caches[name]
+	r.mu.Unlock()
+
+	if ok {
+		c.invalidate()
+	}
+}
+
+func (r *Registry) invalidateAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, c := range r.caches {
+		c.invalidate()
+	}
+}
+
+// Metrics returns the hit and miss counters of every cache since startup.
+func (r *Registry) Metrics() []data.CacheMetrics {
+	r.mu.Lock()
+	caches := make([]entry, 0, len(r.caches))
+	for _, c := range r.caches {
+		caches = append(caches, c)
+	}
+	r.mu.Unlock()
+
+	metrics := make([]data.CacheMetrics, 0, len(caches))
+	for _, c := range caches {
+		metrics = append(metrics, c.metrics())
+	}
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].Name < metrics[j].Name })
+	return metrics
+}
+
+// Listen applies the invalidations published by other instances until ctx is cancelled.
+// Notifications sent while the connection was down are lost, so every cache is dropped after reconnecting.
+func (r *Registry) Listen(ctx context.Context, dsn string) error {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logging.FromContext(ctx).ErrorContext(ctx, "Cache invalidation listener failed", "event", event, "error", err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(channel); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n := <-listener.Notify:
+			// nil after the connection was re-established
+			if n == nil {
+				r.invalidateAll()
+				continue
+			}
+			r.invalidateLocal(n.Extra)
+		case <-time.After(90 * time.Second):
+			// detects a silently dropped connection
+			go listener.Ping()
+		}
+	}
+}
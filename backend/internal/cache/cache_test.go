@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValue_LoadsOnceUntilInvalidated(t *testing.T) {
+	r := NewRegistry(nil, time.Minute)
+
+	loads := 0
+	v := New(r, "flags", func() (int, error) {
+		loads++
+		return loads, nil
+	})
+
+	got, err := v.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, got)
+
+	got, _ = v.Get()
+	assert.Equal(t, 1, got)
+	assert.Equal(t, 1, loads)
+
+	r.invalidateLocal("flags")
+	r.invalidateLocal("flags") // already dropped, not counted again
+	r.invalidateLocal("unknown")
+
+	got, _ = v.Get()
+	assert.Equal(t, 2, got)
+
+	m := r.Metrics()
+	assert.Len(t, m, 1)
+	assert.Equal(t, "flags", m[0].Name)
+	assert.Equal(t, int64(1), m[0].Hits)
+	assert.Equal(t, int64(2), m[0].Misses)
+	assert.Equal(t, int64(1), m[0].Invalidations)
+}
+
+func TestValue_ErrorsAreNotCached(t *testing.T) {
+	r := NewRegistry(nil, time.Minute)
+
+	fail := true
+	v := New(r, "announcements", func() ([]string, error) {
+		if fail {
+			return nil, errors.New("db down")
+		}
+		return []string{"maintenance tonight"}, nil
+	})
+
+	_, err := v.Get()
+	assert.Error(t, err)
+
+	fail = false
+	got, err := v.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"maintenance tonight"}, got)
+}
+
+func TestValue_ExpiresAfterTTL(t *testing.T) {
+	r := NewRegistry(nil, 10*time.Millisecond)
+
+	loads := 0
+	v := New(r, "flags", func() (int, error) {
+		loads++
+		return loads, nil
+	})
+
+	v.Get()
+	v.Get()
+	assert.Equal(t, 1, loads)
+
+	time.Sleep(15 * time.Millisecond)
+	v.Get()
+	assert.Equal(t, 2, loads)
+}
+
+func TestValue_ZeroTTLDisablesCaching(t *testing.T) {
+	r := NewRegistry(nil, 0)
+
+	loads := 0
+	v := New(r, "flags", func() (int, error) {
+		loads++
+		return loads, nil
+	})
+
+	v.Get()
+	v.Get()
+	assert.Equal(t, 2, loads)
+}
+
+func TestRegistry_InvalidateAll(t *testing.T) {
+	r := NewRegistry(nil, time.Minute)
+
+	loads := map[string]int{}
+	for _, name := range []string{"b", "a"} {
+		name := name
+		v := New(r, name, func() (int, error) {
+			loads[name]++
+			return 0, nil
+		})
+		v.Get()
+	}
+
+	r.invalidateAll()
+
+	m := r.Metrics()
+	assert.Equal(t, "a", m[0].Name)
+	assert.Equal(t, "b", m[1].Name)
+	assert.Equal(t, int64(1), m[0].Invalidations)
+	assert.Equal(t, int64(1), m[1].Invalidations)
+}
@@ -0,0 +1,364 @@
+// Package codegen translates the turtle programs stored as project data into source code of other languages,
+// so classes can carry on in the language they teach. Every language is a Target; the program is parsed once into
+// a tree of steps that follows the editor's execution, see package render, and each target writes it out.
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrCycle is returned for programs that run into a node they are already running,
+	// which the editor would run until the instruction limit.
+	ErrCycle = errors.New("codegen: the program has a cycle")
+	// ErrTooLarge is returned for programs that would translate into more than maxNodes nodes,
+	// typically branches joining again over and over.
+	ErrTooLarge = errors.New("codegen: the program is too large to translate")
+)
+
+// maxNodes bounds the nodes visited while parsing, a node reached through several paths is translated once for each.
+const maxNodes = 5000
+
+// Target is a language programs are translated to. Supporting another language takes a Target and an entry in targets.
+type Target interface {
+	// Extension is the file extension of the generated code, without the dot.
+	Extension() string
+	ContentType() string
+	Generate(p *Program) []byte
+}
+
+// targets are the languages selectable by name with the format parameter of project exports.
+var targets = map[string]Target{
+	"javascript": JavaScript{},
+	"logo":       Logo{},
+}
+
+// Lookup returns the target with the given name.
+func Lookup(name string) (Target, bool) {
+	t, ok := targets[name]
+	return t, ok
+}
+
+// Formats returns the names of the targets in alphabetical order.
+func Formats() []string {
+	names := make([]string, 0, len(targets))
+	for name := range targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Op is the kind of a Step.
+type Op int
+
+const (
+	// OpMove moves the turtle forward by Value, backwards when negative.
+	OpMove Op = iota
+	// OpTurn turns the turtle clockwise by Value degrees.
+	OpTurn
+	// OpPen lifts or lowers the pen and sets its Color.
+	OpPen
+	// OpFork continues the turtle on every one of Branches as a turtle of its own. It ends its block.
+	OpFork
+	// OpRepeat runs every one of Body Count times on every turtle, see Step.Simple.
+	OpRepeat
+)
+
+// Step is a single instruction of a Block.
+type Step struct {
+	Op      Op
+	Value   float64
+	PenDown bool
+	Color   string // #rrggbb or #rrggbbaa
+
+	Branches []Block
+
+	// A repeat is run from one turtle. Each iteration runs every body on every turtle alive, so turtles multiply
+	// when there are several bodies or a body forks. With Spawn every iteration releases its turtles to Then,
+	// otherwise the turtles of the last iteration are released. Each released turtle runs every one of Then,
+	// or ends when there are none. Unless the repeat is simple it ends its block.
+	Count int
+	Spawn bool
+	Body  []Block
+	Then  []Block
+}
+
+// Simple reports whether the step is a repeat that keeps a single turtle, which every language has a loop for.
+// The block simply continues after it, Then is always empty.
+func (s Step) Simple() bool {
+	return s.Op == OpRepeat && !s.Spawn && len(s.Body) == 1 && s.Body[0].Linear()
+}
+
+// Block is a sequence of steps run by a turtle.
+type Block []Step
+
+// Linear reports whether the block keeps a single turtle from start to end.
+func (b Block) Linear() bool {
+	for _, s := range b {
+		if s.Op == OpFork || (s.Op == OpRepeat && !s.Simple()) {
+			return false
+		}
+	}
+	return true
+}
+
+// Program is a parsed turtle program. Its turtle starts in the middle facing up, with a black pen down.
+type Program struct {
+	Title string
+	Main  Block
+}
+
+type flowNode struct {
+	ID   string                     `json:"id"`
+	Type string                     `json:"type"`
+	Data map[string]json.RawMessage `json:"data"`
+}
+
+type flowEdge struct {
+	Source       string `json:"source"`
+	Target       string `json:"target"`
+	SourceHandle string `json:"sourceHandle"`
+}
+
+// Parse parses the program in the flow document. Like project data, the document may be a JSON object
+// or a string containing one. An empty document or one without a start node is an empty program.
+func Parse(raw json.RawMessage) (*Program, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return &Program{}, nil
+	}
+	if raw[0] == '"' {
+		var encoded string
+		if err := json.Unmarshal(raw, &encoded); err != nil {
+			return nil, err
+		}
+		raw = json.RawMessage(encoded)
+	}
+
+	var doc struct {
+		Nodes []flowNode `json:"nodes"`
+		Edges []flowEdge `json:"edges"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+
+	p := parser{
+		nodes:   map[string]flowNode{},
+		edges:   map[string][]flowEdge{},
+		running: map[string]bool{},
+	}
+	for _, n := range doc.Nodes {
+		if _, exists := p.nodes[n.ID]; !exists {
+			p.nodes[n.ID] = n
+		}
+	}
+	for _, e := range doc.Edges {
+		p.edges[e.Source] = append(p.edges[e.Source], e)
+	}
+
+	for _, n := range doc.Nodes {
+		if n.Type == "startNode" {
+			main, err := p.block(n.ID)
+			if err != nil {
+				return nil, err
+			}
+			return &Program{Main: main}, nil
+		}
+	}
+	return &Program{}, nil
+}
+
+type parser struct {
+	nodes   map[string]flowNode
+	edges   map[string][]flowEdge
+	running map[string]bool // the nodes on the way from the start to the current one
+	visited int
+}
+
+// block parses what a turtle runs from the node on.
+func (p *parser) block(nodeID string) (Block, error) {
+	n, ok := p.nodes[nodeID]
+	if !ok {
+		return nil, nil
+	}
+	if p.running[nodeID] {
+		return nil, ErrCycle
+	}
+	if p.visited++; p.visited > maxNodes {
+		return nil, ErrTooLarge
+	}
+	p.running[nodeID] = true
+	defer delete(p.running, nodeID)
+
+	muted := boolParam(n.Data, "muted")
+
+	var b Block
+	if !muted {
+		b = nodeSteps(n)
+	}
+
+	if n.Type == "loopNode" && !muted {
+		loop, err := p.loop(n)
+		if err != nil {
+			return nil, err
+		}
+		return append(b, loop...), nil
+	}
+
+	var next []Block
+	for _, e := range p.edges[nodeID] {
+		if e.SourceHandle == "loop" {
+			continue
+		}
+		child, err := p.block(e.Target)
+		if err != nil {
+			return nil, err
+		}
+		next = append(next, child)
+	}
+	return append(b, fork(next)...), nil
+}
+
+func (p *parser) loop(n flowNode) (Block, error) {
+	repeat := Step{
+		Op:    OpRepeat,
+		Count: max(int(numberParam(n.Data, "loopCount")), 0),
+		Spawn: boolParam(n.Data, "createTurtleOnIteration"),
+	}
+
+	var then []Block
+	for _, e := range p.edges[n.ID] {
+		if e.SourceHandle != "loop" && e.SourceHandle != "out" {
+			continue
+		}
+		child, err := p.block(e.Target)
+		if err != nil {
+			return nil, err
+		}
+		if e.SourceHandle == "loop" {
+			repeat.Body = append(repeat.Body, child)
+		} else {
+			then = append(then, child)
+		}
+	}
+	if len(repeat.Body) == 0 {
+		repeat.Body = []Block{nil}
+	}
+
+	if repeat.Simple() {
+		return append(Block{repeat}, fork(then)...), nil
+	}
+	repeat.Then = then
+	return Block{repeat}, nil
+}
+
+// fork continues a block with the blocks following it, a single one simply goes on.
+func fork(next []Block) Block {
+	switch len(next) {
+	case 0:
+		return nil
+	case 1:
+		return next[0]
+	}
+	return Block{{Op: OpFork, Branches: next}}
+}
+
+// nodeSteps translates a node into steps with the defaults the editor applies.
+func nodeSteps(n flowNode) Block {
+	switch n.Type {
+	case "moveNode":
+		distance := numberParam(n.Data, "distance")
+		if distance == 0 {
+			distance = 10
+		}
+		return Block{{Op: OpMove, Value: distance}}
+	case "rotateNode":
+		return Block{{Op: OpTurn, Value: numberParam(n.Data, "angle")}}
+	case "penNode":
+		var color string
+		_ = json.Unmarshal(n.Data["color"], &color)
+		return Block{{Op: OpPen, PenDown: boolParam(n.Data, "penDown"), Color: normalizeColor(color)}}
+	}
+	return nil
+}
+
+func numberParam(params map[string]json.RawMessage, name string) float64 {
+	var n float64
+	if json.Unmarshal(params[name], &n) != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0
+	}
+	return n
+}
+
+func boolParam(params map[string]json.RawMessage, name string) bool {
+	var b bool
+	_ = json.Unmarshal(params[name], &b)
+	return b
+}
+
+// normalizeColor returns the #rgb, #rrggbb and #rrggbbaa colors the editor stores as #rrggbb or #rrggbbaa,
+// anything else draws black. Only colors that parse make it into the generated code, so project data can't inject code.
+func normalizeColor(s string) string {
+	if len(s) == 0 || s[0] != '#' {
+		return "#000000"
+	}
+
+	hex := strings.ToLower(s[1:])
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 && len(hex) != 8 {
+		return "#000000"
+	}
+	if _, err := strconv.ParseUint(hex, 16, 32); err != nil {
+		return "#000000"
+	}
+	return "#" + hex[:6] + alpha(hex)
+}
+
+// alpha returns the alpha digits of a rrggbbaa color unless the color is opaque.
+func alpha(hex string) string {
+	if len(hex) == 8 && hex[6:] != "ff" {
+		return hex[6:]
+	}
+	return ""
+}
+
+// rgb returns the red, green and blue components of a normalized color.
+func rgb(color string) (uint8, uint8, uint8) {
+	value, _ := strconv.ParseUint(color[1:7], 16, 32)
+	return uint8(value >> 16), uint8(value >> 8), uint8(value)
+}
+
+// number formats a number as short as possible, as written in the editor.
+func number(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// comment returns the text on a single line, for the titles of projects in comments.
+func comment(s string) string {
+	return strings.Join(strings.FieldsFunc(s, func(r rune) bool { return r < ' ' || r == 0x7f || r == 0x2028 || r == 0x2029 }), " ")
+}
+
+// writer writes indented lines of code.
+type writer struct {
+	buf    bytes.Buffer
+	indent int
+	unit   string
+}
+
+func (w *writer) line(s string) {
+	if s != "" {
+		w.buf.WriteString(strings.Repeat(w.unit, w.indent))
+		w.buf.WriteString(s)
+	}
+	w.buf.WriteByte('\n')
+}
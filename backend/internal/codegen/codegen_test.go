@@ -0,0 +1,159 @@
+package codegen
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the code generation tests")
+
+// TestGolden translates the programs in testdata into every target and compares the code with the
+// golden files next to them, run with -update to accept changes.
+func TestGolden(t *testing.T) {
+	programs, err := filepath.Glob("testdata/*.json")
+	if err != nil || len(programs) == 0 {
+		t.Fatalf("No test programs found: %v", err)
+	}
+
+	for _, path := range programs {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+
+		program, err := Parse(raw)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		program.Title = name
+
+		for _, format := range Formats() {
+			target, _ := Lookup(format)
+			t.Run(fmt.Sprintf("%s %s", name, format), func(t *testing.T) {
+				golden := filepath.Join("testdata", fmt.Sprintf("%s.%s.golden", name, target.Extension()))
+				got := target.Generate(program)
+
+				if *update {
+					if err := os.WriteFile(golden, got, 0644); err != nil {
+						t.Fatal(err)
+					}
+					return
+				}
+
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatalf("Missing golden file, run the tests with -update: %v", err)
+				}
+				assert.Equal(t, string(want), string(got))
+			})
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := map[string]struct {
+		data    string
+		want    Block
+		wantErr error
+	}{
+		"Empty payload": {
+			data: ``,
+		},
+		"No start node": {
+			data: `{"nodes":[{"id":"m","type":"moveNode","data":{"distance":50}}],"edges":[]}`,
+		},
+		"Encoded as a string": {
+			data: `"{\"nodes\":[{\"id\":\"s\",\"type\":\"startNode\"},{\"id\":\"m\",\"type\":\"moveNode\",\"data\":{}}],\"edges\":[{\"source\":\"s\",\"target\":\"m\"}]}"`,
+			want: Block{{Op: OpMove, Value: 10}},
+		},
+		"Single loop body": {
+			data: `{"nodes":[
+				{"id":"s","type":"startNode"},
+				{"id":"loop","type":"loopNode","data":{"loopCount":3}},
+				{"id":"m","type":"moveNode","data":{"distance":5}},
+				{"id":"after","type":"rotateNode","data":{"angle":45}}
+			],"edges":[
+				{"source":"s","target":"loop"},
+				{"source":"loop","sourceHandle":"loop","target":"m"},
+				{"source":"loop","sourceHandle":"out","target":"after"}
+			]}`,
+			want: Block{
+				{Op: OpRepeat, Count: 3, Body: []Block{{{Op: OpMove, Value: 5}}}},
+				{Op: OpTurn, Value: 45},
+			},
+		},
+		"Spawning loop": {
+			data: `{"nodes":[
+				{"id":"s","type":"startNode"},
+				{"id":"loop","type":"loopNode","data":{"loopCount":2,"createTurtleOnIteration":true}},
+				{"id":"m","type":"moveNode","data":{"distance":5}}
+			],"edges":[{"source":"s","target":"loop"},{"source":"loop","sourceHandle":"loop","target":"m"}]}`,
+			want: Block{{Op: OpRepeat, Count: 2, Spawn: true, Body: []Block{{{Op: OpMove, Value: 5}}}}},
+		},
+		"Unsafe color": {
+			data: `{"nodes":[
+				{"id":"s","type":"startNode"},
+				{"id":"p","type":"penNode","data":{"penDown":true,"color":"\");alert(1);//"}}
+			],"edges":[{"source":"s","target":"p"}]}`,
+			want: Block{{Op: OpPen, PenDown: true, Color: "#000000"}},
+		},
+		"Cycle": {
+			data: `{"nodes":[
+				{"id":"s","type":"startNode"},
+				{"id":"a","type":"moveNode"},
+				{"id":"b","type":"rotateNode","data":{"angle":10}}
+			],"edges":[{"source":"s","target":"a"},{"source":"a","target":"b"},{"source":"b","target":"a"}]}`,
+			wantErr: ErrCycle,
+		},
+		"Branches joining over and over": {
+			data:    diamonds(20),
+			wantErr: ErrTooLarge,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			program, err := Parse(json.RawMessage(tt.data))
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, program.Main)
+		})
+	}
+}
+
+// diamonds chains n pairs of branches that join again, doubling the paths with every pair.
+func diamonds(n int) string {
+	nodes := []string{`{"id":"n0","type":"startNode"}`}
+	var edges []string
+	for i := 1; i <= n; i++ {
+		nodes = append(nodes,
+			fmt.Sprintf(`{"id":"a%d","type":"moveNode"}`, i),
+			fmt.Sprintf(`{"id":"b%d","type":"rotateNode"}`, i),
+			fmt.Sprintf(`{"id":"n%d","type":"moveNode"}`, i),
+		)
+		edges = append(edges,
+			fmt.Sprintf(`{"source":"n%d","target":"a%d"}`, i-1, i),
+			fmt.Sprintf(`{"source":"n%d","target":"b%d"}`, i-1, i),
+			fmt.Sprintf(`{"source":"a%d","target":"n%d"}`, i, i),
+			fmt.Sprintf(`{"source":"b%d","target":"n%d"}`, i, i),
+		)
+	}
+	return fmt.Sprintf(`{"nodes":[%s],"edges":[%s]}`, strings.Join(nodes, ","), strings.Join(edges, ","))
+}
+
+func TestComment(t *testing.T) {
+	assert.Equal(t, "Spiral */ alert(1)", comment("Spiral\n*/ alert(1)"))
+	assert.Equal(t, "", comment("\r\n"))
+}
@@ -0,0 +1,194 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// JavaScript translates programs into a script drawing on an HTML canvas, with a small turtle class in the
+// style of Python's turtle module. Branches clone the turtle, as the editor spawns turtles.
+type JavaScript struct{}
+
+func (JavaScript) Extension() string { return "js" }
+
+func (JavaScript) ContentType() string { return "text/javascript; charset=utf-8" }
+
+const jsTurtle = `// A turtle starts in the middle of the canvas facing up with a black pen down,
+// positive angles turn it clockwise as in the editor.
+class Turtle {
+  constructor(ctx, x = 0, y = 0, heading = 90, penDown = true, color = "#000000") {
+    this.ctx = ctx;
+    this.x = x;
+    this.y = y;
+    this.heading = heading;
+    this.penDown = penDown;
+    this.color = color;
+  }
+
+  clone() {
+    return new Turtle(this.ctx, this.x, this.y, this.heading, this.penDown, this.color);
+  }
+
+  forward(distance) {
+    const radians = (this.heading * Math.PI) / 180;
+    const x = this.x + Math.cos(radians) * distance;
+    const y = this.y - Math.sin(radians) * distance;
+    if (this.penDown) {
+      this.ctx.strokeStyle = this.color;
+      this.ctx.beginPath();
+      this.ctx.moveTo(this.x, this.y);
+      this.ctx.lineTo(x, y);
+      this.ctx.stroke();
+    }
+    this.x = x;
+    this.y = y;
+  }
+
+  right(angle) {
+    this.heading -= angle;
+  }
+
+  pen(down, color) {
+    this.penDown = down;
+    this.color = color;
+  }
+}
+`
+
+const jsFork = `
+// fork continues the turtle on every branch as a turtle of its own and returns the turtles they end with.
+function fork(t, branches) {
+  return branches.flatMap((branch) => branch(t.clone()));
+}
+`
+
+const jsRepeat = `
+// repeat runs every body on every turtle count times, so turtles multiply when there are several bodies.
+// With spawn every iteration releases its turtles to then, otherwise the turtles of the last iteration are released.
+function repeat(t, count, spawn, bodies, then) {
+  let turtles = [t];
+  const ended = [];
+  for (let i = 0; i < count; i++) {
+    turtles = turtles.flatMap((turtle) => bodies.flatMap((body) => body(turtle.clone())));
+    if (spawn) {
+      ended.push(...turtles.flatMap((turtle) => then(turtle.clone())));
+    }
+  }
+  if (!spawn) {
+    ended.push(...turtles.flatMap(then));
+  }
+  return ended;
+}
+`
+
+const jsCanvas = `
+const canvas = document.getElementById("turtle");
+const ctx = canvas.getContext("2d");
+ctx.translate(canvas.width / 2, canvas.height / 2);
+ctx.lineWidth = 2;
+ctx.lineCap = "round";
+main(new Turtle(ctx));
+`
+
+func (JavaScript) Generate(p *Program) []byte {
+	g := jsGenerator{w: &writer{unit: "  "}}
+	g.block(p.Main, false)
+	body := g.w.buf.String()
+
+	var b strings.Builder
+	if title := comment(p.Title); title != "" {
+		fmt.Fprintf(&b, "// %s\n", title)
+	}
+	b.WriteString("// Generated by Turtle Graphics, load it in a page with <canvas id=\"turtle\" width=\"800\" height=\"600\"></canvas>.\n\n")
+	b.WriteString(jsTurtle)
+	if g.forks {
+		b.WriteString(jsFork)
+	}
+	if g.repeats {
+		b.WriteString(jsRepeat)
+	}
+	b.WriteString("\nfunction main(t) {\n")
+	b.WriteString(body)
+	b.WriteString("}\n")
+	b.WriteString(jsCanvas)
+	return []byte(b.String())
+}
+
+type jsGenerator struct {
+	w              *writer
+	loops          int // depth of the simple loops, naming their counters
+	forks, repeats bool
+}
+
+// block writes the steps of a block. As a value, the block returns the turtles it ends with.
+func (g *jsGenerator) block(b Block, value bool) {
+	g.w.indent++
+	defer func() { g.w.indent-- }()
+
+	ret := ""
+	if value {
+		ret = "return "
+	}
+
+	for _, s := range b {
+		switch s.Op {
+		case OpMove:
+			g.w.line(fmt.Sprintf("t.forward(%s);", number(s.Value)))
+		case OpTurn:
+			g.w.line(fmt.Sprintf("t.right(%s);", number(s.Value)))
+		case OpPen:
+			g.w.line(fmt.Sprintf("t.pen(%t, %q);", s.PenDown, s.Color))
+		case OpFork:
+			g.forks = true
+			g.w.line(ret + "fork(t, [")
+			g.functions(s.Branches)
+			g.w.line("]);")
+			return
+		case OpRepeat:
+			if s.Simple() {
+				counter := g.counter()
+				g.w.line(fmt.Sprintf("for (let %s = 0; %s < %d; %s++) {", counter, counter, s.Count, counter))
+				g.loops++
+				g.block(s.Body[0], false)
+				g.loops--
+				g.w.line("}")
+				continue
+			}
+
+			g.repeats = true
+			g.w.line(fmt.Sprintf("%srepeat(t, %d, %t, [", ret, s.Count, s.Spawn))
+			g.functions(s.Body)
+			if len(s.Then) == 0 {
+				g.w.line("], (t) => [t]);")
+				return
+			}
+			g.w.line("], (t) => {")
+			g.block(fork(s.Then), true)
+			g.w.line("});")
+			return
+		}
+	}
+
+	if value {
+		g.w.line("return [t];")
+	}
+}
+
+// functions writes blocks as a list of functions of a turtle returning the turtles they end with.
+func (g *jsGenerator) functions(blocks []Block) {
+	g.w.indent++
+	for _, b := range blocks {
+		g.w.line("(t) => {")
+		g.block(b, true)
+		g.w.line("},")
+	}
+	g.w.indent--
+}
+
+func (g *jsGenerator) counter() string {
+	const names = "ijklmn"
+	if g.loops < len(names) {
+		return names[g.loops : g.loops+1]
+	}
+	return fmt.Sprintf("i%d", g.loops)
+}
@@ -0,0 +1,194 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Logo translates programs into classic Logo procedures, with pen colors as RGB lists from 0 to 255 as FMSLogo
+// and most web interpreters take them. Logo has a single turtle, so branches save its state and restore it
+// for every turtle the editor would spawn.
+type Logo struct{}
+
+func (Logo) Extension() string { return "logo" }
+
+func (Logo) ContentType() string { return "text/plain; charset=utf-8" }
+
+const logoState = `
+; the state of the turtle as a list of its position, heading, pen and color
+to savestate
+  output (list pos heading pendownp pencolor)
+end
+
+to restorestate :state
+  penup
+  setpos first :state
+  setheading item 2 :state
+  setpencolor item 4 :state
+  if item 3 :state [pendown]
+end
+
+; keepturtle ends a branch, it outputs the turtle as the only one the branch ends with
+to keepturtle
+  output (list savestate)
+end
+`
+
+const logoFork = `
+; forkturtles runs every branch from the current turtle and outputs the turtles they end with
+to forkturtles :branches
+  localmake "start savestate
+  localmake "ended []
+  foreach :branches [
+    restorestate :start
+    make "ended sentence :ended run (list ?)
+  ]
+  output :ended
+end
+`
+
+const logoRepeat = `
+; repeatturtles runs every body on every turtle count times, so turtles multiply when there are several bodies.
+; With spawn every iteration releases its turtles to then, otherwise the turtles of the last iteration are released.
+to repeatturtles :count :spawn :bodies :then
+  localmake "turtles (list savestate)
+  localmake "ended []
+  repeat :count [
+    localmake "next []
+    foreach :turtles [
+      localmake "state ?
+      foreach :bodies [
+        restorestate :state
+        make "next sentence :next run (list ?)
+      ]
+    ]
+    make "turtles :next
+    if :spawn [make "ended sentence :ended releaseturtles :turtles :then]
+  ]
+  if not :spawn [make "ended sentence :ended releaseturtles :turtles :then]
+  output :ended
+end
+
+to releaseturtles :turtles :then
+  localmake "ended []
+  foreach :turtles [
+    restorestate ?
+    make "ended sentence :ended run (list :then)
+  ]
+  output :ended
+end
+`
+
+func (Logo) Generate(p *Program) []byte {
+	g := logoGenerator{}
+	main := &writer{unit: "  "}
+	main.line("to main")
+	main.indent++
+	main.line("clearscreen")
+	main.line("pendown")
+	main.line("setpencolor [0 0 0]")
+	main.indent--
+	g.block(main, p.Main, false)
+	main.line("end")
+
+	var b strings.Builder
+	if title := comment(p.Title); title != "" {
+		fmt.Fprintf(&b, "; %s\n", title)
+	}
+	b.WriteString("; Generated by Turtle Graphics, load it and run main.\n\n")
+	b.WriteString(main.buf.String())
+	for _, procedure := range g.procedures {
+		b.WriteString("\n")
+		b.WriteString(procedure)
+	}
+	if g.forks || g.repeats {
+		b.WriteString(logoState)
+	}
+	if g.forks {
+		b.WriteString(logoFork)
+	}
+	if g.repeats {
+		b.WriteString(logoRepeat)
+	}
+	return []byte(b.String())
+}
+
+type logoGenerator struct {
+	procedures     []string
+	forks, repeats bool
+}
+
+// block writes the steps of a block. As a value, the block outputs the turtles it ends with,
+// otherwise they are ignored.
+func (g *logoGenerator) block(w *writer, b Block, value bool) {
+	w.indent++
+	defer func() { w.indent-- }()
+
+	ret := "ignore "
+	if value {
+		ret = "output "
+	}
+
+	for _, s := range b {
+		switch s.Op {
+		case OpMove:
+			w.line("forward " + number(s.Value))
+		case OpTurn:
+			w.line("right " + number(s.Value))
+		case OpPen:
+			if s.PenDown {
+				w.line("pendown")
+			} else {
+				w.line("penup")
+			}
+			r, gr, bl := rgb(s.Color)
+			w.line(fmt.Sprintf("setpencolor [%d %d %d]", r, gr, bl))
+		case OpFork:
+			g.forks = true
+			w.line(fmt.Sprintf("%sforkturtles [%s]", ret, g.procedureList(s.Branches)))
+			return
+		case OpRepeat:
+			if s.Simple() {
+				w.line(fmt.Sprintf("repeat %d [", s.Count))
+				g.block(w, s.Body[0], false)
+				w.line("]")
+				continue
+			}
+
+			g.repeats = true
+			bodies := g.procedureList(s.Body)
+			then := "keepturtle"
+			if len(s.Then) > 0 {
+				then = g.procedure(fork(s.Then))
+			}
+			w.line(fmt.Sprintf("%srepeatturtles %d \"%t [%s] \"%s", ret, s.Count, s.Spawn, bodies, then))
+			return
+		}
+	}
+
+	if value {
+		w.line("output (list savestate)")
+	}
+}
+
+// procedure writes a block as a procedure outputting the turtles it ends with and returns its name.
+func (g *logoGenerator) procedure(b Block) string {
+	name := fmt.Sprintf("branch%d", len(g.procedures)+1)
+	g.procedures = append(g.procedures, "")
+	index := len(g.procedures) - 1
+
+	w := &writer{unit: "  "}
+	w.line("to " + name)
+	g.block(w, b, true)
+	w.line("end")
+	g.procedures[index] = w.buf.String()
+	return name
+}
+
+func (g *logoGenerator) procedureList(blocks []Block) string {
+	names := make([]string, len(blocks))
+	for i, b := range blocks {
+		names[i] = g.procedure(b)
+	}
+	return strings.Join(names, " ")
+}
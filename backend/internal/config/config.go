@@ -3,18 +3,56 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Env      string
-	Server   ServerConfig
-	Database DatabaseConfig
-	Mail     MailConfig
-	JWT      JWTConfig
+	Env           string
+	Server        ServerConfig
+	Database      DatabaseConfig
+	Shards        ShardsConfig
+	Mail          MailConfig
+	JWT           JWTConfig
+	LTI           LTIConfig
+	OAuth         OAuthConfig
+	Featured      FeaturedConfig
+	Limits        LimitsConfig
+	Dumps         DumpsConfig
+	Crawler       CrawlerConfig
+	Login         LoginVerificationConfig
+	Tokens        TokensConfig
+	Webhooks      WebhooksConfig
+	Partitions    PartitionsConfig
+	Passwords     PasswordsConfig
+	Cache         CacheConfig
+	Guests        GuestsConfig
+	Signups       SignupsConfig
+	Render        RenderConfig
+	Shedding      LoadSheddingConfig
+	Imports       ImportsConfig
+	Compaction    CompactionConfig
+	Integrity     IntegrityConfig
+	Retention     RetentionConfig
+	IDs           IDsConfig
+	Quotas        QuotasConfig
+	Discover      DiscoverConfig
+	Notifications NotificationsConfig
+	Storage       StorageConfig
+	Avatars       AvatarsConfig
+	GRPC          GRPCConfig
+	Probes        ProbesConfig
+	Anomalies     AnomaliesConfig
+	RateLimits    RateLimitsConfig
+	Exports       ExportsConfig
+	Worker        WorkerConfig
+	Tasks         TasksConfig
+	Deletions     DeletionsConfig
 }
 
 type ServerConfig struct {
@@ -24,6 +62,46 @@ type ServerConfig struct {
 	WriteTimeout int
 	FrontendPath string
 	AllowOrigins []string
+	BodyLimit    string // largest request body, e.g. 2M, empty disables the limit
+	// TrustedProxies are the CIDR ranges of the reverse proxies whose X-Forwarded-For header gives the client IP,
+	// the address of the connection is the client IP when empty
+	TrustedProxies []string
+}
+
+// GRPCConfig holds the internal gRPC API, served to render workers and other services inside our network.
+type GRPCConfig struct {
+	Addr  string // address the API listens on, e.g. :9090, empty disables it
+	Token string // bearer token internal clients authenticate with
+}
+
+// ProbesConfig holds the identity of load balancer probes and uptime checkers, which aren't rate limited or counted as traffic.
+type ProbesConfig struct {
+	Token string // sent by probes in X-Probe-Token, empty disables the probe identity
+}
+
+// AnomaliesConfig holds when the analyzer flags a window of traffic as anomalous and whom it notifies about it.
+type AnomaliesConfig struct {
+	Interval           int      // in minutes, the window of traffic analyzed at a time, 0 disables the analyzer
+	RegistrationFactor int      // how many times the registrations of an average window of the last day make a spike
+	MinRegistrations   int      // registrations a window needs to have before it can make a spike
+	ASNHeader          string   // request header carrying the network (ASN) of the client set by the proxy or CDN, empty disables the likes check
+	LikesPerASN        int      // likes from the clients of one network in a window that make a surge
+	RouteErrors        int      // server errors of one route in a window that make a surge
+	WebhookURL         string   // notified about every new alert, empty disables the webhook
+	WebhookSecret      string   // signs the webhook requests
+	Emails             []string // addresses emailed about every new alert
+}
+
+// RateLimitsConfig holds the request budgets per minute of the rate limited routes, kept per IP for anonymous clients
+// and per user for signed-in ones. A budget of 0 disables its limit.
+type RateLimitsConfig struct {
+	RedisURL  string // Redis server the buckets are shared through by every instance, empty keeps them in memory
+	Auth      int    // signing in and refreshing sessions, shared by the auth routes
+	Reads     int    // reads of routes not limited otherwise, shared by all of them
+	Users     int    // every request of a signed-in user, shared by all signed-in routes on top of their own limit
+	Polled    int    // each route polled by the client
+	Sensitive int    // each route sending emails or checking codes
+	Costly    int    // each route creating resources for anonymous clients or processing uploads
 }
 
 type DatabaseConfig struct {
@@ -33,6 +111,22 @@ type DatabaseConfig struct {
 	Password string
 	Name     string
 	SSLMode  string
+	// MaxOpenConns caps the connection pool, 0 leaves it unlimited. Requests queue by priority while all connections are in use.
+	MaxOpenConns int
+	// OnlineMigrationsInterval is in minutes, how often pending online migrations are checked for, 0 disables them
+	OnlineMigrationsInterval int
+	// ReplicaURL is the connection string of a replica reads are served from in read-only mode.
+	// Without one, read-only mode keeps reading from the primary database and only turns writes away.
+	ReplicaURL string
+	// ReadOnly starts the API in read-only mode, e.g. instances started while the primary database is failing over
+	ReadOnly bool
+}
+
+// ShardsConfig holds the regional databases user data can live in besides the primary database.
+// Users without a region, or in the home region, are served by the primary database.
+type ShardsConfig struct {
+	Home string            // region the primary database is located in
+	URLs map[string]string // connection URL of each regional database by region
 }
 
 type MailConfig struct {
@@ -42,11 +136,275 @@ type MailConfig struct {
 	Password  string
 	From      string
 	ClientURL string
+	Capture   bool // emails are kept in memory for GET /api/testing/mailbox instead of being sent, never in production
+	// UnsubscribeSecret signs the unsubscribe links of optional emails, JWT_SECRET when not set
+	UnsubscribeSecret string
 }
 
 type JWTConfig struct {
-	Secret     string
-	ExpireTime int // in hours
+	Secret       string   // HS256 secret, used to sign tokens when no key directory is set
+	ExpireTime   int      // in hours
+	KeysDir      string   // directory of PEM encoded RSA signing keys named <kid>.pem, enables RS256 and key rotation
+	PrimaryKeyID string   // key new tokens are signed with, the newest key in KeysDir when empty
+	GraceKeyIDs  []string // older keys still accepted when verifying tokens
+	// SecretGraceUntil is when tokens signed with Secret stop being accepted once KeysDir is set,
+	// they are rejected right away when zero
+	SecretGraceUntil time.Time
+	// ImpersonationTTL is in minutes, how long a token issued to support staff acting as a user is valid
+	ImpersonationTTL int
+	// EmbedTTL is in minutes, how long a token letting other sites embed a project is valid
+	EmbedTTL int
+}
+
+type LTIConfig struct {
+	ToolURL        string // public URL of the API, used for LTI redirect URIs
+	PrivateKeyPath string // RSA private key (PEM) used to sign LTI messages
+	KeyID          string
+}
+
+type OAuthConfig struct {
+	CallbackURL string // public URL of the API, used for provider redirect URIs
+	Google      OAuthProviderConfig
+	GitHub      OAuthProviderConfig
+}
+
+// OAuthProviderConfig holds the client credentials of a social login provider.
+// A provider is disabled when its client ID is empty.
+type OAuthProviderConfig struct {
+	ClientID     string
+	ClientSecret string
+}
+
+type FeaturedConfig struct {
+	Slots            int // number of projects featured at once
+	RotationInterval int // in minutes, 0 disables automatic rotation
+	GracePeriod      int // in hours, a featured project is never rotated out sooner
+	InactivityWindow int // in hours, the window engagement is measured in
+	MinLikes         int // likes within the window needed to keep the slot
+	Duration         int // in hours, how long backfilled projects are featured for
+}
+
+type LimitsConfig struct {
+	ForksPerHour  int // 0 disables the limit
+	ForksPerDay   int // 0 disables the limit
+	GuestProjects int // projects a guest account can hold before it has to register, 0 disables the limit
+	FlowNodes     int // nodes a project flow can have, 0 disables the limit
+	FlowBytes     int // size of a submitted project flow, 0 disables the limit
+	// UniqueTitles refuses a project title the creator already gave another of their projects, ignoring case
+	UniqueTitles bool
+}
+
+type WebhooksConfig struct {
+	CheckInterval int // in minutes, how often milestones are checked, 0 disables project webhooks
+	Timeout       int // in seconds, per delivery attempt
+	MaxAttempts   int // how often a delivery is tried before it is recorded as failed
+}
+
+// PasswordsConfig holds the password policy.
+type PasswordsConfig struct {
+	MinLength int
+	MinScore  int // 0-4, estimated strength a password needs
+	// BreachedFilter is the path of a bloom filter of breached password hashes built with cmd/hibp-bloom, empty disables the check
+	BreachedFilter string
+}
+
+// CacheConfig holds the in-memory cache of feature flags and announcements.
+type CacheConfig struct {
+	// TTL is in seconds, how long a cached value is used at most when an invalidation is missed, 0 disables caching
+	TTL int
+}
+
+// GuestsConfig holds the anonymous guest accounts people can try the editor with before registering.
+type GuestsConfig struct {
+	TTL             time.Duration // how long a guest account and its projects are kept unless claimed
+	CleanupInterval int           // in minutes, how often expired guests are deleted, 0 disables the cleanup
+}
+
+// SignupsConfig holds the registration budgets, subnets and email domains on the allowlist are exempt.
+type SignupsConfig struct {
+	PerSubnetPerHour int // signups from a single /24 (IPv4) or /48 (IPv6) subnet per hour, 0 disables the limit
+	PerDomainPerHour int // signups with email addresses of a single domain per hour, 0 disables the limit
+}
+
+// LoadSheddingConfig holds the load at which low-priority endpoints start answering 503.
+type LoadSheddingConfig struct {
+	MaxInFlight  int           // requests in flight on this instance, 0 disables the limit
+	MaxLatency   time.Duration // average latency of recent requests, 0 disables the limit
+	QueueTimeout time.Duration // how long requests wait for admission while the database pool is saturated
+}
+
+// ImportsConfig holds the limits of project import files, which can be uploaded in chunks when they exceed the body limit.
+type ImportsConfig struct {
+	MaxBytes        int           // size of an import file
+	ChunkBytes      int           // size of a single chunk of an upload
+	UploadTTL       time.Duration // how long an unfinished upload can be resumed
+	CleanupInterval int           // in minutes, how often expired uploads are deleted, 0 disables the cleanup
+}
+
+// ExportsConfig holds how the copies of their data users ask for are prepared and kept.
+type ExportsConfig struct {
+	Interval int           // in minutes, how often pending exports are prepared and expired ones deleted, 0 disables exports
+	TTL      time.Duration // how long a prepared export can be downloaded
+}
+
+// CompactionConfig holds the schedule and pacing of the job that re-serializes stored project data canonically.
+type CompactionConfig struct {
+	Interval  int           // in hours, 0 disables the scheduled compaction
+	BatchSize int           // projects updated per transaction
+	Pause     time.Duration // between batches
+}
+
+// IntegrityConfig holds the schedule and pacing of the scan comparing stored project data with its checksums.
+type IntegrityConfig struct {
+	Interval  int           // in hours, 0 disables the scheduled scan
+	BatchSize int           // projects read per query
+	Pause     time.Duration // between batches
+}
+
+// RetentionConfig holds the retention periods of the data classes purged row by row, in days.
+// A period of 0 keeps the data forever. The partitioned tables are pruned by the partition maintenance instead.
+type RetentionConfig struct {
+	Interval          int // in hours, how often expired data is purged, 0 disables the scheduled purge
+	BatchSize         int // rows deleted per statement
+	LoginHistory      int // trusted login locations not seen for this long
+	ProjectViews      int // daily view records, kept at least as long as the trending window
+	TakenDownProjects int // projects hidden by moderators
+	Emails            int // records of the emails sent
+}
+
+// QuotasConfig holds what the users of each role can store and run. Moderators get the premium quota.
+type QuotasConfig struct {
+	User    QuotaConfig
+	Premium QuotaConfig
+	Admin   QuotaConfig
+}
+
+// QuotaConfig bounds what the users of a role can store and run, 0 disables a limit.
+// Runs are bounded by the render limits for everyone.
+type QuotaConfig struct {
+	Projects int // projects a user can own
+	Bytes    int // size of the flow data of a single project
+	Storage  int // size of the flow data and thumbnails of all the projects a user owns
+
+	RunSteps     int // instructions a single server-side run can take
+	RunMillis    int // how long a single run can take, in milliseconds
+	RunSegments  int // lines a single run can draw, which are held in memory
+	ComputeDaily int // instructions all the runs of a user can take per day (UTC)
+}
+
+// DiscoverConfig holds which public projects the discover endpoint picks from.
+type DiscoverConfig struct {
+	MinLikes int // likes a project needs to be picked
+	SeenDays int // projects the viewer viewed within as many days are skipped, views are kept for RETENTION_PROJECT_VIEWS_DAYS
+}
+
+// NotificationsConfig holds how the activity on projects is notified to their creators.
+type NotificationsConfig struct {
+	DigestInterval int // in minutes, how often due email digests are sent, 0 disables digests
+}
+
+// StorageConfig holds where uploaded files, like avatars, are stored.
+type StorageConfig struct {
+	Driver string // local or s3
+	Dir    string // root directory of the local driver
+	S3     S3Config
+}
+
+// S3Config holds the bucket of the s3 driver, on AWS or any S3 compatible service.
+type S3Config struct {
+	Endpoint  string // e.g. https://s3.eu-central-1.amazonaws.com
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool // addresses the bucket in the path instead of the host name, for services like MinIO
+}
+
+// AvatarsConfig holds the limits of uploaded avatars.
+type AvatarsConfig struct {
+	MaxBytes  int // size of an uploaded image
+	MaxPixels int // width times height of an uploaded image, checked before it is decoded
+}
+
+// IDsConfig holds how the IDs of new projects are generated.
+type IDsConfig struct {
+	Version int // UUID version, 7 (time-ordered) or 4 (random, as IDs were before)
+}
+
+// RenderConfig holds the limits of server-side program execution and the size of the rendered thumbnails.
+type RenderConfig struct {
+	MaxInstructions int           // turtle commands a program can step through over all its turtles
+	Timeout         time.Duration // how long a single program can run
+	Width           int           // of thumbnails, in pixels
+	Height          int           // of thumbnails, in pixels
+	// Workers leaves rendering to render worker processes (cmd/worker), the API only queues thumbnails
+	// and waits up to WorkerWait for one to be rendered.
+	Workers    bool
+	WorkerWait time.Duration
+}
+
+// DeletionsConfig holds the deletion of accounts their owners asked to delete.
+type DeletionsConfig struct {
+	GracePeriod time.Duration // time the owner has to change their mind by logging in
+	Interval    int           // in minutes, how often accounts past their grace period are deleted, 0 disables it
+}
+
+// WorkerConfig holds a render worker process.
+type WorkerConfig struct {
+	Addr        string        // address health checks and metrics are served on, empty disables them
+	Concurrency int           // jobs rendered at once, 0 renders as many as there are CPUs
+	Poll        time.Duration // how long an idle worker waits before looking for jobs again
+	Heartbeat   time.Duration // how often the worker reports itself, peers missing 3 heartbeats are removed
+	MaxAttempts int           // claims of a job before its program is given up on as exceeding the limits
+}
+
+// TasksConfig holds the task runner of the API, which sends queued emails among other background tasks.
+type TasksConfig struct {
+	Concurrency int           // tasks run at once by an instance, 0 leaves the queue to other instances
+	Poll        time.Duration // how long an idle runner waits before looking for tasks again
+	Timeout     time.Duration // how long a task can run, tasks claimed for twice as long are requeued
+	MaxAttempts int           // runs of a task before it's dead
+	Backoff     time.Duration // wait before the first retry, doubled on every retry after it
+	MaxBackoff  time.Duration // longest wait between retries
+}
+
+// PartitionsConfig holds the maintenance of the tables partitioned by month.
+type PartitionsConfig struct {
+	Interval int // in hours, how often partitions are created and pruned, 0 disables the maintenance
+	Ahead    int // number of future monthly partitions kept ready
+	// months of data kept besides the current month, 0 keeps everything
+	EventsRetention        int
+	AuditRetention         int
+	NotificationsRetention int
+}
+
+type DumpsConfig struct {
+	Dir      string // directory the dump files are written to
+	Interval int    // in hours, 0 disables automatic dumps
+	Keep     int    // number of most recent dumps kept on disk
+}
+
+type CrawlerConfig struct {
+	BotRequestsPerMinute  int // request budget of a single crawler
+	UserRequestsPerMinute int // request budget of a single regular client
+	BehaviorThreshold     int // unauthenticated requests per minute after which a client is treated as a crawler
+	CacheTTL              int // in seconds, how long public responses are served from cache to crawlers
+}
+
+type LoginVerificationConfig struct {
+	CountryHeader string // request header carrying the client country set by the proxy or CDN, empty disables verification
+	CodeTTL       int    // in minutes, how long an emailed login code is valid
+	// MagicLinksPerHour is how many login links can be requested for a single email address per hour, 0 disables the limit
+	MagicLinksPerHour int
+}
+
+// TokensConfig holds how long the tokens of each scope are valid.
+type TokensConfig struct {
+	ActivationTTL   time.Duration
+	ResetTTL        time.Duration
+	DeactivationTTL time.Duration
+	RefreshTTL      time.Duration
+	MagicLinkTTL    time.Duration
 }
 
 func Load(envFile string) (*Config, error) {
@@ -61,43 +419,365 @@ func Load(envFile string) (*Config, error) {
 	cfg := &Config{
 		Env: GetEnv("ENV", "DEV"), // DEV | PROD
 		Server: ServerConfig{
-			Port:         GetEnvAsInt("SERVER_PORT", 8080),
-			Host:         GetEnv("SERVER_HOST", ""),
-			ReadTimeout:  GetEnvAsInt("SERVER_READ_TIMEOUT", 15),
-			WriteTimeout: GetEnvAsInt("SERVER_WRITE_TIMEOUT", 15),
-			FrontendPath: GetEnv("CLIENT_PATH", ""),
-			AllowOrigins: GetEnvAsSlice("ALLOW_ORIGINS", []string{"*"}),
+			Port:           GetEnvAsInt("SERVER_PORT", 8080),
+			Host:           GetEnv("SERVER_HOST", ""),
+			ReadTimeout:    GetEnvAsInt("SERVER_READ_TIMEOUT", 15),
+			WriteTimeout:   GetEnvAsInt("SERVER_WRITE_TIMEOUT", 15),
+			FrontendPath:   GetEnv("CLIENT_PATH", ""),
+			AllowOrigins:   GetEnvAsSlice("ALLOW_ORIGINS", []string{"*"}),
+			BodyLimit:      GetEnv("SERVER_BODY_LIMIT", "2M"),
+			TrustedProxies: GetEnvAsSlice("TRUSTED_PROXIES", []string{}),
+		},
+		GRPC: GRPCConfig{
+			Addr:  GetEnv("GRPC_ADDR", ""),
+			Token: GetEnv("GRPC_TOKEN", ""),
+		},
+		Probes: ProbesConfig{
+			Token: GetEnv("PROBE_TOKEN", ""),
+		},
+		RateLimits: RateLimitsConfig{
+			RedisURL:  GetEnv("RATE_LIMIT_REDIS_URL", ""),
+			Auth:      GetEnvAsInt("RATE_LIMIT_AUTH", 30),
+			Reads:     GetEnvAsInt("RATE_LIMIT_READS", 300),
+			Users:     GetEnvAsInt("RATE_LIMIT_USERS", 600),
+			Polled:    GetEnvAsInt("RATE_LIMIT_POLLED", 20),
+			Sensitive: GetEnvAsInt("RATE_LIMIT_SENSITIVE", 10),
+			Costly:    GetEnvAsInt("RATE_LIMIT_COSTLY", 5),
+		},
+		Anomalies: AnomaliesConfig{
+			Interval:           GetEnvAsInt("ANOMALY_INTERVAL", 5),
+			RegistrationFactor: GetEnvAsInt("ANOMALY_REGISTRATION_FACTOR", 5),
+			MinRegistrations:   GetEnvAsInt("ANOMALY_MIN_REGISTRATIONS", 20),
+			ASNHeader:          GetEnv("ANOMALY_ASN_HEADER", ""),
+			LikesPerASN:        GetEnvAsInt("ANOMALY_LIKES_PER_ASN", 200),
+			RouteErrors:        GetEnvAsInt("ANOMALY_ROUTE_ERRORS", 50),
+			WebhookURL:         GetEnv("ANOMALY_WEBHOOK_URL", ""),
+			WebhookSecret:      GetEnv("ANOMALY_WEBHOOK_SECRET", ""),
+			Emails:             GetEnvAsSlice("ANOMALY_EMAILS", []string{}),
 		},
 		Database: DatabaseConfig{
-			Host:     GetEnv("DB_HOST", "localhost"),
-			Port:     GetEnvAsInt("DB_PORT", 5432),
-			User:     GetEnv("DB_USER", "postgres"),
-			Password: GetEnv("DB_PASSWORD", ""),
-			Name:     GetEnv("DB_NAME", "turtlegraphics"),
-			SSLMode:  GetEnv("DB_SSLMODE", "disable"),
+			Host:                     GetEnv("DB_HOST", "localhost"),
+			Port:                     GetEnvAsInt("DB_PORT", 5432),
+			User:                     GetEnv("DB_USER", "postgres"),
+			Password:                 GetEnv("DB_PASSWORD", ""),
+			Name:                     GetEnv("DB_NAME", "turtlegraphics"),
+			SSLMode:                  GetEnv("DB_SSLMODE", "disable"),
+			MaxOpenConns:             GetEnvAsInt("DB_MAX_OPEN_CONNS", 25),
+			OnlineMigrationsInterval: GetEnvAsInt("DB_ONLINE_MIGRATIONS_INTERVAL", 10),
+			ReplicaURL:               GetEnv("DB_REPLICA_URL", ""),
+			ReadOnly:                 GetEnvAsBool("READ_ONLY_MODE", false),
 		},
+		Shards: loadShards(),
 		Mail: MailConfig{
-			Host:      GetEnv("MAIL_HOST", "smtp.mailtrap.io"),
-			Port:      GetEnvAsInt("MAIL_PORT", 2525),
-			Username:  GetEnv("MAIL_USERNAME", ""),
-			Password:  GetEnv("MAIL_PASSWORD", ""),
-			From:      GetEnv("MAIL_FROM", "noreply@turtlegraphics.com"),
-			ClientURL: GetEnv("CLIENT_URL", "http://website.com"),
+			Host:              GetEnv("MAIL_HOST", "smtp.mailtrap.io"),
+			Port:              GetEnvAsInt("MAIL_PORT", 2525),
+			Username:          GetEnv("MAIL_USERNAME", ""),
+			Password:          GetEnv("MAIL_PASSWORD", ""),
+			From:              GetEnv("MAIL_FROM", "noreply@turtlegraphics.com"),
+			ClientURL:         GetEnv("CLIENT_URL", "http://website.com"),
+			Capture:           GetEnvAsBool("MAIL_CAPTURE", false),
+			UnsubscribeSecret: GetEnv("MAIL_UNSUBSCRIBE_SECRET", ""),
 		},
 		JWT: JWTConfig{
-			Secret:     GetEnv("JWT_SECRET", ""),
-			ExpireTime: GetEnvAsInt("JWT_EXPIRE_TIME", 24), // 24 hours default
+			Secret:           GetEnv("JWT_SECRET", ""),
+			ExpireTime:       GetEnvAsInt("JWT_EXPIRE_TIME", 24), // 24 hours default
+			KeysDir:          GetEnv("JWT_KEYS_DIR", ""),
+			PrimaryKeyID:     GetEnv("JWT_PRIMARY_KEY_ID", ""),
+			GraceKeyIDs:      GetEnvAsSlice("JWT_GRACE_KEY_IDS", []string{}),
+			SecretGraceUntil: GetEnvAsTime("JWT_SECRET_GRACE_UNTIL", time.Time{}),
+			ImpersonationTTL: GetEnvAsInt("JWT_IMPERSONATION_TTL", 15),
+			EmbedTTL:         GetEnvAsInt("JWT_EMBED_TTL", 60),
+		},
+		LTI: LTIConfig{
+			ToolURL:        GetEnv("LTI_TOOL_URL", "http://localhost:8080"),
+			PrivateKeyPath: GetEnv("LTI_PRIVATE_KEY_PATH", ""),
+			KeyID:          GetEnv("LTI_KEY_ID", "nodeturtle-lti"),
+		},
+		OAuth: OAuthConfig{
+			CallbackURL: GetEnv("OAUTH_CALLBACK_URL", "http://localhost:8080"),
+			Google: OAuthProviderConfig{
+				ClientID:     GetEnv("GOOGLE_CLIENT_ID", ""),
+				ClientSecret: GetEnv("GOOGLE_CLIENT_SECRET", ""),
+			},
+			GitHub: OAuthProviderConfig{
+				ClientID:     GetEnv("GITHUB_CLIENT_ID", ""),
+				ClientSecret: GetEnv("GITHUB_CLIENT_SECRET", ""),
+			},
+		},
+		Featured: FeaturedConfig{
+			Slots:            GetEnvAsInt("FEATURED_SLOTS", 6),
+			RotationInterval: GetEnvAsInt("FEATURED_ROTATION_INTERVAL", 60),
+			GracePeriod:      GetEnvAsInt("FEATURED_GRACE_PERIOD", 48),
+			InactivityWindow: GetEnvAsInt("FEATURED_INACTIVITY_WINDOW", 72),
+			MinLikes:         GetEnvAsInt("FEATURED_MIN_LIKES", 1),
+			Duration:         GetEnvAsInt("FEATURED_DURATION", 168),
+		},
+		Limits: LimitsConfig{
+			ForksPerHour:  GetEnvAsInt("FORK_LIMIT_HOURLY", 10),
+			ForksPerDay:   GetEnvAsInt("FORK_LIMIT_DAILY", 50),
+			GuestProjects: GetEnvAsInt("GUEST_PROJECT_LIMIT", 3),
+			FlowNodes:     GetEnvAsInt("FLOW_NODE_LIMIT", 1000),
+			FlowBytes:     GetEnvAsInt("FLOW_SIZE_LIMIT", 1<<20),
+			UniqueTitles:  GetEnvAsBool("PROJECT_UNIQUE_TITLES", false),
+		},
+		Dumps: DumpsConfig{
+			Dir:      GetEnv("DUMPS_DIR", "./dumps"),
+			Interval: GetEnvAsInt("DUMPS_INTERVAL", 24),
+			Keep:     GetEnvAsInt("DUMPS_KEEP", 7),
+		},
+		Crawler: CrawlerConfig{
+			BotRequestsPerMinute:  GetEnvAsInt("CRAWLER_BOT_RPM", 30),
+			UserRequestsPerMinute: GetEnvAsInt("CRAWLER_USER_RPM", 300),
+			BehaviorThreshold:     GetEnvAsInt("CRAWLER_BEHAVIOR_THRESHOLD", 120),
+			CacheTTL:              GetEnvAsInt("CRAWLER_CACHE_TTL", 300),
+		},
+		Login: LoginVerificationConfig{
+			CountryHeader:     GetEnv("LOGIN_COUNTRY_HEADER", ""),
+			CodeTTL:           GetEnvAsInt("LOGIN_CODE_TTL", 15),
+			MagicLinksPerHour: GetEnvAsInt("LOGIN_MAGIC_LINKS_PER_HOUR", 5),
+		},
+		Tokens: TokensConfig{
+			ActivationTTL:   GetEnvAsDuration("TOKEN_TTL_ACTIVATION", 72*time.Hour),
+			ResetTTL:        GetEnvAsDuration("TOKEN_TTL_RESET", 30*time.Minute),
+			DeactivationTTL: GetEnvAsDuration("TOKEN_TTL_DEACTIVATION", 15*time.Minute),
+			RefreshTTL:      GetEnvAsDuration("TOKEN_TTL_REFRESH", 168*time.Hour),
+			MagicLinkTTL:    GetEnvAsDuration("TOKEN_TTL_MAGIC_LINK", 15*time.Minute),
+		},
+		Webhooks: WebhooksConfig{
+			CheckInterval: GetEnvAsInt("WEBHOOKS_CHECK_INTERVAL", 1),
+			Timeout:       GetEnvAsInt("WEBHOOKS_TIMEOUT", 10),
+			MaxAttempts:   GetEnvAsInt("WEBHOOKS_MAX_ATTEMPTS", 3),
+		},
+		Passwords: PasswordsConfig{
+			MinLength:      GetEnvAsInt("PASSWORD_MIN_LENGTH", 8),
+			MinScore:       GetEnvAsInt("PASSWORD_MIN_SCORE", 2),
+			BreachedFilter: GetEnv("PASSWORD_BREACHED_FILTER", ""),
+		},
+		Partitions: PartitionsConfig{
+			Interval:               GetEnvAsInt("PARTITIONS_INTERVAL", 6),
+			Ahead:                  GetEnvAsInt("PARTITIONS_AHEAD", 2),
+			EventsRetention:        GetEnvAsInt("PARTITIONS_EVENTS_RETENTION", 0),
+			AuditRetention:         GetEnvAsInt("PARTITIONS_AUDIT_RETENTION", 24),
+			NotificationsRetention: GetEnvAsInt("PARTITIONS_NOTIFICATIONS_RETENTION", 3),
+		},
+		Cache: CacheConfig{
+			TTL: GetEnvAsInt("CACHE_TTL", 300),
+		},
+		Guests: GuestsConfig{
+			TTL:             GetEnvAsDuration("GUEST_TTL", 24*time.Hour),
+			CleanupInterval: GetEnvAsInt("GUEST_CLEANUP_INTERVAL", 60),
+		},
+		Signups: SignupsConfig{
+			PerSubnetPerHour: GetEnvAsInt("SIGNUP_LIMIT_SUBNET_HOURLY", 10),
+			PerDomainPerHour: GetEnvAsInt("SIGNUP_LIMIT_DOMAIN_HOURLY", 100),
+		},
+		Render: RenderConfig{
+			MaxInstructions: GetEnvAsInt("RENDER_MAX_INSTRUCTIONS", 200000),
+			Timeout:         GetEnvAsDuration("RENDER_TIMEOUT", 2*time.Second),
+			Width:           GetEnvAsInt("THUMBNAIL_WIDTH", 320),
+			Height:          GetEnvAsInt("THUMBNAIL_HEIGHT", 240),
+			Workers:         GetEnvAsBool("RENDER_WORKERS", false),
+			WorkerWait:      GetEnvAsDuration("RENDER_WORKER_WAIT", 3*time.Second),
+		},
+		Deletions: DeletionsConfig{
+			GracePeriod: GetEnvAsDuration("ACCOUNT_DELETION_GRACE", 30*24*time.Hour),
+			Interval:    GetEnvAsInt("ACCOUNT_DELETION_INTERVAL", 60),
+		},
+		Worker: WorkerConfig{
+			Addr:        GetEnv("WORKER_ADDR", ":9100"),
+			Concurrency: GetEnvAsInt("WORKER_CONCURRENCY", 0),
+			Poll:        GetEnvAsDuration("WORKER_POLL", 500*time.Millisecond),
+			Heartbeat:   GetEnvAsDuration("WORKER_HEARTBEAT", 10*time.Second),
+			MaxAttempts: GetEnvAsInt("WORKER_MAX_ATTEMPTS", 3),
+		},
+		Tasks: TasksConfig{
+			Concurrency: GetEnvAsInt("TASK_CONCURRENCY", 4),
+			Poll:        GetEnvAsDuration("TASK_POLL", time.Second),
+			Timeout:     GetEnvAsDuration("TASK_TIMEOUT", time.Minute),
+			MaxAttempts: GetEnvAsInt("TASK_MAX_ATTEMPTS", 8),
+			Backoff:     GetEnvAsDuration("TASK_BACKOFF", 30*time.Second),
+			MaxBackoff:  GetEnvAsDuration("TASK_MAX_BACKOFF", time.Hour),
+		},
+		Shedding: LoadSheddingConfig{
+			MaxInFlight:  GetEnvAsInt("LOAD_SHED_MAX_IN_FLIGHT", 200),
+			MaxLatency:   GetEnvAsDuration("LOAD_SHED_MAX_LATENCY", 2*time.Second),
+			QueueTimeout: GetEnvAsDuration("LOAD_SHED_QUEUE_TIMEOUT", 5*time.Second),
+		},
+		Imports: ImportsConfig{
+			MaxBytes:        GetEnvAsInt("IMPORT_SIZE_LIMIT", 16<<20),
+			ChunkBytes:      GetEnvAsInt("IMPORT_CHUNK_SIZE", 1<<20),
+			UploadTTL:       GetEnvAsDuration("IMPORT_UPLOAD_TTL", 24*time.Hour),
+			CleanupInterval: GetEnvAsInt("IMPORT_CLEANUP_INTERVAL", 60),
+		},
+		Exports: ExportsConfig{
+			Interval: GetEnvAsInt("EXPORTS_INTERVAL", 5),
+			TTL:      GetEnvAsDuration("EXPORTS_TTL", 7*24*time.Hour),
+		},
+		Compaction: CompactionConfig{
+			Interval:  GetEnvAsInt("PROJECT_COMPACTION_INTERVAL", 0),
+			BatchSize: GetEnvAsInt("PROJECT_COMPACTION_BATCH_SIZE", 500),
+			Pause:     GetEnvAsDuration("PROJECT_COMPACTION_PAUSE", 100*time.Millisecond),
+		},
+		Integrity: IntegrityConfig{
+			Interval:  GetEnvAsInt("INTEGRITY_SCAN_INTERVAL", 24),
+			BatchSize: GetEnvAsInt("INTEGRITY_SCAN_BATCH_SIZE", 1000),
+			Pause:     GetEnvAsDuration("INTEGRITY_SCAN_PAUSE", 100*time.Millisecond),
+		},
+		Retention: RetentionConfig{
+			Interval:          GetEnvAsInt("RETENTION_INTERVAL", 24),
+			BatchSize:         GetEnvAsInt("RETENTION_BATCH_SIZE", 1000),
+			LoginHistory:      GetEnvAsInt("RETENTION_LOGIN_HISTORY_DAYS", 365),
+			ProjectViews:      GetEnvAsInt("RETENTION_PROJECT_VIEWS_DAYS", 30),
+			TakenDownProjects: GetEnvAsInt("RETENTION_TAKEN_DOWN_PROJECTS_DAYS", 0),
+			Emails:            GetEnvAsInt("RETENTION_EMAILS_DAYS", 90),
+		},
+		Quotas: QuotasConfig{
+			User: QuotaConfig{
+				Projects:     GetEnvAsInt("QUOTA_USER_PROJECTS", 50),
+				Bytes:        GetEnvAsInt("QUOTA_USER_BYTES", 256<<10),
+				Storage:      GetEnvAsInt("QUOTA_USER_STORAGE", 10<<20),
+				RunSteps:     GetEnvAsInt("QUOTA_USER_RUN_STEPS", 50000),
+				RunMillis:    GetEnvAsInt("QUOTA_USER_RUN_MILLIS", 500),
+				RunSegments:  GetEnvAsInt("QUOTA_USER_RUN_SEGMENTS", 20000),
+				ComputeDaily: GetEnvAsInt("QUOTA_USER_COMPUTE_DAILY", 2000000),
+			},
+			Premium: QuotaConfig{
+				Projects:     GetEnvAsInt("QUOTA_PREMIUM_PROJECTS", 500),
+				Bytes:        GetEnvAsInt("QUOTA_PREMIUM_BYTES", 1<<20),
+				Storage:      GetEnvAsInt("QUOTA_PREMIUM_STORAGE", 200<<20),
+				RunSteps:     GetEnvAsInt("QUOTA_PREMIUM_RUN_STEPS", 200000),
+				RunMillis:    GetEnvAsInt("QUOTA_PREMIUM_RUN_MILLIS", 2000),
+				RunSegments:  GetEnvAsInt("QUOTA_PREMIUM_RUN_SEGMENTS", 100000),
+				ComputeDaily: GetEnvAsInt("QUOTA_PREMIUM_COMPUTE_DAILY", 20000000),
+			},
+			Admin: QuotaConfig{
+				Projects:     GetEnvAsInt("QUOTA_ADMIN_PROJECTS", 0),
+				Bytes:        GetEnvAsInt("QUOTA_ADMIN_BYTES", 0),
+				Storage:      GetEnvAsInt("QUOTA_ADMIN_STORAGE", 0),
+				RunSteps:     GetEnvAsInt("QUOTA_ADMIN_RUN_STEPS", 0),
+				RunMillis:    GetEnvAsInt("QUOTA_ADMIN_RUN_MILLIS", 0),
+				RunSegments:  GetEnvAsInt("QUOTA_ADMIN_RUN_SEGMENTS", 0),
+				ComputeDaily: GetEnvAsInt("QUOTA_ADMIN_COMPUTE_DAILY", 0),
+			},
+		},
+		Discover: DiscoverConfig{
+			MinLikes: GetEnvAsInt("DISCOVER_MIN_LIKES", 5),
+			SeenDays: GetEnvAsInt("DISCOVER_SEEN_DAYS", 30),
+		},
+		Notifications: NotificationsConfig{
+			DigestInterval: GetEnvAsInt("NOTIFICATIONS_DIGEST_INTERVAL", 60),
+		},
+		IDs: IDsConfig{
+			Version: GetEnvAsInt("ID_UUID_VERSION", 7),
+		},
+		Storage: StorageConfig{
+			Driver: GetEnv("STORAGE_DRIVER", "local"),
+			Dir:    GetEnv("STORAGE_DIR", "./uploads"),
+			S3: S3Config{
+				Endpoint:  GetEnv("STORAGE_S3_ENDPOINT", ""),
+				Region:    GetEnv("STORAGE_S3_REGION", "us-east-1"),
+				Bucket:    GetEnv("STORAGE_S3_BUCKET", ""),
+				AccessKey: GetEnv("STORAGE_S3_ACCESS_KEY", ""),
+				SecretKey: GetEnv("STORAGE_S3_SECRET_KEY", ""),
+				PathStyle: GetEnvAsBool("STORAGE_S3_PATH_STYLE", false),
+			},
+		},
+		Avatars: AvatarsConfig{
+			MaxBytes:  GetEnvAsInt("AVATAR_MAX_BYTES", 1<<20),
+			MaxPixels: GetEnvAsInt("AVATAR_MAX_PIXELS", 4096*4096),
 		},
 	}
 
 	// Validate required fields
-	if cfg.JWT.Secret == "" {
-		return nil, errors.New("JWT_SECRET must be set")
+	if cfg.JWT.Secret == "" && cfg.JWT.KeysDir == "" {
+		return nil, errors.New("JWT_SECRET or JWT_KEYS_DIR must be set")
+	}
+
+	// unsubscribe links must keep working, they're in emails sent long ago
+	if cfg.Mail.UnsubscribeSecret == "" {
+		cfg.Mail.UnsubscribeSecret = cfg.JWT.Secret
+	}
+	if cfg.Mail.UnsubscribeSecret == "" {
+		return nil, errors.New("MAIL_UNSUBSCRIBE_SECRET must be set when JWT_SECRET isn't")
+	}
+
+	for _, cidr := range cfg.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return nil, fmt.Errorf("TRUSTED_PROXIES must list CIDR ranges, %q isn't one", cidr)
+		}
+	}
+
+	for region, url := range cfg.Shards.URLs {
+		if url == "" {
+			return nil, fmt.Errorf("DB_SHARD_%s_URL must be set", strings.ToUpper(region))
+		}
+	}
+
+	// the test mailbox hands out activation and reset links to anyone
+	if cfg.Mail.Capture && cfg.Env == "PROD" {
+		return nil, errors.New("MAIL_CAPTURE can't be enabled in production")
+	}
+
+	// the trending score reads the views of the last two weeks
+	if cfg.Retention.ProjectViews > 0 && cfg.Retention.ProjectViews < 14 {
+		return nil, errors.New("RETENTION_PROJECT_VIEWS_DAYS must be at least 14")
+	}
+
+	if cfg.IDs.Version != 4 && cfg.IDs.Version != 7 {
+		return nil, errors.New("ID_UUID_VERSION must be 4 or 7")
+	}
+
+	if cfg.GRPC.Addr != "" && len(cfg.GRPC.Token) < 32 {
+		return nil, errors.New("GRPC_TOKEN must be at least 32 characters long when GRPC_ADDR is set")
+	}
+
+	if cfg.Probes.Token != "" && len(cfg.Probes.Token) < 32 {
+		return nil, errors.New("PROBE_TOKEN must be at least 32 characters long")
+	}
+
+	if cfg.Anomalies.WebhookURL != "" && len(cfg.Anomalies.WebhookSecret) < 16 {
+		return nil, errors.New("ANOMALY_WEBHOOK_SECRET must be at least 16 characters long when ANOMALY_WEBHOOK_URL is set")
+	}
+
+	if cfg.Worker.Heartbeat <= 0 || cfg.Worker.Poll <= 0 || cfg.Worker.MaxAttempts < 1 {
+		return nil, errors.New("WORKER_HEARTBEAT and WORKER_POLL must be positive and WORKER_MAX_ATTEMPTS at least 1")
+	}
+
+	if cfg.Tasks.Poll <= 0 || cfg.Tasks.Timeout <= 0 || cfg.Tasks.Backoff <= 0 || cfg.Tasks.MaxAttempts < 1 {
+		return nil, errors.New("TASK_POLL, TASK_TIMEOUT and TASK_BACKOFF must be positive and TASK_MAX_ATTEMPTS at least 1")
+	}
+	if cfg.Tasks.MaxBackoff < cfg.Tasks.Backoff {
+		return nil, errors.New("TASK_MAX_BACKOFF must be at least TASK_BACKOFF")
+	}
+
+	switch cfg.Storage.Driver {
+	case "local":
+	case "s3":
+		if cfg.Storage.S3.Endpoint == "" || cfg.Storage.S3.Bucket == "" {
+			return nil, errors.New("STORAGE_S3_ENDPOINT and STORAGE_S3_BUCKET must be set")
+		}
+	default:
+		return nil, errors.New("STORAGE_DRIVER must be local or s3")
 	}
 
 	return cfg, nil
 }
 
+// loadShards reads the regional databases listed in DB_SHARDS, each connected to through DB_SHARD_<REGION>_URL.
+func loadShards() ShardsConfig {
+	shards := ShardsConfig{
+		Home: GetEnv("DB_HOME_REGION", ""),
+		URLs: map[string]string{},
+	}
+	for _, region := range GetEnvAsSlice("DB_SHARDS", []string{}) {
+		if region == "" {
+			continue
+		}
+		shards.URLs[region] = GetEnv("DB_SHARD_"+strings.ToUpper(region)+"_URL", "")
+	}
+	return shards
+}
+
 // Helper functions to get environment variables
 
 // GetEnv retrieves environment value.
@@ -119,6 +799,36 @@ func GetEnvAsInt(key string, fallback int) int {
 	return fallback
 }
 
+// GetEnvAsBool retrieves environment value and parses it as a boolean such as "true" or "1".
+// If the variable is not present or invalid, returns fallback bool value.
+func GetEnvAsBool(key string, fallback bool) bool {
+	strValue := GetEnv(key, "")
+	if value, err := strconv.ParseBool(strValue); err == nil {
+		return value
+	}
+	return fallback
+}
+
+// GetEnvAsDuration retrieves environment value and parses it as a duration such as "30m" or "72h".
+// If the variable is not present or invalid, returns fallback duration.
+func GetEnvAsDuration(key string, fallback time.Duration) time.Duration {
+	strValue := GetEnv(key, "")
+	if value, err := time.ParseDuration(strValue); err == nil && value > 0 {
+		return value
+	}
+	return fallback
+}
+
+// GetEnvAsTime retrieves environment value and parses it as an RFC 3339 time such as "2026-11-01T00:00:00Z".
+// If the variable is not present or invalid, returns fallback time.
+func GetEnvAsTime(key string, fallback time.Time) time.Time {
+	strValue := GetEnv(key, "")
+	if value, err := time.Parse(time.RFC3339, strValue); err == nil {
+		return value
+	}
+	return fallback
+}
+
 // GetEnvAsSlice retrieves environment value and converts it to string slice.
 // Expects comma-separated values. If the variable is not present, returns fallback slice.
 func GetEnvAsSlice(key string, fallback []string) []string {
@@ -0,0 +1,17 @@
+package data
+
+import "time"
+
+// ProjectAccessibility holds the descriptions the owner wrote of what a project draws, for clients to show to screen readers.
+type ProjectAccessibility struct {
+	ThumbnailAlt      string    `json:"thumbnail_alt"`      // alt text of the thumbnail
+	OutputDescription string    `json:"output_description"` // description of the drawing a run of the program renders
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// ProjectAccessibilityUpdate changes the accessibility descriptions of a project. Fields left out keep their value,
+// an empty string clears one.
+type ProjectAccessibilityUpdate struct {
+	ThumbnailAlt      *string `json:"thumbnail_alt,omitempty" validate:"omitempty,max=500"`
+	OutputDescription *string `json:"output_description,omitempty" validate:"omitempty,max=2000"`
+}
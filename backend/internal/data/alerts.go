@@ -0,0 +1,49 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AlertKind is the kind of anomaly an alert was raised for.
+type AlertKind string
+
+const (
+	AlertRegistrationSpike AlertKind = "registration_spike" // far more registrations than usual
+	AlertLikesSurge        AlertKind = "likes_surge"        // many likes from the clients of one network (ASN)
+	AlertServerErrors      AlertKind = "server_errors"      // a surge of server errors on one route
+)
+
+// WebhookEventAlert is the event of the webhook notified about new alerts.
+const WebhookEventAlert = "admin.alert"
+
+// Alert is an anomaly the analyzer flagged in a window of traffic, for admins to look into.
+// Subject is what the anomaly is about, the network for likes and the route for server errors.
+// Observed is what the window counted and Threshold what it had to exceed to be flagged.
+type Alert struct {
+	ID             int64      `json:"id"`
+	Kind           AlertKind  `json:"kind"`
+	Subject        string     `json:"subject,omitempty"`
+	Observed       int        `json:"observed"`
+	Threshold      float64    `json:"threshold"`
+	WindowStart    time.Time  `json:"window_start"`
+	WindowEnd      time.Time  `json:"window_end"`
+	CreatedAt      time.Time  `json:"created_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy *uuid.UUID `json:"acknowledged_by,omitempty"`
+}
+
+// AlertFilter narrows down the alerts to list, newest first. Empty fields match any alert.
+// Open only matches alerts nobody acknowledged yet.
+type AlertFilter struct {
+	Kind  AlertKind `query:"kind" validate:"omitempty,oneof=registration_spike likes_surge server_errors"`
+	Open  bool      `query:"open"`
+	Limit int       `query:"limit" validate:"omitempty,min=1,max=500"`
+}
+
+// AlertEvent is the JSON body posted to the alert webhook.
+type AlertEvent struct {
+	Event string `json:"event"`
+	Alert Alert  `json:"alert"`
+}
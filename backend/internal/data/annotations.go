@@ -0,0 +1,22 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Annotation holds the internal notes and labels staff keep on a user or project,
+// e.g. "repeat offender" or "verified educator". It is never shown to the users themselves.
+type Annotation struct {
+	Labels    []string   `json:"labels"`
+	Notes     string     `json:"notes"`
+	UpdatedBy *uuid.UUID `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// AnnotationUpdate replaces the notes and labels of a user or project.
+type AnnotationUpdate struct {
+	Labels []string `json:"labels" validate:"max=20,dive,min=1,max=50"`
+	Notes  string   `json:"notes" validate:"max=5000"`
+}
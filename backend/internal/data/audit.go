@@ -0,0 +1,111 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Actions recorded in the audit log.
+const (
+	AuditImpersonationStart    = "impersonation.start"
+	AuditImpersonationStop     = "impersonation.stop"
+	AuditImpersonationRequest  = "impersonation.request"
+	AuditAnnotationUpdate      = "annotation.update"
+	AuditVerificationReview    = "verification.review"
+	AuditSignupAllowlistAdd    = "signup_allowlist.add"
+	AuditSignupAllowlistRemove = "signup_allowlist.remove"
+	AuditTokensRevoke          = "tokens.revoke"
+	AuditProjectsCompact       = "projects.compact"
+	AuditReportDismiss         = "report.dismiss"
+	AuditProjectTakedown       = "project.takedown"
+	AuditProjectFeature        = "project.feature"
+	AuditProjectUnfeature      = "project.unfeature"
+	AuditFeaturedPin           = "featured.pin"
+	AuditFeaturedEnqueue       = "featured.enqueue"
+	AuditFeaturedDequeue       = "featured.dequeue"
+	AuditFeaturedRotate        = "featured.rotate"
+	AuditRetentionPurge        = "retention.purge"
+	AuditReadOnlyEnable        = "read_only.enable"
+	AuditReadOnlyDisable       = "read_only.disable"
+	AuditActivationResend      = "user.activation_resend"
+	AuditPasswordResetSend     = "user.password_reset_send"
+	AuditBanAppealReview       = "ban_appeal.review"
+	AuditIPBanAdd              = "ip_ban.add"
+	AuditIPBanUpdate           = "ip_ban.update"
+	AuditIPBanRemove           = "ip_ban.remove"
+	AuditUserUpdate            = "user.update"
+	AuditUserRoleChange        = "user.role_change"
+	AuditUserDelete            = "user.delete"
+	AuditUserBan               = "user.ban"
+	AuditUserUnban             = "user.unban"
+	AuditUsersBulk             = "users.bulk"
+	AuditLoginFailure          = "auth.login_failure"
+)
+
+// AuditEntry records an action taken by a user, typically a privileged one.
+// Target identifies what the action was taken on, e.g. a target type "user" with the user's ID.
+type AuditEntry struct {
+	ID         int64                  `json:"id"`
+	ActorID    *uuid.UUID             `json:"actor_id,omitempty"`
+	Action     string                 `json:"action"`
+	TargetType string                 `json:"target_type,omitempty"`
+	TargetID   string                 `json:"target_id,omitempty"`
+	Details    map[string]interface{} `json:"details"`
+	IP         string                 `json:"ip,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// FeaturingAuditActions are the actions that make up the history of the featured projects.
+var FeaturingAuditActions = []string{
+	AuditProjectFeature,
+	AuditProjectUnfeature,
+	AuditFeaturedPin,
+	AuditFeaturedEnqueue,
+	AuditFeaturedDequeue,
+	AuditFeaturedRotate,
+}
+
+// AuditFilter narrows down the audit log entries to list. Empty fields match any entry.
+// After and Before bound the time of the entries, a page continues after Cursor.
+type AuditFilter struct {
+	Actions    []string
+	ActorID    *uuid.UUID
+	TargetType string
+	TargetID   string
+	After      *time.Time
+	Before     *time.Time
+	Cursor     *AuditCursor
+	Limit      int
+}
+
+// HistoryKind is where an entry of the history of a target comes from.
+type HistoryKind string
+
+const (
+	HistoryAudit    HistoryKind = "audit"    // a staff action from the audit log
+	HistorySanction HistoryKind = "sanction" // a ban, unban, takedown or report decision
+	HistoryState    HistoryKind = "state"    // a change the target went through, e.g. its creation or an appeal
+)
+
+// HistoryEntry is an entry of the history of a user or a project, which merges the audit log with the sanctions
+// and state changes of the target for disputes to be looked into in one place.
+type HistoryEntry struct {
+	Kind          HistoryKind            `json:"kind"`
+	Action        string                 `json:"action"`
+	ActorID       *uuid.UUID             `json:"actor_id,omitempty"`
+	ActorUsername string                 `json:"actor_username,omitempty"`
+	Summary       string                 `json:"summary,omitempty"` // the reason, message or title the entry carries
+	Details       map[string]interface{} `json:"details"`
+	CreatedAt     time.Time              `json:"created_at"`
+}
+
+// HistoryFilter narrows down the history of a target. Search matches the action, the summary, the details
+// and the username of the actor.
+type HistoryFilter struct {
+	Kind   HistoryKind `query:"kind" validate:"omitempty,oneof=audit sanction state"`
+	Search string      `query:"search" validate:"max=200"`
+	After  *time.Time  `query:"after"`
+	Before *time.Time  `query:"before"`
+	Limit  int         `query:"limit" validate:"omitempty,min=1,max=500"`
+}
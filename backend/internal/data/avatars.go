@@ -0,0 +1,31 @@
+package data
+
+import "fmt"
+
+// AvatarSizes are the widths and heights, in pixels, avatars are stored at. Profiles show the first.
+var AvatarSizes = []int{256, 64}
+
+// Avatar tells where the sizes of a user's avatar are served from.
+type Avatar struct {
+	URLs map[string]string `json:"urls"` // keyed by size, e.g. "64"
+}
+
+// AvatarImageKey returns the object storage key a size of the avatar stored under key is kept at.
+func AvatarImageKey(key string, size int) string {
+	return fmt.Sprintf("%s-%d.png", key, size)
+}
+
+// AvatarURL returns the path a size of the avatar stored under key is served from.
+// Keys are unique per upload, so the image behind a URL never changes.
+func AvatarURL(key string, size int) string {
+	return "/api/" + AvatarImageKey(key, size)
+}
+
+// NewAvatar returns where the sizes of the avatar stored under key are served from.
+func NewAvatar(key string) *Avatar {
+	avatar := Avatar{URLs: map[string]string{}}
+	for _, size := range AvatarSizes {
+		avatar.URLs[fmt.Sprint(size)] = AvatarURL(key, size)
+	}
+	return &avatar
+}
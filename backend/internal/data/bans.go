@@ -0,0 +1,93 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BanAction is what a ban history entry did.
+type BanAction string
+
+const (
+	BanActionBan   BanAction = "ban"
+	BanActionUnban BanAction = "unban"
+)
+
+// BanEvent is an entry of a user's ban history. Entries are only ever added, a ban running out adds none.
+type BanEvent struct {
+	ID            int64      `json:"id"`
+	UserID        uuid.UUID  `json:"user_id"`
+	Action        BanAction  `json:"action"`
+	Reason        string     `json:"reason,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	ActorID       *uuid.UUID `json:"actor_id,omitempty"` // nil when the moderator is unknown
+	ActorUsername string     `json:"actor_username,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// AppealStatus is the review state of a ban appeal.
+type AppealStatus string
+
+const (
+	AppealOpen     AppealStatus = "open"
+	AppealAccepted AppealStatus = "accepted" // the ban was lifted
+	AppealRejected AppealStatus = "rejected"
+)
+
+// BanAppeal is a banned user's appeal of their ban, waiting for or resolved by a moderator.
+type BanAppeal struct {
+	ID           uuid.UUID    `json:"id"`
+	UserID       uuid.UUID    `json:"user_id"`
+	Username     string       `json:"username"`
+	Email        string       `json:"-"`
+	BanID        int64        `json:"ban_id"`
+	BanReason    string       `json:"ban_reason"`
+	BanExpiresAt *time.Time   `json:"ban_expires_at,omitempty"`
+	Message      string       `json:"message"`
+	Status       AppealStatus `json:"status"`
+	Resolution   string       `json:"resolution,omitempty"`
+	ResolvedBy   *uuid.UUID   `json:"resolved_by,omitempty"`
+	ResolvedAt   *time.Time   `json:"resolved_at,omitempty"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+// BanAppealSubmission is what a banned user submits to appeal their ban. Banned users can't sign in,
+// so the appeal carries the credentials of the account.
+type BanAppealSubmission struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+	Message  string `json:"message" validate:"required,max=2000"`
+}
+
+// BanAppealDecision is a moderator's decision on an appeal, the resolution is sent to the user.
+type BanAppealDecision struct {
+	Status     AppealStatus `json:"status" validate:"required,oneof=accepted rejected"`
+	Resolution string       `json:"resolution" validate:"max=1000"`
+}
+
+// IPBan blocks every request from a range of IP addresses, until ExpiresAt or for good when it is nil.
+type IPBan struct {
+	ID        int64      `json:"id"`
+	Subnet    string     `json:"subnet"`
+	Reason    string     `json:"reason"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// IPBanCreate is the payload of a new IP ban, a subnet in CIDR notation or a single IP.
+// Duration is in hours and required unless the ban is permanent.
+type IPBanCreate struct {
+	Subnet    string `json:"subnet" validate:"required,cidr|ip"`
+	Reason    string `json:"reason" validate:"required,max=500"`
+	Duration  int    `json:"duration" validate:"omitempty,min=1"`
+	Permanent bool   `json:"permanent"`
+}
+
+// IPBanUpdate replaces the reason and the expiry of an IP ban, the expiry counting from now.
+type IPBanUpdate struct {
+	Reason    string `json:"reason" validate:"required,max=500"`
+	Duration  int    `json:"duration" validate:"omitempty,min=1"`
+	Permanent bool   `json:"permanent"`
+}
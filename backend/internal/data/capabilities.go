@@ -0,0 +1,16 @@
+package data
+
+// Capabilities tells the editor what the server supports for the current user, so frontends of different
+// versions can adapt instead of hardcoding assumptions about the server. A limit of 0 means there is none.
+type Capabilities struct {
+	Role           *RoleType           `json:"role,omitempty"` // nil when not signed in, the limits are then those of new accounts
+	MaxDataBytes   int                 `json:"max_data_bytes"` // size of the flow data of a single project
+	MaxNodes       int                 `json:"max_nodes"`
+	MaxProjects    int                 `json:"max_projects"`
+	MaxStorage     int                 `json:"max_storage"`
+	MaxImportBytes int                 `json:"max_import_bytes"`
+	NodeTypes      []string            `json:"node_types"`
+	Realtime       bool                `json:"realtime"`       // whether the event stream is available
+	ExportFormats  map[string][]string `json:"export_formats"` // by what is exported, empty when exports are unavailable
+	ReadOnly       bool                `json:"read_only"`      // changes are turned away for now
+}
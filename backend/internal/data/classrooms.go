@@ -0,0 +1,47 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ClassroomRole defines the role of a member within a classroom.
+type ClassroomRole string
+
+const (
+	ClassroomTeacher ClassroomRole = "teacher"
+	ClassroomStudent ClassroomRole = "student"
+)
+
+// Classroom represents a group of users sharing projects only with each other.
+type Classroom struct {
+	ID            uuid.UUID `json:"id"`
+	Name          string    `json:"name"`
+	OwnerID       uuid.UUID `json:"owner_id"`
+	OwnerUsername string    `json:"owner_username"`
+	MembersCount  int       `json:"members_count"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ClassroomMember represents a user on a classroom roster.
+type ClassroomMember struct {
+	UserID   uuid.UUID     `json:"user_id"`
+	Username string        `json:"username"`
+	Role     ClassroomRole `json:"role"`
+	JoinedAt time.Time     `json:"joined_at"`
+}
+
+// ClassroomInvite represents a pending invitation of a user to a classroom roster.
+type ClassroomInvite struct {
+	ClassroomID   uuid.UUID `json:"classroom_id"`
+	ClassroomName string    `json:"classroom_name"`
+	OwnerUsername string    `json:"owner_username"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ClassroomCreate represents the data required to create a new classroom.
+type ClassroomCreate struct {
+	Name    string    `json:"name" validate:"required,min=3,max=100"`
+	OwnerID uuid.UUID `json:"owner_id"`
+}
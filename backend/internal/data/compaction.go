@@ -0,0 +1,38 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CompactionSampleSize is how many of the changed projects a compaction report lists.
+const CompactionSampleSize = 50
+
+// CompactionOptions controls a run of the project data compaction.
+type CompactionOptions struct {
+	DryRun    bool
+	BatchSize int           // projects read and updated per transaction
+	Pause     time.Duration // waited between batches to leave room for regular traffic
+}
+
+// CompactionReport summarizes a run of the project data compaction. In a dry run nothing is written
+// and the report shows what a real run would change.
+type CompactionReport struct {
+	DryRun      bool               `json:"dry_run"`
+	Scanned     int                `json:"scanned"`
+	Changed     int                `json:"changed"`
+	Skipped     int                `json:"skipped"` // data that isn't a flow object, or was saved during the run
+	BytesBefore int64              `json:"bytes_before"`
+	BytesAfter  int64              `json:"bytes_after"`
+	Projects    []CompactedProject `json:"projects"` // the first changed projects, up to CompactionSampleSize
+	StartedAt   time.Time          `json:"started_at"`
+	FinishedAt  time.Time          `json:"finished_at"`
+}
+
+// CompactedProject is a project whose data was, or in a dry run would be, re-serialized.
+type CompactedProject struct {
+	ID          uuid.UUID `json:"id"`
+	BytesBefore int       `json:"bytes_before"`
+	BytesAfter  int       `json:"bytes_after"`
+}
@@ -0,0 +1,54 @@
+package data
+
+import (
+	"time"
+)
+
+// ConsentPurpose is something a user can agree to their data being used for.
+type ConsentPurpose string
+
+const (
+	ConsentAnalytics       ConsentPurpose = "analytics"
+	ConsentMarketingEmails ConsentPurpose = "marketing_emails"
+	ConsentResearchData    ConsentPurpose = "research_data" // inclusion in the public data dumps
+)
+
+// ConsentPurposes lists every purpose consent is tracked for.
+var ConsentPurposes = []ConsentPurpose{ConsentAnalytics, ConsentMarketingEmails, ConsentResearchData}
+
+// DefaultConsent reports whether a user who never made a choice consents to the purpose.
+// Research data has been opt-out since the data dumps were introduced, everything else is opt-in.
+func DefaultConsent(purpose ConsentPurpose) bool {
+	return purpose == ConsentResearchData
+}
+
+// Consent is a user's current choice for a purpose and when it was last given or withdrawn.
+type Consent struct {
+	Purpose     ConsentPurpose `json:"purpose"`
+	Granted     bool           `json:"granted"`
+	GrantedAt   *time.Time     `json:"granted_at,omitempty"`
+	WithdrawnAt *time.Time     `json:"withdrawn_at,omitempty"`
+	UpdatedAt   *time.Time     `json:"updated_at,omitempty"` // nil while the default applies
+}
+
+// ConsentUpdate gives or withdraws consent for the purposes that are set, leaving the others unchanged.
+type ConsentUpdate struct {
+	Analytics       *bool `json:"analytics"`
+	MarketingEmails *bool `json:"marketing_emails"`
+	ResearchData    *bool `json:"research_data"`
+}
+
+// Changes returns the choices made in the update by purpose.
+func (u ConsentUpdate) Changes() map[ConsentPurpose]bool {
+	changes := map[ConsentPurpose]bool{}
+	if u.Analytics != nil {
+		changes[ConsentAnalytics] = *u.Analytics
+	}
+	if u.MarketingEmails != nil {
+		changes[ConsentMarketingEmails] = *u.MarketingEmails
+	}
+	if u.ResearchData != nil {
+		changes[ConsentResearchData] = *u.ResearchData
+	}
+	return changes
+}
@@ -0,0 +1,28 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreditStatus is the answer of the credited user to a co-author credit.
+type CreditStatus string
+
+const (
+	CreditPending  CreditStatus = "pending"
+	CreditAccepted CreditStatus = "accepted"
+	CreditDeclined CreditStatus = "declined"
+)
+
+// Credit names a co-author of a project. Unlike collaborators, co-authors get no edit rights,
+// the project is only listed under "contributed to" on their profile once they accept.
+type Credit struct {
+	ProjectID    uuid.UUID    `json:"project_id"`
+	ProjectTitle string       `json:"project_title"`
+	UserID       uuid.UUID    `json:"user_id"`
+	Username     string       `json:"username"`
+	Status       CreditStatus `json:"status"`
+	CreatedAt    time.Time    `json:"created_at"`
+	RespondedAt  *time.Time   `json:"responded_at,omitempty"`
+}
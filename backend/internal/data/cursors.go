@@ -0,0 +1,88 @@
+package data
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidCursor is returned for a cursor that wasn't handed out by the API.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ProjectCursor marks where a page of projects sorted by a time ended, the next page continues after it.
+// Clients get it as an opaque string and pass it back as is.
+type ProjectCursor struct {
+	Time time.Time // the sort key of the last project of the page, e.g. its creation time
+	ID   uuid.UUID // orders projects with the same time
+}
+
+// Encode returns the cursor as an opaque string safe to use in URLs.
+// Times are kept to the microsecond, the precision of the database.
+func (c ProjectCursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(c.Time.UnixMicro(), 10) + "_" + c.ID.String()))
+}
+
+// DecodeProjectCursor parses a cursor returned by Encode.
+// It returns ErrInvalidCursor if the cursor is malformed.
+func DecodeProjectCursor(s string) (*ProjectCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	micros, id, ok := strings.Cut(string(raw), "_")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	t, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	projectID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &ProjectCursor{Time: time.UnixMicro(t).UTC(), ID: projectID}, nil
+}
+
+// AuditCursor marks where a page of audit log entries, newest first, ended. The next page continues after it.
+type AuditCursor struct {
+	Time time.Time // when the last entry of the page was recorded
+	ID   int64     // orders entries recorded at the same time
+}
+
+// Encode returns the cursor as an opaque string safe to use in URLs.
+func (c AuditCursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(c.Time.UnixMicro(), 10) + "_" + strconv.FormatInt(c.ID, 10)))
+}
+
+// DecodeAuditCursor parses a cursor returned by Encode.
+// It returns ErrInvalidCursor if the cursor is malformed.
+func DecodeAuditCursor(s string) (*AuditCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	micros, id, ok := strings.Cut(string(raw), "_")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	t, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	entryID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &AuditCursor{Time: time.UnixMicro(t).UTC(), ID: entryID}, nil
+}
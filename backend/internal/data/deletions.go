@@ -0,0 +1,16 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccountDeletion is the deletion of an account its owner asked for, carried out once the grace period ends.
+type AccountDeletion struct {
+	UserID       uuid.UUID `json:"user_id"`
+	Username     string    `json:"username"`
+	Email        string    `json:"email"`
+	RequestedAt  time.Time `json:"requested_at"`
+	ScheduledFor time.Time `json:"scheduled_for"`
+}
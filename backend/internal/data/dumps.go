@@ -0,0 +1,25 @@
+package data
+
+import "time"
+
+// DataDump represents a generated public data dump available for download.
+type DataDump struct {
+	ID        int64     `json:"id"`
+	Filename  string    `json:"filename"`
+	Records   int       `json:"records"`
+	SizeBytes int64     `json:"size_bytes"`
+	SHA256    string    `json:"sha256"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DumpProject is a single record of a public data dump.
+// Projects and creators are replaced by pseudonyms that are stable within one dump only, titles are left out.
+type DumpProject struct {
+	ID           string    `json:"id"`
+	Creator      string    `json:"creator"`
+	LikesCount   int       `json:"likes_count"`
+	ForkCount    int       `json:"fork_count"`
+	ForkedFrom   *string   `json:"forked_from,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastEditedAt time.Time `json:"last_edited_at"`
+}
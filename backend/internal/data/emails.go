@@ -0,0 +1,117 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailStatus is how far the delivery of an email got.
+type EmailStatus string
+
+const (
+	EmailPending EmailStatus = "pending" // being sent, or the API stopped while sending it
+	EmailSent    EmailStatus = "sent"    // accepted by the mail provider
+	EmailFailed  EmailStatus = "failed"  // the provider refused it or couldn't be reached
+	// not sent, the recipient unsubscribed from the category of the email
+	EmailSuppressed EmailStatus = "suppressed"
+)
+
+// EmailCategory is a kind of optional email users can unsubscribe from. Emails without a category,
+// such as activation and password reset links, are sent whatever the preferences of the recipient.
+type EmailCategory string
+
+const (
+	EmailMarketing EmailCategory = "marketing"
+	EmailDigests   EmailCategory = "digests"
+	EmailSecurity  EmailCategory = "security" // notices about the account the user didn't ask for, like bans and takedowns
+)
+
+// EmailPreferences is which optional emails a user gets. Marketing is the consent to marketing emails.
+type EmailPreferences struct {
+	Marketing bool       `json:"marketing"`
+	Digests   bool       `json:"digests"`
+	Security  bool       `json:"security"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"` // nil while the defaults apply
+}
+
+// Allows reports whether the user gets emails of the category.
+func (p EmailPreferences) Allows(category EmailCategory) bool {
+	switch category {
+	case EmailMarketing:
+		return p.Marketing
+	case EmailDigests:
+		return p.Digests
+	case EmailSecurity:
+		return p.Security
+	}
+	return true
+}
+
+// EmailPreferencesUpdate changes the preferences that are set, leaving the others unchanged.
+type EmailPreferencesUpdate struct {
+	Marketing *bool `json:"marketing"`
+	Digests   *bool `json:"digests"`
+	Security  *bool `json:"security"`
+}
+
+// Email records an email sent by the API, linked to the request that triggered it.
+// MessageID is the Message-ID header of the email, mail providers can look up its delivery by it.
+type Email struct {
+	ID        int64       `json:"id"`
+	UserID    *uuid.UUID  `json:"user_id,omitempty"` // the user the recipient address belonged to when the email was sent
+	Recipient string      `json:"recipient"`
+	Template  string      `json:"template"`
+	Subject   string      `json:"subject"`
+	RequestID string      `json:"request_id,omitempty"` // empty for emails not sent on behalf of a request
+	MessageID string      `json:"message_id"`
+	Status    EmailStatus `json:"status"`
+	Error     string      `json:"error,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	SentAt    *time.Time  `json:"sent_at,omitempty"`
+}
+
+// EmailFilter narrows down the emails to list. Empty fields match any email.
+type EmailFilter struct {
+	UserID    *uuid.UUID
+	Recipient string
+	RequestID string
+	Limit     int
+}
+
+// CapturedEmail is an email kept in the test mailbox instead of being sent.
+// Data holds the values the template was rendered with, such as the url of activation and reset links.
+type CapturedEmail struct {
+	To        string            `json:"to"`
+	Subject   string            `json:"subject"`
+	Template  string            `json:"template"`
+	Data      map[string]string `json:"data"`
+	Body      string            `json:"body"`
+	MessageID string            `json:"message_id"`
+	SentAt    time.Time         `json:"sent_at"`
+}
+
+// EmailTemplate is an email template with the subject it's usually sent with and sample data to preview it with.
+// Emails of templates with a Category carry a link to unsubscribe from it.
+type EmailTemplate struct {
+	Name     string            `json:"name"`
+	Subject  string            `json:"subject"`
+	Category EmailCategory     `json:"category,omitempty"`
+	Sample   map[string]string `json:"sample"`
+	Loaded   bool              `json:"loaded"` // false when the template file failed to load, emails using it aren't sent
+}
+
+// EmailPreview is a template rendered with sample data.
+type EmailPreview struct {
+	Template string            `json:"template"`
+	Subject  string            `json:"subject"`
+	Data     map[string]string `json:"data"` // the sample data with the overrides applied, as passed when sending
+	HTML     string            `json:"html"`
+}
+
+// EmailPreviewRequest overrides fields of the sample data of a template, and asks for the preview to be emailed
+// to the admin requesting it.
+type EmailPreviewRequest struct {
+	Data map[string]string `json:"data"`
+	Send bool              `json:"send"`
+}
@@ -0,0 +1,58 @@
+package data
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidETag is returned for an entity tag that wasn't handed out by the API.
+var ErrInvalidETag = errors.New("invalid entity tag")
+
+// ProjectETag identifies the state of a project, it changes with every edit of the project.
+// Counters like likes and views aren't part of it, so a project can be liked without its tag changing.
+type ProjectETag struct {
+	Version      int
+	LastEditedAt time.Time
+}
+
+// ETag returns the entity tag of the project's current state.
+func (p Project) ETag() ProjectETag {
+	return ProjectETag{Version: p.Version, LastEditedAt: p.LastEditedAt}
+}
+
+// String returns the tag as a quoted strong entity tag, ready for the ETag header.
+// Times are kept to the microsecond, the precision of the database.
+func (t ProjectETag) String() string {
+	return `"` + strconv.Itoa(t.Version) + "-" + strconv.FormatInt(t.LastEditedAt.UnixMicro(), 36) + `"`
+}
+
+// ParseProjectETag parses a tag returned by String.
+// It returns ErrInvalidETag if the tag is malformed or weak, weak tags don't identify a state precisely enough to write on.
+func ParseProjectETag(s string) (*ProjectETag, error) {
+	raw, ok := strings.CutPrefix(s, `"`)
+	if !ok {
+		return nil, ErrInvalidETag
+	}
+	raw, ok = strings.CutSuffix(raw, `"`)
+	if !ok {
+		return nil, ErrInvalidETag
+	}
+
+	version, edited, ok := strings.Cut(raw, "-")
+	if !ok {
+		return nil, ErrInvalidETag
+	}
+
+	v, err := strconv.Atoi(version)
+	if err != nil {
+		return nil, ErrInvalidETag
+	}
+	micros, err := strconv.ParseInt(edited, 36, 64)
+	if err != nil {
+		return nil, ErrInvalidETag
+	}
+
+	return &ProjectETag{Version: v, LastEditedAt: time.UnixMicro(micros).UTC()}, nil
+}
@@ -0,0 +1,48 @@
+package data
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Types of the domain events appended to the event log.
+const (
+	DomainCountersSeeded = "project.counters_seeded" // the counters of the project when the log started, the payload is ProjectCounters
+	DomainProjectLiked   = "project.liked"
+	DomainProjectUnliked = "project.unliked"
+	DomainProjectViewed  = "project.viewed"
+	DomainProjectForked  = "project.forked"       // the payload is ForkEvent
+	DomainForkDeleted    = "project.fork_deleted" // the payload is ForkEvent
+)
+
+// DomainEvent is something that happened to a project, as recorded in the event log.
+type DomainEvent struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	ProjectID uuid.UUID       `json:"project_id"`
+	ActorID   *uuid.UUID      `json:"actor_id,omitempty"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// ProjectCounters are the denormalized counters of a project.
+type ProjectCounters struct {
+	Likes int `json:"likes"`
+	Views int `json:"views"`
+	Forks int `json:"forks"`
+}
+
+// ForkEvent names the fork a fork event of its source project is about.
+type ForkEvent struct {
+	ForkID uuid.UUID `json:"fork_id"`
+}
+
+// ProjectionRebuild is the outcome of rebuilding a projection from the event log.
+type ProjectionRebuild struct {
+	Projection string `json:"projection"`
+	Events     int64  `json:"events"`     // the events replayed
+	RowsFixed  int64  `json:"rows_fixed"` // the rows of the projection that had drifted from the log
+	DryRun     bool   `json:"dry_run"`
+}
@@ -0,0 +1,27 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DataExportStatus is the state of a data export.
+type DataExportStatus string
+
+const (
+	ExportPending DataExportStatus = "pending" // waiting for the background job to prepare it
+	ExportReady   DataExportStatus = "ready"   // can be downloaded through the emailed link until it expires
+	ExportFailed  DataExportStatus = "failed"
+)
+
+// DataExport is a copy of everything stored about a user, which they asked for.
+// The archive itself is only reachable through the link emailed once it's ready.
+type DataExport struct {
+	ID          uuid.UUID        `json:"id"`
+	Status      DataExportStatus `json:"status"`
+	Size        *int64           `json:"size,omitempty"` // of the archive, in bytes
+	RequestedAt time.Time        `json:"requested_at"`
+	CompletedAt *time.Time       `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time       `json:"expires_at,omitempty"`
+}
@@ -0,0 +1,34 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FeaturedQueueEntry represents a curated project waiting for a free featured slot.
+type FeaturedQueueEntry struct {
+	ProjectID       uuid.UUID  `json:"project_id"`
+	Title           string     `json:"title"`
+	CreatorUsername string     `json:"creator_username"`
+	QueuedBy        *uuid.UUID `json:"queued_by,omitempty"`
+	QueuedAt        time.Time  `json:"queued_at"`
+}
+
+// FeaturedRotation represents the outcome of a featured rotation run.
+type FeaturedRotation struct {
+	Expired  []uuid.UUID `json:"expired"`
+	Featured []uuid.UUID `json:"featured"`
+}
+
+// FeaturedProject represents a project currently holding a featured slot.
+type FeaturedProject struct {
+	ProjectID          uuid.UUID  `json:"project_id"`
+	Title              string     `json:"title"`
+	CreatorUsername    string     `json:"creator_username"`
+	FeaturedAt         *time.Time `json:"featured_at,omitempty"`
+	FeaturedUntil      time.Time  `json:"featured_until"`
+	FeaturedBy         *uuid.UUID `json:"featured_by,omitempty"`
+	FeaturedByUsername *string    `json:"featured_by_username,omitempty"`
+	Pinned             bool       `json:"pinned"`
+}
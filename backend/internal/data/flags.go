@@ -0,0 +1,44 @@
+package data
+
+import "time"
+
+// Flags the API itself reacts to.
+const (
+	// FlagShedLoad turns away low-priority requests regardless of the current load.
+	FlagShedLoad = "shed-load"
+)
+
+// FeatureFlag turns a feature on or off for every user without a deployment.
+type FeatureFlag struct {
+	Name        string    `json:"name"`
+	Enabled     bool      `json:"enabled"`
+	Description string    `json:"description"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// AnnouncementLevel controls how prominently an announcement is shown.
+type AnnouncementLevel string
+
+const (
+	AnnouncementInfo     AnnouncementLevel = "info"
+	AnnouncementWarning  AnnouncementLevel = "warning"
+	AnnouncementCritical AnnouncementLevel = "critical"
+)
+
+// Announcement is a banner shown to every user between StartsAt and EndsAt.
+type Announcement struct {
+	ID        int64             `json:"id"`
+	Message   string            `json:"message"`
+	Level     AnnouncementLevel `json:"level"`
+	StartsAt  time.Time         `json:"starts_at"`
+	EndsAt    *time.Time        `json:"ends_at,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// AnnouncementCreate is the payload to publish an announcement, it starts immediately when StartsAt is empty.
+type AnnouncementCreate struct {
+	Message  string            `json:"message" validate:"required,max=500"`
+	Level    AnnouncementLevel `json:"level" validate:"omitempty,oneof=info warning critical"`
+	StartsAt *time.Time        `json:"starts_at"`
+	EndsAt   *time.Time        `json:"ends_at"`
+}
@@ -0,0 +1,31 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Folder is a folder a user sorts their own projects into. Top-level folders can hold subfolders,
+// which can't be nested any further.
+type Folder struct {
+	ID         uuid.UUID   `json:"id"`
+	ParentID   *uuid.UUID  `json:"parent_id,omitempty"`
+	Name       string      `json:"name"`
+	Position   int         `json:"position"`
+	ProjectIDs []uuid.UUID `json:"project_ids"`       // projects in the folder, in their order
+	Folders    []Folder    `json:"folders,omitempty"` // subfolders of a top-level folder, in their order
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// FolderCreate represents the fields of a new folder. It's added after the folders sharing its parent.
+type FolderCreate struct {
+	Name     string     `json:"name" validate:"required,min=1,max=100"`
+	ParentID *uuid.UUID `json:"parent_id,omitempty"`
+}
+
+// FolderFilter narrows a listing of a user's projects down to one of their folders.
+type FolderFilter struct {
+	FolderID *uuid.UUID // nil for the projects in no folder
+}
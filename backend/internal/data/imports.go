@@ -0,0 +1,46 @@
+package data
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProjectArchiveFormat names the format of project exports, so files of a later format can be told apart.
+const ProjectArchiveFormat = "nodeturtle-project/1"
+
+// ProjectImport is the content of a project import file. Project exports are import files with the format,
+// the time of the export and the project's revisions; files with just a title and a flow are imported as well.
+type ProjectImport struct {
+	Format      string             `json:"format,omitempty" validate:"omitempty,eq=nodeturtle-project/1"`
+	ExportedAt  *time.Time         `json:"exported_at,omitempty"`
+	Title       string             `json:"title" validate:"required,min=3,max=100"`
+	Description string             `json:"description" validate:"max=5000"`
+	Data        json.RawMessage    `json:"data" validate:"required"`
+	Revisions   []ArchivedRevision `json:"revisions,omitempty" validate:"dive"`
+}
+
+// ArchivedRevision is a revision of a project in an export, oldest first.
+// Only the newest revisions up to the limit of the importing user's role are kept.
+type ArchivedRevision struct {
+	Title       string          `json:"title" validate:"required,max=100"`
+	Description string          `json:"description" validate:"max=5000"`
+	Data        json.RawMessage `json:"data" validate:"required"`
+	CreatedAt   time.Time       `json:"created_at" validate:"required"`
+}
+
+// ProjectUpload is a project import file being uploaded in chunks.
+type ProjectUpload struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"-"`
+	Size      int64     `json:"size"`
+	Offset    int64     `json:"offset"` // bytes received so far, where the next chunk starts
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Complete reports whether every byte of the file was received.
+func (u *ProjectUpload) Complete() bool {
+	return u.Offset == u.Size
+}
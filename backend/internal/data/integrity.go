@@ -0,0 +1,37 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IntegrityScanOptions controls a scan of the stored project data for corruption.
+type IntegrityScanOptions struct {
+	BatchSize int           // projects read per query
+	Pause     time.Duration // waited between batches to leave room for regular traffic
+}
+
+// IntegrityReport summarizes a scan of the stored project data.
+type IntegrityReport struct {
+	Scanned    int                `json:"scanned"`
+	Unchecked  int                `json:"unchecked"` // projects without a checksum yet, see the projects_data_checksum online migration
+	Corrupted  []CorruptedProject `json:"corrupted"`
+	StartedAt  time.Time          `json:"started_at"`
+	FinishedAt time.Time          `json:"finished_at"`
+}
+
+// CorruptedProject is a project whose data doesn't match the checksum written along with it.
+type CorruptedProject struct {
+	ID             uuid.UUID `json:"id"`
+	Title          string    `json:"title"`
+	StoredChecksum string    `json:"stored_checksum"`
+	DataChecksum   string    `json:"data_checksum"`
+	DataBytes      int       `json:"data_bytes"`
+	LastEditedAt   time.Time `json:"last_edited_at"`
+}
+
+// IntegrityMetrics represents the project data read since startup that didn't match its checksum.
+type IntegrityMetrics struct {
+	ChecksumMismatches int64 `json:"checksum_mismatches"`
+}
@@ -0,0 +1,31 @@
+package data
+
+import "time"
+
+// JobStatus is the state of a background job.
+type JobStatus string
+
+const (
+	JobRunning   JobStatus = "running"
+	JobCompleted JobStatus = "completed"
+	JobFailed    JobStatus = "failed"
+)
+
+// Kinds of background jobs.
+const (
+	JobKindIndex    = "index"
+	JobKindBackfill = "backfill"
+)
+
+// Job is the progress of a long running background job, e.g. an online index build or a batched backfill.
+type Job struct {
+	Name       string     `json:"name"`
+	Kind       string     `json:"kind"`
+	Status     JobStatus  `json:"status"`
+	Total      *int64     `json:"total,omitempty"`
+	Processed  int64      `json:"processed"`
+	Error      *string    `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,23 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TrustedLocation represents a country a user has confirmed logging in from.
+type TrustedLocation struct {
+	Country    string    `json:"country"` // ISO 3166-1 alpha-2 code
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// LoginChallenge represents a pending login from a new country, confirmed with a one-time code sent by email.
+type LoginChallenge struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"-"`
+	Country   string    `json:"country"`
+	Code      string    `json:"-"` // plaintext, only set when the challenge is created
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,118 @@
+// Package data provides data models and structures for the application.
+package data
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LTI 1.3 message types handled by the tool.
+const (
+	LTIMessageResourceLink = "LtiResourceLinkRequest"
+	LTIMessageDeepLinking  = "LtiDeepLinkingRequest"
+)
+
+// LTIPlatform represents an LMS (Canvas, Moodle, ...) registered as an LTI 1.3 platform.
+type LTIPlatform struct {
+	ID            int64     `json:"id"`
+	Issuer        string    `json:"issuer"`
+	ClientID      string    `json:"client_id"`
+	DeploymentIDs []string  `json:"deployment_ids"`
+	AuthLoginURL  string    `json:"auth_login_url"`
+	AuthTokenURL  string    `json:"auth_token_url"`
+	KeysetURL     string    `json:"keyset_url"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// HasDeployment checks if the deployment ID was registered for the platform.
+func (p LTIPlatform) HasDeployment(deploymentID string) bool {
+	for _, id := range p.DeploymentIDs {
+		if id == deploymentID {
+			return true
+		}
+	}
+	return false
+}
+
+// LTIPlatformCreate represents the data required to register a new LTI platform.
+type LTIPlatformCreate struct {
+	Issuer        string   `json:"issuer" validate:"required,url"`
+	ClientID      string   `json:"client_id" validate:"required"`
+	DeploymentIDs []string `json:"deployment_ids" validate:"required,min=1,dive,required"`
+	AuthLoginURL  string   `json:"auth_login_url" validate:"required,url"`
+	AuthTokenURL  string   `json:"auth_token_url" validate:"required,url"`
+	KeysetURL     string   `json:"keyset_url" validate:"required,url"`
+}
+
+// LTILoginRequest represents the third-party initiated login sent by the platform.
+type LTILoginRequest struct {
+	Issuer          string `query:"iss" form:"iss" validate:"required"`
+	LoginHint       string `query:"login_hint" form:"login_hint" validate:"required"`
+	TargetLinkURI   string `query:"target_link_uri" form:"target_link_uri" validate:"required"`
+	LTIMessageHint  string `query:"lti_message_hint" form:"lti_message_hint"`
+	ClientID        string `query:"client_id" form:"client_id"`
+	LTIDeploymentID string `query:"lti_deployment_id" form:"lti_deployment_id"`
+}
+
+// LTILaunchResult describes the outcome of a validated launch.
+type LTILaunchResult struct {
+	MessageType string
+	UserID      uuid.UUID
+	// set for resource link launches
+	LaunchID  *uuid.UUID
+	ProjectID *uuid.UUID
+	// set for deep linking requests
+	DeepLinkID *uuid.UUID
+	// set instead of UserID when the LMS user has the email of an account that has to confirm the link first
+	LinkRequestID *uuid.UUID
+}
+
+// LTILaunch represents a resource link launch of a NodeTurtle assignment.
+type LTILaunch struct {
+	ID             uuid.UUID  `json:"id"`
+	PlatformID     int64      `json:"platform_id"`
+	UserID         uuid.UUID  `json:"user_id"`
+	Subject        string     `json:"-"`
+	ProjectID      *uuid.UUID `json:"project_id,omitempty"`
+	ResourceLinkID string     `json:"resource_link_id"`
+	LineItemURL    *string    `json:"lineitem_url,omitempty"`
+	SubmittedAt    *time.Time `json:"submitted_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// LTIDeepLinkResponse is the signed response that has to be form-posted back to the platform.
+type LTIDeepLinkResponse struct {
+	ReturnURL string `json:"return_url"`
+	JWT       string `json:"jwt"`
+}
+
+// JWK represents a single RSA JSON Web Key.
+type JWK struct {
+	Kty string `json:"kty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// NewRSAJWK converts an RSA public key used for RS256 signatures into its JWK representation.
+func NewRSAJWK(key *rsa.PublicKey, kid string) JWK {
+	return JWK{
+		Kty: "RSA",
+		Alg: "RS256",
+		Use: "sig",
+		Kid: kid,
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+// JWKS represents a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
@@ -0,0 +1,56 @@
+package data
+
+// BotMetrics represents the traffic counters collected by the crawler middleware since startup.
+type BotMetrics struct {
+	BotRequests    int64            `json:"bot_requests"`
+	UserRequests   int64            `json:"user_requests"`
+	BotThrottled   int64            `json:"bot_throttled"`
+	UserThrottled  int64            `json:"user_throttled"`
+	CacheHits      int64            `json:"cache_hits"`
+	CacheMisses    int64            `json:"cache_misses"`
+	FlaggedClients int              `json:"flagged_clients"` // clients currently treated as crawlers because of their behavior
+	Crawlers       map[string]int64 `json:"crawlers"`        // requests per detected crawler
+}
+
+// ProbeMetrics represents the requests of load balancer probes and uptime checkers since startup,
+// which are left out of the other traffic counters.
+type ProbeMetrics struct {
+	Requests int64            `json:"requests"`
+	Failed   int64            `json:"failed"`   // answered with a server error
+	Rejected int64            `json:"rejected"` // sent an invalid probe token
+	Routes   map[string]int64 `json:"routes"`   // requests per route
+}
+
+// CacheMetrics represents the counters of an in-memory cache since startup.
+type CacheMetrics struct {
+	Name          string `json:"name"`
+	Hits          int64  `json:"hits"`
+	Misses        int64  `json:"misses"`
+	Invalidations int64  `json:"invalidations"` // cached values dropped because the data changed
+}
+
+// LoadMetrics represents the current load of an API instance and the requests shed since startup.
+type LoadMetrics struct {
+	InFlight   int                            `json:"in_flight"`
+	Queued     int                            `json:"queued"`     // requests waiting for admission while the database pool is saturated
+	LatencyMs  float64                        `json:"latency_ms"` // moving average over recent requests
+	Overloaded bool                           `json:"overloaded"`
+	Reason     string                         `json:"reason,omitempty"` // flag, in_flight or latency
+	Shed       int64                          `json:"shed"`
+	Classes    map[string]TrafficClassMetrics `json:"classes"` // per editor, anonymous and crawler traffic
+}
+
+// TrafficClassMetrics represents the requests of a traffic class since startup.
+type TrafficClassMetrics struct {
+	Requests int64 `json:"requests"`
+	Queued   int   `json:"queued"` // currently waiting for admission
+	Shed     int64 `json:"shed"`   // low-priority requests shed and requests that waited too long for admission
+}
+
+// SignupMetrics represents the counters of the signup velocity limits since startup.
+type SignupMetrics struct {
+	Allowed         int64 `json:"allowed"`
+	BlockedBySubnet int64 `json:"blocked_by_subnet"`
+	BlockedByDomain int64 `json:"blocked_by_domain"`
+	Allowlisted     int64 `json:"allowlisted"` // attempts over a limit let through by the allowlist
+}
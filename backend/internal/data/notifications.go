@@ -0,0 +1,64 @@
+package data
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Types of in-app notifications.
+const (
+	NotificationProjectLiked = "project_liked"
+)
+
+// Notification is an in-app notification of a user, Payload depends on its Type.
+type Notification struct {
+	ID        int64           `json:"id"`
+	UserID    uuid.UUID       `json:"-"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	ReadAt    *time.Time      `json:"read_at,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// ProjectLiked is the payload of a notification sent to a creator when one of their projects was liked.
+type ProjectLiked struct {
+	ProjectID    uuid.UUID `json:"project_id"`
+	ProjectTitle string    `json:"project_title"`
+	UserID       uuid.UUID `json:"user_id"`
+	Username     string    `json:"username"`
+}
+
+// DigestFrequency is how often a user gets an email digest of the activity on their projects.
+type DigestFrequency string
+
+const (
+	DigestOff    DigestFrequency = "off"
+	DigestDaily  DigestFrequency = "daily"
+	DigestWeekly DigestFrequency = "weekly"
+)
+
+// NotificationPreferences is how a user wants to hear about activity on their projects.
+type NotificationPreferences struct {
+	Likes     bool            `json:"likes"` // in-app notifications of likes
+	Digest    DigestFrequency `json:"digest"`
+	UpdatedAt *time.Time      `json:"updated_at,omitempty"` // nil while the defaults apply
+}
+
+// DefaultNotificationPreferences are the preferences of users who never changed them.
+var DefaultNotificationPreferences = NotificationPreferences{Likes: true, Digest: DigestOff}
+
+// NotificationPreferencesUpdate changes the preferences that are set, leaving the others unchanged.
+type NotificationPreferencesUpdate struct {
+	Likes  *bool            `json:"likes"`
+	Digest *DigestFrequency `json:"digest" validate:"omitempty,oneof=off daily weekly"`
+}
+
+// ProjectActivity is the activity on a project since the last digest of its creator.
+type ProjectActivity struct {
+	ProjectID uuid.UUID
+	Title     string
+	Likes     int
+	Forks     int
+}
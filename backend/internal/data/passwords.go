@@ -0,0 +1,29 @@
+package data
+
+// Codes of the problems found in a password.
+const (
+	PasswordTooShort      = "too_short"
+	PasswordTooLong       = "too_long"
+	PasswordTooWeak       = "too_weak"
+	PasswordBreached      = "breached"
+	PasswordContainsUser  = "contains_user_info"
+	PasswordCommonPattern = "common_pattern"
+)
+
+// PasswordIssue is a single reason a password was rejected.
+type PasswordIssue struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PasswordStrength is the result of evaluating a password against the password policy.
+// Score ranges from 0 (guessable within a few attempts) to 4 (very unguessable).
+type PasswordStrength struct {
+	Score  int             `json:"score"`
+	Issues []PasswordIssue `json:"issues"`
+}
+
+// Acceptable reports whether the password satisfies the policy.
+func (s PasswordStrength) Acceptable() bool {
+	return len(s.Issues) == 0
+}
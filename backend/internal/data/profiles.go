@@ -0,0 +1,27 @@
+package data
+
+import (
+	"time"
+)
+
+// UserProfile is the public profile of a user, readable by anyone.
+type UserProfile struct {
+	Username    string            `json:"username"`
+	Bio         string            `json:"bio"`
+	Website     *string           `json:"website"`
+	SocialLinks map[string]string `json:"social_links"`
+	// AvatarURL is where the user's avatar is served from, nil as long as they haven't uploaded one.
+	AvatarURL      *string   `json:"avatar_url"`
+	Verified       bool      `json:"verified"`
+	JoinedAt       time.Time `json:"joined_at"`
+	PublicProjects int       `json:"public_projects"`
+	LikesReceived  int       `json:"likes_received"` // the likes of the user's public projects
+}
+
+// UserProfileUpdate replaces what a user tells about themselves on their profile.
+// An empty website or no social links clear them. Social links are keyed by network.
+type UserProfileUpdate struct {
+	Bio         string            `json:"bio" validate:"max=500"`
+	Website     string            `json:"website" validate:"omitempty,url,max=200"`
+	SocialLinks map[string]string `json:"social_links" validate:"omitempty,max=7,dive,keys,oneof=github gitlab mastodon bluesky youtube linkedin x,endkeys,url,max=200"`
+}
@@ -2,6 +2,7 @@ package data
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,17 +10,57 @@ import (
 
 // Project represents a user-created project in the system.
 type Project struct {
-	ID              uuid.UUID       `json:"id"`
-	Title           string          `json:"title"`
-	Description     string          `json:"description"`
-	Data            json.RawMessage `json:"data"` // react-flow JSON data
-	CreatorID       uuid.UUID       `json:"creator_id"`
-	CreatorUsername string          `json:"creator_username"`
-	LikesCount      int             `json:"likes_count"`
-	FeaturedUntil   *time.Time      `json:"featured_until,omitempty"`
-	CreatedAt       time.Time       `json:"created_at"`
-	LastEditedAt    time.Time       `json:"last_edited_at"`
-	IsPublic        bool            `json:"is_public"`
+	ID              uuid.UUID             `json:"id"`
+	Title           string                `json:"title"`
+	Description     string                `json:"description"`
+	Tags            []string              `json:"tags,omitempty"` // left out of list summaries
+	Data            json.RawMessage       `json:"data,omitempty"` // react-flow JSON data, left out of list summaries
+	CreatorID       uuid.UUID             `json:"creator_id"`
+	CreatorUsername string                `json:"creator_username"`
+	CreatorVerified bool                  `json:"creator_verified"`
+	LikesCount      int                   `json:"likes_count"`
+	ViewsCount      int                   `json:"views_count"`
+	FeaturedUntil   *time.Time            `json:"featured_until,omitempty"`
+	CreatedAt       time.Time             `json:"created_at"`
+	LastEditedAt    time.Time             `json:"last_edited_at"`
+	IsPublic        bool                  `json:"is_public"`
+	ClassroomID     *uuid.UUID            `json:"classroom_id,omitempty"` // set when shared only with a classroom roster
+	ForkedFrom      *uuid.UUID            `json:"forked_from,omitempty"`
+	ForkCount       int                   `json:"fork_count"`
+	Version         int                   `json:"version"`                  // incremented on every change of the flow, for optimistic concurrency
+	HiddenAt        *time.Time            `json:"hidden_at,omitempty"`      // set when moderators took the project down, only its creator can still see it
+	IsLikedByMe     *bool                 `json:"is_liked_by_me,omitempty"` // set for authenticated callers on the endpoints knowing who is asking
+	PublishedAt     *time.Time            `json:"published_at,omitempty"`   // set for embeds showing the published snapshot instead of the project
+	Accessibility   *ProjectAccessibility `json:"accessibility,omitempty"`  // set when the owner described the project for screen readers
+}
+
+// RecentProject is a project along with when the user last opened it.
+type RecentProject struct {
+	Project
+	OpenedAt time.Time `json:"opened_at"`
+}
+
+// ProjectRole defines the access a user has to a project.
+type ProjectRole string
+
+const (
+	ProjectOwner  ProjectRole = "owner"
+	ProjectEditor ProjectRole = "editor"
+	ProjectViewer ProjectRole = "viewer"
+)
+
+// ProjectMember represents a user a project is shared with.
+type ProjectMember struct {
+	UserID   uuid.UUID   `json:"user_id"`
+	Username string      `json:"username"`
+	Role     ProjectRole `json:"role"`
+	AddedAt  time.Time   `json:"added_at"`
+}
+
+// ProjectMemberInvite represents the data required to share a project with a user.
+type ProjectMemberInvite struct {
+	Email string      `json:"email" validate:"required,email"`
+	Role  ProjectRole `json:"role" validate:"required,oneof=viewer editor"`
 }
 
 // ProjectLike represents a single "like" or "bookmark" by a user on a project.
@@ -29,13 +70,30 @@ type ProjectLike struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// ProjectLiker represents a user who liked a project.
+type ProjectLiker struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Verified bool      `json:"verified"`
+	LikedAt  time.Time `json:"liked_at"`
+}
+
+// TitleConflict is returned when a creator gives a project a title they already use, with free titles to pick instead.
+type TitleConflict struct {
+	Title       string   `json:"title"`
+	Suggestions []string `json:"suggestions"`
+}
+
 // ProjectCreate represents the data required to create a new project.
 type ProjectCreate struct {
-	Title       string          `json:"title" validate:"required,min=3,max=100,alphanum"`
-	CreatorID   uuid.UUID       `json:"creator_id" validate:"required"`
-	Description string          `json:"description" validate:"max=5000"`
-	Data        json.RawMessage `json:"data,omitempty"`
-	IsPublic    bool            `json:"is_public" validate:"required"`
+	Title       string             `json:"title" validate:"required,min=3,max=100,alphanum"`
+	CreatorID   uuid.UUID          `json:"creator_id" validate:"required"`
+	Description string             `json:"description" validate:"max=5000"`
+	Data        json.RawMessage    `json:"data,omitempty"`
+	IsPublic    bool               `json:"is_public" validate:"required"`
+	ClassroomID *uuid.UUID         `json:"classroom_id,omitempty"`
+	DryRun      bool               `json:"-"` // validates and returns the project without persisting it
+	Revisions   []ArchivedRevision `json:"-"` // earlier versions restored from an export, oldest first
 }
 
 // ProjectUpdate represents the fields that can be updated for a project.
@@ -43,8 +101,32 @@ type ProjectUpdate struct {
 	ID          uuid.UUID       `json:"id"`
 	Title       *string         `json:"title,omitempty" validate:"omitempty,min=3,max=100"`
 	Description *string         `json:"description,omitempty" validate:"omitempty,max=5000"`
+	Tags        []string        `json:"tags,omitempty" validate:"omitempty,max=10,dive,min=1,max=30"` // an empty list clears the tags
 	IsPublic    *bool           `json:"is_public,omitempty"`
+	ClassroomID *uuid.UUID      `json:"classroom_id,omitempty"` // uuid.Nil stops sharing with the classroom
 	Data        json.RawMessage `json:"data,omitempty"`
+	DryRun      bool            `json:"-"` // returns the updated project without persisting the changes
+	IfMatch     *ProjectETag    `json:"-"` // updates the project only if it is still in this state
+}
+
+// NormalizeTags lowercases and trims tags and drops empty and repeated ones, keeping their order.
+func NormalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// ProjectBatchGet is a request for several projects at once, used by clients that would otherwise fetch them one by one.
+type ProjectBatchGet struct {
+	IDs []uuid.UUID `json:"ids" validate:"required,min=1,max=100"`
 }
 
 // PublicProjectFilter defines the options for filtering and paginating public projects.
@@ -52,7 +134,7 @@ type PublicProjectFilter struct {
 	Page       int    `query:"page" validate:"min=1"`
 	Limit      int    `query:"limit" validate:"min=1,max=100"`
 	SearchTerm string `query:"search_term" validate:"omitempty"`
-	SortField  string `query:"sort_field" validate:"omitempty,oneof=created_at likes_count last_edited_at"`
+	SortField  string `query:"sort_field" validate:"omitempty,oneof=created_at likes_count views_count last_edited_at trending"`
 	SortOrder  string `query:"sort_order" validate:"omitempty,oneof=asc desc"`
 }
 
@@ -66,6 +148,15 @@ func DefaultPublicProjectFilter() PublicProjectFilter {
 	}
 }
 
+// DiscoverOptions defines which public projects a random sample is drawn from.
+type DiscoverOptions struct {
+	UserID   *uuid.UUID // signed in viewer, their own projects are left out
+	IP       string     // address of a guest viewer
+	Limit    int
+	MinLikes int // likes a project needs to be picked
+	SeenDays int // projects the viewer viewed within as many days are left out
+}
+
 // ProjectFilter defines the options for filtering and paginating projects.
 type ProjectFilter struct {
 	// Pagination
@@ -76,7 +167,8 @@ type ProjectFilter struct {
 	SearchTerm      string  `query:"search_term" validate:"omitempty"`
 	CreatorUsername *string `query:"creator" validate:"omitempty"`
 	// IsPublic    *bool      `query:"is_public"`
-	IsFeatured *bool `query:"is_featured"`
+	IsFeatured *bool   `query:"is_featured"`
+	Label      *string `query:"label" validate:"omitempty,max=50"` // staff annotation label
 
 	// Time fields
 	CreatedBefore    *time.Time `query:"created_before" validate:"omitempty"`
@@ -102,3 +194,10 @@ func DefaultProjectFilter() ProjectFilter {
 		SortOrder: "desc",
 	}
 }
+
+// ProjectLink is a portable reference to a project, used when exporting lists of projects.
+type ProjectLink struct {
+	Title   string `json:"title"`
+	Creator string `json:"creator"`
+	URL     string `json:"url"`
+}
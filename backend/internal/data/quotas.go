@@ -0,0 +1,23 @@
+package data
+
+// Quota bounds what the users of a role can store and run, 0 disables a limit.
+type Quota struct {
+	MaxProjects int `json:"max_projects"` // projects a user can own
+	MaxBytes    int `json:"max_bytes"`    // size of the flow data of a single project
+	MaxStorage  int `json:"max_storage"`  // size of the flow data and thumbnails of all the projects a user owns
+
+	MaxRunSteps     int `json:"max_run_steps"`     // instructions a single run can take
+	MaxRunMillis    int `json:"max_run_millis"`    // how long a single run can take
+	MaxRunSegments  int `json:"max_run_segments"`  // lines a single run can draw
+	MaxComputeDaily int `json:"max_compute_daily"` // instructions all the runs of a user can take per day
+}
+
+// QuotaUsage is the quota of a user along with how much of it they use.
+type QuotaUsage struct {
+	Role         RoleType `json:"role"`
+	Projects     int      `json:"projects"`
+	StorageBytes int      `json:"storage_bytes"`
+	RunsToday    int      `json:"runs_today"`
+	ComputeToday int      `json:"compute_today"` // instructions taken by the runs of the day
+	Quota
+}
@@ -0,0 +1,47 @@
+package data
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Types of the events pushed to the open sessions of a user.
+const (
+	EventHello        = "hello" // the first event of every stream, the payload is RealtimeHello
+	EventEditConflict = "project.edit_conflict"
+	EventNotification = "notification" // a new in-app notification, the payload is the Notification
+)
+
+// RealtimeEvent is a single event sent to the browser, Type names the event and Data holds its payload.
+type RealtimeEvent struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// RealtimeHello tells a client the protocol version negotiated for its stream and the events it can receive.
+type RealtimeHello struct {
+	Protocol    int      `json:"protocol"`
+	MinProtocol int      `json:"min_protocol"`
+	MaxProtocol int      `json:"max_protocol"`
+	Events      []string `json:"events"`
+}
+
+// ProjectSave tells who saved the flow of a project last, and from which editor session.
+type ProjectSave struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Session  string    `json:"session,omitempty"`
+	SavedAt  time.Time `json:"saved_at"`
+}
+
+// EditConflict is sent when an autosave was rejected because the project changed in the meantime,
+// so the editor can offer to merge instead of silently failing to save.
+type EditConflict struct {
+	ProjectID       uuid.UUID    `json:"project_id"`
+	Version         int          `json:"version"`          // the current version of the project
+	RejectedVersion int          `json:"rejected_version"` // the version the rejected save was based on
+	Session         string       `json:"session,omitempty"`
+	SavedBy         *ProjectSave `json:"saved_by,omitempty"` // unknown when the flow was last changed outside the editor
+}
@@ -0,0 +1,41 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RenderJob is a thumbnail claimed by a render worker.
+type RenderJob struct {
+	ID        int64
+	ProjectID uuid.UUID
+	Format    ThumbnailFormat
+	Version   int // the version the thumbnail was asked for, the worker renders the project as it is when claiming
+	Attempts  int // claims so far, including this one
+}
+
+// RenderWorker represents a render worker process as it reported itself on its last heartbeat.
+type RenderWorker struct {
+	ID          string    `json:"id"`
+	Hostname    string    `json:"hostname"`
+	Concurrency int       `json:"concurrency"`
+	Busy        int       `json:"busy"`
+	JobsDone    int64     `json:"jobs_done"`
+	JobsFailed  int64     `json:"jobs_failed"`
+	StartedAt   time.Time `json:"started_at"`
+	HeartbeatAt time.Time `json:"heartbeat_at"`
+}
+
+// RenderQueueStats represents the thumbnails waiting for render workers, what worker pools scale on.
+type RenderQueueStats struct {
+	Pending       int     `json:"pending"`
+	Running       int     `json:"running"`
+	OldestPending float64 `json:"oldest_pending_seconds"` // 0 when nothing is pending
+}
+
+// RenderStatus represents the render queue along with the workers processing it.
+type RenderStatus struct {
+	Queue   RenderQueueStats `json:"queue"`
+	Workers []RenderWorker   `json:"workers"`
+}
@@ -0,0 +1,64 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReportReason is the category a user picks when reporting a project.
+type ReportReason string
+
+const (
+	ReportSpam          ReportReason = "spam"
+	ReportInappropriate ReportReason = "inappropriate"
+	ReportHarassment    ReportReason = "harassment"
+	ReportCopyright     ReportReason = "copyright"
+	ReportOther         ReportReason = "other"
+)
+
+// ReportStatus is the review state of a project report.
+type ReportStatus string
+
+const (
+	ReportOpen      ReportStatus = "open"
+	ReportDismissed ReportStatus = "dismissed"
+	ReportActioned  ReportStatus = "actioned" // the project was taken down
+)
+
+// ProjectReport is a report a user filed against a project, waiting for or resolved by a moderator.
+type ProjectReport struct {
+	ID               int64        `json:"id"`
+	ProjectID        uuid.UUID    `json:"project_id"`
+	ProjectTitle     string       `json:"project_title"`
+	ReporterID       uuid.UUID    `json:"reporter_id"`
+	ReporterUsername string       `json:"reporter_username"`
+	Reason           ReportReason `json:"reason"`
+	Details          string       `json:"details,omitempty"`
+	Status           ReportStatus `json:"status"`
+	ResolvedBy       *uuid.UUID   `json:"resolved_by,omitempty"`
+	ResolvedAt       *time.Time   `json:"resolved_at,omitempty"`
+	CreatedAt        time.Time    `json:"created_at"`
+}
+
+// ProjectReportSubmission is what a user submits when reporting a project.
+type ProjectReportSubmission struct {
+	Reason  ReportReason `json:"reason" validate:"required,oneof=spam inappropriate harassment copyright other"`
+	Details string       `json:"details" validate:"max=1000"`
+}
+
+// ProjectTakedownRequest is a moderator's decision to take a project down, the reason is sent to its creator.
+type ProjectTakedownRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// ProjectTakedown is a project taken down by a moderator.
+type ProjectTakedown struct {
+	ProjectID       uuid.UUID `json:"project_id"`
+	Title           string    `json:"title"`
+	CreatorUsername string    `json:"creator_username"`
+	CreatorEmail    string    `json:"-"`
+	Reason          string    `json:"reason"`
+	ReportsResolved int       `json:"reports_resolved"` // open reports of the project marked as actioned
+	HiddenAt        time.Time `json:"hidden_at"`
+}
@@ -0,0 +1,59 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Data classes with a retention period.
+const (
+	RetentionLoginHistory      = "login_history"
+	RetentionProjectViews      = "project_views"
+	RetentionTakenDownProjects = "taken_down_projects"
+	RetentionEmails            = "emails"
+	RetentionAnalyticsEvents   = "analytics_events"
+	RetentionAuditLogs         = "audit_logs"
+	RetentionNotifications     = "notifications"
+)
+
+// RetentionMethod is how expired data of a class is removed.
+type RetentionMethod string
+
+const (
+	RetentionPurge      RetentionMethod = "purge"      // rows are deleted by the retention job
+	RetentionPartitions RetentionMethod = "partitions" // monthly partitions are dropped by the partition maintenance
+)
+
+// RetentionTrigger is what started a purge.
+type RetentionTrigger string
+
+const (
+	RetentionScheduled RetentionTrigger = "scheduled"
+	RetentionManual    RetentionTrigger = "manual"
+)
+
+// RetentionClass is a kind of data and how long it is kept.
+// Purged classes are kept for a number of days, partitioned ones for a number of months besides the current one.
+// Neither set means the data is kept forever.
+type RetentionClass struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Method      RetentionMethod `json:"method"`
+	Days        int             `json:"retention_days,omitempty"`
+	Months      int             `json:"retention_months,omitempty"`
+	LastRun     *RetentionRun   `json:"last_run,omitempty"`
+}
+
+// RetentionRun records a purge of a data class.
+type RetentionRun struct {
+	ID         int64            `json:"id"`
+	Class      string           `json:"class"`
+	Cutoff     time.Time        `json:"cutoff"` // data older than this was deleted
+	Deleted    int64            `json:"deleted"`
+	Trigger    RetentionTrigger `json:"trigger"`
+	ActorID    *uuid.UUID       `json:"actor_id,omitempty"`
+	Error      string           `json:"error,omitempty"`
+	StartedAt  time.Time        `json:"started_at"`
+	FinishedAt time.Time        `json:"finished_at"`
+}
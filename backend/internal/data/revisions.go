@@ -0,0 +1,36 @@
+package data
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProjectRevision is an earlier version of a project, stored when the project's flow changed.
+// Data is left out of revision listings.
+type ProjectRevision struct {
+	ID          int64           `json:"id"`
+	ProjectID   uuid.UUID       `json:"project_id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Data        json.RawMessage `json:"data,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// revisionLimits is the number of revisions kept per project, by the role of the project owner.
+var revisionLimits = map[RoleType]int{
+	RoleUser:      10,
+	RolePremium:   100,
+	RoleModerator: 100,
+	RoleAdmin:     100,
+}
+
+// RevisionLimit returns how many revisions are kept for projects of an owner with the given role.
+// The oldest revisions are dropped beyond it.
+func RevisionLimit(role RoleType) int {
+	if limit, ok := revisionLimits[role]; ok {
+		return limit
+	}
+	return revisionLimits[RoleUser]
+}
@@ -17,6 +17,41 @@ type Role struct {
 	CreatedAt   time.Time `json:"created_at"`
 }
 
+// Permission names a single action a role can be granted, such as users.ban.
+type Permission string
+
+// Permissions guarding the administrative routes. Roles are granted them in the role_permissions table.
+const (
+	PermUsersRead           Permission = "users.read"
+	PermUsersUpdate         Permission = "users.update"
+	PermUsersDelete         Permission = "users.delete"
+	PermUsersBan            Permission = "users.ban"
+	PermUsersImpersonate    Permission = "users.impersonate"
+	PermUsersProvision      Permission = "users.provision"
+	PermProjectsRead        Permission = "projects.read"
+	PermProjectsFeature     Permission = "projects.feature"
+	PermDumpsGenerate       Permission = "dumps.generate"
+	PermMetricsRead         Permission = "metrics.read"
+	PermLTIManage           Permission = "lti.manage"
+	PermKeysRotate          Permission = "auth.keys.rotate"
+	PermJobsRead            Permission = "jobs.read"
+	PermFlagsManage         Permission = "flags.manage"
+	PermAnnouncementsManage Permission = "announcements.manage"
+	PermAnnotationsManage   Permission = "annotations.manage"
+	PermUsersVerify         Permission = "users.verify"
+	PermSignupsManage       Permission = "signups.manage"
+	PermTokensRevoke        Permission = "tokens.revoke"
+	PermProjectsCompact     Permission = "projects.compact"
+	PermReportsManage       Permission = "reports.manage"
+	PermRetentionManage     Permission = "retention.manage"
+	PermReadOnlyManage      Permission = "system.read_only"
+	PermUsersBulk           Permission = "users.bulk"
+	PermEmailsPreview       Permission = "emails.preview"
+	PermAuditRead           Permission = "audit.read"
+	PermAlertsManage        Permission = "alerts.manage"
+	PermTasksManage         Permission = "tasks.manage"
+)
+
 // RoleType is an enumeration type for the different user roles in the system.
 type RoleType string
 
@@ -0,0 +1,20 @@
+package data
+
+// RunSegment is a line drawn by a program run on the server, in the coordinates of the editor canvas
+// with the start at the origin and y pointing down.
+type RunSegment struct {
+	X1    float64 `json:"x1"`
+	Y1    float64 `json:"y1"`
+	X2    float64 `json:"x2"`
+	Y2    float64 `json:"y2"`
+	Color string  `json:"color"`
+}
+
+// ProjectRun is what a program run on the server drew and the compute it took.
+type ProjectRun struct {
+	Segments        []RunSegment `json:"segments"`
+	Steps           int          `json:"steps"`
+	Millis          int          `json:"millis"`
+	ComputeToday    int          `json:"compute_today"`     // instructions taken by the user's runs of the day, this one included
+	MaxComputeDaily int          `json:"max_compute_daily"` // 0 when unlimited
+}
@@ -0,0 +1,25 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SignupAllowlistEntry exempts a subnet or an email domain from the signup velocity limits.
+// Exactly one of Subnet and Domain is set.
+type SignupAllowlistEntry struct {
+	ID        int64      `json:"id"`
+	Subnet    *string    `json:"subnet,omitempty"`
+	Domain    *string    `json:"domain,omitempty"`
+	Note      string     `json:"note"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// SignupAllowlistCreate is the payload of a new allowlist entry, a subnet in CIDR notation, a single IP or an email domain.
+type SignupAllowlistCreate struct {
+	Subnet string `json:"subnet" validate:"omitempty,cidr|ip"`
+	Domain string `json:"domain" validate:"omitempty,fqdn"`
+	Note   string `json:"note" validate:"max=200"`
+}
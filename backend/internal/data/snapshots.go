@@ -0,0 +1,38 @@
+package data
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProjectSnapshot is the published version of a project, what embeds show and forks copy
+// while the owner keeps editing the project itself.
+type ProjectSnapshot struct {
+	ProjectID   uuid.UUID       `json:"project_id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Tags        []string        `json:"tags"`
+	Data        json.RawMessage `json:"data"`
+	Version     int             `json:"version"` // version of the project that was published
+	PublishedAt time.Time       `json:"published_at"`
+}
+
+// ProjectMetadataDraft holds the title, description and tags the owner staged for a project.
+// They replace those of the project when it is published next, along with a new snapshot of its data.
+type ProjectMetadataDraft struct {
+	ProjectID   uuid.UUID `json:"project_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Tags        []string  `json:"tags"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ProjectMetadataDraftUpdate stages changes of the metadata of a project. Fields left out keep their staged value,
+// or the current value of the project when nothing is staged yet.
+type ProjectMetadataDraftUpdate struct {
+	Title       *string  `json:"title,omitempty" validate:"omitempty,min=3,max=100"`
+	Description *string  `json:"description,omitempty" validate:"omitempty,max=5000"`
+	Tags        []string `json:"tags,omitempty" validate:"omitempty,max=10,dive,min=1,max=30"`
+}
@@ -0,0 +1,75 @@
+package data
+
+import "time"
+
+// DBHealth represents a snapshot of the database size, table health and connection usage for capacity planning.
+type DBHealth struct {
+	DatabaseBytes int64           `json:"database_bytes"`
+	Tables        []TableHealth   `json:"tables"`
+	Indexes       []IndexUsage    `json:"indexes"`
+	Pool          PoolStats       `json:"pool"`
+	InRecovery    bool            `json:"in_recovery"`          // the API is connected to a replica
+	ReplayLag     *float64        `json:"replay_lag,omitempty"` // seconds behind the primary, only set on a replica
+	Replicas      []ReplicaStatus `json:"replicas"`             // replicas streaming from this server, empty without any
+	CollectedAt   time.Time       `json:"collected_at"`
+}
+
+// ReadOnlyStatus represents whether the API is in read-only mode, in which reads are served and writes turned away.
+type ReadOnlyStatus struct {
+	Enabled bool       `json:"enabled"`
+	Reason  string     `json:"reason,omitempty"` // shown to users whose changes are turned away
+	Replica bool       `json:"replica"`          // reads are served from the replica rather than the primary database
+	Since   *time.Time `json:"since,omitempty"`
+}
+
+// ReadOnlyUpdate represents a request to switch read-only mode on or off.
+type ReadOnlyUpdate struct {
+	Enabled *bool  `json:"enabled" validate:"required"`
+	Reason  string `json:"reason" validate:"max=500"`
+}
+
+// TableHealth represents the size and vacuum state of a table. Row counts are the planner's estimates,
+// counting every row of a large table would be too slow for a dashboard.
+type TableHealth struct {
+	Name           string     `json:"name"`
+	Rows           int64      `json:"rows"`
+	DeadRows       int64      `json:"dead_rows"`
+	BloatRatio     float64    `json:"bloat_ratio"` // share of dead rows, an estimate of the space a vacuum would reclaim
+	TotalBytes     int64      `json:"total_bytes"`
+	TableBytes     int64      `json:"table_bytes"`
+	IndexBytes     int64      `json:"index_bytes"`
+	LastVacuum     *time.Time `json:"last_vacuum,omitempty"`
+	LastAutovacuum *time.Time `json:"last_autovacuum,omitempty"`
+	LastAnalyze    *time.Time `json:"last_analyze,omitempty"`
+}
+
+// IndexUsage represents how often an index has been used since the statistics were last reset.
+type IndexUsage struct {
+	Table  string `json:"table"`
+	Name   string `json:"name"`
+	Scans  int64  `json:"scans"`
+	Bytes  int64  `json:"bytes"`
+	Unused bool   `json:"unused"` // never scanned and not backing a unique or primary key constraint
+}
+
+// PoolStats represents the utilization of the connection pool of the API and of the connection limit of the server.
+type PoolStats struct {
+	MaxOpen           int     `json:"max_open"` // 0 means unlimited
+	Open              int     `json:"open"`
+	InUse             int     `json:"in_use"`
+	Idle              int     `json:"idle"`
+	WaitCount         int64   `json:"wait_count"`
+	WaitMilliseconds  int64   `json:"wait_ms"`
+	ServerConnections int     `json:"server_connections"`
+	MaxConnections    int     `json:"max_connections"`
+	Utilization       float64 `json:"utilization"` // share of the server connection limit in use
+}
+
+// ReplicaStatus represents a replica streaming from the database server.
+type ReplicaStatus struct {
+	Name       string   `json:"name"`
+	ClientAddr *string  `json:"client_addr,omitempty"`
+	State      string   `json:"state"`
+	LagBytes   *int64   `json:"lag_bytes,omitempty"`
+	ReplayLag  *float64 `json:"replay_lag,omitempty"` // seconds
+}
@@ -0,0 +1,51 @@
+package data
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// TaskKind is the kind of work a background task does.
+type TaskKind string
+
+const (
+	TaskEmail TaskKind = "email" // sends an EmailTask
+)
+
+// TaskStatus is the state of a background task in the queue.
+type TaskStatus string
+
+const (
+	TaskPending TaskStatus = "pending" // waiting to run, or to be retried at RunAt
+	TaskRunning TaskStatus = "running"
+	TaskDead    TaskStatus = "dead" // ran out of attempts, kept until an admin retries it
+)
+
+// Task is work queued to be done in the background and retried until it succeeds.
+// The payload isn't returned to admins, as emails carry tokens of the accounts they're sent to.
+type Task struct {
+	ID        int64           `json:"id"`
+	Kind      TaskKind        `json:"kind"`
+	Payload   json.RawMessage `json:"-"`
+	Status    TaskStatus      `json:"status"`
+	Attempts  int             `json:"attempts"`
+	RunAt     time.Time       `json:"run_at"`
+	LastError string          `json:"last_error,omitempty"`
+	RequestID string          `json:"request_id,omitempty"` // of the request that queued the task
+	CreatedAt time.Time       `json:"created_at"`
+	FailedAt  *time.Time      `json:"failed_at,omitempty"`
+}
+
+// TaskFilter narrows down the dead tasks to list, the latest to fail first. An empty kind matches any task.
+type TaskFilter struct {
+	Kind  TaskKind `query:"kind" validate:"omitempty,oneof=email"`
+	Limit int      `query:"limit" validate:"omitempty,min=1,max=500"`
+}
+
+// EmailTask is the payload of a task sending an email.
+type EmailTask struct {
+	To       string            `json:"to"`
+	Subject  string            `json:"subject"`
+	Template string            `json:"template"`
+	Data     map[string]string `json:"data"`
+}
@@ -0,0 +1,32 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ThumbnailFormat is the image format a project thumbnail is rendered in.
+type ThumbnailFormat string
+
+const (
+	ThumbnailPNG ThumbnailFormat = "png"
+	ThumbnailSVG ThumbnailFormat = "svg"
+)
+
+// ContentType returns the MIME type of the format.
+func (f ThumbnailFormat) ContentType() string {
+	if f == ThumbnailSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// Thumbnail represents an image of what the program of a project draws, rendered for a version of the project.
+type Thumbnail struct {
+	ProjectID  uuid.UUID       `json:"project_id"`
+	Format     ThumbnailFormat `json:"format"`
+	Version    int             `json:"version"`
+	Content    []byte          `json:"-"`
+	RenderedAt time.Time       `json:"rendered_at"`
+}
@@ -33,4 +33,29 @@ const (
 
 	// ScopeDeactivate is used for user account deactivation process.
 	ScopeDeactivate TokenScope = "deactive"
+
+	// ScopeMagicLink is used for logging in with a link sent by email instead of a password.
+	ScopeMagicLink TokenScope = "magic_link"
+
+	// ScopeActivationStatus is given to the client that registered an account, to poll whether it got activated.
+	ScopeActivationStatus TokenScope = "activation_status"
 )
+
+// TokenStats represents the number of stored tokens of a scope. Expired tokens are kept until they are cleaned up.
+type TokenStats struct {
+	Scope   TokenScope `json:"scope"`
+	Active  int64      `json:"active"`
+	Expired int64      `json:"expired"`
+}
+
+// TokenRevocation selects the tokens to revoke in bulk, every filter set has to match.
+type TokenRevocation struct {
+	UserID       *uuid.UUID `json:"user_id"`
+	Scope        TokenScope `json:"scope" validate:"omitempty,oneof=user_activation password_reset refresh deactive magic_link activation_status"`
+	IssuedBefore *time.Time `json:"issued_before"`
+}
+
+// Empty reports whether no filter is set, which would select every token.
+func (r TokenRevocation) Empty() bool {
+	return r.UserID == nil && r.Scope == "" && r.IssuedBefore == nil
+}
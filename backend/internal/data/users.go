@@ -13,24 +13,40 @@ import (
 
 // User represents a user in the system with their associated details.
 type User struct {
-	ID          uuid.UUID    `json:"id"`
-	Email       string       `json:"email"`
-	Username    string       `json:"username"`
-	Password    Password     `json:"-"`
-	RoleID      int64        `json:"-"`
-	Role        Role         `json:"role,omitempty"`
-	IsActivated bool         `json:"activated"`
-	LastLogin   sql.NullTime `json:"last_login,omitempty"`
-	CreatedAt   time.Time    `json:"created_at"`
-	Ban         *Ban         `json:"ban,omitempty"`
+	ID                    uuid.UUID    `json:"id"`
+	Email                 string       `json:"email"`
+	Username              string       `json:"username"`
+	Password              Password     `json:"-"`
+	RoleID                int64        `json:"-"`
+	Role                  Role         `json:"role,omitempty"`
+	IsActivated           bool         `json:"activated"`
+	Verified              bool         `json:"verified"`
+	PasswordResetRequired bool         `json:"password_reset_required"`
+	LastLogin             sql.NullTime `json:"last_login,omitempty"`
+	CreatedAt             time.Time    `json:"created_at"`
+	Ban                   *Ban         `json:"ban,omitempty"`
+	// GuestExpiresAt is set for anonymous guest accounts, which are deleted once it passes unless claimed
+	GuestExpiresAt *time.Time `json:"guest_expires_at,omitempty"`
+	// DeactivatedAt is set for accounts deactivated by an admin, which can't be activated again by the user
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"`
+}
+
+// IsDeactivated reports whether an admin deactivated the account, e.g. by deprovisioning it.
+func (u *User) IsDeactivated() bool {
+	return u.DeactivatedAt != nil
+}
+
+// IsGuest reports whether the user is an anonymous guest that hasn't registered yet.
+func (u *User) IsGuest() bool {
+	return u.GuestExpiresAt != nil
 }
 
 type Ban struct {
-	ID        int64     `json:"id"`
-	BannedAt  time.Time `json:"banned_at"`
-	Reason    string    `json:"reason"`
-	BannedBy  uuid.UUID `json:"banned_by,omitempty"`
-	ExpiresAt time.Time `json:"expires_at"`
+	ID        int64      `json:"id"`
+	BannedAt  time.Time  `json:"banned_at"`
+	Reason    string     `json:"reason"`
+	BannedBy  uuid.UUID  `json:"banned_by,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at"` // nil for a permanent ban
 }
 
 // for reading from database and checking if user has any bans
@@ -42,21 +58,39 @@ type OptionalBan struct {
 	ExpiresAt *time.Time
 }
 
+// NotNull reports whether the user has a ban. BannedBy may be NULL, as deleting a moderator keeps their bans,
+// and ExpiresAt is NULL for permanent bans.
 func (ob *OptionalBan) NotNull() bool {
 	return ob.ID != nil &&
-		ob.ExpiresAt != nil &&
 		ob.Reason != nil &&
-		ob.BannedAt != nil &&
-		ob.BannedBy != nil
+		ob.BannedAt != nil
+}
+
+// Banner returns who issued the ban, uuid.Nil when they were deleted since.
+func (ob *OptionalBan) Banner() uuid.UUID {
+	if ob.BannedBy == nil {
+		return uuid.Nil
+	}
+	return *ob.BannedBy
 }
 
 // IsValid checks if the ban is still active.
 func (b *Ban) IsValid() bool {
+	return b.ActiveAt(time.Now().UTC())
+}
+
+// ActiveAt checks if the ban is active at the given time, for callers holding a clock.
+func (b *Ban) ActiveAt(now time.Time) bool {
 	if b == nil {
 		return false
 	}
 
-	return b.ExpiresAt.After(time.Now().UTC())
+	return b.Permanent() || b.ExpiresAt.After(now)
+}
+
+// Permanent reports whether the ban never expires.
+func (b *Ban) Permanent() bool {
+	return b.ExpiresAt == nil
 }
 
 // MarshalJSON provides custom JSON serialization for User.
@@ -122,7 +156,8 @@ func (p *Password) Matches(plaintextPassword string) (bool, error) {
 type UserRegistration struct {
 	Email    string `json:"email" validate:"required,email"`
 	Username string `json:"username" validate:"required,min=3,max=20,alphanum"`
-	Password string `json:"password" validate:"required,min=8"`
+	// Password is checked against the password policy by the handler
+	Password string `json:"password" validate:"required"`
 }
 
 // UserLogin represents the data required for user login.
@@ -140,6 +175,66 @@ type UserUpdate struct {
 	Role      *RoleType `json:"role,omitempty"`
 }
 
+// UsernameChangeInterval is how long users wait between changes of their own username.
+const UsernameChangeInterval = 30 * 24 * time.Hour
+
+// UsernameReservation is how long a username given up stays reserved for its previous owner,
+// so nobody can take over the links to it right away.
+const UsernameReservation = 90 * 24 * time.Hour
+
+// ResolvedUsername is the user a username in a URL leads to. Moved is set when the user has given up the username,
+// clients should then replace it with the current one, like they would follow a 301 redirect.
+type ResolvedUsername struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Moved    bool      `json:"moved"`
+}
+
+// UserProvision represents a single roster entry for bulk account provisioning.
+// The username is generated from the email address when omitted.
+type UserProvision struct {
+	Email    string `json:"email" validate:"required,email"`
+	Username string `json:"username,omitempty" validate:"omitempty,min=3,max=20,alphanum"`
+}
+
+// ProvisionResult represents the outcome of provisioning or deprovisioning a single roster entry.
+// Password holds the generated credentials and is only returned once, on account creation.
+type ProvisionResult struct {
+	Email    string     `json:"email"`
+	UserID   *uuid.UUID `json:"user_id,omitempty"`
+	Username string     `json:"username,omitempty"`
+	Password string     `json:"password,omitempty"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// BulkUserAction is an action applied to many users at once.
+type BulkUserAction string
+
+const (
+	BulkActivate   BulkUserAction = "activate"
+	BulkDeactivate BulkUserAction = "deactivate"
+	BulkSetRole    BulkUserAction = "role"
+	BulkBan        BulkUserAction = "ban"
+)
+
+// BulkUserUpdate represents an action applied to a list of users in a single request.
+// Role is required to change roles, Reason and Duration (in hours) to ban, unless the ban is Permanent.
+type BulkUserUpdate struct {
+	Action    BulkUserAction `json:"action" validate:"required,oneof=activate deactivate role ban"`
+	UserIDs   []uuid.UUID    `json:"user_ids" validate:"required,min=1,max=500"`
+	Role      *RoleType      `json:"role,omitempty"`
+	Reason    string         `json:"reason,omitempty"`
+	Duration  int            `json:"duration,omitempty" validate:"omitempty,min=1"`
+	Permanent bool           `json:"permanent,omitempty"`
+}
+
+// BulkUserResult represents the outcome of a bulk action for a single user, Error is set if it was skipped.
+type BulkUserResult struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
 type UserFilter struct {
 	// Pagination
 	Page  int `query:"page" validate:"omitempty,min=1"`
@@ -151,6 +246,7 @@ type UserFilter struct {
 	Username         *string   `query:"username" validate:"omitempty"`
 	Email            *string   `query:"email" validate:"omitempty"`
 	SearchTerm       *string   `query:"search_term" validate:"omitempty"`
+	Label            *string   `query:"label" validate:"omitempty,max=50"` // staff annotation label
 
 	// Time fields
 	CreatedBefore   *time.Time `query:"created_before" validate:"omitempty"`
@@ -0,0 +1,57 @@
+package data
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// VerificationStatus is the review state of a verification request.
+type VerificationStatus string
+
+const (
+	VerificationPending  VerificationStatus = "pending"
+	VerificationApproved VerificationStatus = "approved"
+	VerificationRejected VerificationStatus = "rejected"
+)
+
+// VerificationRequest is a user's request for the verified creator badge, with the evidence admins review.
+type VerificationRequest struct {
+	ID         int64              `json:"id"`
+	UserID     uuid.UUID          `json:"user_id"`
+	Username   string             `json:"username"`
+	Evidence   string             `json:"evidence"`
+	Status     VerificationStatus `json:"status"`
+	ReviewNote string             `json:"review_note,omitempty"`
+	ReviewedBy *uuid.UUID         `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time         `json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+// VerificationSubmission is the evidence a user submits with a verification request,
+// e.g. links to their channel or school website.
+type VerificationSubmission struct {
+	Evidence string `json:"evidence" validate:"required,min=20,max=2000"`
+}
+
+// VerificationReview is an admin's decision on a pending verification request.
+type VerificationReview struct {
+	Status VerificationStatus `json:"status" validate:"required,oneof=approved rejected"`
+	Note   string             `json:"note" validate:"max=500"`
+}
+
+// verifiedLookalikes are words an account could put in its username to pass as verified or as staff.
+var verifiedLookalikes = []string{"verified", "official"}
+
+// LooksVerified reports whether the username could be mistaken for the verified badge, e.g. "AliceVerified".
+// Only verified accounts may use such usernames.
+func LooksVerified(username string) bool {
+	lower := strings.ToLower(username)
+	for _, word := range verifiedLookalikes {
+		if strings.Contains(lower, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Webhook events sent to project creators.
+const (
+	WebhookEventLikes    = "project.likes"
+	WebhookEventFeatured = "project.featured"
+)
+
+// ProjectWebhook is the endpoint a creator wants notified when their project reaches engagement milestones.
+type ProjectWebhook struct {
+	ProjectID      uuid.UUID `json:"project_id"`
+	URL            string    `json:"url"`
+	Secret         string    `json:"secret"`
+	LikeThresholds []int     `json:"like_thresholds"`
+	NotifyFeatured bool      `json:"notify_featured"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery records a single milestone notification and its outcome.
+type WebhookDelivery struct {
+	ID          int64      `json:"id"`
+	Event       string     `json:"event"`
+	Threshold   int        `json:"threshold,omitempty"`
+	StatusCode  *int       `json:"status_code,omitempty"`
+	Error       *string    `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+}
+
+// WebhookEvent is the JSON body posted to a webhook.
+type WebhookEvent struct {
+	Event        string    `json:"event"`
+	ProjectID    uuid.UUID `json:"project_id"`
+	ProjectTitle string    `json:"project_title"`
+	Threshold    int       `json:"threshold,omitempty"`
+	LikesCount   int       `json:"likes_count"`
+	OccurredAt   time.Time `json:"occurred_at"`
+}
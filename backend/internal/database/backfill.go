@@ -0,0 +1,202 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+)
+
+// ErrBackfillRunning is returned when running a backfill that is already being run elsewhere.
+var ErrBackfillRunning = errors.New("the backfill is already running")
+
+// RowBackfill describes a batched update of the rows of a table with a UUID id primary key, with values computed in Go
+// for what a Backfill's SET clause can't express, such as counting the nodes of a flow or generating slugs.
+type RowBackfill struct {
+	// Description tells what the backfill fills in, listed by cmd/backfill.
+	Description string
+	Table       string
+	// Columns are read from each row and handed to Compute, as the driver returns them: []byte for JSON and bytea,
+	// string for text, int64 for integers, nil for NULL.
+	Columns []string
+	// Set are the columns written with the values Compute returns, in order.
+	Set []string
+	// Where selects the rows that still need the backfill, e.g. "node_count IS NULL".
+	// A row written meanwhile no longer matching it is skipped, the application wrote the value already.
+	Where string
+	// Compute returns the values of the Set columns of a row from the values of its Columns.
+	Compute func(values []interface{}) ([]interface{}, error)
+	// BatchSize is the number of rows updated per transaction, row locks are held only for a single batch.
+	BatchSize int
+	// Rate caps the rows updated per second to leave room for regular traffic, 0 leaves it unlimited.
+	Rate float64
+}
+
+// BackfillRows updates the rows of a table in primary key order, one short transaction per batch.
+// Progress is recorded after every batch, an interrupted backfill resumes after the last processed row.
+func BackfillRows(name string, b RowBackfill) OnlineMigration {
+	if b.BatchSize <= 0 {
+		b.BatchSize = 500
+	}
+
+	selectQuery := fmt.Sprintf("SELECT id, %s FROM %s WHERE id > $1 AND (%s) ORDER BY id LIMIT $2 FOR UPDATE",
+		strings.Join(b.Columns, ", "), b.Table, b.Where)
+	assignments := make([]string, len(b.Set))
+	for i, column := range b.Set {
+		assignments[i] = fmt.Sprintf("%s = $%d", column, i+2)
+	}
+	updateQuery := fmt.Sprintf("UPDATE %s SET %s WHERE id = $1 AND (%s)", b.Table, strings.Join(assignments, ", "), b.Where)
+
+	return OnlineMigration{
+		Name: name,
+		Kind: data.JobKindBackfill,
+		run: func(ctx context.Context, conn *sql.Conn, job *jobProgress) error {
+			if job.total == nil {
+				var total int64
+				if err := conn.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", b.Table, b.Where)).Scan(&total); err != nil {
+					return err
+				}
+				if err := job.setTotal(total); err != nil {
+					return err
+				}
+			}
+
+			for {
+				started := time.Now()
+				updated, last, err := b.batch(ctx, conn, selectQuery, updateQuery, job.cursor)
+				if err != nil {
+					return err
+				}
+				if last == uuid.Nil {
+					return nil
+				}
+
+				if err := job.advance(updated, last); err != nil {
+					return err
+				}
+
+				var pause time.Duration
+				if b.Rate > 0 {
+					pause = time.Duration(float64(updated)/b.Rate*float64(time.Second)) - time.Since(started)
+				}
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(pause):
+				}
+			}
+		},
+	}
+}
+
+// batch updates the rows following the cursor and returns how many it updated and the last row it read,
+// uuid.Nil once no rows are left.
+func (b RowBackfill) batch(ctx context.Context, conn *sql.Conn, selectQuery, updateQuery string, cursor uuid.UUID) (int64, uuid.UUID, error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, uuid.Nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, selectQuery, cursor, b.BatchSize)
+	if err != nil {
+		return 0, uuid.Nil, err
+	}
+
+	type row struct {
+		id     uuid.UUID
+		values []interface{}
+	}
+	batch := make([]row, 0, b.BatchSize)
+	for rows.Next() {
+		r := row{values: make([]interface{}, len(b.Columns))}
+		dest := []interface{}{&r.id}
+		for i := range r.values {
+			dest = append(dest, &r.values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			rows.Close()
+			return 0, uuid.Nil, err
+		}
+		batch = append(batch, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, uuid.Nil, err
+	}
+	if len(batch) == 0 {
+		return 0, uuid.Nil, nil
+	}
+
+	var updated int64
+	for _, r := range batch {
+		values, err := b.Compute(r.values)
+		if err != nil {
+			return 0, uuid.Nil, fmt.Errorf("row %s: %w", r.id, err)
+		}
+		if len(values) != len(b.Set) {
+			return 0, uuid.Nil, fmt.Errorf("row %s: %d values computed for %d columns", r.id, len(values), len(b.Set))
+		}
+
+		result, err := tx.ExecContext(ctx, updateQuery, append([]interface{}{r.id}, values...)...)
+		if err != nil {
+			return 0, uuid.Nil, err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, uuid.Nil, err
+		}
+		updated += n
+	}
+
+	return updated, batch[len(batch)-1].id, tx.Commit()
+}
+
+// RunBackfill runs one of the Backfills on demand, as cmd/backfill does. A completed one is skipped
+// unless restart is set, which runs it again from the first row.
+// It returns ErrBackfillRunning if it is being run elsewhere.
+func RunBackfill(ctx context.Context, db *sql.DB, m OnlineMigration, restart bool) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// the lock of a single backfill, the API may apply unrelated online migrations meanwhile
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1, hashtext($2))", onlineMigrationsLock, m.Name).Scan(&locked); err != nil {
+		return err
+	}
+	if !locked {
+		return ErrBackfillRunning
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1, hashtext($2))", onlineMigrationsLock, m.Name)
+
+	if restart {
+		query := `
+			UPDATE background_jobs
+			SET status = 'running', total = NULL, processed = 0, cursor = NULL, error = NULL,
+			    started_at = NOW(), updated_at = NOW(), finished_at = NULL
+			WHERE name = $1`
+		if _, err := conn.ExecContext(ctx, query, m.Name); err != nil {
+			return err
+		}
+	}
+
+	job, err := startJob(ctx, conn, m)
+	if err != nil || job == nil {
+		return err
+	}
+
+	if err := m.run(ctx, conn, job); err != nil {
+		job.fail(err)
+		return fmt.Errorf("backfill %s: %w", m.Name, err)
+	}
+	return job.complete()
+}
@@ -0,0 +1,37 @@
+package database
+
+import "time"
+
+// OnlineMigrations are applied in the background by the API after the SQL migrations ran,
+// see migrations/README.md. Append new migrations at the end and never rename applied ones,
+// the name is how a completed migration is recognized.
+var OnlineMigrations = []OnlineMigration{
+	CreateIndexConcurrently("idx_projects_public_created_at", "projects (created_at DESC) WHERE is_public = TRUE"),
+	CreateIndexConcurrently("idx_projects_hidden_at", "projects (hidden_at) WHERE hidden_at IS NOT NULL"),
+	// keyset pages of the public and featured listings
+	CreateIndexConcurrently("idx_projects_public_created_at_id", "projects (created_at, id) WHERE is_public = TRUE AND hidden_at IS NULL"),
+	CreateIndexConcurrently("idx_projects_featured_until_id", "projects (featured_until, id) WHERE featured_until IS NOT NULL"),
+	// checksums of the projects saved before they were written along with the data
+	BackfillBatches("projects_data_checksum", Backfill{
+		Table:     "projects",
+		Set:       "data_checksum = md5(data::text)",
+		Where:     "data_checksum IS NULL",
+		BatchSize: 1000,
+		Pause:     100 * time.Millisecond,
+	}),
+	// sizes of the projects saved before they were written along with the data
+	BackfillBatches("projects_data_bytes", Backfill{
+		Table:     "projects",
+		Set:       "data_bytes = octet_length(data::text)",
+		Where:     "data_bytes IS NULL",
+		BatchSize: 1000,
+		Pause:     100 * time.Millisecond,
+	}),
+	// titles are compared case-insensitively among the projects of a creator when unique titles are enforced
+	CreateIndexConcurrently("idx_projects_creator_id_lower_title", "projects (creator_id, LOWER(title))"),
+}
+
+// Backfills are one-off data migrations computed in Go, run on demand with cmd/backfill rather than by the API,
+// typically to fill in a new denormalized column the application writes from then on. Progress is recorded in
+// background_jobs under their name like the progress of online migrations.
+var Backfills = map[string]RowBackfill{}
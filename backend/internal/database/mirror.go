@@ -0,0 +1,247 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sync"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/lib/pq"
+)
+
+// Read-only mode
+//
+// While the primary database fails over, or during maintenance that can't run while users write, the API keeps
+// serving reads instead of going down. Mirror is the connector of the API's connection pool: in read-only mode it
+// opens new connections to a replica, and the read-only middleware turns writes away before they reach the database.
+//
+// Switching drops the idle connections of the pool, and for the drain period the connections in use are closed as
+// they're returned, so the whole pool moves over once the requests running at the switch are done. Requests are
+// cancelled after the write timeout, which must stay below the drain period.
+//
+// The mode is shared by every instance through the read_only_mode table of the primary database, which each instance
+// reads every sync interval, see Sync. While the primary database can't be reached instances stay in their mode.
+//
+// Only the API's pool is switched. The cache and realtime listeners keep their connection to the primary database
+// and reconnect once it's back; nothing is written in read-only mode, so they miss no notifications.
+
+const (
+	// idleConns is the number of idle connections the pool keeps, database/sql's default
+	idleConns = 2
+	// drainPeriod is how long connections returned after a switch are closed rather than kept idle
+	drainPeriod = 30 * time.Second
+	// syncInterval is how often the shared mode is read from the primary database
+	syncInterval = 5 * time.Second
+)
+
+// Mirror connects the pool to the primary database, or to a replica in read-only mode.
+// It implements driver.Connector.
+type Mirror struct {
+	primary    driver.Connector
+	replica    driver.Connector // nil without a replica, reads then stay on the primary database
+	db         *sql.DB
+	shared     *sql.DB // the primary database whatever the mode, holding the mode shared by every instance
+	switching  sync.Mutex
+	mu         sync.RWMutex
+	status     data.ReadOnlyStatus
+	pending    bool // the mode of the instance is yet to be written to the primary database
+	generation int
+}
+
+// ConnectMirror establishes the connection pool of the API through a Mirror, in read-only mode if the config says so.
+func ConnectMirror(cfg config.DatabaseConfig) (*sql.DB, *Mirror, error) {
+	primary, err := pq.NewConnector(DSN(cfg))
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not connect to database: %w", err)
+	}
+
+	m := &Mirror{primary: primary}
+	if cfg.ReplicaURL != "" {
+		replica, err := pq.NewConnector(cfg.ReplicaURL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not connect to the replica: %w", err)
+		}
+		m.replica = replica
+	}
+	// instances started in read-only mode switch the others once the primary database can be reached
+	if cfg.ReadOnly {
+		now := time.Now().UTC()
+		m.status = data.ReadOnlyStatus{Enabled: true, Replica: m.replica != nil, Since: &now}
+		m.pending = true
+	}
+	m.shared = sql.OpenDB(primary)
+	m.shared.SetMaxOpenConns(1)
+
+	m.db = sql.OpenDB(m)
+	m.db.SetMaxOpenConns(cfg.MaxOpenConns)
+	m.db.SetMaxIdleConns(idleConns)
+
+	// in read-only mode this is the replica, the primary may be down
+	if err := m.db.Ping(); err != nil {
+		m.db.Close()
+		return nil, nil, fmt.Errorf("could not ping database: %w", err)
+	}
+
+	return m.db, m, nil
+}
+
+// Connect opens a connection to the replica in read-only mode, to the primary database otherwise.
+func (m *Mirror) Connect(ctx context.Context) (driver.Conn, error) {
+	m.mu.RLock()
+	connector := m.primary
+	if m.status.Enabled && m.replica != nil {
+		connector = m.replica
+	}
+	m.mu.RUnlock()
+
+	return connector.Connect(ctx)
+}
+
+// Driver returns the driver of the primary database.
+func (m *Mirror) Driver() driver.Driver {
+	return m.primary.Driver()
+}
+
+// ReadOnly reports whether the API is in read-only mode.
+func (m *Mirror) ReadOnly() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.status.Enabled
+}
+
+// Status returns whether the API is in read-only mode, since when and why.
+func (m *Mirror) Status() data.ReadOnlyStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.status
+}
+
+// SetReadOnly switches read-only mode on or off for every instance, and moves the pool between the primary database and
+// the replica. Switching it on again only updates the reason. The other instances follow within the sync interval.
+//
+// The mode is written to the primary database first. Switching on still happens when that fails, as the primary
+// database may be down, and the mode is shared once it's back; the error is returned all the same. Switching off
+// doesn't happen without the primary database.
+func (m *Mirror) SetReadOnly(ctx context.Context, enabled bool, reason string) (data.ReadOnlyStatus, error) {
+	m.switching.Lock()
+	defer m.switching.Unlock()
+
+	status := m.Status()
+	switch {
+	case enabled && status.Enabled:
+		status.Reason = reason
+	case enabled:
+		now := time.Now().UTC()
+		status = data.ReadOnlyStatus{Enabled: true, Reason: reason, Since: &now}
+	default:
+		status = data.ReadOnlyStatus{}
+	}
+
+	err := m.share(ctx, status)
+	if err != nil && !enabled {
+		return m.Status(), err
+	}
+	m.apply(status, err != nil)
+
+	return m.Status(), err
+}
+
+// Sync keeps the instance in the mode shared through the primary database until ctx is done, reading it every sync
+// interval. A mode the instance was switched to while the primary database couldn't be reached is written instead.
+func (m *Mirror) Sync(ctx context.Context) {
+	ticker := time.NewTicker(syncInterval)
+	defer ticker.Stop()
+
+	for {
+		// the instance stays in its mode while the primary database is down, it's tried again on the next tick
+		syncCtx, cancel := context.WithTimeout(ctx, syncInterval)
+		m.sync(syncCtx)
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Mirror) sync(ctx context.Context) error {
+	m.switching.Lock()
+	defer m.switching.Unlock()
+
+	m.mu.RLock()
+	status, pending := m.status, m.pending
+	m.mu.RUnlock()
+
+	if pending {
+		if err := m.share(ctx, status); err != nil {
+			return err
+		}
+		m.apply(status, false)
+		return nil
+	}
+
+	var shared data.ReadOnlyStatus
+	err := m.shared.QueryRowContext(ctx, "SELECT enabled, reason, since FROM read_only_mode").Scan(&shared.Enabled, &shared.Reason, &shared.Since)
+	if err != nil {
+		return err
+	}
+	if shared.Enabled != status.Enabled || shared.Reason != status.Reason {
+		m.apply(shared, false)
+	}
+	return nil
+}
+
+// share writes the mode to the primary database for the other instances.
+func (m *Mirror) share(ctx context.Context, status data.ReadOnlyStatus) error {
+	_, err := m.shared.ExecContext(ctx,
+		"UPDATE read_only_mode SET enabled = $1, reason = $2, since = $3",
+		status.Enabled, status.Reason, status.Since,
+	)
+	return err
+}
+
+// apply puts the instance in the mode, moving the pool if it switches between the primary database and the replica.
+func (m *Mirror) apply(status data.ReadOnlyStatus, pending bool) {
+	status.Replica = status.Enabled && m.replica != nil
+
+	m.mu.Lock()
+	wasReplica := m.status.Enabled && m.replica != nil
+	m.status = status
+	m.pending = pending
+	moved := wasReplica != (status.Enabled && m.replica != nil)
+	if moved {
+		m.generation++
+	}
+	generation := m.generation
+	m.mu.Unlock()
+
+	if moved {
+		m.drain(generation)
+	}
+}
+
+// drain closes the connections to the database the pool moved away from, idle ones right away
+// and the ones in use as they're returned during the drain period.
+func (m *Mirror) drain(generation int) {
+	m.db.SetMaxIdleConns(0)
+
+	time.AfterFunc(drainPeriod, func() {
+		m.mu.RLock()
+		current := m.generation == generation
+		m.mu.RUnlock()
+
+		// a later switch restores the idle connections after its own drain period
+		if current {
+			m.db.SetMaxIdleConns(idleConns)
+		}
+	})
+}
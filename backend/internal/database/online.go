@@ -0,0 +1,239 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+)
+
+// onlineMigrationsLock is the advisory lock key held while online migrations run,
+// so only one API instance applies them at a time.
+const onlineMigrationsLock = 7316
+
+// OnlineMigration is a schema change too slow to apply in a regular migration without blocking writes,
+// such as building an index or backfilling a column on a large table. Online migrations are applied
+// by the API in the background and their progress is tracked in background_jobs under their name.
+type OnlineMigration struct {
+	Name string
+	Kind string
+	run  func(ctx context.Context, conn *sql.Conn, job *jobProgress) error
+}
+
+// RunOnlineMigrations applies the migrations that didn't complete yet, in order.
+// It stops at the first failing migration, as later ones may depend on it, and retries it on the next run.
+// Nothing is applied if another instance is already running them.
+func RunOnlineMigrations(ctx context.Context, db *sql.DB, migrations []OnlineMigration) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", onlineMigrationsLock).Scan(&locked); err != nil {
+		return err
+	}
+	if !locked {
+		return nil
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", onlineMigrationsLock)
+
+	for _, m := range migrations {
+		job, err := startJob(ctx, conn, m)
+		if err != nil {
+			return err
+		}
+		if job == nil {
+			continue
+		}
+
+		if err := m.run(ctx, conn, job); err != nil {
+			job.fail(err)
+			return fmt.Errorf("online migration %s: %w", m.Name, err)
+		}
+		if err := job.complete(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreateIndexConcurrently builds an index without locking writes to the table.
+// definition is everything after ON, e.g. "projects (created_at DESC) WHERE is_public = TRUE".
+// An invalid index left behind by an interrupted build is dropped and built again.
+func CreateIndexConcurrently(name, definition string) OnlineMigration {
+	return OnlineMigration{
+		Name: name,
+		Kind: data.JobKindIndex,
+		run: func(ctx context.Context, conn *sql.Conn, _ *jobProgress) error {
+			var valid bool
+			err := conn.QueryRowContext(ctx, `
+				SELECT i.indisvalid
+				FROM pg_class c
+				JOIN pg_index i ON i.indexrelid = c.oid
+				WHERE c.relname = $1`,
+				name,
+			).Scan(&valid)
+			switch {
+			case err == nil && valid:
+				return nil
+			case err == nil:
+				if _, err := conn.ExecContext(ctx, "DROP INDEX CONCURRENTLY IF EXISTS "+name); err != nil {
+					return err
+				}
+			case err != sql.ErrNoRows:
+				return err
+			}
+
+			// the build waits for running transactions, but must not queue up behind a lock and block writes meanwhile
+			if _, err := conn.ExecContext(ctx, "SET lock_timeout = '10s'"); err != nil {
+				return err
+			}
+			defer conn.ExecContext(context.Background(), "RESET lock_timeout")
+
+			_, err = conn.ExecContext(ctx, fmt.Sprintf("CREATE INDEX CONCURRENTLY %s ON %s", name, definition))
+			return err
+		},
+	}
+}
+
+// Backfill describes a batched update of the rows of a table with a UUID id primary key.
+type Backfill struct {
+	Table string
+	// Set is the SET clause applied to each row, e.g. "search_title = lower(title)".
+	Set string
+	// Where selects the rows that still need the backfill, e.g. "search_title IS NULL".
+	// Rows written meanwhile should be covered by the application, so they don't match it.
+	Where string
+	// BatchSize is the number of rows updated per statement, row locks are held only for a single batch.
+	BatchSize int
+	// Pause is the time waited between batches to leave room for regular traffic.
+	Pause time.Duration
+}
+
+// BackfillBatches updates the rows of a table in primary key order, one short transaction per batch.
+// Progress is recorded after every batch, an interrupted backfill resumes after the last processed row.
+func BackfillBatches(name string, b Backfill) OnlineMigration {
+	if b.BatchSize <= 0 {
+		b.BatchSize = 1000
+	}
+
+	query := fmt.Sprintf(`
+		WITH batch AS (
+			SELECT id FROM %[1]s WHERE id > $1 AND (%[3]s) ORDER BY id LIMIT $2
+		), updated AS (
+			UPDATE %[1]s SET %[2]s FROM batch WHERE %[1]s.id = batch.id AND (%[3]s) RETURNING 1
+		)
+		SELECT (SELECT COUNT(*) FROM updated), (SELECT id FROM batch ORDER BY id DESC LIMIT 1)`,
+		b.Table, b.Set, b.Where,
+	)
+
+	return OnlineMigration{
+		Name: name,
+		Kind: data.JobKindBackfill,
+		run: func(ctx context.Context, conn *sql.Conn, job *jobProgress) error {
+			if job.total == nil {
+				var total int64
+				if err := conn.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", b.Table, b.Where)).Scan(&total); err != nil {
+					return err
+				}
+				if err := job.setTotal(total); err != nil {
+					return err
+				}
+			}
+
+			for {
+				var updated int64
+				var last uuid.NullUUID
+				if err := conn.QueryRowContext(ctx, query, job.cursor, b.BatchSize).Scan(&updated, &last); err != nil {
+					return err
+				}
+				if !last.Valid {
+					return nil
+				}
+
+				if err := job.advance(updated, last.UUID); err != nil {
+					return err
+				}
+
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(b.Pause):
+				}
+			}
+		},
+	}
+}
+
+// jobProgress records the progress of an online migration in background_jobs.
+type jobProgress struct {
+	conn   *sql.Conn
+	name   string
+	total  *int64
+	cursor uuid.UUID
+}
+
+// startJob marks the migration as running and returns its progress, or nil if it already completed.
+func startJob(ctx context.Context, conn *sql.Conn, m OnlineMigration) (*jobProgress, error) {
+	job := &jobProgress{conn: conn, name: m.Name}
+
+	var status data.JobStatus
+	var cursor sql.NullString
+	err := conn.QueryRowContext(ctx, `
+		INSERT INTO background_jobs (name, kind, status)
+		VALUES ($1, $2, 'running')
+		ON CONFLICT (name) DO UPDATE
+		SET status = CASE WHEN background_jobs.status = 'completed' THEN 'completed' ELSE 'running' END,
+		    error = NULL, updated_at = NOW()
+		RETURNING status, total, cursor`,
+		m.Name, m.Kind,
+	).Scan(&status, &job.total, &cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	if status == data.JobCompleted {
+		return nil, nil
+	}
+
+	if cursor.Valid {
+		if job.cursor, err = uuid.Parse(cursor.String); err != nil {
+			return nil, err
+		}
+	}
+
+	return job, nil
+}
+
+func (j *jobProgress) setTotal(total int64) error {
+	j.total = &total
+	_, err := j.conn.ExecContext(context.Background(),
+		"UPDATE background_jobs SET total = $2, updated_at = NOW() WHERE name = $1", j.name, total)
+	return err
+}
+
+func (j *jobProgress) advance(processed int64, cursor uuid.UUID) error {
+	j.cursor = cursor
+	_, err := j.conn.ExecContext(context.Background(),
+		"UPDATE background_jobs SET processed = processed + $2, cursor = $3, updated_at = NOW() WHERE name = $1",
+		j.name, processed, cursor.String())
+	return err
+}
+
+func (j *jobProgress) complete() error {
+	_, err := j.conn.ExecContext(context.Background(),
+		"UPDATE background_jobs SET status = 'completed', updated_at = NOW(), finished_at = NOW() WHERE name = $1", j.name)
+	return err
+}
+
+func (j *jobProgress) fail(cause error) {
+	j.conn.ExecContext(context.Background(),
+		"UPDATE background_jobs SET status = 'failed', error = $2, updated_at = NOW() WHERE name = $1", j.name, cause.Error())
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// partitionSuffix is the layout of the month in partition names, e.g. events_p2025_01.
+const partitionSuffix = "_p2006_01"
+
+// PartitionedTable is a table partitioned by month on created_at.
+type PartitionedTable struct {
+	Name string
+	// Retention is the number of months kept besides the current one, older partitions are dropped. 0 keeps all data.
+	Retention int
+}
+
+// MaintainPartitions creates the partitions of the current and the next ahead months of each table,
+// and drops the partitions that fell out of the table's retention.
+func MaintainPartitions(ctx context.Context, db *sql.DB, tables []PartitionedTable, ahead int, now time.Time) error {
+	month := monthStart(now)
+
+	for _, t := range tables {
+		for i := 0; i <= ahead; i++ {
+			if err := createPartition(ctx, db, t.Name, month.AddDate(0, i, 0)); err != nil {
+				return fmt.Errorf("could not create partition of %s: %w", t.Name, err)
+			}
+		}
+
+		if t.Retention > 0 {
+			if err := dropPartitionsBefore(ctx, db, t.Name, month.AddDate(0, -t.Retention, 0)); err != nil {
+				return fmt.Errorf("could not prune partitions of %s: %w", t.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func partitionName(table string, month time.Time) string {
+	return table + month.Format(partitionSuffix)
+}
+
+func createPartition(ctx context.Context, db *sql.DB, table string, month time.Time) error {
+	// table names come from code, the bounds are formatted by us
+	_, err := db.ExecContext(ctx, fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM ('%s') TO ('%s')",
+		partitionName(table, month), table, month.Format(time.RFC3339), month.AddDate(0, 1, 0).Format(time.RFC3339),
+	))
+	return err
+}
+
+// dropPartitionsBefore drops the partitions of the table holding only rows created before the month.
+// Partitions not following our naming are left alone.
+func dropPartitionsBefore(ctx context.Context, db *sql.DB, table string, month time.Time) error {
+	rows, err := db.QueryContext(ctx, `
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		JOIN pg_class p ON p.oid = i.inhparent
+		WHERE p.relname = $1`,
+		table,
+	)
+	if err != nil {
+		return err
+	}
+
+	expired := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+
+		suffix, ok := strings.CutPrefix(name, table)
+		if !ok {
+			continue
+		}
+		start, err := time.Parse(partitionSuffix, suffix)
+		if err != nil {
+			continue
+		}
+		if start.Before(month) {
+			expired = append(expired, name)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, name := range expired {
+		if _, err := db.ExecContext(ctx, "DROP TABLE IF EXISTS "+name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
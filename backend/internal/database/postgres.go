@@ -9,17 +9,21 @@ import (
 	_ "github.com/lib/pq" // PostgreSQL driver
 )
 
-// Connect establishes a connection to the PostgreSQL database
-func Connect(cfg config.DatabaseConfig) (*sql.DB, error) {
-	dsn := fmt.Sprintf(
+// DSN returns the connection string of the PostgreSQL database
+func DSN(cfg config.DatabaseConfig) string {
+	return fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, cfg.SSLMode,
 	)
+}
 
-	db, err := sql.Open("postgres", dsn)
+// Connect establishes a connection to the PostgreSQL database
+func Connect(cfg config.DatabaseConfig) (*sql.DB, error) {
+	db, err := sql.Open("postgres", DSN(cfg))
 	if err != nil {
 		return nil, fmt.Errorf("could not connect to database: %w", err)
 	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
 
 	// Test the connection
 	if err := db.Ping(); err != nil {
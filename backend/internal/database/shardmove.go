@@ -0,0 +1,302 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ShardedTable is a table holding user data that moves with its owner between regional databases.
+type ShardedTable struct {
+	Name string
+	// Owned is the condition selecting the rows of the user $1
+	Owned string
+}
+
+const (
+	ownedProjects   = "project_id IN (SELECT id FROM projects WHERE creator_id = $1)"
+	ownedClassrooms = "classroom_id IN (SELECT id FROM classrooms WHERE owner_id = $1)"
+)
+
+// ShardedTables are the tables moved with a user, parents before the tables referencing them: every table with a
+// foreign key to users, or to another table of the list, that deletes or blocks along with the referenced row.
+// New tables holding user data must be added here, or their rows are deleted with the user's when they move.
+// TestShardedTables checks the list against the foreign keys of the schema.
+var ShardedTables = []ShardedTable{
+	{Name: "users", Owned: "id = $1"},
+	{Name: "tokens", Owned: "user_id = $1"},
+	{Name: "oauth_identities", Owned: "user_id = $1"},
+	{Name: "trusted_locations", Owned: "user_id = $1"},
+	{Name: "login_challenges", Owned: "user_id = $1"},
+	{Name: "user_consents", Owned: "user_id = $1"},
+	{Name: "notification_preferences", Owned: "user_id = $1"},
+	{Name: "email_preferences", Owned: "user_id = $1"},
+	{Name: "user_profiles", Owned: "user_id = $1"},
+	{Name: "user_annotations", Owned: "user_id = $1"},
+	{Name: "username_history", Owned: "user_id = $1"},
+	{Name: "notifications", Owned: "user_id = $1"},
+	{Name: "email_outbox", Owned: "user_id = $1"},
+	{Name: "data_exports", Owned: "user_id = $1"},
+	{Name: "account_deletions", Owned: "user_id = $1"},
+	{Name: "verification_requests", Owned: "user_id = $1"},
+	{Name: "banned_users", Owned: "user_id = $1"},
+	{Name: "ban_history", Owned: "user_id = $1"},
+	{Name: "ban_appeals", Owned: "user_id = $1"},
+	{Name: "compute_usage", Owned: "user_id = $1"},
+	{Name: "lti_user_links", Owned: "user_id = $1"},
+	{Name: "lti_link_requests", Owned: "user_id = $1"},
+	{Name: "lti_launches", Owned: "user_id = $1"},
+	{Name: "lti_deep_links", Owned: "user_id = $1"},
+	{Name: "project_uploads", Owned: "user_id = $1"},
+	{Name: "project_upload_chunks", Owned: "upload_id IN (SELECT id FROM project_uploads WHERE user_id = $1)"},
+	{Name: "project_fork_events", Owned: "user_id = $1"},
+	{Name: "classrooms", Owned: "owner_id = $1"},
+	{Name: "classroom_members", Owned: "user_id = $1 OR " + ownedClassrooms},
+	{Name: "classroom_invites", Owned: "user_id = $1 OR " + ownedClassrooms},
+	{Name: "project_folders", Owned: "user_id = $1"},
+	{Name: "projects", Owned: "creator_id = $1"},
+	{Name: "project_revisions", Owned: ownedProjects},
+	{Name: "project_snapshots", Owned: ownedProjects},
+	{Name: "project_metadata_drafts", Owned: ownedProjects},
+	{Name: "folder_projects", Owned: ownedProjects + " OR folder_id IN (SELECT id FROM project_folders WHERE user_id = $1)"},
+	{Name: "project_accessibility", Owned: ownedProjects},
+	{Name: "project_thumbnails", Owned: ownedProjects},
+	{Name: "render_jobs", Owned: ownedProjects},
+	{Name: "project_annotations", Owned: ownedProjects},
+	{Name: "featured_queue", Owned: ownedProjects},
+	{Name: "project_members", Owned: "user_id = $1 OR " + ownedProjects},
+	{Name: "project_credits", Owned: "user_id = $1 OR " + ownedProjects},
+	{Name: "project_likes", Owned: "user_id = $1 OR " + ownedProjects},
+	{Name: "user_recent_projects", Owned: "user_id = $1 OR " + ownedProjects},
+	{Name: "project_reports", Owned: "reporter_id = $1 OR " + ownedProjects},
+	{Name: "project_views", Owned: ownedProjects},
+	{Name: "project_webhooks", Owned: ownedProjects},
+	{Name: "project_webhook_deliveries", Owned: ownedProjects},
+	{Name: "domain_events", Owned: ownedProjects},
+}
+
+// directoryStub strips the users row of the primary database down to the directory entry of a user who moved away,
+// so none of their personal data stays behind. The stub can't sign in, it only keeps the region and the row other
+// rows of the primary database refer to.
+const directoryStub = `
+	UPDATE users
+	SET region = $2, email = id::text || '@moved.invalid', username = 'moved-' || id::text, password = ''::bytea,
+		avatar_key = NULL, last_login = NULL
+	WHERE id = $1`
+
+// ErrSameRegion is returned when moving a user to the region they are in already.
+var ErrSameRegion = errors.New("the user is in the region already")
+
+// ErrPendingRequest is returned when moving a user with a scheduled deletion or an unfinished data export,
+// which only the primary database processes.
+var ErrPendingRequest = errors.New("the user has a scheduled deletion or an unfinished data export")
+
+// ShardMove is the outcome of moving a user between regional databases.
+type ShardMove struct {
+	ID         int64
+	UserID     uuid.UUID
+	FromRegion string
+	ToRegion   string
+	RowsCopied map[string]int64
+}
+
+// MoveUser moves the rows of a user in ShardedTables from the database of their region to the database of another region.
+//
+// The user's row and projects are locked in the source database for the whole move, so edits wait instead of getting lost.
+// The rows are copied in a single transaction on the target and counted there before the directory is pointed at it,
+// and only then deleted from the source. The users row of the primary database is the directory and is never deleted:
+// leaving the primary database strips it to a stub in the same transaction as the deletes, see directoryStub,
+// and coming back replaces the stub with the copied row.
+// Foreign keys are not checked while copying, as rows like likes reference users living in other regions;
+// this needs a role allowed to set session_replication_role.
+//
+// Users with a scheduled deletion or an unfinished data export are not moved, see ErrPendingRequest. Moving back to
+// the home region records no region in the directory, so services can tell users living elsewhere by their region.
+//
+// Every move is recorded in shard_moves. A move that failed after the copy is left "copied" with the rows still in
+// both databases and has to be finished by hand.
+func MoveUser(ctx context.Context, shards *Shards, userID uuid.UUID, toRegion string) (*ShardMove, error) {
+	fromRegion, err := shards.UserRegion(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not look up the user's region: %w", err)
+	}
+	if toRegion == shards.home {
+		toRegion = ""
+	}
+	if fromRegion == toRegion || (toRegion == "" && fromRegion == shards.home) {
+		return nil, ErrSameRegion
+	}
+
+	src, err := shards.DB(fromRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending bool
+	err = src.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM account_deletions WHERE user_id = $1)
+		    OR EXISTS (SELECT 1 FROM data_exports WHERE user_id = $1 AND status = 'pending')`,
+		userID,
+	).Scan(&pending)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, ErrPendingRequest
+	}
+	dst, err := shards.DB(toRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	move := &ShardMove{UserID: userID, FromRegion: fromRegion, ToRegion: toRegion, RowsCopied: map[string]int64{}}
+	err = shards.primary.QueryRowContext(ctx,
+		"INSERT INTO shard_moves (user_id, from_region, to_region) VALUES ($1, $2, $3) RETURNING id",
+		userID, fromRegion, toRegion,
+	).Scan(&move.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := moveRows(ctx, shards, src, dst, move)
+	if err != nil {
+		finishMove(shards.primary, move, status, err)
+		return nil, err
+	}
+
+	finishMove(shards.primary, move, "done", nil)
+	return move, nil
+}
+
+// moveRows does the move and returns the status the move reached, for the record.
+func moveRows(ctx context.Context, shards *Shards, src, dst *sql.DB, move *ShardMove) (string, error) {
+	srcTx, err := src.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return "failed", err
+	}
+	defer srcTx.Rollback()
+
+	if _, err := srcTx.ExecContext(ctx, "SELECT 1 FROM users WHERE id = $1 FOR UPDATE", move.UserID); err != nil {
+		return "failed", err
+	}
+	if _, err := srcTx.ExecContext(ctx, "SELECT 1 FROM projects WHERE creator_id = $1 FOR UPDATE", move.UserID); err != nil {
+		return "failed", err
+	}
+
+	dstTx, err := dst.BeginTx(ctx, nil)
+	if err != nil {
+		return "failed", err
+	}
+	defer dstTx.Rollback()
+
+	if _, err := dstTx.ExecContext(ctx, "SET LOCAL session_replication_role = replica"); err != nil {
+		return "failed", err
+	}
+
+	// the stub left by moving away is replaced, cascades don't fire for replicas so its references stay
+	if dst == shards.primary {
+		if _, err := dstTx.ExecContext(ctx, "DELETE FROM users WHERE id = $1", move.UserID); err != nil {
+			return "failed", err
+		}
+	}
+
+	for _, t := range ShardedTables {
+		copied, err := copyRows(ctx, srcTx, dstTx, t, move.UserID)
+		if err != nil {
+			return "failed", fmt.Errorf("could not copy %s: %w", t.Name, err)
+		}
+		move.RowsCopied[t.Name] = copied
+	}
+
+	if err := dstTx.Commit(); err != nil {
+		return "failed", err
+	}
+
+	// from here on the target has the data, point the directory at it before cleaning up. The users row of the primary
+	// database is locked by the move when leaving it, so it's updated in the same transaction.
+	if src == shards.primary {
+		if _, err := srcTx.ExecContext(ctx, directoryStub, move.UserID, move.ToRegion); err != nil {
+			return "copied", err
+		}
+	} else if _, err := shards.primary.ExecContext(ctx, "UPDATE users SET region = $2 WHERE id = $1", move.UserID, move.ToRegion); err != nil {
+		return "copied", err
+	}
+
+	for i := len(ShardedTables) - 1; i >= 0; i-- {
+		t := ShardedTables[i]
+		if t.Name == "users" && src == shards.primary {
+			continue
+		}
+		if _, err := srcTx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s", t.Name, t.Owned), move.UserID); err != nil {
+			return "copied", fmt.Errorf("could not delete %s: %w", t.Name, err)
+		}
+	}
+
+	if err := srcTx.Commit(); err != nil {
+		return "copied", err
+	}
+
+	return "done", nil
+}
+
+// copyRows copies the user's rows of a table as JSON, so it works for any table without listing its columns,
+// and checks the target has as many of them afterwards.
+func copyRows(ctx context.Context, srcTx, dstTx *sql.Tx, t ShardedTable, userID uuid.UUID) (int64, error) {
+	// table names and conditions come from ShardedTables
+	rows, err := srcTx.QueryContext(ctx, fmt.Sprintf("SELECT row_to_json(t)::text FROM %s t WHERE %s", t.Name, t.Owned), userID)
+	if err != nil {
+		return 0, err
+	}
+
+	var records []string
+	for rows.Next() {
+		var record string
+		if err := rows.Scan(&record); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		records = append(records, record)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	insert := fmt.Sprintf("INSERT INTO %[1]s OVERRIDING SYSTEM VALUE SELECT * FROM json_populate_record(NULL::%[1]s, $1::json) ON CONFLICT DO NOTHING", t.Name)
+	for _, record := range records {
+		if _, err := dstTx.ExecContext(ctx, insert, record); err != nil {
+			return 0, err
+		}
+	}
+
+	var count int64
+	if err := dstTx.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", t.Name, t.Owned), userID).Scan(&count); err != nil {
+		return 0, err
+	}
+	if count != int64(len(records)) {
+		return 0, fmt.Errorf("expected %d rows on the target, found %d", len(records), count)
+	}
+
+	return count, nil
+}
+
+func finishMove(primary *sql.DB, move *ShardMove, status string, cause error) {
+	var total int64
+	for _, n := range move.RowsCopied {
+		total += n
+	}
+
+	message := ""
+	if cause != nil {
+		message = cause.Error()
+	}
+
+	// recorded even when the move was cancelled
+	primary.ExecContext(context.Background(),
+		"UPDATE shard_moves SET status = $2, rows_copied = $3, error = $4, finished_at = NOW() WHERE id = $1",
+		move.ID, status, total, message,
+	)
+}
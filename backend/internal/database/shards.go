@@ -0,0 +1,142 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"NodeTurtleAPI/internal/config"
+
+	"github.com/google/uuid"
+)
+
+// Data residency
+//
+// Users can be placed in a region so their data lives in a database located there, e.g. EU users in an EU
+// database, while a single API serves everyone. Every regional database runs the same migrations.
+//
+// The users table of the primary database is the directory: it has a row for every user, and users.region
+// names the database holding the rest of their data ("" or the home region is the primary database itself).
+// Shards resolves a user's database from it, and MoveUser moves a user between databases.
+//
+// Services still query the primary database only. Until they resolve the database of the user they act for
+// through Shards, users moved to another region stop seeing their data, so cmd/shard-move refuses to run
+// outside staging. Account deletions and data exports refuse users living in another region rather than
+// leave their regional data behind.
+// Data shared between users across regions (likes, memberships, credits) keeps referencing users living
+// elsewhere; foreign keys to them are only checked within a database.
+
+// ErrUnknownRegion is returned for a region without a configured database.
+var ErrUnknownRegion = errors.New("no database is configured for the region")
+
+// Shards resolves the database holding the data of a user.
+type Shards struct {
+	primary *sql.DB
+	home    string
+	regions map[string]*sql.DB
+}
+
+// NewShards creates a resolver over already connected regional databases.
+func NewShards(primary *sql.DB, home string, regions map[string]*sql.DB) *Shards {
+	return &Shards{
+		primary: primary,
+		home:    home,
+		regions: regions,
+	}
+}
+
+// OpenShards connects to every regional database of the config, each with the same pool size as the primary one.
+func OpenShards(cfg config.ShardsConfig, primary *sql.DB, maxOpenConns int) (*Shards, error) {
+	regions := map[string]*sql.DB{}
+	for region, url := range cfg.URLs {
+		if region == cfg.Home {
+			continue
+		}
+
+		db, err := sql.Open("postgres", url)
+		if err != nil {
+			closeAll(regions)
+			return nil, fmt.Errorf("could not connect to the %s database: %w", region, err)
+		}
+		db.SetMaxOpenConns(maxOpenConns)
+
+		if err := db.Ping(); err != nil {
+			db.Close()
+			closeAll(regions)
+			return nil, fmt.Errorf("could not ping the %s database: %w", region, err)
+		}
+		regions[region] = db
+	}
+
+	return NewShards(primary, cfg.Home, regions), nil
+}
+
+// Primary returns the primary database, which holds the user directory.
+func (s *Shards) Primary() *sql.DB {
+	return s.primary
+}
+
+// DB returns the database of a region. The home region and no region at all are the primary database.
+// It returns ErrUnknownRegion if no database is configured for the region.
+func (s *Shards) DB(region string) (*sql.DB, error) {
+	if region == "" || region == s.home {
+		return s.primary, nil
+	}
+
+	db, ok := s.regions[region]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRegion, region)
+	}
+	return db, nil
+}
+
+// UserRegion looks up the region of a user in the directory.
+// It returns sql.ErrNoRows if the user doesn't exist.
+func (s *Shards) UserRegion(ctx context.Context, userID uuid.UUID) (string, error) {
+	var region string
+	err := s.primary.QueryRowContext(ctx, "SELECT region FROM users WHERE id = $1", userID).Scan(&region)
+	return region, err
+}
+
+// ForUser returns the database holding the data of a user.
+func (s *Shards) ForUser(ctx context.Context, userID uuid.UUID) (*sql.DB, error) {
+	region, err := s.UserRegion(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.DB(region)
+}
+
+// CheckRegions verifies a database is configured for every region users were placed in,
+// so removing a regional database from the config can't silently cut users off their data.
+func (s *Shards) CheckRegions(ctx context.Context) error {
+	rows, err := s.primary.QueryContext(ctx, "SELECT DISTINCT region FROM users WHERE region <> ''")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var region string
+		if err := rows.Scan(&region); err != nil {
+			return err
+		}
+		if _, err := s.DB(region); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// Close closes the regional databases. The primary database is left to its owner.
+func (s *Shards) Close() {
+	closeAll(s.regions)
+}
+
+func closeAll(dbs map[string]*sql.DB) {
+	for _, db := range dbs {
+		db.Close()
+	}
+}
@@ -0,0 +1,130 @@
+package flow
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// ErrNotFlow is returned by Canonicalize for data that isn't a flow object, which is left for people to look at.
+var ErrNotFlow = errors.New("not a flow object")
+
+// documentKeys, nodeKeys and edgeKeys are the fields the editor reads back when a project is opened.
+// Anything else was written by older editor versions or copied from react-flow's internal state,
+// such as selected, dragging, measured or positionAbsolute, and is dropped.
+var (
+	documentKeys = []string{"nodes", "edges", "viewport"}
+	nodeKeys     = []string{"id", "type", "position", "data", "parentId"}
+	edgeKeys     = []string{"id", "source", "target", "sourceHandle", "targetHandle", "type"}
+)
+
+// Canonicalize re-serializes project data in a canonical form: unknown fields are stripped, object keys are sorted,
+// edges between nodes that no longer exist and viewports the editor can't restore are removed, and nodeCount is recounted.
+// Node data is the editor's own state and is kept as is, apart from the ordering of its keys.
+// The order of nodes and edges is kept, as the editor stacks nodes in that order.
+//
+// Data encoded as a string containing the flow stays encoded that way, since that is the form the editor reads.
+// An empty payload is returned unchanged, and data that isn't a flow object returns ErrNotFlow.
+func Canonicalize(raw json.RawMessage) (json.RawMessage, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return raw, nil
+	}
+
+	encoded := raw[0] == '"'
+	if encoded {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, ErrNotFlow
+		}
+		raw = json.RawMessage(s)
+	}
+
+	var doc map[string]any
+	if err := decode(raw, &doc); err != nil || doc == nil {
+		return nil, ErrNotFlow
+	}
+
+	canonical := pick(doc, documentKeys)
+
+	nodes, _ := doc["nodes"].([]any)
+	keptNodes := []any{}
+	nodeIDs := map[string]bool{}
+	for _, n := range nodes {
+		node, ok := n.(map[string]any)
+		if !ok {
+			continue
+		}
+		if id, ok := node["id"].(string); ok {
+			nodeIDs[id] = true
+		}
+		node = pick(node, nodeKeys)
+		if position, ok := node["position"].(map[string]any); ok {
+			node["position"] = pick(position, []string{"x", "y"})
+		}
+		keptNodes = append(keptNodes, node)
+	}
+	canonical["nodes"] = keptNodes
+	canonical["nodeCount"] = len(keptNodes)
+
+	edges, _ := doc["edges"].([]any)
+	keptEdges := []any{}
+	for _, e := range edges {
+		edge, ok := e.(map[string]any)
+		if !ok {
+			continue
+		}
+		source, _ := edge["source"].(string)
+		target, _ := edge["target"].(string)
+		if !nodeIDs[source] || !nodeIDs[target] {
+			continue
+		}
+		keptEdges = append(keptEdges, pick(edge, edgeKeys))
+	}
+	canonical["edges"] = keptEdges
+
+	if vp, ok := doc["viewport"].(map[string]any); ok && validViewport(vp) {
+		canonical["viewport"] = pick(vp, []string{"x", "y", "zoom"})
+	} else {
+		// the editor falls back to the default viewport
+		delete(canonical, "viewport")
+	}
+
+	// maps are marshaled with sorted keys, at every level
+	out, err := json.Marshal(canonical)
+	if err != nil {
+		return nil, err
+	}
+	if encoded {
+		return json.Marshal(string(out))
+	}
+	return out, nil
+}
+
+// decode unmarshals keeping numbers as written, so re-serializing doesn't change their precision or notation.
+func decode(raw json.RawMessage, v any) error {
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+	return d.Decode(v)
+}
+
+// pick returns the given keys of an object, leaving out those it doesn't have.
+func pick(object map[string]any, keys []string) map[string]any {
+	picked := make(map[string]any, len(keys))
+	for _, key := range keys {
+		if value, ok := object[key]; ok {
+			picked[key] = value
+		}
+	}
+	return picked
+}
+
+func validViewport(vp map[string]any) bool {
+	for _, key := range []string{"x", "y", "zoom"} {
+		if _, ok := vp[key].(json.Number); !ok {
+			return false
+		}
+	}
+	zoom, err := vp["zoom"].(json.Number).Float64()
+	return err == nil && zoom > 0
+}
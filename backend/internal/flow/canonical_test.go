@@ -0,0 +1,79 @@
+package flow
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalize(t *testing.T) {
+	tests := map[string]struct {
+		data    string
+		want    string
+		wantErr error
+	}{
+		"Empty payload": {
+			data: ``,
+			want: ``,
+		},
+		"Null payload": {
+			data: `null`,
+			want: `null`,
+		},
+		"Empty flow": {
+			data: `{}`,
+			want: `{"edges":[],"nodeCount":0,"nodes":[]}`,
+		},
+		"Canonical flow is unchanged": {
+			data: `{"edges":[{"id":"e1","source":"a","sourceHandle":"out","target":"b","targetHandle":"in"}],"nodeCount":2,"nodes":[{"data":{},"id":"a","position":{"x":0,"y":0},"type":"startNode"},{"data":{"distance":10},"id":"b","position":{"x":0,"y":100},"type":"moveNode"}],"viewport":{"x":0,"y":0,"zoom":1}}`,
+			want: `{"edges":[{"id":"e1","source":"a","sourceHandle":"out","target":"b","targetHandle":"in"}],"nodeCount":2,"nodes":[{"data":{},"id":"a","position":{"x":0,"y":0},"type":"startNode"},{"data":{"distance":10},"id":"b","position":{"x":0,"y":100},"type":"moveNode"}],"viewport":{"x":0,"y":0,"zoom":1}}`,
+		},
+		"Editor state is stripped": {
+			data: `{"nodes":[{"id":"a","type":"startNode","position":{"x":1.50,"y":2},"positionAbsolute":{"x":1.5,"y":2},"selected":true,"dragging":false,"measured":{"width":150,"height":40},"width":150,"data":{"label":"kept","muted":false}}],"edges":[],"viewport":{"x":0,"y":0,"zoom":1},"nodeCount":1,"legacyVersion":3}`,
+			want: `{"edges":[],"nodeCount":1,"nodes":[{"data":{"label":"kept","muted":false},"id":"a","position":{"x":1.50,"y":2},"type":"startNode"}],"viewport":{"x":0,"y":0,"zoom":1}}`,
+		},
+		"Orphaned edges are removed": {
+			data: `{"nodes":[{"id":"a","type":"startNode","position":{"x":0,"y":0}}],"edges":[{"id":"e1","source":"a","target":"deleted","selected":false},{"id":"e2","source":"a","target":"a","animated":true}]}`,
+			want: `{"edges":[{"id":"e2","source":"a","target":"a"}],"nodeCount":1,"nodes":[{"id":"a","position":{"x":0,"y":0},"type":"startNode"}]}`,
+		},
+		"Invalid viewport is removed": {
+			data: `{"nodes":[],"edges":[],"viewport":{"x":null,"y":0,"zoom":0}}`,
+			want: `{"edges":[],"nodeCount":0,"nodes":[]}`,
+		},
+		"Stale node count is recounted": {
+			data: `{"nodes":[{"id":"a","type":"startNode","position":{"x":0,"y":0}},"garbage"],"edges":[],"nodeCount":7}`,
+			want: `{"edges":[],"nodeCount":1,"nodes":[{"id":"a","position":{"x":0,"y":0},"type":"startNode"}]}`,
+		},
+		"Flow encoded as a string stays encoded": {
+			data: `"{\"viewport\":{\"zoom\":1,\"x\":0,\"y\":0},\"nodes\":[{\"selected\":true,\"id\":\"a\",\"type\":\"startNode\",\"position\":{\"x\":0,\"y\":0}}],\"edges\":[]}"`,
+			want: `"{\"edges\":[],\"nodeCount\":1,\"nodes\":[{\"id\":\"a\",\"position\":{\"x\":0,\"y\":0},\"type\":\"startNode\"}],\"viewport\":{\"x\":0,\"y\":0,\"zoom\":1}}"`,
+		},
+		"Not a flow object": {
+			data:    `[1, 2]`,
+			wantErr: ErrNotFlow,
+		},
+		"String not containing a flow": {
+			data:    `"hello"`,
+			wantErr: ErrNotFlow,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Canonicalize(json.RawMessage(tt.data))
+
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+
+			// canonical data stays as it is
+			again, err := Canonicalize(got)
+			assert.NoError(t, err)
+			assert.Equal(t, string(got), string(again))
+		})
+	}
+}
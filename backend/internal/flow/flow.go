@@ -0,0 +1,323 @@
+// Package flow validates the react-flow documents stored as project data.
+// Errors point into the submitted payload so the editor can highlight the offending node or edge.
+package flow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const maxCommentLength = 5000
+
+// FieldError describes a single invalid value within a payload.
+// Path is a readable location such as data.nodes[3].data.angle and Pointer the same location as an RFC 6901 JSON pointer.
+type FieldError struct {
+	Path    string `json:"path"`
+	Pointer string `json:"pointer"`
+	Message string `json:"message"`
+	NodeID  string `json:"node_id,omitempty"`
+	EdgeID  string `json:"edge_id,omitempty"`
+}
+
+// Errors collects all the problems found in a payload.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Path + ": " + err.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Limits bounds the size of a flow, 0 disables a limit.
+type Limits struct {
+	MaxNodes int
+	MaxBytes int // of the submitted payload
+}
+
+// paramKind is the expected JSON type of a node parameter.
+type paramKind int
+
+const (
+	kindBool paramKind = iota
+	kindNumber
+	kindCount
+	kindString
+	kindText
+)
+
+// nodeParams lists the parameters each node type accepts. Parameters are optional, the editor falls back to defaults.
+var nodeParams = map[string]map[string]paramKind{
+	"nodeBase":    {},
+	"startNode":   {},
+	"moveNode":    {"distance": kindNumber},
+	"rotateNode":  {"angle": kindNumber},
+	"loopNode":    {"loopCount": kindCount, "createTurtleOnIteration": kindBool},
+	"penNode":     {"penDown": kindBool, "color": kindString},
+	"commentNode": {"content": kindText, "collapsed": kindBool},
+}
+
+// NodeTypes returns the node types a flow can contain, sorted by name.
+func NodeTypes() []string {
+	types := make([]string, 0, len(nodeParams))
+	for t := range nodeParams {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+type document struct {
+	Nodes    []json.RawMessage `json:"nodes"`
+	Edges    []json.RawMessage `json:"edges"`
+	Viewport json.RawMessage   `json:"viewport"`
+}
+
+type viewport struct {
+	X, Y, Zoom *float64
+}
+
+type node struct {
+	ID       *string                    `json:"id"`
+	Type     *string                    `json:"type"`
+	Position *struct{ X, Y *float64 }   `json:"position"`
+	Data     map[string]json.RawMessage `json:"data"`
+}
+
+type edge struct {
+	ID     *string `json:"id"`
+	Source *string `json:"source"`
+	Target *string `json:"target"`
+}
+
+// Validate checks project data submitted under the given field name against the limits and returns nil or Errors.
+// The editor submits the flow either as a JSON object or as a string containing one, both are accepted.
+// An empty payload is valid.
+func Validate(field string, raw json.RawMessage, limits Limits) error {
+	v := validator{limits: limits}
+
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil
+	}
+	if limits.MaxBytes > 0 && len(raw) > limits.MaxBytes {
+		v.add(location{}.key(field), fmt.Sprintf("must be at most %d bytes", limits.MaxBytes), "", "")
+		return v.errs
+	}
+
+	if raw[0] == '"' {
+		var encoded string
+		if err := json.Unmarshal(raw, &encoded); err != nil {
+			v.add(location{}.key(field), "must be a flow object", "", "")
+			return v.errs
+		}
+		raw = json.RawMessage(encoded)
+	}
+
+	v.document(location{}.key(field), raw)
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+// location is a path into the payload, kept as its segments so it can be rendered both ways.
+type location []string
+
+func (l location) key(name string) location {
+	return append(l[:len(l):len(l)], name)
+}
+
+func (l location) index(i int) location {
+	return append(l[:len(l):len(l)], "["+strconv.Itoa(i)+"]")
+}
+
+func (l location) path() string {
+	var b strings.Builder
+	for _, segment := range l {
+		if b.Len() > 0 && !strings.HasPrefix(segment, "[") {
+			b.WriteByte('.')
+		}
+		b.WriteString(segment)
+	}
+	return b.String()
+}
+
+func (l location) pointer() string {
+	var b strings.Builder
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+	for _, segment := range l {
+		b.WriteByte('/')
+		b.WriteString(escaper.Replace(strings.Trim(segment, "[]")))
+	}
+	return b.String()
+}
+
+type validator struct {
+	limits Limits
+	errs   Errors
+}
+
+func (v *validator) add(at location, message, nodeID, edgeID string) {
+	v.errs = append(v.errs, FieldError{
+		Path:    at.path(),
+		Pointer: at.pointer(),
+		Message: message,
+		NodeID:  nodeID,
+		EdgeID:  edgeID,
+	})
+}
+
+func (v *validator) document(at location, raw json.RawMessage) {
+	var doc document
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		v.add(at, "must be a flow object with nodes and edges arrays", "", "")
+		return
+	}
+
+	if v.limits.MaxNodes > 0 && len(doc.Nodes) > v.limits.MaxNodes {
+		// not worth looking into every node of a flow that is rejected anyway
+		v.add(at.key("nodes"), fmt.Sprintf("must have at most %d nodes", v.limits.MaxNodes), "", "")
+		return
+	}
+
+	if doc.Viewport != nil && !bytes.Equal(doc.Viewport, []byte("null")) {
+		v.viewport(at.key("viewport"), doc.Viewport)
+	}
+
+	nodeIDs := map[string]bool{}
+	for i, rawNode := range doc.Nodes {
+		if id := v.node(at.key("nodes").index(i), rawNode); id != "" {
+			if nodeIDs[id] {
+				v.add(at.key("nodes").index(i).key("id"), "duplicate node id", id, "")
+			}
+			nodeIDs[id] = true
+		}
+	}
+
+	for i, rawEdge := range doc.Edges {
+		v.edge(at.key("edges").index(i), rawEdge, nodeIDs)
+	}
+}
+
+// viewport validates the pan and zoom the editor restores when the project is opened.
+func (v *validator) viewport(at location, raw json.RawMessage) {
+	var vp viewport
+	if err := json.Unmarshal(raw, &vp); err != nil || vp.X == nil || vp.Y == nil || vp.Zoom == nil {
+		v.add(at, "must have numeric x, y and zoom", "", "")
+		return
+	}
+	if *vp.Zoom <= 0 {
+		v.add(at.key("zoom"), "must be greater than 0", "", "")
+	}
+}
+
+// node validates a single node and returns its id, or an empty string if it has none.
+func (v *validator) node(at location, raw json.RawMessage) string {
+	var n node
+	if err := json.Unmarshal(raw, &n); err != nil {
+		v.add(at, "must be a node object", "", "")
+		return ""
+	}
+
+	id := ""
+	if n.ID == nil || *n.ID == "" {
+		v.add(at.key("id"), "is required", "", "")
+	} else {
+		id = *n.ID
+	}
+
+	if n.Position == nil || n.Position.X == nil || n.Position.Y == nil {
+		v.add(at.key("position"), "must have numeric x and y coordinates", id, "")
+	}
+
+	if n.Type == nil {
+		v.add(at.key("type"), "is required", id, "")
+		return id
+	}
+	params, ok := nodeParams[*n.Type]
+	if !ok {
+		v.add(at.key("type"), fmt.Sprintf("unknown node type %q", *n.Type), id, "")
+		return id
+	}
+
+	names := make([]string, 0, len(n.Data))
+	for name := range n.Data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		kind, ok := params[name]
+		if name == "muted" {
+			kind, ok = kindBool, true
+		}
+		if !ok {
+			// the editor keeps its own state in node data, unknown keys are left alone
+			continue
+		}
+		if message := checkParam(kind, n.Data[name]); message != "" {
+			v.add(at.key("data").key(name), message, id, "")
+		}
+	}
+
+	return id
+}
+
+func (v *validator) edge(at location, raw json.RawMessage, nodeIDs map[string]bool) {
+	var e edge
+	if err := json.Unmarshal(raw, &e); err != nil {
+		v.add(at, "must be an edge object", "", "")
+		return
+	}
+
+	id := ""
+	if e.ID == nil || *e.ID == "" {
+		v.add(at.key("id"), "is required", "", "")
+	} else {
+		id = *e.ID
+	}
+
+	if e.Source == nil || !nodeIDs[*e.Source] {
+		v.add(at.key("source"), "must reference an existing node", "", id)
+	}
+	if e.Target == nil || !nodeIDs[*e.Target] {
+		v.add(at.key("target"), "must reference an existing node", "", id)
+	}
+}
+
+func checkParam(kind paramKind, raw json.RawMessage) string {
+	switch kind {
+	case kindBool:
+		var b bool
+		if json.Unmarshal(raw, &b) != nil {
+			return "must be a boolean"
+		}
+	case kindNumber:
+		var n float64
+		if json.Unmarshal(raw, &n) != nil || math.IsInf(n, 0) {
+			return "must be a number"
+		}
+	case kindCount:
+		var n float64
+		if json.Unmarshal(raw, &n) != nil || n != math.Trunc(n) || n < 0 {
+			return "must be a whole number of at least 0"
+		}
+	case kindString, kindText:
+		var s string
+		if json.Unmarshal(raw, &s) != nil {
+			return "must be a string"
+		}
+		if kind == kindText && len(s) > maxCommentLength {
+			return fmt.Sprintf("must be at most %d characters", maxCommentLength)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,122 @@
+package flow
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	tests := map[string]struct {
+		data     string
+		wantErrs Errors
+	}{
+		"Empty payload": {
+			data: ``,
+		},
+		"Empty flow": {
+			data: `{}`,
+		},
+		"Valid flow": {
+			data: `{"nodes":[
+				{"id":"start","type":"startNode","position":{"x":0,"y":0},"data":{}},
+				{"id":"move","type":"moveNode","position":{"x":0,"y":100},"data":{"distance":50,"muted":false,"label":"kept"}},
+				{"id":"loop","type":"loopNode","position":{"x":0,"y":200},"data":{"loopCount":4}}
+			],"edges":[{"id":"e1","source":"start","target":"move"}]}`,
+		},
+		"Valid flow encoded as a string": {
+			data: `"{\"nodes\":[{\"id\":\"1\",\"type\":\"penNode\",\"position\":{\"x\":0,\"y\":0},\"data\":{\"penDown\":true,\"color\":\"#000\"}}]}"`,
+		},
+		"Valid viewport": {
+			data: `{"nodes":[],"edges":[],"viewport":{"x":-10.5,"y":20,"zoom":1.5}}`,
+		},
+		"Invalid viewport": {
+			data: `{"nodes":[],"edges":[],"viewport":{"x":0,"y":0,"zoom":0}}`,
+			wantErrs: Errors{
+				{Path: "data.viewport.zoom", Pointer: "/data/viewport/zoom", Message: "must be greater than 0"},
+			},
+		},
+		"Incomplete viewport": {
+			data: `{"viewport":{"x":0}}`,
+			wantErrs: Errors{
+				{Path: "data.viewport", Pointer: "/data/viewport", Message: "must have numeric x, y and zoom"},
+			},
+		},
+		"Too many nodes": {
+			data: `{"nodes":[{"id":"a"},{"id":"b"},{"id":"c"},{"id":"d"},{"id":"e"}]}`,
+			wantErrs: Errors{
+				{Path: "data.nodes", Pointer: "/data/nodes", Message: "must have at most 4 nodes"},
+			},
+		},
+		"Payload too large": {
+			data: `{"nodes":[],"edges":[],"padding":"` + strings.Repeat("x", 1000) + `"}`,
+			wantErrs: Errors{
+				{Path: "data", Pointer: "/data", Message: "must be at most 1000 bytes"},
+			},
+		},
+		"Not a flow object": {
+			data: `[1, 2]`,
+			wantErrs: Errors{
+				{Path: "data", Pointer: "/data", Message: "must be a flow object with nodes and edges arrays"},
+			},
+		},
+		"Invalid node parameter": {
+			data: `{"nodes":[
+				{"id":"a","type":"startNode","position":{"x":0,"y":0}},
+				{"id":"b","type":"moveNode","position":{"x":0,"y":0}},
+				{"id":"c","type":"loopNode","position":{"x":0,"y":0}},
+				{"id":"d","type":"rotateNode","position":{"x":0,"y":0},"data":{"angle":"ninety"}}
+			]}`,
+			wantErrs: Errors{
+				{Path: "data.nodes[3].data.angle", Pointer: "/data/nodes/3/data/angle", Message: "must be a number", NodeID: "d"},
+			},
+		},
+		"Invalid nodes and edges": {
+			data: `{"nodes":[
+				{"id":"a","type":"spinNode","position":{"x":0,"y":0}},
+				{"id":"a","type":"loopNode","data":{"loopCount":2.5}}
+			],"edges":[{"id":"e1","source":"a","target":"missing"}]}`,
+			wantErrs: Errors{
+				{Path: "data.nodes[0].type", Pointer: "/data/nodes/0/type", Message: `unknown node type "spinNode"`, NodeID: "a"},
+				{Path: "data.nodes[1].position", Pointer: "/data/nodes/1/position", Message: "must have numeric x and y coordinates", NodeID: "a"},
+				{Path: "data.nodes[1].data.loopCount", Pointer: "/data/nodes/1/data/loopCount", Message: "must be a whole number of at least 0", NodeID: "a"},
+				{Path: "data.nodes[1].id", Pointer: "/data/nodes/1/id", Message: "duplicate node id", NodeID: "a"},
+				{Path: "data.edges[0].target", Pointer: "/data/edges/0/target", Message: "must reference an existing node", EdgeID: "e1"},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := Validate("data", json.RawMessage(tt.data), Limits{MaxNodes: 4, MaxBytes: 1000})
+
+			if tt.wantErrs == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			errs, ok := err.(Errors)
+			if assert.True(t, ok, "Expected flow.Errors") {
+				assert.Equal(t, tt.wantErrs, errs)
+			}
+		})
+	}
+}
+
+func TestLocationPointerEscaping(t *testing.T) {
+	at := location{}.key("data").key("a/b~c").index(2)
+
+	assert.Equal(t, "data.a/b~c[2]", at.path())
+	assert.Equal(t, "/data/a~1b~0c/2", at.pointer())
+}
+
+func TestNodeTypes(t *testing.T) {
+	types := NodeTypes()
+
+	assert.Len(t, types, len(nodeParams))
+	assert.True(t, sort.StringsAreSorted(types))
+	assert.Contains(t, types, "startNode")
+}
@@ -0,0 +1,235 @@
+// Package httpclient creates the HTTP clients used for outbound calls to third parties,
+// like webhook receivers, OAuth providers and LTI platforms.
+// Every client has bounded timeouts, its own connection pool limited per destination,
+// and retries transient failures with exponential backoff and jitter.
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress is returned by clients created with PublicOnly when a URL resolves to an address inside our own network.
+var ErrPrivateAddress = errors.New("address is not publicly routable")
+
+// Options configure a client. Zero values fall back to the defaults below.
+type Options struct {
+	// Timeout bounds a whole call, including retries and reading the response body.
+	Timeout time.Duration
+	// AttemptTimeout bounds connecting and waiting for the response headers of a single attempt.
+	AttemptTimeout time.Duration
+	// MaxAttempts is the number of times a request is sent at most, 1 disables retries.
+	MaxAttempts int
+	// BaseDelay and MaxDelay bound the backoff between attempts.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// RetryUnsafe also retries non-idempotent methods like POST, for receivers that handle duplicates.
+	RetryUnsafe bool
+	// PublicOnly refuses connections to loopback, private and link-local addresses and doesn't follow redirects,
+	// for URLs provided by users.
+	PublicOnly bool
+	// MaxConnsPerHost limits the connections to a single destination, so a slow one can't use up the pool.
+	MaxConnsPerHost int
+}
+
+const (
+	defaultTimeout         = 10 * time.Second
+	defaultAttemptTimeout  = 5 * time.Second
+	defaultMaxAttempts     = 3
+	defaultBaseDelay       = 200 * time.Millisecond
+	defaultMaxDelay        = 2 * time.Second
+	defaultMaxConnsPerHost = 16
+)
+
+// New creates an HTTP client with the given options.
+func New(opts Options) *http.Client {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+	if opts.AttemptTimeout <= 0 {
+		opts.AttemptTimeout = min(defaultAttemptTimeout, opts.Timeout)
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+	if opts.BaseDelay <= 0 {
+		opts.BaseDelay = defaultBaseDelay
+	}
+	if opts.MaxDelay <= 0 {
+		opts.MaxDelay = defaultMaxDelay
+	}
+	if opts.MaxConnsPerHost <= 0 {
+		opts.MaxConnsPerHost = defaultMaxConnsPerHost
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   opts.AttemptTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+	proxy := http.ProxyFromEnvironment
+	if opts.PublicOnly {
+		dialer.Control = publicOnly
+		// the proxy would connect on our behalf, bypassing the address check
+		proxy = nil
+	}
+
+	client := &http.Client{
+		Timeout: opts.Timeout,
+		Transport: &retryTransport{
+			next: &http.Transport{
+				Proxy:                 proxy,
+				DialContext:           dialer.DialContext,
+				ForceAttemptHTTP2:     true,
+				TLSHandshakeTimeout:   opts.AttemptTimeout,
+				ResponseHeaderTimeout: opts.AttemptTimeout,
+				ExpectContinueTimeout: time.Second,
+				MaxIdleConns:          100,
+				MaxIdleConnsPerHost:   min(opts.MaxConnsPerHost, 8),
+				MaxConnsPerHost:       opts.MaxConnsPerHost,
+				IdleConnTimeout:       90 * time.Second,
+			},
+			maxAttempts: opts.MaxAttempts,
+			baseDelay:   opts.BaseDelay,
+			maxDelay:    opts.MaxDelay,
+			retryUnsafe: opts.RetryUnsafe,
+		},
+	}
+	if opts.PublicOnly {
+		// a redirect could point back into the network, the response is expected to come directly
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	return client
+}
+
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return ErrPrivateAddress
+	}
+	return nil
+}
+
+// retryTransport resends requests failing with a network error or a status code signaling a temporary problem.
+type retryTransport struct {
+	next        http.RoundTripper
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	retryUnsafe bool
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.retryable(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+	for attempt := 1; ; attempt++ {
+		r := req
+		if attempt > 1 && req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(ctx)
+			r.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(r)
+		if attempt == t.maxAttempts || !shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
+
+		delay := t.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp); ok {
+				// waiting longer than we are willing to is no better than failing now
+				if after > t.maxDelay {
+					return resp, nil
+				}
+				delay = after
+			}
+			// the connection can only be reused once the body was read
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryable reports whether sending the request again is safe and possible.
+func (t *retryTransport) retryable(req *http.Request) bool {
+	if t.maxAttempts < 2 {
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return t.retryUnsafe
+	}
+}
+
+// backoff returns a random delay up to the exponentially growing limit for the attempt ("full jitter"),
+// so clients failing at the same time don't retry at the same time.
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	limit := t.maxDelay
+	if shift := attempt - 1; shift < 30 {
+		limit = min(t.maxDelay, t.baseDelay<<shift)
+	}
+	return rand.N(limit) + 1
+}
+
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, ErrPrivateAddress)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryAfter parses the Retry-After header, given in seconds or as a date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,160 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// flaky answers with the given status codes in order, then with 200.
+func flaky(statuses ...int) (*httptest.Server, *atomic.Int32, *[]string) {
+	var calls atomic.Int32
+	bodies := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	return server, &calls, &bodies
+}
+
+func fastOptions() Options {
+	return Options{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+}
+
+func TestClient_RetriesTransientStatus(t *testing.T) {
+	server, calls, _ := flaky(http.StatusServiceUnavailable, http.StatusBadGateway)
+	defer server.Close()
+
+	resp, err := New(fastOptions()).Get(server.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestClient_GivesUpAfterMaxAttempts(t *testing.T) {
+	server, calls, _ := flaky(http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	resp, err := New(fastOptions()).Get(server.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestClient_DoesNotRetryClientErrors(t *testing.T) {
+	server, calls, _ := flaky(http.StatusBadRequest)
+	defer server.Close()
+
+	resp, err := New(fastOptions()).Get(server.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestClient_RetriesPostOnlyWhenUnsafeAllowed(t *testing.T) {
+	server, calls, bodies := flaky(http.StatusServiceUnavailable)
+	defer server.Close()
+
+	resp, err := New(fastOptions()).Post(server.URL, "text/plain", strings.NewReader("payload"))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+
+	opts := fastOptions()
+	opts.RetryUnsafe = true
+	resp, err = New(opts).Post(server.URL, "text/plain", strings.NewReader("payload"))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, []string{"payload", "payload"}, *bodies)
+}
+
+func TestClient_RetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", r.URL.Query().Get("after"))
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := New(fastOptions())
+
+	resp, err := client.Get(server.URL + "?after=0")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(2), calls.Load())
+
+	// asked to wait longer than MaxDelay, the 429 is returned right away
+	calls.Store(0)
+	start := time.Now()
+	resp, err = client.Get(server.URL + "?after=60")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestClient_StopsWhenContextIsDone(t *testing.T) {
+	server, calls, _ := flaky(http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	opts := fastOptions()
+	opts.BaseDelay = time.Minute
+	opts.MaxDelay = time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+
+	_, err := New(opts).Do(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestClient_PublicOnly(t *testing.T) {
+	server, calls, _ := flaky()
+	defer server.Close()
+
+	opts := fastOptions()
+	opts.PublicOnly = true
+
+	_, err := New(opts).Get(server.URL)
+	assert.True(t, errors.Is(err, ErrPrivateAddress))
+	assert.Equal(t, int32(0), calls.Load())
+}
+
+func TestBackoff(t *testing.T) {
+	rt := &retryTransport{baseDelay: 100 * time.Millisecond, maxDelay: time.Second}
+
+	for i := 0; i < 100; i++ {
+		assert.LessOrEqual(t, rt.backoff(1), 100*time.Millisecond)
+		assert.LessOrEqual(t, rt.backoff(3), 400*time.Millisecond)
+		assert.LessOrEqual(t, rt.backoff(50), time.Second)
+		assert.Greater(t, rt.backoff(2), time.Duration(0))
+	}
+}
@@ -0,0 +1,27 @@
+// Package ids generates the IDs of new records.
+//
+// Projects get their ID from the service creating them rather than from the uuid_generate_v4() default of their
+// table. UUIDv7 IDs start with a timestamp, so new rows land at the end of the primary key index instead of on
+// random pages all over it, and IDs sort roughly by creation time. Existing v4 IDs stay valid, nothing reads
+// the version of an ID, and the table default still covers rows inserted elsewhere.
+package ids
+
+import "github.com/google/uuid"
+
+// Generator returns the ID of a new record.
+type Generator func() (uuid.UUID, error)
+
+var (
+	// V7 generates time-ordered IDs.
+	V7 Generator = uuid.NewV7
+	// V4 generates random IDs, as the table defaults do.
+	V4 Generator = uuid.NewRandom
+)
+
+// ForVersion returns the generator of the UUID version, V4 for 4 and V7 otherwise.
+func ForVersion(version int) Generator {
+	if version == 4 {
+		return V4
+	}
+	return V7
+}
@@ -0,0 +1,28 @@
+package ids
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForVersion(t *testing.T) {
+	for version, want := range map[int]uuid.Version{4: 4, 7: 7} {
+		id, err := ForVersion(version)()
+		assert.NoError(t, err)
+		assert.Equal(t, want, id.Version())
+	}
+}
+
+func TestV7IsTimeOrdered(t *testing.T) {
+	prev, err := V7()
+	assert.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		id, err := V7()
+		assert.NoError(t, err)
+		assert.Greater(t, id.String(), prev.String())
+		prev = id
+	}
+}
@@ -0,0 +1,85 @@
+// Package logging writes structured JSON logs for log aggregation. Records logged with the context of a request
+// carry its request ID, the attributes gathered while serving it, such as the route and the user, and how long it
+// has been running, so every line a request produces can be correlated with the others.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+
+	"NodeTurtleAPI/internal/requestid"
+)
+
+// New returns a logger writing JSON lines to w, including debug records when debug is set.
+func New(w io.Writer, debug bool) *slog.Logger {
+	level := slog.LevelInfo
+	if debug {
+		level = slog.LevelDebug
+	}
+	return slog.New(Handler{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})})
+}
+
+// Handler adds the request ID and the latency of the request a record is logged for to the record.
+type Handler struct {
+	slog.Handler
+}
+
+// Handle adds the attributes of the request ctx belongs to, if any, and writes the record.
+func (h Handler) Handle(ctx context.Context, r slog.Record) error {
+	if id := requestid.FromContext(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if req, ok := ctx.Value(requestKey{}).(*request); ok {
+		r.AddAttrs(slog.Float64("latency_ms", float64(time.Since(req.start).Microseconds())/1000))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs returns a Handler whose records carry the attributes as well.
+func (h Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return Handler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup returns a Handler qualifying the attributes that follow with the group name.
+func (h Handler) WithGroup(name string) slog.Handler {
+	return Handler{h.Handler.WithGroup(name)}
+}
+
+type requestKey struct{}
+
+// request is the logger of a request being served and when it started.
+type request struct {
+	logger *slog.Logger
+	start  time.Time
+}
+
+// NewContext returns a copy of ctx carrying the logger of a request that started at start.
+func NewContext(ctx context.Context, logger *slog.Logger, start time.Time) context.Context {
+	return context.WithValue(ctx, requestKey{}, &request{logger: logger, start: start})
+}
+
+// With returns a copy of ctx whose logger adds the attributes to every record, e.g. the user once the session is known.
+// Outside of a request, ctx is returned as is.
+func With(ctx context.Context, args ...any) context.Context {
+	req, ok := ctx.Value(requestKey{}).(*request)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, requestKey{}, &request{logger: req.logger.With(args...), start: req.start})
+}
+
+// FromContext returns the logger of the request ctx belongs to, or the default logger outside of requests,
+// e.g. in scheduled jobs.
+func FromContext(ctx context.Context) *slog.Logger {
+	if req, ok := ctx.Value(requestKey{}).(*request); ok {
+		return req.logger
+	}
+	return slog.Default()
+}
+
+// Error logs an unexpected error of the work ctx belongs to.
+func Error(ctx context.Context, msg string, err error) {
+	FromContext(ctx).ErrorContext(ctx, msg, "error", err)
+}
@@ -0,0 +1,53 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/requestid"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestError_CarriesRequestAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, false)
+
+	ctx := requestid.NewContext(context.Background(), "req-1")
+	ctx = NewContext(ctx, logger.With("route", "/api/projects/:id"), time.Now().Add(-time.Second))
+	ctx = With(ctx, "user_id", "user-1")
+
+	Error(ctx, "Internal project retrieval error", errors.New("connection refused"))
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "ERROR", line["level"])
+	assert.Equal(t, "Internal project retrieval error", line["msg"])
+	assert.Equal(t, "connection refused", line["error"])
+	assert.Equal(t, "req-1", line["request_id"])
+	assert.Equal(t, "/api/projects/:id", line["route"])
+	assert.Equal(t, "user-1", line["user_id"])
+	assert.GreaterOrEqual(t, line["latency_ms"], float64(1000))
+}
+
+func TestFromContext_OutsideOfRequests(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, false)
+	ctx := context.Background()
+
+	assert.Equal(t, ctx, With(ctx, "user_id", "user-1"))
+
+	// scheduled jobs log without request attributes
+	logger.InfoContext(ctx, "Scheduled job done")
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.NotContains(t, line, "request_id")
+	assert.NotContains(t, line, "latency_ms")
+	assert.NotContains(t, line, "user_id")
+}
@@ -0,0 +1,44 @@
+package mocks
+
+import (
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAnnotationService struct {
+	mock.Mock
+}
+
+func (m *MockAnnotationService) GetUserAnnotation(userID uuid.UUID) (*data.Annotation, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.Annotation), args.Error(1)
+}
+
+func (m *MockAnnotationService) SetUserAnnotation(userID uuid.UUID, update data.AnnotationUpdate, updatedBy uuid.UUID) (*data.Annotation, error) {
+	args := m.Called(userID, update, updatedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.Annotation), args.Error(1)
+}
+
+func (m *MockAnnotationService) GetProjectAnnotation(projectID uuid.UUID) (*data.Annotation, error) {
+	args := m.Called(projectID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.Annotation), args.Error(1)
+}
+
+func (m *MockAnnotationService) SetProjectAnnotation(projectID uuid.UUID, update data.AnnotationUpdate, updatedBy uuid.UUID) (*data.Annotation, error) {
+	args := m.Called(projectID, update, updatedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.Annotation), args.Error(1)
+}
@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAnnouncementService struct {
+	mock.Mock
+}
+
+func (m *MockAnnouncementService) GetActive() ([]data.Announcement, error) {
+	args := m.Called()
+	return args.Get(0).([]data.Announcement), args.Error(1)
+}
+
+func (m *MockAnnouncementService) Create(announcement data.AnnouncementCreate, createdBy uuid.UUID) (*data.Announcement, error) {
+	args := m.Called(announcement, createdBy)
+
+	var a *data.Announcement
+	if args.Get(0) != nil {
+		a = args.Get(0).(*data.Announcement)
+	}
+
+	return a, args.Error(1)
+}
+
+func (m *MockAnnouncementService) Delete(id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
@@ -0,0 +1,38 @@
+package mocks
+
+import (
+	"context"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAnomalyService struct {
+	mock.Mock
+}
+
+func (m *MockAnomalyService) Analyze(ctx context.Context) ([]data.Alert, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.Alert), args.Error(1)
+}
+
+func (m *MockAnomalyService) ListAlerts(ctx context.Context, filter data.AlertFilter) ([]data.Alert, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.Alert), args.Error(1)
+}
+
+func (m *MockAnomalyService) AcknowledgeAlert(ctx context.Context, id int64, userID uuid.UUID) (*data.Alert, error) {
+	args := m.Called(id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.Alert), args.Error(1)
+}
@@ -0,0 +1,42 @@
+package mocks
+
+import (
+	"NodeTurtleAPI/internal/data"
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuditService struct {
+	mock.Mock
+}
+
+func (m *MockAuditService) Record(entry data.AuditEntry) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func (m *MockAuditService) List(filter data.AuditFilter) ([]data.AuditEntry, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.AuditEntry), args.Error(1)
+}
+
+func (m *MockAuditService) UserHistory(ctx context.Context, userID uuid.UUID, filter data.HistoryFilter) ([]data.HistoryEntry, error) {
+	args := m.Called(userID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.HistoryEntry), args.Error(1)
+}
+
+func (m *MockAuditService) ProjectHistory(ctx context.Context, projectID uuid.UUID, filter data.HistoryFilter) ([]data.HistoryEntry, error) {
+	args := m.Called(projectID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.HistoryEntry), args.Error(1)
+}
@@ -1,9 +1,13 @@
 package mocks
 
 import (
+	"context"
+	"time"
+
 	"NodeTurtleAPI/internal/data"
 	"NodeTurtleAPI/internal/services/auth"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -11,7 +15,7 @@ type MockAuthService struct {
 	mock.Mock
 }
 
-func (m *MockAuthService) Login(email, password string) (string, *data.User, error) {
+func (m *MockAuthService) Login(ctx context.Context, email, password string) (string, *data.User, error) {
 	args := m.Called(email, password)
 
 	var user *data.User
@@ -22,11 +26,31 @@ func (m *MockAuthService) Login(email, password string) (string, *data.User, err
 	return args.String(0), user, args.Error(2)
 }
 
+func (m *MockAuthService) UpdateLastLogin(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
 func (m *MockAuthService) CreateAccessToken(user data.User) (string, error) {
 	args := m.Called(user)
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockAuthService) CreateImpersonationToken(user data.User, impersonatorID uuid.UUID) (string, time.Time, error) {
+	args := m.Called(user, impersonatorID)
+	return args.String(0), args.Get(1).(time.Time), args.Error(2)
+}
+
+func (m *MockAuthService) CreateEmbedToken(projectID uuid.UUID) (string, time.Time, error) {
+	args := m.Called(projectID)
+	return args.String(0), args.Get(1).(time.Time), args.Error(2)
+}
+
+func (m *MockAuthService) VerifyEmbedToken(tokenString string, projectID uuid.UUID) error {
+	args := m.Called(tokenString, projectID)
+	return args.Error(0)
+}
+
 func (m *MockAuthService) VerifyToken(tokenString string) (*auth.Claims, error) {
 	args := m.Called(tokenString)
 
@@ -37,3 +61,32 @@ func (m *MockAuthService) VerifyToken(tokenString string) (*auth.Claims, error)
 
 	return claims, args.Error(1)
 }
+
+func (m *MockAuthService) RotateSigningKey() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAuthService) JWKS() data.JWKS {
+	args := m.Called()
+	return args.Get(0).(data.JWKS)
+}
+
+type MockOAuthService struct {
+	mock.Mock
+}
+
+func (m *MockOAuthService) Providers() []string {
+	args := m.Called()
+	return args.Get(0).([]string)
+}
+
+func (m *MockOAuthService) AuthCodeURL(ctx context.Context, provider string) (string, error) {
+	args := m.Called(provider)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockOAuthService) Authenticate(ctx context.Context, provider, code, state string) (uuid.UUID, error) {
+	args := m.Called(provider, code, state)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
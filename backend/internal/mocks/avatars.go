@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"context"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAvatarService struct {
+	mock.Mock
+}
+
+func (m *MockAvatarService) SetAvatar(ctx context.Context, userID uuid.UUID, upload []byte) (*data.Avatar, error) {
+	args := m.Called(userID, upload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.Avatar), args.Error(1)
+}
+
+func (m *MockAvatarService) DeleteAvatar(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockAvatarService) GetImage(ctx context.Context, userID uuid.UUID, name string) ([]byte, string, error) {
+	args := m.Called(userID, name)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).([]byte), args.String(1), args.Error(2)
+}
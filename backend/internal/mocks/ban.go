@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"NodeTurtleAPI/internal/data"
+	"context"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,7 +13,7 @@ type MockBanService struct {
 	mock.Mock
 }
 
-func (m *MockBanService) BanUser(userId uuid.UUID, bannedBy uuid.UUID, expires_at time.Time, reason string) (*data.Ban, error) {
+func (m *MockBanService) BanUser(ctx context.Context, userId uuid.UUID, bannedBy uuid.UUID, expires_at *time.Time, reason string) (*data.Ban, error) {
 	args := m.Called(userId, bannedBy, expires_at, reason)
 
 	var user *data.Ban
@@ -23,8 +24,124 @@ func (m *MockBanService) BanUser(userId uuid.UUID, bannedBy uuid.UUID, expires_a
 	return user, args.Error(1)
 }
 
-func (m *MockBanService) UnbanUser(userId uuid.UUID) error {
-	args := m.Called(userId)
+func (m *MockBanService) UnbanUser(ctx context.Context, userId uuid.UUID, unbannedBy uuid.UUID) error {
+	args := m.Called(userId, unbannedBy)
+
+	return args.Error(0)
+}
+
+func (m *MockBanService) ListBanHistory(ctx context.Context, userID uuid.UUID) ([]data.BanEvent, error) {
+	args := m.Called(userID)
+
+	var events []data.BanEvent
+	if args.Get(0) != nil {
+		events = args.Get(0).([]data.BanEvent)
+	}
+
+	return events, args.Error(1)
+}
+
+func (m *MockBanService) AppealBan(ctx context.Context, userID uuid.UUID, message string) (*data.BanAppeal, error) {
+	args := m.Called(userID, message)
+
+	var appeal *data.BanAppeal
+	if args.Get(0) != nil {
+		appeal = args.Get(0).(*data.BanAppeal)
+	}
+
+	return appeal, args.Error(1)
+}
+
+func (m *MockBanService) GetAppeal(ctx context.Context, appealID uuid.UUID) (*data.BanAppeal, error) {
+	args := m.Called(appealID)
+
+	var appeal *data.BanAppeal
+	if args.Get(0) != nil {
+		appeal = args.Get(0).(*data.BanAppeal)
+	}
+
+	return appeal, args.Error(1)
+}
+
+func (m *MockBanService) ListOpenAppeals(ctx context.Context) ([]data.BanAppeal, error) {
+	args := m.Called()
+
+	var appeals []data.BanAppeal
+	if args.Get(0) != nil {
+		appeals = args.Get(0).([]data.BanAppeal)
+	}
+
+	return appeals, args.Error(1)
+}
+
+func (m *MockBanService) ResolveAppeal(ctx context.Context, appealID uuid.UUID, moderatorID uuid.UUID, decision data.BanAppealDecision) (*data.BanAppeal, error) {
+	args := m.Called(appealID, moderatorID, decision)
+
+	var appeal *data.BanAppeal
+	if args.Get(0) != nil {
+		appeal = args.Get(0).(*data.BanAppeal)
+	}
+
+	return appeal, args.Error(1)
+}
+
+func (m *MockBanService) ListIPBans(ctx context.Context) ([]data.IPBan, error) {
+	args := m.Called()
+
+	var bans []data.IPBan
+	if args.Get(0) != nil {
+		bans = args.Get(0).([]data.IPBan)
+	}
+
+	return bans, args.Error(1)
+}
+
+func (m *MockBanService) ActiveIPBans(ctx context.Context) ([]data.IPBan, error) {
+	args := m.Called()
+
+	var bans []data.IPBan
+	if args.Get(0) != nil {
+		bans = args.Get(0).([]data.IPBan)
+	}
+
+	return bans, args.Error(1)
+}
+
+func (m *MockBanService) GetIPBan(ctx context.Context, id int64) (*data.IPBan, error) {
+	args := m.Called(id)
+
+	var ban *data.IPBan
+	if args.Get(0) != nil {
+		ban = args.Get(0).(*data.IPBan)
+	}
+
+	return ban, args.Error(1)
+}
+
+func (m *MockBanService) AddIPBan(ctx context.Context, subnet, reason string, expiresAt *time.Time, createdBy uuid.UUID) (*data.IPBan, error) {
+	args := m.Called(subnet, reason, expiresAt, createdBy)
+
+	var ban *data.IPBan
+	if args.Get(0) != nil {
+		ban = args.Get(0).(*data.IPBan)
+	}
+
+	return ban, args.Error(1)
+}
+
+func (m *MockBanService) UpdateIPBan(ctx context.Context, id int64, reason string, expiresAt *time.Time) (*data.IPBan, error) {
+	args := m.Called(id, reason, expiresAt)
+
+	var ban *data.IPBan
+	if args.Get(0) != nil {
+		ban = args.Get(0).(*data.IPBan)
+	}
+
+	return ban, args.Error(1)
+}
+
+func (m *MockBanService) RemoveIPBan(ctx context.Context, id int64) error {
+	args := m.Called(id)
 
 	return args.Error(0)
 }
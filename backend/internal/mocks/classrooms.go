@@ -0,0 +1,91 @@
+package mocks
+
+import (
+	"NodeTurtleAPI/internal/data"
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockClassroomService struct {
+	mock.Mock
+}
+
+func (m *MockClassroomService) CreateClassroom(ctx context.Context, c data.ClassroomCreate) (*data.Classroom, error) {
+	args := m.Called(c)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.Classroom), args.Error(1)
+}
+
+func (m *MockClassroomService) GetClassroom(ctx context.Context, classroomID uuid.UUID) (*data.Classroom, error) {
+	args := m.Called(classroomID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.Classroom), args.Error(1)
+}
+
+func (m *MockClassroomService) GetUserClassrooms(ctx context.Context, userID uuid.UUID) ([]data.Classroom, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.Classroom), args.Error(1)
+}
+
+func (m *MockClassroomService) DeleteClassroom(ctx context.Context, classroomID uuid.UUID) error {
+	args := m.Called(classroomID)
+	return args.Error(0)
+}
+
+func (m *MockClassroomService) GetMembers(ctx context.Context, classroomID uuid.UUID) ([]data.ClassroomMember, error) {
+	args := m.Called(classroomID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.ClassroomMember), args.Error(1)
+}
+
+func (m *MockClassroomService) InviteMembers(ctx context.Context, classroomID uuid.UUID, emails []string) error {
+	args := m.Called(classroomID, emails)
+	return args.Error(0)
+}
+
+func (m *MockClassroomService) GetInvites(ctx context.Context, userID uuid.UUID) ([]data.ClassroomInvite, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.ClassroomInvite), args.Error(1)
+}
+
+func (m *MockClassroomService) AcceptInvite(ctx context.Context, classroomID, userID uuid.UUID) error {
+	args := m.Called(classroomID, userID)
+	return args.Error(0)
+}
+
+func (m *MockClassroomService) DeclineInvite(ctx context.Context, classroomID, userID uuid.UUID) error {
+	args := m.Called(classroomID, userID)
+	return args.Error(0)
+}
+
+func (m *MockClassroomService) RemoveMember(ctx context.Context, classroomID, userID uuid.UUID) error {
+	args := m.Called(classroomID, userID)
+	return args.Error(0)
+}
+
+func (m *MockClassroomService) IsMember(ctx context.Context, classroomID, userID uuid.UUID) (bool, error) {
+	args := m.Called(classroomID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockClassroomService) GetClassroomProjects(ctx context.Context, classroomID uuid.UUID) ([]data.Project, error) {
+	args := m.Called(classroomID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.Project), args.Error(1)
+}
@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"context"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockConsentService struct {
+	mock.Mock
+}
+
+func (m *MockConsentService) GetConsents(ctx context.Context, userID uuid.UUID) ([]data.Consent, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.Consent), args.Error(1)
+}
+
+func (m *MockConsentService) SetConsents(ctx context.Context, userID uuid.UUID, changes map[data.ConsentPurpose]bool) ([]data.Consent, error) {
+	args := m.Called(userID, changes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.Consent), args.Error(1)
+}
+
+func (m *MockConsentService) HasConsent(ctx context.Context, userID uuid.UUID, purpose data.ConsentPurpose) (bool, error) {
+	args := m.Called(userID, purpose)
+	return args.Bool(0), args.Error(1)
+}
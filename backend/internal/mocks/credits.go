@@ -0,0 +1,50 @@
+package mocks
+
+import (
+	"NodeTurtleAPI/internal/data"
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCreditService struct {
+	mock.Mock
+}
+
+func (m *MockCreditService) AddCredit(ctx context.Context, projectID, userID uuid.UUID) (*data.Credit, error) {
+	args := m.Called(projectID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.Credit), args.Error(1)
+}
+
+func (m *MockCreditService) RemoveCredit(ctx context.Context, projectID, userID uuid.UUID) error {
+	args := m.Called(projectID, userID)
+	return args.Error(0)
+}
+
+func (m *MockCreditService) ListCredits(ctx context.Context, projectID uuid.UUID, includeUnaccepted bool) ([]data.Credit, error) {
+	args := m.Called(projectID, includeUnaccepted)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.Credit), args.Error(1)
+}
+
+func (m *MockCreditService) ListPendingCredits(ctx context.Context, userID uuid.UUID) ([]data.Credit, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.Credit), args.Error(1)
+}
+
+func (m *MockCreditService) RespondToCredit(ctx context.Context, projectID, userID uuid.UUID, accept bool) (*data.Credit, error) {
+	args := m.Called(projectID, userID, accept)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.Credit), args.Error(1)
+}
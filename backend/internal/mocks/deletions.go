@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"context"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDeletionService struct {
+	mock.Mock
+}
+
+func (m *MockDeletionService) Schedule(ctx context.Context, userID uuid.UUID) (*data.AccountDeletion, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.AccountDeletion), args.Error(1)
+}
+
+func (m *MockDeletionService) ListPending(ctx context.Context) ([]data.AccountDeletion, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.AccountDeletion), args.Error(1)
+}
+
+func (m *MockDeletionService) DeleteDue(ctx context.Context) (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
@@ -0,0 +1,40 @@
+package mocks
+
+import (
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDumpService struct {
+	mock.Mock
+}
+
+func (m *MockDumpService) Generate() (*data.DataDump, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.DataDump), args.Error(1)
+}
+
+func (m *MockDumpService) List() ([]data.DataDump, error) {
+	args := m.Called()
+	return args.Get(0).([]data.DataDump), args.Error(1)
+}
+
+func (m *MockDumpService) Path(filename string) (string, error) {
+	args := m.Called(filename)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockDumpService) IsOptedOut(userID uuid.UUID) (bool, error) {
+	args := m.Called(userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDumpService) SetOptOut(userID uuid.UUID, optOut bool) error {
+	args := m.Called(userID, optOut)
+	return args.Error(0)
+}
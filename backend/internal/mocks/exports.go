@@ -0,0 +1,48 @@
+package mocks
+
+import (
+	"context"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockExportService struct {
+	mock.Mock
+}
+
+func (m *MockExportService) RequestExport(ctx context.Context, userID uuid.UUID) (*data.DataExport, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.DataExport), args.Error(1)
+}
+
+func (m *MockExportService) ListExports(ctx context.Context, userID uuid.UUID) ([]data.DataExport, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.DataExport), args.Error(1)
+}
+
+func (m *MockExportService) Download(ctx context.Context, token string) ([]byte, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockExportService) PreparePending(ctx context.Context) (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockExportService) DeleteExpired(ctx context.Context) (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
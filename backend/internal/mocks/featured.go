@@ -0,0 +1,69 @@
+package mocks
+
+import (
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockFeaturedService struct {
+	mock.Mock
+}
+
+func (m *MockFeaturedService) Rotate() (*data.FeaturedRotation, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.FeaturedRotation), args.Error(1)
+}
+
+func (m *MockFeaturedService) GetQueue() ([]data.FeaturedQueueEntry, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.FeaturedQueueEntry), args.Error(1)
+}
+
+func (m *MockFeaturedService) Enqueue(projectID, adminID uuid.UUID) (*data.FeaturedQueueEntry, error) {
+	args := m.Called(projectID, adminID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.FeaturedQueueEntry), args.Error(1)
+}
+
+func (m *MockFeaturedService) Dequeue(projectID uuid.UUID) error {
+	args := m.Called(projectID)
+	return args.Error(0)
+}
+
+func (m *MockFeaturedService) SetPinned(projectID uuid.UUID, pinned bool) error {
+	args := m.Called(projectID, pinned)
+	return args.Error(0)
+}
+
+func (m *MockFeaturedService) Feature(projectID, adminID uuid.UUID, until time.Time) (*data.FeaturedProject, error) {
+	args := m.Called(projectID, adminID, until)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.FeaturedProject), args.Error(1)
+}
+
+func (m *MockFeaturedService) Unfeature(projectID uuid.UUID) error {
+	args := m.Called(projectID)
+	return args.Error(0)
+}
+
+func (m *MockFeaturedService) ListFeatured(expiringWithin time.Duration) ([]data.FeaturedProject, error) {
+	args := m.Called(expiringWithin)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.FeaturedProject), args.Error(1)
+}
@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockFlagService struct {
+	mock.Mock
+}
+
+func (m *MockFlagService) GetFlags() ([]data.FeatureFlag, error) {
+	args := m.Called()
+	return args.Get(0).([]data.FeatureFlag), args.Error(1)
+}
+
+func (m *MockFlagService) IsEnabled(name string) bool {
+	args := m.Called(name)
+	return args.Bool(0)
+}
+
+func (m *MockFlagService) SetFlag(name string, enabled bool, description *string) (*data.FeatureFlag, error) {
+	args := m.Called(name, enabled, description)
+
+	var flag *data.FeatureFlag
+	if args.Get(0) != nil {
+		flag = args.Get(0).(*data.FeatureFlag)
+	}
+
+	return flag, args.Error(1)
+}
@@ -0,0 +1,58 @@
+package mocks
+
+import (
+	"context"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockFolderService struct {
+	mock.Mock
+}
+
+func (m *MockFolderService) ListFolders(ctx context.Context, userID uuid.UUID) ([]data.Folder, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.Folder), args.Error(1)
+}
+
+func (m *MockFolderService) CreateFolder(ctx context.Context, userID uuid.UUID, create data.FolderCreate) (*data.Folder, error) {
+	args := m.Called(userID, create)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.Folder), args.Error(1)
+}
+
+func (m *MockFolderService) RenameFolder(ctx context.Context, userID, folderID uuid.UUID, name string) (*data.Folder, error) {
+	args := m.Called(userID, folderID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.Folder), args.Error(1)
+}
+
+func (m *MockFolderService) DeleteFolder(ctx context.Context, userID, folderID uuid.UUID) error {
+	args := m.Called(userID, folderID)
+	return args.Error(0)
+}
+
+func (m *MockFolderService) ReorderFolders(ctx context.Context, userID uuid.UUID, parentID *uuid.UUID, folderIDs []uuid.UUID) error {
+	args := m.Called(userID, parentID, folderIDs)
+	return args.Error(0)
+}
+
+func (m *MockFolderService) MoveProject(ctx context.Context, userID, projectID uuid.UUID, folderID *uuid.UUID) error {
+	args := m.Called(userID, projectID, folderID)
+	return args.Error(0)
+}
+
+func (m *MockFolderService) ReorderProjects(ctx context.Context, userID, folderID uuid.UUID, projectIDs []uuid.UUID) error {
+	args := m.Called(userID, folderID, projectIDs)
+	return args.Error(0)
+}
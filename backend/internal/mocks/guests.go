@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"context"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockGuestService struct {
+	mock.Mock
+}
+
+func (m *MockGuestService) CreateGuest(ctx context.Context) (*data.User, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.User), args.Error(1)
+}
+
+func (m *MockGuestService) ClaimGuest(ctx context.Context, guestID uuid.UUID, reg data.UserRegistration) (*data.User, error) {
+	args := m.Called(guestID, reg)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.User), args.Error(1)
+}
+
+func (m *MockGuestService) DeleteExpiredGuests(ctx context.Context) (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
@@ -0,0 +1,55 @@
+package mocks
+
+import (
+	"NodeTurtleAPI/internal/data"
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockImportService struct {
+	mock.Mock
+}
+
+func (m *MockImportService) CreateUpload(ctx context.Context, userID uuid.UUID, size int64) (*data.ProjectUpload, error) {
+	args := m.Called(userID, size)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.ProjectUpload), args.Error(1)
+}
+
+func (m *MockImportService) GetUpload(ctx context.Context, uploadID, userID uuid.UUID) (*data.ProjectUpload, error) {
+	args := m.Called(uploadID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.ProjectUpload), args.Error(1)
+}
+
+func (m *MockImportService) AppendChunk(ctx context.Context, uploadID, userID uuid.UUID, offset int64, chunk []byte) (*data.ProjectUpload, error) {
+	args := m.Called(uploadID, userID, offset, chunk)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.ProjectUpload), args.Error(1)
+}
+
+func (m *MockImportService) ReadUpload(ctx context.Context, uploadID, userID uuid.UUID) ([]byte, error) {
+	args := m.Called(uploadID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockImportService) DeleteUpload(ctx context.Context, uploadID, userID uuid.UUID) error {
+	args := m.Called(uploadID, userID)
+	return args.Error(0)
+}
+
+func (m *MockImportService) DeleteExpiredUploads(ctx context.Context) (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
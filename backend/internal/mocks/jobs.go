@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockJobService struct {
+	mock.Mock
+}
+
+func (m *MockJobService) GetJobs() ([]data.Job, error) {
+	args := m.Called()
+	return args.Get(0).([]data.Job), args.Error(1)
+}
+
+func (m *MockJobService) GetJob(name string) (*data.Job, error) {
+	args := m.Called(name)
+
+	var job *data.Job
+	if args.Get(0) != nil {
+		job = args.Get(0).(*data.Job)
+	}
+
+	return job, args.Error(1)
+}
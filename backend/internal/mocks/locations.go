@@ -0,0 +1,48 @@
+package mocks
+
+import (
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLocationService struct {
+	mock.Mock
+}
+
+func (m *MockLocationService) IsTrusted(userID uuid.UUID, country string) (bool, error) {
+	args := m.Called(userID, country)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLocationService) Trust(userID uuid.UUID, country string) error {
+	args := m.Called(userID, country)
+	return args.Error(0)
+}
+
+func (m *MockLocationService) ListTrusted(userID uuid.UUID) ([]data.TrustedLocation, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]data.TrustedLocation), args.Error(1)
+}
+
+func (m *MockLocationService) RemoveTrusted(userID uuid.UUID, country string) error {
+	args := m.Called(userID, country)
+	return args.Error(0)
+}
+
+func (m *MockLocationService) CreateChallenge(userID uuid.UUID, country string) (*data.LoginChallenge, error) {
+	args := m.Called(userID, country)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.LoginChallenge), args.Error(1)
+}
+
+func (m *MockLocationService) VerifyChallenge(challengeID uuid.UUID, code string) (*data.LoginChallenge, error) {
+	args := m.Called(challengeID, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.LoginChallenge), args.Error(1)
+}
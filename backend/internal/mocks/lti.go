@@ -0,0 +1,71 @@
+package mocks
+
+import (
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLTIService struct {
+	mock.Mock
+}
+
+func (m *MockLTIService) RegisterPlatform(p data.LTIPlatformCreate) (*data.LTIPlatform, error) {
+	args := m.Called(p)
+	var platform *data.LTIPlatform
+	if args.Get(0) != nil {
+		platform = args.Get(0).(*data.LTIPlatform)
+	}
+	return platform, args.Error(1)
+}
+
+func (m *MockLTIService) ListPlatforms() ([]data.LTIPlatform, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.LTIPlatform), args.Error(1)
+}
+
+func (m *MockLTIService) InitiateLogin(req data.LTILoginRequest) (string, error) {
+	args := m.Called(req)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockLTIService) HandleLaunch(idToken, state string) (*data.LTILaunchResult, error) {
+	args := m.Called(idToken, state)
+	var result *data.LTILaunchResult
+	if args.Get(0) != nil {
+		result = args.Get(0).(*data.LTILaunchResult)
+	}
+	return result, args.Error(1)
+}
+
+func (m *MockLTIService) CreateDeepLinkResponse(deepLinkID, userID uuid.UUID, projectIDs []uuid.UUID) (*data.LTIDeepLinkResponse, error) {
+	args := m.Called(deepLinkID, userID, projectIDs)
+	var response *data.LTIDeepLinkResponse
+	if args.Get(0) != nil {
+		response = args.Get(0).(*data.LTIDeepLinkResponse)
+	}
+	return response, args.Error(1)
+}
+
+func (m *MockLTIService) SubmitAssignment(launchID, userID uuid.UUID) (*data.LTILaunch, error) {
+	args := m.Called(launchID, userID)
+	var launch *data.LTILaunch
+	if args.Get(0) != nil {
+		launch = args.Get(0).(*data.LTILaunch)
+	}
+	return launch, args.Error(1)
+}
+
+func (m *MockLTIService) ConfirmLink(requestID, userID uuid.UUID) error {
+	args := m.Called(requestID, userID)
+	return args.Error(0)
+}
+
+func (m *MockLTIService) JWKS() data.JWKS {
+	args := m.Called()
+	return args.Get(0).(data.JWKS)
+}
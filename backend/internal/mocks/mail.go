@@ -1,12 +1,66 @@
 package mocks
 
-import "github.com/stretchr/testify/mock"
+import (
+	"context"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
 
 type MockMailService struct {
 	mock.Mock
 }
 
-func (m *MockMailService) SendEmail(to, subject, templateName string, data map[string]string) error {
+func (m *MockMailService) SendEmail(ctx context.Context, to, subject, templateName string, data map[string]string) error {
 	args := m.Called(to, subject, templateName, data)
 	return args.Error(0)
 }
+
+func (m *MockMailService) QueueEmail(ctx context.Context, to, subject, templateName string, data map[string]string) error {
+	args := m.Called(to, subject, templateName, data)
+	return args.Error(0)
+}
+
+func (m *MockMailService) ListEmails(ctx context.Context, filter data.EmailFilter) ([]data.Email, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.Email), args.Error(1)
+}
+
+func (m *MockMailService) ListTemplates() []data.EmailTemplate {
+	args := m.Called()
+	return args.Get(0).([]data.EmailTemplate)
+}
+
+func (m *MockMailService) PreviewTemplate(name string, overrides map[string]string) (*data.EmailPreview, error) {
+	args := m.Called(name, overrides)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.EmailPreview), args.Error(1)
+}
+
+func (m *MockMailService) GetPreferences(ctx context.Context, userID uuid.UUID) (*data.EmailPreferences, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.EmailPreferences), args.Error(1)
+}
+
+func (m *MockMailService) UpdatePreferences(ctx context.Context, userID uuid.UUID, update data.EmailPreferencesUpdate) (*data.EmailPreferences, error) {
+	args := m.Called(userID, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.EmailPreferences), args.Error(1)
+}
+
+func (m *MockMailService) Unsubscribe(ctx context.Context, token string) (data.EmailCategory, error) {
+	args := m.Called(token)
+	return args.Get(0).(data.EmailCategory), args.Error(1)
+}
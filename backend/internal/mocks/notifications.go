@@ -0,0 +1,56 @@
+package mocks
+
+import (
+	"context"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockNotificationService struct {
+	mock.Mock
+}
+
+func (m *MockNotificationService) NotifyLike(ctx context.Context, projectID, likerID uuid.UUID) (*data.Notification, error) {
+	args := m.Called(projectID, likerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.Notification), args.Error(1)
+}
+
+func (m *MockNotificationService) GetNotifications(ctx context.Context, userID uuid.UUID, limit int) ([]data.Notification, int, error) {
+	args := m.Called(userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]data.Notification), args.Int(1), args.Error(2)
+}
+
+func (m *MockNotificationService) MarkRead(ctx context.Context, userID uuid.UUID, ids []int64) (int64, error) {
+	args := m.Called(userID, ids)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockNotificationService) GetPreferences(ctx context.Context, userID uuid.UUID) (*data.NotificationPreferences, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.NotificationPreferences), args.Error(1)
+}
+
+func (m *MockNotificationService) UpdatePreferences(ctx context.Context, userID uuid.UUID, update data.NotificationPreferencesUpdate) (*data.NotificationPreferences, error) {
+	args := m.Called(userID, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.NotificationPreferences), args.Error(1)
+}
+
+func (m *MockNotificationService) SendDigests(ctx context.Context) (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
@@ -0,0 +1,16 @@
+package mocks
+
+import (
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPasswordService struct {
+	mock.Mock
+}
+
+func (m *MockPasswordService) Evaluate(password string, userInputs []string) data.PasswordStrength {
+	args := m.Called(password, userInputs)
+	return args.Get(0).(data.PasswordStrength)
+}
@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"context"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockProfileService struct {
+	mock.Mock
+}
+
+func (m *MockProfileService) GetProfile(ctx context.Context, username string) (*data.UserProfile, error) {
+	args := m.Called(username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.UserProfile), args.Error(1)
+}
+
+func (m *MockProfileService) UpdateProfile(ctx context.Context, userID uuid.UUID, update data.UserProfileUpdate) (*data.UserProfile, error) {
+	args := m.Called(userID, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.UserProfile), args.Error(1)
+}
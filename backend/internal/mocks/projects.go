@@ -2,6 +2,8 @@ package mocks
 
 import (
 	"NodeTurtleAPI/internal/data"
+	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,7 +14,7 @@ type MockProjectService struct {
 	mock.Mock
 }
 
-func (m *MockProjectService) CreateProject(p data.ProjectCreate) (*data.Project, error) {
+func (m *MockProjectService) CreateProject(ctx context.Context, p data.ProjectCreate) (*data.Project, error) {
 	args := m.Called(p)
 	var project *data.Project
 	if args.Get(0) != nil {
@@ -21,7 +23,7 @@ func (m *MockProjectService) CreateProject(p data.ProjectCreate) (*data.Project,
 	return project, args.Error(1)
 }
 
-func (m *MockProjectService) GetProject(projectID uuid.UUID, requestingUserID *uuid.UUID) (*data.Project, error) {
+func (m *MockProjectService) GetProject(ctx context.Context, projectID uuid.UUID, requestingUserID *uuid.UUID) (*data.Project, error) {
 	args := m.Called(projectID, requestingUserID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -29,7 +31,31 @@ func (m *MockProjectService) GetProject(projectID uuid.UUID, requestingUserID *u
 	return args.Get(0).(*data.Project), args.Error(1)
 }
 
-func (m *MockProjectService) GetUserProjects(profileUserID, requestingUserID uuid.UUID) ([]data.Project, error) {
+func (m *MockProjectService) GetProjectsByIDs(ctx context.Context, projectIDs []uuid.UUID, requestingUserID *uuid.UUID) ([]data.Project, error) {
+	args := m.Called(projectIDs, requestingUserID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.Project), args.Error(1)
+}
+
+func (m *MockProjectService) GetEmbeddedProject(ctx context.Context, projectID uuid.UUID) (*data.Project, error) {
+	args := m.Called(projectID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.Project), args.Error(1)
+}
+
+func (m *MockProjectService) GetUserProjects(ctx context.Context, profileUserID uuid.UUID, requestingUserID *uuid.UUID, folder *data.FolderFilter) ([]data.Project, error) {
+	args := m.Called(profileUserID, requestingUserID, folder)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.Project), args.Error(1)
+}
+
+func (m *MockProjectService) GetContributedProjects(ctx context.Context, profileUserID uuid.UUID, requestingUserID *uuid.UUID) ([]data.Project, error) {
 	args := m.Called(profileUserID, requestingUserID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -37,7 +63,7 @@ func (m *MockProjectService) GetUserProjects(profileUserID, requestingUserID uui
 	return args.Get(0).([]data.Project), args.Error(1)
 }
 
-func (m *MockProjectService) GetFeaturedProjects(limit, offset int) ([]data.Project, error) {
+func (m *MockProjectService) GetFeaturedProjects(ctx context.Context, limit, offset int) ([]data.Project, error) {
 	args := m.Called(limit, offset)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -45,7 +71,7 @@ func (m *MockProjectService) GetFeaturedProjects(limit, offset int) ([]data.Proj
 	return args.Get(0).([]data.Project), args.Error(1)
 }
 
-func (m *MockProjectService) GetLikedProjects(userID uuid.UUID) ([]data.Project, error) {
+func (m *MockProjectService) GetLikedProjects(ctx context.Context, userID uuid.UUID) ([]data.Project, error) {
 	args := m.Called(userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -53,17 +79,43 @@ func (m *MockProjectService) GetLikedProjects(userID uuid.UUID) ([]data.Project,
 	return args.Get(0).([]data.Project), args.Error(1)
 }
 
-func (m *MockProjectService) LikeProject(projectID, userID uuid.UUID) error {
+func (m *MockProjectService) LikeProject(ctx context.Context, projectID, userID uuid.UUID) error {
 	args := m.Called(projectID, userID)
 	return args.Error(0)
 }
 
-func (m *MockProjectService) UnlikeProject(projectID, userID uuid.UUID) error {
+func (m *MockProjectService) UnlikeProject(ctx context.Context, projectID, userID uuid.UUID) error {
 	args := m.Called(projectID, userID)
 	return args.Error(0)
 }
 
-func (m *MockProjectService) UpdateProject(p data.ProjectUpdate) (*data.Project, error) {
+func (m *MockProjectService) RecordView(ctx context.Context, projectID uuid.UUID, userID *uuid.UUID, ip string) error {
+	args := m.Called(projectID, userID, ip)
+	return args.Error(0)
+}
+
+func (m *MockProjectService) DiscoverProjects(ctx context.Context, opts data.DiscoverOptions) ([]data.Project, error) {
+	args := m.Called(opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.Project), args.Error(1)
+}
+
+func (m *MockProjectService) RecordOpen(ctx context.Context, projectID, userID uuid.UUID) error {
+	args := m.Called(projectID, userID)
+	return args.Error(0)
+}
+
+func (m *MockProjectService) GetRecentProjects(ctx context.Context, userID uuid.UUID, limit int) ([]data.RecentProject, error) {
+	args := m.Called(userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.RecentProject), args.Error(1)
+}
+
+func (m *MockProjectService) UpdateProject(ctx context.Context, p data.ProjectUpdate) (*data.Project, error) {
 	args := m.Called(p)
 	var project *data.Project
 	if args.Get(0) != nil {
@@ -72,12 +124,12 @@ func (m *MockProjectService) UpdateProject(p data.ProjectUpdate) (*data.Project,
 	return project, args.Error(1)
 }
 
-func (m *MockProjectService) DeleteProject(projectID uuid.UUID) error {
+func (m *MockProjectService) DeleteProject(ctx context.Context, projectID uuid.UUID) error {
 	args := m.Called(projectID)
 	return args.Error(0)
 }
 
-func (m *MockProjectService) GetPublicProjects(filters data.PublicProjectFilter) ([]data.Project, int, error) {
+func (m *MockProjectService) GetPublicProjects(ctx context.Context, filters data.PublicProjectFilter) ([]data.Project, int, error) {
 	args := m.Called(filters)
 	if args.Get(0) == nil {
 		return nil, args.Int(1), args.Error(2)
@@ -85,12 +137,12 @@ func (m *MockProjectService) GetPublicProjects(filters data.PublicProjectFilter)
 	return args.Get(0).([]data.Project), args.Int(1), args.Error(2)
 }
 
-func (m *MockProjectService) IsOwner(projectID, userID uuid.UUID) (bool, error) {
+func (m *MockProjectService) IsOwner(ctx context.Context, projectID, userID uuid.UUID) (bool, error) {
 	args := m.Called(projectID, userID)
 	return args.Get(0).(bool), args.Error(1)
 }
 
-func (m *MockProjectService) ListProjects(filters data.ProjectFilter) ([]data.Project, int, error) {
+func (m *MockProjectService) ListProjects(ctx context.Context, filters data.ProjectFilter) ([]data.Project, int, error) {
 	args := m.Called(filters)
 	if args.Get(0) == nil {
 		return nil, args.Int(1), args.Error(2)
@@ -98,7 +150,7 @@ func (m *MockProjectService) ListProjects(filters data.ProjectFilter) ([]data.Pr
 	return args.Get(0).([]data.Project), args.Int(1), args.Error(2)
 }
 
-func (m *MockProjectService) FeatureProject(projectID uuid.UUID, expiresAt *time.Time) (*data.Project, error) {
+func (m *MockProjectService) FeatureProject(ctx context.Context, projectID uuid.UUID, expiresAt *time.Time) (*data.Project, error) {
 	args := m.Called(projectID, expiresAt)
 
 	var project *data.Project
@@ -108,3 +160,229 @@ func (m *MockProjectService) FeatureProject(projectID uuid.UUID, expiresAt *time
 
 	return project, args.Error(1)
 }
+
+func (m *MockProjectService) PublishProject(ctx context.Context, projectID uuid.UUID) (*data.ProjectSnapshot, error) {
+	args := m.Called(projectID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.ProjectSnapshot), args.Error(1)
+}
+
+func (m *MockProjectService) CheckTitle(ctx context.Context, creatorID uuid.UUID, title string, projectID *uuid.UUID) (*data.TitleConflict, error) {
+	args := m.Called(creatorID, title, projectID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.TitleConflict), args.Error(1)
+}
+
+func (m *MockProjectService) GetMetadataDraft(ctx context.Context, projectID uuid.UUID) (*data.ProjectMetadataDraft, error) {
+	args := m.Called(projectID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.ProjectMetadataDraft), args.Error(1)
+}
+
+func (m *MockProjectService) SaveMetadataDraft(ctx context.Context, projectID uuid.UUID, update data.ProjectMetadataDraftUpdate) (*data.ProjectMetadataDraft, error) {
+	args := m.Called(projectID, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.ProjectMetadataDraft), args.Error(1)
+}
+
+func (m *MockProjectService) DiscardMetadataDraft(ctx context.Context, projectID uuid.UUID) error {
+	args := m.Called(projectID)
+	return args.Error(0)
+}
+
+func (m *MockProjectService) SetAccessibility(ctx context.Context, projectID uuid.UUID, update data.ProjectAccessibilityUpdate) (*data.ProjectAccessibility, error) {
+	args := m.Called(projectID, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.ProjectAccessibility), args.Error(1)
+}
+
+func (m *MockProjectService) ForkProject(ctx context.Context, projectID, userID uuid.UUID) (*data.Project, error) {
+	args := m.Called(projectID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.Project), args.Error(1)
+}
+
+func (m *MockProjectService) GetForks(ctx context.Context, projectID uuid.UUID, requestingUserID *uuid.UUID, page, limit int) ([]data.Project, int, error) {
+	args := m.Called(projectID, requestingUserID, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]data.Project), args.Int(1), args.Error(2)
+}
+
+func (m *MockProjectService) GetLikers(ctx context.Context, projectID uuid.UUID, page, limit int) ([]data.ProjectLiker, int, error) {
+	args := m.Called(projectID, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]data.ProjectLiker), args.Int(1), args.Error(2)
+}
+
+func (m *MockProjectService) GetQuota(ctx context.Context, userID uuid.UUID) (*data.QuotaUsage, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.QuotaUsage), args.Error(1)
+}
+
+func (m *MockProjectService) ScanDataIntegrity(ctx context.Context, opts data.IntegrityScanOptions) (*data.IntegrityReport, error) {
+	args := m.Called(opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.IntegrityReport), args.Error(1)
+}
+
+func (m *MockProjectService) CountUserForks(ctx context.Context, userID uuid.UUID, since time.Time) (int, error) {
+	args := m.Called(userID, since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockProjectService) CountUserProjects(ctx context.Context, userID uuid.UUID) (int, error) {
+	args := m.Called(userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockProjectService) ListRevisions(ctx context.Context, projectID uuid.UUID) ([]data.ProjectRevision, error) {
+	args := m.Called(projectID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.ProjectRevision), args.Error(1)
+}
+
+func (m *MockProjectService) GetRevision(ctx context.Context, projectID uuid.UUID, revisionID int64) (*data.ProjectRevision, error) {
+	args := m.Called(projectID, revisionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.ProjectRevision), args.Error(1)
+}
+
+func (m *MockProjectService) GetPublicProjectsAfter(ctx context.Context, filters data.PublicProjectFilter, cursor *data.ProjectCursor) ([]data.Project, *data.ProjectCursor, error) {
+	args := m.Called(filters, cursor)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	next, _ := args.Get(1).(*data.ProjectCursor)
+	return args.Get(0).([]data.Project), next, args.Error(2)
+}
+
+func (m *MockProjectService) GetFeaturedProjectsAfter(ctx context.Context, limit int, cursor *data.ProjectCursor) ([]data.Project, *data.ProjectCursor, error) {
+	args := m.Called(limit, cursor)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	next, _ := args.Get(1).(*data.ProjectCursor)
+	return args.Get(0).([]data.Project), next, args.Error(2)
+}
+
+func (m *MockProjectService) ExportProject(ctx context.Context, projectID uuid.UUID) (*data.ProjectImport, error) {
+	args := m.Called(projectID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.ProjectImport), args.Error(1)
+}
+
+func (m *MockProjectService) SaveProjectData(ctx context.Context, projectID uuid.UUID, flowData json.RawMessage, version int, userID uuid.UUID, session string) (int, error) {
+	args := m.Called(projectID, flowData, version, userID, session)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockProjectService) GetLastSave(ctx context.Context, projectID uuid.UUID) (*data.ProjectSave, error) {
+	args := m.Called(projectID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.ProjectSave), args.Error(1)
+}
+
+func (m *MockProjectService) GetProjectRole(ctx context.Context, projectID, userID uuid.UUID) (data.ProjectRole, error) {
+	args := m.Called(projectID, userID)
+	return args.Get(0).(data.ProjectRole), args.Error(1)
+}
+
+func (m *MockProjectService) GetMembers(ctx context.Context, projectID uuid.UUID) ([]data.ProjectMember, error) {
+	args := m.Called(projectID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.ProjectMember), args.Error(1)
+}
+
+func (m *MockProjectService) AddMember(ctx context.Context, projectID uuid.UUID, invite data.ProjectMemberInvite) (*data.ProjectMember, error) {
+	args := m.Called(projectID, invite)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.ProjectMember), args.Error(1)
+}
+
+func (m *MockProjectService) RemoveMember(ctx context.Context, projectID, userID uuid.UUID) error {
+	args := m.Called(projectID, userID)
+	return args.Error(0)
+}
+
+func (m *MockProjectService) CompactProjectData(ctx context.Context, opts data.CompactionOptions) (*data.CompactionReport, error) {
+	args := m.Called(opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.CompactionReport), args.Error(1)
+}
+
+func (m *MockProjectService) GetUserProjectSummaries(ctx context.Context, profileUserID uuid.UUID, requestingUserID *uuid.UUID, folder *data.FolderFilter) ([]data.Project, error) {
+	args := m.Called(profileUserID, requestingUserID, folder)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.Project), args.Error(1)
+}
+
+func (m *MockProjectService) GetFeaturedProjectSummaries(ctx context.Context, limit, offset int) ([]data.Project, error) {
+	args := m.Called(limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.Project), args.Error(1)
+}
+
+func (m *MockProjectService) GetFeaturedProjectSummariesAfter(ctx context.Context, limit int, cursor *data.ProjectCursor) ([]data.Project, *data.ProjectCursor, error) {
+	args := m.Called(limit, cursor)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	next, _ := args.Get(1).(*data.ProjectCursor)
+	return args.Get(0).([]data.Project), next, args.Error(2)
+}
+
+func (m *MockProjectService) GetPublicProjectSummaries(ctx context.Context, filters data.PublicProjectFilter) ([]data.Project, int, error) {
+	args := m.Called(filters)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]data.Project), args.Int(1), args.Error(2)
+}
+
+func (m *MockProjectService) GetPublicProjectSummariesAfter(ctx context.Context, filters data.PublicProjectFilter, cursor *data.ProjectCursor) ([]data.Project, *data.ProjectCursor, error) {
+	args := m.Called(filters, cursor)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	next, _ := args.Get(1).(*data.ProjectCursor)
+	return args.Get(0).([]data.Project), next, args.Error(2)
+}
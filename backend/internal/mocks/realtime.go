@@ -0,0 +1,23 @@
+package mocks
+
+import (
+	"NodeTurtleAPI/internal/data"
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRealtimeService struct {
+	mock.Mock
+}
+
+func (m *MockRealtimeService) Publish(ctx context.Context, userID uuid.UUID, eventType string, payload interface{}) error {
+	args := m.Called(userID, eventType, payload)
+	return args.Error(0)
+}
+
+func (m *MockRealtimeService) Subscribe(userID uuid.UUID) (<-chan data.RealtimeEvent, func()) {
+	args := m.Called(userID)
+	return args.Get(0).(<-chan data.RealtimeEvent), args.Get(1).(func())
+}
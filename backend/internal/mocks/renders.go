@@ -0,0 +1,20 @@
+package mocks
+
+import (
+	"NodeTurtleAPI/internal/data"
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRenderService struct {
+	mock.Mock
+}
+
+func (m *MockRenderService) GetStatus(ctx context.Context) (*data.RenderStatus, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.RenderStatus), args.Error(1)
+}
@@ -0,0 +1,51 @@
+package mocks
+
+import (
+	"context"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReportService struct {
+	mock.Mock
+}
+
+func (m *MockReportService) ReportProject(ctx context.Context, projectID, reporterID uuid.UUID, submission data.ProjectReportSubmission) (*data.ProjectReport, error) {
+	args := m.Called(projectID, reporterID, submission)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.ProjectReport), args.Error(1)
+}
+
+func (m *MockReportService) GetReport(ctx context.Context, reportID int64) (*data.ProjectReport, error) {
+	args := m.Called(reportID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.ProjectReport), args.Error(1)
+}
+
+func (m *MockReportService) ListOpenReports(ctx context.Context) ([]data.ProjectReport, error) {
+	args := m.Called()
+	return args.Get(0).([]data.ProjectReport), args.Error(1)
+}
+
+func (m *MockReportService) DismissReport(ctx context.Context, reportID int64, moderatorID uuid.UUID) (*data.ProjectReport, error) {
+	args := m.Called(reportID, moderatorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.ProjectReport), args.Error(1)
+}
+
+func (m *MockReportService) TakeDownProject(ctx context.Context, projectID, moderatorID uuid.UUID, reason string) (*data.ProjectTakedown, error) {
+	args := m.Called(projectID, moderatorID, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.ProjectTakedown), args.Error(1)
+}
@@ -0,0 +1,38 @@
+package mocks
+
+import (
+	"context"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRetentionService struct {
+	mock.Mock
+}
+
+func (m *MockRetentionService) Classes(ctx context.Context) ([]data.RetentionClass, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.RetentionClass), args.Error(1)
+}
+
+func (m *MockRetentionService) Purge(ctx context.Context, trigger data.RetentionTrigger, actorID *uuid.UUID) ([]data.RetentionRun, error) {
+	args := m.Called(trigger, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.RetentionRun), args.Error(1)
+}
+
+func (m *MockRetentionService) ListRuns(ctx context.Context, class string, limit int) ([]data.RetentionRun, error) {
+	args := m.Called(class, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.RetentionRun), args.Error(1)
+}
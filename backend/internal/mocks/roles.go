@@ -0,0 +1,21 @@
+package mocks
+
+import (
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRoleService struct {
+	mock.Mock
+}
+
+func (m *MockRoleService) HasPermission(roleID int64, permission data.Permission) (bool, error) {
+	args := m.Called(roleID, permission)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRoleService) GetPermissions(roleID int64) ([]data.Permission, error) {
+	args := m.Called(roleID)
+	return args.Get(0).([]data.Permission), args.Error(1)
+}
@@ -0,0 +1,25 @@
+package mocks
+
+import (
+	"NodeTurtleAPI/internal/data"
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRunService struct {
+	mock.Mock
+}
+
+func (m *MockRunService) Run(ctx context.Context, userID uuid.UUID, flow json.RawMessage) (*data.ProjectRun, error) {
+	args := m.Called(userID, flow)
+
+	var run *data.ProjectRun
+	if args.Get(0) != nil {
+		run = args.Get(0).(*data.ProjectRun)
+	}
+
+	return run, args.Error(1)
+}
@@ -0,0 +1,46 @@
+package mocks
+
+import (
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSignupService struct {
+	mock.Mock
+}
+
+func (m *MockSignupService) IsAllowlisted(ip, domain string) (bool, error) {
+	args := m.Called(ip, domain)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSignupService) ListAllowlist() ([]data.SignupAllowlistEntry, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.SignupAllowlistEntry), args.Error(1)
+}
+
+func (m *MockSignupService) AddToAllowlist(entry data.SignupAllowlistCreate, createdBy uuid.UUID) (*data.SignupAllowlistEntry, error) {
+	args := m.Called(entry, createdBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.SignupAllowlistEntry), args.Error(1)
+}
+
+func (m *MockSignupService) GetAllowlistEntry(id int64) (*data.SignupAllowlistEntry, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.SignupAllowlistEntry), args.Error(1)
+}
+
+func (m *MockSignupService) RemoveFromAllowlist(id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
@@ -0,0 +1,25 @@
+package mocks
+
+import (
+	"NodeTurtleAPI/internal/data"
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSystemService struct {
+	mock.Mock
+}
+
+func (m *MockSystemService) DBHealth() (*data.DBHealth, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.DBHealth), args.Error(1)
+}
+
+func (m *MockSystemService) Ping(ctx context.Context) error {
+	args := m.Called()
+	return args.Error(0)
+}
@@ -0,0 +1,34 @@
+package mocks
+
+import (
+	"context"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTaskService struct {
+	mock.Mock
+}
+
+func (m *MockTaskService) Enqueue(ctx context.Context, kind data.TaskKind, payload interface{}) error {
+	args := m.Called(kind, payload)
+	return args.Error(0)
+}
+
+func (m *MockTaskService) ListDead(ctx context.Context, filter data.TaskFilter) ([]data.Task, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.Task), args.Error(1)
+}
+
+func (m *MockTaskService) Retry(ctx context.Context, id int64) (*data.Task, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.Task), args.Error(1)
+}
@@ -0,0 +1,20 @@
+package mocks
+
+import (
+	"NodeTurtleAPI/internal/data"
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockThumbnailService struct {
+	mock.Mock
+}
+
+func (m *MockThumbnailService) GetThumbnail(ctx context.Context, project *data.Project, format data.ThumbnailFormat) (*data.Thumbnail, error) {
+	args := m.Called(project, format)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.Thumbnail), args.Error(1)
+}
@@ -2,7 +2,7 @@ package mocks
 
 import (
 	"NodeTurtleAPI/internal/data"
-	"time"
+	"context"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
@@ -12,8 +12,8 @@ type MockTokenService struct {
 	mock.Mock
 }
 
-func (m *MockTokenService) New(userID uuid.UUID, ttl time.Duration, scope data.TokenScope) (*data.Token, error) {
-	args := m.Called(userID, ttl, scope)
+func (m *MockTokenService) New(ctx context.Context, userID uuid.UUID, scope data.TokenScope) (*data.Token, error) {
+	args := m.Called(userID, scope)
 
 	var token *data.Token
 	if args.Get(0) != nil {
@@ -22,12 +22,30 @@ func (m *MockTokenService) New(userID uuid.UUID, ttl time.Duration, scope data.T
 	return token, args.Error(1)
 }
 
-func (m *MockTokenService) Insert(token *data.Token) error {
+func (m *MockTokenService) Insert(ctx context.Context, token *data.Token) error {
 	args := m.Called(token)
 	return args.Error(0)
 }
 
-func (m *MockTokenService) DeleteAllForUser(scope data.TokenScope, userID uuid.UUID) error {
+func (m *MockTokenService) Consume(ctx context.Context, scope data.TokenScope, tokenPlaintext string) error {
+	args := m.Called(scope, tokenPlaintext)
+	return args.Error(0)
+}
+
+func (m *MockTokenService) DeleteAllForUser(ctx context.Context, scope data.TokenScope, userID uuid.UUID) error {
 	args := m.Called(scope, userID)
 	return args.Error(0)
 }
+
+func (m *MockTokenService) Stats(ctx context.Context) ([]data.TokenStats, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.TokenStats), args.Error(1)
+}
+
+func (m *MockTokenService) Revoke(ctx context.Context, revocation data.TokenRevocation) (int64, error) {
+	args := m.Called(revocation)
+	return args.Get(0).(int64), args.Error(1)
+}
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"NodeTurtleAPI/internal/data"
+	"context"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
@@ -11,7 +12,7 @@ type MockUserService struct {
 	mock.Mock
 }
 
-func (m *MockUserService) CreateUser(reg data.UserRegistration) (*data.User, error) {
+func (m *MockUserService) CreateUser(ctx context.Context, reg data.UserRegistration) (*data.User, error) {
 	args := m.Called(reg)
 	var user *data.User
 	if args.Get(0) != nil {
@@ -20,23 +21,23 @@ func (m *MockUserService) CreateUser(reg data.UserRegistration) (*data.User, err
 	return user, args.Error(1)
 }
 
-func (m *MockUserService) ResetPassword(token, newPassword string) error {
+func (m *MockUserService) ResetPassword(ctx context.Context, token, newPassword string) error {
 	args := m.Called(token, newPassword)
 	return args.Error(0)
 }
-func (m *MockUserService) ChangePassword(userID uuid.UUID, oldPassword, newPassword string) error {
+func (m *MockUserService) ChangePassword(ctx context.Context, userID uuid.UUID, oldPassword, newPassword string) error {
 	args := m.Called(userID, oldPassword, newPassword)
 	return args.Error(0)
 }
 
-func (m *MockUserService) GetUserByID(userID uuid.UUID) (*data.User, error) {
+func (m *MockUserService) GetUserByID(ctx context.Context, userID uuid.UUID) (*data.User, error) {
 	args := m.Called(userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*data.User), args.Error(1)
 }
-func (m *MockUserService) GetUserByUsername(username string) (*data.User, error) {
+func (m *MockUserService) GetUserByUsername(ctx context.Context, username string) (*data.User, error) {
 	args := m.Called(username)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -44,7 +45,7 @@ func (m *MockUserService) GetUserByUsername(username string) (*data.User, error)
 	return args.Get(0).(*data.User), args.Error(1)
 }
 
-func (m *MockUserService) GetUserByEmail(email string) (*data.User, error) {
+func (m *MockUserService) GetUserByEmail(ctx context.Context, email string) (*data.User, error) {
 	args := m.Called(email)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -52,7 +53,7 @@ func (m *MockUserService) GetUserByEmail(email string) (*data.User, error) {
 	return args.Get(0).(*data.User), args.Error(1)
 }
 
-func (m *MockUserService) ListUsers(filters data.UserFilter) ([]data.User, int, error) {
+func (m *MockUserService) ListUsers(ctx context.Context, filters data.UserFilter) ([]data.User, int, error) {
 	args := m.Called(filters)
 	if args.Get(0) == nil {
 		return nil, args.Int(1), args.Error(2)
@@ -60,7 +61,7 @@ func (m *MockUserService) ListUsers(filters data.UserFilter) ([]data.User, int,
 	return args.Get(0).([]data.User), args.Int(1), args.Error(2)
 }
 
-func (m *MockUserService) UpdateUser(userID uuid.UUID, updates data.UserUpdate) (*data.User, error) {
+func (m *MockUserService) UpdateUser(ctx context.Context, userID uuid.UUID, updates data.UserUpdate) (*data.User, error) {
 	args := m.Called(userID, updates)
 	var user *data.User
 	if args.Get(0) != nil {
@@ -69,12 +70,29 @@ func (m *MockUserService) UpdateUser(userID uuid.UUID, updates data.UserUpdate)
 	return user, args.Error(1)
 }
 
-func (m *MockUserService) DeleteUser(userID uuid.UUID) error {
+func (m *MockUserService) ChangeUsername(ctx context.Context, userID uuid.UUID, username string) (*data.User, error) {
+	args := m.Called(userID, username)
+	var user *data.User
+	if args.Get(0) != nil {
+		user = args.Get(0).(*data.User)
+	}
+	return user, args.Error(1)
+}
+
+func (m *MockUserService) ResolveUsername(ctx context.Context, username string) (*data.ResolvedUsername, error) {
+	args := m.Called(username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.ResolvedUsername), args.Error(1)
+}
+
+func (m *MockUserService) DeleteUser(ctx context.Context, userID uuid.UUID) error {
 	args := m.Called(userID)
 	return args.Error(0)
 }
 
-func (m *MockUserService) GetForToken(tokenScope data.TokenScope, tokenPlaintext string) (*data.User, error) {
+func (m *MockUserService) GetForToken(ctx context.Context, tokenScope data.TokenScope, tokenPlaintext string) (*data.User, error) {
 	args := m.Called(tokenScope, tokenPlaintext)
 	var user *data.User
 	if args.Get(0) != nil {
@@ -83,13 +101,37 @@ func (m *MockUserService) GetForToken(tokenScope data.TokenScope, tokenPlaintext
 	return user, args.Error(1)
 }
 
-func (m *MockUserService) UsernameExists(username string) (bool, error) {
+func (m *MockUserService) UsernameExists(ctx context.Context, username string) (bool, error) {
 	args := m.Called(username)
 
 	return args.Get(0).(bool), args.Error(1)
 }
-func (m *MockUserService) EmailExists(email string) (bool, error) {
+func (m *MockUserService) EmailExists(ctx context.Context, email string) (bool, error) {
 	args := m.Called(email)
 
 	return args.Get(0).(bool), args.Error(1)
 }
+
+func (m *MockUserService) ProvisionUser(ctx context.Context, p data.UserProvision) (*data.User, string, error) {
+	args := m.Called(p)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).(*data.User), args.String(1), args.Error(2)
+}
+
+func (m *MockUserService) DeprovisionUser(ctx context.Context, email string) (*data.User, error) {
+	args := m.Called(email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.User), args.Error(1)
+}
+
+func (m *MockUserService) BulkUpdateUsers(ctx context.Context, actor data.User, update data.BulkUserUpdate) ([]data.BulkUserResult, error) {
+	args := m.Called(actor, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.BulkUserResult), args.Error(1)
+}
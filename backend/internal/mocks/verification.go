@@ -0,0 +1,53 @@
+package mocks
+
+import (
+	"NodeTurtleAPI/internal/data"
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockVerificationService struct {
+	mock.Mock
+}
+
+func (m *MockVerificationService) SubmitRequest(ctx context.Context, userID uuid.UUID, evidence string) (*data.VerificationRequest, error) {
+	args := m.Called(userID, evidence)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.VerificationRequest), args.Error(1)
+}
+
+func (m *MockVerificationService) GetLatestRequest(ctx context.Context, userID uuid.UUID) (*data.VerificationRequest, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.VerificationRequest), args.Error(1)
+}
+
+func (m *MockVerificationService) GetRequest(ctx context.Context, requestID int64) (*data.VerificationRequest, error) {
+	args := m.Called(requestID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.VerificationRequest), args.Error(1)
+}
+
+func (m *MockVerificationService) ListPendingRequests(ctx context.Context) ([]data.VerificationRequest, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]data.VerificationRequest), args.Error(1)
+}
+
+func (m *MockVerificationService) ReviewRequest(ctx context.Context, requestID int64, review data.VerificationReview, reviewerID uuid.UUID) (*data.VerificationRequest, error) {
+	args := m.Called(requestID, review, reviewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.VerificationRequest), args.Error(1)
+}
@@ -0,0 +1,45 @@
+package mocks
+
+import (
+	"context"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) GetProjectWebhook(projectID uuid.UUID) (*data.ProjectWebhook, error) {
+	args := m.Called(projectID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.ProjectWebhook), args.Error(1)
+}
+
+func (m *MockWebhookService) SetProjectWebhook(w data.ProjectWebhook) (*data.ProjectWebhook, error) {
+	args := m.Called(w)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*data.ProjectWebhook), args.Error(1)
+}
+
+func (m *MockWebhookService) DeleteProjectWebhook(projectID uuid.UUID) error {
+	args := m.Called(projectID)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) GetDeliveries(projectID uuid.UUID, limit int) ([]data.WebhookDelivery, error) {
+	args := m.Called(projectID, limit)
+	return args.Get(0).([]data.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookService) DeliverMilestones(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
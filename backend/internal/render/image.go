@@ -0,0 +1,245 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strconv"
+)
+
+const (
+	lineWidth = 2
+	padding   = 8 // pixels kept free around the drawing
+)
+
+var defaultColor = color.RGBA{A: 255}
+
+// Segment is a line drawn by a turtle, in the coordinates of the editor canvas with the start at the origin and y pointing down.
+type Segment struct {
+	X1, Y1, X2, Y2 float64
+	Color          color.RGBA
+}
+
+// Hex returns the color of the segment as #rrggbb, or #rrggbbaa when it's translucent.
+func (s Segment) Hex() string {
+	return hexColor(s.Color)
+}
+
+// Drawing is what the turtles of a program drew.
+type Drawing struct {
+	Segments []Segment
+}
+
+// trace runs the commands of a single turtle, which starts at the origin facing up with the pen down.
+func (d *Drawing) trace(commands []command) {
+	x, y, angle := 0.0, 0.0, 90.0
+	penDown, stroke := true, defaultColor
+
+	for _, cmd := range commands {
+		switch cmd.kind {
+		case cmdMove:
+			radians := angle * math.Pi / 180
+			endX, endY := x+math.Cos(radians)*cmd.value, y-math.Sin(radians)*cmd.value
+			if penDown {
+				d.Segments = append(d.Segments, Segment{X1: x, Y1: y, X2: endX, Y2: endY, Color: stroke})
+			}
+			x, y = endX, endY
+		case cmdRotate:
+			angle += cmd.value
+		case cmdPen:
+			penDown, stroke = cmd.penDown, parseColor(cmd.color)
+		}
+	}
+}
+
+// viewport scales and offsets the drawing so it fits the image, centered and keeping its aspect ratio.
+type viewport struct {
+	scale, offsetX, offsetY float64
+}
+
+func (d *Drawing) fit(width, height int) viewport {
+	if len(d.Segments) == 0 {
+		return viewport{scale: 1}
+	}
+
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, s := range d.Segments {
+		minX, maxX = math.Min(minX, math.Min(s.X1, s.X2)), math.Max(maxX, math.Max(s.X1, s.X2))
+		minY, maxY = math.Min(minY, math.Min(s.Y1, s.Y2)), math.Max(maxY, math.Max(s.Y1, s.Y2))
+	}
+
+	availableX, availableY := float64(width-2*padding), float64(height-2*padding)
+	scale := math.Inf(1)
+	if maxX > minX {
+		scale = availableX / (maxX - minX)
+	}
+	if maxY > minY {
+		scale = math.Min(scale, availableY/(maxY-minY))
+	}
+	if math.IsInf(scale, 1) {
+		scale = 1
+	}
+
+	return viewport{
+		scale:   scale,
+		offsetX: float64(width)/2 - (minX+maxX)/2*scale,
+		offsetY: float64(height)/2 - (minY+maxY)/2*scale,
+	}
+}
+
+func (v viewport) point(x, y float64) (float64, float64) {
+	return x*v.scale + v.offsetX, y*v.scale + v.offsetY
+}
+
+// SVG draws the drawing fitted into an image of the given size on a white background.
+func (d *Drawing) SVG(width, height int) []byte {
+	v := d.fit(width, height)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, width, height, width, height)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/>`, width, height)
+
+	open := false
+	var last Segment
+	for i, s := range d.Segments {
+		x1, y1 := v.point(s.X1, s.Y1)
+		x2, y2 := v.point(s.X2, s.Y2)
+
+		if i == 0 || s.Color != last.Color {
+			if open {
+				b.WriteString(`"/>`)
+			}
+			fmt.Fprintf(&b, `<path fill="none" stroke="%s" stroke-width="%d" stroke-linecap="round" stroke-linejoin="round" d="`, hexColor(s.Color), lineWidth)
+			open = true
+		}
+		if i == 0 || s.Color != last.Color || s.X1 != last.X2 || s.Y1 != last.Y2 {
+			fmt.Fprintf(&b, "M%s %s", coord(x1), coord(y1))
+		}
+		fmt.Fprintf(&b, "L%s %s", coord(x2), coord(y2))
+		last = s
+	}
+	if open {
+		b.WriteString(`"/>`)
+	}
+
+	b.WriteString(`</svg>`)
+	return b.Bytes()
+}
+
+// PNG draws the drawing fitted into an image of the given size on a white background.
+func (d *Drawing) PNG(width, height int) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+
+	v := d.fit(width, height)
+	for _, s := range d.Segments {
+		x1, y1 := v.point(s.X1, s.Y1)
+		x2, y2 := v.point(s.X2, s.Y2)
+		strokeLine(img, x1, y1, x2, y2, s.Color)
+	}
+
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// strokeLine draws an antialiased line with round caps. It walks the major axis of the line
+// and only looks at the pixels near it, so long lines cost their length rather than their bounding box.
+func strokeLine(img *image.RGBA, x1, y1, x2, y2 float64, c color.RGBA) {
+	const radius = lineWidth / 2.0
+
+	steep := math.Abs(y2-y1) > math.Abs(x2-x1)
+	if steep {
+		x1, y1, x2, y2 = y1, x1, y2, x2
+	}
+	if x1 > x2 {
+		x1, y1, x2, y2 = x2, y2, x1, y1
+	}
+
+	slope := 0.0
+	if x2 != x1 {
+		slope = (y2 - y1) / (x2 - x1)
+	}
+
+	for major := int(math.Floor(x1 - radius)); major <= int(math.Ceil(x2+radius)); major++ {
+		center := y1 + slope*(math.Max(x1, math.Min(x2, float64(major)+0.5))-x1)
+		for minor := int(math.Floor(center - radius - 1)); minor <= int(math.Ceil(center+radius+1)); minor++ {
+			px, py := float64(major)+0.5, float64(minor)+0.5
+			coverage := radius + 0.5 - distanceToSegment(px, py, x1, y1, x2, y2)
+			if coverage <= 0 {
+				continue
+			}
+
+			x, y := major, minor
+			if steep {
+				x, y = minor, major
+			}
+			blend(img, x, y, c, math.Min(coverage, 1))
+		}
+	}
+}
+
+func distanceToSegment(px, py, x1, y1, x2, y2 float64) float64 {
+	dx, dy := x2-x1, y2-y1
+	t := 0.0
+	if length := dx*dx + dy*dy; length > 0 {
+		t = math.Max(0, math.Min(1, ((px-x1)*dx+(py-y1)*dy)/length))
+	}
+	return math.Hypot(px-(x1+t*dx), py-(y1+t*dy))
+}
+
+func blend(img *image.RGBA, x, y int, c color.RGBA, coverage float64) {
+	if !(image.Point{X: x, Y: y}).In(img.Rect) {
+		return
+	}
+
+	alpha := coverage * float64(c.A) / 255
+	i := img.PixOffset(x, y)
+	for channel, value := range [3]uint8{c.R, c.G, c.B} {
+		dst := float64(img.Pix[i+channel])
+		img.Pix[i+channel] = uint8(math.Round(dst + (float64(value)-dst)*alpha))
+	}
+}
+
+// parseColor reads the #rgb, #rrggbb and #rrggbbaa colors the editor stores, anything else draws black.
+// Only colors that parse make it into an SVG, so project data can't inject markup.
+func parseColor(s string) color.RGBA {
+	if len(s) == 0 || s[0] != '#' {
+		return defaultColor
+	}
+
+	hex := s[1:]
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return defaultColor
+	}
+
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return defaultColor
+	}
+	return color.RGBA{R: uint8(value >> 24), G: uint8(value >> 16), B: uint8(value >> 8), A: uint8(value)}
+}
+
+func hexColor(c color.RGBA) string {
+	if c.A == 255 {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
+
+func coord(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -0,0 +1,364 @@
+// Package render executes the turtle programs stored as project data and draws the result,
+// so thumbnails and gallery previews don't need a browser.
+// Execution follows the editor: every path from the start node is a turtle of its own, branches and
+// loop iterations spawn further turtles.
+package render
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"time"
+)
+
+var (
+	// ErrInstructionLimit is returned when a program needs more steps than allowed,
+	// typically a loop spawning turtles on every iteration of a branching body.
+	ErrInstructionLimit = errors.New("render: instruction limit exceeded")
+	// ErrTimeLimit is returned when a program doesn't finish within the allowed time.
+	ErrTimeLimit = errors.New("render: time limit exceeded")
+	// ErrMemoryLimit is returned when a program draws more lines than allowed, which are all held in memory.
+	ErrMemoryLimit = errors.New("render: memory limit exceeded")
+)
+
+// Limits bounds the work spent on a single program.
+type Limits struct {
+	MaxInstructions int           // turtle commands executed over all turtles, including the nodes visited to find them
+	Timeout         time.Duration // 0 disables the time limit
+	MaxSegments     int           // lines drawn over all turtles, 0 disables the limit
+}
+
+// Usage is the work spent on a program, whether it finished or not.
+type Usage struct {
+	Steps int // instructions charged, at most Limits.MaxInstructions
+}
+
+type commandKind int
+
+const (
+	cmdMove commandKind = iota
+	cmdRotate
+	cmdPen
+)
+
+type command struct {
+	kind    commandKind
+	value   float64
+	penDown bool
+	color   string
+}
+
+type flowNode struct {
+	ID   string                     `json:"id"`
+	Type string                     `json:"type"`
+	Data map[string]json.RawMessage `json:"data"`
+}
+
+type flowEdge struct {
+	Source       string `json:"source"`
+	Target       string `json:"target"`
+	SourceHandle string `json:"sourceHandle"`
+}
+
+// Execute runs the program in the flow document and returns what the turtles drew.
+// Like project data, the document may be a JSON object or a string containing one. An empty document draws nothing.
+func Execute(ctx context.Context, raw json.RawMessage, limits Limits) (*Drawing, error) {
+	drawing, _, err := Run(ctx, raw, limits)
+	return drawing, err
+}
+
+// Run is Execute reporting the work spent on the program as well, also when it fails on a limit,
+// for callers metering it.
+func Run(ctx context.Context, raw json.RawMessage, limits Limits) (*Drawing, Usage, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return &Drawing{}, Usage{}, nil
+	}
+	if raw[0] == '"' {
+		var encoded string
+		if err := json.Unmarshal(raw, &encoded); err != nil {
+			return nil, Usage{}, err
+		}
+		raw = json.RawMessage(encoded)
+	}
+
+	var doc struct {
+		Nodes []flowNode `json:"nodes"`
+		Edges []flowEdge `json:"edges"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, Usage{}, err
+	}
+
+	if limits.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, limits.Timeout)
+		defer cancel()
+	}
+
+	t := tracer{
+		ctx:    ctx,
+		nodes:  map[string]flowNode{},
+		edges:  map[string][]flowEdge{},
+		budget: limits.MaxInstructions,
+	}
+	for _, n := range doc.Nodes {
+		if _, exists := t.nodes[n.ID]; !exists {
+			t.nodes[n.ID] = n
+		}
+	}
+	for _, e := range doc.Edges {
+		t.edges[e.Source] = append(t.edges[e.Source], e)
+	}
+
+	var start *flowNode
+	for i := range doc.Nodes {
+		if doc.Nodes[i].Type == "startNode" {
+			start = &doc.Nodes[i]
+			break
+		}
+	}
+	if start == nil {
+		return &Drawing{}, Usage{}, nil
+	}
+
+	paths, err := t.collect(start.ID, nil)
+	if err != nil {
+		return nil, t.usage(limits), err
+	}
+
+	drawing := &Drawing{}
+	for _, p := range paths {
+		commands, err := t.commands(p)
+		if err != nil {
+			return nil, t.usage(limits), err
+		}
+		drawing.trace(commands)
+		if limits.MaxSegments > 0 && len(drawing.Segments) > limits.MaxSegments {
+			return nil, t.usage(limits), ErrMemoryLimit
+		}
+	}
+	return drawing, t.usage(limits), nil
+}
+
+// path is the command sequence of a turtle as a list from its last command back, so turtles share
+// the commands they have in common and branching doesn't copy them.
+type path struct {
+	cmd    command
+	prev   *path
+	length int
+}
+
+// tracer collects the command sequences of all turtles, charging every node visit and command to the budget.
+type tracer struct {
+	ctx    context.Context
+	nodes  map[string]flowNode
+	edges  map[string][]flowEdge
+	budget int
+}
+
+func (t *tracer) usage(limits Limits) Usage {
+	return Usage{Steps: limits.MaxInstructions - max(t.budget, 0)}
+}
+
+func (t *tracer) charge(n int) error {
+	t.budget -= n
+	if t.budget < 0 {
+		return ErrInstructionLimit
+	}
+	if t.ctx.Err() != nil {
+		return ErrTimeLimit
+	}
+	return nil
+}
+
+// extend returns base followed by the commands. Every call costs at least one step,
+// so a loop with an empty body can't spin for free.
+func (t *tracer) extend(base *path, commands []command) (*path, error) {
+	if err := t.charge(len(commands) + 1); err != nil {
+		return nil, err
+	}
+	for _, cmd := range commands {
+		length := 1
+		if base != nil {
+			length = base.length + 1
+		}
+		base = &path{cmd: cmd, prev: base, length: length}
+	}
+	return base, nil
+}
+
+// commands returns the commands of a path in order, charging each as it will be executed.
+func (t *tracer) commands(p *path) ([]command, error) {
+	if p == nil {
+		return nil, nil
+	}
+	if err := t.charge(p.length); err != nil {
+		return nil, err
+	}
+	commands := make([]command, p.length)
+	for i := p.length - 1; p != nil; i, p = i-1, p.prev {
+		commands[i] = p.cmd
+	}
+	return commands, nil
+}
+
+func (t *tracer) collect(nodeID string, before *path) ([]*path, error) {
+	n, ok := t.nodes[nodeID]
+	if !ok {
+		return nil, nil
+	}
+	if err := t.charge(1); err != nil {
+		return nil, err
+	}
+
+	muted := boolParam(n.Data, "muted")
+
+	current := before
+	if !muted {
+		var err error
+		if current, err = t.extend(before, nodeCommands(n)); err != nil {
+			return nil, err
+		}
+	}
+
+	if n.Type == "loopNode" && !muted {
+		return t.loop(n, current)
+	}
+
+	var paths []*path
+	found := false
+	for _, e := range t.edges[nodeID] {
+		if e.SourceHandle == "loop" {
+			continue
+		}
+		found = true
+		next, err := t.collect(e.Target, current)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, next...)
+	}
+	if !found {
+		return []*path{current}, nil
+	}
+	return paths, nil
+}
+
+// loop repeats every path through the loop body on every turtle alive, so a branching body doubles the turtles
+// with each iteration. With createTurtleOnIteration each iteration also continues from the out handle.
+func (t *tracer) loop(n flowNode, before *path) ([]*path, error) {
+	count := int(numberParam(n.Data, "loopCount"))
+	spawn := boolParam(n.Data, "createTurtleOnIteration")
+
+	var bodyEdges, outEdges []flowEdge
+	for _, e := range t.edges[n.ID] {
+		switch e.SourceHandle {
+		case "loop":
+			bodyEdges = append(bodyEdges, e)
+		case "out":
+			outEdges = append(outEdges, e)
+		}
+	}
+
+	var deltas [][]command
+	for _, e := range bodyEdges {
+		bodies, err := t.collect(e.Target, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, body := range bodies {
+			delta, err := t.commands(body)
+			if err != nil {
+				return nil, err
+			}
+			deltas = append(deltas, delta)
+		}
+	}
+	if len(bodyEdges) == 0 {
+		deltas = [][]command{nil}
+	}
+
+	var paths []*path
+	release := func(state *path) error {
+		if len(outEdges) == 0 {
+			paths = append(paths, state)
+			return nil
+		}
+		for _, e := range outEdges {
+			next, err := t.collect(e.Target, state)
+			if err != nil {
+				return err
+			}
+			paths = append(paths, next...)
+		}
+		return nil
+	}
+
+	frontier := []*path{before}
+	for i := 0; i < count; i++ {
+		var next []*path
+		for _, base := range frontier {
+			for _, delta := range deltas {
+				state, err := t.extend(base, delta)
+				if err != nil {
+					return nil, err
+				}
+				next = append(next, state)
+				if spawn {
+					if err := release(state); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+		frontier = next
+	}
+
+	if !spawn {
+		for _, state := range frontier {
+			if err := release(state); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return paths, nil
+}
+
+// nodeCommands translates a node into turtle commands with the defaults the editor applies.
+func nodeCommands(n flowNode) []command {
+	switch n.Type {
+	case "moveNode":
+		distance := numberParam(n.Data, "distance")
+		if distance == 0 {
+			distance = 10
+		}
+		return []command{{kind: cmdMove, value: distance}}
+	case "rotateNode":
+		return []command{{kind: cmdRotate, value: -numberParam(n.Data, "angle")}}
+	case "penNode":
+		var color string
+		_ = json.Unmarshal(n.Data["color"], &color)
+		if color == "" {
+			color = "#000"
+		}
+		return []command{{kind: cmdPen, penDown: boolParam(n.Data, "penDown"), color: color}}
+	}
+	return nil
+}
+
+func numberParam(params map[string]json.RawMessage, name string) float64 {
+	var n float64
+	if json.Unmarshal(params[name], &n) != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0
+	}
+	return n
+}
+
+func boolParam(params map[string]json.RawMessage, name string) bool {
+	var b bool
+	_ = json.Unmarshal(params[name], &b)
+	return b
+}
@@ -0,0 +1,189 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// square draws a square with sides of 50, one move and one rotation per loop iteration.
+const square = `{"nodes":[
+	{"id":"start","type":"startNode","data":{}},
+	{"id":"loop","type":"loopNode","data":{"loopCount":4}},
+	{"id":"move","type":"moveNode","data":{"distance":50}},
+	{"id":"turn","type":"rotateNode","data":{"angle":90}}
+],"edges":[
+	{"source":"start","target":"loop"},
+	{"source":"loop","sourceHandle":"loop","target":"move"},
+	{"source":"move","target":"turn"}
+]}`
+
+func TestExecute(t *testing.T) {
+	limits := Limits{MaxInstructions: 10000, Timeout: time.Second}
+
+	tests := map[string]struct {
+		data         string
+		wantSegments int
+		wantErr      error
+	}{
+		"Empty payload": {
+			data: ``,
+		},
+		"No start node": {
+			data: `{"nodes":[{"id":"move","type":"moveNode","data":{"distance":50}}],"edges":[]}`,
+		},
+		"Square": {
+			data:         square,
+			wantSegments: 4,
+		},
+		"Encoded as a string": {
+			data:         `"{\"nodes\":[{\"id\":\"s\",\"type\":\"startNode\"},{\"id\":\"m\",\"type\":\"moveNode\",\"data\":{}}],\"edges\":[{\"source\":\"s\",\"target\":\"m\"}]}"`,
+			wantSegments: 1,
+		},
+		"Branches become turtles": {
+			data: `{"nodes":[
+				{"id":"s","type":"startNode"},
+				{"id":"a","type":"moveNode","data":{"distance":10}},
+				{"id":"b","type":"moveNode","data":{"distance":20}}
+			],"edges":[{"source":"s","target":"a"},{"source":"s","target":"b"}]}`,
+			wantSegments: 2,
+		},
+		"Muted and pen up nodes don't draw": {
+			data: `{"nodes":[
+				{"id":"s","type":"startNode"},
+				{"id":"muted","type":"moveNode","data":{"distance":10,"muted":true}},
+				{"id":"up","type":"penNode","data":{"penDown":false}},
+				{"id":"m","type":"moveNode","data":{"distance":10}}
+			],"edges":[{"source":"s","target":"muted"},{"source":"muted","target":"up"},{"source":"up","target":"m"}]}`,
+		},
+		"Spawning loop": {
+			data: `{"nodes":[
+				{"id":"s","type":"startNode"},
+				{"id":"loop","type":"loopNode","data":{"loopCount":3,"createTurtleOnIteration":true}},
+				{"id":"m","type":"moveNode","data":{"distance":10}}
+			],"edges":[{"source":"s","target":"loop"},{"source":"loop","sourceHandle":"loop","target":"m"}]}`,
+			// the turtles released after one, two and three iterations
+			wantSegments: 1 + 2 + 3,
+		},
+		"Exponential loop": {
+			data: `{"nodes":[
+				{"id":"s","type":"startNode"},
+				{"id":"loop","type":"loopNode","data":{"loopCount":40}},
+				{"id":"left","type":"rotateNode","data":{"angle":45}},
+				{"id":"right","type":"rotateNode","data":{"angle":-45}}
+			],"edges":[
+				{"source":"s","target":"loop"},
+				{"source":"loop","sourceHandle":"loop","target":"left"},
+				{"source":"loop","sourceHandle":"loop","target":"right"}
+			]}`,
+			wantErr: ErrInstructionLimit,
+		},
+		"Empty loop body": {
+			data: `{"nodes":[
+				{"id":"s","type":"startNode"},
+				{"id":"loop","type":"loopNode","data":{"loopCount":1000000000}}
+			],"edges":[{"source":"s","target":"loop"}]}`,
+			wantErr: ErrInstructionLimit,
+		},
+		"Cycle": {
+			data: `{"nodes":[
+				{"id":"s","type":"startNode"},
+				{"id":"a","type":"moveNode"},
+				{"id":"b","type":"rotateNode","data":{"angle":10}}
+			],"edges":[{"source":"s","target":"a"},{"source":"a","target":"b"},{"source":"b","target":"a"}]}`,
+			wantErr: ErrInstructionLimit,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			drawing, err := Execute(context.Background(), json.RawMessage(tt.data), limits)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, drawing.Segments, tt.wantSegments)
+		})
+	}
+}
+
+func TestExecuteTimeLimit(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Execute(ctx, json.RawMessage(square), Limits{MaxInstructions: 10000})
+	assert.ErrorIs(t, err, ErrTimeLimit)
+}
+
+func TestRunUsage(t *testing.T) {
+	drawing, usage, err := Run(context.Background(), json.RawMessage(square), Limits{MaxInstructions: 10000})
+	assert.NoError(t, err)
+	assert.Len(t, drawing.Segments, 4)
+	assert.Positive(t, usage.Steps)
+
+	// the steps taken before hitting a limit are reported, a failed run isn't free
+	_, usage, err = Run(context.Background(), json.RawMessage(square), Limits{MaxInstructions: usage.Steps - 1})
+	assert.ErrorIs(t, err, ErrInstructionLimit)
+	assert.Positive(t, usage.Steps)
+
+	_, _, err = Run(context.Background(), json.RawMessage(square), Limits{MaxInstructions: 10000, MaxSegments: 3})
+	assert.ErrorIs(t, err, ErrMemoryLimit)
+}
+
+func TestExecuteFollowsEditor(t *testing.T) {
+	drawing, err := Execute(context.Background(), json.RawMessage(square), Limits{MaxInstructions: 10000})
+	assert.NoError(t, err)
+
+	// the turtle starts facing up and the editor rotates clockwise for positive angles
+	want := [][4]float64{{0, 0, 0, -50}, {0, -50, 50, -50}, {50, -50, 50, 0}, {50, 0, 0, 0}}
+	for i, s := range drawing.Segments {
+		got := [4]float64{s.X1, s.Y1, s.X2, s.Y2}
+		for j := range got {
+			assert.InDelta(t, want[i][j], got[j], 1e-9)
+		}
+		assert.Equal(t, color.RGBA{A: 255}, s.Color)
+	}
+}
+
+func TestImages(t *testing.T) {
+	drawing, err := Execute(context.Background(), json.RawMessage(`{"nodes":[
+		{"id":"s","type":"startNode"},
+		{"id":"pen","type":"penNode","data":{"penDown":true,"color":"#ff0000"}},
+		{"id":"m","type":"moveNode","data":{"distance":100}}
+	],"edges":[{"source":"s","target":"pen"},{"source":"pen","target":"m"}]}`), Limits{MaxInstructions: 100})
+	assert.NoError(t, err)
+
+	svg := string(drawing.SVG(320, 240))
+	assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="320" height="240"`))
+	assert.Contains(t, svg, `stroke="#ff0000"`)
+	// the vertical line is scaled to the height of the image minus the padding
+	assert.Contains(t, svg, `d="M160.00 232.00L160.00 8.00"`)
+
+	encoded, err := drawing.PNG(320, 240)
+	assert.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(encoded))
+	assert.NoError(t, err)
+	assert.Equal(t, 320, img.Bounds().Dx())
+
+	r, g, b, _ := img.At(160, 120).RGBA()
+	assert.Equal(t, [3]uint32{0xffff, 0, 0}, [3]uint32{r, g, b})
+	r, g, b, _ = img.At(10, 10).RGBA()
+	assert.Equal(t, [3]uint32{0xffff, 0xffff, 0xffff}, [3]uint32{r, g, b})
+}
+
+func TestParseColor(t *testing.T) {
+	assert.Equal(t, color.RGBA{R: 0x12, G: 0x34, B: 0x56, A: 255}, parseColor("#123456"))
+	assert.Equal(t, color.RGBA{R: 0xff, G: 0xff, B: 0, A: 255}, parseColor("#ff0"))
+	assert.Equal(t, color.RGBA{R: 0x12, G: 0x34, B: 0x56, A: 0x80}, parseColor("#12345680"))
+	assert.Equal(t, defaultColor, parseColor(`red"/><script>`))
+	assert.Equal(t, defaultColor, parseColor("#zzz"))
+}
@@ -0,0 +1,18 @@
+// Package requestid carries the ID of the request being served in contexts, so work the request triggers
+// outside of its handler, such as sending an email, can be traced back to it.
+package requestid
+
+import "context"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the request ID.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID carried by ctx, or "" outside of a request, e.g. in scheduled jobs.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
@@ -0,0 +1,206 @@
+// The internal API for render workers, the analytics pipeline and other services inside our network.
+// It shares the service layer with the REST API, so the same visibility rules and errors apply.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: internalpb/internal.proto
+
+package internalpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	InternalAPI_GetProject_FullMethodName      = "/nodeturtle.internal.v1.InternalAPI/GetProject"
+	InternalAPI_GetUser_FullMethodName         = "/nodeturtle.internal.v1.InternalAPI/GetUser"
+	InternalAPI_RenderThumbnail_FullMethodName = "/nodeturtle.internal.v1.InternalAPI/RenderThumbnail"
+)
+
+// InternalAPIClient is the client API for InternalAPI service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type InternalAPIClient interface {
+	// GetProject returns a project as seen by the viewer, only public projects without one.
+	GetProject(ctx context.Context, in *GetProjectRequest, opts ...grpc.CallOption) (*Project, error)
+	// GetUser looks up a user by ID, username or email.
+	GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error)
+	// RenderThumbnail renders the thumbnail of the current version of a project, unless it already was, and returns it.
+	RenderThumbnail(ctx context.Context, in *RenderThumbnailRequest, opts ...grpc.CallOption) (*Thumbnail, error)
+}
+
+type internalAPIClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewInternalAPIClient(cc grpc.ClientConnInterface) InternalAPIClient {
+	return &internalAPIClient{cc}
+}
+
+func (c *internalAPIClient) GetProject(ctx context.Context, in *GetProjectRequest, opts ...grpc.CallOption) (*Project, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Project)
+	err := c.cc.Invoke(ctx, InternalAPI_GetProject_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *internalAPIClient) GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(User)
+	err := c.cc.Invoke(ctx, InternalAPI_GetUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *internalAPIClient) RenderThumbnail(ctx context.Context, in *RenderThumbnailRequest, opts ...grpc.CallOption) (*Thumbnail, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Thumbnail)
+	err := c.cc.Invoke(ctx, InternalAPI_RenderThumbnail_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// InternalAPIServer is the server API for InternalAPI service.
+// All implementations must embed UnimplementedInternalAPIServer
+// for forward compatibility.
+type InternalAPIServer interface {
+	// GetProject returns a project as seen by the viewer, only public projects without one.
+	GetProject(context.Context, *GetProjectRequest) (*Project, error)
+	// GetUser looks up a user by ID, username or email.
+	GetUser(context.Context, *GetUserRequest) (*User, error)
+	// RenderThumbnail renders the thumbnail of the current version of a project, unless it already was, and returns it.
+	RenderThumbnail(context.Context, *RenderThumbnailRequest) (*Thumbnail, error)
+	mustEmbedUnimplementedInternalAPIServer()
+}
+
+// UnimplementedInternalAPIServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedInternalAPIServer struct{}
+
+func (UnimplementedInternalAPIServer) GetProject(context.Context, *GetProjectRequest) (*Project, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProject not implemented")
+}
+func (UnimplementedInternalAPIServer) GetUser(context.Context, *GetUserRequest) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUser not implemented")
+}
+func (UnimplementedInternalAPIServer) RenderThumbnail(context.Context, *RenderThumbnailRequest) (*Thumbnail, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenderThumbnail not implemented")
+}
+func (UnimplementedInternalAPIServer) mustEmbedUnimplementedInternalAPIServer() {}
+func (UnimplementedInternalAPIServer) testEmbeddedByValue()                     {}
+
+// UnsafeInternalAPIServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to InternalAPIServer will
+// result in compilation errors.
+type UnsafeInternalAPIServer interface {
+	mustEmbedUnimplementedInternalAPIServer()
+}
+
+func RegisterInternalAPIServer(s grpc.ServiceRegistrar, srv InternalAPIServer) {
+	// If the following call pancis, it indicates UnimplementedInternalAPIServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&InternalAPI_ServiceDesc, srv)
+}
+
+func _InternalAPI_GetProject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProjectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InternalAPIServer).GetProject(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InternalAPI_GetProject_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InternalAPIServer).GetProject(ctx, req.(*GetProjectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InternalAPI_GetUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InternalAPIServer).GetUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InternalAPI_GetUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InternalAPIServer).GetUser(ctx, req.(*GetUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InternalAPI_RenderThumbnail_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenderThumbnailRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InternalAPIServer).RenderThumbnail(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InternalAPI_RenderThumbnail_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InternalAPIServer).RenderThumbnail(ctx, req.(*RenderThumbnailRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// InternalAPI_ServiceDesc is the grpc.ServiceDesc for InternalAPI service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var InternalAPI_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "nodeturtle.internal.v1.InternalAPI",
+	HandlerType: (*InternalAPIServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetProject",
+			Handler:    _InternalAPI_GetProject_Handler,
+		},
+		{
+			MethodName: "GetUser",
+			Handler:    _InternalAPI_GetUser_Handler,
+		},
+		{
+			MethodName: "RenderThumbnail",
+			Handler:    _InternalAPI_RenderThumbnail_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "internalpb/internal.proto",
+}
@@ -0,0 +1,199 @@
+// Package rpc serves the internal gRPC API, for consumers inside our network like render workers and the analytics pipeline.
+// Large project graphs are passed through as stored instead of being decoded and encoded as JSON again.
+package rpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative internalpb/internal.proto
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/rpc/internalpb"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/projects"
+	"NodeTurtleAPI/internal/services/thumbnails"
+	"NodeTurtleAPI/internal/services/users"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Server implements the InternalAPI service over the same services as the REST API.
+type Server struct {
+	internalpb.UnimplementedInternalAPIServer
+	projectService   projects.IProjectService
+	userService      users.IUserService
+	thumbnailService thumbnails.IThumbnailService
+}
+
+// NewServer creates a gRPC server with the InternalAPI registered, only accepting calls carrying token as a bearer token.
+func NewServer(token string, projectService projects.IProjectService, userService users.IUserService, thumbnailService thumbnails.IThumbnailService) *grpc.Server {
+	server := grpc.NewServer(grpc.UnaryInterceptor(authenticate(token)))
+	internalpb.RegisterInternalAPIServer(server, &Server{
+		projectService:   projectService,
+		userService:      userService,
+		thumbnailService: thumbnailService,
+	})
+	return server
+}
+
+// authenticate rejects calls that don't carry the token in their authorization metadata.
+func authenticate(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) != 1 || subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(values[0], "Bearer ")), []byte(token)) != 1 {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (s *Server) GetProject(ctx context.Context, req *internalpb.GetProjectRequest) (*internalpb.Project, error) {
+	project, err := s.project(ctx, req.GetId(), req.GetViewerId())
+	if err != nil {
+		return nil, err
+	}
+	return projectMessage(project), nil
+}
+
+func (s *Server) GetUser(ctx context.Context, req *internalpb.GetUserRequest) (*internalpb.User, error) {
+	var user *data.User
+	var err error
+	switch lookup := req.GetLookup().(type) {
+	case *internalpb.GetUserRequest_Id:
+		userID, parseErr := uuid.Parse(lookup.Id)
+		if parseErr != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid user ID")
+		}
+		user, err = s.userService.GetUserByID(ctx, userID)
+	case *internalpb.GetUserRequest_Username:
+		user, err = s.userService.GetUserByUsername(ctx, lookup.Username)
+	case *internalpb.GetUserRequest_Email:
+		user, err = s.userService.GetUserByEmail(ctx, lookup.Email)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "an ID, username or email is required")
+	}
+	if err != nil {
+		return nil, statusError(ctx, "user retrieval", err)
+	}
+
+	return &internalpb.User{
+		Id:        user.ID.String(),
+		Username:  user.Username,
+		Email:     user.Email,
+		Role:      user.Role.Name,
+		Activated: user.IsActivated,
+		Verified:  user.Verified,
+		Guest:     user.IsGuest(),
+		Banned:    user.Ban != nil,
+		CreatedAt: timestamppb.New(user.CreatedAt),
+	}, nil
+}
+
+func (s *Server) RenderThumbnail(ctx context.Context, req *internalpb.RenderThumbnailRequest) (*internalpb.Thumbnail, error) {
+	format := data.ThumbnailFormat(req.GetFormat())
+	switch format {
+	case "":
+		format = data.ThumbnailPNG
+	case data.ThumbnailPNG, data.ThumbnailSVG:
+	default:
+		return nil, status.Error(codes.InvalidArgument, "invalid thumbnail format")
+	}
+
+	project, err := s.project(ctx, req.GetProjectId(), req.GetViewerId())
+	if err != nil {
+		return nil, err
+	}
+
+	thumbnail, err := s.thumbnailService.GetThumbnail(ctx, project, format)
+	if err != nil {
+		return nil, statusError(ctx, "thumbnail rendering", err)
+	}
+
+	return &internalpb.Thumbnail{
+		ProjectId:   thumbnail.ProjectID.String(),
+		Format:      string(thumbnail.Format),
+		ContentType: thumbnail.Format.ContentType(),
+		Content:     thumbnail.Content,
+		Version:     int32(thumbnail.Version),
+		RenderedAt:  timestamppb.New(thumbnail.RenderedAt),
+	}, nil
+}
+
+// project reads a project as the viewer, an anonymous visitor when viewerID is empty.
+func (s *Server) project(ctx context.Context, id, viewerID string) (*data.Project, error) {
+	projectID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid project ID")
+	}
+
+	var viewer *uuid.UUID
+	if viewerID != "" {
+		parsed, err := uuid.Parse(viewerID)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid viewer ID")
+		}
+		viewer = &parsed
+	}
+
+	project, err := s.projectService.GetProject(ctx, projectID, viewer)
+	if err != nil {
+		return nil, statusError(ctx, "project retrieval", err)
+	}
+	return project, nil
+}
+
+// statusError maps the errors of the services to gRPC status codes, logging the unexpected ones.
+func statusError(ctx context.Context, operation string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrRecordNotFound), errors.Is(err, services.ErrUserNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, services.ErrRenderLimit):
+		return status.Error(codes.FailedPrecondition, "RENDER_LIMIT")
+	case errors.Is(err, services.ErrRenderPending):
+		return status.Error(codes.Unavailable, "RENDER_PENDING")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	logging.FromContext(ctx).ErrorContext(ctx, "Internal gRPC error", "operation", operation, "error", err)
+	return status.Error(codes.Internal, "internal error")
+}
+
+func projectMessage(p *data.Project) *internalpb.Project {
+	message := &internalpb.Project{
+		Id:              p.ID.String(),
+		Title:           p.Title,
+		Description:     p.Description,
+		Data:            p.Data,
+		CreatorId:       p.CreatorID.String(),
+		CreatorUsername: p.CreatorUsername,
+		IsPublic:        p.IsPublic,
+		LikesCount:      int32(p.LikesCount),
+		ViewsCount:      int32(p.ViewsCount),
+		ForkCount:       int32(p.ForkCount),
+		Version:         int32(p.Version),
+		CreatedAt:       timestamppb.New(p.CreatedAt),
+		LastEditedAt:    timestamppb.New(p.LastEditedAt),
+	}
+	if p.ForkedFrom != nil {
+		message.ForkedFrom = p.ForkedFrom.String()
+	}
+	if p.ClassroomID != nil {
+		message.ClassroomId = p.ClassroomID.String()
+	}
+	if p.HiddenAt != nil {
+		message.HiddenAt = timestamppb.New(*p.HiddenAt)
+	}
+	return message
+}
@@ -0,0 +1,223 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/mocks"
+	"NodeTurtleAPI/internal/rpc/internalpb"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testToken = "0123456789abcdef0123456789abcdef"
+
+// dial serves the API over an in-memory connection and returns a client for it.
+func dial(t *testing.T, projectService *mocks.MockProjectService, userService *mocks.MockUserService, thumbnailService *mocks.MockThumbnailService) internalpb.InternalAPIClient {
+	listener := bufconn.Listen(1 << 20)
+	server := NewServer(testToken, projectService, userService, thumbnailService)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return internalpb.NewInternalAPIClient(conn)
+}
+
+func authenticated(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestAuthentication(t *testing.T) {
+	client := dial(t, &mocks.MockProjectService{}, &mocks.MockUserService{}, &mocks.MockThumbnailService{})
+
+	_, err := client.GetUser(context.Background(), &internalpb.GetUserRequest{Lookup: &internalpb.GetUserRequest_Username{Username: "alice"}})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.GetUser(authenticated("wrong"), &internalpb.GetUserRequest{Lookup: &internalpb.GetUserRequest_Username{Username: "alice"}})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestGetProject(t *testing.T) {
+	projectID, viewerID := uuid.New(), uuid.New()
+	graph := json.RawMessage(`{"nodes":[],"edges":[]}`)
+
+	tests := map[string]struct {
+		req        *internalpb.GetProjectRequest
+		setupMocks func(m *mocks.MockProjectService)
+		wantCode   codes.Code
+	}{
+		"Invalid project ID": {
+			req:      &internalpb.GetProjectRequest{Id: "abc"},
+			wantCode: codes.InvalidArgument,
+		},
+		"Invalid viewer ID": {
+			req:      &internalpb.GetProjectRequest{Id: projectID.String(), ViewerId: "abc"},
+			wantCode: codes.InvalidArgument,
+		},
+		"Project not visible": {
+			req: &internalpb.GetProjectRequest{Id: projectID.String()},
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("GetProject", projectID, (*uuid.UUID)(nil)).Return(nil, services.ErrRecordNotFound)
+			},
+			wantCode: codes.NotFound,
+		},
+		"Project read as viewer": {
+			req: &internalpb.GetProjectRequest{Id: projectID.String(), ViewerId: viewerID.String()},
+			setupMocks: func(m *mocks.MockProjectService) {
+				m.On("GetProject", projectID, &viewerID).Return(&data.Project{ID: projectID, Title: "Spiral", Data: graph, Version: 3}, nil)
+			},
+			wantCode: codes.OK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockProjectService := mocks.MockProjectService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockProjectService)
+			}
+			client := dial(t, &mockProjectService, &mocks.MockUserService{}, &mocks.MockThumbnailService{})
+
+			project, err := client.GetProject(authenticated(testToken), tt.req)
+
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				assert.Equal(t, "Spiral", project.GetTitle())
+				assert.JSONEq(t, string(graph), string(project.GetData()))
+				assert.Equal(t, int32(3), project.GetVersion())
+				assert.Nil(t, project.GetHiddenAt())
+			}
+			mockProjectService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetUser(t *testing.T) {
+	user := &data.User{ID: uuid.New(), Username: "alice", Email: "alice@example.com", Role: data.Role{Name: "user"}, IsActivated: true}
+
+	tests := map[string]struct {
+		req        *internalpb.GetUserRequest
+		setupMocks func(m *mocks.MockUserService)
+		wantCode   codes.Code
+	}{
+		"No lookup": {
+			req:      &internalpb.GetUserRequest{},
+			wantCode: codes.InvalidArgument,
+		},
+		"Invalid user ID": {
+			req:      &internalpb.GetUserRequest{Lookup: &internalpb.GetUserRequest_Id{Id: "abc"}},
+			wantCode: codes.InvalidArgument,
+		},
+		"Found by ID": {
+			req: &internalpb.GetUserRequest{Lookup: &internalpb.GetUserRequest_Id{Id: user.ID.String()}},
+			setupMocks: func(m *mocks.MockUserService) {
+				m.On("GetUserByID", user.ID).Return(user, nil)
+			},
+			wantCode: codes.OK,
+		},
+		"Found by email": {
+			req: &internalpb.GetUserRequest{Lookup: &internalpb.GetUserRequest_Email{Email: user.Email}},
+			setupMocks: func(m *mocks.MockUserService) {
+				m.On("GetUserByEmail", user.Email).Return(user, nil)
+			},
+			wantCode: codes.OK,
+		},
+		"User not found": {
+			req: &internalpb.GetUserRequest{Lookup: &internalpb.GetUserRequest_Username{Username: "nobody"}},
+			setupMocks: func(m *mocks.MockUserService) {
+				m.On("GetUserByUsername", "nobody").Return(nil, services.ErrUserNotFound)
+			},
+			wantCode: codes.NotFound,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockUserService := mocks.MockUserService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockUserService)
+			}
+			client := dial(t, &mocks.MockProjectService{}, &mockUserService, &mocks.MockThumbnailService{})
+
+			got, err := client.GetUser(authenticated(testToken), tt.req)
+
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				assert.Equal(t, user.Username, got.GetUsername())
+				assert.Equal(t, "user", got.GetRole())
+				assert.False(t, got.GetBanned())
+			}
+			mockUserService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRenderThumbnail(t *testing.T) {
+	project := &data.Project{ID: uuid.New(), Version: 2}
+
+	tests := map[string]struct {
+		req        *internalpb.RenderThumbnailRequest
+		setupMocks func(p *mocks.MockProjectService, th *mocks.MockThumbnailService)
+		wantCode   codes.Code
+	}{
+		"Invalid format": {
+			req:      &internalpb.RenderThumbnailRequest{ProjectId: project.ID.String(), Format: "gif"},
+			wantCode: codes.InvalidArgument,
+		},
+		"Program exceeds the limits": {
+			req: &internalpb.RenderThumbnailRequest{ProjectId: project.ID.String()},
+			setupMocks: func(p *mocks.MockProjectService, th *mocks.MockThumbnailService) {
+				p.On("GetProject", project.ID, (*uuid.UUID)(nil)).Return(project, nil)
+				th.On("GetThumbnail", project, data.ThumbnailPNG).Return(nil, services.ErrRenderLimit)
+			},
+			wantCode: codes.FailedPrecondition,
+		},
+		"Thumbnail rendered": {
+			req: &internalpb.RenderThumbnailRequest{ProjectId: project.ID.String(), Format: "svg"},
+			setupMocks: func(p *mocks.MockProjectService, th *mocks.MockThumbnailService) {
+				p.On("GetProject", project.ID, (*uuid.UUID)(nil)).Return(project, nil)
+				th.On("GetThumbnail", project, data.ThumbnailSVG).Return(&data.Thumbnail{ProjectID: project.ID, Format: data.ThumbnailSVG, Version: 2, Content: []byte("<svg/>")}, nil)
+			},
+			wantCode: codes.OK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockProjectService, mockThumbnailService := mocks.MockProjectService{}, mocks.MockThumbnailService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(&mockProjectService, &mockThumbnailService)
+			}
+			client := dial(t, &mockProjectService, &mocks.MockUserService{}, &mockThumbnailService)
+
+			thumbnail, err := client.RenderThumbnail(authenticated(testToken), tt.req)
+
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				assert.Equal(t, "image/svg+xml", thumbnail.GetContentType())
+				assert.Equal(t, "<svg/>", string(thumbnail.GetContent()))
+				assert.Equal(t, int32(2), thumbnail.GetVersion())
+			}
+			mockProjectService.AssertExpectations(t)
+			mockThumbnailService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,209 @@
+// Package runner runs the background tasks of the task queue inside the API process, see tasks.TaskService.
+// Every instance of the API runs tasks, claiming them from the queue in the database, so each task runs on
+// one instance at a time and tasks queued by an instance that went down are run by the others.
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/requestid"
+)
+
+// Queue is the task queue as the runner uses it, implemented by tasks.TaskService.
+type Queue interface {
+	Claim(ctx context.Context) (*data.Task, error)
+	Complete(ctx context.Context, task *data.Task) error
+	Fail(ctx context.Context, task *data.Task, taskErr error, backoff time.Duration, maxAttempts int) (bool, error)
+	Release(ctx context.Context, task *data.Task) error
+	RequeueStale(ctx context.Context, timeout time.Duration, maxAttempts int) (int, error)
+}
+
+// HandlerFunc does the work of a task from its payload. An error has the task retried later.
+type HandlerFunc func(ctx context.Context, payload json.RawMessage) error
+
+// Runner runs the tasks of the queue with the handlers of their kind, as many at once as its concurrency.
+type Runner struct {
+	cfg   config.TasksConfig
+	queue Queue
+
+	mu       sync.Mutex
+	handlers map[data.TaskKind]HandlerFunc
+	skip     func() bool
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// New creates a Runner of the queue without handlers.
+func New(cfg config.TasksConfig, queue Queue) *Runner {
+	return &Runner{
+		cfg:      cfg,
+		queue:    queue,
+		handlers: map[data.TaskKind]HandlerFunc{},
+	}
+}
+
+// Handle registers the handler of the tasks of a kind. Handlers must be registered before Start.
+func (r *Runner) Handle(kind data.TaskKind, fn HandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.handlers[kind] = fn
+}
+
+// SkipWhile makes the runner leave the queue alone while cond reports true, e.g. while the database can't be written to.
+func (r *Runner) SkipWhile(cond func() bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.skip = cond
+}
+
+// Start launches the goroutines running tasks, unless the concurrency of the config is 0.
+// Calling Start on a running runner is a no-op.
+func (r *Runner) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cancel != nil || r.cfg.Concurrency <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.requeue(ctx)
+	}()
+	for i := 0; i < r.cfg.Concurrency; i++ {
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			r.work(ctx)
+		}()
+	}
+}
+
+// Stop stops claiming tasks and waits for the running ones to return. Tasks they were stopped in the middle of
+// are put back in the queue.
+func (r *Runner) Stop() {
+	r.mu.Lock()
+	cancel := r.cancel
+	r.cancel = nil
+	r.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	r.wg.Wait()
+}
+
+// requeue puts the tasks of runners that stopped responding back in the queue every task timeout.
+func (r *Runner) requeue(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Timeout)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if r.skipping() {
+			continue
+		}
+		requeued, err := r.queue.RequeueStale(ctx, 2*r.cfg.Timeout, r.cfg.MaxAttempts)
+		if err != nil {
+			logging.Error(ctx, "Requeueing stale tasks failed", err)
+		} else if requeued > 0 {
+			logging.FromContext(ctx).WarnContext(ctx, "Requeued stale tasks", "count", requeued)
+		}
+	}
+}
+
+// work claims and runs tasks one at a time, waiting a poll interval whenever no task is due.
+func (r *Runner) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		var task *data.Task
+		if !r.skipping() {
+			var err error
+			task, err = r.queue.Claim(ctx)
+			if err != nil && ctx.Err() == nil {
+				logging.Error(ctx, "Claiming a task failed", err)
+			}
+		}
+		if task == nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(r.cfg.Poll):
+			}
+			continue
+		}
+
+		r.run(ctx, task)
+	}
+}
+
+func (r *Runner) run(ctx context.Context, task *data.Task) {
+	r.mu.Lock()
+	handler, ok := r.handlers[task.Kind]
+	r.mu.Unlock()
+
+	// what the task does is logged and recorded with the request that queued it
+	ctx = requestid.NewContext(ctx, task.RequestID)
+	taskCtx, cancel := context.WithTimeout(ctx, r.cfg.Timeout)
+	err := fmt.Errorf("task: no handler for kind %s", task.Kind)
+	if ok {
+		err = handler(taskCtx, task.Payload)
+	}
+	cancel()
+
+	// tasks are finished even when the runner is stopping, which leaves it a few seconds at most
+	finish, cancelFinish := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancelFinish()
+
+	logger := logging.FromContext(ctx).With("task_id", task.ID, "kind", task.Kind, "attempts", task.Attempts)
+	switch {
+	case err == nil:
+		err = r.queue.Complete(finish, task)
+	case ctx.Err() != nil:
+		err = r.queue.Release(finish, task)
+	default:
+		logger.ErrorContext(ctx, "Task failed", "error", err)
+		var dead bool
+		dead, err = r.queue.Fail(finish, task, err, r.backoff(task.Attempts), r.cfg.MaxAttempts)
+		if dead {
+			logger.ErrorContext(ctx, "Task ran out of attempts and is dead")
+		}
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Finishing task failed", "error", err)
+	}
+}
+
+// backoff is the wait before retrying a task that failed its attempts so far, doubling on every attempt.
+func (r *Runner) backoff(attempts int) time.Duration {
+	backoff := r.cfg.Backoff
+	for i := 1; i < attempts && backoff < r.cfg.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, r.cfg.MaxBackoff)
+}
+
+func (r *Runner) skipping() bool {
+	r.mu.Lock()
+	skip := r.skip
+	r.mu.Unlock()
+
+	return skip != nil && skip()
+}
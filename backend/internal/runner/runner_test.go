@@ -0,0 +1,163 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/requestid"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryQueue is a task queue in memory, recording what the runner did with its tasks.
+// Failed tasks that aren't dead are due again right away, whatever their backoff.
+type memoryQueue struct {
+	mu        sync.Mutex
+	pending   []*data.Task
+	completed []int64
+	dead      []int64
+	backoffs  []time.Duration
+}
+
+func (q *memoryQueue) Claim(ctx context.Context) (*data.Task, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) == 0 {
+		return nil, nil
+	}
+	task := q.pending[0]
+	q.pending = q.pending[1:]
+	task.Attempts++
+	return task, nil
+}
+
+func (q *memoryQueue) Complete(ctx context.Context, task *data.Task) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.completed = append(q.completed, task.ID)
+	return nil
+}
+
+func (q *memoryQueue) Fail(ctx context.Context, task *data.Task, taskErr error, backoff time.Duration, maxAttempts int) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	task.LastError = taskErr.Error()
+	if task.Attempts >= maxAttempts {
+		q.dead = append(q.dead, task.ID)
+		return true, nil
+	}
+	q.backoffs = append(q.backoffs, backoff)
+	q.pending = append(q.pending, task)
+	return false, nil
+}
+
+func (q *memoryQueue) Release(ctx context.Context, task *data.Task) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	task.Attempts--
+	q.pending = append(q.pending, task)
+	return nil
+}
+
+func (q *memoryQueue) RequeueStale(ctx context.Context, timeout time.Duration, maxAttempts int) (int, error) {
+	return 0, nil
+}
+
+func (q *memoryQueue) finished() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.completed) + len(q.dead)
+}
+
+func TestRunner(t *testing.T) {
+	queue := &memoryQueue{pending: []*data.Task{
+		{ID: 1, Kind: data.TaskEmail, Payload: json.RawMessage(`{"to":"alice@example.com"}`), RequestID: "req-1"},
+		{ID: 2, Kind: data.TaskEmail, Payload: json.RawMessage(`{"to":"bounce@example.com"}`)},
+		{ID: 3, Kind: data.TaskEmail, Payload: json.RawMessage(`{"to":"flaky@example.com"}`)},
+		{ID: 4, Kind: "meteor_strike", Payload: json.RawMessage(`{}`)},
+	}}
+	cfg := config.TasksConfig{
+		Concurrency: 2,
+		Poll:        10 * time.Millisecond,
+		Timeout:     time.Second,
+		MaxAttempts: 3,
+		Backoff:     30 * time.Second,
+		MaxBackoff:  time.Hour,
+	}
+
+	var mu sync.Mutex
+	requestIDs := map[string]string{}
+	flaky := 0
+	r := New(cfg, queue)
+	r.Handle(data.TaskEmail, func(ctx context.Context, payload json.RawMessage) error {
+		var email data.EmailTask
+		if err := json.Unmarshal(payload, &email); err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		requestIDs[email.To] = requestid.FromContext(ctx)
+		switch email.To {
+		case "bounce@example.com":
+			return errors.New("smtp: mailbox unavailable")
+		case "flaky@example.com":
+			if flaky++; flaky == 1 {
+				return errors.New("smtp: connection reset")
+			}
+		}
+		return nil
+	})
+
+	r.Start()
+	assert.Eventually(t, func() bool { return queue.finished() == 4 }, 5*time.Second, 10*time.Millisecond)
+	r.Stop()
+
+	assert.ElementsMatch(t, []int64{1, 3}, queue.completed)
+	// tasks without a handler fail like any other
+	assert.ElementsMatch(t, []int64{2, 4}, queue.dead)
+	assert.Equal(t, "req-1", requestIDs["alice@example.com"])
+	assert.Contains(t, queue.backoffs, time.Minute, "the second retry waits twice as long as the first")
+}
+
+func TestRunnerSkipWhile(t *testing.T) {
+	queue := &memoryQueue{pending: []*data.Task{{ID: 1, Kind: data.TaskEmail}}}
+	cfg := config.TasksConfig{Concurrency: 1, Poll: 10 * time.Millisecond, Timeout: time.Second, MaxAttempts: 1, Backoff: time.Second, MaxBackoff: time.Second}
+
+	r := New(cfg, queue)
+	r.Handle(data.TaskEmail, func(ctx context.Context, payload json.RawMessage) error { return nil })
+	readOnly := true
+	var mu sync.Mutex
+	r.SkipWhile(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return readOnly
+	})
+
+	r.Start()
+	defer r.Stop()
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 0, queue.finished(), "tasks wait while the database is read-only")
+
+	mu.Lock()
+	readOnly = false
+	mu.Unlock()
+	assert.Eventually(t, func() bool { return queue.finished() == 1 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestRunnerBackoff(t *testing.T) {
+	r := New(config.TasksConfig{Backoff: 30 * time.Second, MaxBackoff: 10 * time.Minute}, &memoryQueue{})
+
+	assert.Equal(t, 30*time.Second, r.backoff(1))
+	assert.Equal(t, time.Minute, r.backoff(2))
+	assert.Equal(t, 8*time.Minute, r.backoff(5))
+	assert.Equal(t, 10*time.Minute, r.backoff(6))
+	assert.Equal(t, 10*time.Minute, r.backoff(50))
+}
@@ -0,0 +1,115 @@
+// Package scheduler runs periodic background jobs inside the API process.
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"NodeTurtleAPI/internal/clock"
+	"NodeTurtleAPI/internal/logging"
+)
+
+// JobFunc is a unit of periodic work. The context is cancelled when the scheduler stops.
+type JobFunc func(ctx context.Context) error
+
+type job struct {
+	name     string
+	interval time.Duration
+	fn       JobFunc
+}
+
+// Scheduler runs registered jobs on fixed intervals until stopped.
+type Scheduler struct {
+	mu      sync.Mutex
+	jobs    []job
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running bool
+	skip    func() bool
+	clock   clock.Clock
+}
+
+// New creates an empty Scheduler whose jobs run on the intervals of the clock.
+func New(clk clock.Clock) *Scheduler {
+	return &Scheduler{clock: clk}
+}
+
+// Every registers a job which runs every interval once the scheduler is started.
+// Jobs registered after Start are ignored until the next Start.
+func (s *Scheduler) Every(name string, interval time.Duration, fn JobFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs = append(s.jobs, job{name: name, interval: interval, fn: fn})
+}
+
+// SkipWhile makes jobs skip their runs while cond reports true, e.g. while the database can't be written to.
+func (s *Scheduler) SkipWhile(cond func() bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.skip = cond
+}
+
+// Start launches a goroutine per registered job. Calling Start on a running scheduler is a no-op.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.running = true
+
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.run(ctx, j)
+	}
+}
+
+// Stop cancels all jobs and waits for running ones to return.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.cancel()
+	s.running = false
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+func (s *Scheduler) run(ctx context.Context, j job) {
+	defer s.wg.Done()
+
+	ticker := s.clock.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			if s.skipping() {
+				continue
+			}
+			if err := j.fn(ctx); err != nil {
+				logging.FromContext(ctx).ErrorContext(ctx, "Scheduled job failed", "job", j.name, "error", err)
+			}
+		}
+	}
+}
+
+func (s *Scheduler) skipping() bool {
+	s.mu.Lock()
+	skip := s.skip
+	s.mu.Unlock()
+
+	return skip != nil && skip()
+}
@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/clock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduler_RunsJobsUntilStopped(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	s := New(clk)
+
+	var runs atomic.Int32
+	s.Every("counter", time.Minute, func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+	s.Every("failing", time.Minute, func(ctx context.Context) error {
+		return errors.New("job failed")
+	})
+
+	s.Start()
+	s.Start() // starting twice must not duplicate jobs
+	assert.Eventually(t, func() bool { return clk.Tickers() == 2 }, time.Second, time.Millisecond)
+
+	// nothing runs before the first interval passed
+	clk.Advance(59 * time.Second)
+	assert.Never(t, func() bool { return runs.Load() > 0 }, 20*time.Millisecond, time.Millisecond)
+
+	for i := int32(1); i <= 3; i++ {
+		clk.Advance(time.Minute)
+		assert.Eventually(t, func() bool { return runs.Load() == i }, time.Second, time.Millisecond)
+	}
+	s.Stop()
+	assert.Equal(t, 0, clk.Tickers())
+
+	clk.Advance(time.Hour)
+	assert.Equal(t, int32(3), runs.Load())
+}
+
+func TestScheduler_StopCancelsContext(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	s := New(clk)
+
+	cancelled := make(chan struct{})
+	s.Every("blocking", time.Minute, func(ctx context.Context) error {
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	})
+
+	s.Start()
+	assert.Eventually(t, func() bool { return clk.Tickers() == 1 }, time.Second, time.Millisecond)
+	clk.Advance(time.Minute)
+	s.Stop()
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("job context was not cancelled")
+	}
+
+	s.Stop() // stopping twice is a no-op
+}
+
+func TestScheduler_SkipWhile(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	s := New(clk)
+
+	var runs atomic.Int32
+	var paused atomic.Bool
+	paused.Store(true)
+	skipped := make(chan struct{}, 1)
+	s.Every("counter", time.Minute, func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+	s.SkipWhile(func() bool {
+		if paused.Load() {
+			skipped <- struct{}{}
+			return true
+		}
+		return false
+	})
+
+	s.Start()
+	defer s.Stop()
+	assert.Eventually(t, func() bool { return clk.Tickers() == 1 }, time.Second, time.Millisecond)
+
+	clk.Advance(time.Minute)
+	select {
+	case <-skipped:
+	case <-time.After(time.Second):
+		t.Fatal("run was not skipped")
+	}
+	assert.Equal(t, int32(0), runs.Load())
+
+	paused.Store(false)
+	clk.Advance(time.Minute)
+	assert.Eventually(t, func() bool { return runs.Load() == 1 }, time.Second, time.Millisecond)
+}
@@ -0,0 +1,115 @@
+// Package annotations keeps the internal notes and labels staff attach to users and projects.
+package annotations
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// IAnnotationService defines the interface for annotation operations.
+type IAnnotationService interface {
+	GetUserAnnotation(userID uuid.UUID) (*data.Annotation, error)
+	SetUserAnnotation(userID uuid.UUID, update data.AnnotationUpdate, updatedBy uuid.UUID) (*data.Annotation, error)
+	GetProjectAnnotation(projectID uuid.UUID) (*data.Annotation, error)
+	SetProjectAnnotation(projectID uuid.UUID, update data.AnnotationUpdate, updatedBy uuid.UUID) (*data.Annotation, error)
+}
+
+// AnnotationService implements the IAnnotationService interface.
+type AnnotationService struct {
+	db *sql.DB
+}
+
+// NewAnnotationService creates a new AnnotationService with the provided database connection.
+func NewAnnotationService(db *sql.DB) AnnotationService {
+	return AnnotationService{
+		db: db,
+	}
+}
+
+// GetUserAnnotation retrieves the annotation of the user, empty if staff haven't annotated the user yet.
+func (s AnnotationService) GetUserAnnotation(userID uuid.UUID) (*data.Annotation, error) {
+	return s.get("user_annotations", "user_id", userID)
+}
+
+// SetUserAnnotation replaces the notes and labels of the user.
+// It returns ErrRecordNotFound if the user doesn't exist.
+func (s AnnotationService) SetUserAnnotation(userID uuid.UUID, update data.AnnotationUpdate, updatedBy uuid.UUID) (*data.Annotation, error) {
+	return s.set("user_annotations", "user_id", userID, update, updatedBy)
+}
+
+// GetProjectAnnotation retrieves the annotation of the project, empty if staff haven't annotated the project yet.
+func (s AnnotationService) GetProjectAnnotation(projectID uuid.UUID) (*data.Annotation, error) {
+	return s.get("project_annotations", "project_id", projectID)
+}
+
+// SetProjectAnnotation replaces the notes and labels of the project.
+// It returns ErrRecordNotFound if the project doesn't exist.
+func (s AnnotationService) SetProjectAnnotation(projectID uuid.UUID, update data.AnnotationUpdate, updatedBy uuid.UUID) (*data.Annotation, error) {
+	return s.set("project_annotations", "project_id", projectID, update, updatedBy)
+}
+
+// get and set are shared by the user and project annotation tables, which only differ in their key column.
+func (s AnnotationService) get(table, column string, id uuid.UUID) (*data.Annotation, error) {
+	var a data.Annotation
+	var labels pq.StringArray
+
+	query := fmt.Sprintf("SELECT labels, notes, updated_by, updated_at FROM %s WHERE %s = $1", table, column)
+	err := s.db.QueryRow(query, id).Scan(&labels, &a.Notes, &a.UpdatedBy, &a.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return &data.Annotation{Labels: []string{}}, nil
+		}
+		return nil, err
+	}
+
+	a.Labels = []string(labels)
+	return &a, nil
+}
+
+func (s AnnotationService) set(table, column string, id uuid.UUID, update data.AnnotationUpdate, updatedBy uuid.UUID) (*data.Annotation, error) {
+	var a data.Annotation
+	var labels pq.StringArray
+
+	query := fmt.Sprintf(`
+		INSERT INTO %[1]s (%[2]s, labels, notes, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (%[2]s) DO UPDATE SET labels = EXCLUDED.labels, notes = EXCLUDED.notes, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+		RETURNING labels, notes, updated_by, updated_at`, table, column)
+
+	err := s.db.QueryRow(query, id, pq.Array(NormalizeLabels(update.Labels)), strings.TrimSpace(update.Notes), updatedBy).
+		Scan(&labels, &a.Notes, &a.UpdatedBy, &a.UpdatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return nil, services.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	a.Labels = []string(labels)
+	return &a, nil
+}
+
+// NormalizeLabels lowercases and trims labels, drops empty and duplicate ones and sorts them,
+// so "Verified Educator" and "verified educator " are the same label when filtering.
+func NormalizeLabels(labels []string) []string {
+	seen := map[string]bool{}
+	normalized := make([]string, 0, len(labels))
+	for _, l := range labels {
+		l = strings.ToLower(strings.Join(strings.Fields(l), " "))
+		if l == "" || seen[l] {
+			continue
+		}
+		seen[l] = true
+		normalized = append(normalized, l)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
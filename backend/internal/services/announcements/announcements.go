@@ -0,0 +1,132 @@
+// Package announcements provides the banners shown to every user, cached in memory as every page load reads them.
+package announcements
+
+import (
+	"database/sql"
+	"time"
+
+	"NodeTurtleAPI/internal/cache"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+)
+
+// cacheName identifies the announcements cache, e.g. in the cache metrics.
+const cacheName = "announcements"
+
+// IAnnouncementService defines the interface for announcement operations.
+type IAnnouncementService interface {
+	GetActive() ([]data.Announcement, error)
+	Create(announcement data.AnnouncementCreate, createdBy uuid.UUID) (*data.Announcement, error)
+	Delete(id int64) error
+}
+
+// AnnouncementService implements the IAnnouncementService interface.
+type AnnouncementService struct {
+	db            *sql.DB
+	caches        *cache.Registry
+	announcements *cache.Value[[]data.Announcement]
+}
+
+// NewAnnouncementService creates a new AnnouncementService with the provided database connection,
+// caching the announcements in caches.
+func NewAnnouncementService(db *sql.DB, caches *cache.Registry) AnnouncementService {
+	s := AnnouncementService{
+		db:     db,
+		caches: caches,
+	}
+	s.announcements = cache.New(caches, cacheName, s.loadAnnouncements)
+
+	return s
+}
+
+// GetActive retrieves the announcements shown right now, the most severe first.
+func (s AnnouncementService) GetActive() ([]data.Announcement, error) {
+	all, err := s.announcements.Get()
+	if err != nil {
+		return []data.Announcement{}, err
+	}
+
+	// the cache holds scheduled announcements too, so they appear on time without an invalidation
+	now := time.Now()
+	active := make([]data.Announcement, 0, len(all))
+	for _, a := range all {
+		if a.StartsAt.After(now) || (a.EndsAt != nil && !a.EndsAt.After(now)) {
+			continue
+		}
+		active = append(active, a)
+	}
+
+	return active, nil
+}
+
+// Create publishes an announcement.
+func (s AnnouncementService) Create(announcement data.AnnouncementCreate, createdBy uuid.UUID) (*data.Announcement, error) {
+	level := announcement.Level
+	if level == "" {
+		level = data.AnnouncementInfo
+	}
+
+	query := `
+		INSERT INTO announcements (message, level, starts_at, ends_at, created_by)
+		VALUES ($1, $2, COALESCE($3, NOW()), $4, $5)
+		RETURNING id, message, level, starts_at, ends_at, created_at`
+
+	var a data.Announcement
+	err := s.db.QueryRow(query, announcement.Message, level, announcement.StartsAt, announcement.EndsAt, createdBy).Scan(
+		&a.ID, &a.Message, &a.Level, &a.StartsAt, &a.EndsAt, &a.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.caches.Invalidate(cacheName); err != nil {
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+// Delete removes an announcement.
+// It returns ErrRecordNotFound if the announcement doesn't exist.
+func (s AnnouncementService) Delete(id int64) error {
+	result, err := s.db.Exec("DELETE FROM announcements WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return services.ErrRecordNotFound
+	}
+
+	return s.caches.Invalidate(cacheName)
+}
+
+// loadAnnouncements loads the announcements that haven't ended yet, including scheduled ones.
+func (s AnnouncementService) loadAnnouncements() ([]data.Announcement, error) {
+	query := `
+		SELECT id, message, level, starts_at, ends_at, created_at
+		FROM announcements
+		WHERE ends_at IS NULL OR ends_at > NOW()
+		ORDER BY CASE level WHEN 'critical' THEN 0 WHEN 'warning' THEN 1 ELSE 2 END, starts_at DESC`
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	announcements := make([]data.Announcement, 0)
+	for rows.Next() {
+		var a data.Announcement
+		if err := rows.Scan(&a.ID, &a.Message, &a.Level, &a.StartsAt, &a.EndsAt, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		announcements = append(announcements, a)
+	}
+
+	return announcements, rows.Err()
+}
@@ -0,0 +1,234 @@
+// Package anomalies flags unusual traffic, such as a spike in registrations, a surge of likes from one network or
+// of server errors on one route, as alerts for admins, so incidents are noticed before users complain about them.
+package anomalies
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"NodeTurtleAPI/internal/clock"
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/httpclient"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/mail"
+	"NodeTurtleAPI/internal/services/webhooks"
+
+	"github.com/google/uuid"
+)
+
+// IAnomalyService defines the interface for anomaly alert operations.
+type IAnomalyService interface {
+	Analyze(ctx context.Context) ([]data.Alert, error)
+	ListAlerts(ctx context.Context, filter data.AlertFilter) ([]data.Alert, error)
+	AcknowledgeAlert(ctx context.Context, id int64, userID uuid.UUID) (*data.Alert, error)
+}
+
+// AnomalyService implements the IAnomalyService interface.
+type AnomalyService struct {
+	db          *sql.DB
+	cfg         config.AnomaliesConfig
+	counters    *Counters
+	mailService mail.IMailService
+	client      *http.Client
+	clock       clock.Clock
+}
+
+// NewAnomalyService creates a new AnomalyService analyzing the traffic of the counters and the registrations
+// in the database, and notifying the webhook and addresses of the config about new alerts.
+func NewAnomalyService(db *sql.DB, cfg config.AnomaliesConfig, counters *Counters, mailService mail.IMailService, clk clock.Clock) AnomalyService {
+	// the webhook is set up by operators, a retried delivery is identified by the ID of the alert
+	client := httpclient.New(httpclient.Options{
+		Timeout:        30 * time.Second,
+		AttemptTimeout: 10 * time.Second,
+		MaxAttempts:    3,
+		RetryUnsafe:    true,
+	})
+
+	return AnomalyService{
+		db:          db,
+		cfg:         cfg,
+		counters:    counters,
+		mailService: mailService,
+		client:      client,
+		clock:       clk,
+	}
+}
+
+const alertColumns = "id, kind, subject, observed, threshold, window_start, window_end, created_at, acknowledged_at, acknowledged_by"
+
+// Analyze checks the windows of traffic that ended since the last analysis and raises an alert for every anomaly
+// found, notifying the webhook and the addresses of the config. Anomalies raised already, e.g. by another instance
+// of the API, aren't raised again. It returns the alerts raised.
+func (s AnomalyService) Analyze(ctx context.Context) ([]data.Alert, error) {
+	interval := time.Duration(s.cfg.Interval) * time.Minute
+	now := s.clock.Now()
+
+	var found []data.Alert
+	for start, w := range s.counters.ended(now) {
+		for asn, likes := range w.likes {
+			if likes >= s.cfg.LikesPerASN {
+				found = append(found, anomaly(data.AlertLikesSurge, asn, likes, float64(s.cfg.LikesPerASN), start, interval))
+			}
+		}
+		for route, failed := range w.errors {
+			if failed >= s.cfg.RouteErrors {
+				found = append(found, anomaly(data.AlertServerErrors, route, failed, float64(s.cfg.RouteErrors), start, interval))
+			}
+		}
+	}
+
+	spike, err := s.registrationSpike(ctx, now.Truncate(interval).Add(-interval), interval)
+	if err != nil {
+		return nil, err
+	}
+	if spike != nil {
+		found = append(found, *spike)
+	}
+
+	raised := []data.Alert{}
+	for _, alert := range found {
+		err := s.db.QueryRowContext(ctx, `
+			INSERT INTO admin_alerts (kind, subject, observed, threshold, window_start, window_end)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (kind, subject, window_start) DO NOTHING
+			RETURNING id, created_at`,
+			alert.Kind, alert.Subject, alert.Observed, alert.Threshold, alert.WindowStart, alert.WindowEnd,
+		).Scan(&alert.ID, &alert.CreatedAt)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return raised, err
+		}
+
+		s.notify(ctx, alert)
+		raised = append(raised, alert)
+	}
+
+	return raised, nil
+}
+
+// registrationSpike compares the registrations of the window starting at start with those of an average window
+// of the day before it. Guests don't count, they haven't registered.
+func (s AnomalyService) registrationSpike(ctx context.Context, start time.Time, interval time.Duration) (*data.Alert, error) {
+	var observed, lastDay int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE created_at >= $1),
+			COUNT(*) FILTER (WHERE created_at < $1)
+		FROM users
+		WHERE created_at >= $1::timestamptz - INTERVAL '1 day' AND created_at < $2 AND guest_expires_at IS NULL`,
+		start, start.Add(interval),
+	).Scan(&observed, &lastDay)
+	if err != nil {
+		return nil, err
+	}
+
+	usual := float64(lastDay) / (24 * float64(time.Hour) / float64(interval))
+	threshold := math.Max(float64(s.cfg.MinRegistrations), usual*float64(s.cfg.RegistrationFactor))
+	if float64(observed) < threshold {
+		return nil, nil
+	}
+
+	alert := anomaly(data.AlertRegistrationSpike, "", observed, math.Round(threshold*100)/100, start, interval)
+	return &alert, nil
+}
+
+func anomaly(kind data.AlertKind, subject string, observed int, threshold float64, start time.Time, interval time.Duration) data.Alert {
+	return data.Alert{
+		Kind:        kind,
+		Subject:     subject,
+		Observed:    observed,
+		Threshold:   threshold,
+		WindowStart: start,
+		WindowEnd:   start.Add(interval),
+	}
+}
+
+// notify posts the alert to the webhook and emails it to the addresses of the config.
+// Failed notifications are logged, the alert is listed all the same.
+func (s AnomalyService) notify(ctx context.Context, alert data.Alert) {
+	if s.cfg.WebhookURL != "" {
+		event := data.AlertEvent{Event: data.WebhookEventAlert, Alert: alert}
+		if _, err := webhooks.Post(ctx, s.client, s.cfg.WebhookURL, s.cfg.WebhookSecret, event.Event, event); err != nil {
+			logging.FromContext(ctx).ErrorContext(ctx, "Failed to post alert to webhook", "alert_id", alert.ID, "error", err)
+		}
+	}
+
+	for _, to := range s.cfg.Emails {
+		fields := map[string]string{
+			"Kind":      string(alert.Kind),
+			"Subject":   alert.Subject,
+			"Observed":  strconv.Itoa(alert.Observed),
+			"Threshold": strconv.FormatFloat(alert.Threshold, 'f', -1, 64),
+			"Window":    fmt.Sprintf("%s to %s", alert.WindowStart.Format("January 2, 2006 at 15:04"), alert.WindowEnd.Format("15:04 UTC")),
+		}
+		if err := s.mailService.SendEmail(ctx, to, "Anomaly detected: "+string(alert.Kind), "anomaly_alert", fields); err != nil {
+			logging.FromContext(ctx).ErrorContext(ctx, "Failed to email alert", "alert_id", alert.ID, "to", to, "error", err)
+		}
+	}
+}
+
+// ListAlerts retrieves the alerts matching the filter, newest first.
+func (s AnomalyService) ListAlerts(ctx context.Context, filter data.AlertFilter) ([]data.Alert, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+alertColumns+`
+		FROM admin_alerts
+		WHERE ($1 = '' OR kind = $1) AND (NOT $2::boolean OR acknowledged_at IS NULL)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3`,
+		filter.Kind, filter.Open, filter.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts := []data.Alert{}
+	for rows.Next() {
+		alert, err := scanAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, *alert)
+	}
+	return alerts, rows.Err()
+}
+
+// AcknowledgeAlert marks the alert as looked into by the user. Acknowledging it again keeps the first acknowledgement.
+// It returns ErrRecordNotFound if the alert doesn't exist.
+func (s AnomalyService) AcknowledgeAlert(ctx context.Context, id int64, userID uuid.UUID) (*data.Alert, error) {
+	alert, err := scanAlert(s.db.QueryRowContext(ctx, `
+		UPDATE admin_alerts
+		SET acknowledged_at = COALESCE(acknowledged_at, NOW()),
+			acknowledged_by = CASE WHEN acknowledged_at IS NULL THEN $2 ELSE acknowledged_by END
+		WHERE id = $1
+		RETURNING `+alertColumns,
+		id, userID,
+	))
+	if err == sql.ErrNoRows {
+		return nil, services.ErrRecordNotFound
+	}
+	return alert, err
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAlert(row scanner) (*data.Alert, error) {
+	var alert data.Alert
+	err := row.Scan(&alert.ID, &alert.Kind, &alert.Subject, &alert.Observed, &alert.Threshold,
+		&alert.WindowStart, &alert.WindowEnd, &alert.CreatedAt, &alert.AcknowledgedAt, &alert.AcknowledgedBy)
+	if err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
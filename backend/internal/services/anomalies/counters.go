@@ -0,0 +1,102 @@
+package anomalies
+
+import (
+	"sync"
+	"time"
+
+	"NodeTurtleAPI/internal/clock"
+)
+
+// maxSubjectLength bounds the networks and routes counted, the ASN header can't be trusted without a proxy setting it.
+const maxSubjectLength = 64
+
+// Counters counts the traffic the analyzer can't read from the database, in windows of the analysis interval.
+// Every instance of the API counts the requests it serves.
+type Counters struct {
+	interval time.Duration
+	clock    clock.Clock
+
+	mu      sync.Mutex
+	windows map[time.Time]*window
+}
+
+// window holds the counts of a window of traffic by network and route.
+type window struct {
+	likes  map[string]int
+	errors map[string]int
+}
+
+// NewCounters creates Counters with windows of the interval, started by the time of the clock.
+func NewCounters(interval time.Duration, clk clock.Clock) *Counters {
+	return &Counters{
+		interval: interval,
+		clock:    clk,
+		windows:  map[time.Time]*window{},
+	}
+}
+
+// RecordLike counts a like from a client of the network.
+func (c *Counters) RecordLike(asn string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.current().likes[truncate(asn)]++
+}
+
+// RecordServerError counts a request of the route answered with a server error.
+func (c *Counters) RecordServerError(route string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.current().errors[truncate(route)]++
+}
+
+// current returns the window of the current time. c.mu must be held.
+func (c *Counters) current() *window {
+	start := c.clock.Now().Truncate(c.interval)
+	w, ok := c.windows[start]
+	if !ok {
+		w = &window{likes: map[string]int{}, errors: map[string]int{}}
+		c.windows[start] = w
+	}
+	return w
+}
+
+// Counts returns a copy of the likes by network and the server errors by route counted in the window starting at start.
+func (c *Counters) Counts(start time.Time) (likes, failures map[string]int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	likes, failures = map[string]int{}, map[string]int{}
+	if w, ok := c.windows[start]; ok {
+		for asn, n := range w.likes {
+			likes[asn] = n
+		}
+		for route, n := range w.errors {
+			failures[route] = n
+		}
+	}
+	return likes, failures
+}
+
+// ended removes the windows that ended by now and returns them by their start.
+func (c *Counters) ended(now time.Time) map[time.Time]*window {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ended := map[time.Time]*window{}
+	for start, w := range c.windows {
+		if !start.Add(c.interval).After(now) {
+			ended[start] = w
+			delete(c.windows, start)
+		}
+	}
+	return ended
+}
+
+func truncate(s string) string {
+	if len(s) > maxSubjectLength {
+		return s[:maxSubjectLength]
+	}
+	return s
+}
@@ -0,0 +1,204 @@
+// Package audit records privileged actions in the audit log.
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// IAuditService defines the interface for audit log operations.
+type IAuditService interface {
+	Record(entry data.AuditEntry) error
+	List(filter data.AuditFilter) ([]data.AuditEntry, error)
+	UserHistory(ctx context.Context, userID uuid.UUID, filter data.HistoryFilter) ([]data.HistoryEntry, error)
+	ProjectHistory(ctx context.Context, projectID uuid.UUID, filter data.HistoryFilter) ([]data.HistoryEntry, error)
+}
+
+// AuditService implements the IAuditService interface.
+type AuditService struct {
+	db *sql.DB
+}
+
+// NewAuditService creates a new AuditService with the provided database connection.
+func NewAuditService(db *sql.DB) AuditService {
+	return AuditService{
+		db: db,
+	}
+}
+
+// Record appends an entry to the audit log.
+func (s AuditService) Record(entry data.AuditEntry) error {
+	details := entry.Details
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO audit_logs (actor_id, action, target_type, target_id, details, ip)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, NULLIF($6, ''))`
+
+	_, err = s.db.Exec(query, entry.ActorID, entry.Action, entry.TargetType, entry.TargetID, detailsJSON, entry.IP)
+	return err
+}
+
+// List retrieves the audit log entries matching the filter, newest first.
+func (s AuditService) List(filter data.AuditFilter) ([]data.AuditEntry, error) {
+	var cursorTime *time.Time
+	var cursorID *int64
+	if filter.Cursor != nil {
+		cursorTime, cursorID = &filter.Cursor.Time, &filter.Cursor.ID
+	}
+
+	query := `
+		SELECT id, actor_id, action, COALESCE(target_type, ''), COALESCE(target_id, ''), details, COALESCE(ip, ''), created_at
+		FROM audit_logs
+		WHERE (cardinality($1::text[]) = 0 OR action = ANY($1))
+		  AND ($2 = '' OR target_type = $2)
+		  AND ($3 = '' OR target_id = $3)
+		  AND ($5::uuid IS NULL OR actor_id = $5)
+		  AND ($6::timestamptz IS NULL OR created_at >= $6)
+		  AND ($7::timestamptz IS NULL OR created_at < $7)
+		  AND ($8::timestamptz IS NULL OR (created_at, id) < ($8, $9::bigint))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4`
+
+	rows, err := s.db.Query(query, pq.Array(filter.Actions), filter.TargetType, filter.TargetID, filter.Limit,
+		filter.ActorID, filter.After, filter.Before, cursorTime, cursorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []data.AuditEntry{}
+	for rows.Next() {
+		var entry data.AuditEntry
+		var details []byte
+		if err := rows.Scan(&entry.ID, &entry.ActorID, &entry.Action, &entry.TargetType, &entry.TargetID, &details, &entry.IP, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(details, &entry.Details); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// userHistory merges the audit log entries about a user with their bans, appeals and account changes.
+// Renames are summarised by the username given up.
+const userHistory = `
+	SELECT 'audit' AS kind, a.action, a.actor_id, '' AS summary, a.details, a.created_at
+	FROM audit_logs a WHERE a.target_type = 'user' AND a.target_id = $1::uuid::text
+	UNION ALL
+	SELECT 'sanction', 'user.' || h.action, h.actor_id, h.reason, jsonb_build_object('expires_at', h.expires_at), h.created_at
+	FROM ban_history h WHERE h.user_id = $1
+	UNION ALL
+	SELECT 'state', 'user.created', NULL, '', '{}'::jsonb, u.created_at
+	FROM users u WHERE u.id = $1
+	UNION ALL
+	SELECT 'state', 'ban_appeal.submitted', b.user_id, b.message, jsonb_build_object('appeal_id', b.id), b.created_at
+	FROM ban_appeals b WHERE b.user_id = $1
+	UNION ALL
+	SELECT 'state', 'ban_appeal.' || b.status, b.resolved_by, b.resolution, jsonb_build_object('appeal_id', b.id), b.resolved_at
+	FROM ban_appeals b WHERE b.user_id = $1 AND b.resolved_at IS NOT NULL
+	UNION ALL
+	SELECT 'state', 'account_deletion.scheduled', d.user_id, '', jsonb_build_object('scheduled_for', d.scheduled_for), d.requested_at
+	FROM account_deletions d WHERE d.user_id = $1
+	UNION ALL
+	SELECT 'state', 'user.renamed', NULL, n.username, jsonb_build_object('reserved_until', n.reserved_until), n.changed_at
+	FROM username_history n WHERE n.user_id = $1`
+
+// projectHistory merges the audit log entries about a project with its reports, takedown and creation.
+const projectHistory = `
+	SELECT 'audit' AS kind, a.action, a.actor_id, '' AS summary, a.details, a.created_at
+	FROM audit_logs a WHERE a.target_type = 'project' AND a.target_id = $1::uuid::text
+	UNION ALL
+	SELECT 'state', 'project.created', p.creator_id, p.title, '{}'::jsonb, p.created_at
+	FROM projects p WHERE p.id = $1
+	UNION ALL
+	SELECT 'sanction', 'project.hidden', p.hidden_by, p.hidden_reason, '{}'::jsonb, p.hidden_at
+	FROM projects p WHERE p.id = $1 AND p.hidden_at IS NOT NULL
+	UNION ALL
+	SELECT 'state', 'report.filed', r.reporter_id, r.reason, jsonb_build_object('report_id', r.id, 'details', r.details), r.created_at
+	FROM project_reports r WHERE r.project_id = $1
+	UNION ALL
+	SELECT 'sanction', 'report.' || r.status, r.resolved_by, r.reason, jsonb_build_object('report_id', r.id), r.resolved_at
+	FROM project_reports r WHERE r.project_id = $1 AND r.resolved_at IS NOT NULL`
+
+// UserHistory retrieves the history of a user in chronological order.
+// It returns ErrUserNotFound if the user doesn't exist.
+func (s AuditService) UserHistory(ctx context.Context, userID uuid.UUID, filter data.HistoryFilter) ([]data.HistoryEntry, error) {
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)", userID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, services.ErrUserNotFound
+	}
+
+	return s.history(ctx, userHistory, userID, filter)
+}
+
+// ProjectHistory retrieves the history of a project in chronological order.
+// It returns ErrProjectNotFound if the project doesn't exist.
+func (s AuditService) ProjectHistory(ctx context.Context, projectID uuid.UUID, filter data.HistoryFilter) ([]data.HistoryEntry, error) {
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM projects WHERE id = $1)", projectID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, services.ErrProjectNotFound
+	}
+
+	return s.history(ctx, projectHistory, projectID, filter)
+}
+
+// history runs the query merging the history of a target and applies the filter to it.
+func (s AuditService) history(ctx context.Context, merged string, targetID uuid.UUID, filter data.HistoryFilter) ([]data.HistoryEntry, error) {
+	query := `
+		SELECT h.kind, h.action, h.actor_id, COALESCE(u.username, ''), h.summary, h.details, h.created_at
+		FROM (` + merged + `) h
+		LEFT JOIN users u ON u.id = h.actor_id
+		WHERE ($2 = '' OR h.kind = $2)
+		  AND ($3::timestamptz IS NULL OR h.created_at >= $3)
+		  AND ($4::timestamptz IS NULL OR h.created_at <= $4)
+		  AND ($5 = '' OR h.action ILIKE '%' || $5 || '%' OR h.summary ILIKE '%' || $5 || '%'
+		       OR h.details::text ILIKE '%' || $5 || '%' OR u.username ILIKE '%' || $5 || '%')
+		ORDER BY h.created_at, h.action
+		LIMIT $6`
+
+	rows, err := s.db.QueryContext(ctx, query, targetID, string(filter.Kind), filter.After, filter.Before, filter.Search, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []data.HistoryEntry{}
+	for rows.Next() {
+		var entry data.HistoryEntry
+		var details []byte
+		if err := rows.Scan(&entry.Kind, &entry.Action, &entry.ActorID, &entry.ActorUsername, &entry.Summary, &details, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(details, &entry.Details); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
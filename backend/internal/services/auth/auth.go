@@ -2,6 +2,7 @@
 package auth
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -11,44 +12,79 @@ import (
 	"NodeTurtleAPI/internal/services"
 
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // Claims represents the JWT claims structure used for authentication tokens.
 // It extends the standard JWT claims with a custom Role field.
+// Impersonator is set on tokens issued to support staff acting as the user, it holds the staff member's user ID.
+// Scope is set on tokens granting limited access to a single resource instead of a user session,
+// the subject is then the ID of that resource.
 type Claims struct {
-	Role string `json:"role"`
+	Role         string `json:"role"`
+	Impersonator string `json:"impersonator,omitempty"`
+	Scope        string `json:"scope,omitempty"`
 	jwt.StandardClaims
 }
 
+// ScopeProjectEmbed grants read-only access to one project, for embedding it on other sites.
+const ScopeProjectEmbed = "project:embed"
+
 // IAuthService defines the interface for authentication operations.
 type IAuthService interface {
-	Login(email, password string) (string, *data.User, error)
+	Login(ctx context.Context, email, password string) (string, *data.User, error)
+	UpdateLastLogin(ctx context.Context, userID uuid.UUID) error
 	CreateAccessToken(user data.User) (string, error)
+	CreateImpersonationToken(user data.User, impersonatorID uuid.UUID) (string, time.Time, error)
+	CreateEmbedToken(projectID uuid.UUID) (string, time.Time, error)
 	VerifyToken(tokenString string) (*Claims, error)
+	VerifyEmbedToken(tokenString string, projectID uuid.UUID) error
+	RotateSigningKey() (string, error)
+	JWKS() data.JWKS
 }
 
 // AuthService implements the IAuthService interface for handling authentication.
+// Tokens are signed with RS256 keys from the key ring when one is configured, and with the HS256 secret otherwise.
+// Tokens signed with the secret are only accepted next to a key ring until its grace period ends.
 type AuthService struct {
-	db     *sql.DB
-	JwtKey []byte
-	JwtExp int
+	db               *sql.DB
+	JwtKey           []byte
+	JwtExp           int
+	impersonationTTL time.Duration
+	embedTTL         time.Duration
+	keys             *KeyRing
+	secretGraceUntil time.Time
 }
 
 // NewService creates a new AuthService with the provided database connection and JWT configuration.
-func NewService(db *sql.DB, jwtConfig config.JWTConfig) AuthService {
-	return AuthService{
-		db:     db,
-		JwtKey: []byte(jwtConfig.Secret),
-		JwtExp: jwtConfig.ExpireTime,
+// It returns an error if the signing keys can't be loaded.
+func NewService(db *sql.DB, jwtConfig config.JWTConfig) (AuthService, error) {
+	s := AuthService{
+		db:               db,
+		JwtKey:           []byte(jwtConfig.Secret),
+		JwtExp:           jwtConfig.ExpireTime,
+		impersonationTTL: time.Duration(jwtConfig.ImpersonationTTL) * time.Minute,
+		embedTTL:         time.Duration(jwtConfig.EmbedTTL) * time.Minute,
+		secretGraceUntil: jwtConfig.SecretGraceUntil,
+	}
+
+	if jwtConfig.KeysDir != "" {
+		keys, err := LoadKeyRing(jwtConfig)
+		if err != nil {
+			return AuthService{}, err
+		}
+		s.keys = keys
 	}
+
+	return s, nil
 }
 
 // Login authenticates a user with the provided email and password.
 // It returns a JWT token and the authenticated user on success, or an error if authentication fails.
 // Returns ErrInvalidCredentials if email/password are incorrect or ErrInactiveAccount if the account is not activated.
-func (s AuthService) Login(email, password string) (string, *data.User, error) {
-	tx, err := s.db.Begin()
+func (s AuthService) Login(ctx context.Context, email, password string) (string, *data.User, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return "", nil, err
 	}
@@ -59,7 +95,7 @@ func (s AuthService) Login(email, password string) (string, *data.User, error) {
 	var ban data.OptionalBan
 
 	query := `
-		SELECT u.id, u.email, u.username, u.password, u.activated,
+		SELECT u.id, u.email, u.username, u.password, u.activated, u.password_reset_required,
 		       r.id, r.name, r.description,
 			    bu.id, bu.expires_at, bu.banned_at, bu.reason, bu.banned_by
 		FROM users u
@@ -68,8 +104,8 @@ func (s AuthService) Login(email, password string) (string, *data.User, error) {
 		WHERE u.email = $1
 	`
 
-	err = tx.QueryRow(query, email).Scan(
-		&user.ID, &user.Email, &user.Username, &user.Password.Hash, &user.IsActivated,
+	err = tx.QueryRowContext(ctx, query, email).Scan(
+		&user.ID, &user.Email, &user.Username, &user.Password.Hash, &user.IsActivated, &user.PasswordResetRequired,
 		&role.ID, &role.Name, &role.Description,
 		&ban.ID, &ban.ExpiresAt, &ban.BannedAt, &ban.Reason, &ban.BannedBy,
 	)
@@ -94,23 +130,31 @@ func (s AuthService) Login(email, password string) (string, *data.User, error) {
 	if ban.NotNull() {
 		user.Ban = &data.Ban{
 			ID:        *ban.ID,
-			ExpiresAt: *ban.ExpiresAt,
+			ExpiresAt: ban.ExpiresAt,
 			Reason:    *ban.Reason,
 			BannedAt:  *ban.BannedAt,
-			BannedBy:  *ban.BannedBy,
+			BannedBy:  ban.Banner(),
 		}
 
 		if user.Ban.IsValid() {
+			if user.Ban.Permanent() {
+				return "", nil, fmt.Errorf("%w (reason: %v, permanently)", services.ErrAccountSuspended, user.Ban.Reason)
+			}
 			return "", nil, fmt.Errorf("%w (reason: %v, expires at: %v)", services.ErrAccountSuspended, user.Ban.Reason, user.Ban.ExpiresAt.Local().Format("2006-01-02"))
 		}
 	}
 
 	// Update last login time
-	_, err = tx.Exec("UPDATE users SET last_login = NOW() AT TIME ZONE 'UTC' WHERE id = $1", user.ID)
+	_, err = tx.ExecContext(ctx, "UPDATE users SET last_login = NOW() AT TIME ZONE 'UTC' WHERE id = $1", user.ID)
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to update last login time: %w", err)
 	}
 
+	// logging in is how users change their mind about deleting their account
+	if _, err := tx.ExecContext(ctx, cancelDeletionQuery, user.ID); err != nil {
+		return "", nil, err
+	}
+
 	user.Role = role
 	token, err := s.CreateAccessToken(user)
 	if err != nil {
@@ -124,13 +168,54 @@ func (s AuthService) Login(email, password string) (string, *data.User, error) {
 	return token, &user, nil
 }
 
+// UpdateLastLogin records a login of the user that didn't go through Login, e.g. with an emailed login link.
+// Like Login, it cancels a scheduled deletion of the account.
+func (s AuthService) UpdateLastLogin(ctx context.Context, userID uuid.UUID) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "UPDATE users SET last_login = NOW() AT TIME ZONE 'UTC' WHERE id = $1", userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, cancelDeletionQuery, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// cancelDeletionQuery cancels the scheduled deletion of an account, see deletions.DeletionService.
+const cancelDeletionQuery = "DELETE FROM account_deletions WHERE user_id = $1"
+
 // VerifyToken validates a JWT token string and returns the claims if valid.
-// Returns ErrInvalidToken if the token is invalid or expired.
+// Returns ErrInvalidToken if the token is invalid or expired, or signed with the HS256 secret
+// while a key ring is configured and the secret's grace period is over.
 func (s AuthService) VerifyToken(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return s.JwtKey, nil
+		// the key is picked by the signing method, so a public key can never be used as an HMAC secret
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA:
+			kid, _ := token.Header["kid"].(string)
+			if s.keys == nil || kid == "" {
+				return nil, services.ErrInvalidToken
+			}
+			key, ok := s.keys.verifier(kid)
+			if !ok {
+				return nil, services.ErrInvalidToken
+			}
+			return key, nil
+		case *jwt.SigningMethodHMAC:
+			if len(s.JwtKey) == 0 || (s.keys != nil && !time.Now().Before(s.secretGraceUntil)) {
+				return nil, services.ErrInvalidToken
+			}
+			return s.JwtKey, nil
+		}
+		return nil, services.ErrInvalidToken
 	})
 
 	if err != nil {
@@ -158,6 +243,80 @@ func (s AuthService) CreateAccessToken(user data.User) (string, error) {
 		},
 	}
 
+	return s.sign(claims)
+}
+
+// CreateImpersonationToken generates a short-lived JWT token letting the impersonator act as the user.
+// The token carries the impersonator's ID, so requests made with it can be told apart and audited.
+// It returns the token and when it expires.
+func (s AuthService) CreateImpersonationToken(user data.User, impersonatorID uuid.UUID) (string, time.Time, error) {
+	expiresAt := time.Now().UTC().Add(s.impersonationTTL)
+
+	claims := &Claims{
+		Role:         user.Role.Name,
+		Impersonator: impersonatorID.String(),
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: expiresAt.Unix(),
+			Subject:   user.ID.String(),
+			IssuedAt:  time.Now().Unix(),
+		},
+	}
+
+	token, err := s.sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
+
+// CreateEmbedToken generates a short-lived JWT token granting read-only access to the project.
+// It can't be used as a session, the subject is the project ID.
+// It returns the token and when it expires.
+func (s AuthService) CreateEmbedToken(projectID uuid.UUID) (string, time.Time, error) {
+	expiresAt := time.Now().UTC().Add(s.embedTTL)
+
+	claims := &Claims{
+		Scope: ScopeProjectEmbed,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: expiresAt.Unix(),
+			Subject:   projectID.String(),
+			IssuedAt:  time.Now().Unix(),
+		},
+	}
+
+	token, err := s.sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
+
+// VerifyEmbedToken checks that the token is a valid embed token for the project.
+// Returns ErrInvalidToken if it is invalid, expired or was issued for another project.
+func (s AuthService) VerifyEmbedToken(tokenString string, projectID uuid.UUID) error {
+	claims, err := s.VerifyToken(tokenString)
+	if err != nil {
+		return services.ErrInvalidToken
+	}
+
+	if claims.Scope != ScopeProjectEmbed || claims.Subject != projectID.String() {
+		return services.ErrInvalidToken
+	}
+
+	return nil
+}
+
+// sign signs the claims with the primary key of the key ring, or with the HS256 secret when there is none.
+func (s AuthService) sign(claims *Claims) (string, error) {
+	if s.keys != nil {
+		kid, key := s.keys.signer()
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = kid
+		return token.SignedString(key)
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString(s.JwtKey)
 	if err != nil {
@@ -167,6 +326,25 @@ func (s AuthService) CreateAccessToken(user data.User) (string, error) {
 	return tokenString, nil
 }
 
+// RotateSigningKey replaces the primary signing key with a newly generated one and returns its id.
+// Tokens signed with the previous key remain valid until they expire.
+// It returns ErrKeyRotationUnavailable when tokens are signed with the HS256 secret.
+func (s AuthService) RotateSigningKey() (string, error) {
+	if s.keys == nil {
+		return "", services.ErrKeyRotationUnavailable
+	}
+	return s.keys.Rotate()
+}
+
+// JWKS returns the public keys access tokens can be verified with.
+// The set is empty when tokens are signed with the HS256 secret, which can't be published.
+func (s AuthService) JWKS() data.JWKS {
+	if s.keys == nil {
+		return data.JWKS{Keys: []data.JWK{}}
+	}
+	return s.keys.JWKS()
+}
+
 // HashPassword creates a bcrypt hash of the provided password.
 // It returns the hashed password as a string or an error if hashing fails.
 func HashPassword(password string) (string, error) {
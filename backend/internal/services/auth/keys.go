@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// signingKeyBits is the size of generated RSA signing keys.
+const signingKeyBits = 2048
+
+type signingKey struct {
+	id        string
+	private   *rsa.PrivateKey
+	createdAt time.Time
+	// retiresAt is when the last token signed with the key expires, zero for the primary key and configured grace keys
+	retiresAt time.Time
+}
+
+func (k *signingKey) retired(now time.Time) bool {
+	return !k.retiresAt.IsZero() && now.After(k.retiresAt)
+}
+
+// KeyRing holds the RSA keys access tokens are signed and verified with, identified by the kid token header.
+// New tokens are signed with the primary key, tokens signed with keys that were rotated out
+// stay valid until they expire.
+type KeyRing struct {
+	mu       sync.RWMutex
+	dir      string
+	tokenTTL time.Duration
+	primary  *signingKey
+	keys     map[string]*signingKey
+}
+
+// LoadKeyRing reads the signing keys from the configured directory.
+// The primary key is the configured one, or the most recently created key. A key is generated when there is none yet.
+// Besides the configured grace keys, keys that were rotated out less than a token lifetime ago are kept for verification.
+func LoadKeyRing(cfg config.JWTConfig) (*KeyRing, error) {
+	k := &KeyRing{
+		dir:      cfg.KeysDir,
+		tokenTTL: time.Duration(cfg.ExpireTime) * time.Hour,
+		keys:     map[string]*signingKey{},
+	}
+
+	if err := os.MkdirAll(cfg.KeysDir, 0700); err != nil {
+		return nil, fmt.Errorf("could not create signing key directory: %w", err)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(cfg.KeysDir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	found := make([]*signingKey, 0, len(paths))
+	for _, path := range paths {
+		key, err := readSigningKey(path)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, key)
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].createdAt.Before(found[j].createdAt) })
+
+	if len(found) == 0 {
+		if cfg.PrimaryKeyID != "" {
+			return nil, fmt.Errorf("primary signing key %q not found in %s", cfg.PrimaryKeyID, cfg.KeysDir)
+		}
+		if _, err := k.Rotate(); err != nil {
+			return nil, err
+		}
+		return k, nil
+	}
+
+	now := time.Now()
+	for i, key := range found {
+		k.keys[key.id] = key
+		if i+1 < len(found) {
+			// superseded by the next key, tokens signed before then are valid for another token lifetime
+			key.retiresAt = found[i+1].createdAt.Add(k.tokenTTL)
+		}
+	}
+
+	k.primary = found[len(found)-1]
+	if cfg.PrimaryKeyID != "" {
+		primary, ok := k.keys[cfg.PrimaryKeyID]
+		if !ok {
+			return nil, fmt.Errorf("primary signing key %q not found in %s", cfg.PrimaryKeyID, cfg.KeysDir)
+		}
+		k.primary = primary
+	}
+	k.primary.retiresAt = time.Time{}
+
+	for _, id := range cfg.GraceKeyIDs {
+		key, ok := k.keys[id]
+		if !ok {
+			return nil, fmt.Errorf("grace signing key %q not found in %s", id, cfg.KeysDir)
+		}
+		key.retiresAt = time.Time{}
+	}
+
+	for id, key := range k.keys {
+		if key.retired(now) {
+			delete(k.keys, id)
+		}
+	}
+
+	return k, nil
+}
+
+func readSigningKey(path string) (*signingKey, error) {
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read signing key: %w", err)
+	}
+
+	private, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse signing key %s: %w", path, err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &signingKey{
+		id:        strings.TrimSuffix(filepath.Base(path), ".pem"),
+		private:   private,
+		createdAt: info.ModTime(),
+	}, nil
+}
+
+// Rotate generates a new primary signing key and stores it in the key directory.
+// The previous primary key keeps verifying the tokens it signed until they expire.
+// It returns the id of the new key.
+func (k *KeyRing) Rotate() (string, error) {
+	private, err := rsa.GenerateKey(rand.Reader, signingKeyBits)
+	if err != nil {
+		return "", err
+	}
+
+	idBytes := make([]byte, 8)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", err
+	}
+	key := &signingKey{
+		id:        hex.EncodeToString(idBytes),
+		private:   private,
+		createdAt: time.Now(),
+	}
+
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(private)})
+	if err := os.WriteFile(filepath.Join(k.dir, key.id+".pem"), pemBytes, 0600); err != nil {
+		return "", fmt.Errorf("could not store signing key: %w", err)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.primary != nil {
+		k.primary.retiresAt = key.createdAt.Add(k.tokenTTL)
+	}
+	k.primary = key
+	k.keys[key.id] = key
+
+	return key.id, nil
+}
+
+// signer returns the id and private key of the primary key.
+func (k *KeyRing) signer() (string, *rsa.PrivateKey) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.primary.id, k.primary.private
+}
+
+// verifier returns the public key with the given id, if it is still accepted.
+func (k *KeyRing) verifier(kid string) (*rsa.PublicKey, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	key, ok := k.keys[kid]
+	if !ok || key.retired(time.Now()) {
+		return nil, false
+	}
+	return &key.private.PublicKey, true
+}
+
+// JWKS returns the public keys tokens are currently verified with, the primary key first.
+func (k *KeyRing) JWKS() data.JWKS {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	now := time.Now()
+	ids := make([]string, 0, len(k.keys))
+	for id, key := range k.keys {
+		if id != k.primary.id && !key.retired(now) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+
+	jwks := data.JWKS{Keys: []data.JWK{data.NewRSAJWK(&k.primary.private.PublicKey, k.primary.id)}}
+	for _, id := range ids {
+		jwks.Keys = append(jwks.Keys, data.NewRSAJWK(&k.keys[id].private.PublicKey, id))
+	}
+	return jwks
+}
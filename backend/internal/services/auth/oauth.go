@@ -0,0 +1,349 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/httpclient"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+)
+
+const oauthStateTTL = 10 * time.Minute
+
+// IOAuthService defines the interface for social login operations.
+type IOAuthService interface {
+	Providers() []string
+	AuthCodeURL(ctx context.Context, provider string) (string, error)
+	Authenticate(ctx context.Context, provider, code, state string) (uuid.UUID, error)
+}
+
+// OAuthService implements the IOAuthService interface using the OAuth2 authorization code flow with PKCE.
+type OAuthService struct {
+	db          *sql.DB
+	callbackURL string
+	providers   map[string]oauthProvider
+	client      *http.Client
+}
+
+// NewOAuthService creates a new OAuthService. Only providers with a configured client ID are enabled.
+func NewOAuthService(db *sql.DB, cfg config.OAuthConfig) OAuthService {
+	providers := map[string]oauthProvider{}
+	if cfg.Google.ClientID != "" {
+		providers["google"] = newGoogleProvider(cfg.Google)
+	}
+	if cfg.GitHub.ClientID != "" {
+		providers["github"] = newGitHubProvider(cfg.GitHub)
+	}
+
+	// the token exchange is a POST and isn't retried, an authorization code can only be used once
+	return OAuthService{
+		db:          db,
+		callbackURL: strings.TrimRight(cfg.CallbackURL, "/"),
+		providers:   providers,
+		client:      httpclient.New(httpclient.Options{Timeout: 10 * time.Second}),
+	}
+}
+
+// Providers returns the names of the enabled providers.
+func (s OAuthService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AuthCodeURL creates a single use state and returns the provider authorization URL the user should be redirected to.
+// It returns ErrUnknownProvider if the provider is not enabled.
+func (s OAuthService) AuthCodeURL(ctx context.Context, provider string) (string, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return "", services.ErrUnknownProvider
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	verifier, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		"INSERT INTO oauth_states (state, provider, code_verifier, expires_at) VALUES ($1, $2, $3, $4)",
+		state, provider, verifier, time.Now().UTC().Add(oauthStateTTL),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.clientID)
+	params.Set("redirect_uri", s.redirectURI(provider))
+	params.Set("scope", strings.Join(p.scopes, " "))
+	params.Set("state", state)
+	params.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	params.Set("code_challenge_method", "S256")
+
+	return p.authURL + "?" + params.Encode(), nil
+}
+
+// Authenticate exchanges the authorization code for the provider account and returns the linked NodeTurtle user.
+// Provider accounts are linked to an existing user by verified email, otherwise a new activated user is created.
+// It returns ErrUnknownProvider if the provider is not enabled, ErrInvalidToken if the state or code is invalid,
+// ErrUnverifiedEmail if the provider account can't be matched to a verified email and ErrInactiveAccount
+// if the account was deactivated.
+func (s OAuthService) Authenticate(ctx context.Context, provider, code, state string) (uuid.UUID, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return uuid.Nil, services.ErrUnknownProvider
+	}
+
+	// state is single use, consuming it also protects against replayed callbacks
+	var verifier string
+	err := s.db.QueryRowContext(ctx,
+		"DELETE FROM oauth_states WHERE state = $1 AND provider = $2 AND expires_at > $3 RETURNING code_verifier",
+		state, provider, time.Now().UTC(),
+	).Scan(&verifier)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.Nil, services.ErrInvalidToken
+		}
+		return uuid.Nil, err
+	}
+
+	accessToken, err := s.exchange(ctx, p, provider, code, verifier)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	identity, err := p.identity(ctx, s.client, accessToken)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("could not fetch %s account: %w", provider, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback()
+
+	userID, err := s.resolveUser(ctx, tx, provider, *identity)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE users SET last_login = NOW() AT TIME ZONE 'UTC' WHERE id = $1", userID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to update last login time: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return uuid.Nil, err
+	}
+
+	return userID, nil
+}
+
+// exchange trades the authorization code for a provider access token.
+func (s OAuthService) exchange(ctx context.Context, p oauthProvider, provider, code, verifier string) (string, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", s.redirectURI(provider))
+	form.Set("client_id", p.clientID)
+	form.Set("client_secret", p.clientSecret)
+	form.Set("code_verifier", verifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("could not exchange %s authorization code: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+			return "", services.ErrInvalidToken
+		}
+		return "", fmt.Errorf("could not exchange %s authorization code: status %d", provider, resp.StatusCode)
+	}
+
+	var payload struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return "", err
+	}
+	// GitHub reports a bad code with a 200 response and no token
+	if payload.AccessToken == "" {
+		return "", services.ErrInvalidToken
+	}
+
+	return payload.AccessToken, nil
+}
+
+// resolveUser returns the user linked to the provider account, linking or creating one by verified email if needed.
+// It returns ErrInactiveAccount for accounts that were deactivated, only accounts waiting for their activation are activated.
+func (s OAuthService) resolveUser(ctx context.Context, tx *sql.Tx, provider string, identity oauthIdentity) (uuid.UUID, error) {
+	var userID uuid.UUID
+	var activated, deactivated bool
+
+	err := tx.QueryRowContext(ctx, `
+		SELECT u.id, u.activated
+		FROM oauth_identities oi
+		JOIN users u ON u.id = oi.user_id
+		WHERE oi.provider = $1 AND oi.subject = $2`,
+		provider, identity.Subject,
+	).Scan(&userID, &activated)
+	if err == nil {
+		if !activated {
+			return uuid.Nil, services.ErrInactiveAccount
+		}
+		return userID, nil
+	}
+	if err != sql.ErrNoRows {
+		return uuid.Nil, err
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return uuid.Nil, services.ErrUnverifiedEmail
+	}
+	email := strings.ToLower(identity.Email)
+
+	err = tx.QueryRowContext(ctx, "SELECT id, activated, deactivated_at IS NOT NULL FROM users WHERE LOWER(email) = $1", email).Scan(&userID, &activated, &deactivated)
+	switch {
+	case err == sql.ErrNoRows:
+		userID, err = s.createUser(ctx, tx, email, identity)
+		if err != nil {
+			return uuid.Nil, err
+		}
+	case err != nil:
+		return uuid.Nil, err
+	case deactivated:
+		return uuid.Nil, services.ErrInactiveAccount
+	case !activated:
+		// the provider verified the address, so a pending activation is no longer needed
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET activated = TRUE WHERE id = $1", userID); err != nil {
+			return uuid.Nil, err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO oauth_identities (provider, subject, user_id, email) VALUES ($1, $2, $3, $4)",
+		provider, identity.Subject, userID, email,
+	)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return userID, nil
+}
+
+// createUser creates an activated account for a provider account. The account gets a random password,
+// the user can set their own through the password reset flow.
+func (s OAuthService) createUser(ctx context.Context, tx *sql.Tx, email string, identity oauthIdentity) (uuid.UUID, error) {
+	password, err := randomToken()
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	hashedPassword, err := HashPassword(password)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	base := usernameBase(identity.Username)
+	if base == "" {
+		local, _, _ := strings.Cut(email, "@")
+		base = usernameBase(local)
+	}
+	if base == "" {
+		base = "turtle"
+	}
+
+	for attempt := 0; attempt < 5; attempt++ {
+		n, err := rand.Int(rand.Reader, big.NewInt(10000))
+		if err != nil {
+			return uuid.Nil, err
+		}
+		username := fmt.Sprintf("%s%04d", base, n.Int64())
+
+		var exists bool
+		if err := tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)", username).Scan(&exists); err != nil {
+			return uuid.Nil, err
+		}
+		if exists {
+			continue
+		}
+
+		var userID uuid.UUID
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO users (email, username, password, role_id, activated, created_at)
+			VALUES ($1, $2, $3, $4, $5, NOW() AT TIME ZONE 'UTC')
+			RETURNING id`,
+			email, username, hashedPassword, data.RoleUser, true,
+		).Scan(&userID)
+
+		return userID, err
+	}
+
+	return uuid.Nil, services.ErrDuplicateUsername
+}
+
+func (s OAuthService) redirectURI(provider string) string {
+	return s.callbackURL + "/api/auth/oauth/" + provider + "/callback"
+}
+
+// usernameBase strips everything but letters and digits from a name so it passes username validation.
+func usernameBase(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+		if b.Len() == 12 {
+			break
+		}
+	}
+
+	if b.Len() < 3 {
+		return ""
+	}
+	return b.String()
+}
+
+func randomToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"NodeTurtleAPI/internal/config"
+)
+
+var errProviderResponse = errors.New("unexpected provider response")
+
+// oauthIdentity is the provider account returned by a provider userinfo endpoint.
+type oauthIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Username      string // used as a hint when creating a new account
+}
+
+// oauthProvider describes the endpoints and credentials of a social login provider.
+type oauthProvider struct {
+	clientID     string
+	clientSecret string
+	authURL      string
+	tokenURL     string
+	scopes       []string
+	identity     func(ctx context.Context, client *http.Client, accessToken string) (*oauthIdentity, error)
+}
+
+func newGoogleProvider(cfg config.OAuthProviderConfig) oauthProvider {
+	return oauthProvider{
+		clientID:     cfg.ClientID,
+		clientSecret: cfg.ClientSecret,
+		authURL:      "https://accounts.google.com/o/oauth2/v2/auth",
+		tokenURL:     "https://oauth2.googleapis.com/token",
+		scopes:       []string{"openid", "email", "profile"},
+		identity:     googleIdentity,
+	}
+}
+
+func newGitHubProvider(cfg config.OAuthProviderConfig) oauthProvider {
+	return oauthProvider{
+		clientID:     cfg.ClientID,
+		clientSecret: cfg.ClientSecret,
+		authURL:      "https://github.com/login/oauth/authorize",
+		tokenURL:     "https://github.com/login/oauth/access_token",
+		scopes:       []string{"read:user", "user:email"},
+		identity:     githubIdentity,
+	}
+}
+
+func googleIdentity(ctx context.Context, client *http.Client, accessToken string) (*oauthIdentity, error) {
+	var info struct {
+		Subject       string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		GivenName     string `json:"given_name"`
+	}
+	if err := getJSON(ctx, client, "https://openidconnect.googleapis.com/v1/userinfo", accessToken, &info); err != nil {
+		return nil, err
+	}
+	if info.Subject == "" {
+		return nil, errProviderResponse
+	}
+
+	return &oauthIdentity{
+		Subject:       info.Subject,
+		Email:         info.Email,
+		EmailVerified: info.EmailVerified,
+		Username:      info.GivenName,
+	}, nil
+}
+
+// githubIdentity loads the GitHub user and its primary email, the public profile email may be unverified or hidden.
+func githubIdentity(ctx context.Context, client *http.Client, accessToken string) (*oauthIdentity, error) {
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+	}
+	if err := getJSON(ctx, client, "https://api.github.com/user", accessToken, &user); err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, errProviderResponse
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(ctx, client, "https://api.github.com/user/emails", accessToken, &emails); err != nil {
+		return nil, err
+	}
+
+	identity := &oauthIdentity{
+		Subject:  strconv.FormatInt(user.ID, 10),
+		Username: user.Login,
+	}
+	for _, e := range emails {
+		if e.Primary {
+			identity.Email = e.Email
+			identity.EmailVerified = e.Verified
+			break
+		}
+	}
+
+	return identity, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url, accessToken string, dst interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: status %d from %s", errProviderResponse, resp.StatusCode, url)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(dst)
+}
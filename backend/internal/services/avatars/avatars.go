@@ -0,0 +1,173 @@
+// Package avatars provides the upload and serving of user avatars, kept in object storage.
+package avatars
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"net/http"
+	"regexp"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// IAvatarService defines the interface for avatar operations.
+type IAvatarService interface {
+	SetAvatar(ctx context.Context, userID uuid.UUID, upload []byte) (*data.Avatar, error)
+	DeleteAvatar(ctx context.Context, userID uuid.UUID) error
+	GetImage(ctx context.Context, userID uuid.UUID, name string) ([]byte, string, error)
+}
+
+// AvatarService implements the IAvatarService interface.
+type AvatarService struct {
+	db      *sql.DB
+	storage storage.Storage
+	cfg     config.AvatarsConfig
+}
+
+// NewAvatarService creates a new AvatarService with the provided database connection, object storage and limits.
+func NewAvatarService(db *sql.DB, store storage.Storage, cfg config.AvatarsConfig) AvatarService {
+	return AvatarService{db: db, storage: store, cfg: cfg}
+}
+
+// uploadFormats are the content types of the images users can upload, as sniffed from their first bytes.
+var uploadFormats = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+}
+
+// imageName matches the name of a stored avatar size, "<upload token>-<size>.png".
+var imageName = regexp.MustCompile(`^[0-9a-f]{16}-([0-9]+)\.png$`)
+
+// SetAvatar replaces the avatar of a user with an uploaded image. The image is cropped square around its center
+// and stored as PNG at each of the data.AvatarSizes, nothing of the upload itself, like its metadata, is kept.
+// It returns ErrInvalidImage if the upload isn't a PNG, JPEG or GIF image, ErrImageTooLarge if it exceeds
+// the size or pixel limits, and ErrUserNotFound if the user doesn't exist.
+func (s AvatarService) SetAvatar(ctx context.Context, userID uuid.UUID, upload []byte) (*data.Avatar, error) {
+	if s.cfg.MaxBytes > 0 && len(upload) > s.cfg.MaxBytes {
+		return nil, services.ErrImageTooLarge
+	}
+	// the declared content type of the upload is up to the client, only what the bytes are counts
+	if !uploadFormats[http.DetectContentType(upload)] {
+		return nil, services.ErrInvalidImage
+	}
+
+	// the dimensions are read from the header first, a small file can declare an image that wouldn't fit in memory
+	header, _, err := image.DecodeConfig(bytes.NewReader(upload))
+	if err != nil || header.Width <= 0 || header.Height <= 0 {
+		return nil, services.ErrInvalidImage
+	}
+	if s.cfg.MaxPixels > 0 && header.Width*header.Height > s.cfg.MaxPixels {
+		return nil, services.ErrImageTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(upload))
+	if err != nil {
+		return nil, services.ErrInvalidImage
+	}
+
+	key, err := newKey(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := encodeSizes(img)
+	if err != nil {
+		return nil, err
+	}
+	for size, content := range images {
+		if err := s.storage.Put(ctx, data.AvatarImageKey(key, size), content, "image/png"); err != nil {
+			s.deleteImages(ctx, key)
+			return nil, err
+		}
+	}
+
+	oldKey, err := s.swapKey(ctx, userID, &key)
+	if err != nil {
+		s.deleteImages(ctx, key)
+		return nil, err
+	}
+	if oldKey != nil {
+		s.deleteImages(ctx, *oldKey)
+	}
+
+	return data.NewAvatar(key), nil
+}
+
+// DeleteAvatar removes the avatar of a user, if they have one.
+// It returns ErrUserNotFound if the user doesn't exist.
+func (s AvatarService) DeleteAvatar(ctx context.Context, userID uuid.UUID) error {
+	oldKey, err := s.swapKey(ctx, userID, nil)
+	if err != nil {
+		return err
+	}
+	if oldKey != nil {
+		s.deleteImages(ctx, *oldKey)
+	}
+	return nil
+}
+
+// GetImage returns a stored size of a user's avatar by the name it's served under, and its content type.
+// It returns ErrRecordNotFound if no such image exists.
+func (s AvatarService) GetImage(ctx context.Context, userID uuid.UUID, name string) ([]byte, string, error) {
+	if !imageName.MatchString(name) {
+		return nil, "", services.ErrRecordNotFound
+	}
+
+	content, contentType, err := s.storage.Get(ctx, fmt.Sprintf("avatars/%s/%s", userID, name))
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, "", services.ErrRecordNotFound
+		}
+		return nil, "", err
+	}
+	return content, contentType, nil
+}
+
+// swapKey sets the avatar key of a user and returns the one it replaced.
+func (s AvatarService) swapKey(ctx context.Context, userID uuid.UUID, key *string) (*string, error) {
+	var oldKey *string
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE users u SET avatar_key = $2
+		FROM (SELECT avatar_key FROM users WHERE id = $1 FOR UPDATE) old
+		WHERE u.id = $1
+		RETURNING old.avatar_key`,
+		userID, key,
+	).Scan(&oldKey)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return oldKey, nil
+}
+
+// deleteImages removes the stored sizes of an avatar. Failures aren't reported, they only leave unreachable objects behind.
+func (s AvatarService) deleteImages(ctx context.Context, key string) {
+	for _, size := range data.AvatarSizes {
+		_ = s.storage.Delete(ctx, data.AvatarImageKey(key, size))
+	}
+}
+
+// newKey returns a key for a new upload of a user. Each upload gets its own, so its images can be cached forever.
+func newKey(userID uuid.UUID) (string, error) {
+	token := make([]byte, 8)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("avatars/%s/%s", userID, hex.EncodeToString(token)), nil
+}
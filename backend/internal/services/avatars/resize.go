@@ -0,0 +1,70 @@
+package avatars
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/png"
+
+	"NodeTurtleAPI/internal/data"
+)
+
+// encodeSizes crops an image square around its center and encodes it as PNG at each of the data.AvatarSizes.
+func encodeSizes(img image.Image) (map[int][]byte, error) {
+	bounds := img.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	origin := image.Pt(bounds.Min.X+(bounds.Dx()-side)/2, bounds.Min.Y+(bounds.Dy()-side)/2)
+
+	square := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(square, square.Bounds(), img, origin, draw.Src)
+
+	images := map[int][]byte{}
+	for _, size := range data.AvatarSizes {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, scale(square, size)); err != nil {
+			return nil, err
+		}
+		images[size] = buf.Bytes()
+	}
+	return images, nil
+}
+
+// scale resizes a square image to size by size pixels. Each pixel is the average of the source pixels it covers,
+// which keeps downscaled avatars smooth; smaller sources are scaled up by repeating pixels.
+func scale(src *image.RGBA, size int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	side := src.Bounds().Dx()
+
+	for y := 0; y < size; y++ {
+		y0, y1 := span(y, size, side)
+		for x := 0; x < size; x++ {
+			x0, x1 := span(x, size, side)
+
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					for c := 0; c < 4; c++ {
+						sum[c] += int(row[sx*4+c])
+					}
+				}
+			}
+
+			count := (y1 - y0) * (x1 - x0)
+			i := y*dst.Stride + x*4
+			for c := 0; c < 4; c++ {
+				dst.Pix[i+c] = uint8((sum[c] + count/2) / count)
+			}
+		}
+	}
+	return dst
+}
+
+// span returns the source pixels [from, to) covered by pixel i of size when scaling from side pixels, at least one.
+func span(i, size, side int) (int, int) {
+	from, to := i*side/size, (i+1)*side/size
+	if to <= from {
+		to = from + 1
+	}
+	return from, to
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"NodeTurtleAPI/internal/data"
+	"context"
 	"database/sql"
 	"time"
 
@@ -11,8 +12,19 @@ import (
 
 // IBanService defines the interface for user banning operations.
 type IBanService interface {
-	BanUser(userId uuid.UUID, bannedBy uuid.UUID, expires_at time.Time, reason string) (*data.Ban, error)
-	UnbanUser(userId uuid.UUID) error
+	BanUser(ctx context.Context, userId uuid.UUID, bannedBy uuid.UUID, expires_at *time.Time, reason string) (*data.Ban, error)
+	UnbanUser(ctx context.Context, userId uuid.UUID, unbannedBy uuid.UUID) error
+	ListBanHistory(ctx context.Context, userID uuid.UUID) ([]data.BanEvent, error)
+	AppealBan(ctx context.Context, userID uuid.UUID, message string) (*data.BanAppeal, error)
+	GetAppeal(ctx context.Context, appealID uuid.UUID) (*data.BanAppeal, error)
+	ListOpenAppeals(ctx context.Context) ([]data.BanAppeal, error)
+	ResolveAppeal(ctx context.Context, appealID uuid.UUID, moderatorID uuid.UUID, decision data.BanAppealDecision) (*data.BanAppeal, error)
+	ListIPBans(ctx context.Context) ([]data.IPBan, error)
+	ActiveIPBans(ctx context.Context) ([]data.IPBan, error)
+	GetIPBan(ctx context.Context, id int64) (*data.IPBan, error)
+	AddIPBan(ctx context.Context, subnet, reason string, expiresAt *time.Time, createdBy uuid.UUID) (*data.IPBan, error)
+	UpdateIPBan(ctx context.Context, id int64, reason string, expiresAt *time.Time) (*data.IPBan, error)
+	RemoveIPBan(ctx context.Context, id int64) error
 }
 
 // BanService implements the IBanService interface for handling user bans.
@@ -27,8 +39,24 @@ func NewBanService(db *sql.DB) BanService {
 	}
 }
 
-func (s BanService) BanUser(userId uuid.UUID, bannedBy uuid.UUID, expires_at time.Time, reason string) (*data.Ban, error) {
-	tx, err := s.db.Begin()
+// execer is a database connection or a transaction.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// RecordBanEvent adds an entry to the ban history of a user. Everything banning or unbanning users records it
+// in the same transaction, expiresAt is nil for unbans and permanent bans.
+func RecordBanEvent(ctx context.Context, db execer, userID uuid.UUID, action data.BanAction, reason string, expiresAt *time.Time, actorID uuid.UUID) error {
+	_, err := db.ExecContext(ctx,
+		"INSERT INTO ban_history (user_id, action, reason, expires_at, actor_id) VALUES ($1, $2, $3, $4, $5)",
+		userID, action, reason, expiresAt, actorID,
+	)
+	return err
+}
+
+// BanUser bans a user until expires_at, or for good when it is nil, replacing any ban they already have.
+func (s BanService) BanUser(ctx context.Context, userId uuid.UUID, bannedBy uuid.UUID, expires_at *time.Time, reason string) (*data.Ban, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -43,11 +71,11 @@ func (s BanService) BanUser(userId uuid.UUID, bannedBy uuid.UUID, expires_at tim
   			SET reason = EXCLUDED.reason,
       		banned_by = EXCLUDED.banned_by,
       		expires_at = EXCLUDED.expires_at
-  		RETURNING id, reason, banned_by, expires_at;
+  		RETURNING id, reason, banned_by, expires_at, banned_at;
 	`
 
-	err = tx.QueryRow(query, userId, reason, bannedBy, expires_at).Scan(
-		&ban.ID, &ban.Reason, &ban.BannedBy, &ban.ExpiresAt,
+	err = tx.QueryRowContext(ctx, query, userId, reason, bannedBy, expires_at).Scan(
+		&ban.ID, &ban.Reason, &ban.BannedBy, &ban.ExpiresAt, &ban.BannedAt,
 	)
 
 	if err != nil {
@@ -58,6 +86,10 @@ func (s BanService) BanUser(userId uuid.UUID, bannedBy uuid.UUID, expires_at tim
 		return nil, err
 	}
 
+	if err = RecordBanEvent(ctx, tx, userId, data.BanActionBan, reason, expires_at, bannedBy); err != nil {
+		return nil, err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return nil, err
 	}
@@ -65,13 +97,24 @@ func (s BanService) BanUser(userId uuid.UUID, bannedBy uuid.UUID, expires_at tim
 	return &ban, nil
 }
 
-func (s BanService) UnbanUser(userId uuid.UUID) error {
-	query := `
-        DELETE FROM banned_users
-        WHERE user_id = $1;
-    `
+func (s BanService) UnbanUser(ctx context.Context, userId uuid.UUID, unbannedBy uuid.UUID) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	result, err := s.db.Exec(query, userId)
+	if err := unban(ctx, tx, userId, unbannedBy, ""); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// unban lifts the ban of a user and records it in the ban history.
+// It returns ErrUserNotFound if the user isn't banned.
+func unban(ctx context.Context, tx *sql.Tx, userID, unbannedBy uuid.UUID, reason string) error {
+	result, err := tx.ExecContext(ctx, "DELETE FROM banned_users WHERE user_id = $1", userID)
 	if err != nil {
 		return err
 	}
@@ -85,5 +128,269 @@ func (s BanService) UnbanUser(userId uuid.UUID) error {
 		return ErrUserNotFound
 	}
 
+	return RecordBanEvent(ctx, tx, userID, data.BanActionUnban, reason, nil, unbannedBy)
+}
+
+// ListBanHistory retrieves every ban and unban of a user, oldest first.
+// It returns ErrUserNotFound if the user doesn't exist.
+func (s BanService) ListBanHistory(ctx context.Context, userID uuid.UUID) ([]data.BanEvent, error) {
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)", userID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT h.id, h.user_id, h.action, h.reason, h.expires_at, h.actor_id, COALESCE(u.username, ''), h.created_at
+		FROM ban_history h
+		LEFT JOIN users u ON h.actor_id = u.id
+		WHERE h.user_id = $1
+		ORDER BY h.id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []data.BanEvent{}
+	for rows.Next() {
+		var e data.BanEvent
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Action, &e.Reason, &e.ExpiresAt, &e.ActorID, &e.ActorUsername, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+// AppealBan files an appeal of the current ban of a user, a ban can be appealed once.
+// It returns ErrNotBanned if the user has no ban in force and ErrAlreadyAppealed if the ban was appealed before.
+func (s BanService) AppealBan(ctx context.Context, userID uuid.UUID, message string) (*data.BanAppeal, error) {
+	var banID int64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT h.id
+		FROM banned_users bu
+		JOIN ban_history h ON h.user_id = bu.user_id AND h.action = 'ban'
+		WHERE bu.user_id = $1 AND (bu.expires_at IS NULL OR bu.expires_at > NOW())
+		ORDER BY h.id DESC
+		LIMIT 1`, userID).Scan(&banID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotBanned
+		}
+		return nil, err
+	}
+
+	var appealID uuid.UUID
+	err = s.db.QueryRowContext(ctx,
+		"INSERT INTO ban_appeals (user_id, ban_id, message) VALUES ($1, $2, $3) RETURNING id",
+		userID, banID, message,
+	).Scan(&appealID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return nil, ErrAlreadyAppealed
+		}
+		return nil, err
+	}
+
+	return s.GetAppeal(ctx, appealID)
+}
+
+const selectAppeal = `
+	SELECT a.id, a.user_id, u.username, u.email, a.ban_id, h.reason, h.expires_at, a.message, a.status, a.resolution,
+	       a.resolved_by, a.resolved_at, a.created_at
+	FROM ban_appeals a
+	JOIN users u ON a.user_id = u.id
+	JOIN ban_history h ON a.ban_id = h.id`
+
+// GetAppeal retrieves a ban appeal. It returns ErrRecordNotFound if the appeal doesn't exist.
+func (s BanService) GetAppeal(ctx context.Context, appealID uuid.UUID) (*data.BanAppeal, error) {
+	return scanAppeal(s.db.QueryRowContext(ctx, selectAppeal+" WHERE a.id = $1", appealID))
+}
+
+// ListOpenAppeals retrieves the appeals waiting for review, oldest first.
+func (s BanService) ListOpenAppeals(ctx context.Context) ([]data.BanAppeal, error) {
+	rows, err := s.db.QueryContext(ctx, selectAppeal+" WHERE a.status = $1 ORDER BY a.created_at", data.AppealOpen)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	appeals := []data.BanAppeal{}
+	for rows.Next() {
+		a, err := scanAppeal(rows)
+		if err != nil {
+			return nil, err
+		}
+		appeals = append(appeals, *a)
+	}
+
+	return appeals, rows.Err()
+}
+
+// ResolveAppeal closes an open appeal. Accepting it lifts the user's ban, unless the ban ran out or was lifted already.
+// It returns ErrRecordNotFound if the appeal doesn't exist and ErrAppealResolved if it was resolved before.
+func (s BanService) ResolveAppeal(ctx context.Context, appealID uuid.UUID, moderatorID uuid.UUID, decision data.BanAppealDecision) (*data.BanAppeal, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var userID uuid.UUID
+	var status data.AppealStatus
+	err = tx.QueryRowContext(ctx, "SELECT user_id, status FROM ban_appeals WHERE id = $1 FOR UPDATE", appealID).Scan(&userID, &status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+	if status != data.AppealOpen {
+		return nil, ErrAppealResolved
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE ban_appeals
+		SET status = $2, resolution = $3, resolved_by = $4, resolved_at = NOW()
+		WHERE id = $1`,
+		appealID, decision.Status, decision.Resolution, moderatorID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if decision.Status == data.AppealAccepted {
+		if err := unban(ctx, tx, userID, moderatorID, "Appeal accepted"); err != nil && err != ErrUserNotFound {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return s.GetAppeal(ctx, appealID)
+}
+
+const ipBanColumns = "id, subnet, reason, created_by, expires_at, created_at"
+
+// ListIPBans returns every IP ban, including expired ones, newest first.
+func (s BanService) ListIPBans(ctx context.Context) ([]data.IPBan, error) {
+	return s.queryIPBans(ctx, "SELECT "+ipBanColumns+" FROM ip_bans ORDER BY created_at DESC")
+}
+
+// ActiveIPBans returns the IP bans in force.
+func (s BanService) ActiveIPBans(ctx context.Context) ([]data.IPBan, error) {
+	return s.queryIPBans(ctx, "SELECT "+ipBanColumns+" FROM ip_bans WHERE expires_at IS NULL OR expires_at > NOW()")
+}
+
+func (s BanService) queryIPBans(ctx context.Context, query string) ([]data.IPBan, error) {
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bans := make([]data.IPBan, 0)
+	for rows.Next() {
+		ban, err := scanIPBan(rows)
+		if err != nil {
+			return nil, err
+		}
+		bans = append(bans, *ban)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return bans, nil
+}
+
+// GetIPBan retrieves a single IP ban.
+// It returns ErrRecordNotFound if the ban doesn't exist.
+func (s BanService) GetIPBan(ctx context.Context, id int64) (*data.IPBan, error) {
+	return scanIPBan(s.db.QueryRowContext(ctx, "SELECT "+ipBanColumns+" FROM ip_bans WHERE id = $1", id))
+}
+
+// AddIPBan bans a subnet in CIDR notation or a single IP until expiresAt, or for good when it is nil.
+// Host bits are cleared, so 10.0.0.5/24 bans 10.0.0.0/24.
+// It returns ErrInvalidData if the subnet can't be parsed and ErrIPAlreadyBanned if the subnet is banned already.
+func (s BanService) AddIPBan(ctx context.Context, subnet, reason string, expiresAt *time.Time, createdBy uuid.UUID) (*data.IPBan, error) {
+	query := `
+		INSERT INTO ip_bans (subnet, reason, created_by, expires_at)
+		VALUES (network($1::inet), $2, $3, $4)
+		RETURNING ` + ipBanColumns
+	ban, err := scanIPBan(s.db.QueryRowContext(ctx, query, subnet, reason, createdBy, expiresAt))
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code {
+			case "23505":
+				return nil, ErrIPAlreadyBanned
+			case "22P02":
+				return nil, ErrInvalidData
+			}
+		}
+		return nil, err
+	}
+
+	return ban, nil
+}
+
+// UpdateIPBan changes the reason and the expiry of an IP ban, the subnet stays.
+// It returns ErrRecordNotFound if the ban doesn't exist.
+func (s BanService) UpdateIPBan(ctx context.Context, id int64, reason string, expiresAt *time.Time) (*data.IPBan, error) {
+	query := "UPDATE ip_bans SET reason = $2, expires_at = $3 WHERE id = $1 RETURNING " + ipBanColumns
+	return scanIPBan(s.db.QueryRowContext(ctx, query, id, reason, expiresAt))
+}
+
+// RemoveIPBan lifts an IP ban.
+// It returns ErrRecordNotFound if the ban doesn't exist.
+func (s BanService) RemoveIPBan(ctx context.Context, id int64) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM ip_bans WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrRecordNotFound
+	}
+
 	return nil
 }
+
+func scanIPBan(row scanner) (*data.IPBan, error) {
+	var b data.IPBan
+	if err := row.Scan(&b.ID, &b.Subnet, &b.Reason, &b.CreatedBy, &b.ExpiresAt, &b.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &b, nil
+}
+
+// scanner is a row of a query, from QueryRow or Query.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAppeal(row scanner) (*data.BanAppeal, error) {
+	var a data.BanAppeal
+	err := row.Scan(&a.ID, &a.UserID, &a.Username, &a.Email, &a.BanID, &a.BanReason, &a.BanExpiresAt, &a.Message, &a.Status, &a.Resolution,
+		&a.ResolvedBy, &a.ResolvedAt, &a.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &a, nil
+}
@@ -0,0 +1,382 @@
+// Package classrooms provides functionality for managing classrooms and their rosters.
+package classrooms
+
+import (
+	"context"
+	"database/sql"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// IClassroomService defines the interface for classroom management operations.
+type IClassroomService interface {
+	CreateClassroom(ctx context.Context, c data.ClassroomCreate) (*data.Classroom, error)
+	GetClassroom(ctx context.Context, classroomID uuid.UUID) (*data.Classroom, error)
+	GetUserClassrooms(ctx context.Context, userID uuid.UUID) ([]data.Classroom, error)
+	DeleteClassroom(ctx context.Context, classroomID uuid.UUID) error
+	GetMembers(ctx context.Context, classroomID uuid.UUID) ([]data.ClassroomMember, error)
+	InviteMembers(ctx context.Context, classroomID uuid.UUID, emails []string) error
+	GetInvites(ctx context.Context, userID uuid.UUID) ([]data.ClassroomInvite, error)
+	AcceptInvite(ctx context.Context, classroomID, userID uuid.UUID) error
+	DeclineInvite(ctx context.Context, classroomID, userID uuid.UUID) error
+	RemoveMember(ctx context.Context, classroomID, userID uuid.UUID) error
+	IsMember(ctx context.Context, classroomID, userID uuid.UUID) (bool, error)
+	GetClassroomProjects(ctx context.Context, classroomID uuid.UUID) ([]data.Project, error)
+}
+
+// ClassroomService implements the IClassroomService interface for managing classrooms.
+type ClassroomService struct {
+	db *sql.DB
+}
+
+// NewClassroomService creates a new ClassroomService with the provided database connection.
+func NewClassroomService(db *sql.DB) ClassroomService {
+	return ClassroomService{
+		db: db,
+	}
+}
+
+// CreateClassroom creates a new classroom and adds its owner to the roster as the teacher.
+func (s ClassroomService) CreateClassroom(ctx context.Context, c data.ClassroomCreate) (*data.Classroom, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var classroom data.Classroom
+	query := `
+		INSERT INTO classrooms (name, owner_id)
+		VALUES ($1, $2)
+		RETURNING id, name, owner_id, (SELECT username FROM users WHERE id = $2), created_at`
+
+	err = tx.QueryRowContext(ctx, query, c.Name, c.OwnerID).Scan(
+		&classroom.ID,
+		&classroom.Name,
+		&classroom.OwnerID,
+		&classroom.OwnerUsername,
+		&classroom.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO classroom_members (classroom_id, user_id, role) VALUES ($1, $2, $3)",
+		classroom.ID, c.OwnerID, data.ClassroomTeacher,
+	)
+	if err != nil {
+		return nil, err
+	}
+	classroom.MembersCount = 1
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &classroom, nil
+}
+
+// GetClassroom retrieves a single classroom by its ID.
+// It returns ErrRecordNotFound if the classroom doesn't exist.
+func (s ClassroomService) GetClassroom(ctx context.Context, classroomID uuid.UUID) (*data.Classroom, error) {
+	var classroom data.Classroom
+	query := `
+		SELECT c.id, c.name, c.owner_id, u.username, c.created_at,
+		       (SELECT COUNT(*) FROM classroom_members cm WHERE cm.classroom_id = c.id)
+		FROM classrooms c
+		JOIN users u ON c.owner_id = u.id
+		WHERE c.id = $1`
+
+	err := s.db.QueryRowContext(ctx, query, classroomID).Scan(
+		&classroom.ID,
+		&classroom.Name,
+		&classroom.OwnerID,
+		&classroom.OwnerUsername,
+		&classroom.CreatedAt,
+		&classroom.MembersCount,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &classroom, nil
+}
+
+// GetUserClassrooms retrieves all classrooms the user is a member of.
+func (s ClassroomService) GetUserClassrooms(ctx context.Context, userID uuid.UUID) ([]data.Classroom, error) {
+	query := `
+		SELECT c.id, c.name, c.owner_id, u.username, c.created_at,
+		       (SELECT COUNT(*) FROM classroom_members m WHERE m.classroom_id = c.id)
+		FROM classrooms c
+		JOIN users u ON c.owner_id = u.id
+		JOIN classroom_members cm ON cm.classroom_id = c.id
+		WHERE cm.user_id = $1
+		ORDER BY c.created_at DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return []data.Classroom{}, err
+	}
+	defer rows.Close()
+
+	classrooms := make([]data.Classroom, 0)
+	for rows.Next() {
+		var classroom data.Classroom
+		if err := rows.Scan(
+			&classroom.ID,
+			&classroom.Name,
+			&classroom.OwnerID,
+			&classroom.OwnerUsername,
+			&classroom.CreatedAt,
+			&classroom.MembersCount,
+		); err != nil {
+			return []data.Classroom{}, err
+		}
+		classrooms = append(classrooms, classroom)
+	}
+
+	if err = rows.Err(); err != nil {
+		return []data.Classroom{}, err
+	}
+
+	return classrooms, nil
+}
+
+// DeleteClassroom deletes a classroom. Projects shared with it become private.
+// It returns ErrRecordNotFound if the classroom doesn't exist.
+func (s ClassroomService) DeleteClassroom(ctx context.Context, classroomID uuid.UUID) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM classrooms WHERE id = $1", classroomID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return services.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// GetMembers retrieves the roster of a classroom, teachers first.
+func (s ClassroomService) GetMembers(ctx context.Context, classroomID uuid.UUID) ([]data.ClassroomMember, error) {
+	query := `
+		SELECT cm.user_id, u.username, cm.role, cm.joined_at
+		FROM classroom_members cm
+		JOIN users u ON cm.user_id = u.id
+		WHERE cm.classroom_id = $1
+		ORDER BY cm.role = 'teacher' DESC, u.username`
+
+	rows, err := s.db.QueryContext(ctx, query, classroomID)
+	if err != nil {
+		return []data.ClassroomMember{}, err
+	}
+	defer rows.Close()
+
+	members := make([]data.ClassroomMember, 0)
+	for rows.Next() {
+		var member data.ClassroomMember
+		if err := rows.Scan(&member.UserID, &member.Username, &member.Role, &member.JoinedAt); err != nil {
+			return []data.ClassroomMember{}, err
+		}
+		members = append(members, member)
+	}
+
+	if err = rows.Err(); err != nil {
+		return []data.ClassroomMember{}, err
+	}
+
+	return members, nil
+}
+
+// InviteMembers invites the users registered with the given emails to the classroom as students.
+// Unknown emails, members and users invited already are skipped without telling the caller,
+// so the roster can't be used to find out which emails are registered.
+func (s ClassroomService) InviteMembers(ctx context.Context, classroomID uuid.UUID, emails []string) error {
+	query := `
+		INSERT INTO classroom_invites (classroom_id, user_id)
+		SELECT $1, u.id FROM users u
+		WHERE u.email = ANY($2)
+			AND NOT EXISTS (SELECT 1 FROM classroom_members cm WHERE cm.classroom_id = $1 AND cm.user_id = u.id)
+		ON CONFLICT (classroom_id, user_id) DO NOTHING`
+
+	_, err := s.db.ExecContext(ctx, query, classroomID, pq.Array(emails))
+	return err
+}
+
+// GetInvites retrieves the pending classroom invitations of a user, newest first.
+func (s ClassroomService) GetInvites(ctx context.Context, userID uuid.UUID) ([]data.ClassroomInvite, error) {
+	query := `
+		SELECT c.id, c.name, u.username, ci.created_at
+		FROM classroom_invites ci
+		JOIN classrooms c ON ci.classroom_id = c.id
+		JOIN users u ON c.owner_id = u.id
+		WHERE ci.user_id = $1
+		ORDER BY ci.created_at DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return []data.ClassroomInvite{}, err
+	}
+	defer rows.Close()
+
+	invites := make([]data.ClassroomInvite, 0)
+	for rows.Next() {
+		var invite data.ClassroomInvite
+		if err := rows.Scan(&invite.ClassroomID, &invite.ClassroomName, &invite.OwnerUsername, &invite.CreatedAt); err != nil {
+			return []data.ClassroomInvite{}, err
+		}
+		invites = append(invites, invite)
+	}
+
+	if err = rows.Err(); err != nil {
+		return []data.ClassroomInvite{}, err
+	}
+
+	return invites, nil
+}
+
+// AcceptInvite adds the invited user to the classroom roster as a student.
+// It returns ErrRecordNotFound if the user has no pending invitation to the classroom.
+func (s ClassroomService) AcceptInvite(ctx context.Context, classroomID, userID uuid.UUID) error {
+	query := `
+		WITH accepted AS (
+			DELETE FROM classroom_invites
+			WHERE classroom_id = $1 AND user_id = $2
+			RETURNING classroom_id, user_id
+		)
+		INSERT INTO classroom_members (classroom_id, user_id, role)
+		SELECT classroom_id, user_id, $3 FROM accepted
+		ON CONFLICT (classroom_id, user_id) DO NOTHING`
+
+	res, err := s.db.ExecContext(ctx, query, classroomID, userID, data.ClassroomStudent)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return services.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// DeclineInvite deletes the invitation of a user to the classroom.
+// It returns ErrRecordNotFound if the user has no pending invitation to the classroom.
+func (s ClassroomService) DeclineInvite(ctx context.Context, classroomID, userID uuid.UUID) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM classroom_invites WHERE classroom_id = $1 AND user_id = $2", classroomID, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return services.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// RemoveMember removes a user from the classroom roster. The classroom owner cannot be removed.
+// It returns ErrRecordNotFound if the user is not a removable member.
+func (s ClassroomService) RemoveMember(ctx context.Context, classroomID, userID uuid.UUID) error {
+	query := `
+		DELETE FROM classroom_members cm
+		USING classrooms c
+		WHERE cm.classroom_id = c.id AND cm.classroom_id = $1 AND cm.user_id = $2 AND c.owner_id <> $2`
+
+	res, err := s.db.ExecContext(ctx, query, classroomID, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return services.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// IsMember checks whether the user is on the classroom roster.
+func (s ClassroomService) IsMember(ctx context.Context, classroomID, userID uuid.UUID) (bool, error) {
+	query := "SELECT EXISTS(SELECT 1 FROM classroom_members WHERE classroom_id = $1 AND user_id = $2)"
+	var exists bool
+	err := s.db.QueryRowContext(ctx, query, classroomID, userID).Scan(&exists)
+	return exists, err
+}
+
+// GetClassroomProjects retrieves the projects shared with a classroom.
+func (s ClassroomService) GetClassroomProjects(ctx context.Context, classroomID uuid.UUID) ([]data.Project, error) {
+	query := `
+		SELECT p.id, p.title, p.description, p.data, p.creator_id, u.username, u.verified, p.likes_count, p.views_count, p.featured_until, p.created_at, p.last_edited_at, p.is_public, p.classroom_id, p.forked_from, p.fork_count, p.version, p.hidden_at
+		FROM projects p
+		JOIN users u ON p.creator_id = u.id
+		WHERE p.classroom_id = $1 AND p.hidden_at IS NULL
+		ORDER BY p.last_edited_at DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, classroomID)
+	if err != nil {
+		return []data.Project{}, err
+	}
+	defer rows.Close()
+
+	projects := make([]data.Project, 0)
+	for rows.Next() {
+		var project data.Project
+		if err := rows.Scan(
+			&project.ID,
+			&project.Title,
+			&project.Description,
+			&project.Data,
+			&project.CreatorID,
+			&project.CreatorUsername,
+			&project.CreatorVerified,
+			&project.LikesCount,
+			&project.ViewsCount,
+			&project.FeaturedUntil,
+			&project.CreatedAt,
+			&project.LastEditedAt,
+			&project.IsPublic,
+			&project.ClassroomID,
+			&project.ForkedFrom,
+			&project.ForkCount,
+			&project.Version,
+			&project.HiddenAt,
+		); err != nil {
+			return []data.Project{}, err
+		}
+		projects = append(projects, project)
+	}
+
+	if err = rows.Err(); err != nil {
+		return []data.Project{}, err
+	}
+
+	return projects, nil
+}
@@ -0,0 +1,141 @@
+// Package consents tracks what users agreed to their data being used for.
+package consents
+
+import (
+	"context"
+	"database/sql"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// IConsentService defines the interface for consent operations.
+type IConsentService interface {
+	GetConsents(ctx context.Context, userID uuid.UUID) ([]data.Consent, error)
+	SetConsents(ctx context.Context, userID uuid.UUID, changes map[data.ConsentPurpose]bool) ([]data.Consent, error)
+	HasConsent(ctx context.Context, userID uuid.UUID, purpose data.ConsentPurpose) (bool, error)
+}
+
+// ConsentService implements the IConsentService interface.
+type ConsentService struct {
+	db *sql.DB
+}
+
+// NewConsentService creates a new ConsentService with the provided database connection.
+func NewConsentService(db *sql.DB) ConsentService {
+	return ConsentService{
+		db: db,
+	}
+}
+
+// GetConsents retrieves the user's choice for every purpose, falling back to the default where they never made one.
+// It returns ErrUserNotFound if the user doesn't exist.
+func (s ConsentService) GetConsents(ctx context.Context, userID uuid.UUID) ([]data.Consent, error) {
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)", userID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, services.ErrUserNotFound
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT purpose, granted, granted_at, withdrawn_at, updated_at FROM user_consents WHERE user_id = $1", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recorded := map[data.ConsentPurpose]data.Consent{}
+	for rows.Next() {
+		var c data.Consent
+		if err := rows.Scan(&c.Purpose, &c.Granted, &c.GrantedAt, &c.WithdrawnAt, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		recorded[c.Purpose] = c
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	consents := make([]data.Consent, 0, len(data.ConsentPurposes))
+	for _, purpose := range data.ConsentPurposes {
+		c, ok := recorded[purpose]
+		if !ok {
+			c = data.Consent{Purpose: purpose, Granted: data.DefaultConsent(purpose)}
+		}
+		consents = append(consents, c)
+	}
+
+	return consents, nil
+}
+
+// SetConsents gives or withdraws consent for the given purposes. Choices that don't change anything keep their timestamps.
+// Withdrawing consent for research data also excludes the user's projects from the next data dump.
+// It returns ErrUserNotFound if the user doesn't exist.
+func (s ConsentService) SetConsents(ctx context.Context, userID uuid.UUID, changes map[data.ConsentPurpose]bool) ([]data.Consent, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for purpose, granted := range changes {
+		if err := Record(ctx, tx, userID, purpose, granted); err != nil {
+			return nil, err
+		}
+	}
+
+	if granted, ok := changes[data.ConsentResearchData]; ok {
+		res, err := tx.ExecContext(ctx, "UPDATE users SET research_opt_out = $2 WHERE id = $1", userID, !granted)
+		if err != nil {
+			return nil, err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return nil, err
+		} else if n == 0 {
+			return nil, services.ErrUserNotFound
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return s.GetConsents(ctx, userID)
+}
+
+// HasConsent checks whether the user consents to the purpose, either explicitly or by default.
+// Features processing personal data for one of the purposes must check it first.
+func (s ConsentService) HasConsent(ctx context.Context, userID uuid.UUID, purpose data.ConsentPurpose) (bool, error) {
+	granted := data.DefaultConsent(purpose)
+	err := s.db.QueryRowContext(ctx, "SELECT granted FROM user_consents WHERE user_id = $1 AND purpose = $2", userID, purpose).Scan(&granted)
+	if err != nil && err != sql.ErrNoRows {
+		return false, err
+	}
+	return granted, nil
+}
+
+// Record saves a single consent choice as part of the transaction. It's shared with the services that
+// kept their own switch for a purpose before consents were tracked, so both stay in agreement.
+func Record(ctx context.Context, tx *sql.Tx, userID uuid.UUID, purpose data.ConsentPurpose, granted bool) error {
+	query := `
+		INSERT INTO user_consents (user_id, purpose, granted, granted_at, withdrawn_at)
+		VALUES ($1, $2, $3, CASE WHEN $3 THEN NOW() END, CASE WHEN NOT $3 THEN NOW() END)
+		ON CONFLICT (user_id, purpose) DO UPDATE
+		SET granted = EXCLUDED.granted,
+		    granted_at = CASE WHEN EXCLUDED.granted AND NOT user_consents.granted THEN NOW() ELSE user_consents.granted_at END,
+		    withdrawn_at = CASE WHEN NOT EXCLUDED.granted AND user_consents.granted THEN NOW() ELSE user_consents.withdrawn_at END,
+		    updated_at = CASE WHEN EXCLUDED.granted <> user_consents.granted THEN NOW() ELSE user_consents.updated_at END`
+
+	_, err := tx.ExecContext(ctx, query, userID, purpose, granted)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return services.ErrUserNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+// Package credits handles the co-authors project owners credit on their projects.
+package credits
+
+import (
+	"context"
+	"database/sql"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ICreditService defines the interface for co-author credit operations.
+type ICreditService interface {
+	AddCredit(ctx context.Context, projectID, userID uuid.UUID) (*data.Credit, error)
+	RemoveCredit(ctx context.Context, projectID, userID uuid.UUID) error
+	ListCredits(ctx context.Context, projectID uuid.UUID, includeUnaccepted bool) ([]data.Credit, error)
+	ListPendingCredits(ctx context.Context, userID uuid.UUID) ([]data.Credit, error)
+	RespondToCredit(ctx context.Context, projectID, userID uuid.UUID, accept bool) (*data.Credit, error)
+}
+
+// CreditService implements the ICreditService interface.
+type CreditService struct {
+	db *sql.DB
+}
+
+// NewCreditService creates a new CreditService with the provided database connection.
+func NewCreditService(db *sql.DB) CreditService {
+	return CreditService{
+		db: db,
+	}
+}
+
+const selectCredit = `
+	SELECT pc.project_id, p.title, pc.user_id, u.username, pc.status, pc.created_at, pc.responded_at
+	FROM project_credits pc
+	JOIN projects p ON pc.project_id = p.id
+	JOIN users u ON pc.user_id = u.id`
+
+// AddCredit credits the user as a co-author of the project. The credit stays pending until the user accepts it.
+// It returns ErrAlreadyCredited if the user was credited before and ErrRecordNotFound if the project or user doesn't exist.
+func (s CreditService) AddCredit(ctx context.Context, projectID, userID uuid.UUID) (*data.Credit, error) {
+	_, err := s.db.ExecContext(ctx, "INSERT INTO project_credits (project_id, user_id) VALUES ($1, $2)", projectID, userID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code {
+			case "23505":
+				return nil, services.ErrAlreadyCredited
+			case "23503":
+				return nil, services.ErrRecordNotFound
+			}
+		}
+		return nil, err
+	}
+
+	return s.getCredit(ctx, projectID, userID)
+}
+
+// RemoveCredit removes the credit of the user from the project, whatever its status.
+// It returns ErrRecordNotFound if the user isn't credited.
+func (s CreditService) RemoveCredit(ctx context.Context, projectID, userID uuid.UUID) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM project_credits WHERE project_id = $1 AND user_id = $2", projectID, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return services.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// ListCredits retrieves the co-authors of the project, oldest credit first.
+// Pending and declined credits are only included when includeUnaccepted is set, for the project owner.
+func (s CreditService) ListCredits(ctx context.Context, projectID uuid.UUID, includeUnaccepted bool) ([]data.Credit, error) {
+	query := selectCredit + " WHERE pc.project_id = $1"
+	if !includeUnaccepted {
+		query += " AND pc.status = 'accepted'"
+	}
+	query += " ORDER BY pc.created_at"
+
+	return s.list(ctx, query, projectID)
+}
+
+// ListPendingCredits retrieves the credits waiting for the user's answer, newest first.
+func (s CreditService) ListPendingCredits(ctx context.Context, userID uuid.UUID) ([]data.Credit, error) {
+	return s.list(ctx, selectCredit+" WHERE pc.user_id = $1 AND pc.status = 'pending' ORDER BY pc.created_at DESC", userID)
+}
+
+// RespondToCredit accepts or declines a pending credit on behalf of the credited user.
+// It returns ErrRecordNotFound if the user has no pending credit on the project.
+func (s CreditService) RespondToCredit(ctx context.Context, projectID, userID uuid.UUID, accept bool) (*data.Credit, error) {
+	status := data.CreditDeclined
+	if accept {
+		status = data.CreditAccepted
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE project_credits SET status = $3, responded_at = NOW()
+		WHERE project_id = $1 AND user_id = $2 AND status = 'pending'`,
+		projectID, userID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		return nil, services.ErrRecordNotFound
+	}
+
+	return s.getCredit(ctx, projectID, userID)
+}
+
+func (s CreditService) getCredit(ctx context.Context, projectID, userID uuid.UUID) (*data.Credit, error) {
+	var c data.Credit
+	err := s.db.QueryRowContext(ctx, selectCredit+" WHERE pc.project_id = $1 AND pc.user_id = $2", projectID, userID).
+		Scan(&c.ProjectID, &c.ProjectTitle, &c.UserID, &c.Username, &c.Status, &c.CreatedAt, &c.RespondedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (s CreditService) list(ctx context.Context, query string, args ...any) ([]data.Credit, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	credits := []data.Credit{}
+	for rows.Next() {
+		var c data.Credit
+		if err := rows.Scan(&c.ProjectID, &c.ProjectTitle, &c.UserID, &c.Username, &c.Status, &c.CreatedAt, &c.RespondedAt); err != nil {
+			return nil, err
+		}
+		credits = append(credits, c)
+	}
+
+	return credits, rows.Err()
+}
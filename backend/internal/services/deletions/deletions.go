@@ -0,0 +1,207 @@
+// Package deletions deletes the accounts their owners asked to delete, once they had time to change their mind.
+package deletions
+
+import (
+	"context"
+	"database/sql"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// IDeletionService defines the interface for account deletion operations.
+type IDeletionService interface {
+	Schedule(ctx context.Context, userID uuid.UUID) (*data.AccountDeletion, error)
+	ListPending(ctx context.Context) ([]data.AccountDeletion, error)
+	DeleteDue(ctx context.Context) (int, error)
+}
+
+// DeletionService implements the IDeletionService interface.
+// Scheduled deletions are cancelled by logging in, see auth.AuthService.
+type DeletionService struct {
+	db      *sql.DB
+	storage storage.Storage
+	cfg     config.DeletionsConfig
+}
+
+// NewDeletionService creates a new DeletionService, which also deletes the files of the users from the object storage.
+func NewDeletionService(db *sql.DB, store storage.Storage, cfg config.DeletionsConfig) DeletionService {
+	return DeletionService{
+		db:      db,
+		storage: store,
+		cfg:     cfg,
+	}
+}
+
+// Schedule schedules the deletion of the user's account at the end of the grace period and signs them out everywhere
+// by revoking all of their tokens. Scheduling an account already scheduled keeps the earlier date.
+// It returns ErrUserNotFound if the user doesn't exist, and ErrUserInRegion if their data lives in a regional
+// database, which deleting them here would leave behind.
+func (s DeletionService) Schedule(ctx context.Context, userID uuid.UUID) (*data.AccountDeletion, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var region string
+	if err := tx.QueryRowContext(ctx, "SELECT region FROM users WHERE id = $1 FOR SHARE", userID).Scan(&region); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrUserNotFound
+		}
+		return nil, err
+	}
+	if region != "" {
+		return nil, services.ErrUserInRegion
+	}
+
+	deletion := data.AccountDeletion{UserID: userID}
+	query := `
+		INSERT INTO account_deletions (user_id, scheduled_for)
+		VALUES ($1, NOW() + make_interval(secs => $2))
+		ON CONFLICT (user_id) DO UPDATE SET scheduled_for = account_deletions.scheduled_for
+		RETURNING requested_at, scheduled_for,
+		          (SELECT username FROM users WHERE id = $1), (SELECT email FROM users WHERE id = $1)`
+	err = tx.QueryRowContext(ctx, query, userID, s.cfg.GracePeriod.Seconds()).Scan(
+		&deletion.RequestedAt, &deletion.ScheduledFor, &deletion.Username, &deletion.Email,
+	)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return nil, services.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM tokens WHERE user_id = $1", userID); err != nil {
+		return nil, err
+	}
+
+	return &deletion, tx.Commit()
+}
+
+// ListPending retrieves the scheduled deletions, the soonest first.
+func (s DeletionService) ListPending(ctx context.Context) ([]data.AccountDeletion, error) {
+	query := `
+		SELECT d.user_id, u.username, u.email, d.requested_at, d.scheduled_for
+		FROM account_deletions d
+		JOIN users u ON u.id = d.user_id
+		ORDER BY d.scheduled_for, d.user_id`
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return []data.AccountDeletion{}, err
+	}
+	defer rows.Close()
+
+	deletions := make([]data.AccountDeletion, 0)
+	for rows.Next() {
+		var d data.AccountDeletion
+		if err := rows.Scan(&d.UserID, &d.Username, &d.Email, &d.RequestedAt, &d.ScheduledFor); err != nil {
+			return []data.AccountDeletion{}, err
+		}
+		deletions = append(deletions, d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return []data.AccountDeletion{}, err
+	}
+
+	return deletions, nil
+}
+
+// DeleteDue deletes the accounts whose grace period ended and returns how many it deleted.
+func (s DeletionService) DeleteDue(ctx context.Context) (int, error) {
+	deleted := 0
+	for {
+		ok, err := s.deleteNext(ctx)
+		if err != nil || !ok {
+			return deleted, err
+		}
+		deleted++
+	}
+}
+
+// deleteNext deletes the next account past its grace period, reporting false when there is none.
+//
+// Everything the user owns is deleted along with the user: their projects, tokens and settings.
+// Users whose data lives in a regional database are never deleted here, only their directory entry would be.
+// Their likes of other projects are kept without naming them, as are the events they caused, so like counts
+// and counters rebuilt from the event log still add up. The audit log is kept as it is.
+func (s DeletionService) deleteNext(ctx context.Context) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var userID uuid.UUID
+	var avatarKey sql.NullString
+	query := `
+		SELECT d.user_id, u.avatar_key
+		FROM account_deletions d
+		JOIN users u ON u.id = d.user_id
+		WHERE d.scheduled_for <= NOW() AND u.region = ''
+		ORDER BY d.scheduled_for
+		LIMIT 1
+		FOR UPDATE OF d SKIP LOCKED`
+	if err := tx.QueryRowContext(ctx, query).Scan(&userID, &avatarKey); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	var keys []string
+	if avatarKey.Valid {
+		for _, size := range data.AvatarSizes {
+			keys = append(keys, data.AvatarImageKey(avatarKey.String, size))
+		}
+	}
+	rows, err := tx.QueryContext(ctx, "SELECT object_key FROM data_exports WHERE user_id = $1 AND object_key IS NOT NULL", userID)
+	if err != nil {
+		return false, err
+	}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			rows.Close()
+			return false, err
+		}
+		keys = append(keys, key)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+
+	statements := []string{
+		"UPDATE domain_events SET actor_id = NULL WHERE actor_id = $1",
+		"UPDATE events SET actor_id = NULL WHERE actor_id = $1",
+		// bans don't cascade, a ban must not disappear along with the admin who issued it
+		"DELETE FROM banned_users WHERE user_id = $1",
+		"UPDATE banned_users SET banned_by = NULL WHERE banned_by = $1",
+		"DELETE FROM users WHERE id = $1",
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement, userID); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	for _, key := range keys {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			logging.FromContext(ctx).ErrorContext(ctx, "Failed to delete file of deleted user", "key", key, "user_id", userID, "error", err)
+		}
+	}
+
+	return true, nil
+}
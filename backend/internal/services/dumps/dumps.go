@@ -0,0 +1,281 @@
+// Package dumps produces anonymized dumps of public data for research use,
+// so bulk consumers can download a dataset instead of crawling the API.
+package dumps
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/consents"
+
+	"github.com/google/uuid"
+)
+
+// IDumpService defines the interface for public data dump operations.
+type IDumpService interface {
+	Generate() (*data.DataDump, error)
+	List() ([]data.DataDump, error)
+	Path(filename string) (string, error)
+	IsOptedOut(userID uuid.UUID) (bool, error)
+	SetOptOut(userID uuid.UUID, optOut bool) error
+}
+
+// DumpService implements the IDumpService interface.
+type DumpService struct {
+	db  *sql.DB
+	cfg config.DumpsConfig
+}
+
+// NewDumpService creates a new DumpService with the provided database connection and dump settings.
+func NewDumpService(db *sql.DB, cfg config.DumpsConfig) DumpService {
+	return DumpService{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+// Generate writes a gzipped JSON lines dump of public projects, records it in the index
+// and removes dumps beyond the configured number to keep.
+// Projects of users who opted out are left out and forks only reference projects included in the dump.
+func (s DumpService) Generate() (*data.DataDump, error) {
+	if err := os.MkdirAll(s.cfg.Dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	// a fresh salt per dump keeps projects and creators from being linked across dumps or back to the site
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	filename := fmt.Sprintf("projects-%s.jsonl.gz", now.Format("20060102-150405"))
+	path := filepath.Join(s.cfg.Dir, filename)
+
+	file, err := os.CreateTemp(s.cfg.Dir, ".dump-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	hash := sha256.New()
+	gz := gzip.NewWriter(io.MultiWriter(file, hash))
+	records, err := s.writeProjects(gz, salt)
+	if err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return nil, err
+	}
+
+	dump := data.DataDump{
+		Filename:  filename,
+		Records:   records,
+		SizeBytes: info.Size(),
+		SHA256:    hex.EncodeToString(hash.Sum(nil)),
+	}
+
+	err = s.db.QueryRow(
+		"INSERT INTO data_dumps (filename, records, size_bytes, sha256) VALUES ($1, $2, $3, $4) RETURNING id, created_at",
+		dump.Filename, dump.Records, dump.SizeBytes, dump.SHA256,
+	).Scan(&dump.ID, &dump.CreatedAt)
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	if err := s.prune(); err != nil {
+		return nil, err
+	}
+
+	return &dump, nil
+}
+
+func (s DumpService) writeProjects(w io.Writer, salt []byte) (int, error) {
+	query := `
+		SELECT p.id, p.creator_id, p.likes_count, p.fork_count,
+		       CASE WHEN EXISTS(
+		           SELECT 1 FROM projects fp JOIN users fu ON fp.creator_id = fu.id
+		           WHERE fp.id = p.forked_from AND fp.is_public = TRUE AND fp.hidden_at IS NULL AND fu.research_opt_out = FALSE
+		       ) THEN p.forked_from END,
+		       p.created_at, p.last_edited_at
+		FROM projects p
+		JOIN users u ON p.creator_id = u.id
+		WHERE p.is_public = TRUE AND p.hidden_at IS NULL AND u.research_opt_out = FALSE
+		ORDER BY p.created_at`
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	enc := json.NewEncoder(w)
+	records := 0
+	for rows.Next() {
+		var project data.DumpProject
+		var id, creatorID uuid.UUID
+		var forkedFrom *uuid.UUID
+		if err := rows.Scan(
+			&id,
+			&creatorID,
+			&project.LikesCount,
+			&project.ForkCount,
+			&forkedFrom,
+			&project.CreatedAt,
+			&project.LastEditedAt,
+		); err != nil {
+			return 0, err
+		}
+
+		project.ID = pseudonym(salt, id)
+		project.Creator = pseudonym(salt, creatorID)
+		if forkedFrom != nil {
+			fork := pseudonym(salt, *forkedFrom)
+			project.ForkedFrom = &fork
+		}
+
+		if err := enc.Encode(project); err != nil {
+			return 0, err
+		}
+		records++
+	}
+
+	return records, rows.Err()
+}
+
+// pseudonym replaces an id with a salted hash, so the same id gets the same pseudonym within a dump.
+func pseudonym(salt []byte, id uuid.UUID) string {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write(id[:])
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// prune removes the dumps beyond the configured number to keep, oldest first.
+func (s DumpService) prune() error {
+	if s.cfg.Keep <= 0 {
+		return nil
+	}
+
+	rows, err := s.db.Query("DELETE FROM data_dumps WHERE id NOT IN (SELECT id FROM data_dumps ORDER BY created_at DESC LIMIT $1) RETURNING filename", s.cfg.Keep)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var filename string
+		if err := rows.Scan(&filename); err != nil {
+			return err
+		}
+		if err := os.Remove(filepath.Join(s.cfg.Dir, filename)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// List retrieves the index of available dumps, newest first.
+func (s DumpService) List() ([]data.DataDump, error) {
+	rows, err := s.db.Query("SELECT id, filename, records, size_bytes, sha256, created_at FROM data_dumps ORDER BY created_at DESC")
+	if err != nil {
+		return []data.DataDump{}, err
+	}
+	defer rows.Close()
+
+	dumps := make([]data.DataDump, 0)
+	for rows.Next() {
+		var dump data.DataDump
+		if err := rows.Scan(&dump.ID, &dump.Filename, &dump.Records, &dump.SizeBytes, &dump.SHA256, &dump.CreatedAt); err != nil {
+			return []data.DataDump{}, err
+		}
+		dumps = append(dumps, dump)
+	}
+
+	if err = rows.Err(); err != nil {
+		return []data.DataDump{}, err
+	}
+
+	return dumps, nil
+}
+
+// Path returns the location on disk of an indexed dump.
+// Only filenames present in the index are resolved. It returns ErrRecordNotFound otherwise.
+func (s DumpService) Path(filename string) (string, error) {
+	var exists bool
+	if err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM data_dumps WHERE filename = $1)", filename).Scan(&exists); err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", services.ErrRecordNotFound
+	}
+
+	return filepath.Join(s.cfg.Dir, filename), nil
+}
+
+// IsOptedOut checks whether the user opted out of public data dumps.
+func (s DumpService) IsOptedOut(userID uuid.UUID) (bool, error) {
+	var optOut bool
+	err := s.db.QueryRow("SELECT research_opt_out FROM users WHERE id = $1", userID).Scan(&optOut)
+	if err == sql.ErrNoRows {
+		return false, services.ErrUserNotFound
+	}
+	return optOut, err
+}
+
+// SetOptOut opts the user out of, or back into, public data dumps. It applies from the next dump on.
+// The choice is recorded as the user's research data consent as well.
+func (s DumpService) SetOptOut(userID uuid.UUID, optOut bool) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec("UPDATE users SET research_opt_out = $2 WHERE id = $1", userID, optOut)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return services.ErrUserNotFound
+	}
+
+	if err := consents.Record(context.Background(), tx, userID, data.ConsentResearchData, !optOut); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
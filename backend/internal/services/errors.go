@@ -7,23 +7,104 @@ import (
 )
 
 var (
-	ErrInactiveAccount    = errors.New("account is not activated")
-	ErrAccountSuspended   = errors.New("account is suspended")
-	ErrUserExists         = errors.New("user already exists")
-	ErrUserNotFound       = errors.New("user not found")
-	ErrProjectNotFound    = errors.New("project not found")
-	ErrDuplicateEmail     = errors.New("email already in use")
-	ErrDuplicateUsername  = errors.New("username already in use")
-	ErrRecordNotFound     = errors.New("record not found")
-	ErrInvalidToken       = errors.New("invalid or expired token")
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrExpiredToken       = errors.New("token has expired")
-	ErrEditConflict       = errors.New("edit conflict")
-	ErrInternal           = errors.New("internal server error")
-	ErrInvalidData        = errors.New("invalid data: the provided input does not match the expected format")
-	ErrNoFields           = errors.New("no fields provided")
+	ErrInactiveAccount        = errors.New("account is not activated")
+	ErrAccountSuspended       = errors.New("account is suspended")
+	ErrUserExists             = errors.New("user already exists")
+	ErrUserNotFound           = errors.New("user not found")
+	ErrProjectNotFound        = errors.New("project not found")
+	ErrDuplicateEmail         = errors.New("email already in use")
+	ErrDuplicateUsername      = errors.New("username already in use")
+	ErrRecordNotFound         = errors.New("record not found")
+	ErrInvalidToken           = errors.New("invalid or expired token")
+	ErrInvalidCredentials     = errors.New("invalid credentials")
+	ErrExpiredToken           = errors.New("token has expired")
+	ErrEditConflict           = errors.New("edit conflict")
+	ErrInternal               = errors.New("internal server error")
+	ErrInvalidData            = errors.New("invalid data: the provided input does not match the expected format")
+	ErrNoFields               = errors.New("no fields provided")
+	ErrPlatformExists         = errors.New("platform already registered")
+	ErrUnknownPlatform        = errors.New("unknown LTI platform")
+	ErrUnsupportedMessage     = errors.New("unsupported LTI message type")
+	ErrGradingUnavailable     = errors.New("grade passback is not available for this launch")
+	ErrAlreadyQueued          = errors.New("project is already queued")
+	ErrUnknownProvider        = errors.New("unknown login provider")
+	ErrUnverifiedEmail        = errors.New("provider account has no verified email")
+	ErrTooManyAttempts        = errors.New("too many attempts")
+	ErrKeyRotationUnavailable = errors.New("signing key rotation requires a key directory")
+	ErrAlreadyVerified        = errors.New("account is already verified")
+	ErrVerificationPending    = errors.New("a verification request is already pending")
+	ErrAlreadyCredited        = errors.New("user is already credited")
+	ErrAlreadyAllowlisted     = errors.New("subnet or domain is already allowlisted")
+	ErrRenderLimit            = errors.New("program exceeds the rendering limits")
+	ErrRenderPending          = errors.New("thumbnail is waiting for a render worker")
+	ErrUploadOffset           = errors.New("chunk does not start where the upload left off")
+	ErrUploadSize             = errors.New("chunk exceeds the size of the upload")
+	ErrUploadIncomplete       = errors.New("upload is incomplete")
+	ErrAlreadyReported        = errors.New("project is already reported")
+	ErrAlreadyTakenDown       = errors.New("project is already taken down")
+	ErrInvalidImage           = errors.New("file is not a supported image")
+	ErrImageTooLarge          = errors.New("image exceeds the size limit")
+	ErrExportPending          = errors.New("a data export is already being prepared")
+	ErrNotBanned              = errors.New("account is not banned")
+	ErrAlreadyAppealed        = errors.New("the ban has been appealed already")
+	ErrAppealResolved         = errors.New("the appeal has been resolved already")
+	ErrIPAlreadyBanned        = errors.New("the IP range is banned already")
+	ErrUsernameReserved       = errors.New("username is reserved")
+	ErrFolderTooDeep          = errors.New("folders can only be nested one level deep")
+	ErrFolderOrder            = errors.New("the order must list every item exactly once")
+	ErrPrivilegedAccount      = errors.New("staff accounts can't sign in through an LMS")
+	ErrUserInRegion           = errors.New("the user's data lives in a regional database")
 )
 
-func BanMessage(reason string, expiresAt time.Time) error {
+// Quotas a change can exceed.
+const (
+	QuotaProjects = "projects"
+	QuotaBytes    = "bytes"
+	QuotaStorage  = "storage"
+	QuotaCompute  = "compute"
+)
+
+// QuotaError is returned when a change would take a user over the quota of their role.
+type QuotaError struct {
+	Quota string `json:"quota"` // QuotaProjects, QuotaBytes, QuotaStorage or QuotaCompute
+	Limit int    `json:"limit"`
+	Used  int    `json:"used"` // projects owned, size of the submitted flow data, storage the change would take up, or compute used today
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s quota exceeded: %d of %d", e.Quota, e.Used, e.Limit)
+}
+
+// UsernameCooldownError is returned when a user changes their username again before UsernameChangeInterval has passed.
+type UsernameCooldownError struct {
+	NextChange time.Time `json:"next_change"`
+}
+
+func (e *UsernameCooldownError) Error() string {
+	return fmt.Sprintf("username can't be changed before %s", e.NextChange.Format(time.RFC3339))
+}
+
+// Budgets a single run can exceed.
+const (
+	RunSteps    = "steps"
+	RunTime     = "time"
+	RunSegments = "segments"
+)
+
+// RunLimitError is returned when a program run exceeds a budget of the user's role.
+type RunLimitError struct {
+	Budget string `json:"budget"` // RunSteps, RunTime or RunSegments
+	Limit  int    `json:"limit"`  // instructions, milliseconds or lines
+}
+
+func (e *RunLimitError) Error() string {
+	return fmt.Sprintf("run exceeds the %s budget of %d", e.Budget, e.Limit)
+}
+
+// BanMessage describes a ban to the banned user, expiresAt is nil for a permanent ban.
+func BanMessage(reason string, expiresAt *time.Time) error {
+	if expiresAt == nil {
+		return fmt.Errorf("account is permanently suspended. Reason: %s", reason)
+	}
 	return fmt.Errorf("account is suspended. Reason: %s. Expires at: %s", reason, expiresAt.Local().Format("2006-01-02"))
 }
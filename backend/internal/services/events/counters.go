@@ -0,0 +1,92 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// projectCounters rebuilds the likes, views and fork counters of projects.
+type projectCounters struct {
+	counters map[uuid.UUID]*data.ProjectCounters
+}
+
+func newProjectCounters() Projection {
+	return &projectCounters{counters: map[uuid.UUID]*data.ProjectCounters{}}
+}
+
+func (p *projectCounters) Apply(e data.DomainEvent) error {
+	c, ok := p.counters[e.ProjectID]
+	if !ok {
+		c = &data.ProjectCounters{}
+		p.counters[e.ProjectID] = c
+	}
+
+	// decrements stop at zero the way the services apply them
+	switch e.Type {
+	case data.DomainCountersSeeded:
+		if err := json.Unmarshal(e.Payload, c); err != nil {
+			return fmt.Errorf("event %d: %w", e.ID, err)
+		}
+	case data.DomainProjectLiked:
+		c.Likes++
+	case data.DomainProjectUnliked:
+		c.Likes = max(0, c.Likes-1)
+	case data.DomainProjectViewed:
+		c.Views++
+	case data.DomainProjectForked:
+		c.Forks++
+	case data.DomainForkDeleted:
+		c.Forks = max(0, c.Forks-1)
+	}
+
+	return nil
+}
+
+// Save sets the counters of the projects in the log and zeroes those of the projects without events.
+// Events of deleted projects are left out.
+func (p *projectCounters) Save(ctx context.Context, tx *sql.Tx) (int64, error) {
+	ids := make([]uuid.UUID, 0, len(p.counters))
+	likes := make([]int64, 0, len(p.counters))
+	views := make([]int64, 0, len(p.counters))
+	forks := make([]int64, 0, len(p.counters))
+	for id, c := range p.counters {
+		ids = append(ids, id)
+		likes = append(likes, int64(c.Likes))
+		views = append(views, int64(c.Views))
+		forks = append(forks, int64(c.Forks))
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE projects p SET likes_count = c.likes, views_count = c.views, fork_count = c.forks
+		FROM unnest($1::uuid[], $2::int[], $3::int[], $4::int[]) AS c(id, likes, views, forks)
+		WHERE p.id = c.id AND (p.likes_count, p.views_count, p.fork_count) IS DISTINCT FROM (c.likes, c.views, c.forks)`,
+		pq.Array(ids), pq.Array(likes), pq.Array(views), pq.Array(forks))
+	if err != nil {
+		return 0, err
+	}
+	fixed, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	res, err = tx.ExecContext(ctx, `
+		UPDATE projects SET likes_count = 0, views_count = 0, fork_count = 0
+		WHERE id <> ALL($1::uuid[]) AND (likes_count, views_count, fork_count) <> (0, 0, 0)`,
+		pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+	zeroed, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return fixed + zeroed, nil
+}
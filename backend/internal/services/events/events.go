@@ -0,0 +1,141 @@
+// Package events keeps the append-only log of domain events and rebuilds the tables derived from it.
+//
+// Services append an event in the same transaction as the change it records, before touching the rows it updates,
+// so the log and the counters it explains can't disagree, and a rebuild holding the log never waits on a writer
+// that waits on it. Rebuilding replays the whole log into a projection and overwrites the derived rows that drifted.
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+)
+
+// ErrUnknownProjection is returned when rebuilding a projection that doesn't exist.
+var ErrUnknownProjection = errors.New("unknown projection")
+
+// IEventService defines the interface for replaying the event log.
+type IEventService interface {
+	Rebuild(ctx context.Context, projection string, dryRun bool) (*data.ProjectionRebuild, error)
+}
+
+// EventService implements the IEventService interface.
+type EventService struct {
+	db *sql.DB
+}
+
+// NewEventService creates a new EventService with the provided database connection.
+func NewEventService(db *sql.DB) EventService {
+	return EventService{db: db}
+}
+
+// execer is a database connection or a transaction.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Append adds an event about a project to the log. payload may be nil.
+func Append(ctx context.Context, db execer, eventType string, projectID uuid.UUID, actorID *uuid.UUID, payload interface{}) error {
+	encoded := []byte("{}")
+	if payload != nil {
+		var err error
+		if encoded, err = json.Marshal(payload); err != nil {
+			return err
+		}
+	}
+
+	_, err := db.ExecContext(ctx, "INSERT INTO domain_events (type, project_id, actor_id, payload) VALUES ($1, $2, $3, $4)", eventType, projectID, actorID, encoded)
+	return err
+}
+
+// Projection is a table derived from the event log.
+type Projection interface {
+	// Apply folds the next event of the log into the projection.
+	Apply(e data.DomainEvent) error
+	// Save writes the projection over its table and returns how many rows changed.
+	Save(ctx context.Context, tx *sql.Tx) (int64, error)
+}
+
+// projections are the projections that can be rebuilt, by name.
+var projections = map[string]func() Projection{
+	"project_counters": newProjectCounters,
+}
+
+// Projections returns the names of the projections that can be rebuilt.
+func Projections() []string {
+	names := make([]string, 0, len(projections))
+	for name := range projections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Rebuild replays the event log into the projection and overwrites the rows of its table that drifted.
+// Appending events waits for the rebuild, so none are missed between the replay and the save.
+// A dry run reports how many rows drifted without changing them.
+func (s EventService) Rebuild(ctx context.Context, projection string, dryRun bool) (*data.ProjectionRebuild, error) {
+	newProjection, ok := projections[projection]
+	if !ok {
+		return nil, ErrUnknownProjection
+	}
+	p := newProjection()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "LOCK TABLE domain_events IN SHARE MODE"); err != nil {
+		return nil, err
+	}
+
+	rebuild := data.ProjectionRebuild{Projection: projection, DryRun: dryRun}
+	if rebuild.Events, err = replay(ctx, tx, p); err != nil {
+		return nil, err
+	}
+
+	if rebuild.RowsFixed, err = p.Save(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		return &rebuild, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &rebuild, nil
+}
+
+// replay applies every event of the log to the projection in order and returns how many there were.
+func replay(ctx context.Context, tx *sql.Tx, p Projection) (int64, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT id, type, project_id, actor_id, payload, created_at FROM domain_events ORDER BY id")
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var n int64
+	for rows.Next() {
+		var e data.DomainEvent
+		if err := rows.Scan(&e.ID, &e.Type, &e.ProjectID, &e.ActorID, &e.Payload, &e.CreatedAt); err != nil {
+			return 0, err
+		}
+		if err := p.Apply(e); err != nil {
+			return 0, err
+		}
+		n++
+	}
+
+	return n, rows.Err()
+}
@@ -0,0 +1,163 @@
+package exports
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// sections are the files of an archive besides the projects, each the JSON a query of the user $1 returns.
+// Secrets, like password and token hashes, are never part of them; tokens are listed by what they're for and when they expire.
+var sections = []struct {
+	name  string
+	query string
+}{
+	{"account.json", `
+		SELECT row_to_json(a) FROM (
+			SELECT u.id, u.email, u.username, r.name AS role, u.activated, u.verified, u.region, u.created_at, u.last_login,
+			       up.bio, up.website, up.social_links
+			FROM users u
+			JOIN roles r ON r.id = u.role_id
+			LEFT JOIN user_profiles up ON up.user_id = u.id
+			WHERE u.id = $1
+		) a`},
+	{"settings.json", `
+		SELECT json_build_object(
+			'consents', (SELECT COALESCE(json_agg(c ORDER BY c.purpose), '[]') FROM (
+				SELECT purpose, granted, granted_at, withdrawn_at FROM user_consents WHERE user_id = $1) c),
+			'notification_preferences', (SELECT row_to_json(n) FROM (
+				SELECT likes, digest FROM notification_preferences WHERE user_id = $1) n),
+			'trusted_locations', (SELECT COALESCE(json_agg(l ORDER BY l.created_at), '[]') FROM (
+				SELECT country, created_at, last_seen_at FROM trusted_locations WHERE user_id = $1) l),
+			'linked_accounts', (SELECT COALESCE(json_agg(o ORDER BY o.created_at), '[]') FROM (
+				SELECT provider, email, created_at FROM oauth_identities WHERE user_id = $1) o)
+		)`},
+	{"likes.json", `
+		SELECT COALESCE(json_agg(l ORDER BY l.liked_at), '[]') FROM (
+			SELECT p.id AS project_id, p.title, pl.created_at AS liked_at
+			FROM project_likes pl
+			JOIN projects p ON p.id = pl.project_id
+			WHERE pl.user_id = $1
+		) l`},
+	{"shared_with_me.json", `
+		SELECT COALESCE(json_agg(m ORDER BY m.added_at), '[]') FROM (
+			SELECT p.id AS project_id, p.title, pm.role, pm.added_at
+			FROM project_members pm
+			JOIN projects p ON p.id = pm.project_id
+			WHERE pm.user_id = $1
+		) m`},
+	{"tokens.json", `
+		SELECT COALESCE(json_agg(t ORDER BY t.created_at), '[]') FROM (
+			SELECT scope, created_at, expires_at FROM tokens WHERE user_id = $1
+		) t`},
+}
+
+// recipient is who an archive is emailed to.
+type recipient struct {
+	email    string
+	username string
+}
+
+// build writes the archive of everything stored about a user: the sections, a file per project they created
+// with its program, and their avatar.
+func (s ExportService) build(ctx context.Context, userID uuid.UUID) ([]byte, recipient, error) {
+	var to recipient
+	var avatarKey *string
+	err := s.db.QueryRowContext(ctx, "SELECT email, username, avatar_key FROM users WHERE id = $1", userID).Scan(&to.email, &to.username, &avatarKey)
+	if err != nil {
+		return nil, to, err
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	now := time.Now()
+
+	for _, section := range sections {
+		var content []byte
+		if err := s.db.QueryRowContext(ctx, section.query, userID).Scan(&content); err != nil {
+			return nil, to, fmt.Errorf("%s: %w", section.name, err)
+		}
+		if err := writeJSON(archive, section.name, content, now); err != nil {
+			return nil, to, err
+		}
+	}
+
+	if err := s.writeProjects(ctx, archive, userID, now); err != nil {
+		return nil, to, err
+	}
+
+	if avatarKey != nil {
+		avatar, _, err := s.storage.Get(ctx, data.AvatarImageKey(*avatarKey, data.AvatarSizes[0]))
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return nil, to, err
+		}
+		if err == nil {
+			if err := writeFile(archive, "avatar.png", avatar, now); err != nil {
+				return nil, to, err
+			}
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, to, err
+	}
+	return buf.Bytes(), to, nil
+}
+
+// writeProjects adds a file per project the user created, with its program as stored.
+func (s ExportService) writeProjects(ctx context.Context, archive *zip.Writer, userID uuid.UUID, now time.Time) error {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT p.id, row_to_json(p) FROM (
+			SELECT id, title, description, is_public, classroom_id, forked_from, likes_count, views_count, fork_count,
+			       created_at, last_edited_at, data
+			FROM projects
+			WHERE creator_id = $1
+			ORDER BY created_at
+		) p`,
+		userID,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var projectID uuid.UUID
+		var content []byte
+		if err := rows.Scan(&projectID, &content); err != nil {
+			return err
+		}
+		if err := writeJSON(archive, "projects/"+projectID.String()+".json", content, now); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// writeJSON adds an indented copy of a JSON document to the archive, for people reading it without tools.
+func writeJSON(archive *zip.Writer, name string, content []byte, modified time.Time) error {
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, content, "", "  "); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return writeFile(archive, name, indented.Bytes(), modified)
+}
+
+func writeFile(archive *zip.Writer, name string, content []byte, modified time.Time) error {
+	w, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
@@ -0,0 +1,279 @@
+// Package exports prepares the copies of their data users ask for, as ZIP archives kept in object storage for a while.
+package exports
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/mail"
+	"NodeTurtleAPI/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// IExportService defines the interface for data export operations.
+type IExportService interface {
+	RequestExport(ctx context.Context, userID uuid.UUID) (*data.DataExport, error)
+	ListExports(ctx context.Context, userID uuid.UUID) ([]data.DataExport, error)
+	Download(ctx context.Context, token string) ([]byte, error)
+	PreparePending(ctx context.Context) (int, error)
+	DeleteExpired(ctx context.Context) (int, error)
+}
+
+// ExportService implements the IExportService interface.
+type ExportService struct {
+	db          *sql.DB
+	storage     storage.Storage
+	mailService mail.IMailService
+	cfg         config.ExportsConfig
+}
+
+// NewExportService creates a new ExportService keeping archives in the object storage and emailing their links through the mail service.
+func NewExportService(db *sql.DB, store storage.Storage, mailService mail.IMailService, cfg config.ExportsConfig) ExportService {
+	return ExportService{
+		db:          db,
+		storage:     store,
+		mailService: mailService,
+		cfg:         cfg,
+	}
+}
+
+const exportColumns = "id, status, size, requested_at, completed_at, expires_at"
+
+// RequestExport queues a copy of the user's data, prepared in the background and emailed once ready.
+// It returns ErrExportPending if an export of the user is being prepared already, ErrUserNotFound if the user doesn't exist,
+// and ErrUserInRegion if their data lives in a regional database, which an archive built here would leave out.
+func (s ExportService) RequestExport(ctx context.Context, userID uuid.UUID) (*data.DataExport, error) {
+	export, err := scanExport(s.db.QueryRowContext(ctx, `
+		INSERT INTO data_exports (id, user_id)
+		SELECT $1, id FROM users WHERE id = $2 AND region = ''
+		RETURNING `+exportColumns,
+		uuid.New(), userID,
+	))
+	if err == sql.ErrNoRows {
+		var exists bool
+		if err := s.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)", userID).Scan(&exists); err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, services.ErrUserInRegion
+		}
+		return nil, services.ErrUserNotFound
+	}
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code {
+			case "23505":
+				return nil, services.ErrExportPending
+			case "23503":
+				return nil, services.ErrUserNotFound
+			}
+		}
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// ListExports retrieves the exports of a user that haven't expired yet, the most recent first.
+func (s ExportService) ListExports(ctx context.Context, userID uuid.UUID) ([]data.DataExport, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+exportColumns+" FROM data_exports WHERE user_id = $1 AND (expires_at IS NULL OR expires_at > NOW()) ORDER BY requested_at DESC",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	exports := []data.DataExport{}
+	for rows.Next() {
+		export, err := scanExport(rows)
+		if err != nil {
+			return nil, err
+		}
+		exports = append(exports, *export)
+	}
+
+	return exports, rows.Err()
+}
+
+// Download retrieves the archive of a ready export by the token of its emailed link.
+// It returns ErrInvalidToken if no export matches the token or it expired.
+func (s ExportService) Download(ctx context.Context, token string) ([]byte, error) {
+	hash := sha256.Sum256([]byte(token))
+
+	var key string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT object_key FROM data_exports
+		WHERE token_hash = $1 AND status = 'ready' AND expires_at > NOW()`,
+		hash[:],
+	).Scan(&key)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	archive, _, err := s.storage.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, services.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	return archive, nil
+}
+
+// PreparePending builds the archives of the pending exports, one at a time, and emails their download links.
+// An export is locked while it's prepared so several API processes can run the job at once.
+// It returns the number of exports prepared; exports that couldn't be built are marked failed.
+func (s ExportService) PreparePending(ctx context.Context) (int, error) {
+	prepared := 0
+	for {
+		done, err := s.prepareNext(ctx)
+		if err != nil {
+			return prepared, err
+		}
+		if !done {
+			return prepared, nil
+		}
+		prepared++
+	}
+}
+
+// prepareNext prepares the oldest pending export not being prepared by another process, reporting whether there was one.
+func (s ExportService) prepareNext(ctx context.Context) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var exportID, userID uuid.UUID
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, user_id FROM data_exports
+		WHERE status = 'pending' AND user_id IN (SELECT id FROM users WHERE region = '')
+		ORDER BY requested_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED`,
+	).Scan(&exportID, &userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	archive, recipient, buildErr := s.build(ctx, userID)
+	if buildErr != nil {
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE data_exports SET status = 'failed', error = $2, completed_at = NOW() WHERE id = $1",
+			exportID, buildErr.Error(),
+		); err != nil {
+			return false, err
+		}
+		return true, tx.Commit()
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return false, err
+	}
+	hash := sha256.Sum256([]byte(token))
+	key := fmt.Sprintf("exports/%s/%s.zip", userID, exportID)
+
+	if err := s.storage.Put(ctx, key, archive, "application/zip"); err != nil {
+		return false, err
+	}
+
+	var expiresAt time.Time
+	err = tx.QueryRowContext(ctx, `
+		UPDATE data_exports
+		SET status = 'ready', token_hash = $2, object_key = $3, size = $4, completed_at = NOW(), expires_at = NOW() + $5 * INTERVAL '1 second'
+		WHERE id = $1
+		RETURNING expires_at`,
+		exportID, hash[:], key, len(archive), s.cfg.TTL.Seconds(),
+	).Scan(&expiresAt)
+	if err != nil {
+		s.storage.Delete(ctx, key)
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.storage.Delete(ctx, key)
+		return false, err
+	}
+
+	// a failed email is in the email log, the export is listed in the account settings either way
+	s.mailService.SendEmail(ctx, recipient.email, "Your Data Export Is Ready - Turtle Graphics", "export", map[string]string{
+		"Username":  recipient.username,
+		"url":       "/api/exports/" + token,
+		"ExpiresAt": expiresAt.UTC().Format("January 2, 2006 at 15:04 UTC"),
+	})
+
+	return true, nil
+}
+
+// DeleteExpired deletes the exports past their expiry along with their archives, and failed ones after as long.
+// It returns the number of exports deleted.
+func (s ExportService) DeleteExpired(ctx context.Context) (int, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		DELETE FROM data_exports
+		WHERE expires_at <= NOW() OR (status = 'failed' AND requested_at <= NOW() - $1 * INTERVAL '1 second')
+		RETURNING object_key`,
+		s.cfg.TTL.Seconds(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	deleted := 0
+	for rows.Next() {
+		var key sql.NullString
+		if err := rows.Scan(&key); err != nil {
+			return deleted, err
+		}
+		if key.Valid {
+			// a leftover archive can't be downloaded anymore without its row
+			s.storage.Delete(ctx, key.String)
+		}
+		deleted++
+	}
+
+	return deleted, rows.Err()
+}
+
+// newToken returns the secret of a download link.
+func newToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret), nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanExport(row scanner) (*data.DataExport, error) {
+	var export data.DataExport
+	if err := row.Scan(&export.ID, &export.Status, &export.Size, &export.RequestedAt, &export.CompletedAt, &export.ExpiresAt); err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
@@ -0,0 +1,422 @@
+// Package featured provides the automated rotation of featured projects.
+package featured
+
+import (
+	"database/sql"
+	"time"
+
+	"NodeTurtleAPI/internal/clock"
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/audit"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// IFeaturedService defines the interface for featured rotation and curation operations.
+type IFeaturedService interface {
+	Rotate() (*data.FeaturedRotation, error)
+	GetQueue() ([]data.FeaturedQueueEntry, error)
+	Enqueue(projectID, adminID uuid.UUID) (*data.FeaturedQueueEntry, error)
+	Dequeue(projectID uuid.UUID) error
+	SetPinned(projectID uuid.UUID, pinned bool) error
+	Feature(projectID, adminID uuid.UUID, until time.Time) (*data.FeaturedProject, error)
+	Unfeature(projectID uuid.UUID) error
+	ListFeatured(expiringWithin time.Duration) ([]data.FeaturedProject, error)
+}
+
+// FeaturedService implements the IFeaturedService interface.
+type FeaturedService struct {
+	db    *sql.DB
+	cfg   config.FeaturedConfig
+	clock clock.Clock
+}
+
+// NewFeaturedService creates a new FeaturedService with the provided database connection and rotation settings.
+// Featured slots start, end and go stale by the time of the clock rather than the database's.
+func NewFeaturedService(db *sql.DB, cfg config.FeaturedConfig, clk clock.Clock) FeaturedService {
+	return FeaturedService{
+		db:    db,
+		cfg:   cfg,
+		clock: clk,
+	}
+}
+
+// Rotate expires featured slots that stopped gaining engagement and backfills free slots from the curated queue.
+// A slot is stale when the project was featured longer than the grace period and gained fewer likes than
+// the configured minimum within the inactivity window. Pinned projects and projects featured by an admin
+// without a queue are only expired this way; projects that are no longer public are always expired.
+func (s FeaturedService) Rotate() (*data.FeaturedRotation, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rotation := &data.FeaturedRotation{
+		Expired:  []uuid.UUID{},
+		Featured: []uuid.UUID{},
+	}
+	now := s.clock.Now()
+
+	query := `
+		UPDATE projects p
+		SET featured_until = $4, featured_pinned = FALSE
+		WHERE p.featured_until > $4 AND (
+			p.is_public = FALSE OR p.hidden_at IS NOT NULL OR (
+				p.featured_pinned = FALSE
+				AND p.featured_at <= $4 - make_interval(hours => $1)
+				AND (
+					SELECT COUNT(*) FROM project_likes pl
+					WHERE pl.project_id = p.id AND pl.created_at > $4 - make_interval(hours => $2)
+				) < $3
+			)
+		)
+		RETURNING p.id`
+
+	rows, err := tx.Query(query, s.cfg.GracePeriod, s.cfg.InactivityWindow, s.cfg.MinLikes, now)
+	if err != nil {
+		return nil, err
+	}
+	rotation.Expired, err = scanIDs(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	var active int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM projects WHERE featured_until > $1", now).Scan(&active); err != nil {
+		return nil, err
+	}
+
+	free := s.cfg.Slots - active
+	if free > 0 {
+		// expired projects are not put back right away, even if they are queued again
+		query = `
+			SELECT q.project_id
+			FROM featured_queue q
+			JOIN projects p ON q.project_id = p.id
+			WHERE p.is_public = TRUE AND p.hidden_at IS NULL
+			  AND (p.featured_until IS NULL OR p.featured_until <= $3)
+			  AND NOT p.id = ANY($2)
+			ORDER BY q.queued_at
+			LIMIT $1
+			FOR UPDATE OF q SKIP LOCKED`
+
+		rows, err := tx.Query(query, free, pq.Array(rotation.Expired), now)
+		if err != nil {
+			return nil, err
+		}
+		next, err := scanIDs(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, projectID := range next {
+			_, err := tx.Exec(`
+				UPDATE projects
+				SET featured_until = $3::timestamptz + make_interval(hours => $2), featured_at = $3,
+				    featured_by = (SELECT queued_by FROM featured_queue WHERE project_id = $1)
+				WHERE id = $1`,
+				projectID, s.cfg.Duration, now,
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, err := tx.Exec("DELETE FROM featured_queue WHERE project_id = $1", projectID); err != nil {
+				return nil, err
+			}
+
+			if _, err := tx.Exec(ensureSnapshot, projectID); err != nil {
+				return nil, err
+			}
+
+			rotation.Featured = append(rotation.Featured, projectID)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return rotation, nil
+}
+
+// GetQueue retrieves the curated queue in backfill order.
+func (s FeaturedService) GetQueue() ([]data.FeaturedQueueEntry, error) {
+	query := `
+		SELECT q.project_id, p.title, u.username, q.queued_by, q.queued_at
+		FROM featured_queue q
+		JOIN projects p ON q.project_id = p.id
+		JOIN users u ON p.creator_id = u.id
+		ORDER BY q.queued_at`
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return []data.FeaturedQueueEntry{}, err
+	}
+	defer rows.Close()
+
+	queue := make([]data.FeaturedQueueEntry, 0)
+	for rows.Next() {
+		var entry data.FeaturedQueueEntry
+		if err := rows.Scan(&entry.ProjectID, &entry.Title, &entry.CreatorUsername, &entry.QueuedBy, &entry.QueuedAt); err != nil {
+			return []data.FeaturedQueueEntry{}, err
+		}
+		queue = append(queue, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return []data.FeaturedQueueEntry{}, err
+	}
+
+	return queue, nil
+}
+
+// Enqueue adds a public project to the end of the curated queue.
+// It returns ErrProjectNotFound if no public project matches or ErrAlreadyQueued if it is already queued.
+func (s FeaturedService) Enqueue(projectID, adminID uuid.UUID) (*data.FeaturedQueueEntry, error) {
+	var entry data.FeaturedQueueEntry
+	query := `
+		INSERT INTO featured_queue (project_id, queued_by)
+		SELECT p.id, $2 FROM projects p WHERE p.id = $1 AND p.is_public = TRUE AND p.hidden_at IS NULL
+		ON CONFLICT (project_id) DO NOTHING
+		RETURNING project_id, (SELECT title FROM projects WHERE id = $1),
+		          (SELECT u.username FROM projects p JOIN users u ON p.creator_id = u.id WHERE p.id = $1),
+		          queued_by, queued_at`
+
+	err := s.db.QueryRow(query, projectID, adminID).Scan(
+		&entry.ProjectID,
+		&entry.Title,
+		&entry.CreatorUsername,
+		&entry.QueuedBy,
+		&entry.QueuedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			var queued bool
+			if err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM featured_queue WHERE project_id = $1)", projectID).Scan(&queued); err != nil {
+				return nil, err
+			}
+			if queued {
+				return nil, services.ErrAlreadyQueued
+			}
+			return nil, services.ErrProjectNotFound
+		}
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// Dequeue removes a project from the curated queue.
+// It returns ErrRecordNotFound if the project is not queued.
+func (s FeaturedService) Dequeue(projectID uuid.UUID) error {
+	res, err := s.db.Exec("DELETE FROM featured_queue WHERE project_id = $1", projectID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return services.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// SetPinned pins or unpins a featured project. Pinned projects keep their slot regardless of engagement
+// until their featured_until passes or an admin unfeatures them.
+// It returns ErrProjectNotFound if the project doesn't exist.
+func (s FeaturedService) SetPinned(projectID uuid.UUID, pinned bool) error {
+	res, err := s.db.Exec("UPDATE projects SET featured_pinned = $2 WHERE id = $1", projectID, pinned)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return services.ErrProjectNotFound
+	}
+
+	return nil
+}
+
+// RecordRotation adds an audit log entry for every project a rotation expired or featured.
+// Rotations run by the scheduler have no actor.
+func RecordRotation(auditService audit.IAuditService, actorID *uuid.UUID, ip string, rotation *data.FeaturedRotation) error {
+	results := map[string][]uuid.UUID{
+		"expired":  rotation.Expired,
+		"featured": rotation.Featured,
+	}
+	for result, projectIDs := range results {
+		for _, projectID := range projectIDs {
+			err := auditService.Record(data.AuditEntry{
+				ActorID:    actorID,
+				Action:     data.AuditFeaturedRotate,
+				TargetType: "project",
+				TargetID:   projectID.String(),
+				Details:    map[string]interface{}{"result": result},
+				IP:         ip,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+const selectFeatured = `
+	SELECT p.id, p.title, u.username, p.featured_at, p.featured_until, p.featured_by, fb.username, p.featured_pinned
+	FROM projects p
+	JOIN users u ON p.creator_id = u.id
+	LEFT JOIN users fb ON p.featured_by = fb.id`
+
+// ensureSnapshot publishes a project getting a featured slot unless it was published before, as the projects service does
+// when a project is made public, so embeds of featured projects don't change while their owner edits them.
+const ensureSnapshot = `
+	INSERT INTO project_snapshots (project_id, title, description, data, version)
+	SELECT id, title, description, data, version FROM projects WHERE id = $1
+	ON CONFLICT (project_id) DO NOTHING`
+
+// Feature gives a public project a featured slot until the given time, taking it off the curated queue.
+// Featuring a project that is already featured moves its end and records the admin again.
+// It returns ErrProjectNotFound if no public project matches.
+func (s FeaturedService) Feature(projectID, adminID uuid.UUID, until time.Time) (*data.FeaturedProject, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE projects
+		SET featured_until = $2, featured_at = $4, featured_by = $3
+		WHERE id = $1 AND is_public = TRUE AND hidden_at IS NULL`,
+		projectID, until, adminID, s.clock.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if rowsAffected == 0 {
+		return nil, services.ErrProjectNotFound
+	}
+
+	if _, err := tx.Exec("DELETE FROM featured_queue WHERE project_id = $1", projectID); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ensureSnapshot, projectID); err != nil {
+		return nil, err
+	}
+
+	featured, err := scanFeatured(tx.QueryRow(selectFeatured+" WHERE p.id = $1", projectID))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return featured, nil
+}
+
+// Unfeature ends the featured slot of a project right away, the same way the rotation expires it.
+// It returns ErrRecordNotFound if the project is not featured.
+func (s FeaturedService) Unfeature(projectID uuid.UUID) error {
+	res, err := s.db.Exec(`
+		UPDATE projects
+		SET featured_until = $2, featured_pinned = FALSE
+		WHERE id = $1 AND featured_until > $2`,
+		projectID, s.clock.Now(),
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return services.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// ListFeatured retrieves the currently featured projects, the ones expiring soonest first.
+// A positive expiringWithin only returns the projects whose slot ends within that time.
+func (s FeaturedService) ListFeatured(expiringWithin time.Duration) ([]data.FeaturedProject, error) {
+	query := selectFeatured + `
+		WHERE p.featured_until > $2
+		  AND ($1::float8 = 0 OR p.featured_until <= $2::timestamptz + make_interval(secs => $1::float8))
+		ORDER BY p.featured_until, p.id`
+
+	rows, err := s.db.Query(query, expiringWithin.Seconds(), s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []data.FeaturedProject{}
+	for rows.Next() {
+		featured, err := scanFeatured(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, *featured)
+	}
+
+	return projects, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanFeatured(row scanner) (*data.FeaturedProject, error) {
+	var f data.FeaturedProject
+	err := row.Scan(&f.ProjectID, &f.Title, &f.CreatorUsername, &f.FeaturedAt, &f.FeaturedUntil, &f.FeaturedBy, &f.FeaturedByUsername, &f.Pinned)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrProjectNotFound
+		}
+		return nil, err
+	}
+	return &f, nil
+}
+
+func scanIDs(rows *sql.Rows) ([]uuid.UUID, error) {
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
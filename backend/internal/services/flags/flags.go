@@ -0,0 +1,104 @@
+// Package flags provides the feature flags, cached in memory as they are read on hot paths.
+package flags
+
+import (
+	"database/sql"
+
+	"NodeTurtleAPI/internal/cache"
+	"NodeTurtleAPI/internal/data"
+)
+
+// cacheName identifies the flags cache, e.g. in the cache metrics.
+const cacheName = "feature_flags"
+
+// IFlagService defines the interface for feature flag operations.
+type IFlagService interface {
+	GetFlags() ([]data.FeatureFlag, error)
+	IsEnabled(name string) bool
+	SetFlag(name string, enabled bool, description *string) (*data.FeatureFlag, error)
+}
+
+// FlagService implements the IFlagService interface.
+type FlagService struct {
+	db     *sql.DB
+	caches *cache.Registry
+	flags  *cache.Value[[]data.FeatureFlag]
+}
+
+// NewFlagService creates a new FlagService with the provided database connection, caching the flags in caches.
+func NewFlagService(db *sql.DB, caches *cache.Registry) FlagService {
+	s := FlagService{
+		db:     db,
+		caches: caches,
+	}
+	s.flags = cache.New(caches, cacheName, s.loadFlags)
+
+	return s
+}
+
+// GetFlags retrieves all feature flags, sorted by name.
+func (s FlagService) GetFlags() ([]data.FeatureFlag, error) {
+	flags, err := s.flags.Get()
+	if err != nil {
+		return []data.FeatureFlag{}, err
+	}
+
+	return flags, nil
+}
+
+// IsEnabled reports whether the feature flag is on. Unknown flags and lookup failures count as off.
+func (s FlagService) IsEnabled(name string) bool {
+	flags, err := s.flags.Get()
+	if err != nil {
+		return false
+	}
+
+	for _, f := range flags {
+		if f.Name == name {
+			return f.Enabled
+		}
+	}
+	return false
+}
+
+// SetFlag turns a feature flag on or off, creating it if needed. The description is kept when nil.
+func (s FlagService) SetFlag(name string, enabled bool, description *string) (*data.FeatureFlag, error) {
+	query := `
+		INSERT INTO feature_flags (name, enabled, description)
+		VALUES ($1, $2, COALESCE($3, ''))
+		ON CONFLICT (name) DO UPDATE
+		SET enabled = EXCLUDED.enabled,
+			description = COALESCE($3, feature_flags.description),
+			updated_at = NOW()
+		RETURNING name, enabled, description, updated_at`
+
+	var f data.FeatureFlag
+	if err := s.db.QueryRow(query, name, enabled, description).Scan(&f.Name, &f.Enabled, &f.Description, &f.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := s.caches.Invalidate(cacheName); err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
+
+func (s FlagService) loadFlags() ([]data.FeatureFlag, error) {
+	rows, err := s.db.Query("SELECT name, enabled, description, updated_at FROM feature_flags ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	flags := make([]data.FeatureFlag, 0)
+	for rows.Next() {
+		var f data.FeatureFlag
+		if err := rows.Scan(&f.Name, &f.Enabled, &f.Description, &f.UpdatedAt); err != nil {
+			return nil, err
+		}
+		flags = append(flags, f)
+	}
+
+	return flags, rows.Err()
+}
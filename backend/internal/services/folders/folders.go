@@ -0,0 +1,364 @@
+// Package folders handles the folders users sort their own projects into.
+package folders
+
+import (
+	"context"
+	"database/sql"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// IFolderService defines the interface for project folder operations.
+type IFolderService interface {
+	ListFolders(ctx context.Context, userID uuid.UUID) ([]data.Folder, error)
+	CreateFolder(ctx context.Context, userID uuid.UUID, create data.FolderCreate) (*data.Folder, error)
+	RenameFolder(ctx context.Context, userID, folderID uuid.UUID, name string) (*data.Folder, error)
+	DeleteFolder(ctx context.Context, userID, folderID uuid.UUID) error
+	ReorderFolders(ctx context.Context, userID uuid.UUID, parentID *uuid.UUID, folderIDs []uuid.UUID) error
+	MoveProject(ctx context.Context, userID, projectID uuid.UUID, folderID *uuid.UUID) error
+	ReorderProjects(ctx context.Context, userID, folderID uuid.UUID, projectIDs []uuid.UUID) error
+}
+
+// FolderService implements the IFolderService interface.
+type FolderService struct {
+	db *sql.DB
+}
+
+// NewFolderService creates a new FolderService with the provided database connection.
+func NewFolderService(db *sql.DB) FolderService {
+	return FolderService{
+		db: db,
+	}
+}
+
+const selectFolder = `
+	SELECT f.id, f.parent_id, f.name, f.position, f.created_at, f.updated_at,
+	       ARRAY(SELECT fp.project_id FROM folder_projects fp WHERE fp.folder_id = f.id ORDER BY fp.position, fp.project_id)
+	FROM project_folders f`
+
+// ListFolders retrieves the folders of the user as a tree, top-level folders holding their subfolders, each in their order.
+func (s FolderService) ListFolders(ctx context.Context, userID uuid.UUID) ([]data.Folder, error) {
+	rows, err := s.db.QueryContext(ctx, selectFolder+`
+		WHERE f.user_id = $1
+		ORDER BY f.parent_id IS NOT NULL, f.position, f.created_at`,
+		userID,
+	)
+	if err != nil {
+		return []data.Folder{}, err
+	}
+	defer rows.Close()
+
+	// top-level folders come first, so every subfolder finds its parent
+	folders := make([]data.Folder, 0)
+	index := map[uuid.UUID]int{}
+	for rows.Next() {
+		folder, err := scanFolder(rows)
+		if err != nil {
+			return []data.Folder{}, err
+		}
+
+		if folder.ParentID == nil {
+			index[folder.ID] = len(folders)
+			folders = append(folders, *folder)
+		} else if i, ok := index[*folder.ParentID]; ok {
+			folders[i].Folders = append(folders[i].Folders, *folder)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return []data.Folder{}, err
+	}
+
+	return folders, nil
+}
+
+// CreateFolder creates a folder of the user after the folders sharing its parent.
+// It returns ErrRecordNotFound if the parent isn't a folder of the user, or ErrFolderTooDeep if the parent is a subfolder.
+func (s FolderService) CreateFolder(ctx context.Context, userID uuid.UUID, create data.FolderCreate) (*data.Folder, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if create.ParentID != nil {
+		var grandparentID *uuid.UUID
+		err = tx.QueryRowContext(ctx, "SELECT parent_id FROM project_folders WHERE id = $1 AND user_id = $2 FOR UPDATE", create.ParentID, userID).
+			Scan(&grandparentID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, services.ErrRecordNotFound
+			}
+			return nil, err
+		}
+		if grandparentID != nil {
+			return nil, services.ErrFolderTooDeep
+		}
+	}
+
+	var folderID uuid.UUID
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO project_folders (user_id, parent_id, name, position)
+		VALUES ($1, $2, $3, (SELECT COALESCE(MAX(position) + 1, 0) FROM project_folders WHERE user_id = $1 AND parent_id IS NOT DISTINCT FROM $2))
+		RETURNING id`,
+		userID, create.ParentID, create.Name,
+	).Scan(&folderID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return nil, services.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return s.getFolder(ctx, userID, folderID)
+}
+
+// RenameFolder renames a folder of the user. It returns ErrRecordNotFound if the user has no such folder.
+func (s FolderService) RenameFolder(ctx context.Context, userID, folderID uuid.UUID, name string) (*data.Folder, error) {
+	result, err := s.db.ExecContext(ctx, "UPDATE project_folders SET name = $3, updated_at = NOW() WHERE id = $1 AND user_id = $2", folderID, userID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		return nil, services.ErrRecordNotFound
+	}
+
+	return s.getFolder(ctx, userID, folderID)
+}
+
+// DeleteFolder deletes a folder of the user together with its subfolders. Their projects are kept, in no folder.
+// It returns ErrRecordNotFound if the user has no such folder.
+func (s FolderService) DeleteFolder(ctx context.Context, userID, folderID uuid.UUID) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM project_folders WHERE id = $1 AND user_id = $2", folderID, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return services.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// ReorderFolders puts the folders of the user sharing the parent, the top-level ones when parentID is nil, in the given order.
+// It returns ErrRecordNotFound if the user has no such parent folder, or ErrFolderOrder if the folders listed aren't
+// exactly its subfolders.
+func (s FolderService) ReorderFolders(ctx context.Context, userID uuid.UUID, parentID *uuid.UUID, folderIDs []uuid.UUID) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if parentID != nil {
+		if err = lockFolder(ctx, tx, userID, *parentID); err != nil {
+			return err
+		}
+	}
+
+	siblings, err := lockIDs(ctx, tx, "SELECT id FROM project_folders WHERE user_id = $1 AND parent_id IS NOT DISTINCT FROM $2 FOR UPDATE", userID, parentID)
+	if err != nil {
+		return err
+	}
+	if !sameIDs(siblings, folderIDs) {
+		return services.ErrFolderOrder
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE project_folders f SET position = o.position - 1, updated_at = NOW()
+		FROM unnest($2::uuid[]) WITH ORDINALITY AS o(id, position)
+		WHERE f.id = o.id AND f.user_id = $1`,
+		userID, pq.Array(folderIDs),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MoveProject puts a project of the user last in one of their folders, or takes it out of its folder when folderID is nil.
+// It returns ErrProjectNotFound if the user didn't create the project, or ErrRecordNotFound if the user has no such folder.
+func (s FolderService) MoveProject(ctx context.Context, userID, projectID uuid.UUID, folderID *uuid.UUID) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var owned bool
+	err = tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM projects WHERE id = $1 AND creator_id = $2)", projectID, userID).Scan(&owned)
+	if err != nil {
+		return err
+	}
+	if !owned {
+		return services.ErrProjectNotFound
+	}
+
+	if folderID == nil {
+		if _, err = tx.ExecContext(ctx, "DELETE FROM folder_projects WHERE project_id = $1", projectID); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	if err = lockFolder(ctx, tx, userID, *folderID); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO folder_projects (project_id, folder_id, position)
+		VALUES ($1, $2, (SELECT COALESCE(MAX(position) + 1, 0) FROM folder_projects WHERE folder_id = $2))
+		ON CONFLICT (project_id) DO UPDATE SET folder_id = EXCLUDED.folder_id, position = EXCLUDED.position
+		WHERE folder_projects.folder_id <> EXCLUDED.folder_id`,
+		projectID, folderID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReorderProjects puts the projects in a folder of the user in the given order.
+// It returns ErrRecordNotFound if the user has no such folder, or ErrFolderOrder if the projects listed aren't
+// exactly the ones in the folder.
+func (s FolderService) ReorderProjects(ctx context.Context, userID, folderID uuid.UUID, projectIDs []uuid.UUID) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = lockFolder(ctx, tx, userID, folderID); err != nil {
+		return err
+	}
+
+	current, err := lockIDs(ctx, tx, "SELECT project_id FROM folder_projects WHERE folder_id = $1 FOR UPDATE", folderID)
+	if err != nil {
+		return err
+	}
+	if !sameIDs(current, projectIDs) {
+		return services.ErrFolderOrder
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE folder_projects fp SET position = o.position - 1
+		FROM unnest($2::uuid[]) WITH ORDINALITY AS o(id, position)
+		WHERE fp.project_id = o.id AND fp.folder_id = $1`,
+		folderID, pq.Array(projectIDs),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE project_folders SET updated_at = NOW() WHERE id = $1", folderID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s FolderService) getFolder(ctx context.Context, userID, folderID uuid.UUID) (*data.Folder, error) {
+	folder, err := scanFolder(s.db.QueryRowContext(ctx, selectFolder+" WHERE f.id = $1 AND f.user_id = $2", folderID, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return folder, nil
+}
+
+// lockFolder locks a folder of the user until the transaction ends, so concurrent changes to it queue up.
+// It returns ErrRecordNotFound if the user has no such folder.
+func lockFolder(ctx context.Context, tx *sql.Tx, userID, folderID uuid.UUID) error {
+	var id uuid.UUID
+	err := tx.QueryRowContext(ctx, "SELECT id FROM project_folders WHERE id = $1 AND user_id = $2 FOR UPDATE", folderID, userID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return services.ErrRecordNotFound
+	}
+	return err
+}
+
+func lockIDs(ctx context.Context, tx *sql.Tx, query string, args ...any) ([]uuid.UUID, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// sameIDs reports whether order lists every ID of ids exactly once and nothing else.
+func sameIDs(ids, order []uuid.UUID) bool {
+	if len(ids) != len(order) {
+		return false
+	}
+
+	remaining := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		remaining[id] = true
+	}
+	for _, id := range order {
+		if !remaining[id] {
+			return false
+		}
+		delete(remaining, id)
+	}
+
+	return true
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanFolder(row scanner) (*data.Folder, error) {
+	var folder data.Folder
+	var projectIDs []string
+	err := row.Scan(&folder.ID, &folder.ParentID, &folder.Name, &folder.Position, &folder.CreatedAt, &folder.UpdatedAt, pq.Array(&projectIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	folder.ProjectIDs = make([]uuid.UUID, 0, len(projectIDs))
+	for _, id := range projectIDs {
+		projectID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, err
+		}
+		folder.ProjectIDs = append(folder.ProjectIDs, projectID)
+	}
+
+	return &folder, nil
+}
@@ -0,0 +1,160 @@
+// Package guests handles anonymous guest accounts, which let people try the editor before registering.
+package guests
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/auth"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// IGuestService defines the interface for guest account operations.
+type IGuestService interface {
+	CreateGuest(ctx context.Context) (*data.User, error)
+	ClaimGuest(ctx context.Context, guestID uuid.UUID, reg data.UserRegistration) (*data.User, error)
+	DeleteExpiredGuests(ctx context.Context) (int64, error)
+}
+
+// GuestService implements the IGuestService interface.
+type GuestService struct {
+	db  *sql.DB
+	ttl time.Duration
+}
+
+// NewGuestService creates a new GuestService with the provided database connection.
+// Guest accounts are deleted together with their projects once ttl has passed.
+func NewGuestService(db *sql.DB, ttl time.Duration) GuestService {
+	return GuestService{
+		db:  db,
+		ttl: ttl,
+	}
+}
+
+// CreateGuest creates an activated guest account. Guests have a placeholder email and a random password
+// nobody knows, they only ever hold the session they were created with.
+func (s GuestService) CreateGuest(ctx context.Context) (*data.User, error) {
+	password, err := randomPassword()
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := auth.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.ReplaceAll(uuid.NewString(), "-", "")
+	expiresAt := time.Now().Add(s.ttl)
+
+	var user data.User
+	query := `
+	INSERT INTO users (email, username, password, role_id, activated, guest_expires_at, created_at)
+	VALUES ($1, $2, $3, $4, true, $5, NOW() AT TIME ZONE 'UTC')
+	RETURNING id, email, username, activated, guest_expires_at, created_at
+	`
+	err = s.db.QueryRowContext(ctx, query, name+"@guests.invalid", "guest"+name[:12], hashedPassword, data.RoleUser, expiresAt).Scan(
+		&user.ID,
+		&user.Email,
+		&user.Username,
+		&user.IsActivated,
+		&user.GuestExpiresAt,
+		&user.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Role = data.Role{ID: data.RoleUser.ToID(), Name: data.RoleUser.String()}
+	return &user, nil
+}
+
+// ClaimGuest registers a new account and moves the projects of the guest to it, then deletes the guest.
+// The new account has to be activated like any other registration.
+// It returns ErrRecordNotFound if the guest doesn't exist or has expired,
+// and ErrDuplicateEmail or ErrDuplicateUsername if the registration is taken.
+func (s GuestService) ClaimGuest(ctx context.Context, guestID uuid.UUID, reg data.UserRegistration) (*data.User, error) {
+	hashedPassword, err := auth.HashPassword(reg.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id uuid.UUID
+	err = tx.QueryRowContext(ctx, "SELECT id FROM users WHERE id = $1 AND guest_expires_at > NOW() FOR UPDATE", guestID).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	var user data.User
+	query := `
+	INSERT INTO users (email, username, password, role_id, activated, created_at)
+	VALUES ($1, $2, $3, $4, false, NOW() AT TIME ZONE 'UTC')
+	RETURNING id, email, username, activated, created_at
+	`
+	err = tx.QueryRowContext(ctx, query, reg.Email, reg.Username, hashedPassword, data.RoleUser).Scan(
+		&user.ID,
+		&user.Email,
+		&user.Username,
+		&user.IsActivated,
+		&user.CreatedAt,
+	)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			if pqErr.Constraint == "users_email_key" {
+				return nil, services.ErrDuplicateEmail
+			}
+			return nil, services.ErrDuplicateUsername
+		}
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE projects SET creator_id = $1 WHERE creator_id = $2", user.ID, guestID); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM users WHERE id = $1", guestID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// DeleteExpiredGuests deletes the guest accounts that expired without being claimed, their projects are deleted with them.
+// It returns the number of deleted guests.
+func (s GuestService) DeleteExpiredGuests(ctx context.Context) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM users WHERE guest_expires_at <= NOW()")
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func randomPassword() (string, error) {
+	bytes := make([]byte, 24)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
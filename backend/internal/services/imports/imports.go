@@ -0,0 +1,184 @@
+// Package imports keeps project import files that are uploaded in chunks until they are complete.
+package imports
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+)
+
+// IImportService defines the interface for chunked import upload operations.
+type IImportService interface {
+	CreateUpload(ctx context.Context, userID uuid.UUID, size int64) (*data.ProjectUpload, error)
+	GetUpload(ctx context.Context, uploadID, userID uuid.UUID) (*data.ProjectUpload, error)
+	AppendChunk(ctx context.Context, uploadID, userID uuid.UUID, offset int64, chunk []byte) (*data.ProjectUpload, error)
+	ReadUpload(ctx context.Context, uploadID, userID uuid.UUID) ([]byte, error)
+	DeleteUpload(ctx context.Context, uploadID, userID uuid.UUID) error
+	DeleteExpiredUploads(ctx context.Context) (int64, error)
+}
+
+// ImportService implements the IImportService interface.
+type ImportService struct {
+	db  *sql.DB
+	ttl time.Duration
+}
+
+// NewImportService creates a new ImportService with the provided database connection.
+// Unfinished uploads can be resumed until ttl has passed.
+func NewImportService(db *sql.DB, ttl time.Duration) ImportService {
+	return ImportService{
+		db:  db,
+		ttl: ttl,
+	}
+}
+
+// CreateUpload starts the upload of an import file of the given size.
+func (s ImportService) CreateUpload(ctx context.Context, userID uuid.UUID, size int64) (*data.ProjectUpload, error) {
+	query := `
+		INSERT INTO project_uploads (user_id, size, expires_at)
+		VALUES ($1, $2, $3)
+		RETURNING id, user_id, size, received, created_at, expires_at`
+
+	return scanUpload(s.db.QueryRowContext(ctx, query, userID, size, time.Now().Add(s.ttl)))
+}
+
+// GetUpload retrieves an unexpired upload of the user.
+// It returns ErrRecordNotFound if there is none, so uploads of other users can't be probed.
+func (s ImportService) GetUpload(ctx context.Context, uploadID, userID uuid.UUID) (*data.ProjectUpload, error) {
+	query := `
+		SELECT id, user_id, size, received, created_at, expires_at
+		FROM project_uploads
+		WHERE id = $1 AND user_id = $2 AND expires_at > NOW()`
+
+	return scanUpload(s.db.QueryRowContext(ctx, query, uploadID, userID))
+}
+
+// AppendChunk stores the next chunk of an upload, which must start where the upload left off.
+// A chunk that doesn't is rejected with ErrUploadOffset, and one that goes past the announced size with ErrUploadSize.
+// Both return the upload, so the client can resume from its offset.
+func (s ImportService) AppendChunk(ctx context.Context, uploadID, userID uuid.UUID, offset int64, chunk []byte) (*data.ProjectUpload, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// locking the upload keeps retried chunks of a flaky connection from being stored twice
+	query := `
+		SELECT id, user_id, size, received, created_at, expires_at
+		FROM project_uploads
+		WHERE id = $1 AND user_id = $2 AND expires_at > NOW()
+		FOR UPDATE`
+
+	upload, err := scanUpload(tx.QueryRowContext(ctx, query, uploadID, userID))
+	if err != nil {
+		return nil, err
+	}
+	if offset != upload.Offset {
+		return upload, services.ErrUploadOffset
+	}
+	if offset+int64(len(chunk)) > upload.Size {
+		return upload, services.ErrUploadSize
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO project_upload_chunks (upload_id, position, content) VALUES ($1, $2, $3)", uploadID, offset, chunk)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `
+		UPDATE project_uploads SET received = received + $2
+		WHERE id = $1
+		RETURNING id, user_id, size, received, created_at, expires_at`
+
+	upload, err = scanUpload(tx.QueryRowContext(ctx, query, uploadID, len(chunk)))
+	if err != nil {
+		return nil, err
+	}
+
+	return upload, tx.Commit()
+}
+
+// ReadUpload assembles the chunks of a complete upload of the user.
+// It returns ErrUploadIncomplete if bytes are still missing.
+func (s ImportService) ReadUpload(ctx context.Context, uploadID, userID uuid.UUID) ([]byte, error) {
+	upload, err := s.GetUpload(ctx, uploadID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !upload.Complete() {
+		return nil, services.ErrUploadIncomplete
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT content FROM project_upload_chunks WHERE upload_id = $1 ORDER BY position", uploadID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var file bytes.Buffer
+	file.Grow(int(upload.Size))
+	for rows.Next() {
+		var chunk []byte
+		if err := rows.Scan(&chunk); err != nil {
+			return nil, err
+		}
+		file.Write(chunk)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return file.Bytes(), nil
+}
+
+// DeleteUpload deletes an upload of the user along with its chunks.
+// It returns ErrRecordNotFound if the user has no such upload.
+func (s ImportService) DeleteUpload(ctx context.Context, uploadID, userID uuid.UUID) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM project_uploads WHERE id = $1 AND user_id = $2", uploadID, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return services.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// DeleteExpiredUploads deletes the uploads that weren't finished in time, along with their chunks.
+// It returns the number of deleted uploads.
+func (s ImportService) DeleteExpiredUploads(ctx context.Context) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM project_uploads WHERE expires_at <= NOW()")
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func scanUpload(row *sql.Row) (*data.ProjectUpload, error) {
+	var upload data.ProjectUpload
+	err := row.Scan(&upload.ID, &upload.UserID, &upload.Size, &upload.Offset, &upload.CreatedAt, &upload.ExpiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &upload, nil
+}
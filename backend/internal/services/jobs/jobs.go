@@ -0,0 +1,70 @@
+// Package jobs provides the progress of long running background jobs.
+package jobs
+
+import (
+	"database/sql"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+)
+
+// IJobService defines the interface for background job progress lookups.
+type IJobService interface {
+	GetJobs() ([]data.Job, error)
+	GetJob(name string) (*data.Job, error)
+}
+
+// JobService implements the IJobService interface.
+type JobService struct {
+	db *sql.DB
+}
+
+// NewJobService creates a new JobService with the provided database connection.
+func NewJobService(db *sql.DB) JobService {
+	return JobService{
+		db: db,
+	}
+}
+
+const jobColumns = "name, kind, status, total, processed, error, started_at, updated_at, finished_at"
+
+// GetJobs retrieves all background jobs, the most recently started first.
+func (s JobService) GetJobs() ([]data.Job, error) {
+	rows, err := s.db.Query("SELECT " + jobColumns + " FROM background_jobs ORDER BY started_at DESC, name")
+	if err != nil {
+		return []data.Job{}, err
+	}
+	defer rows.Close()
+
+	jobs := make([]data.Job, 0)
+	for rows.Next() {
+		var j data.Job
+		if err := rows.Scan(&j.Name, &j.Kind, &j.Status, &j.Total, &j.Processed, &j.Error, &j.StartedAt, &j.UpdatedAt, &j.FinishedAt); err != nil {
+			return []data.Job{}, err
+		}
+		jobs = append(jobs, j)
+	}
+
+	if err = rows.Err(); err != nil {
+		return []data.Job{}, err
+	}
+
+	return jobs, nil
+}
+
+// GetJob retrieves a background job by name.
+// It returns ErrRecordNotFound if the job never ran.
+func (s JobService) GetJob(name string) (*data.Job, error) {
+	var j data.Job
+	err := s.db.QueryRow("SELECT "+jobColumns+" FROM background_jobs WHERE name = $1", name).Scan(
+		&j.Name, &j.Kind, &j.Status, &j.Total, &j.Processed, &j.Error, &j.StartedAt, &j.UpdatedAt, &j.FinishedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &j, nil
+}
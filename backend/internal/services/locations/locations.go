@@ -0,0 +1,211 @@
+// Package locations tracks the countries users log in from and the one-time codes
+// confirming logins from countries a user has not logged in from before.
+package locations
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+)
+
+// maxAttempts is the number of wrong codes after which a challenge is discarded.
+const maxAttempts = 5
+
+// ILocationService defines the interface for trusted location and login challenge operations.
+type ILocationService interface {
+	IsTrusted(userID uuid.UUID, country string) (bool, error)
+	Trust(userID uuid.UUID, country string) error
+	ListTrusted(userID uuid.UUID) ([]data.TrustedLocation, error)
+	RemoveTrusted(userID uuid.UUID, country string) error
+	CreateChallenge(userID uuid.UUID, country string) (*data.LoginChallenge, error)
+	VerifyChallenge(challengeID uuid.UUID, code string) (*data.LoginChallenge, error)
+}
+
+// LocationService implements the ILocationService interface.
+type LocationService struct {
+	db      *sql.DB
+	codeTTL time.Duration
+}
+
+// NewLocationService creates a new LocationService with the provided database connection and login verification settings.
+func NewLocationService(db *sql.DB, cfg config.LoginVerificationConfig) LocationService {
+	return LocationService{
+		db:      db,
+		codeTTL: time.Duration(cfg.CodeTTL) * time.Minute,
+	}
+}
+
+// IsTrusted checks whether the user has logged in from the country before.
+// Users without any trusted location are trusted everywhere, their first login establishes the first location.
+func (s LocationService) IsTrusted(userID uuid.UUID, country string) (bool, error) {
+	var trusted bool
+	query := `
+		SELECT EXISTS(SELECT 1 FROM trusted_locations WHERE user_id = $1 AND country = $2)
+		    OR NOT EXISTS(SELECT 1 FROM trusted_locations WHERE user_id = $1)`
+
+	err := s.db.QueryRow(query, userID, country).Scan(&trusted)
+	return trusted, err
+}
+
+// Trust adds the country to the trusted locations of the user, or refreshes when it was last seen.
+func (s LocationService) Trust(userID uuid.UUID, country string) error {
+	_, err := s.db.Exec(`
+		INSERT INTO trusted_locations (user_id, country) VALUES ($1, $2)
+		ON CONFLICT (user_id, country) DO UPDATE SET last_seen_at = NOW()`,
+		userID, country,
+	)
+	return err
+}
+
+// ListTrusted retrieves the trusted locations of the user, most recently seen first.
+func (s LocationService) ListTrusted(userID uuid.UUID) ([]data.TrustedLocation, error) {
+	rows, err := s.db.Query("SELECT country, created_at, last_seen_at FROM trusted_locations WHERE user_id = $1 ORDER BY last_seen_at DESC", userID)
+	if err != nil {
+		return []data.TrustedLocation{}, err
+	}
+	defer rows.Close()
+
+	locations := make([]data.TrustedLocation, 0)
+	for rows.Next() {
+		var location data.TrustedLocation
+		if err := rows.Scan(&location.Country, &location.CreatedAt, &location.LastSeenAt); err != nil {
+			return []data.TrustedLocation{}, err
+		}
+		locations = append(locations, location)
+	}
+
+	if err = rows.Err(); err != nil {
+		return []data.TrustedLocation{}, err
+	}
+
+	return locations, nil
+}
+
+// RemoveTrusted removes the country from the trusted locations of the user.
+// The next login from there has to be confirmed again. It returns ErrRecordNotFound if the country is not trusted.
+func (s LocationService) RemoveTrusted(userID uuid.UUID, country string) error {
+	res, err := s.db.Exec("DELETE FROM trusted_locations WHERE user_id = $1 AND country = $2", userID, country)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return services.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// CreateChallenge creates a one-time code confirming a login of the user from the country.
+// Pending challenges of the user are replaced, so only the most recently emailed code is valid.
+func (s LocationService) CreateChallenge(userID uuid.UUID, country string) (*data.LoginChallenge, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return nil, err
+	}
+
+	challenge := data.LoginChallenge{
+		UserID:    userID,
+		Country:   country,
+		Code:      fmt.Sprintf("%06d", n.Int64()),
+		ExpiresAt: time.Now().UTC().Add(s.codeTTL),
+	}
+	hash := sha256.Sum256([]byte(challenge.Code))
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM login_challenges WHERE user_id = $1", userID); err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(
+		"INSERT INTO login_challenges (user_id, country, code_hash, expires_at) VALUES ($1, $2, $3, $4) RETURNING id",
+		userID, country, hash[:], challenge.ExpiresAt,
+	).Scan(&challenge.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &challenge, nil
+}
+
+// VerifyChallenge checks the code of a login challenge and consumes the challenge when it matches.
+// It returns ErrInvalidToken if the challenge doesn't exist, expired or the code is wrong,
+// and ErrTooManyAttempts once too many wrong codes were submitted, after which the challenge is discarded.
+func (s LocationService) VerifyChallenge(challengeID uuid.UUID, code string) (*data.LoginChallenge, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	challenge := data.LoginChallenge{ID: challengeID}
+	var codeHash []byte
+	var attempts int
+
+	err = tx.QueryRow(
+		"SELECT user_id, country, code_hash, attempts, expires_at FROM login_challenges WHERE id = $1 AND expires_at > $2 FOR UPDATE",
+		challengeID, time.Now().UTC(),
+	).Scan(&challenge.UserID, &challenge.Country, &codeHash, &attempts, &challenge.ExpiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	hash := sha256.Sum256([]byte(code))
+	if subtle.ConstantTimeCompare(hash[:], codeHash) != 1 {
+		attempts++
+		if attempts >= maxAttempts {
+			if _, err := tx.Exec("DELETE FROM login_challenges WHERE id = $1", challengeID); err != nil {
+				return nil, err
+			}
+			if err := tx.Commit(); err != nil {
+				return nil, err
+			}
+			return nil, services.ErrTooManyAttempts
+		}
+
+		if _, err := tx.Exec("UPDATE login_challenges SET attempts = $2 WHERE id = $1", challengeID, attempts); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return nil, services.ErrInvalidToken
+	}
+
+	if _, err := tx.Exec("DELETE FROM login_challenges WHERE id = $1", challengeID); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &challenge, nil
+}
@@ -0,0 +1,134 @@
+package lti
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// keysetTTL defines for how long a fetched platform key set is trusted before refetching.
+const keysetTTL = time.Hour
+
+// loadPrivateKey reads a PEM encoded RSA private key from disk.
+func loadPrivateKey(path string) (*rsa.PrivateKey, error) {
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read LTI private key: %w", err)
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse LTI private key: %w", err)
+	}
+
+	return key, nil
+}
+
+// parseJWK converts an RSA JWK into a public key.
+func parseJWK(k data.JWK) (*rsa.PublicKey, error) {
+	if k.Kty != "RSA" {
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key modulus: %w", err)
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key exponent: %w", err)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
+
+type keyset struct {
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+// keysetCache fetches and caches the public key sets published by LTI platforms.
+type keysetCache struct {
+	mu      sync.Mutex
+	client  *http.Client
+	keysets map[string]keyset
+}
+
+func newKeysetCache(client *http.Client) *keysetCache {
+	return &keysetCache{
+		client:  client,
+		keysets: make(map[string]keyset),
+	}
+}
+
+// Key returns the public key identified by kid from the key set at url.
+// The key set is refetched when it is stale or does not contain kid, which handles platform key rotation.
+func (c *keysetCache) Key(url, kid string) (*rsa.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ks, ok := c.keysets[url]; ok && time.Since(ks.fetchedAt) < keysetTTL {
+		if key, ok := ks.keys[kid]; ok {
+			return key, nil
+		}
+	}
+
+	ks, err := c.fetch(url)
+	if err != nil {
+		return nil, err
+	}
+	c.keysets[url] = ks
+
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, errors.New("signing key not found in platform key set")
+	}
+
+	return key, nil
+}
+
+func (c *keysetCache) fetch(url string) (keyset, error) {
+	resp, err := c.client.Get(url)
+	if err != nil {
+		return keyset{}, fmt.Errorf("could not fetch platform key set: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return keyset{}, fmt.Errorf("could not fetch platform key set: status %d", resp.StatusCode)
+	}
+
+	var jwks data.JWKS
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return keyset{}, fmt.Errorf("could not decode platform key set: %w", err)
+	}
+
+	ks := keyset{
+		keys:      make(map[string]*rsa.PublicKey, len(jwks.Keys)),
+		fetchedAt: time.Now(),
+	}
+	for _, k := range jwks.Keys {
+		key, err := parseJWK(k)
+		if err != nil {
+			continue
+		}
+		ks.keys[k.Kid] = key
+	}
+
+	return ks, nil
+}
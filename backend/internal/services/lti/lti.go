@@ -0,0 +1,915 @@
+// Package lti implements an LTI 1.3 tool provider so LMS platforms (Canvas, Moodle, ...) can embed NodeTurtle.
+// It covers OIDC launches, deep linking of projects as assignments and grade passback (AGS) of submissions.
+package lti
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/httpclient"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/auth"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// LTI claim names and scopes used by the tool.
+const (
+	ltiVersion = "1.3.0"
+
+	claimMessageType   = "https://purl.imsglobal.org/spec/lti/claim/message_type"
+	claimVersion       = "https://purl.imsglobal.org/spec/lti/claim/version"
+	claimDeploymentID  = "https://purl.imsglobal.org/spec/lti/claim/deployment_id"
+	claimDeepLinkItems = "https://purl.imsglobal.org/spec/lti-dl/claim/content_items"
+	claimDeepLinkData  = "https://purl.imsglobal.org/spec/lti-dl/claim/data"
+
+	scopeScore = "https://purl.imsglobal.org/spec/lti-ags/scope/score"
+
+	loginStateTTL  = 10 * time.Minute
+	deepLinkTTL    = time.Hour
+	linkRequestTTL = time.Hour
+)
+
+// ILTIService defines the interface for LTI 1.3 tool operations.
+type ILTIService interface {
+	RegisterPlatform(p data.LTIPlatformCreate) (*data.LTIPlatform, error)
+	ListPlatforms() ([]data.LTIPlatform, error)
+	InitiateLogin(req data.LTILoginRequest) (string, error)
+	HandleLaunch(idToken, state string) (*data.LTILaunchResult, error)
+	CreateDeepLinkResponse(deepLinkID, userID uuid.UUID, projectIDs []uuid.UUID) (*data.LTIDeepLinkResponse, error)
+	SubmitAssignment(launchID, userID uuid.UUID) (*data.LTILaunch, error)
+	ConfirmLink(requestID, userID uuid.UUID) error
+	JWKS() data.JWKS
+}
+
+// LTIService implements the ILTIService interface.
+type LTIService struct {
+	db      *sql.DB
+	cfg     config.LTIConfig
+	key     *rsa.PrivateKey
+	keysets *keysetCache
+	client  *http.Client
+}
+
+// NewLTIService creates a new LTIService, loading the tool signing key from the configured path.
+func NewLTIService(db *sql.DB, cfg config.LTIConfig) (LTIService, error) {
+	key, err := loadPrivateKey(cfg.PrivateKeyPath)
+	if err != nil {
+		return LTIService{}, err
+	}
+
+	client := httpclient.New(httpclient.Options{Timeout: 10 * time.Second})
+
+	return LTIService{
+		db:      db,
+		cfg:     cfg,
+		key:     key,
+		keysets: newKeysetCache(client),
+		client:  client,
+	}, nil
+}
+
+// launchClaims holds the subset of the LTI id_token claims used by the tool.
+type launchClaims struct {
+	Issuer      string          `json:"iss"`
+	Subject     string          `json:"sub"`
+	Audience    json.RawMessage `json:"aud"`
+	AuthParty   string          `json:"azp"`
+	Nonce       string          `json:"nonce"`
+	Email       string          `json:"email"`
+	Name        string          `json:"name"`
+	GivenName   string          `json:"given_name"`
+	MessageType string          `json:"https://purl.imsglobal.org/spec/lti/claim/message_type"`
+	Version     string          `json:"https://purl.imsglobal.org/spec/lti/claim/version"`
+	Deployment  string          `json:"https://purl.imsglobal.org/spec/lti/claim/deployment_id"`
+	Custom      map[string]any  `json:"https://purl.imsglobal.org/spec/lti/claim/custom"`
+
+	ResourceLink struct {
+		ID string `json:"id"`
+	} `json:"https://purl.imsglobal.org/spec/lti/claim/resource_link"`
+
+	AGS *struct {
+		Scope    []string `json:"scope"`
+		LineItem string   `json:"lineitem"`
+	} `json:"https://purl.imsglobal.org/spec/lti-ags/claim/endpoint"`
+
+	DeepLinking *struct {
+		ReturnURL string `json:"deep_link_return_url"`
+		Data      string `json:"data"`
+	} `json:"https://purl.imsglobal.org/spec/lti-dl/claim/deep_linking_settings"`
+}
+
+// audiences returns the aud claim, which can be either a string or an array of strings.
+func (c launchClaims) audiences() []string {
+	var single string
+	if err := json.Unmarshal(c.Audience, &single); err == nil {
+		return []string{single}
+	}
+
+	var multiple []string
+	_ = json.Unmarshal(c.Audience, &multiple)
+	return multiple
+}
+
+// RegisterPlatform stores a new LTI platform registration.
+func (s LTIService) RegisterPlatform(p data.LTIPlatformCreate) (*data.LTIPlatform, error) {
+	var platform data.LTIPlatform
+
+	query := `
+		INSERT INTO lti_platforms (issuer, client_id, deployment_ids, auth_login_url, auth_token_url, keyset_url)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, issuer, client_id, deployment_ids, auth_login_url, auth_token_url, keyset_url, created_at`
+
+	err := s.db.QueryRow(query, p.Issuer, p.ClientID, pq.Array(p.DeploymentIDs), p.AuthLoginURL, p.AuthTokenURL, p.KeysetURL).Scan(
+		&platform.ID,
+		&platform.Issuer,
+		&platform.ClientID,
+		pq.Array(&platform.DeploymentIDs),
+		&platform.AuthLoginURL,
+		&platform.AuthTokenURL,
+		&platform.KeysetURL,
+		&platform.CreatedAt,
+	)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return nil, services.ErrPlatformExists
+		}
+		return nil, err
+	}
+
+	return &platform, nil
+}
+
+// ListPlatforms returns all registered LTI platforms.
+func (s LTIService) ListPlatforms() ([]data.LTIPlatform, error) {
+	query := `
+		SELECT id, issuer, client_id, deployment_ids, auth_login_url, auth_token_url, keyset_url, created_at
+		FROM lti_platforms
+		ORDER BY created_at DESC`
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	platforms := make([]data.LTIPlatform, 0)
+	for rows.Next() {
+		var p data.LTIPlatform
+		if err := rows.Scan(&p.ID, &p.Issuer, &p.ClientID, pq.Array(&p.DeploymentIDs), &p.AuthLoginURL, &p.AuthTokenURL, &p.KeysetURL, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		platforms = append(platforms, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return platforms, nil
+}
+
+// InitiateLogin handles the third-party initiated login and returns the platform authorization URL
+// the user agent has to be redirected to.
+func (s LTIService) InitiateLogin(req data.LTILoginRequest) (string, error) {
+	platform, err := s.findPlatform(req.Issuer, req.ClientID)
+	if err != nil {
+		return "", err
+	}
+
+	if req.LTIDeploymentID != "" && !platform.HasDeployment(req.LTIDeploymentID) {
+		return "", services.ErrUnknownPlatform
+	}
+
+	state, err := randomString()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.db.Exec(
+		"INSERT INTO lti_login_states (state, nonce, platform_id, expires_at) VALUES ($1, $2, $3, $4)",
+		state, nonce, platform.ID, time.Now().UTC().Add(loginStateTTL),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("scope", "openid")
+	params.Set("response_type", "id_token")
+	params.Set("response_mode", "form_post")
+	params.Set("prompt", "none")
+	params.Set("client_id", platform.ClientID)
+	params.Set("redirect_uri", s.launchURL())
+	params.Set("login_hint", req.LoginHint)
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	if req.LTIMessageHint != "" {
+		params.Set("lti_message_hint", req.LTIMessageHint)
+	}
+
+	separator := "?"
+	if strings.Contains(platform.AuthLoginURL, "?") {
+		separator = "&"
+	}
+
+	return platform.AuthLoginURL + separator + params.Encode(), nil
+}
+
+// HandleLaunch validates an id_token posted by the platform, resolves the NodeTurtle account of the launching user
+// and records the launch. Returns ErrInvalidToken if the state, signature or any of the required claims are invalid.
+// A launch by an LMS user with the email of an unlinked account only records a link request, see ConfirmLink.
+func (s LTIService) HandleLaunch(idToken, state string) (*data.LTILaunchResult, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// state is single use, consuming it also protects against replayed launches
+	var nonce string
+	var platformID int64
+	err = tx.QueryRow(
+		"DELETE FROM lti_login_states WHERE state = $1 AND expires_at > $2 RETURNING nonce, platform_id",
+		state, time.Now().UTC(),
+	).Scan(&nonce, &platformID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	platform, err := s.getPlatform(tx, platformID)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := s.verifyIDToken(idToken, *platform)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Nonce != nonce {
+		return nil, services.ErrInvalidToken
+	}
+
+	userID, linkRequestID, err := s.resolveUser(tx, *platform, *claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if linkRequestID != nil {
+		if err = tx.Commit(); err != nil {
+			return nil, err
+		}
+		return &data.LTILaunchResult{MessageType: claims.MessageType, LinkRequestID: linkRequestID}, nil
+	}
+
+	result := data.LTILaunchResult{
+		MessageType: claims.MessageType,
+		UserID:      userID,
+	}
+
+	switch claims.MessageType {
+	case data.LTIMessageResourceLink:
+		if claims.ResourceLink.ID == "" {
+			return nil, services.ErrInvalidToken
+		}
+
+		if raw, ok := claims.Custom["project_id"].(string); ok {
+			if projectID, err := uuid.Parse(raw); err == nil {
+				result.ProjectID = &projectID
+			}
+		}
+
+		var lineItem *string
+		if claims.AGS != nil && claims.AGS.LineItem != "" && contains(claims.AGS.Scope, scopeScore) {
+			lineItem = &claims.AGS.LineItem
+		}
+
+		var launchID uuid.UUID
+		err = tx.QueryRow(`
+			INSERT INTO lti_launches (platform_id, user_id, subject, project_id, resource_link_id, lineitem_url)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id`,
+			platform.ID, userID, claims.Subject, result.ProjectID, claims.ResourceLink.ID, lineItem,
+		).Scan(&launchID)
+		if err != nil {
+			return nil, err
+		}
+		result.LaunchID = &launchID
+
+	case data.LTIMessageDeepLinking:
+		if claims.DeepLinking == nil || claims.DeepLinking.ReturnURL == "" {
+			return nil, services.ErrInvalidToken
+		}
+
+		var deepLinkID uuid.UUID
+		err = tx.QueryRow(`
+			INSERT INTO lti_deep_links (platform_id, user_id, deployment_id, return_url, data, expires_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id`,
+			platform.ID, userID, claims.Deployment, claims.DeepLinking.ReturnURL, claims.DeepLinking.Data, time.Now().UTC().Add(deepLinkTTL),
+		).Scan(&deepLinkID)
+		if err != nil {
+			return nil, err
+		}
+		result.DeepLinkID = &deepLinkID
+
+	default:
+		return nil, services.ErrUnsupportedMessage
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// CreateDeepLinkResponse builds the signed deep linking response which adds the selected projects
+// as assignments (LTI resource links with a gradable line item) to the platform course.
+// Only public projects or projects owned by the instructor can be linked.
+func (s LTIService) CreateDeepLinkResponse(deepLinkID, userID uuid.UUID, projectIDs []uuid.UUID) (*data.LTIDeepLinkResponse, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var platformID int64
+	var deploymentID, returnURL string
+	var dlData sql.NullString
+	err = tx.QueryRow(
+		"DELETE FROM lti_deep_links WHERE id = $1 AND user_id = $2 AND expires_at > $3 RETURNING platform_id, deployment_id, return_url, data",
+		deepLinkID, userID, time.Now().UTC(),
+	).Scan(&platformID, &deploymentID, &returnURL, &dlData)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	platform, err := s.getPlatform(tx, platformID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(
+		"SELECT id, title, description FROM projects WHERE id = ANY($1) AND ((is_public = TRUE AND hidden_at IS NULL) OR creator_id = $2)",
+		pq.Array(projectIDs), userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]map[string]any, 0, len(projectIDs))
+	for rows.Next() {
+		var id uuid.UUID
+		var title string
+		var description sql.NullString
+		if err := rows.Scan(&id, &title, &description); err != nil {
+			return nil, err
+		}
+
+		items = append(items, map[string]any{
+			"type":   "ltiResourceLink",
+			"title":  title,
+			"text":   description.String,
+			"url":    s.launchURL(),
+			"custom": map[string]string{"project_id": id.String()},
+			"lineItem": map[string]any{
+				"scoreMaximum": 100,
+				"label":        title,
+			},
+		})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return nil, services.ErrProjectNotFound
+	}
+
+	nonce, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	claims := jwt.MapClaims{
+		"iss":              platform.ClientID,
+		"aud":              platform.Issuer,
+		"iat":              now.Unix(),
+		"exp":              now.Add(5 * time.Minute).Unix(),
+		"nonce":            nonce,
+		claimDeploymentID:  deploymentID,
+		claimMessageType:   "LtiDeepLinkingResponse",
+		claimVersion:       ltiVersion,
+		claimDeepLinkItems: items,
+	}
+	if dlData.Valid && dlData.String != "" {
+		claims[claimDeepLinkData] = dlData.String
+	}
+
+	signed, err := s.sign(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &data.LTIDeepLinkResponse{
+		ReturnURL: returnURL,
+		JWT:       signed,
+	}, nil
+}
+
+// SubmitAssignment marks the assignment behind a launch as submitted in the platform gradebook,
+// leaving the actual grading to the instructor. Returns ErrGradingUnavailable if the platform
+// did not grant the score scope for the launch.
+func (s LTIService) SubmitAssignment(launchID, userID uuid.UUID) (*data.LTILaunch, error) {
+	var launch data.LTILaunch
+	err := s.db.QueryRow(`
+		SELECT id, platform_id, user_id, subject, project_id, resource_link_id, lineitem_url, submitted_at, created_at
+		FROM lti_launches
+		WHERE id = $1 AND user_id = $2`,
+		launchID, userID,
+	).Scan(
+		&launch.ID, &launch.PlatformID, &launch.UserID, &launch.Subject, &launch.ProjectID,
+		&launch.ResourceLinkID, &launch.LineItemURL, &launch.SubmittedAt, &launch.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	if launch.LineItemURL == nil {
+		return nil, services.ErrGradingUnavailable
+	}
+
+	platform, err := s.getPlatform(s.db, launch.PlatformID)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := s.accessToken(*platform, scopeScore)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	score, err := json.Marshal(map[string]string{
+		"userId":           launch.Subject,
+		"activityProgress": "Submitted",
+		"gradingProgress":  "PendingManual",
+		"timestamp":        now.Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, scoresURL(*launch.LineItemURL), bytes.NewReader(score))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/vnd.ims.lis.v1.score+json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not post score: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("could not post score: status %d", resp.StatusCode)
+	}
+
+	err = s.db.QueryRow("UPDATE lti_launches SET submitted_at = $2 WHERE id = $1 RETURNING submitted_at", launch.ID, now).Scan(&launch.SubmittedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &launch, nil
+}
+
+// JWKS returns the public key set of the tool, used by platforms to verify messages signed by NodeTurtle.
+func (s LTIService) JWKS() data.JWKS {
+	return data.JWKS{
+		Keys: []data.JWK{data.NewRSAJWK(&s.key.PublicKey, s.cfg.KeyID)},
+	}
+}
+
+// verifyIDToken checks the signature of the id_token against the platform key set and validates the LTI claims.
+func (s LTIService) verifyIDToken(idToken string, platform data.LTIPlatform) (*launchClaims, error) {
+	token, err := jwt.Parse(idToken, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		return s.keysets.Key(platform.KeysetURL, kid)
+	})
+	if err != nil || !token.Valid {
+		return nil, services.ErrInvalidToken
+	}
+
+	mapClaims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, services.ErrInvalidToken
+	}
+
+	raw, err := json.Marshal(mapClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims launchClaims
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		return nil, services.ErrInvalidToken
+	}
+
+	audiences := claims.audiences()
+	switch {
+	case claims.Issuer != platform.Issuer,
+		!contains(audiences, platform.ClientID),
+		len(audiences) > 1 && claims.AuthParty != platform.ClientID,
+		claims.Version != ltiVersion,
+		claims.Subject == "",
+		!platform.HasDeployment(claims.Deployment):
+		return nil, services.ErrInvalidToken
+	}
+
+	return &claims, nil
+}
+
+// resolveUser returns the NodeTurtle account linked to the LMS user, otherwise a new activated account is created.
+// The email claim is not verified by the platform, so an existing account with the same email is never linked
+// automatically: a link request is returned instead, which the owner of the account has to confirm.
+// Returns ErrInactiveAccount for a deactivated account and ErrPrivilegedAccount for staff accounts.
+func (s LTIService) resolveUser(tx *sql.Tx, platform data.LTIPlatform, claims launchClaims) (uuid.UUID, *uuid.UUID, error) {
+	var userID uuid.UUID
+	var roleID int64
+	var activated bool
+
+	err := tx.QueryRow(`
+		SELECT u.id, u.role_id, u.activated
+		FROM lti_user_links l
+		JOIN users u ON u.id = l.user_id
+		WHERE l.platform_id = $1 AND l.subject = $2`,
+		platform.ID, claims.Subject,
+	).Scan(&userID, &roleID, &activated)
+	if err == nil {
+		switch {
+		case roleID >= data.RolesAsInt[data.RoleModerator]:
+			return uuid.Nil, nil, services.ErrPrivilegedAccount
+		case !activated:
+			return uuid.Nil, nil, services.ErrInactiveAccount
+		}
+		return userID, nil, nil
+	}
+	if err != sql.ErrNoRows {
+		return uuid.Nil, nil, err
+	}
+
+	email := claims.Email
+	if email == "" {
+		// platforms may withhold personal data, fall back to an undeliverable address unique to the LMS user
+		sum := sha256.Sum256([]byte(platform.Issuer + "|" + claims.Subject))
+		email = hex.EncodeToString(sum[:10]) + "@lti.invalid"
+	}
+
+	err = tx.QueryRow("SELECT id, role_id FROM users WHERE email = $1", email).Scan(&userID, &roleID)
+	switch {
+	case err == nil:
+		if roleID >= data.RolesAsInt[data.RoleModerator] {
+			return uuid.Nil, nil, services.ErrPrivilegedAccount
+		}
+
+		var requestID uuid.UUID
+		err = tx.QueryRow(`
+			INSERT INTO lti_link_requests (platform_id, subject, user_id, expires_at)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id`,
+			platform.ID, claims.Subject, userID, time.Now().UTC().Add(linkRequestTTL),
+		).Scan(&requestID)
+		if err != nil {
+			return uuid.Nil, nil, err
+		}
+		return uuid.Nil, &requestID, nil
+
+	case err != sql.ErrNoRows:
+		return uuid.Nil, nil, err
+	}
+
+	userID, err = s.createUser(tx, email, claims)
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+
+	_, err = tx.Exec("INSERT INTO lti_user_links (platform_id, subject, user_id) VALUES ($1, $2, $3)", platform.ID, claims.Subject, userID)
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+
+	return userID, nil, nil
+}
+
+// ConfirmLink links the LMS user of a link request to the account of the signed-in user the request was made for,
+// so their next launch signs them in. Returns ErrRecordNotFound if the request doesn't exist, has expired
+// or belongs to another account, and ErrPrivilegedAccount for staff accounts.
+func (s LTIService) ConfirmLink(requestID, userID uuid.UUID) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var roleID int64
+	if err := tx.QueryRow("SELECT role_id FROM users WHERE id = $1", userID).Scan(&roleID); err != nil {
+		if err == sql.ErrNoRows {
+			return services.ErrRecordNotFound
+		}
+		return err
+	}
+	if roleID >= data.RolesAsInt[data.RoleModerator] {
+		return services.ErrPrivilegedAccount
+	}
+
+	var platformID int64
+	var subject string
+	err = tx.QueryRow(
+		"DELETE FROM lti_link_requests WHERE id = $1 AND user_id = $2 AND expires_at > $3 RETURNING platform_id, subject",
+		requestID, userID, time.Now().UTC(),
+	).Scan(&platformID, &subject)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return services.ErrRecordNotFound
+		}
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO lti_user_links (platform_id, subject, user_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (platform_id, subject) DO NOTHING`,
+		platformID, subject, userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// createUser creates an activated account for an LMS user. The account gets a random password,
+// the user can set their own through the password reset flow.
+func (s LTIService) createUser(tx *sql.Tx, email string, claims launchClaims) (uuid.UUID, error) {
+	password, err := randomString()
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	hashedPassword, err := auth.HashPassword(password)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	base := usernameBase(claims.GivenName)
+	if base == "" {
+		base = usernameBase(claims.Name)
+	}
+	if base == "" {
+		base = "student"
+	}
+
+	for attempt := 0; attempt < 5; attempt++ {
+		suffix, err := randomDigits(4)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		username := base + suffix
+
+		var exists bool
+		if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)", username).Scan(&exists); err != nil {
+			return uuid.Nil, err
+		}
+		if exists {
+			continue
+		}
+
+		var userID uuid.UUID
+		err = tx.QueryRow(`
+			INSERT INTO users (email, username, password, role_id, activated, created_at)
+			VALUES ($1, $2, $3, $4, $5, NOW() AT TIME ZONE 'UTC')
+			RETURNING id`,
+			email, username, hashedPassword, data.RoleUser, true,
+		).Scan(&userID)
+
+		return userID, err
+	}
+
+	return uuid.Nil, services.ErrDuplicateUsername
+}
+
+// accessToken requests an OAuth2 access token from the platform using the client credentials grant
+// with a JWT client assertion signed by the tool key.
+func (s LTIService) accessToken(platform data.LTIPlatform, scope string) (string, error) {
+	jti, err := randomString()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+	assertion, err := s.sign(jwt.MapClaims{
+		"iss": platform.ClientID,
+		"sub": platform.ClientID,
+		"aud": platform.AuthTokenURL,
+		"iat": now.Unix(),
+		"exp": now.Add(5 * time.Minute).Unix(),
+		"jti": jti,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_assertion_type", "urn:ietf:params:oauth:client-assertion-type:jwt-bearer")
+	form.Set("client_assertion", assertion)
+	form.Set("scope", scope)
+
+	resp, err := s.client.PostForm(platform.AuthTokenURL, form)
+	if err != nil {
+		return "", fmt.Errorf("could not request platform access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("could not request platform access token: status %d", resp.StatusCode)
+	}
+
+	var payload struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return "", err
+	}
+	if payload.AccessToken == "" {
+		return "", errors.New("platform returned an empty access token")
+	}
+
+	return payload.AccessToken, nil
+}
+
+// sign creates an RS256 JWT signed with the tool key.
+func (s LTIService) sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = s.cfg.KeyID
+	return token.SignedString(s.key)
+}
+
+func (s LTIService) launchURL() string {
+	return strings.TrimRight(s.cfg.ToolURL, "/") + "/api/lti/launch"
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func (s LTIService) getPlatform(q queryer, id int64) (*data.LTIPlatform, error) {
+	var p data.LTIPlatform
+	err := q.QueryRow(`
+		SELECT id, issuer, client_id, deployment_ids, auth_login_url, auth_token_url, keyset_url, created_at
+		FROM lti_platforms WHERE id = $1`, id,
+	).Scan(&p.ID, &p.Issuer, &p.ClientID, pq.Array(&p.DeploymentIDs), &p.AuthLoginURL, &p.AuthTokenURL, &p.KeysetURL, &p.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrUnknownPlatform
+		}
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// findPlatform looks up a platform by issuer. The client ID is optional in the login request,
+// when missing the issuer must identify a single registration.
+func (s LTIService) findPlatform(issuer, clientID string) (*data.LTIPlatform, error) {
+	query := `
+		SELECT id, issuer, client_id, deployment_ids, auth_login_url, auth_token_url, keyset_url, created_at
+		FROM lti_platforms
+		WHERE issuer = $1 AND ($2 = '' OR client_id = $2)
+		LIMIT 2`
+
+	rows, err := s.db.Query(query, issuer, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	platforms := make([]data.LTIPlatform, 0, 2)
+	for rows.Next() {
+		var p data.LTIPlatform
+		if err := rows.Scan(&p.ID, &p.Issuer, &p.ClientID, pq.Array(&p.DeploymentIDs), &p.AuthLoginURL, &p.AuthTokenURL, &p.KeysetURL, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		platforms = append(platforms, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(platforms) != 1 {
+		return nil, services.ErrUnknownPlatform
+	}
+
+	return &platforms[0], nil
+}
+
+// scoresURL returns the AGS scores endpoint of a line item, keeping any query parameters.
+func scoresURL(lineItem string) string {
+	base, query, found := strings.Cut(lineItem, "?")
+	scores := strings.TrimRight(base, "/") + "/scores"
+	if found {
+		scores += "?" + query
+	}
+	return scores
+}
+
+// usernameBase strips everything but letters and digits from a display name so it passes username validation.
+func usernameBase(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+		if b.Len() == 12 {
+			break
+		}
+	}
+
+	if b.Len() < 3 {
+		return ""
+	}
+	return b.String()
+}
+
+func randomString() (string, error) {
+	bytes := make([]byte, 24)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+func randomDigits(n int) (string, error) {
+	bytes := make([]byte, n)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	for i := range bytes {
+		bytes[i] = '0' + bytes[i]%10
+	}
+	return string(bytes), nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
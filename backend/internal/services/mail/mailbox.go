@@ -0,0 +1,73 @@
+package mail
+
+import (
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+)
+
+// mailboxSize is how many captured emails a Mailbox keeps, the oldest ones are dropped first.
+const mailboxSize = 500
+
+// Mailbox keeps the emails the mail service captures instead of sending them, in dev and test setups
+// with MAIL_CAPTURE, so end-to-end tests can read activation and reset links without an SMTP server.
+// It's safe for concurrent use.
+type Mailbox struct {
+	mu     sync.Mutex
+	emails []data.CapturedEmail
+}
+
+// NewMailbox creates an empty Mailbox.
+func NewMailbox() *Mailbox {
+	return &Mailbox{}
+}
+
+// Add captures an email, dropping the oldest one once the mailbox is full.
+func (b *Mailbox) Add(email data.CapturedEmail) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	email.Data = maps.Clone(email.Data)
+	if len(b.emails) >= mailboxSize {
+		b.emails = b.emails[1:]
+	}
+	b.emails = append(b.emails, email)
+}
+
+// capture adds an email rendered by the mail service.
+func (b *Mailbox) capture(to, subject, templateName, messageID, body string, values map[string]string) {
+	b.Add(data.CapturedEmail{
+		To:        to,
+		Subject:   subject,
+		Template:  templateName,
+		Data:      values,
+		Body:      body,
+		MessageID: messageID,
+		SentAt:    time.Now().UTC(),
+	})
+}
+
+// List returns the captured emails, newest first, only those sent to the recipient if it isn't empty.
+func (b *Mailbox) List(recipient string) []data.CapturedEmail {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	emails := []data.CapturedEmail{}
+	for i := len(b.emails) - 1; i >= 0; i-- {
+		if recipient == "" || strings.EqualFold(b.emails[i].To, recipient) {
+			emails = append(emails, b.emails[i])
+		}
+	}
+	return emails
+}
+
+// Clear drops every captured email, so each test can start from an empty mailbox.
+func (b *Mailbox) Clear() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.emails = nil
+}
@@ -0,0 +1,172 @@
+package mail
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"strings"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/consents"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// unsubscribePath is where the unsubscribe links of optional emails lead, relative to the client URL.
+const unsubscribePath = "/api/email/unsubscribe/"
+
+// GetPreferences retrieves which optional emails the user gets, falling back to the defaults where they never chose.
+// It returns ErrUserNotFound if the user doesn't exist.
+func (s *MailService) GetPreferences(ctx context.Context, userID uuid.UUID) (*data.EmailPreferences, error) {
+	_, prefs, err := s.preferences(ctx, "u.id = $1", userID)
+	return prefs, err
+}
+
+// UpdatePreferences changes which optional emails the user gets. Marketing gives or withdraws the consent
+// to marketing emails. It returns ErrUserNotFound if the user doesn't exist.
+func (s *MailService) UpdatePreferences(ctx context.Context, userID uuid.UUID, update data.EmailPreferencesUpdate) (*data.EmailPreferences, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if update.Digests != nil || update.Security != nil {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO email_preferences (user_id, digests, security)
+			VALUES ($1, COALESCE($2, TRUE), COALESCE($3, TRUE))
+			ON CONFLICT (user_id) DO UPDATE SET
+				digests = COALESCE($2, email_preferences.digests),
+				security = COALESCE($3, email_preferences.security),
+				updated_at = NOW()`,
+			userID, update.Digests, update.Security,
+		)
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+				return nil, services.ErrUserNotFound
+			}
+			return nil, err
+		}
+	}
+
+	if update.Marketing != nil {
+		if err = consents.Record(ctx, tx, userID, data.ConsentMarketingEmails, *update.Marketing); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return s.GetPreferences(ctx, userID)
+}
+
+// Unsubscribe turns off the category of emails the unsubscribe token was issued for, and returns the category.
+// Unsubscribing again changes nothing. It returns ErrInvalidToken if the token wasn't issued by us,
+// or ErrUserNotFound if the user no longer exists.
+func (s *MailService) Unsubscribe(ctx context.Context, token string) (data.EmailCategory, error) {
+	userID, category, err := s.parseUnsubscribeToken(token)
+	if err != nil {
+		return "", err
+	}
+
+	off := false
+	var update data.EmailPreferencesUpdate
+	switch category {
+	case data.EmailMarketing:
+		update.Marketing = &off
+	case data.EmailDigests:
+		update.Digests = &off
+	case data.EmailSecurity:
+		update.Security = &off
+	default:
+		return "", services.ErrInvalidToken
+	}
+
+	if _, err := s.UpdatePreferences(ctx, userID, update); err != nil {
+		return "", err
+	}
+	return category, nil
+}
+
+// recipientPreferences retrieves the user an address belongs to and their preferences.
+// The user ID is nil when the address isn't one of a user.
+func (s *MailService) recipientPreferences(ctx context.Context, email string) (*uuid.UUID, *data.EmailPreferences, error) {
+	userID, prefs, err := s.preferences(ctx, "u.email = $1", email)
+	if err == services.ErrUserNotFound {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return &userID, prefs, nil
+}
+
+func (s *MailService) preferences(ctx context.Context, where string, arg interface{}) (uuid.UUID, *data.EmailPreferences, error) {
+	var userID uuid.UUID
+	var prefs data.EmailPreferences
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT u.id, COALESCE(c.granted, $2), COALESCE(ep.digests, TRUE), COALESCE(ep.security, TRUE),
+		       GREATEST(ep.updated_at, c.updated_at)
+		FROM users u
+		LEFT JOIN email_preferences ep ON ep.user_id = u.id
+		LEFT JOIN user_consents c ON c.user_id = u.id AND c.purpose = $3
+		WHERE `+where,
+		arg, data.DefaultConsent(data.ConsentMarketingEmails), data.ConsentMarketingEmails,
+	).Scan(&userID, &prefs.Marketing, &prefs.Digests, &prefs.Security, &prefs.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.Nil, nil, services.ErrUserNotFound
+		}
+		return uuid.Nil, nil, err
+	}
+
+	return userID, &prefs, nil
+}
+
+// unsubscribeToken returns the token of the link unsubscribing the user from a category of emails.
+// It's signed rather than stored and doesn't expire, links in old emails keep working.
+func (s *MailService) unsubscribeToken(userID uuid.UUID, category data.EmailCategory) string {
+	payload := userID.String() + ":" + string(category)
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + base64.RawURLEncoding.EncodeToString(s.signUnsubscribe(payload))
+}
+
+// parseUnsubscribeToken verifies the signature of an unsubscribe token and returns what it unsubscribes from.
+func (s *MailService) parseUnsubscribeToken(token string) (uuid.UUID, data.EmailCategory, error) {
+	encodedPayload, encodedSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return uuid.Nil, "", services.ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return uuid.Nil, "", services.ErrInvalidToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, s.signUnsubscribe(string(payload))) {
+		return uuid.Nil, "", services.ErrInvalidToken
+	}
+
+	id, category, ok := strings.Cut(string(payload), ":")
+	if !ok {
+		return uuid.Nil, "", services.ErrInvalidToken
+	}
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, "", services.ErrInvalidToken
+	}
+
+	return userID, data.EmailCategory(category), nil
+}
+
+func (s *MailService) signUnsubscribe(payload string) []byte {
+	mac := hmac.New(sha256.New, []byte(s.config.UnsubscribeSecret))
+	mac.Write([]byte("unsubscribe:" + payload))
+	return mac.Sum(nil)
+}
@@ -2,73 +2,292 @@ package mail
 
 import (
 	"bytes"
+	"context"
+	"database/sql"
 	"fmt"
 	"html/template"
+	"net/mail"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/requestid"
 
+	"github.com/google/uuid"
 	"gopkg.in/gomail.v2"
 )
 
 type IMailService interface {
-	SendEmail(to, subject, templateName string, data map[string]string) error
+	SendEmail(ctx context.Context, to, subject, templateName string, data map[string]string) error
+	QueueEmail(ctx context.Context, to, subject, templateName string, data map[string]string) error
+	ListEmails(ctx context.Context, filter data.EmailFilter) ([]data.Email, error)
+	ListTemplates() []data.EmailTemplate
+	PreviewTemplate(name string, overrides map[string]string) (*data.EmailPreview, error)
+	GetPreferences(ctx context.Context, userID uuid.UUID) (*data.EmailPreferences, error)
+	UpdatePreferences(ctx context.Context, userID uuid.UUID, update data.EmailPreferencesUpdate) (*data.EmailPreferences, error)
+	Unsubscribe(ctx context.Context, token string) (data.EmailCategory, error)
 }
 
 type MailService struct {
+	db        *sql.DB
 	config    config.MailConfig
 	templates map[string]*template.Template
 	dialer    *gomail.Dialer
+	mailbox   *Mailbox // set when emails are captured instead of sent
 }
 
-func NewMailService(cfg config.MailConfig) MailService {
-	templates := make(map[string]*template.Template)
+// NewMailService creates a new MailService sending through the SMTP server of the config
+// and recording the emails it sends in the database. With capture enabled in the config,
+// emails are kept in its Mailbox instead of being sent.
+func NewMailService(db *sql.DB, cfg config.MailConfig) MailService {
+	loaded := make(map[string]*template.Template)
 	templateDir := "internal/services/mail/templates"
 
-	templateFiles := []string{"activation", "reset", "deactivation", "ban"}
-	for _, name := range templateFiles {
+	for name := range templates {
 		templatePath := filepath.Join(templateDir, name+".html")
 		tmpl, err := template.ParseFiles(templatePath)
 		if err != nil {
 			fmt.Printf("Failed to load email template %s: %v\n", name, err)
 			continue
 		}
-		templates[name] = tmpl
+		loaded[name] = tmpl
 	}
 
 	dialer := gomail.NewDialer(cfg.Host, cfg.Port, cfg.Username, cfg.Password)
 
+	var mailbox *Mailbox
+	if cfg.Capture {
+		mailbox = NewMailbox()
+	}
+
 	return MailService{
+		db:        db,
 		config:    cfg,
-		templates: templates,
+		templates: loaded,
 		dialer:    dialer,
+		mailbox:   mailbox,
 	}
 }
 
-func (s *MailService) SendEmail(to, subject, templateName string, data map[string]string) error {
-	tmpl, ok := s.templates[templateName]
-	if !ok {
-		return fmt.Errorf("template %s not found", templateName)
+// Mailbox returns the mailbox of the captured emails, nil unless capture is enabled.
+func (s *MailService) Mailbox() *Mailbox {
+	return s.mailbox
+}
+
+// SendEmail renders a template and sends it. Every email is recorded in the outbox with the ID of the request
+// in ctx, and the outcome of sending it, so support can tell whether an email a user never got left the API.
+// Emails are usually sent in the background, so recording them outlives the cancellation of ctx.
+//
+// Emails of a template with a category are only sent to users who didn't unsubscribe from it, and carry
+// a link to unsubscribe. Suppressed emails are recorded all the same.
+func (s *MailService) SendEmail(ctx context.Context, to, subject, templateName string, data map[string]string) error {
+	ctx = context.WithoutCancel(ctx)
+
+	// SMTP doesn't hand back an ID of the accepted email, so it gets a Message-ID of ours that providers index
+	messageID := fmt.Sprintf("<%s@%s>", uuid.New(), s.senderDomain())
+
+	emailID, err := s.recordEmail(ctx, to, subject, templateName, requestid.FromContext(ctx), messageID)
+	if err != nil {
+		// an email that can't be recorded is still sent
+		logging.FromContext(ctx).ErrorContext(ctx, "Failed to record email", "to", to, "error", err)
 	}
 
-	var body bytes.Buffer
+	if category := templates[templateName].Category; category != "" {
+		userID, prefs, err := s.recipientPreferences(ctx, to)
+		switch {
+		case err != nil:
+			// preferences that can't be read don't hold up the email
+			logging.FromContext(ctx).ErrorContext(ctx, "Failed to read email preferences", "to", to, "error", err)
+		case userID != nil && !prefs.Allows(category):
+			if emailID != 0 {
+				if err := s.recordSuppressed(ctx, emailID); err != nil {
+					logging.FromContext(ctx).ErrorContext(ctx, "Failed to record outcome of email", "email_id", emailID, "error", err)
+				}
+			}
+			return nil
+		case userID != nil:
+			data["unsubscribe_url"] = unsubscribePath + s.unsubscribeToken(*userID, category)
+		}
+	}
 
-	route, present := data["url"]
-	if present {
-		// attach client url to the route
-		route = s.config.ClientURL + route
-		data["url"] = route
+	err = s.send(to, subject, templateName, messageID, data)
+	if emailID != 0 {
+		if recordErr := s.recordOutcome(ctx, emailID, err); recordErr != nil {
+			logging.FromContext(ctx).ErrorContext(ctx, "Failed to record outcome of email", "email_id", emailID, "error", recordErr)
+		}
 	}
 
-	if err := tmpl.Execute(&body, data); err != nil {
+	return err
+}
+
+func (s *MailService) send(to, subject, templateName, messageID string, data map[string]string) error {
+	body, err := s.render(templateName, data)
+	if err != nil {
 		return err
 	}
 
+	if s.mailbox != nil {
+		s.mailbox.capture(to, subject, templateName, messageID, body, data)
+		return nil
+	}
+
 	m := gomail.NewMessage()
 	m.SetHeader("From", s.config.From)
 	m.SetHeader("To", to)
 	m.SetHeader("Subject", subject)
-	m.SetBody("text/html", body.String())
+	m.SetHeader("Message-ID", messageID)
+	if unsubscribe := data["unsubscribe_url"]; unsubscribe != "" {
+		// mail clients show their own unsubscribe button, posting to the link
+		m.SetHeader("List-Unsubscribe", "<"+unsubscribe+">")
+		m.SetHeader("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	}
+	m.SetBody("text/html", body)
 
 	return s.dialer.DialAndSend(m)
 }
+
+// render renders a template, with the "url" and "unsubscribe_url" fields relative to the client URL.
+func (s *MailService) render(templateName string, data map[string]string) (string, error) {
+	tmpl, ok := s.templates[templateName]
+	if !ok {
+		return "", fmt.Errorf("template %s not found", templateName)
+	}
+
+	for _, field := range []string{"url", "unsubscribe_url"} {
+		if route, present := data[field]; present {
+			// attach client url to the route
+			data[field] = s.config.ClientURL + route
+		}
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		return "", err
+	}
+	return body.String(), nil
+}
+
+// ListTemplates returns the email templates in alphabetical order, and whether each was loaded.
+func (s *MailService) ListTemplates() []data.EmailTemplate {
+	list := make([]data.EmailTemplate, 0, len(templates))
+	for name, t := range templates {
+		t.Name = name
+		_, t.Loaded = s.templates[name]
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// PreviewTemplate renders a template with its sample data, with the fields in overrides replacing those of the sample.
+// It returns ErrTemplateNotFound if there's no such template.
+func (s *MailService) PreviewTemplate(name string, overrides map[string]string) (*data.EmailPreview, error) {
+	t, ok := templates[name]
+	if !ok {
+		return nil, ErrTemplateNotFound
+	}
+
+	fields := make(map[string]string, len(t.Sample)+len(overrides))
+	for k, v := range t.Sample {
+		fields[k] = v
+	}
+	for k, v := range overrides {
+		fields[k] = v
+	}
+
+	rendered := make(map[string]string, len(fields))
+	for k, v := range fields {
+		rendered[k] = v
+	}
+	html, err := s.render(name, rendered)
+	if err != nil {
+		return nil, err
+	}
+	return &data.EmailPreview{Template: name, Subject: t.Subject, Data: fields, HTML: html}, nil
+}
+
+// senderDomain returns the domain of the sender address, the right-hand side of the Message-IDs.
+func (s *MailService) senderDomain() string {
+	if from, err := mail.ParseAddress(s.config.From); err == nil {
+		if _, domain, ok := strings.Cut(from.Address, "@"); ok && domain != "" {
+			return domain
+		}
+	}
+	return "localhost"
+}
+
+// recordEmail adds a pending email to the outbox, linked to the user the recipient address belongs to.
+func (s *MailService) recordEmail(ctx context.Context, to, subject, templateName, requestID, messageID string) (int64, error) {
+	var emailID int64
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO email_outbox (user_id, recipient, template, subject, request_id, message_id)
+		VALUES ((SELECT id FROM users WHERE email = $1), $1, $2, $3, $4, $5)
+		RETURNING id`,
+		to, templateName, subject, requestID, messageID,
+	).Scan(&emailID)
+	return emailID, err
+}
+
+// recordOutcome marks an email of the outbox as sent, or failed with the error.
+func (s *MailService) recordOutcome(ctx context.Context, emailID int64, sendErr error) error {
+	if sendErr != nil {
+		_, err := s.db.ExecContext(ctx, "UPDATE email_outbox SET status = $2, error = $3 WHERE id = $1", emailID, data.EmailFailed, sendErr.Error())
+		return err
+	}
+
+	_, err := s.db.ExecContext(ctx, "UPDATE email_outbox SET status = $2, sent_at = NOW() WHERE id = $1", emailID, data.EmailSent)
+	return err
+}
+
+// recordSuppressed marks an email of the outbox as not sent, the recipient unsubscribed from its category.
+func (s *MailService) recordSuppressed(ctx context.Context, emailID int64) error {
+	_, err := s.db.ExecContext(ctx, "UPDATE email_outbox SET status = $2 WHERE id = $1", emailID, data.EmailSuppressed)
+	return err
+}
+
+// ListEmails retrieves the emails of the outbox matching the filter, newest first.
+func (s *MailService) ListEmails(ctx context.Context, filter data.EmailFilter) ([]data.Email, error) {
+	conditions := []string{"TRUE"}
+	args := []interface{}{}
+
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		conditions = append(conditions, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+	if filter.Recipient != "" {
+		args = append(args, filter.Recipient)
+		conditions = append(conditions, fmt.Sprintf("LOWER(recipient) = LOWER($%d)", len(args)))
+	}
+	if filter.RequestID != "" {
+		args = append(args, filter.RequestID)
+		conditions = append(conditions, fmt.Sprintf("request_id = $%d", len(args)))
+	}
+	args = append(args, filter.Limit)
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, user_id, recipient, template, subject, request_id, message_id, status, error, created_at, sent_at
+		FROM email_outbox
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY created_at DESC, id DESC
+		LIMIT $`+fmt.Sprint(len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	emails := []data.Email{}
+	for rows.Next() {
+		var e data.Email
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Recipient, &e.Template, &e.Subject, &e.RequestID, &e.MessageID, &e.Status, &e.Error, &e.CreatedAt, &e.SentAt); err != nil {
+			return nil, err
+		}
+		emails = append(emails, e)
+	}
+
+	return emails, rows.Err()
+}
@@ -0,0 +1,33 @@
+package mail
+
+import (
+	"context"
+	"encoding/json"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services/tasks"
+)
+
+// QueueEmail queues an email to be sent by the task runner, which retries it with backoff until the SMTP server
+// takes it. Unlike sending the email in the background, the email isn't lost when sending fails or the API stops.
+func (s *MailService) QueueEmail(ctx context.Context, to, subject, templateName string, emailData map[string]string) error {
+	return tasks.Enqueue(ctx, s.db, data.TaskEmail, data.EmailTask{
+		To:       to,
+		Subject:  subject,
+		Template: templateName,
+		Data:     emailData,
+	})
+}
+
+// SendEmailTask sends the email of a task queued by QueueEmail, it's the handler of email tasks.
+func (s *MailService) SendEmailTask(ctx context.Context, payload json.RawMessage) error {
+	var email data.EmailTask
+	if err := json.Unmarshal(payload, &email); err != nil {
+		return err
+	}
+	if email.Data == nil {
+		email.Data = map[string]string{}
+	}
+
+	return s.SendEmail(ctx, email.To, email.Subject, email.Template, email.Data)
+}
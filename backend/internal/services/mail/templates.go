@@ -0,0 +1,57 @@
+package mail
+
+import (
+	"errors"
+
+	"NodeTurtleAPI/internal/data"
+)
+
+// ErrTemplateNotFound is returned when previewing a template that doesn't exist.
+var ErrTemplateNotFound = errors.New("email template not found")
+
+// templates are the email templates by name, every file in the templates directory needs an entry to be loaded.
+// The samples hold every field the templates use, "url" and "unsubscribe_url" are relative to the client URL as when sending.
+var templates = map[string]data.EmailTemplate{
+	"activation": {Subject: "Activate Your Account", Sample: map[string]string{
+		"Username": "alice", "url": "/activate/sample-token", "ExpiresAt": "January 2, 2026 at 15:04 UTC",
+	}},
+	"reset": {Subject: "Reset Your Password", Sample: map[string]string{
+		"Username": "alice", "url": "/reset/sample-token", "ExpiresAt": "January 2, 2026 at 15:04 UTC",
+	}},
+	"deactivation": {Subject: "Account deactivation", Sample: map[string]string{
+		"Username": "alice", "url": "/deactivate/sample-token", "ExpiresAt": "January 2, 2026 at 15:04 UTC",
+	}},
+	"deletion": {Subject: "Account deletion scheduled", Sample: map[string]string{
+		"Username": "alice", "url": "/login", "DeletesAt": "February 1, 2026 at 15:04 UTC",
+	}},
+	"ban": {Subject: "Account Suspended - Turtle Graphics", Category: data.EmailSecurity, Sample: map[string]string{
+		"Username": "alice", "Reason": "Spam in project titles",
+		"BannedAt": "January 2, 2026 at 3:04 PM UTC", "ExpiresAt": "January 9, 2026 at 3:04 PM UTC",
+		"unsubscribe_url": "/api/email/unsubscribe/sample-token",
+	}},
+	"ban_appeal": {Subject: "Ban appeal reviewed - Turtle Graphics", Sample: map[string]string{
+		"Username": "alice", "Decision": "Accepted", "ExpiresAt": "Lifted", "Resolution": "The spam came from a shared computer.",
+		"Outcome": "Your suspension has been lifted and you can log in again.",
+	}},
+	"login_code": {Subject: "Confirm your login", Sample: map[string]string{
+		"Username": "alice", "Code": "123456", "Country": "DE", "ExpiresAt": "January 2, 2026 at 15:04 UTC",
+	}},
+	"magic_link": {Subject: "Your Login Link", Sample: map[string]string{
+		"Username": "alice", "url": "/magic/sample-token", "ExpiresAt": "January 2, 2026 at 15:04 UTC",
+	}},
+	"takedown": {Subject: "Project Taken Down - Turtle Graphics", Category: data.EmailSecurity, Sample: map[string]string{
+		"Username": "alice", "Title": "Spiral Galaxy", "Reason": "Copyrighted artwork",
+		"unsubscribe_url": "/api/email/unsubscribe/sample-token",
+	}},
+	"digest": {Subject: "Your Weekly Digest - Turtle Graphics", Category: data.EmailDigests, Sample: map[string]string{
+		"Username": "alice", "Period": "Weekly", "Likes": "12", "Forks": "3", "Projects": "2", "TopProject": "Spiral Galaxy", "url": "/projects",
+		"unsubscribe_url": "/api/email/unsubscribe/sample-token",
+	}},
+	"export": {Subject: "Your Data Export Is Ready - Turtle Graphics", Sample: map[string]string{
+		"Username": "alice", "url": "/api/exports/sample-token", "ExpiresAt": "January 9, 2026 at 15:04 UTC",
+	}},
+	"anomaly_alert": {Subject: "Anomaly detected - Turtle Graphics", Sample: map[string]string{
+		"Kind": "server_errors", "Subject": "/api/projects/:id", "Observed": "73", "Threshold": "50",
+		"Window": "January 2, 2026 at 15:00 to 15:05 UTC",
+	}},
+}
@@ -0,0 +1,355 @@
+// Package notifications tells creators about the activity on their projects, in the app and by email digests.
+package notifications
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/mail"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// INotificationService defines the interface for notification operations.
+type INotificationService interface {
+	NotifyLike(ctx context.Context, projectID, likerID uuid.UUID) (*data.Notification, error)
+	GetNotifications(ctx context.Context, userID uuid.UUID, limit int) ([]data.Notification, int, error)
+	MarkRead(ctx context.Context, userID uuid.UUID, ids []int64) (int64, error)
+	GetPreferences(ctx context.Context, userID uuid.UUID) (*data.NotificationPreferences, error)
+	UpdatePreferences(ctx context.Context, userID uuid.UUID, update data.NotificationPreferencesUpdate) (*data.NotificationPreferences, error)
+	SendDigests(ctx context.Context) (int, error)
+}
+
+// NotificationService implements the INotificationService interface.
+type NotificationService struct {
+	db          *sql.DB
+	mailService mail.IMailService
+}
+
+// NewNotificationService creates a new NotificationService sending digests through the mail service.
+func NewNotificationService(db *sql.DB, mailService mail.IMailService) NotificationService {
+	return NotificationService{
+		db:          db,
+		mailService: mailService,
+	}
+}
+
+// NotifyLike enqueues an in-app notification to the creator of a project liked by a user.
+// It returns nil without a notification when the creator turned like notifications off, liked their own project,
+// or has an unread notification of the same like already, e.g. after the user unliked and liked the project again.
+// It returns ErrRecordNotFound if the project or the user doesn't exist.
+func (s NotificationService) NotifyLike(ctx context.Context, projectID, likerID uuid.UUID) (*data.Notification, error) {
+	var liked data.ProjectLiked
+	var creatorID uuid.UUID
+	var enabled bool
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT p.creator_id, p.title, u.username, COALESCE(np.likes, TRUE)
+		FROM projects p
+		JOIN users u ON u.id = $2
+		LEFT JOIN notification_preferences np ON np.user_id = p.creator_id
+		WHERE p.id = $1`,
+		projectID, likerID,
+	).Scan(&creatorID, &liked.ProjectTitle, &liked.Username, &enabled)
+	if err == sql.ErrNoRows {
+		return nil, services.ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !enabled || creatorID == likerID {
+		return nil, nil
+	}
+
+	liked.ProjectID = projectID
+	liked.UserID = likerID
+	payload, err := json.Marshal(liked)
+	if err != nil {
+		return nil, err
+	}
+
+	n := data.Notification{UserID: creatorID, Type: data.NotificationProjectLiked, Payload: payload}
+	err = s.db.QueryRowContext(ctx, `
+		INSERT INTO notifications (user_id, type, payload)
+		SELECT $1, $2, $3
+		WHERE NOT EXISTS (
+			SELECT 1 FROM notifications
+			WHERE user_id = $1 AND type = $2 AND read_at IS NULL
+			AND payload->>'project_id' = $4 AND payload->>'user_id' = $5
+		)
+		RETURNING id, created_at`,
+		creatorID, n.Type, payload, projectID.String(), likerID.String(),
+	).Scan(&n.ID, &n.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &n, nil
+}
+
+// GetNotifications retrieves the latest notifications of a user, newest first, and how many of all their notifications are unread.
+func (s NotificationService) GetNotifications(ctx context.Context, userID uuid.UUID, limit int) ([]data.Notification, int, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, type, payload, read_at, created_at
+		FROM notifications
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2`,
+		userID, limit,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	notifications := make([]data.Notification, 0)
+	for rows.Next() {
+		n := data.Notification{UserID: userID}
+		var payload []byte
+		if err := rows.Scan(&n.ID, &n.Type, &payload, &n.ReadAt, &n.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		n.Payload = payload
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var unread int
+	err = s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL", userID).Scan(&unread)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return notifications, unread, nil
+}
+
+// MarkRead marks the given notifications of a user as read, or all of them when no IDs are given.
+// IDs of notifications of other users are ignored. It returns the number of notifications marked.
+func (s NotificationService) MarkRead(ctx context.Context, userID uuid.UUID, ids []int64) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE notifications SET read_at = NOW()
+		WHERE user_id = $1 AND read_at IS NULL
+		AND (cardinality($2::bigint[]) = 0 OR id = ANY($2))`,
+		userID, pq.Array(ids),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// GetPreferences retrieves the notification preferences of a user, the defaults if they never changed them.
+func (s NotificationService) GetPreferences(ctx context.Context, userID uuid.UUID) (*data.NotificationPreferences, error) {
+	prefs := data.DefaultNotificationPreferences
+	var updatedAt time.Time
+
+	err := s.db.QueryRowContext(ctx,
+		"SELECT likes, digest, updated_at FROM notification_preferences WHERE user_id = $1", userID,
+	).Scan(&prefs.Likes, &prefs.Digest, &updatedAt)
+	if err == sql.ErrNoRows {
+		return &prefs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	prefs.UpdatedAt = &updatedAt
+	return &prefs, nil
+}
+
+// UpdatePreferences changes the notification preferences of a user.
+// Switching the digest on starts it from now, so the first digest doesn't summarize activity from before.
+// Whether the user consents to receiving the digest is up to the caller.
+func (s NotificationService) UpdatePreferences(ctx context.Context, userID uuid.UUID, update data.NotificationPreferencesUpdate) (*data.NotificationPreferences, error) {
+	var digest interface{}
+	if update.Digest != nil {
+		digest = string(*update.Digest)
+	}
+
+	var prefs data.NotificationPreferences
+	var updatedAt time.Time
+
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO notification_preferences (user_id, likes, digest)
+		VALUES ($1, COALESCE($2::boolean, TRUE), COALESCE($3::text, 'off'))
+		ON CONFLICT (user_id) DO UPDATE SET
+			likes = COALESCE($2::boolean, notification_preferences.likes),
+			digest = COALESCE($3::text, notification_preferences.digest),
+			digest_sent_at = CASE
+				WHEN notification_preferences.digest = 'off' AND COALESCE($3::text, 'off') <> 'off' THEN NOW()
+				ELSE notification_preferences.digest_sent_at
+			END,
+			updated_at = NOW()
+		RETURNING likes, digest, updated_at`,
+		userID, update.Likes, digest,
+	).Scan(&prefs.Likes, &prefs.Digest, &updatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return nil, services.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	prefs.UpdatedAt = &updatedAt
+	return &prefs, nil
+}
+
+// dueDigest is a digest to send, of the activity between since and until.
+type dueDigest struct {
+	userID    uuid.UUID
+	email     string
+	username  string
+	frequency data.DigestFrequency
+	since     time.Time
+	until     time.Time
+}
+
+// SendDigests emails the daily and weekly digests that are due, summarizing the new likes and forks of the projects
+// of each user since their last digest. Projects have no comments yet, so digests don't cover them.
+//
+// Digests go only to activated users who consent to marketing emails, which is opt-in, and didn't unsubscribe
+// from digests. Users without any activity get no email, their next digest starts from now all the same. A digest is claimed before it's sent, so concurrent
+// runs don't send it twice, and a failed email is not retried. It returns the number of digests sent.
+func (s NotificationService) SendDigests(ctx context.Context) (int, error) {
+	due, err := s.dueDigests(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, d := range due {
+		if ctx.Err() != nil {
+			return sent, ctx.Err()
+		}
+
+		res, err := s.db.ExecContext(ctx,
+			"UPDATE notification_preferences SET digest_sent_at = $3 WHERE user_id = $1 AND digest_sent_at = $2",
+			d.userID, d.since, d.until,
+		)
+		if err != nil {
+			return sent, err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return sent, err
+		} else if n == 0 {
+			// claimed by a concurrent run, or the digest was switched off and on again
+			continue
+		}
+
+		activity, err := s.projectActivity(ctx, d.userID, d.since, d.until)
+		if err != nil {
+			return sent, err
+		}
+		if len(activity) == 0 {
+			continue
+		}
+
+		if err := s.mailService.SendEmail(ctx, d.email, digestSubject(d.frequency), "digest", digestData(d, activity)); err != nil {
+			// the failure is in the email log
+			continue
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+func (s NotificationService) dueDigests(ctx context.Context) ([]dueDigest, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT np.user_id, u.email, u.username, np.digest, np.digest_sent_at, NOW()
+		FROM notification_preferences np
+		JOIN users u ON u.id = np.user_id
+		JOIN user_consents c ON c.user_id = np.user_id AND c.purpose = $1 AND c.granted
+		LEFT JOIN email_preferences ep ON ep.user_id = np.user_id
+		WHERE np.digest <> 'off' AND u.activated AND COALESCE(ep.digests, TRUE)
+		AND np.digest_sent_at <= NOW() - CASE np.digest WHEN 'daily' THEN INTERVAL '1 day' ELSE INTERVAL '7 days' END
+		ORDER BY np.digest_sent_at`,
+		data.ConsentMarketingEmails,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	due := make([]dueDigest, 0)
+	for rows.Next() {
+		var d dueDigest
+		if err := rows.Scan(&d.userID, &d.email, &d.username, &d.frequency, &d.since, &d.until); err != nil {
+			return nil, err
+		}
+		due = append(due, d)
+	}
+
+	return due, rows.Err()
+}
+
+// projectActivity counts the likes and forks of each project of a user between since and until,
+// leaving out projects without any, most active first.
+func (s NotificationService) projectActivity(ctx context.Context, userID uuid.UUID, since, until time.Time) ([]data.ProjectActivity, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, title, likes, forks FROM (
+			SELECT p.id, p.title,
+				(SELECT COUNT(*) FROM project_likes l WHERE l.project_id = p.id AND l.created_at > $2 AND l.created_at <= $3) AS likes,
+				(SELECT COUNT(*) FROM project_fork_events f WHERE f.source_id = p.id AND f.created_at > $2 AND f.created_at <= $3) AS forks
+			FROM projects p
+			WHERE p.creator_id = $1
+		) a
+		WHERE likes > 0 OR forks > 0
+		ORDER BY likes + forks DESC, title`,
+		userID, since, until,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	activity := make([]data.ProjectActivity, 0)
+	for rows.Next() {
+		var a data.ProjectActivity
+		if err := rows.Scan(&a.ProjectID, &a.Title, &a.Likes, &a.Forks); err != nil {
+			return nil, err
+		}
+		activity = append(activity, a)
+	}
+
+	return activity, rows.Err()
+}
+
+func digestSubject(frequency data.DigestFrequency) string {
+	if frequency == data.DigestDaily {
+		return "Your Daily Digest - Turtle Graphics"
+	}
+	return "Your Weekly Digest - Turtle Graphics"
+}
+
+func digestData(d dueDigest, activity []data.ProjectActivity) map[string]string {
+	likes, forks := 0, 0
+	for _, a := range activity {
+		likes += a.Likes
+		forks += a.Forks
+	}
+
+	frequency := string(d.frequency)
+	return map[string]string{
+		"Username":   d.username,
+		"Period":     strings.ToUpper(frequency[:1]) + frequency[1:],
+		"Likes":      strconv.Itoa(likes),
+		"Forks":      strconv.Itoa(forks),
+		"Projects":   strconv.Itoa(len(activity)),
+		"TopProject": activity[0].Title,
+		"url":        "/projects",
+	}
+}
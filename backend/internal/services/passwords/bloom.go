@@ -0,0 +1,113 @@
+package passwords
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// bloomMagic starts every bloom filter file.
+var bloomMagic = [4]byte{'N', 'T', 'B', 'F'}
+
+// BloomFilter is a probabilistic set of SHA-1 password hashes, as published by Have I Been Pwned.
+// It has no false negatives, and a false positive rate chosen when it is built.
+//
+// The file format is the magic "NTBF", the number of hash functions as uint32, the number of bits
+// as uint64, both big endian, followed by the bits.
+type BloomFilter struct {
+	k    uint32
+	m    uint64
+	bits []byte
+}
+
+// NewBloomFilter creates an empty filter sized for n hashes at the false positive rate p.
+func NewBloomFilter(n uint64, p float64) *BloomFilter {
+	if n == 0 {
+		n = 1
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	k := uint32(math.Max(1, math.Round(float64(m)/float64(n)*math.Ln2)))
+
+	return &BloomFilter{k: k, m: m, bits: make([]byte, (m+7)/8)}
+}
+
+// LoadBloomFilter reads a filter written by WriteTo.
+func LoadBloomFilter(path string) (*BloomFilter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+
+	var header struct {
+		Magic [4]byte
+		K     uint32
+		M     uint64
+	}
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+		return nil, fmt.Errorf("could not read bloom filter header: %w", err)
+	}
+	if header.Magic != bloomMagic || header.K == 0 || header.M == 0 {
+		return nil, errors.New("not a bloom filter file")
+	}
+
+	b := &BloomFilter{k: header.K, m: header.M, bits: make([]byte, (header.M+7)/8)}
+	if _, err := io.ReadFull(r, b.bits); err != nil {
+		return nil, fmt.Errorf("could not read bloom filter: %w", err)
+	}
+
+	return b, nil
+}
+
+// WriteTo writes the filter to w.
+func (b *BloomFilter) WriteTo(w io.Writer) (int64, error) {
+	header := struct {
+		Magic [4]byte
+		K     uint32
+		M     uint64
+	}{bloomMagic, b.k, b.m}
+
+	if err := binary.Write(w, binary.BigEndian, header); err != nil {
+		return 0, err
+	}
+	n, err := w.Write(b.bits)
+	return int64(n) + 16, err
+}
+
+// AddHash adds a SHA-1 hash to the filter.
+func (b *BloomFilter) AddHash(hash [sha1.Size]byte) {
+	h1, h2 := b.split(hash)
+	for i := uint64(0); i < uint64(b.k); i++ {
+		pos := (h1 + i*h2) % b.m
+		b.bits[pos/8] |= 1 << (pos % 8)
+	}
+}
+
+// ContainsHash reports whether the SHA-1 hash is probably in the filter.
+func (b *BloomFilter) ContainsHash(hash [sha1.Size]byte) bool {
+	h1, h2 := b.split(hash)
+	for i := uint64(0); i < uint64(b.k); i++ {
+		pos := (h1 + i*h2) % b.m
+		if b.bits[pos/8]&(1<<(pos%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Contains reports whether the password is probably in the filter.
+func (b *BloomFilter) Contains(password string) bool {
+	return b.ContainsHash(sha1.Sum([]byte(password)))
+}
+
+// split derives the two hashes of double hashing from the already uniformly distributed SHA-1 hash.
+func (b *BloomFilter) split(hash [sha1.Size]byte) (uint64, uint64) {
+	return binary.BigEndian.Uint64(hash[0:8]), binary.BigEndian.Uint64(hash[8:16]) | 1
+}
@@ -0,0 +1,29 @@
+package passwords
+
+// commonRanks ranks the most common passwords and words used in them, 1 being the most common.
+// The rank approximates the number of guesses an attacker needs to try the word.
+var commonRanks = rankWords(
+	"password", "123456", "123456789", "12345678", "12345", "qwerty", "abc123", "password1", "111111", "123123",
+	"1234567", "iloveyou", "1234567890", "000000", "admin", "letmein", "welcome", "monkey", "dragon",
+	"football", "baseball", "sunshine", "princess", "master", "shadow", "qwerty123", "trustno1", "superman",
+	"batman", "michael", "jennifer", "hunter", "hello", "freedom", "whatever", "charlie", "donald", "login",
+	"starwars", "passw0rd", "computer", "solo", "secret", "ashley", "bailey", "access", "flower", "hottie",
+	"loveme", "zaq1zaq1", "mustang", "jordan", "harley", "ranger", "buster", "thomas", "tigger", "robert",
+	"soccer", "hockey", "killer", "george", "andrew", "michelle", "pepper", "daniel", "jessica", "nicole",
+	"summer", "winter", "spring", "autumn", "orange", "purple", "yellow", "banana", "cookie", "cheese",
+	"chocolate", "butterfly", "internet", "samsung", "google", "apple", "pokemon", "minecraft", "fortnite",
+	"roblox", "naruto", "matrix", "ninja", "pass", "test", "guest", "user", "root", "changeme", "default",
+	"love", "family", "friend", "friends", "forever", "angel", "baby", "happy", "lucky", "maggie", "ginger",
+	"hannah", "amanda", "joshua", "matthew", "anthony", "william", "taylor", "dallas", "chelsea", "arsenal",
+	"liverpool", "america", "canada", "london", "paris", "school", "student", "teacher", "turtle", "turtles",
+	"nodeturtle", "turtlegraphics", "graphics", "qazwsx", "asdfgh", "zxcvbnm", "654321", "987654321", "666666",
+	"121212", "696969", "7777777", "112233", "abcdef", "abcd1234", "qwertyuiop",
+)
+
+func rankWords(words ...string) map[string]int {
+	ranks := make(map[string]int, len(words))
+	for i, w := range words {
+		ranks[w] = i + 1
+	}
+	return ranks
+}
@@ -0,0 +1,140 @@
+// Package passwords evaluates passwords against the password policy.
+package passwords
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+)
+
+// maxLength is the longest password in bytes, bcrypt ignores everything after it.
+const maxLength = 72
+
+// patternFeedback explains the patterns making a password easy to guess.
+var patternFeedback = map[string]string{
+	patternDictionary: "Avoid common passwords and words",
+	patternUserInput:  "Avoid your username or email address",
+	patternSequence:   "Avoid sequences like abc or 6543",
+	patternRepeat:     "Avoid repeated characters and words",
+	patternKeyboard:   "Avoid keyboard patterns like qwerty",
+	patternYear:       "Avoid years that are associated with you",
+}
+
+// IPasswordService defines the interface for password policy checks.
+type IPasswordService interface {
+	Evaluate(password string, userInputs []string) data.PasswordStrength
+}
+
+// PasswordService implements the IPasswordService interface.
+type PasswordService struct {
+	cfg      config.PasswordsConfig
+	breached *BloomFilter
+}
+
+// NewPasswordService creates a new PasswordService with the provided policy.
+// It returns an error if the breached password filter can't be loaded.
+func NewPasswordService(cfg config.PasswordsConfig) (PasswordService, error) {
+	s := PasswordService{cfg: cfg}
+
+	if cfg.BreachedFilter != "" {
+		filter, err := LoadBloomFilter(cfg.BreachedFilter)
+		if err != nil {
+			return PasswordService{}, fmt.Errorf("could not load breached password filter: %w", err)
+		}
+		s.breached = filter
+	}
+
+	return s, nil
+}
+
+// Evaluate scores the password and lists every reason it doesn't satisfy the policy.
+// userInputs are details of the account, like the username and email, which make a password easy to guess.
+func (s PasswordService) Evaluate(password string, userInputs []string) data.PasswordStrength {
+	issues := make([]data.PasswordIssue, 0)
+
+	if n := len([]rune(password)); n < s.cfg.MinLength {
+		issues = append(issues, data.PasswordIssue{
+			Code:    data.PasswordTooShort,
+			Message: fmt.Sprintf("Password must be at least %d characters long", s.cfg.MinLength),
+		})
+	}
+	if len(password) > maxLength {
+		issues = append(issues, data.PasswordIssue{
+			Code:    data.PasswordTooLong,
+			Message: fmt.Sprintf("Password must be at most %d bytes long", maxLength),
+		})
+	}
+
+	inputs := userInputWords(userInputs)
+	// only the part bcrypt uses is guessed, which also bounds the work for very long passwords
+	log10Guesses, path := estimate(truncate(password, maxLength), inputs)
+	strength := data.PasswordStrength{Score: score(log10Guesses)}
+
+	patterns := map[string]bool{}
+	for _, m := range path {
+		patterns[m.pattern] = true
+	}
+
+	if patterns[patternUserInput] {
+		issues = append(issues, data.PasswordIssue{
+			Code:    data.PasswordContainsUser,
+			Message: "Password must not contain your username or email address",
+		})
+	}
+
+	if s.breached != nil && s.breached.Contains(password) {
+		issues = append(issues, data.PasswordIssue{
+			Code:    data.PasswordBreached,
+			Message: "This password appeared in a data breach, please choose a different one",
+		})
+	}
+
+	if strength.Score < s.cfg.MinScore {
+		issues = append(issues, data.PasswordIssue{
+			Code:    data.PasswordTooWeak,
+			Message: "Password is too easy to guess, add more words or characters",
+		})
+		for _, p := range []string{patternDictionary, patternSequence, patternRepeat, patternKeyboard, patternYear} {
+			if patterns[p] {
+				issues = append(issues, data.PasswordIssue{Code: data.PasswordCommonPattern, Message: patternFeedback[p]})
+			}
+		}
+	}
+
+	strength.Issues = issues
+	return strength
+}
+
+// userInputWords splits account details into the lowercase words a password is checked for,
+// e.g. the parts of an email address.
+func userInputWords(userInputs []string) map[string]bool {
+	words := map[string]bool{}
+	for _, input := range userInputs {
+		input = strings.ToLower(input)
+		local, _, _ := strings.Cut(input, "@")
+		candidates := append([]string{input, local}, strings.FieldsFunc(local, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})...)
+		for _, w := range candidates {
+			if len([]rune(w)) >= 3 {
+				words[w] = true
+			}
+		}
+	}
+	return words
+}
+
+// truncate shortens s to at most n bytes without splitting a character.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
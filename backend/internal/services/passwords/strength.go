@@ -0,0 +1,326 @@
+package passwords
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Patterns a part of a password can follow.
+const (
+	patternDictionary = "dictionary"
+	patternUserInput  = "user_input"
+	patternSequence   = "sequence"
+	patternRepeat     = "repeat"
+	patternKeyboard   = "keyboard"
+	patternYear       = "year"
+)
+
+// bruteforceCardinality is the number of guesses per character not covered by a pattern.
+// Attackers try likely characters first, so it is well below the size of the alphabet.
+const bruteforceCardinality = 10
+
+// keyboardRows are adjacent keys people walk on their keyboard.
+var keyboardRows = []string{"qwertyuiop", "asdfghjkl", "zxcvbnm", "1234567890", "!@#$%^&*()", "qwertzuiop", "azertyuiop"}
+
+// leetSubstitutions maps characters commonly swapped in for letters back to the letter.
+var leetSubstitutions = map[rune]rune{'@': 'a', '4': 'a', '8': 'b', '(': 'c', '3': 'e', '6': 'g', '1': 'i', '!': 'i', '|': 'l', '0': 'o', '$': 's', '5': 's', '7': 't', '+': 't', '2': 'z'}
+
+// match is a part of the password following a guessable pattern, with the number of guesses needed to find it.
+type match struct {
+	start, end int // rune positions, end is exclusive
+	pattern    string
+	guesses    float64
+}
+
+// estimate returns the log10 of the number of guesses needed to find the password, in the spirit of zxcvbn,
+// and the patterns found on the cheapest way to guess it.
+// The password is covered with the combination of matched patterns and brute forced characters that needs the fewest guesses.
+func estimate(password string, userInputs map[string]bool) (float64, []match) {
+	runes := []rune(password)
+	n := len(runes)
+	if n == 0 {
+		return 0, nil
+	}
+
+	matches := findMatches(runes, userInputs)
+
+	// best[k] is the fewest log10 guesses covering the first k runes, via the match ending there
+	best := make([]float64, n+1)
+	via := make([]*match, n+1)
+	for k := 1; k <= n; k++ {
+		best[k] = best[k-1] + math.Log10(bruteforceCardinality)
+		for i := range matches {
+			m := &matches[i]
+			if m.end != k {
+				continue
+			}
+			minGuesses := 10.0
+			if m.end-m.start > 1 {
+				minGuesses = 50
+			}
+			if cost := best[m.start] + math.Log10(math.Max(m.guesses, minGuesses)); cost < best[k] {
+				best[k] = cost
+				via[k] = m
+			}
+		}
+	}
+
+	path := make([]match, 0)
+	for k := n; k > 0; {
+		if m := via[k]; m != nil {
+			path = append(path, *m)
+			k = m.start
+		} else {
+			k--
+		}
+	}
+
+	return best[n], path
+}
+
+// score maps the log10 of guesses to the 0-4 scale used by zxcvbn.
+func score(log10Guesses float64) int {
+	const delta = 0.0000001
+	switch {
+	case log10Guesses < 3+delta:
+		return 0
+	case log10Guesses < 6+delta:
+		return 1
+	case log10Guesses < 8+delta:
+		return 2
+	case log10Guesses < 10+delta:
+		return 3
+	default:
+		return 4
+	}
+}
+
+func findMatches(runes []rune, userInputs map[string]bool) []match {
+	lower := []rune(strings.ToLower(string(runes)))
+	matches := make([]match, 0)
+	matches = append(matches, dictionaryMatches(runes, lower, userInputs)...)
+	matches = append(matches, sequenceMatches(lower)...)
+	matches = append(matches, repeatMatches(lower)...)
+	matches = append(matches, keyboardMatches(lower)...)
+	matches = append(matches, yearMatches(lower)...)
+	return matches
+}
+
+// dictionaryMatches finds common passwords and the user's own details, also when written in l33t speak.
+func dictionaryMatches(runes, lower []rune, userInputs map[string]bool) []match {
+	matches := make([]match, 0)
+	for i := 0; i < len(lower); i++ {
+		for j := i + 3; j <= len(lower); j++ {
+			word := string(lower[i:j])
+			unleet := unleetString(word)
+
+			variations := uppercaseVariations(runes[i:j])
+			if unleet != word {
+				variations *= 2
+			}
+
+			if userInputs[word] || userInputs[unleet] {
+				matches = append(matches, match{start: i, end: j, pattern: patternUserInput, guesses: variations})
+			}
+			if rank, ok := commonRanks[word]; ok {
+				matches = append(matches, match{start: i, end: j, pattern: patternDictionary, guesses: float64(rank) * variations})
+			} else if rank, ok := commonRanks[unleet]; ok {
+				matches = append(matches, match{start: i, end: j, pattern: patternDictionary, guesses: float64(rank) * variations})
+			}
+		}
+	}
+	return matches
+}
+
+func unleetString(s string) string {
+	return strings.Map(func(r rune) rune {
+		if l, ok := leetSubstitutions[r]; ok {
+			return l
+		}
+		return r
+	}, s)
+}
+
+// uppercaseVariations is the number of guesses to find the capitalization of a word.
+// All lowercase, all uppercase and a capitalized first letter are tried first.
+func uppercaseVariations(word []rune) float64 {
+	upper := 0
+	for _, r := range word {
+		if unicode.IsUpper(r) {
+			upper++
+		}
+	}
+	switch {
+	case upper == 0:
+		return 1
+	case upper == len(word) || (upper == 1 && unicode.IsUpper(word[0])):
+		return 2
+	default:
+		return math.Pow(2, float64(min(upper, len(word)-upper)))
+	}
+}
+
+// sequenceMatches finds runs like abc, 9876 or ace of at least three characters.
+func sequenceMatches(lower []rune) []match {
+	matches := make([]match, 0)
+	for i := 0; i+2 < len(lower); {
+		delta := lower[i+1] - lower[i]
+		if delta == 0 || delta > 2 || delta < -2 || !sameClass(lower[i], lower[i+1]) {
+			i++
+			continue
+		}
+
+		j := i + 2
+		for j < len(lower) && lower[j]-lower[j-1] == delta && sameClass(lower[j-1], lower[j]) {
+			j++
+		}
+		if j-i < 3 {
+			i++
+			continue
+		}
+
+		base := 26.0
+		switch {
+		case lower[i] == 'a' || lower[i] == 'z' || lower[i] == '0' || lower[i] == '1' || lower[i] == '9':
+			base = 4
+		case unicode.IsDigit(lower[i]):
+			base = 10
+		}
+		if delta < 0 {
+			base *= 2
+		}
+		matches = append(matches, match{start: i, end: j, pattern: patternSequence, guesses: base * float64(j-i)})
+		i = j - 1
+	}
+	return matches
+}
+
+func sameClass(a, b rune) bool {
+	return (unicode.IsDigit(a) && unicode.IsDigit(b)) || (unicode.IsLetter(a) && unicode.IsLetter(b))
+}
+
+// repeatMatches finds repeated characters like aaa and repeated chunks like abcabc.
+func repeatMatches(lower []rune) []match {
+	matches := make([]match, 0)
+	n := len(lower)
+	for i := 0; i < n; i++ {
+		for size := 1; i+2*size <= n; size++ {
+			chunk := string(lower[i : i+size])
+			// aaaa is matched as a repeat of a, not of aa, and only from where the repetition starts
+			if (size > 1 && periodic(lower[i:i+size])) || (i >= size && string(lower[i-size:i]) == chunk) {
+				continue
+			}
+			count := 1
+			for i+(count+1)*size <= n && string(lower[i+count*size:i+(count+1)*size]) == chunk {
+				count++
+			}
+			if count < 2 || (size == 1 && count < 3) {
+				continue
+			}
+
+			chunkGuesses := float64(charsetSize(lower[i : i+size]))
+			if size > 1 {
+				log10, _ := estimate(chunk, nil)
+				chunkGuesses = math.Pow(10, log10)
+			}
+			matches = append(matches, match{start: i, end: i + count*size, pattern: patternRepeat, guesses: chunkGuesses * float64(count)})
+		}
+	}
+	return matches
+}
+
+// periodic reports whether the runes are a repetition of a shorter chunk.
+func periodic(runes []rune) bool {
+	n := len(runes)
+	for size := 1; size <= n/2; size++ {
+		if n%size != 0 {
+			continue
+		}
+		repeated := true
+		for i := size; i < n && repeated; i++ {
+			repeated = runes[i] == runes[i-size]
+		}
+		if repeated {
+			return true
+		}
+	}
+	return false
+}
+
+// charsetSize is the size of the smallest common alphabet containing the characters.
+func charsetSize(runes []rune) int {
+	size := 0
+	var lower, upper, digit, other bool
+	for _, r := range runes {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+	if lower {
+		size += 26
+	}
+	if upper {
+		size += 26
+	}
+	if digit {
+		size += 10
+	}
+	if other {
+		size += 33
+	}
+	return size
+}
+
+// keyboardMatches finds walks of at least four adjacent keys on a keyboard row, in either direction.
+func keyboardMatches(lower []rune) []match {
+	matches := make([]match, 0)
+	for i := 0; i < len(lower); i++ {
+		for j := i + 4; j <= len(lower); j++ {
+			walk := string(lower[i:j])
+			for _, row := range keyboardRows {
+				if strings.Contains(row, walk) || strings.Contains(row, reverse(walk)) {
+					matches = append(matches, match{start: i, end: j, pattern: patternKeyboard, guesses: 40 * float64(j-i)})
+					break
+				}
+			}
+		}
+	}
+	return matches
+}
+
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+// yearMatches finds recent years, commonly birth years or the current year.
+func yearMatches(lower []rune) []match {
+	matches := make([]match, 0)
+	for i := 0; i+4 <= len(lower); i++ {
+		s := string(lower[i : i+4])
+		if (strings.HasPrefix(s, "19") || strings.HasPrefix(s, "20")) && isDigits(s) {
+			matches = append(matches, match{start: i, end: i + 4, pattern: patternYear, guesses: 120})
+		}
+	}
+	return matches
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
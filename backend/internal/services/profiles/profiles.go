@@ -0,0 +1,137 @@
+// Package profiles provides the public profiles of users.
+package profiles
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// IProfileService defines the interface for public profile operations.
+type IProfileService interface {
+	GetProfile(ctx context.Context, username string) (*data.UserProfile, error)
+	UpdateProfile(ctx context.Context, userID uuid.UUID, update data.UserProfileUpdate) (*data.UserProfile, error)
+}
+
+// ProfileService implements the IProfileService interface.
+type ProfileService struct {
+	db *sql.DB
+}
+
+// NewProfileService creates a new ProfileService with the provided database connection.
+func NewProfileService(db *sql.DB) ProfileService {
+	return ProfileService{db: db}
+}
+
+// profileQuery selects the profile of the users matching the condition appended to it.
+// Only public projects that weren't taken down count, so the profile tells nothing the user's projects don't.
+const profileQuery = `
+	SELECT u.username, COALESCE(up.bio, ''), up.website, COALESCE(up.social_links, '{}'), u.avatar_key, u.verified, u.created_at,
+	       COUNT(p.id), COALESCE(SUM(p.likes_count), 0)
+	FROM users u
+	LEFT JOIN user_profiles up ON up.user_id = u.id
+	LEFT JOIN projects p ON p.creator_id = u.id AND p.is_public = TRUE AND p.hidden_at IS NULL
+	WHERE u.activated = TRUE AND u.guest_expires_at IS NULL AND `
+
+// GetProfile retrieves the public profile of a user by their username, or by a username they gave up
+// when nobody has it now. The profile then holds the current username.
+// It returns ErrUserNotFound if no such user exists, or they have no profile: guests and users who never activated.
+func (s ProfileService) GetProfile(ctx context.Context, username string) (*data.UserProfile, error) {
+	return s.profile(ctx, s.db, `u.id = COALESCE(
+		(SELECT id FROM users WHERE username = $1),
+		(SELECT user_id FROM username_history WHERE username = $1 ORDER BY changed_at DESC LIMIT 1))`, username)
+}
+
+// UpdateProfile replaces what the user tells about themselves on their profile and returns the updated profile.
+// It returns ErrUserNotFound if the user doesn't exist.
+func (s ProfileService) UpdateProfile(ctx context.Context, userID uuid.UUID, update data.UserProfileUpdate) (*data.UserProfile, error) {
+	var website *string
+	if update.Website != "" {
+		website = &update.Website
+	}
+
+	links := update.SocialLinks
+	if links == nil {
+		links = map[string]string{}
+	}
+	encoded, err := json.Marshal(links)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO user_profiles (user_id, bio, website, social_links)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE SET
+			bio = EXCLUDED.bio,
+			website = EXCLUDED.website,
+			social_links = EXCLUDED.social_links,
+			updated_at = NOW()`,
+		userID, update.Bio, website, encoded,
+	)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return nil, services.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	profile, err := s.profile(ctx, tx, "u.id = $1", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return profile, tx.Commit()
+}
+
+// querier is a database connection or a transaction.
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func (s ProfileService) profile(ctx context.Context, db querier, where string, arg interface{}) (*data.UserProfile, error) {
+	var profile data.UserProfile
+	var links []byte
+	var avatarKey *string
+
+	err := db.QueryRowContext(ctx, profileQuery+where+" GROUP BY u.id, up.user_id", arg).Scan(
+		&profile.Username,
+		&profile.Bio,
+		&profile.Website,
+		&links,
+		&avatarKey,
+		&profile.Verified,
+		&profile.JoinedAt,
+		&profile.PublicProjects,
+		&profile.LikesReceived,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(links, &profile.SocialLinks); err != nil {
+		return nil, err
+	}
+
+	if avatarKey != nil {
+		url := data.AvatarURL(*avatarKey, data.AvatarSizes[0])
+		profile.AvatarURL = &url
+	}
+
+	return &profile, nil
+}
@@ -0,0 +1,78 @@
+package projects
+
+import (
+	"context"
+	"database/sql"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// SetAccessibility changes the alt text of the thumbnail or the description of the output of the project.
+// Fields left out of the update keep their value.
+// It returns ErrRecordNotFound if the project doesn't exist.
+func (s ProjectService) SetAccessibility(ctx context.Context, projectID uuid.UUID, update data.ProjectAccessibilityUpdate) (*data.ProjectAccessibility, error) {
+	var accessibility data.ProjectAccessibility
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO project_accessibility (project_id, thumbnail_alt, output_description)
+		SELECT p.id, COALESCE($2::text, a.thumbnail_alt, ''), COALESCE($3::text, a.output_description, '')
+		FROM projects p
+		LEFT JOIN project_accessibility a ON a.project_id = p.id
+		WHERE p.id = $1
+		ON CONFLICT (project_id) DO UPDATE
+		SET thumbnail_alt = EXCLUDED.thumbnail_alt, output_description = EXCLUDED.output_description, updated_at = NOW()
+		RETURNING thumbnail_alt, output_description, updated_at`,
+		projectID, update.ThumbnailAlt, update.OutputDescription,
+	).Scan(&accessibility.ThumbnailAlt, &accessibility.OutputDescription, &accessibility.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &accessibility, nil
+}
+
+// attachAccessibility sets the accessibility descriptions of the projects their owners wrote some for, in one query.
+func (s ProjectService) attachAccessibility(ctx context.Context, projects []data.Project) error {
+	if len(projects) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(projects))
+	for i, p := range projects {
+		ids[i] = p.ID
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT project_id, thumbnail_alt, output_description, updated_at
+		FROM project_accessibility
+		WHERE project_id = ANY($1::uuid[])`,
+		pq.Array(ids),
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	described := make(map[uuid.UUID]*data.ProjectAccessibility)
+	for rows.Next() {
+		var projectID uuid.UUID
+		var accessibility data.ProjectAccessibility
+		if err := rows.Scan(&projectID, &accessibility.ThumbnailAlt, &accessibility.OutputDescription, &accessibility.UpdatedAt); err != nil {
+			return err
+		}
+		described[projectID] = &accessibility
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range projects {
+		projects[i].Accessibility = described[projects[i].ID]
+	}
+	return nil
+}
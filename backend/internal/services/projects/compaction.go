@@ -0,0 +1,163 @@
+package projects
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/flow"
+
+	"github.com/google/uuid"
+)
+
+// CompactProjectData re-serializes the stored flow of every project in its canonical form, see flow.Canonicalize,
+// walking the projects in primary key order one short transaction per batch.
+// Projects keep their version and edit time, as their flow means the same to the editor afterwards,
+// and a project saved while its batch is processed is skipped rather than overwritten.
+// Nothing is written in a dry run, the report lists what would change.
+func (s ProjectService) CompactProjectData(ctx context.Context, opts data.CompactionOptions) (*data.CompactionReport, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+
+	report := data.CompactionReport{
+		DryRun:    opts.DryRun,
+		Projects:  []data.CompactedProject{},
+		StartedAt: time.Now(),
+	}
+
+	after := uuid.Nil
+	for {
+		count, last, err := s.compactBatch(ctx, after, opts, &report)
+		if err != nil {
+			return nil, err
+		}
+		if count < opts.BatchSize {
+			break
+		}
+		after = last
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(opts.Pause):
+		}
+	}
+
+	report.FinishedAt = time.Now()
+	return &report, nil
+}
+
+// compactBatch compacts the projects following after and returns how many were read and the last ID.
+func (s ProjectService) compactBatch(ctx context.Context, after uuid.UUID, opts data.CompactionOptions, report *data.CompactionReport) (int, uuid.UUID, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, after, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, data FROM projects
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2`,
+		after, opts.BatchSize,
+	)
+	if err != nil {
+		return 0, after, err
+	}
+
+	type stored struct {
+		id   uuid.UUID
+		data []byte
+	}
+	var batch []stored
+	for rows.Next() {
+		var p stored
+		if err := rows.Scan(&p.id, &p.data); err != nil {
+			rows.Close()
+			return 0, after, err
+		}
+		batch = append(batch, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, after, err
+	}
+	if len(batch) == 0 {
+		return 0, after, nil
+	}
+
+	for _, p := range batch {
+		report.Scanned++
+
+		canonical, err := flow.Canonicalize(p.data)
+		if err != nil {
+			report.Skipped++
+			continue
+		}
+
+		var original bytes.Buffer
+		if len(p.data) > 0 {
+			if err := json.Compact(&original, p.data); err != nil {
+				report.Skipped++
+				continue
+			}
+		}
+		sizeBefore, sizeAfter := original.Len(), len(canonical)
+		report.BytesBefore += int64(sizeBefore)
+		report.BytesAfter += int64(sizeBefore)
+		if sameJSON(original.Bytes(), canonical) {
+			continue
+		}
+
+		if !opts.DryRun {
+			// corrupted data keeps its checksum mismatch rather than getting a fresh checksum
+			res, err := tx.ExecContext(ctx, `
+				UPDATE projects SET data = $2, data_checksum = `+fmt.Sprintf(dataChecksum, "$2")+`, data_bytes = `+fmt.Sprintf(dataBytes, "$2")+`
+				WHERE id = $1 AND data = $3::jsonb AND (data_checksum IS NULL OR data_checksum = md5(data::text))`,
+				p.id, canonical, original.Bytes(),
+			)
+			if err != nil {
+				return 0, after, err
+			}
+			if n, err := res.RowsAffected(); err != nil || n == 0 {
+				report.Skipped++
+				continue
+			}
+		}
+
+		report.Changed++
+		report.BytesAfter += int64(sizeAfter - sizeBefore)
+		if len(report.Projects) < data.CompactionSampleSize {
+			report.Projects = append(report.Projects, data.CompactedProject{ID: p.id, BytesBefore: sizeBefore, BytesAfter: sizeAfter})
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, after, err
+	}
+	return len(batch), batch[len(batch)-1].id, nil
+}
+
+// sameJSON reports whether two JSON documents hold the same values, regardless of formatting and the order of object keys,
+// the way the database compares them.
+func sameJSON(a, b []byte) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+
+	var va, vb any
+	da := json.NewDecoder(bytes.NewReader(a))
+	da.UseNumber()
+	db := json.NewDecoder(bytes.NewReader(b))
+	db.UseNumber()
+	if da.Decode(&va) != nil || db.Decode(&vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
@@ -0,0 +1,121 @@
+package projects
+
+import (
+	"context"
+	"crypto/md5"
+	"database/sql"
+	"encoding/hex"
+	"sync/atomic"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+
+	"github.com/google/uuid"
+)
+
+// dataChecksum is the checksum of the flow data bound to the given placeholder, written to data_checksum along with it.
+// The database computes it from the data as it stores it, jsonb doesn't keep the formatting it was sent with.
+const dataChecksum = "md5(%s::jsonb::text)"
+
+// integrityMetrics counts the project data read that didn't match its checksum, shared by the copies of a ProjectService.
+type integrityMetrics struct {
+	mismatches atomic.Int64
+}
+
+// IntegrityMetrics returns how often project data didn't match its checksum when read since startup.
+func (s ProjectService) IntegrityMetrics() data.IntegrityMetrics {
+	return data.IntegrityMetrics{
+		ChecksumMismatches: s.integrity.mismatches.Load(),
+	}
+}
+
+// verifyChecksum logs and counts project data that doesn't match the checksum written along with it.
+// The data is returned all the same, its owner can still recover what's left or restore a revision.
+func (s ProjectService) verifyChecksum(ctx context.Context, projectID uuid.UUID, flowData []byte, checksum sql.NullString) {
+	if !checksum.Valid {
+		return
+	}
+
+	sum := md5.Sum(flowData)
+	if hex.EncodeToString(sum[:]) == checksum.String {
+		return
+	}
+
+	s.integrity.mismatches.Add(1)
+	logging.FromContext(ctx).ErrorContext(ctx, "Project data doesn't match its checksum", "project_id", projectID, "bytes", len(flowData))
+}
+
+// ScanDataIntegrity compares the stored flow of every project with the checksum written along with it,
+// walking the projects in primary key order one batch at a time, and lists the projects that don't match.
+// Projects saved before checksums were written are counted as unchecked until they are backfilled.
+func (s ProjectService) ScanDataIntegrity(ctx context.Context, opts data.IntegrityScanOptions) (*data.IntegrityReport, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1000
+	}
+
+	report := data.IntegrityReport{
+		Corrupted: []data.CorruptedProject{},
+		StartedAt: time.Now(),
+	}
+
+	after := uuid.Nil
+	for {
+		count, last, err := s.scanBatch(ctx, after, opts.BatchSize, &report)
+		if err != nil {
+			return nil, err
+		}
+		if count < opts.BatchSize {
+			break
+		}
+		after = last
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(opts.Pause):
+		}
+	}
+
+	report.FinishedAt = time.Now()
+	return &report, nil
+}
+
+// scanBatch checks the projects following after and returns how many were read and the last ID.
+func (s ProjectService) scanBatch(ctx context.Context, after uuid.UUID, batchSize int, report *data.IntegrityReport) (int, uuid.UUID, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, title, data_checksum, md5(data::text), octet_length(data::text), last_edited_at
+		FROM projects
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2`,
+		after, batchSize,
+	)
+	if err != nil {
+		return 0, after, err
+	}
+	defer rows.Close()
+
+	count, last := 0, after
+	for rows.Next() {
+		var p data.CorruptedProject
+		var stored sql.NullString
+		if err := rows.Scan(&p.ID, &p.Title, &stored, &p.DataChecksum, &p.DataBytes, &p.LastEditedAt); err != nil {
+			return 0, after, err
+		}
+		count++
+		last = p.ID
+		report.Scanned++
+
+		switch {
+		case !stored.Valid:
+			report.Unchecked++
+		case stored.String != p.DataChecksum:
+			p.StoredChecksum = stored.String
+			report.Corrupted = append(report.Corrupted, p)
+			logging.FromContext(ctx).ErrorContext(ctx, "Project data doesn't match its checksum", "project_id", p.ID, "bytes", p.DataBytes)
+		}
+	}
+
+	return count, last, rows.Err()
+}
@@ -2,66 +2,147 @@
 package projects
 
 import (
+	"NodeTurtleAPI/internal/config"
 	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/ids"
 	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/events"
+	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // IProjectService defines the interface for project management operations.
 type IProjectService interface {
-	CreateProject(p data.ProjectCreate) (*data.Project, error)
-	GetProject(projectID uuid.UUID, requestingUserID *uuid.UUID) (*data.Project, error)
-	GetUserProjects(profileUserID, requestingUserID uuid.UUID) ([]data.Project, error)
-	GetFeaturedProjects(limit, offset int) ([]data.Project, error)
-	FeatureProject(projectID uuid.UUID, expiresAt *time.Time) (*data.Project, error)
-	GetLikedProjects(userID uuid.UUID) ([]data.Project, error)
-	LikeProject(projectID, userID uuid.UUID) error
-	UnlikeProject(projectID, userID uuid.UUID) error
-	UpdateProject(p data.ProjectUpdate) (*data.Project, error)
-	DeleteProject(projectID uuid.UUID) error
-	IsOwner(projectID, userID uuid.UUID) (bool, error)
-	GetPublicProjects(filters data.PublicProjectFilter) ([]data.Project, int, error)
-	ListProjects(filters data.ProjectFilter) ([]data.Project, int, error)
+	CreateProject(ctx context.Context, p data.ProjectCreate) (*data.Project, error)
+	CheckTitle(ctx context.Context, creatorID uuid.UUID, title string, projectID *uuid.UUID) (*data.TitleConflict, error)
+	GetProject(ctx context.Context, projectID uuid.UUID, requestingUserID *uuid.UUID) (*data.Project, error)
+	GetProjectsByIDs(ctx context.Context, projectIDs []uuid.UUID, requestingUserID *uuid.UUID) ([]data.Project, error)
+	GetEmbeddedProject(ctx context.Context, projectID uuid.UUID) (*data.Project, error)
+	GetUserProjects(ctx context.Context, profileUserID uuid.UUID, requestingUserID *uuid.UUID, folder *data.FolderFilter) ([]data.Project, error)
+	GetUserProjectSummaries(ctx context.Context, profileUserID uuid.UUID, requestingUserID *uuid.UUID, folder *data.FolderFilter) ([]data.Project, error)
+	GetContributedProjects(ctx context.Context, profileUserID uuid.UUID, requestingUserID *uuid.UUID) ([]data.Project, error)
+	SaveProjectData(ctx context.Context, projectID uuid.UUID, flowData json.RawMessage, version int, userID uuid.UUID, session string) (int, error)
+	GetLastSave(ctx context.Context, projectID uuid.UUID) (*data.ProjectSave, error)
+	ListRevisions(ctx context.Context, projectID uuid.UUID) ([]data.ProjectRevision, error)
+	GetRevision(ctx context.Context, projectID uuid.UUID, revisionID int64) (*data.ProjectRevision, error)
+	ExportProject(ctx context.Context, projectID uuid.UUID) (*data.ProjectImport, error)
+	GetFeaturedProjects(ctx context.Context, limit, offset int) ([]data.Project, error)
+	GetFeaturedProjectsAfter(ctx context.Context, limit int, cursor *data.ProjectCursor) ([]data.Project, *data.ProjectCursor, error)
+	GetFeaturedProjectSummaries(ctx context.Context, limit, offset int) ([]data.Project, error)
+	GetFeaturedProjectSummariesAfter(ctx context.Context, limit int, cursor *data.ProjectCursor) ([]data.Project, *data.ProjectCursor, error)
+	FeatureProject(ctx context.Context, projectID uuid.UUID, expiresAt *time.Time) (*data.Project, error)
+	GetLikedProjects(ctx context.Context, userID uuid.UUID) ([]data.Project, error)
+	LikeProject(ctx context.Context, projectID, userID uuid.UUID) error
+	UnlikeProject(ctx context.Context, projectID, userID uuid.UUID) error
+	GetQuota(ctx context.Context, userID uuid.UUID) (*data.QuotaUsage, error)
+	ScanDataIntegrity(ctx context.Context, opts data.IntegrityScanOptions) (*data.IntegrityReport, error)
+	GetLikers(ctx context.Context, projectID uuid.UUID, page, limit int) ([]data.ProjectLiker, int, error)
+	RecordView(ctx context.Context, projectID uuid.UUID, userID *uuid.UUID, ip string) error
+	DiscoverProjects(ctx context.Context, opts data.DiscoverOptions) ([]data.Project, error)
+	RecordOpen(ctx context.Context, projectID, userID uuid.UUID) error
+	GetRecentProjects(ctx context.Context, userID uuid.UUID, limit int) ([]data.RecentProject, error)
+	UpdateProject(ctx context.Context, p data.ProjectUpdate) (*data.Project, error)
+	DeleteProject(ctx context.Context, projectID uuid.UUID) error
+	IsOwner(ctx context.Context, projectID, userID uuid.UUID) (bool, error)
+	GetProjectRole(ctx context.Context, projectID, userID uuid.UUID) (data.ProjectRole, error)
+	GetMembers(ctx context.Context, projectID uuid.UUID) ([]data.ProjectMember, error)
+	AddMember(ctx context.Context, projectID uuid.UUID, invite data.ProjectMemberInvite) (*data.ProjectMember, error)
+	RemoveMember(ctx context.Context, projectID, userID uuid.UUID) error
+	GetPublicProjects(ctx context.Context, filters data.PublicProjectFilter) ([]data.Project, int, error)
+	GetPublicProjectsAfter(ctx context.Context, filters data.PublicProjectFilter, cursor *data.ProjectCursor) ([]data.Project, *data.ProjectCursor, error)
+	GetPublicProjectSummaries(ctx context.Context, filters data.PublicProjectFilter) ([]data.Project, int, error)
+	GetPublicProjectSummariesAfter(ctx context.Context, filters data.PublicProjectFilter, cursor *data.ProjectCursor) ([]data.Project, *data.ProjectCursor, error)
+	ListProjects(ctx context.Context, filters data.ProjectFilter) ([]data.Project, int, error)
+	ForkProject(ctx context.Context, projectID, userID uuid.UUID) (*data.Project, error)
+	PublishProject(ctx context.Context, projectID uuid.UUID) (*data.ProjectSnapshot, error)
+	GetMetadataDraft(ctx context.Context, projectID uuid.UUID) (*data.ProjectMetadataDraft, error)
+	SaveMetadataDraft(ctx context.Context, projectID uuid.UUID, update data.ProjectMetadataDraftUpdate) (*data.ProjectMetadataDraft, error)
+	DiscardMetadataDraft(ctx context.Context, projectID uuid.UUID) error
+	SetAccessibility(ctx context.Context, projectID uuid.UUID, update data.ProjectAccessibilityUpdate) (*data.ProjectAccessibility, error)
+	GetForks(ctx context.Context, projectID uuid.UUID, requestingUserID *uuid.UUID, page, limit int) ([]data.Project, int, error)
+	CountUserForks(ctx context.Context, userID uuid.UUID, since time.Time) (int, error)
+	CountUserProjects(ctx context.Context, userID uuid.UUID) (int, error)
+	CompactProjectData(ctx context.Context, opts data.CompactionOptions) (*data.CompactionReport, error)
 }
 
+// RecentProjectsKept is how many recently opened projects are remembered per user.
+const RecentProjectsKept = 50
+
+// Columns selected as the flow data of the projects of a list. Summaries leave it out, it can be hundreds of KB per project.
+const (
+	projectData   = "p.data"
+	noProjectData = "NULL"
+)
+
 // UserService implements the IUserService interface for managing users.
 type ProjectService struct {
-	db *sql.DB
+	db        *sql.DB
+	newID     ids.Generator
+	quotas    config.QuotasConfig
+	integrity *integrityMetrics
 }
 
-// NewProjectService creates a new ProjectService with the provided database connection.
-func NewProjectService(db *sql.DB) ProjectService {
+// NewProjectService creates a new ProjectService with the provided database connection, generator of project IDs
+// and quotas of the roles.
+func NewProjectService(db *sql.DB, newID ids.Generator, quotas config.QuotasConfig) ProjectService {
 	return ProjectService{
-		db: db,
+		db:        db,
+		newID:     newID,
+		quotas:    quotas,
+		integrity: &integrityMetrics{},
 	}
 }
 
 // CreateProject creates a new project with the provided data for a specific user.
-func (s ProjectService) CreateProject(p data.ProjectCreate) (*data.Project, error) {
-	tx, err := s.db.Begin()
+// On a dry run the project is returned as it would be created, without persisting it.
+// It returns a QuotaError if the user owns as many projects as their role allows or the data exceeds its size or storage quota.
+func (s ProjectService) CreateProject(ctx context.Context, p data.ProjectCreate) (*data.Project, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback()
 
+	quota, stored, err := s.checkProjectQuota(ctx, tx, p.CreatorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDataQuota(quota, p.Data); err != nil {
+		return nil, err
+	}
+	if err := checkStorageQuota(quota, stored, len(p.Data)); err != nil {
+		return nil, err
+	}
+
+	id, err := s.newID()
+	if err != nil {
+		return nil, err
+	}
+
 	var project data.Project
 	query := `
-		INSERT INTO projects (title, description, data, creator_id, is_public)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, title, description, data, creator_id, (SELECT username FROM users WHERE id = $4), likes_count, featured_until, created_at, last_edited_at, is_public`
+		INSERT INTO projects (title, description, data, creator_id, is_public, classroom_id, id, data_checksum, data_bytes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, ` + fmt.Sprintf(dataChecksum, "$3") + `, ` + fmt.Sprintf(dataBytes, "$3") + `)
+		RETURNING id, title, description, data, creator_id, (SELECT username FROM users WHERE id = $4), (SELECT verified FROM users WHERE id = $4), likes_count, views_count, featured_until, created_at, last_edited_at, is_public, classroom_id, forked_from, fork_count, version, hidden_at`
 
-	err = tx.QueryRow(
+	err = tx.QueryRowContext(ctx,
 		query,
 		p.Title,
 		p.Description,
 		p.Data,
 		p.CreatorID,
 		p.IsPublic,
+		p.ClassroomID,
+		id,
 	).Scan(
 		&project.ID,
 		&project.Title,
@@ -69,16 +150,40 @@ func (s ProjectService) CreateProject(p data.ProjectCreate) (*data.Project, erro
 		&project.Data,
 		&project.CreatorID,
 		&project.CreatorUsername,
+		&project.CreatorVerified,
 		&project.LikesCount,
+		&project.ViewsCount,
 		&project.FeaturedUntil,
 		&project.CreatedAt,
 		&project.LastEditedAt,
 		&project.IsPublic,
+		&project.ClassroomID,
+		&project.ForkedFrom,
+		&project.ForkCount,
+		&project.Version,
+		&project.HiddenAt,
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	// a dry run goes through the same statement so constraints are checked, the deferred rollback discards it
+	if p.DryRun {
+		return &project, nil
+	}
+
+	if len(p.Revisions) > 0 {
+		if err := restoreRevisions(ctx, tx, project.ID, p.Revisions); err != nil {
+			return nil, err
+		}
+	}
+
+	if project.IsPublic {
+		if err := ensureSnapshot(ctx, tx, project.ID); err != nil {
+			return nil, err
+		}
+	}
+
 	if err = tx.Commit(); err != nil {
 		return nil, err
 	}
@@ -86,27 +191,49 @@ func (s ProjectService) CreateProject(p data.ProjectCreate) (*data.Project, erro
 	return &project, nil
 }
 
+// classroomVisible matches projects shared with a classroom the user bound to the given placeholder is a member of.
+// Like the other visibility checks it leaves out projects taken down by moderators, which only their creator can still see.
+const classroomVisible = `(p.classroom_id IS NOT NULL AND p.hidden_at IS NULL AND EXISTS(
+	SELECT 1 FROM classroom_members cm WHERE cm.classroom_id = p.classroom_id AND cm.user_id = %s))`
+
+// memberVisible matches projects shared with the user bound to the given placeholder as a viewer or editor.
+const memberVisible = `(p.hidden_at IS NULL AND EXISTS(SELECT 1 FROM project_members pm WHERE pm.project_id = p.id AND pm.user_id = %s))`
+
 // GetProject retrieves a single project by its ID, ensuring the requesting user has permission to view it.
-func (s ProjectService) GetProject(projectID uuid.UUID, requestingUserID *uuid.UUID) (*data.Project, error) {
+// Projects shared with a classroom are visible to the classroom roster only, private projects to their members.
+func (s ProjectService) GetProject(ctx context.Context, projectID uuid.UUID, requestingUserID *uuid.UUID) (*data.Project, error) {
 	var project data.Project
+	var checksum sql.NullString
+	var liked bool
 	query := `
-		SELECT p.id, p.title, p.description, p.data, p.creator_id, u.username, p.likes_count, p.featured_until, p.created_at, p.last_edited_at, p.is_public
+		SELECT p.id, p.title, p.description, p.tags, p.data, p.creator_id, u.username, u.verified, p.likes_count, p.views_count, p.featured_until, p.created_at, p.last_edited_at, p.is_public, p.classroom_id, p.forked_from, p.fork_count, p.version, p.hidden_at, ml.user_id IS NOT NULL, p.data_checksum
 		FROM projects p
 		JOIN users u ON p.creator_id = u.id
-		WHERE p.id = $1 AND (p.is_public = TRUE OR p.creator_id = $2)`
+		LEFT JOIN project_likes ml ON ml.project_id = p.id AND ml.user_id = $2
+		WHERE p.id = $1 AND ((p.is_public = TRUE AND p.hidden_at IS NULL) OR p.creator_id = $2 OR ` + fmt.Sprintf(classroomVisible, "$2") + ` OR ` + fmt.Sprintf(memberVisible, "$2") + `)`
 
-	err := s.db.QueryRow(query, projectID, &requestingUserID).Scan(
+	err := s.db.QueryRowContext(ctx, query, projectID, &requestingUserID).Scan(
 		&project.ID,
 		&project.Title,
 		&project.Description,
+		pq.Array(&project.Tags),
 		&project.Data,
 		&project.CreatorID,
 		&project.CreatorUsername,
+		&project.CreatorVerified,
 		&project.LikesCount,
+		&project.ViewsCount,
 		&project.FeaturedUntil,
 		&project.CreatedAt,
 		&project.LastEditedAt,
 		&project.IsPublic,
+		&project.ClassroomID,
+		&project.ForkedFrom,
+		&project.ForkCount,
+		&project.Version,
+		&project.HiddenAt,
+		&liked,
+		&checksum,
 	)
 
 	if err != nil {
@@ -116,36 +243,38 @@ func (s ProjectService) GetProject(projectID uuid.UUID, requestingUserID *uuid.U
 		return nil, err
 	}
 
-	return &project, nil
+	s.verifyChecksum(ctx, project.ID, project.Data, checksum)
+	project.IsLikedByMe = likedBy(requestingUserID, liked)
+
+	described := []data.Project{project}
+	if err := s.attachAccessibility(ctx, described); err != nil {
+		return nil, err
+	}
+
+	return &described[0], nil
 }
 
-// GetUserProjects retrieves projects for a given user profile.
-// It returns all projects if the requester is the owner, otherwise it only returns public projects.
-func (s ProjectService) GetUserProjects(profileUserID, requestingUserID uuid.UUID) ([]data.Project, error) {
+// GetProjectsByIDs retrieves the projects with the given IDs that are visible to the requesting user, in the order of the IDs.
+// Projects that don't exist or aren't visible are left out rather than reported.
+func (s ProjectService) GetProjectsByIDs(ctx context.Context, projectIDs []uuid.UUID, requestingUserID *uuid.UUID) ([]data.Project, error) {
 	query := `
-		SELECT p.id, p.title, p.description, p.data, p.creator_id, u.username, p.likes_count, p.featured_until, p.created_at, p.last_edited_at, p.is_public
+		SELECT p.id, p.title, p.description, p.data, p.creator_id, u.username, u.verified, p.likes_count, p.views_count, p.featured_until, p.created_at, p.last_edited_at, p.is_public, p.classroom_id, p.forked_from, p.fork_count, p.version, p.hidden_at, ml.user_id IS NOT NULL
 		FROM projects p
 		JOIN users u ON p.creator_id = u.id
-		WHERE p.creator_id = $1`
-
-	args := []interface{}{profileUserID}
-
-	// If the requester is not the owner of the projects, only show public ones.
-	if profileUserID != requestingUserID {
-		query += " AND p.is_public = TRUE"
-	}
-
-	query += " ORDER BY p.last_edited_at DESC"
+		LEFT JOIN project_likes ml ON ml.project_id = p.id AND ml.user_id = $2
+		WHERE p.id = ANY($1::uuid[]) AND ((p.is_public = TRUE AND p.hidden_at IS NULL) OR p.creator_id = $2 OR ` + fmt.Sprintf(classroomVisible, "$2") + ` OR ` + fmt.Sprintf(memberVisible, "$2") + `)
+		ORDER BY array_position($1::uuid[], p.id)`
 
-	rows, err := s.db.Query(query, args...)
+	rows, err := s.db.QueryContext(ctx, query, pq.Array(projectIDs), &requestingUserID)
 	if err != nil {
-		return []data.Project{}, err
+		return nil, err
 	}
 	defer rows.Close()
 
-	projects := make([]data.Project, 0)
+	projects := make([]data.Project, 0, len(projectIDs))
 	for rows.Next() {
 		var project data.Project
+		var liked bool
 		if err := rows.Scan(
 			&project.ID,
 			&project.Title,
@@ -153,45 +282,163 @@ func (s ProjectService) GetUserProjects(profileUserID, requestingUserID uuid.UUI
 			&project.Data,
 			&project.CreatorID,
 			&project.CreatorUsername,
+			&project.CreatorVerified,
 			&project.LikesCount,
+			&project.ViewsCount,
 			&project.FeaturedUntil,
 			&project.CreatedAt,
 			&project.LastEditedAt,
 			&project.IsPublic,
+			&project.ClassroomID,
+			&project.ForkedFrom,
+			&project.ForkCount,
+			&project.Version,
+			&project.HiddenAt,
+			&liked,
 		); err != nil {
-			return []data.Project{}, err
+			return nil, err
 		}
+		project.IsLikedByMe = likedBy(requestingUserID, liked)
 		projects = append(projects, project)
 	}
 
 	if err = rows.Err(); err != nil {
-		return []data.Project{}, err
+		return nil, err
+	}
+
+	if err := s.attachAccessibility(ctx, projects); err != nil {
+		return nil, err
 	}
 
 	return projects, nil
 }
 
-// GetFeaturedProjects retrieves a paginated list of featured projects.
-func (s ProjectService) GetFeaturedProjects(limit, page int) ([]data.Project, error) {
-	offset := (page - 1) * limit
-
+// GetEmbeddedProject retrieves a single project by its ID regardless of its visibility, unless it was taken down.
+// It is used for embeds, where the owner granted access with an embed token.
+// Published projects are returned as they were published, with PublishedAt set, so embeds don't change while the owner edits.
+func (s ProjectService) GetEmbeddedProject(ctx context.Context, projectID uuid.UUID) (*data.Project, error) {
+	var project data.Project
+	var checksum sql.NullString
 	query := `
-		SELECT p.id, p.title, p.description, p.data, p.creator_id, u.username, p.likes_count, p.featured_until, p.created_at, p.last_edited_at, p.is_public
+		SELECT p.id, ` + publishedColumns() + `, p.creator_id, u.username, u.verified, p.likes_count, p.views_count, p.featured_until, p.created_at, p.last_edited_at, p.is_public, p.classroom_id, p.forked_from, p.fork_count, ` + fmt.Sprintf(published, "version") + `, p.hidden_at,
+		       CASE WHEN ps.project_id IS NULL THEN p.data_checksum END, ps.published_at
 		FROM projects p
 		JOIN users u ON p.creator_id = u.id
-		WHERE p.featured_until IS NOT NULL AND p.featured_until > NOW() AND p.is_public = TRUE
-		ORDER BY p.featured_until DESC, p.likes_count DESC
-		LIMIT $1 OFFSET $2`
+		LEFT JOIN project_snapshots ps ON ps.project_id = p.id
+		WHERE p.id = $1 AND p.hidden_at IS NULL`
+
+	err := s.db.QueryRowContext(ctx, query, projectID).Scan(
+		&project.ID,
+		&project.Title,
+		&project.Description,
+		&project.Data,
+		&project.CreatorID,
+		&project.CreatorUsername,
+		&project.CreatorVerified,
+		&project.LikesCount,
+		&project.ViewsCount,
+		&project.FeaturedUntil,
+		&project.CreatedAt,
+		&project.LastEditedAt,
+		&project.IsPublic,
+		&project.ClassroomID,
+		&project.ForkedFrom,
+		&project.ForkCount,
+		&project.Version,
+		&project.HiddenAt,
+		&checksum,
+		&project.PublishedAt,
+	)
 
-	rows, err := s.db.Query(query, limit, offset)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	// snapshots have no checksum of their own
+	if project.PublishedAt == nil {
+		s.verifyChecksum(ctx, project.ID, project.Data, checksum)
+	}
+
+	described := []data.Project{project}
+	if err := s.attachAccessibility(ctx, described); err != nil {
 		return nil, err
 	}
+
+	return &described[0], nil
+}
+
+// GetUserProjects retrieves projects for a given user profile.
+// It returns all projects if the requester is the owner, otherwise it only returns public projects
+// and projects shared with the requester or a classroom they are a member of. Guests, with a nil requestingUserID, see public projects only.
+// With a folder filter, only the projects in the folder of the user are returned in the folder's order, or those in no folder.
+func (s ProjectService) GetUserProjects(ctx context.Context, profileUserID uuid.UUID, requestingUserID *uuid.UUID, folder *data.FolderFilter) ([]data.Project, error) {
+	return s.userProjects(ctx, profileUserID, requestingUserID, folder, projectData)
+}
+
+// GetUserProjectSummaries is GetUserProjects without the flow data of the projects.
+func (s ProjectService) GetUserProjectSummaries(ctx context.Context, profileUserID uuid.UUID, requestingUserID *uuid.UUID, folder *data.FolderFilter) ([]data.Project, error) {
+	return s.userProjects(ctx, profileUserID, requestingUserID, folder, noProjectData)
+}
+
+func (s ProjectService) userProjects(ctx context.Context, profileUserID uuid.UUID, requestingUserID *uuid.UUID, folder *data.FolderFilter, dataColumn string) ([]data.Project, error) {
+	query := `
+		SELECT p.id, p.title, p.description, ` + dataColumn + `, p.creator_id, u.username, u.verified, p.likes_count, p.views_count, p.featured_until, p.created_at, p.last_edited_at, p.is_public, p.classroom_id, p.forked_from, p.fork_count, p.version, p.hidden_at, ml.user_id IS NOT NULL
+		FROM projects p
+		JOIN users u ON p.creator_id = u.id
+		LEFT JOIN project_likes ml ON ml.project_id = p.id AND ml.user_id = $2
+		LEFT JOIN folder_projects fp ON fp.project_id = p.id
+		WHERE p.creator_id = $1`
+	args := []interface{}{profileUserID, requestingUserID}
+
+	// If the requester is not the owner of the projects, only show public and shared ones.
+	if requestingUserID == nil || *requestingUserID != profileUserID {
+		query += " AND ((p.is_public = TRUE AND p.hidden_at IS NULL) OR " + fmt.Sprintf(classroomVisible, "$2") + " OR " + fmt.Sprintf(memberVisible, "$2") + ")"
+	}
+
+	switch {
+	case folder == nil:
+		query += " ORDER BY p.last_edited_at DESC"
+	case folder.FolderID == nil:
+		query += " AND fp.project_id IS NULL ORDER BY p.last_edited_at DESC"
+	default:
+		query += " AND fp.folder_id = $3 ORDER BY fp.position, p.last_edited_at DESC"
+		args = append(args, *folder.FolderID)
+	}
+
+	projects, err := s.queryLikedProjects(ctx, requestingUserID, query, args...)
+	if err != nil {
+		return []data.Project{}, err
+	}
+
+	return projects, nil
+}
+
+// GetContributedProjects retrieves the projects the user accepted a co-author credit on, for the "contributed to" section of the profile.
+// Only projects visible to the requester are returned, the credit doesn't make a private project visible.
+func (s ProjectService) GetContributedProjects(ctx context.Context, profileUserID uuid.UUID, requestingUserID *uuid.UUID) ([]data.Project, error) {
+	query := `
+		SELECT p.id, p.title, p.description, p.data, p.creator_id, u.username, u.verified, p.likes_count, p.views_count, p.featured_until, p.created_at, p.last_edited_at, p.is_public, p.classroom_id, p.forked_from, p.fork_count, p.version, p.hidden_at, ml.user_id IS NOT NULL
+		FROM project_credits pc
+		JOIN projects p ON pc.project_id = p.id
+		JOIN users u ON p.creator_id = u.id
+		LEFT JOIN project_likes ml ON ml.project_id = p.id AND ml.user_id = $2
+		WHERE pc.user_id = $1 AND pc.status = 'accepted'
+		AND ((p.is_public = TRUE AND p.hidden_at IS NULL) OR p.creator_id = $2 OR ` + fmt.Sprintf(classroomVisible, "$2") + ` OR ` + fmt.Sprintf(memberVisible, "$2") + `)
+		ORDER BY pc.responded_at DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, profileUserID, requestingUserID)
+	if err != nil {
+		return []data.Project{}, err
+	}
 	defer rows.Close()
 
 	projects := make([]data.Project, 0)
 	for rows.Next() {
 		var project data.Project
+		var liked bool
 		if err := rows.Scan(
 			&project.ID,
 			&project.Title,
@@ -199,26 +446,101 @@ func (s ProjectService) GetFeaturedProjects(limit, page int) ([]data.Project, er
 			&project.Data,
 			&project.CreatorID,
 			&project.CreatorUsername,
+			&project.CreatorVerified,
 			&project.LikesCount,
+			&project.ViewsCount,
 			&project.FeaturedUntil,
 			&project.CreatedAt,
 			&project.LastEditedAt,
 			&project.IsPublic,
+			&project.ClassroomID,
+			&project.ForkedFrom,
+			&project.ForkCount,
+			&project.Version,
+			&project.HiddenAt,
+			&liked,
 		); err != nil {
-			return nil, err
+			return []data.Project{}, err
 		}
+		project.IsLikedByMe = likedBy(requestingUserID, liked)
 		projects = append(projects, project)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, err
+		return []data.Project{}, err
+	}
+
+	if err := s.attachAccessibility(ctx, projects); err != nil {
+		return []data.Project{}, err
 	}
 
 	return projects, nil
 }
 
-func (s ProjectService) FeatureProject(projectID uuid.UUID, expiresAt *time.Time) (*data.Project, error) {
-	tx, err := s.db.Begin()
+// GetFeaturedProjects retrieves a paginated list of featured projects.
+func (s ProjectService) GetFeaturedProjects(ctx context.Context, limit, page int) ([]data.Project, error) {
+	return s.featuredProjects(ctx, limit, page, projectData)
+}
+
+// GetFeaturedProjectSummaries is GetFeaturedProjects without the flow data of the projects.
+func (s ProjectService) GetFeaturedProjectSummaries(ctx context.Context, limit, page int) ([]data.Project, error) {
+	return s.featuredProjects(ctx, limit, page, noProjectData)
+}
+
+func (s ProjectService) featuredProjects(ctx context.Context, limit, page int, dataColumn string) ([]data.Project, error) {
+	offset := (page - 1) * limit
+
+	query := `
+		SELECT p.id, p.title, p.description, ` + dataColumn + `, p.creator_id, u.username, u.verified, p.likes_count, p.views_count, p.featured_until, p.created_at, p.last_edited_at, p.is_public, p.classroom_id, p.forked_from, p.fork_count, p.version, p.hidden_at
+		FROM projects p
+		JOIN users u ON p.creator_id = u.id
+		WHERE p.featured_until IS NOT NULL AND p.featured_until > NOW() AND p.is_public = TRUE AND p.hidden_at IS NULL
+		ORDER BY p.featured_until DESC, p.likes_count DESC
+		LIMIT $1 OFFSET $2`
+
+	return s.queryProjects(ctx, query, limit, offset)
+}
+
+// GetFeaturedProjectsAfter retrieves a page of currently featured projects continuing after the cursor,
+// or from the start without one. Pages are sorted by the end of the featuring, latest first, so unlike
+// offset pages they don't shift while projects are featured, and later pages are as fast as the first.
+// It returns the cursor of the next page, nil on the last page.
+func (s ProjectService) GetFeaturedProjectsAfter(ctx context.Context, limit int, cursor *data.ProjectCursor) ([]data.Project, *data.ProjectCursor, error) {
+	return s.featuredProjectsAfter(ctx, limit, cursor, projectData)
+}
+
+// GetFeaturedProjectSummariesAfter is GetFeaturedProjectsAfter without the flow data of the projects.
+func (s ProjectService) GetFeaturedProjectSummariesAfter(ctx context.Context, limit int, cursor *data.ProjectCursor) ([]data.Project, *data.ProjectCursor, error) {
+	return s.featuredProjectsAfter(ctx, limit, cursor, noProjectData)
+}
+
+func (s ProjectService) featuredProjectsAfter(ctx context.Context, limit int, cursor *data.ProjectCursor, dataColumn string) ([]data.Project, *data.ProjectCursor, error) {
+	where := "WHERE p.featured_until IS NOT NULL AND p.featured_until > NOW() AND p.is_public = TRUE AND p.hidden_at IS NULL"
+	args := []interface{}{limit + 1}
+	if cursor != nil {
+		where += " AND (p.featured_until, p.id) < ($2, $3)"
+		args = append(args, cursor.Time, cursor.ID)
+	}
+
+	query := `
+		SELECT p.id, p.title, p.description, ` + dataColumn + `, p.creator_id, u.username, u.verified, p.likes_count, p.views_count, p.featured_until, p.created_at, p.last_edited_at, p.is_public, p.classroom_id, p.forked_from, p.fork_count, p.version, p.hidden_at
+		FROM projects p
+		JOIN users u ON p.creator_id = u.id
+		` + where + `
+		ORDER BY p.featured_until DESC, p.id DESC
+		LIMIT $1`
+
+	projects, err := s.queryProjects(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	projects, next := nextPage(projects, limit, func(p data.Project) time.Time { return *p.FeaturedUntil })
+	return projects, next, nil
+}
+
+func (s ProjectService) FeatureProject(ctx context.Context, projectID uuid.UUID, expiresAt *time.Time) (*data.Project, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -228,22 +550,32 @@ func (s ProjectService) FeatureProject(projectID uuid.UUID, expiresAt *time.Time
 
 	query := `
 		UPDATE projects
-		SET featured_until = $2
+		SET featured_until = $2,
+		    featured_at = CASE WHEN $2::timestamptz IS NULL THEN NULL ELSE NOW() END,
+		    featured_pinned = featured_pinned AND $2::timestamptz IS NOT NULL,
+		    featured_by = NULL
 		WHERE id = $1
-		RETURNING id, title, description, data, creator_id, (SELECT username FROM users WHERE id = creator_id), likes_count, featured_until, created_at, last_edited_at, is_public
+		RETURNING id, title, description, data, creator_id, (SELECT username FROM users WHERE id = creator_id), (SELECT verified FROM users WHERE id = creator_id), likes_count, views_count, featured_until, created_at, last_edited_at, is_public, classroom_id, forked_from, fork_count, version, hidden_at
 	`
-	err = tx.QueryRow(query, projectID, expiresAt).Scan(
+	err = tx.QueryRowContext(ctx, query, projectID, expiresAt).Scan(
 		&project.ID,
 		&project.Title,
 		&project.Description,
 		&project.Data,
 		&project.CreatorID,
 		&project.CreatorUsername,
+		&project.CreatorVerified,
 		&project.LikesCount,
+		&project.ViewsCount,
 		&project.FeaturedUntil,
 		&project.CreatedAt,
 		&project.LastEditedAt,
 		&project.IsPublic,
+		&project.ClassroomID,
+		&project.ForkedFrom,
+		&project.ForkCount,
+		&project.Version,
+		&project.HiddenAt,
 	)
 
 	if err != nil {
@@ -254,6 +586,12 @@ func (s ProjectService) FeatureProject(projectID uuid.UUID, expiresAt *time.Time
 		return nil, err
 	}
 
+	if expiresAt != nil {
+		if err := ensureSnapshot(ctx, tx, projectID); err != nil {
+			return nil, err
+		}
+	}
+
 	if err = tx.Commit(); err != nil {
 		return nil, err
 	}
@@ -263,16 +601,16 @@ func (s ProjectService) FeatureProject(projectID uuid.UUID, expiresAt *time.Time
 }
 
 // GetLikedProjects retrieves all projects liked by a specific user.
-func (s ProjectService) GetLikedProjects(userID uuid.UUID) ([]data.Project, error) {
+func (s ProjectService) GetLikedProjects(ctx context.Context, userID uuid.UUID) ([]data.Project, error) {
 	query := `
-		SELECT p.id, p.title, p.description, p.data, p.creator_id, u.username, p.likes_count, p.featured_until, p.created_at, p.last_edited_at, p.is_public
+		SELECT p.id, p.title, p.description, p.data, p.creator_id, u.username, u.verified, p.likes_count, p.views_count, p.featured_until, p.created_at, p.last_edited_at, p.is_public, p.classroom_id, p.forked_from, p.fork_count, p.version, p.hidden_at
 		FROM projects p
 		JOIN users u ON p.creator_id = u.id
 		JOIN project_likes pl ON p.id = pl.project_id
-		WHERE pl.user_id = $1 AND p.is_public = TRUE
+		WHERE pl.user_id = $1 AND p.is_public = TRUE AND p.hidden_at IS NULL
 		ORDER BY pl.created_at DESC`
 
-	rows, err := s.db.Query(query, userID)
+	rows, err := s.db.QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -288,11 +626,18 @@ func (s ProjectService) GetLikedProjects(userID uuid.UUID) ([]data.Project, erro
 			&project.Data,
 			&project.CreatorID,
 			&project.CreatorUsername,
+			&project.CreatorVerified,
 			&project.LikesCount,
+			&project.ViewsCount,
 			&project.FeaturedUntil,
 			&project.CreatedAt,
 			&project.LastEditedAt,
 			&project.IsPublic,
+			&project.ClassroomID,
+			&project.ForkedFrom,
+			&project.ForkCount,
+			&project.Version,
+			&project.HiddenAt,
 		); err != nil {
 			return nil, err
 		}
@@ -303,19 +648,62 @@ func (s ProjectService) GetLikedProjects(userID uuid.UUID) ([]data.Project, erro
 		return nil, err
 	}
 
+	if err := s.attachAccessibility(ctx, projects); err != nil {
+		return nil, err
+	}
+
 	return projects, nil
 }
 
+// GetLikers retrieves a paginated list of the users who liked a project, latest like first.
+// Likes of deleted accounts count towards the like counter of the project but aren't listed.
+func (s ProjectService) GetLikers(ctx context.Context, projectID uuid.UUID, page, limit int) ([]data.ProjectLiker, int, error) {
+	var total int
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM project_likes WHERE project_id = $1 AND user_id IS NOT NULL", projectID).Scan(&total)
+	if err != nil {
+		return []data.ProjectLiker{}, 0, err
+	}
+
+	query := `
+		SELECT pl.user_id, u.username, u.verified, pl.created_at
+		FROM project_likes pl
+		JOIN users u ON pl.user_id = u.id
+		WHERE pl.project_id = $1
+		ORDER BY pl.created_at DESC, pl.user_id
+		LIMIT $2 OFFSET $3`
+
+	rows, err := s.db.QueryContext(ctx, query, projectID, limit, (page-1)*limit)
+	if err != nil {
+		return []data.ProjectLiker{}, 0, err
+	}
+	defer rows.Close()
+
+	likers := make([]data.ProjectLiker, 0)
+	for rows.Next() {
+		var liker data.ProjectLiker
+		if err := rows.Scan(&liker.UserID, &liker.Username, &liker.Verified, &liker.LikedAt); err != nil {
+			return []data.ProjectLiker{}, 0, err
+		}
+		likers = append(likers, liker)
+	}
+
+	if err = rows.Err(); err != nil {
+		return []data.ProjectLiker{}, 0, err
+	}
+
+	return likers, total, nil
+}
+
 // LikeProject adds a like from a user to a project and increments the project's like counter.
-func (s ProjectService) LikeProject(projectID, userID uuid.UUID) error {
-	tx, err := s.db.Begin()
+func (s ProjectService) LikeProject(ctx context.Context, projectID, userID uuid.UUID) error {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
 	query := "INSERT INTO project_likes (project_id, user_id) VALUES ($1, $2) ON CONFLICT (project_id, user_id) DO NOTHING"
-	res, err := tx.Exec(query, projectID, userID)
+	res, err := tx.ExecContext(ctx, query, projectID, userID)
 	if err != nil {
 		return err
 	}
@@ -330,8 +718,12 @@ func (s ProjectService) LikeProject(projectID, userID uuid.UUID) error {
 	}
 
 	if rowsAffected > 0 {
+		if err := events.Append(ctx, tx, data.DomainProjectLiked, projectID, &userID, nil); err != nil {
+			return err
+		}
+
 		query = "UPDATE projects SET likes_count = likes_count + 1 WHERE id = $1"
-		_, err = tx.Exec(query, projectID)
+		_, err = tx.ExecContext(ctx, query, projectID)
 		if err != nil {
 			return err
 		}
@@ -341,14 +733,14 @@ func (s ProjectService) LikeProject(projectID, userID uuid.UUID) error {
 }
 
 // UnlikeProject removes a like from a user on a project and decrements the project's like counter.
-func (s ProjectService) UnlikeProject(projectID, userID uuid.UUID) error {
-	tx, err := s.db.Begin()
+func (s ProjectService) UnlikeProject(ctx context.Context, projectID, userID uuid.UUID) error {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	res, err := tx.Exec("DELETE FROM project_likes WHERE project_id = $1 AND user_id = $2", projectID, userID)
+	res, err := tx.ExecContext(ctx, "DELETE FROM project_likes WHERE project_id = $1 AND user_id = $2", projectID, userID)
 	if err != nil {
 		return err
 	}
@@ -363,7 +755,11 @@ func (s ProjectService) UnlikeProject(projectID, userID uuid.UUID) error {
 	}
 
 	if rowsAffected > 0 {
-		_, err = tx.Exec("UPDATE projects SET likes_count = GREATEST(0, likes_count - 1) WHERE id = $1", projectID)
+		if err := events.Append(ctx, tx, data.DomainProjectUnliked, projectID, &userID, nil); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, "UPDATE projects SET likes_count = GREATEST(0, likes_count - 1) WHERE id = $1", projectID)
 		if err != nil {
 			return err
 		}
@@ -372,9 +768,136 @@ func (s ProjectService) UnlikeProject(projectID, userID uuid.UUID) error {
 	return tx.Commit()
 }
 
+// RecordView counts a view of the project, at most once per viewer and day.
+// Signed in viewers are told apart by their user ID, guests by their address, which is only stored hashed.
+func (s ProjectService) RecordView(ctx context.Context, projectID uuid.UUID, userID *uuid.UUID, ip string) error {
+	viewer := viewerKey(userID, ip)
+
+	query := `
+		WITH inserted AS (
+			INSERT INTO project_views (project_id, viewer) VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+			RETURNING project_id
+		), logged AS (
+			INSERT INTO domain_events (type, project_id, actor_id)
+			SELECT $3, project_id, $4 FROM inserted
+		)
+		UPDATE projects SET views_count = views_count + 1
+		WHERE id IN (SELECT project_id FROM inserted)`
+
+	_, err := s.db.ExecContext(ctx, query, projectID, viewer, data.DomainProjectViewed, userID)
+	return err
+}
+
+// viewerKey identifies a viewer in project_views, by user id when signed in and by a hash of the address otherwise.
+func viewerKey(userID *uuid.UUID, ip string) string {
+	if userID != nil {
+		return "user:" + userID.String()
+	}
+	hash := sha256.Sum256([]byte(ip))
+	return "ip:" + hex.EncodeToString(hash[:])
+}
+
+// DiscoverProjects returns a random sample of public projects with enough likes, without their flow data.
+// Projects of the viewer and projects they viewed recently, as recorded by RecordView, are left out,
+// so asking again keeps turning up projects they haven't seen.
+func (s ProjectService) DiscoverProjects(ctx context.Context, opts data.DiscoverOptions) ([]data.Project, error) {
+	query := `
+		SELECT p.id, p.title, p.description, ` + noProjectData + `, p.creator_id, u.username, u.verified, p.likes_count, p.views_count, p.featured_until, p.created_at, p.last_edited_at, p.is_public, p.classroom_id, p.forked_from, p.fork_count, p.version, p.hidden_at
+		FROM projects p
+		JOIN users u ON p.creator_id = u.id
+		WHERE p.is_public = TRUE AND p.hidden_at IS NULL AND p.likes_count >= $2
+		  AND p.creator_id IS DISTINCT FROM $3
+		  AND NOT EXISTS (
+			SELECT 1 FROM project_views v
+			WHERE v.project_id = p.id AND v.viewer = $4 AND v.viewed_on > CURRENT_DATE - $5::int
+		  )
+		ORDER BY random()
+		LIMIT $1`
+
+	return s.queryProjects(ctx, query, opts.Limit, opts.MinLikes, opts.UserID, viewerKey(opts.UserID, opts.IP), opts.SeenDays)
+}
+
+// RecordOpen remembers that the user opened the project, forgetting the oldest ones beyond RecentProjectsKept.
+func (s ProjectService) RecordOpen(ctx context.Context, projectID, userID uuid.UUID) error {
+	query := `
+		INSERT INTO user_recent_projects (user_id, project_id) VALUES ($1, $2)
+		ON CONFLICT (user_id, project_id) DO UPDATE SET opened_at = NOW()`
+	if _, err := s.db.ExecContext(ctx, query, userID, projectID); err != nil {
+		return err
+	}
+
+	query = `
+		DELETE FROM user_recent_projects
+		WHERE user_id = $1 AND project_id IN (
+			SELECT project_id FROM user_recent_projects
+			WHERE user_id = $1
+			ORDER BY opened_at DESC
+			OFFSET $2
+		)`
+	_, err := s.db.ExecContext(ctx, query, userID, RecentProjectsKept)
+	return err
+}
+
+// GetRecentProjects retrieves the projects the user opened last, most recent first.
+// Projects the user can no longer see are left out.
+func (s ProjectService) GetRecentProjects(ctx context.Context, userID uuid.UUID, limit int) ([]data.RecentProject, error) {
+	query := `
+		SELECT p.id, p.title, p.description, p.data, p.creator_id, u.username, u.verified, p.likes_count, p.views_count, p.featured_until, p.created_at, p.last_edited_at, p.is_public, p.classroom_id, p.forked_from, p.fork_count, p.version, p.hidden_at, r.opened_at
+		FROM user_recent_projects r
+		JOIN projects p ON r.project_id = p.id
+		JOIN users u ON p.creator_id = u.id
+		WHERE r.user_id = $1 AND ((p.is_public = TRUE AND p.hidden_at IS NULL) OR p.creator_id = $1 OR ` + fmt.Sprintf(classroomVisible, "$1") + ` OR ` + fmt.Sprintf(memberVisible, "$1") + `)
+		ORDER BY r.opened_at DESC
+		LIMIT $2`
+
+	rows, err := s.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := make([]data.RecentProject, 0)
+	for rows.Next() {
+		var project data.RecentProject
+		if err := rows.Scan(
+			&project.ID,
+			&project.Title,
+			&project.Description,
+			&project.Data,
+			&project.CreatorID,
+			&project.CreatorUsername,
+			&project.CreatorVerified,
+			&project.LikesCount,
+			&project.ViewsCount,
+			&project.FeaturedUntil,
+			&project.CreatedAt,
+			&project.LastEditedAt,
+			&project.IsPublic,
+			&project.ClassroomID,
+			&project.ForkedFrom,
+			&project.ForkCount,
+			&project.Version,
+			&project.HiddenAt,
+			&project.OpenedAt,
+		); err != nil {
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
 // UpdateProject updates the details of a specific project.
-func (s ProjectService) UpdateProject(p data.ProjectUpdate) (*data.Project, error) {
-	tx, err := s.db.Begin()
+// On a dry run the project is returned as it would be after the update, without persisting the changes.
+// With IfMatch set, it returns ErrEditConflict if the project was edited since it was in that state.
+func (s ProjectService) UpdateProject(ctx context.Context, p data.ProjectUpdate) (*data.Project, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -394,13 +917,35 @@ func (s ProjectService) UpdateProject(p data.ProjectUpdate) (*data.Project, erro
 		args = append(args, *p.Description)
 		argId++
 	}
+	if p.Tags != nil {
+		setValues = append(setValues, fmt.Sprintf("tags = $%d", argId))
+		args = append(args, pq.Array(data.NormalizeTags(p.Tags)))
+		argId++
+	}
 	if p.IsPublic != nil {
 		setValues = append(setValues, fmt.Sprintf("is_public = $%d", argId))
 		args = append(args, *p.IsPublic)
 		argId++
 	}
+	// a project is either public or shared with a classroom, setting one clears the other
+	if p.ClassroomID != nil {
+		if *p.ClassroomID == uuid.Nil {
+			setValues = append(setValues, "classroom_id = NULL")
+		} else {
+			setValues = append(setValues, fmt.Sprintf("classroom_id = $%d", argId))
+			args = append(args, *p.ClassroomID)
+			argId++
+			if p.IsPublic == nil {
+				setValues = append(setValues, "is_public = FALSE")
+			}
+		}
+	} else if p.IsPublic != nil && *p.IsPublic {
+		setValues = append(setValues, "classroom_id = NULL")
+	}
 	if p.Data != nil {
-		setValues = append(setValues, fmt.Sprintf("data = $%d", argId))
+		// changed outside the editor, the previous autosave no longer describes the current version
+		placeholder := fmt.Sprintf("$%d", argId)
+		setValues = append(setValues, "data = "+placeholder, "data_checksum = "+fmt.Sprintf(dataChecksum, placeholder), "data_bytes = "+fmt.Sprintf(dataBytes, placeholder), "version = version + 1", "last_saved_by = NULL", "last_saved_session = NULL")
 		args = append(args, p.Data)
 		argId++
 	}
@@ -409,34 +954,73 @@ func (s ProjectService) UpdateProject(p data.ProjectUpdate) (*data.Project, erro
 		return nil, services.ErrNoFields
 	}
 
+	if p.Data != nil {
+		if err := s.checkOwnerDataQuota(ctx, tx, p.ID, p.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	// the flow is about to change, keep the current version as a revision
+	if p.Data != nil {
+		if err := storeRevision(ctx, tx, p.ID, p.Data); err != nil {
+			return nil, err
+		}
+	}
+
 	// Update the last_edited_at timestamp on any update
 	setValues = append(setValues, "last_edited_at = NOW()")
 
-	query := fmt.Sprintf("UPDATE projects SET %s WHERE id = $%d RETURNING id, title, description, data, creator_id, (SELECT username FROM users WHERE id = creator_id), likes_count, featured_until, created_at, last_edited_at, is_public", strings.Join(setValues, ", "), argId)
+	where := fmt.Sprintf("id = $%d", argId)
 	args = append(args, p.ID)
+	argId++
+	if p.IfMatch != nil {
+		where += fmt.Sprintf(" AND version = $%d AND last_edited_at = $%d", argId, argId+1)
+		args = append(args, p.IfMatch.Version, p.IfMatch.LastEditedAt)
+	}
+
+	query := fmt.Sprintf("UPDATE projects SET %s WHERE %s RETURNING id, title, description, tags, data, creator_id, (SELECT username FROM users WHERE id = creator_id), (SELECT verified FROM users WHERE id = creator_id), likes_count, views_count, featured_until, created_at, last_edited_at, is_public, classroom_id, forked_from, fork_count, version, hidden_at", strings.Join(setValues, ", "), where)
 
 	var project data.Project
-	err = tx.QueryRow(query, args...).Scan(
+	err = tx.QueryRowContext(ctx, query, args...).Scan(
 		&project.ID,
 		&project.Title,
 		&project.Description,
+		pq.Array(&project.Tags),
 		&project.Data,
 		&project.CreatorID,
 		&project.CreatorUsername,
+		&project.CreatorVerified,
 		&project.LikesCount,
+		&project.ViewsCount,
 		&project.FeaturedUntil,
 		&project.CreatedAt,
 		&project.LastEditedAt,
 		&project.IsPublic,
+		&project.ClassroomID,
+		&project.ForkedFrom,
+		&project.ForkCount,
+		&project.Version,
+		&project.HiddenAt,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, services.ErrRecordNotFound
+			return nil, s.updateMissed(ctx, tx, p)
 		}
 		return nil, err
 	}
 
+	// made public, embeds get the version it was made public with until the owner publishes again
+	if p.IsPublic != nil && *p.IsPublic {
+		if err := ensureSnapshot(ctx, tx, p.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.DryRun {
+		return &project, nil
+	}
+
 	if err = tx.Commit(); err != nil {
 		return nil, err
 	}
@@ -444,27 +1028,308 @@ func (s ProjectService) UpdateProject(p data.ProjectUpdate) (*data.Project, erro
 	return &project, nil
 }
 
-// DeleteProject deletes a project from the database.
-func (s ProjectService) DeleteProject(projectID uuid.UUID) error {
-	res, err := s.db.Exec("DELETE FROM projects WHERE id = $1", projectID)
+// DeleteProject deletes a project from the database and decrements the fork counter of the project it was forked from.
+// Forks of the deleted project are kept and detached from it.
+func (s ProjectService) DeleteProject(ctx context.Context, projectID uuid.UUID) error {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	rowsAffected, err := res.RowsAffected()
+	// logged before the project is deleted, like every event is appended before the rows it changes
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO domain_events (type, project_id, payload)
+		SELECT $2, forked_from, jsonb_build_object('fork_id', id) FROM projects WHERE id = $1 AND forked_from IS NOT NULL`,
+		projectID, data.DomainForkDeleted)
 	if err != nil {
 		return err
 	}
 
-	if rowsAffected == 0 {
-		return services.ErrRecordNotFound
+	var forkedFrom *uuid.UUID
+	err = tx.QueryRowContext(ctx, "DELETE FROM projects WHERE id = $1 RETURNING forked_from", projectID).Scan(&forkedFrom)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return services.ErrRecordNotFound
+		}
+		return err
 	}
 
-	return nil
+	if forkedFrom != nil {
+		_, err = tx.ExecContext(ctx, "UPDATE projects SET fork_count = GREATEST(0, fork_count - 1) WHERE id = $1", *forkedFrom)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// updateMissed tells why an update found no project to update.
+func (s ProjectService) updateMissed(ctx context.Context, tx *sql.Tx, p data.ProjectUpdate) error {
+	if p.IfMatch == nil {
+		return services.ErrRecordNotFound
+	}
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM projects WHERE id = $1)", p.ID).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return services.ErrEditConflict
+	}
+	return services.ErrRecordNotFound
+}
+
+// SaveProjectData replaces the flow of the project if it is still at the given version, and returns the new version.
+// The user and editor session saving are remembered for GetLastSave.
+// It returns ErrEditConflict along with the current version if the project changed in the meantime,
+// and ErrRecordNotFound if the project doesn't exist.
+// Autosaves don't store revisions, only updates through UpdateProject do.
+func (s ProjectService) SaveProjectData(ctx context.Context, projectID uuid.UUID, flowData json.RawMessage, version int, userID uuid.UUID, session string) (int, error) {
+	if err := s.checkOwnerDataQuota(ctx, s.db, projectID, flowData); err != nil {
+		return 0, err
+	}
+
+	var current int
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE projects SET data = $2, data_checksum = `+fmt.Sprintf(dataChecksum, "$2")+`, data_bytes = `+fmt.Sprintf(dataBytes, "$2")+`, version = version + 1, last_edited_at = NOW(), last_saved_by = $4, last_saved_session = NULLIF($5, '')
+		WHERE id = $1 AND version = $3
+		RETURNING version`,
+		projectID, flowData, version, userID, session,
+	).Scan(&current)
+	if err == nil {
+		return current, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	// stale version, or no project at all
+	err = s.db.QueryRowContext(ctx, "SELECT version FROM projects WHERE id = $1", projectID).Scan(&current)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, services.ErrRecordNotFound
+		}
+		return 0, err
+	}
+
+	return current, services.ErrEditConflict
+}
+
+// GetLastSave returns who autosaved the current version of the project.
+// It returns ErrRecordNotFound if the project doesn't exist or its flow was last changed outside the editor.
+func (s ProjectService) GetLastSave(ctx context.Context, projectID uuid.UUID) (*data.ProjectSave, error) {
+	var save data.ProjectSave
+	var session sql.NullString
+	err := s.db.QueryRowContext(ctx, `
+		SELECT p.last_saved_by, u.username, p.last_saved_session, p.last_edited_at
+		FROM projects p
+		JOIN users u ON p.last_saved_by = u.id
+		WHERE p.id = $1`,
+		projectID,
+	).Scan(&save.UserID, &save.Username, &session, &save.SavedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	save.Session = session.String
+	return &save, nil
+}
+
+// ForkProject copies a project into a new private project owned by the user and increments the original's fork counter.
+// Visibility of the original is not checked here, callers are expected to load it with GetProject first.
+// Published projects are copied as they were published, unless the owner forks their own project.
+// It returns ErrRecordNotFound if the original project doesn't exist, and a QuotaError if the user owns as many projects as their role allows
+// or the copy doesn't fit in their storage quota.
+func (s ProjectService) ForkProject(ctx context.Context, projectID, userID uuid.UUID) (*data.Project, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	quota, stored, err := s.checkProjectQuota(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.newID()
+	if err != nil {
+		return nil, err
+	}
+
+	var project data.Project
+	query := `
+		INSERT INTO projects (title, description, data, creator_id, is_public, forked_from, id, data_checksum, data_bytes)
+		SELECT ` + publishedColumns() + `, $2, FALSE, p.id, $3,
+		       CASE WHEN ps.project_id IS NULL THEN p.data_checksum ELSE md5(ps.data::text) END,
+		       CASE WHEN ps.project_id IS NULL THEN p.data_bytes ELSE octet_length(ps.data::text) END
+		FROM projects p
+		LEFT JOIN project_snapshots ps ON ps.project_id = p.id AND p.creator_id <> $2
+		WHERE p.id = $1
+		RETURNING id, title, description, data, creator_id, (SELECT username FROM users WHERE id = $2), (SELECT verified FROM users WHERE id = $2), likes_count, views_count, featured_until, created_at, last_edited_at, is_public, classroom_id, forked_from, fork_count, version, hidden_at`
+
+	err = tx.QueryRowContext(ctx, query, projectID, userID, id).Scan(
+		&project.ID,
+		&project.Title,
+		&project.Description,
+		&project.Data,
+		&project.CreatorID,
+		&project.CreatorUsername,
+		&project.CreatorVerified,
+		&project.LikesCount,
+		&project.ViewsCount,
+		&project.FeaturedUntil,
+		&project.CreatedAt,
+		&project.LastEditedAt,
+		&project.IsPublic,
+		&project.ClassroomID,
+		&project.ForkedFrom,
+		&project.ForkCount,
+		&project.Version,
+		&project.HiddenAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	if err := checkStorageQuota(quota, stored, len(project.Data)); err != nil {
+		return nil, err
+	}
+
+	if err := events.Append(ctx, tx, data.DomainProjectForked, projectID, &userID, data.ForkEvent{ForkID: project.ID}); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE projects SET fork_count = fork_count + 1 WHERE id = $1", projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO project_fork_events (user_id, source_id) VALUES ($1, $2)", userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &project, nil
 }
 
+// GetForks retrieves a paginated list of the direct forks of a project that the requesting user can view, newest first.
+func (s ProjectService) GetForks(ctx context.Context, projectID uuid.UUID, requestingUserID *uuid.UUID, page, limit int) ([]data.Project, int, error) {
+	where := `
+		FROM projects p
+		JOIN users u ON p.creator_id = u.id
+		LEFT JOIN project_likes ml ON ml.project_id = p.id AND ml.user_id = $2
+		WHERE p.forked_from = $1 AND ((p.is_public = TRUE AND p.hidden_at IS NULL) OR p.creator_id = $2 OR ` + fmt.Sprintf(classroomVisible, "$2") + ` OR ` + fmt.Sprintf(memberVisible, "$2") + `)`
+
+	var total int
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) "+where, projectID, &requestingUserID).Scan(&total)
+	if err != nil {
+		return []data.Project{}, 0, err
+	}
+
+	query := `
+		SELECT p.id, p.title, p.description, p.data, p.creator_id, u.username, u.verified, p.likes_count, p.views_count, p.featured_until, p.created_at, p.last_edited_at, p.is_public, p.classroom_id, p.forked_from, p.fork_count, p.version, p.hidden_at, ml.user_id IS NOT NULL` + where + `
+		ORDER BY p.created_at DESC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := s.db.QueryContext(ctx, query, projectID, &requestingUserID, limit, (page-1)*limit)
+	if err != nil {
+		return []data.Project{}, 0, err
+	}
+	defer rows.Close()
+
+	projects := make([]data.Project, 0)
+	for rows.Next() {
+		var project data.Project
+		var liked bool
+		if err := rows.Scan(
+			&project.ID,
+			&project.Title,
+			&project.Description,
+			&project.Data,
+			&project.CreatorID,
+			&project.CreatorUsername,
+			&project.CreatorVerified,
+			&project.LikesCount,
+			&project.ViewsCount,
+			&project.FeaturedUntil,
+			&project.CreatedAt,
+			&project.LastEditedAt,
+			&project.IsPublic,
+			&project.ClassroomID,
+			&project.ForkedFrom,
+			&project.ForkCount,
+			&project.Version,
+			&project.HiddenAt,
+			&liked,
+		); err != nil {
+			return []data.Project{}, 0, err
+		}
+		project.IsLikedByMe = likedBy(requestingUserID, liked)
+		projects = append(projects, project)
+	}
+
+	if err = rows.Err(); err != nil {
+		return []data.Project{}, 0, err
+	}
+
+	if err := s.attachAccessibility(ctx, projects); err != nil {
+		return []data.Project{}, 0, err
+	}
+
+	return projects, total, nil
+}
+
+// CountUserForks returns how many forks the user has created since the given time.
+// Forks that were deleted since are still counted.
+func (s ProjectService) CountUserForks(ctx context.Context, userID uuid.UUID, since time.Time) (int, error) {
+	var count int
+	query := "SELECT COUNT(*) FROM project_fork_events WHERE user_id = $1 AND created_at > $2"
+	err := s.db.QueryRowContext(ctx, query, userID, since).Scan(&count)
+	return count, err
+}
+
+// CountUserProjects returns how many projects the user owns.
+func (s ProjectService) CountUserProjects(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM projects WHERE creator_id = $1", userID).Scan(&count)
+	return count, err
+}
+
+// trendingScore ranks projects by their views and likes of the last two weeks, each counting half as much
+// every three days so recent activity wins. A like weighs as much as five views.
+const trendingScore = `(
+	COALESCE((SELECT SUM(POWER(0.5, (CURRENT_DATE - pv.viewed_on) / 3.0))
+		FROM project_views pv
+		WHERE pv.project_id = p.id AND pv.viewed_on > CURRENT_DATE - 14), 0)
+	+ 5 * COALESCE((SELECT SUM(POWER(0.5, EXTRACT(EPOCH FROM NOW() - pl.created_at) / 259200))
+		FROM project_likes pl
+		WHERE pl.project_id = p.id AND pl.created_at > NOW() - INTERVAL '14 days'), 0)
+)`
+
 // GetPublicProjects retrieves a paginated and filtered list of public projects.
-func (s ProjectService) GetPublicProjects(filters data.PublicProjectFilter) ([]data.Project, int, error) {
+func (s ProjectService) GetPublicProjects(ctx context.Context, filters data.PublicProjectFilter) ([]data.Project, int, error) {
+	return s.publicProjects(ctx, filters, projectData)
+}
+
+// GetPublicProjectSummaries is GetPublicProjects without the flow data of the projects.
+func (s ProjectService) GetPublicProjectSummaries(ctx context.Context, filters data.PublicProjectFilter) ([]data.Project, int, error) {
+	return s.publicProjects(ctx, filters, noProjectData)
+}
+
+func (s ProjectService) publicProjects(ctx context.Context, filters data.PublicProjectFilter, dataColumn string) ([]data.Project, int, error) {
 	offset := (filters.Page - 1) * filters.Limit
 
 	baseQuery := `
@@ -472,7 +1337,7 @@ func (s ProjectService) GetPublicProjects(filters data.PublicProjectFilter) ([]d
         JOIN users u ON p.creator_id = u.id
     `
 
-	whereClause := []string{"p.is_public = TRUE"}
+	whereClause := []string{"p.is_public = TRUE", "p.hidden_at IS NULL"}
 	args := []interface{}{}
 
 	// Filter by search term (partial match in project title and creator username)
@@ -488,63 +1353,302 @@ func (s ProjectService) GetPublicProjects(filters data.PublicProjectFilter) ([]d
 	// Count total matching projects
 	countQuery := "SELECT COUNT(*) " + baseQuery + where
 	var total int
-	err := s.db.QueryRow(countQuery, args...).Scan(&total)
+	err := s.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
 	if err != nil {
 		return []data.Project{}, 0, err
 	}
 
+	orderBy := "p." + filters.SortField + " " + filters.SortOrder
+	if filters.SortField == "trending" {
+		orderBy = trendingScore + " " + filters.SortOrder + ", p.created_at " + filters.SortOrder
+	}
+
 	query := `
-        SELECT p.id, p.title, p.description, p.data, p.creator_id, u.username, p.likes_count, p.featured_until, p.created_at, p.last_edited_at, p.is_public
+        SELECT p.id, p.title, p.description, ` + dataColumn + `, p.creator_id, u.username, u.verified, p.likes_count, p.views_count, p.featured_until, p.created_at, p.last_edited_at, p.is_public, p.classroom_id, p.forked_from, p.fork_count, p.version, p.hidden_at
     ` + baseQuery + where + `
-        ORDER BY p.` + filters.SortField + ` ` + filters.SortOrder + `
+        ORDER BY ` + orderBy + `
         LIMIT $` + fmt.Sprint(len(args)+1) + ` OFFSET $` + fmt.Sprint(len(args)+2)
 
 	args = append(args, filters.Limit, offset)
 
-	rows, err := s.db.Query(query, args...)
+	projects, err := s.queryProjects(ctx, query, args...)
 	if err != nil {
 		return []data.Project{}, 0, err
 	}
+
+	return projects, total, nil
+}
+
+// GetPublicProjectsAfter retrieves a page of public projects sorted by creation time, continuing after the cursor,
+// or from the start without one. Unlike offset pages, pages don't shift while projects are published,
+// and later pages are as fast as the first. The sort field of the filters is ignored and the total isn't counted.
+// It returns the cursor of the next page, nil on the last page.
+func (s ProjectService) GetPublicProjectsAfter(ctx context.Context, filters data.PublicProjectFilter, cursor *data.ProjectCursor) ([]data.Project, *data.ProjectCursor, error) {
+	return s.publicProjectsAfter(ctx, filters, cursor, projectData)
+}
+
+// GetPublicProjectSummariesAfter is GetPublicProjectsAfter without the flow data of the projects.
+func (s ProjectService) GetPublicProjectSummariesAfter(ctx context.Context, filters data.PublicProjectFilter, cursor *data.ProjectCursor) ([]data.Project, *data.ProjectCursor, error) {
+	return s.publicProjectsAfter(ctx, filters, cursor, noProjectData)
+}
+
+func (s ProjectService) publicProjectsAfter(ctx context.Context, filters data.PublicProjectFilter, cursor *data.ProjectCursor, dataColumn string) ([]data.Project, *data.ProjectCursor, error) {
+	whereClause := []string{"p.is_public = TRUE", "p.hidden_at IS NULL"}
+	args := []interface{}{}
+
+	if filters.SearchTerm != "" {
+		whereClause = append(whereClause, "(p.title ILIKE $"+fmt.Sprint(len(args)+1)+" OR u.username ILIKE $"+fmt.Sprint(len(args)+2)+")")
+		searchTerm := "%" + filters.SearchTerm + "%"
+		args = append(args, searchTerm, searchTerm)
+	}
+
+	order, after := "DESC", "<"
+	if filters.SortOrder == "asc" {
+		order, after = "ASC", ">"
+	}
+	if cursor != nil {
+		whereClause = append(whereClause, fmt.Sprintf("(p.created_at, p.id) %s ($%d, $%d)", after, len(args)+1, len(args)+2))
+		args = append(args, cursor.Time, cursor.ID)
+	}
+
+	query := `
+		SELECT p.id, p.title, p.description, ` + dataColumn + `, p.creator_id, u.username, u.verified, p.likes_count, p.views_count, p.featured_until, p.created_at, p.last_edited_at, p.is_public, p.classroom_id, p.forked_from, p.fork_count, p.version, p.hidden_at
+		FROM projects p
+		JOIN users u ON p.creator_id = u.id
+		WHERE ` + strings.Join(whereClause, " AND ") + `
+		ORDER BY p.created_at ` + order + `, p.id ` + order + `
+		LIMIT $` + fmt.Sprint(len(args)+1)
+	args = append(args, filters.Limit+1)
+
+	projects, err := s.queryProjects(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	projects, next := nextPage(projects, filters.Limit, func(p data.Project) time.Time { return p.CreatedAt })
+	return projects, next, nil
+}
+
+// queryProjects runs a query selecting the columns of a project joined with its creator.
+// The data column may be noProjectData, the projects are then returned without their flow data.
+func (s ProjectService) queryProjects(ctx context.Context, query string, args ...interface{}) ([]data.Project, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
-	var projects []data.Project
+	projects := make([]data.Project, 0)
 	for rows.Next() {
 		var project data.Project
+		var flowData []byte // json.RawMessage can't hold a NULL
 		if err := rows.Scan(
 			&project.ID,
 			&project.Title,
 			&project.Description,
-			&project.Data,
+			&flowData,
 			&project.CreatorID,
 			&project.CreatorUsername,
+			&project.CreatorVerified,
 			&project.LikesCount,
+			&project.ViewsCount,
 			&project.FeaturedUntil,
 			&project.CreatedAt,
 			&project.LastEditedAt,
 			&project.IsPublic,
+			&project.ClassroomID,
+			&project.ForkedFrom,
+			&project.ForkCount,
+			&project.Version,
+			&project.HiddenAt,
 		); err != nil {
-			return []data.Project{}, 0, err
+			return nil, err
 		}
+		project.Data = flowData
 		projects = append(projects, project)
 	}
 
-	if err = rows.Err(); err != nil {
-		return []data.Project{}, 0, err
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return projects, total, nil
+	return projects, s.attachAccessibility(ctx, projects)
+}
+
+// queryLikedProjects is queryProjects for queries selecting whether the requesting user liked the project after its columns,
+// joined as ml on the likes of the requesting user.
+func (s ProjectService) queryLikedProjects(ctx context.Context, requestingUserID *uuid.UUID, query string, args ...interface{}) ([]data.Project, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := make([]data.Project, 0)
+	for rows.Next() {
+		var project data.Project
+		var flowData []byte // json.RawMessage can't hold a NULL
+		var liked bool
+		if err := rows.Scan(
+			&project.ID,
+			&project.Title,
+			&project.Description,
+			&flowData,
+			&project.CreatorID,
+			&project.CreatorUsername,
+			&project.CreatorVerified,
+			&project.LikesCount,
+			&project.ViewsCount,
+			&project.FeaturedUntil,
+			&project.CreatedAt,
+			&project.LastEditedAt,
+			&project.IsPublic,
+			&project.ClassroomID,
+			&project.ForkedFrom,
+			&project.ForkCount,
+			&project.Version,
+			&project.HiddenAt,
+			&liked,
+		); err != nil {
+			return nil, err
+		}
+		project.Data = flowData
+		project.IsLikedByMe = likedBy(requestingUserID, liked)
+		projects = append(projects, project)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return projects, s.attachAccessibility(ctx, projects)
+}
+
+// likedBy returns whether the requesting user liked a project, or nil for guests as they can't like projects.
+func likedBy(requestingUserID *uuid.UUID, liked bool) *bool {
+	if requestingUserID == nil {
+		return nil
+	}
+	return &liked
+}
+
+// nextPage trims projects queried with one more than the limit to the page,
+// and returns the cursor of the next page if that extra project showed there is one.
+func nextPage(projects []data.Project, limit int, sortKey func(data.Project) time.Time) ([]data.Project, *data.ProjectCursor) {
+	if len(projects) <= limit {
+		return projects, nil
+	}
+
+	projects = projects[:limit]
+	last := projects[limit-1]
+	return projects, &data.ProjectCursor{Time: sortKey(last), ID: last.ID}
 }
 
 // IsOwner checks to see if a user is the creator of a project.
-func (s ProjectService) IsOwner(projectID, userID uuid.UUID) (bool, error) {
+func (s ProjectService) IsOwner(ctx context.Context, projectID, userID uuid.UUID) (bool, error) {
 	query := "SELECT EXISTS(SELECT 1 FROM projects WHERE id = $1 AND creator_id = $2)"
 	var exists bool
-	err := s.db.QueryRow(query, projectID, userID).Scan(&exists)
+	err := s.db.QueryRowContext(ctx, query, projectID, userID).Scan(&exists)
 	return exists, err
 }
 
+// GetProjectRole returns the role of a user on a project, or an empty role if the project isn't shared with them.
+func (s ProjectService) GetProjectRole(ctx context.Context, projectID, userID uuid.UUID) (data.ProjectRole, error) {
+	query := `
+		SELECT CASE WHEN p.creator_id = $2 THEN $3 ELSE COALESCE(pm.role, '') END
+		FROM projects p
+		LEFT JOIN project_members pm ON pm.project_id = p.id AND pm.user_id = $2
+		WHERE p.id = $1`
+
+	var role data.ProjectRole
+	err := s.db.QueryRowContext(ctx, query, projectID, userID, data.ProjectOwner).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return role, err
+}
+
+// GetMembers retrieves the users a project is shared with, editors first.
+func (s ProjectService) GetMembers(ctx context.Context, projectID uuid.UUID) ([]data.ProjectMember, error) {
+	query := `
+		SELECT pm.user_id, u.username, pm.role, pm.added_at
+		FROM project_members pm
+		JOIN users u ON pm.user_id = u.id
+		WHERE pm.project_id = $1
+		ORDER BY pm.role = 'editor' DESC, u.username`
+
+	rows, err := s.db.QueryContext(ctx, query, projectID)
+	if err != nil {
+		return []data.ProjectMember{}, err
+	}
+	defer rows.Close()
+
+	members := make([]data.ProjectMember, 0)
+	for rows.Next() {
+		var member data.ProjectMember
+		if err := rows.Scan(&member.UserID, &member.Username, &member.Role, &member.AddedAt); err != nil {
+			return []data.ProjectMember{}, err
+		}
+		members = append(members, member)
+	}
+
+	if err = rows.Err(); err != nil {
+		return []data.ProjectMember{}, err
+	}
+
+	return members, nil
+}
+
+// AddMember shares a project with the user registered with the invite's email, or changes their role if it already is.
+// It returns ErrRecordNotFound if no user has the email or the user is the project owner.
+func (s ProjectService) AddMember(ctx context.Context, projectID uuid.UUID, invite data.ProjectMemberInvite) (*data.ProjectMember, error) {
+	query := `
+		WITH added AS (
+			INSERT INTO project_members (project_id, user_id, role)
+			SELECT p.id, u.id, $3
+			FROM projects p, users u
+			WHERE p.id = $1 AND u.email = $2 AND u.id <> p.creator_id
+			ON CONFLICT (project_id, user_id) DO UPDATE SET role = EXCLUDED.role
+			RETURNING user_id, role, added_at
+		)
+		SELECT a.user_id, u.username, a.role, a.added_at
+		FROM added a
+		JOIN users u ON a.user_id = u.id`
+
+	var member data.ProjectMember
+	err := s.db.QueryRowContext(ctx, query, projectID, invite.Email, invite.Role).Scan(&member.UserID, &member.Username, &member.Role, &member.AddedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// RemoveMember stops sharing a project with a user.
+// It returns ErrRecordNotFound if the project isn't shared with the user.
+func (s ProjectService) RemoveMember(ctx context.Context, projectID, userID uuid.UUID) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM project_members WHERE project_id = $1 AND user_id = $2", projectID, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return services.ErrRecordNotFound
+	}
+
+	return nil
+}
+
 // ListProjects returns a paginated list of projects and the total count.
-func (s ProjectService) ListProjects(filters data.ProjectFilter) ([]data.Project, int, error) {
+func (s ProjectService) ListProjects(ctx context.Context, filters data.ProjectFilter) ([]data.Project, int, error) {
 	offset := (filters.Page - 1) * filters.Limit
 
 	whereClause := []string{}
@@ -577,6 +1681,12 @@ func (s ProjectService) ListProjects(filters data.ProjectFilter) ([]data.Project
 		}
 	}
 
+	// Filter by staff annotation label
+	if filters.Label != nil && *filters.Label != "" {
+		whereClause = append(whereClause, "EXISTS (SELECT 1 FROM project_annotations pa WHERE pa.project_id = p.id AND $"+fmt.Sprint(len(args)+1)+" = ANY(pa.labels))")
+		args = append(args, strings.ToLower(strings.Join(strings.Fields(*filters.Label), " ")))
+	}
+
 	// Filter by creation time
 	if filters.CreatedBefore != nil {
 		whereClause = append(whereClause, "p.created_at <= $"+fmt.Sprint(len(args)+1))
@@ -622,14 +1732,14 @@ func (s ProjectService) ListProjects(filters data.ProjectFilter) ([]data.Project
 	// Count total matching projects
 	countQuery := "SELECT COUNT(*) FROM projects p JOIN users u ON p.creator_id = u.id " + where
 	var total int
-	err := s.db.QueryRow(countQuery, args...).Scan(&total)
+	err := s.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
 	if err != nil {
 		return []data.Project{}, 0, err
 	}
 
 	query := `
-		SELECT p.id, p.title, p.description, p.data, p.creator_id, u.username,
-		       p.likes_count, p.featured_until, p.created_at, p.last_edited_at, p.is_public
+		SELECT p.id, p.title, p.description, p.data, p.creator_id, u.username, u.verified,
+		       p.likes_count, p.views_count, p.featured_until, p.created_at, p.last_edited_at, p.is_public, p.classroom_id, p.forked_from, p.fork_count, p.version, p.hidden_at
 		FROM projects p
 		JOIN users u ON p.creator_id = u.id
 		` + where + `
@@ -638,7 +1748,7 @@ func (s ProjectService) ListProjects(filters data.ProjectFilter) ([]data.Project
 
 	args = append(args, filters.Limit, offset)
 
-	rows, err := s.db.Query(query, args...)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return []data.Project{}, 0, err
 	}
@@ -651,8 +1761,9 @@ func (s ProjectService) ListProjects(filters data.ProjectFilter) ([]data.Project
 
 		err := rows.Scan(
 			&project.ID, &project.Title, &project.Description, &project.Data,
-			&project.CreatorID, &project.CreatorUsername, &project.LikesCount,
-			&featuredUntil, &project.CreatedAt, &project.LastEditedAt, &project.IsPublic,
+			&project.CreatorID, &project.CreatorUsername, &project.CreatorVerified, &project.LikesCount, &project.ViewsCount,
+			&featuredUntil, &project.CreatedAt, &project.LastEditedAt, &project.IsPublic, &project.ClassroomID,
+			&project.ForkedFrom, &project.ForkCount, &project.Version, &project.HiddenAt,
 		)
 		if err != nil {
 			return []data.Project{}, 0, err
@@ -671,3 +1782,279 @@ func (s ProjectService) ListProjects(filters data.ProjectFilter) ([]data.Project
 
 	return projects, total, nil
 }
+
+// storeRevision saves the current version of the project as a revision, unless its flow equals newData,
+// and drops the oldest revisions beyond the limit of the owner's role.
+func storeRevision(ctx context.Context, tx *sql.Tx, projectID uuid.UUID, newData json.RawMessage) error {
+	var roleID int64
+	err := tx.QueryRowContext(ctx, `
+		WITH revision AS (
+			INSERT INTO project_revisions (project_id, title, description, data)
+			SELECT id, title, description, data FROM projects WHERE id = $1 AND data <> $2::jsonb
+			RETURNING project_id
+		)
+		SELECT u.role_id FROM revision r
+		JOIN projects p ON p.id = r.project_id
+		JOIN users u ON u.id = p.creator_id`,
+		projectID, newData,
+	).Scan(&roleID)
+	if err != nil {
+		// unchanged flow, or a missing project reported by the update itself
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	return trimRevisions(ctx, tx, projectID, roleID)
+}
+
+// restoreRevisions stores the revisions of an imported project with their original times,
+// and drops the oldest ones beyond the limit of the owner's role.
+func restoreRevisions(ctx context.Context, tx *sql.Tx, projectID uuid.UUID, revisions []data.ArchivedRevision) error {
+	for _, r := range revisions {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO project_revisions (project_id, title, description, data, created_at) VALUES ($1, $2, $3, $4, $5)",
+			projectID, r.Title, r.Description, r.Data, r.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	var roleID int64
+	err := tx.QueryRowContext(ctx,
+		"SELECT u.role_id FROM projects p JOIN users u ON u.id = p.creator_id WHERE p.id = $1", projectID,
+	).Scan(&roleID)
+	if err != nil {
+		return err
+	}
+
+	return trimRevisions(ctx, tx, projectID, roleID)
+}
+
+// trimRevisions drops the oldest revisions of the project beyond the limit of the owner's role.
+func trimRevisions(ctx context.Context, tx *sql.Tx, projectID uuid.UUID, roleID int64) error {
+	_, err := tx.ExecContext(ctx, `
+		DELETE FROM project_revisions
+		WHERE project_id = $1 AND id NOT IN (
+			SELECT id FROM project_revisions WHERE project_id = $1 ORDER BY id DESC LIMIT $2
+		)`,
+		projectID, data.RevisionLimit(data.RolesAsString[roleID]),
+	)
+	return err
+}
+
+// ListRevisions retrieves the stored revisions of the project, newest first, without their flow data.
+func (s ProjectService) ListRevisions(ctx context.Context, projectID uuid.UUID) ([]data.ProjectRevision, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, project_id, title, COALESCE(description, ''), created_at
+		FROM project_revisions
+		WHERE project_id = $1
+		ORDER BY id DESC`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []data.ProjectRevision{}
+	for rows.Next() {
+		var r data.ProjectRevision
+		if err := rows.Scan(&r.ID, &r.ProjectID, &r.Title, &r.Description, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, r)
+	}
+
+	return revisions, rows.Err()
+}
+
+// ExportProject retrieves a project with its revisions, oldest first, as a self-contained export that
+// recreates it when imported. Visibility of the project is not checked here.
+// It returns ErrRecordNotFound if the project doesn't exist.
+func (s ProjectService) ExportProject(ctx context.Context, projectID uuid.UUID) (*data.ProjectImport, error) {
+	export := data.ProjectImport{Format: data.ProjectArchiveFormat}
+	err := s.db.QueryRowContext(ctx,
+		"SELECT title, COALESCE(description, ''), data FROM projects WHERE id = $1", projectID,
+	).Scan(&export.Title, &export.Description, &export.Data)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT title, COALESCE(description, ''), data, created_at
+		FROM project_revisions
+		WHERE project_id = $1
+		ORDER BY id`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	export.Revisions = []data.ArchivedRevision{}
+	for rows.Next() {
+		var r data.ArchivedRevision
+		if err := rows.Scan(&r.Title, &r.Description, &r.Data, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		export.Revisions = append(export.Revisions, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	exportedAt := time.Now().UTC()
+	export.ExportedAt = &exportedAt
+
+	return &export, nil
+}
+
+// GetRevision retrieves a revision of the project including its flow data.
+// It returns ErrRecordNotFound if the revision doesn't exist or belongs to another project.
+func (s ProjectService) GetRevision(ctx context.Context, projectID uuid.UUID, revisionID int64) (*data.ProjectRevision, error) {
+	var r data.ProjectRevision
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, project_id, title, COALESCE(description, ''), data, created_at
+		FROM project_revisions
+		WHERE id = $1 AND project_id = $2`, revisionID, projectID,
+	).Scan(&r.ID, &r.ProjectID, &r.Title, &r.Description, &r.Data, &r.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// dataBytes is the size of the flow data bound to the given placeholder, written to data_bytes along with it.
+const dataBytes = "octet_length(%s::jsonb::text)"
+
+// storageUsed adds up the size of the flow data and thumbnails of the projects owned by the user $1.
+// Projects the projects_data_bytes online migration hasn't reached yet are measured on the spot.
+const storageUsed = `
+	(SELECT COALESCE(SUM(COALESCE(sp.data_bytes, octet_length(sp.data::text))), 0) FROM projects sp WHERE sp.creator_id = $1) +
+	(SELECT COALESCE(SUM(octet_length(st.content)), 0) FROM project_thumbnails st JOIN projects sp ON sp.id = st.project_id WHERE sp.creator_id = $1)`
+
+// QuotaFor returns the quota of a role. Moderators get the premium quota.
+func QuotaFor(quotas config.QuotasConfig, role data.RoleType) data.Quota {
+	q := quotas.User
+	switch role {
+	case data.RolePremium, data.RoleModerator:
+		q = quotas.Premium
+	case data.RoleAdmin:
+		q = quotas.Admin
+	}
+	return data.Quota{
+		MaxProjects:     q.Projects,
+		MaxBytes:        q.Bytes,
+		MaxStorage:      q.Storage,
+		MaxRunSteps:     q.RunSteps,
+		MaxRunMillis:    q.RunMillis,
+		MaxRunSegments:  q.RunSegments,
+		MaxComputeDaily: q.ComputeDaily,
+	}
+}
+
+func (s ProjectService) quota(role data.RoleType) data.Quota {
+	return QuotaFor(s.quotas, role)
+}
+
+// GetQuota returns the quota of a user's role, how many projects they own, the storage those take up
+// and the compute their runs took today. It returns ErrUserNotFound if the user doesn't exist.
+func (s ProjectService) GetQuota(ctx context.Context, userID uuid.UUID) (*data.QuotaUsage, error) {
+	var usage data.QuotaUsage
+	err := s.db.QueryRowContext(ctx, `
+		SELECT role_id, (SELECT COUNT(*) FROM projects WHERE creator_id = $1), `+storageUsed+`,
+		       COALESCE(cu.runs, 0), COALESCE(cu.steps, 0)
+		FROM users
+		LEFT JOIN compute_usage cu ON cu.user_id = users.id AND cu.day = (NOW() AT TIME ZONE 'UTC')::date
+		WHERE id = $1`,
+		userID,
+	).Scan(&usage.Role, &usage.Projects, &usage.StorageBytes, &usage.RunsToday, &usage.ComputeToday)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	usage.Quota = s.quota(usage.Role)
+	return &usage, nil
+}
+
+// checkProjectQuota returns the quota of the user and the storage they use, or a QuotaError if they can't own another project.
+// The user's row stays locked until the transaction ends, so concurrent creations can't both pass the check.
+func (s ProjectService) checkProjectQuota(ctx context.Context, tx *sql.Tx, userID uuid.UUID) (data.Quota, int, error) {
+	var role data.RoleType
+	var projects, stored int
+	err := tx.QueryRowContext(ctx,
+		"SELECT role_id, (SELECT COUNT(*) FROM projects WHERE creator_id = $1), "+storageUsed+" FROM users WHERE id = $1 FOR UPDATE",
+		userID,
+	).Scan(&role, &projects, &stored)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return data.Quota{}, 0, services.ErrUserNotFound
+		}
+		return data.Quota{}, 0, err
+	}
+
+	quota := s.quota(role)
+	if quota.MaxProjects > 0 && projects >= quota.MaxProjects {
+		return quota, stored, &services.QuotaError{Quota: services.QuotaProjects, Limit: quota.MaxProjects, Used: projects}
+	}
+
+	return quota, stored, nil
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// checkOwnerDataQuota returns a QuotaError if the flow data exceeds the size or storage quota of the project owner's role,
+// which apply whoever edits the project. A missing project is left for the write itself to report.
+// Unlike creations, concurrent saves of different projects aren't serialized, together they can overshoot the storage quota slightly.
+func (s ProjectService) checkOwnerDataQuota(ctx context.Context, q queryer, projectID uuid.UUID, flowData json.RawMessage) error {
+	var role data.RoleType
+	var stored, current int
+	err := q.QueryRowContext(ctx, `
+		SELECT u.role_id, `+strings.ReplaceAll(storageUsed, "$1", "u.id")+`, COALESCE(p.data_bytes, octet_length(p.data::text))
+		FROM projects p JOIN users u ON u.id = p.creator_id
+		WHERE p.id = $1`,
+		projectID,
+	).Scan(&role, &stored, &current)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	quota := s.quota(role)
+	if err := checkDataQuota(quota, flowData); err != nil {
+		return err
+	}
+	return checkStorageQuota(quota, stored-current, len(flowData))
+}
+
+// checkDataQuota returns a QuotaError if the flow data exceeds the size quota.
+func checkDataQuota(quota data.Quota, flowData json.RawMessage) error {
+	if quota.MaxBytes > 0 && len(flowData) > quota.MaxBytes {
+		return &services.QuotaError{Quota: services.QuotaBytes, Limit: quota.MaxBytes, Used: len(flowData)}
+	}
+	return nil
+}
+
+// checkStorageQuota returns a QuotaError if adding size bytes to the stored ones exceeds the storage quota.
+// Changes that don't add anything pass, so users over their quota after it was lowered can still shrink their projects.
+func checkStorageQuota(quota data.Quota, stored, size int) error {
+	if quota.MaxStorage > 0 && size > 0 && stored+size > quota.MaxStorage {
+		return &services.QuotaError{Quota: services.QuotaStorage, Limit: quota.MaxStorage, Used: stored + size}
+	}
+	return nil
+}
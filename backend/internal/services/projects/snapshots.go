@@ -0,0 +1,139 @@
+package projects
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// takeSnapshot stores the current version of the project $1 as its published snapshot.
+const takeSnapshot = `
+	INSERT INTO project_snapshots (project_id, title, description, tags, data, version)
+	SELECT id, title, description, tags, data, version FROM projects WHERE id = $1`
+
+// published selects a column of the published snapshot joined as ps if the project p has one, of the project otherwise.
+const published = "CASE WHEN ps.project_id IS NULL THEN p.%[1]s ELSE ps.%[1]s END"
+
+// ensureSnapshot publishes the project unless it was published before, so making a project public or featuring it
+// doesn't replace what is already embedded.
+func ensureSnapshot(ctx context.Context, tx *sql.Tx, projectID uuid.UUID) error {
+	_, err := tx.ExecContext(ctx, takeSnapshot+" ON CONFLICT (project_id) DO NOTHING", projectID)
+	return err
+}
+
+// PublishProject replaces the published snapshot of the project with its current version,
+// which embeds show and forks copy from then on. Metadata staged in a draft is applied to the project first,
+// so it goes live together with the snapshot.
+// It returns ErrRecordNotFound if the project doesn't exist.
+func (s ProjectService) PublishProject(ctx context.Context, projectID uuid.UUID) (*data.ProjectSnapshot, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE projects p
+		SET title = d.title, description = d.description, tags = d.tags, last_edited_at = NOW()
+		FROM project_metadata_drafts d
+		WHERE d.project_id = p.id AND p.id = $1`,
+		projectID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM project_metadata_drafts WHERE project_id = $1", projectID); err != nil {
+		return nil, err
+	}
+
+	var snapshot data.ProjectSnapshot
+	err = tx.QueryRowContext(ctx, takeSnapshot+`
+		ON CONFLICT (project_id) DO UPDATE
+		SET title = EXCLUDED.title, description = EXCLUDED.description, tags = EXCLUDED.tags, data = EXCLUDED.data,
+		    version = EXCLUDED.version, published_at = NOW()
+		RETURNING project_id, title, description, tags, data, version, published_at`,
+		projectID,
+	).Scan(&snapshot.ProjectID, &snapshot.Title, &snapshot.Description, pq.Array(&snapshot.Tags), &snapshot.Data, &snapshot.Version, &snapshot.PublishedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// GetMetadataDraft retrieves the metadata staged for the project.
+// It returns ErrRecordNotFound if nothing is staged.
+func (s ProjectService) GetMetadataDraft(ctx context.Context, projectID uuid.UUID) (*data.ProjectMetadataDraft, error) {
+	return scanMetadataDraft(s.db.QueryRowContext(ctx,
+		"SELECT project_id, title, description, tags, updated_at FROM project_metadata_drafts WHERE project_id = $1",
+		projectID,
+	))
+}
+
+// SaveMetadataDraft stages changes of the title, description or tags of the project without changing the project.
+// Fields left out of the update keep their staged value, or the value of the project when nothing was staged.
+// It returns ErrRecordNotFound if the project doesn't exist.
+func (s ProjectService) SaveMetadataDraft(ctx context.Context, projectID uuid.UUID, update data.ProjectMetadataDraftUpdate) (*data.ProjectMetadataDraft, error) {
+	var tags interface{}
+	if update.Tags != nil {
+		tags = pq.Array(data.NormalizeTags(update.Tags))
+	}
+
+	return scanMetadataDraft(s.db.QueryRowContext(ctx, `
+		INSERT INTO project_metadata_drafts (project_id, title, description, tags)
+		SELECT p.id, COALESCE($2::text, d.title, p.title), COALESCE($3::text, d.description, p.description, ''), COALESCE($4::text[], d.tags, p.tags)
+		FROM projects p
+		LEFT JOIN project_metadata_drafts d ON d.project_id = p.id
+		WHERE p.id = $1
+		ON CONFLICT (project_id) DO UPDATE
+		SET title = EXCLUDED.title, description = EXCLUDED.description, tags = EXCLUDED.tags, updated_at = NOW()
+		RETURNING project_id, title, description, tags, updated_at`,
+		projectID, update.Title, update.Description, tags,
+	))
+}
+
+// DiscardMetadataDraft drops the metadata staged for the project.
+// It returns ErrRecordNotFound if nothing is staged.
+func (s ProjectService) DiscardMetadataDraft(ctx context.Context, projectID uuid.UUID) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM project_metadata_drafts WHERE project_id = $1", projectID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return services.ErrRecordNotFound
+	}
+	return nil
+}
+
+func scanMetadataDraft(row *sql.Row) (*data.ProjectMetadataDraft, error) {
+	var draft data.ProjectMetadataDraft
+	if err := row.Scan(&draft.ProjectID, &draft.Title, &draft.Description, pq.Array(&draft.Tags), &draft.UpdatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &draft, nil
+}
+
+// publishedColumns selects the title, description and data of the published snapshot if there is one.
+func publishedColumns() string {
+	return fmt.Sprintf(published, "title") + ", " + fmt.Sprintf(published, "description") + ", " + fmt.Sprintf(published, "data")
+}
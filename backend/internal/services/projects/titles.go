@@ -0,0 +1,80 @@
+package projects
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maxTitleLength is the length of the longest title, in characters
+	maxTitleLength = 100
+	// titleSuggestions is how many free titles a title conflict suggests
+	titleSuggestions = 3
+	// maxSuffixLength is the length of the longest numbered suffix a suggestion gets, " (99999)"
+	maxSuffixLength = 8
+)
+
+// numberedTitle matches the " (2)" suffix of a suggested title, which isn't numbered again.
+var numberedTitle = regexp.MustCompile(` \(\d+\)$`)
+
+// CheckTitle checks whether the creator already gave another of their projects the title, ignoring case.
+// When projectID is set, the titles of the creator of that project are checked, other than its own.
+// It returns nil if the title is free, or the conflict with numbered titles that are.
+func (s ProjectService) CheckTitle(ctx context.Context, creatorID uuid.UUID, title string, projectID *uuid.UUID) (*data.TitleConflict, error) {
+	base := numberedTitle.ReplaceAllString(strings.TrimSpace(title), "")
+	prefix := truncateTitle(base, maxTitleLength-maxSuffixLength)
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT LOWER(title) FROM projects
+		WHERE creator_id = COALESCE((SELECT creator_id FROM projects WHERE id = $1), $2)
+		  AND ($1::uuid IS NULL OR id <> $1)
+		  AND LOWER(LEFT(title, char_length($3))) = LOWER($3)`,
+		projectID, creatorID, prefix,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	taken := map[string]bool{}
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		taken[t] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if !taken[strings.ToLower(title)] {
+		return nil, nil
+	}
+
+	conflict := &data.TitleConflict{Title: title, Suggestions: make([]string, 0, titleSuggestions)}
+	for n := 2; len(conflict.Suggestions) < titleSuggestions; n++ {
+		suffix := fmt.Sprintf(" (%d)", n)
+		suggestion := truncateTitle(base, maxTitleLength-len(suffix)) + suffix
+		if !taken[strings.ToLower(suggestion)] {
+			conflict.Suggestions = append(conflict.Suggestions, suggestion)
+		}
+	}
+
+	return conflict, nil
+}
+
+// truncateTitle cuts a title down to at most n characters.
+func truncateTitle(title string, n int) string {
+	runes := []rune(title)
+	if len(runes) <= n {
+		return title
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,180 @@
+// Package realtime pushes events to the open browser sessions of a user.
+// Events are published with Postgres NOTIFY and handed to the subscribers of every API instance,
+// so they reach the user whichever instance their stream is connected to.
+package realtime
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// channel is the Postgres notification channel events are published on, the payload is a JSON encoded message.
+const channel = "realtime_events"
+
+// bufferSize is how many events a subscriber can fall behind before further events are dropped for it.
+const bufferSize = 16
+
+// IRealtimeService defines the interface for pushing events to users.
+type IRealtimeService interface {
+	Publish(ctx context.Context, userID uuid.UUID, eventType string, payload interface{}) error
+	Subscribe(userID uuid.UUID) (<-chan data.RealtimeEvent, func())
+}
+
+// RealtimeService implements the IRealtimeService interface.
+type RealtimeService struct {
+	db          *sql.DB
+	mu          sync.Mutex
+	subscribers map[uuid.UUID]map[chan data.RealtimeEvent]struct{}
+	closed      bool
+}
+
+// message is what travels through Postgres, the event along with its recipient.
+type message struct {
+	UserID uuid.UUID          `json:"user_id"`
+	Event  data.RealtimeEvent `json:"event"`
+}
+
+// NewRealtimeService creates a new RealtimeService publishing through the provided database connection.
+// Events are only delivered while Listen runs.
+func NewRealtimeService(db *sql.DB) *RealtimeService {
+	return &RealtimeService{
+		db:          db,
+		subscribers: map[uuid.UUID]map[chan data.RealtimeEvent]struct{}{},
+	}
+}
+
+// Publish sends an event to every open session of the user. Users without an open session miss it,
+// events are hints for the editor rather than something to catch up on.
+// It returns ErrInvalidEvent if the event isn't part of the protocol or its payload doesn't match its schema.
+func (s *RealtimeService) Publish(ctx context.Context, userID uuid.UUID, eventType string, payload interface{}) error {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	event := data.RealtimeEvent{Type: eventType, Data: encoded}
+	if err := validate(event); err != nil {
+		return err
+	}
+
+	m, err := json.Marshal(message{
+		UserID: userID,
+		Event:  event,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, "SELECT pg_notify($1, $2)", channel, string(m))
+	return err
+}
+
+// Subscribe returns the events of the user and a function ending the subscription.
+// The channel is closed once the subscription ends or the service is closed.
+func (s *RealtimeService) Subscribe(userID uuid.UUID) (<-chan data.RealtimeEvent, func()) {
+	events := make(chan data.RealtimeEvent, bufferSize)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		close(events)
+		return events, func() {}
+	}
+	if s.subscribers[userID] == nil {
+		s.subscribers[userID] = map[chan data.RealtimeEvent]struct{}{}
+	}
+	s.subscribers[userID][events] = struct{}{}
+
+	return events, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if _, ok := s.subscribers[userID][events]; !ok {
+			return
+		}
+		delete(s.subscribers[userID], events)
+		if len(s.subscribers[userID]) == 0 {
+			delete(s.subscribers, userID)
+		}
+		close(events)
+	}
+}
+
+// deliver hands an event to the subscribers of the user on this instance.
+func (s *RealtimeService) deliver(userID uuid.UUID, event data.RealtimeEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for events := range s.subscribers[userID] {
+		select {
+		case events <- event:
+		default:
+			// a stalled stream must not hold up everyone else
+		}
+	}
+}
+
+// Close ends every subscription, so open streams finish and the server can shut down.
+func (s *RealtimeService) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for userID, subscribers := range s.subscribers {
+		for events := range subscribers {
+			close(events)
+		}
+		delete(s.subscribers, userID)
+	}
+}
+
+// Listen delivers the events published by every instance, including this one, until ctx is cancelled.
+// Events published while the connection was down are lost.
+func (s *RealtimeService) Listen(ctx context.Context, dsn string) error {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logging.FromContext(ctx).ErrorContext(ctx, "Realtime listener failed", "event", event, "error", err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(channel); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n := <-listener.Notify:
+			// nil after the connection was re-established
+			if n == nil {
+				continue
+			}
+			var m message
+			if err := json.Unmarshal([]byte(n.Extra), &m); err != nil {
+				logging.FromContext(ctx).WarnContext(ctx, "Realtime listener received an invalid message", "error", err)
+				continue
+			}
+			// instances running another version during a deploy may publish events this one doesn't know
+			if err := validate(m.Event); err != nil {
+				logging.FromContext(ctx).WarnContext(ctx, "Realtime listener received an unknown event", "error", err)
+				continue
+			}
+			s.deliver(m.UserID, m.Event)
+		case <-time.After(90 * time.Second):
+			// detects a silently dropped connection
+			go listener.Ping()
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package realtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+)
+
+// Versions of the realtime protocol the server speaks. A client names the newest version it speaks when connecting,
+// the stream then speaks the newest version both sides know, and only carries the events that exist in it.
+// An event whose payload changes incompatibly gets a new type in a new version, the old one is kept
+// for older clients until MinProtocol moves past it.
+const (
+	MinProtocol = 1
+	MaxProtocol = 1
+)
+
+var (
+	// ErrUnsupportedProtocol is returned when a client only speaks protocol versions the server dropped.
+	ErrUnsupportedProtocol = errors.New("unsupported realtime protocol")
+	// ErrInvalidEvent is returned when publishing an event of an unknown type or with a payload not matching its schema.
+	ErrInvalidEvent = errors.New("invalid realtime event")
+)
+
+// schema describes an event type of the protocol.
+type schema struct {
+	since int // the protocol version the event was introduced in
+	// validate checks an encoded payload
+	validate func(payload []byte) error
+}
+
+// schemas are the event types of the protocol. Payloads are decoded strictly into their type,
+// so a field that isn't part of the schema is rejected instead of reaching clients that don't expect it.
+var schemas = map[string]schema{
+	data.EventEditConflict: {since: 1, validate: shape(func(e data.EditConflict) error {
+		if e.ProjectID == uuid.Nil {
+			return errors.New("project_id is required")
+		}
+		if e.Version <= 0 {
+			return errors.New("version is required")
+		}
+		return nil
+	})},
+	data.EventNotification: {since: 1, validate: shape(func(n data.Notification) error {
+		if n.ID == 0 || n.Type == "" {
+			return errors.New("id and type are required")
+		}
+		return nil
+	})},
+}
+
+// shape returns a validator decoding a payload into T, without unknown fields, before checking it.
+func shape[T any](check func(T) error) func([]byte) error {
+	return func(payload []byte) error {
+		var v T
+		decoder := json.NewDecoder(bytes.NewReader(payload))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&v); err != nil {
+			return err
+		}
+		return check(v)
+	}
+}
+
+// validate checks that an event is part of the protocol and its payload matches its schema.
+func validate(event data.RealtimeEvent) error {
+	s, ok := schemas[event.Type]
+	if !ok {
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidEvent, event.Type)
+	}
+	if err := s.validate(event.Data); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidEvent, event.Type, err)
+	}
+	return nil
+}
+
+// Negotiate returns the protocol version of a stream from the newest version the client speaks.
+// Clients that don't name one speak the first version.
+func Negotiate(requested string) (int, error) {
+	version := MinProtocol
+	if requested != "" {
+		var err error
+		if version, err = strconv.Atoi(requested); err != nil {
+			return 0, ErrUnsupportedProtocol
+		}
+	}
+
+	if version < MinProtocol {
+		return 0, ErrUnsupportedProtocol
+	}
+	return min(version, MaxProtocol), nil
+}
+
+// Supports reports whether an event type exists in a protocol version.
+func Supports(version int, eventType string) bool {
+	s, ok := schemas[eventType]
+	return ok && s.since <= version
+}
+
+// EventTypes returns the event types of a protocol version.
+func EventTypes(version int) []string {
+	types := []string{}
+	for eventType := range schemas {
+		if Supports(version, eventType) {
+			types = append(types, eventType)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
@@ -0,0 +1,215 @@
+// Package renders provides the queue of thumbnails rendered by render worker processes, and the workers processing it.
+package renders
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+)
+
+// IRenderService defines the interface for render queue operations of the API.
+type IRenderService interface {
+	GetStatus(ctx context.Context) (*data.RenderStatus, error)
+}
+
+// RenderService implements the IRenderService interface, along with the queue operations of render workers.
+type RenderService struct {
+	db *sql.DB
+}
+
+// NewRenderService creates a new RenderService with the provided database connection.
+func NewRenderService(db *sql.DB) RenderService {
+	return RenderService{
+		db: db,
+	}
+}
+
+// GetStatus retrieves the render queue and the registered workers, the longest running first.
+func (s RenderService) GetStatus(ctx context.Context) (*data.RenderStatus, error) {
+	stats, err := s.QueueStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, hostname, concurrency, busy, jobs_done, jobs_failed, started_at, heartbeat_at
+		FROM render_workers
+		ORDER BY started_at, id`
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	status := data.RenderStatus{Queue: *stats, Workers: make([]data.RenderWorker, 0)}
+	for rows.Next() {
+		var w data.RenderWorker
+		if err := rows.Scan(&w.ID, &w.Hostname, &w.Concurrency, &w.Busy, &w.JobsDone, &w.JobsFailed, &w.StartedAt, &w.HeartbeatAt); err != nil {
+			return nil, err
+		}
+		status.Workers = append(status.Workers, w)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+// QueueStats counts the jobs waiting for and being rendered by workers.
+func (s RenderService) QueueStats(ctx context.Context) (*data.RenderQueueStats, error) {
+	var stats data.RenderQueueStats
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE status = 'pending'),
+			COUNT(*) FILTER (WHERE status = 'running'),
+			COALESCE(EXTRACT(EPOCH FROM NOW() - MIN(enqueued_at) FILTER (WHERE status = 'pending')), 0)::float8
+		FROM render_jobs`
+	if err := s.db.QueryRowContext(ctx, query).Scan(&stats.Pending, &stats.Running, &stats.OldestPending); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// Register adds a worker to the registered workers, or refreshes it if it registered already.
+func (s RenderService) Register(ctx context.Context, w data.RenderWorker) error {
+	query := `
+		INSERT INTO render_workers (id, hostname, concurrency)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET hostname = EXCLUDED.hostname, concurrency = EXCLUDED.concurrency, heartbeat_at = NOW()`
+	_, err := s.db.ExecContext(ctx, query, w.ID, w.Hostname, w.Concurrency)
+	return err
+}
+
+// Heartbeat records that a worker is alive along with its load and counters.
+// A worker removed by its peers meanwhile registers again.
+func (s RenderService) Heartbeat(ctx context.Context, w data.RenderWorker) error {
+	query := `
+		INSERT INTO render_workers (id, hostname, concurrency, busy, jobs_done, jobs_failed)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id) DO UPDATE SET
+			busy = EXCLUDED.busy,
+			jobs_done = EXCLUDED.jobs_done,
+			jobs_failed = EXCLUDED.jobs_failed,
+			heartbeat_at = NOW()`
+	_, err := s.db.ExecContext(ctx, query, w.ID, w.Hostname, w.Concurrency, w.Busy, w.JobsDone, w.JobsFailed)
+	return err
+}
+
+// Deregister removes a worker which is shutting down and puts the jobs it was still running back in the queue.
+func (s RenderService) Deregister(ctx context.Context, workerID string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "UPDATE render_jobs SET status = 'pending', worker_id = NULL, claimed_at = NULL WHERE status = 'running' AND worker_id = $1"
+	if _, err := tx.ExecContext(ctx, query, workerID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM render_workers WHERE id = $1", workerID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Claim hands the oldest pending job to a worker, or returns nil if nothing is pending.
+// Jobs locked by a worker claiming concurrently are skipped rather than waited for.
+func (s RenderService) Claim(ctx context.Context, workerID string) (*data.RenderJob, error) {
+	var job data.RenderJob
+	query := `
+		UPDATE render_jobs
+		SET status = 'running', worker_id = $1, claimed_at = NOW(), attempts = attempts + 1
+		WHERE id = (
+			SELECT id FROM render_jobs
+			WHERE status = 'pending'
+			ORDER BY enqueued_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, project_id, format, version, attempts`
+	err := s.db.QueryRowContext(ctx, query, workerID).Scan(&job.ID, &job.ProjectID, &job.Format, &job.Version, &job.Attempts)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Complete removes a job once the worker rendered the version of the project it was claimed at.
+// If a newer version was asked for meanwhile, the job goes back in the queue for it instead.
+func (s RenderService) Complete(ctx context.Context, job *data.RenderJob, version int) error {
+	query := "DELETE FROM render_jobs WHERE id = $1 AND version <= $2"
+	result, err := s.db.ExecContext(ctx, query, job.ID, version)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+
+	query = "UPDATE render_jobs SET status = 'pending', worker_id = NULL, claimed_at = NULL, attempts = 0 WHERE id = $1"
+	_, err = s.db.ExecContext(ctx, query, job.ID)
+	return err
+}
+
+// Release puts a job the worker couldn't render back in the queue, to be retried by any worker.
+func (s RenderService) Release(ctx context.Context, job *data.RenderJob) error {
+	query := "UPDATE render_jobs SET status = 'pending', worker_id = NULL, claimed_at = NULL WHERE id = $1"
+	_, err := s.db.ExecContext(ctx, query, job.ID)
+	return err
+}
+
+// RequeueStale removes the workers which missed their heartbeats for longer than timeout and puts the jobs they were
+// running back in the queue. Pending jobs claimed maxAttempts times already are given up on: their program is recorded
+// as exceeding the rendering limits, as it most likely took down every worker it was handed to.
+// It returns the number of jobs taken back from removed workers.
+func (s RenderService) RequeueStale(ctx context.Context, timeout time.Duration, maxAttempts int) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := "DELETE FROM render_workers WHERE heartbeat_at < NOW() - make_interval(secs => $1)"
+	if _, err := tx.ExecContext(ctx, query, timeout.Seconds()); err != nil {
+		return 0, err
+	}
+
+	query = `
+		UPDATE render_jobs j
+		SET status = 'pending', worker_id = NULL, claimed_at = NULL
+		WHERE status = 'running' AND NOT EXISTS (SELECT 1 FROM render_workers w WHERE w.id = j.worker_id)`
+	result, err := tx.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	requeued, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	query = `
+		WITH abandoned AS (
+			DELETE FROM render_jobs
+			WHERE status = 'pending' AND attempts >= $1
+			RETURNING project_id, format, version
+		)
+		INSERT INTO project_thumbnails (project_id, format, version, error)
+		SELECT project_id, format, version, 'render: worker failed' FROM abandoned
+		ON CONFLICT (project_id, format) DO UPDATE
+		SET version = EXCLUDED.version, content = NULL, error = EXCLUDED.error, rendered_at = NOW()
+		WHERE project_thumbnails.version <= EXCLUDED.version`
+	if _, err := tx.ExecContext(ctx, query, maxAttempts); err != nil {
+		return 0, err
+	}
+
+	return int(requeued), tx.Commit()
+}
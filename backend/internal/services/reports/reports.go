@@ -0,0 +1,187 @@
+// Package reports handles the reports users file against projects and their review by moderators.
+package reports
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// IReportService defines the interface for project report and moderation operations.
+type IReportService interface {
+	ReportProject(ctx context.Context, projectID, reporterID uuid.UUID, submission data.ProjectReportSubmission) (*data.ProjectReport, error)
+	GetReport(ctx context.Context, reportID int64) (*data.ProjectReport, error)
+	ListOpenReports(ctx context.Context) ([]data.ProjectReport, error)
+	DismissReport(ctx context.Context, reportID int64, moderatorID uuid.UUID) (*data.ProjectReport, error)
+	TakeDownProject(ctx context.Context, projectID, moderatorID uuid.UUID, reason string) (*data.ProjectTakedown, error)
+}
+
+// ReportService implements the IReportService interface.
+type ReportService struct {
+	db *sql.DB
+}
+
+// NewReportService creates a new ReportService with the provided database connection.
+func NewReportService(db *sql.DB) ReportService {
+	return ReportService{
+		db: db,
+	}
+}
+
+const selectReport = `
+	SELECT r.id, r.project_id, p.title, r.reporter_id, u.username, r.reason, r.details, r.status, r.resolved_by, r.resolved_at, r.created_at
+	FROM project_reports r
+	JOIN projects p ON r.project_id = p.id
+	JOIN users u ON r.reporter_id = u.id`
+
+// ReportProject files a report against a project. Visibility of the project is not checked here,
+// callers are expected to load it with GetProject first.
+// It returns ErrAlreadyReported if the user's earlier report of the project is still open.
+func (s ReportService) ReportProject(ctx context.Context, projectID, reporterID uuid.UUID, submission data.ProjectReportSubmission) (*data.ProjectReport, error) {
+	var reportID int64
+	err := s.db.QueryRowContext(ctx,
+		"INSERT INTO project_reports (project_id, reporter_id, reason, details) VALUES ($1, $2, $3, $4) RETURNING id",
+		projectID, reporterID, submission.Reason, strings.TrimSpace(submission.Details),
+	).Scan(&reportID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return nil, services.ErrAlreadyReported
+		}
+		return nil, err
+	}
+
+	return s.GetReport(ctx, reportID)
+}
+
+// GetReport retrieves a report by its ID.
+// It returns ErrRecordNotFound if the report doesn't exist.
+func (s ReportService) GetReport(ctx context.Context, reportID int64) (*data.ProjectReport, error) {
+	return scanReport(s.db.QueryRowContext(ctx, selectReport+" WHERE r.id = $1", reportID))
+}
+
+// ListOpenReports retrieves the reports waiting for review, oldest first.
+func (s ReportService) ListOpenReports(ctx context.Context) ([]data.ProjectReport, error) {
+	rows, err := s.db.QueryContext(ctx, selectReport+" WHERE r.status = $1 ORDER BY r.created_at", data.ReportOpen)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := []data.ProjectReport{}
+	for rows.Next() {
+		r, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, *r)
+	}
+
+	return reports, rows.Err()
+}
+
+// DismissReport closes an open report without acting on the project.
+// It returns ErrRecordNotFound if the report doesn't exist or was resolved already.
+func (s ReportService) DismissReport(ctx context.Context, reportID int64, moderatorID uuid.UUID) (*data.ProjectReport, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE project_reports
+		SET status = $2, resolved_by = $3, resolved_at = NOW()
+		WHERE id = $1 AND status = 'open'`,
+		reportID, data.ReportDismissed, moderatorID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, services.ErrRecordNotFound
+	}
+
+	return s.GetReport(ctx, reportID)
+}
+
+// TakeDownProject hides a project from everyone but its creator, ends its time on the featured list,
+// and resolves its open reports as actioned. Unlike a private project, the creator can't make it visible again.
+// It returns ErrRecordNotFound if the project doesn't exist and ErrAlreadyTakenDown if it was taken down before.
+func (s ReportService) TakeDownProject(ctx context.Context, projectID, moderatorID uuid.UUID, reason string) (*data.ProjectTakedown, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	takedown := data.ProjectTakedown{ProjectID: projectID, Reason: strings.TrimSpace(reason)}
+	var hiddenAt sql.NullTime
+	err = tx.QueryRowContext(ctx, `
+		SELECT p.title, p.hidden_at, u.username, u.email
+		FROM projects p
+		JOIN users u ON p.creator_id = u.id
+		WHERE p.id = $1
+		FOR UPDATE OF p`,
+		projectID,
+	).Scan(&takedown.Title, &hiddenAt, &takedown.CreatorUsername, &takedown.CreatorEmail)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	if hiddenAt.Valid {
+		return nil, services.ErrAlreadyTakenDown
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE projects
+		SET hidden_at = NOW(), hidden_reason = $2, hidden_by = $3,
+		    featured_until = CASE WHEN featured_until > NOW() THEN NOW() ELSE featured_until END, featured_pinned = FALSE
+		WHERE id = $1
+		RETURNING hidden_at`,
+		projectID, takedown.Reason, moderatorID,
+	).Scan(&takedown.HiddenAt)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE project_reports
+		SET status = $2, resolved_by = $3, resolved_at = NOW()
+		WHERE project_id = $1 AND status = 'open'`,
+		projectID, data.ReportActioned, moderatorID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	takedown.ReportsResolved = int(resolved)
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &takedown, nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanReport(row scanner) (*data.ProjectReport, error) {
+	var r data.ProjectReport
+	err := row.Scan(&r.ID, &r.ProjectID, &r.ProjectTitle, &r.ReporterID, &r.ReporterUsername, &r.Reason, &r.Details, &r.Status, &r.ResolvedBy, &r.ResolvedAt, &r.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &r, nil
+}
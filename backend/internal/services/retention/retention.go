@@ -0,0 +1,256 @@
+// Package retention enforces how long each class of data is kept and records every purge.
+package retention
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+
+	"github.com/google/uuid"
+)
+
+// IRetentionService defines the interface for data retention operations.
+type IRetentionService interface {
+	Classes(ctx context.Context) ([]data.RetentionClass, error)
+	Purge(ctx context.Context, trigger data.RetentionTrigger, actorID *uuid.UUID) ([]data.RetentionRun, error)
+	ListRuns(ctx context.Context, class string, limit int) ([]data.RetentionRun, error)
+}
+
+// RetentionService implements the IRetentionService interface.
+type RetentionService struct {
+	db         *sql.DB
+	cfg        config.RetentionConfig
+	partitions config.PartitionsConfig
+}
+
+// NewRetentionService creates a new RetentionService with the provided database connection and retention periods.
+// The partitions config is only read to report the retention of the partitioned tables.
+func NewRetentionService(db *sql.DB, cfg config.RetentionConfig, partitions config.PartitionsConfig) RetentionService {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	return RetentionService{
+		db:         db,
+		cfg:        cfg,
+		partitions: partitions,
+	}
+}
+
+const defaultBatchSize = 1000
+
+// purgeable is a data class whose expired rows are deleted by Purge.
+type purgeable struct {
+	name        string
+	description string
+	days        int
+	// queries delete a batch of rows older than $1, at most $2 of them, and select how many were deleted
+	queries []string
+}
+
+func (s RetentionService) purgeable() []purgeable {
+	return []purgeable{
+		{
+			name:        data.RetentionLoginHistory,
+			description: "Countries users logged in from and the login codes sent for new ones",
+			days:        s.cfg.LoginHistory,
+			queries: []string{
+				`WITH deleted AS (
+					DELETE FROM trusted_locations WHERE ctid IN (SELECT ctid FROM trusted_locations WHERE last_seen_at < $1 LIMIT $2)
+					RETURNING 1
+				) SELECT COUNT(*) FROM deleted`,
+				`WITH deleted AS (
+					DELETE FROM login_challenges WHERE id IN (SELECT id FROM login_challenges WHERE expires_at < $1 LIMIT $2)
+					RETURNING 1
+				) SELECT COUNT(*) FROM deleted`,
+			},
+		},
+		{
+			name:        data.RetentionProjectViews,
+			description: "Daily records of who viewed a project, used to count views and rank trending projects",
+			days:        s.cfg.ProjectViews,
+			queries: []string{
+				`WITH deleted AS (
+					DELETE FROM project_views WHERE ctid IN (SELECT ctid FROM project_views WHERE viewed_on < $1::date LIMIT $2)
+					RETURNING 1
+				) SELECT COUNT(*) FROM deleted`,
+			},
+		},
+		{
+			name:        data.RetentionTakenDownProjects,
+			description: "Projects hidden by moderators, counted from the takedown",
+			days:        s.cfg.TakenDownProjects,
+			queries: []string{
+				// same as deleting them one by one, the projects they were forked from lose a fork
+				`WITH deleted AS (
+					DELETE FROM projects WHERE id IN (SELECT id FROM projects WHERE hidden_at < $1 ORDER BY hidden_at LIMIT $2)
+					RETURNING id, forked_from
+				), forks AS (
+					SELECT forked_from, COUNT(*) AS n FROM deleted WHERE forked_from IS NOT NULL GROUP BY forked_from
+				), counted AS (
+					UPDATE projects p SET fork_count = GREATEST(0, p.fork_count - f.n)
+					FROM forks f
+					WHERE p.id = f.forked_from AND p.id NOT IN (SELECT id FROM deleted)
+				) SELECT COUNT(*) FROM deleted`,
+			},
+		},
+		{
+			name:        data.RetentionEmails,
+			description: "Emails sent to users with the request that triggered them, looked up by support",
+			days:        s.cfg.Emails,
+			queries: []string{
+				`WITH deleted AS (
+					DELETE FROM email_outbox WHERE id IN (SELECT id FROM email_outbox WHERE created_at < $1 LIMIT $2)
+					RETURNING 1
+				) SELECT COUNT(*) FROM deleted`,
+			},
+		},
+	}
+}
+
+// Classes lists every data class with its retention period and, for the purged ones, the latest purge.
+func (s RetentionService) Classes(ctx context.Context) ([]data.RetentionClass, error) {
+	lastRuns := map[string]*data.RetentionRun{}
+	rows, err := s.db.QueryContext(ctx, selectRun+" WHERE id IN (SELECT DISTINCT ON (class) id FROM retention_runs ORDER BY class, started_at DESC)")
+	if err != nil {
+		return nil, err
+	}
+	runs, err := scanRuns(rows)
+	if err != nil {
+		return nil, err
+	}
+	for i := range runs {
+		lastRuns[runs[i].Class] = &runs[i]
+	}
+
+	classes := []data.RetentionClass{}
+	for _, p := range s.purgeable() {
+		classes = append(classes, data.RetentionClass{
+			Name:        p.name,
+			Description: p.description,
+			Method:      data.RetentionPurge,
+			Days:        p.days,
+			LastRun:     lastRuns[p.name],
+		})
+	}
+
+	classes = append(classes,
+		data.RetentionClass{
+			Name:        data.RetentionAnalyticsEvents,
+			Description: "Events recorded for analytics",
+			Method:      data.RetentionPartitions,
+			Months:      s.partitions.EventsRetention,
+		},
+		data.RetentionClass{
+			Name:        data.RetentionAuditLogs,
+			Description: "Privileged actions recorded in the audit log",
+			Method:      data.RetentionPartitions,
+			Months:      s.partitions.AuditRetention,
+		},
+		data.RetentionClass{
+			Name:        data.RetentionNotifications,
+			Description: "Notifications shown to users",
+			Method:      data.RetentionPartitions,
+			Months:      s.partitions.NotificationsRetention,
+		},
+	)
+
+	return classes, nil
+}
+
+// Purge deletes the data of every purged class that is older than its retention period and records a run per class.
+// Classes kept forever are skipped. A class failing doesn't stop the others, its run records the error
+// and the errors are returned along with the runs.
+func (s RetentionService) Purge(ctx context.Context, trigger data.RetentionTrigger, actorID *uuid.UUID) ([]data.RetentionRun, error) {
+	runs := []data.RetentionRun{}
+	var errs []error
+
+	for _, p := range s.purgeable() {
+		if p.days <= 0 {
+			continue
+		}
+
+		run := data.RetentionRun{
+			Class:     p.name,
+			Trigger:   trigger,
+			ActorID:   actorID,
+			StartedAt: time.Now().UTC(),
+		}
+		run.Cutoff = run.StartedAt.AddDate(0, 0, -p.days)
+
+		for _, query := range p.queries {
+			deleted, err := s.deleteInBatches(ctx, query, run.Cutoff)
+			run.Deleted += deleted
+			if err != nil {
+				run.Error = err.Error()
+				errs = append(errs, fmt.Errorf("could not purge %s: %w", p.name, err))
+				break
+			}
+		}
+
+		if err := s.recordRun(ctx, &run); err != nil {
+			return runs, errors.Join(append(errs, err)...)
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, errors.Join(errs...)
+}
+
+// ListRuns retrieves the latest purges, newest first, optionally of a single class.
+func (s RetentionService) ListRuns(ctx context.Context, class string, limit int) ([]data.RetentionRun, error) {
+	rows, err := s.db.QueryContext(ctx, selectRun+" WHERE ($1 = '' OR class = $1) ORDER BY started_at DESC, id DESC LIMIT $2", class, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanRuns(rows)
+}
+
+// deleteInBatches runs the delete query until a batch comes back short, so no single statement holds locks for long.
+func (s RetentionService) deleteInBatches(ctx context.Context, query string, cutoff time.Time) (int64, error) {
+	var total int64
+	for {
+		var deleted int64
+		if err := s.db.QueryRowContext(ctx, query, cutoff, s.cfg.BatchSize).Scan(&deleted); err != nil {
+			return total, err
+		}
+		total += deleted
+
+		if deleted < int64(s.cfg.BatchSize) {
+			return total, nil
+		}
+	}
+}
+
+func (s RetentionService) recordRun(ctx context.Context, run *data.RetentionRun) error {
+	query := `
+		INSERT INTO retention_runs (class, cutoff, deleted, trigger, actor_id, error, started_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, finished_at`
+
+	// recorded even when the purge was cancelled half way
+	return s.db.QueryRowContext(context.WithoutCancel(ctx), query,
+		run.Class, run.Cutoff, run.Deleted, run.Trigger, run.ActorID, run.Error, run.StartedAt,
+	).Scan(&run.ID, &run.FinishedAt)
+}
+
+const selectRun = "SELECT id, class, cutoff, deleted, trigger, actor_id, error, started_at, finished_at FROM retention_runs"
+
+func scanRuns(rows *sql.Rows) ([]data.RetentionRun, error) {
+	defer rows.Close()
+
+	runs := []data.RetentionRun{}
+	for rows.Next() {
+		var r data.RetentionRun
+		if err := rows.Scan(&r.ID, &r.Class, &r.Cutoff, &r.Deleted, &r.Trigger, &r.ActorID, &r.Error, &r.StartedAt, &r.FinishedAt); err != nil {
+			return nil, err
+		}
+		runs = append(runs, r)
+	}
+
+	return runs, rows.Err()
+}
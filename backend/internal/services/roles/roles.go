@@ -1 +1,70 @@
+// Package roles provides the permissions granted to user roles.
 package roles
+
+import (
+	"database/sql"
+
+	"NodeTurtleAPI/internal/data"
+)
+
+// IRoleService defines the interface for role permission lookups.
+type IRoleService interface {
+	HasPermission(roleID int64, permission data.Permission) (bool, error)
+	GetPermissions(roleID int64) ([]data.Permission, error)
+}
+
+// RoleService implements the IRoleService interface.
+type RoleService struct {
+	db *sql.DB
+}
+
+// NewRoleService creates a new RoleService with the provided database connection.
+func NewRoleService(db *sql.DB) RoleService {
+	return RoleService{
+		db: db,
+	}
+}
+
+// HasPermission checks whether the role has been granted the permission.
+func (s RoleService) HasPermission(roleID int64, permission data.Permission) (bool, error) {
+	var granted bool
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM role_permissions rp
+			JOIN permissions p ON p.id = rp.permission_id
+			WHERE rp.role_id = $1 AND p.name = $2
+		)`
+
+	err := s.db.QueryRow(query, roleID, permission).Scan(&granted)
+	return granted, err
+}
+
+// GetPermissions retrieves all the permissions granted to the role, sorted by name.
+func (s RoleService) GetPermissions(roleID int64) ([]data.Permission, error) {
+	query := `
+		SELECT p.name FROM role_permissions rp
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE rp.role_id = $1
+		ORDER BY p.name`
+
+	rows, err := s.db.Query(query, roleID)
+	if err != nil {
+		return []data.Permission{}, err
+	}
+	defer rows.Close()
+
+	permissions := make([]data.Permission, 0)
+	for rows.Next() {
+		var permission data.Permission
+		if err := rows.Scan(&permission); err != nil {
+			return []data.Permission{}, err
+		}
+		permissions = append(permissions, permission)
+	}
+
+	if err = rows.Err(); err != nil {
+		return []data.Permission{}, err
+	}
+
+	return permissions, nil
+}
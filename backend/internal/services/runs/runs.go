@@ -0,0 +1,129 @@
+// Package runs executes turtle programs on the server within the budgets of the user's role,
+// and meters the compute every user's runs take per day.
+package runs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/render"
+	"NodeTurtleAPI/internal/services"
+	"NodeTurtleAPI/internal/services/projects"
+
+	"github.com/google/uuid"
+)
+
+// IRunService defines the interface for server-side program runs.
+type IRunService interface {
+	Run(ctx context.Context, userID uuid.UUID, flow json.RawMessage) (*data.ProjectRun, error)
+}
+
+// RunService implements the IRunService interface.
+type RunService struct {
+	db     *sql.DB
+	quotas config.QuotasConfig
+	limits config.RenderConfig
+}
+
+// NewRunService creates a new RunService with the provided database connection, the quotas of the roles
+// and the render limits, which bound the runs of every role.
+func NewRunService(db *sql.DB, quotas config.QuotasConfig, limits config.RenderConfig) RunService {
+	return RunService{
+		db:     db,
+		quotas: quotas,
+		limits: limits,
+	}
+}
+
+// today is the day compute is metered on.
+const today = "(NOW() AT TIME ZONE 'UTC')::date"
+
+// Run executes a program for a user and meters the instructions it took, also when it fails on a limit.
+// A run is never allowed more instructions than the user has left for the day, though concurrent runs
+// may each take what's left.
+//
+// It returns ErrUserNotFound if the user doesn't exist, a QuotaError if the user's compute for the day is used up,
+// a RunLimitError if the program exceeds a budget of a single run, and ErrInvalidData if the flow can't be read.
+func (s RunService) Run(ctx context.Context, userID uuid.UUID, flow json.RawMessage) (*data.ProjectRun, error) {
+	var role data.RoleType
+	var used int
+	err := s.db.QueryRowContext(ctx,
+		"SELECT role_id, COALESCE((SELECT steps FROM compute_usage WHERE user_id = $1 AND day = "+today+"), 0) FROM users WHERE id = $1",
+		userID,
+	).Scan(&role, &used)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	quota := projects.QuotaFor(s.quotas, role)
+	if quota.MaxComputeDaily > 0 && used >= quota.MaxComputeDaily {
+		return nil, &services.QuotaError{Quota: services.QuotaCompute, Limit: quota.MaxComputeDaily, Used: used}
+	}
+
+	limits := render.Limits{
+		MaxInstructions: s.limits.MaxInstructions,
+		Timeout:         s.limits.Timeout,
+		MaxSegments:     quota.MaxRunSegments,
+	}
+	if quota.MaxRunSteps > 0 && quota.MaxRunSteps < limits.MaxInstructions {
+		limits.MaxInstructions = quota.MaxRunSteps
+	}
+	if timeout := time.Duration(quota.MaxRunMillis) * time.Millisecond; timeout > 0 && (limits.Timeout == 0 || timeout < limits.Timeout) {
+		limits.Timeout = timeout
+	}
+	boundByDay := false
+	if left := quota.MaxComputeDaily - used; quota.MaxComputeDaily > 0 && left < limits.MaxInstructions {
+		limits.MaxInstructions = left
+		boundByDay = true
+	}
+
+	start := time.Now()
+	drawing, usage, runErr := render.Run(ctx, flow, limits)
+	elapsed := time.Since(start)
+
+	// metered even when the client went away mid-run
+	err = s.db.QueryRowContext(context.WithoutCancel(ctx), `
+		INSERT INTO compute_usage (user_id, day, runs, steps, millis)
+		VALUES ($1, `+today+`, 1, $2, $3)
+		ON CONFLICT (user_id, day) DO UPDATE
+		SET runs = compute_usage.runs + 1, steps = compute_usage.steps + EXCLUDED.steps, millis = compute_usage.millis + EXCLUDED.millis
+		RETURNING steps`,
+		userID, usage.Steps, elapsed.Milliseconds(),
+	).Scan(&used)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case errors.Is(runErr, render.ErrInstructionLimit) && boundByDay:
+		return nil, &services.QuotaError{Quota: services.QuotaCompute, Limit: quota.MaxComputeDaily, Used: used}
+	case errors.Is(runErr, render.ErrInstructionLimit):
+		return nil, &services.RunLimitError{Budget: services.RunSteps, Limit: limits.MaxInstructions}
+	case errors.Is(runErr, render.ErrTimeLimit):
+		return nil, &services.RunLimitError{Budget: services.RunTime, Limit: int(limits.Timeout.Milliseconds())}
+	case errors.Is(runErr, render.ErrMemoryLimit):
+		return nil, &services.RunLimitError{Budget: services.RunSegments, Limit: limits.MaxSegments}
+	case runErr != nil:
+		return nil, services.ErrInvalidData
+	}
+
+	run := &data.ProjectRun{
+		Segments:        make([]data.RunSegment, len(drawing.Segments)),
+		Steps:           usage.Steps,
+		Millis:          int(elapsed.Milliseconds()),
+		ComputeToday:    used,
+		MaxComputeDaily: quota.MaxComputeDaily,
+	}
+	for i, seg := range drawing.Segments {
+		run.Segments[i] = data.RunSegment{X1: seg.X1, Y1: seg.Y1, X2: seg.X2, Y2: seg.Y2, Color: seg.Hex()}
+	}
+	return run, nil
+}
@@ -0,0 +1,159 @@
+// Package signups keeps the allowlist of subnets and email domains exempt from the signup velocity limits.
+package signups
+
+import (
+	"database/sql"
+	"net"
+	"strings"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ISignupService defines the interface for signup allowlist operations.
+type ISignupService interface {
+	IsAllowlisted(ip, domain string) (bool, error)
+	ListAllowlist() ([]data.SignupAllowlistEntry, error)
+	AddToAllowlist(entry data.SignupAllowlistCreate, createdBy uuid.UUID) (*data.SignupAllowlistEntry, error)
+	GetAllowlistEntry(id int64) (*data.SignupAllowlistEntry, error)
+	RemoveFromAllowlist(id int64) error
+}
+
+// SignupService implements the ISignupService interface.
+type SignupService struct {
+	db *sql.DB
+}
+
+// NewSignupService creates a new SignupService with the provided database connection.
+func NewSignupService(db *sql.DB) SignupService {
+	return SignupService{
+		db: db,
+	}
+}
+
+// IsAllowlisted reports whether the IP lies in an allowlisted subnet or the email domain is allowlisted.
+// An empty domain only matches subnets.
+func (s SignupService) IsAllowlisted(ip, domain string) (bool, error) {
+	var allowlisted bool
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM signup_allowlist
+			WHERE subnet >>= $1::inet OR (domain = $2 AND $2 <> '')
+		)`
+	err := s.db.QueryRow(query, ip, domain).Scan(&allowlisted)
+	return allowlisted, err
+}
+
+// ListAllowlist returns all allowlist entries, newest first.
+func (s SignupService) ListAllowlist() ([]data.SignupAllowlistEntry, error) {
+	rows, err := s.db.Query("SELECT id, subnet, domain, note, created_by, created_at FROM signup_allowlist ORDER BY created_at DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]data.SignupAllowlistEntry, 0)
+	for rows.Next() {
+		var e data.SignupAllowlistEntry
+		if err := rows.Scan(&e.ID, &e.Subnet, &e.Domain, &e.Note, &e.CreatedBy, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// AddToAllowlist adds a subnet or an email domain to the allowlist, a single IP is stored as a subnet of one address.
+// It returns ErrAlreadyAllowlisted if the subnet or domain is on the list already.
+func (s SignupService) AddToAllowlist(entry data.SignupAllowlistCreate, createdBy uuid.UUID) (*data.SignupAllowlistEntry, error) {
+	var subnet, domain *string
+	if entry.Subnet != "" {
+		network, err := normalizeSubnet(entry.Subnet)
+		if err != nil {
+			return nil, err
+		}
+		subnet = &network
+	} else {
+		d := strings.ToLower(entry.Domain)
+		domain = &d
+	}
+
+	var e data.SignupAllowlistEntry
+	query := `
+		INSERT INTO signup_allowlist (subnet, domain, note, created_by)
+		VALUES ($1::cidr, $2, $3, $4)
+		RETURNING id, subnet, domain, note, created_by, created_at`
+	err := s.db.QueryRow(query, subnet, domain, strings.TrimSpace(entry.Note), createdBy).Scan(
+		&e.ID, &e.Subnet, &e.Domain, &e.Note, &e.CreatedBy, &e.CreatedAt,
+	)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return nil, services.ErrAlreadyAllowlisted
+		}
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+// GetAllowlistEntry retrieves a single allowlist entry.
+// It returns ErrRecordNotFound if the entry doesn't exist.
+func (s SignupService) GetAllowlistEntry(id int64) (*data.SignupAllowlistEntry, error) {
+	var e data.SignupAllowlistEntry
+	err := s.db.QueryRow(
+		"SELECT id, subnet, domain, note, created_by, created_at FROM signup_allowlist WHERE id = $1", id,
+	).Scan(&e.ID, &e.Subnet, &e.Domain, &e.Note, &e.CreatedBy, &e.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+// RemoveFromAllowlist removes an entry from the allowlist.
+// It returns ErrRecordNotFound if the entry doesn't exist.
+func (s SignupService) RemoveFromAllowlist(id int64) error {
+	result, err := s.db.Exec("DELETE FROM signup_allowlist WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return services.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// normalizeSubnet turns an IP into a subnet of one address and clears the host bits of a subnet,
+// so 10.0.0.5/24 is stored as 10.0.0.0/24.
+func normalizeSubnet(subnet string) (string, error) {
+	if ip := net.ParseIP(subnet); ip != nil {
+		bits := 128
+		if ip.To4() != nil {
+			bits = 32
+		}
+		return (&net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}).String(), nil
+	}
+
+	_, network, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return "", services.ErrInvalidData
+	}
+	return network.String(), nil
+}
@@ -0,0 +1,181 @@
+// Package system reports on the health of the database the API runs on.
+package system
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+)
+
+// ISystemService defines the interface for database health operations.
+type ISystemService interface {
+	DBHealth() (*data.DBHealth, error)
+	Ping(ctx context.Context) error
+}
+
+// SystemService implements the ISystemService interface.
+type SystemService struct {
+	db *sql.DB
+}
+
+// NewSystemService creates a new SystemService with the provided database connection.
+func NewSystemService(db *sql.DB) SystemService {
+	return SystemService{
+		db: db,
+	}
+}
+
+// Ping checks that the database answers, the cheapest check of whether the API can serve requests.
+func (s SystemService) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// DBHealth collects table sizes, index usage, connection usage and replication lag.
+// Replicas are only listed when connected to the primary, a replica reports its own lag instead.
+func (s SystemService) DBHealth() (*data.DBHealth, error) {
+	health := data.DBHealth{CollectedAt: time.Now()}
+
+	stats := s.db.Stats()
+	health.Pool = data.PoolStats{
+		MaxOpen:          stats.MaxOpenConnections,
+		Open:             stats.OpenConnections,
+		InUse:            stats.InUse,
+		Idle:             stats.Idle,
+		WaitCount:        stats.WaitCount,
+		WaitMilliseconds: stats.WaitDuration.Milliseconds(),
+	}
+
+	query := `
+		SELECT
+			pg_database_size(current_database()),
+			(SELECT COUNT(*) FROM pg_stat_activity WHERE backend_type = 'client backend'),
+			current_setting('max_connections')::int,
+			pg_is_in_recovery(),
+			EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())::float8`
+	err := s.db.QueryRow(query).Scan(
+		&health.DatabaseBytes, &health.Pool.ServerConnections, &health.Pool.MaxConnections, &health.InRecovery, &health.ReplayLag,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if health.Pool.MaxConnections > 0 {
+		health.Pool.Utilization = float64(health.Pool.ServerConnections) / float64(health.Pool.MaxConnections)
+	}
+	if !health.InRecovery {
+		health.ReplayLag = nil
+	}
+
+	if health.Tables, err = s.tables(); err != nil {
+		return nil, err
+	}
+	if health.Indexes, err = s.indexes(); err != nil {
+		return nil, err
+	}
+
+	health.Replicas = make([]data.ReplicaStatus, 0)
+	if !health.InRecovery {
+		if health.Replicas, err = s.replicas(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &health, nil
+}
+
+// tables returns the user tables, largest first.
+func (s SystemService) tables() ([]data.TableHealth, error) {
+	query := `
+		SELECT relname, n_live_tup, n_dead_tup,
+			pg_total_relation_size(relid), pg_relation_size(relid), pg_indexes_size(relid),
+			last_vacuum, last_autovacuum, last_analyze
+		FROM pg_stat_user_tables
+		ORDER BY pg_total_relation_size(relid) DESC, relname`
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := make([]data.TableHealth, 0)
+	for rows.Next() {
+		var t data.TableHealth
+		if err := rows.Scan(
+			&t.Name, &t.Rows, &t.DeadRows, &t.TotalBytes, &t.TableBytes, &t.IndexBytes,
+			&t.LastVacuum, &t.LastAutovacuum, &t.LastAnalyze,
+		); err != nil {
+			return nil, err
+		}
+		if t.Rows+t.DeadRows > 0 {
+			t.BloatRatio = float64(t.DeadRows) / float64(t.Rows+t.DeadRows)
+		}
+		tables = append(tables, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tables, nil
+}
+
+// indexes returns the indexes of the user tables, largest first.
+func (s SystemService) indexes() ([]data.IndexUsage, error) {
+	query := `
+		SELECT s.relname, s.indexrelname, s.idx_scan, pg_relation_size(s.indexrelid),
+			s.idx_scan = 0 AND NOT i.indisunique AND NOT i.indisprimary
+		FROM pg_stat_user_indexes s
+		JOIN pg_index i ON i.indexrelid = s.indexrelid
+		ORDER BY pg_relation_size(s.indexrelid) DESC, s.indexrelname`
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	indexes := make([]data.IndexUsage, 0)
+	for rows.Next() {
+		var i data.IndexUsage
+		if err := rows.Scan(&i.Table, &i.Name, &i.Scans, &i.Bytes, &i.Unused); err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, i)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return indexes, nil
+}
+
+// replicas returns the replicas streaming from the primary with how far behind they are.
+func (s SystemService) replicas() ([]data.ReplicaStatus, error) {
+	query := `
+		SELECT application_name, client_addr::text, state,
+			pg_wal_lsn_diff(pg_current_wal_lsn(), replay_lsn)::bigint,
+			EXTRACT(EPOCH FROM replay_lag)::float8
+		FROM pg_stat_replication
+		ORDER BY application_name`
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	replicas := make([]data.ReplicaStatus, 0)
+	for rows.Next() {
+		var r data.ReplicaStatus
+		if err := rows.Scan(&r.Name, &r.ClientAddr, &r.State, &r.LagBytes, &r.ReplayLag); err != nil {
+			return nil, err
+		}
+		replicas = append(replicas, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return replicas, nil
+}
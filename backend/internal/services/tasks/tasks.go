@@ -0,0 +1,185 @@
+// Package tasks provides the queue of background tasks, such as sending emails, which the task runner retries with
+// backoff until they succeed. Tasks that ran out of attempts stay in the queue as dead tasks for admins to retry.
+package tasks
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/requestid"
+	"NodeTurtleAPI/internal/services"
+)
+
+// ITaskService defines the interface for the task queue operations of the API.
+type ITaskService interface {
+	Enqueue(ctx context.Context, kind data.TaskKind, payload interface{}) error
+	ListDead(ctx context.Context, filter data.TaskFilter) ([]data.Task, error)
+	Retry(ctx context.Context, id int64) (*data.Task, error)
+}
+
+// TaskService implements the ITaskService interface, along with the queue operations of the task runner.
+type TaskService struct {
+	db *sql.DB
+}
+
+// NewTaskService creates a new TaskService with the provided database connection.
+func NewTaskService(db *sql.DB) TaskService {
+	return TaskService{
+		db: db,
+	}
+}
+
+// Execer is a database connection or transaction tasks are queued on.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Enqueue queues a task of the kind with the payload encoded as JSON, to be run right away.
+// The task keeps the ID of the request in ctx, so what it does can be traced back to the request.
+// Queued on a transaction, the task is only run if the transaction commits.
+func Enqueue(ctx context.Context, db Execer, kind data.TaskKind, payload interface{}) error {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	query := "INSERT INTO tasks (kind, payload, request_id) VALUES ($1, $2, $3)"
+	_, err = db.ExecContext(ctx, query, kind, encoded, requestid.FromContext(ctx))
+	return err
+}
+
+// Enqueue queues a task of the kind with the payload encoded as JSON, see Enqueue.
+func (s TaskService) Enqueue(ctx context.Context, kind data.TaskKind, payload interface{}) error {
+	return Enqueue(ctx, s.db, kind, payload)
+}
+
+const taskColumns = "id, kind, payload, status, attempts, run_at, last_error, request_id, created_at, failed_at"
+
+// Claim hands the pending task due the longest to the runner, or returns nil if no task is due.
+// Tasks locked by a runner claiming concurrently are skipped rather than waited for.
+func (s TaskService) Claim(ctx context.Context) (*data.Task, error) {
+	task, err := scanTask(s.db.QueryRowContext(ctx, `
+		UPDATE tasks
+		SET status = 'running', claimed_at = NOW(), attempts = attempts + 1
+		WHERE id = (
+			SELECT id FROM tasks
+			WHERE status = 'pending' AND run_at <= NOW()
+			ORDER BY run_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+taskColumns))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return task, err
+}
+
+// Complete removes a task the runner did.
+func (s TaskService) Complete(ctx context.Context, task *data.Task) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM tasks WHERE id = $1", task.ID)
+	return err
+}
+
+// Fail records why a task failed. A task which failed maxAttempts times is dead, others are retried after backoff.
+// It returns whether the task is dead.
+func (s TaskService) Fail(ctx context.Context, task *data.Task, taskErr error, backoff time.Duration, maxAttempts int) (bool, error) {
+	var status data.TaskStatus
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE tasks
+		SET status = CASE WHEN attempts >= $3 THEN 'dead' ELSE 'pending' END,
+			run_at = NOW() + make_interval(secs => $4),
+			claimed_at = NULL,
+			last_error = $2,
+			failed_at = NOW()
+		WHERE id = $1
+		RETURNING status`,
+		task.ID, taskErr.Error(), maxAttempts, backoff.Seconds(),
+	).Scan(&status)
+	return status == data.TaskDead, err
+}
+
+// Release puts a task the runner was stopped in the middle of back in the queue, without counting the attempt.
+func (s TaskService) Release(ctx context.Context, task *data.Task) error {
+	query := "UPDATE tasks SET status = 'pending', claimed_at = NULL, attempts = attempts - 1 WHERE id = $1"
+	_, err := s.db.ExecContext(ctx, query, task.ID)
+	return err
+}
+
+// RequeueStale puts the tasks claimed for longer than timeout back in the queue, as the runner claiming them
+// must have died, or kills them when that was their last attempt. It returns the number of tasks requeued or killed.
+func (s TaskService) RequeueStale(ctx context.Context, timeout time.Duration, maxAttempts int) (int, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE tasks
+		SET status = CASE WHEN attempts >= $2 THEN 'dead' ELSE 'pending' END,
+			claimed_at = NULL,
+			last_error = 'task: runner stopped responding',
+			failed_at = NOW()
+		WHERE status = 'running' AND claimed_at < NOW() - make_interval(secs => $1)`,
+		timeout.Seconds(), maxAttempts,
+	)
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+// ListDead retrieves the dead tasks matching the filter, the latest to fail first.
+func (s TaskService) ListDead(ctx context.Context, filter data.TaskFilter) ([]data.Task, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+taskColumns+`
+		FROM tasks
+		WHERE status = 'dead' AND ($1 = '' OR kind = $1)
+		ORDER BY failed_at DESC, id DESC
+		LIMIT $2`,
+		filter.Kind, filter.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []data.Task{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *task)
+	}
+	return tasks, rows.Err()
+}
+
+// Retry puts a dead task back in the queue with all its attempts, to be run right away.
+// It returns ErrRecordNotFound if no dead task has the ID.
+func (s TaskService) Retry(ctx context.Context, id int64) (*data.Task, error) {
+	task, err := scanTask(s.db.QueryRowContext(ctx, `
+		UPDATE tasks
+		SET status = 'pending', attempts = 0, run_at = NOW()
+		WHERE id = $1 AND status = 'dead'
+		RETURNING `+taskColumns,
+		id,
+	))
+	if err == sql.ErrNoRows {
+		return nil, services.ErrRecordNotFound
+	}
+	return task, err
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTask(row scanner) (*data.Task, error) {
+	var task data.Task
+	err := row.Scan(&task.ID, &task.Kind, &task.Payload, &task.Status, &task.Attempts, &task.RunAt,
+		&task.LastError, &task.RequestID, &task.CreatedAt, &task.FailedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
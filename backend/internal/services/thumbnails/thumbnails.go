@@ -0,0 +1,189 @@
+// Package thumbnails renders images of what project programs draw and keeps them per project version.
+package thumbnails
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/render"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+)
+
+// awaitPoll is how often a request waiting for a render worker looks for its thumbnail.
+const awaitPoll = 100 * time.Millisecond
+
+// IThumbnailService defines the interface for project thumbnail operations.
+type IThumbnailService interface {
+	GetThumbnail(ctx context.Context, project *data.Project, format data.ThumbnailFormat) (*data.Thumbnail, error)
+}
+
+// ThumbnailService implements the IThumbnailService interface.
+type ThumbnailService struct {
+	db  *sql.DB
+	cfg config.RenderConfig
+}
+
+// NewThumbnailService creates a new ThumbnailService with the provided database connection and rendering limits.
+func NewThumbnailService(db *sql.DB, cfg config.RenderConfig) ThumbnailService {
+	return ThumbnailService{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+// GetThumbnail returns the thumbnail of the current version of the project, rendering and storing it if there is none yet.
+// It returns ErrRenderLimit if the program exceeds the rendering limits, which is remembered until the project changes.
+// When rendering is left to render workers, the thumbnail is queued instead and GetThumbnail waits for a worker
+// to render it, returning ErrRenderPending if none did in time.
+func (s ThumbnailService) GetThumbnail(ctx context.Context, project *data.Project, format data.ThumbnailFormat) (*data.Thumbnail, error) {
+	thumbnail, err := s.stored(ctx, project.ID, format, project.Version)
+	if err != sql.ErrNoRows {
+		return thumbnail, err
+	}
+
+	if s.cfg.Workers {
+		return s.await(ctx, project, format)
+	}
+	return s.Render(ctx, project, format)
+}
+
+// stored returns the thumbnail of the project stored for the version, sql.ErrNoRows if there is none.
+func (s ThumbnailService) stored(ctx context.Context, projectID uuid.UUID, format data.ThumbnailFormat, version int) (*data.Thumbnail, error) {
+	thumbnail := data.Thumbnail{ProjectID: projectID, Format: format}
+	var renderErr sql.NullString
+	query := `
+		SELECT version, content, error, rendered_at
+		FROM project_thumbnails
+		WHERE project_id = $1 AND format = $2 AND version = $3`
+	err := s.db.QueryRowContext(ctx, query, projectID, format, version).Scan(
+		&thumbnail.Version, &thumbnail.Content, &renderErr, &thumbnail.RenderedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if renderErr.Valid {
+		return nil, services.ErrRenderLimit
+	}
+	return &thumbnail, nil
+}
+
+// Render renders the thumbnail of the project and stores it, whether or not one was stored already.
+// It returns ErrRenderLimit if the program exceeds the rendering limits, which is stored as well.
+func (s ThumbnailService) Render(ctx context.Context, project *data.Project, format data.ThumbnailFormat) (*data.Thumbnail, error) {
+	content, err := s.render(ctx, project, format)
+	if ctx.Err() != nil {
+		// the request went away, which says nothing about the program
+		return nil, ctx.Err()
+	}
+	if err != nil && !errors.Is(err, services.ErrRenderLimit) {
+		return nil, err
+	}
+
+	if storeErr := s.store(ctx, project, format, content, err); storeErr != nil {
+		return nil, storeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &data.Thumbnail{
+		ProjectID:  project.ID,
+		Format:     format,
+		Version:    project.Version,
+		Content:    content,
+		RenderedAt: time.Now(),
+	}, nil
+}
+
+// RenderProject renders the thumbnail of the current version of a project and stores it, how render workers process
+// their jobs. It returns the version rendered, a program exceeding the limits is stored as such and not an error here.
+// It returns ErrRecordNotFound if the project was deleted.
+func (s ThumbnailService) RenderProject(ctx context.Context, projectID uuid.UUID, format data.ThumbnailFormat) (int, error) {
+	project := data.Project{ID: projectID}
+	err := s.db.QueryRowContext(ctx, "SELECT data, version FROM projects WHERE id = $1", projectID).Scan(&project.Data, &project.Version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, services.ErrRecordNotFound
+		}
+		return 0, err
+	}
+
+	if _, err := s.Render(ctx, &project, format); err != nil && !errors.Is(err, services.ErrRenderLimit) {
+		return 0, err
+	}
+	return project.Version, nil
+}
+
+// await queues the thumbnail for render workers and waits for it to be stored.
+func (s ThumbnailService) await(ctx context.Context, project *data.Project, format data.ThumbnailFormat) (*data.Thumbnail, error) {
+	query := `
+		INSERT INTO render_jobs (project_id, format, version)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (project_id, format) DO UPDATE SET version = GREATEST(render_jobs.version, EXCLUDED.version)`
+	if _, err := s.db.ExecContext(ctx, query, project.ID, format, project.Version); err != nil {
+		return nil, err
+	}
+
+	wait, cancel := context.WithTimeout(ctx, s.cfg.WorkerWait)
+	defer cancel()
+	ticker := time.NewTicker(awaitPoll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-wait.Done():
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, services.ErrRenderPending
+		case <-ticker.C:
+			thumbnail, err := s.stored(ctx, project.ID, format, project.Version)
+			if err != sql.ErrNoRows {
+				return thumbnail, err
+			}
+		}
+	}
+}
+
+func (s ThumbnailService) render(ctx context.Context, project *data.Project, format data.ThumbnailFormat) ([]byte, error) {
+	drawing, err := render.Execute(ctx, project.Data, render.Limits{
+		MaxInstructions: s.cfg.MaxInstructions,
+		Timeout:         s.cfg.Timeout,
+	})
+	if err != nil {
+		if errors.Is(err, render.ErrInstructionLimit) || errors.Is(err, render.ErrTimeLimit) {
+			return nil, services.ErrRenderLimit
+		}
+		// flows are validated on save, an unreadable one is drawn as empty rather than failing every request
+		drawing = &render.Drawing{}
+	}
+
+	if format == data.ThumbnailSVG {
+		return drawing.SVG(s.cfg.Width, s.cfg.Height), nil
+	}
+	return drawing.PNG(s.cfg.Width, s.cfg.Height)
+}
+
+// store replaces the thumbnail of the project, unless a newer version was stored meanwhile.
+func (s ThumbnailService) store(ctx context.Context, project *data.Project, format data.ThumbnailFormat, content []byte, renderErr error) error {
+	var message *string
+	if renderErr != nil {
+		m := renderErr.Error()
+		message = &m
+	}
+
+	query := `
+		INSERT INTO project_thumbnails (project_id, format, version, content, error)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (project_id, format) DO UPDATE
+		SET version = EXCLUDED.version, content = EXCLUDED.content, error = EXCLUDED.error, rendered_at = NOW()
+		WHERE project_thumbnails.version <= EXCLUDED.version`
+	_, err := s.db.ExecContext(ctx, query, project.ID, format, project.Version, content, message)
+	return err
+}
@@ -2,11 +2,16 @@
 package tokens
 
 import (
+	"NodeTurtleAPI/internal/clock"
+	"NodeTurtleAPI/internal/config"
 	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+	"context"
 	"crypto/rand"
 	"crypto/sha256"
 	"database/sql"
 	"encoding/base32"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,39 +19,101 @@ import (
 
 // ITokenService defines the interface for token management operations.
 type ITokenService interface {
-	New(userID uuid.UUID, ttl time.Duration, scope data.TokenScope) (*data.Token, error)
-	Insert(token *data.Token) error
-	DeleteAllForUser(scope data.TokenScope, userID uuid.UUID) error
+	New(ctx context.Context, userID uuid.UUID, scope data.TokenScope) (*data.Token, error)
+	Insert(ctx context.Context, token *data.Token) error
+	Consume(ctx context.Context, scope data.TokenScope, tokenPlaintext string) error
+	DeleteAllForUser(ctx context.Context, scope data.TokenScope, userID uuid.UUID) error
+	Stats(ctx context.Context) ([]data.TokenStats, error)
+	Revoke(ctx context.Context, revocation data.TokenRevocation) (int64, error)
+}
+
+// singleUse lists the scopes whose tokens are consumed by their first use.
+// Activation tokens stay valid until they expire so activation links can be clicked again.
+var singleUse = map[data.TokenScope]bool{
+	data.ScopePasswordReset: true,
+	data.ScopeDeactivate:    true,
+	data.ScopeRefresh:       true,
+	data.ScopeMagicLink:     true,
 }
 
 // TokenService implements the ITokenService interface for managing tokens.
 type TokenService struct {
-	db *sql.DB
+	db    *sql.DB
+	cfg   config.TokensConfig
+	clock clock.Clock
 }
 
-// NewTokenService creates a new TokenService with the provided database connection.
-func NewTokenService(db *sql.DB) TokenService {
+// NewTokenService creates a new TokenService with the provided database connection and token lifetimes.
+// Tokens expire and are checked against the time of the clock.
+func NewTokenService(db *sql.DB, cfg config.TokensConfig, clk clock.Clock) TokenService {
 	return TokenService{
-		db: db,
+		db:    db,
+		cfg:   cfg,
+		clock: clk,
 	}
 }
 
-// New creates and stores a new token for a specific user.
+// New creates and stores a new token for a specific user, valid for the lifetime configured for its scope.
 // It returns the created token or an error if the operation fails.
-func (s TokenService) New(userID uuid.UUID, ttl time.Duration, scope data.TokenScope) (*data.Token, error) {
-	token, err := GenerateToken(userID, ttl, scope)
+func (s TokenService) New(ctx context.Context, userID uuid.UUID, scope data.TokenScope) (*data.Token, error) {
+	ttl, err := s.ttl(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := generateToken(userID, s.clock.Now().Add(ttl), scope)
 	if err != nil {
 		return nil, err
 	}
 
-	err = s.Insert(token)
+	err = s.Insert(ctx, token)
 	return token, err
 }
 
+func (s TokenService) ttl(scope data.TokenScope) (time.Duration, error) {
+	var ttl time.Duration
+	switch scope {
+	case data.ScopeUserActivation, data.ScopeActivationStatus:
+		ttl = s.cfg.ActivationTTL
+	case data.ScopePasswordReset:
+		ttl = s.cfg.ResetTTL
+	case data.ScopeDeactivate:
+		ttl = s.cfg.DeactivationTTL
+	case data.ScopeRefresh:
+		ttl = s.cfg.RefreshTTL
+	case data.ScopeMagicLink:
+		ttl = s.cfg.MagicLinkTTL
+	}
+
+	if ttl <= 0 {
+		return 0, fmt.Errorf("no lifetime configured for token scope %q", scope)
+	}
+	return ttl, nil
+}
+
+// Consume marks the use of a token. Tokens of single use scopes are deleted, so they can't be used again,
+// tokens of other scopes stay valid until they expire.
+// It returns ErrInvalidToken if the token doesn't exist, expired or was already used.
+func (s TokenService) Consume(ctx context.Context, scope data.TokenScope, tokenPlaintext string) error {
+	hash := sha256.Sum256([]byte(tokenPlaintext))
+
+	query := "SELECT 1 FROM tokens WHERE hash = $1 AND scope = $2 AND expires_at > $3"
+	if singleUse[scope] {
+		query = "DELETE FROM tokens WHERE hash = $1 AND scope = $2 AND expires_at > $3 RETURNING 1"
+	}
+
+	var found int
+	err := s.db.QueryRowContext(ctx, query, hash[:], scope, s.clock.Now()).Scan(&found)
+	if err == sql.ErrNoRows {
+		return services.ErrInvalidToken
+	}
+	return err
+}
+
 // Insert adds a token to the database.
 // Returns an error if the database operation fails.
-func (s TokenService) Insert(token *data.Token) error {
-	tx, err := s.db.Begin()
+func (s TokenService) Insert(ctx context.Context, token *data.Token) error {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
@@ -58,7 +125,7 @@ func (s TokenService) Insert(token *data.Token) error {
 
 	args := []interface{}{token.Hash, token.UserID, token.ExpiresAt, token.Scope}
 
-	_, err = tx.Exec(query, args...)
+	_, err = tx.ExecContext(ctx, query, args...)
 	if err != nil {
 		return err
 	}
@@ -68,8 +135,8 @@ func (s TokenService) Insert(token *data.Token) error {
 
 // DeleteAllForUser removes all tokens with the specified scope for a given user.
 // Returns an error if the database operation fails.
-func (s TokenService) DeleteAllForUser(scope data.TokenScope, userID uuid.UUID) error {
-	tx, err := s.db.Begin()
+func (s TokenService) DeleteAllForUser(ctx context.Context, scope data.TokenScope, userID uuid.UUID) error {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
@@ -81,7 +148,7 @@ func (s TokenService) DeleteAllForUser(scope data.TokenScope, userID uuid.UUID)
 
 	args := []interface{}{scope, userID}
 
-	_, err = tx.Exec(query, args...)
+	_, err = tx.ExecContext(ctx, query, args...)
 	if err != nil {
 		return err
 	}
@@ -89,13 +156,64 @@ func (s TokenService) DeleteAllForUser(scope data.TokenScope, userID uuid.UUID)
 	return tx.Commit()
 }
 
+// Stats returns the number of active and expired tokens per scope.
+func (s TokenService) Stats(ctx context.Context) ([]data.TokenStats, error) {
+	query := `
+        SELECT scope, COUNT(*) FILTER (WHERE expires_at > $1), COUNT(*) FILTER (WHERE expires_at <= $1)
+        FROM tokens
+        GROUP BY scope
+        ORDER BY scope`
+
+	rows, err := s.db.QueryContext(ctx, query, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]data.TokenStats, 0)
+	for rows.Next() {
+		var st data.TokenStats
+		if err := rows.Scan(&st.Scope, &st.Active, &st.Expired); err != nil {
+			return nil, err
+		}
+		stats = append(stats, st)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// Revoke deletes the tokens matching every filter set in the revocation and returns how many were deleted.
+// Revoking refresh tokens ends sessions at their next refresh, access tokens already issued stay valid until they expire.
+func (s TokenService) Revoke(ctx context.Context, revocation data.TokenRevocation) (int64, error) {
+	query := `
+        DELETE FROM tokens
+        WHERE ($1::uuid IS NULL OR user_id = $1)
+        AND ($2 = '' OR scope = $2)
+        AND ($3::timestamptz IS NULL OR created_at < $3)`
+
+	result, err := s.db.ExecContext(ctx, query, revocation.UserID, revocation.Scope, revocation.IssuedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
 // GenerateToken creates a new token for the specified user with the given time-to-live and scope.
 // It generates a secure random plaintext token and its corresponding hash.
 // Returns the created token or an error if generation fails.
 func GenerateToken(userID uuid.UUID, ttl time.Duration, scope data.TokenScope) (*data.Token, error) {
+	return generateToken(userID, clock.System.Now().Add(ttl), scope)
+}
+
+func generateToken(userID uuid.UUID, expiresAt time.Time, scope data.TokenScope) (*data.Token, error) {
 	token := &data.Token{
 		UserID:    userID,
-		ExpiresAt: time.Now().UTC().Add(ttl),
+		ExpiresAt: expiresAt,
 		Scope:     scope,
 	}
 
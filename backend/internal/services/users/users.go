@@ -2,9 +2,12 @@
 package users
 
 import (
+	"context"
+	"crypto/rand"
 	"crypto/sha256"
 	"database/sql"
 	"fmt"
+	"math/big"
 	"strings"
 	"time"
 
@@ -13,23 +16,29 @@ import (
 	"NodeTurtleAPI/internal/services/auth"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // IUserService defines the interface for user management operations.
 type IUserService interface {
-	CreateUser(reg data.UserRegistration) (*data.User, error)
-	ResetPassword(token, newPassword string) error
-	ChangePassword(userID uuid.UUID, oldPassword, newPassword string) error
-	GetUserByID(userID uuid.UUID) (*data.User, error)
-	GetUserByEmail(email string) (*data.User, error)
-	GetUserByUsername(username string) (*data.User, error)
-	ListUsers(filters data.UserFilter) ([]data.User, int, error)
-	UpdateUser(userID uuid.UUID, updates data.UserUpdate) (*data.User, error)
-	DeleteUser(userID uuid.UUID) error
-	GetForToken(tokenScope data.TokenScope, tokenPlaintext string) (*data.User, error)
-	UsernameExists(username string) (bool, error)
-	EmailExists(email string) (bool, error)
+	CreateUser(ctx context.Context, reg data.UserRegistration) (*data.User, error)
+	ResetPassword(ctx context.Context, token, newPassword string) error
+	ChangePassword(ctx context.Context, userID uuid.UUID, oldPassword, newPassword string) error
+	GetUserByID(ctx context.Context, userID uuid.UUID) (*data.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*data.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*data.User, error)
+	ListUsers(ctx context.Context, filters data.UserFilter) ([]data.User, int, error)
+	UpdateUser(ctx context.Context, userID uuid.UUID, updates data.UserUpdate) (*data.User, error)
+	ChangeUsername(ctx context.Context, userID uuid.UUID, username string) (*data.User, error)
+	ResolveUsername(ctx context.Context, username string) (*data.ResolvedUsername, error)
+	DeleteUser(ctx context.Context, userID uuid.UUID) error
+	GetForToken(ctx context.Context, tokenScope data.TokenScope, tokenPlaintext string) (*data.User, error)
+	UsernameExists(ctx context.Context, username string) (bool, error)
+	EmailExists(ctx context.Context, email string) (bool, error)
+	ProvisionUser(ctx context.Context, p data.UserProvision) (*data.User, string, error)
+	DeprovisionUser(ctx context.Context, email string) (*data.User, error)
+	BulkUpdateUsers(ctx context.Context, actor data.User, update data.BulkUserUpdate) ([]data.BulkUserResult, error)
 }
 
 // UserService implements the IUserService interface for managing users.
@@ -47,10 +56,10 @@ func NewUserService(db *sql.DB) UserService {
 // CreateUser creates a new user with the provided registration data.
 // It returns the created user or an error if the operation fails.
 // If an email already exists in the system, it returns ErrDuplicateEmail.
-func (s UserService) CreateUser(reg data.UserRegistration) (*data.User, error) {
+func (s UserService) CreateUser(ctx context.Context, reg data.UserRegistration) (*data.User, error) {
 	var exists bool
 
-	exists, err := s.EmailExists(reg.Email)
+	exists, err := s.EmailExists(ctx, reg.Email)
 	if err != nil {
 		return nil, err
 	}
@@ -58,7 +67,7 @@ func (s UserService) CreateUser(reg data.UserRegistration) (*data.User, error) {
 		return nil, services.ErrDuplicateEmail
 	}
 
-	exists, err = s.UsernameExists(reg.Username)
+	exists, err = s.UsernameExists(ctx, reg.Username)
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +75,7 @@ func (s UserService) CreateUser(reg data.UserRegistration) (*data.User, error) {
 		return nil, services.ErrDuplicateUsername
 	}
 
-	tx, err := s.db.Begin()
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -83,7 +92,7 @@ func (s UserService) CreateUser(reg data.UserRegistration) (*data.User, error) {
 	VALUES ($1, $2, $3, $4, $5, NOW() AT TIME ZONE 'UTC')
 	RETURNING id, email, username, activated, created_at
 	`
-	err = tx.QueryRow(
+	err = tx.QueryRowContext(ctx,
 		query,
 		reg.Email,
 		reg.Username,
@@ -111,8 +120,8 @@ func (s UserService) CreateUser(reg data.UserRegistration) (*data.User, error) {
 // ResetPassword updates a user's password using a valid password reset token.
 // It returns an error if the token is invalid, expired, or if the password
 // update fails. Used when the user can't remember their password
-func (s UserService) ResetPassword(token, newPassword string) error {
-	tx, err := s.db.Begin()
+func (s UserService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
@@ -122,8 +131,9 @@ func (s UserService) ResetPassword(token, newPassword string) error {
 
 	var userID uuid.UUID
 	var expiresAt time.Time
-	query := "SELECT user_id, expires_at FROM tokens WHERE hash = $1 AND scope = $2"
-	err = tx.QueryRow(query, tokenHash[:], data.ScopePasswordReset).Scan(&userID, &expiresAt)
+	// reset tokens are single use, deleting it in the same transaction keeps concurrent requests from reusing it
+	query := "DELETE FROM tokens WHERE hash = $1 AND scope = $2 RETURNING user_id, expires_at"
+	err = tx.QueryRowContext(ctx, query, tokenHash[:], data.ScopePasswordReset).Scan(&userID, &expiresAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return services.ErrInvalidToken
@@ -140,8 +150,8 @@ func (s UserService) ResetPassword(token, newPassword string) error {
 		return err
 	}
 
-	_, err = tx.Exec(
-		"UPDATE users SET password = $1 WHERE id = $2",
+	_, err = tx.ExecContext(ctx,
+		"UPDATE users SET password = $1, password_reset_required = false WHERE id = $2",
 		hashedPassword, userID,
 	)
 	if err != nil {
@@ -154,15 +164,15 @@ func (s UserService) ResetPassword(token, newPassword string) error {
 // ChangePassword updates a user's password after verifying their old password.
 // It returns ErrUserNotFound if the user doesn't exist or ErrInvalidCredentials
 // if the old password is incorrect.
-func (s UserService) ChangePassword(userID uuid.UUID, oldPassword, newPassword string) error {
-	tx, err := s.db.Begin()
+func (s UserService) ChangePassword(ctx context.Context, userID uuid.UUID, oldPassword, newPassword string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
 	var hashedPassword string
-	err = s.db.QueryRow("SELECT password FROM users WHERE id = $1", userID).Scan(&hashedPassword)
+	err = s.db.QueryRowContext(ctx, "SELECT password FROM users WHERE id = $1", userID).Scan(&hashedPassword)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return services.ErrUserNotFound
@@ -179,8 +189,8 @@ func (s UserService) ChangePassword(userID uuid.UUID, oldPassword, newPassword s
 		return err
 	}
 
-	_, err = s.db.Exec(
-		"UPDATE users SET password = $1 WHERE id = $2",
+	_, err = s.db.ExecContext(ctx,
+		"UPDATE users SET password = $1, password_reset_required = false WHERE id = $2",
 		newHashedPassword, userID,
 	)
 	if err != nil {
@@ -193,13 +203,13 @@ func (s UserService) ChangePassword(userID uuid.UUID, oldPassword, newPassword s
 // GetUserByID retrieves a user by their UUID.
 // It returns ErrUserNotFound if the user doesn't exist or ErrInvalidCredentials
 // if the old password is incorrect.
-func (s UserService) GetUserByID(userID uuid.UUID) (*data.User, error) {
+func (s UserService) GetUserByID(ctx context.Context, userID uuid.UUID) (*data.User, error) {
 	var user data.User
 	var role data.Role
 	var ban data.OptionalBan
 
 	query := `
-		SELECT u.id, u.email, u.password, u.username, u.activated, u.created_at, u.last_login,
+		SELECT u.id, u.email, u.password, u.username, u.activated, u.verified, u.password_reset_required, u.created_at, u.last_login, u.guest_expires_at, u.deactivated_at,
 		       r.id, r.name, r.description, r.created_at,
 			   bu.id, bu.expires_at, bu.banned_at, bu.reason, bu.banned_by
 		FROM users u
@@ -208,8 +218,8 @@ func (s UserService) GetUserByID(userID uuid.UUID) (*data.User, error) {
 		WHERE u.id = $1
 	`
 
-	err := s.db.QueryRow(query, userID).Scan(
-		&user.ID, &user.Email, &user.Password.Hash, &user.Username, &user.IsActivated, &user.CreatedAt, &user.LastLogin,
+	err := s.db.QueryRowContext(ctx, query, userID).Scan(
+		&user.ID, &user.Email, &user.Password.Hash, &user.Username, &user.IsActivated, &user.Verified, &user.PasswordResetRequired, &user.CreatedAt, &user.LastLogin, &user.GuestExpiresAt, &user.DeactivatedAt,
 		&role.ID, &role.Name, &role.Description, &role.CreatedAt,
 		&ban.ID, &ban.ExpiresAt, &ban.BannedAt, &ban.Reason, &ban.BannedBy,
 	)
@@ -224,10 +234,10 @@ func (s UserService) GetUserByID(userID uuid.UUID) (*data.User, error) {
 	if ban.NotNull() {
 		user.Ban = &data.Ban{
 			ID:        *ban.ID,
-			ExpiresAt: *ban.ExpiresAt,
+			ExpiresAt: ban.ExpiresAt,
 			Reason:    *ban.Reason,
 			BannedAt:  *ban.BannedAt,
-			BannedBy:  *ban.BannedBy,
+			BannedBy:  ban.Banner(),
 		}
 	}
 
@@ -237,13 +247,13 @@ func (s UserService) GetUserByID(userID uuid.UUID) (*data.User, error) {
 
 // GetUserByEmail retrieves a user by their email address.
 // It returns the user or ErrUserNotFound if no matching user exists.
-func (s UserService) GetUserByEmail(email string) (*data.User, error) {
+func (s UserService) GetUserByEmail(ctx context.Context, email string) (*data.User, error) {
 	var user data.User
 	var role data.Role
 	var ban data.OptionalBan
 
 	query := `
-		SELECT u.id, u.email, u.password, u.username, u.activated, u.created_at, u.last_login,
+		SELECT u.id, u.email, u.password, u.username, u.activated, u.verified, u.created_at, u.last_login, u.deactivated_at,
                r.id, r.name, r.description,
                bu.id, bu.expires_at, bu.banned_at, bu.reason, bu.banned_by
 		FROM users u
@@ -252,8 +262,8 @@ func (s UserService) GetUserByEmail(email string) (*data.User, error) {
 		WHERE u.email = $1
 	`
 
-	err := s.db.QueryRow(query, email).Scan(
-		&user.ID, &user.Email, &user.Password.Hash, &user.Username, &user.IsActivated, &user.CreatedAt, &user.LastLogin,
+	err := s.db.QueryRowContext(ctx, query, email).Scan(
+		&user.ID, &user.Email, &user.Password.Hash, &user.Username, &user.IsActivated, &user.Verified, &user.CreatedAt, &user.LastLogin, &user.DeactivatedAt,
 		&role.ID, &role.Name, &role.Description,
 		&ban.ID, &ban.ExpiresAt, &ban.BannedAt, &ban.Reason, &ban.BannedBy,
 	)
@@ -268,10 +278,10 @@ func (s UserService) GetUserByEmail(email string) (*data.User, error) {
 	if ban.NotNull() {
 		user.Ban = &data.Ban{
 			ID:        *ban.ID,
-			ExpiresAt: *ban.ExpiresAt,
+			ExpiresAt: ban.ExpiresAt,
 			Reason:    *ban.Reason,
 			BannedAt:  *ban.BannedAt,
-			BannedBy:  *ban.BannedBy,
+			BannedBy:  ban.Banner(),
 		}
 	}
 
@@ -281,13 +291,13 @@ func (s UserService) GetUserByEmail(email string) (*data.User, error) {
 
 // GetUserByUsername retrieves a user by their username.
 // It returns the user or ErrUserNotFound if no matching user exists.
-func (s UserService) GetUserByUsername(username string) (*data.User, error) {
+func (s UserService) GetUserByUsername(ctx context.Context, username string) (*data.User, error) {
 	var user data.User
 	var role data.Role
 	var ban data.OptionalBan
 
 	query := `
-		SELECT u.id, u.email, u.username, u.activated, u.created_at, u.last_login,
+		SELECT u.id, u.email, u.username, u.activated, u.verified, u.created_at, u.last_login,
 		       r.id, r.name, r.description,
 			   bu.id, bu.expires_at, bu.banned_at, bu.reason, bu.banned_by
 		FROM users u
@@ -296,8 +306,8 @@ func (s UserService) GetUserByUsername(username string) (*data.User, error) {
 		WHERE u.username = $1
 	`
 
-	err := s.db.QueryRow(query, username).Scan(
-		&user.ID, &user.Email, &user.Username, &user.IsActivated, &user.CreatedAt, &user.LastLogin,
+	err := s.db.QueryRowContext(ctx, query, username).Scan(
+		&user.ID, &user.Email, &user.Username, &user.IsActivated, &user.Verified, &user.CreatedAt, &user.LastLogin,
 		&role.ID, &role.Name, &role.Description, &ban.ID, &ban.ExpiresAt, &ban.BannedAt, &ban.Reason, &ban.BannedBy,
 	)
 
@@ -311,10 +321,10 @@ func (s UserService) GetUserByUsername(username string) (*data.User, error) {
 	if ban.NotNull() {
 		user.Ban = &data.Ban{
 			ID:        *ban.ID,
-			ExpiresAt: *ban.ExpiresAt,
+			ExpiresAt: ban.ExpiresAt,
 			Reason:    *ban.Reason,
 			BannedAt:  *ban.BannedAt,
-			BannedBy:  *ban.BannedBy,
+			BannedBy:  ban.Banner(),
 		}
 	}
 
@@ -323,7 +333,7 @@ func (s UserService) GetUserByUsername(username string) (*data.User, error) {
 }
 
 // ListUsers returns a paginated list of users and the total count.
-func (s UserService) ListUsers(filters data.UserFilter) ([]data.User, int, error) {
+func (s UserService) ListUsers(ctx context.Context, filters data.UserFilter) ([]data.User, int, error) {
 	offset := (filters.Page - 1) * filters.Limit
 
 	whereClause := []string{}
@@ -360,6 +370,12 @@ func (s UserService) ListUsers(filters data.UserFilter) ([]data.User, int, error
 		}
 	}
 
+	// Filter by staff annotation label
+	if filters.Label != nil && *filters.Label != "" {
+		whereClause = append(whereClause, "EXISTS (SELECT 1 FROM user_annotations ua WHERE ua.user_id = u.id AND $"+fmt.Sprint(len(args)+1)+" = ANY(ua.labels))")
+		args = append(args, strings.ToLower(strings.Join(strings.Fields(*filters.Label), " ")))
+	}
+
 	// Filter by creation time
 	if filters.CreatedBefore != nil {
 		whereClause = append(whereClause, "u.created_at <= $"+fmt.Sprint(len(args)+1))
@@ -399,13 +415,13 @@ func (s UserService) ListUsers(filters data.UserFilter) ([]data.User, int, error
 	// Count total matching users
 	countQuery := "SELECT COUNT(*) FROM users u LEFT JOIN banned_users bu ON u.id = bu.user_id " + where
 	var total int
-	err := s.db.QueryRow(countQuery, args...).Scan(&total)
+	err := s.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	query := `
-		SELECT u.id, u.email, u.username, u.activated, u.created_at, u.last_login,
+		SELECT u.id, u.email, u.username, u.activated, u.verified, u.created_at, u.last_login,
 		       r.id, r.name,
 			   bu.id, bu.expires_at, bu.banned_at, bu.banned_by, bu.reason
 		FROM users u
@@ -417,7 +433,7 @@ func (s UserService) ListUsers(filters data.UserFilter) ([]data.User, int, error
 
 	args = append(args, filters.Limit, offset)
 
-	rows, err := s.db.Query(query, args...)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -432,7 +448,7 @@ func (s UserService) ListUsers(filters data.UserFilter) ([]data.User, int, error
 		var lastLogin sql.NullTime
 
 		err := rows.Scan(
-			&user.ID, &user.Email, &user.Username, &user.IsActivated, &user.CreatedAt, &lastLogin,
+			&user.ID, &user.Email, &user.Username, &user.IsActivated, &user.Verified, &user.CreatedAt, &lastLogin,
 			&role.ID, &role.Name,
 			&ban.ID, &ban.ExpiresAt, &ban.BannedAt, &ban.BannedBy, &ban.Reason,
 		)
@@ -446,10 +462,10 @@ func (s UserService) ListUsers(filters data.UserFilter) ([]data.User, int, error
 		if ban.NotNull() {
 			user.Ban = &data.Ban{
 				ID:        *ban.ID,
-				ExpiresAt: *ban.ExpiresAt,
+				ExpiresAt: ban.ExpiresAt,
 				Reason:    *ban.Reason,
 				BannedAt:  *ban.BannedAt,
-				BannedBy:  *ban.BannedBy,
+				BannedBy:  ban.Banner(),
 			}
 		}
 
@@ -466,7 +482,7 @@ func (s UserService) ListUsers(filters data.UserFilter) ([]data.User, int, error
 // UpdateUser modifies a user's fields based on the provided updates map.
 // Valid keys for the updates map are "username", "email", "activated", and "role_id".
 // It returns the updated user, ErrNoFields if the updates map is empty, or ErrUserNotFound if the user doesn't exist.
-func (s UserService) UpdateUser(userID uuid.UUID, updates data.UserUpdate) (*data.User, error) {
+func (s UserService) UpdateUser(ctx context.Context, userID uuid.UUID, updates data.UserUpdate) (*data.User, error) {
 	assignments := []string{}
 	args := []interface{}{}
 	argCount := 1
@@ -482,7 +498,7 @@ func (s UserService) UpdateUser(userID uuid.UUID, updates data.UserUpdate) (*dat
 		argCount++
 	}
 	if updates.Activated != nil {
-		assignments = append(assignments, fmt.Sprintf("activated = $%d", argCount))
+		assignments = append(assignments, fmt.Sprintf("activated = $%d, deactivated_at = CASE WHEN $%d THEN NULL ELSE COALESCE(deactivated_at, NOW()) END", argCount, argCount))
 		args = append(args, *updates.Activated)
 		argCount++
 	}
@@ -496,24 +512,31 @@ func (s UserService) UpdateUser(userID uuid.UUID, updates data.UserUpdate) (*dat
 		return nil, services.ErrNoFields
 	}
 
-	tx, err := s.db.Begin()
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback()
 
-	_, err = s.GetUserByID(userID)
+	current, err := s.GetUserByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
+	// renames by staff are kept too, for the links to the old username to keep working
+	if updates.Username != nil && *updates.Username != current.Username {
+		if err = recordUsername(ctx, tx, userID, current.Username); err != nil {
+			return nil, err
+		}
+	}
+
 	query := "UPDATE users SET " + strings.Join(assignments, ", ")
 	query += fmt.Sprintf(" WHERE id = $%d", argCount)
 	query += " RETURNING id, username, email, activated, role_id"
 	args = append(args, userID)
 
 	var updatedUser data.User
-	err = tx.QueryRow(query, args...).Scan(
+	err = tx.QueryRowContext(ctx, query, args...).Scan(
 		&updatedUser.ID,
 		&updatedUser.Username,
 		&updatedUser.Email,
@@ -530,16 +553,117 @@ func (s UserService) UpdateUser(userID uuid.UUID, updates data.UserUpdate) (*dat
 	return &updatedUser, tx.Commit()
 }
 
+// ChangeUsername changes the username of a user at their own request and reserves the old one for them
+// for data.UsernameReservation. Users can take back a username they gave up.
+// It returns a UsernameCooldownError if the user changed their username less than data.UsernameChangeInterval ago,
+// ErrDuplicateUsername if another user has the username, ErrUsernameReserved if another user gave it up recently,
+// or ErrUserNotFound if the user doesn't exist.
+func (s UserService) ChangeUsername(ctx context.Context, userID uuid.UUID, username string) (*data.User, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var current string
+	var lastChange *time.Time
+	// locking the user keeps two requests from both passing the cooldown
+	err = tx.QueryRowContext(ctx, `
+		SELECT u.username, (SELECT MAX(changed_at) FROM username_history WHERE user_id = u.id)
+		FROM users u WHERE u.id = $1 FOR UPDATE`,
+		userID,
+	).Scan(&current, &lastChange)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if lastChange != nil {
+		if next := lastChange.Add(data.UsernameChangeInterval); time.Now().Before(next) {
+			return nil, &services.UsernameCooldownError{NextChange: next}
+		}
+	}
+
+	var reserved bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM username_history WHERE username = $1 AND user_id <> $2 AND reserved_until > NOW())`,
+		username, userID,
+	).Scan(&reserved)
+	if err != nil {
+		return nil, err
+	}
+	if reserved {
+		return nil, services.ErrUsernameReserved
+	}
+
+	if err = recordUsername(ctx, tx, userID, current); err != nil {
+		return nil, err
+	}
+
+	var user data.User
+	err = tx.QueryRowContext(ctx,
+		"UPDATE users SET username = $1 WHERE id = $2 RETURNING id, username, email, activated, role_id",
+		username, userID,
+	).Scan(&user.ID, &user.Username, &user.Email, &user.IsActivated, &user.RoleID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return nil, services.ErrDuplicateUsername
+		}
+		return nil, err
+	}
+
+	return &user, tx.Commit()
+}
+
+// recordUsername keeps the username a user gives up in their history and reserves it for them.
+func recordUsername(ctx context.Context, tx *sql.Tx, userID uuid.UUID, username string) error {
+	_, err := tx.ExecContext(ctx,
+		"INSERT INTO username_history (user_id, username, reserved_until) VALUES ($1, $2, $3)",
+		userID, username, time.Now().Add(data.UsernameReservation),
+	)
+	return err
+}
+
+// ResolveUsername finds the user a username leads to: the user who has it, or else the user who gave it up last.
+// It returns ErrUserNotFound if nobody ever had the username.
+func (s UserService) ResolveUsername(ctx context.Context, username string) (*data.ResolvedUsername, error) {
+	var resolved data.ResolvedUsername
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT user_id, username, moved FROM (
+			SELECT id AS user_id, username, FALSE AS moved, NULL::timestamptz AS changed_at
+			FROM users WHERE username = $1
+			UNION ALL
+			SELECT u.id, u.username, TRUE, h.changed_at
+			FROM username_history h JOIN users u ON u.id = h.user_id
+			WHERE h.username = $1
+		) r
+		ORDER BY moved, changed_at DESC
+		LIMIT 1`,
+		username,
+	).Scan(&resolved.UserID, &resolved.Username, &resolved.Moved)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return &resolved, nil
+}
+
 // DeleteUser removes a user from the database by their ID.
 // It returns ErrUserNotFound if no matching user exists.
-func (s UserService) DeleteUser(userID uuid.UUID) error {
-	tx, err := s.db.Begin()
+func (s UserService) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	result, err := tx.Exec("DELETE FROM users WHERE id = $1", userID)
+	result, err := tx.ExecContext(ctx, "DELETE FROM users WHERE id = $1", userID)
 	if err != nil {
 		return err
 	}
@@ -559,13 +683,13 @@ func (s UserService) DeleteUser(userID uuid.UUID) error {
 // GetForToken retrieves a user associated with a valid token.
 // It verifies the token's scope and expiration before returning the user.
 // Returns ErrRecordNotFound if no valid token exists.
-func (s UserService) GetForToken(tokenScope data.TokenScope, tokenPlaintext string) (*data.User, error) {
+func (s UserService) GetForToken(ctx context.Context, tokenScope data.TokenScope, tokenPlaintext string) (*data.User, error) {
 	tokenHash := sha256.Sum256([]byte(tokenPlaintext))
 
 	var ban data.OptionalBan
 
 	query := `
-        SELECT users.id, users.created_at, users.username, users.email, users.password, users.activated,
+        SELECT users.id, users.created_at, users.username, users.email, users.password, users.activated, users.deactivated_at,
 		bu.id, bu.expires_at, bu.banned_at, bu.reason, bu.banned_by
         FROM users
         INNER JOIN tokens ON users.id = tokens.user_id
@@ -578,8 +702,8 @@ func (s UserService) GetForToken(tokenScope data.TokenScope, tokenPlaintext stri
 
 	var user data.User
 
-	err := s.db.QueryRow(query, args...).Scan(
-		&user.ID, &user.CreatedAt, &user.Username, &user.Email, &user.Password.Hash, &user.IsActivated,
+	err := s.db.QueryRowContext(ctx, query, args...).Scan(
+		&user.ID, &user.CreatedAt, &user.Username, &user.Email, &user.Password.Hash, &user.IsActivated, &user.DeactivatedAt,
 		&ban.ID, &ban.ExpiresAt, &ban.BannedAt, &ban.Reason, &ban.BannedBy,
 	)
 
@@ -593,28 +717,258 @@ func (s UserService) GetForToken(tokenScope data.TokenScope, tokenPlaintext stri
 	if ban.NotNull() {
 		user.Ban = &data.Ban{
 			ID:        *ban.ID,
-			ExpiresAt: *ban.ExpiresAt,
+			ExpiresAt: ban.ExpiresAt,
 			Reason:    *ban.Reason,
 			BannedAt:  *ban.BannedAt,
-			BannedBy:  *ban.BannedBy,
+			BannedBy:  ban.Banner(),
 		}
 	}
 
 	return &user, nil
 }
 
-func (s UserService) EmailExists(email string) (bool, error) {
+// ProvisionUser creates an activated account from a roster entry with a generated password.
+// The account is flagged to require a password change on first login.
+// It returns the created user and the generated plaintext password, or
+// ErrDuplicateEmail / ErrDuplicateUsername if the account already exists.
+func (s UserService) ProvisionUser(ctx context.Context, p data.UserProvision) (*data.User, string, error) {
+	exists, err := s.EmailExists(ctx, p.Email)
+	if err != nil {
+		return nil, "", err
+	}
+	if exists {
+		return nil, "", services.ErrDuplicateEmail
+	}
+
+	username := p.Username
+	if username == "" {
+		username, err = s.generateUsername(ctx, p.Email)
+		if err != nil {
+			return nil, "", err
+		}
+	} else {
+		exists, err = s.UsernameExists(ctx, username)
+		if err != nil {
+			return nil, "", err
+		}
+		if exists {
+			return nil, "", services.ErrDuplicateUsername
+		}
+	}
+
+	password, err := generatePassword()
+	if err != nil {
+		return nil, "", err
+	}
+
+	hashedPassword, err := auth.HashPassword(password)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var user data.User
+	query := `
+	INSERT INTO users (email, username, password, role_id, activated, password_reset_required, created_at)
+	VALUES ($1, $2, $3, $4, true, true, NOW() AT TIME ZONE 'UTC')
+	RETURNING id, email, username, activated, password_reset_required, created_at
+	`
+	err = s.db.QueryRowContext(ctx, query, p.Email, username, hashedPassword, data.RoleUser).Scan(
+		&user.ID,
+		&user.Email,
+		&user.Username,
+		&user.IsActivated,
+		&user.PasswordResetRequired,
+		&user.CreatedAt,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &user, password, nil
+}
+
+// DeprovisionUser deactivates the account registered with the given email,
+// preventing further logins while keeping the user's projects.
+// It returns ErrUserNotFound if no matching user exists.
+func (s UserService) DeprovisionUser(ctx context.Context, email string) (*data.User, error) {
+	var user data.User
+	err := s.db.QueryRowContext(ctx,
+		"UPDATE users SET activated = false, deactivated_at = COALESCE(deactivated_at, NOW()) WHERE email = $1 RETURNING id, email, username, activated",
+		email,
+	).Scan(&user.ID, &user.Email, &user.Username, &user.IsActivated)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// BulkUpdateUsers applies an action to a list of users in a single transaction and returns the result for each of them.
+// The actor's own account, unknown users and, unless the actor is an admin, users ranked the same or higher than the actor
+// are skipped with an error in their result. Any other error rolls back the whole batch.
+// Deactivated and banned users are signed out.
+func (s UserService) BulkUpdateUsers(ctx context.Context, actor data.User, update data.BulkUserUpdate) ([]data.BulkUserResult, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	signOut := func(userID uuid.UUID) error {
+		_, err := tx.ExecContext(ctx, "DELETE FROM tokens WHERE user_id = $1 AND scope = $2", userID, data.ScopeRefresh)
+		return err
+	}
+
+	var apply func(userID uuid.UUID) error
+	switch update.Action {
+	case data.BulkActivate:
+		apply = func(userID uuid.UUID) error {
+			_, err := tx.ExecContext(ctx, "UPDATE users SET activated = true, deactivated_at = NULL WHERE id = $1", userID)
+			return err
+		}
+	case data.BulkDeactivate:
+		apply = func(userID uuid.UUID) error {
+			if _, err := tx.ExecContext(ctx, "UPDATE users SET activated = false, deactivated_at = COALESCE(deactivated_at, NOW()) WHERE id = $1", userID); err != nil {
+				return err
+			}
+			return signOut(userID)
+		}
+	case data.BulkSetRole:
+		if update.Role == nil || !update.Role.IsValid() {
+			return nil, services.ErrInvalidData
+		}
+		apply = func(userID uuid.UUID) error {
+			_, err := tx.ExecContext(ctx, "UPDATE users SET role_id = $2 WHERE id = $1", userID, update.Role.ToID())
+			return err
+		}
+	case data.BulkBan:
+		var expiresAt *time.Time
+		if !update.Permanent {
+			until := time.Now().UTC().Add(time.Duration(update.Duration) * time.Hour)
+			expiresAt = &until
+		}
+		apply = func(userID uuid.UUID) error {
+			query := `
+				INSERT INTO banned_users (user_id, reason, banned_by, expires_at)
+				VALUES ($1, $2, $3, $4)
+				ON CONFLICT (user_id) DO UPDATE
+				SET reason = EXCLUDED.reason, banned_by = EXCLUDED.banned_by, expires_at = EXCLUDED.expires_at`
+			if _, err := tx.ExecContext(ctx, query, userID, update.Reason, actor.ID, expiresAt); err != nil {
+				return err
+			}
+			if err := services.RecordBanEvent(ctx, tx, userID, data.BanActionBan, update.Reason, expiresAt, actor.ID); err != nil {
+				return err
+			}
+			return signOut(userID)
+		}
+	default:
+		return nil, services.ErrInvalidData
+	}
+
+	results := make([]data.BulkUserResult, 0, len(update.UserIDs))
+	for _, userID := range update.UserIDs {
+		result := data.BulkUserResult{UserID: userID}
+
+		var roleID int64
+		err := tx.QueryRowContext(ctx, "SELECT username, role_id FROM users WHERE id = $1 FOR UPDATE", userID).Scan(&result.Username, &roleID)
+		switch {
+		case err == sql.ErrNoRows:
+			result.Error = "User not found"
+		case err != nil:
+			return nil, err
+		case userID == actor.ID:
+			result.Error = "Cannot change your own account"
+		// roles are ordered by privilege, as for single bans
+		case actor.Role.Name != data.RoleAdmin.String() && roleID >= actor.Role.ID:
+			result.Error = "Cannot change a user with an equal or higher role"
+		}
+		if result.Error != "" {
+			results = append(results, result)
+			continue
+		}
+
+		if err := apply(userID); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, tx.Commit()
+}
+
+// generateUsername derives a free username from the local part of an email address.
+func (s UserService) generateUsername(ctx context.Context, email string) (string, error) {
+	var b strings.Builder
+	local, _, _ := strings.Cut(email, "@")
+	for _, r := range strings.ToLower(local) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+		if b.Len() == 14 {
+			break
+		}
+	}
+
+	base := b.String()
+	if len(base) < 3 || data.LooksVerified(base) {
+		base = "student"
+	}
+
+	for attempt := 0; attempt < 5; attempt++ {
+		n, err := rand.Int(rand.Reader, big.NewInt(10000))
+		if err != nil {
+			return "", err
+		}
+		username := fmt.Sprintf("%s%04d", base, n.Int64())
+
+		exists, err := s.UsernameExists(ctx, username)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return username, nil
+		}
+	}
+
+	return "", services.ErrDuplicateUsername
+}
+
+// generatePassword creates a random password that is easy to read out from a printed roster.
+func generatePassword() (string, error) {
+	const alphabet = "abcdefghjkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+	password := make([]byte, 12)
+	for i := range password {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+		if err != nil {
+			return "", err
+		}
+		password[i] = alphabet[n.Int64()]
+	}
+
+	return string(password), nil
+}
+
+func (s UserService) EmailExists(ctx context.Context, email string) (bool, error) {
 	var exists bool
-	err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)", email).Scan(&exists)
+	err := s.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)", email).Scan(&exists)
 	if err != nil {
 		return false, services.ErrRecordNotFound
 	}
 	return exists, nil
 }
 
-func (s UserService) UsernameExists(username string) (bool, error) {
+// UsernameExists reports whether a user has the username, or it is reserved for the user who gave it up.
+func (s UserService) UsernameExists(ctx context.Context, username string) (bool, error) {
 	var exists bool
-	err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)", username).Scan(&exists)
+	err := s.db.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)
+		    OR EXISTS(SELECT 1 FROM username_history WHERE username = $1 AND reserved_until > NOW())`,
+		username,
+	).Scan(&exists)
 	if err != nil {
 		return false, services.ErrRecordNotFound
 	}
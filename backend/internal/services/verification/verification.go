@@ -0,0 +1,156 @@
+// Package verification handles the requests users submit for the verified creator badge and their review by admins.
+package verification
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// IVerificationService defines the interface for verification request operations.
+type IVerificationService interface {
+	SubmitRequest(ctx context.Context, userID uuid.UUID, evidence string) (*data.VerificationRequest, error)
+	GetLatestRequest(ctx context.Context, userID uuid.UUID) (*data.VerificationRequest, error)
+	GetRequest(ctx context.Context, requestID int64) (*data.VerificationRequest, error)
+	ListPendingRequests(ctx context.Context) ([]data.VerificationRequest, error)
+	ReviewRequest(ctx context.Context, requestID int64, review data.VerificationReview, reviewerID uuid.UUID) (*data.VerificationRequest, error)
+}
+
+// VerificationService implements the IVerificationService interface.
+type VerificationService struct {
+	db *sql.DB
+}
+
+// NewVerificationService creates a new VerificationService with the provided database connection.
+func NewVerificationService(db *sql.DB) VerificationService {
+	return VerificationService{
+		db: db,
+	}
+}
+
+const selectRequest = `
+	SELECT vr.id, vr.user_id, u.username, vr.evidence, vr.status, vr.review_note, vr.reviewed_by, vr.reviewed_at, vr.created_at
+	FROM verification_requests vr
+	JOIN users u ON vr.user_id = u.id`
+
+// SubmitRequest files a verification request with the evidence provided by the user.
+// It returns ErrAlreadyVerified if the user is verified already
+// and ErrVerificationPending if an earlier request of the user hasn't been reviewed yet.
+func (s VerificationService) SubmitRequest(ctx context.Context, userID uuid.UUID, evidence string) (*data.VerificationRequest, error) {
+	var verified bool
+	err := s.db.QueryRowContext(ctx, "SELECT verified FROM users WHERE id = $1", userID).Scan(&verified)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrUserNotFound
+		}
+		return nil, err
+	}
+	if verified {
+		return nil, services.ErrAlreadyVerified
+	}
+
+	var requestID int64
+	err = s.db.QueryRowContext(ctx,
+		"INSERT INTO verification_requests (user_id, evidence) VALUES ($1, $2) RETURNING id",
+		userID, strings.TrimSpace(evidence),
+	).Scan(&requestID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return nil, services.ErrVerificationPending
+		}
+		return nil, err
+	}
+
+	return s.GetRequest(ctx, requestID)
+}
+
+// GetLatestRequest retrieves the most recent verification request of the user.
+// It returns ErrRecordNotFound if the user never requested verification.
+func (s VerificationService) GetLatestRequest(ctx context.Context, userID uuid.UUID) (*data.VerificationRequest, error) {
+	return scanRequest(s.db.QueryRowContext(ctx, selectRequest+" WHERE vr.user_id = $1 ORDER BY vr.created_at DESC LIMIT 1", userID))
+}
+
+// GetRequest retrieves a verification request by its ID.
+// It returns ErrRecordNotFound if the request doesn't exist.
+func (s VerificationService) GetRequest(ctx context.Context, requestID int64) (*data.VerificationRequest, error) {
+	return scanRequest(s.db.QueryRowContext(ctx, selectRequest+" WHERE vr.id = $1", requestID))
+}
+
+// ListPendingRequests retrieves the requests waiting for review, oldest first.
+func (s VerificationService) ListPendingRequests(ctx context.Context) ([]data.VerificationRequest, error) {
+	rows, err := s.db.QueryContext(ctx, selectRequest+" WHERE vr.status = $1 ORDER BY vr.created_at", data.VerificationPending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []data.VerificationRequest{}
+	for rows.Next() {
+		r, err := scanRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, *r)
+	}
+
+	return requests, rows.Err()
+}
+
+// ReviewRequest approves or rejects a pending verification request. Approving it verifies the user.
+// It returns ErrRecordNotFound if the request doesn't exist or was reviewed already.
+func (s VerificationService) ReviewRequest(ctx context.Context, requestID int64, review data.VerificationReview, reviewerID uuid.UUID) (*data.VerificationRequest, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var userID uuid.UUID
+	err = tx.QueryRowContext(ctx, `
+		UPDATE verification_requests
+		SET status = $2, review_note = $3, reviewed_by = $4, reviewed_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING user_id`,
+		requestID, review.Status, strings.TrimSpace(review.Note), reviewerID,
+	).Scan(&userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	if review.Status == data.VerificationApproved {
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET verified = TRUE WHERE id = $1", userID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return s.GetRequest(ctx, requestID)
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRequest(row scanner) (*data.VerificationRequest, error) {
+	var r data.VerificationRequest
+	err := row.Scan(&r.ID, &r.UserID, &r.Username, &r.Evidence, &r.Status, &r.ReviewNote, &r.ReviewedBy, &r.ReviewedAt, &r.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &r, nil
+}
@@ -0,0 +1,288 @@
+// Package webhooks notifies project creators about engagement milestones of their projects through webhooks.
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/httpclient"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// IWebhookService defines the interface for project webhook operations.
+type IWebhookService interface {
+	GetProjectWebhook(projectID uuid.UUID) (*data.ProjectWebhook, error)
+	SetProjectWebhook(w data.ProjectWebhook) (*data.ProjectWebhook, error)
+	DeleteProjectWebhook(projectID uuid.UUID) error
+	GetDeliveries(projectID uuid.UUID, limit int) ([]data.WebhookDelivery, error)
+	DeliverMilestones(ctx context.Context) (int, error)
+}
+
+// WebhookService implements the IWebhookService interface.
+type WebhookService struct {
+	db     *sql.DB
+	client *http.Client
+}
+
+// NewWebhookService creates a new WebhookService with the provided database connection and webhook settings.
+func NewWebhookService(db *sql.DB, cfg config.WebhooksConfig) WebhookService {
+	// webhook URLs are user provided, a retried delivery is identified by its event, project and threshold
+	client := httpclient.New(httpclient.Options{
+		Timeout:        time.Duration(cfg.Timeout*max(cfg.MaxAttempts, 1)) * time.Second,
+		AttemptTimeout: time.Duration(cfg.Timeout) * time.Second,
+		MaxAttempts:    cfg.MaxAttempts,
+		RetryUnsafe:    true,
+		PublicOnly:     true,
+	})
+
+	return WebhookService{
+		db:     db,
+		client: client,
+	}
+}
+
+// GetProjectWebhook retrieves the webhook of the project.
+// It returns ErrRecordNotFound if the project has no webhook.
+func (s WebhookService) GetProjectWebhook(projectID uuid.UUID) (*data.ProjectWebhook, error) {
+	var w data.ProjectWebhook
+	var thresholds pq.Int64Array
+
+	err := s.db.QueryRow(
+		"SELECT project_id, url, secret, like_thresholds, notify_featured, created_at, updated_at FROM project_webhooks WHERE project_id = $1",
+		projectID,
+	).Scan(&w.ProjectID, &w.URL, &w.Secret, &thresholds, &w.NotifyFeatured, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, services.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	w.LikeThresholds = toInts(thresholds)
+	return &w, nil
+}
+
+// SetProjectWebhook creates or replaces the webhook of the project.
+// A signing secret is generated when the webhook is created and kept when it is updated.
+// Milestones the project already reached are delivered on the next check.
+func (s WebhookService) SetProjectWebhook(w data.ProjectWebhook) (*data.ProjectWebhook, error) {
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	var saved data.ProjectWebhook
+	var thresholds pq.Int64Array
+
+	query := `
+		INSERT INTO project_webhooks (project_id, url, secret, like_thresholds, notify_featured)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (project_id) DO UPDATE
+		SET url = EXCLUDED.url, like_thresholds = EXCLUDED.like_thresholds, notify_featured = EXCLUDED.notify_featured, updated_at = NOW()
+		RETURNING project_id, url, secret, like_thresholds, notify_featured, created_at, updated_at`
+
+	err = s.db.QueryRow(query, w.ProjectID, w.URL, secret, toInt64Array(w.LikeThresholds), w.NotifyFeatured).Scan(
+		&saved.ProjectID, &saved.URL, &saved.Secret, &thresholds, &saved.NotifyFeatured, &saved.CreatedAt, &saved.UpdatedAt,
+	)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return nil, services.ErrProjectNotFound
+		}
+		return nil, err
+	}
+
+	saved.LikeThresholds = toInts(thresholds)
+	return &saved, nil
+}
+
+// DeleteProjectWebhook removes the webhook of the project.
+// It returns ErrRecordNotFound if the project has no webhook.
+func (s WebhookService) DeleteProjectWebhook(projectID uuid.UUID) error {
+	res, err := s.db.Exec("DELETE FROM project_webhooks WHERE project_id = $1", projectID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return services.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// GetDeliveries retrieves the most recent milestone notifications of the project.
+func (s WebhookService) GetDeliveries(projectID uuid.UUID, limit int) ([]data.WebhookDelivery, error) {
+	rows, err := s.db.Query(`
+		SELECT id, event, threshold, status_code, error, created_at, delivered_at
+		FROM project_webhook_deliveries
+		WHERE project_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2`,
+		projectID, limit,
+	)
+	if err != nil {
+		return []data.WebhookDelivery{}, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]data.WebhookDelivery, 0)
+	for rows.Next() {
+		var d data.WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.Event, &d.Threshold, &d.StatusCode, &d.Error, &d.CreatedAt, &d.DeliveredAt); err != nil {
+			return []data.WebhookDelivery{}, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return []data.WebhookDelivery{}, err
+	}
+
+	return deliveries, nil
+}
+
+// pendingMilestone is a milestone a project reached, with the webhook it has to be delivered to.
+type pendingMilestone struct {
+	url    string
+	secret string
+	event  data.WebhookEvent
+}
+
+// DeliverMilestones checks every project with a webhook for like thresholds it crossed and for being featured,
+// and delivers the milestones that were not delivered before. Each milestone is delivered at most once,
+// failed deliveries are recorded and not retried. It returns the number of delivered milestones.
+func (s WebhookService) DeliverMilestones(ctx context.Context) (int, error) {
+	milestones, err := s.pendingMilestones()
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, m := range milestones {
+		if ctx.Err() != nil {
+			return delivered, ctx.Err()
+		}
+
+		var deliveryID int64
+		err := s.db.QueryRow(`
+			INSERT INTO project_webhook_deliveries (project_id, event, threshold)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (project_id, event, threshold) DO NOTHING
+			RETURNING id`,
+			m.event.ProjectID, m.event.Event, m.event.Threshold,
+		).Scan(&deliveryID)
+		if err == sql.ErrNoRows {
+			// claimed by a concurrent run
+			continue
+		}
+		if err != nil {
+			return delivered, err
+		}
+
+		m.event.OccurredAt = time.Now().UTC()
+		statusCode, sendErr := Post(ctx, s.client, m.url, m.secret, m.event.Event, m.event)
+
+		var status, message interface{}
+		if statusCode != 0 {
+			status = statusCode
+		}
+		if sendErr != nil {
+			message = sendErr.Error()
+		} else {
+			delivered++
+		}
+
+		if _, err := s.db.Exec(
+			"UPDATE project_webhook_deliveries SET status_code = $2, error = $3, delivered_at = NOW() WHERE id = $1",
+			deliveryID, status, message,
+		); err != nil {
+			return delivered, err
+		}
+	}
+
+	return delivered, nil
+}
+
+func (s WebhookService) pendingMilestones() ([]pendingMilestone, error) {
+	rows, err := s.db.Query(`
+		SELECT w.project_id, w.url, w.secret, w.like_thresholds, w.notify_featured,
+		       p.title, p.likes_count, p.featured_until IS NOT NULL AND p.featured_until > NOW()
+		FROM project_webhooks w
+		JOIN projects p ON p.id = w.project_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	milestones := make([]pendingMilestone, 0)
+	for rows.Next() {
+		var w data.ProjectWebhook
+		var thresholds pq.Int64Array
+		var title string
+		var likes int
+		var featured bool
+
+		if err := rows.Scan(&w.ProjectID, &w.URL, &w.Secret, &thresholds, &w.NotifyFeatured, &title, &likes, &featured); err != nil {
+			return nil, err
+		}
+
+		event := data.WebhookEvent{ProjectID: w.ProjectID, ProjectTitle: title, LikesCount: likes}
+		for _, threshold := range toInts(thresholds) {
+			if likes >= threshold {
+				e := event
+				e.Event = data.WebhookEventLikes
+				e.Threshold = threshold
+				milestones = append(milestones, pendingMilestone{url: w.URL, secret: w.Secret, event: e})
+			}
+		}
+		if w.NotifyFeatured && featured {
+			e := event
+			e.Event = data.WebhookEventFeatured
+			milestones = append(milestones, pendingMilestone{url: w.URL, secret: w.Secret, event: e})
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return milestones, nil
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func toInts(values pq.Int64Array) []int {
+	ints := make([]int, len(values))
+	for i, v := range values {
+		ints[i] = int(v)
+	}
+	return ints
+}
+
+func toInt64Array(values []int) pq.Int64Array {
+	ints := make(pq.Int64Array, len(values))
+	for i, v := range values {
+		ints[i] = int64(v)
+	}
+	return ints
+}
@@ -0,0 +1,50 @@
+// Package storage stores uploaded files, like avatars, as objects on the local disk or in an S3 compatible bucket.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"NodeTurtleAPI/internal/config"
+)
+
+var (
+	// ErrNotFound is returned when reading an object that doesn't exist.
+	ErrNotFound = errors.New("object not found")
+	// ErrInvalidKey is returned for keys that could escape the storage, like ones containing "..".
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// Storage stores objects by key. Keys are slash separated paths, e.g. "avatars/<user ID>/<name>.png".
+type Storage interface {
+	// Put creates or replaces an object.
+	Put(ctx context.Context, key string, body []byte, contentType string) error
+	// Get returns the content and content type of an object, or ErrNotFound.
+	Get(ctx context.Context, key string) ([]byte, string, error)
+	// Delete removes an object, deleting one that doesn't exist isn't an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// New creates the storage selected by STORAGE_DRIVER.
+func New(cfg config.StorageConfig) (Storage, error) {
+	switch cfg.Driver {
+	case "local":
+		return NewLocal(cfg.Dir), nil
+	case "s3":
+		return NewS3(cfg.S3), nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
+
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*(/[A-Za-z0-9_-][A-Za-z0-9._-]*)*$`)
+
+// checkKey rejects keys that aren't relative paths of plain segments, so the same key is valid on every driver.
+func checkKey(key string) error {
+	if len(key) > 512 || !keyPattern.MatchString(key) {
+		return ErrInvalidKey
+	}
+	return nil
+}
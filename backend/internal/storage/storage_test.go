@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"NodeTurtleAPI/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckKey(t *testing.T) {
+	for _, key := range []string{"avatars/abc/def-256.png", "a", "a/b.c/d_e-f"} {
+		assert.NoError(t, checkKey(key), key)
+	}
+	for _, key := range []string{"", "/avatars", "avatars/", "../etc/passwd", "avatars/../x", "avatars//x", ".hidden", "a b", `a\b`} {
+		assert.ErrorIs(t, checkKey(key), ErrInvalidKey, key)
+	}
+}
+
+func TestLocal(t *testing.T) {
+	ctx := context.Background()
+	l := NewLocal(t.TempDir())
+
+	_, _, err := l.Get(ctx, "avatars/x/a-64.png")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.NoError(t, l.Put(ctx, "avatars/x/a-64.png", []byte("first"), "image/png"))
+	assert.NoError(t, l.Put(ctx, "avatars/x/a-64.png", []byte("second"), "image/png"))
+
+	body, contentType, err := l.Get(ctx, "avatars/x/a-64.png")
+	assert.NoError(t, err)
+	assert.Equal(t, "second", string(body))
+	assert.Equal(t, "image/png", contentType)
+
+	assert.NoError(t, l.Delete(ctx, "avatars/x/a-64.png"))
+	assert.NoError(t, l.Delete(ctx, "avatars/x/a-64.png"), "deleting a missing object isn't an error")
+	_, _, err = l.Get(ctx, "avatars/x/a-64.png")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.ErrorIs(t, l.Put(ctx, "../outside", []byte("x"), "text/plain"), ErrInvalidKey)
+}
+
+// the example of https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_sigv-create-signed-request.html
+func TestSigningKey(t *testing.T) {
+	key := signingKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	assert.Equal(t, "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d", hex.EncodeToString(key))
+}
+
+func TestS3(t *testing.T) {
+	var mu sync.Mutex
+	objects := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=key/") || !strings.Contains(auth, "/eu-central-1/s3/aws4_request") ||
+			r.Header.Get("X-Amz-Content-Sha256") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			objects[r.URL.Path], _ = io.ReadAll(r.Body)
+		case http.MethodGet:
+			body, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(body)
+		case http.MethodDelete:
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	s := NewS3(config.S3Config{Endpoint: server.URL, Region: "eu-central-1", Bucket: "uploads", AccessKey: "key", SecretKey: "secret", PathStyle: true})
+
+	assert.NoError(t, s.Put(ctx, "avatars/x/a-64.png", []byte("image"), "image/png"))
+	assert.Contains(t, objects, "/uploads/avatars/x/a-64.png")
+
+	body, contentType, err := s.Get(ctx, "avatars/x/a-64.png")
+	assert.NoError(t, err)
+	assert.Equal(t, "image", string(body))
+	assert.Equal(t, "image/png", contentType)
+
+	assert.NoError(t, s.Delete(ctx, "avatars/x/a-64.png"))
+	_, _, err = s.Get(ctx, "avatars/x/a-64.png")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	denied := NewS3(config.S3Config{Endpoint: server.URL, Region: "us-east-1", Bucket: "uploads", AccessKey: "key", PathStyle: true})
+	assert.Error(t, denied.Put(ctx, "avatars/x/a-64.png", []byte("image"), "image/png"))
+}
+
+func TestS3_VirtualHostedStyle(t *testing.T) {
+	s := NewS3(config.S3Config{Endpoint: "https://s3.eu-central-1.amazonaws.com", Bucket: "uploads"})
+	u, err := s.objectURL("avatars/x/a-64.png")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://uploads.s3.eu-central-1.amazonaws.com/avatars/x/a-64.png", u.String())
+}
@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Handler serves the health check and metrics of the worker.
+//
+// /healthz answers 503 once the worker missed 3 heartbeats, as its peers are about to remove it.
+// /metrics is in the Prometheus text format. Besides the worker's own counters it carries the depth of the whole
+// queue as of the last heartbeat, which is what an autoscaler adds workers on.
+func (w *Worker) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", w.healthz)
+	mux.HandleFunc("/metrics", w.metrics)
+	return mux
+}
+
+func (w *Worker) healthz(rw http.ResponseWriter, r *http.Request) {
+	last := w.heartbeat.Load()
+	if last == 0 || time.Since(time.Unix(0, last)) > 3*w.cfg.Heartbeat {
+		http.Error(rw, "missed heartbeats", http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintln(rw, "ok")
+}
+
+func (w *Worker) metrics(rw http.ResponseWriter, r *http.Request) {
+	w.mu.Lock()
+	stats := w.stats
+	w.mu.Unlock()
+
+	rw.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	metric := func(name, kind, help string, value interface{}) {
+		fmt.Fprintf(rw, "# HELP %s %s\n# TYPE %s %s\n%s %v\n", name, help, name, kind, name, value)
+	}
+	metric("render_worker_slots", "gauge", "Jobs the worker renders at once.", w.cfg.Concurrency)
+	metric("render_worker_busy", "gauge", "Jobs the worker is rendering.", w.busy.Load())
+	metric("render_worker_jobs_done_total", "counter", "Jobs the worker rendered.", w.done.Load())
+	metric("render_worker_jobs_failed_total", "counter", "Jobs the worker failed to render and put back in the queue.", w.failed.Load())
+	metric("render_worker_render_seconds_total", "counter", "Time the worker spent rendering.", time.Duration(w.renderNs.Load()).Seconds())
+	metric("render_queue_pending", "gauge", "Jobs waiting for a worker, over all workers.", stats.Pending)
+	metric("render_queue_running", "gauge", "Jobs being rendered, over all workers.", stats.Running)
+	metric("render_queue_oldest_pending_seconds", "gauge", "Age of the oldest job waiting for a worker.", stats.OldestPending)
+}
@@ -0,0 +1,197 @@
+// Package worker runs the render worker processes, which take thumbnail rendering off the API: CPU-bound
+// program execution then competes with other programs on the workers instead of with API requests.
+// Workers pull jobs from the render queue in the database, so any number of them can run side by side.
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/logging"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+)
+
+// Queue is the render queue as workers use it, implemented by renders.RenderService.
+type Queue interface {
+	Register(ctx context.Context, w data.RenderWorker) error
+	Heartbeat(ctx context.Context, w data.RenderWorker) error
+	Deregister(ctx context.Context, workerID string) error
+	Claim(ctx context.Context, workerID string) (*data.RenderJob, error)
+	Complete(ctx context.Context, job *data.RenderJob, version int) error
+	Release(ctx context.Context, job *data.RenderJob) error
+	RequeueStale(ctx context.Context, timeout time.Duration, maxAttempts int) (int, error)
+	QueueStats(ctx context.Context) (*data.RenderQueueStats, error)
+}
+
+// Renderer renders and stores the thumbnail of the current version of a project, implemented by thumbnails.ThumbnailService.
+type Renderer interface {
+	RenderProject(ctx context.Context, projectID uuid.UUID, format data.ThumbnailFormat) (int, error)
+}
+
+// Worker renders the jobs of the render queue, as many at once as its concurrency.
+type Worker struct {
+	id       string
+	hostname string
+	cfg      config.WorkerConfig
+	queue    Queue
+	renderer Renderer
+
+	busy      atomic.Int64
+	done      atomic.Int64
+	failed    atomic.Int64
+	renderNs  atomic.Int64 // time spent rendering jobs, done or failed
+	heartbeat atomic.Int64 // unix nanoseconds of the last successful heartbeat, 0 before the first one
+
+	mu    sync.Mutex
+	stats data.RenderQueueStats // as of the last heartbeat
+}
+
+// New creates a Worker, identified by the host it runs on and a random suffix so restarts register anew.
+func New(hostname string, cfg config.WorkerConfig, queue Queue, renderer Renderer) *Worker {
+	return &Worker{
+		id:       fmt.Sprintf("%s-%s", hostname, uuid.NewString()[:8]),
+		hostname: hostname,
+		cfg:      cfg,
+		queue:    queue,
+		renderer: renderer,
+	}
+}
+
+// ID returns the ID the worker registers with.
+func (w *Worker) ID() string {
+	return w.id
+}
+
+// Run registers the worker and renders jobs until the context is cancelled. Jobs being rendered then are put back
+// in the queue and the worker deregisters, so scaling a pool down doesn't wait for running programs.
+func (w *Worker) Run(ctx context.Context) error {
+	if err := w.queue.Register(ctx, w.status()); err != nil {
+		return err
+	}
+	w.heartbeat.Store(time.Now().UnixNano())
+	logging.FromContext(ctx).InfoContext(ctx, "Render worker started", "worker_id", w.id, "slots", w.cfg.Concurrency)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.beat(ctx)
+	}()
+	for i := 0; i < w.cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.work(ctx)
+		}()
+	}
+	wg.Wait()
+
+	// the context is done, deregistering must not be
+	shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return w.queue.Deregister(shutdown, w.id)
+}
+
+// beat reports the worker every heartbeat interval, and removes peers that stopped reporting.
+func (w *Worker) beat(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.Heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := w.queue.Heartbeat(ctx, w.status()); err != nil {
+			logging.FromContext(ctx).ErrorContext(ctx, "Render worker heartbeat failed", "worker_id", w.id, "error", err)
+			continue
+		}
+		w.heartbeat.Store(time.Now().UnixNano())
+
+		requeued, err := w.queue.RequeueStale(ctx, 3*w.cfg.Heartbeat, w.cfg.MaxAttempts)
+		if err != nil {
+			logging.FromContext(ctx).ErrorContext(ctx, "Requeueing jobs of stale render workers failed", "error", err)
+		} else if requeued > 0 {
+			logging.FromContext(ctx).WarnContext(ctx, "Requeued render jobs of stale workers", "count", requeued)
+		}
+
+		if stats, err := w.queue.QueueStats(ctx); err == nil {
+			w.mu.Lock()
+			w.stats = *stats
+			w.mu.Unlock()
+		}
+	}
+}
+
+// work claims and renders jobs one at a time, waiting a poll interval whenever the queue is empty.
+func (w *Worker) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := w.queue.Claim(ctx, w.id)
+		if err != nil && ctx.Err() == nil {
+			logging.FromContext(ctx).ErrorContext(ctx, "Claiming a render job failed", "worker_id", w.id, "error", err)
+		}
+		if job == nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(w.cfg.Poll):
+			}
+			continue
+		}
+
+		w.process(ctx, job)
+	}
+}
+
+func (w *Worker) process(ctx context.Context, job *data.RenderJob) {
+	w.busy.Add(1)
+	defer w.busy.Add(-1)
+
+	started := time.Now()
+	version, err := w.renderer.RenderProject(ctx, job.ProjectID, job.Format)
+	w.renderNs.Add(int64(time.Since(started)))
+
+	// jobs are finished even when the worker is stopping, which leaves it a few seconds at most
+	finish, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	switch {
+	case ctx.Err() != nil:
+		err = w.queue.Release(finish, job)
+	case errors.Is(err, services.ErrRecordNotFound):
+		// the project was deleted, its thumbnail along with it
+		err = w.queue.Complete(finish, job, job.Version)
+		w.done.Add(1)
+	case err != nil:
+		w.failed.Add(1)
+		logging.FromContext(ctx).ErrorContext(ctx, "Rendering project failed", "project_id", job.ProjectID, "job_id", job.ID, "error", err)
+		err = w.queue.Release(finish, job)
+	default:
+		w.done.Add(1)
+		err = w.queue.Complete(finish, job, version)
+	}
+	if err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Finishing render job failed", "job_id", job.ID, "error", err)
+	}
+}
+
+// status is the worker as reported on heartbeats.
+func (w *Worker) status() data.RenderWorker {
+	return data.RenderWorker{
+		ID:          w.id,
+		Hostname:    w.hostname,
+		Concurrency: w.cfg.Concurrency,
+		Busy:        int(w.busy.Load()),
+		JobsDone:    w.done.Load(),
+		JobsFailed:  w.failed.Load(),
+	}
+}
@@ -0,0 +1,170 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"NodeTurtleAPI/internal/config"
+	"NodeTurtleAPI/internal/data"
+	"NodeTurtleAPI/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryQueue is a render queue in memory, recording what the worker did with its jobs.
+type memoryQueue struct {
+	mu         sync.Mutex
+	pending    []*data.RenderJob
+	completed  map[int64]int
+	released   []int64
+	registered bool
+	beats      int
+}
+
+func (q *memoryQueue) Register(ctx context.Context, w data.RenderWorker) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.registered = true
+	return nil
+}
+
+func (q *memoryQueue) Heartbeat(ctx context.Context, w data.RenderWorker) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.beats++
+	return nil
+}
+
+func (q *memoryQueue) Deregister(ctx context.Context, workerID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.registered = false
+	return nil
+}
+
+func (q *memoryQueue) Claim(ctx context.Context, workerID string) (*data.RenderJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) == 0 {
+		return nil, nil
+	}
+	job := q.pending[0]
+	q.pending = q.pending[1:]
+	job.Attempts++
+	return job, nil
+}
+
+func (q *memoryQueue) Complete(ctx context.Context, job *data.RenderJob, version int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.completed[job.ID] = version
+	return nil
+}
+
+func (q *memoryQueue) Release(ctx context.Context, job *data.RenderJob) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.released = append(q.released, job.ID)
+	return nil
+}
+
+func (q *memoryQueue) RequeueStale(ctx context.Context, timeout time.Duration, maxAttempts int) (int, error) {
+	return 0, nil
+}
+
+func (q *memoryQueue) QueueStats(ctx context.Context) (*data.RenderQueueStats, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return &data.RenderQueueStats{Pending: len(q.pending)}, nil
+}
+
+func (q *memoryQueue) finished() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.completed) + len(q.released)
+}
+
+// renderFunc adapts a function to the Renderer interface.
+type renderFunc func(ctx context.Context, projectID uuid.UUID, format data.ThumbnailFormat) (int, error)
+
+func (f renderFunc) RenderProject(ctx context.Context, projectID uuid.UUID, format data.ThumbnailFormat) (int, error) {
+	return f(ctx, projectID, format)
+}
+
+func TestWorker(t *testing.T) {
+	rendered, deleted, broken := uuid.New(), uuid.New(), uuid.New()
+	queue := &memoryQueue{
+		pending: []*data.RenderJob{
+			{ID: 1, ProjectID: rendered, Format: data.ThumbnailPNG, Version: 2},
+			{ID: 2, ProjectID: deleted, Format: data.ThumbnailPNG, Version: 1},
+			{ID: 3, ProjectID: broken, Format: data.ThumbnailSVG, Version: 1},
+		},
+		completed: map[int64]int{},
+	}
+	renderer := renderFunc(func(ctx context.Context, projectID uuid.UUID, format data.ThumbnailFormat) (int, error) {
+		switch projectID {
+		case deleted:
+			return 0, services.ErrRecordNotFound
+		case broken:
+			return 0, errors.New("database error")
+		}
+		return 3, nil
+	})
+
+	cfg := config.WorkerConfig{Concurrency: 2, Poll: 10 * time.Millisecond, Heartbeat: 20 * time.Millisecond, MaxAttempts: 3}
+	w := New("render-1", cfg, queue, renderer)
+	assert.Contains(t, w.ID(), "render-1-")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+
+	assert.Eventually(t, func() bool { return queue.finished() == 3 }, time.Second, 5*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		queue.mu.Lock()
+		defer queue.mu.Unlock()
+		return queue.beats > 0
+	}, time.Second, 5*time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	w.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	w.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, rec.Body.String(), "render_worker_slots 2\n")
+	assert.Contains(t, rec.Body.String(), "render_worker_jobs_done_total 2\n")
+	assert.Contains(t, rec.Body.String(), "render_worker_jobs_failed_total 1\n")
+	assert.Contains(t, rec.Body.String(), "# TYPE render_queue_pending gauge\n")
+
+	cancel()
+	assert.NoError(t, <-done)
+
+	assert.False(t, queue.registered, "a stopped worker deregisters")
+	assert.Equal(t, map[int64]int{1: 3, 2: 1}, queue.completed, "jobs complete at the version rendered, deleted projects at their own")
+	assert.Equal(t, []int64{3}, queue.released, "jobs that failed go back in the queue")
+}
+
+func TestWorkerHealthz(t *testing.T) {
+	w := New("render-1", config.WorkerConfig{Heartbeat: time.Second}, &memoryQueue{}, nil)
+
+	rec := httptest.NewRecorder()
+	w.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "unhealthy until registered")
+
+	w.heartbeat.Store(time.Now().Add(-5 * time.Second).UnixNano())
+	rec = httptest.NewRecorder()
+	w.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "unhealthy after missing heartbeats")
+
+	w.heartbeat.Store(time.Now().UnixNano())
+	rec = httptest.NewRecorder()
+	w.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
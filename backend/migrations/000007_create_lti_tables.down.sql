@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS lti_deep_links;
+DROP TABLE IF EXISTS lti_launches;
+DROP TABLE IF EXISTS lti_user_links;
+DROP TABLE IF EXISTS lti_login_states;
+DROP TABLE IF EXISTS lti_platforms;
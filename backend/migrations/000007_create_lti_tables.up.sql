@@ -0,0 +1,58 @@
+CREATE TABLE IF NOT EXISTS lti_platforms (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    issuer TEXT NOT NULL,
+    client_id TEXT NOT NULL,
+    deployment_ids TEXT[] NOT NULL DEFAULT '{}',
+    auth_login_url TEXT NOT NULL,
+    auth_token_url TEXT NOT NULL,
+    keyset_url TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (issuer, client_id)
+);
+
+-- state/nonce pairs issued during OIDC login initiation, consumed by the launch
+CREATE TABLE IF NOT EXISTS lti_login_states (
+    state TEXT PRIMARY KEY,
+    nonce TEXT NOT NULL UNIQUE,
+    platform_id INTEGER NOT NULL REFERENCES lti_platforms(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+-- maps an LMS user (platform + subject) to a NodeTurtle account
+CREATE TABLE IF NOT EXISTS lti_user_links (
+    platform_id INTEGER NOT NULL REFERENCES lti_platforms(id) ON DELETE CASCADE,
+    subject TEXT NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (platform_id, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_lti_user_links_user_id ON lti_user_links(user_id);
+
+-- resource link launches, used for grade passback of assignment submissions
+CREATE TABLE IF NOT EXISTS lti_launches (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    platform_id INTEGER NOT NULL REFERENCES lti_platforms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    subject TEXT NOT NULL,
+    project_id UUID REFERENCES projects(id) ON DELETE SET NULL,
+    resource_link_id TEXT NOT NULL,
+    lineitem_url TEXT,
+    submitted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_lti_launches_user_id ON lti_launches(user_id);
+
+-- pending deep linking requests waiting for the instructor to pick projects
+CREATE TABLE IF NOT EXISTS lti_deep_links (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    platform_id INTEGER NOT NULL REFERENCES lti_platforms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    deployment_id TEXT NOT NULL,
+    return_url TEXT NOT NULL,
+    data TEXT,
+    expires_at TIMESTAMPTZ NOT NULL
+);
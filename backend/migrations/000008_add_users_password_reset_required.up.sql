@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_reset_required bool NOT NULL DEFAULT false;
\ No newline at end of file
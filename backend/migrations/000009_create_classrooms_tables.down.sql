@@ -0,0 +1,4 @@
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_classroom_not_public;
+ALTER TABLE projects DROP COLUMN IF EXISTS classroom_id;
+DROP TABLE IF EXISTS classroom_members;
+DROP TABLE IF EXISTS classrooms;
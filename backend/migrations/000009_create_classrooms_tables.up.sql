@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS classrooms (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_classrooms_owner_id ON classrooms(owner_id);
+
+CREATE TABLE IF NOT EXISTS classroom_members (
+    classroom_id UUID NOT NULL REFERENCES classrooms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL DEFAULT 'student',
+    joined_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (classroom_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_classroom_members_user_id ON classroom_members(user_id);
+
+-- projects shared "to my class" are only visible to the classroom roster and can never be public
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS classroom_id UUID REFERENCES classrooms(id) ON DELETE SET NULL;
+ALTER TABLE projects ADD CONSTRAINT projects_classroom_not_public CHECK (classroom_id IS NULL OR is_public = FALSE);
+
+CREATE INDEX IF NOT EXISTS idx_projects_classroom_id ON projects(classroom_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_project_likes_created_at;
+DROP TABLE IF EXISTS featured_queue;
+ALTER TABLE projects DROP COLUMN IF EXISTS featured_pinned;
+ALTER TABLE projects DROP COLUMN IF EXISTS featured_at;
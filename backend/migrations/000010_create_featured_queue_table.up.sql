@@ -0,0 +1,15 @@
+-- when the current featured slot started, used to measure engagement since featuring
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS featured_at TIMESTAMPTZ;
+-- pinned projects are never rotated out automatically
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS featured_pinned BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE projects SET featured_at = NOW() WHERE featured_until IS NOT NULL AND featured_until > NOW();
+
+CREATE TABLE IF NOT EXISTS featured_queue (
+    project_id UUID PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+    queued_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    queued_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_featured_queue_queued_at ON featured_queue(queued_at);
+CREATE INDEX IF NOT EXISTS idx_project_likes_created_at ON project_likes(project_id, created_at);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS project_fork_events;
+DROP INDEX IF EXISTS idx_projects_forked_from;
+ALTER TABLE projects DROP COLUMN IF EXISTS fork_count;
+ALTER TABLE projects DROP COLUMN IF EXISTS forked_from;
@@ -0,0 +1,14 @@
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS forked_from UUID REFERENCES projects(id) ON DELETE SET NULL;
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS fork_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_projects_forked_from ON projects(forked_from);
+
+-- fork history kept independently of the projects so deleting forks does not reset rate limits
+CREATE TABLE IF NOT EXISTS project_fork_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source_id UUID REFERENCES projects(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_fork_events_user_id ON project_fork_events(user_id, created_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS data_dumps;
+ALTER TABLE users DROP COLUMN IF EXISTS research_opt_out;
@@ -0,0 +1,11 @@
+-- users who opted out have their projects left out of public data dumps
+ALTER TABLE users ADD COLUMN IF NOT EXISTS research_opt_out BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS data_dumps (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    filename TEXT NOT NULL UNIQUE,
+    records INTEGER NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    sha256 TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
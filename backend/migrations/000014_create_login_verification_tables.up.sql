@@ -0,0 +1,21 @@
+-- countries a user has confirmed logging in from
+CREATE TABLE IF NOT EXISTS trusted_locations (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    country CHAR(2) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (user_id, country)
+);
+
+-- one-time codes emailed when a login comes from a country that is not trusted yet
+CREATE TABLE IF NOT EXISTS login_challenges (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    country CHAR(2) NOT NULL,
+    code_hash BYTEA NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_challenges_user_id ON login_challenges(user_id);
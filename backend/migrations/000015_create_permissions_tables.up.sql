@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS permissions (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id INTEGER NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission_id INTEGER NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('users.read', 'List users and view their accounts'),
+    ('users.update', 'Change the username, email, activation and role of users'),
+    ('users.delete', 'Delete user accounts'),
+    ('users.ban', 'Ban and unban users'),
+    ('users.provision', 'Create and remove accounts in bulk'),
+    ('projects.read', 'List all projects, including private ones'),
+    ('projects.feature', 'Feature projects and manage the featured queue'),
+    ('dumps.generate', 'Generate public data dumps'),
+    ('metrics.read', 'View service metrics'),
+    ('lti.manage', 'Register LMS platforms');
+
+-- admins keep full access
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p WHERE r.name = 'admin';
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name IN ('users.read', 'users.ban', 'projects.read', 'projects.feature')
+WHERE r.name = 'moderator';
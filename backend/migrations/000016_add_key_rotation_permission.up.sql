@@ -0,0 +1,6 @@
+INSERT INTO permissions (name, description) VALUES
+    ('auth.keys.rotate', 'Rotate the access token signing key');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'auth.keys.rotate'
+WHERE r.name = 'admin';
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS project_webhooks (
+    project_id UUID PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    like_thresholds INTEGER[] NOT NULL DEFAULT '{100}',
+    notify_featured BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- every milestone is delivered once, the row is claimed before the request is sent
+CREATE TABLE IF NOT EXISTS project_webhook_deliveries (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    event VARCHAR(50) NOT NULL,
+    threshold INTEGER NOT NULL DEFAULT 0,
+    status_code INTEGER,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMPTZ,
+    UNIQUE (project_id, event, threshold)
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_webhook_deliveries_project ON project_webhook_deliveries(project_id, created_at DESC);
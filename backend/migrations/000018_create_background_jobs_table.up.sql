@@ -0,0 +1,21 @@
+-- progress of long running maintenance work such as online index builds and batched backfills
+CREATE TABLE IF NOT EXISTS background_jobs (
+    name TEXT PRIMARY KEY,
+    kind TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('running', 'completed', 'failed')),
+    total BIGINT,
+    processed BIGINT NOT NULL DEFAULT 0,
+    -- last key a batched job processed, it resumes from here after a restart
+    cursor TEXT,
+    error TEXT,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('jobs.read', 'View the progress of background jobs');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'jobs.read'
+WHERE r.name = 'admin';
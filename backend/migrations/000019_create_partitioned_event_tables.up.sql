@@ -0,0 +1,58 @@
+-- High volume append-only tables are partitioned by month on created_at, so old data is removed by dropping
+-- whole partitions instead of deleting rows. Partitions are created ahead and pruned by the API scheduler,
+-- see internal/database/partitions.go. Primary keys of partitioned tables have to include the partition key.
+
+CREATE TABLE IF NOT EXISTS events (
+    id BIGSERIAL,
+    type TEXT NOT NULL,
+    subject_id UUID,
+    actor_id UUID,
+    payload JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+CREATE INDEX IF NOT EXISTS idx_events_type_created_at ON events(type, created_at);
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id BIGSERIAL,
+    actor_id UUID,
+    action TEXT NOT NULL,
+    target_type TEXT,
+    target_id TEXT,
+    details JSONB NOT NULL DEFAULT '{}',
+    ip TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs(actor_id, created_at);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGSERIAL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id, created_at DESC);
+
+-- partitions for the current and the next two months, later ones are created by the scheduler
+DO $$
+DECLARE
+    t TEXT;
+    m DATE;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['events', 'audit_logs', 'notifications'] LOOP
+        FOR i IN 0..2 LOOP
+            m := date_trunc('month', NOW() AT TIME ZONE 'UTC')::DATE + (i || ' month')::INTERVAL;
+            EXECUTE format(
+                'CREATE TABLE IF NOT EXISTS %I PARTITION OF %I FOR VALUES FROM (%L) TO (%L)',
+                t || '_p' || to_char(m, 'YYYY_MM'), t, m::TIMESTAMP AT TIME ZONE 'UTC', (m + INTERVAL '1 month')::TIMESTAMP AT TIME ZONE 'UTC'
+            );
+        END LOOP;
+    END LOOP;
+END $$;
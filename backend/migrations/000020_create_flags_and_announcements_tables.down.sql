@@ -0,0 +1,4 @@
+DELETE FROM permissions WHERE name IN ('flags.manage', 'announcements.manage');
+
+DROP TABLE IF EXISTS announcements;
+DROP TABLE IF EXISTS feature_flags;
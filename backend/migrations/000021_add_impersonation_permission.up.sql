@@ -0,0 +1,6 @@
+INSERT INTO permissions (name, description) VALUES
+    ('users.impersonate', 'Act as a regular user to debug what they see');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'users.impersonate'
+WHERE r.name = 'admin';
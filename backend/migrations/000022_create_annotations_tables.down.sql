@@ -0,0 +1,4 @@
+DELETE FROM permissions WHERE name = 'annotations.manage';
+
+DROP TABLE IF EXISTS project_annotations;
+DROP TABLE IF EXISTS user_annotations;
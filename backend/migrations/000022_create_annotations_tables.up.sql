@@ -0,0 +1,30 @@
+-- the foreign key briefly locks projects
+SET lock_timeout = '5s';
+
+-- internal notes and labels staff keep on accounts and projects, never shown to the users themselves
+CREATE TABLE IF NOT EXISTS user_annotations (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    labels TEXT[] NOT NULL DEFAULT '{}',
+    notes TEXT NOT NULL DEFAULT '',
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS project_annotations (
+    project_id UUID PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+    labels TEXT[] NOT NULL DEFAULT '{}',
+    notes TEXT NOT NULL DEFAULT '',
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- admin listings filter by label
+CREATE INDEX IF NOT EXISTS idx_user_annotations_labels ON user_annotations USING GIN (labels);
+CREATE INDEX IF NOT EXISTS idx_project_annotations_labels ON project_annotations USING GIN (labels);
+
+INSERT INTO permissions (name, description) VALUES
+    ('annotations.manage', 'Read and edit internal notes and labels on users and projects');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'annotations.manage'
+WHERE r.name IN ('admin', 'moderator');
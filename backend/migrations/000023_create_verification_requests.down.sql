@@ -0,0 +1,5 @@
+DELETE FROM permissions WHERE name = 'users.verify';
+
+DROP TABLE IF EXISTS verification_requests;
+
+ALTER TABLE users DROP COLUMN IF EXISTS verified;
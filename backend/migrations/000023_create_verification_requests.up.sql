@@ -0,0 +1,24 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS verified BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- evidence users submit to get the verified badge, reviewed by admins
+CREATE TABLE IF NOT EXISTS verification_requests (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    evidence TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    review_note TEXT NOT NULL DEFAULT '',
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- a user has at most one request waiting for review
+CREATE UNIQUE INDEX IF NOT EXISTS idx_verification_requests_pending ON verification_requests (user_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_verification_requests_user_id ON verification_requests (user_id, created_at DESC);
+
+INSERT INTO permissions (name, description) VALUES
+    ('users.verify', 'Review verification requests and grant the verified badge');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'users.verify'
+WHERE r.name = 'admin';
@@ -0,0 +1,14 @@
+-- the foreign key briefly locks projects
+SET lock_timeout = '5s';
+
+-- co-authors credited by the project owner, listed on their profiles once they accept
+CREATE TABLE IF NOT EXISTS project_credits (
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    responded_at TIMESTAMPTZ,
+    PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_credits_user_id ON project_credits (user_id, status);
@@ -0,0 +1,14 @@
+-- the foreign key briefly locks projects
+SET lock_timeout = '5s';
+
+-- earlier versions of a project, stored whenever its flow changes
+CREATE TABLE IF NOT EXISTS project_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    description TEXT,
+    data JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_revisions_project_id ON project_revisions (project_id, id DESC);
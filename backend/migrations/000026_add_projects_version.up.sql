@@ -0,0 +1,4 @@
+SET lock_timeout = '5s';
+
+-- bumped on every change of the flow so autosaves from concurrent tabs can't overwrite each other
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,4 @@
+DELETE FROM users WHERE guest_expires_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_users_guest_expires_at;
+ALTER TABLE users DROP COLUMN IF EXISTS guest_expires_at;
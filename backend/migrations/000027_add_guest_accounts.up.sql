@@ -0,0 +1,4 @@
+-- guest accounts are anonymous sessions, deleted with their projects once they expire unless claimed
+ALTER TABLE users ADD COLUMN IF NOT EXISTS guest_expires_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_users_guest_expires_at ON users(guest_expires_at) WHERE guest_expires_at IS NOT NULL;
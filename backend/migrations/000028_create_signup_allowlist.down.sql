@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'signups.manage';
+
+DROP TABLE IF EXISTS signup_allowlist;
@@ -0,0 +1,17 @@
+-- subnets and email domains exempt from the signup velocity limits, e.g. schools registering a whole class at once
+CREATE TABLE IF NOT EXISTS signup_allowlist (
+    id BIGSERIAL PRIMARY KEY,
+    subnet CIDR UNIQUE,
+    domain CITEXT UNIQUE,
+    note TEXT NOT NULL DEFAULT '',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((subnet IS NULL) <> (domain IS NULL))
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('signups.manage', 'Manage the subnets and email domains exempt from signup limits');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'signups.manage'
+WHERE r.name = 'admin';
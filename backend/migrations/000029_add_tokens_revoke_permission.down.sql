@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_tokens_created_at;
+
+DELETE FROM permissions WHERE name = 'tokens.revoke';
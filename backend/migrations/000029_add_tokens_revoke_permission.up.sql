@@ -0,0 +1,9 @@
+INSERT INTO permissions (name, description) VALUES
+    ('tokens.revoke', 'Revoke tokens in bulk by user, scope or issue time');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'tokens.revoke'
+WHERE r.name = 'admin';
+
+-- revocations by issue time filter on created_at
+CREATE INDEX IF NOT EXISTS idx_tokens_created_at ON tokens(created_at);
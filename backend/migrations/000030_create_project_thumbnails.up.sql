@@ -0,0 +1,11 @@
+-- thumbnails rendered from the flow of a project, re-rendered once the project version moves on.
+-- Programs exceeding the rendering limits are stored with the error so they aren't executed on every request.
+CREATE TABLE IF NOT EXISTS project_thumbnails (
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    format TEXT NOT NULL CHECK (format IN ('png', 'svg')),
+    version INTEGER NOT NULL,
+    content BYTEA,
+    error TEXT,
+    rendered_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (project_id, format)
+);
@@ -0,0 +1,5 @@
+SET lock_timeout = '5s';
+
+DROP INDEX IF EXISTS idx_project_likes_created_at;
+DROP TABLE IF EXISTS project_views;
+ALTER TABLE projects DROP COLUMN IF EXISTS views_count;
@@ -0,0 +1,15 @@
+SET lock_timeout = '5s';
+
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS views_count INTEGER NOT NULL DEFAULT 0;
+
+-- one row per viewer and day, so reloading a project doesn't inflate its views.
+-- viewer is the user id of signed in viewers and a hash of the address of guests.
+CREATE TABLE IF NOT EXISTS project_views (
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    viewer TEXT NOT NULL,
+    viewed_on DATE NOT NULL DEFAULT CURRENT_DATE,
+    PRIMARY KEY (project_id, viewed_on, viewer)
+);
+
+-- recent likes are part of the trending score
+CREATE INDEX IF NOT EXISTS idx_project_likes_created_at ON project_likes(created_at);
@@ -0,0 +1,10 @@
+-- projects a user opened lately, so the editor can offer to continue where they left off on any device.
+-- Only the latest opening of each project is kept.
+CREATE TABLE IF NOT EXISTS user_recent_projects (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    opened_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, project_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_recent_projects_opened_at ON user_recent_projects(user_id, opened_at DESC);
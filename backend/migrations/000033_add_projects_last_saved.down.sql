@@ -0,0 +1,4 @@
+SET lock_timeout = '5s';
+
+ALTER TABLE projects DROP COLUMN IF EXISTS last_saved_session;
+ALTER TABLE projects DROP COLUMN IF EXISTS last_saved_by;
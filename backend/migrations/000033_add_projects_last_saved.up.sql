@@ -0,0 +1,5 @@
+SET lock_timeout = '5s';
+
+-- who autosaved the flow last and from which editor session, so a rejected autosave can name the other editor
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS last_saved_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS last_saved_session TEXT;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS project_upload_chunks;
+DROP TABLE IF EXISTS project_uploads;
@@ -0,0 +1,20 @@
+-- project import files uploaded in chunks, so a dropped connection only costs the chunk in transit.
+-- Complete uploads are assembled into a project, unfinished ones are deleted once they expire.
+CREATE TABLE IF NOT EXISTS project_uploads (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    size BIGINT NOT NULL CHECK (size > 0),
+    received BIGINT NOT NULL DEFAULT 0 CHECK (received <= size),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_uploads_user_id ON project_uploads(user_id);
+CREATE INDEX IF NOT EXISTS idx_project_uploads_expires_at ON project_uploads(expires_at);
+
+CREATE TABLE IF NOT EXISTS project_upload_chunks (
+    upload_id UUID NOT NULL REFERENCES project_uploads(id) ON DELETE CASCADE,
+    position BIGINT NOT NULL,
+    content BYTEA NOT NULL,
+    PRIMARY KEY (upload_id, position)
+);
@@ -0,0 +1,6 @@
+INSERT INTO permissions (name, description) VALUES
+    ('projects.compact', 'Re-serialize stored project data canonically');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'projects.compact'
+WHERE r.name = 'admin';
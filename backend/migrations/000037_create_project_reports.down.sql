@@ -0,0 +1,7 @@
+DELETE FROM permissions WHERE name = 'reports.manage';
+
+DROP TABLE IF EXISTS project_reports;
+
+ALTER TABLE projects DROP COLUMN IF EXISTS hidden_by;
+ALTER TABLE projects DROP COLUMN IF EXISTS hidden_reason;
+ALTER TABLE projects DROP COLUMN IF EXISTS hidden_at;
@@ -0,0 +1,30 @@
+SET lock_timeout = '5s';
+
+-- projects taken down by moderators are hidden from everyone but their creator, whatever their visibility
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS hidden_at TIMESTAMPTZ;
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS hidden_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS hidden_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+-- reports users file against projects, reviewed by moderators
+CREATE TABLE IF NOT EXISTS project_reports (
+    id BIGSERIAL PRIMARY KEY,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL CHECK (reason IN ('spam', 'inappropriate', 'harassment', 'copyright', 'other')),
+    details TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'dismissed', 'actioned')),
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- a user has at most one open report per project
+CREATE UNIQUE INDEX IF NOT EXISTS idx_project_reports_open ON project_reports (project_id, reporter_id) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_project_reports_status ON project_reports (status, created_at);
+
+INSERT INTO permissions (name, description) VALUES
+    ('reports.manage', 'Review reported projects, dismiss reports and take projects down');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'reports.manage'
+WHERE r.name IN ('admin', 'moderator');
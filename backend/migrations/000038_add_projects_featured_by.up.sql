@@ -0,0 +1,4 @@
+SET lock_timeout = '5s';
+
+-- the admin who featured the project, directly or by queueing it
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS featured_by UUID REFERENCES users(id) ON DELETE SET NULL;
@@ -0,0 +1,15 @@
+-- what each user agreed to, per purpose; a missing row means the purpose's default applies
+CREATE TABLE IF NOT EXISTS user_consents (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose TEXT NOT NULL CHECK (purpose IN ('analytics', 'marketing_emails', 'research_data')),
+    granted BOOLEAN NOT NULL,
+    granted_at TIMESTAMPTZ,
+    withdrawn_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, purpose)
+);
+
+-- research data was opt-out before consents were tracked, keep the earlier choices
+INSERT INTO user_consents (user_id, purpose, granted, withdrawn_at)
+SELECT id, 'research_data', FALSE, NOW() FROM users WHERE research_opt_out = TRUE
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'retention.manage';
+
+DROP TABLE IF EXISTS retention_runs;
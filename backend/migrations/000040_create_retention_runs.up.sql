@@ -0,0 +1,21 @@
+-- every purge of a data class past its retention period, the record auditors ask for
+CREATE TABLE IF NOT EXISTS retention_runs (
+    id BIGSERIAL PRIMARY KEY,
+    class TEXT NOT NULL,
+    cutoff TIMESTAMPTZ NOT NULL,
+    deleted BIGINT NOT NULL DEFAULT 0,
+    trigger TEXT NOT NULL CHECK (trigger IN ('scheduled', 'manual')),
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    error TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_retention_runs_class ON retention_runs(class, started_at DESC);
+
+INSERT INTO permissions (name, description) VALUES
+    ('retention.manage', 'View data retention policies and purge expired data');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'retention.manage'
+WHERE r.name = 'admin';
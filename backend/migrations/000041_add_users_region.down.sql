@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS shard_moves;
+
+ALTER TABLE users DROP COLUMN IF EXISTS region;
@@ -0,0 +1,18 @@
+-- the regional database holding the user's data, empty for the primary database
+-- the users table of the primary database stays the directory of every user, whatever their region
+ALTER TABLE users ADD COLUMN IF NOT EXISTS region TEXT NOT NULL DEFAULT '';
+
+-- moves of users between regional databases, see cmd/shard-move
+CREATE TABLE IF NOT EXISTS shard_moves (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    from_region TEXT NOT NULL,
+    to_region TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'copying' CHECK (status IN ('copying', 'copied', 'done', 'failed')),
+    rows_copied BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_shard_moves_user_id ON shard_moves(user_id, started_at);
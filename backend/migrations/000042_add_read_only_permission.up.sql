@@ -0,0 +1,6 @@
+INSERT INTO permissions (name, description) VALUES
+    ('system.read_only', 'Switch the API to read-only mode during database failovers and maintenance');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'system.read_only'
+WHERE r.name = 'admin';
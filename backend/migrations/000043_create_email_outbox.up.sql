@@ -0,0 +1,17 @@
+-- every email sent, so support can trace a missing email to the request that triggered it and the provider's logs
+CREATE TABLE IF NOT EXISTS email_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    recipient TEXT NOT NULL,
+    template TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    request_id TEXT NOT NULL DEFAULT '',
+    message_id TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_outbox_user_id ON email_outbox(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_email_outbox_created_at ON email_outbox(created_at);
@@ -0,0 +1,5 @@
+SET lock_timeout = '5s';
+
+-- md5 of the flow data as the database prints it, written by the API along with the data so rows damaged in storage
+-- or changed behind its back stand out. Rows from before are filled in by the projects_data_checksum online migration.
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS data_checksum TEXT;
@@ -0,0 +1,3 @@
+SET lock_timeout = '5s';
+
+ALTER TABLE projects DROP COLUMN IF EXISTS data_bytes;
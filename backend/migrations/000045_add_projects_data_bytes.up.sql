@@ -0,0 +1,5 @@
+SET lock_timeout = '5s';
+
+-- size of the flow data as the database prints it, written by the API along with the data so the storage a user
+-- takes up adds up without reading every flow. Rows from before are filled in by the projects_data_bytes online migration.
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS data_bytes INTEGER;
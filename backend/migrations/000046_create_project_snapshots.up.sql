@@ -0,0 +1,14 @@
+-- the foreign key briefly locks projects
+SET lock_timeout = '5s';
+
+-- the published version of a project, served to embeds and copied by forks while the owner keeps editing the project.
+-- It is taken when a project is made public or featured and replaced only when the owner publishes again.
+-- Projects public since before have none yet, embeds and forks use their current version until they are published.
+CREATE TABLE IF NOT EXISTS project_snapshots (
+    project_id UUID PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    description TEXT,
+    data JSONB NOT NULL,
+    version INTEGER NOT NULL,
+    published_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_project_fork_events_source_id;
+DROP TABLE IF EXISTS notification_preferences;
@@ -0,0 +1,15 @@
+-- how a user wants to hear about activity on their projects. Users without a row get the defaults:
+-- in-app notifications of likes, no email digest.
+-- digest_sent_at is where the next digest starts, it's moved forward when a digest is sent or switched on.
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    likes BOOLEAN NOT NULL DEFAULT TRUE,
+    digest TEXT NOT NULL DEFAULT 'off' CHECK (digest IN ('off', 'daily', 'weekly')),
+    digest_sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_preferences_digest ON notification_preferences(digest_sent_at) WHERE digest <> 'off';
+
+-- digests count the forks of a user's projects
+CREATE INDEX IF NOT EXISTS idx_project_fork_events_source_id ON project_fork_events(source_id, created_at);
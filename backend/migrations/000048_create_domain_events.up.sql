@@ -0,0 +1,28 @@
+SET lock_timeout = '5s';
+
+-- append-only log of what happened to projects, written in the same transaction as the counters it explains,
+-- so derived tables can be rebuilt by replaying it, see internal/services/events.
+-- Unlike the partitioned events table it's never pruned: a projection rebuilt from a partial log would be wrong.
+-- Events outlive their project, replays skip projects that no longer exist.
+CREATE TABLE IF NOT EXISTS domain_events (
+    id BIGSERIAL PRIMARY KEY,
+    type TEXT NOT NULL,
+    project_id UUID NOT NULL,
+    actor_id UUID,
+    payload JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_domain_events_project_id ON domain_events(project_id);
+
+-- the log starts with the counters of every project. Likes and forks are counted from their rows to drop the drift
+-- gathered so far, views are taken as they are since project_views only holds the views within its retention.
+INSERT INTO domain_events (type, project_id, payload)
+SELECT 'project.counters_seeded', p.id, jsonb_build_object(
+    'likes', COALESCE(l.count, 0),
+    'views', p.views_count,
+    'forks', COALESCE(f.count, 0)
+)
+FROM projects p
+LEFT JOIN (SELECT project_id, COUNT(*) AS count FROM project_likes GROUP BY project_id) l ON l.project_id = p.id
+LEFT JOIN (SELECT forked_from, COUNT(*) AS count FROM projects WHERE forked_from IS NOT NULL GROUP BY forked_from) f ON f.forked_from = p.id;
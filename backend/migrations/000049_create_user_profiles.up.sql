@@ -0,0 +1,9 @@
+-- what users tell about themselves on their public profile. Users without a row have an empty profile.
+-- social_links maps a network, e.g. "github", to the URL of the user's page there.
+CREATE TABLE IF NOT EXISTS user_profiles (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    bio TEXT NOT NULL DEFAULT '',
+    website TEXT,
+    social_links JSONB NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+-- the object storage key of the user's avatar, its sizes are stored as <key>-<size>.png. NULL without an avatar.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_key TEXT;
@@ -0,0 +1,19 @@
+-- copies of their data users asked for. Pending exports are built by a background job, which stores the archive
+-- under object_key and emails a download link carrying the token hashed in token_hash.
+-- Ready exports can be downloaded until expires_at, then they're deleted along with the archive.
+CREATE TABLE IF NOT EXISTS data_exports (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'ready', 'failed')),
+    token_hash BYTEA UNIQUE,
+    object_key TEXT,
+    size BIGINT,
+    error TEXT,
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_exports_user_id ON data_exports(user_id, requested_at DESC);
+-- a user has one export being prepared at most
+CREATE UNIQUE INDEX IF NOT EXISTS idx_data_exports_pending ON data_exports(user_id) WHERE status = 'pending';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS render_workers;
+DROP TABLE IF EXISTS render_jobs;
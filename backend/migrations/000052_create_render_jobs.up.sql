@@ -0,0 +1,30 @@
+-- thumbnails waiting for a render worker, a job per project and format. Workers claim pending jobs, render the current
+-- version of the project into project_thumbnails and delete the job. attempts counts the claims, so a program that
+-- keeps taking its worker down is given up on instead of being handed to the next one.
+CREATE TABLE IF NOT EXISTS render_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    format TEXT NOT NULL CHECK (format IN ('png', 'svg')),
+    version INTEGER NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running')),
+    worker_id TEXT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    enqueued_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    claimed_at TIMESTAMPTZ,
+    UNIQUE (project_id, format)
+);
+
+CREATE INDEX IF NOT EXISTS idx_render_jobs_pending ON render_jobs(enqueued_at) WHERE status = 'pending';
+
+-- render worker processes, registered while they run. A worker missing its heartbeats is removed by its peers,
+-- which put the jobs it was running back in the queue.
+CREATE TABLE IF NOT EXISTS render_workers (
+    id TEXT PRIMARY KEY,
+    hostname TEXT NOT NULL,
+    concurrency INTEGER NOT NULL,
+    busy INTEGER NOT NULL DEFAULT 0,
+    jobs_done BIGINT NOT NULL DEFAULT 0,
+    jobs_failed BIGINT NOT NULL DEFAULT 0,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    heartbeat_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
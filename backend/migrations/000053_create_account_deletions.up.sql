@@ -0,0 +1,9 @@
+-- accounts their owner asked to delete. Their sessions are revoked when scheduled, a login before scheduled_for
+-- cancels the deletion, afterwards a background job deletes the user with everything cascading from it.
+CREATE TABLE IF NOT EXISTS account_deletions (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    scheduled_for TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_deletions_scheduled_for ON account_deletions(scheduled_for);
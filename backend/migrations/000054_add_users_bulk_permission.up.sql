@@ -0,0 +1,6 @@
+INSERT INTO permissions (name, description) VALUES
+    ('users.bulk', 'Activate, deactivate, change the role of and ban many users at once');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'users.bulk'
+WHERE r.name = 'admin';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS ban_appeals;
+DROP TABLE IF EXISTS ban_history;
+DROP FUNCTION IF EXISTS reject_ban_history_update();
@@ -0,0 +1,42 @@
+-- every ban and unban of a user, banned_users only holds the current ban. Rows are never changed, they go away
+-- with their user. actor_id has no foreign key so the history outlives the moderator, it's NULL for bans
+-- carried over from banned_users whose moderator was deleted already.
+CREATE TABLE IF NOT EXISTS ban_history (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    action TEXT NOT NULL CHECK (action IN ('ban', 'unban')),
+    reason TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMPTZ,
+    actor_id UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ban_history_user_id ON ban_history(user_id, id);
+
+CREATE OR REPLACE FUNCTION reject_ban_history_update() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'ban history is immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER ban_history_immutable
+    BEFORE UPDATE ON ban_history
+    FOR EACH ROW EXECUTE FUNCTION reject_ban_history_update();
+
+INSERT INTO ban_history (user_id, action, reason, expires_at, actor_id, created_at)
+SELECT user_id, 'ban', COALESCE(reason, ''), expires_at, banned_by, banned_at FROM banned_users;
+
+-- appeals of banned users, one per ban, reviewed by moderators. Accepting one lifts the ban.
+CREATE TABLE IF NOT EXISTS ban_appeals (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    ban_id BIGINT NOT NULL UNIQUE REFERENCES ban_history(id) ON DELETE CASCADE,
+    message TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'accepted', 'rejected')),
+    resolution TEXT NOT NULL DEFAULT '',
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_ban_appeals_open ON ban_appeals(created_at) WHERE status = 'open';
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS compute_usage;
@@ -0,0 +1,10 @@
+-- the server-side program runs of each user per day (UTC), metered against the daily compute quota of their role.
+-- Failed runs are metered as well, with the instructions they took before hitting a limit.
+CREATE TABLE IF NOT EXISTS compute_usage (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    runs INTEGER NOT NULL DEFAULT 0,
+    steps BIGINT NOT NULL DEFAULT 0,
+    millis BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, day)
+);
@@ -0,0 +1,6 @@
+INSERT INTO permissions (name, description) VALUES
+    ('emails.preview', 'Preview email templates with sample data and send test emails to oneself');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'emails.preview'
+WHERE r.name = 'admin';
@@ -0,0 +1,10 @@
+-- ranges of IP addresses every request is turned away from, expires_at is NULL for permanent bans
+CREATE TABLE IF NOT EXISTS ip_bans (
+    id BIGSERIAL PRIMARY KEY,
+    subnet CIDR NOT NULL UNIQUE,
+    reason TEXT NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS project_metadata_drafts;
+
+ALTER TABLE project_snapshots DROP COLUMN IF EXISTS tags;
+ALTER TABLE projects DROP COLUMN IF EXISTS tags;
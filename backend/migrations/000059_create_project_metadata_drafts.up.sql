@@ -0,0 +1,14 @@
+SET lock_timeout = '5s';
+
+-- tags describe a project alongside its title and description, the snapshot keeps the published ones
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE project_snapshots ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- metadata changes the owner staged, applied to the project when it is published next together with a new snapshot
+CREATE TABLE IF NOT EXISTS project_metadata_drafts (
+    project_id UUID PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
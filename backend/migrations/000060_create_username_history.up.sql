@@ -0,0 +1,11 @@
+-- usernames users gave up, kept for redirects. Nobody else can take them until reserved_until passes.
+CREATE TABLE IF NOT EXISTS username_history (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    username TEXT NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reserved_until TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_username_history_username ON username_history (username, changed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_username_history_user ON username_history (user_id, changed_at DESC);
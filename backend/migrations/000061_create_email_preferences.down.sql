@@ -0,0 +1,5 @@
+DELETE FROM email_outbox WHERE status = 'suppressed';
+ALTER TABLE email_outbox DROP CONSTRAINT IF EXISTS email_outbox_status_check;
+ALTER TABLE email_outbox ADD CONSTRAINT email_outbox_status_check CHECK (status IN ('pending', 'sent', 'failed'));
+
+DROP TABLE IF EXISTS email_preferences;
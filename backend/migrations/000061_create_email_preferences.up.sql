@@ -0,0 +1,12 @@
+-- which optional emails a user gets, users without a row get every category. Marketing emails follow the
+-- marketing_emails consent instead, consent for them is tracked with the other purposes.
+CREATE TABLE IF NOT EXISTS email_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    digests BOOLEAN NOT NULL DEFAULT TRUE,
+    security BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- emails of a category the recipient unsubscribed from are recorded without being sent
+ALTER TABLE email_outbox DROP CONSTRAINT IF EXISTS email_outbox_status_check;
+ALTER TABLE email_outbox ADD CONSTRAINT email_outbox_status_check CHECK (status IN ('pending', 'sent', 'failed', 'suppressed'));
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS folder_projects;
+DROP TABLE IF EXISTS project_folders;
@@ -0,0 +1,25 @@
+SET lock_timeout = '5s';
+
+-- folders users sort their own projects into, nested at most one level deep. Position orders the folders
+-- sharing a parent, deleting a folder deletes its subfolders and leaves their projects in no folder.
+CREATE TABLE IF NOT EXISTS project_folders (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    parent_id UUID REFERENCES project_folders(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_folders_user_id ON project_folders(user_id, parent_id, position);
+CREATE INDEX IF NOT EXISTS idx_project_folders_parent_id ON project_folders(parent_id);
+
+-- the folder a project is in, projects without a row are in no folder
+CREATE TABLE IF NOT EXISTS folder_projects (
+    project_id UUID PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+    folder_id UUID NOT NULL REFERENCES project_folders(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_folder_projects_folder_id ON folder_projects(folder_id, position);
@@ -0,0 +1,7 @@
+DELETE FROM permissions WHERE name = 'audit.read';
+
+DROP INDEX IF EXISTS idx_audit_logs_target;
+DROP INDEX IF EXISTS idx_audit_logs_action;
+
+DROP TRIGGER IF EXISTS audit_logs_immutable ON audit_logs;
+DROP FUNCTION IF EXISTS reject_audit_logs_change();
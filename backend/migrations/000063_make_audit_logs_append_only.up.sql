@@ -0,0 +1,21 @@
+-- entries of the audit log are never changed or deleted one by one, expired months are dropped as whole partitions
+CREATE OR REPLACE FUNCTION reject_audit_logs_change() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_logs_immutable ON audit_logs;
+CREATE TRIGGER audit_logs_immutable
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION reject_audit_logs_change();
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs(action, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_target ON audit_logs(target_type, target_id, created_at);
+
+INSERT INTO permissions (name, description) VALUES
+    ('audit.read', 'Browse the audit log of administrative and security-sensitive actions');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'audit.read'
+WHERE r.name = 'admin';
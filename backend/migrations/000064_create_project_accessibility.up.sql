@@ -0,0 +1,10 @@
+SET lock_timeout = '5s';
+
+-- descriptions owners write of what their project draws, for screen readers. The alt text goes with the
+-- thumbnail, the output description with the drawing a run of the program renders. Projects without a row have neither.
+CREATE TABLE IF NOT EXISTS project_accessibility (
+    project_id UUID PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+    thumbnail_alt TEXT NOT NULL DEFAULT '',
+    output_description TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
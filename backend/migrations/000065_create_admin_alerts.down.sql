@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'alerts.manage';
+DROP INDEX IF EXISTS idx_users_created_at;
+DROP TABLE IF EXISTS admin_alerts;
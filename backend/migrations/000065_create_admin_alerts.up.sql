@@ -0,0 +1,27 @@
+-- anomalies flagged by the analyzer, one per kind, subject and window however many instances notice it
+CREATE TABLE IF NOT EXISTS admin_alerts (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(50) NOT NULL,
+    subject TEXT NOT NULL DEFAULT '',
+    observed INT NOT NULL,
+    threshold DOUBLE PRECISION NOT NULL,
+    window_start TIMESTAMPTZ NOT NULL,
+    window_end TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    acknowledged_at TIMESTAMPTZ,
+    acknowledged_by UUID REFERENCES users(id) ON DELETE SET NULL,
+
+    UNIQUE (kind, subject, window_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_alerts_open ON admin_alerts(created_at) WHERE acknowledged_at IS NULL;
+
+-- the registration baseline counts the signups of the last day
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users(created_at);
+
+INSERT INTO permissions (name, description) VALUES
+    ('alerts.manage', 'Browse and acknowledge the anomaly alerts');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'alerts.manage'
+WHERE r.name = 'admin';
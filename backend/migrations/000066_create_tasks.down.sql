@@ -0,0 +1,2 @@
+DELETE FROM permissions WHERE name = 'tasks.manage';
+DROP TABLE IF EXISTS tasks;
@@ -0,0 +1,26 @@
+-- work queued by requests to be done in the background, such as sending emails, retried with backoff until it
+-- succeeds or runs out of attempts. Done tasks are deleted, dead ones are kept for admins to retry
+CREATE TABLE IF NOT EXISTS tasks (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'dead')),
+    attempts INT NOT NULL DEFAULT 0,
+    run_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    claimed_at TIMESTAMPTZ,
+    last_error TEXT NOT NULL DEFAULT '',
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    failed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_pending ON tasks(run_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_tasks_running ON tasks(claimed_at) WHERE status = 'running';
+CREATE INDEX IF NOT EXISTS idx_tasks_dead ON tasks(failed_at) WHERE status = 'dead';
+
+INSERT INTO permissions (name, description) VALUES
+    ('tasks.manage', 'Browse and retry the background tasks that ran out of attempts');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'tasks.manage'
+WHERE r.name = 'admin';
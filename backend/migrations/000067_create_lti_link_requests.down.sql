@@ -0,0 +1 @@
+DROP TABLE IF EXISTS lti_link_requests;
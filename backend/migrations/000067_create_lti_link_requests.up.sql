@@ -0,0 +1,10 @@
+-- LMS users launching with the email of an existing account, waiting for the account owner to confirm the link
+CREATE TABLE IF NOT EXISTS lti_link_requests (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    platform_id INTEGER NOT NULL REFERENCES lti_platforms(id) ON DELETE CASCADE,
+    subject TEXT NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_lti_link_requests_user_id ON lti_link_requests(user_id);
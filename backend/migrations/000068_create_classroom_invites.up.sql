@@ -0,0 +1,10 @@
+-- users invited to a classroom roster, they join once they accept
+CREATE TABLE IF NOT EXISTS classroom_invites (
+    classroom_id UUID NOT NULL REFERENCES classrooms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (classroom_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_classroom_invites_user_id ON classroom_invites(user_id);
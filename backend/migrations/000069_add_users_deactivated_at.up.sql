@@ -0,0 +1,5 @@
+-- set when an activated account is deactivated, telling it apart from an account waiting for its activation
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMPTZ;
+
+-- accounts that signed in before were activated once, the others are treated as pending
+UPDATE users SET deactivated_at = NOW() WHERE activated = FALSE AND last_login IS NOT NULL;
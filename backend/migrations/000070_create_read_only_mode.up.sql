@@ -0,0 +1,9 @@
+-- read-only mode shared by every API instance, see internal/database/mirror.go. The table holds a single row.
+CREATE TABLE IF NOT EXISTS read_only_mode (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    reason TEXT NOT NULL DEFAULT '',
+    since TIMESTAMPTZ
+);
+
+INSERT INTO read_only_mode DEFAULT VALUES ON CONFLICT DO NOTHING;
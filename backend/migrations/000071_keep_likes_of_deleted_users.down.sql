@@ -0,0 +1,8 @@
+DELETE FROM project_likes WHERE user_id IS NULL;
+
+ALTER TABLE project_likes DROP CONSTRAINT IF EXISTS project_likes_user_id_fkey;
+ALTER TABLE project_likes ADD CONSTRAINT project_likes_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE project_likes DROP CONSTRAINT IF EXISTS project_likes_project_id_user_id_key;
+ALTER TABLE project_likes ALTER COLUMN user_id SET NOT NULL;
+ALTER TABLE project_likes ADD PRIMARY KEY (project_id, user_id);
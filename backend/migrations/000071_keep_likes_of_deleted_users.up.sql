@@ -0,0 +1,8 @@
+-- likes of deleted accounts are kept without naming who liked, see internal/services/deletions.
+-- The primary key gives way to a unique constraint, which allows any number of likes without a user.
+ALTER TABLE project_likes DROP CONSTRAINT IF EXISTS project_likes_pkey;
+ALTER TABLE project_likes ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE project_likes ADD CONSTRAINT project_likes_project_id_user_id_key UNIQUE (project_id, user_id);
+
+ALTER TABLE project_likes DROP CONSTRAINT IF EXISTS project_likes_user_id_fkey;
+ALTER TABLE project_likes ADD CONSTRAINT project_likes_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL;